@@ -0,0 +1,28 @@
+package adapter
+
+import (
+	"context"
+	"time"
+)
+
+// Backend is a session on the server, as an ActivityMonitor lists it.
+type Backend struct {
+	ID       int64 // process or thread id
+	User     string
+	Database string
+	Client   string // address the session connected from
+	State    string // such as active, idle, idle in transaction
+	Wait     string // what it is waiting on, if anything
+	Query    string // the statement running, or the last one run
+	Duration time.Duration
+}
+
+// ActivityMonitor is an optional interface that connections can implement
+// to list the sessions on the server (pg_stat_activity, the MySQL process
+// list), leaving out the one listing them, and to cancel what one is
+// running or end it.
+type ActivityMonitor interface {
+	Activity(ctx context.Context) ([]Backend, error)
+	CancelBackend(ctx context.Context, id int64) error
+	TerminateBackend(ctx context.Context, id int64) error
+}
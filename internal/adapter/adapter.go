@@ -3,6 +3,7 @@ package adapter
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"strings"
 	"time"
@@ -60,6 +61,37 @@ type BatchIntrospector interface {
 	AllForeignKeys(ctx context.Context, db, schemaName string) (map[string][]schema.ForeignKey, error)
 }
 
+// ObjectIntrospector is an optional interface that connections can implement
+// to list the routines, sequences and triggers of a schema, shown in the
+// schema tree next to tables and views.
+type ObjectIntrospector interface {
+	Routines(ctx context.Context, db, schemaName string) ([]schema.Routine, error)
+	Sequences(ctx context.Context, db, schemaName string) ([]schema.Sequence, error)
+	Triggers(ctx context.Context, db, schemaName string) ([]schema.Trigger, error)
+}
+
+// DDLProvider is an optional interface that connections can implement to
+// return the CREATE statement of a table or view, followed by the CREATE
+// INDEX statements of a table where the database keeps them separately.
+type DDLProvider interface {
+	TableDDL(ctx context.Context, db, schemaName, table string) (string, error)
+}
+
+// TableStatsProvider is an optional interface that connections can implement
+// to report approximate row counts and on-disk sizes for the tables of a
+// schema, keyed by table name. The figures come from catalog statistics where
+// the database keeps them, so they are cheap but may lag behind the data.
+type TableStatsProvider interface {
+	TableStats(ctx context.Context, db, schemaName string) (map[string]schema.TableStats, error)
+}
+
+// DSNValidator is an optional interface that adapters can implement to check
+// the syntax of a connection string without connecting, so that a raw DSN
+// typed in the connection manager is rejected when it is saved.
+type DSNValidator interface {
+	ValidateDSN(dsn string) error
+}
+
 // RowIterator provides paginated access to query results.
 type RowIterator interface {
 	FetchNext(ctx context.Context) ([][]string, error)
@@ -69,6 +101,14 @@ type RowIterator interface {
 	Close() error
 }
 
+// Seeker is an optional interface that RowIterators can implement to jump
+// to a row offset without paging through every row before it.
+type Seeker interface {
+	// Seek positions the iterator so that the next FetchNext returns rows
+	// starting at the 0-based row offset.
+	Seek(ctx context.Context, offset int64) error
+}
+
 // QueryResult holds the result of a query execution.
 type QueryResult struct {
 	Columns  []ColumnMeta
@@ -106,6 +146,16 @@ const (
 	CompletionView
 )
 
+// NullValue is the cell value adapters report for SQL NULL. It contains a
+// NUL byte, so it cannot be confused with an empty string or the text 'NULL'.
+// Consumers decide how to present it (see IsNull).
+const NullValue = "\x00NULL"
+
+// IsNull reports whether a cell value is SQL NULL.
+func IsNull(value string) bool {
+	return value == NullValue
+}
+
 // SentinelEOF returns true if err is io.EOF.
 func SentinelEOF(err error) bool {
 	return errors.Is(err, io.EOF)
@@ -127,6 +177,98 @@ func IsSelectQuery(query string) bool {
 	return false
 }
 
+// CountQuery wraps a row-returning query so that it returns only its row
+// count. It returns "" for statements that cannot be used as a subquery,
+// such as SHOW, PRAGMA, or EXPLAIN.
+func CountQuery(query string) string {
+	q := TrimStatement(query)
+	if !isSubquery(q) {
+		return ""
+	}
+	return "SELECT COUNT(*) FROM (" + q + ") AS gotermsql_count"
+}
+
+// SearchQuery wraps a row-returning query so that it returns the 1-based
+// row number of the first row after row `after` whose column contains
+// text, compared case-insensitively as text. Rows are numbered in the
+// order the query returns them. It returns "" when the query cannot be
+// used as a subquery (see CountQuery).
+func SearchQuery(dialect, query, column, text string, after int64) string {
+	q := TrimStatement(query)
+	if !isSubquery(q) {
+		return ""
+	}
+
+	// "!" escapes LIKE wildcards in the search text on every dialect.
+	pattern := strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(text)
+	lit := QuoteLiteral(dialect, "%"+pattern+"%")
+	col := QuoteIdentifier(dialect, column)
+	var match string
+	switch dialect {
+	case "postgres":
+		match = "CAST(" + col + " AS TEXT) ILIKE " + lit
+	case "duckdb":
+		match = "CAST(" + col + " AS VARCHAR) ILIKE " + lit
+	case "mysql":
+		match = "LOWER(CAST(" + col + " AS CHAR)) LIKE LOWER(" + lit + ")"
+	default:
+		// SQLite's LIKE is case-insensitive for ASCII.
+		match = "CAST(" + col + " AS TEXT) LIKE " + lit
+	}
+	match += " ESCAPE '!'"
+
+	return fmt.Sprintf("SELECT gotermsql_n FROM (SELECT ROW_NUMBER() OVER () AS gotermsql_n, gotermsql_s.* FROM (%s) AS gotermsql_s) AS gotermsql_f WHERE gotermsql_n > %d AND %s ORDER BY gotermsql_n LIMIT 1",
+		q, after, match)
+}
+
+// isSubquery reports whether q starts like a statement that can be
+// wrapped as a subquery in FROM.
+func isSubquery(q string) bool {
+	fields := strings.Fields(strings.ToUpper(q))
+	if len(fields) == 0 {
+		return false
+	}
+	switch fields[0] {
+	case "SELECT", "WITH", "VALUES", "TABLE", "FROM":
+		return true
+	}
+	return false
+}
+
+// QuoteIdentifier quotes a SQL identifier for the given adapter dialect.
+// MySQL uses backticks; all other adapters use ANSI double quotes. Embedded
+// quote characters are escaped by doubling them.
+func QuoteIdentifier(dialect, name string) string {
+	if dialect == "mysql" {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// QuoteLiteral quotes a string as a SQL literal for the given adapter
+// dialect. Single quotes are doubled; MySQL also treats backslash as an
+// escape character by default, so backslashes are doubled there too.
+// NullValue is rendered as the NULL keyword.
+func QuoteLiteral(dialect, value string) string {
+	if IsNull(value) {
+		return "NULL"
+	}
+	if dialect == "mysql" {
+		value = strings.ReplaceAll(value, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// TrimStatement removes surrounding whitespace and any trailing semicolons
+// from a query so it can be embedded as a subquery.
+func TrimStatement(query string) string {
+	q := strings.TrimSpace(query)
+	for strings.HasSuffix(q, ";") {
+		q = strings.TrimSpace(strings.TrimSuffix(q, ";"))
+	}
+	return q
+}
+
 // Registry holds registered adapters by name.
 var Registry = map[string]Adapter{}
 
@@ -5,7 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/sadopc/gotermsql/internal/schema"
 )
 
 // mockAdapter is a minimal adapter for testing the registry.
@@ -231,3 +236,608 @@ func TestErrors(t *testing.T) {
 		t.Error("expected 3 distinct error messages")
 	}
 }
+
+func TestQuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		dialect, name, want string
+	}{
+		{"postgres", "users", `"users"`},
+		{"sqlite", `we"ird`, `"we""ird"`},
+		{"mysql", "users", "`users`"},
+		{"mysql", "we`ird", "`we``ird`"},
+	}
+	for _, tt := range tests {
+		if got := QuoteIdentifier(tt.dialect, tt.name); got != tt.want {
+			t.Errorf("QuoteIdentifier(%q, %q) = %s, want %s", tt.dialect, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTrimStatement(t *testing.T) {
+	tests := map[string]string{
+		"SELECT 1":          "SELECT 1",
+		"  SELECT 1;  ":     "SELECT 1",
+		"SELECT 1 ; ;\n":    "SELECT 1",
+		"SELECT ';' FROM t": "SELECT ';' FROM t",
+	}
+	for in, want := range tests {
+		if got := TrimStatement(in); got != want {
+			t.Errorf("TrimStatement(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestQuoteLiteral(t *testing.T) {
+	tests := []struct {
+		dialect, value, want string
+	}{
+		{"postgres", "plain", "'plain'"},
+		{"sqlite", "it's", "'it''s'"},
+		{"postgres", `a\b`, `'a\b'`},
+		{"mysql", `a\b`, `'a\\b'`},
+		{"mysql", "it's", "'it''s'"},
+		{"postgres", NullValue, "NULL"},
+		{"sqlite", "NULL", "'NULL'"},
+	}
+	for _, tt := range tests {
+		if got := QuoteLiteral(tt.dialect, tt.value); got != tt.want {
+			t.Errorf("QuoteLiteral(%q, %q) = %s, want %s", tt.dialect, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestIsNull(t *testing.T) {
+	if !IsNull(NullValue) {
+		t.Error("IsNull(NullValue) = false")
+	}
+	for _, v := range []string{"", "NULL", "null"} {
+		if IsNull(v) {
+			t.Errorf("IsNull(%q) = true", v)
+		}
+	}
+}
+
+func TestCountQuery(t *testing.T) {
+	tests := map[string]string{
+		"SELECT * FROM t;":                     "SELECT COUNT(*) FROM (SELECT * FROM t) AS gotermsql_count",
+		"with x as (select 1) select * from x": "SELECT COUNT(*) FROM (with x as (select 1) select * from x) AS gotermsql_count",
+		"SHOW TABLES":                          "",
+		"PRAGMA table_info(t)":                 "",
+		"EXPLAIN SELECT 1":                     "",
+		"   ":                                  "",
+	}
+	for in, want := range tests {
+		if got := CountQuery(in); got != want {
+			t.Errorf("CountQuery(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSearchQuery(t *testing.T) {
+	got := SearchQuery("postgres", "SELECT * FROM users;", "name", "50%_off", 10)
+	want := `SELECT gotermsql_n FROM (SELECT ROW_NUMBER() OVER () AS gotermsql_n, gotermsql_s.* FROM (SELECT * FROM users) AS gotermsql_s) AS gotermsql_f WHERE gotermsql_n > 10 AND CAST("name" AS TEXT) ILIKE '%50!%!_off%' ESCAPE '!' ORDER BY gotermsql_n LIMIT 1`
+	if got != want {
+		t.Errorf("SearchQuery(postgres) =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := SearchQuery("mysql", "SELECT 1", "a", "x", 0); !strings.Contains(got, "LOWER(CAST(`a` AS CHAR)) LIKE LOWER('%x%') ESCAPE '!'") {
+		t.Errorf("SearchQuery(mysql) = %q", got)
+	}
+	if got := SearchQuery("duckdb", "SELECT 1", "a", "x", 0); !strings.Contains(got, `CAST("a" AS VARCHAR) ILIKE`) {
+		t.Errorf("SearchQuery(duckdb) = %q", got)
+	}
+	if got := SearchQuery("sqlite", "SHOW TABLES", "a", "x", 0); got != "" {
+		t.Errorf("SearchQuery(SHOW) = %q, want empty", got)
+	}
+}
+
+func TestProfileQuery(t *testing.T) {
+	cols := []schema.Column{{Name: "id", Type: "integer"}, {Name: "doc", Type: "jsonb"}}
+	got := ProfileQuery("postgres", `"users"`, cols, 100)
+	want := `SELECT COUNT(*), COUNT("id"), COUNT(DISTINCT "id"), MIN("id"), MAX("id"), NULL, ` +
+		`COUNT(CAST("doc" AS TEXT)), COUNT(DISTINCT CAST("doc" AS TEXT)), MIN(CAST("doc" AS TEXT)), MAX(CAST("doc" AS TEXT)), AVG(LENGTH(CAST("doc" AS TEXT))) ` +
+		`FROM (SELECT * FROM "users" LIMIT 100) AS gotermsql_sample`
+	if got != want {
+		t.Errorf("ProfileQuery(postgres) =\n%s\nwant\n%s", got, want)
+	}
+	if got := ProfileQuery("mysql", "`t`", cols[1:], 10); !strings.Contains(got, "AVG(CHAR_LENGTH(CAST(`doc` AS CHAR)))") {
+		t.Errorf("ProfileQuery(mysql) = %q", got)
+	}
+	if got := TopValuesQuery("duckdb", `"t"`, cols[1], 10, 5); got != `SELECT CAST("doc" AS VARCHAR), COUNT(*) FROM (SELECT * FROM "t" LIMIT 10) AS gotermsql_sample GROUP BY 1 ORDER BY 2 DESC, 1 LIMIT 5` {
+		t.Errorf("TopValuesQuery(duckdb) = %q", got)
+	}
+
+	p, err := ParseProfile(cols, []string{"10", "10", "10", "1", "10", NullValue, "4", "2", "{}", `{"a":1}`, "3.5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := p.Columns[0]; p.Rows != 10 || id.Nulls != 0 || id.Distinct != 10 || id.Min != "1" || id.AvgLength != -1 {
+		t.Errorf("id = %+v", id)
+	}
+	if doc := p.Columns[1]; doc.Nulls != 6 || doc.Distinct != 2 || doc.Max != `{"a":1}` || doc.AvgLength != 3.5 {
+		t.Errorf("doc = %+v", doc)
+	}
+	if _, err := ParseProfile(cols, []string{"10"}); err == nil {
+		t.Error("a short row should be an error")
+	}
+}
+
+func TestSessionSQL(t *testing.T) {
+	s := Session{ReadOnly: true, StatementTimeout: 30 * time.Second, Schema: "app, public"}
+	tests := []struct {
+		dialect string
+		want    []string
+	}{
+		{"postgres", []string{
+			`SET search_path TO "app", "public"`,
+			"SET statement_timeout = 30000",
+			"SET default_transaction_read_only = on",
+		}},
+		{"mysql", []string{
+			"USE `app, public`",
+			"SET SESSION max_execution_time = 30000",
+			"SET SESSION TRANSACTION READ ONLY",
+		}},
+		{"sqlite", []string{"PRAGMA query_only = ON"}},
+		{"duckdb", []string{"SET schema = 'app, public'"}},
+	}
+	for _, tt := range tests {
+		got := SessionSQL(tt.dialect, s)
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("SessionSQL(%s) = %q, want %q", tt.dialect, got, tt.want)
+		}
+	}
+	if got := SessionSQL("postgres", Session{}); len(got) != 0 {
+		t.Errorf("SessionSQL with no settings = %q, want none", got)
+	}
+}
+
+func TestIsReadOnlyQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"SELECT * FROM users", true},
+		{"  -- note\nselect 1;", true},
+		{"WITH t AS (SELECT 1) SELECT * FROM t", true},
+		{"EXPLAIN ANALYZE SELECT 1", true},
+		{"SHOW TABLES; DESCRIBE users", true},
+		{"PRAGMA table_info(users)", true},
+		{"PRAGMA main.index_list('users'); PRAGMA user_version", true},
+		{"SELECT 'DROP TABLE users', \"update\" FROM t -- delete", true},
+		{"(SELECT 1) UNION (SELECT 2)", true},
+		{"SELECT replace(name, 'a', 'b') FROM t", true},
+
+		{"", true},
+		{"UPDATE users SET a = 1", false},
+		{"SELECT 1; DROP TABLE users", false},
+		{"WITH d AS (DELETE FROM t RETURNING *) SELECT * FROM d", false},
+		{"EXPLAIN ANALYZE DELETE FROM t", false},
+		{"SELECT * INTO copy FROM t", false},
+		{"SELECT * FROM t FOR UPDATE", false},
+		{"PRAGMA journal_mode = WAL", false},
+		{"PRAGMA user_version(5)", false},
+		{"PRAGMA journal_mode(DELETE)", false},
+		{"PRAGMA foreign_keys(0)", false},
+		{"PRAGMA main.foreign_keys('off')", false},
+		{"PRAGMA incremental_vacuum(10)", false},
+		{"BEGIN", false},
+		{"SELECT $$ DELETE $$", false}, // a string only to PostgreSQL
+		{"/* SELECT */ DELETE FROM t", false},
+
+		// Each of these hides a DROP from a reader that does not know
+		// one dialect's rules.
+		{"SELECT 'a\\''; DROP TABLE t; -- '", false},          // MySQL backslash escape
+		{"SELECT 1--1\n; DROP TABLE t", false},                // MySQL needs "-- "
+		{"SELECT 1 /*! ; DROP TABLE t */", false},             // MySQL runs /*! */
+		{"SELECT 1 # '\n; DROP TABLE t; -- '", false},         // MySQL # comment
+		{"SELECT $$'$$; DROP TABLE t; --'", false},            // PostgreSQL dollar quote
+		{"SELECT /* /* */ ' */; DROP TABLE t; -- '", false},   // PostgreSQL nested comment
+		{"SELECT $$'$$, a$x$; DROP TABLE t; -- $x$ '", false}, // $ in an identifier
+	}
+	for _, tt := range tests {
+		if got := IsReadOnlyQuery(tt.query); got != tt.want {
+			t.Errorf("IsReadOnlyQuery(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestLimitQuery(t *testing.T) {
+	tests := []struct {
+		dialect, query, want string
+	}{
+		{"postgres", "SELECT * FROM users;", "SELECT * FROM users\nLIMIT 100"},
+		{"sqlite", "select * from t -- all of it", "select * from t\nLIMIT 100"},
+		{"postgres", "SELECT 1; -- note", "SELECT 1\nLIMIT 100"},
+		{"mysql", "SELECT a -- the a\nFROM t /* all */ ;\n", "SELECT a -- the a\nFROM t\nLIMIT 100"},
+		{"mysql", "WITH a AS (SELECT 1) SELECT * FROM a UNION SELECT 2", "WITH a AS (SELECT 1) SELECT * FROM a UNION SELECT 2\nLIMIT 100"},
+		{"duckdb", "SELECT 'limit' AS \"fetch\"", "SELECT 'limit' AS \"fetch\"\nLIMIT 100"},
+
+		{"postgres", "SELECT * FROM t LIMIT 5", ""},
+		{"postgres", "SELECT * FROM t FETCH FIRST 5 ROWS ONLY", ""},
+		{"postgres", "SELECT * FROM t OFFSET 5", ""},
+		{"postgres", "SELECT * FROM t FOR SHARE", ""},
+		{"postgres", "SELECT 1; SELECT 2", ""},
+		{"postgres", "SHOW search_path", ""},
+		{"postgres", "EXPLAIN SELECT 1", ""},
+		{"postgres", "DELETE FROM t", ""},
+		{"mssql", "SELECT * FROM t", ""},
+	}
+	for _, tt := range tests {
+		got, ok := LimitQuery(tt.dialect, tt.query, 100)
+		if tt.want == "" {
+			if ok || got != tt.query {
+				t.Errorf("LimitQuery(%q) = %q, %v; want it unchanged", tt.query, got, ok)
+			}
+			continue
+		}
+		if !ok || got != tt.want {
+			t.Errorf("LimitQuery(%q) = %q, %v; want %q", tt.query, got, ok, tt.want)
+		}
+	}
+	if _, ok := LimitQuery("postgres", "SELECT 1", 0); ok {
+		t.Error("a limit of 0 should leave queries alone")
+	}
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		dialect, query string
+		want           []string // rules, in order
+	}{
+		{"postgres", "UPDATE users SET active = false", []string{LintMissingWhere}},
+		{"postgres", "DELETE FROM users;", []string{LintMissingWhere}},
+		{"postgres", "WITH old AS (SELECT id FROM users WHERE age > 90) DELETE FROM users", []string{LintMissingWhere}},
+		{"postgres", "UPDATE users SET a = (SELECT b FROM t WHERE t.id = 1)", []string{LintMissingWhere}},
+		{"postgres", "DELETE FROM users WHERE id = 1", nil},
+		{"postgres", "SELECT id FROM t FOR UPDATE", nil},
+		{"postgres", "INSERT INTO t (a) VALUES (1) ON CONFLICT (a) DO UPDATE SET a = 2", nil},
+
+		{"postgres", "SELECT a.id FROM a, b", []string{LintCrossJoin}},
+		{"postgres", "SELECT a.id FROM a, b JOIN c ON c.id = b.id ORDER BY 1", []string{LintCrossJoin}},
+		{"postgres", "SELECT a.id FROM a, b WHERE a.id = b.a_id", nil},
+		{"postgres", "SELECT a.id FROM a, b JOIN c ON c.id = b.id WHERE a.id = b.a_id", nil},
+		{"postgres", "SELECT t.id FROM t, unnest(t.tags) AS tag", nil},
+		{"postgres", "SELECT EXTRACT(YEAR FROM d), x FROM t", nil},
+
+		{"sqlite", "SELECT * FROM t", []string{LintSelectStar}},
+		{"sqlite", "SELECT DISTINCT t.* FROM t", []string{LintSelectStar}},
+		{"sqlite", "SELECT COUNT(*), 2 * 3 FROM t", nil},
+		{"sqlite", "SELECT id FROM (SELECT * FROM t) s", nil},
+		{"sqlite", "SELECT id FROM t WHERE EXISTS (SELECT * FROM u)", nil},
+
+		{"mysql", "SELECT id FROM users WHERE LOWER(email) = 'a@b.c'", []string{LintNonSargable}},
+		{"mysql", "SELECT id FROM users WHERE active AND (YEAR(created_at) > 2020 OR x = 1)", []string{LintNonSargable}},
+		{"mysql", "SELECT id FROM users WHERE name LIKE '%son'", []string{LintNonSargable}},
+		{"mysql", "SELECT id FROM a JOIN b ON DATE(a.at) = b.day", []string{LintNonSargable}},
+		{"mysql", "SELECT LOWER(email) FROM users WHERE email = LOWER('A@B.C') AND name LIKE 'j%'", nil},
+		{"mysql", "SELECT id FROM users WHERE created_at > NOW() - INTERVAL 1 DAY", nil},
+		{"mysql", "SELECT id FROM users WHERE id IN (1, 2) GROUP BY id HAVING COUNT(id) > 1", nil},
+
+		// Strings and comments are not read as SQL.
+		{"postgres", "SELECT id FROM t WHERE note = 'DELETE FROM t' -- SELECT *", nil},
+		{"postgres", "SELECT * FROM a, b; DELETE FROM c", []string{LintCrossJoin, LintSelectStar, LintMissingWhere}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, w := range Lint(tt.dialect, tt.query) {
+			got = append(got, w.Rule)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("Lint(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestDropTargets(t *testing.T) {
+	tests := []struct {
+		dialect, query string
+		want           []DropTarget
+	}{
+		{"postgres", "DROP TABLE users", []DropTarget{{Kind: "TABLE", Name: "users", Ref: "users"}}},
+		{"postgres", `drop table if exists public."Big Table", b cascade;`, []DropTarget{
+			{Kind: "TABLE", Schema: "public", Name: "Big Table", Ref: `public."Big Table"`},
+			{Kind: "TABLE", Name: "b", Ref: "b"},
+		}},
+		{"mysql", "DROP TEMPORARY TABLE `a``b`", []DropTarget{{Kind: "TABLE", Name: "a`b", Ref: "`a``b`"}}},
+		{"postgres", "SELECT 1; DROP SCHEMA s; DROP DATABASE d", []DropTarget{
+			{Kind: "SCHEMA", Name: "s", Ref: "s"},
+			{Kind: "DATABASE", Name: "d", Ref: "d"},
+		}},
+		{"postgres", "DROP VIEW v", nil},
+		{"postgres", "DROP INDEX users_pkey", nil},
+		{"postgres", "SELECT 'DROP TABLE users'", nil},
+	}
+	for _, tt := range tests {
+		got := DropTargets(tt.dialect, tt.query)
+		if len(got) != len(tt.want) {
+			t.Errorf("DropTargets(%q) = %+v, want %+v", tt.query, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("DropTargets(%q)[%d] = %+v, want %+v", tt.query, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	r, err := NewRedactor([]string{"password", "^ssn$", "token"}, []string{`^sk_live_`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		dialect, query, want string
+	}{
+		{"postgres", "SELECT * FROM users WHERE email = 'a@b.c' AND password = 'hunter2'",
+			"SELECT * FROM users WHERE email = 'a@b.c' AND password = '***'"},
+		{"postgres", "select id from users u where u.SSN in ('123-45-6789', '987-65-4321') -- lookup",
+			"select id from users u where u.SSN in ('***', '***') -- lookup"},
+		{"postgres", "UPDATE users SET api_token='abc', name = 'x' WHERE 'abc' = api_token",
+			"UPDATE users SET api_token='***', name = 'x' WHERE '***' = api_token"},
+		{"postgres", "SELECT 1 WHERE ssn_note = 'x' AND ssn IS NOT DISTINCT FROM 123",
+			"SELECT 1 WHERE ssn_note = 'x' AND ssn IS NOT DISTINCT FROM '***'"},
+		{"postgres", "INSERT INTO users (name, \"Password\") VALUES ('ann', 'pw1'), ('bob', 'pw2') RETURNING id",
+			"INSERT INTO users (name, \"Password\") VALUES ('ann', '***'), ('bob', '***') RETURNING id"},
+		{"postgres", "CREATE ROLE app LOGIN PASSWORD 'secret'", "CREATE ROLE app LOGIN PASSWORD '***'"},
+		{"mysql", "CREATE USER 'app'@'%' IDENTIFIED BY \"secret\"", "CREATE USER 'app'@'%' IDENTIFIED BY '***'"},
+		{"postgres", "SELECT charge('sk_live_abc', $$sk_live_def$$)", "SELECT charge('***', '***')"},
+		{"postgres", "SELECT password FROM users WHERE id = 1", "SELECT password FROM users WHERE id = 1"},
+		{"postgres", "SELECT tokens.id FROM tokens WHERE tokens.id = 5", "SELECT tokens.id FROM tokens WHERE tokens.id = 5"},
+		{"mysql", "SET PASSWORD FOR 'bob'@'%' = 'x'", "SET PASSWORD FOR 'bob'@'%' = '***'"},
+		{"mysql", "ALTER USER bob IDENTIFIED WITH caching_sha2_password BY 'x', ann IDENTIFIED BY 'y' PASSWORD EXPIRE",
+			"ALTER USER bob IDENTIFIED WITH caching_sha2_password BY '***', ann IDENTIFIED BY '***' PASSWORD EXPIRE"},
+		{"postgres", "UPDATE users SET password = crypt('x', gen_salt('bf')), name = 'ann' WHERE id = 1",
+			"UPDATE users SET password = crypt('***', gen_salt('***')), name = 'ann' WHERE id = 1"},
+		{"mysql", "SELECT 1 FROM users WHERE password = SHA2('x', 256) AND name = 'ann'",
+			"SELECT 1 FROM users WHERE password = SHA2('***', '***') AND name = 'ann'"},
+		{"postgres", "UPDATE users SET password = 'a' || 'b' WHERE id = 2", "UPDATE users SET password = '***' || '***' WHERE id = 2"},
+		{"postgres", `UPDATE users SET password = E'it\'s' WHERE id = 3`, `UPDATE users SET password = E'***' WHERE id = 3`},
+	}
+	for _, tt := range tests {
+		if got := r.Redact(tt.dialect, tt.query); got != tt.want {
+			t.Errorf("Redact(%q)\n got %q\nwant %q", tt.query, got, tt.want)
+		}
+	}
+
+	var none *Redactor
+	if got := none.Redact("postgres", "SELECT 'sk_live_x'"); got != "SELECT 'sk_live_x'" {
+		t.Errorf("nil Redactor changed the query: %q", got)
+	}
+	if _, err := NewRedactor([]string{"("}, nil); err == nil {
+		t.Error("an invalid pattern should be an error")
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect string
+		query   string
+		want    []string
+	}{
+		{"simple", "sqlite", "SELECT 1; SELECT 2;", []string{"SELECT 1", "SELECT 2"}},
+		{"no trailing delimiter", "sqlite", "SELECT 1;\n\nSELECT 2\n", []string{"SELECT 1", "SELECT 2"}},
+		{"empty and comment-only", "sqlite", ";; -- nothing\n; /* still nothing */", nil},
+		{"strings and identifiers", "sqlite", `SELECT 'a;b', "c;d"; SELECT 2`, []string{`SELECT 'a;b', "c;d"`, "SELECT 2"}},
+		{"doubled quote", "sqlite", "SELECT 'it''s; fine'; SELECT 2", []string{"SELECT 'it''s; fine'", "SELECT 2"}},
+		{"comments", "sqlite", "-- a; b\nSELECT 1 /* ; */; SELECT 2", []string{"-- a; b\nSELECT 1 /* ; */", "SELECT 2"}},
+		{"trigger body", "sqlite", "CREATE TRIGGER tr AFTER INSERT ON t BEGIN UPDATE u SET n = n + 1; DELETE FROM v; END; SELECT 1",
+			[]string{"CREATE TRIGGER tr AFTER INSERT ON t BEGIN UPDATE u SET n = n + 1; DELETE FROM v; END", "SELECT 1"}},
+		{"case in trigger", "sqlite", "CREATE TRIGGER tr AFTER INSERT ON t BEGIN UPDATE u SET n = CASE WHEN 1 THEN 2 END; END; SELECT 1",
+			[]string{"CREATE TRIGGER tr AFTER INSERT ON t BEGIN UPDATE u SET n = CASE WHEN 1 THEN 2 END; END", "SELECT 1"}},
+		{"begin transaction", "sqlite", "BEGIN; UPDATE t SET a = 1; COMMIT", []string{"BEGIN", "UPDATE t SET a = 1", "COMMIT"}},
+		{"dollar quoting", "postgres", "CREATE FUNCTION f() RETURNS int AS $body$ BEGIN RETURN 1; END; $body$ LANGUAGE plpgsql; SELECT f()",
+			[]string{"CREATE FUNCTION f() RETURNS int AS $body$ BEGIN RETURN 1; END; $body$ LANGUAGE plpgsql", "SELECT f()"}},
+		{"dollar in identifier", "postgres", "SELECT a$b$ FROM t; SELECT $1", []string{"SELECT a$b$ FROM t", "SELECT $1"}},
+		{"begin atomic", "postgres", "CREATE FUNCTION f() RETURNS int LANGUAGE sql BEGIN ATOMIC SELECT 1; END; SELECT 2",
+			[]string{"CREATE FUNCTION f() RETURNS int LANGUAGE sql BEGIN ATOMIC SELECT 1; END", "SELECT 2"}},
+		{"escape string", "postgres", `SELECT E'a\';b', e'\\'; SELECT 2`, []string{`SELECT E'a\';b', e'\\'`, "SELECT 2"}},
+		{"backslash outside escape string", "postgres", `SELECT 'a\'; SELECT 2`, []string{`SELECT 'a\'`, "SELECT 2"}},
+		{"nested comment", "postgres", "SELECT 1 /* a /* ; */ b; */; SELECT 2", []string{"SELECT 1 /* a /* ; */ b; */", "SELECT 2"}},
+		{"mysql backslash", "mysql", `SELECT 'a\';b'; SELECT 2`, []string{`SELECT 'a\';b'`, "SELECT 2"}},
+		{"mysql hash comment", "mysql", "SELECT 1 # a; b\n; SELECT 2", []string{"SELECT 1 # a; b", "SELECT 2"}},
+		{"mysql delimiter", "mysql", "DELIMITER $$\nCREATE PROCEDURE p() BEGIN SELECT 1; IF 1 THEN SELECT 2; END IF; END$$\nDELIMITER ;\nCALL p();",
+			[]string{"CREATE PROCEDURE p() BEGIN SELECT 1; IF 1 THEN SELECT 2; END IF; END", "CALL p()"}},
+		{"delimiter only in mysql", "sqlite", "DELIMITER $$\nSELECT 1", []string{"DELIMITER $$\nSELECT 1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, s := range SplitStatements(tt.dialect, tt.query) {
+				if tt.query[s.Start:s.End] != s.Text {
+					t.Errorf("statement %q is at %d:%d, which holds %q", s.Text, s.Start, s.End, tt.query[s.Start:s.End])
+				}
+				got = append(got, s.Text)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitStatements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatementAt(t *testing.T) {
+	query := "SELECT 1;\n\nSELECT 'x;y';\n-- end"
+	tests := []struct {
+		pos  int
+		want string
+	}{
+		{0, "SELECT 1"},
+		{8, "SELECT 1"},
+		{10, "SELECT 1"}, // between statements
+		{11, "SELECT 'x;y'"},
+		{20, "SELECT 'x;y'"},
+		{len(query), "SELECT 'x;y'"},
+	}
+	for _, tt := range tests {
+		got, ok := StatementAt("sqlite", query, tt.pos)
+		if !ok || got.Text != tt.want {
+			t.Errorf("StatementAt(%d) = %q, %v, want %q", tt.pos, got.Text, ok, tt.want)
+		}
+	}
+	if got := (Statement{Start: 11}).Line(query); got != 3 {
+		t.Errorf("Line() = %d, want 3", got)
+	}
+	if _, ok := StatementAt("sqlite", " -- nothing", 0); ok {
+		t.Error("expected no statement in a comment")
+	}
+}
+
+func TestParseParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect string
+		query   string
+		want    string
+		names   []string
+	}{
+		{"none", "postgres", "SELECT 1", "SELECT 1", nil},
+		{"named postgres", "postgres", "SELECT * FROM t WHERE a = :a AND b > :b OR a = :a", "SELECT * FROM t WHERE a = $1 AND b > $2 OR a = $1", []string{"a", "b"}},
+		{"named mysql", "mysql", "SELECT * FROM t WHERE a = :a AND b = :b OR a = :a", "SELECT * FROM t WHERE a = ? AND b = ? OR a = ?", []string{"a", "b"}},
+		{"native dollar", "postgres", "SELECT $2, $1", "SELECT $1, $2", []string{"$2", "$1"}},
+		{"question marks", "sqlite", "SELECT ?, ?", "SELECT ?, ?", []string{"?1", "?2"}},
+		{"json operator", "postgres", "SELECT doc ? 'key' FROM t", "SELECT doc ? 'key' FROM t", nil},
+		{"cast", "postgres", "SELECT :v::int, now()::date", "SELECT $1::int, now()::date", []string{"v"}},
+		{"strings and comments", "sqlite", "SELECT ':a', \"?\" -- :b ?\n/* :c */ FROM t", "SELECT ':a', \"?\" -- :b ?\n/* :c */ FROM t", nil},
+		{"dollar quoted", "postgres", "SELECT $$ :a $1 $$", "SELECT $$ :a $1 $$", nil},
+		{"identifiers", "postgres", "SELECT a$1, x:y FROM t", "SELECT a$1, x:y FROM t", nil},
+		{"assignment", "mysql", "SELECT @n := 1", "SELECT @n := 1", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pq := ParseParams(tt.dialect, tt.query)
+			if pq.Query != tt.want || !reflect.DeepEqual(pq.Names, tt.names) {
+				t.Errorf("ParseParams() = %q %q, want %q %q", pq.Query, pq.Names, tt.want, tt.names)
+			}
+		})
+	}
+
+	// Positional placeholders take a value each, repeated for a name.
+	pq := ParseParams("mysql", "SELECT :a, :b, :a")
+	if got := pq.Args([]any{1, 2}); !reflect.DeepEqual(got, []any{1, 2, 1}) {
+		t.Errorf("Args() = %v, want [1 2 1]", got)
+	}
+	pq = ParseParams("postgres", "SELECT :a, :b, :a")
+	if got := pq.Args([]any{1, 2}); !reflect.DeepEqual(got, []any{1, 2}) {
+		t.Errorf("Args() = %v, want [1 2]", got)
+	}
+}
+
+func TestBindValue(t *testing.T) {
+	tests := []struct {
+		text string
+		want any
+	}{
+		{"", nil},
+		{"null", nil},
+		{"NULL", nil},
+		{"42", int64(42)},
+		{"-7", int64(-7)},
+		{"1.5", 1.5},
+		{"true", true},
+		{"FALSE", false},
+		{"'007'", "007"},
+		{"'it''s'", "it's"},
+		{"''", ""},
+		{"NaN", "NaN"},
+		{"hello world", "hello world"},
+	}
+	for _, tt := range tests {
+		if got := BindValue(tt.text); got != tt.want {
+			t.Errorf("BindValue(%q) = %#v, want %#v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseCopy(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  *CopyCommand
+		sql   string
+	}{
+		{"not a copy", "COPY t TO STDOUT", nil, ""},
+		{"export table", `\copy users to 'users.csv' with (format csv, header)`,
+			&CopyCommand{Source: "users", File: "users.csv", Options: "with (format csv, header)"},
+			"COPY users TO STDOUT with (format csv, header)"},
+		{"export query", `\COPY (SELECT id, ')' FROM t WHERE a = 'x y') TO out.csv CSV HEADER;`,
+			&CopyCommand{Source: "(SELECT id, ')' FROM t WHERE a = 'x y')", File: "out.csv", Options: "CSV HEADER"},
+			"COPY (SELECT id, ')' FROM t WHERE a = 'x y') TO STDOUT CSV HEADER"},
+		{"import columns", "\\copy \"My Table\" (a, b)\nfrom '~/it''s.csv' (FORMAT csv)",
+			&CopyCommand{Source: `"My Table" (a, b)`, From: true, File: "~/it's.csv", Options: "(FORMAT csv)"},
+			`COPY "My Table" (a, b) FROM STDIN (FORMAT csv)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCopy(tt.query)
+			if err != nil {
+				t.Fatalf("ParseCopy() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParseCopy() = %+v, want %+v", got, tt.want)
+			}
+			if got != nil && got.SQL() != tt.sql {
+				t.Errorf("SQL() = %q, want %q", got.SQL(), tt.sql)
+			}
+		})
+	}
+
+	for _, q := range []string{`\copy t from stdin`, `\copy t to`, `\copy t into 'f'`, `\copy (SELECT 1 to 'f'`, `\copy t to 'f`} {
+		if _, err := ParseCopy(q); err == nil {
+			t.Errorf("ParseCopy(%q) succeeded, want an error", q)
+		}
+	}
+
+	for q, want := range map[string]bool{
+		`\copy t to 'f'`:                           true,
+		`\copy (SELECT 1) to 'f'`:                  true,
+		`\copy (DELETE FROM t RETURNING *) to 'f'`: false,
+		`\copy t from 'f'`:                         false,
+	} {
+		cp, _ := ParseCopy(q)
+		if cp.ReadOnly() != want {
+			t.Errorf("ReadOnly(%q) = %v, want %v", q, !want, want)
+		}
+	}
+}
+
+func TestParseAttach(t *testing.T) {
+	tests := []struct {
+		query string
+		want  *AttachCommand
+	}{
+		{"ATTACH DATABASE 'archive.db' AS archive;", &AttachCommand{Path: "archive.db", Alias: "archive"}},
+		{"attach '/tmp/it''s.db' as \"old data\"", &AttachCommand{Path: "/tmp/it's.db", Alias: "old data"}},
+		{"DETACH DATABASE archive", &AttachCommand{Detach: true, Alias: "archive"}},
+		{"detach [old data];", &AttachCommand{Detach: true, Alias: "old data"}},
+		{"ATTACH 'a.db' || '.bak' AS b", nil},
+		{"ATTACH :path AS b", nil},
+		{"DETACH a b", nil},
+		{"SELECT 1", nil},
+	}
+	for _, tt := range tests {
+		if got := ParseAttach(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseAttach(%q) = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestReferencedNames(t *testing.T) {
+	names := []string{"orders", "Order Items", "users", "notes"}
+	tests := []struct {
+		dialect, query string
+		want           []string
+	}{
+		{"sqlite", `SELECT * FROM ORDERS o JOIN "order items" i ON i.id = o.id`, []string{"orders", "Order Items"}},
+		{"mysql", "SELECT u.id FROM `db`.`users` u -- notes\nWHERE u.kind = 'orders'", []string{"users"}},
+		{"postgres", "SELECT $$notes$$, id FROM public.users /* orders */", []string{"users"}},
+		{"sqlite", "SELECT 1", nil},
+	}
+	for _, tt := range tests {
+		if got := ReferencedNames(tt.dialect, tt.query, names); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ReferencedNames(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
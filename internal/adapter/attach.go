@@ -0,0 +1,89 @@
+package adapter
+
+import (
+	"context"
+	"strings"
+)
+
+// Attacher is an optional interface that connections can implement to
+// attach further database files (SQLite's ATTACH DATABASE) to every
+// session of their pool, where the statement alone reaches one. Each
+// attached file is listed by Databases as a database of its own, with one
+// schema named after its alias.
+type Attacher interface {
+	Attach(ctx context.Context, path, alias string) error
+	Detach(ctx context.Context, alias string) error
+}
+
+// AttachCommand is an ATTACH DATABASE or DETACH DATABASE statement.
+type AttachCommand struct {
+	Detach bool
+	Path   string // the file attached; empty for a DETACH
+	Alias  string // the schema name it is attached as
+}
+
+// ParseAttach parses query as one of
+//
+//	ATTACH [DATABASE] 'file' AS name
+//	DETACH [DATABASE] name
+//
+// returning nil when it is neither, or attaches an expression other than
+// a quoted file name.
+func ParseAttach(query string) *AttachCommand {
+	q := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(query), "; \t\r\n"))
+	word, rest := cutWord(q)
+	cmd := &AttachCommand{}
+	switch strings.ToUpper(word) {
+	case "ATTACH":
+	case "DETACH":
+		cmd.Detach = true
+	default:
+		return nil
+	}
+	rest = strings.TrimSpace(rest)
+	if w, r := cutWord(rest); strings.EqualFold(w, "DATABASE") {
+		rest = strings.TrimSpace(r)
+	}
+
+	if !cmd.Detach {
+		if rest == "" || rest[0] != '\'' {
+			return nil
+		}
+		end := lexerFor("sqlite").skipQuoted(rest, 0)
+		if end < 2 || rest[end-1] != '\'' {
+			return nil
+		}
+		cmd.Path = strings.ReplaceAll(rest[1:end-1], "''", "'")
+		w, r := cutWord(strings.TrimSpace(rest[end:]))
+		if !strings.EqualFold(w, "AS") {
+			return nil
+		}
+		rest = strings.TrimSpace(r)
+	}
+
+	if cmd.Alias = unquoteName(rest); cmd.Alias == "" {
+		return nil
+	}
+	return cmd
+}
+
+// unquoteName returns the identifier s, without the double quotes,
+// brackets or backquotes around it, or "" when s is not one identifier.
+func unquoteName(s string) string {
+	if len(s) >= 2 {
+		switch open, end := s[0], s[len(s)-1]; {
+		case open == '"' && end == '"':
+			return strings.ReplaceAll(s[1:len(s)-1], `""`, `"`)
+		case open == '`' && end == '`':
+			return strings.ReplaceAll(s[1:len(s)-1], "``", "`")
+		case open == '[' && end == ']':
+			return s[1 : len(s)-1]
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !(c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80) {
+			return ""
+		}
+	}
+	return s
+}
@@ -0,0 +1,171 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Copier is an optional interface that connections can implement to run a
+// COPY ... TO STDOUT or COPY ... FROM STDIN (PostgreSQL), streaming the data
+// to w or from r rather than holding it in memory. Both return the number
+// of rows copied. An open transaction holds the copy.
+type Copier interface {
+	CopyTo(ctx context.Context, w io.Writer, query string) (int64, error)
+	CopyFrom(ctx context.Context, r io.Reader, query string) (int64, error)
+}
+
+// CopyCommand is a psql \copy meta-command: a COPY run on the server with
+// the data written to or read from a local file.
+type CopyCommand struct {
+	// Source is the table, with its column list if any, or the
+	// parenthesized query copied.
+	Source string
+	// From is set to import the file into the table rather than export to
+	// it.
+	From bool
+	File string
+	// Options are what follows the file name, as in WITH (FORMAT csv).
+	Options string
+}
+
+// ParseCopy parses a \copy meta-command, as psql writes it:
+//
+//	\copy table [(column, ...)] from 'file' [[with] (option, ...)]
+//	\copy {table | (query)} to 'file' [[with] (option, ...)]
+//
+// The file name may be left unquoted when it has no spaces. It returns
+// nil when query is not a \copy, and an error when it is one gotermsql
+// cannot run, such as a copy from stdin.
+func ParseCopy(query string) (*CopyCommand, error) {
+	q := strings.TrimSpace(query)
+	if len(q) < 6 || !strings.EqualFold(q[:5], `\copy`) || !isSpace(q[5]) {
+		return nil, nil
+	}
+	q = strings.TrimSpace(strings.TrimRight(q[5:], "; \t\r\n"))
+	l := lexerFor("postgres")
+
+	// The source: a query, or a table name and an optional column list.
+	var i int
+	if strings.HasPrefix(q, "(") {
+		i = l.closeParen(q, 0)
+	} else {
+		for i < len(q) && !isSpace(q[i]) && q[i] != '(' {
+			if q[i] == '"' {
+				i = l.skipQuoted(q, i)
+				continue
+			}
+			i++
+		}
+		if j := skipSpace(q, i); j < len(q) && q[j] == '(' {
+			i = l.closeParen(q, j)
+		}
+	}
+	if i < 0 {
+		return nil, errors.New(`\copy: unbalanced parentheses`)
+	}
+	cmd := &CopyCommand{Source: strings.TrimSpace(q[:i])}
+	if cmd.Source == "" {
+		return nil, errors.New(`\copy: name a table or a query`)
+	}
+
+	rest := strings.TrimSpace(q[i:])
+	dir, rest := cutWord(rest)
+	switch strings.ToLower(dir) {
+	case "from":
+		cmd.From = true
+	case "to":
+	default:
+		return nil, fmt.Errorf(`\copy: want FROM or TO after %s`, cmd.Source)
+	}
+
+	rest = strings.TrimSpace(rest)
+	switch {
+	case rest == "":
+		return nil, errors.New(`\copy: name the file`)
+	case rest[0] == '\'':
+		end := l.skipQuoted(rest, 0)
+		if end < 2 || rest[end-1] != '\'' {
+			return nil, errors.New(`\copy: unterminated file name`)
+		}
+		cmd.File = strings.ReplaceAll(rest[1:end-1], "''", "'")
+		rest = rest[end:]
+	default:
+		cmd.File, rest = cutWord(rest)
+		switch strings.ToLower(cmd.File) {
+		case "stdin", "stdout", "pstdin", "pstdout", "program":
+			return nil, fmt.Errorf(`\copy: %s is not supported; name a file`, strings.ToLower(cmd.File))
+		}
+	}
+	cmd.Options = strings.TrimSpace(rest)
+	return cmd, nil
+}
+
+// SQL returns the COPY statement run on the server.
+func (c CopyCommand) SQL() string {
+	dir := "TO STDOUT"
+	if c.From {
+		dir = "FROM STDIN"
+	}
+	return strings.TrimSpace("COPY " + c.Source + " " + dir + " " + c.Options)
+}
+
+// ReadOnly returns whether the copy leaves the database as it is: an
+// export of a table, or of a query IsReadOnlyQuery accepts.
+func (c CopyCommand) ReadOnly() bool {
+	if c.From {
+		return false
+	}
+	return !strings.HasPrefix(c.Source, "(") || IsReadOnlyQuery(c.Source[1:len(c.Source)-1])
+}
+
+// closeParen returns the index just past the parenthesis that closes the
+// one at q[i], or -1 when there is none. Quoted text and comments are
+// skipped.
+func (l lexer) closeParen(q string, i int) int {
+	depth := 0
+	for i < len(q) {
+		switch c := q[i]; {
+		case c == '\'' || c == '"':
+			i = l.skipQuoted(q, i)
+			continue
+		case c == '-' && strings.HasPrefix(q[i:], "--"):
+			eol := strings.IndexByte(q[i:], '\n')
+			if eol < 0 {
+				return -1
+			}
+			i += eol
+			continue
+		case c == '/' && strings.HasPrefix(q[i:], "/*"):
+			i = l.skipComment(q, i)
+			continue
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+		i++
+	}
+	return -1
+}
+
+// cutWord splits s at its first space.
+func cutWord(s string) (word, rest string) {
+	i := 0
+	for i < len(s) && !isSpace(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func skipSpace(q string, i int) int {
+	for i < len(q) && isSpace(q[i]) {
+		i++
+	}
+	return i
+}
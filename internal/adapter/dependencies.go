@@ -0,0 +1,62 @@
+package adapter
+
+import (
+	"context"
+	"strings"
+)
+
+// Kinds of the objects a DependencyProvider lists.
+const (
+	DependencyTable    = "table"
+	DependencyView     = "view"
+	DependencyMatView  = "materialized view"
+	DependencyTrigger  = "trigger"
+	DependencyFunction = "function"
+)
+
+// Dependency is an object that depends on a table or view, or one it
+// depends on, and how: the foreign key, the trigger.
+type Dependency struct {
+	Kind   string
+	Schema string
+	Name   string
+	Detail string
+}
+
+// Dependencies are the objects that depend on a table or view, which
+// altering or dropping it breaks, and the objects it depends on.
+type Dependencies struct {
+	Dependents []Dependency // the views reading it, the tables referencing it, its triggers
+	DependsOn  []Dependency // the tables and views it reads or references, its trigger functions
+}
+
+// DependencyProvider is an optional interface that connections can
+// implement to list what depends on a table or view and what it depends
+// on, from the catalog where the database keeps one.
+type DependencyProvider interface {
+	Dependencies(ctx context.Context, db, schemaName, name string) (Dependencies, error)
+}
+
+// ReferencedNames returns those of names that query mentions as an
+// identifier, quoted or not, ignoring case and skipping strings and
+// comments, in the order of names. It is how the objects a view or trigger
+// reads are found where the database keeps only its SQL.
+func ReferencedNames(dialect, query string, names []string) []string {
+	mentioned := map[string]bool{}
+	for _, t := range lexerFor(dialect).tokens(query) {
+		switch {
+		case t.kind == tokWord:
+			mentioned[t.text] = true
+		case t.kind == tokQuoted && t.raw[0] != '\'' && t.raw[0] != '$' && len(t.raw) >= 2:
+			q := t.raw[:1]
+			mentioned[strings.ToUpper(strings.ReplaceAll(t.raw[1:len(t.raw)-1], q+q, q))] = true
+		}
+	}
+	var found []string
+	for _, name := range names {
+		if mentioned[strings.ToUpper(name)] {
+			found = append(found, name)
+		}
+	}
+	return found
+}
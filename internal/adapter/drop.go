@@ -0,0 +1,101 @@
+package adapter
+
+import "strings"
+
+// DropTarget is a table, schema or database a DROP statement removes.
+type DropTarget struct {
+	Kind   string // "TABLE", "SCHEMA" or "DATABASE"
+	Schema string // schema (or database) a table is qualified with, if any
+	Name   string // unquoted, as written
+	Ref    string // as written, quotes included, for use in another statement
+}
+
+// String returns the target's name as written, qualified if it was.
+func (t DropTarget) String() string {
+	if t.Schema != "" {
+		return t.Schema + "." + t.Name
+	}
+	return t.Name
+}
+
+// DropTargets returns what the DROP TABLE, DROP SCHEMA and DROP DATABASE
+// statements in query remove, read the way dialect reads it. DROP TABLE
+// a, b gives a target for each table.
+func DropTargets(dialect, query string) []DropTarget {
+	var targets []DropTarget
+	for _, stmt := range statements(lexerFor(dialect).tokens(query)) {
+		targets = append(targets, dropTargets(stmt)...)
+	}
+	return targets
+}
+
+func dropTargets(stmt []token) []DropTarget {
+	i := 0
+	word := func(words ...string) bool {
+		if i < len(stmt) && stmt[i].kind == tokWord {
+			for _, w := range words {
+				if stmt[i].text == w {
+					i++
+					return true
+				}
+			}
+		}
+		return false
+	}
+	if !word("DROP") {
+		return nil
+	}
+	word("TEMPORARY") // MySQL's DROP TEMPORARY TABLE
+	if !word("TABLE", "SCHEMA", "DATABASE") {
+		return nil
+	}
+	kind := stmt[i-1].text
+	if word("IF") && !word("EXISTS") {
+		return nil
+	}
+
+	var targets []DropTarget
+	for i < len(stmt) {
+		// A name is up to three identifiers joined by dots; a table's
+		// schema is the one before its name.
+		var parts, raw []string
+		for i < len(stmt) {
+			part, ok := identifier(stmt[i])
+			if !ok {
+				break
+			}
+			parts = append(parts, part)
+			raw = append(raw, stmt[i].raw)
+			i++
+			if i == len(stmt) || stmt[i].text != "." {
+				break
+			}
+			i++
+		}
+		if len(parts) == 0 {
+			break
+		}
+		t := DropTarget{Kind: kind, Name: parts[len(parts)-1], Ref: strings.Join(raw, ".")}
+		if len(parts) > 1 {
+			t.Schema = parts[len(parts)-2]
+		}
+		targets = append(targets, t)
+		if i == len(stmt) || stmt[i].text != "," {
+			break
+		}
+		i++
+	}
+	return targets
+}
+
+// identifier returns the name t spells, unquoted, if it is an identifier.
+func identifier(t token) (string, bool) {
+	switch {
+	case t.kind == tokWord:
+		return t.raw, true
+	case t.kind == tokQuoted && (t.raw[0] == '"' || t.raw[0] == '`') && len(t.raw) >= 2:
+		q := t.raw[:1]
+		return strings.ReplaceAll(t.raw[1:len(t.raw)-1], q+q, q), true
+	}
+	return "", false
+}
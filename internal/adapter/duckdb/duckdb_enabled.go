@@ -31,13 +31,18 @@ func (a *duckdbAdapter) Name() string     { return "duckdb" }
 func (a *duckdbAdapter) DefaultPort() int { return 0 }
 
 func (a *duckdbAdapter) Connect(ctx context.Context, dsn string) (adapter.Connection, error) {
+	return a.ConnectSession(ctx, dsn, nil)
+}
+
+// ConnectSession connects with init run on every pooled connection.
+func (a *duckdbAdapter) ConnectSession(ctx context.Context, dsn string, init []string) (adapter.Connection, error) {
 	// Strip the "duckdb://" prefix if present.
 	dsn = strings.TrimPrefix(dsn, "duckdb://")
 	if dsn == "" {
 		dsn = ":memory:"
 	}
 
-	db, err := sql.Open("duckdb", dsn)
+	db, err := adapter.OpenDB("duckdb", dsn, init)
 	if err != nil {
 		return nil, fmt.Errorf("duckdb: open: %w", err)
 	}
@@ -63,6 +68,7 @@ type duckdbConn struct {
 
 	mu     sync.Mutex
 	cancel context.CancelFunc
+	tx     adapter.SQLTx
 }
 
 func (c *duckdbConn) DatabaseName() string { return c.dsn }
@@ -73,6 +79,7 @@ func (c *duckdbConn) Ping(ctx context.Context) error {
 }
 
 func (c *duckdbConn) Close() error {
+	_ = c.tx.Rollback()
 	return c.db.Close()
 }
 
@@ -269,11 +276,138 @@ func (c *duckdbConn) ForeignKeys(ctx context.Context, db, schemaName, table stri
 	return fks, nil
 }
 
+// TableDDL returns the CREATE statement DuckDB keeps for a table or view,
+// followed by the table's indexes.
+func (c *duckdbConn) TableDDL(ctx context.Context, db, schemaName, table string) (string, error) {
+	query := `SELECT sql FROM duckdb_tables() WHERE database_name = ? AND schema_name = ? AND table_name = ?
+		UNION ALL
+		SELECT sql FROM duckdb_views() WHERE database_name = ? AND schema_name = ? AND view_name = ?
+		UNION ALL
+		SELECT sql FROM duckdb_indexes() WHERE database_name = ? AND schema_name = ? AND table_name = ? AND sql IS NOT NULL`
+	rows, err := c.db.QueryContext(ctx, query, db, schemaName, table, db, schemaName, table, db, schemaName, table)
+	if err != nil {
+		return "", fmt.Errorf("duckdb: ddl: %w", err)
+	}
+	defer rows.Close()
+
+	var stmts []string
+	for rows.Next() {
+		var stmt string
+		if err := rows.Scan(&stmt); err != nil {
+			return "", fmt.Errorf("duckdb: ddl scan: %w", err)
+		}
+		stmt = strings.TrimSpace(stmt)
+		if !strings.HasSuffix(stmt, ";") {
+			stmt += ";"
+		}
+		stmts = append(stmts, stmt)
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("duckdb: ddl: %w", err)
+	}
+	if len(stmts) == 0 {
+		return "", fmt.Errorf("duckdb: ddl: %s.%s not found", schemaName, table)
+	}
+	return strings.Join(stmts, "\n\n"), nil
+}
+
+// TableStats reports DuckDB's estimated row count for each table. DuckDB
+// does not expose per-table storage sizes.
+func (c *duckdbConn) TableStats(ctx context.Context, db, schemaName string) (map[string]schema.TableStats, error) {
+	rows, err := c.db.QueryContext(ctx,
+		`SELECT table_name, estimated_size FROM duckdb_tables()
+		 WHERE database_name = ? AND schema_name = ?`, db, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("duckdb: table stats: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]schema.TableStats)
+	for rows.Next() {
+		var name string
+		var count sql.NullInt64
+		if err := rows.Scan(&name, &count); err != nil {
+			return nil, fmt.Errorf("duckdb: table stats scan: %w", err)
+		}
+		st := schema.TableStats{Rows: -1, Bytes: -1}
+		if count.Valid {
+			st.Rows = count.Int64
+		}
+		result[name] = st
+	}
+	return result, rows.Err()
+}
+
+// Routines lists the user-defined macros of a schema. DuckDB has no stored
+// functions or procedures; macros are the closest equivalent.
+func (c *duckdbConn) Routines(ctx context.Context, db, schemaName string) ([]schema.Routine, error) {
+	rows, err := c.db.QueryContext(ctx,
+		`SELECT function_name, array_to_string(parameters, ', ')
+		 FROM duckdb_functions()
+		 WHERE database_name = ? AND schema_name = ?
+		   AND NOT internal AND function_type IN ('macro', 'table_macro')
+		 ORDER BY function_name`, db, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("duckdb: routines: %w", err)
+	}
+	defer rows.Close()
+
+	var routines []schema.Routine
+	for rows.Next() {
+		var r schema.Routine
+		var args sql.NullString
+		if err := rows.Scan(&r.Name, &args); err != nil {
+			return nil, fmt.Errorf("duckdb: routines scan: %w", err)
+		}
+		r.Kind = "function"
+		r.Args = args.String
+		routines = append(routines, r)
+	}
+	return routines, rows.Err()
+}
+
+// Sequences lists the sequences of a schema.
+func (c *duckdbConn) Sequences(ctx context.Context, db, schemaName string) ([]schema.Sequence, error) {
+	rows, err := c.db.QueryContext(ctx,
+		`SELECT sequence_name FROM duckdb_sequences()
+		 WHERE database_name = ? AND schema_name = ?
+		 ORDER BY sequence_name`, db, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("duckdb: sequences: %w", err)
+	}
+	defer rows.Close()
+
+	var seqs []schema.Sequence
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("duckdb: sequences scan: %w", err)
+		}
+		seqs = append(seqs, schema.Sequence{Name: name})
+	}
+	return seqs, rows.Err()
+}
+
+// Triggers returns nothing: DuckDB does not support triggers.
+func (c *duckdbConn) Triggers(ctx context.Context, db, schemaName string) ([]schema.Trigger, error) {
+	return nil, nil
+}
+
 // ---------------------------------------------------------------------------
 // Query execution
 // ---------------------------------------------------------------------------
 
 func (c *duckdbConn) Execute(ctx context.Context, query string) (*adapter.QueryResult, error) {
+	return c.execute(ctx, query, nil)
+}
+
+// ExecuteParams runs query as a prepared statement, binding args to its ?
+// placeholders.
+func (c *duckdbConn) ExecuteParams(ctx context.Context, query string, args []any) (*adapter.QueryResult, error) {
+	return c.execute(ctx, query, args)
+}
+
+func (c *duckdbConn) execute(ctx context.Context, query string, args []any) (*adapter.QueryResult, error) {
 	ctx, cancel := context.WithCancel(ctx)
 	c.mu.Lock()
 	c.cancel = cancel
@@ -290,9 +424,9 @@ func (c *duckdbConn) Execute(ctx context.Context, query string) (*adapter.QueryR
 	isSelect := isSelectQuery(trimmed)
 
 	if isSelect {
-		return c.executeSelect(ctx, query, start)
+		return c.executeSelect(ctx, query, start, args...)
 	}
-	return c.executeExec(ctx, query, start)
+	return c.executeExec(ctx, query, start, args...)
 }
 
 func isSelectQuery(q string) bool {
@@ -305,8 +439,8 @@ func isSelectQuery(q string) bool {
 	return false
 }
 
-func (c *duckdbConn) executeSelect(ctx context.Context, query string, start time.Time) (*adapter.QueryResult, error) {
-	rows, err := c.db.QueryContext(ctx, query)
+func (c *duckdbConn) executeSelect(ctx context.Context, query string, start time.Time, args ...any) (*adapter.QueryResult, error) {
+	rows, err := c.tx.On(ctx, c.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("duckdb: query: %w", err)
 	}
@@ -343,7 +477,7 @@ func (c *duckdbConn) executeSelect(ctx context.Context, query string, start time
 			if v.Valid {
 				row[i] = v.String
 			} else {
-				row[i] = "NULL"
+				row[i] = adapter.NullValue
 			}
 		}
 		resultRows = append(resultRows, row)
@@ -361,8 +495,8 @@ func (c *duckdbConn) executeSelect(ctx context.Context, query string, start time
 	}, nil
 }
 
-func (c *duckdbConn) executeExec(ctx context.Context, query string, start time.Time) (*adapter.QueryResult, error) {
-	result, err := c.db.ExecContext(ctx, query)
+func (c *duckdbConn) executeExec(ctx context.Context, query string, start time.Time, args ...any) (*adapter.QueryResult, error) {
+	result, err := c.tx.On(ctx, c.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("duckdb: exec: %w", err)
 	}
@@ -376,6 +510,34 @@ func (c *duckdbConn) executeExec(ctx context.Context, query string, start time.T
 	}, nil
 }
 
+// Begin starts an explicit transaction that Execute runs in until Commit
+// or Rollback.
+func (c *duckdbConn) Begin(ctx context.Context) error {
+	if err := c.tx.Begin(ctx, c.db); err != nil {
+		return fmt.Errorf("duckdb: begin: %w", err)
+	}
+	return nil
+}
+
+// Commit commits the open transaction.
+func (c *duckdbConn) Commit(ctx context.Context) error {
+	if err := c.tx.Commit(); err != nil {
+		return fmt.Errorf("duckdb: commit: %w", err)
+	}
+	return nil
+}
+
+// Rollback rolls back the open transaction.
+func (c *duckdbConn) Rollback(ctx context.Context) error {
+	if err := c.tx.Rollback(); err != nil {
+		return fmt.Errorf("duckdb: rollback: %w", err)
+	}
+	return nil
+}
+
+// InTransaction returns whether a transaction is open.
+func (c *duckdbConn) InTransaction() bool { return c.tx.Active() }
+
 // ---------------------------------------------------------------------------
 // Streaming (LIMIT/OFFSET pagination)
 // ---------------------------------------------------------------------------
@@ -487,6 +649,16 @@ func (it *duckdbIterator) FetchPrev(ctx context.Context) ([][]string, error) {
 	return page, nil
 }
 
+// Seek moves the LIMIT/OFFSET window so the next FetchNext starts at offset.
+func (it *duckdbIterator) Seek(_ context.Context, offset int64) error {
+	if offset < 0 {
+		offset = 0
+	}
+	it.offset = int(offset)
+	it.done = false
+	return nil
+}
+
 func scanPage(rows *sql.Rows, nCols int) ([][]string, error) {
 	var page [][]string
 	for rows.Next() {
@@ -503,7 +675,7 @@ func scanPage(rows *sql.Rows, nCols int) ([][]string, error) {
 			if v.Valid {
 				row[i] = v.String
 			} else {
-				row[i] = "NULL"
+				row[i] = adapter.NullValue
 			}
 		}
 		page = append(page, row)
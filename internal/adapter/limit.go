@@ -0,0 +1,55 @@
+package adapter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// limitDialects are the dialects that take LIMIT n at the end of a SELECT.
+var limitDialects = map[string]bool{
+	"postgres": true, "mysql": true, "sqlite": true, "duckdb": true,
+}
+
+// rowLimits are the words that mean a query already limits its rows, or
+// that LIMIT cannot follow (FOR SHARE and other locking clauses).
+var rowLimits = map[string]bool{
+	"LIMIT": true, "FETCH": true, "OFFSET": true, "TOP": true, "FOR": true,
+}
+
+// LimitQuery appends LIMIT n to a single read-only SELECT (or WITH ...
+// SELECT) that has no LIMIT, FETCH or OFFSET of its own, so that running
+// it cannot pull a whole table by accident. It returns the query unchanged
+// and false for anything else, and for dialects without LIMIT.
+func LimitQuery(dialect, query string, n int) (string, bool) {
+	if n <= 0 || !limitDialects[dialect] || !IsReadOnlyQuery(query) {
+		return query, false
+	}
+	for _, l := range lexers {
+		words := l.words(query)
+		for len(words) > 0 && words[len(words)-1] == ";" {
+			words = words[:len(words)-1]
+		}
+		if len(words) == 0 || (words[0] != "SELECT" && words[0] != "WITH") {
+			return query, false
+		}
+		for _, w := range words {
+			if w == ";" || rowLimits[w] {
+				return query, false
+			}
+		}
+	}
+	// After the last word, leaving out the ";" and the comments after it,
+	// which a query wrapping this one as a subquery would trip on. The
+	// LIMIT goes on a line of its own, so a -- comment before cannot
+	// swallow it.
+	toks := lexerFor(dialect).tokens(query)
+	last := len(toks) - 1
+	for last >= 0 && toks[last].text == ";" {
+		last--
+	}
+	if last < 0 {
+		return query, false
+	}
+	end := toks[last].pos + len(toks[last].raw)
+	return fmt.Sprintf("%s\nLIMIT %d", strings.TrimSpace(query[:end]), n), true
+}
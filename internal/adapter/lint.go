@@ -0,0 +1,304 @@
+package adapter
+
+import "strings"
+
+// Lint rules, by the names used to turn them on and off.
+const (
+	LintMissingWhere = "missing_where" // UPDATE or DELETE without WHERE
+	LintCrossJoin    = "cross_join"    // FROM a, b without WHERE
+	LintSelectStar   = "select_star"   // SELECT * in the outermost query
+	LintNonSargable  = "non_sargable"  // predicates an index cannot serve
+)
+
+// LintRules lists every lint rule.
+var LintRules = []string{LintMissingWhere, LintCrossJoin, LintSelectStar, LintNonSargable}
+
+// LintWarning is a problem Lint found in a query.
+type LintWarning struct {
+	Rule    string
+	Message string
+}
+
+// clauseEnds are the words that end a FROM list or a WHERE clause.
+var clauseEnds = map[string]bool{
+	"WHERE": true, "GROUP": true, "ORDER": true, "HAVING": true,
+	"LIMIT": true, "OFFSET": true, "FETCH": true, "FOR": true,
+	"UNION": true, "INTERSECT": true, "EXCEPT": true, "WINDOW": true,
+	"QUALIFY": true, "RETURNING": true, "JOIN": true, "SELECT": true,
+}
+
+// notFunctions are words that can come before "(" without calling a
+// function on what is inside.
+var notFunctions = map[string]bool{
+	"AND": true, "OR": true, "NOT": true, "IN": true, "EXISTS": true,
+	"ANY": true, "ALL": true, "SOME": true, "WHERE": true, "ON": true,
+	"SELECT": true, "VALUES": true, "BETWEEN": true, "LIKE": true,
+	"ILIKE": true, "IS": true, "WHEN": true, "THEN": true, "ELSE": true,
+	"CASE": true, "USING": true, "ROW": true,
+}
+
+// notColumns are words inside a function call that are not column names.
+var notColumns = map[string]bool{
+	"NULL": true, "TRUE": true, "FALSE": true, "AS": true, "FROM": true,
+	"INTERVAL": true, "AND": true, "OR": true, "NOT": true, "CASE": true,
+	"WHEN": true, "THEN": true, "ELSE": true, "END": true,
+	"CURRENT_DATE": true, "CURRENT_TIME": true, "CURRENT_TIMESTAMP": true,
+}
+
+// Lint looks for risky patterns in query, read the way dialect reads it:
+// writes without WHERE, implicit cross joins, SELECT * and predicates
+// that keep an index from being used. Each problem is reported once. It
+// is a quick heuristic meant for warnings, not a parser.
+func Lint(dialect, query string) []LintWarning {
+	var warnings []LintWarning
+	seen := map[string]bool{}
+	add := func(rule, message string) {
+		if !seen[message] {
+			seen[message] = true
+			warnings = append(warnings, LintWarning{Rule: rule, Message: message})
+		}
+	}
+	for _, stmt := range statements(lexerFor(dialect).tokens(query)) {
+		if verb := statementVerb(stmt); (verb == "UPDATE" || verb == "DELETE") && !hasWord(stmt, 0, "WHERE") {
+			if verb == "UPDATE" {
+				add(LintMissingWhere, "UPDATE without WHERE changes every row")
+			} else {
+				add(LintMissingWhere, "DELETE without WHERE deletes every row")
+			}
+		}
+		if crossJoin(stmt) {
+			add(LintCrossJoin, "FROM a, b without WHERE is a cross join")
+		}
+		if selectStar(stmt) {
+			add(LintSelectStar, "SELECT * fetches every column")
+		}
+		for _, message := range nonSargable(stmt) {
+			add(LintNonSargable, message)
+		}
+	}
+	return warnings
+}
+
+// lexerFor returns the lexer that reads queries the way dialect does.
+func lexerFor(dialect string) lexer {
+	switch dialect {
+	case "mysql":
+		return lexers[1]
+	case "postgres":
+		return lexers[2]
+	case "duckdb":
+		return lexers[3]
+	}
+	return lexers[0]
+}
+
+// statements splits tokens at ";", dropping empty statements.
+func statements(tokens []token) [][]token {
+	var stmts [][]token
+	start := 0
+	for i := 0; i <= len(tokens); i++ {
+		if i == len(tokens) || tokens[i].text == ";" {
+			if i > start {
+				stmts = append(stmts, tokens[start:i])
+			}
+			start = i + 1
+		}
+	}
+	return stmts
+}
+
+// depths returns the parenthesis depth of each token; a "(" and its ")"
+// are at the depth outside them.
+func depths(stmt []token) []int {
+	d := make([]int, len(stmt))
+	depth := 0
+	for i, t := range stmt {
+		if t.text == ")" && depth > 0 {
+			depth--
+		}
+		d[i] = depth
+		if t.text == "(" {
+			depth++
+		}
+	}
+	return d
+}
+
+// statementVerb returns the word that says what stmt does, past any WITH
+// clause.
+func statementVerb(stmt []token) string {
+	d := depths(stmt)
+	for i, t := range stmt {
+		if t.kind != tokWord || d[i] > 0 {
+			continue
+		}
+		switch t.text {
+		case "SELECT", "INSERT", "UPDATE", "DELETE", "MERGE", "VALUES", "TABLE":
+			return t.text
+		}
+		if i == 0 && t.text != "WITH" {
+			return t.text
+		}
+	}
+	return ""
+}
+
+// hasWord reports whether stmt has word at depth.
+func hasWord(stmt []token, depth int, word string) bool {
+	d := depths(stmt)
+	for i, t := range stmt {
+		if d[i] == depth && t.kind == tokWord && t.text == word {
+			return true
+		}
+	}
+	return false
+}
+
+// crossJoin reports whether a FROM list in stmt joins with commas and no
+// WHERE follows it, past any explicit JOINs. A function after the comma,
+// as in FROM t, unnest(t.tags), is a lateral join and does not count.
+func crossJoin(stmt []token) bool {
+	d := depths(stmt)
+	for i, t := range stmt {
+		if t.kind != tokWord || t.text != "FROM" || !inQuery(stmt, d, i) {
+			continue
+		}
+		comma, where := false, false
+	list:
+		for j := i + 1; j < len(stmt); j++ {
+			switch {
+			case d[j] < d[i]:
+				break list
+			case d[j] > d[i]:
+				continue
+			case stmt[j].text == ",":
+				next := j + 1
+				if next < len(stmt) && stmt[next].text == "LATERAL" {
+					continue
+				}
+				if next+1 < len(stmt) && stmt[next].kind == tokWord && stmt[next+1].text == "(" {
+					continue
+				}
+				comma = true
+			case stmt[j].kind == tokWord && clauseEnds[stmt[j].text] && stmt[j].text != "JOIN":
+				where = stmt[j].text == "WHERE"
+				break list
+			}
+		}
+		if comma && !where {
+			return true
+		}
+	}
+	return false
+}
+
+// inQuery reports whether the token at i is in a query rather than in a
+// function call: at the top level or in parentheses opened by a SELECT.
+func inQuery(stmt []token, d []int, i int) bool {
+	for j := i - 1; j >= 0; j-- {
+		if d[j] < d[i] {
+			return j+1 < len(stmt) && (stmt[j+1].text == "SELECT" || stmt[j+1].text == "WITH")
+		}
+	}
+	return true
+}
+
+// selectStar reports whether the outermost SELECT in stmt selects * or
+// t.*.
+func selectStar(stmt []token) bool {
+	d := depths(stmt)
+	inList := false
+	for i, t := range stmt {
+		if d[i] > 0 {
+			continue
+		}
+		switch {
+		case t.kind == tokWord && t.text == "SELECT":
+			inList = true
+		case t.kind == tokWord && (t.text == "FROM" || clauseEnds[t.text]):
+			inList = false
+		case inList && t.text == "*" && i > 0:
+			switch stmt[i-1].text {
+			case "SELECT", "DISTINCT", "ALL", ",", ".":
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nonSargable returns a message for each predicate in a WHERE or ON clause
+// of stmt that an index on its column cannot serve: a function applied to
+// the column (LOWER(email) = ...) or a LIKE pattern that starts with a
+// wildcard.
+func nonSargable(stmt []token) []string {
+	var messages []string
+	// clause[depth] is the clause being read at each depth; parentheses
+	// inherit it, so WHERE (a OR b) is still in WHERE.
+	clause := []string{""}
+	for i, t := range stmt {
+		top := len(clause) - 1
+		switch {
+		case t.text == "(":
+			clause = append(clause, clause[top])
+		case t.text == ")":
+			if top > 0 {
+				clause = clause[:top]
+			}
+		case t.kind != tokWord:
+		case t.text == "WHERE" || t.text == "ON":
+			clause[top] = "WHERE"
+		case clauseEnds[t.text]:
+			clause[top] = ""
+		case clause[top] != "WHERE":
+		case (t.text == "LIKE" || t.text == "ILIKE") && i+1 < len(stmt) && leadingWildcard(stmt[i+1]):
+			messages = append(messages, t.text+" with a leading wildcard cannot use an index")
+		case !notFunctions[t.text] && i+1 < len(stmt) && stmt[i+1].text == "(" && (i == 0 || stmt[i-1].text != "."):
+			if wrapsColumn(stmt, i+1) {
+				messages = append(messages, t.text+"() around a column keeps its index from being used")
+			}
+		}
+	}
+	return messages
+}
+
+// leadingWildcard reports whether t is a string starting with % or _.
+func leadingWildcard(t token) bool {
+	return t.kind == tokQuoted && (strings.HasPrefix(t.text, "'%") || strings.HasPrefix(t.text, "'_"))
+}
+
+// wrapsColumn reports whether the parentheses opened at stmt[open] hold a
+// column name and are followed by a comparison, as in LOWER(email) = 'a'.
+func wrapsColumn(stmt []token, open int) bool {
+	depth, column := 0, false
+	for j := open; j < len(stmt); j++ {
+		t := stmt[j]
+		switch {
+		case t.text == "(":
+			depth++
+		case t.text == ")":
+			depth--
+			if depth == 0 {
+				return column && j+1 < len(stmt) && comparison(stmt[j+1])
+			}
+		case t.kind == tokWord && t.text == "SELECT":
+			return false // a subquery, not a column
+		case t.kind == tokWord && !notColumns[t.text] && !isDigit(t.text[0]):
+			column = true
+		}
+	}
+	return false
+}
+
+// comparison reports whether t compares the expression before it.
+func comparison(t token) bool {
+	switch t.text {
+	case "=", "<", ">", "!", "LIKE", "ILIKE", "IN", "BETWEEN":
+		return true
+	}
+	return false
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
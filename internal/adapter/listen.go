@@ -0,0 +1,38 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrListenerClosed is returned by Wait once the listener is closed.
+var ErrListenerClosed = errors.New("listener closed")
+
+// Notification is a message sent with NOTIFY to a channel listened on.
+type Notification struct {
+	Channel string
+	Payload string
+	PID     uint32 // server process of the session that sent it
+	At      time.Time
+}
+
+// Notifier is an optional interface that connections can implement to
+// listen for notifications (PostgreSQL LISTEN/NOTIFY) and send them.
+// Listen opens a session of its own, since one listening must stay open
+// while the pool hands out the others; Notify sends at once, outside any
+// open transaction.
+type Notifier interface {
+	Listen(ctx context.Context) (Listener, error)
+	Notify(ctx context.Context, channel, payload string) error
+}
+
+// Listener is a session listening on channels. Wait blocks until a
+// notification arrives on one of them; Listen and Unlisten may be called
+// from another goroutine while it does.
+type Listener interface {
+	Listen(ctx context.Context, channel string) error
+	Unlisten(ctx context.Context, channel string) error
+	Wait(ctx context.Context) (Notification, error)
+	Close() error
+}
@@ -0,0 +1,29 @@
+package adapter
+
+import "context"
+
+// Maintenance commands a Maintainer runs.
+const (
+	MaintainVacuum  = "VACUUM"
+	MaintainAnalyze = "ANALYZE"
+	MaintainReindex = "REINDEX"
+)
+
+// Setting is a storage setting of a database and its value, such as
+// journal_mode wal.
+type Setting struct {
+	Name  string
+	Value string
+}
+
+// Maintainer is an optional interface that connections can implement to
+// show the storage settings of a database (SQLite's PRAGMAs) and check and
+// maintain it. schemaName picks the database: "main", or an attached one.
+type Maintainer interface {
+	MaintenanceSettings(ctx context.Context, schemaName string) ([]Setting, error)
+	// IntegrityCheck returns the problems found, none when the database
+	// is sound.
+	IntegrityCheck(ctx context.Context, schemaName string) ([]string, error)
+	// Maintain runs MaintainVacuum, MaintainAnalyze or MaintainReindex.
+	Maintain(ctx context.Context, schemaName, command string) error
+}
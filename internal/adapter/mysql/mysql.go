@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 
 	"github.com/sadopc/gotermsql/internal/adapter"
 	"github.com/sadopc/gotermsql/internal/schema"
@@ -30,12 +32,17 @@ func (a *mysqlAdapter) Name() string     { return "mysql" }
 func (a *mysqlAdapter) DefaultPort() int { return 3306 }
 
 func (a *mysqlAdapter) Connect(ctx context.Context, dsn string) (adapter.Connection, error) {
+	return a.ConnectSession(ctx, dsn, nil)
+}
+
+// ConnectSession connects with init run on every pooled connection.
+func (a *mysqlAdapter) ConnectSession(ctx context.Context, dsn string, init []string) (adapter.Connection, error) {
 	goDriverDSN, dbName, err := normalizeDSN(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("mysql: invalid dsn: %w", err)
 	}
 
-	db, err := sql.Open("mysql", goDriverDSN)
+	db, err := adapter.OpenDB("mysql", goDriverDSN, init)
 	if err != nil {
 		return nil, fmt.Errorf("mysql: open: %w", err)
 	}
@@ -52,6 +59,19 @@ func (a *mysqlAdapter) Connect(ctx context.Context, dsn string) (adapter.Connect
 	}, nil
 }
 
+// ValidateDSN checks that dsn is a mysql:// URL or a DSN in go-sql-driver
+// format.
+func (a *mysqlAdapter) ValidateDSN(dsn string) error {
+	goDriverDSN, _, err := normalizeDSN(dsn)
+	if err == nil {
+		_, err = mysql.ParseDSN(goDriverDSN)
+	}
+	if err != nil {
+		return fmt.Errorf("mysql: invalid dsn: %w", err)
+	}
+	return nil
+}
+
 // normalizeDSN converts a mysql:// URL-style DSN to go-sql-driver format, or
 // passes through a DSN that is already in go-sql-driver format.
 //
@@ -130,6 +150,7 @@ type mysqlConn struct {
 	mu           sync.Mutex
 	cancel       context.CancelFunc
 	activeConnID int64
+	tx           adapter.SQLTx
 }
 
 func (c *mysqlConn) AdapterName() string  { return "mysql" }
@@ -140,6 +161,7 @@ func (c *mysqlConn) Ping(ctx context.Context) error {
 }
 
 func (c *mysqlConn) Close() error {
+	_ = c.tx.Rollback()
 	return c.db.Close()
 }
 
@@ -499,20 +521,198 @@ func (c *mysqlConn) AllForeignKeys(ctx context.Context, db, schemaName string) (
 // Execute
 // ---------------------------------------------------------------------------
 
+// TableStats reports TABLE_ROWS and DATA_LENGTH + INDEX_LENGTH from
+// information_schema.tables. InnoDB row counts are estimates.
+func (c *mysqlConn) TableStats(ctx context.Context, db, schemaName string) (map[string]schema.TableStats, error) {
+	if db == "" {
+		db = schemaName
+	}
+	if db == "" {
+		db = c.dbName
+	}
+
+	const q = `
+		SELECT TABLE_NAME, TABLE_ROWS, DATA_LENGTH + INDEX_LENGTH
+		FROM information_schema.tables
+		WHERE TABLE_SCHEMA = ?
+		  AND TABLE_TYPE = 'BASE TABLE'`
+
+	rows, err := c.db.QueryContext(ctx, q, db)
+	if err != nil {
+		return nil, fmt.Errorf("table stats: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]schema.TableStats)
+	for rows.Next() {
+		var name string
+		var count, size sql.NullInt64
+		if err := rows.Scan(&name, &count, &size); err != nil {
+			return nil, fmt.Errorf("table stats scan: %w", err)
+		}
+		st := schema.TableStats{Rows: -1, Bytes: -1}
+		if count.Valid {
+			st.Rows = count.Int64
+		}
+		if size.Valid {
+			st.Bytes = size.Int64
+		}
+		result[name] = st
+	}
+	return result, rows.Err()
+}
+
+// Routines lists the stored functions and procedures of a database with
+// their parameters from information_schema.parameters.
+func (c *mysqlConn) Routines(ctx context.Context, db, schemaName string) ([]schema.Routine, error) {
+	if db == "" {
+		db = schemaName
+	}
+	if db == "" {
+		db = c.dbName
+	}
+
+	const q = `
+		SELECT r.ROUTINE_NAME, r.ROUTINE_TYPE, COALESCE(r.DTD_IDENTIFIER, ''),
+		       COALESCE(GROUP_CONCAT(
+		           CONCAT_WS(' ', IF(r.ROUTINE_TYPE = 'PROCEDURE', p.PARAMETER_MODE, NULL), p.PARAMETER_NAME, p.DTD_IDENTIFIER)
+		           ORDER BY p.ORDINAL_POSITION SEPARATOR ', '), '')
+		FROM information_schema.routines r
+		LEFT JOIN information_schema.parameters p
+		       ON p.SPECIFIC_SCHEMA = r.ROUTINE_SCHEMA
+		      AND p.SPECIFIC_NAME = r.SPECIFIC_NAME
+		      AND p.ORDINAL_POSITION > 0
+		WHERE r.ROUTINE_SCHEMA = ?
+		GROUP BY r.ROUTINE_NAME, r.ROUTINE_TYPE, r.DTD_IDENTIFIER
+		ORDER BY r.ROUTINE_NAME`
+
+	rows, err := c.db.QueryContext(ctx, q, db)
+	if err != nil {
+		return nil, fmt.Errorf("routines: %w", err)
+	}
+	defer rows.Close()
+
+	var routines []schema.Routine
+	for rows.Next() {
+		var r schema.Routine
+		var kind string
+		if err := rows.Scan(&r.Name, &kind, &r.Returns, &r.Args); err != nil {
+			return nil, fmt.Errorf("routines scan: %w", err)
+		}
+		r.Kind = strings.ToLower(kind)
+		routines = append(routines, r)
+	}
+	return routines, rows.Err()
+}
+
+// Sequences returns nothing: MySQL has no sequence objects.
+func (c *mysqlConn) Sequences(ctx context.Context, db, schemaName string) ([]schema.Sequence, error) {
+	return nil, nil
+}
+
+// Triggers lists the triggers of a database.
+func (c *mysqlConn) Triggers(ctx context.Context, db, schemaName string) ([]schema.Trigger, error) {
+	if db == "" {
+		db = schemaName
+	}
+	if db == "" {
+		db = c.dbName
+	}
+
+	const q = `
+		SELECT TRIGGER_NAME, EVENT_OBJECT_TABLE, ACTION_TIMING, EVENT_MANIPULATION
+		FROM information_schema.triggers
+		WHERE TRIGGER_SCHEMA = ?
+		ORDER BY EVENT_OBJECT_TABLE, TRIGGER_NAME`
+
+	rows, err := c.db.QueryContext(ctx, q, db)
+	if err != nil {
+		return nil, fmt.Errorf("triggers: %w", err)
+	}
+	defer rows.Close()
+
+	var triggers []schema.Trigger
+	for rows.Next() {
+		var t schema.Trigger
+		if err := rows.Scan(&t.Name, &t.Table, &t.Timing, &t.Event); err != nil {
+			return nil, fmt.Errorf("triggers scan: %w", err)
+		}
+		triggers = append(triggers, t)
+	}
+	return triggers, rows.Err()
+}
+
+// TableDDL returns the CREATE statement reported by SHOW CREATE TABLE, which
+// also works for views (then its second column is the CREATE VIEW).
+func (c *mysqlConn) TableDDL(ctx context.Context, db, schemaName, table string) (string, error) {
+	if db == "" {
+		db = schemaName
+	}
+	if db == "" {
+		db = c.dbName
+	}
+	q := "SHOW CREATE TABLE " + adapter.QuoteIdentifier("mysql", db) + "." + adapter.QuoteIdentifier("mysql", table)
+	rows, err := c.db.QueryContext(ctx, q)
+	if err != nil {
+		return "", fmt.Errorf("ddl: %w", err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return "", fmt.Errorf("ddl: %w", err)
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return "", fmt.Errorf("ddl: %w", err)
+		}
+		return "", fmt.Errorf("ddl: %s.%s not found", db, table)
+	}
+	vals := make([]sql.NullString, len(cols))
+	ptrs := make([]any, len(cols))
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	if err := rows.Scan(ptrs...); err != nil {
+		return "", fmt.Errorf("ddl scan: %w", err)
+	}
+	if len(vals) < 2 {
+		return "", fmt.Errorf("ddl: unexpected SHOW CREATE TABLE result")
+	}
+	return vals[1].String + ";", nil
+}
+
 func (c *mysqlConn) Execute(ctx context.Context, query string) (*adapter.QueryResult, error) {
+	return c.execute(ctx, query, nil)
+}
+
+// ExecuteParams runs query as a server-side prepared statement, binding
+// args to its ? placeholders.
+func (c *mysqlConn) ExecuteParams(ctx context.Context, query string, args []any) (*adapter.QueryResult, error) {
+	return c.execute(ctx, query, args)
+}
+
+func (c *mysqlConn) execute(ctx context.Context, query string, args []any) (*adapter.QueryResult, error) {
 	ctx, cancel := context.WithCancel(ctx)
 
 	// Pin to a dedicated connection from the pool so that CONNECTION_ID()
-	// accurately identifies the session running our query.
-	sqlConn, err := c.db.Conn(ctx)
-	if err != nil {
-		cancel()
-		return nil, fmt.Errorf("mysql: acquire conn: %w", err)
+	// accurately identifies the session running our query. An open
+	// transaction is already pinned to one.
+	var conn adapter.Querier
+	if tx := c.tx.Tx(); tx != nil && !adapter.WantsPool(ctx) {
+		conn = tx
+	} else {
+		sqlConn, err := c.db.Conn(ctx)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("mysql: acquire conn: %w", err)
+		}
+		defer sqlConn.Close()
+		conn = sqlConn
 	}
 
 	var connID int64
-	if err := sqlConn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&connID); err != nil {
-		sqlConn.Close()
+	if err := conn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&connID); err != nil {
 		cancel()
 		return nil, fmt.Errorf("mysql: connection_id: %w", err)
 	}
@@ -527,20 +727,19 @@ func (c *mysqlConn) Execute(ctx context.Context, query string) (*adapter.QueryRe
 		c.cancel = nil
 		c.activeConnID = 0
 		c.mu.Unlock()
-		sqlConn.Close()
 		cancel()
 	}()
 
 	start := time.Now()
 
 	if isSelectQuery(query) {
-		return c.executeSelectOnConn(ctx, sqlConn, query, start)
+		return c.executeSelectOnConn(ctx, conn, query, start, args...)
 	}
-	return c.executeExecOnConn(ctx, sqlConn, query, start)
+	return c.executeExecOnConn(ctx, conn, query, start, args...)
 }
 
-func (c *mysqlConn) executeSelectOnConn(ctx context.Context, conn *sql.Conn, query string, start time.Time) (*adapter.QueryResult, error) {
-	rows, err := conn.QueryContext(ctx, query)
+func (c *mysqlConn) executeSelectOnConn(ctx context.Context, conn adapter.Querier, query string, start time.Time, args ...any) (*adapter.QueryResult, error) {
+	rows, err := conn.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -577,7 +776,7 @@ func (c *mysqlConn) executeSelectOnConn(ctx context.Context, conn *sql.Conn, que
 			if v.Valid {
 				row[i] = v.String
 			} else {
-				row[i] = "NULL"
+				row[i] = adapter.NullValue
 			}
 		}
 		resultRows = append(resultRows, row)
@@ -595,8 +794,8 @@ func (c *mysqlConn) executeSelectOnConn(ctx context.Context, conn *sql.Conn, que
 	}, nil
 }
 
-func (c *mysqlConn) executeExecOnConn(ctx context.Context, conn *sql.Conn, query string, start time.Time) (*adapter.QueryResult, error) {
-	result, err := conn.ExecContext(ctx, query)
+func (c *mysqlConn) executeExecOnConn(ctx context.Context, conn adapter.Querier, query string, start time.Time, args ...any) (*adapter.QueryResult, error) {
+	result, err := conn.ExecContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -627,151 +826,533 @@ func (c *mysqlConn) Cancel() error {
 		return nil // no active query
 	}
 
-	// Open a short-lived connection to issue KILL QUERY.
+	ctx, killCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer killCancel()
+	return c.kill(ctx, "QUERY", connID)
+}
+
+// kill runs KILL QUERY or KILL CONNECTION on thread id from a short-lived
+// connection of its own, so it never waits for one from the pool.
+func (c *mysqlConn) kill(ctx context.Context, what string, id int64) error {
 	killDB, err := sql.Open("mysql", c.dsn)
 	if err != nil {
-		return fmt.Errorf("mysql: cancel open: %w", err)
+		return fmt.Errorf("mysql: kill open: %w", err)
 	}
 	defer killDB.Close()
 
-	ctx, killCancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer killCancel()
-
-	_, err = killDB.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", connID))
-	if err != nil {
-		return fmt.Errorf("mysql: kill query: %w", err)
+	query := fmt.Sprintf("KILL %s %d", what, id)
+	start := time.Now()
+	if _, err := killDB.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("mysql: kill %s: %w", strings.ToLower(what), err)
 	}
+	adapter.Record(ctx, query, start)
 	return nil
 }
 
 // ---------------------------------------------------------------------------
-// Streaming (LIMIT/OFFSET pagination)
+// Dependencies (implements adapter.DependencyProvider)
 // ---------------------------------------------------------------------------
 
-func (c *mysqlConn) ExecuteStreaming(ctx context.Context, query string, pageSize int) (adapter.RowIterator, error) {
-	// Probe columns by running the query with LIMIT 0.
-	probeQuery := fmt.Sprintf("SELECT * FROM (%s) AS _t LIMIT 0", strings.TrimRight(query, "; \t\n"))
+// Dependencies lists the views, foreign keys and triggers that depend on a
+// table or view and what it depends on. What views and triggers read is
+// found by searching their SQL for the names of the tables and views of
+// the schema, which works on MariaDB and MySQL before 8.0.13 too, where
+// information_schema has no VIEW_TABLE_USAGE; views of other schemas are
+// left out.
+func (c *mysqlConn) Dependencies(ctx context.Context, db, schemaName, name string) (adapter.Dependencies, error) {
+	var deps adapter.Dependencies
+	if db == "" {
+		db = schemaName
+	}
+	if db == "" {
+		db = c.dbName
+	}
 
-	rows, err := c.db.QueryContext(ctx, probeQuery)
+	rows, err := c.db.QueryContext(ctx, `
+		SELECT t.TABLE_NAME, t.TABLE_TYPE, COALESCE(v.VIEW_DEFINITION, '')
+		FROM information_schema.tables t
+		LEFT JOIN information_schema.views v
+			ON v.TABLE_SCHEMA = t.TABLE_SCHEMA AND v.TABLE_NAME = t.TABLE_NAME
+		WHERE t.TABLE_SCHEMA = ?
+		ORDER BY t.TABLE_NAME`, db)
 	if err != nil {
-		return nil, err
+		return deps, fmt.Errorf("dependencies: %w", err)
 	}
-	colTypes, err := rows.ColumnTypes()
+	defer rows.Close()
+	kinds := map[string]string{}
+	views := map[string]string{} // view name → its SELECT
+	var relations []string
+	for rows.Next() {
+		var table, typ, def string
+		if err := rows.Scan(&table, &typ, &def); err != nil {
+			return deps, fmt.Errorf("dependencies scan: %w", err)
+		}
+		kinds[strings.ToLower(table)] = adapter.DependencyTable
+		if typ == "VIEW" {
+			kinds[strings.ToLower(table)] = adapter.DependencyView
+			views[table] = def
+		}
+		relations = append(relations, table)
+	}
+	if err := rows.Err(); err != nil {
+		return deps, fmt.Errorf("dependencies: %w", err)
+	}
+	if _, ok := kinds[strings.ToLower(name)]; !ok {
+		return deps, fmt.Errorf("dependencies: %s.%s not found", db, name)
+	}
+
+	// others returns the tables and views stmt reads but name.
+	others := func(stmt string) []string {
+		var found []string
+		for _, r := range adapter.ReferencedNames("mysql", stmt, relations) {
+			if !strings.EqualFold(r, name) {
+				found = append(found, r)
+			}
+		}
+		return found
+	}
+	for _, table := range relations {
+		def, ok := views[table]
+		if !ok {
+			continue
+		}
+		switch {
+		case strings.EqualFold(table, name):
+			for _, r := range others(def) {
+				deps.DependsOn = append(deps.DependsOn, adapter.Dependency{Kind: kinds[strings.ToLower(r)], Schema: db, Name: r})
+			}
+		case len(adapter.ReferencedNames("mysql", def, []string{name})) > 0:
+			deps.Dependents = append(deps.Dependents, adapter.Dependency{Kind: adapter.DependencyView, Schema: db, Name: table})
+		}
+	}
+
+	fkRows, err := c.db.QueryContext(ctx, `
+		SELECT TABLE_SCHEMA, TABLE_NAME, REFERENCED_TABLE_SCHEMA, REFERENCED_TABLE_NAME,
+		       GROUP_CONCAT(COLUMN_NAME ORDER BY ORDINAL_POSITION SEPARATOR ', '),
+		       GROUP_CONCAT(REFERENCED_COLUMN_NAME ORDER BY ORDINAL_POSITION SEPARATOR ', ')
+		FROM information_schema.key_column_usage
+		WHERE REFERENCED_TABLE_NAME IS NOT NULL
+		  AND ((TABLE_SCHEMA = ? AND TABLE_NAME = ?) OR (REFERENCED_TABLE_SCHEMA = ? AND REFERENCED_TABLE_NAME = ?))
+		GROUP BY TABLE_SCHEMA, TABLE_NAME, CONSTRAINT_NAME, REFERENCED_TABLE_SCHEMA, REFERENCED_TABLE_NAME
+		ORDER BY TABLE_SCHEMA, TABLE_NAME, CONSTRAINT_NAME`, db, name, db, name)
 	if err != nil {
-		rows.Close()
-		return nil, err
+		return deps, fmt.Errorf("dependencies: %w", err)
+	}
+	defer fkRows.Close()
+	for fkRows.Next() {
+		var tableSchema, table, refSchema, refTable, cols, refCols string
+		if err := fkRows.Scan(&tableSchema, &table, &refSchema, &refTable, &cols, &refCols); err != nil {
+			return deps, fmt.Errorf("dependencies scan: %w", err)
+		}
+		detail := fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s(%s)", cols, refTable, refCols)
+		if tableSchema == db && strings.EqualFold(table, name) {
+			deps.DependsOn = append(deps.DependsOn, adapter.Dependency{Kind: adapter.DependencyTable, Schema: refSchema, Name: refTable, Detail: detail})
+		}
+		if refSchema == db && strings.EqualFold(refTable, name) {
+			deps.Dependents = append(deps.Dependents, adapter.Dependency{Kind: adapter.DependencyTable, Schema: tableSchema, Name: table, Detail: detail})
+		}
+	}
+	if err := fkRows.Err(); err != nil {
+		return deps, fmt.Errorf("dependencies: %w", err)
 	}
-	rows.Close()
 
-	columns := make([]adapter.ColumnMeta, len(colTypes))
-	for i, ct := range colTypes {
-		columns[i].Name = ct.Name()
-		columns[i].Type = ct.DatabaseTypeName()
-		if n, ok := ct.Nullable(); ok {
-			columns[i].Nullable = n
+	trgRows, err := c.db.QueryContext(ctx, `
+		SELECT TRIGGER_NAME, EVENT_OBJECT_TABLE, ACTION_TIMING, EVENT_MANIPULATION, ACTION_STATEMENT
+		FROM information_schema.triggers
+		WHERE TRIGGER_SCHEMA = ?
+		ORDER BY TRIGGER_NAME`, db)
+	if err != nil {
+		return deps, fmt.Errorf("dependencies: %w", err)
+	}
+	defer trgRows.Close()
+	for trgRows.Next() {
+		var trigger, table, timing, event, body string
+		if err := trgRows.Scan(&trigger, &table, &timing, &event, &body); err != nil {
+			return deps, fmt.Errorf("dependencies scan: %w", err)
+		}
+		switch {
+		case strings.EqualFold(table, name):
+			deps.Dependents = append(deps.Dependents, adapter.Dependency{Kind: adapter.DependencyTrigger, Schema: db, Name: trigger, Detail: timing + " " + event})
+			for _, r := range others(body) {
+				deps.DependsOn = append(deps.DependsOn, adapter.Dependency{Kind: kinds[strings.ToLower(r)], Schema: db, Name: r, Detail: "used by trigger " + trigger})
+			}
+		case len(adapter.ReferencedNames("mysql", body, []string{name})) > 0:
+			deps.Dependents = append(deps.Dependents, adapter.Dependency{Kind: adapter.DependencyTrigger, Schema: db, Name: trigger, Detail: "on " + table})
 		}
 	}
+	return deps, trgRows.Err()
+}
 
-	return &rowIterator{
-		conn:      c,
-		baseQuery: strings.TrimRight(query, "; \t\n"),
-		pageSize:  pageSize,
-		columns:   columns,
-		offset:    0,
-	}, nil
+// ---------------------------------------------------------------------------
+// Process list (implements adapter.ActivityMonitor)
+// ---------------------------------------------------------------------------
+
+// Activity lists the threads of SHOW FULL PROCESSLIST but the one asking.
+// The thread's command (Query, Sleep …) is its state and the server's
+// state text what it waits on.
+func (c *mysqlConn) Activity(ctx context.Context) ([]adapter.Backend, error) {
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("mysql: processlist: %w", err)
+	}
+	defer conn.Close()
+	var self int64
+	if err := conn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&self); err != nil {
+		return nil, fmt.Errorf("mysql: connection_id: %w", err)
+	}
+	rows, err := conn.QueryContext(ctx, "SHOW FULL PROCESSLIST")
+	if err != nil {
+		return nil, fmt.Errorf("mysql: processlist: %w", err)
+	}
+	defer rows.Close()
+
+	// MariaDB adds columns of its own, so they are read by name.
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("mysql: processlist: %w", err)
+	}
+	vals := make([]sql.NullString, len(names))
+	dest := make([]any, len(names))
+	for i := range vals {
+		dest[i] = &vals[i]
+	}
+	var backends []adapter.Backend
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("mysql: processlist: %w", err)
+		}
+		col := make(map[string]string, len(names))
+		for i, n := range names {
+			col[strings.ToLower(n)] = vals[i].String
+		}
+		id, _ := strconv.ParseInt(col["id"], 10, 64)
+		if id == self {
+			continue
+		}
+		secs, _ := strconv.ParseInt(col["time"], 10, 64)
+		backends = append(backends, adapter.Backend{
+			ID:       id,
+			User:     col["user"],
+			Database: col["db"],
+			Client:   col["host"],
+			State:    col["command"],
+			Wait:     col["state"],
+			Query:    col["info"],
+			Duration: time.Duration(secs) * time.Second,
+		})
+	}
+	return backends, rows.Err()
 }
 
-type rowIterator struct {
-	conn      *mysqlConn
-	baseQuery string
-	pageSize  int
-	columns   []adapter.ColumnMeta
-	offset    int64
+// CancelBackend stops the statement thread id is running (KILL QUERY).
+func (c *mysqlConn) CancelBackend(ctx context.Context, id int64) error {
+	return c.kill(ctx, "QUERY", id)
 }
 
-func (it *rowIterator) Columns() []adapter.ColumnMeta { return it.columns }
-func (it *rowIterator) TotalRows() int64              { return -1 }
-func (it *rowIterator) Close() error                  { return nil }
+// TerminateBackend closes the connection of thread id (KILL CONNECTION).
+func (c *mysqlConn) TerminateBackend(ctx context.Context, id int64) error {
+	return c.kill(ctx, "CONNECTION", id)
+}
 
-func (it *rowIterator) FetchNext(ctx context.Context) ([][]string, error) {
-	q := fmt.Sprintf("SELECT * FROM (%s) AS _t LIMIT %d OFFSET %d",
-		it.baseQuery, it.pageSize, it.offset)
+// Begin starts an explicit transaction that Execute runs in until Commit
+// or Rollback.
+func (c *mysqlConn) Begin(ctx context.Context) error {
+	if err := c.tx.Begin(ctx, c.db); err != nil {
+		return fmt.Errorf("mysql: begin: %w", err)
+	}
+	return nil
+}
 
-	rows, err := it.conn.db.QueryContext(ctx, q)
-	if err != nil {
+// Commit commits the open transaction.
+func (c *mysqlConn) Commit(ctx context.Context) error {
+	if err := c.tx.Commit(); err != nil {
+		return fmt.Errorf("mysql: commit: %w", err)
+	}
+	return nil
+}
+
+// Rollback rolls back the open transaction.
+func (c *mysqlConn) Rollback(ctx context.Context) error {
+	if err := c.tx.Rollback(); err != nil {
+		return fmt.Errorf("mysql: rollback: %w", err)
+	}
+	return nil
+}
+
+// InTransaction returns whether a transaction is open.
+func (c *mysqlConn) InTransaction() bool { return c.tx.Active() }
+
+// ---------------------------------------------------------------------------
+// Streaming (one unbuffered result on a dedicated connection)
+// ---------------------------------------------------------------------------
+
+// streamCachePages is how many of the last pages read a streaming iterator
+// keeps for FetchPrev and short backward seeks.
+const streamCachePages = 10
+
+// ExecuteStreaming runs query once on a connection of its own and reads the
+// result as it is paged through. The driver does not buffer results, so the
+// rows not fetched yet wait on the server; the connection stays pinned
+// until Close. The context must stay alive for the iterator's lifetime.
+func (c *mysqlConn) ExecuteStreaming(ctx context.Context, query string, pageSize int) (adapter.RowIterator, error) {
+	base, cancel := context.WithCancel(ctx)
+	it := &rowIterator{
+		conn:     c,
+		query:    query,
+		pageSize: pageSize,
+		base:     base,
+		cancel:   cancel,
+	}
+	if err := it.open(ctx); err != nil {
+		cancel()
 		return nil, err
 	}
-	defer rows.Close()
+	return it, nil
+}
+
+// rowIterator implements adapter.RowIterator over a single result read
+// forward. The last streamCachePages pages are kept, so paging back over
+// them costs nothing; going back further runs the query again.
+type rowIterator struct {
+	conn     *mysqlConn
+	query    string
+	pageSize int
+	columns  []adapter.ColumnMeta
+
+	base   context.Context // lives until Close
+	cancel context.CancelFunc
+	closed atomic.Bool
+
+	mu         sync.Mutex // serializes fetches with each other and Close
+	sqlConn    *sql.Conn
+	rows       *sql.Rows
+	streamStop context.CancelFunc // aborts the current result
+	read       int64              // rows read from the current result
+	done       bool               // the result has no more rows
+
+	cache      [][]string // the last rows read
+	cacheStart int64      // row number of cache[0]
+	next       int64      // row number FetchNext returns first
+}
 
-	page, err := scanPage(rows, len(it.columns))
+// open runs the query on a connection from the pool, reading nothing yet.
+// Cancelling ctx aborts it while it runs; Cancel() does too, as for any
+// query.
+func (it *rowIterator) open(ctx context.Context) error {
+	sctx, stop := context.WithCancel(it.base)
+	defer context.AfterFunc(ctx, stop)()
+
+	conn, err := it.conn.db.Conn(sctx)
 	if err != nil {
-		return nil, err
+		stop()
+		return fmt.Errorf("mysql: acquire conn: %w", err)
+	}
+	var connID int64
+	if err := conn.QueryRowContext(sctx, "SELECT CONNECTION_ID()").Scan(&connID); err != nil {
+		conn.Close()
+		stop()
+		return fmt.Errorf("mysql: connection_id: %w", err)
 	}
 
-	if len(page) == 0 {
-		return nil, io.EOF
+	c := it.conn
+	c.mu.Lock()
+	c.cancel = stop
+	c.activeConnID = connID
+	c.mu.Unlock()
+	rows, err := conn.QueryContext(sctx, it.query)
+	c.mu.Lock()
+	if c.activeConnID == connID {
+		c.cancel = nil
+		c.activeConnID = 0
+	}
+	c.mu.Unlock()
+	if err != nil {
+		conn.Close()
+		stop()
+		if sctx.Err() != nil {
+			return adapter.ErrCancelled
+		}
+		return err
 	}
 
-	it.offset += int64(len(page))
-	return page, nil
+	if it.columns == nil {
+		colTypes, err := rows.ColumnTypes()
+		if err != nil {
+			rows.Close()
+			conn.Close()
+			stop()
+			return err
+		}
+		it.columns = make([]adapter.ColumnMeta, len(colTypes))
+		for i, ct := range colTypes {
+			it.columns[i].Name = ct.Name()
+			it.columns[i].Type = ct.DatabaseTypeName()
+			if n, ok := ct.Nullable(); ok {
+				it.columns[i].Nullable = n
+			}
+		}
+	}
+
+	it.sqlConn, it.rows, it.streamStop = conn, rows, stop
+	it.read, it.done = 0, false
+	it.cache, it.cacheStart = nil, 0
+	return nil
 }
 
-func (it *rowIterator) FetchPrev(ctx context.Context) ([][]string, error) {
-	newOffset := it.offset - int64(it.pageSize)*2
-	if newOffset < 0 {
-		// If we haven't scrolled enough pages to go back, clamp or error.
-		if it.offset-int64(it.pageSize) <= 0 {
-			return nil, adapter.ErrNoBidirectional
-		}
-		newOffset = 0
+// closeStream drops the current result and returns its connection to the
+// pool. It aborts the read rather than draining the rows left.
+func (it *rowIterator) closeStream() {
+	if it.rows == nil {
+		return
 	}
+	it.streamStop()
+	it.rows.Close()
+	it.sqlConn.Close()
+	it.rows, it.sqlConn = nil, nil
+}
 
-	it.offset = newOffset
+func (it *rowIterator) Columns() []adapter.ColumnMeta { return it.columns }
+func (it *rowIterator) TotalRows() int64              { return -1 }
 
-	q := fmt.Sprintf("SELECT * FROM (%s) AS _t LIMIT %d OFFSET %d",
-		it.baseQuery, it.pageSize, it.offset)
+// Close aborts the result, interrupting a fetch in progress, and returns
+// the connection to the pool.
+func (it *rowIterator) Close() error {
+	if !it.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+	it.cancel()
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	it.closeStream()
+	it.cache = nil
+	return nil
+}
 
-	rows, err := it.conn.db.QueryContext(ctx, q)
+func (it *rowIterator) FetchNext(ctx context.Context) ([][]string, error) {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	page, err := it.page(ctx, it.next)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	if len(page) == 0 {
+		return nil, io.EOF
+	}
+	it.next += int64(len(page))
+	return page, nil
+}
 
-	page, err := scanPage(rows, len(it.columns))
+// FetchPrev returns the page before the last one fetched.
+func (it *rowIterator) FetchPrev(ctx context.Context) ([][]string, error) {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	size := int64(it.pageSize)
+	if it.next-size <= 0 {
+		return nil, adapter.ErrNoBidirectional
+	}
+	start := max(it.next-2*size, 0)
+	page, err := it.page(ctx, start)
 	if err != nil {
 		return nil, err
 	}
-
 	if len(page) == 0 {
 		return nil, io.EOF
 	}
-
-	it.offset += int64(len(page))
+	it.next = start + int64(len(page))
 	return page, nil
 }
 
-func scanPage(rows *sql.Rows, nCols int) ([][]string, error) {
-	var page [][]string
-	for rows.Next() {
-		values := make([]sql.NullString, nCols)
-		ptrs := make([]any, nCols)
-		for i := range values {
-			ptrs[i] = &values[i]
-		}
-		if err := rows.Scan(ptrs...); err != nil {
+// Seek moves the iterator so the next FetchNext starts at offset. Rows up
+// to it are read and dropped when it lies ahead; the query runs again when
+// it lies before the pages kept.
+func (it *rowIterator) Seek(_ context.Context, offset int64) error {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	if it.closed.Load() {
+		return io.EOF
+	}
+	it.next = max(offset, 0)
+	return nil
+}
+
+// page returns the page starting at row number start, reading the result
+// up to its end and running the query again if start was dropped from the
+// cache.
+func (it *rowIterator) page(ctx context.Context, start int64) ([][]string, error) {
+	if it.closed.Load() {
+		return nil, io.EOF
+	}
+	if start < it.cacheStart || (it.rows == nil && !it.done) {
+		it.closeStream()
+		if err := it.open(ctx); err != nil {
 			return nil, err
 		}
-		row := make([]string, nCols)
-		for i, v := range values {
-			if v.Valid {
-				row[i] = v.String
-			} else {
-				row[i] = "NULL"
+	}
+	if err := it.readTo(ctx, start+int64(it.pageSize)); err != nil {
+		return nil, err
+	}
+	lo := int(start - it.cacheStart)
+	if lo >= len(it.cache) {
+		return nil, nil
+	}
+	hi := min(lo+it.pageSize, len(it.cache))
+	return it.cache[lo:hi:hi], nil
+}
+
+// readTo reads the result until n rows have been read or it ends, keeping
+// the last streamCachePages pages. Cancelling ctx aborts the result; the
+// next page runs the query again.
+func (it *rowIterator) readTo(ctx context.Context, n int64) error {
+	defer context.AfterFunc(ctx, it.streamStop)()
+	limit := streamCachePages * it.pageSize
+	for it.read < n && !it.done {
+		if !it.rows.Next() {
+			err := it.rows.Err()
+			it.closeStream()
+			if err != nil {
+				if ctx.Err() != nil || it.base.Err() != nil {
+					return adapter.ErrCancelled
+				}
+				return err
 			}
+			it.done = true
+			break
+		}
+		row, err := scanRow(it.rows, len(it.columns))
+		if err != nil {
+			it.closeStream()
+			return err
+		}
+		it.cache = append(it.cache, row)
+		it.read++
+		if len(it.cache) > limit {
+			it.cache = it.cache[1:]
+			it.cacheStart++
+		}
+	}
+	return nil
+}
+
+// scanRow scans the current row of rows as strings, NULL as
+// adapter.NullValue.
+func scanRow(rows *sql.Rows, nCols int) ([]string, error) {
+	values := make([]sql.NullString, nCols)
+	ptrs := make([]any, nCols)
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	if err := rows.Scan(ptrs...); err != nil {
+		return nil, err
+	}
+	row := make([]string, nCols)
+	for i, v := range values {
+		if v.Valid {
+			row[i] = v.String
+		} else {
+			row[i] = adapter.NullValue
 		}
-		page = append(page, row)
 	}
-	return page, rows.Err()
+	return row, nil
 }
 
 // ---------------------------------------------------------------------------
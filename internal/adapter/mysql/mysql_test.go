@@ -1,6 +1,13 @@
 package mysql
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strconv"
+	"sync/atomic"
 	"testing"
 
 	"github.com/sadopc/gotermsql/internal/adapter"
@@ -214,3 +221,120 @@ func containsParseTime(dsn string) bool {
 	}
 	return false
 }
+
+func TestValidateDSN(t *testing.T) {
+	a := &mysqlAdapter{}
+	for _, dsn := range []string{"mysql://root@localhost/shop", "root:pw@tcp(localhost:3306)/shop"} {
+		if err := a.ValidateDSN(dsn); err != nil {
+			t.Errorf("ValidateDSN(%q) = %v, want nil", dsn, err)
+		}
+	}
+	for _, dsn := range []string{"root@tcp(localhost:3306", "root@tcp(localhost:3306)/shop?timeout=soon"} {
+		if err := a.ValidateDSN(dsn); err == nil {
+			t.Errorf("ValidateDSN(%q) = nil, want an error", dsn)
+		}
+	}
+}
+
+// countDriver is a database/sql driver whose queries return the numbers
+// 0 to rows-1, one per row, counting the queries run.
+type countDriver struct {
+	rows    int
+	queries atomic.Int32
+}
+
+func (d *countDriver) Open(string) (driver.Conn, error) { return countConn{d}, nil }
+
+type countConn struct{ d *countDriver }
+
+func (countConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (countConn) Close() error                        { return nil }
+func (countConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c countConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if query == "SELECT CONNECTION_ID()" {
+		return &countRows{n: 1, from: 7}, nil
+	}
+	c.d.queries.Add(1)
+	return &countRows{n: c.d.rows}, nil
+}
+
+type countRows struct{ i, n, from int }
+
+func (r *countRows) Columns() []string { return []string{"n"} }
+func (r *countRows) Close() error      { return nil }
+
+func (r *countRows) Next(dest []driver.Value) error {
+	if r.i == r.n {
+		return io.EOF
+	}
+	dest[0] = int64(r.from + r.i)
+	r.i++
+	return nil
+}
+
+var countDrivers atomic.Int32
+
+func TestStreaming(t *testing.T) {
+	d := &countDriver{rows: 25}
+	name := "count" + strconv.Itoa(int(countDrivers.Add(1)))
+	sql.Register(name, d)
+	db, _ := sql.Open(name, "")
+	defer db.Close()
+	c := &mysqlConn{db: db}
+	ctx := context.Background()
+
+	// One row a page keeps the last streamCachePages rows.
+	iter, err := c.ExecuteStreaming(ctx, "SELECT n FROM t", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer iter.Close()
+	if cols := iter.Columns(); len(cols) != 1 || cols[0].Name != "n" {
+		t.Fatalf("columns = %v", cols)
+	}
+	first := func(page [][]string, err error) string {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return page[0][0]
+	}
+	for i := range 20 {
+		if got := first(iter.FetchNext(ctx)); got != strconv.Itoa(i) {
+			t.Fatalf("page %d = %s", i, got)
+		}
+	}
+	if got := first(iter.FetchPrev(ctx)); got != "18" {
+		t.Errorf("previous page = %s, want 18", got)
+	}
+	if n := d.queries.Load(); n != 1 {
+		t.Errorf("ran the query %d times, want once", n)
+	}
+
+	// Going back past the pages kept runs the query again.
+	seeker := iter.(adapter.Seeker)
+	if err := seeker.Seek(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if got := first(iter.FetchNext(ctx)); got != "2" || d.queries.Load() != 2 {
+		t.Errorf("row after seek = %s with %d queries, want 2 with 2", got, d.queries.Load())
+	}
+	if err := seeker.Seek(ctx, 24); err != nil {
+		t.Fatal(err)
+	}
+	if got := first(iter.FetchNext(ctx)); got != "24" {
+		t.Errorf("last row = %s, want 24", got)
+	}
+	if _, err := iter.FetchNext(ctx); !errors.Is(err, io.EOF) {
+		t.Errorf("past the end: got %v, want io.EOF", err)
+	}
+	if got := first(iter.FetchPrev(ctx)); got != "23" {
+		t.Errorf("previous page at the end = %s, want 23", got)
+	}
+
+	iter.Close()
+	if _, err := iter.FetchNext(ctx); !errors.Is(err, io.EOF) {
+		t.Errorf("after Close: got %v, want io.EOF", err)
+	}
+}
@@ -0,0 +1,166 @@
+package adapter
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// ParamExecutor is an optional interface that connections can implement to
+// run a query as a prepared statement, sending args apart from its text
+// for the placeholders ParseParams rewrote it to, so values never need
+// quoting. PostgreSQL keeps the statement prepared on its session, and
+// repeated runs reuse its plan.
+type ParamExecutor interface {
+	ExecuteParams(ctx context.Context, query string, args []any) (*QueryResult, error)
+}
+
+// ParamQuery is a query with bind parameters, rewritten to the
+// placeholders of its dialect: $1, $2 ... for PostgreSQL, ? for the rest.
+type ParamQuery struct {
+	Query string
+	// Names are the parameters, in order of first appearance: "id" for
+	// :id, "$2" for $2, and "?1", "?2" ... for each ?.
+	Names []string
+
+	refs       []int // index in Names of each placeholder, in order
+	positional bool  // placeholders are ?, one value each
+}
+
+// ParseParams finds the bind parameters of query, read the way dialect
+// reads it: :name anywhere, $1 for PostgreSQL and DuckDB, and ? for all but
+// PostgreSQL, where it is a JSON operator. Strings, quoted identifiers,
+// comments and casts such as ::int are left alone. A query without
+// parameters has no Names.
+func ParseParams(dialect, query string) ParamQuery {
+	l := lexerFor(dialect)
+	dollar := dialect == "postgres"
+	var b strings.Builder
+	pq := ParamQuery{positional: !dollar}
+	index := map[string]int{}
+	add := func(name string) {
+		i, ok := index[name]
+		if !ok {
+			i = len(pq.Names)
+			index[name] = i
+			pq.Names = append(pq.Names, name)
+		}
+		pq.refs = append(pq.refs, i)
+		if dollar {
+			b.WriteString("$" + strconv.Itoa(i+1))
+		} else {
+			b.WriteString("?")
+		}
+	}
+
+	q := query
+	last := 0 // q[last:i] is yet to be copied
+	for i := 0; i < len(q); {
+		c := q[i]
+		switch {
+		case c == '-' && strings.HasPrefix(q[i:], "--") && (!l.mysql || i+2 == len(q) || isSpace(q[i+2])),
+			c == '#' && l.mysql:
+			eol := strings.IndexByte(q[i:], '\n')
+			if eol < 0 {
+				eol = len(q) - i
+			}
+			i += eol
+		case c == '/' && strings.HasPrefix(q[i:], "/*") && !(l.mysql && strings.HasPrefix(q[i:], "/*!")):
+			i = l.skipComment(q, i)
+		case c == '\'' || c == '"' || c == '`':
+			i = l.skipQuoted(q, i)
+		case c == ':' && i+1 < len(q) && q[i+1] == ':':
+			i += 2 // a cast
+		case c == ':' && i+1 < len(q) && isNameStart(q[i+1]) && (i == 0 || !isWordByte(q[i-1])):
+			j := i + 1
+			for j < len(q) && isWordByte(q[j]) {
+				j++
+			}
+			b.WriteString(q[last:i])
+			add(q[i+1 : j])
+			i, last = j, j
+		case c == '$' && l.dollar:
+			if tag, ok := dollarTag(q[i:]); ok {
+				e := strings.Index(q[i+len(tag):], tag)
+				if e < 0 {
+					i = len(q)
+					break
+				}
+				i += len(tag) + e + len(tag)
+				break
+			}
+			j := i + 1
+			for j < len(q) && q[j] >= '0' && q[j] <= '9' {
+				j++
+			}
+			if j == i+1 || (i > 0 && isWordByte(q[i-1])) {
+				i++
+				break
+			}
+			b.WriteString(q[last:i])
+			add(q[i:j])
+			i, last = j, j
+		case c == '?' && dialect != "postgres":
+			b.WriteString(q[last:i])
+			add("?" + strconv.Itoa(len(pq.refs)+1))
+			i, last = i+1, i+1
+		case isWordByte(c):
+			// Skip whole words, so a$1 stays an identifier.
+			for i < len(q) && (isWordByte(q[i]) || q[i] == '$' && l.dollar) {
+				i++
+			}
+		default:
+			i++
+		}
+	}
+	if len(pq.Names) == 0 {
+		return ParamQuery{Query: query}
+	}
+	b.WriteString(q[last:])
+	pq.Query = b.String()
+	return pq
+}
+
+// Args returns the bind values for the placeholders of pq, given values
+// for its Names in order.
+func (pq ParamQuery) Args(values []any) []any {
+	if pq.positional {
+		// One value per ?, repeated where a name is.
+		args := make([]any, len(pq.refs))
+		for i, ref := range pq.refs {
+			args[i] = values[ref]
+		}
+		return args
+	}
+	return values
+}
+
+// BindValue reads text typed for a parameter as a typed value: nothing or
+// NULL as nil, true and false as booleans, numbers as int64 or float64, and
+// anything else as a string. Quoting in single quotes keeps it a string,
+// as in '007'; two quotes alone are the empty string.
+func BindValue(text string) any {
+	t := strings.TrimSpace(text)
+	if len(t) >= 2 && t[0] == '\'' && t[len(t)-1] == '\'' {
+		return strings.ReplaceAll(t[1:len(t)-1], "''", "'")
+	}
+	switch strings.ToLower(t) {
+	case "", "null":
+		return nil
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if n, err := strconv.ParseInt(t, 10, 64); err == nil {
+		return n
+	}
+	if f, err := strconv.ParseFloat(t, 64); err == nil && !strings.ContainsAny(t, "xXnN") {
+		return f
+	}
+	return text
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
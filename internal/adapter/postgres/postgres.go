@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/url"
@@ -30,7 +31,22 @@ func (a *postgresAdapter) Name() string     { return "postgres" }
 func (a *postgresAdapter) DefaultPort() int { return 5432 }
 
 func (a *postgresAdapter) Connect(ctx context.Context, dsn string) (adapter.Connection, error) {
-	pool, err := pgxpool.New(ctx, dsn)
+	return a.ConnectSession(ctx, dsn, nil)
+}
+
+// ConnectSession connects with init run on every pooled connection and on
+// the direct connections opened for streaming.
+func (a *postgresAdapter) ConnectSession(ctx context.Context, dsn string, init []string) (adapter.Connection, error) {
+	cfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("postgres connect: %w", err)
+	}
+	if len(init) > 0 {
+		cfg.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+			return setupSession(ctx, conn, init)
+		}
+	}
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("postgres connect: %w", err)
 	}
@@ -45,9 +61,29 @@ func (a *postgresAdapter) Connect(ctx context.Context, dsn string) (adapter.Conn
 		pool:   pool,
 		dsn:    dsn,
 		dbName: dbName,
+		init:   init,
 	}, nil
 }
 
+// setupSession runs the session setup statements on conn.
+func setupSession(ctx context.Context, conn *pgx.Conn, init []string) error {
+	for _, stmt := range init {
+		if _, err := conn.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("session setup %q: %w", stmt, err)
+		}
+	}
+	return nil
+}
+
+// ValidateDSN checks that dsn is a URL or keyword=value connection string
+// pgx can parse.
+func (a *postgresAdapter) ValidateDSN(dsn string) error {
+	if _, err := pgconn.ParseConfig(dsn); err != nil {
+		return fmt.Errorf("postgres: invalid dsn: %w", err)
+	}
+	return nil
+}
+
 // extractDBName parses the database name from the DSN.
 func extractDBName(dsn string) string {
 	if dsn == "" {
@@ -72,8 +108,18 @@ type pgConn struct {
 	pool     *pgxpool.Pool
 	dsn      string
 	dbName   string
+	init     []string // session setup run on every connection
 	cancelMu sync.Mutex
 	cancelFn context.CancelFunc
+
+	txMu sync.Mutex
+	tx   pgx.Tx // explicit transaction Execute runs in, or nil
+}
+
+// querier is the part of a pool and a transaction that runs statements.
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
 }
 
 func (c *pgConn) DatabaseName() string { return c.dbName }
@@ -84,6 +130,7 @@ func (c *pgConn) Ping(ctx context.Context) error {
 }
 
 func (c *pgConn) Close() error {
+	_ = c.Rollback(context.Background())
 	c.pool.Close()
 	return nil
 }
@@ -176,9 +223,11 @@ func (c *pgConn) loadSchemas(ctx context.Context, dbName string) ([]schema.Schem
 		}
 
 		tables, _ := c.Tables(ctx, dbName, name)
+		matViews, _ := c.matViews(ctx, name)
 		schemas = append(schemas, schema.Schema{
 			Name:   name,
 			Tables: tables,
+			Views:  matViews,
 		})
 	}
 	return schemas, rows.Err()
@@ -212,6 +261,30 @@ func (c *pgConn) Tables(ctx context.Context, db, schemaName string) ([]schema.Ta
 	return tables, rows.Err()
 }
 
+// matViews lists the materialized views of a schema. They are missing from
+// information_schema, so pg_matviews is queried instead.
+func (c *pgConn) matViews(ctx context.Context, schemaName string) ([]schema.View, error) {
+	rows, err := c.pool.Query(ctx,
+		`SELECT matviewname, definition
+		 FROM pg_matviews
+		 WHERE schemaname = $1
+		 ORDER BY matviewname`, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("materialized views: %w", err)
+	}
+	defer rows.Close()
+
+	var views []schema.View
+	for rows.Next() {
+		v := schema.View{Materialized: true}
+		if err := rows.Scan(&v.Name, &v.Definition); err != nil {
+			return nil, fmt.Errorf("materialized views scan: %w", err)
+		}
+		views = append(views, v)
+	}
+	return views, rows.Err()
+}
+
 func (c *pgConn) Columns(ctx context.Context, db, schemaName, table string) ([]schema.Column, error) {
 	if schemaName == "" {
 		schemaName = "public"
@@ -540,11 +613,297 @@ func (c *pgConn) AllForeignKeys(ctx context.Context, db, schemaName string) (map
 	return result, nil
 }
 
+// TableDDL reconstructs the CREATE statement of a table or view from the
+// catalog, since Postgres has no SHOW CREATE TABLE. Indexes that do not
+// back a constraint follow as CREATE INDEX statements.
+func (c *pgConn) TableDDL(ctx context.Context, db, schemaName, table string) (string, error) {
+	if schemaName == "" {
+		schemaName = "public"
+	}
+
+	var (
+		oid     uint32
+		relkind string
+	)
+	err := c.pool.QueryRow(ctx,
+		`SELECT c.oid, c.relkind::text
+		 FROM pg_class c
+		 JOIN pg_namespace n ON n.oid = c.relnamespace
+		 WHERE n.nspname = $1 AND c.relname = $2`, schemaName, table).Scan(&oid, &relkind)
+	if err != nil {
+		return "", fmt.Errorf("ddl: %s.%s: %w", schemaName, table, err)
+	}
+	name := adapter.QuoteIdentifier("postgres", schemaName) + "." + adapter.QuoteIdentifier("postgres", table)
+
+	if relkind == "v" || relkind == "m" {
+		var def string
+		if err := c.pool.QueryRow(ctx, `SELECT pg_get_viewdef($1::oid, true)`, oid).Scan(&def); err != nil {
+			return "", fmt.Errorf("ddl view: %w", err)
+		}
+		kind := "VIEW"
+		if relkind == "m" {
+			kind = "MATERIALIZED VIEW"
+		}
+		return fmt.Sprintf("CREATE %s %s AS\n%s", kind, name, strings.TrimSpace(def)), nil
+	}
+
+	rows, err := c.pool.Query(ctx,
+		`SELECT a.attname, format_type(a.atttypid, a.atttypmod), a.attnotnull,
+		        pg_get_expr(d.adbin, d.adrelid)
+		 FROM pg_attribute a
+		 LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+		 WHERE a.attrelid = $1 AND a.attnum > 0 AND NOT a.attisdropped
+		 ORDER BY a.attnum`, oid)
+	if err != nil {
+		return "", fmt.Errorf("ddl columns: %w", err)
+	}
+	var defs []string
+	for rows.Next() {
+		var (
+			col, typ string
+			notNull  bool
+			def      *string
+		)
+		if err := rows.Scan(&col, &typ, &notNull, &def); err != nil {
+			rows.Close()
+			return "", fmt.Errorf("ddl columns scan: %w", err)
+		}
+		defs = append(defs, pgColumnDef(col, typ, notNull, def))
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("ddl columns: %w", err)
+	}
+
+	rows, err = c.pool.Query(ctx,
+		`SELECT conname, pg_get_constraintdef(oid, true)
+		 FROM pg_constraint
+		 WHERE conrelid = $1
+		 ORDER BY contype <> 'p', conname`, oid)
+	if err != nil {
+		return "", fmt.Errorf("ddl constraints: %w", err)
+	}
+	for rows.Next() {
+		var con, def string
+		if err := rows.Scan(&con, &def); err != nil {
+			rows.Close()
+			return "", fmt.Errorf("ddl constraints scan: %w", err)
+		}
+		defs = append(defs, "CONSTRAINT "+adapter.QuoteIdentifier("postgres", con)+" "+def)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("ddl constraints: %w", err)
+	}
+
+	var indexes []string
+	rows, err = c.pool.Query(ctx,
+		`SELECT pg_get_indexdef(i.indexrelid)
+		 FROM pg_index i
+		 WHERE i.indrelid = $1
+		   AND NOT EXISTS (SELECT 1 FROM pg_constraint c WHERE c.conindid = i.indexrelid)
+		 ORDER BY i.indexrelid`, oid)
+	if err != nil {
+		return "", fmt.Errorf("ddl indexes: %w", err)
+	}
+	for rows.Next() {
+		var def string
+		if err := rows.Scan(&def); err != nil {
+			rows.Close()
+			return "", fmt.Errorf("ddl indexes scan: %w", err)
+		}
+		indexes = append(indexes, def)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("ddl indexes: %w", err)
+	}
+
+	return pgCreateTable(name, defs, indexes), nil
+}
+
+// TableStats reports the planner's row estimate (pg_class.reltuples) and the
+// total size including indexes and TOAST for each table of a schema. Tables
+// that were never vacuumed or analyzed have no row estimate.
+func (c *pgConn) TableStats(ctx context.Context, db, schemaName string) (map[string]schema.TableStats, error) {
+	if schemaName == "" {
+		schemaName = "public"
+	}
+
+	rows, err := c.pool.Query(ctx,
+		`SELECT c.relname, c.reltuples::bigint, pg_total_relation_size(c.oid)
+		 FROM pg_class c
+		 JOIN pg_namespace n ON n.oid = c.relnamespace
+		 WHERE n.nspname = $1 AND c.relkind IN ('r', 'p', 'm')`, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("table stats: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]schema.TableStats)
+	for rows.Next() {
+		var name string
+		var st schema.TableStats
+		if err := rows.Scan(&name, &st.Rows, &st.Bytes); err != nil {
+			return nil, fmt.Errorf("table stats scan: %w", err)
+		}
+		if st.Rows < 0 {
+			st.Rows = -1
+		}
+		result[name] = st
+	}
+	return result, rows.Err()
+}
+
+// Routines lists the functions and procedures of a schema, skipping
+// aggregates and window functions.
+func (c *pgConn) Routines(ctx context.Context, db, schemaName string) ([]schema.Routine, error) {
+	if schemaName == "" {
+		schemaName = "public"
+	}
+
+	rows, err := c.pool.Query(ctx,
+		`SELECT p.proname,
+		        p.prokind = 'p',
+		        pg_get_function_arguments(p.oid),
+		        COALESCE(pg_get_function_result(p.oid), '')
+		 FROM pg_proc p
+		 JOIN pg_namespace n ON n.oid = p.pronamespace
+		 WHERE n.nspname = $1 AND p.prokind IN ('f', 'p')
+		 ORDER BY p.proname, pg_get_function_arguments(p.oid)`, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("routines: %w", err)
+	}
+	defer rows.Close()
+
+	var routines []schema.Routine
+	for rows.Next() {
+		var r schema.Routine
+		var isProc bool
+		if err := rows.Scan(&r.Name, &isProc, &r.Args, &r.Returns); err != nil {
+			return nil, fmt.Errorf("routines scan: %w", err)
+		}
+		r.Kind = "function"
+		if isProc {
+			r.Kind = "procedure"
+			r.Returns = ""
+		}
+		routines = append(routines, r)
+	}
+	return routines, rows.Err()
+}
+
+// Sequences lists the sequences of a schema.
+func (c *pgConn) Sequences(ctx context.Context, db, schemaName string) ([]schema.Sequence, error) {
+	if schemaName == "" {
+		schemaName = "public"
+	}
+
+	rows, err := c.pool.Query(ctx,
+		`SELECT sequence_name FROM information_schema.sequences
+		 WHERE sequence_schema = $1
+		 ORDER BY sequence_name`, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("sequences: %w", err)
+	}
+	defer rows.Close()
+
+	var seqs []schema.Sequence
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("sequences scan: %w", err)
+		}
+		seqs = append(seqs, schema.Sequence{Name: name})
+	}
+	return seqs, rows.Err()
+}
+
+// Triggers lists the triggers on the tables and views of a schema.
+// information_schema reports one row per event, so they are folded together.
+func (c *pgConn) Triggers(ctx context.Context, db, schemaName string) ([]schema.Trigger, error) {
+	if schemaName == "" {
+		schemaName = "public"
+	}
+
+	rows, err := c.pool.Query(ctx,
+		`SELECT trigger_name, event_object_table, action_timing,
+		        string_agg(event_manipulation, ' OR ' ORDER BY event_manipulation)
+		 FROM information_schema.triggers
+		 WHERE trigger_schema = $1
+		 GROUP BY trigger_name, event_object_table, action_timing
+		 ORDER BY event_object_table, trigger_name`, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("triggers: %w", err)
+	}
+	defer rows.Close()
+
+	var triggers []schema.Trigger
+	for rows.Next() {
+		var t schema.Trigger
+		if err := rows.Scan(&t.Name, &t.Table, &t.Timing, &t.Event); err != nil {
+			return nil, fmt.Errorf("triggers scan: %w", err)
+		}
+		triggers = append(triggers, t)
+	}
+	return triggers, rows.Err()
+}
+
+// pgColumnDef renders one column of a CREATE TABLE statement.
+func pgColumnDef(name, typ string, notNull bool, def *string) string {
+	s := adapter.QuoteIdentifier("postgres", name) + " " + typ
+	if def != nil {
+		s += " DEFAULT " + *def
+	}
+	if notNull {
+		s += " NOT NULL"
+	}
+	return s
+}
+
+// pgCreateTable assembles a CREATE TABLE statement from its column and
+// constraint definitions, followed by separate index statements.
+func pgCreateTable(name string, defs, indexes []string) string {
+	var sb strings.Builder
+	sb.WriteString("CREATE TABLE " + name + " (\n")
+	for i, d := range defs {
+		sb.WriteString("    " + d)
+		if i < len(defs)-1 {
+			sb.WriteByte(',')
+		}
+		sb.WriteByte('\n')
+	}
+	sb.WriteString(");")
+	for _, idx := range indexes {
+		sb.WriteString("\n\n" + idx + ";")
+	}
+	return sb.String()
+}
+
 // ---------------------------------------------------------------------------
 // Query Execution
 // ---------------------------------------------------------------------------
 
 func (c *pgConn) Execute(ctx context.Context, query string) (*adapter.QueryResult, error) {
+	return c.execute(ctx, query, nil)
+}
+
+// ExecuteParams runs query as a prepared statement, binding args to its
+// $1, $2 ... placeholders. pgx keeps the statement prepared on each session
+// it runs on, so running it again reuses the plan. Values are sent as text
+// for the server to read as the parameter's type, so an int64 can fill a
+// numeric or text parameter alike.
+func (c *pgConn) ExecuteParams(ctx context.Context, query string, args []any) (*adapter.QueryResult, error) {
+	text := make([]any, len(args))
+	for i, a := range args {
+		if a != nil {
+			text[i] = fmt.Sprint(a)
+		}
+	}
+	return c.execute(ctx, query, text)
+}
+
+func (c *pgConn) execute(ctx context.Context, query string, args []any) (*adapter.QueryResult, error) {
 	ctx, cancel := context.WithCancel(ctx)
 	c.setCancel(cancel)
 	defer c.clearCancel()
@@ -553,13 +912,13 @@ func (c *pgConn) Execute(ctx context.Context, query string) (*adapter.QueryResul
 	isSelect := isSelectQuery(query)
 
 	if isSelect {
-		return c.executeSelect(ctx, query, start)
+		return c.executeSelect(ctx, query, start, args...)
 	}
-	return c.executeNonSelect(ctx, query, start)
+	return c.executeNonSelect(ctx, query, start, args...)
 }
 
-func (c *pgConn) executeSelect(ctx context.Context, query string, start time.Time) (*adapter.QueryResult, error) {
-	rows, err := c.pool.Query(ctx, query)
+func (c *pgConn) executeSelect(ctx context.Context, query string, start time.Time, args ...any) (*adapter.QueryResult, error) {
+	rows, err := c.session(ctx).Query(ctx, query, args...)
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, adapter.ErrCancelled
@@ -576,7 +935,7 @@ func (c *pgConn) executeSelect(ctx context.Context, query string, start time.Tim
 		if err != nil {
 			return nil, fmt.Errorf("execute values: %w", err)
 		}
-		result = append(result, valuesToStrings(vals))
+		result = append(result, rowToStrings(vals, cols))
 	}
 	if err := rows.Err(); err != nil {
 		if ctx.Err() != nil {
@@ -594,8 +953,8 @@ func (c *pgConn) executeSelect(ctx context.Context, query string, start time.Tim
 	}, nil
 }
 
-func (c *pgConn) executeNonSelect(ctx context.Context, query string, start time.Time) (*adapter.QueryResult, error) {
-	tag, err := c.pool.Exec(ctx, query)
+func (c *pgConn) executeNonSelect(ctx context.Context, query string, start time.Time, args ...any) (*adapter.QueryResult, error) {
+	tag, err := c.session(ctx).Exec(ctx, query, args...)
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, adapter.ErrCancelled
@@ -611,6 +970,71 @@ func (c *pgConn) executeNonSelect(ctx context.Context, query string, start time.
 	}, nil
 }
 
+// session returns the open transaction, or the pool when there is none or
+// ctx wants it (adapter.OnPool).
+func (c *pgConn) session(ctx context.Context) querier {
+	c.txMu.Lock()
+	defer c.txMu.Unlock()
+	if c.tx != nil && !adapter.WantsPool(ctx) {
+		return c.tx
+	}
+	return c.pool
+}
+
+// Begin starts an explicit transaction that Execute runs in until Commit
+// or Rollback.
+func (c *pgConn) Begin(ctx context.Context) error {
+	c.txMu.Lock()
+	defer c.txMu.Unlock()
+	if c.tx != nil {
+		return adapter.ErrTransactionActive
+	}
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	c.tx = tx
+	return nil
+}
+
+// Commit commits the open transaction. The transaction is over even when
+// committing fails; a transaction aborted by an error is rolled back.
+func (c *pgConn) Commit(ctx context.Context) error {
+	c.txMu.Lock()
+	defer c.txMu.Unlock()
+	if c.tx == nil {
+		return adapter.ErrNoTransaction
+	}
+	err := c.tx.Commit(ctx)
+	c.tx = nil
+	if err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// Rollback rolls back the open transaction.
+func (c *pgConn) Rollback(ctx context.Context) error {
+	c.txMu.Lock()
+	defer c.txMu.Unlock()
+	if c.tx == nil {
+		return adapter.ErrNoTransaction
+	}
+	err := c.tx.Rollback(ctx)
+	c.tx = nil
+	if err != nil {
+		return fmt.Errorf("rollback: %w", err)
+	}
+	return nil
+}
+
+// InTransaction returns whether a transaction is open.
+func (c *pgConn) InTransaction() bool {
+	c.txMu.Lock()
+	defer c.txMu.Unlock()
+	return c.tx != nil
+}
+
 // ---------------------------------------------------------------------------
 // Streaming with server-side cursors
 // ---------------------------------------------------------------------------
@@ -626,6 +1050,12 @@ func (c *pgConn) ExecuteStreaming(ctx context.Context, query string, pageSize in
 		c.clearCancel()
 		return nil, fmt.Errorf("streaming connect: %w", err)
 	}
+	if err := setupSession(ctx, conn, c.init); err != nil {
+		conn.Close(ctx)
+		cancel()
+		c.clearCancel()
+		return nil, err
+	}
 
 	tx, err := conn.Begin(ctx)
 	if err != nil {
@@ -668,7 +1098,7 @@ func (c *pgConn) ExecuteStreaming(ctx context.Context, query string, pageSize in
 			c.clearCancel()
 			return nil, fmt.Errorf("initial fetch values: %w", err)
 		}
-		firstBatch = append(firstBatch, valuesToStrings(vals))
+		firstBatch = append(firstBatch, rowToStrings(vals, cols))
 	}
 	rows.Close()
 	if err := rows.Err(); err != nil {
@@ -745,6 +1175,26 @@ func (it *pgRowIterator) FetchPrev(ctx context.Context) ([][]string, error) {
 	return it.fetch(ctx, fmt.Sprintf("FETCH BACKWARD %d FROM %s", it.pageSize, it.cursorName))
 }
 
+// Seek positions the cursor with MOVE ABSOLUTE so the next FetchNext starts
+// at the 0-based row offset. MOVE ABSOLUTE n leaves the cursor on row n, so
+// the following FETCH FORWARD begins with row n+1.
+func (it *pgRowIterator) Seek(ctx context.Context, offset int64) error {
+	if it.closed.Load() {
+		return io.EOF
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	it.firstBatch = nil
+	if _, err := it.tx.Exec(ctx, fmt.Sprintf("MOVE ABSOLUTE %d IN %s", offset, it.cursorName)); err != nil {
+		if ctx.Err() != nil {
+			return adapter.ErrCancelled
+		}
+		return fmt.Errorf("cursor move: %w", err)
+	}
+	return nil
+}
+
 func (it *pgRowIterator) fetch(ctx context.Context, sql string) ([][]string, error) {
 	rows, err := it.tx.Query(ctx, sql)
 	if err != nil {
@@ -761,7 +1211,7 @@ func (it *pgRowIterator) fetch(ctx context.Context, sql string) ([][]string, err
 		if err != nil {
 			return nil, fmt.Errorf("cursor fetch values: %w", err)
 		}
-		batch = append(batch, valuesToStrings(vals))
+		batch = append(batch, rowToStrings(vals, it.cols))
 	}
 	if err := rows.Err(); err != nil {
 		if ctx.Err() != nil {
@@ -793,6 +1243,333 @@ func (it *pgRowIterator) Close() error {
 	return err
 }
 
+// ---------------------------------------------------------------------------
+// COPY (implements adapter.Copier)
+// ---------------------------------------------------------------------------
+
+// CopyTo runs query, a COPY ... TO STDOUT, writing the data to w.
+func (c *pgConn) CopyTo(ctx context.Context, w io.Writer, query string) (int64, error) {
+	return c.copy(ctx, func(pc *pgconn.PgConn) (pgconn.CommandTag, error) {
+		return pc.CopyTo(ctx, w, query)
+	})
+}
+
+// CopyFrom runs query, a COPY ... FROM STDIN, sending it the data read
+// from r.
+func (c *pgConn) CopyFrom(ctx context.Context, r io.Reader, query string) (int64, error) {
+	return c.copy(ctx, func(pc *pgconn.PgConn) (pgconn.CommandTag, error) {
+		return pc.CopyFrom(ctx, r, query)
+	})
+}
+
+// copy runs a COPY on the session holding the open transaction, or on a
+// connection from the pool.
+func (c *pgConn) copy(ctx context.Context, run func(*pgconn.PgConn) (pgconn.CommandTag, error)) (int64, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	c.setCancel(cancel)
+	defer c.clearCancel()
+
+	c.txMu.Lock()
+	tx := c.tx
+	c.txMu.Unlock()
+	var pc *pgconn.PgConn
+	if tx != nil {
+		pc = tx.Conn().PgConn()
+	} else {
+		conn, err := c.pool.Acquire(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("copy: %w", err)
+		}
+		defer conn.Release()
+		pc = conn.Conn().PgConn()
+	}
+	tag, err := run(pc)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, adapter.ErrCancelled
+		}
+		return 0, fmt.Errorf("copy: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ---------------------------------------------------------------------------
+// Dependencies (implements adapter.DependencyProvider)
+// ---------------------------------------------------------------------------
+
+// dependenciesQuery lists, for the relation $1.$2, the views and
+// materialized views whose rewrite rules read it, the foreign keys
+// referencing it and its triggers, then the relations its rules read, the
+// ones its foreign keys reference and its trigger functions.
+const dependenciesQuery = `
+WITH target AS (
+	SELECT c.oid FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+	WHERE n.nspname = $1 AND c.relname = $2
+), rules AS (
+	SELECT r.ev_class AS reader, d.refobjid AS read
+	FROM pg_rewrite r
+	JOIN pg_depend d ON d.objid = r.oid
+	 AND d.classid = 'pg_rewrite'::regclass AND d.refclassid = 'pg_class'::regclass
+	WHERE r.ev_class <> d.refobjid
+)
+SELECT false, c.relkind::text, n.nspname, c.relname, ''
+FROM rules JOIN pg_class c ON c.oid = rules.reader JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE rules.read IN (SELECT oid FROM target) AND c.relkind IN ('r', 'p', 'f', 'v', 'm')
+UNION
+SELECT false, c.relkind::text, n.nspname, c.relname, pg_get_constraintdef(con.oid)
+FROM pg_constraint con JOIN pg_class c ON c.oid = con.conrelid JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE con.contype = 'f' AND con.confrelid IN (SELECT oid FROM target)
+UNION
+SELECT false, 'trigger', n.nspname, t.tgname, 'runs ' || p.proname || '()'
+FROM pg_trigger t JOIN pg_class c ON c.oid = t.tgrelid JOIN pg_namespace n ON n.oid = c.relnamespace
+JOIN pg_proc p ON p.oid = t.tgfoid
+WHERE t.tgrelid IN (SELECT oid FROM target) AND NOT t.tgisinternal
+UNION
+SELECT true, c.relkind::text, n.nspname, c.relname, ''
+FROM rules JOIN pg_class c ON c.oid = rules.read JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE rules.reader IN (SELECT oid FROM target) AND c.relkind IN ('r', 'p', 'f', 'v', 'm')
+UNION
+SELECT true, c.relkind::text, n.nspname, c.relname, pg_get_constraintdef(con.oid)
+FROM pg_constraint con JOIN pg_class c ON c.oid = con.confrelid JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE con.contype = 'f' AND con.conrelid IN (SELECT oid FROM target)
+UNION
+SELECT true, 'function', n.nspname, p.proname, 'used by trigger ' || t.tgname
+FROM pg_trigger t JOIN pg_proc p ON p.oid = t.tgfoid JOIN pg_namespace n ON n.oid = p.pronamespace
+WHERE t.tgrelid IN (SELECT oid FROM target) AND NOT t.tgisinternal
+ORDER BY 1, 3, 4, 5`
+
+// pgRelationKinds names the relkinds of pg_class a dependency can be.
+var pgRelationKinds = map[string]string{
+	"r": adapter.DependencyTable,
+	"p": adapter.DependencyTable,
+	"f": adapter.DependencyTable,
+	"v": adapter.DependencyView,
+	"m": adapter.DependencyMatView,
+}
+
+// Dependencies lists what depends on a table or view and what it depends
+// on, from pg_depend, pg_constraint and pg_trigger.
+func (c *pgConn) Dependencies(ctx context.Context, db, schemaName, name string) (adapter.Dependencies, error) {
+	var deps adapter.Dependencies
+	if schemaName == "" {
+		schemaName = "public"
+	}
+	rows, err := c.pool.Query(ctx, dependenciesQuery, schemaName, name)
+	if err != nil {
+		return deps, fmt.Errorf("dependencies: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var dependsOn bool
+		var d adapter.Dependency
+		if err := rows.Scan(&dependsOn, &d.Kind, &d.Schema, &d.Name, &d.Detail); err != nil {
+			return deps, fmt.Errorf("dependencies scan: %w", err)
+		}
+		if kind, ok := pgRelationKinds[d.Kind]; ok {
+			d.Kind = kind
+		}
+		if dependsOn {
+			deps.DependsOn = append(deps.DependsOn, d)
+		} else {
+			deps.Dependents = append(deps.Dependents, d)
+		}
+	}
+	return deps, rows.Err()
+}
+
+// ---------------------------------------------------------------------------
+// Activity (implements adapter.ActivityMonitor)
+// ---------------------------------------------------------------------------
+
+// Activity lists the sessions in pg_stat_activity but the one asking,
+// background processes included, which show their type as their state.
+// Duration is the time in the current state: since the query started for
+// an active session.
+func (c *pgConn) Activity(ctx context.Context) ([]adapter.Backend, error) {
+	rows, err := c.pool.Query(ctx,
+		`SELECT pid, coalesce(usename, ''), coalesce(datname, ''), coalesce(client_addr::text, ''),
+		        coalesce(state, backend_type, ''),
+		        coalesce(wait_event_type || ': ' || wait_event, ''),
+		        coalesce(query, ''),
+		        coalesce(extract(epoch FROM now() - CASE WHEN state = 'active' THEN query_start ELSE state_change END), 0)::float8
+		   FROM pg_stat_activity
+		  WHERE pid <> pg_backend_pid()`)
+	if err != nil {
+		return nil, fmt.Errorf("activity: %w", err)
+	}
+	defer rows.Close()
+	var backends []adapter.Backend
+	for rows.Next() {
+		var b adapter.Backend
+		var secs float64
+		if err := rows.Scan(&b.ID, &b.User, &b.Database, &b.Client, &b.State, &b.Wait, &b.Query, &secs); err != nil {
+			return nil, fmt.Errorf("activity: %w", err)
+		}
+		b.Duration = time.Duration(secs * float64(time.Second))
+		backends = append(backends, b)
+	}
+	return backends, rows.Err()
+}
+
+// CancelBackend cancels the query the backend with pid id is running.
+func (c *pgConn) CancelBackend(ctx context.Context, id int64) error {
+	return c.signalBackend(ctx, "pg_cancel_backend", id)
+}
+
+// TerminateBackend ends the session of the backend with pid id.
+func (c *pgConn) TerminateBackend(ctx context.Context, id int64) error {
+	return c.signalBackend(ctx, "pg_terminate_backend", id)
+}
+
+// signalBackend calls fn, pg_cancel_backend or pg_terminate_backend, on
+// pid id. The server refuses when the role may not signal it.
+func (c *pgConn) signalBackend(ctx context.Context, fn string, id int64) error {
+	var ok bool
+	start := time.Now()
+	if err := c.pool.QueryRow(ctx, "SELECT "+fn+"($1::int)", id).Scan(&ok); err != nil {
+		return fmt.Errorf("%s: %w", fn, err)
+	}
+	adapter.Record(ctx, fmt.Sprintf("SELECT %s(%d)", fn, id), start)
+	if !ok {
+		return fmt.Errorf("%s: no server process with pid %d", fn, id)
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// LISTEN/NOTIFY (implements adapter.Notifier)
+// ---------------------------------------------------------------------------
+
+// Listen opens a direct connection (not from the pool) to LISTEN on.
+func (c *pgConn) Listen(ctx context.Context) (adapter.Listener, error) {
+	conn, err := pgx.Connect(ctx, c.dsn)
+	if err != nil {
+		return nil, fmt.Errorf("listen connect: %w", err)
+	}
+	if err := setupSession(ctx, conn, c.init); err != nil {
+		conn.Close(ctx)
+		return nil, err
+	}
+	l := &pgListener{conn: conn}
+	l.cond = sync.NewCond(&l.mu)
+	return l, nil
+}
+
+// Notify sends payload to channel with pg_notify on the pool, so it is
+// delivered at once even while a transaction is open.
+func (c *pgConn) Notify(ctx context.Context, channel, payload string) error {
+	_, err := c.pool.Exec(ctx, "SELECT pg_notify($1, $2)", channel, payload)
+	return err
+}
+
+// pgListener implements adapter.Listener. A pgx.Conn runs one thing at a
+// time, so LISTEN and UNLISTEN interrupt the Wait in progress, which
+// resumes once they are done; notifications that arrive meanwhile are
+// queued by pgx and returned by the next wait.
+type pgListener struct {
+	conn *pgx.Conn
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	busy    bool               // conn is in use
+	pending int                // commands waiting for conn, ahead of Wait
+	wake    context.CancelFunc // interrupts the wait in progress, or nil
+	closed  bool
+}
+
+func (l *pgListener) Listen(ctx context.Context, channel string) error {
+	return l.exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize())
+}
+
+func (l *pgListener) Unlisten(ctx context.Context, channel string) error {
+	return l.exec(ctx, "UNLISTEN "+pgx.Identifier{channel}.Sanitize())
+}
+
+// exec runs sql on the listening connection, interrupting the wait.
+func (l *pgListener) exec(ctx context.Context, sql string) error {
+	if err := l.acquire(nil); err != nil {
+		return err
+	}
+	defer l.release()
+	_, err := l.conn.Exec(ctx, sql)
+	return err
+}
+
+// acquire waits for the connection to be free and takes it. A command
+// interrupts the wait in progress and goes ahead of the next one; a wait
+// passes wake, which interrupts it.
+func (l *pgListener) acquire(wake context.CancelFunc) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	command := wake == nil
+	if command {
+		l.pending++
+		defer func() { l.pending-- }()
+		if l.wake != nil {
+			l.wake()
+		}
+	}
+	for !l.closed && (l.busy || !command && l.pending > 0) {
+		l.cond.Wait()
+	}
+	if l.closed {
+		return adapter.ErrListenerClosed
+	}
+	l.busy = true
+	l.wake = wake
+	return nil
+}
+
+func (l *pgListener) release() {
+	l.mu.Lock()
+	l.busy = false
+	l.wake = nil
+	l.cond.Broadcast()
+	l.mu.Unlock()
+}
+
+func (l *pgListener) Wait(ctx context.Context) (adapter.Notification, error) {
+	for {
+		waitCtx, cancel := context.WithCancel(ctx)
+		if err := l.acquire(cancel); err != nil {
+			cancel()
+			return adapter.Notification{}, err
+		}
+		n, err := l.conn.WaitForNotification(waitCtx)
+		cancel()
+		l.release()
+		if err == nil {
+			return adapter.Notification{Channel: n.Channel, Payload: n.Payload, PID: n.PID, At: time.Now()}, nil
+		}
+		if ctx.Err() != nil || waitCtx.Err() == nil || l.conn.IsClosed() {
+			return adapter.Notification{}, err
+		}
+		// Interrupted to run a command; wait again once it is done.
+	}
+}
+
+// Close stops the wait in progress and closes the connection.
+func (l *pgListener) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	if l.wake != nil {
+		l.wake()
+	}
+	for l.busy {
+		l.cond.Wait()
+	}
+	l.cond.Broadcast()
+	l.mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return l.conn.Close(ctx)
+}
+
 // ---------------------------------------------------------------------------
 // Completions
 // ---------------------------------------------------------------------------
@@ -948,10 +1725,27 @@ func valuesToStrings(vals []any) []string {
 	return out
 }
 
+// rowToStrings converts a row like valuesToStrings, but always includes the
+// time of day for timestamp columns so midnight values line up with the
+// rest of the column instead of collapsing to a bare date.
+func rowToStrings(vals []any, cols []adapter.ColumnMeta) []string {
+	out := valuesToStrings(vals)
+	for i, v := range vals {
+		t, ok := v.(time.Time)
+		if !ok || i >= len(cols) {
+			continue
+		}
+		if cols[i].Type == "timestamp" || cols[i].Type == "timestamptz" {
+			out[i] = t.Format("2006-01-02 15:04:05")
+		}
+	}
+	return out
+}
+
 // valueToString converts a single database value to a string representation.
 func valueToString(v any) string {
 	if v == nil {
-		return ""
+		return adapter.NullValue
 	}
 	switch val := v.(type) {
 	case string:
@@ -1022,6 +1816,12 @@ func valueToString(v any) string {
 			return s
 		}
 		return fmt.Sprintf("%v", dv)
+	case map[string]any, []any:
+		// json and jsonb, decoded by pgx; shown as JSON again.
+		if b, err := json.Marshal(val); err == nil {
+			return string(b)
+		}
+		return fmt.Sprintf("%v", v)
 	default:
 		return fmt.Sprintf("%v", v)
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -462,3 +463,119 @@ func TestIntegration_ErrorHandling(t *testing.T) {
 		t.Error("expected error for syntax error, got nil")
 	}
 }
+
+func TestIntegration_ListenNotify(t *testing.T) {
+	conn := connectForTest(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	notifier := conn.(adapter.Notifier)
+	l, err := notifier.Listen(ctx)
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer l.Close()
+
+	// LISTEN while a wait is in progress interrupts it.
+	got := make(chan adapter.Notification, 1)
+	go func() {
+		n, err := l.Wait(ctx)
+		if err != nil {
+			t.Errorf("Wait failed: %v", err)
+		}
+		got <- n
+	}()
+	if err := l.Listen(ctx, "Test Channel"); err != nil {
+		t.Fatalf("LISTEN failed: %v", err)
+	}
+	if err := notifier.Notify(ctx, "Test Channel", "hello"); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	n := <-got
+	if n.Channel != "Test Channel" || n.Payload != "hello" {
+		t.Errorf("got %+v, want hello on Test Channel", n)
+	}
+
+	l.Close()
+	if _, err := l.Wait(ctx); err != adapter.ErrListenerClosed {
+		t.Errorf("Wait after Close = %v, want ErrListenerClosed", err)
+	}
+}
+
+func TestIntegration_Copy(t *testing.T) {
+	conn := connectForTest(t)
+	ctx := context.Background()
+	copier := conn.(adapter.Copier)
+
+	conn.Execute(ctx, "DROP TABLE IF EXISTS test_copy")
+	if _, err := conn.Execute(ctx, "CREATE TABLE test_copy (id int, name text)"); err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+	t.Cleanup(func() { conn.Execute(ctx, "DROP TABLE IF EXISTS test_copy") })
+
+	n, err := copier.CopyFrom(ctx, strings.NewReader("1,alice\n2,bob\n"), "COPY test_copy FROM STDIN (FORMAT csv)")
+	if err != nil || n != 2 {
+		t.Fatalf("CopyFrom = %d, %v; want 2 rows", n, err)
+	}
+	var out strings.Builder
+	n, err = copier.CopyTo(ctx, &out, "COPY (SELECT * FROM test_copy ORDER BY id) TO STDOUT (FORMAT csv)")
+	if err != nil || n != 2 || out.String() != "1,alice\n2,bob\n" {
+		t.Errorf("CopyTo = %d, %q, %v; want the rows back", n, out.String(), err)
+	}
+}
+
+func TestIntegration_Activity(t *testing.T) {
+	conn := connectForTest(t)
+	ctx := context.Background()
+	am := conn.(adapter.ActivityMonitor)
+
+	backends, err := am.Activity(ctx)
+	if err != nil {
+		t.Fatalf("Activity failed: %v", err)
+	}
+	if len(backends) == 0 {
+		t.Error("want the server's background processes at least")
+	}
+	if err := am.CancelBackend(ctx, 0); err == nil {
+		t.Error("cancelling pid 0 should fail")
+	}
+}
+
+func TestIntegration_Dependencies(t *testing.T) {
+	conn := connectForTest(t)
+	ctx := context.Background()
+	dp := conn.(adapter.DependencyProvider)
+
+	drop := "DROP TABLE IF EXISTS test_dep_child, test_dep_parent CASCADE"
+	conn.Execute(ctx, drop)
+	for _, stmt := range []string{
+		"CREATE TABLE test_dep_parent (id int PRIMARY KEY)",
+		"CREATE TABLE test_dep_child (id int, parent_id int REFERENCES test_dep_parent(id))",
+		"CREATE VIEW test_dep_view AS SELECT p.id FROM test_dep_parent p JOIN test_dep_child c ON c.parent_id = p.id",
+	} {
+		if _, err := conn.Execute(ctx, stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	t.Cleanup(func() { conn.Execute(ctx, drop) })
+
+	deps, err := dp.Dependencies(ctx, "", "public", "test_dep_parent")
+	if err != nil {
+		t.Fatalf("Dependencies failed: %v", err)
+	}
+	var names []string
+	for _, d := range deps.Dependents {
+		names = append(names, d.Kind+" "+d.Name)
+	}
+	if got := strings.Join(names, ", "); got != "table test_dep_child, view test_dep_view" {
+		t.Errorf("dependents = %s, want the child table and the view", got)
+	}
+
+	deps, err = dp.Dependencies(ctx, "", "public", "test_dep_view")
+	if err != nil {
+		t.Fatalf("Dependencies failed: %v", err)
+	}
+	if len(deps.DependsOn) != 2 {
+		t.Errorf("the view depends on %+v, want both tables", deps.DependsOn)
+	}
+}
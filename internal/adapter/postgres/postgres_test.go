@@ -133,7 +133,7 @@ func TestValueToString(t *testing.T) {
 		value any
 		want  string
 	}{
-		{"nil", nil, ""},
+		{"nil", nil, adapter.NullValue},
 		{"string", "hello", "hello"},
 		{"empty string", "", ""},
 		{"bytes", []byte("world"), "world"},
@@ -160,6 +160,8 @@ func TestValueToString(t *testing.T) {
 			0x12, 0x34,
 			0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0,
 		}, "12345678-9abc-def0-1234-56789abcdef0"},
+		{"json object", map[string]any{"a": []any{1.0, "b"}}, `{"a":[1,"b"]}`},
+		{"json array", []any{map[string]any{"Plan": nil}}, `[{"Plan":null}]`},
 		{"unknown type (int)", 42, "42"},
 	}
 
@@ -176,7 +178,7 @@ func TestValueToString(t *testing.T) {
 func TestValuesToStrings(t *testing.T) {
 	input := []any{"hello", int32(42), nil, true}
 	got := valuesToStrings(input)
-	want := []string{"hello", "42", "", "true"}
+	want := []string{"hello", "42", adapter.NullValue, "true"}
 
 	if len(got) != len(want) {
 		t.Fatalf("valuesToStrings() returned %d elements, want %d", len(got), len(want))
@@ -239,3 +241,49 @@ func TestPgTypeOIDToName(t *testing.T) {
 		})
 	}
 }
+
+func TestRowToStrings_TimestampKeepsTime(t *testing.T) {
+	midnight := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	cols := []adapter.ColumnMeta{{Type: "timestamp"}, {Type: "date"}, {Type: "timestamptz"}}
+	got := rowToStrings([]any{midnight, midnight, nil}, cols)
+	want := []string{"2024-03-01 00:00:00", "2024-03-01", adapter.NullValue}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("rowToStrings()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestPgCreateTable(t *testing.T) {
+	def := "nextval('users_id_seq'::regclass)"
+	defs := []string{
+		pgColumnDef("id", "integer", true, &def),
+		pgColumnDef("Name", "text", false, nil),
+		"CONSTRAINT users_pkey PRIMARY KEY (id)",
+	}
+	got := pgCreateTable("public.users", defs, []string{"CREATE INDEX users_name ON public.users USING btree (\"Name\")"})
+	want := `CREATE TABLE public.users (
+    "id" integer DEFAULT nextval('users_id_seq'::regclass) NOT NULL,
+    "Name" text,
+    CONSTRAINT users_pkey PRIMARY KEY (id)
+);
+
+CREATE INDEX users_name ON public.users USING btree ("Name");`
+	if got != want {
+		t.Errorf("pgCreateTable =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestValidateDSN(t *testing.T) {
+	a := &postgresAdapter{}
+	for _, dsn := range []string{"postgres://app@db:5432/shop?sslmode=disable", "host=db user=app dbname=shop"} {
+		if err := a.ValidateDSN(dsn); err != nil {
+			t.Errorf("ValidateDSN(%q) = %v, want nil", dsn, err)
+		}
+	}
+	for _, dsn := range []string{"postgres://app@db:port/shop", "host=db sslmode=bogus"} {
+		if err := a.ValidateDSN(dsn); err == nil {
+			t.Errorf("ValidateDSN(%q) = nil, want an error", dsn)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package adapter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+// ColumnProfile summarizes the values of one column of a sample of rows.
+type ColumnProfile struct {
+	Name     string
+	Type     string
+	Nulls    int64
+	Distinct int64
+	Min, Max string // "" when every value is NULL
+	// AvgLength is the mean length of the values as text, or -1 for
+	// numbers and times, whose length says little.
+	AvgLength float64
+	Top       []ValueCount // the most frequent values, most frequent first
+}
+
+// ValueCount is a value and how many rows of a sample hold it.
+type ValueCount struct {
+	Value string // NullValue for NULL
+	Count int64
+}
+
+// TableProfile summarizes a sample of the rows of a table, column by
+// column.
+type TableProfile struct {
+	Rows    int64 // the rows profiled
+	Columns []ColumnProfile
+}
+
+// orderedTypes are the types whose values are compared as themselves
+// rather than as text: numbers and times.
+var orderedTypes = map[string]bool{
+	"tinyint": true, "smallint": true, "mediumint": true, "int": true, "integer": true, "bigint": true,
+	"int2": true, "int4": true, "int8": true, "hugeint": true, "utinyint": true, "usmallint": true,
+	"uinteger": true, "ubigint": true, "serial": true, "smallserial": true, "bigserial": true,
+	"decimal": true, "numeric": true, "real": true, "double": true, "float": true, "float4": true,
+	"float8": true, "money": true, "date": true, "time": true, "timetz": true, "timestamp": true,
+	"timestamptz": true, "datetime": true, "year": true, "interval": true,
+}
+
+// ordered reports whether a column type is a number or a time.
+func ordered(typ string) bool {
+	name, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(typ)), "(")
+	if fields := strings.Fields(name); len(fields) > 0 {
+		return orderedTypes[fields[0]]
+	}
+	return false
+}
+
+// castText casts a SQL expression to text in the given dialect.
+func castText(dialect, expr string) string {
+	switch dialect {
+	case "duckdb":
+		return "CAST(" + expr + " AS VARCHAR)"
+	case "mysql":
+		return "CAST(" + expr + " AS CHAR)"
+	}
+	return "CAST(" + expr + " AS TEXT)"
+}
+
+// profileExpr returns the expression a column is profiled by: the column
+// itself for numbers and times, and its text otherwise, which every type
+// can be counted, compared and measured by (PostgreSQL cannot compare
+// json or boolean values with MIN).
+func profileExpr(dialect string, c schema.Column) string {
+	col := QuoteIdentifier(dialect, c.Name)
+	if ordered(c.Type) {
+		return col
+	}
+	return castText(dialect, col)
+}
+
+// profileSample returns the FROM clause of the first sample rows of
+// table, an identifier quoted for the dialect.
+func profileSample(table string, sample int) string {
+	return fmt.Sprintf("FROM (SELECT * FROM %s LIMIT %d) AS gotermsql_sample", table, sample)
+}
+
+// ProfileQuery returns the query profiling the columns of the first
+// sample rows of table, quoted for the dialect: a single row holding the
+// number of rows, then for each column its values, its distinct values,
+// its least and greatest value and their mean length.
+func ProfileQuery(dialect, table string, columns []schema.Column, sample int) string {
+	exprs := []string{"COUNT(*)"}
+	for _, c := range columns {
+		e := profileExpr(dialect, c)
+		length := "NULL"
+		if !ordered(c.Type) {
+			fn := "LENGTH"
+			if dialect == "mysql" {
+				fn = "CHAR_LENGTH" // LENGTH counts bytes
+			}
+			length = "AVG(" + fn + "(" + e + "))"
+		}
+		exprs = append(exprs, "COUNT("+e+")", "COUNT(DISTINCT "+e+")", "MIN("+e+")", "MAX("+e+")", length)
+	}
+	return "SELECT " + strings.Join(exprs, ", ") + " " + profileSample(table, sample)
+}
+
+// TopValuesQuery returns the query listing the n values of column most
+// frequent in the first sample rows of table, with their counts.
+func TopValuesQuery(dialect, table string, column schema.Column, sample, n int) string {
+	return fmt.Sprintf("SELECT %s, COUNT(*) %s GROUP BY 1 ORDER BY 2 DESC, 1 LIMIT %d",
+		profileExpr(dialect, column), profileSample(table, sample), n)
+}
+
+// ParseProfile reads the row returned by ProfileQuery for columns.
+func ParseProfile(columns []schema.Column, row []string) (TableProfile, error) {
+	if len(row) != 1+5*len(columns) {
+		return TableProfile{}, fmt.Errorf("profile: %d values, want %d", len(row), 1+5*len(columns))
+	}
+	number := func(s string) int64 {
+		n, _ := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+		return n
+	}
+	text := func(s string) string {
+		if IsNull(s) {
+			return ""
+		}
+		return s
+	}
+	p := TableProfile{Rows: number(row[0])}
+	for i, c := range columns {
+		v := row[1+5*i:]
+		cp := ColumnProfile{
+			Name:      c.Name,
+			Type:      c.Type,
+			Nulls:     p.Rows - number(v[0]),
+			Distinct:  number(v[1]),
+			Min:       text(v[2]),
+			Max:       text(v[3]),
+			AvgLength: -1,
+		}
+		if avg, err := strconv.ParseFloat(strings.TrimSpace(v[4]), 64); err == nil {
+			cp.AvgLength = avg
+		} else if !ordered(c.Type) {
+			cp.AvgLength = 0 // every value is NULL
+		}
+		p.Columns = append(p.Columns, cp)
+	}
+	return p, nil
+}
+
+// ParseTopValues reads the rows returned by TopValuesQuery.
+func ParseTopValues(rows [][]string) []ValueCount {
+	var top []ValueCount
+	for _, r := range rows {
+		if len(r) < 2 {
+			continue
+		}
+		n, _ := strconv.ParseInt(strings.TrimSpace(r[1]), 10, 64)
+		top = append(top, ValueCount{Value: r[0], Count: n})
+	}
+	return top
+}
@@ -0,0 +1,255 @@
+package adapter
+
+import "strings"
+
+// readStatements are the statements IsReadOnlyQuery lets through, by first
+// keyword.
+var readStatements = map[string]bool{
+	"SELECT": true, "WITH": true, "EXPLAIN": true, "SHOW": true,
+	"DESCRIBE": true, "DESC": true, "TABLE": true, "VALUES": true,
+	"FROM": true, "SUMMARIZE": true, "PIVOT": true, "UNPIVOT": true,
+	"PRAGMA": true,
+}
+
+// writeKeywords make a statement a write wherever they appear: in a CTE
+// (WITH d AS (DELETE ... RETURNING *)), after EXPLAIN ANALYZE, as SELECT
+// INTO, or as a row lock (FOR UPDATE).
+var writeKeywords = map[string]bool{
+	"INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true,
+	"UPSERT": true, "INTO": true, "CREATE": true, "DROP": true,
+	"ALTER": true, "TRUNCATE": true, "RENAME": true, "GRANT": true,
+	"REVOKE": true, "COPY": true, "CALL": true, "EXEC": true,
+	"EXECUTE": true, "DO": true, "LOCK": true, "VACUUM": true,
+	"ATTACH": true, "DETACH": true, "INSTALL": true, "LOAD": true,
+	"CHECKPOINT": true, "REINDEX": true, "REFRESH": true,
+}
+
+// IsReadOnlyQuery reports whether every statement in query only reads: it
+// starts with SELECT, WITH, EXPLAIN, SHOW or another reading statement and
+// has no keyword that writes anywhere in it. PRAGMA is allowed only
+// without an assignment, and called with an argument only when it reads.
+// It errs towards calling a query a write, and it cannot see writes made
+// by functions a SELECT calls, such as nextval.
+//
+// Dialects disagree on where strings and comments end, so the query is
+// read the way each one would and must be read-only every time.
+func IsReadOnlyQuery(query string) bool {
+	for _, l := range lexers {
+		if !readOnly(l.tokens(query)) {
+			return false
+		}
+	}
+	return true
+}
+
+// lexer holds the ways dialects differ in splitting a query into words.
+type lexer struct {
+	backslash    bool // \ escapes in '...' and "..." strings
+	mysql        bool // # comments, "-- " needs a space, /*! ... */ is run
+	dollar       bool // $tag$...$tag$ strings
+	nestComments bool // /* /* */ */ is one comment
+	escapeString bool // \ escapes in E'...' strings
+}
+
+// lexers are the readings IsReadOnlyQuery checks: SQLite and standard SQL,
+// MySQL, PostgreSQL, and DuckDB.
+var lexers = []lexer{
+	{},
+	{backslash: true, mysql: true},
+	{dollar: true, nestComments: true, escapeString: true},
+	{dollar: true},
+}
+
+// readPragmas are the PRAGMAs that only read when given an argument, as
+// PRAGMA table_info(t). Any other called with one sets what it names.
+var readPragmas = map[string]bool{
+	"TABLE_INFO": true, "TABLE_XINFO": true, "TABLE_LIST": true,
+	"INDEX_INFO": true, "INDEX_XINFO": true, "INDEX_LIST": true,
+	"FOREIGN_KEY_LIST": true, "FOREIGN_KEY_CHECK": true,
+	"INTEGRITY_CHECK": true, "QUICK_CHECK": true,
+	"SHOW": true, "STORAGE_INFO": true,
+}
+
+// readOnly checks the tokens of a query, split into statements at ";".
+func readOnly(tokens []token) bool {
+	start := true
+	first, last := "", ""
+	for _, t := range tokens {
+		switch {
+		case t.text == ";":
+			start = true
+			continue
+		case t.kind != tokWord && t.text != "=" && t.text != "(":
+			continue
+		case start && t.text == "(":
+			continue // (SELECT ...) UNION ...
+		}
+		if start {
+			if !readStatements[t.text] {
+				return false
+			}
+			first = t.text
+			start = false
+			continue
+		}
+		if writeKeywords[t.text] {
+			return false
+		}
+		if first == "PRAGMA" && (t.text == "=" || t.text == "(" && !readPragmas[last]) {
+			return false
+		}
+		last = t.text
+	}
+	return true
+}
+
+// words returns the upper-cased keywords and identifiers of query, with
+// ";" and "=" as words of their own, skipping comments, strings and
+// quoted identifiers.
+func (l lexer) words(query string) []string {
+	var words []string
+	for _, t := range l.tokens(query) {
+		if t.kind == tokWord || t.text == ";" || t.text == "=" {
+			words = append(words, t.text)
+		}
+	}
+	return words
+}
+
+// token is a piece of a query as a lexer reads it.
+type token struct {
+	kind tokenKind
+	text string
+	raw  string // text as written
+	pos  int    // byte offset of raw in the query
+}
+
+type tokenKind int
+
+const (
+	tokWord   tokenKind = iota // keyword, identifier or number, upper-cased
+	tokQuoted                  // string or quoted identifier, as written
+	tokPunct                   // any other character
+)
+
+// tokens splits query into tokens, skipping comments and white space.
+func (l lexer) tokens(query string) []token {
+	var tokens []token
+	q := query
+	for i := 0; i < len(q); {
+		c := q[i]
+		switch {
+		case c == '-' && strings.HasPrefix(q[i:], "--") && (!l.mysql || i+2 == len(q) || isSpace(q[i+2])),
+			c == '#' && l.mysql:
+			end := strings.IndexByte(q[i:], '\n')
+			if end < 0 {
+				return tokens
+			}
+			i += end + 1
+		case c == '/' && strings.HasPrefix(q[i:], "/*!") && l.mysql:
+			// MySQL runs the body of /*! ... */; read it as code.
+			i += 3
+		case c == '/' && strings.HasPrefix(q[i:], "/*"):
+			i = l.skipComment(q, i)
+		case c == '\'' || c == '"' || c == '`':
+			j := l.skipQuoted(q, i)
+			tokens = append(tokens, token{tokQuoted, q[i:j], q[i:j], i})
+			i = j
+		case (c == 'E' || c == 'e') && l.escapeString && strings.HasPrefix(q[i+1:], "'"):
+			// The E is left out of the token, so a masked E'...' stays
+			// a string.
+			j := lexer{backslash: true}.skipQuoted(q, i+1)
+			tokens = append(tokens, token{tokQuoted, q[i+1 : j], q[i+1 : j], i + 1})
+			i = j
+		case c == '$' && l.dollar:
+			if tag, ok := dollarTag(q[i:]); ok {
+				end := strings.Index(q[i+len(tag):], tag)
+				if end < 0 {
+					return tokens
+				}
+				j := i + len(tag) + end + len(tag)
+				tokens = append(tokens, token{tokQuoted, q[i:j], q[i:j], i})
+				i = j
+			} else {
+				tokens = append(tokens, token{tokPunct, "$", "$", i})
+				i++
+			}
+		case isWordByte(c):
+			j := i
+			// PostgreSQL allows $ in identifiers, as in a$b$, which is not
+			// a dollar quote.
+			for j < len(q) && (isWordByte(q[j]) || q[j] == '$' && l.dollar) {
+				j++
+			}
+			tokens = append(tokens, token{tokWord, strings.ToUpper(q[i:j]), q[i:j], i})
+			i = j
+		case isSpace(c):
+			i++
+		default:
+			tokens = append(tokens, token{tokPunct, string(c), string(c), i})
+			i++
+		}
+	}
+	return tokens
+}
+
+// skipComment returns the index just past the block comment starting at
+// q[i].
+func (l lexer) skipComment(q string, i int) int {
+	depth := 0
+	for j := i; j < len(q)-1; j++ {
+		switch {
+		case q[j] == '/' && q[j+1] == '*' && (depth == 0 || l.nestComments):
+			depth++
+			j++
+		case q[j] == '*' && q[j+1] == '/':
+			depth--
+			j++
+			if depth == 0 {
+				return j + 1
+			}
+		}
+	}
+	return len(q)
+}
+
+// skipQuoted returns the index just past the quoted string or identifier
+// starting at q[i]. A doubled quote is an escaped quote.
+func (l lexer) skipQuoted(q string, i int) int {
+	quote := q[i]
+	for j := i + 1; j < len(q); j++ {
+		switch {
+		case l.backslash && quote != '`' && q[j] == '\\':
+			j++
+		case q[j] == quote:
+			if j+1 < len(q) && q[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(q)
+}
+
+// dollarTag returns the $tag$ opening a dollar-quoted string at the start
+// of s, if there is one.
+func dollarTag(s string) (string, bool) {
+	for j := 1; j < len(s); j++ {
+		switch {
+		case s[j] == '$':
+			return s[:j+1], true
+		case !isWordByte(s[j]) || (j == 1 && s[j] >= '0' && s[j] <= '9'):
+			return "", false // $1 is a parameter
+		}
+	}
+	return "", false
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,27 @@
+package adapter
+
+import (
+	"context"
+	"time"
+)
+
+// Recorder is told of each statement an optional interface runs on its
+// own once it has run, such as the VACUUM of a Maintainer or the KILL of
+// an ActivityMonitor, so the app can add it to the history and the audit
+// log as it does the statements it runs through Execute.
+type Recorder func(query string, duration time.Duration)
+
+type recorderKey struct{}
+
+// WithRecorder returns ctx carrying r.
+func WithRecorder(ctx context.Context, r Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// Record tells the Recorder ctx carries, if any, that query ran from start
+// until now.
+func Record(ctx context.Context, query string, start time.Time) {
+	if r, _ := ctx.Value(recorderKey{}).(Recorder); r != nil {
+		r(query, time.Since(start))
+	}
+}
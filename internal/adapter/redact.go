@@ -0,0 +1,263 @@
+package adapter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// redacted replaces a masked literal.
+const redacted = "'***'"
+
+// Redactor masks secrets in queries before they are kept anywhere, such
+// as the history and the audit log. A nil *Redactor leaves queries alone.
+type Redactor struct {
+	columns []*regexp.Regexp // column names whose values are secret
+	values  []*regexp.Regexp // literal values that are secret wherever they appear
+}
+
+// NewRedactor compiles the column name patterns (matched case-insensitively,
+// so "password" also covers user_password) and the value patterns. It
+// returns nil if both are empty.
+func NewRedactor(columns, values []string) (*Redactor, error) {
+	if len(columns) == 0 && len(values) == 0 {
+		return nil, nil
+	}
+	r := &Redactor{}
+	for _, p := range columns {
+		re, err := regexp.Compile("(?i)" + p)
+		if err != nil {
+			return nil, fmt.Errorf("redact column pattern %q: %w", p, err)
+		}
+		r.columns = append(r.columns, re)
+	}
+	for _, p := range values {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("redact value pattern %q: %w", p, err)
+		}
+		r.values = append(r.values, re)
+	}
+	return r, nil
+}
+
+// Redact returns query, read the way dialect reads it, with these literals
+// replaced by '***':
+//   - literals matching a value pattern;
+//   - literals compared with or assigned to a matching column
+//     (password = '...', ssn IN (...), SET token = crypt('...', ...)),
+//     anywhere in the expression, or written right after it
+//     (PASSWORD '...');
+//   - the values an INSERT gives a matching column;
+//   - the password of IDENTIFIED [WITH plugin] BY '...' and of
+//     SET PASSWORD [FOR user] = '...'.
+func (r *Redactor) Redact(dialect, query string) string {
+	if r == nil {
+		return query
+	}
+	l := lexerFor(dialect)
+	toks := l.tokens(query)
+	mask := make([]bool, len(toks))
+	for i, t := range toks {
+		switch {
+		case l.literal(t):
+			if r.secretValue(literalValue(t)) {
+				mask[i] = true
+			}
+		case t.kind == tokWord && t.text == "IDENTIFIED":
+			l.maskAfter(toks, i, mask, "BY", "AS")
+		case t.kind == tokWord && t.text == "SET" && i+1 < len(toks) && toks[i+1].text == "PASSWORD":
+			l.maskAfter(toks, i, mask, "=")
+		case t.kind == tokWord && t.text == "INSERT":
+			r.maskInserted(l, toks, i, mask)
+		case r.secretColumn(toks, i):
+			l.maskCompared(toks, i, mask)
+		}
+	}
+
+	var b strings.Builder
+	end := 0
+	for i, t := range toks {
+		if mask[i] {
+			b.WriteString(query[end:t.pos])
+			b.WriteString(redacted)
+			end = t.pos + len(t.raw)
+		}
+	}
+	if end == 0 {
+		return query
+	}
+	b.WriteString(query[end:])
+	return b.String()
+}
+
+// literal reports whether t is a string or number.
+func (l lexer) literal(t token) bool {
+	switch t.kind {
+	case tokQuoted:
+		// "..." is a string only in MySQL.
+		return t.raw[0] == '\'' || t.raw[0] == '$' || (t.raw[0] == '"' && l.mysql)
+	case tokWord:
+		return isDigit(t.text[0])
+	}
+	return false
+}
+
+// literalValue returns the value t spells, without its quotes.
+func literalValue(t token) string {
+	if t.kind != tokQuoted || len(t.raw) < 2 {
+		return t.raw
+	}
+	if t.raw[0] == '$' {
+		tag, _ := dollarTag(t.raw)
+		return strings.TrimSuffix(strings.TrimPrefix(t.raw, tag), tag)
+	}
+	return t.raw[1 : len(t.raw)-1]
+}
+
+func (r *Redactor) secretValue(v string) bool {
+	for _, re := range r.values {
+		if re.MatchString(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// secretColumn reports whether toks[i] names a column matching a column
+// pattern: an identifier that is not followed by a dot, so that in
+// secrets.note the table name does not count.
+func (r *Redactor) secretColumn(toks []token, i int) bool {
+	name, ok := identifier(toks[i])
+	if !ok || (i+1 < len(toks) && toks[i+1].text == ".") {
+		return false
+	}
+	for _, re := range r.columns {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareWords may stand between a column and the value it is compared
+// with.
+var compareWords = map[string]bool{
+	"=": true, "<": true, ">": true, "!": true, "NOT": true, "LIKE": true,
+	"ILIKE": true, "IN": true, "IS": true, "DISTINCT": true, "FROM": true,
+}
+
+// exprEnds end the expression a value is compared with or assigned to,
+// outside parentheses.
+var exprEnds = map[string]bool{
+	",": true, ";": true, ")": true, "WHERE": true, "AND": true, "OR": true,
+	"FROM": true, "ORDER": true, "GROUP": true, "HAVING": true, "LIMIT": true,
+	"RETURNING": true, "UNION": true, "WHEN": true, "THEN": true,
+	"ELSE": true, "END": true, "ON": true,
+}
+
+// maskCompared masks the literals compared with or assigned to the column
+// at toks[col], on either side.
+func (l lexer) maskCompared(toks []token, col int, mask []bool) {
+	if col >= 2 && toks[col-1].text == "=" && l.literal(toks[col-2]) {
+		mask[col-2] = true
+	}
+	j := col + 1
+	for j < len(toks) && compareWords[toks[j].text] && toks[j].kind != tokQuoted {
+		j++
+	}
+	if j == len(toks) {
+		return
+	}
+	if j == col+1 {
+		// PASSWORD '...': only the value written right after it.
+		mask[j] = mask[j] || l.literal(toks[j])
+		return
+	}
+	l.maskExpression(toks, j, mask)
+}
+
+// maskAfter masks the expression after the first of words that follows
+// toks[i] in the same statement and list item, as the BY of IDENTIFIED
+// WITH plugin BY '...'.
+func (l lexer) maskAfter(toks []token, i int, mask []bool, words ...string) {
+	for j := i + 1; j < len(toks) && toks[j].text != ";" && toks[j].text != ","; j++ {
+		for _, w := range words {
+			if toks[j].text == w && toks[j].kind != tokQuoted {
+				l.maskExpression(toks, j+1, mask)
+				return
+			}
+		}
+	}
+}
+
+// maskExpression masks every literal of the expression starting at
+// toks[j], as 'a' || 'b' or crypt('...', gen_salt('bf')), up to the first
+// of exprEnds outside its parentheses.
+func (l lexer) maskExpression(toks []token, j int, mask []bool) {
+	for depth := 0; j < len(toks); j++ {
+		t := toks[j]
+		if depth == 0 && t.kind != tokQuoted && exprEnds[t.text] {
+			return
+		}
+		switch t.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		}
+		mask[j] = mask[j] || l.literal(t)
+	}
+}
+
+// maskInserted masks the values of secret columns in the INSERT starting
+// at toks[i], for INSERT INTO t (a, b) VALUES (1, 2), (3, 4).
+func (r *Redactor) maskInserted(l lexer, toks []token, i int, mask []bool) {
+	// The column list: the first parenthesis before VALUES.
+	j := i + 1
+	for j < len(toks) && toks[j].text != "(" && toks[j].text != "VALUES" && toks[j].text != ";" {
+		j++
+	}
+	if j == len(toks) || toks[j].text != "(" {
+		return
+	}
+	secret := map[int]bool{}
+	n := 0
+	for j++; j < len(toks) && toks[j].text != ")"; j++ {
+		switch {
+		case toks[j].text == ",":
+			n++
+		case r.secretColumn(toks, j):
+			secret[n] = true
+		}
+	}
+	if len(secret) == 0 || j+1 >= len(toks) || toks[j+1].text != "VALUES" {
+		return
+	}
+
+	// Each row of values.
+	for j += 2; j < len(toks) && toks[j].text == "("; j++ {
+		depth, n := 0, 0
+		for ; j < len(toks); j++ {
+			switch toks[j].text {
+			case "(":
+				depth++
+			case ")":
+				depth--
+			case ",":
+				if depth == 1 {
+					n++
+				}
+			}
+			if depth == 0 {
+				break
+			}
+			mask[j] = mask[j] || (secret[n] && l.literal(toks[j]))
+		}
+		// Past the row's ")" to the "," before the next one.
+		if j+1 >= len(toks) || toks[j+1].text != "," {
+			return
+		}
+		j++
+	}
+}
@@ -0,0 +1,147 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Session holds settings applied to every session of a connection.
+type Session struct {
+	ReadOnly         bool
+	StatementTimeout time.Duration
+	Schema           string // default schema; a comma-separated search_path on PostgreSQL
+}
+
+// SessionSQL returns the statements that apply s to a session of the given
+// adapter dialect. Settings a database has no session statement for, such
+// as a statement timeout on SQLite, are left out.
+func SessionSQL(dialect string, s Session) []string {
+	var stmts []string
+	ms := s.StatementTimeout.Milliseconds()
+	switch dialect {
+	case "postgres":
+		if s.Schema != "" {
+			var names []string
+			for _, name := range strings.Split(s.Schema, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					names = append(names, QuoteIdentifier(dialect, name))
+				}
+			}
+			stmts = append(stmts, "SET search_path TO "+strings.Join(names, ", "))
+		}
+		if ms > 0 {
+			stmts = append(stmts, fmt.Sprintf("SET statement_timeout = %d", ms))
+		}
+		if s.ReadOnly {
+			stmts = append(stmts, "SET default_transaction_read_only = on")
+		}
+	case "mysql":
+		if s.Schema != "" {
+			stmts = append(stmts, "USE "+QuoteIdentifier(dialect, s.Schema))
+		}
+		if ms > 0 {
+			// Only SELECT statements are subject to max_execution_time.
+			stmts = append(stmts, fmt.Sprintf("SET SESSION max_execution_time = %d", ms))
+		}
+		if s.ReadOnly {
+			stmts = append(stmts, "SET SESSION TRANSACTION READ ONLY")
+		}
+	case "sqlite":
+		if s.ReadOnly {
+			stmts = append(stmts, "PRAGMA query_only = ON")
+		}
+	case "duckdb":
+		if s.Schema != "" {
+			stmts = append(stmts, "SET schema = "+QuoteLiteral(dialect, s.Schema))
+		}
+	}
+	return stmts
+}
+
+// SessionConnector is an optional interface that adapters can implement to
+// run statements on every session of a connection's pool as it is opened,
+// not just on the one that happens to run them. Without it the statements
+// are run once after connecting.
+type SessionConnector interface {
+	ConnectSession(ctx context.Context, dsn string, init []string) (Connection, error)
+}
+
+// OpenDB is sql.Open for a pool whose connections each run the init
+// statements before first use.
+func OpenDB(driverName, dsn string, init []string) (*sql.DB, error) {
+	if len(init) == 0 {
+		return sql.Open(driverName, dsn)
+	}
+	return OpenDBFunc(driverName, dsn, func() []string { return init })
+}
+
+// OpenDBFunc is OpenDB with the statements asked of init as each connection
+// opens, for a pool whose session setup changes while it is open.
+func OpenDBFunc(driverName, dsn string, init func() []string) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	drv := db.Driver()
+	_ = db.Close()
+
+	c := &initConnector{drv: drv, dsn: dsn, init: init}
+	if dc, ok := drv.(driver.DriverContext); ok {
+		if c.connector, err = dc.OpenConnector(dsn); err != nil {
+			return nil, err
+		}
+	}
+	return sql.OpenDB(c), nil
+}
+
+// initConnector opens driver connections and runs init on each.
+type initConnector struct {
+	drv       driver.Driver
+	connector driver.Connector // nil when drv opens connections itself
+	dsn       string
+	init      func() []string
+}
+
+func (c *initConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	var conn driver.Conn
+	var err error
+	if c.connector != nil {
+		conn, err = c.connector.Connect(ctx)
+	} else {
+		conn, err = c.drv.Open(c.dsn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, stmt := range c.init() {
+		if err := execDriver(ctx, conn, stmt); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("session setup %q: %w", stmt, err)
+		}
+	}
+	return conn, nil
+}
+
+func (c *initConnector) Driver() driver.Driver { return c.drv }
+
+// execDriver runs a statement on a driver connection.
+func execDriver(ctx context.Context, conn driver.Conn, stmt string) error {
+	if ex, ok := conn.(driver.ExecerContext); ok {
+		_, err := ex.ExecContext(ctx, stmt, nil)
+		if !errors.Is(err, driver.ErrSkip) {
+			return err
+		}
+	}
+	st, err := conn.Prepare(stmt)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	_, err = st.Exec(nil) //nolint:staticcheck // the fallback for drivers without ExecerContext
+	return err
+}
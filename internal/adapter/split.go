@@ -0,0 +1,169 @@
+package adapter
+
+import "strings"
+
+// Statement is one statement of a query, as SplitStatements finds it.
+type Statement struct {
+	Text  string // without the delimiter and surrounding white space
+	Start int    // byte offset of Text in the query
+	End   int    // byte offset just past Text
+}
+
+// Line returns the one-based line of query that s starts on.
+func (s Statement) Line(query string) int {
+	return strings.Count(query[:s.Start], "\n") + 1
+}
+
+// Keyword returns the first keyword of s, upper-cased, read the way
+// dialect reads it.
+func (s Statement) Keyword(dialect string) string {
+	for _, w := range lexerFor(dialect).words(s.Text) {
+		if w != ";" && w != "=" {
+			return w
+		}
+	}
+	return ""
+}
+
+// routineWords, after CREATE, start a statement whose body may hold
+// BEGIN ... END blocks with statements of their own, as SQLite triggers and
+// PostgreSQL BEGIN ATOMIC functions do. MySQL scripts set a DELIMITER for
+// these instead.
+var routineWords = map[string]bool{
+	"TRIGGER": true, "FUNCTION": true, "PROCEDURE": true,
+}
+
+// SplitStatements splits query into its statements, read the way dialect
+// reads it: a ";" in a string, quoted identifier, comment or dollar-quoted
+// body does not end a statement, nor does one inside the BEGIN ... END body
+// of a trigger or routine. For MySQL, a DELIMITER line sets what ends
+// statements from then on, as the mysql client does. Statements with
+// nothing but comments are dropped.
+func SplitStatements(dialect, query string) []Statement {
+	l := lexerFor(dialect)
+	q := query
+	var stmts []Statement
+	delim := ";"
+	start := 0
+	code := false    // the statement has more than comments
+	first := ""      // its first word
+	routine := false // it creates a trigger or routine
+	depth := 0       // BEGIN/CASE ... END blocks open in its body
+
+	end := func(i, next int) {
+		if code {
+			text := strings.TrimSpace(q[start:i])
+			lead := len(q[start:i]) - len(strings.TrimLeft(q[start:i], " \t\r\n"))
+			stmts = append(stmts, Statement{Text: text, Start: start + lead, End: start + lead + len(text)})
+		}
+		start, code, first, routine, depth = next, false, "", false, 0
+	}
+
+	for i := 0; i < len(q); {
+		c := q[i]
+		switch {
+		case l.mysql && lineStart(q, i) && delimiterCommand(q[i:]):
+			end(i, i)
+			eol := strings.IndexByte(q[i:], '\n')
+			if eol < 0 {
+				eol = len(q) - i
+			}
+			if d := strings.TrimSpace(q[i+len("DELIMITER") : i+eol]); d != "" {
+				delim = d
+			}
+			i += eol
+			start = i
+		case c == '-' && strings.HasPrefix(q[i:], "--") && (!l.mysql || i+2 == len(q) || isSpace(q[i+2])),
+			c == '#' && l.mysql:
+			eol := strings.IndexByte(q[i:], '\n')
+			if eol < 0 {
+				eol = len(q) - i
+			}
+			i += eol
+		case c == '/' && strings.HasPrefix(q[i:], "/*!") && l.mysql:
+			code = true
+			i += 3
+		case c == '/' && strings.HasPrefix(q[i:], "/*"):
+			i = l.skipComment(q, i)
+		case depth == 0 && strings.HasPrefix(q[i:], delim):
+			end(i, i+len(delim))
+			i += len(delim)
+		case c == '\'' || c == '"' || c == '`':
+			code = true
+			i = l.skipQuoted(q, i)
+		case (c == 'E' || c == 'e') && l.escapeString && strings.HasPrefix(q[i+1:], "'"):
+			code = true
+			i = lexer{backslash: true}.skipQuoted(q, i+1)
+		case c == '$' && l.dollar:
+			code = true
+			tag, ok := dollarTag(q[i:])
+			if !ok {
+				i++
+				break
+			}
+			e := strings.Index(q[i+len(tag):], tag)
+			if e < 0 {
+				i = len(q)
+				break
+			}
+			i += len(tag) + e + len(tag)
+		case isWordByte(c):
+			code = true
+			j := i
+			for j < len(q) && (isWordByte(q[j]) || q[j] == '$' && l.dollar) {
+				j++
+			}
+			w := strings.ToUpper(q[i:j])
+			switch {
+			case first == "":
+				first = w
+			case first == "CREATE" && depth == 0 && routineWords[w] && !l.mysql:
+				routine = true
+			case routine && (w == "BEGIN" || w == "CASE"):
+				depth++
+			case routine && w == "END" && depth > 0:
+				depth--
+			}
+			i = j
+		case isSpace(c):
+			i++
+		default:
+			code = true
+			i++
+		}
+	}
+	end(len(q), len(q))
+	return stmts
+}
+
+// StatementAt returns the statement of query, split as SplitStatements
+// does, that the byte offset pos is in. Between statements it is the one
+// before pos, or the first before any. ok is false if query has none.
+func StatementAt(dialect, query string, pos int) (stmt Statement, ok bool) {
+	stmts := SplitStatements(dialect, query)
+	if len(stmts) == 0 {
+		return Statement{}, false
+	}
+	at := 0
+	for i, s := range stmts {
+		if s.Start <= pos {
+			at = i
+		}
+	}
+	return stmts[at], true
+}
+
+// lineStart reports whether q[i] starts a line, past any indentation.
+func lineStart(q string, i int) bool {
+	for i > 0 && (q[i-1] == ' ' || q[i-1] == '\t') {
+		i--
+	}
+	return i == 0 || q[i-1] == '\n'
+}
+
+// delimiterCommand reports whether s starts with the mysql client's
+// DELIMITER command.
+func delimiterCommand(s string) bool {
+	const word = "DELIMITER"
+	return len(s) > len(word) && strings.EqualFold(s[:len(word)], word) && (s[len(word)] == ' ' || s[len(word)] == '\t')
+}
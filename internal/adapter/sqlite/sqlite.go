@@ -1,11 +1,15 @@
 package sqlite
 
 import (
+	"cmp"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"io"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -27,9 +31,15 @@ func (a *sqliteAdapter) Name() string     { return "sqlite" }
 func (a *sqliteAdapter) DefaultPort() int { return 0 }
 
 func (a *sqliteAdapter) Connect(ctx context.Context, dsn string) (adapter.Connection, error) {
+	return a.ConnectSession(ctx, dsn, nil)
+}
+
+// ConnectSession connects with init run on every pooled connection.
+func (a *sqliteAdapter) ConnectSession(ctx context.Context, dsn string, init []string) (adapter.Connection, error) {
 	dsn = normalizeDSN(dsn)
+	c := &sqliteConn{dsn: dsn, init: init}
 
-	db, err := sql.Open("sqlite", dsn)
+	db, err := adapter.OpenDBFunc("sqlite", dsn, c.sessionInit)
 	if err != nil {
 		return nil, fmt.Errorf("sqlite open: %w", err)
 	}
@@ -45,16 +55,12 @@ func (a *sqliteAdapter) Connect(ctx context.Context, dsn string) (adapter.Connec
 		return nil, fmt.Errorf("sqlite enable foreign keys: %w", err)
 	}
 
-	dbName := dsn
+	c.db = db
+	c.dbName = dsn
 	if dsn != ":memory:" {
-		dbName = filepath.Base(dsn)
+		c.dbName = filepath.Base(dsn)
 	}
-
-	return &sqliteConn{
-		db:     db,
-		dsn:    dsn,
-		dbName: dbName,
-	}, nil
+	return c, nil
 }
 
 // normalizeDSN strips common SQLite URI prefixes.
@@ -73,9 +79,20 @@ type sqliteConn struct {
 	db     *sql.DB
 	dsn    string
 	dbName string
+	init   []string // run on every pooled connection
 
 	mu       sync.Mutex
 	cancelFn context.CancelFunc
+	tx       adapter.SQLTx
+
+	attachMu sync.Mutex
+	attached []attachment // in the order attached
+}
+
+// attachment is a database file attached to every pooled connection.
+type attachment struct {
+	path  string
+	alias string
 }
 
 func (c *sqliteConn) AdapterName() string  { return "sqlite" }
@@ -86,16 +103,19 @@ func (c *sqliteConn) Ping(ctx context.Context) error {
 }
 
 func (c *sqliteConn) Close() error {
+	_ = c.tx.Rollback()
 	return c.db.Close()
 }
 
-// Databases returns a single database entry for the opened SQLite file.
+// Databases returns a database entry for the opened SQLite file, with its
+// "main" schema, and one for each attached file, with a schema named after
+// its alias.
 func (c *sqliteConn) Databases(ctx context.Context) ([]schema.Database, error) {
 	tables, err := c.Tables(ctx, c.dbName, "main")
 	if err != nil {
 		return nil, err
 	}
-	return []schema.Database{
+	dbs := []schema.Database{
 		{
 			Name: c.dbName,
 			Schemas: []schema.Schema{
@@ -105,13 +125,33 @@ func (c *sqliteConn) Databases(ctx context.Context) ([]schema.Database, error) {
 				},
 			},
 		},
-	}, nil
+	}
+	for _, alias := range c.aliases() {
+		tables, err := c.Tables(ctx, alias, alias)
+		if err != nil {
+			return nil, err
+		}
+		dbs = append(dbs, schema.Database{
+			Name:    alias,
+			Schemas: []schema.Schema{{Name: alias, Tables: tables}},
+		})
+	}
+	return dbs, nil
+}
+
+// quoteSchema quotes the schema name of the main database or an attached
+// one, "main" when it is empty.
+func quoteSchema(name string) string {
+	if name == "" {
+		name = "main"
+	}
+	return adapter.QuoteIdentifier("sqlite", name)
 }
 
 // Tables returns all user tables in the database.
 func (c *sqliteConn) Tables(ctx context.Context, db, schemaName string) ([]schema.Table, error) {
-	rows, err := c.db.QueryContext(ctx,
-		"SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	rows, err := c.db.QueryContext(ctx, fmt.Sprintf(
+		"SELECT name FROM %s.sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%%' ORDER BY name", quoteSchema(schemaName)))
 	if err != nil {
 		return nil, fmt.Errorf("sqlite tables: %w", err)
 	}
@@ -130,7 +170,7 @@ func (c *sqliteConn) Tables(ctx context.Context, db, schemaName string) ([]schem
 
 // Columns returns column metadata for the given table using PRAGMA table_info.
 func (c *sqliteConn) Columns(ctx context.Context, db, schemaName, table string) ([]schema.Column, error) {
-	rows, err := c.db.QueryContext(ctx, fmt.Sprintf("PRAGMA table_info(%q)", table))
+	rows, err := c.db.QueryContext(ctx, fmt.Sprintf("PRAGMA %s.table_info(%q)", quoteSchema(schemaName), table))
 	if err != nil {
 		return nil, fmt.Errorf("sqlite columns: %w", err)
 	}
@@ -165,7 +205,7 @@ func (c *sqliteConn) Columns(ctx context.Context, db, schemaName, table string)
 
 // Indexes returns index information for the given table.
 func (c *sqliteConn) Indexes(ctx context.Context, db, schemaName, table string) ([]schema.Index, error) {
-	listRows, err := c.db.QueryContext(ctx, fmt.Sprintf("PRAGMA index_list(%q)", table))
+	listRows, err := c.db.QueryContext(ctx, fmt.Sprintf("PRAGMA %s.index_list(%q)", quoteSchema(schemaName), table))
 	if err != nil {
 		return nil, fmt.Errorf("sqlite index_list: %w", err)
 	}
@@ -195,7 +235,7 @@ func (c *sqliteConn) Indexes(ctx context.Context, db, schemaName, table string)
 
 	var indexes []schema.Index
 	for _, entry := range entries {
-		infoRows, err := c.db.QueryContext(ctx, fmt.Sprintf("PRAGMA index_info(%q)", entry.name))
+		infoRows, err := c.db.QueryContext(ctx, fmt.Sprintf("PRAGMA %s.index_info(%q)", quoteSchema(schemaName), entry.name))
 		if err != nil {
 			return nil, fmt.Errorf("sqlite index_info: %w", err)
 		}
@@ -229,7 +269,7 @@ func (c *sqliteConn) Indexes(ctx context.Context, db, schemaName, table string)
 
 // ForeignKeys returns foreign key constraints for the given table.
 func (c *sqliteConn) ForeignKeys(ctx context.Context, db, schemaName, table string) ([]schema.ForeignKey, error) {
-	rows, err := c.db.QueryContext(ctx, fmt.Sprintf("PRAGMA foreign_key_list(%q)", table))
+	rows, err := c.db.QueryContext(ctx, fmt.Sprintf("PRAGMA %s.foreign_key_list(%q)", quoteSchema(schemaName), table))
 	if err != nil {
 		return nil, fmt.Errorf("sqlite foreign_key_list: %w", err)
 	}
@@ -284,8 +324,369 @@ func (c *sqliteConn) ForeignKeys(ctx context.Context, db, schemaName, table stri
 	return fks, nil
 }
 
+// AllColumns returns the columns of every table in the schema in one query,
+// joining sqlite_master with the pragma_table_info table-valued function.
+func (c *sqliteConn) AllColumns(ctx context.Context, db, schemaName string) (map[string][]schema.Column, error) {
+	rows, err := c.db.QueryContext(ctx, fmt.Sprintf(
+		`SELECT m.name, p.name, p.type, p."notnull", p.dflt_value, p.pk
+		 FROM %s.sqlite_master m
+		 JOIN pragma_table_info(m.name, ?) p
+		 WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%%'
+		 ORDER BY m.name, p.cid`, quoteSchema(schemaName)), schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite all columns: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string][]schema.Column)
+	for rows.Next() {
+		var (
+			table     string
+			col       schema.Column
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&table, &col.Name, &col.Type, &notNull, &dfltValue, &pk); err != nil {
+			return nil, fmt.Errorf("sqlite all columns scan: %w", err)
+		}
+		col.Nullable = notNull == 0
+		col.IsPK = pk > 0
+		col.Default = dfltValue.String
+		result[table] = append(result[table], col)
+	}
+	return result, rows.Err()
+}
+
+// AllIndexes returns the indexes of every table in the schema in one query.
+// Columns of an expression index have no name and are left out.
+func (c *sqliteConn) AllIndexes(ctx context.Context, db, schemaName string) (map[string][]schema.Index, error) {
+	rows, err := c.db.QueryContext(ctx, fmt.Sprintf(
+		`SELECT m.name, il.name, il."unique", ii.name
+		 FROM %s.sqlite_master m
+		 JOIN pragma_index_list(m.name, ?) il
+		 JOIN pragma_index_info(il.name, ?) ii
+		 WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%%'
+		 ORDER BY m.name, il.seq, ii.seqno`, quoteSchema(schemaName)), schemaName, schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite all indexes: %w", err)
+	}
+	defer rows.Close()
+
+	type idxKey struct{ table, name string }
+	indexMap := make(map[idxKey]*schema.Index)
+	var order []idxKey
+
+	for rows.Next() {
+		var (
+			table, idxName string
+			unique         int
+			colName        sql.NullString
+		)
+		if err := rows.Scan(&table, &idxName, &unique, &colName); err != nil {
+			return nil, fmt.Errorf("sqlite all indexes scan: %w", err)
+		}
+		key := idxKey{table, idxName}
+		idx, ok := indexMap[key]
+		if !ok {
+			idx = &schema.Index{Name: idxName, Unique: unique == 1}
+			indexMap[key] = idx
+			order = append(order, key)
+		}
+		if colName.Valid {
+			idx.Columns = append(idx.Columns, colName.String)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]schema.Index)
+	for _, key := range order {
+		result[key.table] = append(result[key.table], *indexMap[key])
+	}
+	return result, nil
+}
+
+// AllForeignKeys returns the foreign keys of every table in the schema in
+// one query, named like ForeignKeys names them.
+func (c *sqliteConn) AllForeignKeys(ctx context.Context, db, schemaName string) (map[string][]schema.ForeignKey, error) {
+	rows, err := c.db.QueryContext(ctx, fmt.Sprintf(
+		`SELECT m.name, fk.id, fk."table", fk."from", fk."to"
+		 FROM %s.sqlite_master m
+		 JOIN pragma_foreign_key_list(m.name, ?) fk
+		 WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%%'
+		 ORDER BY m.name, fk.id, fk.seq`, quoteSchema(schemaName)), schemaName)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite all foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	type fkKey struct {
+		table string
+		id    int
+	}
+	fkMap := make(map[fkKey]*schema.ForeignKey)
+	var fkOrder []fkKey
+
+	for rows.Next() {
+		var (
+			table, refTable, from string
+			id                    int
+			to                    sql.NullString
+		)
+		if err := rows.Scan(&table, &id, &refTable, &from, &to); err != nil {
+			return nil, fmt.Errorf("sqlite all foreign keys scan: %w", err)
+		}
+		key := fkKey{table, id}
+		fk, ok := fkMap[key]
+		if !ok {
+			fk = &schema.ForeignKey{Name: fmt.Sprintf("fk_%s_%d", table, id), RefTable: refTable}
+			fkMap[key] = fk
+			fkOrder = append(fkOrder, key)
+		}
+		fk.Columns = append(fk.Columns, from)
+		fk.RefColumns = append(fk.RefColumns, to.String)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]schema.ForeignKey)
+	for _, key := range fkOrder {
+		result[key.table] = append(result[key.table], *fkMap[key])
+	}
+	return result, nil
+}
+
+// TableDDL returns the SQL that created a table or view, as stored in
+// sqlite_master, followed by the table's explicit indexes and triggers.
+func (c *sqliteConn) TableDDL(ctx context.Context, db, schemaName, table string) (string, error) {
+	rows, err := c.db.QueryContext(ctx, fmt.Sprintf(
+		`SELECT sql FROM %s.sqlite_master
+		 WHERE tbl_name = ? AND sql IS NOT NULL
+		 ORDER BY CASE type WHEN 'table' THEN 0 WHEN 'view' THEN 0 WHEN 'index' THEN 1 ELSE 2 END, name`, quoteSchema(schemaName)), table)
+	if err != nil {
+		return "", fmt.Errorf("sqlite ddl: %w", err)
+	}
+	defer rows.Close()
+
+	var stmts []string
+	for rows.Next() {
+		var stmt string
+		if err := rows.Scan(&stmt); err != nil {
+			return "", fmt.Errorf("sqlite ddl scan: %w", err)
+		}
+		stmts = append(stmts, stmt+";")
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("sqlite ddl: %w", err)
+	}
+	if len(stmts) == 0 {
+		return "", fmt.Errorf("sqlite ddl: %s not found", table)
+	}
+	return strings.Join(stmts, "\n\n"), nil
+}
+
+// TableStats counts the rows of every table, since SQLite keeps no row
+// estimates, and sums their pages (table and indexes) from the dbstat
+// virtual table. Sizes are left unknown when dbstat is not available.
+func (c *sqliteConn) TableStats(ctx context.Context, db, schemaName string) (map[string]schema.TableStats, error) {
+	tables, err := c.Tables(ctx, db, schemaName)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]schema.TableStats, len(tables))
+	for _, t := range tables {
+		st := schema.TableStats{Rows: -1, Bytes: -1}
+		q := "SELECT COUNT(*) FROM " + quoteSchema(schemaName) + "." + adapter.QuoteIdentifier("sqlite", t.Name)
+		if err := c.db.QueryRowContext(ctx, q).Scan(&st.Rows); err != nil {
+			return nil, fmt.Errorf("sqlite table stats: %w", err)
+		}
+		result[t.Name] = st
+	}
+
+	rows, err := c.db.QueryContext(ctx, fmt.Sprintf(
+		`SELECT m.tbl_name, SUM(s.pgsize)
+		 FROM dbstat(?) s JOIN %s.sqlite_master m ON m.name = s.name
+		 GROUP BY m.tbl_name`, quoteSchema(schemaName)), cmp.Or(schemaName, "main"))
+	if err != nil {
+		return result, nil // built without SQLITE_ENABLE_DBSTAT_VTAB
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var size int64
+		if err := rows.Scan(&name, &size); err != nil {
+			return nil, fmt.Errorf("sqlite table stats scan: %w", err)
+		}
+		if st, ok := result[name]; ok {
+			st.Bytes = size
+			result[name] = st
+		}
+	}
+	return result, rows.Err()
+}
+
+// Routines returns nothing: SQLite has no stored routines.
+func (c *sqliteConn) Routines(ctx context.Context, db, schemaName string) ([]schema.Routine, error) {
+	return nil, nil
+}
+
+// Sequences returns nothing: SQLite has no sequence objects.
+func (c *sqliteConn) Sequences(ctx context.Context, db, schemaName string) ([]schema.Sequence, error) {
+	return nil, nil
+}
+
+// triggerHeader matches the timing and event of a CREATE TRIGGER statement.
+var triggerHeader = regexp.MustCompile(`(?is)\b(BEFORE|AFTER|INSTEAD\s+OF)?\s*(INSERT|DELETE|UPDATE)\b[^;]*?\bON\b`)
+
+// Triggers lists the triggers in sqlite_master. SQLite keeps no catalog of
+// their timing and event, so those are read back from the stored SQL.
+func (c *sqliteConn) Triggers(ctx context.Context, db, schemaName string) ([]schema.Trigger, error) {
+	rows, err := c.db.QueryContext(ctx, fmt.Sprintf(
+		"SELECT name, tbl_name, sql FROM %s.sqlite_master WHERE type='trigger' ORDER BY tbl_name, name", quoteSchema(schemaName)))
+	if err != nil {
+		return nil, fmt.Errorf("sqlite triggers: %w", err)
+	}
+	defer rows.Close()
+
+	var triggers []schema.Trigger
+	for rows.Next() {
+		var t schema.Trigger
+		var stmt string
+		if err := rows.Scan(&t.Name, &t.Table, &stmt); err != nil {
+			return nil, fmt.Errorf("sqlite triggers scan: %w", err)
+		}
+		t.Timing, t.Event = triggerEvent(stmt)
+		triggers = append(triggers, t)
+	}
+	return triggers, rows.Err()
+}
+
+// triggerEvent reads the timing and event of a trigger from its CREATE
+// TRIGGER statement.
+func triggerEvent(stmt string) (timing, event string) {
+	m := triggerHeader.FindStringSubmatch(stmt)
+	if m == nil {
+		return "", ""
+	}
+	timing = strings.ToUpper(strings.Join(strings.Fields(m[1]), " "))
+	if timing == "" {
+		timing = "BEFORE" // SQLite's default
+	}
+	return timing, strings.ToUpper(m[2])
+}
+
+// Dependencies lists the views, foreign keys and triggers that depend on a
+// table or view and what it depends on. SQLite keeps no catalog of what
+// views and triggers read, so their SQL is searched for the names of the
+// tables and views of the schema; a column sharing such a name is taken
+// for a reference too.
+func (c *sqliteConn) Dependencies(ctx context.Context, db, schemaName, name string) (adapter.Dependencies, error) {
+	var deps adapter.Dependencies
+	rows, err := c.db.QueryContext(ctx, fmt.Sprintf(
+		`SELECT type, name, tbl_name, COALESCE(sql, '') FROM %s.sqlite_master
+		 WHERE type IN ('table', 'view', 'trigger') AND name NOT LIKE 'sqlite_%%'
+		 ORDER BY name`, quoteSchema(schemaName)))
+	if err != nil {
+		return deps, fmt.Errorf("sqlite dependencies: %w", err)
+	}
+	defer rows.Close()
+
+	type object struct{ kind, name, table, sql string }
+	var objects []object
+	kinds := map[string]string{}
+	var relations []string // the tables and views
+	for rows.Next() {
+		var o object
+		if err := rows.Scan(&o.kind, &o.name, &o.table, &o.sql); err != nil {
+			return deps, fmt.Errorf("sqlite dependencies scan: %w", err)
+		}
+		objects = append(objects, o)
+		if o.kind != "trigger" {
+			kinds[strings.ToLower(o.name)] = o.kind
+			relations = append(relations, o.name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return deps, fmt.Errorf("sqlite dependencies: %w", err)
+	}
+	if _, ok := kinds[strings.ToLower(name)]; !ok {
+		return deps, fmt.Errorf("sqlite dependencies: %s not found", name)
+	}
+	fks, err := c.AllForeignKeys(ctx, db, schemaName)
+	if err != nil {
+		return deps, err
+	}
+
+	// others returns the tables and views stmt reads but name.
+	others := func(stmt string) []string {
+		var found []string
+		for _, r := range adapter.ReferencedNames("sqlite", stmt, relations) {
+			if !strings.EqualFold(r, name) {
+				found = append(found, r)
+			}
+		}
+		return found
+	}
+	add := func(list *[]adapter.Dependency, kind, depName, detail string) {
+		*list = append(*list, adapter.Dependency{Kind: kind, Schema: schemaName, Name: depName, Detail: detail})
+	}
+	for _, o := range objects {
+		self := strings.EqualFold(o.name, name)
+		switch {
+		case o.kind == "view" && self:
+			for _, r := range others(o.sql) {
+				add(&deps.DependsOn, kinds[strings.ToLower(r)], r, "")
+			}
+		case o.kind == "view":
+			if len(adapter.ReferencedNames("sqlite", o.sql, []string{name})) > 0 {
+				add(&deps.Dependents, adapter.DependencyView, o.name, "")
+			}
+		case o.kind == "table":
+			for _, fk := range fks[o.name] {
+				switch {
+				case self:
+					add(&deps.DependsOn, adapter.DependencyTable, fk.RefTable, foreignKeyDetail(fk))
+				case strings.EqualFold(fk.RefTable, name):
+					add(&deps.Dependents, adapter.DependencyTable, o.name, foreignKeyDetail(fk))
+				}
+			}
+		case strings.EqualFold(o.table, name):
+			timing, event := triggerEvent(o.sql)
+			add(&deps.Dependents, adapter.DependencyTrigger, o.name, strings.TrimSpace(timing+" "+event))
+			for _, r := range others(o.sql) {
+				add(&deps.DependsOn, kinds[strings.ToLower(r)], r, "used by trigger "+o.name)
+			}
+		default:
+			if len(adapter.ReferencedNames("sqlite", o.sql, []string{name})) > 0 {
+				add(&deps.Dependents, adapter.DependencyTrigger, o.name, "on "+o.table)
+			}
+		}
+	}
+	return deps, nil
+}
+
+// foreignKeyDetail renders a foreign key the way it is declared.
+func foreignKeyDetail(fk schema.ForeignKey) string {
+	return fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s(%s)",
+		strings.Join(fk.Columns, ", "), fk.RefTable, strings.Join(fk.RefColumns, ", "))
+}
+
 // Execute runs a query and returns the result.
 func (c *sqliteConn) Execute(ctx context.Context, query string) (*adapter.QueryResult, error) {
+	return c.execute(ctx, query, nil)
+}
+
+// ExecuteParams runs query as a prepared statement, binding args to its ?
+// placeholders.
+func (c *sqliteConn) ExecuteParams(ctx context.Context, query string, args []any) (*adapter.QueryResult, error) {
+	return c.execute(ctx, query, args)
+}
+
+func (c *sqliteConn) execute(ctx context.Context, query string, args []any) (*adapter.QueryResult, error) {
 	ctx, cancel := context.WithCancel(ctx)
 	c.mu.Lock()
 	c.cancelFn = cancel
@@ -298,6 +699,10 @@ func (c *sqliteConn) Execute(ctx context.Context, query string) (*adapter.QueryR
 		cancel()
 	}()
 
+	if cmd := adapter.ParseAttach(query); cmd != nil && args == nil {
+		return c.runAttach(ctx, cmd)
+	}
+
 	trimmed := strings.TrimSpace(strings.ToUpper(query))
 	isSelect := strings.HasPrefix(trimmed, "SELECT") ||
 		strings.HasPrefix(trimmed, "PRAGMA") ||
@@ -307,13 +712,13 @@ func (c *sqliteConn) Execute(ctx context.Context, query string) (*adapter.QueryR
 	start := time.Now()
 
 	if isSelect {
-		return c.executeQuery(ctx, query, start)
+		return c.executeQuery(ctx, query, start, args...)
 	}
-	return c.executeExec(ctx, query, start)
+	return c.executeExec(ctx, query, start, args...)
 }
 
-func (c *sqliteConn) executeQuery(ctx context.Context, query string, start time.Time) (*adapter.QueryResult, error) {
-	rows, err := c.db.QueryContext(ctx, query)
+func (c *sqliteConn) executeQuery(ctx context.Context, query string, start time.Time, args ...any) (*adapter.QueryResult, error) {
+	rows, err := c.tx.On(ctx, c.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, adapter.ErrCancelled
@@ -354,7 +759,7 @@ func (c *sqliteConn) executeQuery(ctx context.Context, query string, start time.
 			if ns.Valid {
 				row[i] = ns.String
 			} else {
-				row[i] = "NULL"
+				row[i] = adapter.NullValue
 			}
 		}
 		resultRows = append(resultRows, row)
@@ -375,8 +780,8 @@ func (c *sqliteConn) executeQuery(ctx context.Context, query string, start time.
 	}, nil
 }
 
-func (c *sqliteConn) executeExec(ctx context.Context, query string, start time.Time) (*adapter.QueryResult, error) {
-	result, err := c.db.ExecContext(ctx, query)
+func (c *sqliteConn) executeExec(ctx context.Context, query string, start time.Time, args ...any) (*adapter.QueryResult, error) {
+	result, err := c.tx.On(ctx, c.db).ExecContext(ctx, query, args...)
 	if err != nil {
 		if ctx.Err() != nil {
 			return nil, adapter.ErrCancelled
@@ -405,6 +810,228 @@ func (c *sqliteConn) Cancel() error {
 	return nil
 }
 
+// Begin starts an explicit transaction that Execute runs in until Commit
+// or Rollback.
+func (c *sqliteConn) Begin(ctx context.Context) error {
+	if err := c.tx.Begin(ctx, c.db); err != nil {
+		return fmt.Errorf("sqlite begin: %w", err)
+	}
+	return nil
+}
+
+// Commit commits the open transaction.
+func (c *sqliteConn) Commit(ctx context.Context) error {
+	if err := c.tx.Commit(); err != nil {
+		return fmt.Errorf("sqlite commit: %w", err)
+	}
+	return nil
+}
+
+// Rollback rolls back the open transaction.
+func (c *sqliteConn) Rollback(ctx context.Context) error {
+	if err := c.tx.Rollback(); err != nil {
+		return fmt.Errorf("sqlite rollback: %w", err)
+	}
+	return nil
+}
+
+// InTransaction returns whether a transaction is open.
+func (c *sqliteConn) InTransaction() bool { return c.tx.Active() }
+
+// sessionInit returns the statements a new pooled connection runs: the
+// session's own, then an ATTACH for each database attached so far.
+func (c *sqliteConn) sessionInit() []string {
+	c.attachMu.Lock()
+	defer c.attachMu.Unlock()
+	stmts := slices.Clone(c.init)
+	for _, a := range c.attached {
+		stmts = append(stmts, attachSQL(a.path, a.alias))
+	}
+	return stmts
+}
+
+func attachSQL(path, alias string) string {
+	return "ATTACH DATABASE " + adapter.QuoteLiteral("sqlite", path) + " AS " + adapter.QuoteIdentifier("sqlite", alias)
+}
+
+// aliases returns the schema names of the attached databases.
+func (c *sqliteConn) aliases() []string {
+	c.attachMu.Lock()
+	defer c.attachMu.Unlock()
+	var aliases []string
+	for _, a := range c.attached {
+		aliases = append(aliases, a.alias)
+	}
+	return aliases
+}
+
+// Attach attaches the database file at path as alias. ATTACH reaches only
+// the session running it, so the file is attached on one to check it
+// opens, then the idle sessions are closed: the ones opened after attach
+// it as they connect (sessionInit).
+func (c *sqliteConn) Attach(ctx context.Context, path, alias string) error {
+	if inMemory(c.dsn) {
+		return errInMemoryAttach
+	}
+	if c.tx.Active() {
+		return errors.New("sqlite attach: cannot attach a database inside a transaction")
+	}
+	if slices.ContainsFunc(c.aliases(), func(a string) bool { return strings.EqualFold(a, alias) }) {
+		return fmt.Errorf("sqlite attach: database %s is already in use", alias)
+	}
+	conn, err := c.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("sqlite attach: %w", err)
+	}
+	_, err = conn.ExecContext(ctx, attachSQL(path, alias))
+	conn.Close()
+	if err != nil {
+		return fmt.Errorf("sqlite attach: %w", err)
+	}
+
+	c.attachMu.Lock()
+	c.attached = append(c.attached, attachment{path: path, alias: alias})
+	c.attachMu.Unlock()
+	c.resetPool()
+	return nil
+}
+
+// Detach detaches the database attached as alias from every session.
+func (c *sqliteConn) Detach(ctx context.Context, alias string) error {
+	if inMemory(c.dsn) {
+		return errInMemoryAttach
+	}
+	if c.tx.Active() {
+		return errors.New("sqlite detach: cannot detach a database inside a transaction")
+	}
+	c.attachMu.Lock()
+	i := slices.IndexFunc(c.attached, func(a attachment) bool { return strings.EqualFold(a.alias, alias) })
+	if i >= 0 {
+		c.attached = slices.Delete(c.attached, i, i+1)
+	}
+	c.attachMu.Unlock()
+	if i < 0 {
+		return fmt.Errorf("sqlite detach: no such database: %s", alias)
+	}
+	c.resetPool()
+	return nil
+}
+
+// errInMemoryAttach refuses ATTACH and DETACH on a database that lives
+// in its session, which resetPool would close and lose.
+var errInMemoryAttach = errors.New("sqlite attach: cannot attach databases to an in-memory database")
+
+// inMemory reports whether dsn opens a database that lives only as long
+// as its session: :memory:, mode=memory, or a temporary one ("").
+func inMemory(dsn string) bool {
+	return dsn == "" || strings.HasPrefix(dsn, ":memory:") || strings.Contains(dsn, "mode=memory")
+}
+
+// resetPool closes the idle sessions, so the next ones open with the
+// databases attached now. A session busy meanwhile keeps the ones it has
+// until it is closed.
+func (c *sqliteConn) resetPool() {
+	c.db.SetMaxIdleConns(0)
+	c.db.SetMaxIdleConns(2) // database/sql's default
+}
+
+// runAttach runs an ATTACH or DETACH statement on every session.
+func (c *sqliteConn) runAttach(ctx context.Context, cmd *adapter.AttachCommand) (*adapter.QueryResult, error) {
+	start := time.Now()
+	if cmd.Detach {
+		if err := c.Detach(ctx, cmd.Alias); err != nil {
+			return nil, err
+		}
+		return &adapter.QueryResult{Duration: time.Since(start), Message: "Detached " + cmd.Alias}, nil
+	}
+	if err := c.Attach(ctx, cmd.Path, cmd.Alias); err != nil {
+		return nil, err
+	}
+	return &adapter.QueryResult{
+		Duration: time.Since(start),
+		Message:  fmt.Sprintf("Attached %s as %s", filepath.Base(cmd.Path), cmd.Alias),
+	}, nil
+}
+
+// maintenanceSettings are the PRAGMAs the maintenance panel shows.
+var maintenanceSettings = []string{
+	"journal_mode", "synchronous", "page_size", "cache_size",
+	"page_count", "freelist_count", "auto_vacuum",
+}
+
+// MaintenanceSettings reads the storage PRAGMAs of a database.
+func (c *sqliteConn) MaintenanceSettings(ctx context.Context, schemaName string) ([]adapter.Setting, error) {
+	settings := make([]adapter.Setting, 0, len(maintenanceSettings))
+	for _, name := range maintenanceSettings {
+		var value string
+		q := fmt.Sprintf("PRAGMA %s.%s", quoteSchema(schemaName), name)
+		if err := c.db.QueryRowContext(ctx, q).Scan(&value); err != nil {
+			return nil, fmt.Errorf("sqlite pragma %s: %w", name, err)
+		}
+		settings = append(settings, adapter.Setting{Name: name, Value: value})
+	}
+	return settings, nil
+}
+
+// IntegrityCheck runs PRAGMA integrity_check, which reports "ok" alone
+// when it finds nothing wrong.
+func (c *sqliteConn) IntegrityCheck(ctx context.Context, schemaName string) ([]string, error) {
+	rows, err := c.db.QueryContext(ctx, fmt.Sprintf("PRAGMA %s.integrity_check", quoteSchema(schemaName)))
+	if err != nil {
+		return nil, fmt.Errorf("sqlite integrity_check: %w", err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, fmt.Errorf("sqlite integrity_check scan: %w", err)
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		if ctx.Err() != nil {
+			return nil, adapter.ErrCancelled
+		}
+		return nil, fmt.Errorf("sqlite integrity_check: %w", err)
+	}
+	return problems, nil
+}
+
+// Maintain runs VACUUM, ANALYZE or REINDEX on a database. REINDEX takes no
+// database name, so the tables are reindexed one by one.
+func (c *sqliteConn) Maintain(ctx context.Context, schemaName, command string) error {
+	var stmts []string
+	switch command {
+	case adapter.MaintainVacuum, adapter.MaintainAnalyze:
+		stmts = []string{command + " " + quoteSchema(schemaName)}
+	case adapter.MaintainReindex:
+		tables, err := c.Tables(ctx, c.dbName, schemaName)
+		if err != nil {
+			return err
+		}
+		for _, t := range tables {
+			stmts = append(stmts, "REINDEX "+quoteSchema(schemaName)+"."+adapter.QuoteIdentifier("sqlite", t.Name))
+		}
+	default:
+		return fmt.Errorf("sqlite: unknown maintenance command %q", command)
+	}
+	for _, stmt := range stmts {
+		start := time.Now()
+		if _, err := c.db.ExecContext(ctx, stmt); err != nil {
+			if ctx.Err() != nil {
+				return adapter.ErrCancelled
+			}
+			return fmt.Errorf("sqlite %s: %w", strings.ToLower(command), err)
+		}
+		adapter.Record(ctx, stmt, start)
+	}
+	return nil
+}
+
 // ExecuteStreaming returns a RowIterator for paginated access to query results.
 func (c *sqliteConn) ExecuteStreaming(ctx context.Context, query string, pageSize int) (adapter.RowIterator, error) {
 	// First, execute a probe query to discover column metadata.
@@ -441,18 +1068,43 @@ func (c *sqliteConn) ExecuteStreaming(ctx context.Context, query string, pageSiz
 	}, nil
 }
 
-// Completions returns autocomplete items for tables and their columns.
+// Completions returns autocomplete items for tables and their columns, the
+// tables of attached databases qualified with their alias.
 func (c *sqliteConn) Completions(ctx context.Context) ([]adapter.CompletionItem, error) {
 	var items []adapter.CompletionItem
+	for _, alias := range c.aliases() {
+		items = append(items, adapter.CompletionItem{
+			Label:  alias,
+			Kind:   adapter.CompletionSchema,
+			Detail: "attached database",
+		})
+	}
+	for _, schemaName := range append([]string{"main"}, c.aliases()...) {
+		more, err := c.schemaCompletions(ctx, schemaName)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, more...)
+	}
+	return items, nil
+}
+
+// schemaCompletions returns the completion items of one database.
+func (c *sqliteConn) schemaCompletions(ctx context.Context, schemaName string) ([]adapter.CompletionItem, error) {
+	var items []adapter.CompletionItem
 
-	tables, err := c.Tables(ctx, c.dbName, "main")
+	tables, err := c.Tables(ctx, c.dbName, schemaName)
 	if err != nil {
 		return nil, err
 	}
 
+	prefix := ""
+	if schemaName != "main" {
+		prefix = schemaName + "."
+	}
 	for _, t := range tables {
 		items = append(items, adapter.CompletionItem{
-			Label:  t.Name,
+			Label:  prefix + t.Name,
 			Kind:   adapter.CompletionTable,
 			Detail: "table",
 		})
@@ -460,16 +1112,16 @@ func (c *sqliteConn) Completions(ctx context.Context) ([]adapter.CompletionItem,
 
 	// Batch: get all columns for all tables in a single query using
 	// pragma_table_info(). Falls back to per-table queries if unsupported.
-	rows, err := c.db.QueryContext(ctx,
+	rows, err := c.db.QueryContext(ctx, fmt.Sprintf(
 		`SELECT m.name, p.name, p.type
-		 FROM sqlite_master m
-		 JOIN pragma_table_info(m.name) p
+		 FROM %s.sqlite_master m
+		 JOIN pragma_table_info(m.name, ?) p
 		 WHERE m.type IN ('table', 'view')
-		 ORDER BY m.name, p.cid`)
+		 ORDER BY m.name, p.cid`, quoteSchema(schemaName)), schemaName)
 	if err != nil {
 		// Fallback: per-table column queries
 		for _, t := range tables {
-			columns, cErr := c.Columns(ctx, c.dbName, "main", t.Name)
+			columns, cErr := c.Columns(ctx, c.dbName, schemaName, t.Name)
 			if cErr != nil {
 				continue
 			}
@@ -567,6 +1219,15 @@ func (it *rowIterator) FetchPrev(ctx context.Context) ([][]string, error) {
 	return data, nil
 }
 
+// Seek moves the LIMIT/OFFSET window so the next FetchNext starts at offset.
+func (it *rowIterator) Seek(_ context.Context, offset int64) error {
+	if offset < 0 {
+		offset = 0
+	}
+	it.offset = int(offset)
+	return nil
+}
+
 func (it *rowIterator) Close() error {
 	return nil
 }
@@ -589,7 +1250,7 @@ func scanAllRows(rows *sql.Rows, colCount int) ([][]string, error) {
 			if ns.Valid {
 				row[i] = ns.String
 			} else {
-				row[i] = "NULL"
+				row[i] = adapter.NullValue
 			}
 		}
 		result = append(result, row)
@@ -2,12 +2,18 @@ package sqlite
 
 import (
 	"context"
+	"errors"
 	"io"
+	"path/filepath"
+	"reflect"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/schema"
 )
 
 func TestSQLiteAdapter_Name(t *testing.T) {
@@ -117,6 +123,203 @@ func TestConnect_InMemory(t *testing.T) {
 	}
 }
 
+func TestConnectSession_EverySession(t *testing.T) {
+	a := &sqliteAdapter{}
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "ro.db")
+
+	conn, err := a.ConnectSession(ctx, path, []string{"PRAGMA query_only = ON"})
+	if err != nil {
+		t.Fatalf("ConnectSession() error: %v", err)
+	}
+	defer conn.Close()
+
+	// Hold two pooled connections so both are set up.
+	db := conn.(*sqliteConn).db
+	for i := 0; i < 2; i++ {
+		c, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		var on int
+		if err := c.QueryRowContext(ctx, "PRAGMA query_only").Scan(&on); err != nil {
+			t.Fatal(err)
+		}
+		if on != 1 {
+			t.Errorf("session %d: query_only = %d, want 1", i, on)
+		}
+	}
+
+	if _, err := conn.Execute(ctx, "CREATE TABLE t (id INTEGER)"); err == nil {
+		t.Error("write on a read-only session should fail")
+	}
+}
+
+func TestAttach(t *testing.T) {
+	a := &sqliteAdapter{}
+	ctx := context.Background()
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "archive.db")
+	{
+		c, err := a.Connect(ctx, archive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Execute(ctx, "CREATE TABLE orders (id INTEGER PRIMARY KEY, total REAL)"); err != nil {
+			t.Fatal(err)
+		}
+		c.Close()
+	}
+
+	conn, err := a.Connect(ctx, filepath.Join(dir, "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Execute(ctx, "CREATE TABLE users (id INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+	res, err := conn.Execute(ctx, "ATTACH DATABASE '"+archive+"' AS old;")
+	if err != nil {
+		t.Fatalf("ATTACH error: %v", err)
+	}
+	if res.Message != "Attached archive.db as old" {
+		t.Errorf("message = %q", res.Message)
+	}
+
+	// Every pooled session sees the attached file.
+	db := conn.(*sqliteConn).db
+	for i := 0; i < 3; i++ {
+		c, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		var n int
+		if err := c.QueryRowContext(ctx, "SELECT COUNT(*) FROM old.orders").Scan(&n); err != nil {
+			t.Errorf("session %d: %v", i, err)
+		}
+	}
+
+	dbs, err := conn.Databases(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dbs) != 2 || dbs[1].Name != "old" || dbs[1].Schemas[0].Name != "old" ||
+		len(dbs[1].Schemas[0].Tables) != 1 || dbs[1].Schemas[0].Tables[0].Name != "orders" {
+		t.Fatalf("databases = %+v, want app.db and old with orders", dbs)
+	}
+	cols, err := conn.Columns(ctx, "old", "old", "orders")
+	if err != nil || len(cols) != 2 || !cols[0].IsPK {
+		t.Errorf("columns = %+v, %v", cols, err)
+	}
+	allCols, err := conn.(adapter.BatchIntrospector).AllColumns(ctx, "old", "old")
+	if err != nil || len(allCols) != 1 || !reflect.DeepEqual(allCols["orders"], cols) {
+		t.Errorf("AllColumns = %+v, %v, want the columns of orders", allCols, err)
+	}
+	items, err := conn.Completions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var labels []string
+	for _, it := range items {
+		labels = append(labels, it.Label)
+	}
+	if !slices.Contains(labels, "old.orders") || !slices.Contains(labels, "users") {
+		t.Errorf("completions = %v, want old.orders and users", labels)
+	}
+
+	if _, err := conn.Execute(ctx, "ATTACH 'other.db' AS old"); err == nil {
+		t.Error("attaching a second database as old should fail")
+	}
+	if _, err := conn.Execute(ctx, "DETACH old"); err != nil {
+		t.Fatal(err)
+	}
+	if dbs, _ := conn.Databases(ctx); len(dbs) != 1 {
+		t.Errorf("databases after DETACH = %d, want 1", len(dbs))
+	}
+	if _, err := conn.Execute(ctx, "SELECT * FROM old.orders"); err == nil {
+		t.Error("old should be detached from every session")
+	}
+}
+
+// An in-memory database lives in its session: ATTACH is refused rather
+// than closing the session, and the database with it.
+func TestAttach_InMemory(t *testing.T) {
+	a := &sqliteAdapter{}
+	ctx := context.Background()
+	conn, err := a.Connect(ctx, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Execute(ctx, "CREATE TABLE keep (id INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(t.TempDir(), "archive.db")
+	if _, err := conn.Execute(ctx, "ATTACH DATABASE '"+archive+"' AS old"); err == nil || !strings.Contains(err.Error(), "in-memory") {
+		t.Errorf("ATTACH error = %v, want it refused", err)
+	}
+	if _, err := conn.Execute(ctx, "DETACH old"); err == nil {
+		t.Error("DETACH should be refused")
+	}
+	if _, err := conn.Execute(ctx, "SELECT * FROM keep"); err != nil {
+		t.Errorf("keep was lost: %v", err)
+	}
+}
+
+func TestMaintenance(t *testing.T) {
+	a := &sqliteAdapter{}
+	ctx := context.Background()
+	conn, err := a.Connect(ctx, filepath.Join(t.TempDir(), "app.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Execute(ctx, "CREATE TABLE t (id INTEGER PRIMARY KEY, name TEXT); CREATE INDEX t_name ON t (name); INSERT INTO t (name) VALUES ('a'), ('b')"); err != nil {
+		t.Fatal(err)
+	}
+	mt := conn.(adapter.Maintainer)
+
+	settings, err := mt.MaintenanceSettings(ctx, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for _, s := range settings {
+		got[s.Name] = s.Value
+	}
+	if got["page_size"] == "" || got["journal_mode"] == "" || got["cache_size"] == "" {
+		t.Errorf("settings = %v", settings)
+	}
+
+	if problems, err := mt.IntegrityCheck(ctx, "main"); err != nil || len(problems) != 0 {
+		t.Errorf("IntegrityCheck = %v, %v, want no problems", problems, err)
+	}
+	var ran []string
+	recording := adapter.WithRecorder(ctx, func(query string, _ time.Duration) { ran = append(ran, query) })
+	for _, cmd := range []string{adapter.MaintainVacuum, adapter.MaintainAnalyze, adapter.MaintainReindex} {
+		if err := mt.Maintain(recording, "", cmd); err != nil {
+			t.Errorf("%s: %v", cmd, err)
+		}
+	}
+	if want := []string{`VACUUM "main"`, `ANALYZE "main"`, `REINDEX "main"."t"`}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("recorded %q, want %q", ran, want)
+	}
+	if err := mt.Maintain(ctx, "main", "DROP"); err == nil {
+		t.Error("an unknown command should fail")
+	}
+}
+
+func TestConnectSession_BadInit(t *testing.T) {
+	a := &sqliteAdapter{}
+	_, err := a.ConnectSession(context.Background(), ":memory:", []string{"NOT SQL"})
+	if err == nil || !strings.Contains(err.Error(), "NOT SQL") {
+		t.Errorf("error = %v, want one naming the failed statement", err)
+	}
+}
+
 func TestExecute_InMemory(t *testing.T) {
 	conn := openMemory(t)
 	defer conn.Close()
@@ -406,8 +609,38 @@ func TestExecute_NullHandling(t *testing.T) {
 	if result.Rows[0][0] != "1" {
 		t.Errorf("Row[0][0] = %q, want %q", result.Rows[0][0], "1")
 	}
-	if result.Rows[0][1] != "NULL" {
-		t.Errorf("Row[0][1] = %q, want %q (NULL representation)", result.Rows[0][1], "NULL")
+	if result.Rows[0][1] != adapter.NullValue {
+		t.Errorf("Row[0][1] = %q, want %q (NULL representation)", result.Rows[0][1], adapter.NullValue)
+	}
+}
+
+func TestExecuteParams(t *testing.T) {
+	conn := openMemory(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	pe, ok := conn.(adapter.ParamExecutor)
+	if !ok {
+		t.Fatal("sqlite connection does not implement ParamExecutor")
+	}
+	if _, err := conn.Execute(ctx, "CREATE TABLE p (id INTEGER, name TEXT)"); err != nil {
+		t.Fatalf("CREATE TABLE error: %v", err)
+	}
+	// A quote in the value needs no escaping.
+	res, err := pe.ExecuteParams(ctx, "INSERT INTO p VALUES (?, ?), (?, ?)", []any{int64(1), "O'Brien", int64(2), nil})
+	if err != nil {
+		t.Fatalf("INSERT error: %v", err)
+	}
+	if res.RowCount != 2 {
+		t.Errorf("RowCount = %d, want 2", res.RowCount)
+	}
+
+	res, err = pe.ExecuteParams(ctx, "SELECT name FROM p WHERE id = ? OR name IS ?", []any{int64(1), nil})
+	if err != nil {
+		t.Fatalf("SELECT error: %v", err)
+	}
+	if len(res.Rows) != 2 || res.Rows[0][0] != "O'Brien" || res.Rows[1][0] != adapter.NullValue {
+		t.Errorf("Rows = %v, want O'Brien and NULL", res.Rows)
 	}
 }
 
@@ -512,6 +745,58 @@ func TestForeignKeys_InMemory(t *testing.T) {
 	}
 }
 
+func TestBatchIntrospection_InMemory(t *testing.T) {
+	conn := openMemory(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE parent (a INTEGER, b TEXT NOT NULL DEFAULT 'x', PRIMARY KEY (a, b))",
+		"CREATE TABLE child (id INTEGER PRIMARY KEY, pa INTEGER, pb TEXT, FOREIGN KEY (pa, pb) REFERENCES parent(a, b))",
+		"CREATE TABLE note (id INTEGER PRIMARY KEY, child_id INTEGER REFERENCES child(id), body TEXT)",
+		"CREATE UNIQUE INDEX idx_note_body ON note(body, child_id)",
+		"CREATE INDEX idx_child_pa ON child(pa)",
+		"CREATE VIEW v AS SELECT * FROM note",
+	} {
+		if _, err := conn.Execute(ctx, stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	batch := conn.(adapter.BatchIntrospector)
+	allCols, err := batch.AllColumns(ctx, ":memory:", "main")
+	if err != nil {
+		t.Fatalf("AllColumns() error: %v", err)
+	}
+	allIdxs, err := batch.AllIndexes(ctx, ":memory:", "main")
+	if err != nil {
+		t.Fatalf("AllIndexes() error: %v", err)
+	}
+	allFKs, err := batch.AllForeignKeys(ctx, ":memory:", "main")
+	if err != nil {
+		t.Fatalf("AllForeignKeys() error: %v", err)
+	}
+	if _, ok := allCols["v"]; ok {
+		t.Error("AllColumns() should list tables only, got the view")
+	}
+
+	// The batch methods agree with the per-table ones.
+	for _, table := range []string{"parent", "child", "note"} {
+		cols, _ := conn.Columns(ctx, ":memory:", "main", table)
+		if !reflect.DeepEqual(allCols[table], cols) {
+			t.Errorf("AllColumns()[%s] = %+v, want %+v", table, allCols[table], cols)
+		}
+		idxs, _ := conn.Indexes(ctx, ":memory:", "main", table)
+		if !reflect.DeepEqual(allIdxs[table], idxs) {
+			t.Errorf("AllIndexes()[%s] = %+v, want %+v", table, allIdxs[table], idxs)
+		}
+		fks, _ := conn.ForeignKeys(ctx, ":memory:", "main", table)
+		if !reflect.DeepEqual(allFKs[table], fks) {
+			t.Errorf("AllForeignKeys()[%s] = %+v, want %+v", table, allFKs[table], fks)
+		}
+	}
+}
+
 func TestCompletions_InMemory(t *testing.T) {
 	conn := openMemory(t)
 	defer conn.Close()
@@ -619,6 +904,281 @@ func TestExecuteStreaming_InMemory(t *testing.T) {
 	}
 }
 
+func TestExecuteStreaming_Seek(t *testing.T) {
+	conn := openMemory(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	if _, err := conn.Execute(ctx, "CREATE TABLE seek_test (id INTEGER PRIMARY KEY)"); err != nil {
+		t.Fatalf("CREATE TABLE error: %v", err)
+	}
+	for i := 1; i <= 10; i++ {
+		if _, err := conn.Execute(ctx, "INSERT INTO seek_test VALUES ("+itoa(i)+")"); err != nil {
+			t.Fatalf("INSERT error: %v", err)
+		}
+	}
+
+	iter, err := conn.ExecuteStreaming(ctx, "SELECT id FROM seek_test ORDER BY id", 3)
+	if err != nil {
+		t.Fatalf("ExecuteStreaming error: %v", err)
+	}
+	defer iter.Close()
+
+	seeker, ok := iter.(adapter.Seeker)
+	if !ok {
+		t.Fatal("sqlite iterator should implement adapter.Seeker")
+	}
+	if err := seeker.Seek(ctx, 7); err != nil {
+		t.Fatalf("Seek error: %v", err)
+	}
+	page, err := iter.FetchNext(ctx)
+	if err != nil {
+		t.Fatalf("FetchNext after Seek error: %v", err)
+	}
+	if len(page) != 3 || page[0][0] != "8" {
+		t.Errorf("page after Seek(7) = %v, want rows 8-10", page)
+	}
+	if _, err := iter.FetchNext(ctx); !adapter.SentinelEOF(err) {
+		t.Errorf("FetchNext past the end = %v, want EOF", err)
+	}
+}
+
+func TestSearchQuery_FindsRowNumber(t *testing.T) {
+	conn := openMemory(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	if _, err := conn.Execute(ctx, "CREATE TABLE search_test (name TEXT)"); err != nil {
+		t.Fatalf("CREATE TABLE error: %v", err)
+	}
+	for _, name := range []string{"alpha", "Beta", "gamma", "beta 100%"} {
+		if _, err := conn.Execute(ctx, "INSERT INTO search_test VALUES ('"+name+"')"); err != nil {
+			t.Fatalf("INSERT error: %v", err)
+		}
+	}
+
+	query := "SELECT name FROM search_test ORDER BY rowid"
+	tests := []struct {
+		text  string
+		after int64
+		want  string
+	}{
+		{"beta", 0, "2"},
+		{"beta", 2, "4"},
+		{"0%", 0, "4"},
+		{"delta", 0, ""},
+	}
+	for _, tt := range tests {
+		res, err := conn.Execute(ctx, adapter.SearchQuery("sqlite", query, "name", tt.text, tt.after))
+		if err != nil {
+			t.Fatalf("search %q error: %v", tt.text, err)
+		}
+		got := ""
+		if len(res.Rows) > 0 {
+			got = res.Rows[0][0]
+		}
+		if got != tt.want {
+			t.Errorf("search %q after %d = %q, want %q", tt.text, tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestProfile_InMemory(t *testing.T) {
+	conn := openMemory(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE people (id INTEGER PRIMARY KEY, city TEXT)",
+		"INSERT INTO people (city) VALUES ('Oslo'), ('Oslo'), ('Rome'), (NULL), ('Oslo')",
+	} {
+		if _, err := conn.Execute(ctx, stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	cols := []schema.Column{{Name: "id", Type: "INTEGER"}, {Name: "city", Type: "TEXT"}}
+	res, err := conn.Execute(ctx, adapter.ProfileQuery("sqlite", `"people"`, cols, 4))
+	if err != nil {
+		t.Fatalf("profile error: %v", err)
+	}
+	p, err := adapter.ParseProfile(cols, res.Rows[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	city := p.Columns[1]
+	if p.Rows != 4 || city.Nulls != 1 || city.Distinct != 2 || city.Min != "Oslo" || city.Max != "Rome" || city.AvgLength != 4 {
+		t.Errorf("profile of the first 4 rows = %d rows, city %+v", p.Rows, city)
+	}
+
+	res, err = conn.Execute(ctx, adapter.TopValuesQuery("sqlite", `"people"`, cols[1], 5, 2))
+	if err != nil {
+		t.Fatalf("top values error: %v", err)
+	}
+	top := adapter.ParseTopValues(res.Rows)
+	if len(top) != 2 || top[0] != (adapter.ValueCount{Value: "Oslo", Count: 3}) {
+		t.Errorf("top values = %v, want Oslo 3 first", top)
+	}
+}
+
+func TestTableDDL_InMemory(t *testing.T) {
+	conn := openMemory(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE ddl_test (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+		"CREATE INDEX idx_ddl_name ON ddl_test(name)",
+	} {
+		if _, err := conn.Execute(ctx, stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	p, ok := conn.(adapter.DDLProvider)
+	if !ok {
+		t.Fatal("sqlite connection should implement adapter.DDLProvider")
+	}
+	got, err := p.TableDDL(ctx, "main", "main", "ddl_test")
+	if err != nil {
+		t.Fatalf("TableDDL error: %v", err)
+	}
+	want := "CREATE TABLE ddl_test (id INTEGER PRIMARY KEY, name TEXT NOT NULL);\n\n" +
+		"CREATE INDEX idx_ddl_name ON ddl_test(name);"
+	if got != want {
+		t.Errorf("TableDDL =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, err := p.TableDDL(ctx, "main", "main", "missing"); err == nil {
+		t.Error("TableDDL of a missing table should fail")
+	}
+}
+
+func TestTableStats_InMemory(t *testing.T) {
+	conn := openMemory(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE stats_test (id INTEGER PRIMARY KEY)",
+		"INSERT INTO stats_test VALUES (1), (2), (3)",
+		"CREATE TABLE stats_empty (id INTEGER)",
+	} {
+		if _, err := conn.Execute(ctx, stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	p, ok := conn.(adapter.TableStatsProvider)
+	if !ok {
+		t.Fatal("sqlite connection should implement adapter.TableStatsProvider")
+	}
+	stats, err := p.TableStats(ctx, "main", "main")
+	if err != nil {
+		t.Fatalf("TableStats error: %v", err)
+	}
+	if got := stats["stats_test"].Rows; got != 3 {
+		t.Errorf("stats_test rows = %d, want 3", got)
+	}
+	if got := stats["stats_empty"].Rows; got != 0 {
+		t.Errorf("stats_empty rows = %d, want 0", got)
+	}
+}
+
+func TestTriggers_InMemory(t *testing.T) {
+	conn := openMemory(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE trig_test (id INTEGER, updated TEXT)",
+		"CREATE TRIGGER trig_touch AFTER UPDATE OF id ON trig_test BEGIN UPDATE trig_test SET updated = 'x'; END",
+		"CREATE TRIGGER trig_check INSERT ON trig_test BEGIN SELECT 1; END",
+	} {
+		if _, err := conn.Execute(ctx, stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	p, ok := conn.(adapter.ObjectIntrospector)
+	if !ok {
+		t.Fatal("sqlite connection should implement adapter.ObjectIntrospector")
+	}
+	triggers, err := p.Triggers(ctx, "main", "main")
+	if err != nil {
+		t.Fatalf("Triggers error: %v", err)
+	}
+	want := []schema.Trigger{
+		{Name: "trig_check", Table: "trig_test", Timing: "BEFORE", Event: "INSERT"},
+		{Name: "trig_touch", Table: "trig_test", Timing: "AFTER", Event: "UPDATE"},
+	}
+	if len(triggers) != len(want) {
+		t.Fatalf("got %d triggers, want %d: %+v", len(triggers), len(want), triggers)
+	}
+	for i := range want {
+		if triggers[i] != want[i] {
+			t.Errorf("trigger %d = %+v, want %+v", i, triggers[i], want[i])
+		}
+	}
+}
+
+func TestDependencies_InMemory(t *testing.T) {
+	conn := openMemory(t)
+	defer conn.Close()
+
+	ctx := context.Background()
+	for _, stmt := range []string{
+		"CREATE TABLE customers (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, customer_id INTEGER REFERENCES customers(id))",
+		"CREATE TABLE audit (what TEXT)",
+		`CREATE VIEW big_customers AS SELECT c.name FROM "customers" c JOIN orders o ON o.customer_id = c.id`,
+		"CREATE VIEW names AS SELECT name FROM big_customers WHERE name <> 'orders'",
+		"CREATE TRIGGER customers_audit AFTER DELETE ON customers BEGIN INSERT INTO audit VALUES ('customer'); END",
+		"CREATE TRIGGER orders_check BEFORE INSERT ON orders BEGIN SELECT id FROM customers; END",
+	} {
+		if _, err := conn.Execute(ctx, stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	p, ok := conn.(adapter.DependencyProvider)
+	if !ok {
+		t.Fatal("sqlite connection should implement adapter.DependencyProvider")
+	}
+
+	deps, err := p.Dependencies(ctx, "main", "main", "customers")
+	if err != nil {
+		t.Fatalf("Dependencies error: %v", err)
+	}
+	wantDependents := []adapter.Dependency{
+		{Kind: "view", Schema: "main", Name: "big_customers"},
+		{Kind: "trigger", Schema: "main", Name: "customers_audit", Detail: "AFTER DELETE"},
+		{Kind: "table", Schema: "main", Name: "orders", Detail: "FOREIGN KEY (customer_id) REFERENCES customers(id)"},
+		{Kind: "trigger", Schema: "main", Name: "orders_check", Detail: "on orders"},
+	}
+	wantDependsOn := []adapter.Dependency{
+		{Kind: "table", Schema: "main", Name: "audit", Detail: "used by trigger customers_audit"},
+	}
+	if !reflect.DeepEqual(deps.Dependents, wantDependents) {
+		t.Errorf("dependents of customers = %+v, want %+v", deps.Dependents, wantDependents)
+	}
+	if !reflect.DeepEqual(deps.DependsOn, wantDependsOn) {
+		t.Errorf("customers depends on %+v, want %+v", deps.DependsOn, wantDependsOn)
+	}
+
+	// The string 'orders' is not a reference.
+	deps, err = p.Dependencies(ctx, "main", "main", "names")
+	if err != nil {
+		t.Fatalf("Dependencies error: %v", err)
+	}
+	wantDependsOn = []adapter.Dependency{{Kind: "view", Schema: "main", Name: "big_customers"}}
+	if len(deps.Dependents) != 0 || !reflect.DeepEqual(deps.DependsOn, wantDependsOn) {
+		t.Errorf("dependencies of names = %+v, want it to depend on big_customers alone", deps)
+	}
+
+	if _, err := p.Dependencies(ctx, "main", "main", "missing"); err == nil {
+		t.Error("expected an error for a missing table")
+	}
+}
+
 func TestExecuteStreaming_10MillionRows(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping 10M row test in short mode")
@@ -742,6 +1302,84 @@ func TestCancel_InMemory(t *testing.T) {
 	}
 }
 
+func TestTransaction(t *testing.T) {
+	a := &sqliteAdapter{}
+	ctx := context.Background()
+	conn, err := a.Connect(ctx, filepath.Join(t.TempDir(), "tx.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	tx := conn.(adapter.Transactor)
+	if _, err := conn.Execute(ctx, "CREATE TABLE t (id INTEGER)"); err != nil {
+		t.Fatal(err)
+	}
+
+	// count reads on another of the pool's connections, outside the
+	// transaction.
+	count := func() int {
+		var n int
+		if err := conn.(*sqliteConn).db.QueryRowContext(ctx, "SELECT COUNT(*) FROM t").Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+
+	if err := tx.Commit(ctx); !errors.Is(err, adapter.ErrNoTransaction) {
+		t.Errorf("Commit() with no transaction = %v, want ErrNoTransaction", err)
+	}
+
+	if err := tx.Begin(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !tx.InTransaction() {
+		t.Error("InTransaction() = false after Begin")
+	}
+	if err := tx.Begin(ctx); !errors.Is(err, adapter.ErrTransactionActive) {
+		t.Errorf("second Begin() = %v, want ErrTransactionActive", err)
+	}
+	if _, err := conn.Execute(ctx, "INSERT INTO t VALUES (1)"); err != nil {
+		t.Fatal(err)
+	}
+	res, err := conn.Execute(ctx, "SELECT COUNT(*) FROM t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Rows[0][0] != "1" {
+		t.Errorf("count inside the transaction = %s, want 1", res.Rows[0][0])
+	}
+	// The app's own queries run on the pool, outside it.
+	res, err = conn.Execute(adapter.OnPool(ctx), "SELECT COUNT(*) FROM t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Rows[0][0] != "0" {
+		t.Errorf("count on the pool = %s, want 0", res.Rows[0][0])
+	}
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if tx.InTransaction() {
+		t.Error("InTransaction() = true after Rollback")
+	}
+	if n := count(); n != 0 {
+		t.Errorf("rows after rollback = %d, want 0", n)
+	}
+
+	if err := tx.Begin(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Execute(ctx, "INSERT INTO t VALUES (2)"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := count(); n != 1 {
+		t.Errorf("rows after commit = %d, want 1", n)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Helpers
 // ---------------------------------------------------------------------------
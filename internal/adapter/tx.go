@@ -0,0 +1,121 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+var (
+	ErrNoTransaction     = errors.New("no transaction in progress")
+	ErrTransactionActive = errors.New("a transaction is already in progress")
+)
+
+// Transactor is an optional interface that connections can implement to
+// run statements in an explicit transaction. Between Begin and Commit or
+// Rollback, Execute runs on the session holding the transaction;
+// introspection and streaming keep using the rest of the pool, so they do
+// not see its uncommitted changes.
+type Transactor interface {
+	Begin(ctx context.Context) error
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+	InTransaction() bool
+}
+
+type poolKey struct{}
+
+// OnPool returns ctx marked to run Execute on the pool even while a
+// transaction is open. The app runs its own queries, such as counting the
+// rows a DROP removes, under it: the user's transaction neither sees them
+// nor, on PostgreSQL, is aborted when one fails.
+func OnPool(ctx context.Context) context.Context {
+	return context.WithValue(ctx, poolKey{}, true)
+}
+
+// WantsPool reports whether ctx was marked by OnPool.
+func WantsPool(ctx context.Context) bool {
+	on, _ := ctx.Value(poolKey{}).(bool)
+	return on
+}
+
+// Querier is the part of *sql.DB, *sql.Conn and *sql.Tx that runs
+// statements.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// SQLTx holds the explicit transaction of a connection built on a
+// database/sql pool. The zero value has no transaction open.
+type SQLTx struct {
+	mu sync.Mutex
+	tx *sql.Tx
+}
+
+// Begin starts a transaction on one of db's connections. The transaction
+// outlives ctx, which only bounds starting it.
+func (t *SQLTx) Begin(ctx context.Context, db *sql.DB) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.tx != nil {
+		return ErrTransactionActive
+	}
+	// database/sql rolls a transaction back when its context is cancelled.
+	tx, err := db.BeginTx(context.WithoutCancel(ctx), nil)
+	if err != nil {
+		return err
+	}
+	t.tx = tx
+	return nil
+}
+
+// Commit commits the open transaction. The transaction is over even when
+// committing fails.
+func (t *SQLTx) Commit() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.tx == nil {
+		return ErrNoTransaction
+	}
+	err := t.tx.Commit()
+	t.tx = nil
+	return err
+}
+
+// Rollback rolls back the open transaction.
+func (t *SQLTx) Rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.tx == nil {
+		return ErrNoTransaction
+	}
+	err := t.tx.Rollback()
+	t.tx = nil
+	return err
+}
+
+// Active returns whether a transaction is open.
+func (t *SQLTx) Active() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tx != nil
+}
+
+// Tx returns the open transaction, or nil.
+func (t *SQLTx) Tx() *sql.Tx {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tx
+}
+
+// On returns the open transaction, or db when there is none or ctx wants
+// the pool.
+func (t *SQLTx) On(ctx context.Context, db *sql.DB) Querier {
+	if tx := t.Tx(); tx != nil && !WantsPool(ctx) {
+		return tx
+	}
+	return db
+}
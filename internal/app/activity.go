@@ -0,0 +1,119 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+)
+
+// activityTimeout bounds listing the sessions and signalling one.
+const activityTimeout = 10 * time.Second
+
+// activityLoadedMsg carries the sessions listed on connection generation
+// connGen.
+type activityLoadedMsg struct {
+	backends []adapter.Backend
+	err      error
+	connGen  uint64
+}
+
+// activitySignalledMsg reports a query cancelled or a session ended.
+type activitySignalledMsg struct {
+	id        int64
+	terminate bool
+	err       error
+	connGen   uint64
+}
+
+// activityMonitor returns the connection if it can list the sessions on
+// the server, or nil.
+func (m *Model) activityMonitor() adapter.ActivityMonitor {
+	am, _ := m.conn.(adapter.ActivityMonitor)
+	return am
+}
+
+// openActivity opens the session manager (Alt+A) and lists the sessions,
+// again every few seconds while auto-refresh is on.
+func (m *Model) openActivity() tea.Cmd {
+	if m.activityMonitor() == nil {
+		text := "Not connected"
+		if m.conn != nil {
+			text = "The session list is not available for " + m.conn.AdapterName()
+		}
+		return m.toast(ToastError, text)
+	}
+	return tea.Batch(m.activity.Show(), m.loadActivity())
+}
+
+// loadActivity lists the sessions in the background.
+func (m *Model) loadActivity() tea.Cmd {
+	am := m.activityMonitor()
+	if am == nil {
+		return nil
+	}
+	gen := m.connGen
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), activityTimeout)
+		defer cancel()
+		backends, err := am.Activity(ctx)
+		return activityLoadedMsg{backends: backends, err: err, connGen: gen}
+	}
+}
+
+// handleActivityLoaded shows the sessions listed.
+func (m *Model) handleActivityLoaded(msg activityLoadedMsg) {
+	if msg.connGen != m.connGen {
+		return
+	}
+	if msg.err != nil {
+		m.activity.SetMessage("Could not list the sessions: "+sanitizeError(msg.err.Error()), false)
+		return
+	}
+	m.activity.SetBackends(msg.backends)
+}
+
+// signalBackend cancels the query of session id, or ends the session, and
+// records the statement that did. Safe mode blocks both.
+func (m *Model) signalBackend(id int64, terminate bool) tea.Cmd {
+	am := m.activityMonitor()
+	if am == nil {
+		return nil
+	}
+	if m.safeMode {
+		m.activity.SetMessage(safeModeBlocked, false)
+		return nil
+	}
+	gen, writes := m.connGen, m.writes()
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(writes.recording(context.Background()), activityTimeout)
+		defer cancel()
+		var err error
+		if terminate {
+			err = am.TerminateBackend(ctx, id)
+		} else {
+			err = am.CancelBackend(ctx, id)
+		}
+		return activitySignalledMsg{id: id, terminate: terminate, err: err, connGen: gen}
+	}
+}
+
+// handleActivitySignalled reports a cancel or an end and lists the sessions
+// again.
+func (m *Model) handleActivitySignalled(msg activitySignalledMsg) tea.Cmd {
+	if msg.connGen != m.connGen {
+		return nil
+	}
+	if msg.err != nil {
+		m.activity.SetMessage(sanitizeError(msg.err.Error()), false)
+		return nil
+	}
+	text := fmt.Sprintf("Cancelled the query of session %d", msg.id)
+	if msg.terminate {
+		text = fmt.Sprintf("Ended session %d", msg.id)
+	}
+	m.activity.SetMessage(text, true)
+	return m.loadActivity()
+}
@@ -0,0 +1,55 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/ui/activity"
+)
+
+// activityConn is a postgres testConn listing backends.
+type activityConn struct {
+	pgConn
+	backends   []adapter.Backend
+	terminated *[]int64
+}
+
+func (c activityConn) Activity(context.Context) ([]adapter.Backend, error) { return c.backends, nil }
+
+func (c activityConn) CancelBackend(context.Context, int64) error { return nil }
+
+func (c activityConn) TerminateBackend(_ context.Context, id int64) error {
+	*c.terminated = append(*c.terminated, id)
+	return nil
+}
+
+func TestActivity(t *testing.T) {
+	m := step(New(config.DefaultConfig(), nil, nil), tea.WindowSizeMsg{Width: 160, Height: 40})
+	var terminated []int64
+	m.conn = activityConn{pgConn: pgConn{&testConn{dbName: "app"}}, backends: []adapter.Backend{{ID: 7, Query: "SELECT pg_sleep(60)"}}, terminated: &terminated}
+
+	m = step(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a"), Alt: true})
+	if !m.activity.Visible() || len(m.activity.Backends()) != 1 {
+		t.Fatalf("Alt+A should list the sessions, got %v", m.activity.Backends())
+	}
+
+	m.safeMode = true
+	m = step(m, activity.TerminateMsg{ID: 7})
+	if len(terminated) != 0 {
+		t.Error("safe mode should not end sessions")
+	}
+	m.safeMode = false
+	m = step(m, activity.TerminateMsg{ID: 7})
+	if len(terminated) != 1 || terminated[0] != 7 {
+		t.Errorf("terminated %v, want 7", terminated)
+	}
+
+	m.activity.Hide()
+	m.conn = sqliteConn{&testConn{dbName: "app"}}
+	if m.openActivity(); m.activity.Visible() {
+		t.Error("the session manager opened on sqlite")
+	}
+}
@@ -2,6 +2,7 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
@@ -19,15 +21,38 @@ import (
 	"github.com/sadopc/gotermsql/internal/config"
 	"github.com/sadopc/gotermsql/internal/history"
 	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/schemacache"
+	"github.com/sadopc/gotermsql/internal/telemetry"
 	"github.com/sadopc/gotermsql/internal/theme"
+	"github.com/sadopc/gotermsql/internal/tunnel"
+	"github.com/sadopc/gotermsql/internal/ui/activity"
 	"github.com/sadopc/gotermsql/internal/ui/autocomplete"
+	"github.com/sadopc/gotermsql/internal/ui/bulkupdate"
 	"github.com/sadopc/gotermsql/internal/ui/connmgr"
+	"github.com/sadopc/gotermsql/internal/ui/datadiff"
+	"github.com/sadopc/gotermsql/internal/ui/datagen"
+	"github.com/sadopc/gotermsql/internal/ui/dependencies"
+	"github.com/sadopc/gotermsql/internal/ui/dialog"
+	"github.com/sadopc/gotermsql/internal/ui/dump"
 	"github.com/sadopc/gotermsql/internal/ui/editor"
+	"github.com/sadopc/gotermsql/internal/ui/erdiagram"
 	"github.com/sadopc/gotermsql/internal/ui/historybrowser"
+	"github.com/sadopc/gotermsql/internal/ui/listen"
+	"github.com/sadopc/gotermsql/internal/ui/maintenance"
+	"github.com/sadopc/gotermsql/internal/ui/objectsearch"
+	"github.com/sadopc/gotermsql/internal/ui/params"
+	"github.com/sadopc/gotermsql/internal/ui/profiler"
+	"github.com/sadopc/gotermsql/internal/ui/querylib"
 	"github.com/sadopc/gotermsql/internal/ui/results"
+	"github.com/sadopc/gotermsql/internal/ui/rowform"
+	"github.com/sadopc/gotermsql/internal/ui/schemadiff"
 	"github.com/sadopc/gotermsql/internal/ui/sidebar"
 	"github.com/sadopc/gotermsql/internal/ui/statusbar"
+	"github.com/sadopc/gotermsql/internal/ui/switcher"
 	"github.com/sadopc/gotermsql/internal/ui/tabs"
+	"github.com/sadopc/gotermsql/internal/ui/toast"
+	"github.com/sadopc/gotermsql/internal/ui/transfer"
+	"github.com/sadopc/gotermsql/internal/ui/viewer"
 )
 
 // TabState holds per-tab state.
@@ -36,6 +61,28 @@ type TabState struct {
 	Results results.Model
 	Query   string
 	RunID   uint64
+
+	// Ran is Query as it was sent, with the LIMIT results.auto_limit
+	// added, which AutoLimit then holds; it is Query and 0 otherwise.
+	Ran       string
+	AutoLimit int
+
+	Lint []string // warnings about the query last run from the editor
+
+	File string // the file the editor was last read from or written to with :e or :w
+
+	started time.Time // when the query last run was sent
+
+	countCancel context.CancelFunc // background total-count query, if running
+	copying     *copyProgress      // the \copy running, if any
+}
+
+// sent returns the query the tab's result came from, as it was run.
+func (ts *TabState) sent() string {
+	if ts.Ran != "" {
+		return ts.Ran
+	}
+	return ts.Query
 }
 
 // Model is the root application model.
@@ -45,7 +92,10 @@ type Model struct {
 	height       int
 	sidebarWidth int
 	editorHeight int // percentage of main area for editor (rest for results)
+	editorWidth  int // the same, with the results beside the editor
+	sideBySide   bool
 	showSidebar  bool
+	drag         divider // pane divider being dragged with the mouse
 
 	// Focus
 	focusedPane Pane
@@ -56,7 +106,27 @@ type Model struct {
 	statusbar   statusbar.Model
 	connMgr     connmgr.Model
 	histBrowser historybrowser.Model
+	queryLib    querylib.Model
+	params      params.Model
+	listen      listen.Model
+	activity    activity.Model
+	maintenance maintenance.Model
+	schemaDiff  schemadiff.Model
+	erDiagram   erdiagram.Model
+	profiler    profiler.Model
+	depBrowser  dependencies.Model
+	transfer    transfer.Model
+	rowForm     rowform.Model
+	dataGen     datagen.Model
+	bulkUpdate  bulkupdate.Model
+	dataDiff    datadiff.Model
+	dump        dump.Model
+	switcher    switcher.Model
+	objSearch   objectsearch.Model
+	viewer      viewer.Model
 	autocomp    autocomplete.Model
+	dialog      dialog.Model
+	toasts      toast.Model
 
 	// Per-tab state
 	tabStates map[int]*TabState
@@ -65,15 +135,105 @@ type Model struct {
 	conn       adapter.Connection
 	cancelFunc context.CancelFunc
 	connGen    uint64
+	tunnel     *tunnel.Tunnel // SSH tunnel conn runs through, or nil
+
+	// maintCancel stops the maintenance command running, or is nil.
+	maintCancel context.CancelFunc
+
+	// snapshotDir keeps the schema snapshots, "" for none; snapshots are
+	// the ones last read from it. diffCancel stops the schemas of the
+	// comparison loading, and diffGen drops the replies of an earlier one.
+	snapshotDir string
+	snapshots   []schemacache.Snapshot
+	diffCancel  context.CancelFunc
+	diffGen     int
+
+	// profileCancel stops the table being profiled, and profileGen drops
+	// the profiles of a panel closed or opened again since.
+	profileCancel context.CancelFunc
+	profileGen    int
+
+	// depsFor is the table or view the dependency browser was opened on
+	// last; the dependencies read for another are dropped.
+	depsFor DependenciesMsg
+
+	// transferFrom is the table or view the copy wizard was opened on;
+	// transferCancel stops its copy running, transferGen drops the replies
+	// of an earlier one, and transferProgress counts the rows copied.
+	transferFrom     TransferTableMsg
+	transferCancel   context.CancelFunc
+	transferGen      int
+	transferProgress *rowProgress
+
+	// rowFormFor is the table the insert form was asked for last; the
+	// columns read for another are dropped.
+	rowFormFor InsertRowMsg
+
+	// dataGenFor is the table the test data generator fills;
+	// dataGenCancel stops its run, dataGenGen drops the replies of an
+	// earlier one, and dataGenProgress counts the rows inserted.
+	dataGenFor      GenerateDataMsg
+	dataGenCancel   context.CancelFunc
+	dataGenGen      int
+	dataGenProgress *rowProgress
+
+	// bulkUpdateFor is the table the bulk UPDATE wizard was asked for
+	// last, and bulkUpdateStmt the UPDATE it previews; a preview of
+	// another is dropped.
+	bulkUpdateFor  BulkUpdateMsg
+	bulkUpdateStmt string
+
+	// dataDiffFor is the table whose rows are compared; dataDiffCancel
+	// stops the comparison, dataDiffGen drops the replies of an earlier
+	// one, and dataDiffProgress counts the rows compared.
+	dataDiffFor      CompareDataMsg
+	dataDiffCancel   context.CancelFunc
+	dataDiffGen      int
+	dataDiffProgress *rowProgress
+
+	// dumpFor is the table dumped to a file; dumpCancel stops the dump,
+	// dumpGen drops the replies of an earlier one, and dumpProgress counts
+	// the rows written.
+	dumpFor      DumpTableMsg
+	dumpCancel   context.CancelFunc
+	dumpGen      int
+	dumpProgress *rowProgress
+
+	// listener is the session listening for notifications on conn, or nil;
+	// listenPending are the channels to listen on once it has opened.
+	listener      adapter.Listener
+	listenPending []string
 
 	// Engine
 	compEngine *completion.Engine
 
 	// Config
-	cfg     *config.Config
-	history *history.History
-	audit   *audit.Logger
-	dsn     string
+	cfg      *config.Config
+	history  *history.History
+	audit    *audit.Logger
+	tracer   *telemetry.Tracer // nil when tracing is off
+	redactor *adapter.Redactor // masks secrets in saved queries; nil = off
+	dsn      string
+	connName string // saved connection connected to, "" for ad hoc
+
+	// execDefaults are the execution defaults of the saved connection, or nil.
+	execDefaults *config.ExecDefaults
+
+	// autoConnecting names the saved connection being connected to on
+	// startup, until it connects, fails or a key aborts it.
+	autoConnecting string
+	autoCancel     context.CancelFunc
+
+	// restoring is set while the startup offer to restore the last session
+	// is shown; restoreConnMgr when the connection manager waits for it.
+	restoring      bool
+	restoreConnMgr bool
+
+	// safeMode blocks every statement that is not read-only (F3).
+	safeMode bool
+	// autoCommit commits each statement as it runs; when off, they run in
+	// a transaction left open until committed or rolled back (F4).
+	autoCommit bool
 
 	// Keybinding
 	keyMap   KeyMap
@@ -81,7 +241,11 @@ type Model struct {
 	vimState VimState
 
 	// Schema loading
-	schemaCancel context.CancelFunc
+	schemaCancel  context.CancelFunc
+	databases     []schema.Database // last loaded schema
+	schemaRefresh bool              // the schema being loaded was asked for again; toast when done
+	schemaCache   *schemacache.Cache
+	schemaHash    string // schemacache.Hash of the schema shown, "" until one is
 
 	// State
 	showHelp       bool
@@ -89,6 +253,34 @@ type Model struct {
 	executing      bool
 	executingTabID int
 	quitting       bool
+
+	// leaderOn is set once the leader key is pressed, while the keys of a
+	// leader mapping, leader, are typed.
+	leaderOn bool
+	leader   []string
+
+	// chord is the first key of a two-key chord, while the second is
+	// awaited; chordReplay is set while keys that made no chord are
+	// handled as if typed alone.
+	chord       *tea.KeyMsg
+	chordReplay bool
+
+	// zen shows only the editor, or the results while they are focused;
+	// zenFrom is the pane focused when it was turned on.
+	zen     bool
+	zenFrom Pane
+
+	// split shows a second tab's editor next to the active one, or nil.
+	split *editorSplit
+
+	// blurred is set while the terminal window has lost focus.
+	blurred bool
+
+	// schedules are the library queries run every so often, by library
+	// and path; scheduleLog holds their runs, newest first.
+	schedules   map[string]*schedule
+	scheduleGen uint64
+	scheduleLog []scheduledRun
 }
 
 // New creates a new app model.
@@ -97,13 +289,14 @@ func New(cfg *config.Config, hist *history.History, auditLog *audit.Logger) Mode
 	var km KeyMap
 	if keyMode == KeyModeVim {
 		km = VimKeyMap()
+		km.Sequences = append(km.Sequences, leaderSequences(cfg.Mappings)...)
 	} else {
 		km = StandardKeyMap()
 	}
 
 	// Set theme
 	if t := theme.Get(cfg.Theme); t != nil {
-		theme.Current = t
+		theme.Current = theme.ForProfile(t, lipgloss.ColorProfile())
 	}
 
 	compEngine := completion.NewEngine("sql")
@@ -111,6 +304,8 @@ func New(cfg *config.Config, hist *history.History, auditLog *audit.Logger) Mode
 	m := Model{
 		sidebarWidth: 30,
 		editorHeight: 50,
+		editorWidth:  50,
+		sideBySide:   cfg.Layout == config.LayoutSideBySide,
 		showSidebar:  true,
 		focusedPane:  PaneEditor,
 
@@ -119,6 +314,25 @@ func New(cfg *config.Config, hist *history.History, auditLog *audit.Logger) Mode
 		statusbar:   statusbar.New(),
 		connMgr:     connmgr.New(cfg.Connections),
 		histBrowser: historybrowser.New(hist),
+		queryLib:    querylib.New(),
+		params:      params.New(),
+		listen:      listen.New(),
+		activity:    activity.New(),
+		maintenance: maintenance.New(),
+		schemaDiff:  schemadiff.New(),
+		erDiagram:   erdiagram.New(),
+		profiler:    profiler.New(),
+		depBrowser:  dependencies.New(),
+		transfer:    transfer.New(),
+		rowForm:     rowform.New(),
+		dataGen:     datagen.New(),
+		bulkUpdate:  bulkupdate.New(),
+		dataDiff:    datadiff.New(),
+		dump:        dump.New(),
+		switcher:    switcher.New(),
+		objSearch:   objectsearch.New(compEngine),
+		viewer:      viewer.New(),
+		toasts:      toast.New(),
 		autocomp:    autocomplete.New(compEngine),
 
 		tabStates:  make(map[int]*TabState),
@@ -128,23 +342,62 @@ func New(cfg *config.Config, hist *history.History, auditLog *audit.Logger) Mode
 		audit:      auditLog,
 		keyMap:     km,
 		keyMode:    keyMode,
+		safeMode:   cfg.SafeMode,
+		autoCommit: true,
 	}
 
 	// Initialize first tab state
 	ed := editor.New(0)
 	ed.Focus()
+	ed.SetVim(keyMode == KeyModeVim)
 	m.tabStates[0] = &TabState{
 		Editor:  ed,
-		Results: results.New(0),
+		Results: m.newResults(0),
 	}
 
+	m.sidebar.SetShowStats(cfg.Sidebar.TableStats)
 	m.statusbar.SetKeyMode(keyMode)
+	m.statusbar.SetSafeMode(cfg.SafeMode)
+	m.statusbar.SetLayout(cfg.StatusBarLayout())
 	return m
 }
 
+// newResults creates a results pane for a tab with the configured display
+// options.
+func (m *Model) newResults(tabID int) results.Model {
+	r := results.New(tabID)
+	if m.cfg != nil {
+		r.SetOptions(results.Options{
+			RowNumbers:  m.cfg.Results.RowNumbers,
+			StickyFirst: m.cfg.Results.StickyFirstColumn,
+			NullText:    m.cfg.Results.NullDisplay,
+		})
+		r.SetPaging(m.pageSize(), m.cfg.Results.MaxBufferedRows)
+		r.SetHistorySize(m.cfg.Results.ResultHistory)
+	}
+	return r
+}
+
+// pageSize returns the streaming page size: the connection's default, or
+// the configured one.
+func (m *Model) pageSize() int {
+	if m.execDefaults != nil && m.execDefaults.PageSize > 0 {
+		return m.execDefaults.PageSize
+	}
+	return m.cfg.Results.PageSize
+}
+
 // Init initializes the application.
 func (m Model) Init() tea.Cmd {
-	return nil
+	cmds := []tea.Cmd{m.statusbar.Init()}
+	if conflicts := KeyConflicts(m.cfg); len(conflicts) > 0 {
+		text := "Key binding conflict: " + conflicts[0]
+		if len(conflicts) > 1 {
+			text += fmt.Sprintf(" (and %d more)", len(conflicts)-1)
+		}
+		cmds = append(cmds, func() tea.Msg { return StatusMsg{Text: text, IsError: true} })
+	}
+	return tea.Batch(cmds...)
 }
 
 // Update handles all messages.
@@ -152,6 +405,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
+	case tea.FocusMsg:
+		m.blurred = false
+
+	case tea.BlurMsg:
+		m.blurred = true
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height + heightOffset()
@@ -159,6 +418,24 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
+		// The startup offer to restore the last session comes first
+		if m.restoring {
+			var cmd tea.Cmd
+			m.dialog, cmd = m.dialog.Update(msg)
+			if !m.dialog.Visible() {
+				m.restoring = false
+				if cmd == nil { // dismissed with Esc
+					cmd = func() tea.Msg { return restoreSessionMsg{} }
+				}
+			}
+			return m, cmd
+		}
+
+		// Any key aborts the startup auto-connect
+		if m.autoConnecting != "" {
+			return m, m.abortAutoConnect()
+		}
+
 		// Connection manager takes priority
 		if m.connMgr.Visible() {
 			var cmd tea.Cmd
@@ -169,6 +446,26 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Batch(cmds...)
 		}
 
+		// Connection switcher takes priority when visible
+		if m.switcher.Visible() {
+			var cmd tea.Cmd
+			m.switcher, cmd = m.switcher.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			return m, tea.Batch(cmds...)
+		}
+
+		// Object search takes priority when visible
+		if m.objSearch.Visible() {
+			var cmd tea.Cmd
+			m.objSearch, cmd = m.objSearch.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			return m, tea.Batch(cmds...)
+		}
+
 		// History browser takes priority when visible
 		if m.histBrowser.Visible() {
 			var cmd tea.Cmd
@@ -179,6 +476,176 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Batch(cmds...)
 		}
 
+		// Query library takes priority when visible
+		if m.queryLib.Visible() {
+			var cmd tea.Cmd
+			m.queryLib, cmd = m.queryLib.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			return m, tea.Batch(cmds...)
+		}
+
+		// Parameter prompt takes priority when visible
+		if m.params.Visible() {
+			var cmd tea.Cmd
+			m.params, cmd = m.params.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			return m, tea.Batch(cmds...)
+		}
+
+		// LISTEN/NOTIFY panel takes priority when visible
+		if m.listen.Visible() {
+			var cmd tea.Cmd
+			m.listen, cmd = m.listen.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			return m, tea.Batch(cmds...)
+		}
+
+		// Session manager takes priority when visible
+		if m.activity.Visible() {
+			var cmd tea.Cmd
+			m.activity, cmd = m.activity.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			return m, tea.Batch(cmds...)
+		}
+
+		// Maintenance panel takes priority when visible
+		if m.maintenance.Visible() {
+			var cmd tea.Cmd
+			m.maintenance, cmd = m.maintenance.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			return m, tea.Batch(cmds...)
+		}
+
+		// Schema comparison takes priority when visible
+		if m.schemaDiff.Visible() {
+			var cmd tea.Cmd
+			m.schemaDiff, cmd = m.schemaDiff.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			return m, tea.Batch(cmds...)
+		}
+
+		// ER diagram takes priority when visible
+		if m.erDiagram.Visible() {
+			var cmd tea.Cmd
+			m.erDiagram, cmd = m.erDiagram.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			return m, tea.Batch(cmds...)
+		}
+
+		// Table profile takes priority when visible
+		if m.profiler.Visible() {
+			var cmd tea.Cmd
+			m.profiler, cmd = m.profiler.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			return m, tea.Batch(cmds...)
+		}
+
+		// Dependency browser takes priority when visible
+		if m.depBrowser.Visible() {
+			var cmd tea.Cmd
+			m.depBrowser, cmd = m.depBrowser.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			return m, tea.Batch(cmds...)
+		}
+
+		// Copy wizard takes priority when visible
+		if m.transfer.Visible() {
+			var cmd tea.Cmd
+			m.transfer, cmd = m.transfer.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			return m, tea.Batch(cmds...)
+		}
+
+		// Insert form takes priority when visible
+		if m.rowForm.Visible() {
+			var cmd tea.Cmd
+			m.rowForm, cmd = m.rowForm.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			return m, tea.Batch(cmds...)
+		}
+
+		// Test data generator takes priority when visible
+		if m.dataGen.Visible() {
+			var cmd tea.Cmd
+			m.dataGen, cmd = m.dataGen.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			return m, tea.Batch(cmds...)
+		}
+
+		// Bulk UPDATE wizard takes priority when visible
+		if m.bulkUpdate.Visible() {
+			var cmd tea.Cmd
+			m.bulkUpdate, cmd = m.bulkUpdate.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			return m, tea.Batch(cmds...)
+		}
+
+		// Data comparison takes priority when visible
+		if m.dataDiff.Visible() {
+			var cmd tea.Cmd
+			m.dataDiff, cmd = m.dataDiff.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			return m, tea.Batch(cmds...)
+		}
+
+		// Table dump takes priority when visible
+		if m.dump.Visible() {
+			var cmd tea.Cmd
+			m.dump, cmd = m.dump.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			return m, tea.Batch(cmds...)
+		}
+
+		// Text viewer (table DDL) takes priority when visible
+		if m.viewer.Visible() {
+			var cmd tea.Cmd
+			m.viewer, cmd = m.viewer.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			return m, tea.Batch(cmds...)
+		}
+
+		// Confirmation dialog takes priority when visible
+		if m.dialog.Visible() {
+			var cmd tea.Cmd
+			m.dialog, cmd = m.dialog.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			return m, tea.Batch(cmds...)
+		}
+
 		// Help overlay consumes all keys except toggle/close
 		if m.showHelp {
 			if msg.String() == "f1" || msg.String() == "?" || msg.String() == "esc" || msg.String() == "q" {
@@ -200,6 +667,39 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		// Leader mappings and two-key chords
+		if cmd, ok := m.handleLeader(msg); ok {
+			return m, cmd
+		}
+		if cmd, ok := m.handleChord(msg); ok {
+			return m, cmd
+		}
+
+		// Layout keys; the panes would take them as typed letters
+		switch msg.String() {
+		case "alt+z":
+			m.toggleZen()
+			return m, nil
+		case "alt+l":
+			m.sideBySide = !m.sideBySide
+			m.updateLayout()
+			return m, nil
+		case "alt+s":
+			return m, m.toggleSplit()
+		case "alt+w":
+			return m, m.otherSplit()
+		case "alt+j":
+			return m, m.openScheduleLog()
+		case "alt+n":
+			return m, m.openListen()
+		case "alt+a":
+			return m, m.openActivity()
+		case "alt+d":
+			return m, m.openSchemaDiff()
+		case "alt+e":
+			return m, m.sidebar.ERDiagram()
+		}
+
 		// Global keybindings
 		cmd := m.handleGlobalKeys(msg)
 		if cmd != nil {
@@ -213,6 +713,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = append(cmds, cmd)
 		}
 
+	case tea.MouseMsg:
+		if cmd := m.handleMouse(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
 	case ConnectMsg:
 		// Cancel any query context from the previous connection.
 		if m.cancelFunc != nil {
@@ -228,6 +733,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Close all tab iterators (they may hold their own DB connections)
 		for _, ts := range m.tabStates {
 			ts.Results.CloseIterator()
+			ts.stopCount()
+		}
+		if m.inTransaction() {
+			cmds = append(cmds, m.toast(ToastWarning, "Open transaction rolled back on "+m.conn.DatabaseName()))
 		}
 		if m.conn != nil {
 			_ = m.conn.Close()
@@ -235,17 +744,56 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.schemaCancel != nil {
 			m.schemaCancel()
 		}
+		m.closeListener()
+		m.closeTunnel()
+		m.stopMaintenance()
+		m.maintenance.Hide()
+		m.stopSchemaDiff()
+		m.schemaDiff.Hide()
+		m.erDiagram.Hide()
+		m.stopProfile()
+		m.profiler.Hide()
+		m.depBrowser.Hide()
+		m.stopTransfer()
+		m.transfer.Hide()
+		m.rowForm.Hide()
+		m.stopDataGen()
+		m.dataGen.Hide()
+		m.bulkUpdate.Hide()
+		m.stopDataDiff()
+		m.dataDiff.Hide()
+		m.stopDump()
+		m.dump.Hide()
 		m.conn = msg.Conn
 		m.connGen++
+		if msg.Tunnel != nil {
+			m.tunnel = msg.Tunnel
+			cmds = append(cmds, watchTunnel(msg.Tunnel, m.connGen))
+		}
 		m.dsn = audit.SanitizeDSN(msg.DSN)
+		if cmd := m.touchRecent(msg.Name); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
 		m.showConnMgr = false
 		m.connMgr.Hide()
 		var cmd tea.Cmd
 		m.statusbar, cmd = m.statusbar.Update(msg)
 		cmds = append(cmds, cmd)
+		m.tabs.SetEnv(msg.Env, msg.EnvColor)
+		m.execDefaults = msg.Defaults
+		m.autoCommit = msg.Defaults.AutoCommitOn()
+		if m.cfg != nil {
+			for _, ts := range m.tabStates {
+				ts.Results.SetPaging(m.pageSize(), m.cfg.Results.MaxBufferedRows)
+			}
+		}
 		// Load schema
+		m.sidebar.SetDialect(msg.Conn.AdapterName())
+		m.loadFavorites()
 		m.sidebar.SetLoading(true)
-		cmds = append(cmds, m.loadSchema())
+		m.schemaRefresh = false
+		m.schemaHash = ""
+		cmds = append(cmds, m.loadSchema(), m.loadCachedSchema())
 
 	case ConnectErrMsg:
 		errText := "unknown error"
@@ -262,17 +810,27 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.ConnGen != m.connGen {
 			break // stale schema from previous connection
 		}
+		if !msg.Cached.IsZero() {
+			cmds = append(cmds, m.showCachedSchema(msg))
+			break
+		}
 		m.sidebar.SetLoading(false)
-		var cmd tea.Cmd
-		m.sidebar, cmd = m.sidebar.Update(msg)
-		cmds = append(cmds, cmd)
-		// Update completion engine
-		if m.conn != nil {
-			m.compEngine = completion.NewEngine(m.conn.AdapterName())
-			m.compEngine.UpdateSchema(msg.Databases)
-			m.autocomp.SetEngine(m.compEngine)
-		} else {
-			m.compEngine.UpdateSchema(msg.Databases)
+		if m.schemaRefresh {
+			m.schemaRefresh = false
+			cmds = append(cmds, m.toast(ToastSuccess, schemaSummary(msg.Databases, schema.Diff(m.databases, msg.Databases))))
+		}
+		// A schema equal to the one shown (from the cache, or before a
+		// refresh) leaves the tree as it is, expanded nodes and all.
+		if msg.Hash == "" || msg.Hash != m.schemaHash {
+			m.schemaHash = msg.Hash
+			m.databases = msg.Databases
+			var cmd tea.Cmd
+			m.sidebar, cmd = m.sidebar.Update(msg)
+			cmds = append(cmds, cmd)
+			m.setCompletionSchema(msg.Databases)
+		}
+		if m.sidebar.ShowStats() {
+			cmds = append(cmds, m.loadTableStats())
 		}
 		// Show warnings if any
 		if len(msg.Warnings) > 0 {
@@ -281,6 +839,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				Text: fmt.Sprintf("Schema loaded with %d warnings", len(msg.Warnings)),
 			})
 			cmds = append(cmds, sbCmd)
+		} else if msg.Lazy {
+			var sbCmd tea.Cmd
+			m.statusbar, sbCmd = m.statusbar.Update(StatusMsg{
+				Text: "Large schema: table columns load when a table is expanded",
+			})
+			cmds = append(cmds, sbCmd)
 		}
 
 	case SchemaErrMsg:
@@ -288,6 +852,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			break // stale error from previous connection
 		}
 		m.sidebar.SetLoading(false)
+		m.schemaRefresh = false
 		errText := "unknown error"
 		if msg.Err != nil {
 			errText = msg.Err.Error()
@@ -299,6 +864,23 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, sbCmd)
 
 	case ExecuteQueryMsg:
+		if commit, ok := txStatement(msg.Query); ok && !m.autoCommit {
+			cmds = append(cmds, m.endTransaction(commit, false))
+			break
+		}
+		if m.safeMode && !readOnly(msg.Query) {
+			var sbCmd tea.Cmd
+			m.statusbar, sbCmd = m.statusbar.Update(StatusMsg{Text: safeModeBlocked, IsError: true})
+			cmds = append(cmds, sbCmd)
+			break
+		}
+		if cmd := m.checkDrop(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+			break
+		}
+		if m.promptParams(msg) {
+			break
+		}
 		// Cancel any in-flight query before starting a new one
 		if m.executing {
 			if m.cancelFunc != nil {
@@ -309,7 +891,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			m.executing = false
 		}
-		cmds = append(cmds, m.executeQuery(msg.Query, msg.TabID))
+		if ts := m.tabStates[msg.TabID]; ts != nil {
+			ts.Lint = nil
+			if msg.Lint {
+				ts.Lint = m.lint(msg.Query)
+			}
+		}
+		cmds = append(cmds, m.executeQuery(msg))
+
+	case dropSizeMsg:
+		if cmd := m.handleDropSize(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
 
 	case QueryStartedMsg:
 		if msg.ConnGen != m.connGen {
@@ -320,12 +913,28 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.executing = true
 			m.executingTabID = msg.TabID
 			ts.Results.SetLoading(true)
+			ts.Results.SetDeadline(time.Time{})
+			if msg.Timeout > 0 {
+				ts.Results.SetDeadline(time.Now().Add(msg.Timeout))
+				cmds = append(cmds, queryTick(msg.TabID, msg.RunID))
+			}
+		}
+
+	case copyTickMsg:
+		if cmd := m.handleCopyTick(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case queryTickMsg:
+		if cmd := m.handleQueryTick(msg); cmd != nil {
+			cmds = append(cmds, cmd)
 		}
 
 	case QueryResultMsg:
 		if msg.ConnGen != m.connGen {
 			break
 		}
+		m.syncTransaction()
 		ts := m.tabStates[msg.TabID]
 		if ts == nil {
 			// Tab was closed while query was in flight
@@ -339,11 +948,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			ts.Results.SetLoading(false)
 			if msg.Result != nil {
 				ts.Results.SetResults(msg.Result)
+				ts.Results.SetQuery(ts.Query)
+				ts.Results.SetAutoLimit(ts.AutoLimit)
 			}
 			// Save to history
 			if m.history != nil && m.conn != nil && msg.Result != nil {
 				_ = m.history.Add(history.HistoryEntry{
-					Query:        ts.Query,
+					Query:        m.redact(ts.Query),
 					Adapter:      m.conn.AdapterName(),
 					DatabaseName: m.conn.DatabaseName(),
 					ExecutedAt:   time.Now(),
@@ -354,7 +965,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.auditLog(ts.Query, msg.Result.Duration.Milliseconds(), msg.Result.RowCount, false)
 			var sbCmd tea.Cmd
 			m.statusbar, sbCmd = m.statusbar.Update(msg)
-			cmds = append(cmds, sbCmd)
+			cmds = append(cmds, sbCmd, m.notifyDone(msg.TabID, false))
+			if m.attaches(ts.Query) {
+				cmds = append(cmds, m.refreshSchema())
+			}
 		}
 
 	case QueryStreamingMsg:
@@ -376,13 +990,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.executing = false
 		ts.Results.SetLoading(false)
-		ts.Results.SetQueryDuration(msg.Duration)
 		ts.Results.SetIterator(msg.Iterator)
+		ts.Results.SetQuery(ts.Query)
+		ts.Results.SetAutoLimit(ts.AutoLimit)
+		ts.Results.SetQueryDuration(msg.Duration)
 		cmds = append(cmds, results.FetchFirstPage(msg.Iterator, msg.TabID))
+		if cmd := m.startCount(ts, msg.TabID); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
 		// Save to history
 		if m.history != nil && m.conn != nil {
 			_ = m.history.Add(history.HistoryEntry{
-				Query:        ts.Query,
+				Query:        m.redact(ts.Query),
 				Adapter:      m.conn.AdapterName(),
 				DatabaseName: m.conn.DatabaseName(),
 				ExecutedAt:   time.Now(),
@@ -393,12 +1012,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.auditLog(ts.Query, msg.Duration.Milliseconds(), -1, false)
 		var sbCmd tea.Cmd
 		m.statusbar, sbCmd = m.statusbar.Update(msg)
-		cmds = append(cmds, sbCmd)
+		cmds = append(cmds, sbCmd, m.notifyDone(msg.TabID, false))
+
+	case TotalRowsMsg:
+		m.handleTotalRows(msg)
 
 	case QueryErrMsg:
 		if msg.ConnGen != m.connGen {
 			break
 		}
+		m.syncTransaction()
 		ts := m.tabStates[msg.TabID]
 		if ts == nil {
 			// Tab was closed while query was in flight
@@ -414,39 +1037,30 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Save error to history
 			if m.history != nil && m.conn != nil {
 				_ = m.history.Add(history.HistoryEntry{
-					Query:        ts.Query,
+					Query:        m.redact(ts.Query),
 					Adapter:      m.conn.AdapterName(),
 					DatabaseName: m.conn.DatabaseName(),
 					ExecutedAt:   time.Now(),
 					IsError:      true,
+					ErrorMessage: errorText(msg.Err),
 				})
 			}
 			m.auditLog(ts.Query, 0, 0, true)
 			var sbCmd tea.Cmd
 			m.statusbar, sbCmd = m.statusbar.Update(msg)
-			cmds = append(cmds, sbCmd)
+			cmds = append(cmds, sbCmd, m.notifyDone(msg.TabID, true))
+			if connectionLost(msg.Err) {
+				cmds = append(cmds, m.toast(ToastError, "Connection lost: "+sanitizeError(msg.Err.Error())))
+			}
 		}
 
 	case NewTabMsg:
-		// Blur current editor before switching
-		if ts := m.activeTabState(); ts != nil {
-			ts.Editor.Blur()
-		}
-		var cmd tea.Cmd
-		m.tabs, cmd = m.tabs.Update(msg)
+		tabID, cmd := m.addTab(msg.Query)
 		cmds = append(cmds, cmd)
-		tabID := m.tabs.ActiveID()
-		ed := editor.New(tabID)
-		ed.Focus()
-		if msg.Query != "" {
-			ed.SetValue(msg.Query)
+		if msg.Run && msg.Query != "" {
+			query := msg.Query
+			cmds = append(cmds, func() tea.Msg { return ExecuteQueryMsg{Query: query, TabID: tabID} })
 		}
-		m.tabStates[tabID] = &TabState{
-			Editor:  ed,
-			Results: results.New(tabID),
-		}
-		m.updateLayout()
-		m.focusedPane = PaneEditor
 
 	case CloseTabMsg:
 		if m.executing && msg.TabID == m.executingTabID {
@@ -460,8 +1074,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		if ts := m.tabStates[msg.TabID]; ts != nil {
 			ts.Results.CloseIterator()
+			ts.stopCount()
 		}
 		delete(m.tabStates, msg.TabID)
+		if m.split != nil && (msg.TabID == m.split.tab || msg.TabID == m.split.active) {
+			m.split = nil
+		}
 		var cmd tea.Cmd
 		m.tabs, cmd = m.tabs.Update(msg)
 		cmds = append(cmds, cmd)
@@ -474,6 +1092,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			ts.Results.Blur()
 		}
 		m.tabs, _ = m.tabs.Update(msg)
+		m.syncSplit()
 		m.updateLayout()
 		m.setFocus(m.focusedPane)
 
@@ -481,12 +1100,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.keyMode == KeyModeStandard {
 			m.keyMode = KeyModeVim
 			m.keyMap = VimKeyMap()
-			m.vimState = VimNormal
+			m.keyMap.Sequences = append(m.keyMap.Sequences, leaderSequences(m.cfg.Mappings)...)
 		} else {
 			m.keyMode = KeyModeStandard
 			m.keyMap = StandardKeyMap()
 		}
+		for _, ts := range m.tabStates {
+			ts.Editor.SetVim(m.keyMode == KeyModeVim)
+		}
 		m.statusbar.SetKeyMode(m.keyMode)
+		m.syncVimState()
 		var sbCmd tea.Cmd
 		m.statusbar, sbCmd = m.statusbar.Update(msg)
 		cmds = append(cmds, sbCmd)
@@ -497,38 +1120,197 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			ts.Editor.ReplaceWord(msg.Text, msg.PrefixLen)
 		}
 
+	case objectsearch.PickMsg:
+		if cmd := m.revealObject(msg.Object); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case switcher.PickMsg:
+		cmds = append(cmds, m.switchConnection(msg.Conn))
+
 	case historybrowser.SelectQueryMsg:
 		ts := m.activeTabState()
 		if ts != nil {
 			ts.Editor.SetValue(msg.Query)
 		}
 
+	case historybrowser.RunQueryMsg:
+		if cmd := m.rerunHistory(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case querylib.InsertMsg:
+		m.insertLibraryQuery(msg)
+
+	case listen.ListenMsg:
+		if cmd := m.handleListen(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case listenerOpenedMsg:
+		if cmd := m.handleListenerOpened(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case listenedMsg:
+		m.handleListened(msg)
+
+	case notificationMsg:
+		if cmd := m.handleNotification(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case listen.NotifyMsg:
+		if cmd := m.handleNotify(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case notifySentMsg:
+		m.handleNotifySent(msg)
+
+	case activity.TickMsg:
+		var cmd tea.Cmd
+		m.activity, cmd = m.activity.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case activity.RefreshMsg:
+		if cmd := m.loadActivity(); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case activityLoadedMsg:
+		m.handleActivityLoaded(msg)
+
+	case activity.CancelMsg:
+		if cmd := m.signalBackend(msg.ID, false); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case activity.TerminateMsg:
+		if cmd := m.signalBackend(msg.ID, true); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case activitySignalledMsg:
+		if cmd := m.handleActivitySignalled(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case MaintenanceMsg:
+		cmds = append(cmds, m.openMaintenance(msg))
+
+	case maintLoadedMsg:
+		m.handleMaintLoaded(msg)
+
+	case maintenance.TickMsg:
+		var cmd tea.Cmd
+		m.maintenance, cmd = m.maintenance.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case maintenance.RunMsg:
+		if cmd := m.runMaintenance(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case maintenance.CancelMsg:
+		m.stopMaintenance()
+
+	case diffSourcesMsg:
+		m.handleDiffSources(msg)
+
+	case schemadiff.CompareMsg:
+		if cmd := m.compareSchemas(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case schemadiff.CancelMsg:
+		m.stopSchemaDiff()
+
+	case diffLoadedMsg:
+		m.handleDiffLoaded(msg)
+
+	case schemadiff.SnapshotMsg:
+		if cmd := m.saveSnapshot(); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case snapshotSavedMsg:
+		if cmd := m.handleSnapshotSaved(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case maintDoneMsg:
+		if cmd := m.handleMaintDone(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case querylib.SyncMsg:
+		cmds = append(cmds, m.syncLibrary())
+
+	case librarySyncedMsg:
+		if cmd := m.handleLibrarySynced(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case schedulesLoadedMsg:
+		if cmd := m.setSchedules(msg.queries); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case scheduleTickMsg:
+		if cmd := m.handleScheduleTick(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case scheduleRanMsg:
+		if cmd := m.handleScheduleRan(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case querylib.LibraryUpdatedMsg:
+		if cmd := m.saveLibrary(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case autoConnectMsg:
+		return m.handleAutoConnect(msg)
+
+	case restoreSessionMsg:
+		if cmd := m.handleRestoreSession(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
 	case connmgr.ConnectRequestMsg:
-		cmds = append(cmds, m.connect(msg.AdapterName, msg.DSN))
+		if msg.Saved != nil {
+			cmds = append(cmds, m.connectSaved(*msg.Saved))
+		} else {
+			cmds = append(cmds, m.connect(msg.AdapterName, msg.DSN))
+		}
 
 	case connmgr.ConnectionsUpdatedMsg:
-		m.cfg.Connections = msg.Connections
-		if err := m.cfg.SaveDefault(); err != nil {
-			var sbCmd tea.Cmd
-			m.statusbar, sbCmd = m.statusbar.Update(StatusMsg{
-				Text: "Failed to save connections: " + err.Error(), IsError: true,
-			})
-			cmds = append(cmds, sbCmd)
+		if cmd := m.saveConnections(msg.Connections); cmd != nil {
+			cmds = append(cmds, cmd)
 		}
 
 	case ExportCompleteMsg:
+		cmds = append(cmds, m.toast(ToastSuccess, fmt.Sprintf("Exported %d rows to %s", msg.RowCount, msg.Path)))
+
+	case StatusMsg:
 		var sbCmd tea.Cmd
-		m.statusbar, sbCmd = m.statusbar.Update(StatusMsg{
-			Text: fmt.Sprintf("Exported %d rows to %s", msg.RowCount, msg.Path),
-		})
+		m.statusbar, sbCmd = m.statusbar.Update(msg)
 		cmds = append(cmds, sbCmd)
 
 	case ExportErrMsg:
-		var sbCmd tea.Cmd
-		m.statusbar, sbCmd = m.statusbar.Update(StatusMsg{
-			Text: "Export failed: " + msg.Err.Error(), IsError: true,
-		})
-		cmds = append(cmds, sbCmd)
+		cmds = append(cmds, m.toast(ToastError, "Export failed: "+msg.Err.Error()))
+
+	case ToastMsg, toast.DismissMsg:
+		var cmd tea.Cmd
+		m.toasts, cmd = m.toasts.Update(msg)
+		cmds = append(cmds, cmd)
 
 	case results.FetchedPageMsg:
 		ts := m.tabStates[msg.TabID]
@@ -538,33 +1320,311 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = append(cmds, cmd)
 		}
 
+	case editor.ExCommandMsg:
+		cmds = append(cmds, m.handleExCommand(msg))
+
+	case results.RemoveLimitMsg:
+		if ts := m.tabStates[msg.TabID]; ts != nil && ts.AutoLimit > 0 {
+			query, tabID := ts.Query, msg.TabID
+			cmds = append(cmds, func() tea.Msg { return ExecuteQueryMsg{Query: query, TabID: tabID, NoLimit: true} })
+		}
+
+	case results.SortQueryMsg:
+		ts := m.tabStates[msg.TabID]
+		if ts == nil || m.conn == nil || ts.Query == "" {
+			break
+		}
+		dir := "ASC"
+		if msg.Desc {
+			dir = "DESC"
+		}
+		query := fmt.Sprintf("SELECT * FROM (%s) AS sorted ORDER BY %s %s",
+			adapter.TrimStatement(ts.Query), adapter.QuoteIdentifier(m.conn.AdapterName(), msg.Column), dir)
+		tabID := msg.TabID
+		m.showDialog("Sort Streaming Result",
+			fmt.Sprintf("Only the buffered rows were sorted. Re-run the query with ORDER BY %s %s?", msg.Column, dir),
+			dialog.Button{Label: "Re-run", Action: func() tea.Msg { return ExecuteQueryMsg{Query: query, TabID: tabID} }},
+			dialog.Button{Label: "Keep", Action: func() tea.Msg { return nil }},
+		)
+
+	case LoadTableStatsMsg:
+		if cmd := m.loadTableStats(); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case TableStatsMsg:
+		if cmd := m.handleTableStats(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case LoadTableMsg:
+		if cmd := m.loadTable(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case TableLoadedMsg:
+		if cmd := m.handleTableLoaded(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.sidebar, cmd = m.sidebar.Update(msg)
+		cmds = append(cmds, cmd)
+
+	case TunnelClosedMsg:
+		if msg.ConnGen == m.connGen {
+			var sbCmd tea.Cmd
+			m.statusbar, sbCmd = m.statusbar.Update(msg)
+			cmds = append(cmds, sbCmd, m.toast(ToastError, "Connection lost: SSH tunnel closed"))
+		}
+
+	case FavoritesChangedMsg:
+		if cmd := m.saveFavorites(msg.Tables); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case TableActionMsg:
+		if cmd := m.handleTableAction(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case tableCountMsg:
+		if msg.ConnGen == m.connGen {
+			var sbCmd tea.Cmd
+			m.statusbar, sbCmd = m.statusbar.Update(msg.status())
+			cmds = append(cmds, sbCmd)
+		}
+
+	case RefreshMatViewMsg:
+		m.confirmRefreshMatView(msg)
+
+	case ShowDDLMsg:
+		if cmd := m.loadDDL(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case ERDiagramMsg:
+		if cmd := m.openERDiagram(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case erTablesMsg:
+		if cmd := m.handleERTables(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case ProfileTableMsg:
+		cmds = append(cmds, m.openProfile(msg))
+
+	case profiler.CancelMsg:
+		m.stopProfile()
+
+	case profiledMsg:
+		m.handleProfiled(msg)
+
+	case DependenciesMsg:
+		cmds = append(cmds, m.openDependencies(msg))
+
+	case depsLoadedMsg:
+		m.handleDependencies(msg)
+
+	case dependencies.PickMsg:
+		cmds = append(cmds, m.revealDependency(msg.Dependency))
+
+	case TransferTableMsg:
+		cmds = append(cmds, m.openTransfer(msg))
+
+	case transfer.StartMsg:
+		cmds = append(cmds, m.startTransfer(msg))
+
+	case transfer.CancelMsg:
+		m.stopTransfer()
+
+	case transferTickMsg:
+		cmds = append(cmds, m.handleTransferTick(msg))
+
+	case transferDoneMsg:
+		cmds = append(cmds, m.handleTransferDone(msg))
+
+	case InsertRowMsg:
+		cmds = append(cmds, m.openRowForm(msg))
+
+	case rowFormLoadedMsg:
+		cmds = append(cmds, m.handleRowFormLoaded(msg))
+
+	case rowform.LookupMsg:
+		cmds = append(cmds, m.lookupRow(msg))
+
+	case rowLookupMsg:
+		m.handleRowLookup(msg)
+
+	case rowform.SubmitMsg:
+		cmds = append(cmds, m.insertRow(msg))
+
+	case rowInsertedMsg:
+		cmds = append(cmds, m.handleRowInserted(msg))
+
+	case GenerateDataMsg:
+		cmds = append(cmds, m.openDataGen(msg))
+
+	case datagen.StartMsg:
+		cmds = append(cmds, m.startDataGen(msg))
+
+	case datagen.CancelMsg:
+		m.stopDataGen()
+
+	case dataGenTickMsg:
+		cmds = append(cmds, m.handleDataGenTick(msg))
+
+	case dataGenDoneMsg:
+		m.handleDataGenDone(msg)
+
+	case BulkUpdateMsg:
+		cmds = append(cmds, m.openBulkUpdate(msg))
+
+	case bulkColumnsMsg:
+		cmds = append(cmds, m.handleBulkColumns(msg))
+
+	case bulkupdate.PreviewMsg:
+		cmds = append(cmds, m.previewBulkUpdate(msg))
+
+	case bulkPreviewMsg:
+		m.handleBulkPreview(msg)
+
+	case bulkupdate.RunMsg:
+		cmds = append(cmds, m.runBulkUpdate())
+
+	case bulkUpdatedMsg:
+		m.handleBulkUpdated(msg)
+
+	case bulkupdate.EndMsg:
+		cmds = append(cmds, m.endTransaction(msg.Commit, false))
+
+	case CompareDataMsg:
+		cmds = append(cmds, m.openDataDiff(msg))
+
+	case datadiff.StartMsg:
+		cmds = append(cmds, m.startDataDiff(msg))
+
+	case datadiff.CancelMsg:
+		m.stopDataDiff()
+
+	case dataDiffTickMsg:
+		cmds = append(cmds, m.handleDataDiffTick(msg))
+
+	case dataDiffDoneMsg:
+		m.handleDataDiffDone(msg)
+
+	case DumpTableMsg:
+		cmds = append(cmds, m.openDump(msg))
+
+	case dump.StartMsg:
+		cmds = append(cmds, m.startDump(msg))
+
+	case dump.CancelMsg:
+		m.stopDump()
+
+	case dumpTickMsg:
+		cmds = append(cmds, m.handleDumpTick(msg))
+
+	case dumpDoneMsg:
+		m.handleDumpDone(msg)
+
+	case PortTableMsg:
+		if cmd := m.portTable(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case portedTableMsg:
+		if cmd := m.handlePortedTable(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case explainMsg:
+		if cmd := m.explain(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case explainLoadedMsg:
+		if cmd := m.handleExplainLoaded(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case ddlLoadedMsg:
+		if cmd := m.handleDDLLoaded(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case results.FindMsg:
+		if cmd := m.startFind(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case foundRowMsg:
+		if cmd := m.handleFoundRow(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case results.EditCellMsg:
+		if cmd := m.confirmCellEdit(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case cellUpdatedMsg:
+		if cmd := m.handleCellUpdated(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case txEndedMsg:
+		if cmd := m.handleTxEnded(msg); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
 	case statusbar.ClearStatusMsg:
 		m.statusbar, _ = m.statusbar.Update(msg)
+
+	case statusbar.ClockTickMsg:
+		var cmd tea.Cmd
+		m.statusbar, cmd = m.statusbar.Update(msg)
+		cmds = append(cmds, cmd)
 	}
 
 	return m, tea.Batch(cmds...)
 }
 
-func (m *Model) handleGlobalKeys(msg tea.KeyMsg) tea.Cmd {
-	switch {
-	case msg.String() == "ctrl+q":
-		m.quitting = true
-		if m.cancelFunc != nil {
-			m.cancelFunc()
-			m.cancelFunc = nil
-		}
-		if m.executing && m.conn != nil {
-			m.conn.Cancel()
-		}
-		m.executing = false
-		m.executingTabID = 0
-		for _, ts := range m.tabStates {
-			ts.Results.CloseIterator()
-		}
-		if m.schemaCancel != nil {
-			m.schemaCancel()
-		}
-		return tea.Quit
+// quit stops any running work and quits.
+func (m *Model) quit() tea.Cmd {
+	m.quitting = true
+	if m.cancelFunc != nil {
+		m.cancelFunc()
+		m.cancelFunc = nil
+	}
+	if m.executing && m.conn != nil {
+		m.conn.Cancel()
+	}
+	m.executing = false
+	m.executingTabID = 0
+	for _, ts := range m.tabStates {
+		ts.Results.CloseIterator()
+		ts.stopCount()
+	}
+	if m.schemaCancel != nil {
+		m.schemaCancel()
+	}
+	m.stopSchemaDiff()
+	m.stopProfile()
+	m.stopTransfer()
+	m.stopDataGen()
+	m.stopDataDiff()
+	m.stopDump()
+	return tea.Quit
+}
+
+func (m *Model) handleGlobalKeys(msg tea.KeyMsg) tea.Cmd {
+	switch {
+	case msg.String() == "ctrl+q":
+		return m.confirmQuit()
 
 	case msg.String() == "ctrl+c":
 		if m.executing {
@@ -585,40 +1645,63 @@ func (m *Model) handleGlobalKeys(msg tea.KeyMsg) tea.Cmd {
 		m.showHelp = !m.showHelp
 		return nil
 
-	case msg.String() == "?" && m.focusedPane != PaneEditor:
+	case msg.String() == "?" && !m.textInputFocused():
 		m.showHelp = !m.showHelp
 		return nil
 
 	case msg.String() == "f2":
 		return func() tea.Msg { return ToggleKeyModeMsg{} }
 
+	case msg.String() == "f3":
+		return m.toggleSafeMode()
+
+	case msg.String() == "f4":
+		return m.toggleAutoCommit()
+
+	case msg.String() == "f6":
+		return m.endTransaction(true, false)
+
+	case msg.String() == "f7":
+		return m.endTransaction(false, false)
+
+	case msg.String() == "f8":
+		return m.explainQuery()
+
 	case msg.String() == "ctrl+b":
 		m.showSidebar = !m.showSidebar
 		m.updateLayout()
 		return nil
 
-	case msg.String() == "ctrl+r":
-		if m.conn != nil {
-			m.sidebar.SetLoading(true)
-			return m.loadSchema()
-		}
-		return nil
+	case msg.String() == "ctrl+r" && !m.vimCommandKeys():
+		return m.refreshSchema()
 
 	case msg.String() == "ctrl+e":
-		return m.exportResults()
+		return m.exportResults("csv")
 
 	case msg.String() == "ctrl+o":
 		m.connMgr.Show()
 		return nil
 
+	case msg.String() == "ctrl+p":
+		m.switcher.Show(m.cfg.Connections, m.cfg.Recent, m.connName)
+		return nil
+
+	case msg.String() == "alt+o":
+		m.objSearch.Show()
+		return nil
+
 	case msg.String() == "ctrl+h":
 		if m.histBrowser.Visible() {
 			m.histBrowser.Hide()
 		} else {
+			m.histBrowser.SetScope(m.historyScope())
 			m.histBrowser.Show()
 		}
 		return nil
 
+	case msg.String() == "ctrl+l":
+		return m.openLibrary()
+
 	case msg.String() == "ctrl+t":
 		return func() tea.Msg { return NewTabMsg{} }
 
@@ -632,7 +1715,7 @@ func (m *Model) handleGlobalKeys(msg tea.KeyMsg) tea.Cmd {
 	case msg.String() == "ctrl+[":
 		return m.tabs.PrevTab()
 
-	case msg.String() == "tab" && m.focusedPane != PaneEditor:
+	case msg.String() == "tab" && !m.textInputFocused():
 		m.cycleFocus(1)
 		return nil
 
@@ -667,15 +1750,15 @@ func (m *Model) handleGlobalKeys(msg tea.KeyMsg) tea.Cmd {
 		return nil
 
 	case msg.String() == "ctrl+up":
-		if m.editorHeight > 20 {
-			m.editorHeight -= 5
+		if share := m.editorShare(); *share > 20 {
+			*share -= 5
 			m.updateLayout()
 		}
 		return nil
 
 	case msg.String() == "ctrl+down":
-		if m.editorHeight < 80 {
-			m.editorHeight += 5
+		if share := m.editorShare(); *share < 80 {
+			*share += 5
 			m.updateLayout()
 		}
 		return nil
@@ -683,6 +1766,21 @@ func (m *Model) handleGlobalKeys(msg tea.KeyMsg) tea.Cmd {
 	return nil
 }
 
+// textInputFocused reports whether the focused pane is capturing typed text,
+// in which case single-character global shortcuts must not fire.
+func (m *Model) textInputFocused() bool {
+	switch m.focusedPane {
+	case PaneEditor:
+		return true
+	case PaneSidebar:
+		return m.sidebar.InputFocused()
+	case PaneResults:
+		ts := m.activeTabState()
+		return ts != nil && ts.Results.InputFocused()
+	}
+	return false
+}
+
 func (m *Model) handleFocusedPaneKey(msg tea.KeyMsg) tea.Cmd {
 	ts := m.activeTabState()
 	if ts == nil {
@@ -697,14 +1795,20 @@ func (m *Model) handleFocusedPaneKey(msg tea.KeyMsg) tea.Cmd {
 
 	case PaneEditor:
 		// Execute query on ctrl+enter, F5, or ctrl+g
-		if msg.String() == "ctrl+enter" || msg.String() == "f5" || msg.String() == "ctrl+g" {
+		if msg.String() == "ctrl+enter" || msg.String() == "f5" || msg.String() == "ctrl+g" || msg.String() == "alt+enter" {
 			query := ts.Editor.Value()
 			if query != "" {
 				tabID := m.tabs.ActiveID()
-				return func() tea.Msg { return ExecuteQueryMsg{Query: query, TabID: tabID} }
+				noTimeout := msg.String() == "alt+enter"
+				return func() tea.Msg {
+					return ExecuteQueryMsg{Query: query, TabID: tabID, Lint: true, NoTimeout: noTimeout}
+				}
 			}
 			return nil
 		}
+		if msg.String() == "f9" {
+			return m.executeStatementAtCursor()
+		}
 
 		// Trigger autocomplete on ctrl+space
 		if msg.String() == "ctrl+@" || msg.String() == "ctrl+ " {
@@ -716,9 +1820,11 @@ func (m *Model) handleFocusedPaneKey(msg tea.KeyMsg) tea.Cmd {
 
 		var cmd tea.Cmd
 		ts.Editor, cmd = ts.Editor.Update(msg)
+		m.syncVimState()
 
-		// Trigger autocomplete after typing
-		if isTypingKey(msg) {
+		// Trigger autocomplete after typing; outside vim's insert mode
+		// keys are commands.
+		if isTypingKey(msg) && ts.Editor.VimMode() == VimInsert {
 			text := ts.Editor.Value()
 			m.autocomp.Trigger(text, len(text))
 		}
@@ -746,6 +1852,154 @@ func (m Model) View() string {
 
 	th := theme.Current
 
+	var view string
+	if m.zen {
+		view = m.zenView()
+	} else {
+		view = m.panesView()
+	}
+
+	// Confirmation dialog overlay
+	view = m.dialog.Overlay(view)
+
+	// Notifications over the top-right corner
+	view = m.toasts.Overlay(view)
+
+	// Help overlay — full-screen centered
+	if m.showHelp {
+		helpContent := m.renderHelpScreen(th)
+		view = lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, helpContent)
+	}
+
+	// Connection switcher overlay
+	if m.switcher.Visible() {
+		centered := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.switcher.View())
+		return clampViewHeight(centered, m.height)
+	}
+
+	// Object search overlay
+	if m.objSearch.Visible() {
+		centered := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.objSearch.View())
+		return clampViewHeight(centered, m.height)
+	}
+
+	// History browser overlay
+	if m.histBrowser.Visible() {
+		histView := m.histBrowser.View()
+		centered := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, histView)
+		return clampViewHeight(centered, m.height)
+	}
+
+	// Query library overlay
+	if m.queryLib.Visible() {
+		centered := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.queryLib.View())
+		return clampViewHeight(centered, m.height)
+	}
+
+	// Parameter prompt overlay
+	if m.params.Visible() {
+		centered := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.params.View())
+		return clampViewHeight(centered, m.height)
+	}
+
+	// LISTEN/NOTIFY panel overlay
+	if m.listen.Visible() {
+		centered := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.listen.View())
+		return clampViewHeight(centered, m.height)
+	}
+
+	// Session manager overlay
+	if m.activity.Visible() {
+		centered := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.activity.View())
+		return clampViewHeight(centered, m.height)
+	}
+
+	// Maintenance panel overlay
+	if m.maintenance.Visible() {
+		centered := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.maintenance.View())
+		return clampViewHeight(centered, m.height)
+	}
+
+	// Schema comparison overlay
+	if m.schemaDiff.Visible() {
+		centered := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.schemaDiff.View())
+		return clampViewHeight(centered, m.height)
+	}
+
+	// ER diagram overlay
+	if m.erDiagram.Visible() {
+		centered := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.erDiagram.View())
+		return clampViewHeight(centered, m.height)
+	}
+
+	// Table profile overlay
+	if m.profiler.Visible() {
+		centered := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.profiler.View())
+		return clampViewHeight(centered, m.height)
+	}
+
+	// Dependency browser overlay
+	if m.depBrowser.Visible() {
+		centered := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.depBrowser.View())
+		return clampViewHeight(centered, m.height)
+	}
+
+	// Copy wizard overlay
+	if m.transfer.Visible() {
+		centered := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.transfer.View())
+		return clampViewHeight(centered, m.height)
+	}
+
+	// Insert form overlay
+	if m.rowForm.Visible() {
+		centered := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.rowForm.View())
+		return clampViewHeight(centered, m.height)
+	}
+
+	// Test data generator overlay
+	if m.dataGen.Visible() {
+		centered := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.dataGen.View())
+		return clampViewHeight(centered, m.height)
+	}
+
+	// Bulk UPDATE wizard overlay
+	if m.bulkUpdate.Visible() {
+		centered := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.bulkUpdate.View())
+		return clampViewHeight(centered, m.height)
+	}
+
+	// Data comparison overlay
+	if m.dataDiff.Visible() {
+		centered := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.dataDiff.View())
+		return clampViewHeight(centered, m.height)
+	}
+
+	// Table dump overlay
+	if m.dump.Visible() {
+		centered := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.dump.View())
+		return clampViewHeight(centered, m.height)
+	}
+
+	// Text viewer overlay
+	if m.viewer.Visible() {
+		centered := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.viewer.View())
+		return clampViewHeight(centered, m.height)
+	}
+
+	// Connection manager overlay
+	if m.connMgr.Visible() {
+		connView := m.connMgr.View()
+		// Center the connection manager
+		centered := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, connView)
+		return clampViewHeight(centered, m.height)
+	}
+
+	return clampViewHeight(view, m.height)
+}
+
+// panesView draws the tab bar, the sidebar, the editor and results of the
+// active tab, and the status bar.
+func (m Model) panesView() string {
 	// Tab bar (top)
 	tabBar := m.tabs.View()
 
@@ -762,41 +2016,45 @@ func (m Model) View() string {
 	ts := m.activeTabState()
 	var editorView, resultsView string
 	if ts != nil {
-		editorH := mainHeight * m.editorHeight / 100
-		resultsH := mainHeight - editorH
-		if editorH < 3 {
-			editorH = 3
-		}
-		if resultsH < 3 {
-			resultsH = 3
-		}
-
 		mainWidth := m.width
 		if m.showSidebar {
 			mainWidth = m.width - m.sidebarWidth
 		}
+		editorW, editorH, resultsW, resultsH := m.paneSizes(mainWidth, mainHeight)
+
+		banner := lintBanner(ts, resultsW)
+		if banner != "" && resultsH > 3 {
+			resultsH--
+		} else {
+			banner = ""
+		}
 
-		ts.Editor.SetSize(mainWidth, editorH)
-		ts.Results.SetSize(mainWidth, resultsH)
+		splitEd := m.splitEditor()
+		activeW, activeH := editorW, editorH
+		if splitEd != nil {
+			var otherW, otherH int
+			activeW, activeH, otherW, otherH = m.splitSizes(editorW, editorH)
+			splitEd.SetSize(otherW, otherH)
+		}
+		ts.Editor.SetSize(activeW, activeH)
+		ts.Results.SetSize(resultsW, resultsH)
 
 		editorView = ts.Editor.View()
 		resultsView = ts.Results.View()
+		if banner != "" {
+			resultsView = banner + "\n" + resultsView
+		}
 
 		// Autocomplete overlay - render within editor space to avoid pushing content off-screen
 		if m.autocomp.Visible() {
-			acView := m.autocomp.View()
-			acHeight := lipgloss.Height(acView)
-			editorLines := strings.Split(editorView, "\n")
-			if acHeight < len(editorLines) {
-				// Replace bottom lines of editor with autocomplete
-				editorLines = editorLines[:len(editorLines)-acHeight]
-				editorView = strings.Join(editorLines, "\n") + "\n" + acView
-			} else {
-				// Editor too small, just show autocomplete below first line
-				if len(editorLines) > 1 {
-					editorView = editorLines[0] + "\n" + acView
-				}
-			}
+			editorView = overlayBottom(editorView, m.autocomp.View())
+		}
+		// Which-key hints for a vim command being typed
+		if hints := m.whichKey(activeW, activeH); hints != "" {
+			editorView = overlayBottom(editorView, hints)
+		}
+		if splitEd != nil {
+			editorView = m.joinSplit(editorView, splitEd.View())
 		}
 	} else {
 		editorView = "No active tab"
@@ -804,6 +2062,9 @@ func (m Model) View() string {
 	}
 
 	mainContent := lipgloss.JoinVertical(lipgloss.Left, editorView, resultsView)
+	if m.sideBySide {
+		mainContent = lipgloss.JoinHorizontal(lipgloss.Top, editorView, resultsView)
+	}
 
 	// Sidebar + Main
 	var content string
@@ -816,30 +2077,7 @@ func (m Model) View() string {
 	}
 
 	// Assemble full view
-	view := lipgloss.JoinVertical(lipgloss.Left, tabBar, content, statusBar)
-
-	// Help overlay — full-screen centered
-	if m.showHelp {
-		helpContent := m.renderHelpScreen(th)
-		view = lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, helpContent)
-	}
-
-	// History browser overlay
-	if m.histBrowser.Visible() {
-		histView := m.histBrowser.View()
-		centered := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, histView)
-		return clampViewHeight(centered, m.height)
-	}
-
-	// Connection manager overlay
-	if m.connMgr.Visible() {
-		connView := m.connMgr.View()
-		// Center the connection manager
-		centered := lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, connView)
-		return clampViewHeight(centered, m.height)
-	}
-
-	return clampViewHeight(view, m.height)
+	return lipgloss.JoinVertical(lipgloss.Left, tabBar, content, statusBar)
 }
 
 // heightOffset returns the height adjustment from the GOTERMSQL_HEIGHT_OFFSET
@@ -883,6 +2121,32 @@ func (m *Model) updateLayout() {
 
 	// History browser
 	m.histBrowser.SetSize(m.width, m.height)
+	m.queryLib.SetSize(m.width, m.height)
+	m.params.SetSize(m.width, m.height)
+	m.listen.SetSize(m.width, m.height)
+	m.activity.SetSize(m.width, m.height)
+	m.maintenance.SetSize(m.width, m.height)
+	m.schemaDiff.SetSize(m.width, m.height)
+	m.erDiagram.SetSize(m.width, m.height)
+	m.profiler.SetSize(m.width, m.height)
+	m.depBrowser.SetSize(m.width, m.height)
+	m.transfer.SetSize(m.width, m.height)
+	m.rowForm.SetSize(m.width, m.height)
+	m.dataGen.SetSize(m.width, m.height)
+	m.bulkUpdate.SetSize(m.width, m.height)
+	m.dataDiff.SetSize(m.width, m.height)
+	m.dump.SetSize(m.width, m.height)
+
+	// Connection switcher
+	m.switcher.SetSize(m.width, m.height)
+	m.objSearch.SetSize(m.width, m.height)
+
+	// Text viewer
+	m.viewer.SetSize(m.width, m.height)
+	m.toasts.SetSize(m.width)
+
+	// Dialog
+	m.dialog.SetSize(m.width, m.height)
 
 	// Resize components
 	mainHeight := m.height - 3 // tab bar + status bar estimate
@@ -893,17 +2157,31 @@ func (m *Model) updateLayout() {
 	}
 
 	ts := m.activeTabState()
-	if ts != nil {
-		editorH := mainHeight * m.editorHeight / 100
-		resultsH := mainHeight - editorH
-		ts.Editor.SetSize(mainWidth, editorH)
-		ts.Results.SetSize(mainWidth, resultsH)
+	if ts != nil && m.zen {
+		ts.Editor.SetSize(m.width, m.height)
+		ts.Results.SetSize(m.width, m.height)
+	} else if ts != nil {
+		editorW, editorH, resultsW, resultsH := m.paneSizes(mainWidth, mainHeight)
+		if splitEd := m.splitEditor(); splitEd != nil {
+			var otherW, otherH int
+			editorW, editorH, otherW, otherH = m.splitSizes(editorW, editorH)
+			splitEd.SetSize(otherW, otherH)
+		}
+		ts.Editor.SetSize(editorW, editorH)
+		ts.Results.SetSize(resultsW, resultsH)
 	}
 }
 
+// showDialog replaces the current dialog with a new one and shows it.
+func (m *Model) showDialog(title, body string, buttons ...dialog.Button) {
+	m.dialog = dialog.New(title, body, buttons...)
+	m.dialog.SetSize(m.width, m.height)
+	m.dialog.Show()
+}
+
 func (m *Model) cycleFocus(direction int) {
 	panes := []Pane{PaneEditor, PaneResults}
-	if m.showSidebar {
+	if m.showSidebar && !m.zen {
 		panes = []Pane{PaneSidebar, PaneEditor, PaneResults}
 	}
 
@@ -920,6 +2198,11 @@ func (m *Model) cycleFocus(direction int) {
 }
 
 func (m *Model) setFocus(pane Pane) {
+	if pane == PaneSidebar && m.zen {
+		// The sidebar is hidden in zen mode; going to it leaves.
+		m.zen = false
+		m.updateLayout()
+	}
 	// Blur current
 	switch m.focusedPane {
 	case PaneSidebar:
@@ -949,32 +2232,101 @@ func (m *Model) setFocus(pane Pane) {
 			ts.Results.Focus()
 		}
 	}
+	m.syncVimState()
+}
+
+// vimCommandKeys reports whether keys go to the editor as vim commands:
+// it has focus and is out of insert mode.
+func (m *Model) vimCommandKeys() bool {
+	ts := m.activeTabState()
+	return m.focusedPane == PaneEditor && ts != nil && ts.Editor.VimMode() != VimInsert
+}
+
+// syncVimState shows the vim mode of the active editor in the status bar.
+func (m *Model) syncVimState() {
+	if ts := m.activeTabState(); ts != nil && m.keyMode == KeyModeVim {
+		m.vimState = ts.Editor.VimMode()
+		m.statusbar.SetVimState(m.vimState)
+		m.statusbar.SetVimRecording(ts.Editor.VimRecording())
+	}
 }
 
 func (m Model) activeTabState() *TabState {
 	return m.tabStates[m.tabs.ActiveID()]
 }
 
+// addTab opens a tab with query in its editor and makes it the active,
+// focused one. It returns the tab's ID and the tab bar's switch command.
+func (m *Model) addTab(query string) (int, tea.Cmd) {
+	// Blur current editor before switching
+	if ts := m.activeTabState(); ts != nil {
+		ts.Editor.Blur()
+	}
+	var cmd tea.Cmd
+	m.tabs, cmd = m.tabs.Update(NewTabMsg{})
+	tabID := m.tabs.ActiveID()
+	ed := editor.New(tabID)
+	ed.Focus()
+	ed.SetVim(m.keyMode == KeyModeVim)
+	if query != "" {
+		ed.SetValue(query)
+	}
+	m.tabStates[tabID] = &TabState{
+		Editor:  ed,
+		Results: m.newResults(tabID),
+	}
+	m.syncSplit()
+	m.updateLayout()
+	m.focusedPane = PaneEditor
+	return tabID, cmd
+}
+
 func (m *Model) connect(adapterName, dsn string) tea.Cmd {
 	return func() tea.Msg {
-		a, ok := adapter.Registry[adapterName]
-		if !ok {
-			return ConnectErrMsg{Err: fmt.Errorf("unknown adapter: %s", adapterName)}
-		}
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
-		conn, err := a.Connect(ctx, dsn)
+		conn, err := openConnection(ctx, adapterName, dsn, nil)
 		if err != nil {
 			return ConnectErrMsg{Err: err}
 		}
-		if err := conn.Ping(ctx); err != nil {
-			conn.Close()
-			return ConnectErrMsg{Err: err}
-		}
 		return ConnectMsg{Conn: conn, Adapter: adapterName, DSN: dsn}
 	}
 }
 
+// openConnection connects with the named adapter and pings the server.
+// init is run on every session the connection opens, or only once after
+// connecting when the adapter is not a SessionConnector.
+func openConnection(ctx context.Context, adapterName, dsn string, init []string) (adapter.Connection, error) {
+	a, ok := adapter.Registry[adapterName]
+	if !ok {
+		return nil, fmt.Errorf("unknown adapter: %s", adapterName)
+	}
+	var conn adapter.Connection
+	var err error
+	sc, perSession := a.(adapter.SessionConnector)
+	if perSession {
+		conn, err = sc.ConnectSession(ctx, dsn, init)
+	} else {
+		conn, err = a.Connect(ctx, dsn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.Ping(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !perSession {
+		for _, stmt := range init {
+			if _, err := conn.Execute(ctx, stmt); err != nil {
+				conn.Close()
+				return nil, fmt.Errorf("session setup %q: %w", stmt, err)
+			}
+		}
+	}
+	return conn, nil
+}
+
 func (m *Model) renderHelpScreen(th *theme.Theme) string {
 	titleStyle := lipgloss.NewStyle().
 		Bold(true).
@@ -1008,8 +2360,16 @@ func (m *Model) renderHelpScreen(th *theme.Theme) string {
 	b.WriteString("\n")
 	b.WriteString(line("F5 / Ctrl+G", "Execute query"))
 	b.WriteString("\n")
+	b.WriteString(line("Alt+Enter", "Execute without the query timeout"))
+	b.WriteString("\n")
+	b.WriteString(line("F9", "Execute the statement under the cursor"))
+	b.WriteString("\n")
 	b.WriteString(line("Ctrl+C", "Cancel running query"))
 	b.WriteString("\n")
+	b.WriteString(line("F6 / F7", "Commit / roll back the open transaction"))
+	b.WriteString("\n")
+	b.WriteString(line("F8", "Run under EXPLAIN ANALYZE and show the plan"))
+	b.WriteString("\n")
 	b.WriteString(line("Ctrl+Space", "Trigger autocomplete"))
 	b.WriteString("\n")
 	b.WriteString(line("Ctrl+E", "Export results"))
@@ -1035,14 +2395,34 @@ func (m *Model) renderHelpScreen(th *theme.Theme) string {
 	b.WriteString("\n")
 	b.WriteString(line("Ctrl+O", "Connection manager"))
 	b.WriteString("\n")
+	b.WriteString(line("Ctrl+P", "Switch connection"))
+	b.WriteString("\n")
 	b.WriteString(line("Ctrl+B", "Toggle sidebar"))
+	b.WriteString(line("Alt+Z", "Zen mode (editor or results only)"))
+	b.WriteString(line("Alt+L", "Results below / beside the editor"))
+	b.WriteString(line("Alt+S", "Split the editor with another tab"))
+	b.WriteString(line("Alt+W", "Other split"))
 	b.WriteString("\n")
-	b.WriteString(line("Ctrl+R", "Refresh schema"))
+	b.WriteString(line("Ctrl+R / g r", "Refresh schema"))
+	b.WriteString("\n")
+	b.WriteString(line("g d", "Go to the definition of the table under the cursor"))
 	b.WriteString("\n")
 	b.WriteString(line("Ctrl+H", "Query history"))
+	b.WriteString(line("Alt+O", "Find a table, column or other object by name"))
+	b.WriteString("\n")
+	b.WriteString(line("Ctrl+L", "Saved query library"))
+	b.WriteString(line("Alt+J", "Log of scheduled library queries"))
+	b.WriteString(line("Alt+N", "LISTEN/NOTIFY panel (PostgreSQL)"))
+	b.WriteString(line("Alt+A", "Sessions on the server"))
+	b.WriteString(line("Alt+D", "Compare schemas, migration script"))
+	b.WriteString(line("Alt+E", "ER diagram of the schema or table"))
 	b.WriteString("\n")
 	b.WriteString(line("F2", "Toggle vim / standard mode"))
 	b.WriteString("\n")
+	b.WriteString(line("F3", "Toggle safe mode (read-only statements only)"))
+	b.WriteString("\n")
+	b.WriteString(line("F4", "Toggle autocommit for the connection"))
+	b.WriteString("\n")
 	b.WriteString(line("Ctrl+Q", "Quit"))
 	b.WriteString("\n")
 
@@ -1051,6 +2431,47 @@ func (m *Model) renderHelpScreen(th *theme.Theme) string {
 	b.WriteString(line("Ctrl+Arrow keys", "Resize sidebar / editor split"))
 	b.WriteString("\n")
 
+	b.WriteString(sectionStyle.Render("  Results"))
+	b.WriteString("\n")
+	b.WriteString(line("Left / Right", "Select column (scrolls wide results)"))
+	b.WriteString("\n")
+	b.WriteString(line("s / click header", "Sort by column (asc / desc / off)"))
+	b.WriteString("\n")
+	b.WriteString(line("/", "Filter rows (col:text, /regex/)"))
+	b.WriteString("\n")
+	b.WriteString(line("Esc", "Clear filter"))
+	b.WriteString("\n")
+	b.WriteString(line(":", "Go to row N (or page N / buffer N for this tab)"))
+	b.WriteString("\n")
+	b.WriteString(line(":find TEXT / n", "Find in column (server-side when streaming) / next"))
+	b.WriteString("\n")
+	b.WriteString(line("{ / }", "Previous / next result of this tab (no re-run)"))
+	b.WriteString("\n")
+	b.WriteString(line("y", "Copy cell / row (TSV, CSV, JSON) / column"))
+	b.WriteString("\n")
+	b.WriteString(line("e", "Edit cell (single-table SELECT with primary key)"))
+	b.WriteString("\n")
+	b.WriteString(line("#", "Toggle row numbers"))
+	b.WriteString("\n")
+	b.WriteString(line("F", "Keep first column visible when scrolling"))
+	b.WriteString("\n")
+	b.WriteString(line("a", "Column aggregates (count, sum, avg, min, max)"))
+	b.WriteString("\n")
+	b.WriteString(line("B", "Inline bars / sparkline for a numeric column"))
+	b.WriteString("\n")
+	b.WriteString(line("L", "Re-run without the automatic LIMIT"))
+	b.WriteString("\n")
+	b.WriteString(line("x", "Toggle record view ([ / ] previous / next row)"))
+	b.WriteString("\n")
+	b.WriteString(line("i", "Inspect cell (full text, hex dump for binary)"))
+	b.WriteString("\n")
+	b.WriteString(line("H / U", "Hide column / show all columns"))
+	b.WriteString("\n")
+	b.WriteString(line("P", "Pin / unpin column to the left"))
+	b.WriteString("\n")
+	b.WriteString(line("< / >", "Move column left / right"))
+	b.WriteString("\n")
+
 	b.WriteString(sectionStyle.Render("  Sidebar"))
 	b.WriteString("\n")
 	b.WriteString(line("Enter / Right", "Expand node / open table"))
@@ -1059,6 +2480,20 @@ func (m *Model) renderHelpScreen(th *theme.Theme) string {
 	b.WriteString("\n")
 	b.WriteString(line("Up / Down", "Navigate"))
 	b.WriteString("\n")
+	b.WriteString(line("/", "Search tables, views and columns (Esc clears)"))
+	b.WriteString("\n")
+	b.WriteString(line("Enter on FK", "Jump to the referenced / referencing table"))
+	b.WriteString("\n")
+	b.WriteString(line("Space / m", "Action menu (peek, count, copy, SQL templates, drop)"))
+	b.WriteString("\n")
+	b.WriteString(line("d", "Show table DDL (y copy, e open in tab)"))
+	b.WriteString("\n")
+	b.WriteString(line("c", "Show row counts and sizes (again to hide)"))
+	b.WriteString("\n")
+	b.WriteString(line("f", "Star / unstar table (Favorites section)"))
+	b.WriteString("\n")
+	b.WriteString(line("r", "Refresh materialized view"))
+	b.WriteString("\n")
 
 	b.WriteString("\n")
 	b.WriteString(mutedStyle.Render("  Press ? / F1 / Esc to close"))
@@ -1069,6 +2504,8 @@ func (m *Model) renderHelpScreen(th *theme.Theme) string {
 func (m *Model) loadSchema() tea.Cmd {
 	conn := m.conn
 	gen := m.connGen
+	lazyThreshold := m.cfg.Sidebar.LazyThreshold
+	cache, cacheKey := m.schemaCache, m.favoritesKey()
 
 	// Cancel any in-flight schema load
 	if m.schemaCancel != nil {
@@ -1082,88 +2519,63 @@ func (m *Model) loadSchema() tea.Cmd {
 		if conn == nil {
 			return SchemaErrMsg{Err: adapter.ErrNotConnected, ConnGen: gen}
 		}
-		dbs, err := conn.Databases(ctx)
+		databases, warnings, lazy, err := introspect(ctx, conn, lazyThreshold)
 		if err != nil {
 			return SchemaErrMsg{Err: err, ConnGen: gen}
 		}
 
-		// Load full schema for each database
-		var databases []schema.Database
-		var warnings []string
-		batchConn, hasBatch := conn.(adapter.BatchIntrospector)
-
-		for _, db := range dbs {
-			for si := range db.Schemas {
-				s := &db.Schemas[si]
-				if hasBatch && len(s.Tables) > 0 {
-					// Batch introspection: 3 queries per schema instead of 3*N
-					allCols, err := batchConn.AllColumns(ctx, db.Name, s.Name)
-					if err != nil {
-						warnings = append(warnings, fmt.Sprintf("batch columns(%s): %v", s.Name, err))
-					}
-					allIdxs, err := batchConn.AllIndexes(ctx, db.Name, s.Name)
-					if err != nil {
-						warnings = append(warnings, fmt.Sprintf("batch indexes(%s): %v", s.Name, err))
-					}
-					allFKs, err := batchConn.AllForeignKeys(ctx, db.Name, s.Name)
-					if err != nil {
-						warnings = append(warnings, fmt.Sprintf("batch fkeys(%s): %v", s.Name, err))
-					}
-					for ti := range s.Tables {
-						t := &s.Tables[ti]
-						if cols, ok := allCols[t.Name]; ok {
-							t.Columns = cols
-						}
-						if idxs, ok := allIdxs[t.Name]; ok {
-							t.Indexes = idxs
-						}
-						if fks, ok := allFKs[t.Name]; ok {
-							t.FKs = fks
-						}
-					}
-				} else {
-					// Per-table fallback
-					for ti := range s.Tables {
-						t := &s.Tables[ti]
-						cols, err := conn.Columns(ctx, db.Name, s.Name, t.Name)
-						if err == nil {
-							t.Columns = cols
-						} else {
-							warnings = append(warnings, fmt.Sprintf("columns(%s.%s): %v", s.Name, t.Name, err))
-						}
-						idxs, err := conn.Indexes(ctx, db.Name, s.Name, t.Name)
-						if err == nil {
-							t.Indexes = idxs
-						} else {
-							warnings = append(warnings, fmt.Sprintf("indexes(%s.%s): %v", s.Name, t.Name, err))
-						}
-						fks, err := conn.ForeignKeys(ctx, db.Name, s.Name, t.Name)
-						if err == nil {
-							t.FKs = fks
-						} else {
-							warnings = append(warnings, fmt.Sprintf("fkeys(%s.%s): %v", s.Name, t.Name, err))
-						}
-					}
-				}
+		// Keep a complete schema for the next connect; the cache is a
+		// convenience, so failing to write it is not reported.
+		var hash string
+		if cache != nil && cacheKey != "" {
+			hash = schemacache.Hash(databases)
+			if len(warnings) == 0 {
+				_ = cache.Save(schemacache.Entry{Key: cacheKey, Hash: hash, Lazy: lazy, Databases: databases})
 			}
-			databases = append(databases, db)
 		}
 
-		return SchemaLoadedMsg{Databases: databases, ConnGen: gen, Warnings: warnings}
+		return SchemaLoadedMsg{Databases: databases, ConnGen: gen, Warnings: warnings, Lazy: lazy, Hash: hash}
 	}
 }
 
-func (m *Model) executeQuery(query string, tabID int) tea.Cmd {
+// executeQuery runs query in the tab. With autoLimit, a SELECT without a
+// LIMIT of its own gets results.auto_limit added.
+func (m *Model) executeQuery(msg ExecuteQueryMsg) tea.Cmd {
+	query, tabID := msg.Query, msg.TabID
 	conn := m.conn
 	ts := m.tabStates[tabID]
 	if ts == nil {
 		return nil
 	}
 	ts.Query = query
+	ts.Ran, ts.AutoLimit = query, 0
+	if n := m.cfg.Results.AutoLimit; !msg.NoLimit && n > 0 && conn != nil {
+		if limited, ok := adapter.LimitQuery(conn.AdapterName(), query, n); ok {
+			ts.Ran, ts.AutoLimit = limited, n
+		}
+	}
+	run := ts.Ran
+	ts.started = time.Now()
 	ts.RunID++
+	ts.stopCount()
 	runID := ts.RunID
 	connGen := m.connGen
+	pageSize := ts.Results.PageSize()
 	isSelect := adapter.IsSelectQuery(query)
+	tracer := m.tracer
+	begin := m.beginTx()
+	timeout := m.queryTimeout()
+	if msg.NoTimeout {
+		timeout = 0
+	}
+	if cmd, ok := m.executeCopy(query, tabID, runID); ok {
+		return cmd
+	}
+	args := msg.Args
+	var script []adapter.Statement
+	if conn != nil && args == nil {
+		script = scriptStatements(conn.AdapterName(), query)
+	}
 
 	// No timeout on the parent context — streaming iterators may be browsed
 	// for hours. Cancellation is explicit (Ctrl+C, new query, tab close, quit).
@@ -1171,19 +2583,42 @@ func (m *Model) executeQuery(query string, tabID int) tea.Cmd {
 	m.cancelFunc = cancel
 
 	return tea.Batch(
-		func() tea.Msg { return QueryStartedMsg{TabID: tabID, RunID: runID, ConnGen: connGen} },
+		func() tea.Msg {
+			return QueryStartedMsg{TabID: tabID, RunID: runID, ConnGen: connGen, Timeout: timeout}
+		},
 		func() tea.Msg {
 			if conn == nil {
 				cancel()
 				return QueryErrMsg{Err: adapter.ErrNotConnected, TabID: tabID, RunID: runID, ConnGen: connGen}
 			}
 
-			start := time.Now()
+			if begin != nil {
+				if err := begin(ctx); err != nil {
+					cancel()
+					return QueryErrMsg{Err: err, TabID: tabID, RunID: runID, ConnGen: connGen}
+				}
+			}
 
-			// Streaming path for SELECT-like queries
-			if isSelect {
-				iter, err := conn.ExecuteStreaming(ctx, query, 1000)
+			if script != nil {
+				defer cancel()
+				result, err := runScript(ctx, conn, tracer, query, script, timeout)
+				if err != nil {
+					return QueryErrMsg{Err: err, TabID: tabID, RunID: runID, ConnGen: connGen}
+				}
+				return QueryResultMsg{Result: result, TabID: tabID, RunID: runID, ConnGen: connGen}
+			}
+
+			start := time.Now()
+			span, sent := tracer.Start(run, conn.AdapterName(), conn.DatabaseName())
+
+			// Streaming path for SELECT-like queries. Iterators page on
+			// sessions of their own, so inside a transaction, which they
+			// would not see, the query runs whole, as does one with bind
+			// values.
+			if isSelect && begin == nil && args == nil {
+				iter, err := conn.ExecuteStreaming(ctx, sent, pageSize)
 				if err == nil {
+					span.End(-1, nil)
 					// Don't cancel — iterator needs context alive for page fetches
 					return QueryStreamingMsg{
 						Iterator: iter,
@@ -1196,15 +2631,31 @@ func (m *Model) executeQuery(query string, tabID int) tea.Cmd {
 				// Streaming failed, fall through to Execute
 			}
 
-			// Non-streaming path (or streaming fallback): add 5-min timeout
-			execCtx, execCancel := context.WithTimeout(ctx, 5*time.Minute)
-			defer execCancel()
+			// Non-streaming path (or streaming fallback): add the query
+			// timeout, if any.
+			execCtx := ctx
+			if timeout > 0 {
+				var execCancel context.CancelFunc
+				execCtx, execCancel = context.WithTimeout(ctx, timeout)
+				defer execCancel()
+			}
 			defer cancel()
 
-			result, err := conn.Execute(execCtx, query)
+			var result *adapter.QueryResult
+			var err error
+			if args != nil {
+				result, err = executeParams(execCtx, conn, sent, args)
+			} else {
+				result, err = conn.Execute(execCtx, sent)
+			}
 			if err != nil {
+				if errors.Is(execCtx.Err(), context.DeadlineExceeded) {
+					err = fmt.Errorf("timed out after %s (Alt+Enter runs without a timeout): %w", timeout, err)
+				}
+				span.End(-1, err)
 				return QueryErrMsg{Err: err, TabID: tabID, RunID: runID, ConnGen: connGen}
 			}
+			span.End(result.RowCount, nil)
 
 			return QueryResultMsg{Result: result, TabID: tabID, RunID: runID, ConnGen: connGen}
 		},
@@ -1215,6 +2666,28 @@ func (m *Model) executeQuery(query string, tabID int) tea.Cmd {
 func (m *Model) SetConnection(conn adapter.Connection, adapterName, dsn string) {
 	m.conn = conn
 	m.dsn = audit.SanitizeDSN(dsn)
+	m.sidebar.SetDialect(adapterName)
+	m.loadFavorites()
+}
+
+// SetTracer sets the tracer that exports a span for each query run.
+func (m *Model) SetTracer(t *telemetry.Tracer) {
+	m.tracer = t
+}
+
+// SetRedactor sets the redactor that masks secrets in queries written to
+// the history and the audit log.
+func (m *Model) SetRedactor(r *adapter.Redactor) {
+	m.redactor = r
+}
+
+// redact masks the secrets in query before it is saved.
+func (m *Model) redact(query string) string {
+	dialect := ""
+	if m.conn != nil {
+		dialect = m.conn.AdapterName()
+	}
+	return m.redactor.Redact(dialect, query)
 }
 
 func (m *Model) auditLog(query string, durationMS, rowCount int64, isError bool) {
@@ -1223,7 +2696,7 @@ func (m *Model) auditLog(query string, durationMS, rowCount int64, isError bool)
 	}
 	m.audit.Log(audit.Entry{
 		Timestamp:    time.Now(),
-		Query:        query,
+		Query:        m.redact(query),
 		Adapter:      m.conn.AdapterName(),
 		DatabaseName: m.conn.DatabaseName(),
 		DurationMS:   durationMS,
@@ -1248,7 +2721,9 @@ func (m *Model) ShowConnManager() {
 	m.connMgr.Show()
 }
 
-func (m *Model) exportResults() tea.Cmd {
+// exportResults writes the active tab's results to a file in the working
+// directory, as CSV or, if format is "json", JSON.
+func (m *Model) exportResults(format string) tea.Cmd {
 	ts := m.activeTabState()
 	if ts == nil {
 		return nil
@@ -1266,8 +2741,12 @@ func (m *Model) exportResults() tea.Cmd {
 		if err != nil {
 			return ExportErrMsg{Err: err}
 		}
-		path := filepath.Join(dir, fmt.Sprintf("export_%s.csv", time.Now().Format("20060102_150405")))
-		if err := results.ExportCSV(path, cols, rows); err != nil {
+		export, ext := results.ExportCSV, "csv"
+		if format == "json" {
+			export, ext = results.ExportJSON, "json"
+		}
+		path := filepath.Join(dir, fmt.Sprintf("export_%s.%s", time.Now().Format("20060102_150405"), ext))
+		if err := export(path, cols, rows); err != nil {
 			return ExportErrMsg{Err: err}
 		}
 		return ExportCompleteMsg{Path: path, RowCount: int64(len(rows))}
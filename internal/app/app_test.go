@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"io"
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -10,6 +11,8 @@ import (
 	"github.com/sadopc/gotermsql/internal/adapter"
 	"github.com/sadopc/gotermsql/internal/config"
 	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/telemetry"
+	"github.com/sadopc/gotermsql/internal/ui/results"
 )
 
 // ---------------------------------------------------------------------------
@@ -336,10 +339,34 @@ func TestUpdate_SwitchTabMsg_BlursInactiveTabs(t *testing.T) {
 	}
 }
 
+func TestUpdate_ResultsFilterCapturesShortcutKeys(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	m.setFocus(PaneResults)
+
+	model, _ := m.Update(keyMsgFromString("/"))
+	m = model.(Model)
+	if !m.activeTabState().Results.Filtering() {
+		t.Fatal("expected results filter input to be focused")
+	}
+
+	for _, key := range []string{"?", "tab"} {
+		model, _ = m.Update(keyMsgFromString(key))
+		m = model.(Model)
+	}
+	if m.showHelp {
+		t.Error("? typed into the filter should not open help")
+	}
+	if m.focusedPane != PaneResults {
+		t.Errorf("tab typed into the filter should not move focus, got pane %v", m.focusedPane)
+	}
+}
+
 type testConn struct {
 	dbName      string
 	cancelCalls int
 	closed      bool
+	executed    []string
+	result      *adapter.QueryResult
 }
 
 func (c *testConn) Databases(context.Context) ([]schema.Database, error) {
@@ -357,8 +384,9 @@ func (c *testConn) Indexes(context.Context, string, string, string) ([]schema.In
 func (c *testConn) ForeignKeys(context.Context, string, string, string) ([]schema.ForeignKey, error) {
 	return nil, nil
 }
-func (c *testConn) Execute(context.Context, string) (*adapter.QueryResult, error) {
-	return nil, nil
+func (c *testConn) Execute(_ context.Context, query string) (*adapter.QueryResult, error) {
+	c.executed = append(c.executed, query)
+	return c.result, nil
 }
 func (c *testConn) Cancel() error {
 	c.cancelCalls++
@@ -488,3 +516,118 @@ func keyMsgFromString(s string) tea.KeyMsg {
 		}
 	}
 }
+
+func TestExecuteQuery_Traced(t *testing.T) {
+	tracer, err := telemetry.New(telemetry.Options{Endpoint: "http://127.0.0.1:1", Propagate: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tracer.Close()
+
+	m := New(config.DefaultConfig(), nil, nil)
+	conn := &testConn{dbName: "app", result: &adapter.QueryResult{RowCount: 2}}
+	m.conn = conn
+	m.SetTracer(tracer)
+	tabID := m.tabs.ActiveID()
+
+	msgs := drainBatch(m.executeQuery(ExecuteQueryMsg{Query: "UPDATE t SET a = 1", TabID: tabID}))
+	if len(conn.executed) != 1 || !strings.HasPrefix(conn.executed[0], "UPDATE t SET a = 1 /*traceparent='00-") {
+		t.Fatalf("executed %q, want the query with a traceparent comment", conn.executed)
+	}
+	if got := m.tabStates[tabID].Query; got != "UPDATE t SET a = 1" {
+		t.Errorf("tab query = %q, want it without the comment", got)
+	}
+	var done bool
+	for _, msg := range msgs {
+		if _, ok := msg.(QueryResultMsg); ok {
+			done = true
+		}
+	}
+	if !done {
+		t.Errorf("got %v, want a QueryResultMsg", msgs)
+	}
+}
+
+// sqliteConn is a testConn speaking a dialect LimitQuery knows.
+type sqliteConn struct{ *testConn }
+
+func (sqliteConn) AdapterName() string { return "sqlite" }
+
+func TestExecuteQuery_AutoLimit(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Results.AutoLimit = 1000
+	m := New(cfg, nil, nil)
+	m.conn = sqliteConn{&testConn{dbName: "app"}}
+	tabID := m.tabs.ActiveID()
+	ts := m.tabStates[tabID]
+
+	m.executeQuery(ExecuteQueryMsg{Query: "SELECT * FROM t;", TabID: tabID})
+	if ts.Ran != "SELECT * FROM t\nLIMIT 1000" || ts.AutoLimit != 1000 {
+		t.Errorf("ran %q (limit %d), want the query with LIMIT 1000", ts.Ran, ts.AutoLimit)
+	}
+	if ts.Query != "SELECT * FROM t;" {
+		t.Errorf("tab query = %q, want it as written", ts.Query)
+	}
+
+	// L re-runs the query as written.
+	_, cmd := m.Update(results.RemoveLimitMsg{TabID: tabID})
+	var rerun ExecuteQueryMsg
+	for _, msg := range drainBatch(cmd) {
+		if e, ok := msg.(ExecuteQueryMsg); ok {
+			rerun = e
+		}
+	}
+	if rerun.Query != "SELECT * FROM t;" || !rerun.NoLimit {
+		t.Fatalf("got %#v, want the query re-run without a limit", rerun)
+	}
+	m.Update(rerun)
+	if ts.Ran != "SELECT * FROM t;" || ts.AutoLimit != 0 {
+		t.Errorf("ran %q (limit %d), want the query unchanged", ts.Ran, ts.AutoLimit)
+	}
+
+	m.executeQuery(ExecuteQueryMsg{Query: "SELECT * FROM t LIMIT 5", TabID: tabID})
+	if ts.AutoLimit != 0 {
+		t.Error("a query with its own LIMIT should run unchanged")
+	}
+}
+
+func TestVimMode_EditorKeys(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.KeyMode = "vim"
+	m := New(cfg, nil, nil)
+	m.conn = &testConn{dbName: "app"}
+	ed := &m.tabStates[m.tabs.ActiveID()].Editor
+	ed.SetValue("SELECT 1")
+	ed.SetCursor(0, 0)
+	press := func(k tea.KeyMsg) {
+		model, _ := m.Update(k)
+		m = model.(Model)
+		ed = &m.tabStates[m.tabs.ActiveID()].Editor
+	}
+
+	press(keyMsgFromString("x"))
+	if got := ed.Value(); got != "ELECT 1" {
+		t.Errorf("x in normal mode: Value() = %q, want %q", got, "ELECT 1")
+	}
+	if m.autocomp.Visible() {
+		t.Error("vim commands should not open completions")
+	}
+	press(keyMsgFromString("u"))
+	press(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if got := ed.Value(); got != "ELECT 1" {
+		t.Errorf("ctrl+r in normal mode should redo, not reload the schema: Value() = %q", got)
+	}
+	press(keyMsgFromString("i"))
+	if m.vimState != VimInsert {
+		t.Errorf("vimState = %v, want INSERT", m.vimState)
+	}
+
+	model, _ := m.Update(ToggleKeyModeMsg{})
+	m = model.(Model)
+	ed = &m.tabStates[m.tabs.ActiveID()].Editor
+	ed.SetCursor(0, 0)
+	press(keyMsgFromString("x"))
+	if got := ed.Value(); got != "xELECT 1" {
+		t.Errorf("standard mode: Value() = %q, want %q", got, "xELECT 1")
+	}
+}
@@ -0,0 +1,18 @@
+package app
+
+import "github.com/sadopc/gotermsql/internal/adapter"
+
+// attaches returns whether query attaches or detaches a database on a
+// connection that lists attached databases in the sidebar, so the schema
+// needs loading again once it has run.
+func (m *Model) attaches(query string) bool {
+	if _, ok := m.conn.(adapter.Attacher); !ok {
+		return false
+	}
+	for _, stmt := range adapter.SplitStatements(m.conn.AdapterName(), query) {
+		if adapter.ParseAttach(stmt.Text) != nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+// attachConn is a sqlite testConn that attaches databases.
+type attachConn struct{ sqliteConn }
+
+func (attachConn) Attach(context.Context, string, string) error { return nil }
+func (attachConn) Detach(context.Context, string) error         { return nil }
+
+func TestAttach_RefreshesSchema(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	tabID := m.tabs.ActiveID()
+	ts := m.tabStates[tabID]
+
+	run := func(query string) bool {
+		m.schemaRefresh = false
+		ts.Query = query
+		model, _ := m.Update(QueryResultMsg{Result: &adapter.QueryResult{}, TabID: tabID, RunID: ts.RunID})
+		m = model.(Model)
+		return m.schemaRefresh
+	}
+
+	m.conn = attachConn{sqliteConn{&testConn{dbName: "app.db"}}}
+	if !run("SELECT 1;\nATTACH DATABASE 'archive.db' AS old;") {
+		t.Error("ATTACH should reload the schema")
+	}
+	if !run("detach old") {
+		t.Error("DETACH should reload the schema")
+	}
+	if run("SELECT 1") {
+		t.Error("SELECT should leave the schema as it is")
+	}
+
+	m.conn = sqliteConn{&testConn{dbName: "app.db"}}
+	if run("ATTACH 'archive.db' AS old") {
+		t.Error("a connection without attached databases in the sidebar should not reload")
+	}
+}
@@ -0,0 +1,268 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/ddl"
+	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/ui/bulkupdate"
+)
+
+// bulkUpdateTimeout bounds reading the columns, the preview and the
+// UPDATE of the bulk UPDATE wizard.
+const bulkUpdateTimeout = 60 * time.Second
+
+// bulkPreviewRows is how many of the rows matched the preview shows.
+const bulkPreviewRows = 20
+
+// bulkColumnsMsg carries the columns of the table of request, read on
+// connection generation connGen.
+type bulkColumnsMsg struct {
+	request BulkUpdateMsg
+	columns []schema.Column
+	err     error
+	connGen uint64
+}
+
+// bulkPreviewMsg carries the rows the UPDATE statement matches.
+type bulkPreviewMsg struct {
+	statement string
+	count     int64
+	header    []string
+	rows      [][]string
+	err       error
+	connGen   uint64
+}
+
+// bulkUpdatedMsg reports the UPDATE ran, in a transaction still open when
+// inTx is set.
+type bulkUpdatedMsg struct {
+	query    string
+	rowCount int64
+	duration time.Duration
+	inTx     bool
+	err      error
+	connGen  uint64
+}
+
+// openBulkUpdate reads the columns of a table in the background, to open
+// the bulk UPDATE wizard on it.
+func (m *Model) openBulkUpdate(msg BulkUpdateMsg) tea.Cmd {
+	if m.conn == nil {
+		return m.toast(ToastError, "Not connected")
+	}
+	if m.safeMode {
+		return m.toast(ToastError, safeModeBlocked)
+	}
+	m.bulkUpdateFor = msg
+	conn, connGen := m.conn, m.connGen
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), bulkUpdateTimeout)
+		defer cancel()
+		cols, err := conn.Columns(ctx, msg.Database, msg.Schema, msg.Table)
+		return bulkColumnsMsg{request: msg, columns: cols, err: err, connGen: connGen}
+	}
+}
+
+// handleBulkColumns opens the wizard, unless another was asked for since.
+func (m *Model) handleBulkColumns(msg bulkColumnsMsg) tea.Cmd {
+	if msg.connGen != m.connGen || msg.request != m.bulkUpdateFor {
+		return nil
+	}
+	if msg.err != nil {
+		return m.toast(ToastError, "Could not read the columns: "+sanitizeError(msg.err.Error()))
+	}
+	if len(msg.columns) == 0 {
+		return m.toast(ToastError, msg.request.Table+" has no columns")
+	}
+	m.bulkUpdate.Show(msg.request.Table, msg.columns)
+	return nil
+}
+
+// bulkWhere writes each condition of a WHERE clause, its value a literal
+// of its column's type.
+func bulkWhere(dialect string, where []bulkupdate.Condition) ([]string, error) {
+	var conds []string
+	for _, c := range where {
+		col := adapter.QuoteIdentifier(dialect, c.Column.Name)
+		switch c.Operator {
+		case "IS NULL", "IS NOT NULL":
+			conds = append(conds, col+" "+c.Operator)
+		case "LIKE":
+			conds = append(conds, col+" LIKE "+adapter.QuoteLiteral(dialect, c.Value))
+		case "IN":
+			var values []string
+			for _, v := range strings.Split(c.Value, ",") {
+				lit, err := ddl.Literal(dialect, c.Column.Type, strings.TrimSpace(v))
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", c.Column.Name, err)
+				}
+				values = append(values, lit)
+			}
+			conds = append(conds, col+" IN ("+strings.Join(values, ", ")+")")
+		default:
+			lit, err := ddl.Literal(dialect, c.Column.Type, c.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", c.Column.Name, err)
+			}
+			conds = append(conds, col+" "+c.Operator+" "+lit)
+		}
+	}
+	return conds, nil
+}
+
+// buildBulkUpdate writes the UPDATE of table setting set on the rows
+// matching every condition of where, each value a literal of its column's
+// type.
+func buildBulkUpdate(dialect, table string, set []bulkupdate.Assignment, where []string) (string, error) {
+	assigns := make([]string, len(set))
+	for i, a := range set {
+		lit, err := ddl.Literal(dialect, a.Column.Type, a.Value)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", a.Column.Name, err)
+		}
+		assigns[i] = adapter.QuoteIdentifier(dialect, a.Column.Name) + " = " + lit
+	}
+	stmt := "UPDATE " + table + "\nSET " + strings.Join(assigns, ",\n    ")
+	if len(where) > 0 {
+		stmt += "\nWHERE " + strings.Join(where, "\n  AND ")
+	}
+	return stmt, nil
+}
+
+// bulkUpdateTable returns the table of the wizard quoted for dialect.
+func (m *Model) bulkUpdateTable(dialect string) string {
+	name := adapter.QuoteIdentifier(dialect, m.bulkUpdateFor.Table)
+	if m.bulkUpdateFor.Schema != "" {
+		name = adapter.QuoteIdentifier(dialect, m.bulkUpdateFor.Schema) + "." + name
+	}
+	return name
+}
+
+// previewBulkUpdate writes the UPDATE of the wizard and counts and reads
+// the rows it matches in the background.
+func (m *Model) previewBulkUpdate(msg bulkupdate.PreviewMsg) tea.Cmd {
+	if m.conn == nil {
+		m.bulkUpdate.SetError("Not connected")
+		return nil
+	}
+	dialect := m.conn.AdapterName()
+	table := m.bulkUpdateTable(dialect)
+	where, err := bulkWhere(dialect, msg.Where)
+	if err == nil {
+		m.bulkUpdateStmt, err = buildBulkUpdate(dialect, table, msg.Set, where)
+	}
+	if err != nil {
+		m.bulkUpdateStmt = ""
+		m.bulkUpdate.SetError(err.Error())
+		return nil
+	}
+	from := " FROM " + table
+	if len(where) > 0 {
+		from += " WHERE " + strings.Join(where, " AND ")
+	}
+	conn, connGen, stmt := m.conn, m.connGen, m.bulkUpdateStmt
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(adapter.OnPool(context.Background()), bulkUpdateTimeout)
+		defer cancel()
+		preview := bulkPreviewMsg{statement: stmt, connGen: connGen}
+		res, err := conn.Execute(ctx, "SELECT COUNT(*)"+from)
+		if err != nil {
+			preview.err = err
+			return preview
+		}
+		if len(res.Rows) == 1 && len(res.Rows[0]) == 1 {
+			fmt.Sscan(res.Rows[0][0], &preview.count)
+		}
+		if res, err = conn.Execute(ctx, fmt.Sprintf("SELECT *%s LIMIT %d", from, bulkPreviewRows)); err != nil {
+			preview.err = err
+			return preview
+		}
+		for _, c := range res.Columns {
+			preview.header = append(preview.header, c.Name)
+		}
+		preview.rows = res.Rows
+		return preview
+	}
+}
+
+// handleBulkPreview shows the rows matched, unless the UPDATE changed
+// since.
+func (m *Model) handleBulkPreview(msg bulkPreviewMsg) {
+	if msg.connGen != m.connGen || msg.statement != m.bulkUpdateStmt {
+		return
+	}
+	if msg.err != nil {
+		m.bulkUpdate.SetError("Preview failed: " + sanitizeError(msg.err.Error()))
+		return
+	}
+	m.bulkUpdate.SetPreview(msg.statement, msg.count, msg.header, msg.rows)
+}
+
+// runBulkUpdate runs the UPDATE previewed in a transaction, opening one
+// unless one is open already, so that it can be committed or rolled back
+// after. A failed UPDATE rolls back the transaction it opened.
+func (m *Model) runBulkUpdate() tea.Cmd {
+	if m.conn == nil || m.bulkUpdateStmt == "" {
+		return nil
+	}
+	if m.safeMode {
+		m.bulkUpdate.SetError(safeModeBlocked)
+		return nil
+	}
+	conn, gen, tracer, tx, stmt := m.conn, m.connGen, m.tracer, m.transactor(), m.bulkUpdateStmt
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), bulkUpdateTimeout)
+		defer cancel()
+		began := false
+		if tx != nil && !tx.InTransaction() {
+			if err := tx.Begin(ctx); err != nil {
+				return bulkUpdatedMsg{query: stmt, err: err, connGen: gen}
+			}
+			began = true
+		}
+		start := time.Now()
+		span, sent := tracer.Start(stmt, conn.AdapterName(), conn.DatabaseName())
+		res, err := conn.Execute(ctx, sent)
+		done := bulkUpdatedMsg{query: stmt, duration: time.Since(start), inTx: tx != nil, err: err, connGen: gen}
+		if res != nil {
+			done.rowCount = res.RowCount
+		}
+		if err != nil {
+			span.End(-1, err)
+			if began {
+				_ = tx.Rollback(ctx)
+			}
+			return done
+		}
+		span.End(done.rowCount, nil)
+		return done
+	}
+}
+
+// handleBulkUpdated records the UPDATE and asks to commit or roll it back,
+// or shows why it failed.
+func (m *Model) handleBulkUpdated(msg bulkUpdatedMsg) {
+	if msg.connGen != m.connGen {
+		return
+	}
+	m.syncTransaction()
+	if msg.err != nil {
+		m.auditLog(msg.query, msg.duration.Milliseconds(), 0, true)
+		m.bulkUpdate.SetError("Update failed: " + sanitizeError(msg.err.Error()))
+		return
+	}
+	m.recordWrite(msg.query, msg.duration, msg.rowCount)
+	text := fmt.Sprintf("%d rows updated", msg.rowCount)
+	if msg.inTx {
+		text += " in a transaction, not committed yet"
+	} else {
+		text += "; " + m.conn.AdapterName() + " has no transactions to roll it back"
+	}
+	m.bulkUpdate.Done(text, msg.inTx)
+}
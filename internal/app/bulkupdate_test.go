@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/ui/bulkupdate"
+)
+
+func TestBuildBulkUpdate(t *testing.T) {
+	id := schema.Column{Name: "id", Type: "integer"}
+	status := schema.Column{Name: "status", Type: "text"}
+	where, err := bulkWhere("postgres", []bulkupdate.Condition{
+		{Column: id, Operator: "IN", Value: "1, 2,3"},
+		{Column: status, Operator: "LIKE", Value: "it's%"},
+		{Column: status, Operator: "IS NOT NULL"},
+		{Column: id, Operator: ">=", Value: "0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := []bulkupdate.Assignment{{Column: status, Value: "done"}, {Column: id, Value: adapter.NullValue}}
+	got, err := buildBulkUpdate("postgres", `"public"."orders"`, set, where)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `UPDATE "public"."orders"
+SET "status" = 'done',
+    "id" = NULL
+WHERE "id" IN (1, 2, 3)
+  AND "status" LIKE 'it''s%'
+  AND "status" IS NOT NULL
+  AND "id" >= 0`
+	if got != want {
+		t.Errorf("UPDATE =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, err := bulkWhere("postgres", []bulkupdate.Condition{{Column: id, Operator: "IN", Value: "1,x"}}); err == nil || !strings.HasPrefix(err.Error(), "id: ") {
+		t.Errorf("a value not a number should fail naming its column, got %v", err)
+	}
+	if _, err := buildBulkUpdate("postgres", "orders", []bulkupdate.Assignment{{Column: id, Value: "x"}}, nil); err == nil {
+		t.Error("a value not a number should fail")
+	}
+}
+
+func TestBulkUpdate(t *testing.T) {
+	sc := openSQLite(t, filepath.Join(t.TempDir(), "shop.db"),
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, status TEXT)",
+		"INSERT INTO orders VALUES (1, 'new'), (2, 'new'), (3, 'paid')")
+	conn, closeConn, err := connectSaved(context.Background(), sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeConn()
+	m := step(New(config.DefaultConfig(), nil, nil), tea.WindowSizeMsg{Width: 140, Height: 40})
+	m.conn = conn
+
+	m = step(m, BulkUpdateMsg{Schema: "main", Table: "orders"})
+	if !m.bulkUpdate.Visible() {
+		t.Fatal("the bulk UPDATE wizard should open")
+	}
+	cols, err := conn.Columns(context.Background(), "", "main", "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	preview := bulkupdate.PreviewMsg{
+		Set:   []bulkupdate.Assignment{{Column: cols[1], Value: "shipped"}},
+		Where: []bulkupdate.Condition{{Column: cols[1], Operator: "=", Value: "new"}},
+	}
+	run := func(m Model) Model {
+		// Reach the preview page, as enter on the conditions does.
+		m.bulkUpdate.Show("orders", cols)
+		m.bulkUpdate, _ = m.bulkUpdate.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1")})
+		m.bulkUpdate, _ = m.bulkUpdate.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m.bulkUpdate, _ = m.bulkUpdate.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = step(m, preview)
+		if view := m.bulkUpdate.View(); !strings.Contains(view, "2 rows match") || !strings.Contains(view, `"status" = 'new'`) {
+			t.Fatalf("the preview should count 2 rows:\n%s", view)
+		}
+		return step(m, bulkupdate.RunMsg{})
+	}
+	count := func(status string) string {
+		res, err := conn.Execute(context.Background(), "SELECT COUNT(*) FROM orders WHERE status = '"+status+"'")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.Rows[0][0]
+	}
+
+	m = run(m)
+	if view := m.bulkUpdate.View(); !strings.Contains(view, "2 rows updated") || !m.inTransaction() {
+		t.Fatalf("the UPDATE should run in an open transaction:\n%s", view)
+	}
+	if got := count("shipped"); got != "2" {
+		t.Errorf("shipped inside the transaction = %s, want 2", got)
+	}
+	m = step(m, bulkupdate.EndMsg{Commit: false})
+	if got := count("shipped"); got != "0" || m.inTransaction() {
+		t.Errorf("shipped after the rollback = %s, want 0", got)
+	}
+
+	m = run(m)
+	m = step(m, bulkupdate.EndMsg{Commit: true})
+	if got := count("shipped"); got != "2" || m.inTransaction() {
+		t.Errorf("shipped after the commit = %s, want 2", got)
+	}
+}
+
+func TestBulkUpdate_SafeMode(t *testing.T) {
+	m := step(New(config.DefaultConfig(), nil, nil), tea.WindowSizeMsg{Width: 120, Height: 40})
+	m.conn = &testConn{dbName: "app"}
+	m.safeMode = true
+	model, _ := m.Update(BulkUpdateMsg{Table: "orders"})
+	if m = model.(Model); m.bulkUpdate.Visible() || m.bulkUpdateFor.Table != "" {
+		t.Error("safe mode should refuse the bulk UPDATE wizard")
+	}
+}
@@ -0,0 +1,196 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+// chordKeys reports whether two-key chords such as g d are read: while the
+// focused pane takes no text, which in the editor is vim normal mode with
+// no command half typed.
+func (m *Model) chordKeys() bool {
+	if m.focusedPane == PaneEditor {
+		ts := m.activeTabState()
+		return ts != nil && ts.Editor.VimIdle()
+	}
+	return !m.textInputFocused()
+}
+
+// handleChord reads the two keys of a chord from the KeyMap and runs it.
+// When the second key makes no chord, both keys are handled as if typed
+// alone, so g g still goes to the first line. It reports whether it took
+// the key.
+func (m *Model) handleChord(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if m.chordReplay {
+		return nil, false
+	}
+	if m.chord == nil {
+		if !m.startsChord(msg.String()) || !m.chordKeys() {
+			return nil, false
+		}
+		m.chord = &msg
+		return nil, true
+	}
+
+	first := *m.chord
+	m.chord = nil
+	keys := first.String() + " " + msg.String()
+	switch {
+	case slices.Contains(m.keyMap.GoToDefinition.Keys(), keys):
+		return m.goToDefinition(), true
+	case slices.Contains(m.keyMap.RefreshSchema.Keys(), keys):
+		return m.refreshSchema(), true
+	}
+
+	m.chordReplay = true
+	defer func() { m.chordReplay = false }()
+	var cmds []tea.Cmd
+	for _, k := range []tea.KeyMsg{first, msg} {
+		model, cmd := m.Update(k)
+		*m = model.(Model)
+		cmds = append(cmds, cmd)
+	}
+	return tea.Batch(cmds...), true
+}
+
+// startsChord reports whether some chord starts with k.
+func (m *Model) startsChord(k string) bool {
+	for _, b := range m.keyMap.Chords() {
+		for _, keys := range b.Keys() {
+			if first, _, ok := strings.Cut(keys, " "); ok && first == k {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// refreshSchema reloads the schema of the connection.
+func (m *Model) refreshSchema() tea.Cmd {
+	if m.conn == nil {
+		return nil
+	}
+	m.sidebar.SetLoading(true)
+	m.schemaRefresh = true
+	return m.loadSchema()
+}
+
+// goToDefinition shows the CREATE statement of the table or view named
+// under the editor's cursor, selecting it in the sidebar, or of the one
+// selected in the sidebar.
+func (m *Model) goToDefinition() tea.Cmd {
+	switch m.focusedPane {
+	case PaneSidebar:
+		return m.sidebar.ShowDDL()
+	case PaneEditor:
+	default:
+		return nil
+	}
+	ts := m.activeTabState()
+	if ts == nil {
+		return nil
+	}
+	name := ts.Editor.NameAtCursor()
+	if name == "" {
+		return exStatus("No table name under the cursor", true)
+	}
+	ref, ok := m.findRelation(name)
+	if !ok {
+		return exStatus("No table or view named "+name, true)
+	}
+	m.sidebar.RevealTable(ref.Database, ref.Schema, ref.Table)
+	return func() tea.Msg { return ShowDDLMsg(ref) }
+}
+
+// findRelation looks up a table or view in the loaded schema by name,
+// optionally qualified by schema and database. Unquoted parts match in any
+// case.
+func (m *Model) findRelation(name string) (ShowDDLMsg, bool) {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		parts[i] = strings.Trim(p, "\"`")
+	}
+	table := parts[len(parts)-1]
+	var schemaName, database string
+	if len(parts) > 1 {
+		schemaName = parts[len(parts)-2]
+	}
+	if len(parts) > 2 {
+		database = parts[len(parts)-3]
+	}
+	for _, db := range m.databases {
+		if database != "" && !strings.EqualFold(db.Name, database) {
+			continue
+		}
+		for _, s := range db.Schemas {
+			if schemaName != "" && !strings.EqualFold(s.Name, schemaName) {
+				continue
+			}
+			for _, t := range s.Tables {
+				if strings.EqualFold(t.Name, table) {
+					return ShowDDLMsg{Database: db.Name, Schema: s.Name, Table: t.Name}, true
+				}
+			}
+			for _, v := range s.Views {
+				if strings.EqualFold(v.Name, table) {
+					return ShowDDLMsg{Database: db.Name, Schema: s.Name, Table: v.Name}, true
+				}
+			}
+		}
+	}
+	return ShowDDLMsg{}, false
+}
+
+// KeyConflicts lists the leader mappings in cfg that cannot work as
+// written: a leader key that is also a built-in key in vim mode, and
+// mappings typed the same as, or starting with, another.
+func KeyConflicts(cfg *config.Config) []string {
+	if cfg == nil || len(cfg.Mappings) == 0 {
+		return nil
+	}
+	var conflicts []string
+	km := VimKeyMap()
+	leader := cfg.LeaderKey()
+	builtins := append(km.Chords(), km.Sequences...)
+	builtins = append(builtins, km.VimUp, km.VimDown, km.VimLeft, km.VimRight,
+		km.VimInsert, km.VimAppend, km.VimEscape, km.VimTop, km.VimBottom,
+		km.VimSearch, km.VimVisual, km.VimYank)
+	for _, group := range km.FullHelp() {
+		builtins = append(builtins, group...)
+	}
+	for _, b := range builtins {
+		if slices.ContainsFunc(b.Keys(), func(k string) bool {
+			first, _, _ := strings.Cut(k, " ")
+			return first == leader
+		}) {
+			conflicts = append(conflicts, fmt.Sprintf("the leader key %q is also %s", hintKeyName(leader), b.Help().Desc))
+			break
+		}
+	}
+
+	name := func(mp config.Mapping) string {
+		var keys []string
+		for _, k := range mp.Sequence() {
+			keys = append(keys, hintKeyName(k))
+		}
+		return "<leader>" + strings.Join(keys, "")
+	}
+	for i, a := range cfg.Mappings {
+		for _, b := range cfg.Mappings[:i] {
+			sa, sb := a.Sequence(), b.Sequence()
+			switch {
+			case slices.Equal(sa, sb):
+				conflicts = append(conflicts, name(a)+" is mapped twice")
+			case len(sa) < len(sb) && slices.Equal(sa, sb[:len(sa)]):
+				conflicts = append(conflicts, name(a)+" hides "+name(b))
+			case len(sb) < len(sa) && slices.Equal(sb, sa[:len(sb)]):
+				conflicts = append(conflicts, name(b)+" hides "+name(a))
+			}
+		}
+	}
+	return conflicts
+}
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+func TestChord_GoToDefinition(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.KeyMode = "vim"
+	m := New(cfg, nil, nil)
+	m.conn = &testConn{dbName: "app"}
+	m.databases = []schema.Database{{Name: "app", Schemas: []schema.Schema{
+		{Name: "public", Tables: []schema.Table{{Name: "users"}}, Views: []schema.View{{Name: "active_users"}}},
+	}}}
+	ed := &m.tabStates[m.tabs.ActiveID()].Editor
+	ed.SetValue("SELECT * FROM public.Users\nJOIN active_users\nJOIN nope")
+	ed.SetCursor(0, 22)
+	press := func(s string) tea.Cmd {
+		model, cmd := m.Update(keyMsgFromString(s))
+		m = model.(Model)
+		ed = &m.tabStates[m.tabs.ActiveID()].Editor
+		return cmd
+	}
+
+	press("g")
+	if hints := m.whichKey(80, 20); !strings.Contains(hints, "go to definition") || !strings.Contains(hints, "refresh schema") {
+		t.Errorf("hints after g:\n%s", hints)
+	}
+	want := ShowDDLMsg{Database: "app", Schema: "public", Table: "users"}
+	if cmd := press("d"); cmd == nil || cmd() != want {
+		t.Errorf("g d on public.Users did not show %+v", want)
+	}
+
+	ed.SetCursor(1, 8)
+	want.Table = "active_users"
+	if cmd := press("g"); cmd != nil {
+		t.Error("g should wait for the next key")
+	}
+	if cmd := press("d"); cmd == nil || cmd() != want {
+		t.Errorf("g d on a view did not show %+v", want)
+	}
+
+	ed.SetCursor(2, 6)
+	press("g")
+	if msg := press("d")(); !strings.Contains(msg.(StatusMsg).Text, "No table or view named nope") {
+		t.Errorf("g d on an unknown name sent %#v", msg)
+	}
+
+	// Keys that make no chord are handled as typed.
+	press("g")
+	press("g")
+	if line, _ := ed.Cursor(); line != 0 {
+		t.Errorf("g g: cursor line = %d, want 0", line)
+	}
+	if cmd := press("g"); cmd != nil {
+		t.Error("g should wait for the next key")
+	}
+	if cmd := press("r"); cmd == nil {
+		t.Error("g r should reload the schema")
+	}
+
+	// In insert mode g is text.
+	press("i")
+	press("g")
+	press("d")
+	if got := ed.Value(); !strings.HasPrefix(got, "gdSELECT") {
+		t.Errorf("insert mode: Value() = %q", got)
+	}
+}
+
+func TestChord_StandardEditorTypes(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	for _, s := range []string{"g", "d"} {
+		model, _ := m.Update(keyMsgFromString(s))
+		m = model.(Model)
+	}
+	if got := m.tabStates[m.tabs.ActiveID()].Editor.Value(); got != "gd" {
+		t.Errorf("standard mode editor: Value() = %q, want %q", got, "gd")
+	}
+}
+
+func TestKeyConflicts(t *testing.T) {
+	cfg := config.DefaultConfig()
+	if got := KeyConflicts(cfg); len(got) != 0 {
+		t.Errorf("no mappings: KeyConflicts() = %q", got)
+	}
+
+	cfg.Leader = "g"
+	cfg.Mappings = []config.Mapping{
+		{Keys: "<leader>p", Action: config.ActionExport},
+		{Keys: "<leader>pc", Action: config.ActionExport},
+		{Keys: "<leader>x", Action: config.ActionExport},
+		{Keys: "<leader>x", Action: config.ActionExport},
+	}
+	got := KeyConflicts(cfg)
+	want := []string{`the leader key "g"`, "<leader>p hides <leader>pc", "<leader>x is mapped twice"}
+	if len(got) != len(want) {
+		t.Fatalf("KeyConflicts() = %q, want %d conflicts", got, len(want))
+	}
+	for i, w := range want {
+		if !strings.Contains(got[i], w) {
+			t.Errorf("KeyConflicts()[%d] = %q, want it to contain %q", i, got[i], w)
+		}
+	}
+
+	cfg.Leader = "<space>"
+	cfg.Mappings = cfg.Mappings[:1]
+	if got := KeyConflicts(cfg); len(got) != 0 {
+		t.Errorf("<space> leader: KeyConflicts() = %q", got)
+	}
+	if msg := New(cfg, nil, nil).Init(); msg != nil {
+		t.Error("Init should report nothing without conflicts")
+	}
+}
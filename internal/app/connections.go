@@ -0,0 +1,126 @@
+package app
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/keychain"
+	"github.com/sadopc/gotermsql/internal/ui/connmgr"
+)
+
+// saveConnections saves the connection list from the connection manager.
+// With the keychain enabled, passwords move into it and the config file
+// keeps only their secret IDs; secrets of deleted connections are removed.
+// When the keychain is unavailable the password is saved as typed.
+func (m *Model) saveConnections(conns []config.SavedConnection) tea.Cmd {
+	conns = append([]config.SavedConnection(nil), conns...)
+	var warning string
+	if m.cfg.Keychain {
+		for i := range conns {
+			if err := keychain.Store(&conns[i]); err != nil {
+				warning = "Password saved in the config file: " + err.Error()
+			}
+		}
+		for _, id := range keychain.Orphans(m.cfg.Connections, conns) {
+			_ = keychain.Delete(id)
+		}
+	}
+	m.cfg.Connections = conns
+	m.connMgr.SetConnections(conns)
+
+	if err := m.cfg.SaveDefault(); err != nil {
+		text := "Failed to save connections: " + err.Error()
+		return func() tea.Msg { return StatusMsg{Text: text, IsError: true} }
+	}
+	if warning != "" {
+		return func() tea.Msg { return StatusMsg{Text: warning, IsError: true} }
+	}
+	return nil
+}
+
+// switchConnection connects to a connection picked in the switcher,
+// replacing the current one.
+func (m *Model) switchConnection(sc config.SavedConnection) tea.Cmd {
+	status := "Connecting to " + sc.Name + "…"
+	return tea.Batch(
+		connmgr.Connect(sc),
+		func() tea.Msg { return StatusMsg{Text: status} },
+	)
+}
+
+// touchRecent records that the saved connection name was just connected
+// to, for the switcher. Ad hoc connections (name "") are not recorded.
+func (m *Model) touchRecent(name string) tea.Cmd {
+	m.connName = name
+	if name == "" {
+		return nil
+	}
+	m.cfg.TouchRecent(name)
+	if err := m.cfg.SaveDefault(); err != nil {
+		text := "Failed to save recent connections: " + err.Error()
+		return func() tea.Msg { return StatusMsg{Text: text, IsError: true} }
+	}
+	return nil
+}
+
+// autoConnectMsg carries the outcome of the startup auto-connect, a
+// ConnectMsg or ConnectErrMsg, so that it can be dropped once aborted.
+type autoConnectMsg struct {
+	Msg tea.Msg
+}
+
+// ConnectLast starts connecting to the most recently used saved connection,
+// for auto_connect and --last. It returns nil when there is none. Until the
+// attempt finishes any key aborts it and opens the connection manager.
+func (m *Model) ConnectLast() tea.Cmd {
+	last := m.cfg.LastConnection()
+	if last == nil {
+		return nil
+	}
+	sc := *last
+	ctx, cancel := context.WithCancel(context.Background())
+	m.autoConnecting = sc.Name
+	m.autoCancel = cancel
+	m.statusbar, _ = m.statusbar.Update(StatusMsg{Text: "Connecting to " + sc.Name + "… (any key to cancel)"})
+	return func() tea.Msg {
+		// Connect resolves the password and environment references.
+		msg := connmgr.Connect(sc)()
+		if req, ok := msg.(connmgr.ConnectRequestMsg); ok {
+			msg = dialSaved(ctx, *req.Saved)
+		}
+		return autoConnectMsg{Msg: msg}
+	}
+}
+
+// abortAutoConnect cancels the startup auto-connect and opens the
+// connection manager instead.
+func (m *Model) abortAutoConnect() tea.Cmd {
+	m.autoCancel()
+	m.autoConnecting = ""
+	m.connMgr.Show()
+	var cmd tea.Cmd
+	m.statusbar, cmd = m.statusbar.Update(StatusMsg{Text: "Auto-connect cancelled"})
+	return cmd
+}
+
+// handleAutoConnect passes on the outcome of the auto-connect, or closes a
+// connection that arrives after it was aborted. A failed attempt opens the
+// connection manager.
+func (m Model) handleAutoConnect(msg autoConnectMsg) (tea.Model, tea.Cmd) {
+	if m.autoConnecting == "" {
+		if cm, ok := msg.Msg.(ConnectMsg); ok {
+			_ = cm.Conn.Close()
+			if cm.Tunnel != nil {
+				_ = cm.Tunnel.Close()
+			}
+		}
+		return m, nil
+	}
+	m.autoCancel()
+	m.autoConnecting = ""
+	if _, failed := msg.Msg.(ConnectErrMsg); failed {
+		m.connMgr.Show()
+	}
+	return m.Update(msg.Msg)
+}
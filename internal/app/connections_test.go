@@ -0,0 +1,206 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	_ "github.com/sadopc/gotermsql/internal/adapter/sqlite"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/ui/connmgr"
+	"github.com/sadopc/gotermsql/internal/ui/switcher"
+	"github.com/zalando/go-keyring"
+)
+
+func TestSaveConnections_PasswordsGoToKeychain(t *testing.T) {
+	keyring.MockInit()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+
+	m := New(config.DefaultConfig(), nil, nil)
+	updated, _ := m.Update(connmgr.ConnectionsUpdatedMsg{Connections: []config.SavedConnection{
+		{Name: "prod", Adapter: "postgres", Host: "db", User: "app", Password: "s3cret"},
+	}})
+	m = updated.(Model)
+
+	sc := m.cfg.Connections[0]
+	if sc.Password != "" || sc.SecretID == "" {
+		t.Fatalf("saved Password %q, SecretID %q; want only a secret ID", sc.Password, sc.SecretID)
+	}
+	dir, _ := config.ConfigDir()
+	data, err := os.ReadFile(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	if strings.Contains(string(data), "s3cret") {
+		t.Error("config file contains the plaintext password")
+	}
+	if pw, err := keyring.Get("gotermsql", sc.SecretID); err != nil || pw != "s3cret" {
+		t.Errorf("keychain = %q, %v; want s3cret", pw, err)
+	}
+
+	// Deleting the connection removes its secret.
+	updated, _ = m.Update(connmgr.ConnectionsUpdatedMsg{})
+	m = updated.(Model)
+	if _, err := keyring.Get("gotermsql", sc.SecretID); err == nil {
+		t.Error("secret of the deleted connection is still in the keychain")
+	}
+}
+
+func TestSwitcher_RecordsRecentAndSwitches(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+
+	cfg := config.DefaultConfig()
+	cfg.Connections = []config.SavedConnection{
+		{Name: "dev", Adapter: "postgres", Host: "dev-db"},
+		{Name: "prod", Adapter: "postgres", Host: "prod-db", Env: "prod"},
+	}
+	m := New(cfg, nil, nil)
+	model, _ := m.Update(ConnectMsg{Conn: &testConn{dbName: "app"}, Adapter: "postgres", DSN: "postgres://prod-db/app", Name: "prod"})
+	m = model.(Model)
+	if len(m.cfg.Recent) != 1 || m.cfg.Recent[0] != "prod" {
+		t.Fatalf("Recent = %v, want [prod]", m.cfg.Recent)
+	}
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	m = model.(Model)
+	if !m.switcher.Visible() {
+		t.Fatal("ctrl+p should open the switcher")
+	}
+	// The active connection is listed first, so enter picks the other one.
+	model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(Model)
+	pick, ok := cmd().(switcher.PickMsg)
+	if !ok || pick.Conn.Name != "dev" {
+		t.Fatalf("got %#v, want PickMsg for dev", cmd())
+	}
+
+	_, cmd = m.Update(pick)
+	var req *connmgr.ConnectRequestMsg
+	for _, msg := range drainBatch(cmd) {
+		if r, ok := msg.(connmgr.ConnectRequestMsg); ok {
+			req = &r
+		}
+	}
+	if req == nil || req.Saved == nil || req.Saved.Name != "dev" {
+		t.Errorf("switching should request a connection to dev, got %+v", req)
+	}
+}
+
+func TestConnectSaved_AppliesDefaults(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+
+	sc := config.SavedConnection{
+		Name:    "local",
+		Adapter: "sqlite",
+		File:    filepath.Join(t.TempDir(), "app.db"),
+		Defaults: &config.ExecDefaults{
+			PageSize:   50,
+			ReadOnly:   true,
+			StartupSQL: []string{"PRAGMA cache_size = 100"},
+		},
+	}
+	if got := sessionInit(sc); strings.Join(got, "; ") != "PRAGMA query_only = ON; PRAGMA cache_size = 100" {
+		t.Errorf("sessionInit() = %q", got)
+	}
+
+	m := New(config.DefaultConfig(), nil, nil)
+	cm, ok := m.connectSaved(sc)().(ConnectMsg)
+	if !ok {
+		t.Fatal("connectSaved should connect")
+	}
+	defer cm.Conn.Close()
+	if _, err := cm.Conn.Execute(context.Background(), "CREATE TABLE t (id INTEGER)"); err == nil {
+		t.Error("the read-only default should reject writes")
+	}
+
+	model, _ := m.Update(cm)
+	m = model.(Model)
+	if got := m.activeTabState().Results.PageSize(); got != 50 {
+		t.Errorf("page size = %d, want the connection's 50", got)
+	}
+}
+
+func TestConnectLast(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+
+	cfg := config.DefaultConfig()
+	cfg.Connections = []config.SavedConnection{
+		{Name: "local", Adapter: "sqlite", File: filepath.Join(t.TempDir(), "app.db")},
+	}
+	m := New(cfg, nil, nil)
+	if m.ConnectLast() != nil {
+		t.Fatal("ConnectLast() without a recent connection should do nothing")
+	}
+
+	cfg.Recent = []string{"local"}
+	m.statusbar.SetSize(120)
+	cmd := m.ConnectLast()
+	if cmd == nil {
+		t.Fatal("ConnectLast() should connect to the recent connection")
+	}
+	if !strings.Contains(m.statusbar.View(), "Connecting to local") {
+		t.Errorf("status bar should show the attempt:\n%s", m.statusbar.View())
+	}
+	model, _ := m.Update(cmd())
+	m = model.(Model)
+	if m.Connection() == nil || m.connName != "local" {
+		t.Fatalf("should be connected to local, got %q", m.connName)
+	}
+	m.Connection().Close()
+}
+
+func TestConnectLast_KeyAborts(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+
+	cfg := config.DefaultConfig()
+	cfg.Connections = []config.SavedConnection{
+		{Name: "local", Adapter: "sqlite", File: filepath.Join(t.TempDir(), "app.db")},
+	}
+	cfg.Recent = []string{"local"}
+	m := New(cfg, nil, nil)
+	cmd := m.ConnectLast()
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = model.(Model)
+	if !m.connMgr.Visible() {
+		t.Error("aborting should open the connection manager")
+	}
+
+	// The connection finishing afterwards is dropped.
+	model, _ = m.Update(cmd())
+	m = model.(Model)
+	if m.Connection() != nil {
+		t.Error("an aborted auto-connect should not connect")
+	}
+}
+
+// drainBatch runs cmd and the commands of any batches it returns, and
+// returns the messages produced.
+func drainBatch(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	batch, ok := msg.(tea.BatchMsg)
+	if !ok {
+		return []tea.Msg{msg}
+	}
+	var msgs []tea.Msg
+	for _, c := range batch {
+		msgs = append(msgs, drainBatch(c)...)
+	}
+	return msgs
+}
@@ -0,0 +1,188 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/ui/format"
+)
+
+// copyTickInterval is how often the progress of a \copy is redrawn.
+const copyTickInterval = 250 * time.Millisecond
+
+// copyProgress counts the bytes a \copy has moved.
+type copyProgress struct {
+	from  bool
+	done  atomic.Int64
+	total atomic.Int64 // size of the file imported, once known
+}
+
+// String describes the progress: the share of the file sent for an
+// import, the bytes written for an export.
+func (p *copyProgress) String() string {
+	done, total := p.done.Load(), p.total.Load()
+	if p.from && total > 0 {
+		return fmt.Sprintf("%s of %s sent (%d%%)", format.Size(done), format.Size(total), done*100/total)
+	}
+	if p.from {
+		return format.Size(done) + " sent"
+	}
+	return format.Size(done) + " written"
+}
+
+// copyTickMsg redraws the progress of the \copy running in a tab.
+type copyTickMsg struct {
+	TabID int
+	RunID uint64
+}
+
+func copyTick(tabID int, runID uint64) tea.Cmd {
+	return tea.Tick(copyTickInterval, func(time.Time) tea.Msg {
+		return copyTickMsg{TabID: tabID, RunID: runID}
+	})
+}
+
+// handleCopyTick shows the progress of the \copy while it runs.
+func (m *Model) handleCopyTick(msg copyTickMsg) tea.Cmd {
+	ts := m.tabStates[msg.TabID]
+	if !m.executing || m.executingTabID != msg.TabID || ts == nil || ts.RunID != msg.RunID || ts.copying == nil {
+		return nil
+	}
+	ts.Results.SetProgress(ts.copying.String())
+	return copyTick(msg.TabID, msg.RunID)
+}
+
+// readOnly returns whether safe mode lets query run: a statement
+// adapter.IsReadOnlyQuery accepts, or a \copy that exports.
+func readOnly(query string) bool {
+	if cp, err := adapter.ParseCopy(query); cp != nil || err != nil {
+		return cp != nil && cp.ReadOnly()
+	}
+	return adapter.IsReadOnlyQuery(query)
+}
+
+// executeCopy runs query as a psql-style \copy when it is one, streaming
+// the data between the server and the local file. It runs without the
+// query timeout, as bulk copies take as long as they take; Ctrl+C cancels
+// it, and an open or autocommit-off transaction holds it.
+func (m *Model) executeCopy(query string, tabID int, runID uint64) (tea.Cmd, bool) {
+	cp, err := adapter.ParseCopy(query)
+	if cp == nil && err == nil {
+		return nil, false
+	}
+	conn, connGen := m.conn, m.connGen
+	ts := m.tabStates[tabID]
+	progress := &copyProgress{from: cp != nil && cp.From}
+	ts.copying = progress
+	begin := m.beginTx()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelFunc = cancel
+
+	return tea.Batch(
+		func() tea.Msg {
+			return QueryStartedMsg{TabID: tabID, RunID: runID, ConnGen: connGen}
+		},
+		copyTick(tabID, runID),
+		func() tea.Msg {
+			defer cancel()
+			fail := func(err error) tea.Msg {
+				return QueryErrMsg{Err: err, TabID: tabID, RunID: runID, ConnGen: connGen}
+			}
+			if err != nil {
+				return fail(err)
+			}
+			if conn == nil {
+				return fail(adapter.ErrNotConnected)
+			}
+			copier, ok := conn.(adapter.Copier)
+			if !ok {
+				return fail(fmt.Errorf(`\copy is not available for %s`, conn.AdapterName()))
+			}
+			if begin != nil {
+				if err := begin(ctx); err != nil {
+					return fail(err)
+				}
+			}
+			start := time.Now()
+			rows, err := runCopy(ctx, copier, cp, progress)
+			if err != nil {
+				return fail(err)
+			}
+			dir := "to"
+			if cp.From {
+				dir = "from"
+			}
+			return QueryResultMsg{
+				Result: &adapter.QueryResult{
+					RowCount: rows,
+					Duration: time.Since(start),
+					Message:  fmt.Sprintf("COPY %d (%s %s %s)", rows, format.Size(progress.done.Load()), dir, filepath.Base(cp.File)),
+				},
+				TabID: tabID, RunID: runID, ConnGen: connGen,
+			}
+		},
+	), true
+}
+
+// runCopy streams the data of cp between the local file and copier,
+// counting the bytes in progress. A failed export leaves no file behind.
+func runCopy(ctx context.Context, copier adapter.Copier, cp *adapter.CopyCommand, progress *copyProgress) (int64, error) {
+	path := config.ExpandHome(cp.File)
+	if cp.From {
+		f, err := os.Open(path)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+		if info, err := f.Stat(); err == nil {
+			progress.total.Store(info.Size())
+		}
+		return copier.CopyFrom(ctx, &countingReader{r: f, n: &progress.done}, cp.SQL())
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := copier.CopyTo(ctx, &countingWriter{w: f, n: &progress.done}, cp.SQL())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return 0, err
+	}
+	return rows, nil
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n *atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n *atomic.Int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+// copyConn is a postgres testConn that runs COPY on the data it holds.
+type copyConn struct {
+	pgConn
+	data   string
+	copied *[]string // COPY statements run
+}
+
+func (c copyConn) CopyTo(_ context.Context, w io.Writer, query string) (int64, error) {
+	*c.copied = append(*c.copied, query)
+	_, err := io.WriteString(w, c.data)
+	return int64(strings.Count(c.data, "\n")), err
+}
+
+func (c copyConn) CopyFrom(_ context.Context, r io.Reader, query string) (int64, error) {
+	*c.copied = append(*c.copied, query)
+	data, err := io.ReadAll(r)
+	return int64(strings.Count(string(data), "\n")), err
+}
+
+func TestExecuteQuery_Copy(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	var copied []string
+	m.conn = copyConn{pgConn: pgConn{&testConn{dbName: "app"}}, data: "1,a\n2,b\n", copied: &copied}
+	tabID := m.tabs.ActiveID()
+	path := filepath.Join(t.TempDir(), "users.csv")
+
+	run := func(query string) tea.Msg {
+		model, cmd := m.Update(ExecuteQueryMsg{Query: query, TabID: tabID})
+		m = model.(Model)
+		for _, msg := range drainBatch(cmd) {
+			switch msg.(type) {
+			case QueryResultMsg, QueryErrMsg:
+				return msg
+			}
+		}
+		return nil
+	}
+
+	msg := run(`\copy users to '` + path + `' with (format csv)`)
+	if res, ok := msg.(QueryResultMsg); !ok || res.Result.Message != "COPY 2 (8B to users.csv)" {
+		t.Fatalf("got %#v, want the export result", msg)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "1,a\n2,b\n" {
+		t.Errorf("file = %q, want the data", data)
+	}
+
+	if readOnly(`\copy users from 'f'`) || !readOnly(`\copy users to 'f'`) {
+		t.Error("safe mode should block imports only")
+	}
+	msg = run(`\copy users from '` + path + `' with (format csv)`)
+	if res, ok := msg.(QueryResultMsg); !ok || res.Result.RowCount != 2 {
+		t.Fatalf("got %#v, want the import result", msg)
+	}
+	want := []string{"COPY users TO STDOUT with (format csv)", "COPY users FROM STDIN with (format csv)"}
+	if strings.Join(copied, "; ") != strings.Join(want, "; ") {
+		t.Errorf("ran %q, want %q", copied, want)
+	}
+
+	m.conn = sqliteConn{&testConn{dbName: "app"}}
+	if e, ok := run(`\copy users to 'x.csv'`).(QueryErrMsg); !ok || !strings.Contains(e.Err.Error(), "not available for sqlite") {
+		t.Errorf("got %#v, want an error on sqlite", e)
+	}
+}
+
+func TestCopyProgress(t *testing.T) {
+	p := &copyProgress{from: true}
+	p.total.Store(4 << 20)
+	p.done.Store(1 << 20)
+	if got := p.String(); got != "1M of 4M sent (25%)" {
+		t.Errorf("progress = %q", got)
+	}
+	p = &copyProgress{}
+	p.done.Store(1536)
+	if got := p.String(); got != "1.5K written" {
+		t.Errorf("progress = %q", got)
+	}
+}
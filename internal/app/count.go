@@ -0,0 +1,82 @@
+package app
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+)
+
+// countTimeout bounds the background COUNT(*) for a streaming result.
+const countTimeout = 5 * time.Minute
+
+// startCount runs SELECT COUNT(*) over the tab's streaming query so the
+// results footer can show "N of M rows". It runs through the connection
+// pool, so it does not block the connection the iterator streams from.
+// It returns nil when background counting is disabled or the query cannot
+// be wrapped.
+func (m *Model) startCount(ts *TabState, tabID int) tea.Cmd {
+	if m.cfg == nil || !m.cfg.Results.BackgroundCount || m.conn == nil {
+		return nil
+	}
+	countQuery := adapter.CountQuery(ts.sent())
+	if countQuery == "" {
+		return nil
+	}
+
+	ts.stopCount()
+	ctx, cancel := context.WithTimeout(adapter.OnPool(context.Background()), countTimeout)
+	ts.countCancel = cancel
+	conn := m.conn
+	runID := ts.RunID
+	connGen := m.connGen
+	return func() tea.Msg {
+		defer cancel()
+		msg := TotalRowsMsg{Count: -1, TabID: tabID, RunID: runID, ConnGen: connGen}
+		res, err := conn.Execute(ctx, countQuery)
+		if err != nil {
+			msg.Err = err
+			return msg
+		}
+		if res != nil && len(res.Rows) > 0 && len(res.Rows[0]) > 0 {
+			msg.Count = parseCount(res.Rows[0][0])
+		}
+		return msg
+	}
+}
+
+// handleTotalRows hands a background row count to the tab it belongs to.
+// Failures are ignored: the footer keeps showing the loaded row count.
+func (m *Model) handleTotalRows(msg TotalRowsMsg) {
+	if msg.ConnGen != m.connGen {
+		return
+	}
+	ts := m.tabStates[msg.TabID]
+	if ts == nil || msg.RunID != ts.RunID {
+		return
+	}
+	ts.countCancel = nil
+	if msg.Err == nil && msg.Count >= 0 {
+		ts.Results.SetTotalRows(msg.Count)
+	}
+}
+
+// stopCount cancels the tab's background count, if one is running.
+func (ts *TabState) stopCount() {
+	if ts.countCancel != nil {
+		ts.countCancel()
+		ts.countCancel = nil
+	}
+}
+
+// parseCount parses a COUNT(*) cell, or returns -1.
+func parseCount(s string) int64 {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+func TestStartCount_UpdatesTotalRows(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	conn := &testConn{result: &adapter.QueryResult{Rows: [][]string{{"42"}}}}
+	m.conn = conn
+	ts := m.tabStates[0]
+	ts.Query = "SELECT * FROM users;"
+	ts.RunID = 3
+	ts.Results.SetIterator(&testIter{})
+
+	cmd := m.startCount(ts, 0)
+	if cmd == nil {
+		t.Fatal("expected a count command")
+	}
+	msg := cmd().(TotalRowsMsg)
+	if want := "SELECT COUNT(*) FROM (SELECT * FROM users) AS gotermsql_count"; len(conn.executed) != 1 || conn.executed[0] != want {
+		t.Fatalf("executed %v, want %q", conn.executed, want)
+	}
+	if msg.Count != 42 || msg.RunID != 3 {
+		t.Fatalf("msg = %+v", msg)
+	}
+
+	// A count for an earlier run is ignored.
+	stale := msg
+	stale.RunID = 2
+	stale.Count = 7
+	model, _ := m.Update(stale)
+	m = model.(Model)
+	if got := m.tabStates[0].Results.RowCount(); got != -1 {
+		t.Fatalf("stale count applied: %d", got)
+	}
+
+	model, _ = m.Update(msg)
+	m = model.(Model)
+	if got := m.tabStates[0].Results.RowCount(); got != 42 {
+		t.Errorf("RowCount = %d, want 42", got)
+	}
+}
+
+func TestStartCount_Skipped(t *testing.T) {
+	cfg := config.DefaultConfig()
+	m := New(cfg, nil, nil)
+	m.conn = &testConn{}
+	ts := m.tabStates[0]
+
+	ts.Query = "SHOW TABLES"
+	if m.startCount(ts, 0) != nil {
+		t.Error("SHOW cannot be counted")
+	}
+
+	cfg.Results.BackgroundCount = false
+	ts.Query = "SELECT 1"
+	if m.startCount(ts, 0) != nil {
+		t.Error("background_count: false should disable counting")
+	}
+}
@@ -0,0 +1,424 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/ddl"
+	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/ui/datadiff"
+)
+
+// dataDiffLimit caps the rows that differ a comparison lists and writes
+// DML for; the rest are only counted.
+const dataDiffLimit = 10_000
+
+// diffTable is a table on a connection, whose rows are compared.
+type diffTable struct {
+	conn     adapter.Connection
+	database string
+	schema   string
+	table    string
+}
+
+// rowChange is a row that differs between the tables compared, with the
+// values of the columns compared on each side: from is nil for a row
+// added, to for a row deleted.
+type rowChange struct {
+	from, to []string
+}
+
+// tableDiff is what a comparison found. The columns compared are those
+// both tables have, the keys first, typed as the table compared, of
+// dialect, has them.
+type tableDiff struct {
+	dialect                       string
+	columns                       []schema.Column
+	keys                          int
+	added, deleted, changed, same int64
+	changes                       []rowChange // up to dataDiffLimit
+}
+
+// dataDiffTickMsg redraws the progress of comparison gen.
+type dataDiffTickMsg struct{ gen int }
+
+func dataDiffTick(gen int) tea.Cmd {
+	return tea.Tick(transferTickInterval, func(time.Time) tea.Msg { return dataDiffTickMsg{gen: gen} })
+}
+
+// dataDiffDoneMsg reports comparison gen finished.
+type dataDiffDoneMsg struct {
+	diff  *tableDiff
+	other string // the other table, named for the DML
+	err   error
+	gen   int
+}
+
+// openDataDiff opens the comparison of the rows of a table with a table on
+// the connection open or a saved one.
+func (m *Model) openDataDiff(msg CompareDataMsg) tea.Cmd {
+	if m.conn == nil {
+		return m.toast(ToastError, "Not connected")
+	}
+	targets := []datadiff.Target{{Current: true, Adapter: m.conn.AdapterName()}}
+	for _, sc := range m.cfg.Connections {
+		targets = append(targets, datadiff.Target{Name: sc.Name, Adapter: sc.Adapter})
+	}
+	m.dataDiffFor = msg
+	m.dataDiff.Show(msg.Table, targets)
+	return nil
+}
+
+// startDataDiff compares the rows in the background, connecting to the
+// saved connection picked first, until they are compared or the
+// comparison is closed. It only reads, so safe mode lets it run.
+func (m *Model) startDataDiff(msg datadiff.StartMsg) tea.Cmd {
+	if m.conn == nil {
+		m.dataDiff.Fail("Not connected")
+		return nil
+	}
+	var sc *config.SavedConnection
+	if !msg.Target.Current {
+		for i := range m.cfg.Connections {
+			if m.cfg.Connections[i].Name == msg.Target.Name {
+				sc = &m.cfg.Connections[i]
+			}
+		}
+		if sc == nil {
+			m.dataDiff.Fail("Connection " + msg.Target.Name + " is gone")
+			return nil
+		}
+	}
+
+	m.stopDataDiff()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.dataDiffCancel = cancel
+	m.dataDiffGen++
+	progress := &rowProgress{verb: "compared"}
+	progress.total.Store(-1)
+	m.dataDiffProgress = progress
+	from := diffTable{conn: m.conn, database: m.dataDiffFor.Database, schema: m.dataDiffFor.Schema, table: m.dataDiffFor.Table}
+	gen := m.dataDiffGen
+	return tea.Batch(dataDiffTick(gen), func() tea.Msg {
+		defer cancel()
+		done := dataDiffDoneMsg{other: msg.Table, gen: gen}
+		to := diffTable{conn: from.conn, database: from.database, schema: from.schema, table: msg.Table}
+		if sc != nil {
+			conn, closeConn, err := connectSaved(ctx, *sc)
+			if err != nil {
+				done.err = err
+				return done
+			}
+			defer closeConn()
+			to = diffTable{conn: conn, database: conn.DatabaseName(), schema: defaultSchemas[conn.AdapterName()], table: msg.Table}
+			done.other += " on " + sc.Name
+		}
+		if schemaName, table, ok := strings.Cut(msg.Table, "."); ok {
+			to.schema, to.table = schemaName, table
+		}
+		done.diff, done.err = compareTables(ctx, from, to, progress)
+		if done.err != nil && ctx.Err() != nil {
+			done.err = ctx.Err() // adapters report a cancel their own way
+		}
+		return done
+	})
+}
+
+// compareTables compares the rows of from and to, matched by the primary
+// key of from, on the columns both have. Each side is read a batch at a
+// time sorted by the key, and the two are walked side by side, so neither
+// is held in memory.
+func compareTables(ctx context.Context, from, to diffTable, progress *rowProgress) (*tableDiff, error) {
+	dialect := from.conn.AdapterName()
+	fromCols, err := from.conn.Columns(ctx, from.database, from.schema, from.table)
+	if err != nil {
+		return nil, err
+	}
+	if len(fromCols) == 0 {
+		return nil, fmt.Errorf("%s has no columns", from.table)
+	}
+	toCols, err := to.conn.Columns(ctx, to.database, to.schema, to.table)
+	if err != nil {
+		return nil, err
+	}
+	if len(toCols) == 0 {
+		return nil, fmt.Errorf("table %s not found", to.table)
+	}
+
+	// Columns are matched by name, ignoring case.
+	var keys, rest, toKeys, toRest []schema.Column
+	for _, c := range fromCols {
+		i := len(toCols) - 1
+		for i >= 0 && !strings.EqualFold(toCols[i].Name, c.Name) {
+			i--
+		}
+		switch {
+		case i < 0 && c.IsPK:
+			return nil, fmt.Errorf("%s has no column %s, of the primary key of %s", to.table, c.Name, from.table)
+		case i < 0:
+		case c.IsPK:
+			keys, toKeys = append(keys, c), append(toKeys, toCols[i])
+		default:
+			rest, toRest = append(rest, c), append(toRest, toCols[i])
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s has no primary key to match the rows by", from.table)
+	}
+	d := &tableDiff{dialect: dialect, columns: append(keys, rest...), keys: len(keys)}
+	compare := func(a, b []string) int {
+		for i, k := range keys {
+			if c := ddl.CompareKey(dialect, k.Type, a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+
+	a, err := readSorted(ctx, from, d.columns, len(keys), compare)
+	if err != nil {
+		return nil, err
+	}
+	defer a.iter.Close()
+	b, err := readSorted(ctx, to, append(toKeys, toRest...), len(keys), compare)
+	if err != nil {
+		return nil, err
+	}
+	defer b.iter.Close()
+
+	for a.row != nil || b.row != nil {
+		c := 0
+		switch {
+		case a.row == nil:
+			c = 1
+		case b.row == nil:
+			c = -1
+		default:
+			c = compare(a.row, b.row)
+		}
+		switch {
+		case c < 0:
+			d.deleted++
+			d.add(rowChange{from: a.row})
+			err = a.next(ctx)
+		case c > 0:
+			d.added++
+			d.add(rowChange{to: b.row})
+			err = b.next(ctx)
+		default:
+			if d.sameRow(a.row, b.row) {
+				d.same++
+			} else {
+				d.changed++
+				d.add(rowChange{from: a.row, to: b.row})
+			}
+			if err = a.next(ctx); err == nil {
+				err = b.next(ctx)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		progress.done.Add(1)
+	}
+	return d, nil
+}
+
+// add keeps a row that differs, up to dataDiffLimit.
+func (d *tableDiff) add(c rowChange) {
+	if len(d.changes) < dataDiffLimit {
+		d.changes = append(d.changes, c)
+	}
+}
+
+// sameRow reports whether the columns past the keys hold the same values.
+func (d *tableDiff) sameRow(a, b []string) bool {
+	for i := d.keys; i < len(d.columns); i++ {
+		if !ddl.SameValue(d.dialect, d.columns[i].Type, a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedRows walks the rows of a table in the order of its key.
+type sortedRows struct {
+	iter    adapter.RowIterator
+	table   string
+	compare func(a, b []string) int
+	batch   [][]string
+	row     []string // the current row, nil past the last
+}
+
+// readSorted reads columns of t sorted by the first keys of them, and
+// moves to the first row.
+func readSorted(ctx context.Context, t diffTable, columns []schema.Column, keys int, compare func(a, b []string) int) (*sortedRows, error) {
+	dialect := t.conn.AdapterName()
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = ddl.SelectTerm(dialect, c.Type, c.Name)
+	}
+	order := make([]string, keys)
+	for i, c := range columns[:keys] {
+		order[i] = ddl.OrderKey(dialect, c.Type, c.Name)
+	}
+	source := adapter.QuoteIdentifier(dialect, t.table)
+	if t.schema != "" {
+		source = adapter.QuoteIdentifier(dialect, t.schema) + "." + source
+	}
+	query := "SELECT " + strings.Join(names, ", ") + " FROM " + source + " ORDER BY " + strings.Join(order, ", ")
+	iter, err := t.conn.ExecuteStreaming(ctx, query, transferBatch)
+	if err != nil {
+		return nil, err
+	}
+	r := &sortedRows{iter: iter, table: t.table, compare: compare}
+	if err := r.next(ctx); err != nil {
+		iter.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// next moves to the next row, fetching the next batch when the one read
+// is used up. Keys out of order or repeated are an error, as the walk
+// would pair the wrong rows.
+func (r *sortedRows) next(ctx context.Context) error {
+	prev := r.row
+	for len(r.batch) == 0 {
+		rows, err := r.iter.FetchNext(ctx)
+		if adapter.SentinelEOF(err) || (err == nil && len(rows) == 0) {
+			r.row = nil
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		r.batch = rows
+	}
+	r.row, r.batch = r.batch[0], r.batch[1:]
+	if prev != nil && r.compare(prev, r.row) >= 0 {
+		return fmt.Errorf("the keys of %s repeat or do not sort alike on both sides", r.table)
+	}
+	return nil
+}
+
+// handleDataDiffTick shows the progress of the comparison while it runs.
+func (m *Model) handleDataDiffTick(msg dataDiffTickMsg) tea.Cmd {
+	if msg.gen != m.dataDiffGen || !m.dataDiff.Running() || m.dataDiffProgress == nil {
+		return nil
+	}
+	m.dataDiff.SetProgress(m.dataDiffProgress.String())
+	return dataDiffTick(msg.gen)
+}
+
+// stopDataDiff cancels the comparison running, if any.
+func (m *Model) stopDataDiff() {
+	if m.dataDiffCancel != nil {
+		m.dataDiffCancel()
+		m.dataDiffCancel = nil
+	}
+}
+
+// handleDataDiffDone shows the rows that differ, or why the comparison
+// stopped.
+func (m *Model) handleDataDiffDone(msg dataDiffDoneMsg) {
+	if msg.gen != m.dataDiffGen {
+		return
+	}
+	m.stopDataDiff()
+	progress := m.dataDiffProgress
+	m.dataDiffProgress = nil
+	switch {
+	case errors.Is(msg.err, context.Canceled):
+		m.dataDiff.Fail(fmt.Sprintf("Stopped after %d rows", progress.done.Load()))
+		return
+	case msg.err != nil:
+		m.dataDiff.Fail(sanitizeError(msg.err.Error()))
+		return
+	}
+	m.dataDiff.SetResult(diffResult(m.dataDiffFor, msg.other, msg.diff))
+}
+
+// diffResult lists the rows that differ and writes the DML turning the
+// table compared into the other.
+func diffResult(table CompareDataMsg, other string, d *tableDiff) datadiff.Result {
+	r := datadiff.Result{Added: d.added, Deleted: d.deleted, Changed: d.changed, Same: d.same}
+	named := func(row []string, from, to int) string {
+		parts := make([]string, 0, to-from)
+		for i := from; i < to; i++ {
+			parts = append(parts, d.columns[i].Name+"="+row[i])
+		}
+		return strings.Join(parts, ", ")
+	}
+	for _, c := range d.changes {
+		switch {
+		case c.from == nil:
+			r.Rows = append(r.Rows, datadiff.Row{Kind: datadiff.Added, Key: named(c.to, 0, d.keys), Detail: named(c.to, d.keys, len(d.columns))})
+		case c.to == nil:
+			r.Rows = append(r.Rows, datadiff.Row{Kind: datadiff.Deleted, Key: named(c.from, 0, d.keys), Detail: named(c.from, d.keys, len(d.columns))})
+		default:
+			var changed []string
+			for i := d.keys; i < len(d.columns); i++ {
+				if !ddl.SameValue(d.dialect, d.columns[i].Type, c.from[i], c.to[i]) {
+					changed = append(changed, d.columns[i].Name+": "+c.from[i]+" → "+c.to[i])
+				}
+			}
+			r.Rows = append(r.Rows, datadiff.Row{Kind: datadiff.Changed, Key: named(c.from, 0, d.keys), Detail: strings.Join(changed, ", ")})
+		}
+	}
+	if len(d.changes) > 0 {
+		r.Script = reconcileScript(table, other, d)
+	}
+	return r
+}
+
+// reconcileScript writes the DELETE, UPDATE and INSERT statements that
+// turn the rows of table into those of other, for the rows listed.
+func reconcileScript(table CompareDataMsg, other string, d *tableDiff) string {
+	dialect := d.dialect
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "-- Turns the rows of %s into those of %s: %d deleted, %d changed, %d added\n",
+		table.Table, other, d.deleted, d.changed, d.added)
+	if differ := d.added + d.deleted + d.changed; int64(len(d.changes)) < differ {
+		fmt.Fprintf(&sb, "-- Only the first %d of the %d rows that differ\n", len(d.changes), differ)
+	}
+	keys, rest := d.columns[:d.keys], d.columns[d.keys:]
+	var deleted, added [][]string
+	for _, c := range d.changes {
+		switch {
+		case c.from == nil:
+			added = append(added, c.to)
+		case c.to == nil:
+			deleted = append(deleted, c.from)
+		}
+	}
+	for start := 0; start < len(deleted); start += transferBatch {
+		batch := deleted[start:min(start+transferBatch, len(deleted))]
+		sb.WriteString(ddl.DeleteRows(dialect, table.Schema, table.Table, keys, batch) + ";\n")
+	}
+	for _, c := range d.changes {
+		if c.from == nil || c.to == nil {
+			continue
+		}
+		var set []schema.Column
+		var values []string
+		for i, col := range rest {
+			if !ddl.SameValue(dialect, col.Type, c.from[d.keys+i], c.to[d.keys+i]) {
+				set = append(set, col)
+				values = append(values, c.to[d.keys+i])
+			}
+		}
+		sb.WriteString(ddl.UpdateRow(dialect, table.Schema, table.Table, keys, c.from[:d.keys], set, values) + ";\n")
+	}
+	for start := 0; start < len(added); start += transferBatch {
+		batch := added[start:min(start+transferBatch, len(added))]
+		sb.WriteString(ddl.InsertRows(dialect, table.Schema, table.Table, d.columns, batch) + ";\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/ui/datadiff"
+)
+
+func TestDataDiff(t *testing.T) {
+	dir := t.TempDir()
+	local := openSQLite(t, filepath.Join(dir, "local.db"),
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, status TEXT, total NUMERIC, note TEXT)",
+		"INSERT INTO orders VALUES (1, 'new', 1.50, NULL), (2, 'new', 3, 'x'), (3, 'paid', 4, NULL), (5, 'gone', 1, NULL)")
+	prod := openSQLite(t, filepath.Join(dir, "prod.db"),
+		"CREATE TABLE orders (ID INTEGER PRIMARY KEY, status TEXT, total NUMERIC, placed TEXT)",
+		"INSERT INTO orders VALUES (1, 'new', 1.5, 'today'), (2, 'paid', 3, NULL), (3, 'paid', 4, NULL), (4, 'it''s', 9, NULL), (10, 'new', 2, NULL)")
+
+	cfg := config.DefaultConfig()
+	cfg.Connections = []config.SavedConnection{prod}
+	m := step(New(cfg, nil, nil), tea.WindowSizeMsg{Width: 140, Height: 40})
+	conn, closeConn, err := connectSaved(context.Background(), local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeConn()
+	m.conn = conn
+
+	compare := func(m Model) Model {
+		m = step(m, CompareDataMsg{Schema: "main", Table: "orders"})
+		if !m.dataDiff.Visible() {
+			t.Fatal("the data comparison should open")
+		}
+		model, cmd := m.Update(datadiff.StartMsg{Target: datadiff.Target{Name: prod.Name, Adapter: "sqlite"}, Table: "orders"})
+		m = model.(Model)
+		for _, msg := range drainBatch(cmd) {
+			if done, ok := msg.(dataDiffDoneMsg); ok {
+				if done.err != nil {
+					t.Fatal(done.err)
+				}
+				m = step(m, done)
+			}
+		}
+		return m
+	}
+
+	m = compare(m)
+	view := m.dataDiff.View()
+	for _, want := range []string{"+ 2 added", "- 1 deleted", "~ 1 changed", "2 the same", "status: new → paid", "id=4", "id=5"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+
+	// The DML turns the local rows into those of prod.
+	model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	m = model.(Model)
+	tab, ok := cmd().(NewTabMsg)
+	if !ok {
+		t.Fatalf("e sent %#v, want a new tab", tab)
+	}
+	for _, want := range []string{`DELETE FROM "main"."orders" WHERE "id" IN (5)`, `UPDATE "main"."orders" SET "status" = 'paid' WHERE "id" = 2`, `(4, 'it''s', 9)`} {
+		if !strings.Contains(tab.Query, want) {
+			t.Errorf("DML lacks %s:\n%s", want, tab.Query)
+		}
+	}
+	for _, stmt := range adapter.SplitStatements("sqlite", tab.Query) {
+		if _, err := conn.Execute(context.Background(), stmt.Text); err != nil {
+			t.Fatalf("%s: %v", stmt.Text, err)
+		}
+	}
+	m = compare(m)
+	if view := m.dataDiff.View(); !strings.Contains(view, "The tables hold the same rows") || !strings.Contains(view, "5 the same") {
+		t.Errorf("after the DML the tables should hold the same rows:\n%s", view)
+	}
+}
+
+func TestCompareTables_Errors(t *testing.T) {
+	sc := openSQLite(t, filepath.Join(t.TempDir(), "shop.db"),
+		"CREATE TABLE logs (line TEXT)",
+		"CREATE TABLE codes (code TEXT PRIMARY KEY, label TEXT)",
+		"CREATE TABLE codes_copy (label TEXT)")
+	conn, closeConn, err := connectSaved(context.Background(), sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeConn()
+	table := func(name string) diffTable { return diffTable{conn: conn, schema: "main", table: name} }
+
+	for _, tt := range []struct{ from, to, want string }{
+		{"logs", "logs", "logs has no primary key"},
+		{"codes", "codes_copy", "codes_copy has no column code"},
+		{"codes", "missing", "table missing not found"},
+	} {
+		_, err := compareTables(context.Background(), table(tt.from), table(tt.to), &rowProgress{})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("compare %s with %s = %v, want %q", tt.from, tt.to, err, tt.want)
+		}
+	}
+}
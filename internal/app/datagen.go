@@ -0,0 +1,229 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/ddl"
+	"github.com/sadopc/gotermsql/internal/fakedata"
+	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/ui/datagen"
+)
+
+// dataGenBatch is how many rows of test data one INSERT adds.
+const dataGenBatch = 500
+
+// dataGenSamples caps the rows of a referenced table foreign keys pick
+// their values from.
+const dataGenSamples = 1000
+
+// dataGenTickMsg redraws the progress of test data run gen.
+type dataGenTickMsg struct{ gen int }
+
+func dataGenTick(gen int) tea.Cmd {
+	return tea.Tick(transferTickInterval, func(time.Time) tea.Msg { return dataGenTickMsg{gen: gen} })
+}
+
+// dataGenDoneMsg reports test data run gen finished, with the rows it
+// inserted.
+type dataGenDoneMsg struct {
+	rows int64
+	err  error
+	gen  int
+}
+
+// openDataGen opens the dialog of the test data generator for a table.
+func (m *Model) openDataGen(msg GenerateDataMsg) tea.Cmd {
+	if m.conn == nil {
+		return m.toast(ToastError, "Not connected")
+	}
+	if m.safeMode {
+		return m.toast(ToastError, safeModeBlocked)
+	}
+	m.dataGenFor = msg
+	m.dataGen.Show(msg.Table)
+	return nil
+}
+
+// startDataGen inserts the rows of test data asked for in the background,
+// until they are in or the dialog stops it.
+func (m *Model) startDataGen(msg datagen.StartMsg) tea.Cmd {
+	if m.conn == nil {
+		m.dataGen.Done("Not connected", true)
+		return nil
+	}
+	if m.safeMode {
+		m.dataGen.Done(safeModeBlocked, true)
+		return nil
+	}
+	m.stopDataGen()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.dataGenCancel = cancel
+	m.dataGenGen++
+	progress := &rowProgress{verb: "inserted"}
+	progress.total.Store(int64(msg.Rows))
+	m.dataGenProgress = progress
+	conn, target, gen, writes := m.conn, m.dataGenFor, m.dataGenGen, m.writes()
+	return tea.Batch(dataGenTick(gen), func() tea.Msg {
+		defer cancel()
+		err := fillTable(ctx, conn, writes, target, msg.Rows, progress)
+		if err != nil && ctx.Err() != nil {
+			err = ctx.Err() // adapters report a cancel their own way
+		}
+		return dataGenDoneMsg{rows: progress.done.Load(), err: err, gen: gen}
+	})
+}
+
+// fillTable inserts n rows of test data into the table of target. A
+// primary key the database numbers is left to it, and one of a single
+// integer column with no default counts up from the greatest it has. A
+// foreign key takes the values of rows of the table it references. The
+// INSERTs are recorded with record.
+func fillTable(ctx context.Context, conn adapter.Connection, record writeRecorder, target GenerateDataMsg, n int, progress *rowProgress) error {
+	dialect := conn.AdapterName()
+	cols, err := conn.Columns(ctx, target.Database, target.Schema, target.Table)
+	if err != nil {
+		return err
+	}
+	fks, err := conn.ForeignKeys(ctx, target.Database, target.Schema, target.Table)
+	if err != nil {
+		return err
+	}
+	qualified := func(table string) string {
+		name := adapter.QuoteIdentifier(dialect, table)
+		if target.Schema != "" {
+			name = adapter.QuoteIdentifier(dialect, target.Schema) + "." + name
+		}
+		return name
+	}
+
+	var write []schema.Column
+	pks := 0
+	for _, c := range cols {
+		if c.IsPK {
+			pks++
+		}
+		if c.IsPK && c.Default != "" {
+			continue // serial or identity
+		}
+		write = append(write, c)
+	}
+	if len(write) == 0 {
+		return fmt.Errorf("the database fills every column of %s", target.Table)
+	}
+	position := func(name string) int {
+		for i, c := range write {
+			if strings.EqualFold(c.Name, name) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	var refs []fakedata.Reference
+	for _, fk := range fks {
+		if len(fk.Columns) == 0 || len(fk.RefColumns) != len(fk.Columns) {
+			continue
+		}
+		ref := fakedata.Reference{}
+		required := false
+		var picked, notNull []string
+		for i, name := range fk.Columns {
+			p := position(name)
+			if p < 0 {
+				ref.Columns = nil
+				break
+			}
+			ref.Columns = append(ref.Columns, p)
+			required = required || !write[p].Nullable
+			picked = append(picked, adapter.QuoteIdentifier(dialect, fk.RefColumns[i]))
+			notNull = append(notNull, picked[i]+" IS NOT NULL")
+		}
+		if ref.Columns == nil {
+			continue
+		}
+		res, err := conn.Execute(ctx, fmt.Sprintf("SELECT DISTINCT %s FROM %s WHERE %s LIMIT %d",
+			strings.Join(picked, ", "), qualified(fk.RefTable), strings.Join(notNull, " AND "), dataGenSamples))
+		if err != nil {
+			return fmt.Errorf("sample %s: %w", fk.RefTable, err)
+		}
+		if len(res.Rows) == 0 && required {
+			return fmt.Errorf("%s has no rows for %s to reference", fk.RefTable, strings.Join(fk.Columns, ", "))
+		}
+		ref.Samples = res.Rows
+		refs = append(refs, ref)
+	}
+
+	g := fakedata.New(write, refs, uint64(time.Now().UnixNano()))
+	if pks == 1 {
+		for i, c := range write {
+			if !c.IsPK || !strings.Contains(strings.ToLower(c.Type), "int") {
+				continue
+			}
+			res, err := conn.Execute(ctx, "SELECT MAX("+adapter.QuoteIdentifier(dialect, c.Name)+") FROM "+qualified(target.Table))
+			if err != nil {
+				return err
+			}
+			start := int64(1)
+			if len(res.Rows) == 1 && len(res.Rows[0]) == 1 {
+				if last, err := strconv.ParseInt(res.Rows[0][0], 10, 64); err == nil {
+					start = last + 1
+				}
+			}
+			g.Count(i, start)
+		}
+	}
+
+	for done := 0; done < n; {
+		rows := make([][]string, min(dataGenBatch, n-done))
+		for i := range rows {
+			rows[i] = g.Row()
+		}
+		if _, err := record.execute(ctx, conn, ddl.InsertRows(dialect, target.Schema, target.Table, write, rows)); err != nil {
+			return fmt.Errorf("insert after %d rows: %w", done, err)
+		}
+		done += len(rows)
+		progress.done.Store(int64(done))
+	}
+	return nil
+}
+
+// handleDataGenTick shows the progress of the run while it inserts.
+func (m *Model) handleDataGenTick(msg dataGenTickMsg) tea.Cmd {
+	if msg.gen != m.dataGenGen || !m.dataGen.Running() || m.dataGenProgress == nil {
+		return nil
+	}
+	m.dataGen.SetProgress(m.dataGenProgress.String())
+	return dataGenTick(msg.gen)
+}
+
+// stopDataGen cancels the run inserting, if any.
+func (m *Model) stopDataGen() {
+	if m.dataGenCancel != nil {
+		m.dataGenCancel()
+		m.dataGenCancel = nil
+	}
+}
+
+// handleDataGenDone reports how the run ended.
+func (m *Model) handleDataGenDone(msg dataGenDoneMsg) {
+	if msg.gen != m.dataGenGen {
+		return
+	}
+	m.stopDataGen()
+	m.dataGenProgress = nil
+	switch {
+	case errors.Is(msg.err, context.Canceled):
+		m.dataGen.Done(fmt.Sprintf("Stopped after %d rows", msg.rows), true)
+	case msg.err != nil:
+		m.dataGen.Done(sanitizeError(msg.err.Error()), true)
+	default:
+		m.dataGen.Done(fmt.Sprintf("%d rows inserted into %s", msg.rows, m.dataGenFor.Table), false)
+	}
+}
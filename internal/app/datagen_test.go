@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/ui/datagen"
+)
+
+func TestDataGen(t *testing.T) {
+	sc := openSQLite(t, filepath.Join(t.TempDir(), "shop.db"),
+		"CREATE TABLE customers (id INTEGER PRIMARY KEY, email TEXT NOT NULL UNIQUE)",
+		"INSERT INTO customers VALUES (7, 'a@example.com'), (9, 'b@example.com')",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, customer_id INTEGER NOT NULL REFERENCES customers(id), placed DATE, total NUMERIC(10,2), paid BOOLEAN)",
+		"INSERT INTO orders (id, customer_id) VALUES (41, 7)")
+	conn, closeConn, err := connectSaved(context.Background(), sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeConn()
+	m := step(New(config.DefaultConfig(), nil, nil), tea.WindowSizeMsg{Width: 140, Height: 40})
+	m.conn = conn
+
+	m = step(m, GenerateDataMsg{Schema: "main", Table: "orders"})
+	if !m.dataGen.Visible() {
+		t.Fatal("the test data dialog should open")
+	}
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlU})
+	model, _ = model.(Model).Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("1200")})
+	model, cmd := model.(Model).Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(Model)
+	start, ok := cmd().(datagen.StartMsg)
+	if !ok || start.Rows != 1200 {
+		t.Fatalf("enter sent %#v, want 1200 rows", start)
+	}
+	model, cmd = m.Update(start)
+	m = model.(Model)
+	for _, msg := range drainBatch(cmd) {
+		if done, ok := msg.(dataGenDoneMsg); ok {
+			if done.err != nil || done.rows != 1200 {
+				t.Fatalf("run = %+v, want 1200 rows", done)
+			}
+			m = step(m, done)
+		}
+	}
+	if view := m.dataGen.View(); !strings.Contains(view, "1200 rows inserted into orders") {
+		t.Errorf("dialog should report the rows:\n%s", view)
+	}
+
+	for q, want := range map[string]string{
+		"SELECT COUNT(*) FROM orders":                                                           "1201",
+		"SELECT MIN(id) FROM orders WHERE id > 41":                                              "42",
+		"SELECT COUNT(*) FROM orders WHERE customer_id NOT IN (7, 9)":                           "0",
+		"SELECT COUNT(*) FROM orders WHERE placed IS NOT NULL AND placed NOT LIKE '____-__-__'": "0",
+		"SELECT COUNT(*) FROM orders WHERE typeof(paid) NOT IN ('integer', 'null')":             "0",
+	} {
+		res, err := conn.Execute(context.Background(), q)
+		if err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+		if got := res.Rows[0][0]; got != want {
+			t.Errorf("%s = %s, want %s", q, got, want)
+		}
+	}
+}
+
+func TestDataGen_NoParentRows(t *testing.T) {
+	sc := openSQLite(t, filepath.Join(t.TempDir(), "shop.db"),
+		"CREATE TABLE customers (id INTEGER PRIMARY KEY)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, customer_id INTEGER NOT NULL REFERENCES customers(id))")
+	conn, closeConn, err := connectSaved(context.Background(), sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeConn()
+	err = fillTable(context.Background(), conn, writeRecorder{}, GenerateDataMsg{Schema: "main", Table: "orders"}, 10, &rowProgress{})
+	if err == nil || !strings.Contains(err.Error(), "customers has no rows for customer_id") {
+		t.Errorf("fillTable = %v, want it to need customers", err)
+	}
+}
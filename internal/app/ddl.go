@@ -0,0 +1,108 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/ddl"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+// ddlTimeout bounds fetching the CREATE statement of a table.
+const ddlTimeout = 30 * time.Second
+
+// ddlLoadedMsg carries a fetched CREATE statement, tagged with the
+// connection generation so a reply from a closed connection is dropped.
+type ddlLoadedMsg struct {
+	Title   string
+	DDL     string
+	Err     error
+	ConnGen uint64
+}
+
+// loadDDL fetches the CREATE statement of a table or view in the background.
+func (m *Model) loadDDL(msg ShowDDLMsg) tea.Cmd {
+	if m.conn == nil {
+		return nil
+	}
+	provider, ok := m.conn.(adapter.DDLProvider)
+	if !ok {
+		text := "DDL is not available for " + m.conn.AdapterName()
+		return func() tea.Msg { return StatusMsg{Text: text, IsError: true} }
+	}
+
+	title := msg.Table
+	if msg.Schema != "" && msg.Schema != "main" {
+		title = msg.Schema + "." + title
+	}
+	gen := m.connGen
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), ddlTimeout)
+		defer cancel()
+		ddl, err := provider.TableDDL(ctx, msg.Database, msg.Schema, msg.Table)
+		return ddlLoadedMsg{Title: title, DDL: ddl, Err: err, ConnGen: gen}
+	}
+}
+
+// handleDDLLoaded opens the viewer on a fetched CREATE statement.
+func (m *Model) handleDDLLoaded(msg ddlLoadedMsg) tea.Cmd {
+	if msg.ConnGen != m.connGen {
+		return nil
+	}
+	if msg.Err != nil {
+		return func() tea.Msg {
+			return StatusMsg{Text: "Could not load DDL: " + msg.Err.Error(), IsError: true}
+		}
+	}
+	m.viewer.Show(msg.Title, msg.DDL)
+	return nil
+}
+
+// portedTableMsg carries the CREATE TABLE of a table written for another
+// dialect, tagged with the connection generation.
+type portedTableMsg struct {
+	DDL     string
+	Err     error
+	ConnGen uint64
+}
+
+// portTable reads the columns, indexes and foreign keys of a table in the
+// background and writes its CREATE TABLE for the dialect asked for.
+func (m *Model) portTable(msg PortTableMsg) tea.Cmd {
+	if m.conn == nil {
+		return nil
+	}
+	conn := m.conn
+	gen := m.connGen
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), ddlTimeout)
+		defer cancel()
+		t := schema.Table{Name: msg.Table}
+		cols, err := conn.Columns(ctx, msg.Database, msg.Schema, msg.Table)
+		if err != nil {
+			return portedTableMsg{Err: err, ConnGen: gen}
+		}
+		t.Columns = cols
+		t.Indexes, _ = conn.Indexes(ctx, msg.Database, msg.Schema, msg.Table)
+		t.FKs, _ = conn.ForeignKeys(ctx, msg.Database, msg.Schema, msg.Table)
+		header := fmt.Sprintf("-- %s from %s, for %s\n\n", msg.Table, conn.AdapterName(), msg.Dialect)
+		return portedTableMsg{DDL: header + ddl.CreateTable(conn.AdapterName(), msg.Dialect, t), ConnGen: gen}
+	}
+}
+
+// handlePortedTable opens the CREATE TABLE written for another dialect in
+// a new tab.
+func (m *Model) handlePortedTable(msg portedTableMsg) tea.Cmd {
+	if msg.ConnGen != m.connGen {
+		return nil
+	}
+	if msg.Err != nil {
+		text := "Could not read the table: " + sanitizeError(msg.Err.Error())
+		return func() tea.Msg { return StatusMsg{Text: text, IsError: true} }
+	}
+	query := msg.DDL
+	return func() tea.Msg { return NewTabMsg{Query: query} }
+}
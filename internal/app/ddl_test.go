@@ -0,0 +1,27 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+func TestPortTable(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	m.conn = diffConn{sqliteConn: sqliteConn{&testConn{dbName: "app"}}, tables: map[string][]schema.Column{
+		"users": {{Name: "id", Type: "INTEGER", IsPK: true}, {Name: "name", Type: "TEXT", Nullable: true}},
+	}}
+
+	msg := m.portTable(PortTableMsg{Database: "app", Schema: "main", Table: "users", Dialect: "postgres"})()
+	tab, ok := m.handlePortedTable(msg.(portedTableMsg))().(NewTabMsg)
+	want := "-- users from sqlite, for postgres\n\nCREATE TABLE \"users\" (\n    \"id\" bigint NOT NULL,\n    \"name\" text,\n    PRIMARY KEY (\"id\")\n);\n"
+	if !ok || tab.Query != want {
+		t.Errorf("opened %#v, want the table written for postgres", tab)
+	}
+
+	m.connGen++ // reconnected
+	if cmd := m.handlePortedTable(msg.(portedTableMsg)); cmd != nil {
+		t.Error("a table read from a closed connection was opened")
+	}
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+)
+
+// depsTimeout bounds reading the dependencies of a table or view.
+const depsTimeout = 30 * time.Second
+
+// depsLoadedMsg carries the dependencies of the table or view of request,
+// read on connection generation connGen.
+type depsLoadedMsg struct {
+	request DependenciesMsg
+	deps    adapter.Dependencies
+	err     error
+	connGen uint64
+}
+
+// openDependencies opens the dependency browser of a table or view and
+// reads its dependencies from the catalog in the background.
+func (m *Model) openDependencies(msg DependenciesMsg) tea.Cmd {
+	if m.conn == nil {
+		return m.toast(ToastError, "Not connected")
+	}
+	provider, ok := m.conn.(adapter.DependencyProvider)
+	if !ok {
+		return m.toast(ToastError, "Dependencies are not available for "+m.conn.AdapterName())
+	}
+	m.depBrowser.Show(msg.Table)
+	m.depsFor = msg
+	connGen := m.connGen
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), depsTimeout)
+		defer cancel()
+		deps, err := provider.Dependencies(ctx, msg.Database, msg.Schema, msg.Table)
+		return depsLoadedMsg{request: msg, deps: deps, err: err, connGen: connGen}
+	}
+}
+
+// handleDependencies shows the dependencies read, unless the browser was
+// closed or opened on another table since.
+func (m *Model) handleDependencies(msg depsLoadedMsg) {
+	if msg.connGen != m.connGen || msg.request != m.depsFor || !m.depBrowser.Visible() {
+		return
+	}
+	if msg.err != nil {
+		m.depBrowser.SetError("Could not read dependencies: " + sanitizeError(msg.err.Error()))
+		return
+	}
+	m.depBrowser.SetDependencies(msg.deps)
+}
+
+// revealDependency selects a dependency picked in the browser in the
+// sidebar, showing and focusing the sidebar. One the catalog names by
+// another schema than the tree does, as MySQL's databases, is looked for
+// in the schema browsed.
+func (m *Model) revealDependency(d adapter.Dependency) tea.Cmd {
+	db := m.depsFor.Database
+	if !m.sidebar.RevealObject(db, d.Schema, "", d.Name) && !m.sidebar.RevealObject(db, m.depsFor.Schema, "", d.Name) {
+		return m.toast(ToastError, d.Name+" is not in the schema tree")
+	}
+	m.focusSidebar()
+	return nil
+}
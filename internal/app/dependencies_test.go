@@ -0,0 +1,69 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/ui/sidebar"
+)
+
+// depsConn is a connection that reports fixed dependencies.
+type depsConn struct {
+	*testConn
+	deps adapter.Dependencies
+}
+
+func (c depsConn) Dependencies(ctx context.Context, db, schemaName, name string) (adapter.Dependencies, error) {
+	return c.deps, nil
+}
+
+func TestDependencies(t *testing.T) {
+	m := step(New(config.DefaultConfig(), nil, nil), tea.WindowSizeMsg{Width: 120, Height: 40})
+	m.conn = &testConn{dbName: "shop"}
+	model, _ := m.Update(SchemaLoadedMsg{ConnGen: m.connGen, Databases: []schema.Database{{Name: "shop", Schemas: []schema.Schema{{Name: "public",
+		Tables: []schema.Table{{Name: "customers"}, {Name: "orders"}},
+	}}}}})
+	m = model.(Model)
+
+	request := DependenciesMsg{Database: "shop", Schema: "public", Table: "customers"}
+	if model, _ = m.Update(request); model.(Model).depBrowser.Visible() {
+		t.Fatal("the browser should not open for a connection without dependencies")
+	}
+
+	orders := adapter.Dependency{Kind: adapter.DependencyTable, Schema: "public", Name: "orders", Detail: "FOREIGN KEY (customer_id) REFERENCES customers(id)"}
+	m.conn = depsConn{testConn: &testConn{dbName: "shop"}, deps: adapter.Dependencies{Dependents: []adapter.Dependency{orders}}}
+	model, cmd := m.Update(request)
+	m = model.(Model)
+	if !m.depBrowser.Visible() || !m.depBrowser.Loading() {
+		t.Fatal("the browser should open, loading")
+	}
+	reply := cmd()
+
+	// A reply for a connection closed since is dropped.
+	stale := reply.(depsLoadedMsg)
+	stale.connGen++
+	if model, _ = m.Update(stale); !model.(Model).depBrowser.Loading() {
+		t.Error("dependencies read on another connection were shown")
+	}
+	model, _ = m.Update(reply)
+	m = model.(Model)
+	if m.depBrowser.Loading() {
+		t.Fatal("the dependencies read were not shown")
+	}
+
+	// Picking orders selects it in the sidebar.
+	model, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(Model)
+	model, _ = m.Update(cmd())
+	m = model.(Model)
+	if m.depBrowser.Visible() || m.focusedPane != PaneSidebar {
+		t.Fatal("picking a dependency should close the browser and focus the sidebar")
+	}
+	if node := m.sidebar.Selected(); node == nil || node.Kind != sidebar.NodeTable || node.Table != "orders" {
+		t.Errorf("selected %+v, want the table orders", node)
+	}
+}
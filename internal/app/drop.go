@@ -0,0 +1,138 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/ui/dialog"
+)
+
+// dropCheckTimeout bounds counting the rows a DROP would remove.
+const dropCheckTimeout = 10 * time.Second
+
+// dropCheck is a DROP target and the tables whose rows count for it.
+type dropCheck struct {
+	target adapter.DropTarget
+	tables []string // names to count, ready to use in a query
+	known  bool     // false if the target's tables are not known
+}
+
+// dropSizeMsg reports whether a DROP removes more rows than
+// drop_confirm_rows, so that it must be confirmed by typing a name.
+type dropSizeMsg struct {
+	exec    ExecuteQueryMsg
+	large   *adapter.DropTarget // the first target over the limit, or nil
+	unknown bool                // large's size could not be checked
+	connGen uint64
+}
+
+// checkDrop returns a command that counts the rows the DROP TABLE, SCHEMA
+// or DATABASE statements in msg's query would remove, or nil if the query
+// can run straight away. Counting stops as soon as the limit is passed, so
+// a large table is not read whole, and runs on the pool, so a failed count
+// leaves an open transaction alone.
+func (m *Model) checkDrop(msg ExecuteQueryMsg) tea.Cmd {
+	limit := m.cfg.DropConfirmRows
+	if msg.Confirmed || limit < 0 || m.conn == nil {
+		return nil
+	}
+	dialect := m.conn.AdapterName()
+	targets := adapter.DropTargets(dialect, msg.Query)
+	if len(targets) == 0 {
+		return nil
+	}
+	checks := make([]dropCheck, len(targets))
+	for i, t := range targets {
+		checks[i] = m.dropCheck(dialect, t)
+	}
+
+	conn := m.conn
+	gen := m.connGen
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(adapter.OnPool(context.Background()), dropCheckTimeout)
+		defer cancel()
+		reply := dropSizeMsg{exec: msg, connGen: gen}
+		for _, c := range checks {
+			target := c.target
+			if !c.known {
+				reply.large, reply.unknown = &target, true
+				return reply
+			}
+			var total int64
+			for _, table := range c.tables {
+				query := adapter.CountQuery(fmt.Sprintf("SELECT 1 FROM %s LIMIT %d", table, limit-total+1))
+				res, err := conn.Execute(ctx, query)
+				n := int64(-1)
+				if err == nil && res != nil && len(res.Rows) > 0 && len(res.Rows[0]) > 0 {
+					n = parseCount(res.Rows[0][0])
+				}
+				if n < 0 {
+					reply.large, reply.unknown = &target, true
+					return reply
+				}
+				if total += n; total > limit {
+					reply.large = &target
+					return reply
+				}
+			}
+		}
+		return reply
+	}
+}
+
+// dropCheck finds the tables of t in the schema tree. A table is counted
+// as written; a schema or database by the tables loaded for it.
+func (m *Model) dropCheck(dialect string, t adapter.DropTarget) dropCheck {
+	c := dropCheck{target: t}
+	if t.Kind == "TABLE" {
+		c.tables, c.known = []string{t.Ref}, true
+		return c
+	}
+	for _, db := range m.databases {
+		wholeDB := t.Kind == "DATABASE" || dialect == "mysql" // MySQL's schemas are databases
+		for _, s := range db.Schemas {
+			if !strings.EqualFold(s.Name, t.Name) && !(wholeDB && strings.EqualFold(db.Name, t.Name)) {
+				continue
+			}
+			qualifier := s.Name
+			if qualifier == "" {
+				qualifier = db.Name
+			}
+			for _, table := range s.Tables {
+				c.tables = append(c.tables, adapter.QuoteIdentifier(dialect, qualifier)+"."+adapter.QuoteIdentifier(dialect, table.Name))
+			}
+			c.known = true
+		}
+	}
+	return c
+}
+
+// handleDropSize runs the checked DROP, asking for the name of a large
+// target to be typed first.
+func (m *Model) handleDropSize(msg dropSizeMsg) tea.Cmd {
+	if msg.connGen != m.connGen {
+		return nil
+	}
+	exec := msg.exec
+	exec.Confirmed = true
+	if msg.large == nil {
+		return func() tea.Msg { return exec }
+	}
+	t := *msg.large
+	kind := strings.ToLower(t.Kind)
+	warning := fmt.Sprintf("The %s %s holds more than %d rows.", kind, t, m.cfg.DropConfirmRows)
+	if msg.unknown {
+		warning = fmt.Sprintf("The size of %s %s could not be checked.", kind, t)
+	}
+	m.showDialog("Drop "+kind,
+		adapter.TrimStatement(exec.Query)+"\n\n"+warning+" This cannot be undone.",
+		dialog.Button{Label: "Drop", Guarded: true, Action: func() tea.Msg { return exec }},
+		dialog.Button{Label: "Cancel", Action: func() tea.Msg { return nil }},
+	)
+	m.dialog.RequireText(t.Name)
+	return nil
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+// dropModel returns a model whose count queries all return rows.
+func dropModel(rows string) (Model, *testConn) {
+	m := New(config.DefaultConfig(), nil, nil)
+	conn := &testConn{dbName: "app", result: &adapter.QueryResult{Rows: [][]string{{rows}}}}
+	m.conn = conn
+	m.databases = []schema.Database{{Name: "app", Schemas: []schema.Schema{
+		{Name: "sales", Tables: []schema.Table{{Name: "orders"}, {Name: "items"}}},
+	}}}
+	return m, conn
+}
+
+// checkedDrop runs query through the DROP size check and returns the model
+// and the command it leaves: the query to run, or nil if it asks first.
+func checkedDrop(t *testing.T, m Model, query string) (Model, tea.Cmd) {
+	t.Helper()
+	tabID := m.tabs.ActiveID()
+	model, cmd := m.Update(ExecuteQueryMsg{Query: query, TabID: tabID})
+	m = model.(Model)
+	if m.tabStates[tabID].RunID != 0 {
+		t.Fatalf("%q ran before its size was checked", query)
+	}
+	var size dropSizeMsg
+	for _, msg := range drainBatch(cmd) {
+		if s, ok := msg.(dropSizeMsg); ok {
+			size = s
+		}
+	}
+	model, cmd = m.Update(size)
+	return model.(Model), cmd
+}
+
+func TestDrop_LargeTableNeedsTypedName(t *testing.T) {
+	m, conn := dropModel("1001")
+	m, cmd := checkedDrop(t, m, "DROP TABLE IF EXISTS public.users CASCADE")
+	if cmd != nil || !m.dialog.Visible() {
+		t.Fatal("dropping a large table should ask first")
+	}
+	if len(conn.executed) != 1 || conn.executed[0] != "SELECT COUNT(*) FROM (SELECT 1 FROM public.users LIMIT 1001) AS gotermsql_count" {
+		t.Errorf("executed %q, want a bounded count", conn.executed)
+	}
+	if view := m.dialog.View(); !strings.Contains(view, "public.users holds more than 1000 rows") || !strings.Contains(view, "Type users to confirm") {
+		t.Errorf("dialog view = %q", view)
+	}
+
+	for _, r := range "users" {
+		model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = model.(Model)
+	}
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("typing the table name should confirm")
+	}
+	if got, ok := cmd().(ExecuteQueryMsg); !ok || !got.Confirmed {
+		t.Errorf("confirm = %#v, want the confirmed DROP", got)
+	}
+}
+
+func TestDrop_SmallTableRuns(t *testing.T) {
+	m, _ := dropModel("3")
+	m, cmd := checkedDrop(t, m, "DROP TABLE users")
+	if m.dialog.Visible() || cmd == nil {
+		t.Fatal("dropping a small table should not ask")
+	}
+	got, ok := cmd().(ExecuteQueryMsg)
+	if !ok || got.Query != "DROP TABLE users" || !got.Confirmed {
+		t.Fatalf("got %#v, want the DROP to run", got)
+	}
+	tabID := m.tabs.ActiveID()
+	m.Update(got)
+	if m.tabStates[tabID].RunID == 0 {
+		t.Error("the confirmed DROP should run")
+	}
+}
+
+func TestDrop_Schema(t *testing.T) {
+	// Two tables of 600 rows: the schema is over the limit.
+	m, conn := dropModel("600")
+	m, _ = checkedDrop(t, m, "DROP SCHEMA sales CASCADE")
+	if !m.dialog.Visible() || len(conn.executed) != 2 {
+		t.Fatalf("dialog = %v after %q, want both tables counted and a dialog", m.dialog.Visible(), conn.executed)
+	}
+	if !strings.Contains(conn.executed[1], `FROM "sales"."items" LIMIT 401`) {
+		t.Errorf("second count = %q, want it bounded by what is left", conn.executed[1])
+	}
+
+	// A schema that is not in the tree cannot be checked.
+	m, conn = dropModel("0")
+	m, _ = checkedDrop(t, m, "DROP SCHEMA archive")
+	if !m.dialog.Visible() || len(conn.executed) != 0 {
+		t.Error("an unknown schema should ask without counting")
+	}
+}
+
+func TestDrop_Off(t *testing.T) {
+	m, _ := dropModel("5000")
+	m.cfg.DropConfirmRows = -1
+	tabID := m.tabs.ActiveID()
+	m.Update(ExecuteQueryMsg{Query: "DROP TABLE users", TabID: tabID})
+	if m.tabStates[tabID].RunID == 0 {
+		t.Error("with drop_confirm_rows -1 the DROP should run straight away")
+	}
+}
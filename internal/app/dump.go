@@ -0,0 +1,251 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/ddl"
+	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/ui/dump"
+	"github.com/sadopc/gotermsql/internal/ui/format"
+)
+
+// dumpTickMsg redraws the progress of dump gen.
+type dumpTickMsg struct{ gen int }
+
+func dumpTick(gen int) tea.Cmd {
+	return tea.Tick(transferTickInterval, func(time.Time) tea.Msg { return dumpTickMsg{gen: gen} })
+}
+
+// dumpDoneMsg reports dump gen finished, with the rows it wrote and the
+// size of the file.
+type dumpDoneMsg struct {
+	path  string
+	rows  int64
+	bytes int64
+	err   error
+	gen   int
+}
+
+// openDump opens the dialog that dumps a table to a file. Dumping only
+// reads, so safe mode allows it.
+func (m *Model) openDump(msg DumpTableMsg) tea.Cmd {
+	if m.conn == nil {
+		return m.toast(ToastError, "Not connected")
+	}
+	_, canCopy := m.conn.(adapter.Copier)
+	m.dumpFor = msg
+	m.dump.Show(msg.Table, canCopy)
+	return nil
+}
+
+// startDump writes the table to the file asked for in the background,
+// until it is written or the dialog stops it.
+func (m *Model) startDump(msg dump.StartMsg) tea.Cmd {
+	if m.conn == nil {
+		m.dump.Done("Not connected", true)
+		return nil
+	}
+	m.stopDump()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.dumpCancel = cancel
+	m.dumpGen++
+	progress := &rowProgress{verb: "written"}
+	m.dumpProgress = progress
+	conn, target, gen := m.conn, m.dumpFor, m.dumpGen
+	path := config.ExpandHome(msg.Path)
+	return tea.Batch(dumpTick(gen), func() tea.Msg {
+		defer cancel()
+		size, err := dumpTable(ctx, conn, target, path, msg.Copy, progress)
+		if err != nil && ctx.Err() != nil {
+			err = ctx.Err() // adapters report a cancel their own way
+		}
+		return dumpDoneMsg{path: path, rows: progress.done.Load(), bytes: size, err: err, gen: gen}
+	})
+}
+
+// sequenceDefault matches the default of a column numbered by a
+// PostgreSQL sequence, capturing the name of the sequence.
+var sequenceDefault = regexp.MustCompile(`(?i)^nextval\('([^']+)'`)
+
+// dumpTable writes the CREATE TABLE of the table of target to the file at
+// path, then its rows: as INSERT statements of a batch each, or as the
+// data of a COPY FROM stdin for psql when useCopy is set. The names are left
+// unqualified, to restore the table into the schema the file is run in.
+// It returns the size of the file; a failed dump leaves no file behind.
+func dumpTable(ctx context.Context, conn adapter.Connection, target DumpTableMsg, path string, useCopy bool, progress *rowProgress) (int64, error) {
+	dialect := conn.AdapterName()
+	var copier adapter.Copier
+	if useCopy {
+		c, ok := conn.(adapter.Copier)
+		if !ok {
+			return 0, fmt.Errorf("COPY is not available for %s", dialect)
+		}
+		copier = c
+	}
+	cols, err := conn.Columns(ctx, target.Database, target.Schema, target.Table)
+	if err != nil {
+		return 0, err
+	}
+	if len(cols) == 0 {
+		return 0, fmt.Errorf("table %s not found", target.Table)
+	}
+	t := schema.Table{Name: target.Table, Columns: cols}
+	t.Indexes, _ = conn.Indexes(ctx, target.Database, target.Schema, target.Table)
+	t.FKs, _ = conn.ForeignKeys(ctx, target.Database, target.Schema, target.Table)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	var size atomic.Int64
+	w := bufio.NewWriter(&countingWriter{w: f, n: &size})
+	err = writeDump(ctx, conn, copier, target, t, w, progress)
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return 0, err
+	}
+	return size.Load(), nil
+}
+
+// writeDump writes the dump of table t to w, its rows through copier
+// when it is set.
+func writeDump(ctx context.Context, conn adapter.Connection, copier adapter.Copier, target DumpTableMsg, t schema.Table, w io.Writer, progress *rowProgress) error {
+	dialect := conn.AdapterName()
+	source := adapter.QuoteIdentifier(dialect, t.Name)
+	if target.Schema != "" && target.Schema != "main" {
+		source = adapter.QuoteIdentifier(dialect, target.Schema) + "." + source
+	}
+	names := make([]string, len(t.Columns))
+	terms := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		names[i] = adapter.QuoteIdentifier(dialect, c.Name)
+		terms[i] = ddl.SelectTerm(dialect, c.Type, c.Name)
+	}
+	list := strings.Join(names, ", ")
+
+	if _, err := fmt.Fprintf(w, "-- %s dumped from %s on %s\n\n", t.Name, dialect, time.Now().Format("2006-01-02 15:04:05")); err != nil {
+		return err
+	}
+	// A sequence numbering a column goes with the table when it is
+	// dropped, so it is created again and set past the rows restored.
+	var sequences []string
+	for _, c := range t.Columns {
+		if s := sequenceDefault.FindStringSubmatch(c.Default); s != nil {
+			sequences = append(sequences, fmt.Sprintf("SELECT setval('%s', (SELECT max(%s) FROM %s));\n",
+				s[1], adapter.QuoteIdentifier(dialect, c.Name), adapter.QuoteIdentifier(dialect, t.Name)))
+			if _, err := fmt.Fprintf(w, "CREATE SEQUENCE IF NOT EXISTS %s;\n\n", s[1]); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := io.WriteString(w, ddl.CreateTable(dialect, dialect, t)+"\n"); err != nil {
+		return err
+	}
+
+	if copier != nil {
+		if _, err := fmt.Fprintf(w, "COPY %s (%s) FROM stdin;\n", adapter.QuoteIdentifier(dialect, t.Name), list); err != nil {
+			return err
+		}
+		// The text format writes a row a line, escaping the newlines in it.
+		rows, err := copier.CopyTo(ctx, &lineCountingWriter{w: w, n: &progress.done}, "COPY "+source+" ("+list+") TO STDOUT")
+		if err != nil {
+			return err
+		}
+		progress.done.Store(rows)
+		if _, err := io.WriteString(w, "\\.\n"); err != nil {
+			return err
+		}
+	} else {
+		iter, err := conn.ExecuteStreaming(ctx, "SELECT "+strings.Join(terms, ", ")+" FROM "+source, transferBatch)
+		if err != nil {
+			return err
+		}
+		defer iter.Close()
+		progress.total.Store(iter.TotalRows())
+		for {
+			rows, err := iter.FetchNext(ctx)
+			if adapter.SentinelEOF(err) || (err == nil && len(rows) == 0) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if _, err := io.WriteString(w, ddl.InsertRows(dialect, "", t.Name, t.Columns, rows)+";\n\n"); err != nil {
+				return err
+			}
+			progress.done.Add(int64(len(rows)))
+		}
+	}
+
+	if len(sequences) > 0 {
+		if _, err := io.WriteString(w, "\n"+strings.Join(sequences, "")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lineCountingWriter counts the lines written through it.
+type lineCountingWriter struct {
+	w io.Writer
+	n *atomic.Int64
+}
+
+func (c *lineCountingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n.Add(int64(bytes.Count(p[:n], []byte{'\n'})))
+	return n, err
+}
+
+// handleDumpTick shows the progress of the dump while it writes.
+func (m *Model) handleDumpTick(msg dumpTickMsg) tea.Cmd {
+	if msg.gen != m.dumpGen || !m.dump.Running() || m.dumpProgress == nil {
+		return nil
+	}
+	m.dump.SetProgress(m.dumpProgress.String())
+	return dumpTick(msg.gen)
+}
+
+// stopDump cancels the dump writing, if any.
+func (m *Model) stopDump() {
+	if m.dumpCancel != nil {
+		m.dumpCancel()
+		m.dumpCancel = nil
+	}
+}
+
+// handleDumpDone reports how the dump ended.
+func (m *Model) handleDumpDone(msg dumpDoneMsg) {
+	if msg.gen != m.dumpGen {
+		return
+	}
+	m.stopDump()
+	m.dumpProgress = nil
+	switch {
+	case errors.Is(msg.err, context.Canceled):
+		m.dump.Done(fmt.Sprintf("Stopped after %d rows; the file was removed", msg.rows), true)
+	case msg.err != nil:
+		m.dump.Done(sanitizeError(msg.err.Error()), true)
+	default:
+		m.dump.Done(fmt.Sprintf("%d rows of %s written to %s (%s)", msg.rows, m.dumpFor.Table, msg.path, format.Size(msg.bytes)), false)
+	}
+}
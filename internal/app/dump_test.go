@@ -0,0 +1,101 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/ui/dump"
+)
+
+func TestDump(t *testing.T) {
+	dir := t.TempDir()
+	sc := openSQLite(t, filepath.Join(dir, "shop.db"),
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, status TEXT NOT NULL, note TEXT, tag BLOB)",
+		"CREATE INDEX orders_status ON orders (status)",
+		"WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 1200) INSERT INTO orders SELECT i, 'new', NULL, NULL FROM n",
+		"UPDATE orders SET note = 'it''s' || char(10) || 'late' WHERE id = 7",
+		"UPDATE orders SET tag = X'00FF27' WHERE id = 7")
+	conn, closeConn, err := connectSaved(context.Background(), sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeConn()
+	m := step(New(config.DefaultConfig(), nil, nil), tea.WindowSizeMsg{Width: 140, Height: 40})
+	m.conn = conn
+
+	m = step(m, DumpTableMsg{Schema: "main", Table: "orders"})
+	if !m.dump.Visible() || strings.Contains(m.dump.View(), "COPY") {
+		t.Fatalf("the dump dialog should open without COPY:\n%s", m.dump.View())
+	}
+	path := filepath.Join(dir, "orders.sql")
+	model, cmd := m.Update(dump.StartMsg{Path: path})
+	m = model.(Model)
+	for _, msg := range drainBatch(cmd) {
+		if done, ok := msg.(dumpDoneMsg); ok {
+			if done.err != nil || done.rows != 1200 {
+				t.Fatalf("dump = %+v, want 1200 rows", done)
+			}
+			m = step(m, done)
+		}
+	}
+	if view := m.dump.View(); !strings.Contains(view, "1200 rows of orders written to") {
+		t.Errorf("dialog should report the rows:\n%s", view)
+	}
+
+	// The file restores the table into another database.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := openSQLite(t, filepath.Join(dir, "restored.db"))
+	conn2, closeConn2, err := connectSaved(context.Background(), restored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeConn2()
+	for _, stmt := range adapter.SplitStatements("sqlite", string(data)) {
+		if _, err := conn2.Execute(context.Background(), stmt.Text); err != nil {
+			t.Fatalf("%s: %v", stmt.Text, err)
+		}
+	}
+	for q, want := range map[string]string{
+		"SELECT COUNT(*) FROM orders":                                            "1200",
+		"SELECT note FROM orders WHERE id = 7":                                   "it's\nlate",
+		"SELECT COUNT(*) FROM orders WHERE note IS NULL":                         "1199",
+		"SELECT hex(tag) FROM orders WHERE id = 7":                               "00FF27",
+		"SELECT COUNT(*) FROM sqlite_master WHERE name = 'orders_status'":        "1",
+		"SELECT COUNT(*) FROM pragma_table_info('orders') WHERE \"notnull\" = 1": "1",
+	} {
+		res, err := conn2.Execute(context.Background(), q)
+		if err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+		if got := res.Rows[0][0]; got != want {
+			t.Errorf("%s = %q, want %q", q, got, want)
+		}
+	}
+}
+
+func TestDumpTable_Errors(t *testing.T) {
+	dir := t.TempDir()
+	sc := openSQLite(t, filepath.Join(dir, "shop.db"), "CREATE TABLE logs (line TEXT)")
+	conn, closeConn, err := connectSaved(context.Background(), sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeConn()
+
+	path := filepath.Join(dir, "logs.sql")
+	if _, err := dumpTable(context.Background(), conn, DumpTableMsg{Schema: "main", Table: "logs"}, path, true, &rowProgress{}); err == nil || !strings.Contains(err.Error(), "COPY is not available for sqlite") {
+		t.Errorf("COPY from sqlite = %v, want it refused", err)
+	}
+	if _, err := dumpTable(context.Background(), conn, DumpTableMsg{Schema: "main", Table: "missing"}, path, false, &rowProgress{}); err == nil || !strings.Contains(err.Error(), "table missing not found") {
+		t.Errorf("dump of a missing table = %v", err)
+	}
+}
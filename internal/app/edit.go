@@ -0,0 +1,243 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/ui/dialog"
+	"github.com/sadopc/gotermsql/internal/ui/results"
+)
+
+// cellUpdatedMsg reports the outcome of an UPDATE generated from a grid edit.
+type cellUpdatedMsg struct {
+	Edit     results.EditCellMsg
+	Query    string
+	RowCount int64
+	Duration time.Duration
+	Err      error
+	ConnGen  uint64
+}
+
+// ident matches one optionally quoted SQL identifier.
+const ident = `(?:"[^"]+"|` + "`[^`]+`" + `|[A-Za-z_][A-Za-z0-9_$]*)`
+
+var (
+	// reSingleTable matches "SELECT ... FROM [schema.]table [alias] [tail]".
+	reSingleTable = regexp.MustCompile(`(?is)^\s*SELECT\s+.+?\s+FROM\s+(` + ident + `(?:\.` + ident + `)?)` +
+		`(?:\s+(?:AS\s+)?` + ident + `)?\s*(?:(?:WHERE|ORDER|LIMIT|OFFSET|FETCH)\b.*)?;?\s*$`)
+	// reNotSingleTable rejects queries whose rows cannot be mapped back to a
+	// single table row.
+	reNotSingleTable = regexp.MustCompile(`(?i)\b(JOIN|UNION|INTERSECT|EXCEPT|GROUP\s+BY|DISTINCT|HAVING)\b|\(\s*SELECT\b`)
+)
+
+// singleTableSelect extracts the table a simple single-table SELECT reads
+// from. schemaName is empty when the query does not qualify the table.
+func singleTableSelect(query string) (schemaName, table string, ok bool) {
+	if reNotSingleTable.MatchString(query) {
+		return "", "", false
+	}
+	m := reSingleTable.FindStringSubmatch(query)
+	if m == nil {
+		return "", "", false
+	}
+	name := m[1]
+	if strings.Contains(name, ",") {
+		return "", "", false
+	}
+	parts := splitQualified(name)
+	if len(parts) == 2 {
+		return parts[0], parts[1], true
+	}
+	return "", parts[0], true
+}
+
+// splitQualified splits "a.b" on the dot outside quotes and unquotes parts.
+func splitQualified(name string) []string {
+	var parts []string
+	var cur strings.Builder
+	var quote rune
+	for _, r := range name {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == 0 && (r == '"' || r == '`'):
+			quote = r
+		case quote == 0 && r == '.':
+			parts = append(parts, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	return append(parts, cur.String())
+}
+
+// findTable looks a table up in the loaded schema. When schemaName is empty
+// the first table with a matching name wins.
+func findTable(dbs []schema.Database, schemaName, table string) *schema.Table {
+	for di := range dbs {
+		for si := range dbs[di].Schemas {
+			s := &dbs[di].Schemas[si]
+			if schemaName != "" && !strings.EqualFold(s.Name, schemaName) {
+				continue
+			}
+			for ti := range s.Tables {
+				if strings.EqualFold(s.Tables[ti].Name, table) {
+					return &s.Tables[ti]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// buildCellUpdate generates the UPDATE statement for a grid edit. It
+// requires a single-table SELECT whose result includes every primary key
+// column of that table.
+func buildCellUpdate(dialect, query string, dbs []schema.Database, cols []adapter.ColumnMeta, e results.EditCellMsg) (string, error) {
+	schemaName, tableName, ok := singleTableSelect(query)
+	if !ok {
+		return "", errors.New("only single-table SELECT results can be edited")
+	}
+	tbl := findTable(dbs, schemaName, tableName)
+	if tbl == nil {
+		return "", fmt.Errorf("table %s not found in the loaded schema", tableName)
+	}
+	if e.Column < 0 || e.Column >= len(cols) {
+		return "", errors.New("no column selected")
+	}
+
+	colIndex := func(name string) int {
+		for i, c := range cols {
+			if strings.EqualFold(c.Name, name) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	target := ""
+	var where []string
+	for _, c := range tbl.Columns {
+		if strings.EqualFold(c.Name, cols[e.Column].Name) {
+			target = c.Name
+		}
+		if !c.IsPK {
+			continue
+		}
+		i := colIndex(c.Name)
+		if i < 0 || i >= len(e.Row) {
+			return "", fmt.Errorf("primary key column %s is not in the result", c.Name)
+		}
+		where = append(where, adapter.QuoteIdentifier(dialect, c.Name)+" = "+adapter.QuoteLiteral(dialect, e.Row[i]))
+	}
+	if len(where) == 0 {
+		return "", fmt.Errorf("table %s has no primary key", tbl.Name)
+	}
+	if target == "" {
+		return "", fmt.Errorf("%s is not a column of %s", cols[e.Column].Name, tbl.Name)
+	}
+
+	qualified := adapter.QuoteIdentifier(dialect, tbl.Name)
+	if schemaName != "" {
+		qualified = adapter.QuoteIdentifier(dialect, schemaName) + "." + qualified
+	}
+	value := adapter.QuoteLiteral(dialect, e.Value)
+	if e.SetNull {
+		value = "NULL"
+	}
+	return fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s",
+		qualified, adapter.QuoteIdentifier(dialect, target), value, strings.Join(where, " AND ")), nil
+}
+
+// confirmCellEdit previews the UPDATE for a grid edit and runs it when the
+// user confirms.
+func (m *Model) confirmCellEdit(e results.EditCellMsg) tea.Cmd {
+	ts := m.tabStates[e.TabID]
+	if ts == nil || m.conn == nil {
+		return nil
+	}
+	if m.safeMode {
+		var sbCmd tea.Cmd
+		m.statusbar, sbCmd = m.statusbar.Update(StatusMsg{Text: safeModeBlocked, IsError: true})
+		return sbCmd
+	}
+	stmt, err := buildCellUpdate(m.conn.AdapterName(), ts.Query, m.databases, ts.Results.Columns(), e)
+	if err != nil {
+		var sbCmd tea.Cmd
+		m.statusbar, sbCmd = m.statusbar.Update(StatusMsg{Text: "Cannot edit: " + err.Error(), IsError: true})
+		return sbCmd
+	}
+
+	conn := m.conn
+	gen := m.connGen
+	tracer := m.tracer
+	begin := m.beginTx()
+	m.showDialog("Update Row", stmt,
+		dialog.Button{Label: "Execute", Action: func() tea.Msg {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if begin != nil {
+				if err := begin(ctx); err != nil {
+					return cellUpdatedMsg{Edit: e, Query: stmt, Err: err, ConnGen: gen}
+				}
+			}
+			start := time.Now()
+			span, sent := tracer.Start(stmt, conn.AdapterName(), conn.DatabaseName())
+			res, err := conn.Execute(ctx, sent)
+			msg := cellUpdatedMsg{Edit: e, Query: stmt, Duration: time.Since(start), Err: err, ConnGen: gen}
+			if res != nil {
+				msg.RowCount = res.RowCount
+			}
+			if err != nil {
+				span.End(-1, err)
+			} else {
+				span.End(msg.RowCount, nil)
+			}
+			return msg
+		}},
+		dialog.Button{Label: "Cancel", Action: func() tea.Msg { return nil }},
+	)
+	return nil
+}
+
+// handleCellUpdated applies a successful grid edit and records it.
+func (m *Model) handleCellUpdated(msg cellUpdatedMsg) tea.Cmd {
+	if msg.ConnGen != m.connGen {
+		return nil
+	}
+	m.syncTransaction()
+	if msg.Err != nil {
+		m.auditLog(msg.Query, msg.Duration.Milliseconds(), 0, true)
+		var sbCmd tea.Cmd
+		m.statusbar, sbCmd = m.statusbar.Update(StatusMsg{
+			Text: "Update failed: " + sanitizeError(msg.Err.Error()), IsError: true,
+		})
+		return sbCmd
+	}
+
+	m.recordWrite(msg.Query, msg.Duration, msg.RowCount)
+
+	if ts := m.tabStates[msg.Edit.TabID]; ts != nil && msg.RowCount != 0 {
+		ts.Results.ApplyEdit(msg.Edit)
+	}
+
+	text := "Row updated"
+	isErr := false
+	switch {
+	case msg.RowCount == 0:
+		text, isErr = "Update matched no rows; the row may have changed", true
+	case msg.RowCount > 1:
+		text = fmt.Sprintf("Update affected %d rows", msg.RowCount)
+	}
+	var sbCmd tea.Cmd
+	m.statusbar, sbCmd = m.statusbar.Update(StatusMsg{Text: text, IsError: isErr})
+	return sbCmd
+}
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/ui/results"
+)
+
+func TestSingleTableSelect(t *testing.T) {
+	tests := []struct {
+		query         string
+		schema, table string
+		ok            bool
+	}{
+		{"SELECT * FROM users", "", "users", true},
+		{"select id, name from users where id > 3 order by id limit 10;", "", "users", true},
+		{`SELECT * FROM public."Order Items" oi WHERE oi.qty > 1`, "public", "Order Items", true},
+		{"SELECT * FROM `shop`.`orders`", "shop", "orders", true},
+		{"SELECT * FROM users AS u", "", "users", true},
+		{"SELECT * FROM users u JOIN orders o ON o.user_id = u.id", "", "", false},
+		{"SELECT * FROM a, b", "", "", false},
+		{"SELECT count(*) FROM users GROUP BY name", "", "", false},
+		{"SELECT DISTINCT name FROM users", "", "", false},
+		{"SELECT * FROM (SELECT 1) t", "", "", false},
+		{"UPDATE users SET x = 1", "", "", false},
+	}
+	for _, tt := range tests {
+		s, tbl, ok := singleTableSelect(tt.query)
+		if ok != tt.ok || s != tt.schema || tbl != tt.table {
+			t.Errorf("singleTableSelect(%q) = (%q, %q, %v), want (%q, %q, %v)",
+				tt.query, s, tbl, ok, tt.schema, tt.table, tt.ok)
+		}
+	}
+}
+
+func editSchema() []schema.Database {
+	return []schema.Database{{
+		Name: "app",
+		Schemas: []schema.Schema{{
+			Name: "public",
+			Tables: []schema.Table{
+				{
+					Name: "users",
+					Columns: []schema.Column{
+						{Name: "id", IsPK: true},
+						{Name: "name"},
+					},
+				},
+				{
+					Name:    "logs",
+					Columns: []schema.Column{{Name: "msg"}},
+				},
+			},
+		}},
+	}}
+}
+
+func TestBuildCellUpdate(t *testing.T) {
+	cols := []adapter.ColumnMeta{{Name: "id"}, {Name: "name"}}
+	edit := results.EditCellMsg{Row: []string{"7", "bob"}, Column: 1, Value: "O'Brien"}
+
+	got, err := buildCellUpdate("postgres", "SELECT * FROM users", editSchema(), cols, edit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `UPDATE "users" SET "name" = 'O''Brien' WHERE "id" = '7'`
+	if got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	edit.SetNull = true
+	got, err = buildCellUpdate("mysql", "SELECT * FROM public.users", editSchema(), cols, edit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "UPDATE `public`.`users` SET `name` = NULL WHERE `id` = '7'"
+	if got != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestBuildCellUpdate_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		cols    []adapter.ColumnMeta
+		row     []string
+		wantErr string
+	}{
+		{"join", "SELECT * FROM users u JOIN logs l ON true", []adapter.ColumnMeta{{Name: "name"}}, []string{"x"}, "single-table"},
+		{"unknown table", "SELECT * FROM missing", []adapter.ColumnMeta{{Name: "name"}}, []string{"x"}, "not found"},
+		{"no pk", "SELECT * FROM logs", []adapter.ColumnMeta{{Name: "msg"}}, []string{"x"}, "no primary key"},
+		{"pk not selected", "SELECT name FROM users", []adapter.ColumnMeta{{Name: "name"}}, []string{"x"}, "not in the result"},
+		{"computed column", "SELECT id, upper(name) AS shout FROM users", []adapter.ColumnMeta{{Name: "id"}, {Name: "shout"}}, []string{"1", "X"}, "not a column"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edit := results.EditCellMsg{Row: tt.row, Column: len(tt.cols) - 1, Value: "v"}
+			_, err := buildCellUpdate("postgres", tt.query, editSchema(), tt.cols, edit)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCellEdit_ShowsPreviewDialog(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	m.conn = &testConn{dbName: "app"}
+	m.databases = editSchema()
+	ts := m.activeTabState()
+	ts.Query = "SELECT * FROM users"
+	ts.Results.SetResults(&adapter.QueryResult{
+		Columns:  []adapter.ColumnMeta{{Name: "id"}, {Name: "name"}},
+		Rows:     [][]string{{"1", "a"}},
+		IsSelect: true,
+	})
+
+	model, _ := m.Update(results.EditCellMsg{TabID: m.tabs.ActiveID(), Row: []string{"1", "a"}, Column: 1, Value: "b"})
+	m = model.(Model)
+	if !m.dialog.Visible() {
+		t.Fatal("expected the UPDATE preview dialog")
+	}
+	if view := m.dialog.View(); !strings.Contains(view, `UPDATE "users"`) {
+		t.Errorf("dialog view = %q, want the generated UPDATE", view)
+	}
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sadopc/gotermsql/internal/ui/editor"
+)
+
+// editorSplit shows the editor of a second tab next to the active one's,
+// left of it and right of it when the results are below, above and below
+// it when they are beside. The results are always the active tab's.
+type editorSplit struct {
+	tab    int  // the tab whose editor is shown next to the active one
+	active int  // the tab that was active when the split last saw it
+	first  bool // tab's editor is drawn first (left or above)
+}
+
+// toggleSplit opens or closes the split. Opened, it shows the next tab, or
+// a new one if there is only one, which then takes the focus.
+func (m *Model) toggleSplit() tea.Cmd {
+	if m.split != nil {
+		m.split = nil
+		m.updateLayout()
+		return nil
+	}
+	active := m.tabs.ActiveID()
+	tabs := m.tabs.Tabs()
+	if len(tabs) < 2 {
+		tabID, cmd := m.addTab("")
+		m.split = &editorSplit{tab: active, active: tabID, first: true}
+		m.updateLayout()
+		return cmd
+	}
+	next := tabs[0].ID
+	for i, t := range tabs {
+		if t.ID == active {
+			next = tabs[(i+1)%len(tabs)].ID
+		}
+	}
+	m.split = &editorSplit{tab: next, active: active}
+	m.setFocus(PaneEditor)
+	m.updateLayout()
+	return nil
+}
+
+// otherSplit moves the focus to the editor of the split's other tab, which
+// becomes the active tab.
+func (m *Model) otherSplit() tea.Cmd {
+	if m.split == nil {
+		return nil
+	}
+	tabID := m.split.tab
+	m.focusedPane = PaneEditor
+	return func() tea.Msg { return SwitchTabMsg{TabID: tabID} }
+}
+
+// syncSplit follows the active tab: switching to the split's tab swaps the
+// two, so each editor stays where it is drawn.
+func (m *Model) syncSplit() {
+	if m.split == nil {
+		return
+	}
+	active := m.tabs.ActiveID()
+	if active == m.split.tab {
+		m.split.tab = m.split.active
+		m.split.first = !m.split.first
+	}
+	m.split.active = active
+}
+
+// splitEditor returns the editor shown next to the active one, or nil;
+// zen mode shows only the active one.
+func (m Model) splitEditor() *editor.Model {
+	if m.split == nil || m.zen {
+		return nil
+	}
+	ts := m.tabStates[m.split.tab]
+	if ts == nil || m.split.tab == m.tabs.ActiveID() {
+		return nil
+	}
+	return &ts.Editor
+}
+
+// splitSizes divides the editor area of width by height between the active
+// editor and the split's.
+func (m Model) splitSizes(width, height int) (activeW, activeH, otherW, otherH int) {
+	if m.sideBySide {
+		first, second := height/2, height-height/2
+		if m.split.first {
+			return width, second, width, first
+		}
+		return width, first, width, second
+	}
+	first, second := width/2, width-width/2
+	if m.split.first {
+		return second, height, first, height
+	}
+	return first, height, second, height
+}
+
+// joinSplit draws the active editor's view and the split's in their places.
+func (m Model) joinSplit(active, other string) string {
+	first, second := active, other
+	if m.split.first {
+		first, second = other, active
+	}
+	if m.sideBySide {
+		return lipgloss.JoinVertical(lipgloss.Left, first, second)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, first, second)
+}
+
+// inSplit reports whether x, y, within the editor area at left, top of
+// width by height, is on the split's editor.
+func (m Model) inSplit(x, y, left, top, width, height int) bool {
+	if m.splitEditor() == nil {
+		return false
+	}
+	activeW, activeH, otherW, otherH := m.splitSizes(width, height)
+	switch {
+	case m.sideBySide && m.split.first:
+		return y < top+otherH
+	case m.sideBySide:
+		return y >= top+activeH
+	case m.split.first:
+		return x < left+otherW
+	}
+	return x >= left+activeW
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+func TestEditorSplit(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	m.conn = &testConn{dbName: "app"}
+	update := func(msg tea.Msg) {
+		model, cmd := m.Update(msg)
+		m = model.(Model)
+		if cmd == nil {
+			return
+		}
+		// Follow the tab switch the key asks for.
+		if sw, ok := cmd().(SwitchTabMsg); ok {
+			model, _ = m.Update(sw)
+			m = model.(Model)
+		}
+	}
+	alt := func(r rune) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}, Alt: true} }
+	update(tea.WindowSizeMsg{Width: 160, Height: 30})
+	first := m.tabs.ActiveID()
+	m.tabStates[first].Editor.SetValue("SELECT 'left'")
+
+	// With one tab, the split opens a new one beside it and focuses it.
+	update(alt('s'))
+	second := m.tabs.ActiveID()
+	if m.split == nil || second == first || m.split.tab != first {
+		t.Fatalf("split = %+v, active = %d; want a new tab split with %d", m.split, second, first)
+	}
+	update(keyMsgFromString("x"))
+	row := func() string {
+		for _, line := range strings.Split(m.View(), "\n") {
+			if strings.Contains(line, "left") {
+				return line
+			}
+		}
+		t.Fatalf("no line shows the first editor:\n%s", m.View())
+		return ""
+	}
+	if line := row(); strings.Index(line, "left") > strings.Index(line, "x") {
+		t.Errorf("the first tab should stay on the left, the new one on the right: %q", line)
+	}
+	if got := m.tabStates[second].Editor.Value(); got != "x" {
+		t.Errorf("typing should go to the focused split, second editor = %q", got)
+	}
+
+	// alt+w moves to the other split without moving either.
+	update(alt('w'))
+	if m.tabs.ActiveID() != first || m.split.tab != second {
+		t.Fatalf("after alt+w: active = %d, split = %+v", m.tabs.ActiveID(), m.split)
+	}
+	if line := row(); strings.Index(line, "left") > strings.Index(line, "x") {
+		t.Errorf("the editors should not swap places: %q", line)
+	}
+	update(keyMsgFromString("!"))
+	if got := m.tabStates[first].Editor.Value(); got != "SELECT 'left'!" {
+		t.Errorf("first editor = %q, want the typed key", got)
+	}
+
+	// A click on the other editor focuses it.
+	l := m.layout()
+	m, cmd := click(m, l.mainX+l.mainW-5, l.tabH+3)
+	if cmd == nil || cmd() != (SwitchTabMsg{TabID: second}) {
+		t.Error("a click on the other split should switch to its tab")
+	}
+
+	update(alt('s'))
+	if m.split != nil || strings.Count(m.View(), "SELECT 'left'") != 1 {
+		t.Error("alt+s should close the split")
+	}
+
+	update(alt('s'))
+	update(CloseTabMsg{TabID: second})
+	if m.split != nil {
+		t.Error("closing a tab of the split should close it")
+	}
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+// erDiagramTimeout bounds loading the foreign keys of a schema that was
+// loaded lazily.
+const erDiagramTimeout = time.Minute
+
+// erTablesMsg carries the tables of a lazily loaded schema, with their
+// columns and foreign keys, for the ER diagram.
+type erTablesMsg struct {
+	title   string
+	focus   string
+	tables  []schema.Table
+	err     error
+	connGen uint64
+}
+
+// openERDiagram opens the ER diagram of the schema msg names. The tables of
+// a schema loaded lazily are loaded in full first, in the background.
+func (m *Model) openERDiagram(msg ERDiagramMsg) tea.Cmd {
+	dbName, s := m.diagramSchema(msg.Database, msg.Schema)
+	if s == nil {
+		return m.toast(ToastError, "No schema loaded")
+	}
+	title := s.Name
+	if title == "" || title == "main" {
+		title = dbName // SQLite and DuckDB name the default schema main
+	}
+	lazy := slices.ContainsFunc(s.Tables, func(t schema.Table) bool { return t.Columns == nil })
+	if !lazy || m.conn == nil {
+		m.erDiagram.Show(title, s.Tables, msg.Table)
+		return nil
+	}
+
+	conn, gen := m.conn, m.connGen
+	load := schema.Schema{Name: s.Name, Tables: slices.Clone(s.Tables)}
+	status := func() tea.Msg { return StatusMsg{Text: "Loading the foreign keys of " + title + "..."} }
+	return tea.Batch(status, func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), erDiagramTimeout)
+		defer cancel()
+		loadSchemaTables(ctx, conn, dbName, &load)
+		return erTablesMsg{title: title, focus: msg.Table, tables: load.Tables, err: ctx.Err(), connGen: gen}
+	})
+}
+
+// diagramSchema finds a loaded schema by database and name; empty names
+// mean the first.
+func (m *Model) diagramSchema(dbName, schemaName string) (string, *schema.Schema) {
+	for di := range m.databases {
+		db := &m.databases[di]
+		if dbName != "" && db.Name != dbName {
+			continue
+		}
+		for si := range db.Schemas {
+			if schemaName == "" || db.Schemas[si].Name == schemaName {
+				return db.Name, &db.Schemas[si]
+			}
+		}
+	}
+	return "", nil
+}
+
+// handleERTables opens the ER diagram on the tables loaded for it.
+func (m *Model) handleERTables(msg erTablesMsg) tea.Cmd {
+	if msg.connGen != m.connGen {
+		return nil
+	}
+	if msg.err != nil {
+		return m.toast(ToastError, "Loading the foreign keys of "+msg.title+" timed out")
+	}
+	m.erDiagram.Show(msg.title, msg.tables, msg.focus)
+	return nil
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+func TestERDiagram(t *testing.T) {
+	m := step(New(config.DefaultConfig(), nil, nil), tea.WindowSizeMsg{Width: 160, Height: 40})
+	m.conn = diffConn{sqliteConn: sqliteConn{&testConn{dbName: "app"}}, tables: map[string][]schema.Column{
+		"users":  {{Name: "id", Type: "INTEGER", IsPK: true}},
+		"orders": {{Name: "id", Type: "INTEGER", IsPK: true}},
+	}}
+	// A schema loaded lazily: the tables have no columns yet.
+	m.databases = []schema.Database{{Name: "app", Schemas: []schema.Schema{{Name: "main", Tables: []schema.Table{
+		{Name: "orders"}, {Name: "users"},
+	}}}}}
+
+	model, cmd := m.Update(ERDiagramMsg{Table: "orders"})
+	m = model.(Model)
+	if m.erDiagram.Visible() {
+		t.Fatal("the diagram opened before its tables were loaded")
+	}
+	var loaded erTablesMsg
+	for _, msg := range drainBatch(cmd) {
+		if msg, ok := msg.(erTablesMsg); ok {
+			loaded = msg
+		}
+	}
+	if len(loaded.tables) != 2 || loaded.tables[0].Columns == nil {
+		t.Fatalf("loaded %#v, want both tables with their columns", loaded.tables)
+	}
+	m = step(m, loaded)
+	if !m.erDiagram.Visible() || m.erDiagram.Focus() != "orders" || m.erDiagram.Selected() != "orders" {
+		t.Fatalf("the diagram should show the neighbours of orders, focus %q", m.erDiagram.Focus())
+	}
+
+	m = step(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.erDiagram.Visible() {
+		t.Error("esc should close the diagram")
+	}
+	m.connGen++ // reconnected
+	if m = step(m, loaded); m.erDiagram.Visible() {
+		t.Error("tables loaded from a closed connection were shown")
+	}
+}
@@ -0,0 +1,117 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/ui/editor"
+)
+
+// handleExCommand runs the vim ex commands the editor hands over: :w and
+// :e on files, :q to close the tab (or quit on the last one), :wq, :run
+// to run the editor's query, and :source to run a script file.
+func (m *Model) handleExCommand(msg editor.ExCommandMsg) tea.Cmd {
+	ts := m.tabStates[msg.TabID]
+	if ts == nil {
+		return nil
+	}
+	switch msg.Name {
+	case "w":
+		return m.writeTab(msg.TabID, ts, msg.Arg)
+	case "e":
+		return m.editFile(msg.TabID, ts, msg.Arg, msg.Force)
+	case "q":
+		return m.quitTab(msg.TabID, ts, msg.Force)
+	case "wq":
+		if ts.Editor.Modified() || msg.Arg != "" {
+			cmd := m.writeTab(msg.TabID, ts, msg.Arg)
+			if ts.Editor.Modified() {
+				return cmd // the write failed
+			}
+		}
+		return m.quitTab(msg.TabID, ts, false)
+	case "run":
+		query := ts.Editor.Value()
+		if query == "" {
+			return nil
+		}
+		tabID, noTimeout := msg.TabID, msg.Force
+		return func() tea.Msg { return ExecuteQueryMsg{Query: query, TabID: tabID, Lint: true, NoTimeout: noTimeout} }
+	case "source":
+		return m.sourceFile(msg.TabID, msg.Arg, msg.Force)
+	}
+	return nil
+}
+
+// writeTab writes the tab's editor to path, or to the file it was last
+// read from or written to. Like vim, it ends the file with a line break.
+func (m *Model) writeTab(tabID int, ts *TabState, path string) tea.Cmd {
+	if path == "" {
+		path = ts.File
+	}
+	if path == "" {
+		return exStatus("No file name", true)
+	}
+	text := ts.Editor.Value()
+	data := text
+	if data != "" && !strings.HasSuffix(data, "\n") {
+		data += "\n"
+	}
+	if err := os.WriteFile(config.ExpandHome(path), []byte(data), 0o644); err != nil {
+		return exStatus("Write failed: "+err.Error(), true)
+	}
+	ts.File = path
+	ts.Editor.ResetModified()
+	m.tabs.SetTitle(tabID, filepath.Base(path))
+	return exStatus(fmt.Sprintf("%q %dL, %dB written", path, strings.Count(data, "\n"), len(data)), false)
+}
+
+// editFile reads path into the tab's editor, refusing to drop unsaved
+// changes unless forced. A file that does not exist yet starts empty.
+func (m *Model) editFile(tabID int, ts *TabState, path string, force bool) tea.Cmd {
+	if ts.Editor.Modified() && !force {
+		return exStatus("No write since last change (add ! to override)", true)
+	}
+	if path == "" {
+		path = ts.File
+	}
+	if path == "" {
+		return exStatus("No file name", true)
+	}
+	data, err := os.ReadFile(config.ExpandHome(path))
+	status := fmt.Sprintf("%q %dL, %dB", path, strings.Count(string(data), "\n"), len(data))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		status = fmt.Sprintf("%q [New]", path)
+	case err != nil:
+		return exStatus("Cannot read "+path+": "+err.Error(), true)
+	}
+	ts.Editor.SetValue(strings.TrimSuffix(string(data), "\n"))
+	ts.Editor.SetCursor(0, 0)
+	ts.Editor.ResetModified()
+	ts.File = path
+	m.tabs.SetTitle(tabID, filepath.Base(path))
+	return exStatus(status, false)
+}
+
+// quitTab closes the tab, or quits from the last one, refusing to drop
+// unsaved changes unless forced.
+func (m *Model) quitTab(tabID int, ts *TabState, force bool) tea.Cmd {
+	if ts.Editor.Modified() && !force {
+		return exStatus("No write since last change (add ! to override)", true)
+	}
+	if m.tabs.Count() <= 1 {
+		return m.confirmQuit()
+	}
+	return func() tea.Msg { return CloseTabMsg{TabID: tabID} }
+}
+
+func exStatus(text string, isErr bool) tea.Cmd {
+	return func() tea.Msg { return StatusMsg{Text: text, IsError: isErr} }
+}
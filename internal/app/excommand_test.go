@@ -0,0 +1,73 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/ui/editor"
+)
+
+func TestExCommand_WriteEditQuit(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	tabID := m.tabs.ActiveID()
+	ts := m.tabStates[tabID]
+	ts.Editor.InsertText("SELECT 1")
+	path := filepath.Join(t.TempDir(), "q.sql")
+	ex := func(name, arg string, force bool) tea.Msg {
+		cmd := m.handleExCommand(editor.ExCommandMsg{TabID: tabID, Name: name, Arg: arg, Force: force})
+		if cmd == nil {
+			return nil
+		}
+		return cmd()
+	}
+
+	if msg, _ := ex("q", "", false).(StatusMsg); !msg.IsError {
+		t.Error(":q with unsaved changes should refuse")
+	}
+	if msg, _ := ex("w", "", false).(StatusMsg); msg.Text != "No file name" {
+		t.Errorf(":w without a file = %q", msg.Text)
+	}
+	if msg, _ := ex("w", path, false).(StatusMsg); msg.IsError {
+		t.Fatalf(":w failed: %s", msg.Text)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "SELECT 1\n" {
+		t.Errorf("wrote %q", data)
+	}
+	if ts.Editor.Modified() || m.tabs.ActiveTab().Title != "q.sql" {
+		t.Error(":w should clear the modified flag and name the tab after the file")
+	}
+
+	if err := os.WriteFile(path, []byte("SELECT 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ts.Editor.InsertText("-- edited")
+	if msg, _ := ex("e", "", false).(StatusMsg); !msg.IsError {
+		t.Error(":e with unsaved changes should refuse")
+	}
+	ex("e", "", true)
+	if got := ts.Editor.Value(); got != "SELECT 2" {
+		t.Errorf(":e! read %q, want %q", got, "SELECT 2")
+	}
+
+	if msg, ok := ex("run", "", false).(ExecuteQueryMsg); !ok || msg.Query != "SELECT 2" || msg.TabID != tabID {
+		t.Errorf(":run = %#v", msg)
+	}
+	if _, ok := ex("q", "", false).(tea.QuitMsg); !ok {
+		t.Error(":q on the last tab should quit")
+	}
+}
+
+func TestExCommand_QuitClosesTab(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	tabID, _ := m.addTab("")
+	cmd := m.handleExCommand(editor.ExCommandMsg{TabID: tabID, Name: "q"})
+	if cmd == nil {
+		t.Fatal(":q should close the tab")
+	}
+	if msg, ok := cmd().(CloseTabMsg); !ok || msg.TabID != tabID {
+		t.Errorf(":q = %#v, want CloseTabMsg for tab %d", msg, tabID)
+	}
+}
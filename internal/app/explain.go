@@ -0,0 +1,128 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/plan"
+	"github.com/sadopc/gotermsql/internal/theme"
+	"github.com/sadopc/gotermsql/internal/ui/dialog"
+)
+
+// explainMsg asks for the query to be run under EXPLAIN ANALYZE.
+// Confirmed is set once running a write has been agreed to.
+type explainMsg struct {
+	Query     string
+	Confirmed bool
+}
+
+// explainLoadedMsg carries an executed plan, tagged with the connection
+// generation so a reply from a closed connection is dropped.
+type explainLoadedMsg struct {
+	Plan    *plan.Plan
+	Err     error
+	ConnGen uint64
+}
+
+// explainQuery runs the query of the active tab under EXPLAIN ANALYZE (F8).
+func (m *Model) explainQuery() tea.Cmd {
+	ts := m.activeTabState()
+	if ts == nil || strings.TrimSpace(ts.Editor.Value()) == "" {
+		return exStatus("No query to explain", true)
+	}
+	return m.explain(explainMsg{Query: ts.Editor.Value()})
+}
+
+// explain runs msg.Query under EXPLAIN ANALYZE in the background. As that
+// executes the query, a write is refused in safe mode and otherwise asked
+// about first.
+func (m *Model) explain(msg explainMsg) tea.Cmd {
+	if m.conn == nil {
+		return exStatus("Not connected", true)
+	}
+	dialect := m.conn.AdapterName()
+	query, err := plan.Query(dialect, msg.Query)
+	if err != nil {
+		return exStatus(err.Error(), true)
+	}
+	if !adapter.IsReadOnlyQuery(msg.Query) {
+		if m.safeMode {
+			return exStatus(safeModeBlocked, true)
+		}
+		if !msg.Confirmed {
+			msg.Confirmed = true
+			m.showDialog("Explain Analyze",
+				adapter.TrimStatement(msg.Query)+"\n\nEXPLAIN ANALYZE runs the statement, and its changes are kept.",
+				dialog.Button{Label: "Run", Action: func() tea.Msg { return msg }},
+				dialog.Button{Label: "Cancel", Action: func() tea.Msg { return nil }},
+			)
+			return nil
+		}
+	}
+
+	conn := m.conn
+	gen := m.connGen
+	begin := m.beginTx()
+	timeout := m.queryTimeout()
+	return tea.Batch(
+		exStatus("Running EXPLAIN ANALYZE…", false),
+		func() tea.Msg {
+			ctx := context.Background()
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+			if begin != nil {
+				if err := begin(ctx); err != nil {
+					return explainLoadedMsg{Err: err, ConnGen: gen}
+				}
+			}
+			res, err := conn.Execute(ctx, query)
+			if err != nil {
+				return explainLoadedMsg{Err: err, ConnGen: gen}
+			}
+			p, err := plan.Parse(dialect, res)
+			return explainLoadedMsg{Plan: p, Err: err, ConnGen: gen}
+		},
+	)
+}
+
+// handleExplainLoaded opens the viewer on an executed plan, coloring the
+// nodes by the share of the time spent in them.
+func (m *Model) handleExplainLoaded(msg explainLoadedMsg) tea.Cmd {
+	if msg.ConnGen != m.connGen {
+		return nil
+	}
+	m.syncTransaction()
+	if msg.Err != nil {
+		text := sanitizeError(msg.Err.Error())
+		if !errors.Is(msg.Err, plan.ErrUnsupported) {
+			text = "EXPLAIN ANALYZE failed: " + text
+		}
+		return exStatus(text, true)
+	}
+	th := theme.Current
+	lines := msg.Plan.Lines()
+	text := make([]string, len(lines))
+	styles := make([]lipgloss.Style, len(lines))
+	for i, l := range lines {
+		text[i] = l.Text
+		switch l.Heat {
+		case plan.HeatHot:
+			styles[i] = th.ErrorText
+		case plan.HeatWarm:
+			styles[i] = th.WarningText
+		case plan.HeatCold:
+			styles[i] = lipgloss.NewStyle()
+		default:
+			styles[i] = th.MutedText
+		}
+	}
+	m.viewer.ShowStyled("Explain Analyze", strings.Join(text, "\n"), styles)
+	return nil
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+type pgConn struct{ *testConn }
+
+func (pgConn) AdapterName() string { return "postgres" }
+
+const explainJSON = `[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "users",
+  "Plan Rows": 1, "Actual Rows": 500, "Actual Loops": 1, "Actual Total Time": 2.0,
+  "Shared Read Blocks": 8}, "Planning Time": 0.1, "Execution Time": 2.1}]`
+
+func TestExplainAnalyze(t *testing.T) {
+	m := step(New(config.DefaultConfig(), nil, nil), tea.WindowSizeMsg{Width: 160, Height: 40})
+	conn := &testConn{dbName: "app", result: &adapter.QueryResult{Rows: [][]string{{explainJSON}}}}
+	m.conn = pgConn{conn}
+	m.activeTabState().Editor.SetValue("SELECT * FROM users;")
+
+	m = step(m, tea.KeyMsg{Type: tea.KeyF8})
+	want := "EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) SELECT * FROM users"
+	if !slices.Equal(conn.executed, []string{want}) {
+		t.Fatalf("executed = %q, want %q", conn.executed, want)
+	}
+	if !m.viewer.Visible() {
+		t.Fatal("expected the plan in the viewer")
+	}
+	view := m.viewer.View()
+	for _, s := range []string{"Seq Scan on users", "⚠ 500× under", "read 8"} {
+		if !strings.Contains(view, s) {
+			t.Errorf("plan view lacks %q", s)
+		}
+	}
+}
+
+func TestExplainAnalyze_WriteAsks(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	conn := &testConn{dbName: "app", result: &adapter.QueryResult{Rows: [][]string{{explainJSON}}}}
+	m.conn = pgConn{conn}
+	m.activeTabState().Editor.SetValue("DELETE FROM users")
+
+	m = step(m, tea.KeyMsg{Type: tea.KeyF8})
+	if len(conn.executed) != 0 || !m.dialog.Visible() {
+		t.Fatal("expected EXPLAIN ANALYZE of a write to ask first")
+	}
+	// Enter picks Run.
+	model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(Model)
+	for _, next := range drainBatch(cmd) {
+		m = step(m, next)
+	}
+	if len(conn.executed) != 1 {
+		t.Errorf("executed = %q, want the DELETE explained once agreed to", conn.executed)
+	}
+
+	conn.executed = nil
+	m.safeMode = true
+	m = step(m, tea.KeyMsg{Type: tea.KeyF8})
+	if len(conn.executed) != 0 || m.dialog.Visible() {
+		t.Error("safe mode should refuse EXPLAIN ANALYZE of a write")
+	}
+}
+
+func TestExplainAnalyze_Unsupported(t *testing.T) {
+	m := step(New(config.DefaultConfig(), nil, nil), tea.WindowSizeMsg{Width: 160, Height: 40})
+	conn := sqliteConn{&testConn{dbName: "app"}}
+	m.conn = conn
+	m.activeTabState().Editor.SetValue("SELECT 1")
+
+	m = step(m, tea.KeyMsg{Type: tea.KeyF8})
+	if len(conn.executed) != 0 || m.viewer.Visible() {
+		t.Error("EXPLAIN ANALYZE should not run on sqlite")
+	}
+	if !strings.Contains(m.statusbar.View(), "not supported") {
+		t.Error("expected the status bar to say EXPLAIN ANALYZE is not supported")
+	}
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// favoritesKey returns the key the connection's favorite tables are saved
+// under in the config: the DSN with its password masked.
+func (m Model) favoritesKey() string {
+	if m.dsn != "" {
+		return m.dsn
+	}
+	if m.conn != nil {
+		return m.conn.AdapterName() + ":" + m.conn.DatabaseName()
+	}
+	return ""
+}
+
+// loadFavorites shows the current connection's favorites in the sidebar.
+func (m *Model) loadFavorites() {
+	m.sidebar.SetFavorites(m.cfg.Sidebar.Favorites[m.favoritesKey()])
+}
+
+// saveFavorites records the connection's favorites in the config file.
+func (m *Model) saveFavorites(tables []string) tea.Cmd {
+	key := m.favoritesKey()
+	if key == "" {
+		return nil
+	}
+	if len(tables) == 0 {
+		delete(m.cfg.Sidebar.Favorites, key)
+	} else {
+		if m.cfg.Sidebar.Favorites == nil {
+			m.cfg.Sidebar.Favorites = make(map[string][]string)
+		}
+		m.cfg.Sidebar.Favorites[key] = tables
+	}
+	if err := m.cfg.SaveDefault(); err != nil {
+		text := "Failed to save favorites: " + err.Error()
+		return func() tea.Msg { return StatusMsg{Text: text, IsError: true} }
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+func TestFavorites_SavedPerConnection(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpHome, ".config"))
+
+	m := New(config.DefaultConfig(), nil, nil)
+	model, _ := m.Update(ConnectMsg{Conn: &testConn{dbName: "app"}, Adapter: "postgres", DSN: "postgres://bob:secret@db/app"})
+	m = model.(Model)
+
+	model, _ = m.Update(FavoritesChangedMsg{Tables: []string{"public.users"}})
+	m = model.(Model)
+
+	loaded, err := config.LoadDefault()
+	if err != nil {
+		t.Fatalf("LoadDefault() error = %v", err)
+	}
+	got := loaded.Sidebar.Favorites["postgres://%2A%2A%2A@db/app"]
+	if len(got) != 1 || got[0] != "public.users" {
+		t.Errorf("saved favorites = %v", loaded.Sidebar.Favorites)
+	}
+
+	// A later session on the same server gets them back, whatever the password.
+	m = New(loaded, nil, nil)
+	model, _ = m.Update(ConnectMsg{Conn: &testConn{dbName: "app"}, Adapter: "postgres", DSN: "postgres://bob:changed@db/app"})
+	m = model.(Model)
+	if favs := m.sidebar.Favorites(); len(favs) != 1 || favs[0] != "public.users" {
+		t.Errorf("favorites after reconnecting = %v", favs)
+	}
+}
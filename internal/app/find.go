@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/ui/results"
+)
+
+// findTimeout bounds a server-side search over a streaming result.
+const findTimeout = 2 * time.Minute
+
+// foundRowMsg carries a server-side search result back to its tab, tagged
+// so stale answers from an earlier run or connection are dropped.
+type foundRowMsg struct {
+	Found   results.FoundRowMsg
+	RunID   uint64
+	ConnGen uint64
+}
+
+// startFind searches the tab's streaming query on the server with
+// adapter.SearchQuery, through the connection pool like startCount.
+func (m *Model) startFind(msg results.FindMsg) tea.Cmd {
+	ts := m.tabStates[msg.TabID]
+	if ts == nil || m.conn == nil || ts.Query == "" {
+		return nil
+	}
+	query := adapter.SearchQuery(m.conn.AdapterName(), ts.sent(), msg.Column, msg.Text, msg.After)
+	if query == "" {
+		return func() tea.Msg {
+			return StatusMsg{Text: "Only SELECT results can be searched on the server", IsError: true}
+		}
+	}
+
+	conn := m.conn
+	reply := foundRowMsg{
+		Found:   results.FoundRowMsg{TabID: msg.TabID, Text: msg.Text},
+		RunID:   ts.RunID,
+		ConnGen: m.connGen,
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(adapter.OnPool(context.Background()), findTimeout)
+		defer cancel()
+		res, err := conn.Execute(ctx, query)
+		if err != nil {
+			reply.Found.Err = err
+			return reply
+		}
+		if res != nil && len(res.Rows) > 0 && len(res.Rows[0]) > 0 {
+			reply.Found.Row = parseCount(res.Rows[0][0])
+		}
+		return reply
+	}
+}
+
+// handleFoundRow hands a search result to the tab that asked for it.
+func (m *Model) handleFoundRow(msg foundRowMsg) tea.Cmd {
+	if msg.ConnGen != m.connGen {
+		return nil
+	}
+	ts := m.tabStates[msg.Found.TabID]
+	if ts == nil || msg.RunID != ts.RunID {
+		return nil
+	}
+	var cmd tea.Cmd
+	ts.Results, cmd = ts.Results.Update(msg.Found)
+	return cmd
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/ui/results"
+)
+
+func TestStartFind_RunsSearchQuery(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	conn := &testConn{result: &adapter.QueryResult{Rows: [][]string{{"17"}}}}
+	m.conn = conn
+	ts := m.tabStates[0]
+	ts.Query = "SELECT * FROM users"
+	ts.RunID = 5
+
+	cmd := m.startFind(results.FindMsg{TabID: 0, Column: "email", Text: "bob", After: 3})
+	if cmd == nil {
+		t.Fatal("expected a search command")
+	}
+	msg := cmd().(foundRowMsg)
+	if len(conn.executed) != 1 || !strings.Contains(conn.executed[0], "gotermsql_n > 3") {
+		t.Fatalf("executed %v, want a search query after row 3", conn.executed)
+	}
+	if msg.Found.Row != 17 || msg.RunID != 5 {
+		t.Fatalf("msg = %+v", msg)
+	}
+
+	// Results of an earlier run are dropped.
+	msg.RunID = 4
+	if m.handleFoundRow(msg) != nil {
+		t.Error("stale search result should be ignored")
+	}
+}
+
+func TestStartFind_NotSearchable(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	m.conn = &testConn{}
+	m.tabStates[0].Query = "SHOW TABLES"
+	cmd := m.startFind(results.FindMsg{TabID: 0, Column: "a", Text: "x"})
+	if cmd == nil {
+		t.Fatal("expected an error status")
+	}
+	if msg, ok := cmd().(StatusMsg); !ok || !msg.IsError {
+		t.Errorf("got %#v, want an error StatusMsg", cmd())
+	}
+}
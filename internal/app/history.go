@@ -0,0 +1,42 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/history"
+	"github.com/sadopc/gotermsql/internal/ui/historybrowser"
+)
+
+// historyScope returns the adapter and database the history browser shows
+// by default, or nil when not connected.
+func (m *Model) historyScope() *history.Scope {
+	if m.conn == nil {
+		return nil
+	}
+	return &history.Scope{Adapter: m.conn.AdapterName(), Database: m.conn.DatabaseName()}
+}
+
+// rerunHistory runs a query picked in the history browser, replacing the
+// active tab's query or in a new tab.
+func (m *Model) rerunHistory(msg historybrowser.RunQueryMsg) tea.Cmd {
+	query := msg.Query
+	if msg.NewTab {
+		return func() tea.Msg { return NewTabMsg{Query: query, Run: true} }
+	}
+	ts := m.activeTabState()
+	if ts == nil {
+		return nil
+	}
+	ts.Editor.SetValue(query)
+	m.setFocus(PaneEditor)
+	tabID := m.tabs.ActiveID()
+	return func() tea.Msg { return ExecuteQueryMsg{Query: query, TabID: tabID} }
+}
+
+// errorText returns the message of a failed query's error for the history,
+// with any DSN password masked.
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return sanitizeError(err.Error())
+}
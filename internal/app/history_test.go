@@ -0,0 +1,131 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/audit"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/history"
+	"github.com/sadopc/gotermsql/internal/ui/historybrowser"
+)
+
+func TestRerunHistory(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	m.activeTabState().Editor.SetValue("SELECT old")
+	tabID := m.tabs.ActiveID()
+
+	model, cmd := m.Update(historybrowser.RunQueryMsg{Query: "SELECT 1"})
+	m = model.(Model)
+	if got := m.activeTabState().Editor.Value(); got != "SELECT 1" {
+		t.Errorf("editor = %q, want the rerun query", got)
+	}
+	var exec *ExecuteQueryMsg
+	for _, msg := range drainBatch(cmd) {
+		if e, ok := msg.(ExecuteQueryMsg); ok {
+			exec = &e
+		}
+	}
+	if exec == nil || exec.Query != "SELECT 1" || exec.TabID != tabID {
+		t.Fatalf("got %+v, want SELECT 1 run in tab %d", exec, tabID)
+	}
+
+	_, cmd = m.Update(historybrowser.RunQueryMsg{Query: "SELECT 2", NewTab: true})
+	var tab *NewTabMsg
+	for _, msg := range drainBatch(cmd) {
+		if n, ok := msg.(NewTabMsg); ok {
+			tab = &n
+		}
+	}
+	if tab == nil || tab.Query != "SELECT 2" || !tab.Run {
+		t.Fatalf("got %+v, want a new tab running SELECT 2", tab)
+	}
+}
+
+func TestQueryErr_RecordsMessage(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpHome, ".config"))
+	hist, err := history.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hist.Close()
+
+	m := New(config.DefaultConfig(), hist, nil)
+	model, _ := m.Update(ConnectMsg{Conn: &testConn{dbName: "app"}, Adapter: "postgres", DSN: "postgres://u@db/app"})
+	m = model.(Model)
+	ts := m.activeTabState()
+	ts.Query = "SELECT nope"
+	ts.RunID = 7
+
+	model, _ = m.Update(QueryErrMsg{
+		Err:     errors.New(`column "nope" does not exist`),
+		TabID:   m.tabs.ActiveID(),
+		RunID:   7,
+		ConnGen: m.connGen,
+	})
+	m = model.(Model)
+
+	entries, err := hist.Recent(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || !entries[0].IsError || entries[0].ErrorMessage != `column "nope" does not exist` {
+		t.Errorf("history = %+v", entries)
+	}
+}
+
+func TestRedact_HistoryAndAudit(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpHome, ".config"))
+	hist, err := history.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hist.Close()
+	auditPath := filepath.Join(tmpHome, "audit.jsonl")
+	auditLog, err := audit.New(auditPath, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer auditLog.Close()
+	redactor, err := adapter.NewRedactor([]string{"password"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := New(config.DefaultConfig(), hist, auditLog)
+	m.SetRedactor(redactor)
+	model, _ := m.Update(ConnectMsg{Conn: &testConn{dbName: "app"}, Adapter: "postgres", DSN: "postgres://u@db/app"})
+	m = model.(Model)
+	ts := m.activeTabState()
+	ts.Query = "SELECT id FROM users WHERE password = 'hunter2'"
+	ts.RunID = 7
+
+	m.Update(QueryErrMsg{Err: errors.New("boom"), TabID: m.tabs.ActiveID(), RunID: 7, ConnGen: m.connGen})
+
+	const want = "SELECT id FROM users WHERE password = '***'"
+	entries, err := hist.Recent(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Query != want {
+		t.Errorf("history = %+v, want the password masked", entries)
+	}
+	data, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "hunter2") || !strings.Contains(string(data), "password = '***'") {
+		t.Errorf("audit log = %s, want the password masked", data)
+	}
+	if ts.Query != "SELECT id FROM users WHERE password = 'hunter2'" {
+		t.Error("the tab should keep the query as written")
+	}
+}
@@ -0,0 +1,155 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+// introspect loads the schema of conn: its databases, with the columns,
+// indexes and foreign keys of every table and the routines, sequences and
+// triggers of every schema. Problems with single objects come back as
+// warnings. When the database has more tables than lazyThreshold (0 for
+// no limit), only table names are loaded and lazy is set.
+func introspect(ctx context.Context, conn adapter.Connection, lazyThreshold int) (databases []schema.Database, warnings []string, lazy bool, err error) {
+	dbs, err := conn.Databases(ctx)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	// Huge schemas load only table names; the sidebar asks for a
+	// table's columns when it is expanded (LoadTableMsg).
+	tableCount := 0
+	for _, db := range dbs {
+		for _, s := range db.Schemas {
+			tableCount += len(s.Tables)
+		}
+	}
+	lazy = lazyThreshold > 0 && tableCount > lazyThreshold
+
+	// Load full schema for each database
+	objConn, hasObjects := conn.(adapter.ObjectIntrospector)
+
+	for _, db := range dbs {
+		for si := range db.Schemas {
+			s := &db.Schemas[si]
+			if !lazy {
+				warnings = append(warnings, loadSchemaTables(ctx, conn, db.Name, s)...)
+			}
+			if hasObjects {
+				var err error
+				if s.Routines, err = objConn.Routines(ctx, db.Name, s.Name); err != nil {
+					warnings = append(warnings, fmt.Sprintf("routines(%s): %v", s.Name, err))
+				}
+				if s.Sequences, err = objConn.Sequences(ctx, db.Name, s.Name); err != nil {
+					warnings = append(warnings, fmt.Sprintf("sequences(%s): %v", s.Name, err))
+				}
+				if s.Triggers, err = objConn.Triggers(ctx, db.Name, s.Name); err != nil {
+					warnings = append(warnings, fmt.Sprintf("triggers(%s): %v", s.Name, err))
+				}
+			}
+		}
+		databases = append(databases, db)
+	}
+	return databases, warnings, lazy, nil
+}
+
+// loadSchemaTables fills in the columns, indexes and foreign keys of every
+// table of s, with three queries for the whole schema when conn has batch
+// introspection.
+func loadSchemaTables(ctx context.Context, conn adapter.Connection, dbName string, s *schema.Schema) []string {
+	batchConn, hasBatch := conn.(adapter.BatchIntrospector)
+	if !hasBatch || len(s.Tables) == 0 {
+		// Per-table fallback, several tables at a time
+		return loadTables(ctx, conn, dbName, s)
+	}
+	// Batch introspection: 3 queries per schema instead of 3*N
+	var warnings []string
+	allCols, err := batchConn.AllColumns(ctx, dbName, s.Name)
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("batch columns(%s): %v", s.Name, err))
+	}
+	allIdxs, err := batchConn.AllIndexes(ctx, dbName, s.Name)
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("batch indexes(%s): %v", s.Name, err))
+	}
+	allFKs, err := batchConn.AllForeignKeys(ctx, dbName, s.Name)
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("batch fkeys(%s): %v", s.Name, err))
+	}
+	for ti := range s.Tables {
+		t := &s.Tables[ti]
+		if cols, ok := allCols[t.Name]; ok {
+			t.Columns = cols
+		}
+		if idxs, ok := allIdxs[t.Name]; ok {
+			t.Indexes = idxs
+		}
+		if fks, ok := allFKs[t.Name]; ok {
+			t.FKs = fks
+		}
+	}
+	return warnings
+}
+
+// introspectWorkers bounds how many tables loadTables introspects at once.
+// Enough to hide round trips to a remote server without flooding its pool.
+const introspectWorkers = 8
+
+// loadTables fills in the columns, indexes and foreign keys of every table
+// of s, one table per call, for connections without batch introspection.
+// Tables are loaded by up to introspectWorkers goroutines; the warnings
+// come back in table order regardless.
+func loadTables(ctx context.Context, conn adapter.Connection, dbName string, s *schema.Schema) []string {
+	tableWarnings := make([][]string, len(s.Tables))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(introspectWorkers, len(s.Tables)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ti := range next {
+				tableWarnings[ti] = loadTableDetails(ctx, conn, dbName, s.Name, &s.Tables[ti])
+			}
+		}()
+	}
+	for ti := range s.Tables {
+		next <- ti
+	}
+	close(next)
+	wg.Wait()
+
+	var warnings []string
+	for _, w := range tableWarnings {
+		warnings = append(warnings, w...)
+	}
+	return warnings
+}
+
+// loadTableDetails loads one table's columns, indexes and foreign keys into
+// t, returning a warning for each that failed.
+func loadTableDetails(ctx context.Context, conn adapter.Connection, dbName, schemaName string, t *schema.Table) []string {
+	var warnings []string
+	cols, err := conn.Columns(ctx, dbName, schemaName, t.Name)
+	if err == nil {
+		t.Columns = cols
+	} else {
+		warnings = append(warnings, fmt.Sprintf("columns(%s.%s): %v", schemaName, t.Name, err))
+	}
+	idxs, err := conn.Indexes(ctx, dbName, schemaName, t.Name)
+	if err == nil {
+		t.Indexes = idxs
+	} else {
+		warnings = append(warnings, fmt.Sprintf("indexes(%s.%s): %v", schemaName, t.Name, err))
+	}
+	fks, err := conn.ForeignKeys(ctx, dbName, schemaName, t.Name)
+	if err == nil {
+		t.FKs = fks
+	} else {
+		warnings = append(warnings, fmt.Sprintf("fkeys(%s.%s): %v", schemaName, t.Name, err))
+	}
+	return warnings
+}
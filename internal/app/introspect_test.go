@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+// introspectConn is a testConn whose Columns takes a while and records how
+// many calls overlapped.
+type introspectConn struct {
+	*testConn
+	mu        sync.Mutex
+	running   int
+	maxActive int
+}
+
+func (c *introspectConn) Columns(_ context.Context, _, _, table string) ([]schema.Column, error) {
+	c.mu.Lock()
+	c.running++
+	c.maxActive = max(c.maxActive, c.running)
+	c.mu.Unlock()
+	time.Sleep(5 * time.Millisecond)
+	c.mu.Lock()
+	c.running--
+	c.mu.Unlock()
+	if table == "t3" || table == "t7" {
+		return nil, errors.New("denied")
+	}
+	return []schema.Column{{Name: table + "_id"}}, nil
+}
+
+func TestLoadTables(t *testing.T) {
+	conn := &introspectConn{testConn: &testConn{dbName: "app"}}
+	s := &schema.Schema{Name: "main"}
+	for i := range 30 {
+		s.Tables = append(s.Tables, schema.Table{Name: fmt.Sprintf("t%d", i)})
+	}
+
+	warnings := loadTables(context.Background(), conn, "app", s)
+
+	if conn.maxActive < 2 || conn.maxActive > introspectWorkers {
+		t.Errorf("%d tables loaded at once, want 2..%d", conn.maxActive, introspectWorkers)
+	}
+	for _, tbl := range s.Tables {
+		if tbl.Name != "t3" && tbl.Name != "t7" && (len(tbl.Columns) != 1 || tbl.Columns[0].Name != tbl.Name+"_id") {
+			t.Errorf("%s columns = %v", tbl.Name, tbl.Columns)
+		}
+	}
+	want := []string{"columns(main.t3): denied", "columns(main.t7): denied"}
+	if fmt.Sprint(warnings) != fmt.Sprint(want) {
+		t.Errorf("warnings = %q, want %q in table order", warnings, want)
+	}
+}
@@ -1,6 +1,10 @@
 package app
 
-import "github.com/charmbracelet/bubbles/key"
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
 
 // KeyMap defines all application keybindings.
 type KeyMap struct {
@@ -11,25 +15,53 @@ type KeyMap struct {
 	FocusEditor  key.Binding
 	FocusResults key.Binding
 
+	// GoToDefinition shows the CREATE statement of the table under the
+	// cursor. Like RefreshSchema's g r, it is a two-key chord: keys with
+	// a space are chords, read while the focused pane takes no text.
+	GoToDefinition key.Binding
+
 	// Tabs
 	NewTab   key.Binding
 	CloseTab key.Binding
 	NextTab  key.Binding
 	PrevTab  key.Binding
 
+	// SplitEditor shows a second tab's editor next to the active one;
+	// OtherSplit moves between the two.
+	SplitEditor key.Binding
+	OtherSplit  key.Binding
+
 	// Editor
-	ExecuteQuery key.Binding
-	CancelQuery  key.Binding
+	ExecuteQuery     key.Binding
+	ExecuteNoTimeout key.Binding
+	ExecuteStatement key.Binding
+	CancelQuery      key.Binding
+	ExplainAnalyze   key.Binding
+
+	// ToggleAutoCommit turns autocommit off, leaving statements in a
+	// transaction until Commit or Rollback ends it, and back on.
+	ToggleAutoCommit key.Binding
+	Commit           key.Binding
+	Rollback         key.Binding
 
 	// App
-	Quit          key.Binding
-	Help          key.Binding
-	ToggleKeyMode key.Binding
-	ToggleSidebar key.Binding
-	RefreshSchema key.Binding
-	OpenConnMgr   key.Binding
-	History       key.Binding
-	Export        key.Binding
+	Quit           key.Binding
+	Help           key.Binding
+	ToggleKeyMode  key.Binding
+	ToggleSafeMode key.Binding
+	ToggleSidebar  key.Binding
+	ToggleZen      key.Binding
+	ToggleLayout   key.Binding
+	RefreshSchema  key.Binding
+	OpenConnMgr    key.Binding
+	History        key.Binding
+	ScheduleLog    key.Binding
+	Listen         key.Binding
+	Activity       key.Binding
+	SchemaDiff     key.Binding
+	ERDiagram      key.Binding
+	FindObject     key.Binding
+	Export         key.Binding
 
 	// Pane resizing
 	ResizeLeft  key.Binding
@@ -50,6 +82,12 @@ type KeyMap struct {
 	VimSearch key.Binding
 	VimVisual key.Binding
 	VimYank   key.Binding
+
+	// Sequences are the multi-key commands, each bound to its keys joined
+	// by spaces ("d i w") with the last key as its help key, including one
+	// for each prefix that needs more keys ("d i"). A {char} key stands for
+	// any character. The which-key hints list them.
+	Sequences []key.Binding
 }
 
 // StandardKeyMap returns keybindings for standard mode.
@@ -91,14 +129,42 @@ func StandardKeyMap() KeyMap {
 			key.WithKeys("ctrl+pgup", "ctrl+["),
 			key.WithHelp("ctrl+pgup", "prev tab"),
 		),
+		SplitEditor: key.NewBinding(
+			key.WithKeys("alt+s"),
+			key.WithHelp("alt+s", "split editor"),
+		),
+		OtherSplit: key.NewBinding(
+			key.WithKeys("alt+w"),
+			key.WithHelp("alt+w", "other split"),
+		),
 		ExecuteQuery: key.NewBinding(
 			key.WithKeys("ctrl+enter", "f5", "ctrl+g"),
 			key.WithHelp("ctrl+enter", "run query"),
 		),
+		ExecuteNoTimeout: key.NewBinding(
+			key.WithKeys("alt+enter"),
+			key.WithHelp("alt+enter", "run without timeout"),
+		),
+		ExecuteStatement: key.NewBinding(
+			key.WithKeys("f9"),
+			key.WithHelp("f9", "run statement under cursor"),
+		),
 		CancelQuery: key.NewBinding(
 			key.WithKeys("ctrl+c"),
 			key.WithHelp("ctrl+c", "cancel query"),
 		),
+		ExplainAnalyze: key.NewBinding(
+			key.WithKeys("f8"),
+			key.WithHelp("f8", "explain analyze"),
+		),
+		Commit: key.NewBinding(
+			key.WithKeys("f6"),
+			key.WithHelp("f6", "commit"),
+		),
+		Rollback: key.NewBinding(
+			key.WithKeys("f7"),
+			key.WithHelp("f7", "rollback"),
+		),
 		Quit: key.NewBinding(
 			key.WithKeys("ctrl+q"),
 			key.WithHelp("ctrl+q", "quit"),
@@ -111,12 +177,32 @@ func StandardKeyMap() KeyMap {
 			key.WithKeys("f2"),
 			key.WithHelp("f2", "vim/standard"),
 		),
+		ToggleSafeMode: key.NewBinding(
+			key.WithKeys("f3"),
+			key.WithHelp("f3", "safe mode"),
+		),
+		ToggleAutoCommit: key.NewBinding(
+			key.WithKeys("f4"),
+			key.WithHelp("f4", "autocommit"),
+		),
 		ToggleSidebar: key.NewBinding(
 			key.WithKeys("ctrl+b"),
 			key.WithHelp("ctrl+b", "toggle sidebar"),
 		),
+		ToggleZen: key.NewBinding(
+			key.WithKeys("alt+z"),
+			key.WithHelp("alt+z", "zen mode"),
+		),
+		ToggleLayout: key.NewBinding(
+			key.WithKeys("alt+l"),
+			key.WithHelp("alt+l", "results beside/below"),
+		),
+		GoToDefinition: key.NewBinding(
+			key.WithKeys("g d"),
+			key.WithHelp("g d", "go to definition"),
+		),
 		RefreshSchema: key.NewBinding(
-			key.WithKeys("ctrl+r"),
+			key.WithKeys("ctrl+r", "g r"),
 			key.WithHelp("ctrl+r", "refresh schema"),
 		),
 		OpenConnMgr: key.NewBinding(
@@ -127,6 +213,30 @@ func StandardKeyMap() KeyMap {
 			key.WithKeys("ctrl+h"),
 			key.WithHelp("ctrl+h", "history"),
 		),
+		ScheduleLog: key.NewBinding(
+			key.WithKeys("alt+j"),
+			key.WithHelp("alt+j", "scheduled runs"),
+		),
+		Listen: key.NewBinding(
+			key.WithKeys("alt+n"),
+			key.WithHelp("alt+n", "listen/notify"),
+		),
+		Activity: key.NewBinding(
+			key.WithKeys("alt+a"),
+			key.WithHelp("alt+a", "sessions"),
+		),
+		SchemaDiff: key.NewBinding(
+			key.WithKeys("alt+d"),
+			key.WithHelp("alt+d", "compare schemas"),
+		),
+		ERDiagram: key.NewBinding(
+			key.WithKeys("alt+e"),
+			key.WithHelp("alt+e", "er diagram"),
+		),
+		FindObject: key.NewBinding(
+			key.WithKeys("alt+o"),
+			key.WithHelp("alt+o", "find object"),
+		),
 		Export: key.NewBinding(
 			key.WithKeys("ctrl+e"),
 			key.WithHelp("ctrl+e", "export"),
@@ -203,9 +313,115 @@ func VimKeyMap() KeyMap {
 		key.WithHelp("y", "yank"),
 	)
 
+	km.Sequences = vimSequences()
+
 	return km
 }
 
+// vimSequences returns the multi-key commands of the editor's vim normal
+// and visual modes.
+func vimSequences() []key.Binding {
+	var seqs []key.Binding
+	add := func(keys, desc string) {
+		fields := strings.Fields(keys)
+		seqs = append(seqs, key.NewBinding(
+			key.WithKeys(keys),
+			key.WithHelp(fields[len(fields)-1], desc),
+		))
+	}
+
+	objects := []struct{ key, desc string }{
+		{"w", "word"}, {"W", "WORD"}, {"'", "'quoted'"}, {`"`, `"quoted"`},
+		{"`", "`quoted`"}, {"(", "(parentheses)"}, {"b", "(parentheses)"},
+		{"[", "[brackets]"}, {"{", "{braces}"}, {"B", "{braces}"},
+		{"p", "paragraph"},
+	}
+	addObjects := func(prefix string) {
+		add(prefix+"i", "inside object")
+		add(prefix+"a", "around object")
+		for _, o := range objects {
+			add(prefix+"i "+o.key, "inside "+o.desc)
+			add(prefix+"a "+o.key, "around "+o.desc)
+		}
+	}
+
+	add("g", "go to")
+	add("g g", "first line")
+	for _, op := range []struct{ key, verb string }{{"d", "delete"}, {"c", "change"}, {"y", "yank"}} {
+		p := op.key + " "
+		add(op.key, op.verb)
+		add(p+op.key, op.verb+" line")
+		add(p+"w", "to next word")
+		add(p+"e", "to end of word")
+		add(p+"b", "to previous word")
+		add(p+"j", "line and the one below")
+		add(p+"k", "line and the one above")
+		add(p+"0", "to start of line")
+		add(p+"^", "to first non-blank")
+		add(p+"$", "to end of line")
+		add(p+"G", "to last line")
+		add(p+"g", "go to")
+		add(p+"g g", "to first line")
+		add(p+"}", "to end of paragraph")
+		add(p+"{", "to start of paragraph")
+		add(p+"%", "to matching bracket")
+		add(p+"f", "to character")
+		add(p+"f {char}", "to next {char}")
+		add(p+"t", "till character")
+		add(p+"t {char}", "till next {char}")
+		addObjects(p)
+	}
+	// In visual mode i and a select a text object.
+	addObjects("")
+
+	add("f", "find character")
+	add("f {char}", "next {char}")
+	add("F", "find character backward")
+	add("F {char}", "previous {char}")
+	add("t", "till character")
+	add("t {char}", "before next {char}")
+	add("T", "till character backward")
+	add("T {char}", "after previous {char}")
+	add("r", "replace character")
+	add("r {char}", "replace with {char}")
+	add(`"`, "register")
+	add(`" {char}`, "use register a-z, 0-9 or -")
+	add(`" "`, "unnamed register")
+	add(`" +`, "system clipboard")
+	add(`" _`, "black hole")
+	add("q", "record macro")
+	add("q {char}", "record into register a-z")
+	add("@", "run macro")
+	add("@ {char}", "run register a-z")
+	add("@ @", "run last macro again")
+	return seqs
+}
+
+// Chords returns the bindings with two-key chords.
+func (k KeyMap) Chords() []key.Binding {
+	return []key.Binding{k.GoToDefinition, k.RefreshSchema}
+}
+
+// Continuations returns the chords and sequences that take one key more
+// than the keys typed so far, so they list what can follow them. Each has
+// that key as its help key.
+func (k KeyMap) Continuations(typed []string) []key.Binding {
+	var next []key.Binding
+	for _, b := range append(k.Chords(), k.Sequences...) {
+		for _, keys := range b.Keys() {
+			fields := strings.Fields(keys)
+			if len(fields) == len(typed)+1 && strings.Join(fields[:len(typed)], " ") == strings.Join(typed, " ") {
+				next = append(next, key.NewBinding(
+					key.WithKeys(keys),
+					key.WithHelp(fields[len(typed)], b.Help().Desc),
+				))
+				break
+			}
+		}
+	}
+	return next
+}
+
 // ShortHelp returns a subset of keybindings for the short help view.
 func (k KeyMap) ShortHelp() []key.Binding {
 	return []key.Binding{
@@ -216,10 +432,10 @@ func (k KeyMap) ShortHelp() []key.Binding {
 // FullHelp returns all keybindings grouped for the full help view.
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.ExecuteQuery, k.CancelQuery, k.Export},
-		{k.FocusNext, k.FocusPrev, k.FocusSidebar, k.FocusEditor, k.FocusResults},
-		{k.NewTab, k.CloseTab, k.NextTab, k.PrevTab},
-		{k.ToggleKeyMode, k.ToggleSidebar, k.RefreshSchema, k.OpenConnMgr, k.History},
+		{k.ExecuteQuery, k.ExecuteNoTimeout, k.ExecuteStatement, k.CancelQuery, k.ExplainAnalyze, k.Commit, k.Rollback, k.Export},
+		{k.FocusNext, k.FocusPrev, k.FocusSidebar, k.FocusEditor, k.FocusResults, k.GoToDefinition},
+		{k.NewTab, k.CloseTab, k.NextTab, k.PrevTab, k.SplitEditor, k.OtherSplit},
+		{k.ToggleKeyMode, k.ToggleSafeMode, k.ToggleAutoCommit, k.ToggleSidebar, k.ToggleZen, k.ToggleLayout, k.RefreshSchema, k.OpenConnMgr, k.History, k.ScheduleLog, k.Listen, k.Activity},
 		{k.ResizeLeft, k.ResizeRight, k.ResizeUp, k.ResizeDown},
 		{k.Quit, k.Help},
 	}
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -427,21 +428,21 @@ func TestFullHelp_ContainsAllGroups(t *testing.T) {
 	km := StandardKeyMap()
 	full := km.FullHelp()
 
-	// Group 0: Editor actions (ExecuteQuery, CancelQuery, Export)
-	if len(full[0]) != 3 {
-		t.Errorf("FullHelp group 0 (editor) length = %d, want 3", len(full[0]))
+	// Group 0: Editor actions (ExecuteQuery, ExecuteNoTimeout, ExecuteStatement, CancelQuery, ExplainAnalyze, Commit, Rollback, Export)
+	if len(full[0]) != 8 {
+		t.Errorf("FullHelp group 0 (editor) length = %d, want 8", len(full[0]))
 	}
-	// Group 1: Navigation (FocusNext, FocusPrev, FocusSidebar, FocusEditor, FocusResults)
-	if len(full[1]) != 5 {
-		t.Errorf("FullHelp group 1 (navigation) length = %d, want 5", len(full[1]))
+	// Group 1: Navigation (FocusNext, FocusPrev, FocusSidebar, FocusEditor, FocusResults, GoToDefinition)
+	if len(full[1]) != 6 {
+		t.Errorf("FullHelp group 1 (navigation) length = %d, want 6", len(full[1]))
 	}
-	// Group 2: Tabs (NewTab, CloseTab, NextTab, PrevTab)
-	if len(full[2]) != 4 {
-		t.Errorf("FullHelp group 2 (tabs) length = %d, want 4", len(full[2]))
+	// Group 2: Tabs (NewTab, CloseTab, NextTab, PrevTab, SplitEditor, OtherSplit)
+	if len(full[2]) != 6 {
+		t.Errorf("FullHelp group 2 (tabs) length = %d, want 6", len(full[2]))
 	}
-	// Group 3: App (ToggleKeyMode, ToggleSidebar, RefreshSchema, OpenConnMgr, History)
-	if len(full[3]) != 5 {
-		t.Errorf("FullHelp group 3 (app) length = %d, want 5", len(full[3]))
+	// Group 3: App (ToggleKeyMode, ToggleSafeMode, ToggleAutoCommit, ToggleSidebar, ToggleZen, ToggleLayout, RefreshSchema, OpenConnMgr, History, ScheduleLog, Listen, Activity)
+	if len(full[3]) != 12 {
+		t.Errorf("FullHelp group 3 (app) length = %d, want 12", len(full[3]))
 	}
 	// Group 4: Resize (ResizeLeft, ResizeRight, ResizeUp, ResizeDown)
 	if len(full[4]) != 4 {
@@ -476,10 +477,26 @@ func TestStandardKeyMap_SpecificKeyValues(t *testing.T) {
 		{"Help", km.Help, "f1"},
 		{"ToggleKeyMode", km.ToggleKeyMode, "f2"},
 		{"ToggleSidebar", km.ToggleSidebar, "ctrl+b"},
+		{"ToggleZen", km.ToggleZen, "alt+z"},
+		{"ToggleLayout", km.ToggleLayout, "alt+l"},
+		{"SplitEditor", km.SplitEditor, "alt+s"},
+		{"OtherSplit", km.OtherSplit, "alt+w"},
+		{"ScheduleLog", km.ScheduleLog, "alt+j"},
+		{"Listen", km.Listen, "alt+n"},
+		{"Activity", km.Activity, "alt+a"},
+		{"SchemaDiff", km.SchemaDiff, "alt+d"},
+		{"ERDiagram", km.ERDiagram, "alt+e"},
+		{"FindObject", km.FindObject, "alt+o"},
 		{"RefreshSchema", km.RefreshSchema, "ctrl+r"},
 		{"OpenConnMgr", km.OpenConnMgr, "ctrl+o"},
 		{"Export", km.Export, "ctrl+e"},
 		{"CancelQuery", km.CancelQuery, "ctrl+c"},
+		{"ExecuteNoTimeout", km.ExecuteNoTimeout, "alt+enter"},
+		{"ExecuteStatement", km.ExecuteStatement, "f9"},
+		{"ExplainAnalyze", km.ExplainAnalyze, "f8"},
+		{"Commit", km.Commit, "f6"},
+		{"Rollback", km.Rollback, "f7"},
+		{"ToggleAutoCommit", km.ToggleAutoCommit, "f4"},
 		{"ResizeLeft", km.ResizeLeft, "ctrl+left"},
 		{"ResizeRight", km.ResizeRight, "ctrl+right"},
 		{"ResizeUp", km.ResizeUp, "ctrl+up"},
@@ -536,3 +553,50 @@ func TestStandardKeyMap_PrevTabMultipleKeys(t *testing.T) {
 		t.Errorf("PrevTab missing ctrl+[, keys = %v", keys)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Continuations
+// ---------------------------------------------------------------------------
+
+func TestKeyMap_Continuations(t *testing.T) {
+	km := VimKeyMap()
+	helpKeys := func(typed ...string) []string {
+		var keys []string
+		for _, b := range km.Continuations(typed) {
+			keys = append(keys, b.Help().Key)
+		}
+		return keys
+	}
+	has := func(keys []string, want string) bool {
+		for _, k := range keys {
+			if k == want {
+				return true
+			}
+		}
+		return false
+	}
+
+	if keys := helpKeys("g"); strings.Join(keys, " ") != "d r g" {
+		t.Errorf("after g: %v, want the chords g d and g r, then gg", keys)
+	}
+	for _, want := range []string{"d", "w", "$", "i", "a", "g"} {
+		if keys := helpKeys("d"); !has(keys, want) {
+			t.Errorf("after d: %v, want %q among them", keys, want)
+		}
+	}
+	if keys := helpKeys("c", "i"); !has(keys, "(") || has(keys, "c") {
+		t.Errorf("after ci: %v, want the text objects", keys)
+	}
+	if keys := helpKeys("@"); !has(keys, "@") || !has(keys, "{char}") {
+		t.Errorf("after @: %v, want @ and {char}", keys)
+	}
+	if keys := helpKeys("x"); len(keys) != 0 {
+		t.Errorf("x takes no more keys, got %v", keys)
+	}
+	if seqs := StandardKeyMap().Continuations([]string{"d"}); len(seqs) != 0 {
+		t.Errorf("standard mode has no sequences, got %d", len(seqs))
+	}
+	if seqs := StandardKeyMap().Continuations([]string{"g"}); len(seqs) != 2 {
+		t.Errorf("standard mode has the two chords, got %d", len(seqs))
+	}
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+// tableLoadTimeout bounds loading one table of a lazily loaded schema.
+const tableLoadTimeout = 30 * time.Second
+
+// loadTable loads the columns, indexes and foreign keys of a table the
+// sidebar expanded, for schemas too large to load up front. Only the
+// columns are required; indexes and foreign keys are best effort.
+func (m *Model) loadTable(msg LoadTableMsg) tea.Cmd {
+	if m.conn == nil {
+		return nil
+	}
+	conn := m.conn
+	gen := m.connGen
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), tableLoadTimeout)
+		defer cancel()
+
+		reply := TableLoadedMsg{Database: msg.Database, Schema: msg.Schema, Table: schema.Table{Name: msg.Table}, ConnGen: gen}
+		reply.Table.Columns, reply.Err = conn.Columns(ctx, msg.Database, msg.Schema, msg.Table)
+		if reply.Err != nil {
+			return reply
+		}
+		reply.Table.Indexes, _ = conn.Indexes(ctx, msg.Database, msg.Schema, msg.Table)
+		reply.Table.FKs, _ = conn.ForeignKeys(ctx, msg.Database, msg.Schema, msg.Table)
+		return reply
+	}
+}
+
+// handleTableLoaded records a table loaded on demand, so completion learns
+// its columns, and passes it to the sidebar.
+func (m *Model) handleTableLoaded(msg TableLoadedMsg) tea.Cmd {
+	if msg.ConnGen != m.connGen {
+		return nil
+	}
+	if msg.Err == nil && m.storeTable(msg) {
+		m.compEngine.UpdateSchema(m.databases)
+	}
+	var cmd tea.Cmd
+	m.sidebar, cmd = m.sidebar.Update(msg)
+	if msg.Err != nil {
+		text := "Loading " + msg.Table.Name + " failed: " + sanitizeError(msg.Err.Error())
+		return tea.Batch(cmd, func() tea.Msg { return StatusMsg{Text: text, IsError: true} })
+	}
+	return cmd
+}
+
+// storeTable puts a loaded table into m.databases. It reports whether the
+// table was found.
+func (m *Model) storeTable(msg TableLoadedMsg) bool {
+	for di := range m.databases {
+		db := &m.databases[di]
+		if db.Name != msg.Database {
+			continue
+		}
+		for si := range db.Schemas {
+			s := &db.Schemas[si]
+			if s.Name != msg.Schema {
+				continue
+			}
+			for ti := range s.Tables {
+				if s.Tables[ti].Name == msg.Table.Name {
+					s.Tables[ti] = msg.Table
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+func TestTableLoaded_UpdatesSchema(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	m.conn = &testConn{dbName: "big"}
+	m.databases = []schema.Database{{
+		Name:    "big",
+		Schemas: []schema.Schema{{Name: "public", Tables: []schema.Table{{Name: "users"}}}},
+	}}
+
+	cols := []schema.Column{{Name: "id", Type: "integer"}}
+	model, _ := m.Update(TableLoadedMsg{Database: "big", Schema: "public", Table: schema.Table{Name: "users", Columns: cols}, ConnGen: m.connGen})
+	m = model.(Model)
+	if got := m.databases[0].Schemas[0].Tables[0].Columns; len(got) != 1 || got[0].Name != "id" {
+		t.Errorf("columns = %v, want the loaded ones", got)
+	}
+
+	stale := TableLoadedMsg{Database: "big", Schema: "public", Table: schema.Table{Name: "users"}, ConnGen: m.connGen + 1}
+	model, _ = m.Update(stale)
+	m = model.(Model)
+	if got := m.databases[0].Schemas[0].Tables[0].Columns; len(got) != 1 {
+		t.Error("a table from an earlier connection should be ignored")
+	}
+}
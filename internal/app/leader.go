@@ -0,0 +1,171 @@
+package app
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+// leaderKeys reports whether the leader key starts a mapping: in vim mode,
+// when the focused pane is not taking text. In the editor that is normal
+// mode with no command half typed.
+func (m *Model) leaderKeys() bool {
+	if m.keyMode != KeyModeVim || len(m.cfg.Mappings) == 0 {
+		return false
+	}
+	if m.focusedPane == PaneEditor {
+		ts := m.activeTabState()
+		return ts != nil && ts.Editor.VimIdle()
+	}
+	return !m.textInputFocused()
+}
+
+// handleLeader reads the keys of a leader mapping, running it once they
+// are all typed. It reports whether it took the key.
+func (m *Model) handleLeader(msg tea.KeyMsg) (tea.Cmd, bool) {
+	k := msg.String()
+	if !m.leaderOn {
+		if k != m.cfg.LeaderKey() || !m.leaderKeys() {
+			return nil, false
+		}
+		m.leaderOn, m.leader = true, nil
+		return nil, true
+	}
+	if k == "esc" {
+		m.leaderOn, m.leader = false, nil
+		return nil, true
+	}
+
+	typed := append(append([]string(nil), m.leader...), k)
+	prefix := false
+	for _, mp := range m.cfg.Mappings {
+		seq := mp.Sequence()
+		switch {
+		case slices.Equal(seq, typed):
+			m.leaderOn, m.leader = false, nil
+			return m.runMapping(mp), true
+		case len(seq) > len(typed) && slices.Equal(seq[:len(typed)], typed):
+			prefix = true
+		}
+	}
+	if prefix {
+		m.leader = typed
+		return nil, true
+	}
+	m.leaderOn, m.leader = false, nil
+	text := "No mapping for <leader>" + strings.Join(typed, "")
+	return func() tea.Msg { return StatusMsg{Text: text, IsError: true} }, true
+}
+
+// runMapping runs the action of a leader mapping.
+func (m *Model) runMapping(mp config.Mapping) tea.Cmd {
+	switch mp.Action {
+	case config.ActionRunQuery:
+		return m.runSavedQuery(mp.Arg)
+	case config.ActionInsertSnippet:
+		ts := m.activeTabState()
+		if ts == nil {
+			return nil
+		}
+		ts.Editor.InsertAtCursor(mp.Arg)
+		m.setFocus(PaneEditor)
+		return nil
+	case config.ActionSwitchConnection:
+		sc := m.cfg.FindConnection(mp.Arg)
+		if sc == nil {
+			return exStatus("No saved connection named "+mp.Arg, true)
+		}
+		return m.switchConnection(*sc)
+	case config.ActionExport:
+		switch format := strings.ToLower(mp.Arg); format {
+		case "", "csv", "json":
+			return m.exportResults(format)
+		default:
+			return exStatus("Unknown export format: "+mp.Arg, true)
+		}
+	}
+	return exStatus("Unknown mapping action: "+mp.Action, true)
+}
+
+// runSavedQuery runs the query called name, or folder/name, from the query
+// library in the active tab.
+func (m *Model) runSavedQuery(name string) tea.Cmd {
+	ts := m.activeTabState()
+	if ts == nil {
+		return nil
+	}
+	queries, _, err := m.loadLibrary()
+	if err != nil {
+		return exStatus("Failed to load query library: "+err.Error(), true)
+	}
+	for _, q := range queries {
+		if q.Path() == name || q.Name == name {
+			tabID, query := m.tabs.ActiveID(), q.SQL
+			return func() tea.Msg { return ExecuteQueryMsg{Query: query, TabID: tabID, Lint: true} }
+		}
+	}
+	return exStatus("No saved query named "+name, true)
+}
+
+// leaderSequences returns the which-key sequences of the leader mappings,
+// with one for each prefix some mappings share.
+func leaderSequences(mappings []config.Mapping) []key.Binding {
+	var seqs []key.Binding
+	seen := map[string]bool{}
+	add := func(keys []string, desc string) {
+		joined := strings.Join(keys, " ")
+		if seen[joined] {
+			return
+		}
+		seen[joined] = true
+		seqs = append(seqs, key.NewBinding(
+			key.WithKeys(joined),
+			key.WithHelp(keys[len(keys)-1], desc),
+		))
+	}
+	for _, mp := range mappings {
+		keys := []string{"<leader>"}
+		for _, k := range mp.Sequence() {
+			keys = append(keys, hintKeyName(k))
+		}
+		if len(keys) == 1 {
+			continue
+		}
+		add(keys, mappingDesc(mp))
+		for i := len(keys) - 1; i > 1; i-- {
+			add(keys[:i], "more mappings")
+		}
+	}
+	return seqs
+}
+
+// hintKeyName names a key the way the key hints show it, which must not
+// contain spaces.
+func hintKeyName(k string) string {
+	if k == " " {
+		return "space"
+	}
+	return k
+}
+
+// mappingDesc describes a leader mapping for the key hints.
+func mappingDesc(mp config.Mapping) string {
+	if mp.Desc != "" {
+		return mp.Desc
+	}
+	switch mp.Action {
+	case config.ActionRunQuery:
+		return "run " + mp.Arg
+	case config.ActionInsertSnippet:
+		return "insert snippet"
+	case config.ActionSwitchConnection:
+		return "connect to " + mp.Arg
+	case config.ActionExport:
+		return "export " + strings.ToLower(cmp.Or(mp.Arg, "csv"))
+	}
+	return mp.Action
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/library"
+)
+
+func leaderModel(t *testing.T) Model {
+	t.Helper()
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpHome, ".config"))
+
+	cfg := config.DefaultConfig()
+	cfg.KeyMode = "vim"
+	cfg.Leader = "<space>"
+	cfg.Mappings = []config.Mapping{
+		{Keys: "<leader>is", Action: config.ActionInsertSnippet, Arg: "now()", Desc: "insert now()"},
+		{Keys: "<leader>pc", Action: config.ActionRunQuery, Arg: "reports/page count"},
+		{Keys: "<leader>x", Action: config.ActionExport, Arg: "json"},
+		{Keys: "<leader>cp", Action: config.ActionSwitchConnection, Arg: "prod"},
+	}
+	m := New(cfg, nil, nil)
+	m.conn = &testConn{dbName: "app"}
+	return m
+}
+
+func TestLeader_Mappings(t *testing.T) {
+	m := leaderModel(t)
+	ed := &m.tabStates[m.tabs.ActiveID()].Editor
+	ed.SetValue("SELECT  FROM t")
+	ed.SetCursor(0, 7)
+	press := func(s string) tea.Cmd {
+		model, cmd := m.Update(keyMsgFromString(s))
+		m = model.(Model)
+		ed = &m.tabStates[m.tabs.ActiveID()].Editor
+		return cmd
+	}
+
+	press(" ")
+	press("i")
+	if hints := m.whichKey(80, 20); !strings.Contains(hints, "<leader> i …") || !strings.Contains(hints, "insert now()") {
+		t.Errorf("hints after <leader>i:\n%s", hints)
+	}
+	press("s")
+	if got := ed.Value(); got != "SELECT now() FROM t" {
+		t.Errorf("after <leader>is: Value() = %q, want %q", got, "SELECT now() FROM t")
+	}
+	if m.leaderOn || m.whichKey(80, 20) != "" {
+		t.Error("the mapping should be done")
+	}
+	press("u")
+	if got := ed.Value(); got != "SELECT  FROM t" {
+		t.Errorf("u should take the snippet back: Value() = %q", got)
+	}
+
+	// Export and switch_connection report what went wrong.
+	press(" ")
+	if msg, ok := press("x")().(ExportErrMsg); !ok {
+		t.Errorf("<leader>x with no results sent %#v, want an ExportErrMsg", msg)
+	}
+	press(" ")
+	press("c")
+	if msg := press("p")(); !strings.Contains(msg.(StatusMsg).Text, "No saved connection named prod") {
+		t.Errorf("<leader>cp sent %#v", msg)
+	}
+
+	// Keys no mapping starts with are an error; esc drops the leader.
+	press(" ")
+	if msg := press("z")(); !msg.(StatusMsg).IsError {
+		t.Errorf("<leader>z sent %#v", msg)
+	}
+	press(" ")
+	press("esc")
+	press("x")
+	if got := ed.Value(); got != "SELECT FROM t" {
+		t.Errorf("x after an abandoned leader should delete: Value() = %q", got)
+	}
+}
+
+func TestLeader_RunSavedQuery(t *testing.T) {
+	m := leaderModel(t)
+	if err := library.SaveDefault([]library.Query{{Name: "page count", Folder: "reports", SQL: "SELECT 42"}}); err != nil {
+		t.Fatal(err)
+	}
+	var cmd tea.Cmd
+	for _, k := range []string{" ", "p", "c"} {
+		model, c := m.Update(keyMsgFromString(k))
+		m, cmd = model.(Model), c
+	}
+	msg, ok := cmd().(ExecuteQueryMsg)
+	if !ok || msg.Query != "SELECT 42" || msg.TabID != m.tabs.ActiveID() {
+		t.Errorf("<leader>pc sent %#v, want the saved query run", msg)
+	}
+}
+
+func TestLeader_OnlyInVimNormalMode(t *testing.T) {
+	m := leaderModel(t)
+	ed := &m.tabStates[m.tabs.ActiveID()].Editor
+	ed.SetValue("")
+	for _, k := range []string{"i", " ", "i", "s"} {
+		model, _ := m.Update(keyMsgFromString(k))
+		m = model.(Model)
+	}
+	if got := m.tabStates[m.tabs.ActiveID()].Editor.Value(); got != " is" {
+		t.Errorf("in insert mode the leader is typed: Value() = %q", got)
+	}
+}
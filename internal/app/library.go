@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/library"
+	"github.com/sadopc/gotermsql/internal/ui/querylib"
+)
+
+// librarySyncTimeout bounds pulling the shared libraries.
+const librarySyncTimeout = time.Minute
+
+// librarySyncedMsg reports that the shared libraries were pulled.
+type librarySyncedMsg struct {
+	err error
+}
+
+// loadLibrary reads the personal query library and the shared ones. A
+// shared library that cannot be read is left out and reported in warning.
+func (m *Model) loadLibrary() (queries []library.Query, warning string, err error) {
+	queries, err = library.LoadDefault()
+	if err != nil {
+		return nil, "", err
+	}
+	var problems []string
+	for _, lib := range m.cfg.Library.Shared {
+		shared, err := library.LoadShared(lib)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		queries = append(queries, shared...)
+	}
+	return queries, strings.Join(problems, "; "), nil
+}
+
+// openLibrary shows the saved query library, with the shared libraries,
+// offering to save the active editor's query.
+func (m *Model) openLibrary() tea.Cmd {
+	queries, warning, err := m.loadLibrary()
+	if err != nil {
+		text := "Failed to load query library: " + err.Error()
+		return func() tea.Msg { return StatusMsg{Text: text, IsError: true} }
+	}
+	editorSQL := ""
+	if ts := m.activeTabState(); ts != nil {
+		editorSQL = ts.Editor.Value()
+	}
+	m.queryLib.Show(queries, editorSQL)
+	if warning != "" {
+		m.queryLib.SetMessage(warning, false)
+	}
+	return nil
+}
+
+// syncLibrary pulls the shared libraries kept in git.
+func (m *Model) syncLibrary() tea.Cmd {
+	shared := m.cfg.Library.Shared
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), librarySyncTimeout)
+		defer cancel()
+		return librarySyncedMsg{err: library.Sync(ctx, shared)}
+	}
+}
+
+// handleLibrarySynced reloads the library after a sync, picking up any
+// schedules the shared libraries changed.
+func (m *Model) handleLibrarySynced(msg librarySyncedMsg) tea.Cmd {
+	queries, warning, err := m.loadLibrary()
+	var cmd tea.Cmd
+	if err == nil {
+		m.queryLib.SetQueries(queries)
+		cmd = m.setSchedules(queries)
+	}
+	switch {
+	case msg.err != nil:
+		m.queryLib.SetMessage(msg.err.Error(), false)
+	case err != nil:
+		m.queryLib.SetMessage("Failed to load query library: "+err.Error(), false)
+	case warning != "":
+		m.queryLib.SetMessage(warning, false)
+	case len(m.cfg.Library.Shared) == 0:
+		m.queryLib.SetMessage("No shared libraries are configured", true)
+	default:
+		m.queryLib.SetMessage("Shared libraries are up to date", true)
+	}
+	return cmd
+}
+
+// insertLibraryQuery adds a query from the library to the active editor,
+// below any query already there.
+func (m *Model) insertLibraryQuery(msg querylib.InsertMsg) {
+	ts := m.activeTabState()
+	if ts == nil {
+		return
+	}
+	current := strings.TrimRight(ts.Editor.Value(), " \t\n")
+	if current == "" {
+		ts.Editor.SetValue(msg.SQL)
+	} else {
+		ts.Editor.SetValue(current + "\n\n" + msg.SQL)
+	}
+	m.setFocus(PaneEditor)
+}
+
+// saveLibrary writes the query library file.
+func (m *Model) saveLibrary(msg querylib.LibraryUpdatedMsg) tea.Cmd {
+	if err := library.SaveDefault(msg.Queries); err != nil {
+		text := "Failed to save query library: " + err.Error()
+		return func() tea.Msg { return StatusMsg{Text: text, IsError: true} }
+	}
+	text := msg.Notice
+	status := func() tea.Msg { return StatusMsg{Text: text} }
+	// The update holds only the personal library; the shared ones keep
+	// their schedules.
+	if queries, _, err := m.loadLibrary(); err == nil {
+		return tea.Batch(status, m.setSchedules(queries))
+	}
+	return status
+}
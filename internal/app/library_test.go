@@ -0,0 +1,111 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/library"
+	"github.com/sadopc/gotermsql/internal/ui/querylib"
+)
+
+func TestLibrary_SaveAndInsert(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpHome, ".config"))
+
+	m := New(config.DefaultConfig(), nil, nil)
+	m.activeTabState().Editor.SetValue("SELECT count(*) FROM users")
+
+	press := func(msg tea.KeyMsg) tea.Cmd {
+		model, cmd := m.Update(msg)
+		m = model.(Model)
+		return cmd
+	}
+	typeText := func(s string) {
+		press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)})
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyCtrlL})
+	if !m.queryLib.Visible() {
+		t.Fatal("expected Ctrl+L to open the query library")
+	}
+	press(tea.KeyMsg{Type: tea.KeyCtrlS})
+	typeText("user count")
+	press(tea.KeyMsg{Type: tea.KeyTab})
+	typeText("stats")
+	cmd := press(tea.KeyMsg{Type: tea.KeyEnter})
+
+	var updated *querylib.LibraryUpdatedMsg
+	for _, msg := range drainBatch(cmd) {
+		if u, ok := msg.(querylib.LibraryUpdatedMsg); ok {
+			updated = &u
+		}
+	}
+	if updated == nil {
+		t.Fatal("expected a LibraryUpdatedMsg after saving")
+	}
+	model, _ := m.Update(*updated)
+	m = model.(Model)
+
+	saved, err := library.LoadDefault()
+	if err != nil {
+		t.Fatalf("LoadDefault: %v", err)
+	}
+	if len(saved) != 1 || saved[0].Path() != "stats/user count" || saved[0].SQL != "SELECT count(*) FROM users" {
+		t.Fatalf("saved library = %+v", saved)
+	}
+
+	// Picking the query adds it below the editor's contents.
+	m.activeTabState().Editor.SetValue("SELECT 1;")
+	cmd = press(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.queryLib.Visible() {
+		t.Fatal("expected the library to close after picking a query")
+	}
+	model, _ = m.Update(cmd())
+	m = model.(Model)
+	if got, want := m.activeTabState().Editor.Value(), "SELECT 1;\n\nSELECT count(*) FROM users"; got != want {
+		t.Errorf("editor = %q, want %q", got, want)
+	}
+}
+
+func TestLibrary_Shared(t *testing.T) {
+	tmpHome := t.TempDir()
+	t.Setenv("HOME", tmpHome)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpHome, ".config"))
+	sharedPath := filepath.Join(tmpHome, "team.yaml")
+	if err := library.Save(sharedPath, []library.Query{{Name: "orders", SQL: "SELECT 1"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Library.Shared = []config.SharedLibrary{
+		{Name: "team", Path: sharedPath},
+		{Name: "gone", Path: filepath.Join(tmpHome, "missing")},
+	}
+	m := New(cfg, nil, nil)
+	if cmd := m.openLibrary(); cmd != nil {
+		t.Fatalf("a missing shared library should not stop the library opening: %+v", cmd())
+	}
+	if !m.queryLib.Visible() {
+		t.Fatal("expected the library to open")
+	}
+	view := m.queryLib.View()
+	if !strings.Contains(view, "[team]") || !strings.Contains(view, "shared library gone") {
+		t.Errorf("expected the team queries and a warning about gone:\n%s", view)
+	}
+
+	// After a sync the library is reloaded.
+	if err := library.Save(sharedPath, []library.Query{{Name: "orders", SQL: "SELECT 1"}, {Name: "refunds", SQL: "SELECT 2"}}); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Library.Shared = cfg.Library.Shared[:1]
+	model, _ := m.Update(librarySyncedMsg{})
+	m = model.(Model)
+	view = m.queryLib.View()
+	if !strings.Contains(view, "refunds") || !strings.Contains(view, "Shared libraries are up to date") {
+		t.Errorf("expected the reloaded library:\n%s", view)
+	}
+}
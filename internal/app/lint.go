@@ -0,0 +1,38 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+// lint returns the warnings for query from the lint rules turned on in
+// the config. They do not stop the query from running.
+func (m *Model) lint(query string) []string {
+	if !m.cfg.Lint.Enabled {
+		return nil
+	}
+	dialect := ""
+	if m.conn != nil {
+		dialect = m.conn.AdapterName()
+	}
+	var warnings []string
+	for _, w := range adapter.Lint(dialect, query) {
+		if m.cfg.Lint.RuleEnabled(w.Rule) {
+			warnings = append(warnings, w.Message)
+		}
+	}
+	return warnings
+}
+
+// lintBanner renders the tab's lint warnings as a line above its results,
+// or "" if there are none.
+func lintBanner(ts *TabState, width int) string {
+	if len(ts.Lint) == 0 {
+		return ""
+	}
+	text := runewidth.Truncate(" ⚠ "+strings.Join(ts.Lint, " · "), width, "…")
+	return theme.Current.WarningText.Render(text)
+}
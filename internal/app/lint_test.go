@@ -0,0 +1,43 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+func TestLint_BannerAboveResults(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Lint.Rules = map[string]bool{"select_star": false}
+	m := New(cfg, nil, nil)
+	m.conn = &testConn{dbName: "app"}
+	m.width, m.height = 120, 40
+	tabID := m.tabs.ActiveID()
+	ts := m.tabStates[tabID]
+
+	model, _ := m.Update(ExecuteQueryMsg{Query: "DELETE FROM users", TabID: tabID, Lint: true})
+	m = model.(Model)
+	if len(ts.Lint) != 1 || !strings.Contains(ts.Lint[0], "DELETE without WHERE") {
+		t.Fatalf("Lint = %q, want the missing WHERE warning", ts.Lint)
+	}
+	if ts.RunID == 0 {
+		t.Error("a lint warning should not stop the query")
+	}
+	if !strings.Contains(m.View(), "⚠ DELETE without WHERE") {
+		t.Error("view should show the warning banner")
+	}
+
+	// Turned off rules are not reported.
+	m.Update(ExecuteQueryMsg{Query: "SELECT * FROM users", TabID: tabID, Lint: true})
+	if len(ts.Lint) != 0 {
+		t.Errorf("Lint = %q, want select_star turned off", ts.Lint)
+	}
+
+	// Queries the app runs itself are not linted, and clear the banner.
+	m.Update(ExecuteQueryMsg{Query: "DELETE FROM users", TabID: tabID, Lint: true})
+	m.Update(ExecuteQueryMsg{Query: "UPDATE users SET a = 1", TabID: tabID})
+	if len(ts.Lint) != 0 {
+		t.Errorf("Lint = %q, want none", ts.Lint)
+	}
+}
@@ -0,0 +1,214 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/ui/listen"
+)
+
+// listenTimeout bounds opening the listening session, LISTEN, UNLISTEN and
+// sending a notification.
+const listenTimeout = 30 * time.Second
+
+// listenerOpenedMsg reports the listening session opened for connection
+// generation connGen.
+type listenerOpenedMsg struct {
+	listener adapter.Listener
+	err      error
+	connGen  uint64
+}
+
+// listenedMsg reports a LISTEN or UNLISTEN.
+type listenedMsg struct {
+	listen.ListenMsg
+	err     error
+	connGen uint64
+}
+
+// notificationMsg carries a notification received, or why waiting for one
+// stopped.
+type notificationMsg struct {
+	n       adapter.Notification
+	err     error
+	connGen uint64
+}
+
+// notifySentMsg reports a notification sent.
+type notifySentMsg struct {
+	channel string
+	err     error
+}
+
+// notifier returns the connection if it can LISTEN and NOTIFY, or nil.
+func (m *Model) notifier() adapter.Notifier {
+	n, _ := m.conn.(adapter.Notifier)
+	return n
+}
+
+// openListen opens the LISTEN/NOTIFY panel (Alt+N).
+func (m *Model) openListen() tea.Cmd {
+	if m.notifier() == nil {
+		return m.toast(ToastError, m.listenUnavailable())
+	}
+	m.listen.Show()
+	return nil
+}
+
+// listenUnavailable explains why the connection cannot LISTEN.
+func (m *Model) listenUnavailable() string {
+	if m.conn == nil {
+		return "Not connected"
+	}
+	return "LISTEN/NOTIFY is not available for " + m.conn.AdapterName()
+}
+
+// handleListen listens on a channel typed in the panel, or stops. The
+// listening session is opened on first use; channels asked for while it
+// opens are listened on once it is.
+func (m *Model) handleListen(msg listen.ListenMsg) tea.Cmd {
+	notifier := m.notifier()
+	if notifier == nil {
+		m.listen.SetMessage(m.listenUnavailable(), false)
+		return nil
+	}
+	if m.listener != nil {
+		return listenOn(m.listener, msg, m.connGen)
+	}
+	if msg.Unlisten {
+		return nil
+	}
+	m.listenPending = append(m.listenPending, msg.Channel)
+	if len(m.listenPending) > 1 {
+		return nil // already opening
+	}
+	gen := m.connGen
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), listenTimeout)
+		defer cancel()
+		l, err := notifier.Listen(ctx)
+		return listenerOpenedMsg{listener: l, err: err, connGen: gen}
+	}
+}
+
+// listenOn runs LISTEN or UNLISTEN on l.
+func listenOn(l adapter.Listener, msg listen.ListenMsg, gen uint64) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), listenTimeout)
+		defer cancel()
+		var err error
+		if msg.Unlisten {
+			err = l.Unlisten(ctx, msg.Channel)
+		} else {
+			err = l.Listen(ctx, msg.Channel)
+		}
+		return listenedMsg{ListenMsg: msg, err: err, connGen: gen}
+	}
+}
+
+// waitNotification waits for the next notification on l.
+func waitNotification(l adapter.Listener, gen uint64) tea.Cmd {
+	return func() tea.Msg {
+		n, err := l.Wait(context.Background())
+		return notificationMsg{n: n, err: err, connGen: gen}
+	}
+}
+
+// handleListenerOpened listens on the channels asked for while the session
+// opened, and starts waiting for notifications.
+func (m *Model) handleListenerOpened(msg listenerOpenedMsg) tea.Cmd {
+	if msg.connGen != m.connGen {
+		if msg.listener != nil {
+			go msg.listener.Close()
+		}
+		return nil
+	}
+	pending := m.listenPending
+	m.listenPending = nil
+	if msg.err != nil {
+		m.listen.SetMessage("Could not listen: "+sanitizeError(msg.err.Error()), false)
+		return nil
+	}
+	m.listener = msg.listener
+	cmds := []tea.Cmd{waitNotification(m.listener, m.connGen)}
+	for _, ch := range pending {
+		cmds = append(cmds, listenOn(m.listener, listen.ListenMsg{Channel: ch}, m.connGen))
+	}
+	return tea.Batch(cmds...)
+}
+
+// handleListened shows the channels listened on after a LISTEN or
+// UNLISTEN.
+func (m *Model) handleListened(msg listenedMsg) {
+	if msg.connGen != m.connGen {
+		return
+	}
+	if msg.err != nil {
+		m.listen.SetMessage(sanitizeError(msg.err.Error()), false)
+		return
+	}
+	m.listen.SetListening(msg.Channel, !msg.Unlisten)
+}
+
+// handleNotification adds a notification to the panel and waits for the
+// next one. When the session fails, listening stops.
+func (m *Model) handleNotification(msg notificationMsg) tea.Cmd {
+	if msg.connGen != m.connGen || m.listener == nil {
+		return nil
+	}
+	if msg.err != nil {
+		if errors.Is(msg.err, adapter.ErrListenerClosed) {
+			return nil
+		}
+		m.closeListener()
+		return m.toast(ToastError, "Stopped listening: "+sanitizeError(msg.err.Error()))
+	}
+	m.listen.Receive(msg.n)
+	return waitNotification(m.listener, m.connGen)
+}
+
+// handleNotify sends a notification typed in the panel. Safe mode blocks
+// it, since listeners act on it.
+func (m *Model) handleNotify(msg listen.NotifyMsg) tea.Cmd {
+	notifier := m.notifier()
+	if notifier == nil {
+		m.listen.SetMessage(m.listenUnavailable(), false)
+		return nil
+	}
+	if m.safeMode {
+		m.listen.SetMessage(safeModeBlocked, false)
+		return nil
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), listenTimeout)
+		defer cancel()
+		err := notifier.Notify(ctx, msg.Channel, msg.Payload)
+		return notifySentMsg{channel: msg.Channel, err: err}
+	}
+}
+
+// handleNotifySent reports a notification sent in the panel.
+func (m *Model) handleNotifySent(msg notifySentMsg) {
+	if msg.err != nil {
+		m.listen.SetMessage("Could not notify: "+sanitizeError(msg.err.Error()), false)
+		return
+	}
+	m.listen.SetMessage("Sent to "+msg.channel, true)
+}
+
+// closeListener closes the listening session, if any, when the connection
+// it belongs to goes.
+func (m *Model) closeListener() {
+	m.listenPending = nil
+	m.listen.StopListening()
+	if m.listener == nil {
+		return
+	}
+	l := m.listener
+	m.listener = nil
+	// Closing waits for a LISTEN in progress, so it is not done here.
+	go l.Close()
+}
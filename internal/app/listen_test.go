@@ -0,0 +1,108 @@
+package app
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/ui/listen"
+)
+
+// notifyConn is a postgres testConn that can LISTEN and NOTIFY.
+type notifyConn struct {
+	pgConn
+	listener *fakeListener
+	sent     *[]adapter.Notification
+}
+
+func (c notifyConn) Listen(context.Context) (adapter.Listener, error) { return c.listener, nil }
+
+func (c notifyConn) Notify(_ context.Context, channel, payload string) error {
+	*c.sent = append(*c.sent, adapter.Notification{Channel: channel, Payload: payload})
+	return nil
+}
+
+// fakeListener records the channels listened on; Wait returns what is
+// sent on next.
+type fakeListener struct {
+	channels []string
+	next     chan adapter.Notification
+}
+
+func (l *fakeListener) Listen(_ context.Context, channel string) error {
+	l.channels = append(l.channels, channel)
+	return nil
+}
+
+func (l *fakeListener) Unlisten(context.Context, string) error { return nil }
+
+func (l *fakeListener) Wait(context.Context) (adapter.Notification, error) {
+	n, ok := <-l.next
+	if !ok {
+		return adapter.Notification{}, adapter.ErrListenerClosed
+	}
+	return n, nil
+}
+
+func (l *fakeListener) Close() error { return nil }
+
+func TestListen(t *testing.T) {
+	m := step(New(config.DefaultConfig(), nil, nil), tea.WindowSizeMsg{Width: 160, Height: 40})
+	l := &fakeListener{next: make(chan adapter.Notification, 1)}
+	var sent []adapter.Notification
+	m.conn = notifyConn{pgConn: pgConn{&testConn{dbName: "app"}}, listener: l, sent: &sent}
+
+	m = step(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n"), Alt: true})
+	if !m.listen.Visible() {
+		t.Fatal("Alt+N should open the panel")
+	}
+
+	// Opening the session, listening on the channel and the wait for the
+	// first notification.
+	model, cmd := m.Update(listen.ListenMsg{Channel: "orders"})
+	m = model.(Model)
+	model, cmd = m.Update(cmd())
+	m = model.(Model)
+	l.next <- adapter.Notification{Channel: "orders", Payload: "42"}
+	var wait tea.Cmd
+	for _, msg := range drainBatch(cmd) {
+		model, cmd = m.Update(msg)
+		m = model.(Model)
+		if _, ok := msg.(notificationMsg); ok {
+			wait = cmd
+		}
+	}
+	if !reflect.DeepEqual(l.channels, []string{"orders"}) || !reflect.DeepEqual(m.listen.Channels(), []string{"orders"}) {
+		t.Errorf("listening on %q, shown %q; want orders", l.channels, m.listen.Channels())
+	}
+	if got := m.listen.Received(); len(got) != 1 || got[0].Payload != "42" {
+		t.Errorf("received %v, want the notification", got)
+	}
+	if wait == nil {
+		t.Error("want a wait for the next notification")
+	}
+
+	m = step(m, listen.NotifyMsg{Channel: "orders", Payload: "hi"})
+	if len(sent) != 1 || sent[0].Payload != "hi" {
+		t.Errorf("sent %v, want the notification", sent)
+	}
+	m.safeMode = true
+	m = step(m, listen.NotifyMsg{Channel: "orders", Payload: "again"})
+	if len(sent) != 1 {
+		t.Error("safe mode should block NOTIFY")
+	}
+
+	// Another connection closes the session.
+	close(l.next)
+	m = step(m, ConnectMsg{Conn: sqliteConn{&testConn{dbName: "other"}}})
+	if m.listener != nil || len(m.listen.Channels()) != 0 {
+		t.Error("connecting elsewhere should stop listening")
+	}
+	m.listen.Hide()
+	if m.openListen(); m.listen.Visible() {
+		t.Error("the panel opened for a connection that cannot LISTEN")
+	}
+}
@@ -0,0 +1,146 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/ui/maintenance"
+)
+
+// maintenanceTimeout bounds reading the settings of a database. The
+// commands themselves run until they finish or the panel is closed.
+const maintenanceTimeout = 10 * time.Second
+
+// maintLoadedMsg carries the settings of a database read on connection
+// generation connGen.
+type maintLoadedMsg struct {
+	schema   string
+	settings []adapter.Setting
+	err      error
+	connGen  uint64
+}
+
+// maintDoneMsg reports a maintenance command finished; problems are what
+// an integrity check found.
+type maintDoneMsg struct {
+	command  string
+	problems []string
+	err      error
+	connGen  uint64
+}
+
+// maintainer returns the connection if it can maintain its databases, or
+// nil.
+func (m *Model) maintainer() adapter.Maintainer {
+	mt, _ := m.conn.(adapter.Maintainer)
+	return mt
+}
+
+// openMaintenance opens the maintenance panel of a database and reads its
+// settings.
+func (m *Model) openMaintenance(msg MaintenanceMsg) tea.Cmd {
+	if m.maintainer() == nil {
+		text := "Not connected"
+		if m.conn != nil {
+			text = "Maintenance is not available for " + m.conn.AdapterName()
+		}
+		return m.toast(ToastError, text)
+	}
+	m.maintenance.Show(msg.Database, msg.Schema)
+	return m.loadMaintenance()
+}
+
+// loadMaintenance reads the settings of the database shown in the
+// background.
+func (m *Model) loadMaintenance() tea.Cmd {
+	mt := m.maintainer()
+	if mt == nil {
+		return nil
+	}
+	gen, schemaName := m.connGen, m.maintenance.Schema()
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), maintenanceTimeout)
+		defer cancel()
+		settings, err := mt.MaintenanceSettings(ctx, schemaName)
+		return maintLoadedMsg{schema: schemaName, settings: settings, err: err, connGen: gen}
+	}
+}
+
+// handleMaintLoaded shows the settings read.
+func (m *Model) handleMaintLoaded(msg maintLoadedMsg) {
+	if msg.connGen != m.connGen || msg.schema != m.maintenance.Schema() {
+		return
+	}
+	if msg.err != nil {
+		m.maintenance.SetMessage("Could not read the settings: "+sanitizeError(msg.err.Error()), false)
+		return
+	}
+	m.maintenance.SetSettings(msg.settings)
+}
+
+// runMaintenance runs an integrity check or a maintenance command in the
+// background, until it finishes or the panel is closed, recording the
+// statements a command runs. Safe mode blocks everything but the integrity
+// check, which only reads.
+func (m *Model) runMaintenance(msg maintenance.RunMsg) tea.Cmd {
+	mt := m.maintainer()
+	if mt == nil || m.maintenance.Running() != "" {
+		return nil
+	}
+	if m.safeMode && msg.Command != maintenance.IntegrityCheck {
+		m.maintenance.SetMessage(safeModeBlocked, false)
+		return nil
+	}
+	ctx, cancel := context.WithCancel(m.writes().recording(context.Background()))
+	m.maintCancel = cancel
+	gen := m.connGen
+	run := func() tea.Msg {
+		done := maintDoneMsg{command: msg.Command, connGen: gen}
+		if msg.Command == maintenance.IntegrityCheck {
+			done.problems, done.err = mt.IntegrityCheck(ctx, msg.Schema)
+		} else {
+			done.err = mt.Maintain(ctx, msg.Schema, msg.Command)
+		}
+		if done.err != nil && ctx.Err() != nil {
+			done.err = ctx.Err() // adapters report a cancel their own way
+		}
+		return done
+	}
+	return tea.Batch(m.maintenance.Start(msg.Command), run)
+}
+
+// stopMaintenance cancels the command running, if any.
+func (m *Model) stopMaintenance() {
+	if m.maintCancel != nil {
+		m.maintCancel()
+		m.maintCancel = nil
+	}
+}
+
+// handleMaintDone reports a command finished and reads the settings again,
+// which VACUUM may have changed.
+func (m *Model) handleMaintDone(msg maintDoneMsg) tea.Cmd {
+	if msg.connGen != m.connGen {
+		return nil
+	}
+	m.stopMaintenance()
+	switch {
+	case errors.Is(msg.err, context.Canceled):
+		m.maintenance.Done("cancelled")
+		return nil
+	case msg.err != nil:
+		m.maintenance.Done(sanitizeError(msg.err.Error()))
+		return nil
+	}
+	m.maintenance.Done("")
+	if msg.command == maintenance.IntegrityCheck {
+		m.maintenance.SetProblems(msg.problems)
+	}
+	if !m.maintenance.Visible() {
+		return nil
+	}
+	return m.loadMaintenance()
+}
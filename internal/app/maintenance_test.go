@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/ui/maintenance"
+)
+
+// maintConn is a sqlite testConn that records the maintenance run.
+type maintConn struct {
+	sqliteConn
+	ran *[]string
+}
+
+func (c maintConn) MaintenanceSettings(context.Context, string) ([]adapter.Setting, error) {
+	return []adapter.Setting{{Name: "journal_mode", Value: "delete"}}, nil
+}
+
+func (c maintConn) IntegrityCheck(context.Context, string) ([]string, error) {
+	return []string{"page 4 is never used"}, nil
+}
+
+func (c maintConn) Maintain(_ context.Context, schemaName, command string) error {
+	*c.ran = append(*c.ran, schemaName+" "+command)
+	return nil
+}
+
+func TestMaintenance(t *testing.T) {
+	m := step(New(config.DefaultConfig(), nil, nil), tea.WindowSizeMsg{Width: 160, Height: 40})
+	var ran []string
+	m.conn = maintConn{sqliteConn: sqliteConn{&testConn{dbName: "app"}}, ran: &ran}
+
+	m = step(m, MaintenanceMsg{Database: "archive", Schema: "old"})
+	if !m.maintenance.Visible() {
+		t.Fatal("the maintenance panel did not open")
+	}
+
+	m.safeMode = true
+	m = step(m, maintenance.RunMsg{Schema: "old", Command: adapter.MaintainVacuum})
+	if len(ran) != 0 {
+		t.Error("safe mode should not run VACUUM")
+	}
+	m = step(m, maintenance.RunMsg{Schema: "old", Command: maintenance.IntegrityCheck})
+	if m.maintenance.Running() != "" {
+		t.Error("the integrity check is still shown as running")
+	}
+
+	m.safeMode = false
+	m = step(m, maintenance.RunMsg{Schema: "old", Command: adapter.MaintainVacuum})
+	if len(ran) != 1 || ran[0] != "old VACUUM" {
+		t.Errorf("ran %v, want VACUUM on old", ran)
+	}
+	if m.maintCancel != nil {
+		t.Error("the finished command was not released")
+	}
+
+	m.maintenance.Hide()
+	m.conn = pgConn{&testConn{dbName: "app"}}
+	if m.openMaintenance(MaintenanceMsg{Database: "app", Schema: "public"}); m.maintenance.Visible() {
+		t.Error("the maintenance panel opened on postgres")
+	}
+}
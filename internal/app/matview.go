@@ -0,0 +1,32 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/ui/dialog"
+)
+
+// confirmRefreshMatView asks before refreshing a materialized view, which
+// recomputes all of its rows, and then runs the REFRESH in the active tab.
+// CONCURRENTLY keeps the view readable meanwhile but needs a unique index.
+func (m *Model) confirmRefreshMatView(msg RefreshMatViewMsg) tea.Cmd {
+	if m.conn == nil {
+		return nil
+	}
+	dialect := m.conn.AdapterName()
+	name := adapter.QuoteIdentifier(dialect, msg.View)
+	if msg.Schema != "" {
+		name = adapter.QuoteIdentifier(dialect, msg.Schema) + "." + name
+	}
+	query := "REFRESH MATERIALIZED VIEW " + name
+	concurrent := "REFRESH MATERIALIZED VIEW CONCURRENTLY " + name
+	tabID := m.tabs.ActiveID()
+
+	m.showDialog("Refresh Materialized View",
+		query+"\n\nThis recomputes every row of the view.",
+		dialog.Button{Label: "Refresh", Action: func() tea.Msg { return ExecuteQueryMsg{Query: query, TabID: tabID} }},
+		dialog.Button{Label: "Concurrently", Action: func() tea.Msg { return ExecuteQueryMsg{Query: concurrent, TabID: tabID} }},
+		dialog.Button{Label: "Cancel", Action: func() tea.Msg { return nil }},
+	)
+	return nil
+}
@@ -0,0 +1,26 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+func TestRefreshMatView_AsksFirst(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	conn := &testConn{dbName: "app"}
+	m.conn = conn
+
+	model, _ := m.Update(RefreshMatViewMsg{Database: "app", Schema: "public", View: "daily_sales"})
+	m = model.(Model)
+	if !m.dialog.Visible() {
+		t.Fatal("expected a confirmation dialog")
+	}
+	if view := m.dialog.View(); !strings.Contains(view, `REFRESH MATERIALIZED VIEW "public"."daily_sales"`) {
+		t.Errorf("dialog view = %q, want the REFRESH statement", view)
+	}
+	if len(conn.executed) != 0 {
+		t.Errorf("nothing should run before confirming, ran %v", conn.executed)
+	}
+}
@@ -7,27 +7,50 @@ import appmsg "github.com/sadopc/gotermsql/internal/msg"
 
 // Re-export types used within app package.
 type (
-	Pane              = appmsg.Pane
-	KeyMode           = appmsg.KeyMode
-	VimState          = appmsg.VimState
-	ConnectMsg        = appmsg.ConnectMsg
-	ConnectErrMsg     = appmsg.ConnectErrMsg
-	DisconnectMsg     = appmsg.DisconnectMsg
-	SchemaLoadedMsg   = appmsg.SchemaLoadedMsg
-	SchemaErrMsg      = appmsg.SchemaErrMsg
-	ExecuteQueryMsg   = appmsg.ExecuteQueryMsg
-	QueryStartedMsg   = appmsg.QueryStartedMsg
-	QueryResultMsg    = appmsg.QueryResultMsg
-	QueryErrMsg       = appmsg.QueryErrMsg
-	QueryStreamingMsg = appmsg.QueryStreamingMsg
-	NewTabMsg         = appmsg.NewTabMsg
-	CloseTabMsg       = appmsg.CloseTabMsg
-	SwitchTabMsg      = appmsg.SwitchTabMsg
-	StatusMsg         = appmsg.StatusMsg
-	ToggleKeyModeMsg  = appmsg.ToggleKeyModeMsg
-	InsertTextMsg     = appmsg.InsertTextMsg
-	ExportCompleteMsg = appmsg.ExportCompleteMsg
-	ExportErrMsg      = appmsg.ExportErrMsg
+	Pane                = appmsg.Pane
+	KeyMode             = appmsg.KeyMode
+	VimState            = appmsg.VimState
+	ConnectMsg          = appmsg.ConnectMsg
+	ConnectErrMsg       = appmsg.ConnectErrMsg
+	DisconnectMsg       = appmsg.DisconnectMsg
+	SchemaLoadedMsg     = appmsg.SchemaLoadedMsg
+	SchemaErrMsg        = appmsg.SchemaErrMsg
+	ExecuteQueryMsg     = appmsg.ExecuteQueryMsg
+	QueryStartedMsg     = appmsg.QueryStartedMsg
+	QueryResultMsg      = appmsg.QueryResultMsg
+	QueryErrMsg         = appmsg.QueryErrMsg
+	QueryStreamingMsg   = appmsg.QueryStreamingMsg
+	TotalRowsMsg        = appmsg.TotalRowsMsg
+	NewTabMsg           = appmsg.NewTabMsg
+	CloseTabMsg         = appmsg.CloseTabMsg
+	SwitchTabMsg        = appmsg.SwitchTabMsg
+	StatusMsg           = appmsg.StatusMsg
+	ToggleKeyModeMsg    = appmsg.ToggleKeyModeMsg
+	InsertTextMsg       = appmsg.InsertTextMsg
+	ExportCompleteMsg   = appmsg.ExportCompleteMsg
+	ExportErrMsg        = appmsg.ExportErrMsg
+	ShowDDLMsg          = appmsg.ShowDDLMsg
+	PortTableMsg        = appmsg.PortTableMsg
+	DependenciesMsg     = appmsg.DependenciesMsg
+	TransferTableMsg    = appmsg.TransferTableMsg
+	InsertRowMsg        = appmsg.InsertRowMsg
+	GenerateDataMsg     = appmsg.GenerateDataMsg
+	BulkUpdateMsg       = appmsg.BulkUpdateMsg
+	CompareDataMsg      = appmsg.CompareDataMsg
+	DumpTableMsg        = appmsg.DumpTableMsg
+	ERDiagramMsg        = appmsg.ERDiagramMsg
+	ProfileTableMsg     = appmsg.ProfileTableMsg
+	MaintenanceMsg      = appmsg.MaintenanceMsg
+	LoadTableStatsMsg   = appmsg.LoadTableStatsMsg
+	TableStatsMsg       = appmsg.TableStatsMsg
+	RefreshMatViewMsg   = appmsg.RefreshMatViewMsg
+	TableActionMsg      = appmsg.TableActionMsg
+	FavoritesChangedMsg = appmsg.FavoritesChangedMsg
+	LoadTableMsg        = appmsg.LoadTableMsg
+	TableLoadedMsg      = appmsg.TableLoadedMsg
+	TunnelClosedMsg     = appmsg.TunnelClosedMsg
+	ToastMsg            = appmsg.ToastMsg
+	ToastLevel          = appmsg.ToastLevel
 )
 
 // Re-export constants.
@@ -40,6 +63,10 @@ const (
 	VimNormal       = appmsg.VimNormal
 	VimInsert       = appmsg.VimInsert
 	VimVisual       = appmsg.VimVisual
+	ToastInfo       = appmsg.ToastInfo
+	ToastSuccess    = appmsg.ToastSuccess
+	ToastWarning    = appmsg.ToastWarning
+	ToastError      = appmsg.ToastError
 )
 
 // Re-export functions.
@@ -0,0 +1,160 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// divider is a border between panes that can be dragged to resize them.
+type divider int
+
+const (
+	dividerNone    divider = iota
+	dividerSidebar         // between the sidebar and the editor and results
+	dividerEditor          // between the editor and the results
+)
+
+// paneLayout is where View draws the panes, in screen cells.
+type paneLayout struct {
+	tabH     int // height of the tab bar
+	mainH    int // height of the panes under it
+	mainX    int // left edge of the editor and results
+	mainW    int // width of the editor and results together
+	resultsX int // left edge of the results
+	resultsY int // top of the results
+}
+
+// layout returns where the panes are, mirroring the layout math in View.
+func (m Model) layout() paneLayout {
+	if m.zen {
+		// One pane fills the screen; the results start below it when
+		// the editor is shown.
+		l := paneLayout{mainH: m.height, mainW: m.width, resultsY: m.height}
+		if m.focusedPane == PaneResults {
+			l.resultsY = 0
+		}
+		return l
+	}
+	l := paneLayout{tabH: lipgloss.Height(m.tabs.View())}
+	l.mainH = max(m.height-l.tabH-lipgloss.Height(m.statusbar.View()), 1)
+	l.mainW = m.width
+	if m.showSidebar {
+		l.mainX = m.sidebarWidth
+		l.mainW -= m.sidebarWidth
+	}
+	editorW, editorH, _, _ := m.paneSizes(l.mainW, l.mainH)
+	l.resultsX, l.resultsY = l.mainX, l.tabH+editorH
+	if m.sideBySide {
+		l.resultsX, l.resultsY = l.mainX+editorW, l.tabH
+	}
+	return l
+}
+
+// editorW returns the width of the editor area.
+func (l paneLayout) editorW() int {
+	if l.resultsX > l.mainX {
+		return l.resultsX - l.mainX
+	}
+	return l.mainW
+}
+
+// editorH returns the height of the editor area.
+func (l paneLayout) editorH() int {
+	if l.resultsY > l.tabH {
+		return l.resultsY - l.tabH
+	}
+	return l.mainH
+}
+
+// handleMouse routes mouse events to the pane under the pointer. A click
+// focuses the pane; on a divider it starts dragging it instead. Overlays
+// swallow mouse input while visible.
+func (m *Model) handleMouse(msg tea.MouseMsg) tea.Cmd {
+	if m.connMgr.Visible() || m.switcher.Visible() || m.objSearch.Visible() || m.histBrowser.Visible() || m.queryLib.Visible() || m.params.Visible() || m.listen.Visible() || m.activity.Visible() || m.maintenance.Visible() || m.schemaDiff.Visible() || m.erDiagram.Visible() || m.profiler.Visible() || m.depBrowser.Visible() || m.transfer.Visible() || m.rowForm.Visible() || m.dataGen.Visible() || m.bulkUpdate.Visible() || m.dataDiff.Visible() || m.dump.Visible() || m.viewer.Visible() || m.dialog.Visible() || m.showHelp {
+		m.drag = dividerNone
+		return nil
+	}
+	ts := m.activeTabState()
+	if ts == nil {
+		return nil
+	}
+	l := m.layout()
+
+	if m.drag != dividerNone {
+		switch msg.Action {
+		case tea.MouseActionMotion:
+			m.dragTo(msg.X, msg.Y, l)
+		case tea.MouseActionRelease:
+			m.drag = dividerNone
+		}
+		return nil
+	}
+	if msg.Action != tea.MouseActionPress {
+		return nil
+	}
+	click := msg.Button == tea.MouseButtonLeft
+	if d := m.dividerAt(msg.X, msg.Y, l); click && d != dividerNone {
+		m.drag = d
+		return nil
+	}
+
+	var cmd tea.Cmd
+	switch {
+	case msg.Y < l.tabH:
+		m.tabs, cmd = m.tabs.Update(msg)
+	case msg.X < l.mainX:
+		if click && m.focusedPane != PaneSidebar {
+			m.setFocus(PaneSidebar)
+		}
+		local := msg
+		local.Y -= l.tabH
+		m.sidebar, cmd = m.sidebar.Update(local)
+	case msg.Y < l.resultsY || msg.X < l.resultsX:
+		if click && m.inSplit(msg.X, msg.Y, l.mainX, l.tabH, l.editorW(), l.editorH()) {
+			return m.otherSplit()
+		}
+		if click && m.focusedPane != PaneEditor {
+			m.setFocus(PaneEditor)
+		}
+	default:
+		if click && m.focusedPane != PaneResults {
+			m.setFocus(PaneResults)
+		}
+		local := msg
+		local.X -= l.resultsX
+		local.Y -= l.resultsY
+		ts.Results, cmd = ts.Results.Update(local)
+	}
+	return cmd
+}
+
+// dividerAt returns the divider whose borders are at x, y.
+func (m Model) dividerAt(x, y int, l paneLayout) divider {
+	switch {
+	case m.zen, y < l.tabH || y >= l.tabH+l.mainH:
+		return dividerNone
+	case m.showSidebar && (x == l.mainX-1 || x == l.mainX):
+		return dividerSidebar
+	case m.sideBySide && (x == l.resultsX-1 || x == l.resultsX):
+		return dividerEditor
+	case !m.sideBySide && x >= l.mainX && (y == l.resultsY-1 || y == l.resultsY):
+		return dividerEditor
+	}
+	return dividerNone
+}
+
+// dragTo moves the divider being dragged to x, y, within the limits of the
+// resize keys.
+func (m *Model) dragTo(x, y int, l paneLayout) {
+	switch m.drag {
+	case dividerSidebar:
+		m.sidebarWidth = min(max(x+1, 15), max(m.width/2, 15))
+	case dividerEditor:
+		if m.sideBySide {
+			m.editorWidth = min(max((x-l.mainX)*100/max(l.mainW, 1), 20), 80)
+			break
+		}
+		m.editorHeight = min(max((y-l.tabH)*100/l.mainH, 20), 80)
+	}
+	m.updateLayout()
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+func mouseModel() Model {
+	m := New(config.DefaultConfig(), nil, nil)
+	model, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	return model.(Model)
+}
+
+func mouse(m Model, x, y int, action tea.MouseAction, button tea.MouseButton) (Model, tea.Cmd) {
+	model, cmd := m.Update(tea.MouseMsg{X: x, Y: y, Action: action, Button: button})
+	return model.(Model), cmd
+}
+
+func click(m Model, x, y int) (Model, tea.Cmd) {
+	return mouse(m, x, y, tea.MouseActionPress, tea.MouseButtonLeft)
+}
+
+func TestMouse_FocusesPanes(t *testing.T) {
+	m := mouseModel()
+	l := m.layout()
+
+	tests := []struct {
+		x, y int
+		want Pane
+	}{
+		{5, l.tabH + 3, PaneSidebar},
+		{l.mainX + 10, l.tabH + 3, PaneEditor},
+		{l.mainX + 10, l.resultsY + 5, PaneResults},
+	}
+	for _, tt := range tests {
+		m, _ = click(m, tt.x, tt.y)
+		if m.focusedPane != tt.want {
+			t.Errorf("click at %d,%d: focused pane = %v, want %v", tt.x, tt.y, m.focusedPane, tt.want)
+		}
+	}
+
+	// The wheel scrolls without taking focus.
+	m, _ = mouse(m, 5, l.tabH+3, tea.MouseActionPress, tea.MouseButtonWheelDown)
+	if m.focusedPane != PaneResults {
+		t.Errorf("the wheel moved the focus to %v", m.focusedPane)
+	}
+
+	// Overlays take no mouse input.
+	m.showHelp = true
+	m, _ = click(m, 5, l.tabH+3)
+	if m.focusedPane != PaneResults {
+		t.Error("a click under the help screen should do nothing")
+	}
+}
+
+func TestMouse_Tabs(t *testing.T) {
+	m := mouseModel()
+	m.addTab("")
+	// Each tab is 9 columns wide, then the new tab button.
+	m, cmd := click(m, 3, 1)
+	if cmd == nil || cmd() != (SwitchTabMsg{TabID: 0}) {
+		t.Fatal("a click on the first tab should switch to it")
+	}
+	if _, cmd = click(m, 20, 1); cmd == nil || cmd() != (NewTabMsg{}) {
+		t.Error("a click on + should open a new tab")
+	}
+}
+
+func TestMouse_DragDividers(t *testing.T) {
+	m := mouseModel()
+	l := m.layout()
+
+	m, _ = click(m, l.mainX-1, l.tabH+5)
+	m, _ = mouse(m, 40, l.tabH+5, tea.MouseActionMotion, tea.MouseButtonLeft)
+	if m.sidebarWidth != 41 {
+		t.Errorf("after dragging the sidebar border to 40: width = %d, want 41", m.sidebarWidth)
+	}
+	m, _ = mouse(m, 100, l.tabH+5, tea.MouseActionMotion, tea.MouseButtonLeft)
+	if m.sidebarWidth != 60 {
+		t.Errorf("the sidebar should stop at half the width, got %d", m.sidebarWidth)
+	}
+	m, _ = mouse(m, 100, l.tabH+5, tea.MouseActionRelease, tea.MouseButtonLeft)
+	m, _ = mouse(m, 20, l.tabH+5, tea.MouseActionMotion, tea.MouseButtonNone)
+	if m.sidebarWidth != 60 {
+		t.Error("moving after the release should not resize")
+	}
+
+	l = m.layout()
+	m, _ = click(m, l.mainX+10, l.resultsY)
+	m, _ = mouse(m, l.mainX+10, l.tabH+l.mainH/4, tea.MouseActionMotion, tea.MouseButtonLeft)
+	if m.editorHeight != 24 {
+		t.Errorf("after dragging the editor border up: editor height = %d%%, want 24%%", m.editorHeight)
+	}
+	m, _ = mouse(m, l.mainX+10, l.tabH+l.mainH, tea.MouseActionMotion, tea.MouseButtonLeft)
+	if m.editorHeight != 80 {
+		t.Errorf("the editor should stop at 80%%, got %d%%", m.editorHeight)
+	}
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// writeTerminal sends escape sequences straight to the terminal; swapped
+// out in tests.
+var writeTerminal = func(s string) { _, _ = os.Stdout.WriteString(s) }
+
+// notifyDone tells the user that the query of tab tabID is done, if it ran
+// for notify.after or longer while they looked elsewhere: at another tab,
+// which gets a badge until it is shown, or at another window, which the
+// terminal reports with focus events.
+func (m *Model) notifyDone(tabID int, failed bool) tea.Cmd {
+	cfg := m.cfg.Notify
+	ts := m.tabStates[tabID]
+	if cfg.After <= 0 || ts == nil || ts.started.IsZero() {
+		return nil
+	}
+	took := time.Since(ts.started)
+	away := tabID != m.tabs.ActiveID()
+	if took < cfg.After || (!away && !m.blurred) {
+		return nil
+	}
+	if away {
+		m.tabs.SetDone(tabID, true)
+	}
+
+	var seq strings.Builder
+	if cfg.Bell {
+		seq.WriteString("\a")
+	}
+	if cfg.Desktop {
+		text := fmt.Sprintf("%s finished after %s", m.tabTitle(tabID), took.Round(time.Second))
+		if failed {
+			text = fmt.Sprintf("%s failed after %s", m.tabTitle(tabID), took.Round(time.Second))
+		}
+		// OSC 9, which iTerm2, kitty, WezTerm, Ghostty and Windows
+		// Terminal show as a notification. Control characters would end
+		// the sequence early.
+		text = strings.Map(func(r rune) rune {
+			if r < ' ' || r == 0x7f {
+				return -1
+			}
+			return r
+		}, "gotermsql: "+text)
+		seq.WriteString("\x1b]9;" + text + "\a")
+	}
+	if seq.Len() == 0 {
+		return nil
+	}
+	out := seq.String()
+	return func() tea.Msg {
+		writeTerminal(out)
+		return nil
+	}
+}
+
+// tabTitle returns the title of tab tabID.
+func (m *Model) tabTitle(tabID int) string {
+	for _, t := range m.tabs.Tabs() {
+		if t.ID == tabID {
+			return t.Title
+		}
+	}
+	return "Query"
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+// finish reports a query of tab tabID sent ago as done, returning what
+// reached the terminal.
+func finish(t *testing.T, m *Model, tabID int, ago time.Duration) string {
+	t.Helper()
+	var written string
+	orig := writeTerminal
+	defer func() { writeTerminal = orig }()
+	writeTerminal = func(s string) { written += s }
+
+	m.tabStates[tabID].started = time.Now().Add(-ago)
+	if cmd := m.notifyDone(tabID, false); cmd != nil {
+		cmd()
+	}
+	return written
+}
+
+func TestNotifyDone(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Notify.Desktop = true
+	m := New(cfg, nil, nil)
+	first := m.tabs.ActiveID()
+	m = step(m, NewTabMsg{})
+
+	if got := finish(t, &m, first, time.Second); got != "" {
+		t.Errorf("a quick query wrote %q, want nothing", got)
+	}
+	if got := finish(t, &m, first, time.Minute); got != "\a\x1b]9;gotermsql: Query 1 finished after 1m0s\a" {
+		t.Errorf("a long query in another tab wrote %q, want the bell and a notification", got)
+	}
+	if !m.tabs.Tabs()[0].Done {
+		t.Error("expected the tab to be badged")
+	}
+	m = step(m, SwitchTabMsg{TabID: first})
+	if m.tabs.Tabs()[0].Done {
+		t.Error("expected the badge to go once the tab is shown")
+	}
+
+	// In the tab shown, only when the terminal is in the background.
+	if got := finish(t, &m, first, time.Minute); got != "" {
+		t.Errorf("a long query in the tab shown wrote %q, want nothing", got)
+	}
+	m = step(m, tea.BlurMsg{})
+	if got := finish(t, &m, first, time.Minute); got == "" {
+		t.Error("expected a notification while the terminal is unfocused")
+	}
+	if m.tabs.Tabs()[0].Done {
+		t.Error("the tab shown should not be badged")
+	}
+}
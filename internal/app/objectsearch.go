@@ -0,0 +1,34 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/completion"
+)
+
+// revealObject selects an object picked in the object search in the
+// sidebar, showing and focusing the sidebar. A column the tree does not
+// list, such as a view's, selects its table or view instead.
+func (m *Model) revealObject(o completion.Object) tea.Cmd {
+	table := ""
+	if o.Kind == completion.KindColumn {
+		table = o.Table
+	}
+	found := m.sidebar.RevealObject(o.Database, o.Schema, table, o.Name)
+	if !found && table != "" {
+		found = m.sidebar.RevealObject(o.Database, o.Schema, "", table)
+	}
+	if !found {
+		return m.toast(ToastError, o.Name+" is not in the schema tree")
+	}
+	m.focusSidebar()
+	return nil
+}
+
+// focusSidebar shows the sidebar, if hidden, and focuses it.
+func (m *Model) focusSidebar() {
+	if !m.showSidebar {
+		m.showSidebar = true
+		m.updateLayout()
+	}
+	m.setFocus(PaneSidebar)
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/ui/sidebar"
+)
+
+func TestFindObject(t *testing.T) {
+	m := step(New(config.DefaultConfig(), nil, nil), tea.WindowSizeMsg{Width: 120, Height: 40})
+	m.conn = &testConn{dbName: "shop"}
+	model, _ := m.Update(SchemaLoadedMsg{ConnGen: m.connGen, Databases: []schema.Database{{Name: "shop", Schemas: []schema.Schema{{Name: "public",
+		Tables: []schema.Table{
+			{Name: "orders", Columns: []schema.Column{{Name: "id"}, {Name: "customer_uuid", Type: "uuid"}}},
+			{Name: "users", Columns: []schema.Column{{Name: "id"}}},
+		},
+		Views: []schema.View{{Name: "recent_orders", Columns: []schema.Column{{Name: "customer_uuid"}}}},
+	}}}}})
+	m = model.(Model)
+	m.setFocus(PaneEditor)
+
+	m = step(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o"), Alt: true})
+	if !m.objSearch.Visible() {
+		t.Fatal("Alt+O should open the object search")
+	}
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("customer_uuid")})
+	m = model.(Model)
+	if got := len(m.objSearch.Found()); got != 2 {
+		t.Fatalf("found %d objects, want the column of orders and of recent_orders", got)
+	}
+
+	// The column of the table is selected in the sidebar.
+	m = step(m, tea.KeyMsg{Type: tea.KeyEnter})
+	node := m.sidebar.Selected()
+	if m.objSearch.Visible() || m.focusedPane != PaneSidebar {
+		t.Fatal("picking an object should close the search and focus the sidebar")
+	}
+	if node == nil || node.Kind != sidebar.NodeColumn || node.Table != "orders" || node.Column != "customer_uuid" {
+		t.Fatalf("selected %+v, want orders.customer_uuid", node)
+	}
+
+	// The tree does not list the columns of views: the view is selected.
+	m = step(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o"), Alt: true})
+	m = step(m, tea.KeyMsg{Type: tea.KeyDown})
+	m = step(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if node := m.sidebar.Selected(); node == nil || node.Kind != sidebar.NodeView || node.Table != "recent_orders" {
+		t.Errorf("selected %+v, want the view recent_orders", node)
+	}
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+)
+
+// promptParams opens the parameter prompt when msg's query has bind
+// parameters (:name, $1 or ?) and the connection can bind them, to run it
+// with the values typed. Scripts and \copy commands run as written.
+func (m *Model) promptParams(msg ExecuteQueryMsg) bool {
+	if msg.Args != nil || m.conn == nil {
+		return false
+	}
+	if cp, err := adapter.ParseCopy(msg.Query); cp != nil || err != nil {
+		return false
+	}
+	if _, ok := m.conn.(adapter.ParamExecutor); !ok {
+		return false
+	}
+	dialect := m.conn.AdapterName()
+	pq := adapter.ParseParams(dialect, msg.Query)
+	if len(pq.Names) == 0 || scriptStatements(dialect, msg.Query) != nil {
+		return false
+	}
+	m.params.Show(pq.Names, func(values []string) tea.Msg {
+		args := make([]any, len(values))
+		for i, v := range values {
+			args[i] = adapter.BindValue(v)
+		}
+		msg.Args = args
+		return msg
+	})
+	return true
+}
+
+// executeParams runs query on conn as a prepared statement, binding args
+// to the parameters adapter.ParseParams finds in it.
+func executeParams(ctx context.Context, conn adapter.Connection, query string, args []any) (*adapter.QueryResult, error) {
+	pe, ok := conn.(adapter.ParamExecutor)
+	if !ok {
+		return nil, fmt.Errorf("%s connections cannot bind parameters", conn.AdapterName())
+	}
+	pq := adapter.ParseParams(conn.AdapterName(), query)
+	if len(pq.Names) != len(args) {
+		return nil, fmt.Errorf("the query has %d parameters, but %d values were given", len(pq.Names), len(args))
+	}
+	return pe.ExecuteParams(ctx, pq.Query, pq.Args(args))
+}
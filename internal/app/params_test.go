@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+// paramConn is a postgres testConn that records the bind values it got.
+type paramConn struct {
+	*testConn
+	args *[]any
+}
+
+func (c paramConn) AdapterName() string { return "postgres" }
+
+func (c paramConn) ExecuteParams(_ context.Context, query string, args []any) (*adapter.QueryResult, error) {
+	c.executed = append(c.executed, query)
+	*c.args = args
+	return c.result, nil
+}
+
+func TestExecuteQuery_Params(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	var args []any
+	conn := paramConn{&testConn{dbName: "app", result: &adapter.QueryResult{}}, &args}
+	m.conn = conn
+	tabID := m.tabs.ActiveID()
+
+	// The query waits for its values.
+	model, cmd := m.Update(ExecuteQueryMsg{Query: "SELECT * FROM users WHERE id = :id AND name = :name", TabID: tabID})
+	m = model.(Model)
+	if cmd != nil && len(drainBatch(cmd)) > 0 || !m.params.Visible() {
+		t.Fatal("expected the parameter prompt")
+	}
+	for _, k := range []tea.KeyMsg{
+		{Type: tea.KeyRunes, Runes: []rune("7")},
+		{Type: tea.KeyTab},
+		{Type: tea.KeyRunes, Runes: []rune("O'Brien")},
+		{Type: tea.KeyEnter},
+	} {
+		model, cmd = m.Update(k)
+		m = model.(Model)
+	}
+	msgs := drainBatch(cmd)
+	if len(msgs) != 1 {
+		t.Fatalf("enter sent %v, want the query", msgs)
+	}
+	run := msgs[0].(ExecuteQueryMsg)
+	if !reflect.DeepEqual(run.Args, []any{int64(7), "O'Brien"}) {
+		t.Errorf("Args = %#v, want 7 and O'Brien", run.Args)
+	}
+
+	// With its values, it runs as a prepared statement.
+	for _, msg := range drainBatch(m.executeQuery(run)) {
+		if e, ok := msg.(QueryErrMsg); ok {
+			t.Fatalf("query failed: %v", e.Err)
+		}
+	}
+	if want := []string{"SELECT * FROM users WHERE id = $1 AND name = $2"}; !reflect.DeepEqual(conn.executed, want) {
+		t.Errorf("executed %q, want %q", conn.executed, want)
+	}
+	if !reflect.DeepEqual(args, []any{int64(7), "O'Brien"}) {
+		t.Errorf("bound %#v", args)
+	}
+}
+
+func TestExecuteQuery_NoParams(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	var args []any
+	m.conn = paramConn{&testConn{dbName: "app", result: &adapter.QueryResult{}}, &args}
+
+	// No prompt for queries without parameters, nor for a ? to PostgreSQL.
+	for _, query := range []string{"SELECT 1", "SELECT doc ? 'a' FROM t", "SELECT ':a'"} {
+		model, _ := m.Update(ExecuteQueryMsg{Query: query, TabID: m.tabs.ActiveID()})
+		if model.(Model).params.Visible() {
+			t.Errorf("%q prompted for parameters", query)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+)
+
+// profileTimeout bounds profiling a table.
+const profileTimeout = 5 * time.Minute
+
+// profileSample is the most rows of a table profiled, and profileTop how
+// many of the most frequent values of each column are listed.
+const (
+	profileSample = 10000
+	profileTop    = 5
+)
+
+// profiledMsg carries the profile of a table computed on connection
+// generation connGen, for the panel opened gen-th.
+type profiledMsg struct {
+	profile adapter.TableProfile
+	err     error
+	gen     int
+	connGen uint64
+}
+
+// openProfile opens the profile panel of a table and profiles a sample of
+// its rows in the background: the columns read afresh, one query for the
+// statistics of them all, and one per column for its most frequent values.
+func (m *Model) openProfile(msg ProfileTableMsg) tea.Cmd {
+	if m.conn == nil {
+		return m.toast(ToastError, "Not connected")
+	}
+	dialect := m.conn.AdapterName()
+	name := adapter.QuoteIdentifier(dialect, msg.Table)
+	if msg.Schema != "" && msg.Schema != "main" {
+		name = adapter.QuoteIdentifier(dialect, msg.Schema) + "." + name
+	}
+	m.profiler.Show(msg.Table, profileSample)
+	m.stopProfile()
+	ctx, cancel := context.WithTimeout(adapter.OnPool(context.Background()), profileTimeout)
+	m.profileCancel = cancel
+	m.profileGen++
+	conn, gen, connGen := m.conn, m.profileGen, m.connGen
+	return func() tea.Msg {
+		defer cancel()
+		reply := profiledMsg{gen: gen, connGen: connGen}
+		cols, err := conn.Columns(ctx, msg.Database, msg.Schema, msg.Table)
+		if err != nil {
+			reply.err = err
+			return reply
+		}
+		res, err := conn.Execute(ctx, adapter.ProfileQuery(dialect, name, cols, profileSample))
+		switch {
+		case err != nil:
+			reply.err = err
+			return reply
+		case res == nil || len(res.Rows) == 0:
+			reply.err = errors.New("no result")
+			return reply
+		}
+		if reply.profile, reply.err = adapter.ParseProfile(cols, res.Rows[0]); reply.err != nil {
+			return reply
+		}
+		for i, c := range cols {
+			res, err := conn.Execute(ctx, adapter.TopValuesQuery(dialect, name, c, profileSample, profileTop))
+			if err != nil {
+				reply.err = err
+				return reply
+			}
+			reply.profile.Columns[i].Top = adapter.ParseTopValues(res.Rows)
+		}
+		return reply
+	}
+}
+
+// stopProfile cancels the profiling running, if any.
+func (m *Model) stopProfile() {
+	if m.profileCancel != nil {
+		m.profileCancel()
+		m.profileCancel = nil
+	}
+}
+
+// handleProfiled shows a finished profile, unless the panel was closed or
+// opened again since.
+func (m *Model) handleProfiled(msg profiledMsg) {
+	if msg.connGen != m.connGen || msg.gen != m.profileGen || !m.profiler.Visible() {
+		return
+	}
+	m.stopProfile()
+	if msg.err != nil {
+		m.profiler.SetError("Profiling failed: " + sanitizeError(msg.err.Error()))
+		return
+	}
+	m.profiler.SetProfile(msg.profile)
+}
@@ -0,0 +1,46 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+func TestProfileTable(t *testing.T) {
+	m := step(New(config.DefaultConfig(), nil, nil), tea.WindowSizeMsg{Width: 160, Height: 40})
+	tc := &testConn{dbName: "app", result: &adapter.QueryResult{Rows: [][]string{{"3", "3", "3", "1", "3", adapter.NullValue}}}}
+	m.conn = diffConn{sqliteConn: sqliteConn{tc}, tables: map[string][]schema.Column{
+		"users": {{Name: "id", Type: "INTEGER", IsPK: true}},
+	}}
+
+	model, cmd := m.Update(ProfileTableMsg{Database: "app", Schema: "main", Table: "users"})
+	m = model.(Model)
+	if !m.profiler.Visible() || !m.profiler.Loading() {
+		t.Fatal("the profile should open, loading")
+	}
+	msg := cmd()
+	if len(tc.executed) != 2 || !strings.Contains(tc.executed[0], `FROM (SELECT * FROM "users" LIMIT 10000)`) {
+		t.Fatalf("ran %q, want the profile of a sample of users and its top values", tc.executed)
+	}
+	m = step(m, msg)
+	if m.profiler.Loading() || m.profileCancel != nil {
+		t.Error("the finished profile was not shown and released")
+	}
+	if view := m.profiler.View(); !strings.Contains(view, "3 rows profiled") {
+		t.Errorf("view lacks the profile:\n%s", view)
+	}
+
+	// A profile closed while loading is dropped.
+	model, cmd = m.Update(ProfileTableMsg{Database: "app", Schema: "main", Table: "users"})
+	m = step(model.(Model), tea.KeyMsg{Type: tea.KeyEsc})
+	if m.profiler.Visible() || m.profileCancel != nil {
+		t.Error("esc should close the profile and stop it")
+	}
+	if m = step(m, cmd()); m.profiler.Visible() {
+		t.Error("a profile arrived after it was closed")
+	}
+}
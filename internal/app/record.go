@@ -0,0 +1,91 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/audit"
+	"github.com/sadopc/gotermsql/internal/history"
+)
+
+// writeRecorder adds the statements a form, wizard or background task runs
+// on one connection to the history and the audit log. It only reads what
+// it holds, so a task running in the background records each statement as
+// it runs.
+type writeRecorder struct {
+	history   *history.History
+	audit     *audit.Logger
+	redactor  *adapter.Redactor
+	connected bool
+	dialect   string
+	database  string
+	dsn       string
+}
+
+// writes returns the recorder of the statements run on the connection
+// open, which records nothing when there is none.
+func (m *Model) writes() writeRecorder {
+	r := writeRecorder{history: m.history, audit: m.audit, redactor: m.redactor}
+	if m.conn == nil {
+		return r
+	}
+	return r.on(m.conn, m.dsn)
+}
+
+// on returns r for the statements run on conn, opened with dsn, which is
+// logged without its credentials.
+func (r writeRecorder) on(conn adapter.Connection, dsn string) writeRecorder {
+	r.connected, r.dialect, r.database, r.dsn = true, conn.AdapterName(), conn.DatabaseName(), audit.SanitizeDSN(dsn)
+	return r
+}
+
+// record adds query, which ran in duration and touched rowCount rows.
+func (r writeRecorder) record(query string, duration time.Duration, rowCount int64) {
+	if !r.connected {
+		return
+	}
+	saved := r.redactor.Redact(r.dialect, query)
+	if r.history != nil {
+		_ = r.history.Add(history.HistoryEntry{
+			Query:        saved,
+			Adapter:      r.dialect,
+			DatabaseName: r.database,
+			ExecutedAt:   time.Now(),
+			DurationMS:   duration.Milliseconds(),
+			RowCount:     rowCount,
+		})
+	}
+	r.audit.Log(audit.Entry{
+		Timestamp:    time.Now(),
+		Query:        saved,
+		Adapter:      r.dialect,
+		DatabaseName: r.database,
+		DurationMS:   duration.Milliseconds(),
+		RowCount:     rowCount,
+		DSN:          r.dsn,
+	})
+}
+
+// execute runs query on conn and records it once it has run.
+func (r writeRecorder) execute(ctx context.Context, conn adapter.Connection, query string) (*adapter.QueryResult, error) {
+	res, err := conn.Execute(ctx, query)
+	if err == nil && res != nil {
+		r.record(query, res.Duration, res.RowCount)
+	}
+	return res, err
+}
+
+// recording returns ctx telling r of the statements an optional interface
+// runs on its own (adapter.Record).
+func (r writeRecorder) recording(ctx context.Context) context.Context {
+	return adapter.WithRecorder(ctx, func(query string, duration time.Duration) {
+		r.record(query, duration, -1)
+	})
+}
+
+// recordWrite adds a statement a form or wizard ran to the history and the
+// audit log.
+func (m *Model) recordWrite(query string, duration time.Duration, rowCount int64) {
+	m.writes().record(query, duration, rowCount)
+}
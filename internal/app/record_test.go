@@ -0,0 +1,102 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/audit"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/history"
+)
+
+// recordingModel returns a model with a history and an audit log, and the
+// path of the log.
+func recordingModel(t *testing.T) (Model, *history.History, string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	hist, err := history.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { hist.Close() })
+	path := filepath.Join(home, "audit.jsonl")
+	log, err := audit.New(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { log.Close() })
+	return New(config.DefaultConfig(), hist, log), hist, path
+}
+
+func TestWriteRecorder(t *testing.T) {
+	m, hist, auditPath := recordingModel(t)
+	m.writes().record("DELETE FROM t", time.Millisecond, 3)
+	if entries, _ := hist.Recent(10); len(entries) != 0 {
+		t.Errorf("recorded %+v with no connection", entries)
+	}
+
+	dir := t.TempDir()
+	sc := openSQLite(t, filepath.Join(dir, "shop.db"),
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, total REAL)")
+	conn, closeConn, err := connectSaved(context.Background(), sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeConn()
+	m.conn, m.dsn = conn, sc.BuildDSN()
+
+	// A task records the statements it runs as they run.
+	ctx := context.Background()
+	if err := fillTable(ctx, conn, m.writes(), GenerateDataMsg{Schema: "main", Table: "orders"}, 3, &rowProgress{}); err != nil {
+		t.Fatal(err)
+	}
+	// An optional interface tells of those it runs on its own.
+	adapter.Record(m.writes().recording(ctx), `VACUUM "main"`, time.Now())
+	// A scheduled query is recorded as run on its own connection.
+	if _, err := runScheduled(sc, "DELETE FROM orders WHERE id = 1", 0, m.writes()); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := hist.Recent(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || entries[0].Query != "DELETE FROM orders WHERE id = 1" || entries[1].Query != `VACUUM "main"` ||
+		!strings.HasPrefix(entries[2].Query, `INSERT INTO "main"."orders"`) || entries[2].RowCount != 3 || entries[2].Adapter != "sqlite" {
+		t.Errorf("history = %+v, want the INSERT, the VACUUM and the DELETE", entries)
+	}
+	data, err := os.ReadFile(auditPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 3 || !strings.Contains(string(data), "VACUUM") {
+		t.Errorf("audit log = %s, want the three statements", data)
+	}
+}
+
+func TestCellUpdatedRecorded(t *testing.T) {
+	m, hist, _ := recordingModel(t)
+	sc := openSQLite(t, filepath.Join(t.TempDir(), "shop.db"), "CREATE TABLE orders (id INTEGER PRIMARY KEY)")
+	conn, closeConn, err := connectSaved(context.Background(), sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeConn()
+	m.conn, m.dsn = conn, sc.BuildDSN()
+
+	m.handleCellUpdated(cellUpdatedMsg{Query: "UPDATE orders SET id = 2 WHERE id = 1", Duration: time.Millisecond, RowCount: 1, ConnGen: m.connGen})
+	entries, err := hist.Recent(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Query != "UPDATE orders SET id = 2 WHERE id = 1" || entries[0].RowCount != 1 || entries[0].Adapter != "sqlite" {
+		t.Errorf("history = %+v, want the UPDATE", entries)
+	}
+}
@@ -0,0 +1,241 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/ddl"
+	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/ui/rowform"
+)
+
+// rowFormTimeout bounds reading the columns of the form, a lookup and the
+// INSERT.
+const rowFormTimeout = 30 * time.Second
+
+// rowLookupLimit caps the values a foreign key lookup lists.
+const rowLookupLimit = 1000
+
+// rowFormLoadedMsg carries the fields of the insert form of request, read
+// on connection generation connGen.
+type rowFormLoadedMsg struct {
+	request InsertRowMsg
+	fields  []rowform.Field
+	err     error
+	connGen uint64
+}
+
+// rowLookupMsg carries the values a field of the insert form may take.
+type rowLookupMsg struct {
+	field   int
+	options []rowform.Option
+	err     error
+	connGen uint64
+}
+
+// rowInsertedMsg reports the INSERT of the form ran.
+type rowInsertedMsg struct {
+	query    string
+	rowCount int64
+	duration time.Duration
+	err      error
+	connGen  uint64
+}
+
+// openRowForm reads the columns and foreign keys of a table in the
+// background, to open the form inserting a row into it.
+func (m *Model) openRowForm(msg InsertRowMsg) tea.Cmd {
+	if m.conn == nil {
+		return m.toast(ToastError, "Not connected")
+	}
+	if m.safeMode {
+		return m.toast(ToastError, safeModeBlocked)
+	}
+	m.rowFormFor = msg
+	conn, connGen := m.conn, m.connGen
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), rowFormTimeout)
+		defer cancel()
+		loaded := rowFormLoadedMsg{request: msg, connGen: connGen}
+		cols, err := conn.Columns(ctx, msg.Database, msg.Schema, msg.Table)
+		if err != nil {
+			loaded.err = err
+			return loaded
+		}
+		fks, err := conn.ForeignKeys(ctx, msg.Database, msg.Schema, msg.Table)
+		if err != nil {
+			loaded.err = err
+			return loaded
+		}
+		loaded.fields = rowFields(cols, fks)
+		return loaded
+	}
+}
+
+// rowFields makes a field of each column, with what its foreign key
+// references. Only a foreign key of one column can be looked up.
+func rowFields(cols []schema.Column, fks []schema.ForeignKey) []rowform.Field {
+	fields := make([]rowform.Field, len(cols))
+	for i, c := range cols {
+		fields[i].Column = c
+		for _, fk := range fks {
+			if len(fk.Columns) == 1 && len(fk.RefColumns) == 1 && strings.EqualFold(fk.Columns[0], c.Name) {
+				fields[i].RefTable, fields[i].RefColumn = fk.RefTable, fk.RefColumns[0]
+				break
+			}
+		}
+	}
+	return fields
+}
+
+// handleRowFormLoaded opens the form, unless another was asked for since.
+func (m *Model) handleRowFormLoaded(msg rowFormLoadedMsg) tea.Cmd {
+	if msg.connGen != m.connGen || msg.request != m.rowFormFor {
+		return nil
+	}
+	if msg.err != nil {
+		return m.toast(ToastError, "Could not read the columns: "+sanitizeError(msg.err.Error()))
+	}
+	if len(msg.fields) == 0 {
+		return m.toast(ToastError, msg.request.Table+" has no columns")
+	}
+	m.rowForm.Show(msg.request.Table, msg.fields)
+	return nil
+}
+
+// rowFormTable returns the table of the form quoted for dialect, or
+// another table of its schema.
+func (m *Model) rowFormTable(dialect, table string) string {
+	name := adapter.QuoteIdentifier(dialect, table)
+	if m.rowFormFor.Schema != "" {
+		name = adapter.QuoteIdentifier(dialect, m.rowFormFor.Schema) + "." + name
+	}
+	return name
+}
+
+// lookupRow reads the values of the column a foreign key of the form
+// references, each with the rest of its row, in the background.
+func (m *Model) lookupRow(msg rowform.LookupMsg) tea.Cmd {
+	if m.conn == nil {
+		return nil
+	}
+	conn, connGen := m.conn, m.connGen
+	dialect := conn.AdapterName()
+	table := m.rowFormTable(dialect, msg.Table)
+	query := fmt.Sprintf("SELECT %s, %s.* FROM %s ORDER BY 1 LIMIT %d",
+		adapter.QuoteIdentifier(dialect, msg.Column), adapter.QuoteIdentifier(dialect, msg.Table), table, rowLookupLimit)
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(adapter.OnPool(context.Background()), rowFormTimeout)
+		defer cancel()
+		res, err := conn.Execute(ctx, query)
+		if err != nil {
+			return rowLookupMsg{field: msg.Field, err: err, connGen: connGen}
+		}
+		options := make([]rowform.Option, 0, len(res.Rows))
+		for _, row := range res.Rows {
+			if len(row) == 0 {
+				continue
+			}
+			options = append(options, rowform.Option{Value: row[0], Label: strings.Join(row[1:], "  ")})
+		}
+		return rowLookupMsg{field: msg.Field, options: options, connGen: connGen}
+	}
+}
+
+// handleRowLookup lists the values looked up.
+func (m *Model) handleRowLookup(msg rowLookupMsg) {
+	if msg.connGen != m.connGen {
+		return
+	}
+	if msg.err != nil {
+		m.rowForm.SetLookupError(msg.field, "Lookup failed: "+sanitizeError(msg.err.Error()))
+		return
+	}
+	m.rowForm.SetLookup(msg.field, msg.options)
+}
+
+// buildRowInsert writes the INSERT of the values of the form, each as a
+// literal of its column's type.
+func buildRowInsert(dialect, table string, cols []schema.Column, values []string) (string, error) {
+	if len(cols) == 0 {
+		if dialect == "mysql" {
+			return "INSERT INTO " + table + " () VALUES ()", nil
+		}
+		return "INSERT INTO " + table + " DEFAULT VALUES", nil
+	}
+	names := make([]string, len(cols))
+	literals := make([]string, len(cols))
+	for i, c := range cols {
+		lit, err := ddl.Literal(dialect, c.Type, values[i])
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", c.Name, err)
+		}
+		names[i] = adapter.QuoteIdentifier(dialect, c.Name)
+		literals[i] = lit
+	}
+	return "INSERT INTO " + table + " (" + strings.Join(names, ", ") + ") VALUES (" + strings.Join(literals, ", ") + ")", nil
+}
+
+// insertRow runs the INSERT of the form, in the transaction open or one
+// opened for it when autocommit is off.
+func (m *Model) insertRow(msg rowform.SubmitMsg) tea.Cmd {
+	if m.conn == nil {
+		m.rowForm.SetError("Not connected")
+		return nil
+	}
+	if m.safeMode {
+		m.rowForm.SetError(safeModeBlocked)
+		return nil
+	}
+	dialect := m.conn.AdapterName()
+	stmt, err := buildRowInsert(dialect, m.rowFormTable(dialect, m.rowForm.Table()), msg.Columns, msg.Values)
+	if err != nil {
+		m.rowForm.SetError(err.Error())
+		return nil
+	}
+
+	conn, gen, tracer, begin := m.conn, m.connGen, m.tracer, m.beginTx()
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), rowFormTimeout)
+		defer cancel()
+		if begin != nil {
+			if err := begin(ctx); err != nil {
+				return rowInsertedMsg{query: stmt, err: err, connGen: gen}
+			}
+		}
+		start := time.Now()
+		span, sent := tracer.Start(stmt, conn.AdapterName(), conn.DatabaseName())
+		res, err := conn.Execute(ctx, sent)
+		done := rowInsertedMsg{query: stmt, duration: time.Since(start), err: err, connGen: gen}
+		if res != nil {
+			done.rowCount = res.RowCount
+		}
+		if err != nil {
+			span.End(-1, err)
+		} else {
+			span.End(done.rowCount, nil)
+		}
+		return done
+	}
+}
+
+// handleRowInserted records the INSERT and closes the form, or shows why
+// it failed in the form.
+func (m *Model) handleRowInserted(msg rowInsertedMsg) tea.Cmd {
+	if msg.connGen != m.connGen {
+		return nil
+	}
+	m.syncTransaction()
+	if msg.err != nil {
+		m.auditLog(msg.query, msg.duration.Milliseconds(), 0, true)
+		m.rowForm.SetError("Insert failed: " + sanitizeError(msg.err.Error()))
+		return nil
+	}
+	m.recordWrite(msg.query, msg.duration, msg.rowCount)
+	m.rowForm.Hide()
+	return m.toast(ToastSuccess, "Row inserted into "+m.rowForm.Table())
+}
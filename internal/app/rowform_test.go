@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/ui/rowform"
+)
+
+func TestBuildRowInsert(t *testing.T) {
+	cols := []schema.Column{{Name: "id", Type: "integer"}, {Name: "note", Type: "text"}}
+	got, err := buildRowInsert("postgres", `"public"."orders"`, cols, []string{"7", "it's"})
+	if want := `INSERT INTO "public"."orders" ("id", "note") VALUES (7, 'it''s')`; err != nil || got != want {
+		t.Errorf("buildRowInsert = %s, %v; want %s", got, err, want)
+	}
+	if _, err := buildRowInsert("postgres", "t", cols, []string{"seven", "x"}); err == nil || !strings.HasPrefix(err.Error(), "id: ") {
+		t.Errorf("a word for id should fail naming it, got %v", err)
+	}
+	for dialect, want := range map[string]string{"postgres": "INSERT INTO t DEFAULT VALUES", "mysql": "INSERT INTO t () VALUES ()"} {
+		if got, _ := buildRowInsert(dialect, "t", nil, nil); got != want {
+			t.Errorf("buildRowInsert(%s) with no values = %s, want %s", dialect, got, want)
+		}
+	}
+}
+
+func TestRowForm(t *testing.T) {
+	sc := openSQLite(t, filepath.Join(t.TempDir(), "shop.db"),
+		"CREATE TABLE customers (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+		"INSERT INTO customers VALUES (1, 'ann'), (2, 'bob')",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, customer_id INTEGER NOT NULL REFERENCES customers(id), total NUMERIC, status TEXT NOT NULL DEFAULT 'new')")
+	conn, closeConn, err := connectSaved(context.Background(), sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeConn()
+	m := step(New(config.DefaultConfig(), nil, nil), tea.WindowSizeMsg{Width: 140, Height: 40})
+	m.conn = conn
+
+	m = step(m, InsertRowMsg{Schema: "main", Table: "orders"})
+	if !m.rowForm.Visible() || !strings.Contains(m.rowForm.View(), "→ customers.id") {
+		t.Fatalf("the form should open with the foreign key:\n%s", m.rowForm.View())
+	}
+	press := func(keys ...tea.KeyMsg) tea.Cmd {
+		var cmd tea.Cmd
+		for _, key := range keys {
+			var model tea.Model
+			model, cmd = m.Update(key)
+			m = model.(Model)
+		}
+		return cmd
+	}
+
+	// Look customer_id up and pick bob.
+	press(tea.KeyMsg{Type: tea.KeyTab})
+	m = step(m, press(tea.KeyMsg{Type: tea.KeyCtrlF})())
+	if view := m.rowForm.View(); !strings.Contains(view, "bob") {
+		t.Fatalf("the lookup should list the customers:\n%s", view)
+	}
+	press(tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyEnter})
+
+	press(tea.KeyMsg{Type: tea.KeyTab}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("lots")})
+	m = step(m, press(tea.KeyMsg{Type: tea.KeyEnter})())
+	if view := m.rowForm.View(); !m.rowForm.Visible() || !strings.Contains(view, `total: "lots" is not a number`) {
+		t.Fatalf("a word for total should be refused:\n%s", view)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyCtrlU}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("9.5")})
+	submit, ok := press(tea.KeyMsg{Type: tea.KeyEnter})().(rowform.SubmitMsg)
+	if !ok {
+		t.Fatal("enter should submit the row")
+	}
+	m = step(m, submit)
+	if m.rowForm.Visible() {
+		t.Fatalf("the form should close once the row is inserted:\n%s", m.rowForm.View())
+	}
+
+	res, err := conn.Execute(context.Background(), "SELECT id || customer_id || total || status FROM orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Rows) != 1 || res.Rows[0][0] != "129.5new" {
+		t.Errorf("orders = %v, want the row inserted", res.Rows)
+	}
+}
+
+func TestRowForm_SafeMode(t *testing.T) {
+	m := step(New(config.DefaultConfig(), nil, nil), tea.WindowSizeMsg{Width: 140, Height: 40})
+	m.conn = &testConn{dbName: "app"}
+	m.safeMode = true
+	model, _ := m.Update(InsertRowMsg{Schema: "main", Table: "orders"})
+	if model.(Model).rowFormFor != (InsertRowMsg{}) {
+		t.Error("safe mode should keep the insert form closed")
+	}
+}
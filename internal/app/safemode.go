@@ -0,0 +1,21 @@
+package app
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// safeModeBlocked is the status shown when safe mode stops a statement.
+const safeModeBlocked = "Safe mode: only read-only statements can run (F3 to turn off)"
+
+// toggleSafeMode turns safe mode on or off. While it is on, only
+// statements adapter.IsReadOnlyQuery accepts are run, whatever the
+// database, and grid edits are refused.
+func (m *Model) toggleSafeMode() tea.Cmd {
+	m.safeMode = !m.safeMode
+	m.statusbar.SetSafeMode(m.safeMode)
+	text := "Safe mode off"
+	if m.safeMode {
+		text = "Safe mode on: only read-only statements will run"
+	}
+	var cmd tea.Cmd
+	m.statusbar, cmd = m.statusbar.Update(StatusMsg{Text: text})
+	return cmd
+}
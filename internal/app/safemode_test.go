@@ -0,0 +1,59 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+func TestSafeMode_BlocksWrites(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	m.conn = &testConn{dbName: "app"}
+	m.statusbar.SetSize(120)
+	tabID := m.tabs.ActiveID()
+	// executeQuery bumps the tab's run ID as soon as it starts a query.
+	run := func(query string) bool {
+		before := m.tabStates[tabID].RunID
+		model, _ := m.Update(ExecuteQueryMsg{Query: query, TabID: tabID})
+		m = model.(Model)
+		return m.tabStates[tabID].RunID != before
+	}
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyF3})
+	m = model.(Model)
+	if !m.safeMode {
+		t.Fatal("F3 should turn safe mode on")
+	}
+	if !strings.Contains(m.statusbar.View(), "SAFE") {
+		t.Error("status bar should show the safe mode indicator")
+	}
+
+	for _, q := range []string{"DELETE FROM users", "SELECT 1; DROP TABLE users"} {
+		if run(q) {
+			t.Errorf("safe mode ran %q", q)
+		}
+	}
+	if !run("SELECT 1") {
+		t.Error("safe mode should run SELECT 1")
+	}
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyF3})
+	m = model.(Model)
+	if strings.Contains(m.statusbar.View(), "SAFE") {
+		t.Error("the indicator should go when safe mode is off")
+	}
+	if !run("DELETE FROM users") {
+		t.Error("with safe mode off, DELETE should run")
+	}
+}
+
+func TestSafeMode_FromConfig(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.SafeMode = true
+	m := New(cfg, nil, nil)
+	if !m.safeMode {
+		t.Error("safe_mode: true should start in safe mode")
+	}
+}
@@ -0,0 +1,274 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/keychain"
+	"github.com/sadopc/gotermsql/internal/library"
+	"github.com/sadopc/gotermsql/internal/theme"
+	"github.com/sadopc/gotermsql/internal/tunnel"
+)
+
+const (
+	// scheduleLogSize is how many runs the schedule log keeps.
+	scheduleLogSize = 100
+	// scheduleLogRows is how many rows of a result the log shows.
+	scheduleLogRows = 10
+	// scheduleLogTitle is the title of the viewer showing the log.
+	scheduleLogTitle = "Scheduled Runs"
+)
+
+// schedule is a library query that runs by itself every so often.
+type schedule struct {
+	query   library.Query
+	gen     uint64 // changes with the query, so ticks set before are dropped
+	running bool
+}
+
+// scheduledRun is a run in the schedule log.
+type scheduledRun struct {
+	at     time.Time
+	query  library.Query
+	result *adapter.QueryResult
+	err    error
+}
+
+// schedulesLoadedMsg carries the library read on startup.
+type schedulesLoadedMsg struct {
+	queries []library.Query
+}
+
+// scheduleTickMsg asks for the scheduled query key to run.
+type scheduleTickMsg struct {
+	key string
+	gen uint64
+}
+
+// scheduleRanMsg reports a run of the scheduled query key.
+type scheduleRanMsg struct {
+	key string
+	gen uint64
+	run scheduledRun
+}
+
+// scheduleKey identifies a query across the personal and shared libraries.
+func scheduleKey(q library.Query) string {
+	return q.Source + "\x00" + q.Path()
+}
+
+// LoadSchedules reads the query library in the background to start the
+// queries scheduled in it.
+func (m Model) LoadSchedules() tea.Cmd {
+	return func() tea.Msg {
+		queries, _, err := m.loadLibrary()
+		if err != nil {
+			return StatusMsg{Text: "Scheduled queries not started: " + err.Error(), IsError: true}
+		}
+		return schedulesLoadedMsg{queries: queries}
+	}
+}
+
+// setSchedules starts the scheduled queries among queries that are new or
+// changed, and stops those no longer scheduled.
+func (m *Model) setSchedules(queries []library.Query) tea.Cmd {
+	if m.schedules == nil {
+		m.schedules = make(map[string]*schedule)
+	}
+	var cmds []tea.Cmd
+	seen := make(map[string]bool)
+	for _, q := range queries {
+		if !q.Scheduled() {
+			continue
+		}
+		key := scheduleKey(q)
+		seen[key] = true
+		s := m.schedules[key]
+		if s != nil && s.query.SQL == q.SQL && s.query.Every == q.Every && s.query.Connection == q.Connection {
+			continue
+		}
+		m.scheduleGen++
+		m.schedules[key] = &schedule{query: q, gen: m.scheduleGen}
+		cmds = append(cmds, scheduleTick(key, m.scheduleGen, q.Every))
+	}
+	for key := range m.schedules {
+		if !seen[key] {
+			delete(m.schedules, key)
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+func scheduleTick(key string, gen uint64, every time.Duration) tea.Cmd {
+	return tea.Tick(every, func(time.Time) tea.Msg {
+		return scheduleTickMsg{key: key, gen: gen}
+	})
+}
+
+// handleScheduleTick runs a scheduled query on a connection of its own,
+// unless its last run is still going. Safe mode holds for it too.
+func (m *Model) handleScheduleTick(msg scheduleTickMsg) tea.Cmd {
+	s := m.schedules[msg.key]
+	if s == nil || s.gen != msg.gen || s.running {
+		return nil
+	}
+	q := s.query
+	if m.safeMode && !adapter.IsReadOnlyQuery(q.SQL) {
+		run := scheduledRun{at: time.Now(), query: q, err: fmt.Errorf("skipped: %s", safeModeBlocked)}
+		return func() tea.Msg { return scheduleRanMsg{key: msg.key, gen: msg.gen, run: run} }
+	}
+	s.running = true
+	sc := m.cfg.FindConnection(q.Connection)
+	if sc == nil {
+		run := scheduledRun{at: time.Now(), query: q, err: fmt.Errorf("no saved connection called %q", q.Connection)}
+		return func() tea.Msg { return scheduleRanMsg{key: msg.key, gen: msg.gen, run: run} }
+	}
+	saved := *sc
+	timeout := queryTimeoutFor(m.cfg.QueryTimeout, saved.Defaults)
+	writes := m.writes()
+	return func() tea.Msg {
+		res, err := runScheduled(saved, q.SQL, timeout, writes)
+		return scheduleRanMsg{key: msg.key, gen: msg.gen, run: scheduledRun{at: time.Now(), query: q, result: res, err: err}}
+	}
+}
+
+// runScheduled opens the saved connection sc, runs query on it within
+// timeout, if any, recording it with writes, and closes it again.
+func runScheduled(sc config.SavedConnection, query string, timeout time.Duration, writes writeRecorder) (*adapter.QueryResult, error) {
+	sc, err := sc.Expand()
+	if err != nil {
+		return nil, err
+	}
+	if err := keychain.Resolve(&sc); err != nil {
+		return nil, err
+	}
+	dialCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	dsn, tun, err := tunnel.ForConnection(dialCtx, sc)
+	if err != nil {
+		return nil, err
+	}
+	if tun != nil {
+		defer tun.Close()
+	}
+	conn, err := openConnection(dialCtx, sc.Adapter, dsn, sessionInit(sc))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return writes.on(conn, dsn).execute(ctx, conn, query)
+}
+
+// handleScheduleRan logs a scheduled run, reports a failure and sets the
+// next run.
+func (m *Model) handleScheduleRan(msg scheduleRanMsg) tea.Cmd {
+	m.scheduleLog = append([]scheduledRun{msg.run}, m.scheduleLog...)
+	if len(m.scheduleLog) > scheduleLogSize {
+		m.scheduleLog = m.scheduleLog[:scheduleLogSize]
+	}
+	if m.viewer.Visible() && m.viewer.Title() == scheduleLogTitle {
+		m.showScheduleLog()
+	}
+
+	var cmds []tea.Cmd
+	if s := m.schedules[msg.key]; s != nil && s.gen == msg.gen {
+		s.running = false
+		cmds = append(cmds, scheduleTick(msg.key, s.gen, s.query.Every))
+	}
+	if msg.run.err != nil {
+		text := fmt.Sprintf("Scheduled %s failed: %s (Alt+J shows the log)", msg.run.query.Path(), sanitizeError(msg.run.err.Error()))
+		cmds = append(cmds, m.toast(ToastError, text))
+	}
+	return tea.Batch(cmds...)
+}
+
+// openScheduleLog shows the log of scheduled runs (Alt+J).
+func (m *Model) openScheduleLog() tea.Cmd {
+	if len(m.schedules) == 0 && len(m.scheduleLog) == 0 {
+		return exStatus("No scheduled queries; set Run every on a query in the library (Ctrl+L)", false)
+	}
+	m.showScheduleLog()
+	return nil
+}
+
+// showScheduleLog opens the viewer on the schedule log, newest run first,
+// each with the start of its result.
+func (m *Model) showScheduleLog() {
+	th := theme.Current
+	var lines []string
+	var styles []lipgloss.Style
+	add := func(style lipgloss.Style, text ...string) {
+		for _, t := range text {
+			lines = append(lines, t)
+			styles = append(styles, style)
+		}
+	}
+
+	if len(m.scheduleLog) == 0 {
+		add(th.MutedText, "No runs yet.")
+	}
+	for i, run := range m.scheduleLog {
+		if i > 0 {
+			add(lipgloss.NewStyle(), "")
+		}
+		head := fmt.Sprintf("%s  %s on %s", run.at.Format("2006-01-02 15:04:05"), run.query.Path(), run.query.Connection)
+		res := run.result
+		switch {
+		case run.err != nil:
+			add(th.ErrorText, head+": "+sanitizeError(run.err.Error()))
+			continue
+		case res == nil:
+			add(th.SuccessText, head)
+			continue
+		case len(res.Columns) == 0:
+			add(th.SuccessText, fmt.Sprintf("%s: %s in %s", head, res.Message, res.Duration.Round(time.Millisecond)))
+			continue
+		}
+		add(th.SuccessText, fmt.Sprintf("%s: %d rows in %s", head, len(res.Rows), res.Duration.Round(time.Millisecond)))
+		add(lipgloss.NewStyle(), resultTable(res, scheduleLogRows)...)
+		if more := len(res.Rows) - scheduleLogRows; more > 0 {
+			add(th.MutedText, fmt.Sprintf("    … %d more rows", more))
+		}
+	}
+	m.viewer.ShowStyled(scheduleLogTitle, strings.Join(lines, "\n"), styles)
+}
+
+// resultTable lays out the columns and first n rows of res as text,
+// indented under a log entry.
+func resultTable(res *adapter.QueryResult, n int) []string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	names := make([]string, len(res.Columns))
+	for i, c := range res.Columns {
+		names[i] = c.Name
+	}
+	fmt.Fprintln(w, "    "+strings.Join(names, "\t"))
+	for _, row := range res.Rows[:min(n, len(res.Rows))] {
+		cells := make([]string, len(row))
+		for i, v := range row {
+			cells[i] = strings.Map(func(r rune) rune {
+				if r == '\n' || r == '\t' {
+					return ' '
+				}
+				return r
+			}, v)
+		}
+		fmt.Fprintln(w, "    "+strings.Join(cells, "\t"))
+	}
+	w.Flush()
+	return strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/library"
+)
+
+// runSchedule runs the scheduled query key as its tick would, returning the
+// run logged.
+func runSchedule(t *testing.T, m *Model, key string) scheduledRun {
+	t.Helper()
+	s := m.schedules[key]
+	if s == nil {
+		t.Fatalf("%q is not scheduled", key)
+	}
+	cmd := m.handleScheduleTick(scheduleTickMsg{key: key, gen: s.gen})
+	if cmd == nil {
+		t.Fatal("the tick ran nothing")
+	}
+	ran, ok := cmd().(scheduleRanMsg)
+	if !ok {
+		t.Fatal("the tick did not report a run")
+	}
+	m.handleScheduleRan(ran)
+	return m.scheduleLog[0]
+}
+
+func TestSchedules(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Connections = []config.SavedConnection{
+		{Name: "local", Adapter: "sqlite", File: filepath.Join(t.TempDir(), "app.db")},
+	}
+	m := step(New(cfg, nil, nil), tea.WindowSizeMsg{Width: 160, Height: 40})
+
+	if cmd := m.openScheduleLog(); cmd == nil || m.viewer.Visible() {
+		t.Error("with nothing scheduled, expected a status rather than the log")
+	}
+
+	count := library.Query{Name: "count", Folder: "checks", SQL: "SELECT 1 AS n UNION SELECT 2", Every: time.Minute, Connection: "local"}
+	lost := library.Query{Name: "lost", SQL: "SELECT 1", Every: time.Hour, Connection: "gone"}
+	write := library.Query{Name: "write", SQL: "CREATE TABLE t (a INT)", Every: time.Hour, Connection: "local"}
+	m.setSchedules([]library.Query{count, lost, write, {Name: "plain", SQL: "SELECT 1"}})
+	if len(m.schedules) != 3 {
+		t.Fatalf("scheduled %d queries, want 3", len(m.schedules))
+	}
+
+	run := runSchedule(t, &m, scheduleKey(count))
+	if run.err != nil || run.result == nil || len(run.result.Rows) != 2 {
+		t.Fatalf("run = %+v, want 2 rows", run)
+	}
+	if run := runSchedule(t, &m, scheduleKey(lost)); run.err == nil || !strings.Contains(run.err.Error(), `"gone"`) {
+		t.Errorf("err = %v, want the missing connection", run.err)
+	}
+	m.safeMode = true
+	if run := runSchedule(t, &m, scheduleKey(write)); run.err == nil || !strings.Contains(run.err.Error(), "Safe mode") {
+		t.Errorf("err = %v, want safe mode to skip the write", run.err)
+	}
+
+	m.openScheduleLog()
+	view := m.viewer.View()
+	for _, want := range []string{"checks/count on local: 2 rows", "lost on gone: no saved connection", "write on local: skipped"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("log lacks %q:\n%s", want, view)
+		}
+	}
+
+	// A tick set before the query changed is dropped, as is one for a
+	// query no longer scheduled.
+	old := m.schedules[scheduleKey(count)].gen
+	count.SQL = "SELECT 3"
+	m.setSchedules([]library.Query{count})
+	if cmd := m.handleScheduleTick(scheduleTickMsg{key: scheduleKey(count), gen: old}); cmd != nil {
+		t.Error("a stale tick ran the query")
+	}
+	if _, ok := m.schedules[scheduleKey(lost)]; ok {
+		t.Error("expected the unscheduled query to stop")
+	}
+}
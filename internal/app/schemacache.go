@@ -0,0 +1,61 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/completion"
+	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/schemacache"
+	"github.com/sadopc/gotermsql/internal/ui/historybrowser"
+)
+
+// SetSchemaCache sets the cache the schema of each connection is kept in
+// between runs; nil turns it off.
+func (m *Model) SetSchemaCache(c *schemacache.Cache) {
+	m.schemaCache = c
+}
+
+// loadCachedSchema reads the cached schema of the connection in the
+// background, replying with a SchemaLoadedMsg marked Cached, or nothing.
+func (m *Model) loadCachedSchema() tea.Cmd {
+	cache, key, gen := m.schemaCache, m.favoritesKey(), m.connGen
+	if cache == nil || key == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		e, err := cache.Load(key)
+		if err != nil || e == nil {
+			return nil // the schema loads anyway
+		}
+		return SchemaLoadedMsg{Databases: e.Databases, ConnGen: gen, Lazy: e.Lazy, Hash: e.Hash, Cached: e.Saved}
+	}
+}
+
+// showCachedSchema paints the sidebar and fills completion from the
+// cached schema, unless the schema has loaded first.
+func (m *Model) showCachedSchema(msg SchemaLoadedMsg) tea.Cmd {
+	if m.schemaHash != "" {
+		return nil
+	}
+	m.schemaHash = msg.Hash
+	m.databases = msg.Databases
+	var cmd tea.Cmd
+	m.sidebar, cmd = m.sidebar.Update(msg)
+	m.setCompletionSchema(msg.Databases)
+	var sbCmd tea.Cmd
+	m.statusbar, sbCmd = m.statusbar.Update(StatusMsg{
+		Text: "Schema from cache (saved " + historybrowser.RelativeTime(msg.Cached) + "), refreshing...",
+	})
+	return tea.Batch(cmd, sbCmd)
+}
+
+// setCompletionSchema points completion at databases.
+func (m *Model) setCompletionSchema(databases []schema.Database) {
+	if m.conn != nil {
+		m.compEngine = completion.NewEngine(m.conn.AdapterName())
+		m.compEngine.UpdateSchema(databases)
+		m.autocomp.SetEngine(m.compEngine)
+		m.objSearch.SetEngine(m.compEngine)
+	} else {
+		m.compEngine.UpdateSchema(databases)
+	}
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/schemacache"
+)
+
+func TestSchemaCache(t *testing.T) {
+	m := step(New(config.DefaultConfig(), nil, nil), tea.WindowSizeMsg{Width: 120, Height: 40})
+	m.conn = sqliteConn{&testConn{dbName: "app"}}
+	m.dsn = "app.db"
+	cache := schemacache.New(t.TempDir(), time.Hour)
+	m.SetSchemaCache(cache)
+
+	cached := []schema.Database{{Name: "app", Schemas: []schema.Schema{{Name: "main", Tables: []schema.Table{{Name: "orders"}}}}}}
+	if err := cache.Save(schemacache.Entry{Key: "app.db", Databases: cached}); err != nil {
+		t.Fatal(err)
+	}
+	msg, ok := m.loadCachedSchema()().(SchemaLoadedMsg)
+	if !ok || msg.Cached.IsZero() {
+		t.Fatalf("loadCachedSchema = %#v", msg)
+	}
+	m = step(m, msg)
+	if len(m.databases) != 1 || m.schemaHash != schemacache.Hash(cached) {
+		t.Fatalf("the cached schema was not shown: %v", m.databases)
+	}
+
+	// The same schema loaded again leaves what is shown alone.
+	m = step(m, SchemaLoadedMsg{Hash: m.schemaHash})
+	if len(m.databases) != 1 {
+		t.Error("an unchanged schema replaced the one shown")
+	}
+	// A cached schema arriving after the loaded one is dropped.
+	m = step(m, msg)
+	m = step(m, SchemaLoadedMsg{Hash: "changed"})
+	if len(m.databases) != 0 || m.schemaHash != "changed" {
+		t.Errorf("a changed schema was not shown: %v", m.databases)
+	}
+	m = step(m, msg)
+	if len(m.databases) != 0 {
+		t.Error("a late cached schema replaced the loaded one")
+	}
+
+	// Loading the schema saves it for next time.
+	m.loadSchema()()
+	if e, err := cache.Load("app.db"); err != nil || e == nil || len(e.Databases) != 0 {
+		t.Errorf("Load = %#v, %v, want the empty schema loaded", e, err)
+	}
+}
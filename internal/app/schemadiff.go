@@ -0,0 +1,269 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/ddl"
+	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/schemacache"
+	"github.com/sadopc/gotermsql/internal/ui/connmgr"
+	"github.com/sadopc/gotermsql/internal/ui/historybrowser"
+	"github.com/sadopc/gotermsql/internal/ui/schemadiff"
+)
+
+// schemaDiffTimeout bounds loading both schemas of a comparison, including
+// connecting to a saved connection.
+const schemaDiffTimeout = 2 * time.Minute
+
+// diffSide is one schema of a comparison.
+type diffSide struct {
+	label     string
+	dialect   string
+	databases []schema.Database
+}
+
+// diffSourcesMsg carries the snapshots read for the source list.
+type diffSourcesMsg struct {
+	snapshots []schemacache.Snapshot
+	err       error
+}
+
+// diffLoadedMsg carries both schemas of comparison gen.
+type diffLoadedMsg struct {
+	from, to diffSide
+	err      error
+	gen      int
+}
+
+// snapshotSavedMsg reports a snapshot of the connection's schema saved.
+type snapshotSavedMsg struct {
+	name string
+	err  error
+}
+
+// SetSnapshotDir sets the directory schema snapshots are kept in; ""
+// turns them off.
+func (m *Model) SetSnapshotDir(dir string) {
+	m.snapshotDir = dir
+}
+
+// openSchemaDiff opens the schema comparison (Alt+D) on the connection,
+// the saved connections and the snapshots, which are read in the
+// background.
+func (m *Model) openSchemaDiff() tea.Cmd {
+	m.snapshots = nil
+	m.schemaDiff.Show(m.diffSources())
+	return m.loadSnapshots()
+}
+
+// loadSnapshots reads the snapshots in the background.
+func (m *Model) loadSnapshots() tea.Cmd {
+	dir := m.snapshotDir
+	if dir == "" {
+		return nil
+	}
+	return func() tea.Msg {
+		snaps, err := schemacache.Snapshots(dir)
+		return diffSourcesMsg{snapshots: snaps, err: err}
+	}
+}
+
+// handleDiffSources lists the snapshots read.
+func (m *Model) handleDiffSources(msg diffSourcesMsg) {
+	if msg.err != nil {
+		m.schemaDiff.SetMessage("Could not read the snapshots: "+msg.err.Error(), false)
+		return
+	}
+	m.snapshots = msg.snapshots
+	m.schemaDiff.SetSources(m.diffSources())
+}
+
+// diffSources lists what can be compared: the connection open, the saved
+// connections and the snapshots.
+func (m *Model) diffSources() []schemadiff.Source {
+	var sources []schemadiff.Source
+	if m.conn != nil {
+		sources = append(sources, schemadiff.Source{Kind: schemadiff.SourceCurrent, Detail: m.conn.AdapterName() + " " + m.connLabel()})
+	}
+	for _, sc := range m.cfg.Connections {
+		sources = append(sources, schemadiff.Source{Kind: schemadiff.SourceSaved, Name: sc.Name, Detail: sc.Adapter})
+	}
+	for _, s := range m.snapshots {
+		detail := s.Adapter + ", saved " + historybrowser.RelativeTime(s.Saved)
+		sources = append(sources, schemadiff.Source{Kind: schemadiff.SourceSnapshot, Name: s.Name, Detail: detail})
+	}
+	return sources
+}
+
+// connLabel names the connection open: its saved name, else its database.
+func (m *Model) connLabel() string {
+	if m.conn == nil {
+		return ""
+	}
+	return cmp.Or(m.connName, m.conn.DatabaseName())
+}
+
+// compareSchemas loads both schemas of a comparison in the background,
+// until they are loaded or the comparison is closed.
+func (m *Model) compareSchemas(msg schemadiff.CompareMsg) tea.Cmd {
+	from, err := m.diffLoader(msg.From)
+	if err == nil {
+		var to func(context.Context) (diffSide, error)
+		if to, err = m.diffLoader(msg.To); err == nil {
+			return m.startDiff(from, to)
+		}
+	}
+	m.schemaDiff.SetMessage(err.Error(), false)
+	return nil
+}
+
+// startDiff runs the loaders of both sides under a context kept in
+// m.diffCancel.
+func (m *Model) startDiff(from, to func(context.Context) (diffSide, error)) tea.Cmd {
+	m.stopSchemaDiff()
+	ctx, cancel := context.WithTimeout(context.Background(), schemaDiffTimeout)
+	m.diffCancel = cancel
+	m.diffGen++
+	gen := m.diffGen
+	return func() tea.Msg {
+		defer cancel()
+		msg := diffLoadedMsg{gen: gen}
+		if msg.from, msg.err = from(ctx); msg.err == nil {
+			msg.to, msg.err = to(ctx)
+		}
+		return msg
+	}
+}
+
+// diffLoader returns the function that loads the schema of src.
+func (m *Model) diffLoader(src schemadiff.Source) (func(context.Context) (diffSide, error), error) {
+	switch src.Kind {
+	case schemadiff.SourceCurrent:
+		conn := m.conn
+		if conn == nil {
+			return nil, adapter.ErrNotConnected
+		}
+		label := m.connLabel()
+		return func(ctx context.Context) (diffSide, error) {
+			dbs, _, _, err := introspect(ctx, conn, 0)
+			return diffSide{label: label, dialect: conn.AdapterName(), databases: dbs}, err
+		}, nil
+
+	case schemadiff.SourceSnapshot:
+		for _, s := range m.snapshots {
+			if s.Name == src.Name {
+				side := diffSide{label: "snapshot " + s.Name, dialect: s.Adapter, databases: s.Databases}
+				return func(context.Context) (diffSide, error) { return side, nil }, nil
+			}
+		}
+		return nil, fmt.Errorf("snapshot %s is gone", src.Name)
+	}
+
+	for _, sc := range m.cfg.Connections {
+		if sc.Name != src.Name {
+			continue
+		}
+		return func(ctx context.Context) (diffSide, error) {
+			side := diffSide{label: sc.Name, dialect: sc.Adapter}
+			conn, closeConn, err := connectSaved(ctx, sc)
+			if err != nil {
+				return side, err
+			}
+			defer closeConn()
+			side.databases, _, _, err = introspect(ctx, conn, 0)
+			return side, err
+		}, nil
+	}
+	return nil, fmt.Errorf("connection %s is gone", src.Name)
+}
+
+// connectSaved opens a connection of its own to a saved connection, aside
+// from the one open; closeConn closes it and its tunnel.
+func connectSaved(ctx context.Context, sc config.SavedConnection) (conn adapter.Connection, closeConn func(), err error) {
+	// Connect resolves the password and environment references.
+	msg := connmgr.Connect(sc)()
+	if req, ok := msg.(connmgr.ConnectRequestMsg); ok {
+		msg = dialSaved(ctx, *req.Saved)
+	}
+	switch msg := msg.(type) {
+	case ConnectErrMsg:
+		return nil, nil, fmt.Errorf("connect to %s: %w", sc.Name, msg.Err)
+	case ConnectMsg:
+		return msg.Conn, func() {
+			_ = msg.Conn.Close()
+			if msg.Tunnel != nil {
+				_ = msg.Tunnel.Close()
+			}
+		}, nil
+	}
+	return nil, nil, fmt.Errorf("connect to %s: unexpected %T", sc.Name, msg)
+}
+
+// handleDiffLoaded compares the two schemas loaded and shows the objects
+// that differ, with the migration in the dialect of the side it changes.
+func (m *Model) handleDiffLoaded(msg diffLoadedMsg) {
+	if msg.gen != m.diffGen || !m.schemaDiff.Loading() {
+		return
+	}
+	m.stopSchemaDiff()
+	if msg.err != nil {
+		text := sanitizeError(msg.err.Error())
+		if errors.Is(msg.err, context.DeadlineExceeded) {
+			text = "Loading the schemas timed out"
+		}
+		m.schemaDiff.SetMessage(text, false)
+		return
+	}
+	diffs := schema.Compare(msg.from.databases, msg.to.databases)
+	m.schemaDiff.SetDiff(msg.from.label, msg.to.label, diffs, ddl.Migration(msg.from.dialect, diffs))
+}
+
+// stopSchemaDiff cancels the schemas loading, if any.
+func (m *Model) stopSchemaDiff() {
+	if m.diffCancel != nil {
+		m.diffCancel()
+		m.diffCancel = nil
+	}
+}
+
+// saveSnapshot saves the schema of the connection open as a snapshot named
+// after the connection and the time, loading it in full first.
+func (m *Model) saveSnapshot() tea.Cmd {
+	if m.conn == nil {
+		m.schemaDiff.SetMessage("Not connected", false)
+		return nil
+	}
+	if m.snapshotDir == "" {
+		m.schemaDiff.SetMessage("Snapshots are not available: no config directory", false)
+		return nil
+	}
+	conn, dir := m.conn, m.snapshotDir
+	name := m.connLabel() + " " + time.Now().Format("2006-01-02 15:04:05")
+	m.schemaDiff.SetMessage("Saving snapshot "+name+"...", true)
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), schemaDiffTimeout)
+		defer cancel()
+		dbs, _, _, err := introspect(ctx, conn, 0)
+		if err == nil {
+			err = schemacache.SaveSnapshot(dir, schemacache.Snapshot{Name: name, Adapter: conn.AdapterName(), Databases: dbs})
+		}
+		return snapshotSavedMsg{name: name, err: err}
+	}
+}
+
+// handleSnapshotSaved reports a snapshot saved and lists it.
+func (m *Model) handleSnapshotSaved(msg snapshotSavedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.schemaDiff.SetMessage("Could not save the snapshot: "+sanitizeError(msg.err.Error()), false)
+		return nil
+	}
+	m.schemaDiff.SetMessage("Saved snapshot "+msg.name, true)
+	return m.loadSnapshots()
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/schemacache"
+	"github.com/sadopc/gotermsql/internal/ui/schemadiff"
+)
+
+// diffConn is a sqlite testConn with tables.
+type diffConn struct {
+	sqliteConn
+	tables map[string][]schema.Column
+}
+
+func (c diffConn) Databases(context.Context) ([]schema.Database, error) {
+	var tables []schema.Table
+	for _, name := range []string{"orders", "users"} {
+		if _, ok := c.tables[name]; ok {
+			tables = append(tables, schema.Table{Name: name})
+		}
+	}
+	return []schema.Database{{Name: "app", Schemas: []schema.Schema{{Name: "main", Tables: tables}}}}, nil
+}
+
+func (c diffConn) Columns(_ context.Context, _, _, table string) ([]schema.Column, error) {
+	return c.tables[table], nil
+}
+
+func TestSchemaDiff(t *testing.T) {
+	m := step(New(config.DefaultConfig(), nil, nil), tea.WindowSizeMsg{Width: 160, Height: 40})
+	dir := t.TempDir()
+	m.SetSnapshotDir(dir)
+	m.conn = diffConn{sqliteConn: sqliteConn{&testConn{dbName: "app"}}, tables: map[string][]schema.Column{
+		"users": {{Name: "id", Type: "INTEGER", IsPK: true}},
+	}}
+
+	// Snapshot the schema as it is now.
+	m = step(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d"), Alt: true})
+	if !m.schemaDiff.Visible() {
+		t.Fatal("Alt+D should open the schema comparison")
+	}
+	m = step(m, m.saveSnapshot()())
+	if len(m.snapshots) != 1 {
+		t.Fatalf("snapshots = %v, want the one saved", m.snapshots)
+	}
+
+	m.conn = diffConn{sqliteConn: sqliteConn{&testConn{dbName: "app"}}, tables: map[string][]schema.Column{
+		"users":  {{Name: "id", Type: "INTEGER", IsPK: true}, {Name: "email", Type: "TEXT", Nullable: true}},
+		"orders": {{Name: "id", Type: "INTEGER", IsPK: true}},
+	}}
+	m.schemaDiff.Show(m.diffSources())
+
+	// Change the snapshot (listed last) into the current schema.
+	m = step(m, tea.KeyMsg{Type: tea.KeyEnd})
+	m = step(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m = step(m, tea.KeyMsg{Type: tea.KeyHome})
+	model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(Model)
+	msgs := drainBatch(cmd)
+	if len(msgs) != 1 {
+		t.Fatalf("picking both sides sent %v, want a CompareMsg", msgs)
+	}
+	if cm, ok := msgs[0].(schemadiff.CompareMsg); !ok || cm.From.Kind != schemadiff.SourceSnapshot || cm.To.Kind != schemadiff.SourceCurrent {
+		t.Fatalf("sent %#v, want the snapshot compared with the connection", msgs[0])
+	}
+	m = step(m, msgs[0])
+
+	if got := len(m.schemaDiff.Diffs()); got != 2 {
+		t.Fatalf("listed %d differences, want orders added and users altered", got)
+	}
+	script := m.schemaDiff.Script()
+	for _, want := range []string{`CREATE TABLE "orders"`, `ALTER TABLE "users" ADD COLUMN "email" TEXT;`} {
+		if !strings.Contains(script, want) {
+			t.Errorf("migration lacks %q:\n%s", want, script)
+		}
+	}
+	if m.diffCancel != nil {
+		t.Error("the finished comparison was not released")
+	}
+
+	// A comparison closed while loading is dropped.
+	m.schemaDiff.Show(m.diffSources())
+	m = step(m, tea.KeyMsg{Type: tea.KeyEnter})
+	model, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(Model)
+	model, load := m.Update(drainBatch(cmd)[0])
+	m = step(model.(Model), tea.KeyMsg{Type: tea.KeyEsc})
+	if m.schemaDiff.Visible() || m.diffCancel != nil {
+		t.Error("esc should close the comparison and stop the loading")
+	}
+	m = step(m, load())
+	if len(m.schemaDiff.Diffs()) != 0 {
+		t.Error("a comparison arrived after it was closed")
+	}
+}
+
+func TestSchemaDiff_Snapshots(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	m.cfg.Connections = []config.SavedConnection{{Name: "prod", Adapter: "postgres"}}
+	dir := t.TempDir()
+	if err := schemacache.SaveSnapshot(dir, schemacache.Snapshot{Name: "before", Adapter: "postgres"}); err != nil {
+		t.Fatal(err)
+	}
+	m.SetSnapshotDir(dir)
+
+	m = step(m, m.openSchemaDiff()())
+	sources := m.diffSources()
+	if len(sources) != 2 || sources[0].Name != "prod" || sources[1].Kind != schemadiff.SourceSnapshot {
+		t.Errorf("sources = %v, want the saved connection and the snapshot", sources)
+	}
+	if _, err := m.diffLoader(schemadiff.Source{Kind: schemadiff.SourceCurrent}); err == nil {
+		t.Error("the connection was offered while not connected")
+	}
+}
@@ -0,0 +1,100 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/telemetry"
+)
+
+// transactionWords start statements that begin or end a transaction.
+var transactionWords = map[string]bool{
+	"BEGIN": true, "START": true, "COMMIT": true, "ROLLBACK": true,
+	"END": true, "SAVEPOINT": true, "RELEASE": true,
+}
+
+// scriptStatements returns the statements of query to run one at a time,
+// or nil to send it whole: when it has only one, or when it runs its own
+// transaction, which must stay on one session while the statements of a
+// script may each get another from the pool.
+func scriptStatements(dialect, query string) []adapter.Statement {
+	stmts := adapter.SplitStatements(dialect, query)
+	if len(stmts) < 2 {
+		return nil
+	}
+	for _, s := range stmts {
+		if transactionWords[s.Keyword(dialect)] {
+			return nil
+		}
+	}
+	return stmts
+}
+
+// runScript runs the statements of query in order within timeout, if any,
+// stopping at the first to fail. The result is the last statement's.
+func runScript(ctx context.Context, conn adapter.Connection, tracer *telemetry.Tracer, query string, stmts []adapter.Statement, timeout time.Duration) (*adapter.QueryResult, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	var result *adapter.QueryResult
+	var total time.Duration
+	for i, s := range stmts {
+		span, sent := tracer.Start(s.Text, conn.AdapterName(), conn.DatabaseName())
+		res, err := conn.Execute(ctx, sent)
+		if err != nil {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				err = fmt.Errorf("timed out after %s (Alt+Enter runs without a timeout): %w", timeout, err)
+			}
+			span.End(-1, err)
+			return nil, fmt.Errorf("statement %d of %d (line %d): %w", i+1, len(stmts), s.Line(query), err)
+		}
+		span.End(res.RowCount, nil)
+		total += res.Duration
+		result = res
+	}
+	result.Duration = total
+	result.Message = strings.TrimSpace(fmt.Sprintf("%d statements. %s", len(stmts), result.Message))
+	return result, nil
+}
+
+// executeStatementAtCursor runs the statement of the active editor the
+// cursor is in (F9), rather than the whole editor.
+func (m *Model) executeStatementAtCursor() tea.Cmd {
+	ts := m.activeTabState()
+	if ts == nil {
+		return nil
+	}
+	dialect := ""
+	if m.conn != nil {
+		dialect = m.conn.AdapterName()
+	}
+	stmt, ok := adapter.StatementAt(dialect, ts.Editor.Value(), ts.Editor.CursorOffset())
+	if !ok {
+		return nil
+	}
+	tabID, query := m.tabs.ActiveID(), stmt.Text
+	return func() tea.Msg { return ExecuteQueryMsg{Query: query, TabID: tabID, Lint: true} }
+}
+
+// sourceFile runs the SQL script at path in the tab (:source), leaving the
+// editor as it is. Forced, it runs without a timeout.
+func (m *Model) sourceFile(tabID int, path string, force bool) tea.Cmd {
+	if path == "" {
+		return exStatus("No file name", true)
+	}
+	data, err := os.ReadFile(config.ExpandHome(path))
+	if err != nil {
+		return exStatus("Cannot read "+path+": "+err.Error(), true)
+	}
+	query := string(data)
+	return func() tea.Msg { return ExecuteQueryMsg{Query: query, TabID: tabID, Lint: true, NoTimeout: force} }
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/ui/editor"
+)
+
+// failConn is a sqlite testConn on which the statement fail errors.
+type failConn struct {
+	*testConn
+	fail string
+}
+
+func (c failConn) AdapterName() string { return "sqlite" }
+
+func (c failConn) Execute(ctx context.Context, query string) (*adapter.QueryResult, error) {
+	if query == c.fail {
+		c.executed = append(c.executed, query)
+		return nil, errors.New("no such table: nope")
+	}
+	return c.testConn.Execute(ctx, query)
+}
+
+func TestExecuteQuery_Script(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	conn := failConn{testConn: &testConn{dbName: "app", result: &adapter.QueryResult{RowCount: 1, Message: "1 row affected"}}, fail: "DELETE FROM nope"}
+	m.conn = conn
+	tabID := m.tabs.ActiveID()
+
+	run := func(query string) tea.Msg {
+		conn.executed = nil
+		for _, msg := range drainBatch(m.executeQuery(ExecuteQueryMsg{Query: query, TabID: tabID})) {
+			switch msg.(type) {
+			case QueryResultMsg, QueryErrMsg:
+				return msg
+			}
+		}
+		return nil
+	}
+
+	msg := run("INSERT INTO t VALUES ('a;b');\n-- next\nUPDATE t SET a = 1;")
+	want := []string{"INSERT INTO t VALUES ('a;b')", "-- next\nUPDATE t SET a = 1"}
+	if !reflect.DeepEqual(conn.executed, want) {
+		t.Errorf("executed %q, want %q", conn.executed, want)
+	}
+	if res, ok := msg.(QueryResultMsg); !ok || res.Result.Message != "2 statements. 1 row affected" {
+		t.Errorf("got %#v, want the last statement's result", msg)
+	}
+
+	msg = run("UPDATE t SET a = 1;\nDELETE FROM nope;\nDROP TABLE t;")
+	if len(conn.executed) != 2 {
+		t.Errorf("executed %q, want the script to stop at the failure", conn.executed)
+	}
+	if e, ok := msg.(QueryErrMsg); !ok || !strings.Contains(e.Err.Error(), "statement 2 of 3 (line 2): no such table") {
+		t.Errorf("got %#v, want the failing statement named", msg)
+	}
+
+	// A script with its own transaction is sent whole.
+	run("BEGIN; UPDATE t SET a = 1; COMMIT;")
+	if len(conn.executed) != 1 {
+		t.Errorf("executed %q, want the script whole", conn.executed)
+	}
+}
+
+func TestExecuteStatementAtCursor(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	m.conn = sqliteConn{&testConn{dbName: "app"}}
+	ts := m.activeTabState()
+	ts.Editor.SetValue("SELECT 1;\nSELECT 'é;' AS x;\nSELECT 3;")
+	ts.Editor.SetCursor(1, 12)
+	m.focusedPane = PaneEditor
+
+	model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyF9})
+	m = model.(Model)
+	msgs := drainBatch(cmd)
+	if len(msgs) != 1 || msgs[0].(ExecuteQueryMsg).Query != "SELECT 'é;' AS x" {
+		t.Fatalf("F9 sent %v, want the second statement", msgs)
+	}
+}
+
+func TestSourceFile(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	tabID := m.tabs.ActiveID()
+	path := filepath.Join(t.TempDir(), "seed.sql")
+	if err := os.WriteFile(path, []byte("INSERT INTO t VALUES (1);\nINSERT INTO t VALUES (2);\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := drainBatch(m.handleExCommand(editor.ExCommandMsg{TabID: tabID, Name: "source", Arg: path}))
+	if len(msgs) != 1 || !strings.Contains(msgs[0].(ExecuteQueryMsg).Query, "VALUES (2)") {
+		t.Fatalf(":source sent %v, want the script", msgs)
+	}
+	if ts := m.activeTabState(); ts.Editor.Value() != "" {
+		t.Errorf("the editor changed to %q", ts.Editor.Value())
+	}
+	msgs = drainBatch(m.handleExCommand(editor.ExCommandMsg{TabID: tabID, Name: "source", Arg: path + ".missing"}))
+	if status, ok := msgs[0].(StatusMsg); !ok || !status.IsError {
+		t.Errorf(":source of a missing file sent %v, want an error", msgs)
+	}
+}
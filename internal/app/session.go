@@ -0,0 +1,94 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/session"
+	"github.com/sadopc/gotermsql/internal/ui/dialog"
+)
+
+// restoreSessionMsg answers the offer to restore the last session. The
+// session is nil when the offer was declined.
+type restoreSessionMsg struct {
+	session *session.Session
+}
+
+// Session returns the open tabs, for saving on quit.
+func (m Model) Session() *session.Session {
+	s := &session.Session{Connection: m.connName}
+	activeID := m.tabs.ActiveID()
+	for i, tab := range m.tabs.Tabs() {
+		ts := m.tabStates[tab.ID]
+		if ts == nil {
+			continue
+		}
+		line, col := ts.Editor.Cursor()
+		if tab.ID == activeID {
+			s.Active = i
+		}
+		s.Tabs = append(s.Tabs, session.Tab{Query: ts.Editor.Value(), Line: line, Column: col})
+	}
+	return s
+}
+
+// OfferRestore asks on startup whether to reopen the tabs of the last
+// session. The connection manager, if shown, waits for the answer, as
+// restoring may reconnect instead.
+func (m *Model) OfferRestore(s *session.Session) {
+	if s.Empty() {
+		return
+	}
+	m.restoreConnMgr = m.connMgr.Visible()
+	m.connMgr.Hide()
+	m.restoring = true
+
+	body := fmt.Sprintf("Reopen the %d tab(s) from your last session?", len(s.Tabs))
+	if s.Connection != "" && m.restoreConnMgr && m.cfg.FindConnection(s.Connection) != nil {
+		body = fmt.Sprintf("Reopen the %d tab(s) from your last session and reconnect to %s?", len(s.Tabs), s.Connection)
+	}
+	m.showDialog("Restore Session", body,
+		dialog.Button{Label: "Restore", Action: func() tea.Msg { return restoreSessionMsg{session: s} }},
+		dialog.Button{Label: "Discard", Action: func() tea.Msg { return restoreSessionMsg{} }},
+	)
+}
+
+// handleRestoreSession reopens the session's tabs if the offer was taken,
+// then reconnects or shows the connection manager it held back.
+func (m *Model) handleRestoreSession(msg restoreSessionMsg) tea.Cmd {
+	showConnMgr := m.restoreConnMgr
+	m.restoreConnMgr = false
+	if msg.session != nil {
+		m.restoreTabs(msg.session)
+		if sc := m.cfg.FindConnection(msg.session.Connection); sc != nil && showConnMgr && m.conn == nil {
+			return m.switchConnection(*sc)
+		}
+	}
+	if showConnMgr && m.conn == nil {
+		m.connMgr.Show()
+	}
+	return nil
+}
+
+// restoreTabs replaces the startup tab with the session's tabs.
+func (m *Model) restoreTabs(s *session.Session) {
+	var ids []int
+	for i, t := range s.Tabs {
+		id := m.tabs.ActiveID()
+		if i > 0 {
+			id, _ = m.addTab("")
+		}
+		ed := &m.tabStates[id].Editor
+		ed.SetValue(t.Query)
+		ed.SetCursor(t.Line, t.Column)
+		ids = append(ids, id)
+	}
+	if s.Active < len(ids) {
+		m.tabs, _ = m.tabs.Update(SwitchTabMsg{TabID: ids[s.Active]})
+	}
+	for _, ts := range m.tabStates {
+		ts.Editor.Blur()
+	}
+	m.updateLayout()
+	m.setFocus(PaneEditor)
+}
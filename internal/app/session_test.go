@@ -0,0 +1,97 @@
+package app
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/session"
+	"github.com/sadopc/gotermsql/internal/ui/connmgr"
+)
+
+func TestSession_SaveAndRestore(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	m.connName = "local"
+	m.activeTabState().Editor.SetValue("SELECT 1")
+	model, _ := m.Update(NewTabMsg{Query: "SELECT *\nFROM users"})
+	m = model.(Model)
+	m.activeTabState().Editor.SetCursor(0, 3)
+	model, _ = m.Update(NewTabMsg{Query: "SELECT 3"})
+	m = model.(Model)
+	m.tabs, _ = m.tabs.Update(SwitchTabMsg{TabID: 1})
+
+	s := m.Session()
+	want := []session.Tab{{Query: "SELECT 1", Column: 8}, {Query: "SELECT *\nFROM users", Column: 3}, {Query: "SELECT 3", Column: 8}}
+	if len(s.Tabs) != 3 || s.Active != 1 || s.Connection != "local" {
+		t.Fatalf("Session() = %+v", s)
+	}
+	for i := range want {
+		if s.Tabs[i] != want[i] {
+			t.Errorf("tab %d = %+v, want %+v", i, s.Tabs[i], want[i])
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Connections = []config.SavedConnection{{Name: "local", Adapter: "sqlite", File: ":memory:"}}
+	r := New(cfg, nil, nil)
+	r.ShowConnManager()
+	r.OfferRestore(s)
+	if r.connMgr.Visible() || !r.dialog.Visible() {
+		t.Fatal("expected the restore offer in place of the connection manager")
+	}
+
+	// Enter picks Restore.
+	model, cmd := r.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	r = model.(Model)
+	model, cmd = r.Update(cmd())
+	r = model.(Model)
+	if r.tabs.Count() != 3 || r.tabs.ActiveID() != r.tabs.Tabs()[1].ID {
+		t.Fatalf("got %d tabs, active %d; want 3 with the second active", r.tabs.Count(), r.tabs.ActiveID())
+	}
+	for i, tab := range r.tabs.Tabs() {
+		ed := r.tabStates[tab.ID].Editor
+		line, col := ed.Cursor()
+		if ed.Value() != want[i].Query || line != want[i].Line || col != want[i].Column {
+			t.Errorf("tab %d = %q at %d,%d; want %+v", i, ed.Value(), line, col, want[i])
+		}
+	}
+	if r.connMgr.Visible() {
+		t.Error("expected restoring to reconnect rather than show the connection manager")
+	}
+	var connecting bool
+	for _, msg := range drainBatch(cmd) {
+		if _, ok := msg.(connmgr.ConnectRequestMsg); ok {
+			connecting = true
+		}
+	}
+	if !connecting {
+		t.Error("expected restoring to reconnect to local")
+	}
+}
+
+func TestSession_Discard(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	m.ShowConnManager()
+	m.OfferRestore(&session.Session{Tabs: []session.Tab{{Query: "SELECT 1"}}})
+
+	model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = model.(Model)
+	if cmd == nil {
+		t.Fatal("expected Esc to decline the offer")
+	}
+	model, _ = m.Update(cmd())
+	m = model.(Model)
+	if m.activeTabState().Editor.Value() != "" || m.tabs.Count() != 1 {
+		t.Error("expected declining to keep the empty tab")
+	}
+	if !m.connMgr.Visible() {
+		t.Error("expected the connection manager after declining")
+	}
+
+	// An empty session is not offered.
+	m = New(config.DefaultConfig(), nil, nil)
+	m.OfferRestore(&session.Session{Tabs: []session.Tab{{Query: " "}}})
+	if m.dialog.Visible() {
+		t.Error("expected no offer for an empty session")
+	}
+}
@@ -0,0 +1,38 @@
+package app
+
+// minPaneWidth is the narrowest the editor or results get side by side.
+const minPaneWidth = 20
+
+// paneSizes splits a main area of width by height between the editor and
+// the results: stacked by editorHeight, or side by side by editorWidth.
+func (m Model) paneSizes(width, height int) (editorW, editorH, resultsW, resultsH int) {
+	if m.sideBySide {
+		editorW = width * m.editorWidth / 100
+		resultsW = width - editorW
+		if editorW < minPaneWidth {
+			editorW = minPaneWidth
+		}
+		if resultsW < minPaneWidth {
+			resultsW = minPaneWidth
+		}
+		return editorW, height, resultsW, height
+	}
+	editorH = height * m.editorHeight / 100
+	resultsH = height - editorH
+	if editorH < 3 {
+		editorH = 3
+	}
+	if resultsH < 3 {
+		resultsH = 3
+	}
+	return width, editorH, width, resultsH
+}
+
+// editorShare returns the percentage of the main area the editor takes in
+// the current layout, for the resize keys to change.
+func (m *Model) editorShare() *int {
+	if m.sideBySide {
+		return &m.editorWidth
+	}
+	return &m.editorHeight
+}
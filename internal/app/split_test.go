@@ -0,0 +1,64 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+func TestSideBySide(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Layout = config.LayoutSideBySide
+	m := New(cfg, nil, nil)
+	m.conn = &testConn{dbName: "app"}
+	update := func(msg tea.Msg) {
+		model, _ := m.Update(msg)
+		m = model.(Model)
+	}
+	update(tea.WindowSizeMsg{Width: 200, Height: 30})
+
+	l := m.layout()
+	if l.resultsY != l.tabH || l.resultsX != l.mainX+l.mainW/2 {
+		t.Fatalf("layout = %+v, want the results beside the editor at half the width", l)
+	}
+	// The editor's and the results' top borders share a row.
+	row := strings.Split(m.View(), "\n")[l.tabH]
+	if strings.Count(row, "╭") != 3 {
+		t.Errorf("the sidebar, editor and results should start on the same row: %q", row)
+	}
+
+	m, _ = click(m, l.resultsX+5, l.tabH+10)
+	if m.focusedPane != PaneResults {
+		t.Errorf("a click right of the editor should focus the results, focus = %v", m.focusedPane)
+	}
+	m, _ = click(m, l.resultsX-5, l.tabH+10)
+	if m.focusedPane != PaneEditor {
+		t.Errorf("a click on the editor should focus it, focus = %v", m.focusedPane)
+	}
+
+	// The border between them drags sideways.
+	m, _ = click(m, l.resultsX, l.tabH+10)
+	x := l.mainX + l.mainW*30/100
+	m, _ = mouse(m, x, l.tabH+10, tea.MouseActionMotion, tea.MouseButtonLeft)
+	m, _ = mouse(m, x, l.tabH+10, tea.MouseActionRelease, tea.MouseButtonLeft)
+	if m.editorWidth != 30 || m.editorHeight != 50 {
+		t.Errorf("after the drag: editor width = %d%%, height = %d%%; want 30%%, 50%%", m.editorWidth, m.editorHeight)
+	}
+	update(tea.KeyMsg{Type: tea.KeyCtrlDown})
+	if m.editorWidth != 35 {
+		t.Errorf("ctrl+down side by side should widen the editor, got %d%%", m.editorWidth)
+	}
+
+	update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'l'}, Alt: true})
+	if m.sideBySide {
+		t.Fatal("alt+l should stack the results again")
+	}
+	if l := m.layout(); l.resultsX != l.mainX || l.resultsY <= l.tabH {
+		t.Errorf("stacked layout = %+v", l)
+	}
+	if v := m.tabStates[m.tabs.ActiveID()].Editor.Value(); v != "" {
+		t.Errorf("alt+l should not be typed into the editor, got %q", v)
+	}
+}
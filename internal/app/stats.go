@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+// statsTimeout bounds loading the sidebar's row counts and sizes.
+const statsTimeout = time.Minute
+
+// loadTableStats loads row counts and sizes for every schema of the last
+// loaded schema tree, in the background and after the tree itself, so a
+// slow statistics query never holds up the sidebar.
+func (m *Model) loadTableStats() tea.Cmd {
+	if m.conn == nil || len(m.databases) == 0 {
+		return nil
+	}
+	provider, ok := m.conn.(adapter.TableStatsProvider)
+	if !ok {
+		text := "Row counts are not available for " + m.conn.AdapterName()
+		return func() tea.Msg { return StatusMsg{Text: text, IsError: true} }
+	}
+
+	databases := m.databases
+	gen := m.connGen
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), statsTimeout)
+		defer cancel()
+
+		stats := make(map[schema.TableRef]schema.TableStats)
+		var warnings []string
+		for _, db := range databases {
+			for _, s := range db.Schemas {
+				if len(s.Tables) == 0 {
+					continue
+				}
+				byTable, err := provider.TableStats(ctx, db.Name, s.Name)
+				if err != nil {
+					warnings = append(warnings, fmt.Sprintf("table stats(%s): %v", s.Name, err))
+					continue
+				}
+				for name, st := range byTable {
+					stats[schema.TableRef{Database: db.Name, Schema: s.Name, Table: name}] = st
+				}
+			}
+		}
+		return TableStatsMsg{Stats: stats, ConnGen: gen, Warnings: warnings}
+	}
+}
+
+// handleTableStats passes loaded row counts and sizes to the sidebar.
+func (m *Model) handleTableStats(msg TableStatsMsg) tea.Cmd {
+	if msg.ConnGen != m.connGen {
+		return nil
+	}
+	var cmd tea.Cmd
+	m.sidebar, cmd = m.sidebar.Update(msg)
+	if len(msg.Warnings) > 0 {
+		text := fmt.Sprintf("Row counts loaded with %d warnings: %s", len(msg.Warnings), msg.Warnings[0])
+		return tea.Batch(cmd, func() tea.Msg { return StatusMsg{Text: text, IsError: true} })
+	}
+	return cmd
+}
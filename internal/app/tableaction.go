@@ -0,0 +1,89 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	appmsg "github.com/sadopc/gotermsql/internal/msg"
+	"github.com/sadopc/gotermsql/internal/ui/dialog"
+)
+
+// tableCountTimeout bounds a "Count rows" from the sidebar menu.
+const tableCountTimeout = time.Minute
+
+// tableCountMsg carries the result of a "Count rows" from the sidebar menu.
+type tableCountMsg struct {
+	Table   string
+	Count   int64
+	Err     error
+	ConnGen uint64
+}
+
+// status renders the count for the status bar.
+func (msg tableCountMsg) status() StatusMsg {
+	if msg.Err != nil {
+		return StatusMsg{Text: "Count failed: " + sanitizeError(msg.Err.Error()), IsError: true}
+	}
+	return StatusMsg{Text: fmt.Sprintf("%s: %d rows", msg.Table, msg.Count)}
+}
+
+// handleTableAction runs a table operation picked from the sidebar menu.
+// Counting runs in the background; TRUNCATE and DROP ask first and then
+// run in the active tab like any other statement, so they are audited and
+// kept in the history. DROP needs the table name typed to confirm.
+func (m *Model) handleTableAction(msg TableActionMsg) tea.Cmd {
+	if m.conn == nil {
+		return nil
+	}
+	dialect := m.conn.AdapterName()
+	name := adapter.QuoteIdentifier(dialect, msg.Table)
+	if msg.Schema != "" && msg.Schema != "main" {
+		name = adapter.QuoteIdentifier(dialect, msg.Schema) + "." + name
+	}
+	tabID := m.tabs.ActiveID()
+
+	switch msg.Action {
+	case appmsg.TableCount:
+		conn := m.conn
+		reply := tableCountMsg{Table: msg.Table, ConnGen: m.connGen}
+		query := "SELECT COUNT(*) FROM " + name
+		return func() tea.Msg {
+			ctx, cancel := context.WithTimeout(adapter.OnPool(context.Background()), tableCountTimeout)
+			defer cancel()
+			res, err := conn.Execute(ctx, query)
+			switch {
+			case err != nil:
+				reply.Err = err
+			case res == nil || len(res.Rows) == 0 || len(res.Rows[0]) == 0:
+				reply.Err = fmt.Errorf("no result")
+			default:
+				reply.Count = parseCount(res.Rows[0][0])
+			}
+			return reply
+		}
+
+	case appmsg.TableTruncate:
+		query := "TRUNCATE TABLE " + name
+		if dialect == "sqlite" {
+			query = "DELETE FROM " + name // SQLite has no TRUNCATE
+		}
+		m.showDialog("Truncate Table",
+			query+"\n\nThis deletes every row of "+msg.Table+".",
+			dialog.Button{Label: "Truncate", Action: func() tea.Msg { return ExecuteQueryMsg{Query: query, TabID: tabID} }},
+			dialog.Button{Label: "Cancel", Action: func() tea.Msg { return nil }},
+		)
+
+	case appmsg.TableDrop:
+		query := "DROP " + msg.Kind + " " + name
+		m.showDialog("Drop "+msg.Kind,
+			query+"\n\nThis cannot be undone. Refresh the schema (Ctrl+R) afterwards.",
+			dialog.Button{Label: "Drop", Guarded: true, Action: func() tea.Msg { return ExecuteQueryMsg{Query: query, TabID: tabID, Confirmed: true} }},
+			dialog.Button{Label: "Cancel", Action: func() tea.Msg { return nil }},
+		)
+		m.dialog.RequireText(msg.Table)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+	appmsg "github.com/sadopc/gotermsql/internal/msg"
+)
+
+func TestTableAction_DropNeedsTypedName(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	conn := &testConn{dbName: "app"}
+	m.conn = conn
+
+	model, _ := m.Update(TableActionMsg{Action: appmsg.TableDrop, Database: "app", Schema: "public", Table: "users", Kind: "TABLE"})
+	m = model.(Model)
+	if !m.dialog.Visible() {
+		t.Fatal("expected a confirmation dialog")
+	}
+	if view := m.dialog.View(); !strings.Contains(view, `DROP TABLE "public"."users"`) {
+		t.Errorf("dialog view = %q, want the DROP statement", view)
+	}
+
+	model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = model.(Model)
+	if cmd != nil || !m.dialog.Visible() {
+		t.Fatal("enter without typing the table name should not drop it")
+	}
+
+	for _, r := range "users" {
+		model, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = model.(Model)
+	}
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("typing the table name should confirm")
+	}
+	got, ok := cmd().(ExecuteQueryMsg)
+	if !ok || got.Query != `DROP TABLE "public"."users"` || !got.Confirmed {
+		t.Errorf("confirm = %#v, want the confirmed DROP statement", got)
+	}
+}
+
+func TestTableAction_Count(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	conn := &testConn{dbName: "app", result: &adapter.QueryResult{Rows: [][]string{{"42"}}}}
+	m.conn = conn
+
+	_, cmd := m.Update(TableActionMsg{Action: appmsg.TableCount, Schema: "public", Table: "users", Kind: "TABLE"})
+	if cmd == nil {
+		t.Fatal("expected a count command")
+	}
+	got := cmd().(tableCountMsg)
+	if got.Count != 42 || got.status().Text != "users: 42 rows" {
+		t.Errorf("count = %#v", got)
+	}
+	if len(conn.executed) != 1 || conn.executed[0] != `SELECT COUNT(*) FROM "public"."users"` {
+		t.Errorf("executed %v", conn.executed)
+	}
+}
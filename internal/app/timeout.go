@@ -0,0 +1,48 @@
+package app
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+// queryTickMsg redraws the time left to a running query once a second.
+type queryTickMsg struct {
+	TabID int
+	RunID uint64
+}
+
+// queryTimeout returns how long a query may run before it is cancelled, or
+// 0 for as long as it takes: the connection's query_timeout, else the
+// global one, and no longer than the connection's statement timeout.
+func (m *Model) queryTimeout() time.Duration {
+	return queryTimeoutFor(m.cfg.QueryTimeout, m.execDefaults)
+}
+
+// queryTimeoutFor returns the query timeout on a connection with defaults
+// d, given the global query_timeout.
+func queryTimeoutFor(timeout time.Duration, d *config.ExecDefaults) time.Duration {
+	if d != nil && d.QueryTimeout > 0 {
+		timeout = d.QueryTimeout
+	}
+	if d != nil && d.StatementTimeout > 0 && (timeout <= 0 || d.StatementTimeout < timeout) {
+		timeout = d.StatementTimeout
+	}
+	return max(timeout, 0)
+}
+
+func queryTick(tabID int, runID uint64) tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return queryTickMsg{TabID: tabID, RunID: runID}
+	})
+}
+
+// handleQueryTick keeps ticking while the query is still running.
+func (m *Model) handleQueryTick(msg queryTickMsg) tea.Cmd {
+	ts := m.tabStates[msg.TabID]
+	if !m.executing || m.executingTabID != msg.TabID || ts == nil || ts.RunID != msg.RunID {
+		return nil
+	}
+	return queryTick(msg.TabID, msg.RunID)
+}
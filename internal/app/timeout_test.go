@@ -0,0 +1,101 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+// slowConn is a testConn whose statements run until they are cancelled.
+type slowConn struct{ *testConn }
+
+func (c slowConn) Execute(ctx context.Context, query string) (*adapter.QueryResult, error) {
+	c.executed = append(c.executed, query)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestQueryTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		global   time.Duration
+		defaults *config.ExecDefaults
+		want     time.Duration
+	}{
+		{"global", 5 * time.Minute, nil, 5 * time.Minute},
+		{"none", 0, nil, 0},
+		{"connection", 5 * time.Minute, &config.ExecDefaults{QueryTimeout: time.Hour}, time.Hour},
+		{"connection without global", 0, &config.ExecDefaults{QueryTimeout: time.Minute}, time.Minute},
+		{"statement timeout shorter", 5 * time.Minute, &config.ExecDefaults{StatementTimeout: 30 * time.Second}, 30 * time.Second},
+		{"statement timeout without global", 0, &config.ExecDefaults{StatementTimeout: 30 * time.Second}, 30 * time.Second},
+		{"statement timeout longer", time.Minute, &config.ExecDefaults{StatementTimeout: time.Hour}, time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.QueryTimeout = tt.global
+			m := New(cfg, nil, nil)
+			m.execDefaults = tt.defaults
+			if got := m.queryTimeout(); got != tt.want {
+				t.Errorf("queryTimeout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteQuery_TimesOut(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.QueryTimeout = 10 * time.Millisecond
+	m := step(New(cfg, nil, nil), tea.WindowSizeMsg{Width: 120, Height: 40})
+	m.conn = slowConn{&testConn{dbName: "app"}}
+	tabID := m.tabs.ActiveID()
+
+	var started QueryStartedMsg
+	var failed QueryErrMsg
+	for _, msg := range drainBatch(m.executeQuery(ExecuteQueryMsg{Query: "UPDATE t SET a = 1", TabID: tabID})) {
+		switch msg := msg.(type) {
+		case QueryStartedMsg:
+			started = msg
+		case QueryErrMsg:
+			failed = msg
+		}
+	}
+	if started.Timeout != cfg.QueryTimeout {
+		t.Errorf("started with timeout %v, want %v", started.Timeout, cfg.QueryTimeout)
+	}
+	if failed.Err == nil || !strings.Contains(failed.Err.Error(), "timed out after 10ms") {
+		t.Errorf("error = %v, want a timeout", failed.Err)
+	}
+
+	// While it runs, the results show the time left.
+	model, _ := m.Update(QueryStartedMsg{TabID: tabID, RunID: m.tabStates[tabID].RunID, Timeout: time.Hour})
+	m = model.(Model)
+	if view := m.tabStates[tabID].Results.View(); !strings.Contains(view, "times out in 59m") {
+		t.Errorf("results view lacks the time left:\n%s", view)
+	}
+}
+
+func TestExecuteQuery_NoTimeout(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	m.conn = &testConn{dbName: "app", result: &adapter.QueryResult{RowCount: 1}}
+	m.activeTabState().Editor.SetValue("UPDATE t SET a = 1")
+	m.focusedPane = PaneEditor
+
+	model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter, Alt: true})
+	m = model.(Model)
+	msgs := drainBatch(cmd)
+	if len(msgs) != 1 || !msgs[0].(ExecuteQueryMsg).NoTimeout {
+		t.Fatalf("alt+enter sent %v, want an ExecuteQueryMsg without timeout", msgs)
+	}
+	_, cmd = m.Update(msgs[0])
+	for _, msg := range drainBatch(cmd) {
+		if started, ok := msg.(QueryStartedMsg); ok && started.Timeout != 0 {
+			t.Errorf("started with timeout %v, want none", started.Timeout)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+// toast shows a notification in the top-right corner.
+func (m *Model) toast(level ToastLevel, text string) tea.Cmd {
+	var cmd tea.Cmd
+	m.toasts, cmd = m.toasts.Update(ToastMsg{Text: text, Level: level})
+	return cmd
+}
+
+// schemaSummary says what a refreshed schema holds, or what changed in it:
+// the object when there is one, how many were added, dropped and altered
+// when there are more.
+func schemaSummary(dbs []schema.Database, changes schema.Changes) string {
+	if changes.Empty() {
+		tables := 0
+		for _, db := range dbs {
+			for _, s := range db.Schemas {
+				tables += len(s.Tables)
+			}
+		}
+		return fmt.Sprintf("Schema refreshed: %d tables, no changes", tables)
+	}
+	var parts []string
+	for _, c := range []struct {
+		verb  string
+		names []string
+	}{{"added", changes.Added}, {"dropped", changes.Dropped}, {"altered", changes.Altered}} {
+		switch {
+		case len(c.names) == 0:
+		case len(changes.Added)+len(changes.Dropped)+len(changes.Altered) == 1:
+			parts = append(parts, c.verb+" "+c.names[0])
+		default:
+			parts = append(parts, fmt.Sprintf("%d %s", len(c.names), c.verb))
+		}
+	}
+	return "Schema refreshed: " + strings.Join(parts, ", ")
+}
+
+// connectionLost reports whether a query failed because the connection to
+// the server broke, rather than because of the query.
+func connectionLost(err error) bool {
+	var netErr *net.OpError
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.As(err, &netErr)
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+func TestToast_Events(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	m.conn = &testConn{dbName: "app"}
+	update := func(msg tea.Msg) {
+		model, _ := m.Update(msg)
+		m = model.(Model)
+	}
+	update(tea.WindowSizeMsg{Width: 120, Height: 30})
+
+	// Two events in a row both stay visible.
+	update(ExportCompleteMsg{Path: "/tmp/out.csv", RowCount: 3})
+	update(ExportErrMsg{Err: errors.New("disk full")})
+	view := m.View()
+	for _, want := range []string{"Exported 3 rows to /tmp/out.csv", "Export failed: disk full"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view should show the toast %q:\n%s", want, view)
+		}
+	}
+
+	// Only a refresh asked for is announced, not the load on connect.
+	dbs := []schema.Database{{Name: "app", Schemas: []schema.Schema{
+		{Name: "public", Tables: []schema.Table{{Name: "a"}, {Name: "b"}}},
+	}}}
+	update(SchemaLoadedMsg{Databases: dbs, ConnGen: m.connGen})
+	if strings.Contains(m.View(), "Schema refreshed") {
+		t.Error("a schema load that was not a refresh should not toast")
+	}
+	m.refreshSchema()
+	update(SchemaLoadedMsg{Databases: dbs, ConnGen: m.connGen})
+	if !strings.Contains(m.View(), "Schema refreshed: 2 tables") {
+		t.Errorf("view should announce the refresh:\n%s", m.View())
+	}
+
+	// The refresh says what changed.
+	dbs = []schema.Database{{Name: "app", Schemas: []schema.Schema{
+		{Name: "public", Tables: []schema.Table{{Name: "a"}, {Name: "c"}}},
+	}}}
+	m.refreshSchema()
+	update(SchemaLoadedMsg{Databases: dbs, ConnGen: m.connGen})
+	if !strings.Contains(m.View(), "Schema refreshed: 1 added, 1 dropped") {
+		t.Errorf("view should list the changes:\n%s", m.View())
+	}
+}
+
+func TestConnectionLost(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{fmt.Errorf("query: %w", driver.ErrBadConn), true},
+		{errors.New(`relation "nope" does not exist`), false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := connectionLost(tt.err); got != tt.want {
+			t.Errorf("connectionLost(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/ui/dialog"
+)
+
+// txTimeout bounds a commit or rollback.
+const txTimeout = 30 * time.Second
+
+// txEndedMsg reports a commit or rollback on connection generation connGen.
+type txEndedMsg struct {
+	commit  bool
+	quit    bool // quit once it is done
+	err     error
+	connGen uint64
+}
+
+// transactor returns the connection if it can hold an explicit
+// transaction, or nil.
+func (m *Model) transactor() adapter.Transactor {
+	tx, _ := m.conn.(adapter.Transactor)
+	return tx
+}
+
+// inTransaction returns whether the connection has a transaction open.
+func (m *Model) inTransaction() bool {
+	tx := m.transactor()
+	return tx != nil && tx.InTransaction()
+}
+
+// syncTransaction shows in the status bar whether a transaction is open.
+func (m *Model) syncTransaction() {
+	m.statusbar.SetTransaction(m.inTransaction())
+}
+
+// beginTx returns what to run before a statement: with autocommit off, it
+// opens a transaction unless one is open already. The statement then runs
+// in it. With autocommit on it is nil.
+func (m *Model) beginTx() func(context.Context) error {
+	tx := m.transactor()
+	if m.autoCommit || tx == nil {
+		return nil
+	}
+	return func(ctx context.Context) error {
+		if tx.InTransaction() {
+			return nil
+		}
+		return tx.Begin(ctx)
+	}
+}
+
+// toggleAutoCommit turns autocommit on or off (F4), saving the setting
+// with the saved connection. It cannot be turned back on while a
+// transaction is open, so that its changes are not committed by surprise.
+func (m *Model) toggleAutoCommit() tea.Cmd {
+	if m.conn == nil {
+		return exStatus("Not connected", true)
+	}
+	if m.transactor() == nil {
+		return exStatus("Autocommit cannot be turned off on "+m.conn.AdapterName(), true)
+	}
+	if m.inTransaction() {
+		return exStatus("Commit (F6) or roll back (F7) the open transaction first", true)
+	}
+	m.autoCommit = !m.autoCommit
+	text := "Autocommit on"
+	if !m.autoCommit {
+		text = "Autocommit off: statements run in a transaction until F6 commits or F7 rolls back"
+	}
+	if err := m.saveAutoCommit(); err != nil {
+		return exStatus(text+" (not saved: "+err.Error()+")", true)
+	}
+	return exStatus(text, false)
+}
+
+// saveAutoCommit stores the autocommit setting in the defaults of the
+// saved connection, if connected to one. Unset is saved for on.
+func (m *Model) saveAutoCommit() error {
+	sc := m.cfg.FindConnection(m.connName)
+	if m.connName == "" || sc == nil {
+		return nil
+	}
+	if sc.Defaults == nil {
+		sc.Defaults = &config.ExecDefaults{}
+	}
+	sc.Defaults.AutoCommit = nil
+	if !m.autoCommit {
+		off := false
+		sc.Defaults.AutoCommit = &off
+	}
+	m.execDefaults = sc.Defaults
+	m.connMgr.SetConnections(m.cfg.Connections)
+	return m.cfg.SaveDefault()
+}
+
+// endTransaction commits or rolls back the open transaction (F6, F7),
+// quitting afterwards with quit.
+func (m *Model) endTransaction(commit, quit bool) tea.Cmd {
+	tx := m.transactor()
+	if tx == nil || !tx.InTransaction() {
+		if quit {
+			return m.quit()
+		}
+		return exStatus("No transaction in progress", true)
+	}
+	gen := m.connGen
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), txTimeout)
+		defer cancel()
+		var err error
+		if commit {
+			err = tx.Commit(ctx)
+		} else {
+			err = tx.Rollback(ctx)
+		}
+		return txEndedMsg{commit: commit, quit: quit, err: err, connGen: gen}
+	}
+}
+
+// handleTxEnded reports a commit or rollback.
+func (m *Model) handleTxEnded(msg txEndedMsg) tea.Cmd {
+	if msg.connGen != m.connGen {
+		return nil
+	}
+	m.syncTransaction()
+	if msg.quit && msg.err == nil {
+		return m.quit()
+	}
+	switch {
+	case msg.err != nil && msg.commit:
+		return exStatus("Commit failed: "+sanitizeError(msg.err.Error()), true)
+	case msg.err != nil:
+		return exStatus("Rollback failed: "+sanitizeError(msg.err.Error()), true)
+	case msg.commit:
+		return exStatus("Transaction committed", false)
+	}
+	return exStatus("Transaction rolled back", false)
+}
+
+// confirmQuit quits, first asking what to do with the open transaction,
+// if any.
+func (m *Model) confirmQuit() tea.Cmd {
+	if !m.inTransaction() {
+		return m.quit()
+	}
+	m.showDialog("Open Transaction",
+		"The transaction has uncommitted changes.",
+		dialog.Button{Label: "Commit", Action: m.endTransaction(true, true)},
+		dialog.Button{Label: "Roll back", Action: m.endTransaction(false, true)},
+		dialog.Button{Label: "Cancel", Action: func() tea.Msg { return nil }},
+	)
+	return nil
+}
+
+// txStatement reports whether query is a plain COMMIT or ROLLBACK, which
+// must end the open transaction through the connection rather than run
+// inside it. commit tells which.
+func txStatement(query string) (commit, ok bool) {
+	words := strings.Fields(strings.ToUpper(adapter.TrimStatement(query)))
+	if len(words) == 0 || len(words) > 2 {
+		return false, false
+	}
+	if len(words) == 2 && words[1] != "WORK" && words[1] != "TRANSACTION" {
+		return false, false
+	}
+	switch words[0] {
+	case "COMMIT", "END":
+		return true, true
+	case "ROLLBACK", "ABORT":
+		return false, true
+	}
+	return false, false
+}
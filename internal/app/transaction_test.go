@@ -0,0 +1,176 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+// txConn is a testConn that can hold a transaction.
+type txConn struct {
+	*testConn
+	open bool
+	ends []string
+}
+
+func (c *txConn) Begin(context.Context) error {
+	if c.open {
+		return adapter.ErrTransactionActive
+	}
+	c.open = true
+	return nil
+}
+func (c *txConn) Commit(context.Context) error   { return c.end("commit") }
+func (c *txConn) Rollback(context.Context) error { return c.end("rollback") }
+func (c *txConn) InTransaction() bool            { return c.open }
+
+func (c *txConn) end(how string) error {
+	if !c.open {
+		return adapter.ErrNoTransaction
+	}
+	c.open = false
+	c.ends = append(c.ends, how)
+	return nil
+}
+
+// step feeds msg to m, then the messages its command returns. Their own
+// commands are not run; they may be timers.
+func step(m Model, msg tea.Msg) Model {
+	model, cmd := m.Update(msg)
+	m = model.(Model)
+	for _, next := range drainBatch(cmd) {
+		model, _ = m.Update(next)
+		m = model.(Model)
+	}
+	return m
+}
+
+func TestAutoCommit_Off(t *testing.T) {
+	m := step(New(config.DefaultConfig(), nil, nil), tea.WindowSizeMsg{Width: 120, Height: 40})
+	conn := &txConn{testConn: &testConn{dbName: "app", result: &adapter.QueryResult{RowCount: 1}}}
+	m.conn = conn
+
+	m = step(m, tea.KeyMsg{Type: tea.KeyF4})
+	if m.autoCommit {
+		t.Fatal("F4 should turn autocommit off")
+	}
+
+	m = step(m, ExecuteQueryMsg{Query: "UPDATE t SET a = 1", TabID: 0})
+	if !conn.open {
+		t.Fatal("expected the statement to open a transaction")
+	}
+	if !strings.Contains(m.statusbar.View(), " TX ") {
+		t.Error("expected the status bar to show the open transaction")
+	}
+	m = step(m, tea.KeyMsg{Type: tea.KeyF4})
+	if m.autoCommit {
+		t.Error("autocommit should not turn back on while a transaction is open")
+	}
+
+	// A COMMIT typed in the editor ends the transaction instead of running.
+	m = step(m, ExecuteQueryMsg{Query: "commit;", TabID: 0})
+	if conn.open || !slices.Equal(conn.ends, []string{"commit"}) {
+		t.Fatalf("open = %v, ends = %v; want one commit", conn.open, conn.ends)
+	}
+	if slices.Contains(conn.executed, "commit;") {
+		t.Error("COMMIT should not run inside the transaction")
+	}
+	if strings.Contains(m.statusbar.View(), " TX ") {
+		t.Error("expected the transaction indicator to go after the commit")
+	}
+
+	m = step(m, ExecuteQueryMsg{Query: "DELETE FROM t", TabID: 0})
+	m = step(m, tea.KeyMsg{Type: tea.KeyF7})
+	if conn.open || !slices.Equal(conn.ends, []string{"commit", "rollback"}) {
+		t.Errorf("open = %v, ends = %v; want a rollback after the commit", conn.open, conn.ends)
+	}
+}
+
+func TestAutoCommit_On(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	conn := &txConn{testConn: &testConn{dbName: "app", result: &adapter.QueryResult{RowCount: 1}}}
+	m.conn = conn
+
+	m = step(m, ExecuteQueryMsg{Query: "UPDATE t SET a = 1", TabID: 0})
+	if conn.open {
+		t.Error("with autocommit on no transaction should be opened")
+	}
+	m = step(m, ExecuteQueryMsg{Query: "COMMIT", TabID: 0})
+	if !slices.Contains(conn.executed, "COMMIT") {
+		t.Error("with autocommit on COMMIT should run as typed")
+	}
+}
+
+func TestAutoCommit_SavedWithConnection(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+
+	cfg := config.DefaultConfig()
+	cfg.Connections = []config.SavedConnection{{Name: "local", Adapter: "sqlite", File: ":memory:"}}
+	m := New(cfg, nil, nil)
+	m.conn = &txConn{testConn: &testConn{dbName: "app"}}
+	m.connName = "local"
+
+	m = step(m, tea.KeyMsg{Type: tea.KeyF4})
+	dir, _ := config.ConfigDir()
+	saved, err := config.Load(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := saved.Connections[0].Defaults; d.AutoCommitOn() {
+		t.Errorf("saved defaults = %+v, want autocommit off", d)
+	}
+
+	// Reconnecting picks the setting up again.
+	m.autoCommit = true
+	m = step(m, ConnectMsg{Conn: &testConn{dbName: "app"}, Adapter: "sqlite", Name: "local", Defaults: saved.Connections[0].Defaults})
+	if m.autoCommit {
+		t.Error("expected autocommit off after reconnecting")
+	}
+}
+
+func TestAutoCommit_QuitAsks(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	conn := &txConn{testConn: &testConn{dbName: "app"}, open: true}
+	m.conn = conn
+
+	m = step(m, tea.KeyMsg{Type: tea.KeyCtrlQ})
+	if m.quitting || !m.dialog.Visible() {
+		t.Fatal("expected quitting with an open transaction to ask first")
+	}
+	// Enter picks Commit.
+	m = step(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.quitting || !slices.Equal(conn.ends, []string{"commit"}) {
+		t.Errorf("quitting = %v, ends = %v; want to commit and quit", m.quitting, conn.ends)
+	}
+}
+
+func TestTxStatement(t *testing.T) {
+	tests := []struct {
+		query      string
+		commit, ok bool
+	}{
+		{"COMMIT", true, true},
+		{" commit work; ", true, true},
+		{"END", true, true},
+		{"ROLLBACK", false, true},
+		{"rollback transaction", false, true},
+		{"ROLLBACK TO SAVEPOINT a", false, false},
+		{"COMMIT PREPARED 'x'", false, false},
+		{"SELECT 1", false, false},
+		{"", false, false},
+	}
+	for _, tt := range tests {
+		commit, ok := txStatement(tt.query)
+		if commit != tt.commit || ok != tt.ok {
+			t.Errorf("txStatement(%q) = %v, %v; want %v, %v", tt.query, commit, ok, tt.commit, tt.ok)
+		}
+	}
+}
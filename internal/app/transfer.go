@@ -0,0 +1,254 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/ddl"
+	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/ui/transfer"
+)
+
+// transferBatch is how many rows are read, and inserted by one INSERT, at
+// a time.
+const transferBatch = 500
+
+// transferTickInterval is how often the progress of a copy is redrawn.
+const transferTickInterval = 250 * time.Millisecond
+
+// defaultSchemas are the schemas a table named without one is created in
+// and looked for, by dialect. MySQL looks in the database connected to.
+var defaultSchemas = map[string]string{
+	"postgres": "public",
+	"sqlite":   "main",
+	"duckdb":   "main",
+}
+
+// rowProgress counts the rows a copy, or the test data generator, has
+// inserted.
+type rowProgress struct {
+	verb  string // what is done to the rows, as "copied"
+	done  atomic.Int64
+	total atomic.Int64 // rows to insert, -1 while unknown
+}
+
+// String describes the progress: the share of the rows done when their
+// number is known.
+func (p *rowProgress) String() string {
+	done, total := p.done.Load(), p.total.Load()
+	if total > 0 {
+		return fmt.Sprintf("%d of %d rows %s (%d%%)", done, total, p.verb, done*100/total)
+	}
+	return fmt.Sprintf("%d rows %s", done, p.verb)
+}
+
+// transferTickMsg redraws the progress of copy gen.
+type transferTickMsg struct{ gen int }
+
+func transferTick(gen int) tea.Cmd {
+	return tea.Tick(transferTickInterval, func(time.Time) tea.Msg { return transferTickMsg{gen: gen} })
+}
+
+// transferDoneMsg reports copy gen finished: the rows inserted, whether
+// the table was created, and the columns of the source it does not have.
+type transferDoneMsg struct {
+	rows    int64
+	created bool
+	skipped []string
+	err     error
+	gen     int
+}
+
+// openTransfer opens the wizard that copies the rows of a table or view to
+// a saved connection.
+func (m *Model) openTransfer(msg TransferTableMsg) tea.Cmd {
+	if m.conn == nil {
+		return m.toast(ToastError, "Not connected")
+	}
+	var targets []transfer.Target
+	for _, sc := range m.cfg.Connections {
+		targets = append(targets, transfer.Target{Name: sc.Name, Adapter: sc.Adapter})
+	}
+	m.transferFrom = msg
+	m.transfer.Show(msg.Table, targets)
+	return nil
+}
+
+// startTransfer connects to the connection picked and copies the rows in
+// the background, until they are copied or the wizard stops it. The copy
+// only reads the connection open, so safe mode lets it run.
+func (m *Model) startTransfer(msg transfer.StartMsg) tea.Cmd {
+	if m.conn == nil {
+		m.transfer.Done("Not connected", true)
+		return nil
+	}
+	i := len(m.cfg.Connections) - 1
+	for i >= 0 && m.cfg.Connections[i].Name != msg.Target.Name {
+		i--
+	}
+	if i < 0 {
+		m.transfer.Done("Connection "+msg.Target.Name+" is gone", true)
+		return nil
+	}
+	sc := m.cfg.Connections[i]
+
+	m.stopTransfer()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.transferCancel = cancel
+	m.transferGen++
+	progress := &rowProgress{verb: "copied"}
+	progress.total.Store(-1)
+	m.transferProgress = progress
+	src, from, gen, writes := m.conn, m.transferFrom, m.transferGen, m.writes()
+	return tea.Batch(transferTick(gen), func() tea.Msg {
+		defer cancel()
+		done := transferDoneMsg{gen: gen}
+		dst, closeDst, err := connectSaved(ctx, sc)
+		if err != nil {
+			done.err = err
+			return done
+		}
+		defer closeDst()
+		done.created, done.skipped, done.err = copyRows(ctx, src, dst, writes.on(dst, sc.BuildDSN()), from, msg.Table, msg.Empty, progress)
+		done.rows = progress.done.Load()
+		if done.err != nil && ctx.Err() != nil {
+			done.err = ctx.Err() // adapters report a cancel their own way
+		}
+		return done
+	})
+}
+
+// copyRows copies the rows of from on src to table on dst, creating the
+// table from the columns of from when dst has none by that name, else
+// deleting its rows first if empty is set. The columns both have are
+// copied, read a batch at a time and inserted as the columns of table
+// take them. The statements run on dst are recorded with record.
+func copyRows(ctx context.Context, src, dst adapter.Connection, record writeRecorder, from TransferTableMsg, table string, empty bool, progress *rowProgress) (created bool, skipped []string, err error) {
+	srcDialect, dstDialect := src.AdapterName(), dst.AdapterName()
+	cols, err := src.Columns(ctx, from.Database, from.Schema, from.Table)
+	if err != nil {
+		return false, nil, err
+	}
+	if len(cols) == 0 {
+		return false, nil, fmt.Errorf("%s has no columns", from.Table)
+	}
+
+	dstDB, dstSchema := dst.DatabaseName(), defaultSchemas[dstDialect]
+	dstCols, err := dst.Columns(ctx, dstDB, dstSchema, table)
+	if err != nil {
+		return false, nil, err
+	}
+	quoted := adapter.QuoteIdentifier(dstDialect, table)
+	switch {
+	case len(dstCols) == 0:
+		create := ddl.CreateTable(srcDialect, dstDialect, schema.Table{Name: table, Columns: cols})
+		for _, stmt := range adapter.SplitStatements(dstDialect, create) {
+			if _, err := record.execute(ctx, dst, stmt.Text); err != nil {
+				return false, nil, fmt.Errorf("create %s: %w", table, err)
+			}
+		}
+		created = true
+		if dstCols, err = dst.Columns(ctx, dstDB, dstSchema, table); err != nil {
+			return created, nil, err
+		}
+	case empty:
+		if _, err := record.execute(ctx, dst, "DELETE FROM "+quoted); err != nil {
+			return false, nil, err
+		}
+	}
+
+	// Columns are matched by name, ignoring case.
+	var read []string
+	var write []schema.Column
+	for _, c := range cols {
+		i := len(dstCols) - 1
+		for i >= 0 && !strings.EqualFold(dstCols[i].Name, c.Name) {
+			i--
+		}
+		if i < 0 {
+			skipped = append(skipped, c.Name)
+			continue
+		}
+		read = append(read, ddl.SelectTerm(srcDialect, c.Type, c.Name))
+		write = append(write, dstCols[i])
+	}
+	if len(write) == 0 {
+		return created, skipped, fmt.Errorf("%s has none of the columns of %s", table, from.Table)
+	}
+
+	source := adapter.QuoteIdentifier(srcDialect, from.Table)
+	if from.Schema != "" && from.Schema != "main" {
+		source = adapter.QuoteIdentifier(srcDialect, from.Schema) + "." + source
+	}
+	iter, err := src.ExecuteStreaming(ctx, "SELECT "+strings.Join(read, ", ")+" FROM "+source, transferBatch)
+	if err != nil {
+		return created, skipped, err
+	}
+	defer iter.Close()
+	progress.total.Store(iter.TotalRows())
+	for {
+		rows, err := iter.FetchNext(ctx)
+		if adapter.SentinelEOF(err) || (err == nil && len(rows) == 0) {
+			return created, skipped, nil
+		}
+		if err != nil {
+			return created, skipped, err
+		}
+		if _, err := record.execute(ctx, dst, ddl.InsertRows(dstDialect, "", table, write, rows)); err != nil {
+			return created, skipped, fmt.Errorf("insert after %d rows: %w", progress.done.Load(), err)
+		}
+		progress.done.Add(int64(len(rows)))
+	}
+}
+
+// handleTransferTick shows the progress of the copy while it runs.
+func (m *Model) handleTransferTick(msg transferTickMsg) tea.Cmd {
+	if msg.gen != m.transferGen || !m.transfer.Running() || m.transferProgress == nil {
+		return nil
+	}
+	m.transfer.SetProgress(m.transferProgress.String())
+	return transferTick(msg.gen)
+}
+
+// stopTransfer cancels the copy running, if any.
+func (m *Model) stopTransfer() {
+	if m.transferCancel != nil {
+		m.transferCancel()
+		m.transferCancel = nil
+	}
+}
+
+// handleTransferDone reports how the copy ended.
+func (m *Model) handleTransferDone(msg transferDoneMsg) tea.Cmd {
+	if msg.gen != m.transferGen {
+		return nil
+	}
+	m.stopTransfer()
+	m.transferProgress = nil
+	text := fmt.Sprintf("%d rows copied", msg.rows)
+	if msg.created {
+		text += " into a new table"
+	}
+	if len(msg.skipped) > 0 {
+		text += "; left out " + strings.Join(msg.skipped, ", ") + ", missing from the target"
+	}
+	switch {
+	case errors.Is(msg.err, context.Canceled):
+		m.transfer.Done(fmt.Sprintf("Stopped after %d rows", msg.rows), true)
+		return nil
+	case msg.err != nil:
+		m.transfer.Done(sanitizeError(msg.err.Error()), true)
+		return nil
+	}
+	m.transfer.Done(text, false)
+	if !m.transfer.Visible() {
+		return m.toast(ToastSuccess, text)
+	}
+	return nil
+}
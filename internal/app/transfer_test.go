@@ -0,0 +1,115 @@
+package app
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/ui/transfer"
+)
+
+// openSQLite connects to a SQLite file, running setup on it.
+func openSQLite(t *testing.T, path string, setup ...string) config.SavedConnection {
+	t.Helper()
+	sc := config.SavedConnection{Name: filepath.Base(path), Adapter: "sqlite", File: path}
+	conn, closeConn, err := connectSaved(context.Background(), sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeConn()
+	for _, q := range setup {
+		if _, err := conn.Execute(context.Background(), q); err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+	}
+	return sc
+}
+
+func TestTransfer(t *testing.T) {
+	dir := t.TempDir()
+	src := openSQLite(t, filepath.Join(dir, "prod.db"),
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, active BOOLEAN, note TEXT)",
+		"INSERT INTO users VALUES (1, 'ann', 1, 'it''s'), (2, 'bob', 0, NULL)")
+	dst := openSQLite(t, filepath.Join(dir, "local.db"),
+		"CREATE TABLE people (ID INTEGER, name TEXT, active BOOLEAN)",
+		"INSERT INTO people VALUES (9, 'old', 0)")
+
+	cfg := config.DefaultConfig()
+	cfg.Connections = []config.SavedConnection{src, dst}
+	m := step(New(cfg, nil, nil), tea.WindowSizeMsg{Width: 120, Height: 40})
+	conn, closeConn, err := connectSaved(context.Background(), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeConn()
+	m.conn = conn
+
+	run := func(table string, empty bool) transferDoneMsg {
+		t.Helper()
+		m = step(m, TransferTableMsg{Schema: "main", Table: "users"})
+		if !m.transfer.Visible() {
+			t.Fatal("the copy wizard should open")
+		}
+		// Pick local.db and name the table, skipping the cursor blink.
+		for _, key := range []tea.KeyMsg{{Type: tea.KeyDown}, {Type: tea.KeyEnter}, {Type: tea.KeyCtrlU}, {Type: tea.KeyRunes, Runes: []rune(table)}} {
+			model, _ := m.Update(key)
+			m = model.(Model)
+		}
+		if empty {
+			m = step(m, tea.KeyMsg{Type: tea.KeyTab})
+		}
+		model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = model.(Model)
+		start, ok := cmd().(transfer.StartMsg)
+		if !ok || start.Target.Name != "local.db" || start.Table != table || start.Empty != empty {
+			t.Fatalf("enter sent %#v", start)
+		}
+		model, cmd = m.Update(start)
+		m = model.(Model)
+		if !m.transfer.Running() {
+			t.Fatal("starting should show the progress")
+		}
+		var done transferDoneMsg
+		for _, msg := range drainBatch(cmd) {
+			if d, ok := msg.(transferDoneMsg); ok {
+				done = d
+			}
+		}
+		m = step(m, done)
+		return done
+	}
+
+	done := run("people", true)
+	if done.err != nil || done.rows != 2 || done.created || strings.Join(done.skipped, ",") != "note" {
+		t.Fatalf("copy into people = %+v", done)
+	}
+	if view := m.transfer.View(); !strings.Contains(view, "2 rows copied; left out note") {
+		t.Errorf("wizard should report the copy:\n%s", view)
+	}
+
+	done = run("users_copy", false)
+	if done.err != nil || done.rows != 2 || !done.created || len(done.skipped) != 0 {
+		t.Fatalf("copy into users_copy = %+v", done)
+	}
+
+	check, closeCheck, err := connectSaved(context.Background(), dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeCheck()
+	for q, want := range map[string]string{
+		"SELECT group_concat(ID || name || active, ',') FROM people":                  "1ann1,2bob0",
+		"SELECT group_concat(id || name || coalesce(note, '-'), ',') FROM users_copy": "1annit's,2bob-",
+	} {
+		res, err := check.Execute(context.Background(), q)
+		if err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+		if got := res.Rows[0][0]; got != want {
+			t.Errorf("%s = %q, want %q", q, got, want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/theme"
+	"github.com/sadopc/gotermsql/internal/tunnel"
+)
+
+// connectSaved connects to a saved connection, through its SSH tunnel if it
+// has one. The tunnel travels with the ConnectMsg and is closed with the
+// connection.
+func (m *Model) connectSaved(sc config.SavedConnection) tea.Cmd {
+	return func() tea.Msg {
+		return dialSaved(context.Background(), sc)
+	}
+}
+
+// dialSaved opens the tunnel and connection of a saved connection whose
+// password is already resolved, giving up after 30 seconds or when ctx is
+// cancelled. It returns a ConnectMsg or ConnectErrMsg.
+func dialSaved(ctx context.Context, sc config.SavedConnection) tea.Msg {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	dsn, tun, err := tunnel.ForConnection(ctx, sc)
+	if err != nil {
+		return ConnectErrMsg{Err: err}
+	}
+	conn, err := openConnection(ctx, sc.Adapter, dsn, sessionInit(sc))
+	if err != nil {
+		if tun != nil {
+			tun.Close()
+		}
+		return ConnectErrMsg{Err: err}
+	}
+	// Keep the real server in the DSN shown and audited, not the local
+	// end of the tunnel.
+	return ConnectMsg{
+		Conn:     conn,
+		Adapter:  sc.Adapter,
+		DSN:      sc.BuildDSN(),
+		Tunnel:   tun,
+		Name:     sc.Name,
+		Env:      sc.Env,
+		EnvColor: theme.EnvColor(sc.Env, sc.Color),
+		Defaults: sc.Defaults,
+	}
+}
+
+// sessionInit returns the statements run on every session of a saved
+// connection: the session settings of its defaults, then its startup SQL.
+func sessionInit(sc config.SavedConnection) []string {
+	d := sc.Defaults
+	if d == nil {
+		return nil
+	}
+	init := adapter.SessionSQL(sc.Adapter, adapter.Session{
+		ReadOnly:         d.ReadOnly,
+		StatementTimeout: d.StatementTimeout,
+		Schema:           d.Schema,
+	})
+	return append(init, d.StartupSQL...)
+}
+
+// watchTunnel reports when the tunnel of connection generation gen exits.
+// Tunnels closed on reconnect or quit report with an old generation and
+// are ignored.
+func watchTunnel(tun *tunnel.Tunnel, gen uint64) tea.Cmd {
+	return func() tea.Msg {
+		<-tun.Done()
+		return TunnelClosedMsg{Err: tun.Err(), ConnGen: gen}
+	}
+}
+
+// closeTunnel stops the SSH tunnel of the current connection, if any.
+func (m *Model) closeTunnel() {
+	if m.tunnel != nil {
+		_ = m.tunnel.Close()
+		m.tunnel = nil
+	}
+}
+
+// Tunnel returns the SSH tunnel of the current connection, or nil.
+func (m Model) Tunnel() *tunnel.Tunnel {
+	return m.tunnel
+}
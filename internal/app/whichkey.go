@@ -0,0 +1,100 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+// whichKey renders the hints for a leader mapping, a key chord or a vim
+// command still being typed in the focused editor: each key that can
+// follow with what it does, from the KeyMap's chords and sequences, fitting
+// in width and height. It returns "" when nothing is being typed.
+func (m Model) whichKey(width, height int) string {
+	ts := m.activeTabState()
+	var typed []string
+	switch {
+	case m.leaderOn:
+		typed = []string{"<leader>"}
+		for _, k := range m.leader {
+			typed = append(typed, hintKeyName(k))
+		}
+	case m.chord != nil:
+		typed = []string{m.chord.String()}
+	case ts != nil && m.focusedPane == PaneEditor:
+		typed = hintPrefix(ts.Editor.VimPending())
+	}
+	if len(typed) == 0 {
+		return ""
+	}
+	next := m.keyMap.Continuations(typed)
+	if len(next) == 0 {
+		return ""
+	}
+	th := theme.Current
+
+	keyW, cellW := 0, 0
+	for _, b := range next {
+		keyW = max(keyW, runewidth.StringWidth(b.Help().Key))
+	}
+	for _, b := range next {
+		cellW = max(cellW, keyW+3+runewidth.StringWidth(b.Help().Desc))
+	}
+	// Border and padding take four columns.
+	inner := max(width-4, 1)
+	cellW = max(min(cellW, inner), keyW)
+	cols := max((inner+2)/(cellW+2), 1)
+	rows := (len(next) + cols - 1) / cols
+	// Border and title take three lines.
+	if maxRows := max(height-3, 1); rows > maxRows {
+		rows = maxRows
+		next = next[:min(len(next), rows*cols)]
+	}
+
+	lines := make([]string, rows)
+	for i, b := range next {
+		row := i % rows
+		h := b.Help()
+		desc := runewidth.Truncate(" → "+h.Desc, cellW-keyW, "…")
+		if lines[row] != "" {
+			lines[row] += "  "
+		}
+		lines[row] += th.DialogTitle.Render(runewidth.FillRight(h.Key, keyW)) + runewidth.FillRight(desc, cellW-keyW)
+	}
+	title := th.MutedText.Render(strings.Join(typed, " ") + " …")
+	return th.AutocompleteBorder.Padding(0, 1).Render(title + "\n" + strings.Join(lines, "\n"))
+}
+
+// hintPrefix drops the register and counts from the keys typed for a vim
+// command, as they do not change what can follow.
+func hintPrefix(keys []string) []string {
+	var typed []string
+	digit := func(k string) bool { return len(k) == 1 && k[0] >= '0' && k[0] <= '9' }
+	for i := 0; i < len(keys); i++ {
+		k := keys[i]
+		switch {
+		case i == 0 && k == `"` && len(keys) > 1:
+			i++
+			continue
+		case digit(k) && (k != "0" || i > 0 && digit(keys[i-1])):
+			continue
+		}
+		typed = append(typed, k)
+	}
+	return typed
+}
+
+// overlayBottom draws popup over the last lines of view, or below its first
+// line if view is too short for it.
+func overlayBottom(view, popup string) string {
+	lines := strings.Split(view, "\n")
+	popupH := strings.Count(popup, "\n") + 1
+	if popupH < len(lines) {
+		return strings.Join(lines[:len(lines)-popupH], "\n") + "\n" + popup
+	}
+	if len(lines) > 1 {
+		return lines[0] + "\n" + popup
+	}
+	return view
+}
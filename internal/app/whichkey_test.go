@@ -0,0 +1,72 @@
+package app
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+func TestWhichKey_PendingVimCommand(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.KeyMode = "vim"
+	m := New(cfg, nil, nil)
+	m.tabStates[m.tabs.ActiveID()].Editor.SetValue("SELECT abc FROM t")
+	m.tabStates[m.tabs.ActiveID()].Editor.SetCursor(0, 0)
+	press := func(s string) {
+		model, _ := m.Update(keyMsgFromString(s))
+		m = model.(Model)
+	}
+
+	if got := m.whichKey(80, 20); got != "" {
+		t.Fatalf("no hints expected before a key, got %q", got)
+	}
+	press("d")
+	hints := m.whichKey(80, 20)
+	for _, want := range []string{"d …", "delete line", "to next word", "inside object"} {
+		if !strings.Contains(hints, want) {
+			t.Errorf("hints after d should contain %q:\n%s", want, hints)
+		}
+	}
+	press("i")
+	if hints := m.whichKey(80, 20); !strings.Contains(hints, "inside word") || strings.Contains(hints, "delete line") {
+		t.Errorf("hints after di should list text objects:\n%s", hints)
+	}
+	press("w")
+	if got := m.whichKey(80, 20); got != "" {
+		t.Errorf("hints should go once the command is done, got %q", got)
+	}
+	if got := m.tabStates[m.tabs.ActiveID()].Editor.Value(); got != " abc FROM t" {
+		t.Errorf("Value() = %q after diw", got)
+	}
+
+	// Too little room cuts the list short rather than growing the editor.
+	press("d")
+	if lines := strings.Count(m.whichKey(80, 6), "\n") + 1; lines > 6 {
+		t.Errorf("hints take %d lines in a 6 line editor", lines)
+	}
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = model.(Model)
+	if got := m.whichKey(80, 20); got != "" {
+		t.Errorf("esc should drop the hints, got %q", got)
+	}
+}
+
+func TestHintPrefix(t *testing.T) {
+	tests := []struct {
+		keys, want []string
+	}{
+		{[]string{"d"}, []string{"d"}},
+		{[]string{"1", "0", "d", "2"}, []string{"d"}},
+		{[]string{`"`, "a", "y"}, []string{"y"}},
+		{[]string{`"`}, []string{`"`}},
+		{[]string{"d", "i"}, []string{"d", "i"}},
+	}
+	for _, tt := range tests {
+		if got := hintPrefix(tt.keys); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("hintPrefix(%q) = %q, want %q", tt.keys, got, tt.want)
+		}
+	}
+}
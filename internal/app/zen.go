@@ -0,0 +1,55 @@
+package app
+
+// toggleZen turns zen mode on or off. On, the editor takes the whole
+// screen, or the results while they are focused; the tab bar, sidebar and
+// status bar are hidden. Off, the layout is as it was, with the sidebar
+// focused again if it was when zen mode was turned on.
+func (m *Model) toggleZen() {
+	if !m.zen {
+		m.zenFrom = m.focusedPane
+		if m.focusedPane == PaneSidebar {
+			m.setFocus(PaneEditor)
+		}
+		m.zen = true
+		m.updateLayout()
+		return
+	}
+	m.zen = false
+	m.updateLayout()
+	if m.zenFrom == PaneSidebar && m.showSidebar {
+		m.setFocus(PaneSidebar)
+	}
+}
+
+// zenView draws the focused pane of the active tab over the whole screen.
+func (m Model) zenView() string {
+	ts := m.activeTabState()
+	if ts == nil {
+		return "No active tab"
+	}
+
+	if m.focusedPane == PaneResults {
+		resultsH := m.height
+		banner := lintBanner(ts, m.width)
+		if banner != "" && resultsH > 3 {
+			resultsH--
+		} else {
+			banner = ""
+		}
+		ts.Results.SetSize(m.width, resultsH)
+		if banner != "" {
+			return banner + "\n" + ts.Results.View()
+		}
+		return ts.Results.View()
+	}
+
+	ts.Editor.SetSize(m.width, m.height)
+	view := ts.Editor.View()
+	if m.autocomp.Visible() {
+		view = overlayBottom(view, m.autocomp.View())
+	}
+	if hints := m.whichKey(m.width, m.height); hints != "" {
+		view = overlayBottom(view, hints)
+	}
+	return view
+}
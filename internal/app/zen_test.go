@@ -0,0 +1,63 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+func TestZen(t *testing.T) {
+	m := New(config.DefaultConfig(), nil, nil)
+	m.conn = &testConn{dbName: "app"}
+	update := func(msg tea.Msg) {
+		model, _ := m.Update(msg)
+		m = model.(Model)
+	}
+	zenKey := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'z'}, Alt: true}
+	update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	m.setFocus(PaneSidebar)
+	normal := m.View()
+
+	update(zenKey)
+	if !m.zen || m.focusedPane != PaneEditor {
+		t.Fatalf("zen = %v, focus = %v; want zen with the editor focused", m.zen, m.focusedPane)
+	}
+	view := m.View()
+	if lines := strings.Count(view, "\n") + 1; lines != 30 {
+		t.Errorf("zen view has %d lines, want the full 30", lines)
+	}
+	for _, hidden := range []string{"Query 1", "disconnected", "Schema Browser"} {
+		if strings.Contains(view, hidden) {
+			t.Errorf("zen view should hide the bars, found %q:\n%s", hidden, view)
+		}
+	}
+	if l := m.layout(); l.tabH != 0 || l.mainX != 0 || l.resultsY != 30 {
+		t.Errorf("zen layout = %+v, want the editor over the whole screen", l)
+	}
+
+	// Focus moves between the editor and results only, each full screen.
+	update(keyMsgFromString("shift+tab"))
+	if m.focusedPane != PaneResults || m.layout().resultsY != 0 {
+		t.Errorf("shift+tab in zen: focus = %v, layout = %+v", m.focusedPane, m.layout())
+	}
+	update(keyMsgFromString("shift+tab"))
+	if m.focusedPane != PaneEditor {
+		t.Errorf("shift+tab in zen should skip the hidden sidebar, focus = %v", m.focusedPane)
+	}
+
+	update(zenKey)
+	if m.zen || m.focusedPane != PaneSidebar {
+		t.Errorf("leaving zen: zen = %v, focus = %v; want the sidebar focused again", m.zen, m.focusedPane)
+	}
+	if got := m.View(); got != normal {
+		t.Errorf("leaving zen should restore the layout:\n%s\nwant:\n%s", got, normal)
+	}
+
+	update(zenKey)
+	m.setFocus(PaneSidebar)
+	if m.zen {
+		t.Error("focusing the sidebar should leave zen")
+	}
+}
@@ -0,0 +1,258 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Hash chaining: each entry's hash is the SHA-256 of its JSON line as
+// written without the hash field, and that JSON includes the hash of the
+// entry before it. Changing, removing or reordering entries breaks the
+// chain from that point on. Entries cut off the end leave no trace in the
+// file, so keep the latest hash (Verifier.Head) somewhere else, or forward
+// entries to syslog, to notice that.
+//
+// Several instances may log to the same file, so each entry is written
+// under a lock on the file and chained to the last entry in it then, not
+// to the last one this Logger wrote.
+
+// Chain turns on hash chaining for the entries logged from now on,
+// continuing from the last entry in the file. Calling Chain on a nil
+// Logger is a no-op.
+func (l *Logger) Chain() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := lastHash(l.path); err != nil {
+		return fmt.Errorf("audit: read last entry: %w", err)
+	}
+	l.chain = true
+	return nil
+}
+
+// writeChained appends the line for e, chained to the last entry in the
+// file, holding the lock on the file from reading that entry until the
+// line is written and the file rotated if it is due.
+func (l *Logger) writeChained(e *Entry) error {
+	f, err := l.lockCurrent()
+	if err != nil {
+		return err
+	}
+	defer unlockFile(f) // rotating closes f, which unlocks it already
+	prev, err := l.chainHead()
+	if err != nil {
+		return err
+	}
+	line, err := chainLine(e, prev)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(line); err != nil {
+		return err
+	}
+	if l.maxSizeMB > 0 {
+		l.rotateIfNeeded()
+	}
+	return nil
+}
+
+// lockCurrent locks the file at l.path, reopening it first when another
+// instance has rotated the one l holds away.
+func (l *Logger) lockCurrent() (*os.File, error) {
+	for {
+		if err := lockFile(l.f); err != nil {
+			return nil, err
+		}
+		held, err := l.f.Stat()
+		if err != nil {
+			unlockFile(l.f)
+			return nil, err
+		}
+		if current, err := os.Stat(l.path); err == nil && os.SameFile(held, current) {
+			return l.f, nil
+		}
+		unlockFile(l.f)
+		f, err := openLog(l.path)
+		if err != nil {
+			return nil, err
+		}
+		_ = l.f.Close()
+		l.f = f
+		l.enc = json.NewEncoder(f)
+	}
+}
+
+// chainHead returns the hash to chain the next entry to: that of the last
+// entry in the file, or, when the file was just rotated, of the last in
+// the rotated one.
+func (l *Logger) chainHead() (string, error) {
+	info, err := l.f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > 0 {
+		return lastHash(l.path)
+	}
+	return lastHash(l.path + ".1")
+}
+
+// chainLine returns the JSON line for e, chained to the entry whose hash
+// is prev, and sets e's hashes.
+func chainLine(e *Entry, prev string) ([]byte, error) {
+	e.PrevHash = prev
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	e.Hash = hex.EncodeToString(sum[:])
+	// Hash is the last field, so this is what marshaling e now gives.
+	line := append(data[:len(data)-1], hashSuffix(e.Hash)...)
+	return append(line, '\n'), nil
+}
+
+// hashSuffix is how a chained JSON line ends.
+func hashSuffix(hash string) []byte {
+	return []byte(`,"hash":"` + hash + `"}`)
+}
+
+// lastHash returns the hash of the last entry in the file at path, or ""
+// if the file is missing or empty or its last entry is not chained.
+func lastHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	defer f.Close()
+	line, err := lastLine(f)
+	if err != nil || len(line) == 0 {
+		return "", err
+	}
+	var e struct {
+		Hash string `json:"hash"`
+	}
+	if json.Unmarshal(line, &e) != nil {
+		return "", nil
+	}
+	return e.Hash, nil
+}
+
+// lastLine returns the last non-empty line of f, reading backwards so a
+// large log is not read whole.
+func lastLine(f *os.File) ([]byte, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	const chunk = 4096
+	var line []byte
+	end := info.Size()
+	for end > 0 {
+		start := max(end-chunk, 0)
+		buf := make([]byte, end-start)
+		if _, err := f.ReadAt(buf, start); err != nil {
+			return nil, err
+		}
+		line = append(buf, line...)
+		end = start
+		trimmed := bytes.TrimRight(line, "\r\n")
+		if i := bytes.LastIndexByte(trimmed, '\n'); i >= 0 {
+			return trimmed[i+1:], nil
+		}
+	}
+	return bytes.TrimRight(line, "\r\n"), nil
+}
+
+// ChainError reports where an audit log's hash chain is broken.
+type ChainError struct {
+	Line   int
+	Reason string
+}
+
+func (e *ChainError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Reason)
+}
+
+// Verifier checks the hash chain of audit log files. Pass the files to
+// Verify oldest first, e.g. audit.jsonl.1 then audit.jsonl, so the chain
+// is followed across a rotation.
+type Verifier struct {
+	Entries   int    // entries read
+	Chained   int    // entries with a verified hash
+	Unchained int    // entries written before chaining was turned on
+	Anchor    string // previous hash of the first chained entry, if it is not in the files
+	Head      string // hash of the last chained entry
+}
+
+// Verify reads the entries in r and checks each chained one: that its hash
+// matches its content and that it follows the entry before it. Entries
+// without a hash are only allowed before the first chained one. It
+// returns a *ChainError for the first problem found.
+func (v *Verifier) Verify(r io.Reader) error {
+	br := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		line, err := br.ReadBytes('\n')
+		if len(line) == 0 && err == io.EOF {
+			return nil
+		}
+		if err != nil && err != io.EOF {
+			return err
+		}
+		line = bytes.TrimRight(line, "\r\n")
+		if len(line) == 0 {
+			continue
+		}
+		if cerr := v.check(line); cerr != "" {
+			return &ChainError{Line: n, Reason: cerr}
+		}
+	}
+}
+
+// check verifies one line, returning what is wrong with it or "".
+func (v *Verifier) check(line []byte) string {
+	var e struct {
+		PrevHash string `json:"prev_hash"`
+		Hash     string `json:"hash"`
+	}
+	if err := json.Unmarshal(line, &e); err != nil {
+		return "not a JSON entry"
+	}
+	v.Entries++
+	if e.Hash == "" {
+		if v.Chained > 0 {
+			return "entry has no hash; it was added or altered after chaining began"
+		}
+		v.Unchained++
+		return ""
+	}
+
+	suffix := hashSuffix(e.Hash)
+	if !bytes.HasSuffix(line, suffix) {
+		return "hash is not the last field of the entry"
+	}
+	body := append(bytes.Clone(line[:len(line)-len(suffix)]), '}')
+	sum := sha256.Sum256(body)
+	if hex.EncodeToString(sum[:]) != e.Hash {
+		return "hash does not match the entry; it was modified"
+	}
+	if v.Chained == 0 {
+		v.Anchor = e.PrevHash
+	} else if e.PrevHash != v.Head {
+		return "previous hash does not match; an entry before it was removed or reordered"
+	}
+	v.Chained++
+	v.Head = e.Hash
+	return ""
+}
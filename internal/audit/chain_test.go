@@ -0,0 +1,174 @@
+package audit
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func writeChained(t *testing.T, path string, queries ...string) {
+	t.Helper()
+	l, err := New(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if err := l.Chain(); err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range queries {
+		l.Log(Entry{Timestamp: time.Now(), Query: q, Adapter: "sqlite"})
+	}
+}
+
+func verify(t *testing.T, paths ...string) (*Verifier, error) {
+	t.Helper()
+	v := &Verifier{}
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := v.Verify(bytes.NewReader(data)); err != nil {
+			return v, err
+		}
+	}
+	return v, nil
+}
+
+func TestChain_Verifies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	// Entries from before chaining was turned on are allowed.
+	l, err := New(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Log(Entry{Query: "SELECT 0"})
+	l.Close()
+
+	writeChained(t, path, "SELECT 1", `SELECT '<"quoted">'`)
+	// Reopening continues the chain.
+	writeChained(t, path, "SELECT 3")
+
+	v, err := verify(t, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Entries != 4 || v.Chained != 3 || v.Unchained != 1 || v.Anchor != "" {
+		t.Errorf("verifier = %+v", v)
+	}
+	last, _ := lastHash(path)
+	if v.Head == "" || v.Head != last {
+		t.Errorf("head = %q, want the last entry's hash %q", v.Head, last)
+	}
+}
+
+func TestChain_DetectsTampering(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.jsonl")
+	writeChained(t, path, "SELECT 1", "DELETE FROM users", "SELECT 3")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitAfter(strings.TrimSuffix(string(data), "\n"), "\n")
+
+	tests := []struct {
+		name  string
+		lines []string
+		line  int
+	}{
+		{"modified", []string{lines[0], strings.Replace(lines[1], "DELETE", "SELECT", 1), lines[2]}, 2},
+		{"removed", []string{lines[0], lines[2]}, 2},
+		{"reordered", []string{lines[1], lines[0], lines[2]}, 2},
+		{"inserted", []string{lines[0], `{"query":"SELECT 2"}` + "\n", lines[1], lines[2]}, 2},
+		{"truncated", []string{lines[0], lines[1][:40]}, 2},
+	}
+	for _, tt := range tests {
+		p := filepath.Join(dir, tt.name+".jsonl")
+		if err := os.WriteFile(p, []byte(strings.Join(tt.lines, "")), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := verify(t, p)
+		var ce *ChainError
+		if !errors.As(err, &ce) || ce.Line != tt.line {
+			t.Errorf("%s: got %v, want a chain error on line %d", tt.name, err, tt.line)
+		}
+	}
+}
+
+func TestChain_AcrossRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.jsonl")
+	l, err := New(path, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Chain(); err != nil {
+		t.Fatal(err)
+	}
+	bigQuery := strings.Repeat("x", 10000)
+	for range 120 {
+		l.Log(Entry{Query: bigQuery, Adapter: "test"})
+	}
+	l.Close()
+
+	v, err := verify(t, path+".1", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Chained != 120 {
+		t.Errorf("chained = %d, want 120", v.Chained)
+	}
+	// The newer file alone starts from an entry it does not have.
+	v, err = verify(t, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Anchor == "" {
+		t.Error("expected the rotated file's chain to be anchored to the backup")
+	}
+}
+
+// Two instances logging to the same file take turns on it, each chaining
+// to the last entry either wrote, across a rotation one of them does.
+func TestChain_TwoWriters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	var loggers []*Logger
+	for range 2 {
+		l, err := New(path, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer l.Close()
+		if err := l.Chain(); err != nil {
+			t.Fatal(err)
+		}
+		loggers = append(loggers, l)
+	}
+	bigQuery := strings.Repeat("x", 10000)
+	var wg sync.WaitGroup
+	for _, l := range loggers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 60 {
+				l.Log(Entry{Query: bigQuery, Adapter: "test"})
+			}
+		}()
+	}
+	wg.Wait()
+
+	v, err := verify(t, path+".1", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Chained != 120 {
+		t.Errorf("chained = %d, want 120", v.Chained)
+	}
+}
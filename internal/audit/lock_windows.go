@@ -0,0 +1,27 @@
+//go:build windows
+
+package audit
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockOffset is where the byte locked lies, far past the end of any log:
+// Windows locks are mandatory, and a lock on the entries would keep them
+// from being read back.
+const lockOffset = 0x7fffffff
+
+// lockFile takes an exclusive lock on f, waiting while another process
+// holds it.
+func lockFile(f *os.File) error {
+	ol := windows.Overlapped{OffsetHigh: lockOffset}
+	return windows.LockFileEx(windows.Handle(f.Fd()), windows.LOCKFILE_EXCLUSIVE_LOCK, 0, 1, 0, &ol)
+}
+
+// unlockFile releases the lock lockFile took.
+func unlockFile(f *os.File) error {
+	ol := windows.Overlapped{OffsetHigh: lockOffset}
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, 1, 0, &ol)
+}
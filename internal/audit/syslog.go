@@ -0,0 +1,194 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Syslog priority parts: entries are sent with the user facility, at
+// info severity, or notice for failed queries.
+const (
+	facilityUser   = 1
+	severityNotice = 5
+	severityInfo   = 6
+)
+
+const (
+	forwardQueue   = 256             // entries waiting to be sent before new ones are dropped
+	forwardTimeout = 5 * time.Second // bound on dialing and each write
+	forwardDrain   = 2 * time.Second // how long Close waits for queued entries
+)
+
+// localSyslog lists the sockets of the local syslog daemon, by platform.
+var localSyslog = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// forwarder sends entries to a syslog daemon or collector in the
+// background, so a slow endpoint never holds up Log.
+type forwarder struct {
+	network  string
+	addrs    []string // tried in order when dialing
+	conn     net.Conn
+	hostname string
+	pid      int
+	entries  chan Entry
+	done     chan struct{}
+}
+
+// Forward sends every entry logged from now on to syslog as well as the
+// file. target is "local" for the local syslog daemon, or a URL:
+// udp://host[:port], tcp://host[:port] (port 514 by default) or
+// unix:///path/to/socket. Each entry is an RFC 5424 message whose text is
+// the entry's JSON. If the endpoint is unreachable later, entries are
+// dropped until it is back; the file still has them all.
+func (l *Logger) Forward(target string) error {
+	if l == nil {
+		return nil
+	}
+	network, addrs, err := parseTarget(target)
+	if err != nil {
+		return fmt.Errorf("audit: syslog: %w", err)
+	}
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+	f := &forwarder{
+		network:  network,
+		addrs:    addrs,
+		hostname: hostname,
+		pid:      os.Getpid(),
+		entries:  make(chan Entry, forwardQueue),
+		done:     make(chan struct{}),
+	}
+	if err := f.dial(); err != nil {
+		return fmt.Errorf("audit: syslog: %w", err)
+	}
+	go f.run()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.fwd != nil {
+		l.fwd.close()
+	}
+	l.fwd = f
+	return nil
+}
+
+// parseTarget returns the network and addresses to dial for a Forward
+// target.
+func parseTarget(target string) (string, []string, error) {
+	if target == "local" {
+		return "unixgram", localSyslog, nil
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", nil, err
+	}
+	switch u.Scheme {
+	case "udp", "tcp":
+		if u.Hostname() == "" {
+			return "", nil, fmt.Errorf("%q has no host", target)
+		}
+		port := u.Port()
+		if port == "" {
+			port = "514"
+		}
+		return u.Scheme, []string{net.JoinHostPort(u.Hostname(), port)}, nil
+	case "unix", "unixgram":
+		if u.Path == "" {
+			return "", nil, fmt.Errorf("%q has no socket path", target)
+		}
+		return "unixgram", []string{u.Path}, nil
+	}
+	return "", nil, fmt.Errorf("unsupported target %q (want local, udp://, tcp:// or unix://)", target)
+}
+
+// dial connects to the first address that answers.
+func (f *forwarder) dial() error {
+	var errs []error
+	for _, addr := range f.addrs {
+		conn, err := net.DialTimeout(f.network, addr, forwardTimeout)
+		if err == nil {
+			f.conn = conn
+			return nil
+		}
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// send queues e, dropping it if the queue is full.
+func (f *forwarder) send(e Entry) {
+	select {
+	case f.entries <- e:
+	default:
+	}
+}
+
+func (f *forwarder) run() {
+	defer close(f.done)
+	for e := range f.entries {
+		msg, err := formatSyslog(e, f.hostname, f.pid)
+		if err != nil {
+			continue
+		}
+		if f.network == "tcp" {
+			// Octet-counting framing (RFC 6587), as messages can span lines.
+			msg = append([]byte(fmt.Sprintf("%d ", len(msg))), msg...)
+		}
+		// A dropped connection is redialed once per entry.
+		if f.write(msg) != nil {
+			if f.conn != nil {
+				f.conn.Close()
+				f.conn = nil
+			}
+			if f.dial() == nil {
+				_ = f.write(msg)
+			}
+		}
+	}
+	if f.conn != nil {
+		f.conn.Close()
+	}
+}
+
+func (f *forwarder) write(msg []byte) error {
+	if f.conn == nil {
+		return net.ErrClosed
+	}
+	_ = f.conn.SetWriteDeadline(time.Now().Add(forwardTimeout))
+	_, err := f.conn.Write(msg)
+	return err
+}
+
+// close stops taking entries and waits a little for the queued ones to be
+// sent.
+func (f *forwarder) close() {
+	close(f.entries)
+	select {
+	case <-f.done:
+	case <-time.After(forwardDrain):
+	}
+}
+
+// formatSyslog formats e as an RFC 5424 syslog message.
+func formatSyslog(e Entry, hostname string, pid int) ([]byte, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	severity := severityInfo
+	if e.IsError {
+		severity = severityNotice
+	}
+	ts := e.Timestamp.UTC().Format("2006-01-02T15:04:05.000000Z07:00")
+	hostname = strings.ReplaceAll(hostname, " ", "-")
+	return []byte(fmt.Sprintf("<%d>1 %s %s gotermsql %d query - %s",
+		facilityUser*8+severity, ts, hostname, pid, data)), nil
+}
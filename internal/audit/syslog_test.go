@@ -0,0 +1,164 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testEntry() Entry {
+	return Entry{
+		Timestamp:    time.Date(2026, 1, 2, 3, 4, 5, 123456789, time.UTC),
+		Query:        "SELECT *\nFROM users",
+		Adapter:      "postgres",
+		DatabaseName: "shop",
+		DurationMS:   7,
+		RowCount:     3,
+		DSN:          "postgres://***@db/shop",
+	}
+}
+
+// syslogJSON returns the entry in a syslog message after checking its header.
+func syslogJSON(t *testing.T, msg string, wantPri int) Entry {
+	t.Helper()
+	prefix := "<" + strconv.Itoa(wantPri) + ">1 2026-01-02T03:04:05.123456Z "
+	if !strings.HasPrefix(msg, prefix) {
+		t.Fatalf("message %q does not start with %q", msg, prefix)
+	}
+	_, data, ok := strings.Cut(msg, " query - ")
+	if !ok {
+		t.Fatalf("message %q has no MSGID", msg)
+	}
+	var e Entry
+	if err := json.Unmarshal([]byte(data), &e); err != nil {
+		t.Fatalf("message body is not an entry: %v\n%s", err, data)
+	}
+	return e
+}
+
+func TestForward_UDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("udp: %v", err)
+	}
+	defer pc.Close()
+
+	l, err := New(filepath.Join(t.TempDir(), "audit.jsonl"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if err := l.Forward("udp://" + pc.LocalAddr().String()); err != nil {
+		t.Fatalf("Forward: %v", err)
+	}
+	e := testEntry()
+	e.IsError = true
+	l.Log(e)
+
+	buf := make([]byte, 4096)
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	got := syslogJSON(t, string(buf[:n]), facilityUser*8+severityNotice)
+	if got.Query != e.Query || !got.IsError || got.DSN != e.DSN {
+		t.Errorf("forwarded %+v, want %+v", got, e)
+	}
+}
+
+func TestForward_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("tcp: %v", err)
+	}
+	defer ln.Close()
+	received := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			size, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(size))
+			msg := make([]byte, n)
+			if _, err := io.ReadFull(r, msg); err != nil {
+				return
+			}
+			received <- string(msg)
+		}
+	}()
+
+	l, err := New(filepath.Join(t.TempDir(), "audit.jsonl"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Forward("tcp://" + ln.Addr().String()); err != nil {
+		t.Fatalf("Forward: %v", err)
+	}
+	l.Log(testEntry())
+	// Close sends what is queued.
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-received:
+		if got := syslogJSON(t, msg, facilityUser*8+severityInfo); got.Query != "SELECT *\nFROM users" {
+			t.Errorf("forwarded query %q", got.Query)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		target, network, addr string
+	}{
+		{"udp://logs.example.com", "udp", "logs.example.com:514"},
+		{"tcp://10.0.0.5:6514", "tcp", "10.0.0.5:6514"},
+		{"unix:///var/run/syslog", "unixgram", "/var/run/syslog"},
+		{"local", "unixgram", "/dev/log"},
+	}
+	for _, tt := range tests {
+		network, addrs, err := parseTarget(tt.target)
+		if err != nil {
+			t.Errorf("parseTarget(%q): %v", tt.target, err)
+			continue
+		}
+		if network != tt.network || addrs[0] != tt.addr {
+			t.Errorf("parseTarget(%q) = %s %v, want %s %s", tt.target, network, addrs, tt.network, tt.addr)
+		}
+	}
+	for _, bad := range []string{"http://example.com", "udp://", "unix://", "logs.example.com"} {
+		if _, _, err := parseTarget(bad); err == nil {
+			t.Errorf("parseTarget(%q): expected an error", bad)
+		}
+	}
+}
+
+func TestForward_Unreachable(t *testing.T) {
+	l, err := New(filepath.Join(t.TempDir(), "audit.jsonl"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if err := l.Forward("unix://" + filepath.Join(t.TempDir(), "missing.sock")); err == nil {
+		t.Fatal("expected an error for a missing socket")
+	}
+	// Logging to the file still works.
+	l.Log(testEntry())
+}
@@ -21,6 +21,8 @@ type Engine struct {
 	dialect   string
 	keywords  []string
 	functions []string
+	routines  []schema.Routine // user-defined functions and procedures
+	objects   []Object         // every named object, for Search
 }
 
 // NewEngine creates a completion engine with keyword/function lists for the given dialect.
@@ -41,6 +43,7 @@ func (e *Engine) UpdateSchema(databases []schema.Database) {
 	e.tables = make(map[string][]schema.Column)
 	e.schemas = nil
 	e.databases = nil
+	e.routines = nil
 
 	for _, db := range databases {
 		e.databases = append(e.databases, db.Name)
@@ -58,8 +61,10 @@ func (e *Engine) UpdateSchema(databases []schema.Database) {
 				e.tables[key] = v.Columns
 				e.tables[v.Name] = v.Columns
 			}
+			e.routines = append(e.routines, s.Routines...)
 		}
 	}
+	e.objects = indexObjects(databases)
 }
 
 // Complete returns completion candidates for the given text and cursor position.
@@ -392,9 +397,22 @@ func (e *Engine) keywordCompletions() []adapter.CompletionItem {
 	return items
 }
 
-// functionCompletions returns completion items for all functions in the dialect.
+// functionCompletions returns completion items for the schema's own routines,
+// with their signatures, followed by the built-in functions of the dialect.
 func (e *Engine) functionCompletions() []adapter.CompletionItem {
-	items := make([]adapter.CompletionItem, 0, len(e.functions))
+	items := make([]adapter.CompletionItem, 0, len(e.routines)+len(e.functions))
+	seen := make(map[string]bool, len(e.routines))
+	for _, r := range e.routines {
+		if seen[r.Name] {
+			continue // overloads complete to the same name
+		}
+		seen[r.Name] = true
+		items = append(items, adapter.CompletionItem{
+			Label:  r.Name,
+			Kind:   adapter.CompletionFunction,
+			Detail: r.Signature(),
+		})
+	}
 	for _, fn := range e.functions {
 		items = append(items, adapter.CompletionItem{
 			Label:  fn,
@@ -742,6 +742,38 @@ func TestComplete_NoSchemaLoaded(t *testing.T) {
 	}
 }
 
+func TestSearch(t *testing.T) {
+	e := newTestEngine()
+
+	found := e.Search("ID")
+	if len(found) < 3 {
+		t.Fatalf("Search(ID) = %v, want the id columns of users, orders and active_users", found)
+	}
+	tables := map[string]bool{}
+	for _, o := range found[:3] {
+		if o.Kind != KindColumn || o.Name != "id" || o.Database != "testdb" || o.Schema != "public" {
+			t.Errorf("exact matches should come first, got %+v", o)
+		}
+		tables[o.Table] = true
+	}
+	if !tables["users"] || !tables["orders"] || !tables["active_users"] {
+		t.Errorf("id found in %v", tables)
+	}
+
+	found = e.Search("actusr")
+	if len(found) == 0 || found[0].Kind != KindView || found[0].Name != "active_users" {
+		t.Errorf("Search(actusr) = %v, want the view active_users", found)
+	}
+	if found := e.Search("  "); found != nil {
+		t.Errorf("an empty search found %v", found)
+	}
+
+	e.UpdateSchema(nil)
+	if found := e.Search("id"); len(found) != 0 {
+		t.Errorf("Search after the schema was cleared = %v", found)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // 8. parseFromTables
 // ---------------------------------------------------------------------------
@@ -1342,6 +1374,35 @@ func TestComplete_FunctionSUM(t *testing.T) {
 	}
 }
 
+func TestComplete_SchemaRoutines(t *testing.T) {
+	e := NewEngine("postgres")
+	e.UpdateSchema([]schema.Database{{
+		Name: "testdb",
+		Schemas: []schema.Schema{{
+			Name: "public",
+			Routines: []schema.Routine{
+				{Name: "calc_total", Kind: "function", Args: "order_id integer", Returns: "numeric"},
+				{Name: "calc_total", Kind: "function", Args: "order_id bigint", Returns: "numeric"},
+			},
+		}},
+	}})
+	text := "SELECT calc_"
+	items := e.Complete(text, len(text))
+
+	var found []adapter.CompletionItem
+	for _, it := range items {
+		if it.Label == "calc_total" {
+			found = append(found, it)
+		}
+	}
+	if len(found) != 1 {
+		t.Fatalf("expected calc_total once, got %v", collectLabels(items))
+	}
+	if found[0].Kind != adapter.CompletionFunction || found[0].Detail != "calc_total(order_id integer) → numeric" {
+		t.Errorf("unexpected item %+v", found[0])
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Complete - large result capping
 // ---------------------------------------------------------------------------
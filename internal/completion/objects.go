@@ -0,0 +1,119 @@
+package completion
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sahilm/fuzzy"
+)
+
+// Object kinds in the index searched by Search.
+const (
+	KindTable     = "table"
+	KindView      = "view"
+	KindMatView   = "matview"
+	KindColumn    = "column"
+	KindFunction  = "function"
+	KindProcedure = "procedure"
+	KindSequence  = "sequence"
+	KindTrigger   = "trigger"
+)
+
+// maxSearchResults caps the objects Search returns.
+const maxSearchResults = 200
+
+// Object is a named object of the schema, found by Search.
+type Object struct {
+	Kind     string
+	Name     string
+	Database string
+	Schema   string
+	Table    string // the table or view of a column or trigger
+	Detail   string // a column's type, a routine's or trigger's signature
+}
+
+// objectNames implements fuzzy.Source over the lowercased object names.
+type objectNames []Object
+
+func (o objectNames) String(i int) string { return strings.ToLower(o[i].Name) }
+func (o objectNames) Len() int            { return len(o) }
+
+// indexObjects lists every named object of databases, in schema order.
+func indexObjects(databases []schema.Database) []Object {
+	var objects []Object
+	for _, db := range databases {
+		for _, s := range db.Schemas {
+			add := func(o Object) {
+				o.Database, o.Schema = db.Name, s.Name
+				objects = append(objects, o)
+			}
+			columns := func(table string, cols []schema.Column) {
+				for _, c := range cols {
+					add(Object{Kind: KindColumn, Name: c.Name, Table: table, Detail: c.Type})
+				}
+			}
+			for _, t := range s.Tables {
+				add(Object{Kind: KindTable, Name: t.Name})
+				columns(t.Name, t.Columns)
+			}
+			for _, v := range s.Views {
+				kind := KindView
+				if v.Materialized {
+					kind = KindMatView
+				}
+				add(Object{Kind: kind, Name: v.Name})
+				columns(v.Name, v.Columns)
+			}
+			for _, r := range s.Routines {
+				kind := KindFunction
+				if r.Kind == "procedure" {
+					kind = KindProcedure
+				}
+				add(Object{Kind: kind, Name: r.Name, Detail: r.Signature()})
+			}
+			for _, seq := range s.Sequences {
+				add(Object{Kind: KindSequence, Name: seq.Name})
+			}
+			for _, t := range s.Triggers {
+				add(Object{Kind: KindTrigger, Name: t.Name, Table: t.Table, Detail: t.Signature()})
+			}
+		}
+	}
+	return objects
+}
+
+// Search returns the objects whose names match pattern, ignoring case:
+// those named exactly pattern first, then the fuzzy matches, best first.
+// Columns are only known for the tables loaded.
+func (e *Engine) Search(pattern string) []Object {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "" {
+		return nil
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	// Equal scores keep the order of the index, schema by schema.
+	matches := fuzzy.FindFrom(pattern, objectNames(e.objects))
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Index < matches[j].Index
+	})
+	var exact, rest []Object
+	for _, match := range matches {
+		o := e.objects[match.Index]
+		if strings.EqualFold(o.Name, pattern) {
+			exact = append(exact, o)
+		} else {
+			rest = append(rest, o)
+		}
+	}
+	found := append(exact, rest...)
+	if len(found) > maxSearchResults {
+		found = found[:maxSearchResults]
+	}
+	return found
+}
@@ -5,19 +5,185 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Config holds all application configuration.
 type Config struct {
-	Theme       string            `yaml:"theme"`
-	KeyMode     string            `yaml:"keymode"` // "vim" or "standard"
-	Editor      EditorConfig      `yaml:"editor"`
-	Results     ResultsConfig     `yaml:"results"`
-	Audit       AuditConfig       `yaml:"audit"`
-	Connections []SavedConnection `yaml:"connections"`
+	Theme           string            `yaml:"theme"`
+	Colors          string            `yaml:"colors,omitempty"` // "auto", "truecolor", "256", "16" or "none"
+	KeyMode         string            `yaml:"keymode"`          // "vim" or "standard"
+	Layout          string            `yaml:"layout"`           // "stacked" or "side-by-side" (results beside the editor)
+	Leader          string            `yaml:"leader"`           // the key that starts Mappings in vim mode, e.g. "<space>"
+	Mappings        []Mapping         `yaml:"mappings,omitempty"`
+	Editor          EditorConfig      `yaml:"editor"`
+	Results         ResultsConfig     `yaml:"results"`
+	Sidebar         SidebarConfig     `yaml:"sidebar"`
+	History         HistoryConfig     `yaml:"history"`
+	Library         LibraryConfig     `yaml:"library"`
+	Audit           AuditConfig       `yaml:"audit"`
+	Telemetry       TelemetryConfig   `yaml:"telemetry"`
+	Lint            LintConfig        `yaml:"lint"`
+	Redact          RedactConfig      `yaml:"redact"`
+	Notify          NotifyConfig      `yaml:"notify"`
+	Keychain        bool              `yaml:"keychain"`          // keep saved passwords in the OS keychain
+	AutoConnect     bool              `yaml:"auto_connect"`      // reconnect to the last used connection on startup
+	RestoreSession  bool              `yaml:"restore_session"`   // save open tabs on quit and offer them on startup
+	SafeMode        bool              `yaml:"safe_mode"`         // start with only read-only statements allowed (F3 toggles)
+	DropConfirmRows int64             `yaml:"drop_confirm_rows"` // DROP of anything holding more rows asks for its name (-1 = never)
+	QueryTimeout    time.Duration     `yaml:"query_timeout"`     // cancel queries running longer (0 = never)
+	Connections     []SavedConnection `yaml:"connections"`
+
+	// StatusBar replaces the default status bar layout (see
+	// StatusBarLayout) when set.
+	StatusBar *StatusBarConfig `yaml:"statusbar,omitempty"`
+
+	// Recent lists the names of the saved connections used last, most
+	// recent first.
+	Recent []string `yaml:"recent,omitempty"`
+}
+
+// The values of Layout.
+const (
+	LayoutStacked    = "stacked"
+	LayoutSideBySide = "side-by-side"
+)
+
+// maxRecent is how many connections Recent remembers.
+const maxRecent = 10
+
+// TouchRecent moves the saved connection name to the front of Recent.
+func (c *Config) TouchRecent(name string) {
+	recent := []string{name}
+	for _, r := range c.Recent {
+		if r != name && len(recent) < maxRecent {
+			recent = append(recent, r)
+		}
+	}
+	c.Recent = recent
+}
+
+// LastConnection returns the most recently used saved connection that still
+// exists, or nil.
+func (c *Config) LastConnection() *SavedConnection {
+	for _, name := range c.Recent {
+		if sc := c.FindConnection(name); sc != nil {
+			return sc
+		}
+	}
+	return nil
+}
+
+// FindConnection returns the saved connection called name, or nil.
+func (c *Config) FindConnection(name string) *SavedConnection {
+	for i := range c.Connections {
+		if c.Connections[i].Name == name {
+			return &c.Connections[i]
+		}
+	}
+	return nil
+}
+
+// Mapping binds keys typed after the leader key in vim mode to an action.
+type Mapping struct {
+	Keys   string `yaml:"keys"`   // e.g. "<leader>pc"; the <leader> may be left out
+	Action string `yaml:"action"` // run_query, insert_snippet, switch_connection or export
+
+	// Arg is what the action works on: the saved query's name (or
+	// folder/name), the snippet's text, the saved connection's name, or
+	// the export format, csv or json.
+	Arg  string `yaml:"arg,omitempty"`
+	Desc string `yaml:"desc,omitempty"` // shown in the key hints
+}
+
+// The actions a Mapping can run.
+const (
+	ActionRunQuery         = "run_query"
+	ActionInsertSnippet    = "insert_snippet"
+	ActionSwitchConnection = "switch_connection"
+	ActionExport           = "export"
+)
+
+// Sequence returns the keys of the mapping after the leader, as bubbletea
+// names them.
+func (m Mapping) Sequence() []string {
+	return ParseKeys(strings.TrimPrefix(m.Keys, "<leader>"))
+}
+
+// ParseKeys reads keys written the way vim writes them in mappings: one
+// character per key, with <space>, <cr>, <tab>, <esc>, <bs> and <c-x> for
+// the keys that have no character. It returns the keys as bubbletea names
+// them, e.g. " " and "ctrl+x".
+func ParseKeys(s string) []string {
+	var keys []string
+	for s != "" {
+		if s[0] == '<' {
+			if end := strings.IndexByte(s, '>'); end > 1 {
+				name := strings.ToLower(s[1:end])
+				special := map[string]string{
+					"space": " ", "cr": "enter", "enter": "enter", "tab": "tab",
+					"esc": "esc", "bs": "backspace", "lt": "<", "bslash": `\`,
+				}
+				switch {
+				case special[name] != "":
+					keys = append(keys, special[name])
+					s = s[end+1:]
+					continue
+				case strings.HasPrefix(name, "c-") && len(name) > 2:
+					keys = append(keys, "ctrl+"+name[2:])
+					s = s[end+1:]
+					continue
+				}
+			}
+		}
+		r := []rune(s)[0]
+		keys = append(keys, string(r))
+		s = s[len(string(r)):]
+	}
+	return keys
+}
+
+// LeaderKey returns the leader key as bubbletea names it; vim's \ if none
+// is set.
+func (c *Config) LeaderKey() string {
+	if keys := ParseKeys(c.Leader); len(keys) == 1 {
+		return keys[0]
+	}
+	return `\`
+}
+
+// HistoryConfig limits the query history.
+type HistoryConfig struct {
+	MaxEntries int           `yaml:"max_entries"` // newest entries kept (0 = no limit)
+	MaxAge     time.Duration `yaml:"max_age"`     // drop entries older than this, e.g. "720h" (0 = keep)
+	Dedupe     bool          `yaml:"dedupe"`      // rerunning the last query updates its entry
+}
+
+// NotifyConfig controls telling the user that a long query is done while
+// they look at another tab or window.
+type NotifyConfig struct {
+	After   time.Duration `yaml:"after"`   // queries running longer notify (0 = never)
+	Bell    bool          `yaml:"bell"`    // ring the terminal bell
+	Desktop bool          `yaml:"desktop"` // send a desktop notification (OSC 9)
+}
+
+// LibraryConfig configures the saved query library.
+type LibraryConfig struct {
+	// Shared lists team libraries shown read-only next to the personal one.
+	Shared []SharedLibrary `yaml:"shared,omitempty"`
+}
+
+// SharedLibrary is a query library kept in a directory or file others
+// write to, such as a shared drive or a git checkout.
+type SharedLibrary struct {
+	Name string `yaml:"name"`
+	Path string `yaml:"path"`          // a library file, or a directory of them
+	Git  bool   `yaml:"git,omitempty"` // Path is in a git checkout; syncing pulls it
 }
 
 // AuditConfig controls the JSON Lines audit log.
@@ -25,6 +191,110 @@ type AuditConfig struct {
 	Enabled   bool   `yaml:"enabled"`
 	Path      string `yaml:"path"`        // empty = ConfigDir()/audit.jsonl
 	MaxSizeMB int    `yaml:"max_size_mb"` // 0 = no rotation
+	HashChain bool   `yaml:"hash_chain"`  // chain entries by hash; check with "gotermsql audit verify"
+	// Syslog also forwards entries to syslog: "local", or
+	// udp://host:port, tcp://host:port or unix:///path. Empty = off.
+	Syslog string `yaml:"syslog,omitempty"`
+}
+
+// TelemetryConfig controls exporting a trace span for every query to an
+// OpenTelemetry collector.
+type TelemetryConfig struct {
+	// Endpoint is the collector's OTLP/HTTP URL, e.g.
+	// http://localhost:4318. Empty = OTEL_EXPORTER_OTLP_ENDPOINT, or off.
+	Endpoint    string            `yaml:"endpoint,omitempty"`
+	Headers     map[string]string `yaml:"headers,omitempty"`      // sent with every export, e.g. for auth
+	ServiceName string            `yaml:"service_name,omitempty"` // empty = "gotermsql"
+	Propagate   bool              `yaml:"propagate,omitempty"`    // add a traceparent comment to queries
+}
+
+// LintConfig controls the warnings shown for risky queries run from the
+// editor.
+type LintConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Rules turns single rules on or off by name: missing_where,
+	// cross_join, select_star, non_sargable. Rules not listed are on.
+	Rules map[string]bool `yaml:"rules,omitempty"`
+}
+
+// RuleEnabled reports whether the lint rule named rule is on.
+func (c LintConfig) RuleEnabled(rule string) bool {
+	on, ok := c.Rules[rule]
+	return c.Enabled && (on || !ok)
+}
+
+// RedactConfig lists the secrets masked in queries before they are
+// written to the history and the audit log.
+type RedactConfig struct {
+	// Columns are patterns for column names whose values are secret,
+	// matched case-insensitively anywhere in the name, e.g. password.
+	Columns []string `yaml:"columns,omitempty"`
+	// Values are patterns for literals that are secret wherever they
+	// appear, e.g. ^sk_live_.
+	Values []string `yaml:"values,omitempty"`
+}
+
+// StatusBarConfig lays out the status bar like a modeline: the segments
+// drawn at its left, in its middle and at its right, in order. A segment
+// left out of all three is hidden.
+type StatusBarConfig struct {
+	Left   []string `yaml:"left"`
+	Center []string `yaml:"center"`
+	Right  []string `yaml:"right"`
+}
+
+// The segments a StatusBarConfig can show.
+const (
+	SegmentConnection  = "connection"  // adapter://database, environment, read-only and tunnel
+	SegmentDatabase    = "database"    // the database name alone
+	SegmentSafeMode    = "safe_mode"   // SAFE while safe mode is on
+	SegmentTransaction = "transaction" // TX while a transaction is open
+	SegmentMessage     = "message"     // messages, else the last query's time and rows, else key hints
+	SegmentDuration    = "duration"    // the last query's time
+	SegmentRows        = "rows"        // the last query's row count
+	SegmentClock       = "clock"       // the time of day
+	SegmentKeyMode     = "key_mode"    // the key mode, vim state and macro recording
+	SegmentCursor      = "cursor"      // the editor's line:column
+)
+
+// Segments lists the status bar segments.
+var Segments = []string{
+	SegmentConnection, SegmentDatabase, SegmentSafeMode, SegmentTransaction,
+	SegmentMessage, SegmentDuration, SegmentRows, SegmentClock,
+	SegmentKeyMode, SegmentCursor,
+}
+
+// DefaultStatusBar returns the layout used when the config sets none.
+func DefaultStatusBar() StatusBarConfig {
+	return StatusBarConfig{
+		Left:   []string{SegmentConnection, SegmentSafeMode, SegmentTransaction},
+		Center: []string{SegmentMessage},
+		Right:  []string{SegmentKeyMode, SegmentCursor},
+	}
+}
+
+// StatusBarLayout returns the status bar layout of the config.
+func (c *Config) StatusBarLayout() StatusBarConfig {
+	if c.StatusBar == nil {
+		return DefaultStatusBar()
+	}
+	return *c.StatusBar
+}
+
+// Validate reports the segments that are not known.
+func (c StatusBarConfig) Validate() error {
+	var unknown []string
+	for _, seg := range slices.Concat(c.Left, c.Center, c.Right) {
+		if !slices.Contains(Segments, seg) {
+			unknown = append(unknown, strconv.Quote(seg))
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("statusbar: unknown segment %s (one of %s)",
+			strings.Join(unknown, ", "), strings.Join(Segments, ", "))
+	}
+	return nil
 }
 
 // EditorConfig holds editor-related settings.
@@ -35,8 +305,34 @@ type EditorConfig struct {
 
 // ResultsConfig holds result display settings.
 type ResultsConfig struct {
-	PageSize       int `yaml:"page_size"`
-	MaxColumnWidth int `yaml:"max_column_width"`
+	PageSize          int    `yaml:"page_size"`         // rows fetched per page when streaming
+	MaxBufferedRows   int    `yaml:"max_buffered_rows"` // rows kept in memory while streaming
+	MaxColumnWidth    int    `yaml:"max_column_width"`
+	RowNumbers        bool   `yaml:"row_numbers"`
+	StickyFirstColumn bool   `yaml:"sticky_first_column"`
+	NullDisplay       string `yaml:"null_display"`     // marker for SQL NULL, e.g. "NULL" or "∅"
+	BackgroundCount   bool   `yaml:"background_count"` // count streaming results with SELECT COUNT(*)
+	ResultHistory     int    `yaml:"result_history"`   // earlier results kept per tab (0 = off)
+	AutoLimit         int    `yaml:"auto_limit"`       // add LIMIT n to SELECTs without one (0 = off)
+}
+
+// SidebarConfig holds schema browser settings.
+type SidebarConfig struct {
+	TableStats bool `yaml:"table_stats"` // show row counts and sizes next to tables
+
+	// LazyThreshold is the table count above which only table names are
+	// loaded up front; a table's columns load when it is expanded. Zero
+	// always loads everything.
+	LazyThreshold int `yaml:"lazy_threshold"`
+
+	// SchemaCacheTTL is how long the schema loaded for a connection is kept
+	// on disk, to be shown at once on the next connect while it loads
+	// again. Zero turns the cache off.
+	SchemaCacheTTL time.Duration `yaml:"schema_cache_ttl"`
+
+	// Favorites lists the starred tables ("schema.table") per connection,
+	// keyed by the connection's DSN with the password masked.
+	Favorites map[string][]string `yaml:"favorites,omitempty"`
 }
 
 // SavedConnection holds parameters for a saved database connection.
@@ -48,8 +344,73 @@ type SavedConnection struct {
 	Port     int    `yaml:"port,omitempty"`
 	User     string `yaml:"user,omitempty"`
 	Password string `yaml:"password,omitempty"`
+	SecretID string `yaml:"secret_id,omitempty"` // password kept in the OS keychain
 	Database string `yaml:"database,omitempty"`
 	File     string `yaml:"file,omitempty"`
+
+	// Group folders the connection in the connection manager, e.g. "work".
+	Group string `yaml:"group,omitempty"`
+	// Env tags the environment, e.g. "prod" or "staging". The tab bar and
+	// status bar take its color while connected.
+	Env string `yaml:"env,omitempty"`
+	// Color overrides the color of Env, as "#RRGGBB" or an ANSI number.
+	Color string `yaml:"color,omitempty"`
+
+	// SSH, when set, reaches Host:Port through an SSH tunnel.
+	SSH *SSHTunnel `yaml:"ssh,omitempty"`
+
+	// Defaults, when set, are applied on every connect.
+	Defaults *ExecDefaults `yaml:"defaults,omitempty"`
+}
+
+// ExecDefaults are the execution settings of a saved connection. The session
+// settings are applied by the database to every session of the connection,
+// so they also hold for queries the server runs on the connection pool's
+// other sessions.
+type ExecDefaults struct {
+	// StatementTimeout cancels statements running longer, e.g. "30s".
+	StatementTimeout time.Duration `yaml:"statement_timeout,omitempty"`
+	// QueryTimeout overrides query_timeout for this connection. Unlike
+	// StatementTimeout it is kept by the client, not the database.
+	QueryTimeout time.Duration `yaml:"query_timeout,omitempty"`
+	// PageSize overrides results.page_size for this connection.
+	PageSize int `yaml:"page_size,omitempty"`
+	// ReadOnly makes the database reject writes.
+	ReadOnly bool `yaml:"read_only,omitempty"`
+	// Schema is the default schema: the search_path on PostgreSQL, which
+	// may list several, the current database on MySQL and the current
+	// schema on DuckDB.
+	Schema string `yaml:"schema,omitempty"`
+	// StartupSQL is run on every new session after the settings above,
+	// e.g. "SET application_name = 'gotermsql'".
+	StartupSQL []string `yaml:"startup_sql,omitempty"`
+	// AutoCommit, when false, runs statements in a transaction that stays
+	// open until it is committed or rolled back. Unset means on.
+	AutoCommit *bool `yaml:"autocommit,omitempty"`
+}
+
+// AutoCommitOn returns whether statements commit as they run. It is true
+// for a nil d.
+func (d *ExecDefaults) AutoCommitOn() bool {
+	return d == nil || d.AutoCommit == nil || *d.AutoCommit
+}
+
+// SSHTunnel holds the SSH server a connection is tunnelled through. The
+// system ssh client opens the tunnel, so ~/.ssh/config and agents apply.
+type SSHTunnel struct {
+	Host         string `yaml:"host"`
+	Port         int    `yaml:"port,omitempty"` // default 22
+	User         string `yaml:"user,omitempty"`
+	IdentityFile string `yaml:"identity_file,omitempty"`
+	JumpHost     string `yaml:"jump_host,omitempty"` // passed to ssh -J, e.g. "user@bastion"
+}
+
+// Target returns the SSH destination as "user@host", or "host".
+func (t SSHTunnel) Target() string {
+	if t.User != "" {
+		return t.User + "@" + t.Host
+	}
+	return t.Host
 }
 
 // DefaultConfig returns a Config populated with sensible defaults.
@@ -57,14 +418,38 @@ func DefaultConfig() *Config {
 	return &Config{
 		Theme:   "default",
 		KeyMode: "standard",
+		Layout:  LayoutStacked,
 		Editor: EditorConfig{
 			TabSize:         4,
 			ShowLineNumbers: true,
 		},
 		Results: ResultsConfig{
-			PageSize:       1000,
-			MaxColumnWidth: 50,
+			PageSize:        1000,
+			MaxBufferedRows: 5000,
+			MaxColumnWidth:  50,
+			NullDisplay:     "NULL",
+			BackgroundCount: true,
+			ResultHistory:   10,
+		},
+		Sidebar: SidebarConfig{
+			LazyThreshold:  500,
+			SchemaCacheTTL: 7 * 24 * time.Hour,
+		},
+		History: HistoryConfig{
+			MaxEntries: 10000,
+			Dedupe:     true,
 		},
+		Lint: LintConfig{
+			Enabled: true,
+		},
+		Notify: NotifyConfig{
+			After: 10 * time.Second,
+			Bell:  true,
+		},
+		Keychain:        true,
+		RestoreSession:  true,
+		DropConfirmRows: 1000,
+		QueryTimeout:    5 * time.Minute,
 	}
 }
 
@@ -79,6 +464,17 @@ func ConfigDir() (string, error) {
 	return filepath.Join(base, "gotermsql"), nil
 }
 
+// ExpandHome replaces a leading ~ in path with the home directory, as a
+// shell would.
+func ExpandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
 // Load reads a Config from the YAML file at path. If the file does not exist,
 // it returns DefaultConfig without error.
 func Load(path string) (*Config, error) {
@@ -159,14 +555,48 @@ func (c *Config) SaveDefault() error {
 	return c.Save(filepath.Join(dir, "config.yaml"))
 }
 
+// dsnWithPassword adds password to a postgres or mysql DSN that names a
+// user but has no password, and returns any other DSN unchanged.
+func dsnWithPassword(adapterName, dsn, password string) string {
+	if password == "" {
+		return dsn
+	}
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" && u.Opaque == "" {
+		if u.User == nil || u.User.Username() == "" {
+			return dsn
+		}
+		if _, ok := u.User.Password(); ok {
+			return dsn
+		}
+		u.User = url.UserPassword(u.User.Username(), password)
+		return u.String()
+	}
+	switch adapterName {
+	case "postgres":
+		// keyword=value form
+		if strings.Contains(dsn, "=") && !strings.Contains(dsn, "password=") {
+			escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(password)
+			return dsn + " password='" + escaped + "'"
+		}
+	case "mysql":
+		// go-sql-driver form: user[:password]@protocol(address)/dbname
+		if at := strings.Index(dsn, "@"); at > 0 && !strings.Contains(dsn[:at], ":") {
+			return dsn[:at] + ":" + url.QueryEscape(password) + dsn[at:]
+		}
+	}
+	return dsn
+}
+
 // BuildDSN constructs a connection string from the individual fields of a
-// SavedConnection. If DSN is already set, it is returned as-is. For
-// file-based adapters (sqlite, duckdb) it returns the File field. For
-// postgres it builds a proper postgres:// URL with escaped credentials. For
-// mysql it builds the go-sql-driver format with escaped password.
+// SavedConnection. If DSN is already set, it is returned as-is, except
+// that a Password is added to a DSN that has a user but no password, so a
+// raw DSN can keep its password in the keychain. For file-based adapters
+// (sqlite, duckdb) it returns the File field. For postgres it builds a
+// proper postgres:// URL with escaped credentials. For mysql it builds the
+// go-sql-driver format with escaped password.
 func (sc *SavedConnection) BuildDSN() string {
 	if sc.DSN != "" {
-		return sc.DSN
+		return dsnWithPassword(strings.ToLower(sc.Adapter), sc.DSN, sc.Password)
 	}
 
 	adapterName := strings.ToLower(sc.Adapter)
@@ -266,9 +696,12 @@ func (sc *SavedConnection) DisplayString() string {
 		location = host
 	}
 
-	db := sc.Database
-	if db != "" {
-		return fmt.Sprintf("%s://%s/%s", sc.Adapter, location, db)
+	s := fmt.Sprintf("%s://%s", sc.Adapter, location)
+	if sc.Database != "" {
+		s += "/" + sc.Database
+	}
+	if sc.SSH != nil {
+		s += " via ssh " + sc.SSH.Target()
 	}
-	return fmt.Sprintf("%s://%s", sc.Adapter, location)
+	return s
 }
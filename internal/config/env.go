@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// envRef matches a ${NAME} environment variable reference.
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// HasEnvRef reports whether s refers to an environment variable.
+func HasEnvRef(s string) bool {
+	return envRef.MatchString(s)
+}
+
+// Expand returns a copy of sc with ${NAME} references in its text fields,
+// including the DSN and SSH settings, replaced by the environment variable
+// NAME. It is called at connect time so a shared connections file need not
+// hold credentials. Only the braced form is expanded, so a literal "$" in a
+// password is left alone. A variable that is not set is an error rather
+// than an empty value.
+func (sc *SavedConnection) Expand() (SavedConnection, error) {
+	out := *sc
+	var missing []string
+	expand := func(s string) string {
+		return envRef.ReplaceAllStringFunc(s, func(ref string) string {
+			name := envRef.FindStringSubmatch(ref)[1]
+			v, ok := os.LookupEnv(name)
+			if !ok {
+				missing = append(missing, name)
+			}
+			return v
+		})
+	}
+	for _, f := range []*string{&out.DSN, &out.Host, &out.User, &out.Password, &out.Database, &out.File} {
+		*f = expand(*f)
+	}
+	if sc.SSH != nil {
+		ssh := *sc.SSH
+		for _, f := range []*string{&ssh.Host, &ssh.User, &ssh.IdentityFile, &ssh.JumpHost} {
+			*f = expand(*f)
+		}
+		out.SSH = &ssh
+	}
+	if len(missing) > 0 {
+		return *sc, fmt.Errorf("connection %s: environment variable %s is not set", sc.Name, missing[0])
+	}
+	return out, nil
+}
@@ -0,0 +1,425 @@
+// Package ddl writes SQL that creates, alters and drops schema objects, for
+// the schema comparison's migration scripts.
+package ddl
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+// Migration returns the statements that turn the old side of diffs (as
+// listed by schema.Compare) into the new one, for a database of dialect.
+// What the dialect cannot do in place, and routines and triggers, whose
+// bodies the schema model does not hold, are left as comments to finish
+// by hand.
+//
+// Dependents go first: triggers and views are dropped before tables, and
+// foreign keys before the tables they point at; new tables are created
+// after the tables they reference, and views last.
+func Migration(dialect string, diffs []schema.ObjectDiff) string {
+	g := generator{dialect: dialect}
+	var creates, alters, drops []schema.ObjectDiff
+	for _, d := range diffs {
+		switch d.Change {
+		case schema.Added:
+			creates = append(creates, d)
+		case schema.Altered:
+			alters = append(alters, d)
+		case schema.Dropped:
+			drops = append(drops, d)
+		}
+	}
+
+	for _, d := range drops {
+		switch d.Kind {
+		case schema.KindTrigger:
+			g.dropTrigger(d)
+		case schema.KindView:
+			g.dropView(d)
+		case schema.KindRoutine:
+			g.dropRoutine(d)
+		}
+	}
+	for _, d := range alters {
+		switch d.Kind {
+		case schema.KindTrigger, schema.KindRoutine:
+			g.comment("%s %s changed: its definition is not known, replace it by hand", d.Kind, d.Label())
+		case schema.KindView:
+			g.replaceView(d)
+		case schema.KindTable:
+			g.dropForeignKeys(d)
+		}
+	}
+	for _, d := range creates {
+		switch d.Kind {
+		case schema.KindSchema:
+			g.createSchema(d)
+		case schema.KindSequence:
+			g.statement("CREATE SEQUENCE %s", g.qualify(d.Schema, d.Name))
+		}
+	}
+	for _, d := range tableOrder(creates) {
+		g.createTable(d.Schema, *d.Table)
+	}
+	for _, d := range alters {
+		if d.Kind == schema.KindTable {
+			g.alterTable(d)
+		}
+	}
+	for _, d := range drops {
+		switch d.Kind {
+		case schema.KindTable:
+			g.statement("DROP TABLE %s", g.qualify(d.Schema, d.Name))
+		case schema.KindSequence:
+			g.statement("DROP SEQUENCE %s", g.qualify(d.Schema, d.Name))
+		}
+	}
+	for _, d := range creates {
+		switch d.Kind {
+		case schema.KindView:
+			g.createView(d)
+		case schema.KindRoutine, schema.KindTrigger:
+			g.comment("%s %s added: its definition is not known, create it by hand", d.Kind, d.Label())
+		}
+	}
+	for _, d := range drops {
+		if d.Kind == schema.KindSchema {
+			g.dropSchema(d)
+		}
+	}
+	return g.String()
+}
+
+// generator collects the statements of a script.
+type generator struct {
+	dialect  string
+	identity map[string]bool // columns numbered by the database, in CreateTable
+	parts    []string
+}
+
+func (g *generator) String() string {
+	if len(g.parts) == 0 {
+		return ""
+	}
+	return strings.Join(g.parts, "\n\n") + "\n"
+}
+
+func (g *generator) statement(format string, args ...any) {
+	g.parts = append(g.parts, fmt.Sprintf(format, args...)+";")
+}
+
+func (g *generator) comment(format string, args ...any) {
+	g.parts = append(g.parts, "-- "+fmt.Sprintf(format, args...))
+}
+
+func (g *generator) quote(name string) string {
+	return adapter.QuoteIdentifier(g.dialect, name)
+}
+
+// qualify quotes name within schemaName. SQLite's main database is left
+// out, as unqualified names mean it.
+func (g *generator) qualify(schemaName, name string) string {
+	if schemaName == "" || (g.dialect == "sqlite" && schemaName == "main") {
+		return g.quote(name)
+	}
+	return g.quote(schemaName) + "." + g.quote(name)
+}
+
+func (g *generator) quoteAll(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = g.quote(n)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func (g *generator) createSchema(d schema.ObjectDiff) {
+	switch g.dialect {
+	case "sqlite":
+		g.comment("database %s added: attach it with ATTACH DATABASE", d.Name)
+	case "mysql":
+		g.statement("CREATE DATABASE %s", g.quote(d.Name))
+	default:
+		g.statement("CREATE SCHEMA %s", g.quote(d.Name))
+	}
+}
+
+func (g *generator) dropSchema(d schema.ObjectDiff) {
+	switch g.dialect {
+	case "sqlite":
+		g.comment("database %s dropped: detach it with DETACH DATABASE", d.Name)
+	case "mysql":
+		g.statement("DROP DATABASE %s", g.quote(d.Name))
+	default:
+		g.statement("DROP SCHEMA %s", g.quote(d.Name))
+	}
+}
+
+// columnDef renders a column as it is declared in CREATE TABLE.
+func (g *generator) columnDef(c schema.Column) string {
+	def := g.quote(c.Name) + " " + c.Type
+	if !c.Nullable {
+		def += " NOT NULL"
+	}
+	if c.Default != "" {
+		def += " DEFAULT " + c.Default
+	}
+	if g.identity[c.Name] {
+		def += g.identityClause()
+	}
+	return def
+}
+
+// foreignKeyDef renders a foreign key constraint. Its target is in the
+// same schema, as adapters report it.
+func (g *generator) foreignKeyDef(schemaName string, fk schema.ForeignKey) string {
+	def := ""
+	if fk.Name != "" && g.dialect != "sqlite" {
+		def = "CONSTRAINT " + g.quote(fk.Name) + " "
+	}
+	return def + fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s)",
+		g.quoteAll(fk.Columns), g.qualify(schemaName, fk.RefTable), g.quoteAll(fk.RefColumns))
+}
+
+// createTable writes CREATE TABLE for t, with its primary key and foreign
+// keys, and CREATE INDEX for its other indexes.
+func (g *generator) createTable(schemaName string, t schema.Table) {
+	var lines []string
+	for _, c := range t.Columns {
+		lines = append(lines, g.columnDef(c))
+	}
+	pk := t.PrimaryKey()
+	if len(pk) > 0 {
+		lines = append(lines, "PRIMARY KEY ("+g.quoteAll(pk)+")")
+	}
+	for _, fk := range t.FKs {
+		lines = append(lines, g.foreignKeyDef(schemaName, fk))
+	}
+	g.statement("CREATE TABLE %s (\n    %s\n)", g.qualify(schemaName, t.Name), strings.Join(lines, ",\n    "))
+	for _, idx := range t.Indexes {
+		if !primaryKeyIndex(idx, pk) {
+			g.createIndex(schemaName, t.Name, idx)
+		}
+	}
+}
+
+// primaryKeyIndex reports whether idx is the index behind the primary key,
+// which CREATE TABLE makes.
+func primaryKeyIndex(idx schema.Index, pk []string) bool {
+	return idx.Unique && len(pk) > 0 && slices.Equal(idx.Columns, pk)
+}
+
+func (g *generator) createIndex(schemaName, table string, idx schema.Index) {
+	name := idx.Name
+	if strings.HasPrefix(name, "sqlite_autoindex_") {
+		// Reserved for the indexes of UNIQUE constraints.
+		name = table + "_" + strings.Join(idx.Columns, "_") + "_key"
+	}
+	unique := ""
+	if idx.Unique {
+		unique = "UNIQUE "
+	}
+	target := g.qualify(schemaName, name)
+	if g.dialect == "postgres" || g.dialect == "duckdb" {
+		target = g.quote(name) // created in the table's schema
+	}
+	g.statement("CREATE %sINDEX %s ON %s (%s)", unique, target, g.qualify(schemaName, table), g.quoteAll(idx.Columns))
+}
+
+func (g *generator) dropIndex(schemaName, table string, idx schema.Index) {
+	if g.dialect == "mysql" {
+		g.statement("DROP INDEX %s ON %s", g.quote(idx.Name), g.qualify(schemaName, table))
+		return
+	}
+	g.statement("DROP INDEX %s", g.qualify(schemaName, idx.Name))
+}
+
+// dropForeignKeys drops the foreign keys an altered table no longer has,
+// before any table they point at is dropped.
+func (g *generator) dropForeignKeys(d schema.ObjectDiff) {
+	table := g.qualify(d.Schema, d.Name)
+	for _, fk := range d.Changes.DroppedFKs {
+		switch {
+		case g.dialect == "sqlite" || g.dialect == "duckdb":
+			g.comment("%s cannot drop foreign key %s of %s in place: rebuild the table", g.dialect, g.foreignKeyDef(d.Schema, fk), d.Label())
+		case fk.Name == "":
+			g.comment("foreign key %s of %s has no name: drop it by hand", g.foreignKeyDef(d.Schema, fk), d.Label())
+		case g.dialect == "mysql":
+			g.statement("ALTER TABLE %s DROP FOREIGN KEY %s", table, g.quote(fk.Name))
+		default:
+			g.statement("ALTER TABLE %s DROP CONSTRAINT %s", table, g.quote(fk.Name))
+		}
+	}
+}
+
+// alterTable writes the statements that change a table in place: columns
+// added, altered and dropped, the primary key, indexes and new foreign
+// keys.
+func (g *generator) alterTable(d schema.ObjectDiff) {
+	ch := d.Changes
+	table := g.qualify(d.Schema, d.Name)
+	for _, c := range ch.AddedColumns {
+		g.statement("ALTER TABLE %s ADD COLUMN %s", table, g.columnDef(c))
+	}
+	for _, c := range ch.AlteredColumns {
+		g.alterColumn(d, table, c)
+	}
+	for _, idx := range ch.DroppedIndexes {
+		if !primaryKeyIndex(idx, d.Table.PrimaryKey()) {
+			g.dropIndex(d.Schema, d.Name, idx)
+		}
+	}
+	for _, c := range ch.DroppedColumns {
+		g.statement("ALTER TABLE %s DROP COLUMN %s", table, g.quote(c.Name))
+	}
+	if ch.PKChanged {
+		g.comment("primary key of %s changed to (%s): change it by hand", d.Label(), strings.Join(ch.PrimaryKey, ", "))
+	}
+	for _, idx := range ch.AddedIndexes {
+		if !primaryKeyIndex(idx, ch.PrimaryKey) && !primaryKeyIndex(idx, d.Table.PrimaryKey()) {
+			g.createIndex(d.Schema, d.Name, idx)
+		}
+	}
+	for _, fk := range ch.AddedFKs {
+		if g.dialect == "sqlite" || g.dialect == "duckdb" {
+			g.comment("%s cannot add foreign key %s to %s in place: rebuild the table", g.dialect, g.foreignKeyDef(d.Schema, fk), d.Label())
+			continue
+		}
+		g.statement("ALTER TABLE %s ADD %s", table, g.foreignKeyDef(d.Schema, fk))
+	}
+}
+
+// alterColumn changes the type, nullability and default of a column.
+// MySQL redefines the whole column; SQLite cannot change one.
+func (g *generator) alterColumn(d schema.ObjectDiff, table string, c schema.ColumnChange) {
+	switch g.dialect {
+	case "sqlite":
+		g.comment("SQLite cannot alter column %s of %s (%s): rebuild the table", c.New.Name, d.Label(), g.columnDef(c.New))
+		return
+	case "mysql":
+		g.statement("ALTER TABLE %s MODIFY COLUMN %s", table, g.columnDef(c.New))
+		return
+	}
+	col := g.quote(c.New.Name)
+	if !strings.EqualFold(c.Old.Type, c.New.Type) {
+		g.statement("ALTER TABLE %s ALTER COLUMN %s TYPE %s", table, col, c.New.Type)
+	}
+	if c.Old.Nullable != c.New.Nullable {
+		if c.New.Nullable {
+			g.statement("ALTER TABLE %s ALTER COLUMN %s DROP NOT NULL", table, col)
+		} else {
+			g.statement("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL", table, col)
+		}
+	}
+	if c.Old.Default != c.New.Default {
+		if c.New.Default == "" {
+			g.statement("ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT", table, col)
+		} else {
+			g.statement("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT %s", table, col, c.New.Default)
+		}
+	}
+}
+
+// viewKind returns "VIEW" or "MATERIALIZED VIEW".
+func viewKind(v *schema.View) string {
+	if v.Materialized {
+		return "MATERIALIZED VIEW"
+	}
+	return "VIEW"
+}
+
+func (g *generator) createView(d schema.ObjectDiff) {
+	def := adapter.TrimStatement(d.View.Definition)
+	if def == "" {
+		g.comment("%s %s added: its definition is not known, create it by hand", strings.ToLower(viewKind(d.View)), d.Label())
+		return
+	}
+	g.statement("CREATE %s %s AS\n%s", viewKind(d.View), g.qualify(d.Schema, d.Name), def)
+}
+
+func (g *generator) dropView(d schema.ObjectDiff) {
+	g.statement("DROP %s %s", viewKind(d.View), g.qualify(d.Schema, d.Name))
+}
+
+// replaceView redefines an altered view: CREATE OR REPLACE where the
+// database has it, else dropped and created again.
+func (g *generator) replaceView(d schema.ObjectDiff) {
+	def := adapter.TrimStatement(d.View.Definition)
+	switch {
+	case def == "":
+		g.comment("view %s changed: its definition is not known, replace it by hand", d.Label())
+	case d.View.Materialized || g.dialect == "sqlite":
+		g.dropView(d)
+		g.createView(d)
+	default:
+		g.statement("CREATE OR REPLACE VIEW %s AS\n%s", g.qualify(d.Schema, d.Name), def)
+	}
+}
+
+func (g *generator) dropRoutine(d schema.ObjectDiff) {
+	r := d.Routine
+	kind := strings.ToUpper(r.Kind)
+	switch {
+	case g.dialect == "duckdb":
+		g.statement("DROP MACRO %s", g.qualify(d.Schema, r.Name))
+	case kind != "PROCEDURE" && kind != "FUNCTION":
+		g.comment("%s %s dropped: drop it by hand", r.Kind, d.Label())
+	case g.dialect == "postgres":
+		g.statement("DROP %s %s(%s)", kind, g.qualify(d.Schema, r.Name), r.Args)
+	default:
+		g.statement("DROP %s %s", kind, g.qualify(d.Schema, r.Name))
+	}
+}
+
+func (g *generator) dropTrigger(d schema.ObjectDiff) {
+	if g.dialect == "postgres" {
+		g.statement("DROP TRIGGER %s ON %s", g.quote(d.Name), g.qualify(d.Schema, d.Trigger.Table))
+		return
+	}
+	g.statement("DROP TRIGGER %s", g.qualify(d.Schema, d.Name))
+}
+
+// tableOrder returns the tables added in diffs, each after the added
+// tables its foreign keys point at. Tables in a cycle keep their order.
+func tableOrder(diffs []schema.ObjectDiff) []schema.ObjectDiff {
+	var pending []schema.ObjectDiff
+	for _, d := range diffs {
+		if d.Kind == schema.KindTable {
+			pending = append(pending, d)
+		}
+	}
+	key := func(d schema.ObjectDiff) string { return d.Schema + "." + d.Name }
+	waiting := make(map[string]bool, len(pending))
+	for _, d := range pending {
+		waiting[key(d)] = true
+	}
+
+	var ordered []schema.ObjectDiff
+	for len(pending) > 0 {
+		var rest []schema.ObjectDiff
+		for _, d := range pending {
+			blocked := false
+			for _, fk := range d.Table.FKs {
+				ref := d.Schema + "." + fk.RefTable
+				if ref != key(d) && waiting[ref] {
+					blocked = true
+				}
+			}
+			if blocked {
+				rest = append(rest, d)
+				continue
+			}
+			ordered = append(ordered, d)
+			delete(waiting, key(d))
+		}
+		if len(rest) == len(pending) {
+			return append(ordered, rest...) // a cycle
+		}
+		pending = rest
+	}
+	return ordered
+}
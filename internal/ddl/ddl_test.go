@@ -0,0 +1,107 @@
+package ddl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+func TestMigration(t *testing.T) {
+	old := []schema.Database{{Name: "app", Schemas: []schema.Schema{{
+		Name: "public",
+		Tables: []schema.Table{
+			{
+				Name: "users",
+				Columns: []schema.Column{
+					{Name: "id", Type: "integer", IsPK: true},
+					{Name: "name", Type: "text", Nullable: true},
+					{Name: "legacy", Type: "text", Nullable: true},
+				},
+				Indexes: []schema.Index{{Name: "users_pkey", Columns: []string{"id"}, Unique: true}},
+			},
+			{Name: "tmp", Columns: []schema.Column{{Name: "x", Type: "text"}}},
+		},
+		Views: []schema.View{{Name: "totals", Materialized: true, Definition: "SELECT 1"}},
+	}}}}
+	cur := []schema.Database{{Name: "app", Schemas: []schema.Schema{{
+		Name: "public",
+		Tables: []schema.Table{
+			{
+				Name: "users",
+				Columns: []schema.Column{
+					{Name: "id", Type: "integer", IsPK: true},
+					{Name: "name", Type: "varchar(80)", Default: "''"},
+					{Name: "email", Type: "text", Nullable: true},
+				},
+				Indexes: []schema.Index{
+					{Name: "users_pkey", Columns: []string{"id"}, Unique: true},
+					{Name: "users_email", Columns: []string{"email"}, Unique: true},
+				},
+			},
+			{
+				Name:    "orders",
+				Columns: []schema.Column{{Name: "id", Type: "integer", IsPK: true}, {Name: "user_id", Type: "integer"}},
+				Indexes: []schema.Index{{Name: "orders_pkey", Columns: []string{"id"}, Unique: true}},
+				FKs:     []schema.ForeignKey{{Name: "orders_user", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}}},
+			},
+		},
+		Views: []schema.View{{Name: "totals", Materialized: true, Definition: "SELECT 2;"}},
+	}}}}
+
+	got := Migration("postgres", schema.Compare(old, cur))
+	want := []string{
+		`DROP MATERIALIZED VIEW "public"."totals";`,
+		`CREATE MATERIALIZED VIEW "public"."totals" AS` + "\nSELECT 2;",
+		"CREATE TABLE \"public\".\"orders\" (\n" +
+			"    \"id\" integer NOT NULL,\n" +
+			"    \"user_id\" integer NOT NULL,\n" +
+			"    PRIMARY KEY (\"id\"),\n" +
+			"    CONSTRAINT \"orders_user\" FOREIGN KEY (\"user_id\") REFERENCES \"public\".\"users\" (\"id\")\n" +
+			");",
+		`ALTER TABLE "public"."users" ADD COLUMN "email" text;`,
+		`ALTER TABLE "public"."users" ALTER COLUMN "name" TYPE varchar(80);`,
+		`ALTER TABLE "public"."users" ALTER COLUMN "name" SET NOT NULL;`,
+		`ALTER TABLE "public"."users" ALTER COLUMN "name" SET DEFAULT '';`,
+		`ALTER TABLE "public"."users" DROP COLUMN "legacy";`,
+		`CREATE UNIQUE INDEX "users_email" ON "public"."users" ("email");`,
+		`DROP TABLE "public"."tmp";`,
+	}
+	if got != strings.Join(want, "\n\n")+"\n" {
+		t.Errorf("Migration =\n%s\nwant\n%s", got, strings.Join(want, "\n\n"))
+	}
+	if got := Migration("postgres", schema.Compare(cur, cur)); got != "" {
+		t.Errorf("migration between equal schemas = %q", got)
+	}
+}
+
+func TestMigration_SQLite(t *testing.T) {
+	old := []schema.Database{{Name: "app.db", Schemas: []schema.Schema{{
+		Name:   "main",
+		Tables: []schema.Table{{Name: "t", Columns: []schema.Column{{Name: "a", Type: "INTEGER"}}}},
+	}}}}
+	cur := []schema.Database{{Name: "app.db", Schemas: []schema.Schema{{
+		Name:   "main",
+		Tables: []schema.Table{{Name: "t", Columns: []schema.Column{{Name: "a", Type: "TEXT"}}}},
+	}}}}
+
+	got := Migration("sqlite", schema.Compare(old, cur))
+	if !strings.HasPrefix(got, "-- SQLite cannot alter column a of main.t") {
+		t.Errorf("SQLite migration = %q, want a note to rebuild the table", got)
+	}
+}
+
+func TestTableOrder(t *testing.T) {
+	diffs := []schema.ObjectDiff{
+		{Kind: schema.KindTable, Schema: "s", Name: "lines", Table: &schema.Table{Name: "lines", FKs: []schema.ForeignKey{{RefTable: "orders"}}}},
+		{Kind: schema.KindTable, Schema: "s", Name: "orders", Table: &schema.Table{Name: "orders", FKs: []schema.ForeignKey{{RefTable: "users"}, {RefTable: "orders"}}}},
+		{Kind: schema.KindTable, Schema: "s", Name: "users", Table: &schema.Table{Name: "users"}},
+	}
+	var got []string
+	for _, d := range tableOrder(diffs) {
+		got = append(got, d.Name)
+	}
+	if strings.Join(got, " ") != "users orders lines" {
+		t.Errorf("tableOrder = %v, want users orders lines", got)
+	}
+}
@@ -0,0 +1,161 @@
+package ddl
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+// InsertRows returns one INSERT statement adding rows to table, of
+// schemaName unless it is "", in a database of dialect, the values of
+// each row in the order of columns.
+// The values are read as an adapter reports them, whatever database they
+// came from, and written as the type of their column in dialect takes
+// them: booleans as true or false or 1 or 0, numbers bare, times without
+// the T of ISO 8601, bytes in hex, and anything else quoted.
+func InsertRows(dialect, schemaName, table string, columns []schema.Column, rows [][]string) string {
+	var sb strings.Builder
+	sb.WriteString("INSERT INTO " + qualifiedName(dialect, schemaName, table) + " (")
+	kinds := make([]typeKind, len(columns))
+	for i, c := range columns {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(adapter.QuoteIdentifier(dialect, c.Name))
+		kinds[i] = kindOf(dialect, c.Type)
+	}
+	sb.WriteString(") VALUES")
+	for r, row := range rows {
+		if r > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString("\n(")
+		for i := range columns {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			value := adapter.NullValue
+			if i < len(row) {
+				value = row[i]
+			}
+			sb.WriteString(literal(dialect, kinds[i], value))
+		}
+		sb.WriteString(")")
+	}
+	return sb.String()
+}
+
+// Literal returns value as a literal of a column of type typ in dialect,
+// written as InsertRows writes it. A value a numeric or boolean column
+// cannot take is an error, to report before the database does.
+func Literal(dialect, typ, value string) (string, error) {
+	kind := kindOf(dialect, typ)
+	v := strings.ToLower(strings.TrimSpace(value))
+	if adapter.IsNull(value) {
+		return "NULL", nil
+	}
+	switch kind {
+	case typeBool:
+		if _, ok := truth[v]; !ok {
+			return "", fmt.Errorf("%q is not true or false", value)
+		}
+	case typeSmallInt, typeInt, typeBigInt, typeDecimal, typeReal, typeDouble:
+		_, word := truth[v]
+		if _, err := strconv.ParseFloat(v, 64); (err != nil || strings.ContainsAny(v, "in")) && !word {
+			return "", fmt.Errorf("%q is not a number", value)
+		}
+	}
+	return literal(dialect, kind, value), nil
+}
+
+// kindOf returns the family of a column type of dialect, typeUnknown for
+// one CreateTable would not recognise either.
+func kindOf(dialect, typ string) typeKind {
+	match := typePattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(typ)))
+	if match == nil {
+		return typeUnknown
+	}
+	name, args := match[1], match[2]
+	for _, mod := range typeModifiers {
+		if _, known := typeNames[name]; !known {
+			name = strings.TrimSuffix(name, mod)
+		}
+	}
+	if dialect == "mysql" && name == "tinyint" && args == "1" {
+		return typeBool
+	}
+	return typeNames[name]
+}
+
+// truth reads the ways databases write a boolean.
+var truth = map[string]bool{
+	"t": true, "true": true, "y": true, "yes": true, "on": true, "1": true,
+	"f": false, "false": false, "n": false, "no": false, "off": false, "0": false,
+}
+
+// timeLayouts are the ISO 8601 forms a time is rewritten from, as SQLite
+// and JSON keep them, and the zoned ones PostgreSQL writes as text.
+var timeLayouts = []string{
+	time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02T15:04",
+	"2006-01-02 15:04:05.999999999Z07:00", "2006-01-02 15:04:05.999999999Z07",
+}
+
+// literal writes value as a literal of a column of kind in dialect.
+func literal(dialect string, kind typeKind, value string) string {
+	if adapter.IsNull(value) {
+		return "NULL"
+	}
+	switch kind {
+	case typeBool:
+		if b, ok := truth[strings.ToLower(strings.TrimSpace(value))]; ok {
+			switch {
+			case dialect == "mysql" || dialect == "sqlite":
+				return map[bool]string{true: "1", false: "0"}[b]
+			case b:
+				return "TRUE"
+			}
+			return "FALSE"
+		}
+	case typeSmallInt, typeInt, typeBigInt, typeDecimal, typeReal, typeDouble:
+		v := strings.TrimSpace(value)
+		if _, err := strconv.ParseFloat(v, 64); err == nil && v != "" && !strings.ContainsAny(v, "iInN") {
+			return v
+		}
+		if b, ok := truth[strings.ToLower(v)]; ok {
+			return map[bool]string{true: "1", false: "0"}[b]
+		}
+	case typeBytes:
+		h := hex.EncodeToString([]byte(value))
+		switch dialect {
+		case "postgres":
+			return `'\x` + h + `'::bytea`
+		case "duckdb":
+			var sb strings.Builder
+			for i := 0; i < len(h); i += 2 {
+				sb.WriteString(`\x` + h[i:i+2])
+			}
+			return "'" + sb.String() + "'::BLOB"
+		}
+		return "X'" + h + "'"
+	case typeDate, typeTimestamp, typeTimestampTZ:
+		for _, layout := range timeLayouts {
+			t, err := time.Parse(layout, value)
+			if err != nil {
+				continue
+			}
+			switch {
+			case kind == typeDate:
+				return adapter.QuoteLiteral(dialect, t.Format("2006-01-02"))
+			case kind == typeTimestampTZ && dialect != "mysql":
+				return adapter.QuoteLiteral(dialect, t.Format("2006-01-02 15:04:05.999999999Z07:00"))
+			}
+			return adapter.QuoteLiteral(dialect, t.Format("2006-01-02 15:04:05.999999999"))
+		}
+	}
+	return adapter.QuoteLiteral(dialect, value)
+}
@@ -0,0 +1,79 @@
+package ddl
+
+import (
+	"testing"
+
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+func TestInsertRows(t *testing.T) {
+	columns := []schema.Column{
+		{Name: "id", Type: "bigint"},
+		{Name: "active", Type: "boolean"},
+		{Name: "name", Type: "text"},
+		{Name: "seen", Type: "timestamp with time zone"},
+	}
+	rows := [][]string{
+		{"1", "t", "O'Brien", "2024-03-01T10:20:30Z"},
+		{"2e+06", "0", adapter.NullValue, "2024-03-01 10:20:30"},
+	}
+	got := InsertRows("postgres", "", "people", columns, rows)
+	want := `INSERT INTO "people" ("id", "active", "name", "seen") VALUES
+(1, TRUE, 'O''Brien', '2024-03-01 10:20:30Z'),
+(2e+06, FALSE, NULL, '2024-03-01 10:20:30')`
+	if got != want {
+		t.Errorf("InsertRows(postgres) =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestLiteral(t *testing.T) {
+	tests := []struct {
+		dialect, typ, value, want string
+	}{
+		{"mysql", "tinyint(1)", "true", "1"},
+		{"sqlite", "BOOLEAN", "f", "0"},
+		{"duckdb", "BOOLEAN", "maybe", "'maybe'"},
+		{"mysql", "int unsigned", "42", "42"},
+		{"mysql", "decimal(10,2)", "NaN", "'NaN'"},
+		{"sqlite", "INTEGER", "true", "1"},
+		{"postgres", "integer", "", "''"},
+		{"mysql", "datetime", "2024-03-01T10:20:30.5+02:00", "'2024-03-01 10:20:30.5'"},
+		{"mysql", "date", "2024-03-01T00:00:00Z", "'2024-03-01'"},
+		{"mysql", "varchar(10)", `a\b`, `'a\\b'`},
+		{"postgres", "jsonb", `{"a": 1}`, `'{"a": 1}'`},
+		{"postgres", "bytea", "\x00\xffA'", `'\x00ff4127'::bytea`},
+		{"mysql", "blob", "\x00\xff", "X'00ff'"},
+		{"sqlite", "BLOB", "", "X''"},
+		{"duckdb", "BLOB", "\x01A", `'\x01\x41'::BLOB`},
+		{"postgres", "timestamptz", "2024-03-01 10:20:30.123456+00", "'2024-03-01 10:20:30.123456Z'"},
+		{"postgres", "timestamptz", "2024-03-01 10:20:30+05:30", "'2024-03-01 10:20:30+05:30'"},
+		{"mysql", "datetime(6)", "2024-03-01 10:20:30.5-07", "'2024-03-01 10:20:30.5'"},
+	}
+	for _, tt := range tests {
+		if got := literal(tt.dialect, kindOf(tt.dialect, tt.typ), tt.value); got != tt.want {
+			t.Errorf("literal(%s %s, %q) = %s, want %s", tt.dialect, tt.typ, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLiteral_Checked(t *testing.T) {
+	tests := []struct {
+		typ, value, want string
+		fails            bool
+	}{
+		{typ: "integer", value: " 42 ", want: "42"},
+		{typ: "numeric(10,2)", value: "12,5", fails: true},
+		{typ: "double precision", value: "inf", fails: true},
+		{typ: "boolean", value: "yes", want: "TRUE"},
+		{typ: "boolean", value: "maybe", fails: true},
+		{typ: "integer", value: adapter.NullValue, want: "NULL"},
+		{typ: "text", value: "42", want: "'42'"},
+	}
+	for _, tt := range tests {
+		got, err := Literal("postgres", tt.typ, tt.value)
+		if (err != nil) != tt.fails || got != tt.want {
+			t.Errorf("Literal(%s, %q) = %s, %v; want %s, failing %v", tt.typ, tt.value, got, err, tt.want, tt.fails)
+		}
+	}
+}
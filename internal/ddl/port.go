@@ -0,0 +1,426 @@
+package ddl
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+// Dialects lists the dialects CreateTable can write, with their display
+// names.
+var Dialects = []struct{ Name, Title string }{
+	{"postgres", "PostgreSQL"},
+	{"mysql", "MySQL"},
+	{"sqlite", "SQLite"},
+	{"duckdb", "DuckDB"},
+}
+
+// CreateTable returns the CREATE TABLE statement, and CREATE INDEX for the
+// other indexes, that make table t, as read from a database of dialect
+// from, in a database of dialect to. Names are left unqualified, to be run
+// in the database the table is copied into.
+//
+// Types are mapped to their nearest equivalent in to, and columns numbered
+// by a sequence or AUTO_INCREMENT stay numbered the way to does it.
+// Defaults that are not a literal or the current time are left out; each
+// type or default that could not be carried over as it was is noted in a
+// comment above the statement.
+func CreateTable(from, to string, t schema.Table) string {
+	if from == to {
+		g := generator{dialect: to}
+		g.createTable("", t)
+		return g.String()
+	}
+	g := generator{dialect: to, identity: map[string]bool{}}
+	keys := keyColumns(t)
+	port := t
+	port.Columns = make([]schema.Column, len(t.Columns))
+	for i, c := range t.Columns {
+		typ, note := portType(from, to, c.Type, keys[c.Name])
+		if note != "" {
+			g.comment("%s: %s", c.Name, note)
+		}
+		def, auto, ok := portDefault(from, to, c.Default)
+		if !ok {
+			g.comment("%s: default %s left out", c.Name, c.Default)
+		}
+		switch {
+		case strings.EqualFold(typ, "boolean") && (def == "1" || def == "0"):
+			def = map[string]string{"1": "true", "0": "false"}[def]
+		case to == "mysql" && def != "" && (typ == "longtext" || typ == "longblob" || typ == "json"):
+			// MySQL takes only an expression as the default of these.
+			def = "(" + def + ")"
+		}
+		if auto {
+			if to == "duckdb" {
+				g.comment("%s: numbered by the database in %s; DuckDB needs a sequence for it", c.Name, from)
+			} else {
+				g.identity[c.Name] = true
+			}
+		}
+		c.Type, c.Default = typ, def
+		port.Columns[i] = c
+	}
+	g.createTable("", port)
+	return g.String()
+}
+
+// keyColumns returns the columns of t in its primary key, an index or a
+// foreign key, which MySQL cannot key on as TEXT or BLOB.
+func keyColumns(t schema.Table) map[string]bool {
+	keys := make(map[string]bool)
+	for _, c := range t.Columns {
+		if c.IsPK {
+			keys[c.Name] = true
+		}
+	}
+	for _, idx := range t.Indexes {
+		for _, c := range idx.Columns {
+			keys[c] = true
+		}
+	}
+	for _, fk := range t.FKs {
+		for _, c := range fk.Columns {
+			keys[c] = true
+		}
+	}
+	return keys
+}
+
+// identityClause returns what makes a column numbered by the database in
+// the dialect, after its NOT NULL and DEFAULT.
+func (g *generator) identityClause() string {
+	switch g.dialect {
+	case "postgres":
+		return " GENERATED BY DEFAULT AS IDENTITY"
+	case "mysql":
+		return " AUTO_INCREMENT"
+	}
+	// SQLite numbers an INTEGER PRIMARY KEY itself.
+	return ""
+}
+
+// typeKind is a family of column types every dialect has an equivalent for.
+type typeKind int
+
+const (
+	typeUnknown typeKind = iota
+	typeBool
+	typeSmallInt
+	typeInt
+	typeBigInt
+	typeDecimal
+	typeReal
+	typeDouble
+	typeChar
+	typeVarchar
+	typeText
+	typeBytes
+	typeDate
+	typeTime
+	typeTimestamp
+	typeTimestampTZ
+	typeJSON
+	typeUUID
+)
+
+// typeNames maps the type names of all dialects, lowercased and without
+// their length or precision, to their family.
+var typeNames = map[string]typeKind{
+	"boolean": typeBool, "bool": typeBool,
+	"smallint": typeSmallInt, "int2": typeSmallInt, "tinyint": typeSmallInt, "utinyint": typeSmallInt,
+	"integer": typeInt, "int": typeInt, "int4": typeInt, "mediumint": typeInt, "serial": typeInt, "usmallint": typeInt,
+	"bigint": typeBigInt, "int8": typeBigInt, "bigserial": typeBigInt, "uinteger": typeBigInt,
+	"numeric": typeDecimal, "decimal": typeDecimal, "hugeint": typeDecimal, "ubigint": typeDecimal,
+	"real": typeReal, "float4": typeReal, "float": typeReal,
+	"double": typeDouble, "double precision": typeDouble, "float8": typeDouble,
+	"char": typeChar, "character": typeChar, "bpchar": typeChar, "nchar": typeChar,
+	"varchar": typeVarchar, "character varying": typeVarchar, "nvarchar": typeVarchar,
+	"text": typeText, "tinytext": typeText, "mediumtext": typeText, "longtext": typeText, "clob": typeText, "string": typeText,
+	"bytea": typeBytes, "blob": typeBytes, "tinyblob": typeBytes, "mediumblob": typeBytes, "longblob": typeBytes,
+	"binary": typeBytes, "varbinary": typeBytes,
+	"date": typeDate,
+	"time": typeTime, "time without time zone": typeTime,
+	"timestamp": typeTimestamp, "timestamp without time zone": typeTimestamp, "datetime": typeTimestamp,
+	"timestamptz": typeTimestampTZ, "timestamp with time zone": typeTimestampTZ,
+	"json": typeJSON, "jsonb": typeJSON,
+	"uuid": typeUUID,
+}
+
+// typePattern splits a type into its name, its arguments and what follows
+// them, as in "int(10) unsigned" or "timestamp(3) with time zone".
+var typePattern = regexp.MustCompile(`^([a-z][a-z0-9 ]*[a-z0-9]|[a-z])\s*(?:\(([^)]*)\))?\s*([a-z ]*)$`)
+
+// typeModifiers are the words that can follow a type without arguments, as
+// in "int unsigned".
+var typeModifiers = []string{" unsigned", " signed", " zerofill", " with time zone", " without time zone"}
+
+// portType returns the type of dialect to nearest to typ of dialect from,
+// and a note when it is not an equivalent. A key column is kept indexable
+// in MySQL.
+func portType(from, to, typ string, key bool) (string, string) {
+	lower := strings.ToLower(strings.TrimSpace(typ))
+	if base, ok := strings.CutSuffix(lower, "[]"); ok {
+		if to == "postgres" || to == "duckdb" {
+			elem, note := portType(from, to, base, false)
+			return elem + "[]", note
+		}
+		return portName(to, typeJSON, "", key), typ + " array stored as " + portName(to, typeJSON, "", key)
+	}
+
+	switch lower {
+	case "":
+		// A SQLite column declared without a type holds anything.
+		return portName(to, typeText, "", key), "no type, stored as text"
+	case "user-defined", "array":
+		// Postgres reports enums, domains and arrays without their type.
+		return portName(to, typeText, "", key), "type " + typ + " stored as text"
+	}
+	match := typePattern.FindStringSubmatch(lower)
+	if match == nil {
+		return typ, "type " + typ + " kept as it is"
+	}
+	name, args, rest := match[1], match[2], match[3]
+	for _, mod := range typeModifiers {
+		if _, known := typeNames[name]; !known && strings.HasSuffix(name, mod) {
+			name, rest = strings.TrimSuffix(name, mod), mod+" "+rest
+		}
+	}
+	kind, known := typeNames[name]
+	switch {
+	case from == "mysql" && name == "tinyint" && args == "1":
+		kind = typeBool
+	case from == "sqlite" && kind == typeInt:
+		kind = typeBigInt // SQLite's integers are 64-bit
+	case kind == typeTimestamp && strings.Contains(rest, "with time zone") && !strings.Contains(rest, "without"):
+		kind = typeTimestampTZ
+	case strings.Contains(rest, "unsigned"):
+		// One size up holds every value.
+		switch kind {
+		case typeSmallInt:
+			kind = typeInt
+		case typeInt:
+			kind = typeBigInt
+		case typeBigInt:
+			kind, args = typeDecimal, "20"
+		}
+	case name == "hugeint":
+		args = "38"
+	case name == "ubigint":
+		args = "20"
+	}
+	if kind == typeText && key && to == "mysql" {
+		kind = typeVarchar
+	}
+	if known {
+		return portName(to, kind, args, key), ""
+	}
+
+	switch {
+	case from == "mysql" && (name == "enum" || name == "set"):
+		return portName(to, typeText, "", key), typ + " stored as text"
+	case from == "sqlite":
+		// SQLite's rules for the affinity of a declared type.
+		kind := typeDecimal
+		switch {
+		case strings.Contains(name, "int"):
+			kind = typeBigInt
+		case strings.Contains(name, "char"), strings.Contains(name, "clob"), strings.Contains(name, "text"):
+			kind = typeText
+		case strings.Contains(name, "blob"):
+			kind = typeBytes
+		case strings.Contains(name, "real"), strings.Contains(name, "floa"), strings.Contains(name, "doub"):
+			kind = typeDouble
+		}
+		return portName(to, kind, "", key), ""
+	case to == "sqlite":
+		return "TEXT", "type " + typ + " stored as TEXT"
+	}
+	return typ, "type " + typ + " kept as it is"
+}
+
+// portName returns the name of type kind in dialect, with args as its
+// length or precision where the dialect has one.
+func portName(dialect string, kind typeKind, args string, key bool) string {
+	withArgs := func(name string) string {
+		if args == "" {
+			return name
+		}
+		return name + "(" + args + ")"
+	}
+	switch dialect {
+	case "sqlite":
+		switch kind {
+		case typeBool, typeSmallInt, typeInt, typeBigInt:
+			return "INTEGER"
+		case typeDecimal:
+			return "NUMERIC"
+		case typeReal, typeDouble:
+			return "REAL"
+		case typeBytes:
+			return "BLOB"
+		}
+		return "TEXT"
+
+	case "mysql":
+		switch kind {
+		case typeBool:
+			return "tinyint(1)"
+		case typeSmallInt:
+			return "smallint"
+		case typeInt:
+			return "int"
+		case typeBigInt:
+			return "bigint"
+		case typeDecimal:
+			return withArgs("decimal")
+		case typeReal:
+			return "float"
+		case typeDouble:
+			return "double"
+		case typeChar:
+			return withArgs("char")
+		case typeVarchar:
+			if args == "" {
+				args = "255"
+			}
+			return withArgs("varchar")
+		case typeBytes:
+			if key {
+				return "varbinary(255)"
+			}
+			return "longblob"
+		case typeDate:
+			return "date"
+		case typeTime:
+			return "time"
+		case typeTimestamp, typeTimestampTZ:
+			return "datetime"
+		case typeJSON:
+			return "json"
+		case typeUUID:
+			return "char(36)"
+		}
+		return "longtext"
+
+	case "duckdb":
+		switch kind {
+		case typeBool:
+			return "BOOLEAN"
+		case typeSmallInt:
+			return "SMALLINT"
+		case typeInt:
+			return "INTEGER"
+		case typeBigInt:
+			return "BIGINT"
+		case typeDecimal:
+			return withArgs("DECIMAL")
+		case typeReal:
+			return "REAL"
+		case typeDouble:
+			return "DOUBLE"
+		case typeBytes:
+			return "BLOB"
+		case typeDate:
+			return "DATE"
+		case typeTime:
+			return "TIME"
+		case typeTimestamp:
+			return "TIMESTAMP"
+		case typeTimestampTZ:
+			return "TIMESTAMPTZ"
+		case typeJSON:
+			return "JSON"
+		case typeUUID:
+			return "UUID"
+		}
+		return "VARCHAR"
+	}
+
+	switch kind {
+	case typeBool:
+		return "boolean"
+	case typeSmallInt:
+		return "smallint"
+	case typeInt:
+		return "integer"
+	case typeBigInt:
+		return "bigint"
+	case typeDecimal:
+		return withArgs("numeric")
+	case typeReal:
+		return "real"
+	case typeDouble:
+		return "double precision"
+	case typeChar:
+		return withArgs("char")
+	case typeVarchar:
+		return withArgs("varchar")
+	case typeBytes:
+		return "bytea"
+	case typeDate:
+		return "date"
+	case typeTime:
+		return "time"
+	case typeTimestamp:
+		return "timestamp"
+	case typeTimestampTZ:
+		return "timestamptz"
+	case typeJSON:
+		return "jsonb"
+	case typeUUID:
+		return "uuid"
+	}
+	return "text"
+}
+
+// castSuffix matches the casts Postgres reports defaults with, as in
+// 'new'::character varying.
+var castSuffix = regexp.MustCompile(`::[a-z ]+(\[\])?$`)
+
+// portDefault returns the default of dialect to for def of dialect from:
+// literals and the current time carry over, and a sequence (or DuckDB's
+// nextval) makes the column numbered by the database, auto. ok is false
+// when the default is left out.
+func portDefault(from, to, def string) (port string, auto, ok bool) {
+	d := strings.TrimSpace(def)
+	switch upper := strings.ToUpper(d); {
+	case d == "" || upper == "NULL":
+		return "", false, true
+	case strings.HasPrefix(upper, "NEXTVAL("):
+		return "", true, true
+	case upper == "CURRENT_TIMESTAMP" || upper == "NOW()" || upper == "CURRENT_TIMESTAMP()" ||
+		strings.HasPrefix(upper, "CURRENT_TIMESTAMP("):
+		return "CURRENT_TIMESTAMP", false, true
+	case upper == "CURRENT_DATE" || upper == "CURDATE()":
+		return "CURRENT_DATE", false, true
+	case upper == "TRUE" || upper == "FALSE":
+		if to == "sqlite" {
+			return map[bool]string{true: "1", false: "0"}[upper == "TRUE"], false, true
+		}
+		return strings.ToLower(upper), false, true
+	}
+
+	if from == "postgres" {
+		d = castSuffix.ReplaceAllString(d, "")
+		d = strings.TrimSuffix(strings.TrimPrefix(d, "("), ")")
+	}
+	if _, err := strconv.ParseFloat(d, 64); err == nil {
+		return d, false, true
+	}
+	if len(d) >= 2 && d[0] == '\'' && d[len(d)-1] == '\'' {
+		value := strings.ReplaceAll(d[1:len(d)-1], "''", "'")
+		if from == "mysql" {
+			value = strings.ReplaceAll(value, `\\`, `\`)
+		}
+		return adapter.QuoteLiteral(to, value), false, true
+	}
+	if from == "mysql" && !strings.ContainsAny(d, "()") {
+		// MySQL reports a string default without its quotes.
+		return adapter.QuoteLiteral(to, d), false, true
+	}
+	return "", false, false
+}
@@ -0,0 +1,128 @@
+package ddl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+func TestCreateTable(t *testing.T) {
+	// As the MySQL adapter reports it.
+	users := schema.Table{
+		Name: "users",
+		Columns: []schema.Column{
+			{Name: "id", Type: "int unsigned", IsPK: true},
+			{Name: "email", Type: "varchar(120)"},
+			{Name: "active", Type: "tinyint(1)", Default: "1"},
+			{Name: "role", Type: "enum('admin','user')", Default: "user"},
+			{Name: "bio", Type: "text", Nullable: true},
+			{Name: "created", Type: "datetime", Default: "CURRENT_TIMESTAMP"},
+			{Name: "team_id", Type: "int", Nullable: true},
+		},
+		Indexes: []schema.Index{
+			{Name: "PRIMARY", Columns: []string{"id"}, Unique: true},
+			{Name: "users_email", Columns: []string{"email"}, Unique: true},
+		},
+		FKs: []schema.ForeignKey{{Name: "users_team", Columns: []string{"team_id"}, RefTable: "teams", RefColumns: []string{"id"}}},
+	}
+
+	got := CreateTable("mysql", "postgres", users)
+	want := []string{
+		"-- role: enum('admin','user') stored as text",
+		"CREATE TABLE \"users\" (\n" +
+			"    \"id\" bigint NOT NULL,\n" +
+			"    \"email\" varchar(120) NOT NULL,\n" +
+			"    \"active\" boolean NOT NULL DEFAULT true,\n" +
+			"    \"role\" text NOT NULL DEFAULT 'user',\n" +
+			"    \"bio\" text,\n" +
+			"    \"created\" timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,\n" +
+			"    \"team_id\" integer,\n" +
+			"    PRIMARY KEY (\"id\"),\n" +
+			"    CONSTRAINT \"users_team\" FOREIGN KEY (\"team_id\") REFERENCES \"teams\" (\"id\")\n" +
+			");",
+		`CREATE UNIQUE INDEX "users_email" ON "users" ("email");`,
+	}
+	if got != strings.Join(want, "\n\n")+"\n" {
+		t.Errorf("CreateTable(mysql, postgres) =\n%s\nwant\n%s", got, strings.Join(want, "\n\n"))
+	}
+
+	if got := CreateTable("mysql", "mysql", users); !strings.Contains(got, "`role` enum('admin','user') NOT NULL DEFAULT user") {
+		t.Errorf("the same dialect should keep the table as it is:\n%s", got)
+	}
+}
+
+func TestCreateTable_Identity(t *testing.T) {
+	// As the Postgres adapter reports a serial key and a typed default.
+	orders := schema.Table{
+		Name: "orders",
+		Columns: []schema.Column{
+			{Name: "id", Type: "integer", IsPK: true, Default: "nextval('orders_id_seq'::regclass)"},
+			{Name: "note", Type: "text", Default: "'it''s new'::text"},
+			{Name: "code", Type: "text"},
+			{Name: "tags", Type: "text[]", Nullable: true},
+			{Name: "due", Type: "date", Default: "(now() + '1 day'::interval)"},
+		},
+		Indexes: []schema.Index{{Name: "orders_code", Columns: []string{"code"}}},
+	}
+
+	tests := []struct {
+		to   string
+		want []string
+	}{
+		{"mysql", []string{
+			"-- tags: text[] array stored as json",
+			"-- due: default (now() + '1 day'::interval) left out",
+			"`id` int NOT NULL AUTO_INCREMENT,",
+			"`note` longtext NOT NULL DEFAULT ('it''s new'),",
+			"`code` varchar(255) NOT NULL,", // indexed
+			"CREATE INDEX `orders_code` ON `orders` (`code`);",
+		}},
+		{"sqlite", []string{
+			`"id" INTEGER NOT NULL,`,
+			`"tags" TEXT,`,
+			`PRIMARY KEY ("id")`,
+		}},
+		{"duckdb", []string{
+			"-- id: numbered by the database in postgres; DuckDB needs a sequence for it",
+			`"tags" VARCHAR[],`,
+		}},
+	}
+	for _, tt := range tests {
+		got := CreateTable("postgres", tt.to, orders)
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("CreateTable(postgres, %s) lacks %q:\n%s", tt.to, want, got)
+			}
+		}
+	}
+}
+
+func TestPortType(t *testing.T) {
+	tests := []struct {
+		from, to, typ string
+		want          string
+	}{
+		{"postgres", "mysql", "character varying", "varchar(255)"},
+		{"postgres", "mysql", "timestamp with time zone", "datetime"},
+		{"postgres", "duckdb", "timestamp with time zone", "TIMESTAMPTZ"},
+		{"postgres", "duckdb", "numeric(10,2)", "DECIMAL(10,2)"},
+		{"postgres", "sqlite", "double precision", "REAL"},
+		{"postgres", "sqlite", "inet", "TEXT"},
+		{"postgres", "mysql", "USER-DEFINED", "longtext"},
+		{"mysql", "postgres", "bigint(20) unsigned", "numeric(20)"},
+		{"mysql", "postgres", "tinyint(4)", "smallint"},
+		{"mysql", "duckdb", "longblob", "BLOB"},
+		{"mysql", "postgres", "geometry", "geometry"},
+		{"sqlite", "postgres", "VARCHAR(40)", "varchar(40)"},
+		{"sqlite", "postgres", "UNSIGNED BIG INT", "bigint"},
+		{"sqlite", "mysql", "", "longtext"},
+		{"duckdb", "postgres", "HUGEINT", "numeric(38)"},
+		{"duckdb", "postgres", "UUID", "uuid"},
+	}
+	for _, tt := range tests {
+		if got, _ := portType(tt.from, tt.to, tt.typ, false); got != tt.want {
+			t.Errorf("portType(%s, %s, %q) = %q, want %q", tt.from, tt.to, tt.typ, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package ddl
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+// SameValue reports whether a and b, read as an adapter reports them from
+// any database, are the same value of a column of type typ in dialect:
+// numbers compare by value, so 1.50 is 1.5, and anything else is the same
+// when InsertRows would write it the same, so t and 1 are the same
+// boolean.
+func SameValue(dialect, typ, a, b string) bool {
+	if adapter.IsNull(a) || adapter.IsNull(b) {
+		return adapter.IsNull(a) == adapter.IsNull(b)
+	}
+	kind := kindOf(dialect, typ)
+	if numericKind(kind) {
+		x, okX := new(big.Rat).SetString(strings.TrimSpace(a))
+		y, okY := new(big.Rat).SetString(strings.TrimSpace(b))
+		if okX && okY {
+			return x.Cmp(y) == 0
+		}
+	}
+	return literal(dialect, kind, a) == literal(dialect, kind, b)
+}
+
+// CompareKey orders two values of a key column of type typ in dialect as
+// OrderKey sorts them: numbers by value, anything else byte by byte.
+func CompareKey(dialect, typ, a, b string) int {
+	if numericKind(kindOf(dialect, typ)) {
+		x, okX := new(big.Rat).SetString(strings.TrimSpace(a))
+		y, okY := new(big.Rat).SetString(strings.TrimSpace(b))
+		if okX && okY {
+			return x.Cmp(y)
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// OrderKey returns the ORDER BY term sorting by column, of type typ in
+// dialect, in the order CompareKey takes: text by its bytes rather than
+// by the collation of the column.
+func OrderKey(dialect, typ, column string) string {
+	quoted := adapter.QuoteIdentifier(dialect, column)
+	switch kindOf(dialect, typ) {
+	case typeChar, typeVarchar, typeText:
+		switch dialect {
+		case "postgres":
+			return quoted + ` COLLATE "C"`
+		case "mysql":
+			return "CAST(" + quoted + " AS BINARY)"
+		}
+	}
+	return quoted
+}
+
+// SelectTerm returns the term selecting column, of type typ in dialect,
+// in a form InsertRows writes back whole: PostgreSQL's times are read as
+// text, as the adapter shows them without their fraction and zone.
+func SelectTerm(dialect, typ, column string) string {
+	quoted := adapter.QuoteIdentifier(dialect, column)
+	switch kindOf(dialect, typ) {
+	case typeTime, typeTimestamp, typeTimestampTZ:
+		if dialect == "postgres" {
+			return quoted + "::text"
+		}
+	}
+	return quoted
+}
+
+// DeleteRows returns one DELETE removing the rows of table, of schemaName
+// unless it is "", whose columns keys hold the values of one of rows.
+func DeleteRows(dialect, schemaName, table string, keys []schema.Column, rows [][]string) string {
+	var sb strings.Builder
+	sb.WriteString("DELETE FROM " + qualifiedName(dialect, schemaName, table) + " WHERE ")
+	if len(keys) == 1 {
+		sb.WriteString(adapter.QuoteIdentifier(dialect, keys[0].Name) + " IN (")
+		for r, row := range rows {
+			if r > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(literal(dialect, kindOf(dialect, keys[0].Type), row[0]))
+		}
+		sb.WriteString(")")
+		return sb.String()
+	}
+	for r, row := range rows {
+		if r > 0 {
+			sb.WriteString("\n   OR ")
+		}
+		sb.WriteString("(" + keyMatch(dialect, keys, row) + ")")
+	}
+	return sb.String()
+}
+
+// UpdateRow returns the UPDATE setting columns to values on the row of
+// table, of schemaName unless it is "", whose columns keys hold key.
+func UpdateRow(dialect, schemaName, table string, keys []schema.Column, key []string, columns []schema.Column, values []string) string {
+	assigns := make([]string, len(columns))
+	for i, c := range columns {
+		assigns[i] = adapter.QuoteIdentifier(dialect, c.Name) + " = " + literal(dialect, kindOf(dialect, c.Type), values[i])
+	}
+	return "UPDATE " + qualifiedName(dialect, schemaName, table) + " SET " + strings.Join(assigns, ", ") +
+		" WHERE " + keyMatch(dialect, keys, key)
+}
+
+// keyMatch writes the condition matching the row whose columns keys hold
+// key.
+func keyMatch(dialect string, keys []schema.Column, key []string) string {
+	conds := make([]string, len(keys))
+	for i, k := range keys {
+		conds[i] = adapter.QuoteIdentifier(dialect, k.Name) + " = " + literal(dialect, kindOf(dialect, k.Type), key[i])
+	}
+	return strings.Join(conds, " AND ")
+}
+
+// qualifiedName quotes table, with schemaName in front unless it is "".
+func qualifiedName(dialect, schemaName, table string) string {
+	if schemaName == "" {
+		return adapter.QuoteIdentifier(dialect, table)
+	}
+	return adapter.QuoteIdentifier(dialect, schemaName) + "." + adapter.QuoteIdentifier(dialect, table)
+}
+
+// numericKind reports whether kind is a family of numbers.
+func numericKind(kind typeKind) bool {
+	switch kind {
+	case typeSmallInt, typeInt, typeBigInt, typeDecimal, typeReal, typeDouble:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package ddl
+
+import (
+	"testing"
+
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+func TestSameValue(t *testing.T) {
+	tests := []struct {
+		dialect, typ, a, b string
+		want               bool
+	}{
+		{"postgres", "numeric(10,2)", "1.50", "1.5", true},
+		{"postgres", "numeric(30,2)", "12345678901234567890.01", "12345678901234567890.02", false},
+		{"postgres", "boolean", "t", "1", true},
+		{"mysql", "tinyint(1)", "0", "false", true},
+		{"sqlite", "TEXT", "a", "a ", false},
+		{"postgres", "timestamp", "2024-03-01T10:20:30Z", "2024-03-01 10:20:30", true},
+		{"postgres", "text", adapter.NullValue, "", false},
+		{"postgres", "text", adapter.NullValue, adapter.NullValue, true},
+	}
+	for _, tt := range tests {
+		if got := SameValue(tt.dialect, tt.typ, tt.a, tt.b); got != tt.want {
+			t.Errorf("SameValue(%s %s, %q, %q) = %v, want %v", tt.dialect, tt.typ, tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCompareKey(t *testing.T) {
+	if got := CompareKey("postgres", "integer", "9", "10"); got >= 0 {
+		t.Errorf("9 should sort before 10 as integers, got %d", got)
+	}
+	if got := CompareKey("postgres", "text", "9", "10"); got <= 0 {
+		t.Errorf("9 should sort after 10 as text, got %d", got)
+	}
+	if got := CompareKey("postgres", "text", "B", "a"); got >= 0 {
+		t.Errorf("B should sort before a byte by byte, got %d", got)
+	}
+}
+
+func TestOrderKey(t *testing.T) {
+	tests := []struct{ dialect, typ, want string }{
+		{"postgres", "character varying(20)", `"code" COLLATE "C"`},
+		{"mysql", "varchar(20)", "CAST(`code` AS BINARY)"},
+		{"sqlite", "TEXT", `"code"`},
+		{"postgres", "uuid", `"code"`},
+	}
+	for _, tt := range tests {
+		if got := OrderKey(tt.dialect, tt.typ, "code"); got != tt.want {
+			t.Errorf("OrderKey(%s, %s) = %s, want %s", tt.dialect, tt.typ, got, tt.want)
+		}
+	}
+}
+
+func TestSelectTerm(t *testing.T) {
+	tests := []struct{ dialect, typ, want string }{
+		{"postgres", "timestamp with time zone", `"at"::text`},
+		{"postgres", "timestamp(3) without time zone", `"at"::text`},
+		{"postgres", "time", `"at"::text`},
+		{"postgres", "date", `"at"`},
+		{"mysql", "datetime(6)", "`at`"},
+	}
+	for _, tt := range tests {
+		if got := SelectTerm(tt.dialect, tt.typ, "at"); got != tt.want {
+			t.Errorf("SelectTerm(%s, %s) = %s, want %s", tt.dialect, tt.typ, got, tt.want)
+		}
+	}
+}
+
+func TestDeleteAndUpdateRows(t *testing.T) {
+	id := schema.Column{Name: "id", Type: "integer"}
+	region := schema.Column{Name: "region", Type: "text"}
+	if got, want := DeleteRows("postgres", "public", "orders", []schema.Column{id}, [][]string{{"1"}, {"2"}}),
+		`DELETE FROM "public"."orders" WHERE "id" IN (1, 2)`; got != want {
+		t.Errorf("DeleteRows =\n%s\nwant\n%s", got, want)
+	}
+	got := DeleteRows("mysql", "", "stock", []schema.Column{region, id}, [][]string{{"eu", "1"}, {"us", "2"}})
+	want := "DELETE FROM `stock` WHERE (`region` = 'eu' AND `id` = 1)\n   OR (`region` = 'us' AND `id` = 2)"
+	if got != want {
+		t.Errorf("DeleteRows =\n%s\nwant\n%s", got, want)
+	}
+
+	active := schema.Column{Name: "active", Type: "boolean"}
+	got = UpdateRow("sqlite", "", "users", []schema.Column{id}, []string{"7"}, []schema.Column{region, active}, []string{"it's", "true"})
+	want = `UPDATE "users" SET "region" = 'it''s', "active" = 1 WHERE "id" = 7`
+	if got != want {
+		t.Errorf("UpdateRow =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,330 @@
+// Package fakedata makes up plausible rows for a table, to fill it with
+// test data: names, emails, dates and lorem ipsum, picked by the name and
+// type of each column, and values of its foreign keys sampled from the
+// tables they reference.
+package fakedata
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+// Reference is a foreign key of the table: the positions of its columns
+// among those generated, and rows of the values of the columns they
+// reference, to pick from.
+type Reference struct {
+	Columns []int
+	Samples [][]string
+}
+
+// nullShare is the share of NULLs in a column that takes them.
+const nullShare = 0.1
+
+// Generator makes up rows of values for columns.
+type Generator struct {
+	columns []schema.Column
+	kinds   []kind
+	sizes   []int   // most characters a text column takes, 0 for any
+	refs    []int   // the reference of each column, -1 for none
+	serial  []int64 // the next value of a counted column, 0 for none
+	samples []Reference
+	rng     *rand.Rand
+	row     int64
+	now     time.Time
+}
+
+// New returns a generator of rows for columns, whose foreign keys refs
+// are, seeded with seed.
+func New(columns []schema.Column, refs []Reference, seed uint64) *Generator {
+	g := &Generator{
+		columns: columns,
+		kinds:   make([]kind, len(columns)),
+		sizes:   make([]int, len(columns)),
+		refs:    make([]int, len(columns)),
+		serial:  make([]int64, len(columns)),
+		samples: refs,
+		rng:     rand.New(rand.NewPCG(seed, seed>>1|1)),
+		now:     time.Now().UTC().Truncate(time.Second),
+	}
+	for i, c := range columns {
+		g.kinds[i], g.sizes[i] = kindOf(c.Type)
+		g.refs[i] = -1
+	}
+	for r, ref := range refs {
+		for _, i := range ref.Columns {
+			g.refs[i] = r
+		}
+	}
+	return g
+}
+
+// Count makes column i count up from start, as a key with no default.
+func (g *Generator) Count(i int, start int64) {
+	g.serial[i] = start
+}
+
+// Row makes up the next row, a value per column, adapter.NullValue for
+// NULL.
+func (g *Generator) Row() []string {
+	g.row++
+	row := make([]string, len(g.columns))
+	picked := make(map[int][]string, len(g.samples))
+	for i, c := range g.columns {
+		if r := g.refs[i]; r >= 0 {
+			ref := g.samples[r]
+			sample, ok := picked[r]
+			if !ok && len(ref.Samples) > 0 {
+				sample = ref.Samples[g.rng.IntN(len(ref.Samples))]
+				picked[r] = sample
+			}
+			row[i] = adapter.NullValue
+			for j, col := range ref.Columns {
+				if col == i && j < len(sample) {
+					row[i] = sample[j]
+				}
+			}
+			continue
+		}
+		if g.serial[i] != 0 {
+			row[i] = strconv.FormatInt(g.serial[i], 10)
+			g.serial[i]++
+			continue
+		}
+		if c.IsPK && g.kinds[i] == kindText {
+			// Keep text keys apart from each other and, likely, from
+			// the keys the table has.
+			row[i] = g.fit(fmt.Sprintf("%s-%d", g.uuid()[:8], g.row), g.sizes[i])
+			continue
+		}
+		if c.Nullable && !c.IsPK && g.rng.Float64() < nullShare {
+			row[i] = adapter.NullValue
+			continue
+		}
+		row[i] = g.value(i)
+	}
+	return row
+}
+
+// kind is the family of a column type.
+type kind int
+
+const (
+	kindText kind = iota
+	kindInt
+	kindDecimal
+	kindBool
+	kindDate
+	kindTime
+	kindTimestamp
+	kindUUID
+	kindJSON
+)
+
+// sizePattern finds the length of a character type, as in varchar(40).
+var sizePattern = regexp.MustCompile(`char\w*\s*\((\d+)\)`)
+
+// kindOf returns the family of a column type, and the most characters a
+// text column of it takes (0 for any).
+func kindOf(typ string) (kind, int) {
+	t := strings.ToLower(typ)
+	switch {
+	case strings.Contains(t, "bool"), strings.HasPrefix(t, "tinyint(1)"), t == "bit" || t == "bit(1)":
+		return kindBool, 0
+	case strings.Contains(t, "int"), strings.Contains(t, "serial"):
+		return kindInt, 0
+	case strings.Contains(t, "numeric"), strings.Contains(t, "decimal"), strings.Contains(t, "real"),
+		strings.Contains(t, "float"), strings.Contains(t, "double"), strings.Contains(t, "money"):
+		return kindDecimal, 0
+	case strings.Contains(t, "timestamp"), strings.Contains(t, "datetime"):
+		return kindTimestamp, 0
+	case strings.Contains(t, "date"):
+		return kindDate, 0
+	case strings.HasPrefix(t, "time"):
+		return kindTime, 0
+	case strings.Contains(t, "uuid"), t == "uniqueidentifier":
+		return kindUUID, 0
+	case strings.Contains(t, "json"):
+		return kindJSON, 0
+	}
+	if m := sizePattern.FindStringSubmatch(t); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return kindText, n
+	}
+	return kindText, 0
+}
+
+// value makes up a value of column i, by its name, else by its type.
+func (g *Generator) value(i int) string {
+	raw := g.columns[i].Name
+	name := strings.ToLower(raw)
+	switch g.kinds[i] {
+	case kindBool:
+		return strconv.FormatBool(g.rng.IntN(2) == 0)
+	case kindInt:
+		switch {
+		case hasWord(raw, "age"):
+			return strconv.Itoa(18 + g.rng.IntN(72))
+		case hasWord(raw, "year"):
+			return strconv.Itoa(g.now.Year() - g.rng.IntN(30))
+		case strings.Contains(name, "qty"), strings.Contains(name, "quantity"), strings.Contains(name, "count"):
+			return strconv.Itoa(1 + g.rng.IntN(20))
+		}
+		return strconv.Itoa(g.rng.IntN(1000))
+	case kindDecimal:
+		if hasWord(raw, "lat", "latitude") {
+			return strconv.FormatFloat(g.rng.Float64()*180-90, 'f', 6, 64)
+		}
+		if hasWord(raw, "lon", "lng", "long", "longitude") {
+			return strconv.FormatFloat(g.rng.Float64()*360-180, 'f', 6, 64)
+		}
+		return strconv.FormatFloat(float64(g.rng.IntN(100000))/100, 'f', 2, 64)
+	case kindDate:
+		return g.when().Format("2006-01-02")
+	case kindTime:
+		return g.when().Format("15:04:05")
+	case kindTimestamp:
+		return g.when().Format("2006-01-02 15:04:05")
+	case kindUUID:
+		return g.uuid()
+	case kindJSON:
+		return fmt.Sprintf(`{"id": %d, "tag": %q}`, g.row, g.pick(loremWords))
+	}
+	return g.fit(g.text(raw), g.sizes[i])
+}
+
+// text makes up the value of a text column named raw.
+func (g *Generator) text(raw string) string {
+	name := strings.ToLower(raw)
+	first, last := g.pick(firstNames), g.pick(lastNames)
+	switch {
+	case strings.Contains(name, "mail"):
+		return fmt.Sprintf("%s.%s%d@example.com", strings.ToLower(first), strings.ToLower(last), g.row)
+	case strings.Contains(name, "first"), strings.Contains(name, "given"):
+		return first
+	case strings.Contains(name, "last"), strings.Contains(name, "surname"), strings.Contains(name, "family"):
+		return last
+	case strings.Contains(name, "user"), strings.Contains(name, "login"), strings.Contains(name, "handle"):
+		return fmt.Sprintf("%s%s%d", strings.ToLower(first[:1]), strings.ToLower(last), g.row)
+	case strings.Contains(name, "phone"), strings.Contains(name, "mobile"), strings.Contains(name, "fax"):
+		return fmt.Sprintf("+1-555-%03d-%04d", g.rng.IntN(1000), g.rng.IntN(10000))
+	case strings.Contains(name, "url"), strings.Contains(name, "website"), strings.Contains(name, "link"):
+		return fmt.Sprintf("https://example.com/%s/%d", g.pick(loremWords), g.row)
+	case strings.Contains(name, "city"), strings.Contains(name, "town"):
+		return g.pick(cities)
+	case strings.Contains(name, "country"):
+		return g.pick(countries)
+	case strings.Contains(name, "street"), strings.Contains(name, "address"):
+		return fmt.Sprintf("%d %s Street", 1+g.rng.IntN(999), g.pick(lastNames))
+	case strings.Contains(name, "zip"), strings.Contains(name, "postal"), strings.Contains(name, "postcode"):
+		return fmt.Sprintf("%05d", g.rng.IntN(100000))
+	case strings.Contains(name, "company"), hasWord(raw, "org", "organization"):
+		return last + " " + g.pick(companySuffixes)
+	case strings.Contains(name, "status"), hasWord(raw, "state"):
+		return g.pick(statuses)
+	case strings.Contains(name, "color"), strings.Contains(name, "colour"):
+		return g.pick(colors)
+	case hasWord(raw, "code", "sku", "ref", "reference"):
+		return fmt.Sprintf("%s-%05d", strings.ToUpper(g.pick(codeWords)[:3]), g.row)
+	case strings.Contains(name, "uuid"), strings.Contains(name, "guid"):
+		return g.uuid()
+	case strings.Contains(name, "name"):
+		return first + " " + last
+	case strings.Contains(name, "title"), strings.Contains(name, "subject"), strings.Contains(name, "label"):
+		return g.sentence(3 + g.rng.IntN(4))
+	case strings.Contains(name, "desc"), strings.Contains(name, "body"), strings.Contains(name, "content"),
+		hasWord(raw, "text"), strings.Contains(name, "comment"), strings.Contains(name, "note"),
+		strings.Contains(name, "bio"), strings.Contains(name, "summary"), strings.Contains(name, "message"):
+		return g.sentence(8+g.rng.IntN(12)) + " " + g.sentence(8+g.rng.IntN(12))
+	}
+	return g.sentence(2 + g.rng.IntN(4))
+}
+
+// hasWord returns whether one of the words of name, split at underscores,
+// dashes, spaces and lower to upper case changes, is one of words.
+func hasWord(name string, words ...string) bool {
+	for _, w := range nameWords.FindAllString(name, -1) {
+		for _, word := range words {
+			if strings.EqualFold(w, word) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nameWords finds the words of a column name.
+var nameWords = regexp.MustCompile(`[A-Z]?[a-z0-9]+|[A-Z]+`)
+
+// when makes up a time in the last two years.
+func (g *Generator) when() time.Time {
+	return g.now.Add(-time.Duration(g.rng.Int64N(int64(2 * 365 * 24 * time.Hour))))
+}
+
+// uuid makes up a version 4 UUID.
+func (g *Generator) uuid() string {
+	var b [16]byte
+	for i := range b {
+		b[i] = byte(g.rng.IntN(256))
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// sentence makes up a sentence of n words of lorem ipsum.
+func (g *Generator) sentence(n int) string {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = g.pick(loremWords)
+	}
+	words[0] = strings.ToUpper(words[0][:1]) + words[0][1:]
+	return strings.Join(words, " ") + "."
+}
+
+// fit cuts s to the size of its column, if it has one.
+func (g *Generator) fit(s string, size int) string {
+	if size > 0 && len(s) > size {
+		return s[:size]
+	}
+	return s
+}
+
+func (g *Generator) pick(list []string) string {
+	return list[g.rng.IntN(len(list))]
+}
+
+var (
+	firstNames = []string{"Ada", "Alan", "Anna", "Ben", "Carla", "David", "Elena", "Frank", "Grace", "Hugo",
+		"Ines", "James", "Kim", "Linus", "Maria", "Noah", "Olga", "Paul", "Rosa", "Sam", "Tara", "Victor", "Wendy", "Yuki"}
+	lastNames = []string{"Adams", "Baker", "Chen", "Diaz", "Evans", "Fischer", "Garcia", "Hopper", "Ito", "Jones",
+		"Kowalski", "Lovelace", "Martin", "Nakamura", "Olsen", "Patel", "Quinn", "Rossi", "Smith", "Turing", "Weber", "Young"}
+	cities          = []string{"Amsterdam", "Berlin", "Boston", "Lisbon", "London", "Madrid", "Oslo", "Paris", "Seoul", "Tokyo", "Toronto", "Vienna"}
+	countries       = []string{"Canada", "France", "Germany", "Japan", "Netherlands", "Norway", "Portugal", "Spain", "United Kingdom", "United States"}
+	companySuffixes = []string{"Inc", "LLC", "Ltd", "GmbH", "Group", "Labs"}
+	statuses        = []string{"active", "inactive", "pending", "archived"}
+	colors          = []string{"red", "green", "blue", "black", "white", "orange", "purple"}
+	loremWords      = strings.Fields(`lorem ipsum dolor sit amet consectetur adipiscing elit sed do eiusmod
+		tempor incididunt ut labore et dolore magna aliqua enim ad minim veniam quis nostrud exercitation
+		ullamco laboris nisi aliquip ex ea commodo consequat duis aute irure in reprehenderit voluptate velit
+		esse cillum fugiat nulla pariatur excepteur sint occaecat cupidatat non proident sunt culpa qui officia
+		deserunt mollit anim id est laborum`)
+)
+
+// codeWords are the lorem words long enough to start a code such as
+// DOL-00042.
+var codeWords = func() []string {
+	var words []string
+	for _, w := range loremWords {
+		if len(w) >= 3 {
+			words = append(words, w)
+		}
+	}
+	return words
+}()
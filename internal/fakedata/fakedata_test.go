@@ -0,0 +1,98 @@
+package fakedata
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+func TestRow(t *testing.T) {
+	columns := []schema.Column{
+		{Name: "id", Type: "integer", IsPK: true},
+		{Name: "email", Type: "varchar(255)"},
+		{Name: "firstName", Type: "text"},
+		{Name: "country_code", Type: "char(2)"},
+		{Name: "active", Type: "boolean"},
+		{Name: "created_at", Type: "timestamp"},
+		{Name: "page_count", Type: "integer"},
+		{Name: "team_id", Type: "integer"},
+		{Name: "bio", Type: "text", Nullable: true},
+		{Name: "token", Type: "uuid"},
+	}
+	teams := Reference{Columns: []int{7}, Samples: [][]string{{"10"}, {"20"}}}
+	g := New(columns, []Reference{teams}, 1)
+	g.Count(0, 101)
+
+	checks := map[string]*regexp.Regexp{
+		"email":      regexp.MustCompile(`^[a-z]+\.[a-z]+\d+@example\.com$`),
+		"firstName":  regexp.MustCompile(`^[A-Z][a-z]+$`),
+		"active":     regexp.MustCompile(`^(true|false)$`),
+		"created_at": regexp.MustCompile(`^\d{4}-\d\d-\d\d \d\d:\d\d:\d\d$`),
+		"team_id":    regexp.MustCompile(`^(10|20)$`),
+		"token":      regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`),
+	}
+	emails := map[string]bool{}
+	nulls := 0
+	for n := range 200 {
+		row := g.Row()
+		if len(row) != len(columns) {
+			t.Fatalf("row %v has %d values, want %d", row, len(row), len(columns))
+		}
+		if row[0] != strconv.Itoa(101+n) {
+			t.Errorf("id = %s, want %d", row[0], 101+n)
+		}
+		for i, c := range columns {
+			if re := checks[c.Name]; re != nil && !re.MatchString(row[i]) {
+				t.Errorf("%s = %q, want it to match %s", c.Name, row[i], re)
+			}
+		}
+		if len(row[3]) != 2 {
+			t.Errorf("country_code = %q, longer than char(2) takes", row[3])
+		}
+		if v, err := strconv.Atoi(row[6]); err != nil || v < 0 {
+			t.Errorf("page_count = %q, want a number", row[6])
+		}
+		if adapter.IsNull(row[8]) {
+			nulls++
+		} else if !strings.HasSuffix(row[8], ".") {
+			t.Errorf("bio = %q, want lorem ipsum", row[8])
+		}
+		emails[row[1]] = true
+	}
+	if len(emails) != 200 {
+		t.Errorf("%d distinct emails in 200 rows, want each apart", len(emails))
+	}
+	if nulls == 0 || nulls > 60 {
+		t.Errorf("bio was NULL %d times in 200 rows, want about a tenth", nulls)
+	}
+}
+
+func TestRow_Codes(t *testing.T) {
+	g := New([]schema.Column{{Name: "sku", Type: "varchar(20)"}}, nil, 1)
+	code := regexp.MustCompile(`^[A-Z]{3}-\d{5}$`)
+	for range 2000 {
+		if row := g.Row(); !code.MatchString(row[0]) {
+			t.Fatalf("sku = %q, want a code like DOL-00042", row[0])
+		}
+	}
+}
+
+func TestRow_EmptyReference(t *testing.T) {
+	g := New([]schema.Column{{Name: "team_id", Type: "integer", Nullable: true}},
+		[]Reference{{Columns: []int{0}}}, 1)
+	if row := g.Row(); !adapter.IsNull(row[0]) {
+		t.Errorf("a reference with no rows to pick should be NULL, got %q", row[0])
+	}
+}
+
+func TestHasWord(t *testing.T) {
+	for name, want := range map[string]bool{"age": true, "user_age": true, "userAge": true, "page_count": false, "usage": false} {
+		if got := hasWord(name, "age"); got != want {
+			t.Errorf("hasWord(%q, age) = %v, want %v", name, got, want)
+		}
+	}
+}
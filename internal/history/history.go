@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	_ "modernc.org/sqlite"
 
@@ -20,9 +22,95 @@ const createTableSQL = `CREATE TABLE IF NOT EXISTS history (
 	executed_at  DATETIME DEFAULT CURRENT_TIMESTAMP,
 	duration_ms  INTEGER,
 	row_count    INTEGER,
-	is_error     BOOLEAN DEFAULT FALSE
+	is_error     BOOLEAN DEFAULT FALSE,
+	error_message TEXT
 )`
 
+// searchSQL creates the full-text index over the history table and the
+// triggers keeping it in step. The trigram tokenizer matches any substring
+// of three or more characters, like the LIKE search it speeds up.
+const searchSQL = `
+CREATE INDEX IF NOT EXISTS history_executed_at ON history (executed_at);
+CREATE INDEX IF NOT EXISTS history_database_name ON history (database_name);
+CREATE VIRTUAL TABLE IF NOT EXISTS history_fts USING fts5 (
+	query, database_name, adapter,
+	content = 'history', content_rowid = 'id', tokenize = 'trigram'
+);
+CREATE TRIGGER IF NOT EXISTS history_fts_insert AFTER INSERT ON history BEGIN
+	INSERT INTO history_fts (rowid, query, database_name, adapter)
+	VALUES (new.id, new.query, new.database_name, new.adapter);
+END;
+CREATE TRIGGER IF NOT EXISTS history_fts_delete AFTER DELETE ON history BEGIN
+	INSERT INTO history_fts (history_fts, rowid, query, database_name, adapter)
+	VALUES ('delete', old.id, old.query, old.database_name, old.adapter);
+END;
+CREATE TRIGGER IF NOT EXISTS history_fts_update AFTER UPDATE OF query, database_name, adapter ON history BEGIN
+	INSERT INTO history_fts (history_fts, rowid, query, database_name, adapter)
+	VALUES ('delete', old.id, old.query, old.database_name, old.adapter);
+	INSERT INTO history_fts (rowid, query, database_name, adapter)
+	VALUES (new.id, new.query, new.database_name, new.adapter);
+END;`
+
+// minSearchTerm is the shortest term, in characters, the trigram index can
+// find; shorter terms are matched with LIKE.
+const minSearchTerm = 3
+
+// migrate brings a history table created by an older version up to date:
+// it adds missing columns and builds the search index over the entries
+// already stored.
+func migrate(db *sql.DB) error {
+	if err := addColumns(db); err != nil {
+		return err
+	}
+	var indexed int
+	if err := db.QueryRow(
+		`SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = 'history_fts'`,
+	).Scan(&indexed); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck // a no-op after Commit
+	if _, err := tx.Exec(searchSQL); err != nil {
+		return err
+	}
+	if indexed == 0 {
+		if _, err := tx.Exec(`INSERT INTO history_fts (history_fts) VALUES ('rebuild')`); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// addColumns adds the columns newer versions expect to the history table.
+func addColumns(db *sql.DB) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info('history')`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	has := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		has[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if !has["error_message"] {
+		if _, err := db.Exec(`ALTER TABLE history ADD COLUMN error_message TEXT`); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // HistoryEntry represents a single executed query in the history log.
 type HistoryEntry struct {
 	ID           int64
@@ -33,11 +121,20 @@ type HistoryEntry struct {
 	DurationMS   int64
 	RowCount     int64
 	IsError      bool
+	ErrorMessage string // why the query failed, when IsError
 }
 
 // History provides SQLite-backed query history storage.
 type History struct {
-	db *sql.DB
+	db     *sql.DB
+	policy Policy
+}
+
+// Policy limits how much history is kept. The zero Policy keeps everything.
+type Policy struct {
+	MaxEntries int           // newest entries kept; 0 = no limit
+	MaxAge     time.Duration // entries older than this are dropped; 0 = no limit
+	Dedupe     bool          // rerunning the last query updates its entry instead of adding one
 }
 
 // New opens (or creates) the history database at ConfigDir()/history.db and
@@ -61,15 +158,44 @@ func New() (*History, error) {
 		db.Close()
 		return nil, fmt.Errorf("history: create table: %w", err)
 	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("history: migrate: %w", err)
+	}
 
 	return &History{db: db}, nil
 }
 
-// Add inserts a new history entry.
+// SetPolicy sets the retention policy and prunes the entries it no longer
+// keeps.
+func (h *History) SetPolicy(p Policy) error {
+	h.policy = p
+	return h.prune()
+}
+
+// Add inserts a new history entry. Under a Dedupe policy, an entry for the
+// same query, adapter and database as the most recent one replaces it.
 func (h *History) Add(entry HistoryEntry) error {
+	if h.policy.Dedupe {
+		res, err := h.db.Exec(
+			`UPDATE history
+			 SET executed_at = ?, duration_ms = ?, row_count = ?, is_error = ?, error_message = ?
+			 WHERE id = (SELECT max(id) FROM history)
+			   AND query = ? AND adapter = ? AND database_name = ?`,
+			entry.ExecutedAt, entry.DurationMS, entry.RowCount, entry.IsError, entry.ErrorMessage,
+			entry.Query, entry.Adapter, entry.DatabaseName,
+		)
+		if err != nil {
+			return fmt.Errorf("history add: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			return nil
+		}
+	}
+
 	_, err := h.db.Exec(
-		`INSERT INTO history (query, adapter, database_name, executed_at, duration_ms, row_count, is_error)
-		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO history (query, adapter, database_name, executed_at, duration_ms, row_count, is_error, error_message)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
 		entry.Query,
 		entry.Adapter,
 		entry.DatabaseName,
@@ -77,10 +203,32 @@ func (h *History) Add(entry HistoryEntry) error {
 		entry.DurationMS,
 		entry.RowCount,
 		entry.IsError,
+		entry.ErrorMessage,
 	)
 	if err != nil {
 		return fmt.Errorf("history add: %w", err)
 	}
+	return h.prune()
+}
+
+// prune deletes the entries the policy does not keep.
+func (h *History) prune() error {
+	if h.policy.MaxEntries > 0 {
+		if _, err := h.db.Exec(
+			`DELETE FROM history WHERE id <= (SELECT id FROM history ORDER BY id DESC LIMIT 1 OFFSET ?)`,
+			h.policy.MaxEntries,
+		); err != nil {
+			return fmt.Errorf("history prune: %w", err)
+		}
+	}
+	if h.policy.MaxAge > 0 {
+		if _, err := h.db.Exec(
+			`DELETE FROM history WHERE executed_at < ?`,
+			time.Now().Add(-h.policy.MaxAge),
+		); err != nil {
+			return fmt.Errorf("history prune: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -89,7 +237,7 @@ func (h *History) Add(entry HistoryEntry) error {
 // rows.
 func (h *History) Search(pattern string, limit int) ([]HistoryEntry, error) {
 	rows, err := h.db.Query(
-		`SELECT id, query, adapter, database_name, executed_at, duration_ms, row_count, is_error
+		`SELECT id, query, adapter, database_name, executed_at, duration_ms, row_count, is_error, error_message
 		 FROM history
 		 WHERE query LIKE ?
 		 ORDER BY executed_at DESC
@@ -104,10 +252,146 @@ func (h *History) Search(pattern string, limit int) ([]HistoryEntry, error) {
 	return scanEntries(rows)
 }
 
+// Filter selects history entries. Every term must appear, case-insensitively,
+// in the query text, database name or adapter of an entry.
+type Filter struct {
+	Terms    []string
+	Database string // substring of the database name
+	Adapter  string // adapter name
+	Error    *bool  // only failed (true) or successful (false) queries
+	Scope    *Scope // only queries run on this adapter and database
+}
+
+// Scope names the adapter and exact database name queries were run on.
+type Scope struct {
+	Adapter  string
+	Database string
+}
+
+// ParseFilter parses a search typed in the history browser. Words are
+// terms, "quoted text" is one term, and error:true, error:false, db:NAME
+// and adapter:NAME narrow the search. Any other word with a colon, such as
+// "::text", is a term.
+func ParseFilter(s string) Filter {
+	var f Filter
+	for _, tok := range tokenize(s) {
+		key, value, ok := strings.Cut(tok, ":")
+		if ok && value != "" {
+			switch strings.ToLower(key) {
+			case "error", "err":
+				switch strings.ToLower(value) {
+				case "true", "yes", "1":
+					v := true
+					f.Error = &v
+					continue
+				case "false", "no", "0":
+					v := false
+					f.Error = &v
+					continue
+				}
+			case "db", "database":
+				f.Database = value
+				continue
+			case "adapter":
+				f.Adapter = value
+				continue
+			}
+		}
+		f.Terms = append(f.Terms, tok)
+	}
+	return f
+}
+
+// tokenize splits s on whitespace, keeping "quoted text" together.
+func tokenize(s string) []string {
+	var toks []string
+	for {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return toks
+		}
+		if s[0] == '"' {
+			if end := strings.IndexByte(s[1:], '"'); end >= 0 {
+				if tok := s[1 : end+1]; tok != "" {
+					toks = append(toks, tok)
+				}
+				s = s[end+2:]
+				continue
+			}
+			s = s[1:] // unclosed quote: take the rest as words
+			continue
+		}
+		end := strings.IndexAny(s, " \t\n")
+		if end < 0 {
+			end = len(s)
+		}
+		toks = append(toks, s[:end])
+		s = s[end:]
+	}
+}
+
+// Find returns the history entries matching f, most recent first, limited
+// to limit rows. Terms are looked up in the full-text index.
+func (h *History) Find(f Filter, limit int) ([]HistoryEntry, error) {
+	var where []string
+	var args []any
+	var phrases []string
+	for _, term := range f.Terms {
+		if utf8.RuneCountInString(term) >= minSearchTerm {
+			phrases = append(phrases, `"`+strings.ReplaceAll(term, `"`, `""`)+`"`)
+			continue
+		}
+		like := "%" + escapeLike(term) + "%"
+		where = append(where, `(query LIKE ? ESCAPE '\' OR database_name LIKE ? ESCAPE '\' OR adapter LIKE ? ESCAPE '\')`)
+		args = append(args, like, like, like)
+	}
+	if len(phrases) > 0 {
+		where = append(where, `id IN (SELECT rowid FROM history_fts WHERE history_fts MATCH ?)`)
+		args = append(args, strings.Join(phrases, " AND "))
+	}
+	if f.Database != "" {
+		where = append(where, `database_name LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escapeLike(f.Database)+"%")
+	}
+	if f.Adapter != "" {
+		where = append(where, `adapter = ? COLLATE NOCASE`)
+		args = append(args, f.Adapter)
+	}
+	if f.Scope != nil {
+		where = append(where, `adapter = ? COLLATE NOCASE AND database_name = ?`)
+		args = append(args, f.Scope.Adapter, f.Scope.Database)
+	}
+	if f.Error != nil {
+		where = append(where, `is_error = ?`)
+		args = append(args, *f.Error)
+	}
+
+	query := `SELECT id, query, adapter, database_name, executed_at, duration_ms, row_count, is_error, error_message
+		 FROM history`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY executed_at DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := h.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("history find: %w", err)
+	}
+	defer rows.Close()
+
+	return scanEntries(rows)
+}
+
+// escapeLike escapes the LIKE wildcards in s, for use with ESCAPE '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
 // Recent returns the most recent history entries, limited to limit rows.
 func (h *History) Recent(limit int) ([]HistoryEntry, error) {
 	rows, err := h.db.Query(
-		`SELECT id, query, adapter, database_name, executed_at, duration_ms, row_count, is_error
+		`SELECT id, query, adapter, database_name, executed_at, duration_ms, row_count, is_error, error_message
 		 FROM history
 		 ORDER BY executed_at DESC
 		 LIMIT ?`,
@@ -129,6 +413,19 @@ func (h *History) Clear() error {
 	return nil
 }
 
+// ClearScope deletes the entries run on the scope's adapter and database,
+// returning how many were deleted.
+func (h *History) ClearScope(scope Scope) (int64, error) {
+	res, err := h.db.Exec(
+		`DELETE FROM history WHERE adapter = ? COLLATE NOCASE AND database_name = ?`,
+		scope.Adapter, scope.Database,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("history clear: %w", err)
+	}
+	return res.RowsAffected()
+}
+
 // Close closes the underlying database connection.
 func (h *History) Close() error {
 	return h.db.Close()
@@ -139,6 +436,7 @@ func scanEntries(rows *sql.Rows) ([]HistoryEntry, error) {
 	var entries []HistoryEntry
 	for rows.Next() {
 		var e HistoryEntry
+		var errMsg sql.NullString // NULL in entries from before the column existed
 		if err := rows.Scan(
 			&e.ID,
 			&e.Query,
@@ -148,9 +446,11 @@ func scanEntries(rows *sql.Rows) ([]HistoryEntry, error) {
 			&e.DurationMS,
 			&e.RowCount,
 			&e.IsError,
+			&errMsg,
 		); err != nil {
 			return nil, fmt.Errorf("history scan: %w", err)
 		}
+		e.ErrorMessage = errMsg.String
 		entries = append(entries, e)
 	}
 	if err := rows.Err(); err != nil {
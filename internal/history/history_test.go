@@ -2,8 +2,10 @@ package history
 
 import (
 	"database/sql"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -25,6 +27,10 @@ func newTestHistory(t *testing.T, dir string) *History {
 		db.Close()
 		t.Fatalf("create table: %v", err)
 	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		t.Fatalf("migrate: %v", err)
+	}
 
 	return &History{db: db}
 }
@@ -53,6 +59,89 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestMigrate_AddsErrorMessage(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	// The table as created before error messages were kept.
+	if _, err := db.Exec(`CREATE TABLE history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT, query TEXT NOT NULL, adapter TEXT, database_name TEXT,
+		executed_at DATETIME DEFAULT CURRENT_TIMESTAMP, duration_ms INTEGER, row_count INTEGER,
+		is_error BOOLEAN DEFAULT FALSE)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO history (query, adapter, database_name, executed_at, duration_ms, row_count, is_error)
+		VALUES ('SELECT 1', 'sqlite', 'old.db', ?, 1, 1, 0)`, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 { // a second run finds the column in place
+		if err := migrate(db); err != nil {
+			t.Fatalf("migrate: %v", err)
+		}
+	}
+
+	h := &History{db: db}
+	if err := h.Add(HistoryEntry{Query: "SELECT x", ExecutedAt: time.Now(), IsError: true, ErrorMessage: "no such column: x"}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := h.Recent(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].ErrorMessage != "no such column: x" || entries[1].ErrorMessage != "" {
+		t.Errorf("entries = %+v", entries)
+	}
+
+	// Entries from before the search index existed are indexed.
+	found, err := h.Find(ParseFilter("old.db"), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Query != "SELECT 1" {
+		t.Errorf("Find(old.db) = %+v", found)
+	}
+}
+
+func TestFind_IndexFollowsChanges(t *testing.T) {
+	h := newTestHistory(t, t.TempDir())
+	defer h.Close()
+	if err := h.SetPolicy(Policy{Dedupe: true}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i, q := range []string{"SELECT name FROM customers", "SELECT name FROM customers", `SELECT "weird" FROM t`} {
+		if err := h.Add(HistoryEntry{Query: q, Adapter: "sqlite", DatabaseName: "app.db", ExecutedAt: now.Add(time.Duration(i) * time.Second)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	count := func(search string) int {
+		t.Helper()
+		got, err := h.Find(ParseFilter(search), 100)
+		if err != nil {
+			t.Fatalf("Find(%q): %v", search, err)
+		}
+		return len(got)
+	}
+	if n := count("customers"); n != 1 {
+		t.Errorf("Find(customers) after a deduped rerun = %d entries, want 1", n)
+	}
+	if n := count(`"weird"`); n != 1 {
+		t.Errorf("Find with a quote in the term = %d entries, want 1", n)
+	}
+	if n := count("ustom NAME"); n != 1 {
+		t.Errorf("Find(ustom NAME) = %d entries, want 1", n)
+	}
+
+	if _, err := h.ClearScope(Scope{Adapter: "sqlite", Database: "app.db"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := count("customers"); n != 0 {
+		t.Errorf("Find(customers) after clearing = %d entries, want 0", n)
+	}
+}
+
 func TestAddAndRecent(t *testing.T) {
 	h := newTestHistory(t, t.TempDir())
 	defer h.Close()
@@ -456,3 +545,183 @@ func TestNewCreatesDBFile(t *testing.T) {
 		t.Error("history.db file was not created in any expected config dir location")
 	}
 }
+
+func TestParseFilter(t *testing.T) {
+	f := ParseFilter(`users error:true db:orders adapter:postgres "order by" x::text`)
+	if strings.Join(f.Terms, "|") != "users|order by|x::text" {
+		t.Errorf("Terms = %q", f.Terms)
+	}
+	if f.Database != "orders" || f.Adapter != "postgres" || f.Error == nil || !*f.Error {
+		t.Errorf("Filter = %+v", f)
+	}
+	if f := ParseFilter("error:no"); f.Error == nil || *f.Error {
+		t.Errorf("error:no = %v, want false", f.Error)
+	}
+}
+
+func TestFind(t *testing.T) {
+	h := newTestHistory(t, t.TempDir())
+	defer h.Close()
+
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	entries := []HistoryEntry{
+		{Query: "SELECT * FROM users", Adapter: "postgres", DatabaseName: "shop"},
+		{Query: "SELECT * FROM orders", Adapter: "postgres", DatabaseName: "shop", IsError: true},
+		{Query: "SELECT 100%", Adapter: "mysql", DatabaseName: "orders_archive"},
+		{Query: "DELETE FROM users", Adapter: "sqlite", DatabaseName: "local.db"},
+	}
+	for i, e := range entries {
+		e.ExecutedAt = base.Add(time.Duration(i) * time.Minute)
+		if err := h.Add(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		search string
+		want   []string
+	}{
+		{"users", []string{"DELETE FROM users", "SELECT * FROM users"}},
+		{"USERS delete", []string{"DELETE FROM users"}},
+		{"error:true", []string{"SELECT * FROM orders"}},
+		{"error:false postgres", []string{"SELECT * FROM users"}},
+		{"db:orders", []string{"SELECT 100%"}},
+		{"orders", []string{"SELECT 100%", "SELECT * FROM orders"}},
+		{"adapter:SQLITE", []string{"DELETE FROM users"}},
+		{"0%", []string{"SELECT 100%"}},
+		{"local.db", []string{"DELETE FROM users"}},
+	}
+	for _, tt := range tests {
+		got, err := h.Find(ParseFilter(tt.search), 10)
+		if err != nil {
+			t.Fatalf("Find(%q) error = %v", tt.search, err)
+		}
+		var queries []string
+		for _, e := range got {
+			queries = append(queries, e.Query)
+		}
+		if strings.Join(queries, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("Find(%q) = %q, want %q", tt.search, queries, tt.want)
+		}
+	}
+
+	// A scope matches the database name exactly, not as a substring.
+	f := ParseFilter("select")
+	f.Scope = &Scope{Adapter: "postgres", Database: "shop"}
+	got, err := h.Find(f, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Query != "SELECT * FROM orders" || got[1].Query != "SELECT * FROM users" {
+		t.Errorf("Find with scope = %+v", got)
+	}
+	f.Scope = &Scope{Adapter: "postgres", Database: "sho"}
+	if got, _ := h.Find(f, 10); len(got) != 0 {
+		t.Errorf("Find with a partial database name = %+v, want none", got)
+	}
+}
+
+func TestPolicy_Dedupe(t *testing.T) {
+	h := newTestHistory(t, t.TempDir())
+	defer h.Close()
+	if err := h.SetPolicy(Policy{Dedupe: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Now().Add(-time.Hour)
+	add := func(query, db string, minute int) {
+		t.Helper()
+		err := h.Add(HistoryEntry{
+			Query: query, Adapter: "postgres", DatabaseName: db,
+			ExecutedAt: base.Add(time.Duration(minute) * time.Minute), RowCount: int64(minute),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	add("SELECT 1", "shop", 0)
+	add("SELECT 1", "shop", 1) // rerun: updates the entry
+	add("SELECT 1", "other", 2)
+	add("SELECT 2", "other", 3)
+	add("SELECT 1", "other", 4) // not consecutive
+
+	entries, err := h.Recent(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Query+"@"+e.DatabaseName)
+	}
+	want := "SELECT 1@other|SELECT 2@other|SELECT 1@other|SELECT 1@shop"
+	if strings.Join(got, "|") != want {
+		t.Fatalf("entries = %q, want %q", got, want)
+	}
+	if entries[3].RowCount != 1 {
+		t.Errorf("deduplicated entry RowCount = %d, want the rerun's 1", entries[3].RowCount)
+	}
+}
+
+func TestPolicy_MaxEntriesAndAge(t *testing.T) {
+	h := newTestHistory(t, t.TempDir())
+	defer h.Close()
+
+	now := time.Now()
+	for i := range 6 {
+		err := h.Add(HistoryEntry{
+			Query:      fmt.Sprintf("SELECT %d", i),
+			ExecutedAt: now.Add(time.Duration(i-5) * 24 * time.Hour),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Entries from 5 and 4 days ago are older than the limit.
+	if err := h.SetPolicy(Policy{MaxAge: 84 * time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := h.Recent(10); len(entries) != 4 {
+		t.Fatalf("after MaxAge: %d entries, want 4", len(entries))
+	}
+
+	if err := h.SetPolicy(Policy{MaxEntries: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Add(HistoryEntry{Query: "SELECT 6", ExecutedAt: now.Add(time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := h.Recent(10)
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Query)
+	}
+	if strings.Join(got, "|") != "SELECT 6|SELECT 5|SELECT 4" {
+		t.Fatalf("after MaxEntries: %q", got)
+	}
+}
+
+func TestClearScope(t *testing.T) {
+	h := newTestHistory(t, t.TempDir())
+	defer h.Close()
+	for _, e := range []HistoryEntry{
+		{Query: "SELECT 1", Adapter: "postgres", DatabaseName: "shop"},
+		{Query: "SELECT 2", Adapter: "postgres", DatabaseName: "shop"},
+		{Query: "SELECT 3", Adapter: "sqlite", DatabaseName: "shop"},
+	} {
+		e.ExecutedAt = time.Now()
+		if err := h.Add(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n, err := h.ClearScope(Scope{Adapter: "postgres", Database: "shop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("ClearScope deleted %d, want 2", n)
+	}
+	if entries, _ := h.Recent(10); len(entries) != 1 || entries[0].Adapter != "sqlite" {
+		t.Errorf("remaining = %+v", entries)
+	}
+}
@@ -0,0 +1,176 @@
+package history
+
+import (
+	"fmt"
+	"time"
+)
+
+// statsTop is how many queries and connections each part of a Summary
+// lists.
+const statsTop = 10
+
+// QueryCount is a query with how often it was run.
+type QueryCount struct {
+	Query  string
+	Count  int
+	MeanMS int64 // mean duration
+}
+
+// ConnectionStats counts the queries run on one adapter and database.
+type ConnectionStats struct {
+	Adapter  string
+	Database string
+	Queries  int
+	Errors   int
+}
+
+// ErrorRate returns the fraction of the queries that failed.
+func (c ConnectionStats) ErrorRate() float64 {
+	if c.Queries == 0 {
+		return 0
+	}
+	return float64(c.Errors) / float64(c.Queries)
+}
+
+// DayCount is the number of queries run on one day.
+type DayCount struct {
+	Day   time.Time // local midnight
+	Count int
+}
+
+// Summary describes the history: what runs most, what runs slowest, how
+// often each connection fails and how many queries run each day.
+type Summary struct {
+	Total       int
+	Errors      int
+	Frequent    []QueryCount      // most run first
+	Slowest     []HistoryEntry    // successful queries, slowest first
+	Connections []ConnectionStats // busiest first
+	Daily       []DayCount        // oldest first, ending today
+}
+
+// Summarize summarizes the entries of scope, or of every connection when
+// scope is nil, with the daily volume of the days days up to now.
+func (h *History) Summarize(scope *Scope, days int, now time.Time) (*Summary, error) {
+	where, args := "", []any(nil)
+	if scope != nil {
+		where = `adapter = ? COLLATE NOCASE AND database_name = ?`
+		args = []any{scope.Adapter, scope.Database}
+	}
+	clause := func(cond string) string {
+		switch {
+		case where == "" && cond == "":
+			return ""
+		case where == "":
+			return " WHERE " + cond
+		case cond == "":
+			return " WHERE " + where
+		}
+		return " WHERE " + where + " AND " + cond
+	}
+	s := &Summary{}
+
+	if err := h.db.QueryRow(
+		`SELECT count(*), coalesce(sum(is_error), 0) FROM history`+clause(""), args...,
+	).Scan(&s.Total, &s.Errors); err != nil {
+		return nil, fmt.Errorf("history stats: %w", err)
+	}
+
+	rows, err := h.db.Query(
+		`SELECT query, count(*), coalesce(avg(duration_ms), 0) FROM history`+clause("")+
+			` GROUP BY query ORDER BY count(*) DESC, max(id) DESC LIMIT ?`,
+		append(args, statsTop)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("history stats: %w", err)
+	}
+	for rows.Next() {
+		var q QueryCount
+		var mean float64
+		if err := rows.Scan(&q.Query, &q.Count, &mean); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("history stats: %w", err)
+		}
+		q.MeanMS = int64(mean)
+		s.Frequent = append(s.Frequent, q)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("history stats: %w", err)
+	}
+
+	rows, err = h.db.Query(
+		`SELECT id, query, adapter, database_name, executed_at, duration_ms, row_count, is_error, error_message
+		 FROM history`+clause("NOT is_error")+` ORDER BY duration_ms DESC, id DESC LIMIT ?`,
+		append(args, statsTop)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("history stats: %w", err)
+	}
+	s.Slowest, err = scanEntries(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err = h.db.Query(
+		`SELECT coalesce(adapter, ''), coalesce(database_name, ''), count(*), coalesce(sum(is_error), 0) FROM history`+clause("")+
+			` GROUP BY 1, 2 ORDER BY count(*) DESC LIMIT ?`,
+		append(args, statsTop)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("history stats: %w", err)
+	}
+	for rows.Next() {
+		var c ConnectionStats
+		if err := rows.Scan(&c.Adapter, &c.Database, &c.Queries, &c.Errors); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("history stats: %w", err)
+		}
+		s.Connections = append(s.Connections, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("history stats: %w", err)
+	}
+
+	if days > 0 {
+		if s.Daily, err = h.daily(clause("executed_at >= ?"), args, days, now); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// daily counts the queries matching where on each of the days days up to
+// now.
+func (h *History) daily(where string, args []any, days int, now time.Time) ([]DayCount, error) {
+	now = now.Local()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	first := today.AddDate(0, 0, -(days - 1))
+	counts := make([]DayCount, days)
+	index := make(map[string]int, days)
+	for i := range counts {
+		counts[i].Day = first.AddDate(0, 0, i)
+		index[counts[i].Day.Format(time.DateOnly)] = i
+	}
+
+	rows, err := h.db.Query(`SELECT executed_at FROM history`+where, append(args, first)...)
+	if err != nil {
+		return nil, fmt.Errorf("history stats: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var at time.Time
+		if err := rows.Scan(&at); err != nil {
+			return nil, fmt.Errorf("history stats: %w", err)
+		}
+		if i, ok := index[at.Local().Format(time.DateOnly)]; ok {
+			counts[i].Count++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("history stats: %w", err)
+	}
+	return counts, nil
+}
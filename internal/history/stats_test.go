@@ -0,0 +1,65 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarize(t *testing.T) {
+	h := newTestHistory(t, t.TempDir())
+	defer h.Close()
+	now := time.Date(2025, 6, 10, 15, 0, 0, 0, time.Local)
+	day := 24 * time.Hour
+	entries := []HistoryEntry{
+		{Query: "SELECT 1", Adapter: "postgres", DatabaseName: "shop", DurationMS: 5, ExecutedAt: now.Add(-2 * day)},
+		{Query: "SELECT 1", Adapter: "postgres", DatabaseName: "shop", DurationMS: 15, ExecutedAt: now.Add(-day)},
+		{Query: "SELECT 1", Adapter: "postgres", DatabaseName: "shop", DurationMS: 10, ExecutedAt: now},
+		{Query: "SELECT slow()", Adapter: "postgres", DatabaseName: "shop", DurationMS: 9000, ExecutedAt: now},
+		{Query: "SELECT broken", Adapter: "postgres", DatabaseName: "shop", DurationMS: 99999, IsError: true, ExecutedAt: now},
+		{Query: "SELECT 2", Adapter: "sqlite", DatabaseName: "a.db", DurationMS: 1, IsError: true, ExecutedAt: now.Add(-30 * day)},
+	}
+	for _, e := range entries {
+		if err := h.Add(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := h.Summarize(nil, 3, now)
+	if err != nil {
+		t.Fatalf("Summarize: %v", err)
+	}
+	if s.Total != 6 || s.Errors != 2 {
+		t.Errorf("Total, Errors = %d, %d; want 6, 2", s.Total, s.Errors)
+	}
+	if len(s.Frequent) != 4 || s.Frequent[0] != (QueryCount{Query: "SELECT 1", Count: 3, MeanMS: 10}) {
+		t.Errorf("Frequent = %+v", s.Frequent)
+	}
+	// Failed queries are not the slowest, however long they ran.
+	if len(s.Slowest) != 4 || s.Slowest[0].Query != "SELECT slow()" {
+		t.Errorf("Slowest = %+v", s.Slowest)
+	}
+	if len(s.Connections) != 2 || s.Connections[0] != (ConnectionStats{Adapter: "postgres", Database: "shop", Queries: 5, Errors: 1}) {
+		t.Errorf("Connections = %+v", s.Connections)
+	}
+	if rate := s.Connections[1].ErrorRate(); rate != 1 {
+		t.Errorf("sqlite error rate = %v, want 1", rate)
+	}
+	var counts []int
+	for _, d := range s.Daily {
+		counts = append(counts, d.Count)
+	}
+	if len(counts) != 3 || counts[0] != 1 || counts[1] != 1 || counts[2] != 3 {
+		t.Errorf("Daily = %v, want [1 1 3]", counts)
+	}
+	if !s.Daily[2].Day.Equal(time.Date(2025, 6, 10, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("last day = %v, want today", s.Daily[2].Day)
+	}
+
+	s, err = h.Summarize(&Scope{Adapter: "sqlite", Database: "a.db"}, 3, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Total != 1 || s.Errors != 1 || len(s.Slowest) != 0 || len(s.Connections) != 1 {
+		t.Errorf("scoped summary = %+v", s)
+	}
+}
@@ -0,0 +1,276 @@
+package history
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Export and import formats.
+const (
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+)
+
+// record is an entry as exported.
+type record struct {
+	ExecutedAt   time.Time `json:"executed_at"`
+	Adapter      string    `json:"adapter"`
+	DatabaseName string    `json:"database_name"`
+	Query        string    `json:"query"`
+	DurationMS   int64     `json:"duration_ms"`
+	RowCount     int64     `json:"row_count"`
+	IsError      bool      `json:"is_error"`
+	ErrorMessage string    `json:"error_message,omitempty"`
+}
+
+var csvHeader = []string{"executed_at", "adapter", "database_name", "query", "duration_ms", "row_count", "is_error", "error_message"}
+
+// FormatFor returns the format for a file path: CSV for .csv files and JSON
+// Lines otherwise.
+func FormatFor(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return FormatCSV
+	}
+	return FormatJSONL
+}
+
+// Export writes every entry to w, oldest first, as JSON Lines or CSV. It
+// returns the number of entries written.
+func (h *History) Export(w io.Writer, format string) (int, error) {
+	if format != FormatJSONL && format != FormatCSV {
+		return 0, fmt.Errorf("unknown history format %q (want jsonl or csv)", format)
+	}
+	entries, err := h.all()
+	if err != nil {
+		return 0, err
+	}
+
+	if format == FormatCSV {
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return 0, fmt.Errorf("history export: %w", err)
+		}
+		for _, e := range entries {
+			if err := cw.Write([]string{
+				e.ExecutedAt.Format(time.RFC3339Nano),
+				e.Adapter,
+				e.DatabaseName,
+				e.Query,
+				strconv.FormatInt(e.DurationMS, 10),
+				strconv.FormatInt(e.RowCount, 10),
+				strconv.FormatBool(e.IsError),
+				e.ErrorMessage,
+			}); err != nil {
+				return 0, fmt.Errorf("history export: %w", err)
+			}
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return 0, fmt.Errorf("history export: %w", err)
+		}
+		return len(entries), nil
+	}
+
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		if err := enc.Encode(record{
+			ExecutedAt:   e.ExecutedAt,
+			Adapter:      e.Adapter,
+			DatabaseName: e.DatabaseName,
+			Query:        e.Query,
+			DurationMS:   e.DurationMS,
+			RowCount:     e.RowCount,
+			IsError:      e.IsError,
+			ErrorMessage: e.ErrorMessage,
+		}); err != nil {
+			return 0, fmt.Errorf("history export: %w", err)
+		}
+	}
+	return len(entries), nil
+}
+
+// Import reads entries written by Export from r and adds them with their
+// original timestamps. Entries already in the history, with the same query,
+// adapter, database and time, are skipped, so importing a file twice adds
+// nothing. It returns the number of entries added.
+func (h *History) Import(r io.Reader, format string) (int, error) {
+	var records []record
+	var err error
+	switch format {
+	case FormatJSONL:
+		records, err = readJSONL(r)
+	case FormatCSV:
+		records, err = readCSV(r)
+	default:
+		return 0, fmt.Errorf("unknown history format %q (want jsonl or csv)", format)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	existing, err := h.all()
+	if err != nil {
+		return 0, err
+	}
+	seen := make(map[string]bool, len(existing))
+	for _, e := range existing {
+		seen[entryKey(e.Query, e.Adapter, e.DatabaseName, e.ExecutedAt)] = true
+	}
+
+	tx, err := h.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("history import: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck // a no-op after Commit
+
+	added := 0
+	for _, rec := range records {
+		key := entryKey(rec.Query, rec.Adapter, rec.DatabaseName, rec.ExecutedAt)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		// Stored times are read back by their zone name, which a parsed
+		// offset lacks; store them in the local zone like new entries.
+		_, err := tx.Exec(
+			`INSERT INTO history (query, adapter, database_name, executed_at, duration_ms, row_count, is_error, error_message)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			rec.Query, rec.Adapter, rec.DatabaseName, rec.ExecutedAt.Local(), rec.DurationMS, rec.RowCount, rec.IsError, rec.ErrorMessage,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("history import: %w", err)
+		}
+		added++
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("history import: %w", err)
+	}
+	return added, h.prune()
+}
+
+// all returns every entry, oldest first.
+func (h *History) all() ([]HistoryEntry, error) {
+	rows, err := h.db.Query(
+		`SELECT id, query, adapter, database_name, executed_at, duration_ms, row_count, is_error, error_message
+		 FROM history
+		 ORDER BY executed_at, id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("history read: %w", err)
+	}
+	defer rows.Close()
+	return scanEntries(rows)
+}
+
+// entryKey identifies an entry for skipping duplicates on import.
+func entryKey(query, adapter, database string, at time.Time) string {
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%d", query, adapter, database, at.UnixNano())
+}
+
+func readJSONL(r io.Reader) ([]record, error) {
+	var records []record
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		var rec record
+		if err := json.Unmarshal([]byte(text), &rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := checkRecord(rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, rec)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+	return records, nil
+}
+
+func readCSV(r io.Reader) ([]record, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+	col := make(map[string]int, len(header))
+	for i, name := range header {
+		col[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"executed_at", "query"} {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("CSV has no %s column", name)
+		}
+	}
+	field := func(row []string, name string) string {
+		if i, ok := col[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+
+	var records []record
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read history: %w", err)
+		}
+		rec := record{
+			Adapter:      field(row, "adapter"),
+			DatabaseName: field(row, "database_name"),
+			Query:        field(row, "query"),
+			ErrorMessage: field(row, "error_message"),
+		}
+		if rec.ExecutedAt, err = time.Parse(time.RFC3339Nano, field(row, "executed_at")); err != nil {
+			return nil, fmt.Errorf("line %d: executed_at: %w", line, err)
+		}
+		for _, f := range []struct {
+			name string
+			dst  *int64
+		}{{"duration_ms", &rec.DurationMS}, {"row_count", &rec.RowCount}} {
+			if v := field(row, f.name); v != "" {
+				if *f.dst, err = strconv.ParseInt(v, 10, 64); err != nil {
+					return nil, fmt.Errorf("line %d: %s: %w", line, f.name, err)
+				}
+			}
+		}
+		if v := field(row, "is_error"); v != "" {
+			if rec.IsError, err = strconv.ParseBool(v); err != nil {
+				return nil, fmt.Errorf("line %d: is_error: %w", line, err)
+			}
+		}
+		if err := checkRecord(rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, rec)
+	}
+}
+
+// checkRecord rejects records missing what an entry needs.
+func checkRecord(rec record) error {
+	switch {
+	case strings.TrimSpace(rec.Query) == "":
+		return errors.New("entry has no query")
+	case rec.ExecutedAt.IsZero():
+		return errors.New("entry has no executed_at time")
+	}
+	return nil
+}
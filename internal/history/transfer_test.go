@@ -0,0 +1,131 @@
+package history
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func seedTransfer(t *testing.T, h *History) []HistoryEntry {
+	t.Helper()
+	base := time.Date(2025, 3, 1, 9, 30, 0, 123456000, time.FixedZone("CET", 3600))
+	entries := []HistoryEntry{
+		{Query: "SELECT 1", Adapter: "postgres", DatabaseName: "shop", DurationMS: 12, RowCount: 1},
+		{Query: "SELECT *\nFROM \"odd, name\"", Adapter: "sqlite", DatabaseName: "a.db", DurationMS: 3, IsError: true, ErrorMessage: "no such table: odd, name"},
+	}
+	for i := range entries {
+		entries[i].ExecutedAt = base.Add(time.Duration(i) * time.Hour)
+		if err := h.Add(entries[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return entries
+}
+
+func TestExportImport_RoundTrip(t *testing.T) {
+	for _, format := range []string{FormatJSONL, FormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			src := newTestHistory(t, t.TempDir())
+			defer src.Close()
+			want := seedTransfer(t, src)
+
+			var buf bytes.Buffer
+			n, err := src.Export(&buf, format)
+			if err != nil {
+				t.Fatalf("Export: %v", err)
+			}
+			if n != 2 {
+				t.Fatalf("Export wrote %d entries, want 2", n)
+			}
+			data := buf.String()
+
+			dst := newTestHistory(t, t.TempDir())
+			defer dst.Close()
+			added, err := dst.Import(strings.NewReader(data), format)
+			if err != nil {
+				t.Fatalf("Import: %v", err)
+			}
+			if added != 2 {
+				t.Fatalf("Import added %d, want 2", added)
+			}
+
+			got, err := dst.Recent(10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 2 {
+				t.Fatalf("got %d entries, want 2", len(got))
+			}
+			for i, e := range []HistoryEntry{got[1], got[0]} {
+				w := want[i]
+				if e.Query != w.Query || e.Adapter != w.Adapter || e.DatabaseName != w.DatabaseName ||
+					e.DurationMS != w.DurationMS || e.RowCount != w.RowCount || e.IsError != w.IsError || e.ErrorMessage != w.ErrorMessage ||
+					!e.ExecutedAt.Equal(w.ExecutedAt) {
+					t.Errorf("entry %d = %+v, want %+v", i, e, w)
+				}
+			}
+
+			// Importing the same file again adds nothing.
+			added, err = dst.Import(strings.NewReader(data), format)
+			if err != nil {
+				t.Fatalf("second Import: %v", err)
+			}
+			if added != 0 {
+				t.Errorf("second Import added %d, want 0", added)
+			}
+		})
+	}
+}
+
+func TestImport_SkipsOwnEntries(t *testing.T) {
+	h := newTestHistory(t, t.TempDir())
+	defer h.Close()
+	if err := h.Add(HistoryEntry{Query: "SELECT 1", Adapter: "sqlite", ExecutedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := h.Export(&buf, FormatJSONL); err != nil {
+		t.Fatal(err)
+	}
+	added, err := h.Import(&buf, FormatJSONL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 0 {
+		t.Errorf("re-importing an export added %d entries, want 0", added)
+	}
+}
+
+func TestImport_Invalid(t *testing.T) {
+	h := newTestHistory(t, t.TempDir())
+	defer h.Close()
+	tests := []struct {
+		name, format, data string
+	}{
+		{"bad json", FormatJSONL, "{oops\n"},
+		{"no query", FormatJSONL, `{"executed_at":"2025-01-01T00:00:00Z"}` + "\n"},
+		{"no time", FormatJSONL, `{"query":"SELECT 1"}` + "\n"},
+		{"missing column", FormatCSV, "query\nSELECT 1\n"},
+		{"bad time", FormatCSV, "executed_at,query\nyesterday,SELECT 1\n"},
+		{"bad number", FormatCSV, "executed_at,query,row_count\n2025-01-01T00:00:00Z,SELECT 1,many\n"},
+		{"unknown format", "xml", ""},
+	}
+	for _, tt := range tests {
+		if _, err := h.Import(strings.NewReader(tt.data), tt.format); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+	if entries, _ := h.Recent(10); len(entries) != 0 {
+		t.Errorf("invalid imports added %d entries", len(entries))
+	}
+}
+
+func TestFormatFor(t *testing.T) {
+	if got := FormatFor("history.CSV"); got != FormatCSV {
+		t.Errorf("FormatFor(.CSV) = %q", got)
+	}
+	if got := FormatFor("history.jsonl"); got != FormatJSONL {
+		t.Errorf("FormatFor(.jsonl) = %q", got)
+	}
+}
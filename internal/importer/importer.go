@@ -0,0 +1,428 @@
+// Package importer reads connections saved by other tools — ~/.pgpass,
+// ~/.my.cnf, DBeaver's data-sources.json and DataGrip's dataSources.xml —
+// and turns them into saved connections.
+package importer
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+// Kind is a file format connections can be imported from.
+type Kind string
+
+const (
+	KindPgpass   Kind = "pgpass"
+	KindMyCnf    Kind = "my.cnf"
+	KindDBeaver  Kind = "DBeaver"
+	KindDataGrip Kind = "DataGrip"
+)
+
+// Source is a file connections can be imported from.
+type Source struct {
+	Kind Kind
+	Path string
+}
+
+// Detect returns the files of the known formats found in their default
+// locations.
+func Detect() []Source {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	candidates := []Source{
+		{KindPgpass, os.Getenv("PGPASSFILE")},
+		{KindPgpass, filepath.Join(home, ".pgpass")},
+		{KindMyCnf, filepath.Join(home, ".my.cnf")},
+	}
+	if runtime.GOOS == "windows" {
+		appData := os.Getenv("APPDATA")
+		candidates = append(candidates,
+			Source{KindPgpass, filepath.Join(appData, "postgresql", "pgpass.conf")},
+			Source{KindDBeaver, filepath.Join(appData, "DBeaverData", "workspace6", "General", ".dbeaver", "data-sources.json")},
+		)
+	} else {
+		candidates = append(candidates,
+			Source{KindDBeaver, filepath.Join(home, ".local", "share", "DBeaverData", "workspace6", "General", ".dbeaver", "data-sources.json")},
+			Source{KindDBeaver, filepath.Join(home, "Library", "DBeaverData", "workspace6", "General", ".dbeaver", "data-sources.json")},
+		)
+	}
+
+	var found []Source
+	seen := make(map[string]bool)
+	for _, c := range candidates {
+		if c.Path == "" || seen[c.Path] {
+			continue
+		}
+		seen[c.Path] = true
+		if fi, err := os.Stat(c.Path); err == nil && !fi.IsDir() {
+			found = append(found, c)
+		}
+	}
+	return found
+}
+
+// KindOf guesses the format of a file from its name.
+func KindOf(path string) (Kind, error) {
+	base := strings.ToLower(filepath.Base(path))
+	switch {
+	case base == ".pgpass" || base == "pgpass.conf" || strings.Contains(base, "pgpass"):
+		return KindPgpass, nil
+	case strings.HasSuffix(base, ".cnf") || strings.HasSuffix(base, ".ini"):
+		return KindMyCnf, nil
+	case strings.HasSuffix(base, ".json"):
+		return KindDBeaver, nil
+	case strings.HasSuffix(base, ".xml"):
+		return KindDataGrip, nil
+	}
+	return "", fmt.Errorf("%s: unknown format (expected .pgpass, .my.cnf, DBeaver .json or DataGrip .xml)", filepath.Base(path))
+}
+
+// ReadFile reads the connections in a file of the given kind.
+func ReadFile(kind Kind, path string) ([]config.SavedConnection, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("import: %w", err)
+	}
+	defer f.Close()
+
+	var conns []config.SavedConnection
+	switch kind {
+	case KindPgpass:
+		conns, err = Pgpass(f)
+	case KindMyCnf:
+		conns, err = MyCnf(f)
+	case KindDBeaver:
+		conns, err = DBeaver(f)
+	case KindDataGrip:
+		conns, err = DataGrip(f)
+	default:
+		err = fmt.Errorf("unknown format %q", kind)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("import %s: %w", filepath.Base(path), err)
+	}
+	return conns, nil
+}
+
+// Pgpass reads a PostgreSQL password file, with lines of
+// hostname:port:database:username:password. Entries with a wildcard host
+// cannot be connected to and are skipped; other wildcards are left empty.
+func Pgpass(r io.Reader) ([]config.SavedConnection, error) {
+	var conns []config.SavedConnection
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		f := splitPgpass(line)
+		if len(f) != 5 || f[0] == "*" {
+			continue
+		}
+		for i := 1; i < 4; i++ {
+			if f[i] == "*" {
+				f[i] = ""
+			}
+		}
+		conn := config.SavedConnection{
+			Adapter:  "postgres",
+			Host:     f[0],
+			User:     f[3],
+			Password: f[4],
+			Database: f[2],
+		}
+		conn.Port, _ = strconv.Atoi(f[1])
+		conn.Name = defaultName(conn)
+		conns = append(conns, conn)
+	}
+	return conns, sc.Err()
+}
+
+// splitPgpass splits a .pgpass line on unescaped colons, unescaping \: and
+// \\.
+func splitPgpass(line string) []string {
+	var fields []string
+	var cur strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && i+1 < len(line):
+			i++
+			cur.WriteByte(line[i])
+		case c == ':':
+			fields = append(fields, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	return append(fields, cur.String())
+}
+
+// MyCnf reads a MySQL option file. The [client] and [mysql] groups give one
+// connection; each suffixed group, such as [clientprod] used with
+// --defaults-group-suffix=prod, gives another that inherits from them.
+func MyCnf(r io.Reader) ([]config.SavedConnection, error) {
+	groups := make(map[string]map[string]string)
+	var order []string
+	var cur map[string]string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';':
+			continue
+		case line[0] == '[' && strings.HasSuffix(line, "]"):
+			name := strings.ToLower(strings.TrimSpace(line[1 : len(line)-1]))
+			if _, ok := groups[name]; !ok {
+				groups[name] = make(map[string]string)
+				order = append(order, name)
+			}
+			cur = groups[name]
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		key, value, _ := strings.Cut(line, "=")
+		key = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(key)), "_", "-")
+		cur[key] = unquote(strings.TrimSpace(value))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	base := make(map[string]string)
+	for _, g := range []string{"client", "mysql"} {
+		for k, v := range groups[g] {
+			base[k] = v
+		}
+	}
+	var conns []config.SavedConnection
+	add := func(name string, opts map[string]string) {
+		if opts["host"] == "" && opts["user"] == "" && opts["socket"] == "" {
+			return
+		}
+		conn := config.SavedConnection{
+			Name:     name,
+			Adapter:  "mysql",
+			Host:     opts["host"],
+			User:     opts["user"],
+			Password: opts["password"],
+			Database: opts["database"],
+		}
+		conn.Port, _ = strconv.Atoi(opts["port"])
+		if conn.Name == "" {
+			conn.Name = defaultName(conn)
+		}
+		conns = append(conns, conn)
+	}
+	add("", base)
+	for _, g := range order {
+		var suffix string
+		switch {
+		case g == "client" || g == "mysql":
+			continue
+		case strings.HasPrefix(g, "client"):
+			suffix = strings.TrimPrefix(g, "client")
+		case strings.HasPrefix(g, "mysql"):
+			suffix = strings.TrimPrefix(g, "mysql")
+		default:
+			continue // [mysqld], [mysqldump] and other tools
+		}
+		if suffix == "d" || suffix == "dump" || suffix == "admin" {
+			continue
+		}
+		opts := make(map[string]string)
+		for k, v := range base {
+			opts[k] = v
+		}
+		for k, v := range groups[g] {
+			opts[k] = v
+		}
+		add(strings.TrimLeft(suffix, "_-"), opts)
+	}
+	return conns, nil
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// dbeaverFile is the part of DBeaver's data-sources.json that is read.
+type dbeaverFile struct {
+	Connections map[string]struct {
+		Provider      string `json:"provider"`
+		Driver        string `json:"driver"`
+		Name          string `json:"name"`
+		Folder        string `json:"folder"`
+		Configuration struct {
+			Host     string `json:"host"`
+			Port     string `json:"port"`
+			Database string `json:"database"`
+			URL      string `json:"url"`
+			User     string `json:"user"`
+			Type     string `json:"type"` // connection type: dev, test, prod
+		} `json:"configuration"`
+	} `json:"connections"`
+}
+
+// DBeaver reads a DBeaver data-sources.json. DBeaver encrypts saved
+// passwords in a separate file, so they are not imported. Folders become
+// groups and connection types (dev, test, prod) environment tags.
+func DBeaver(r io.Reader) ([]config.SavedConnection, error) {
+	var f dbeaverFile
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(f.Connections))
+	for id := range f.Connections {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var conns []config.SavedConnection
+	for _, id := range ids {
+		c := f.Connections[id]
+		cfg := c.Configuration
+		conn, ok := fromJDBC(cfg.URL)
+		if !ok {
+			conn.Adapter = adapterFor(c.Provider + " " + c.Driver)
+			if conn.Adapter == "" {
+				continue
+			}
+		}
+		if cfg.Host != "" {
+			conn.Host = cfg.Host
+		}
+		if p, err := strconv.Atoi(cfg.Port); err == nil {
+			conn.Port = p
+		}
+		if cfg.Database != "" {
+			if conn.Adapter == "sqlite" || conn.Adapter == "duckdb" {
+				conn.File = cfg.Database
+			} else {
+				conn.Database = cfg.Database
+			}
+		}
+		conn.User = cfg.User
+		conn.Name = c.Name
+		conn.Group = c.Folder
+		conn.Env = cfg.Type
+		if conn.Name == "" {
+			conn.Name = defaultName(conn)
+		}
+		conns = append(conns, conn)
+	}
+	return conns, nil
+}
+
+// dataGripFile is the part of DataGrip's dataSources.xml that is read.
+type dataGripFile struct {
+	DataSources []struct {
+		Name      string `xml:"name,attr"`
+		DriverRef string `xml:"driver-ref"`
+		JDBCURL   string `xml:"jdbc-url"`
+		UserName  string `xml:"user-name"`
+	} `xml:"component>data-source"`
+}
+
+// DataGrip reads a DataGrip (or other JetBrains IDE) dataSources.xml.
+// Passwords live in the IDE's keychain and are not imported.
+func DataGrip(r io.Reader) ([]config.SavedConnection, error) {
+	var f dataGripFile
+	if err := xml.NewDecoder(r).Decode(&f); err != nil {
+		return nil, err
+	}
+	var conns []config.SavedConnection
+	for _, ds := range f.DataSources {
+		conn, ok := fromJDBC(ds.JDBCURL)
+		if !ok {
+			continue
+		}
+		if ds.UserName != "" {
+			conn.User = ds.UserName
+		}
+		conn.Name = ds.Name
+		if conn.Name == "" {
+			conn.Name = defaultName(conn)
+		}
+		conns = append(conns, conn)
+	}
+	return conns, nil
+}
+
+// fromJDBC parses a JDBC URL of a supported database, such as
+// jdbc:postgresql://host:5432/db or jdbc:sqlite:/path/to.db.
+func fromJDBC(jdbc string) (config.SavedConnection, bool) {
+	rest, ok := strings.CutPrefix(jdbc, "jdbc:")
+	if !ok {
+		return config.SavedConnection{}, false
+	}
+	scheme, target, _ := strings.Cut(rest, ":")
+	conn := config.SavedConnection{Adapter: adapterFor(scheme)}
+	switch conn.Adapter {
+	case "sqlite", "duckdb":
+		conn.File = strings.TrimPrefix(target, "//")
+		return conn, conn.File != ""
+	case "postgres", "mysql":
+		u, err := url.Parse(scheme + ":" + target)
+		if err != nil || u.Hostname() == "" {
+			return conn, false
+		}
+		conn.Host = u.Hostname()
+		conn.Port, _ = strconv.Atoi(u.Port())
+		conn.Database = strings.TrimPrefix(u.Path, "/")
+		conn.User = u.Query().Get("user")
+		return conn, true
+	}
+	return conn, false
+}
+
+// adapterFor returns the adapter for a driver or JDBC scheme name, or "".
+func adapterFor(name string) string {
+	name = strings.ToLower(name)
+	switch {
+	case strings.Contains(name, "postgres"):
+		return "postgres"
+	case strings.Contains(name, "mysql") || strings.Contains(name, "mariadb"):
+		return "mysql"
+	case strings.Contains(name, "sqlite"):
+		return "sqlite"
+	case strings.Contains(name, "duckdb"):
+		return "duckdb"
+	}
+	return ""
+}
+
+// defaultName names an imported connection without a name of its own,
+// e.g. "app@db.example.com/app".
+func defaultName(c config.SavedConnection) string {
+	name := c.Host
+	if name == "" {
+		name = "localhost"
+	}
+	if c.User != "" {
+		name = c.User + "@" + name
+	}
+	if c.Database != "" {
+		name += "/" + c.Database
+	}
+	return name
+}
@@ -0,0 +1,163 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+func TestPgpass(t *testing.T) {
+	in := `# comment
+db.example.com:5432:app:alice:s3cr\:et
+localhost:*:*:postgres:pw
+*:*:*:bob:any
+broken line
+`
+	conns, err := Pgpass(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("Pgpass: %v", err)
+	}
+	if len(conns) != 2 {
+		t.Fatalf("got %d connections, want 2 (wildcard host skipped): %+v", len(conns), conns)
+	}
+	want := config.SavedConnection{
+		Name: "alice@db.example.com/app", Adapter: "postgres",
+		Host: "db.example.com", Port: 5432, User: "alice", Password: "s3cr:et", Database: "app",
+	}
+	if conns[0] != want {
+		t.Errorf("conns[0] = %+v, want %+v", conns[0], want)
+	}
+	if c := conns[1]; c.Port != 0 || c.Database != "" || c.Name != "postgres@localhost" {
+		t.Errorf("conns[1] = %+v, want wildcards left empty", c)
+	}
+}
+
+func TestMyCnf(t *testing.T) {
+	in := `[client]
+user = root
+password = "p@ss word"
+
+[mysqld]
+port = 3307
+
+[clientprod]
+host = prod.db
+database = shop
+`
+	conns, err := MyCnf(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("MyCnf: %v", err)
+	}
+	if len(conns) != 2 {
+		t.Fatalf("got %d connections, want 2: %+v", len(conns), conns)
+	}
+	if c := conns[0]; c.Adapter != "mysql" || c.User != "root" || c.Password != "p@ss word" || c.Port != 0 {
+		t.Errorf("conns[0] = %+v", c)
+	}
+	if c := conns[1]; c.Name != "prod" || c.Host != "prod.db" || c.User != "root" || c.Database != "shop" {
+		t.Errorf("conns[1] = %+v, want [clientprod] over [client]", c)
+	}
+}
+
+func TestDBeaver(t *testing.T) {
+	in := `{
+	"folders": {"acme": {}},
+	"connections": {
+		"postgres-jdbc-1": {
+			"provider": "postgresql", "driver": "postgres-jdbc", "name": "Acme prod", "folder": "acme",
+			"configuration": {"host": "db1", "port": "5433", "database": "acme", "user": "app", "type": "prod",
+				"url": "jdbc:postgresql://db1:5433/acme"}
+		},
+		"sqlite-1": {
+			"provider": "sqlite", "driver": "sqlite_jdbc", "name": "Local",
+			"configuration": {"database": "/data/local.db", "url": "jdbc:sqlite:/data/local.db", "type": "dev"}
+		},
+		"oracle-1": {
+			"provider": "oracle", "name": "Legacy",
+			"configuration": {"url": "jdbc:oracle:thin:@db:1521:xe"}
+		}
+	}
+}`
+	conns, err := DBeaver(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("DBeaver: %v", err)
+	}
+	if len(conns) != 2 {
+		t.Fatalf("got %d connections, want 2 (oracle skipped): %+v", len(conns), conns)
+	}
+	want := config.SavedConnection{
+		Name: "Acme prod", Adapter: "postgres", Host: "db1", Port: 5433, User: "app",
+		Database: "acme", Group: "acme", Env: "prod",
+	}
+	if conns[0] != want {
+		t.Errorf("conns[0] = %+v, want %+v", conns[0], want)
+	}
+	if c := conns[1]; c.Adapter != "sqlite" || c.File != "/data/local.db" || c.Env != "dev" {
+		t.Errorf("conns[1] = %+v", c)
+	}
+}
+
+func TestDataGrip(t *testing.T) {
+	in := `<?xml version="1.0" encoding="UTF-8"?>
+<project version="4">
+  <component name="DataSourceManagerImpl" format="xml" multifile-model="true">
+    <data-source source="LOCAL" name="shop@mysql" uuid="1">
+      <driver-ref>mysql.8</driver-ref>
+      <jdbc-url>jdbc:mysql://mysql.internal:3306/shop</jdbc-url>
+      <user-name>shop</user-name>
+    </data-source>
+    <data-source source="LOCAL" name="analytics" uuid="2">
+      <driver-ref>duckdb</driver-ref>
+      <jdbc-url>jdbc:duckdb:/srv/analytics.duckdb</jdbc-url>
+    </data-source>
+  </component>
+</project>`
+	conns, err := DataGrip(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("DataGrip: %v", err)
+	}
+	if len(conns) != 2 {
+		t.Fatalf("got %d connections, want 2: %+v", len(conns), conns)
+	}
+	if c := conns[0]; c.Name != "shop@mysql" || c.Adapter != "mysql" || c.Host != "mysql.internal" || c.Port != 3306 || c.Database != "shop" || c.User != "shop" {
+		t.Errorf("conns[0] = %+v", c)
+	}
+	if c := conns[1]; c.Adapter != "duckdb" || c.File != "/srv/analytics.duckdb" {
+		t.Errorf("conns[1] = %+v", c)
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	tests := map[string]Kind{
+		"/home/me/.pgpass":            KindPgpass,
+		`C:\pg\pgpass.conf`:           KindPgpass,
+		"/home/me/.my.cnf":            KindMyCnf,
+		"/tmp/data-sources.json":      KindDBeaver,
+		"/proj/.idea/dataSources.xml": KindDataGrip,
+	}
+	for path, want := range tests {
+		if got, err := KindOf(path); err != nil || got != want {
+			t.Errorf("KindOf(%q) = %q, %v; want %q", path, got, err, want)
+		}
+	}
+	if _, err := KindOf("/tmp/notes.txt"); err == nil {
+		t.Error("KindOf(notes.txt) should fail")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("PGPASSFILE", "")
+	if err := os.WriteFile(filepath.Join(home, ".pgpass"), []byte("db:5432:app:me:pw\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got := Detect()
+	if len(got) != 1 || got[0].Kind != KindPgpass {
+		t.Errorf("Detect() = %+v, want the .pgpass", got)
+	}
+}
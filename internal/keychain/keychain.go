@@ -0,0 +1,97 @@
+// Package keychain keeps saved connection passwords in the OS keychain
+// (macOS Keychain, Secret Service on Linux, Windows Credential Manager), so
+// the config file only holds a secret ID.
+package keychain
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/zalando/go-keyring"
+)
+
+// service is the keychain service name secrets are stored under.
+const service = "gotermsql"
+
+// Resolve fills in the password of sc from the keychain when sc refers to
+// a secret and has no password of its own.
+func Resolve(sc *config.SavedConnection) error {
+	if sc.SecretID == "" || sc.Password != "" {
+		return nil
+	}
+	pw, err := keyring.Get(service, sc.SecretID)
+	if err != nil {
+		return fmt.Errorf("keychain: password of %s: %w", sc.Name, err)
+	}
+	sc.Password = pw
+	return nil
+}
+
+// Store moves the password of sc into the keychain, under its secret ID or
+// a new one, leaving sc with only the ID. Connections without a password,
+// or whose password is a ${NAME} environment reference, are left alone.
+func Store(sc *config.SavedConnection) error {
+	if sc.Password == "" || config.HasEnvRef(sc.Password) {
+		return nil
+	}
+	id := sc.SecretID
+	if id == "" {
+		id = newID()
+	}
+	if err := keyring.Set(service, id, sc.Password); err != nil {
+		return fmt.Errorf("keychain: %w", err)
+	}
+	sc.SecretID = id
+	sc.Password = ""
+	return nil
+}
+
+// Delete removes a secret. Secrets already gone are not an error.
+func Delete(id string) error {
+	if err := keyring.Delete(service, id); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("keychain: %w", err)
+	}
+	return nil
+}
+
+// Migrate moves every plaintext password in cfg into the keychain and
+// returns how many moved. It stops at the first error, leaving the
+// connections it did not reach unchanged.
+func Migrate(cfg *config.Config) (int, error) {
+	moved := 0
+	for i := range cfg.Connections {
+		if p := cfg.Connections[i].Password; p == "" || config.HasEnvRef(p) {
+			continue
+		}
+		if err := Store(&cfg.Connections[i]); err != nil {
+			return moved, err
+		}
+		moved++
+	}
+	return moved, nil
+}
+
+// Orphans returns the secret IDs used by before but not by after, which
+// are left behind when connections are deleted.
+func Orphans(before, after []config.SavedConnection) []string {
+	used := make(map[string]bool)
+	for _, sc := range after {
+		used[sc.SecretID] = true
+	}
+	var ids []string
+	for _, sc := range before {
+		if sc.SecretID != "" && !used[sc.SecretID] {
+			ids = append(ids, sc.SecretID)
+		}
+	}
+	return ids
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
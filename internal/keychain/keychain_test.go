@@ -0,0 +1,98 @@
+package keychain
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/zalando/go-keyring"
+)
+
+func TestStoreAndResolve(t *testing.T) {
+	keyring.MockInit()
+
+	sc := config.SavedConnection{Name: "prod", Password: "s3cret"}
+	if err := Store(&sc); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	if sc.Password != "" || sc.SecretID == "" {
+		t.Fatalf("after Store: Password %q, SecretID %q; want only an ID", sc.Password, sc.SecretID)
+	}
+
+	resolved := sc
+	if err := Resolve(&resolved); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if resolved.Password != "s3cret" {
+		t.Errorf("Password = %q, want s3cret", resolved.Password)
+	}
+
+	// A new password replaces the secret under the same ID.
+	resolved.Password = "rotated"
+	id := resolved.SecretID
+	if err := Store(&resolved); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	if resolved.SecretID != id {
+		t.Errorf("SecretID changed from %q to %q", id, resolved.SecretID)
+	}
+	if err := Resolve(&resolved); err != nil || resolved.Password != "rotated" {
+		t.Errorf("Resolve = %q, %v; want rotated", resolved.Password, err)
+	}
+
+	if err := Delete(id); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := Delete(id); err != nil {
+		t.Errorf("Delete of a missing secret: %v", err)
+	}
+	gone := config.SavedConnection{Name: "prod", SecretID: id}
+	if err := Resolve(&gone); err == nil {
+		t.Error("Resolve of a deleted secret succeeded")
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	keyring.MockInit()
+
+	cfg := &config.Config{Connections: []config.SavedConnection{
+		{Name: "a", Password: "one"},
+		{Name: "b"},
+		{Name: "c", Password: "three"},
+	}}
+	moved, err := Migrate(cfg)
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if moved != 2 {
+		t.Errorf("moved = %d, want 2", moved)
+	}
+	for _, sc := range cfg.Connections {
+		if sc.Password != "" {
+			t.Errorf("%s still has a plaintext password", sc.Name)
+		}
+	}
+	if cfg.Connections[1].SecretID != "" {
+		t.Errorf("connection without a password got secret %q", cfg.Connections[1].SecretID)
+	}
+}
+
+func TestOrphans(t *testing.T) {
+	before := []config.SavedConnection{{SecretID: "a"}, {SecretID: "b"}, {}}
+	after := []config.SavedConnection{{SecretID: "b"}}
+	if got := Orphans(before, after); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("Orphans = %v, want [a]", got)
+	}
+}
+
+func TestStore_LeavesEnvReference(t *testing.T) {
+	keyring.MockInit()
+
+	sc := config.SavedConnection{Name: "shared", Password: "${PGPASSWORD}"}
+	if err := Store(&sc); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	if sc.Password != "${PGPASSWORD}" || sc.SecretID != "" {
+		t.Errorf("Password %q, SecretID %q; want the reference kept in the config", sc.Password, sc.SecretID)
+	}
+}
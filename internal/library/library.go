@@ -0,0 +1,239 @@
+// Package library stores the saved query library: named queries, with a
+// description and tags, organized into folders. It is kept as YAML in the
+// config directory so it can be edited by hand or shared.
+package library
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sadopc/gotermsql/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Query is a saved query.
+type Query struct {
+	Name        string   `yaml:"name"`
+	Folder      string   `yaml:"folder,omitempty"` // slash-separated, e.g. "reports/monthly"
+	Description string   `yaml:"description,omitempty"`
+	Tags        []string `yaml:"tags,omitempty"`
+	SQL         string   `yaml:"sql"`
+
+	// Every, when set, runs the query by itself that often against the
+	// saved connection called Connection, logging the results.
+	Every      time.Duration `yaml:"every,omitempty"`
+	Connection string        `yaml:"connection,omitempty"`
+
+	// Source names the shared library the query came from, or is empty
+	// for the personal library. Shared queries are read-only.
+	Source string `yaml:"-"`
+}
+
+// MinEvery is the shortest interval a query can be scheduled at.
+const MinEvery = time.Minute
+
+// Scheduled reports whether q runs by itself every so often.
+func (q Query) Scheduled() bool { return q.Every > 0 }
+
+// ReadOnly reports whether q is from a shared library.
+func (q Query) ReadOnly() bool { return q.Source != "" }
+
+// Path returns the query's folder and name joined with a slash.
+func (q Query) Path() string {
+	if q.Folder == "" {
+		return q.Name
+	}
+	return q.Folder + "/" + q.Name
+}
+
+// file is the layout of the library file.
+type file struct {
+	Queries []Query `yaml:"queries"`
+}
+
+// DefaultPath returns ConfigDir()/queries.yaml.
+func DefaultPath() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "queries.yaml"), nil
+}
+
+// Load reads the queries in the library file at path, sorted by folder and
+// name. A missing file is an empty library.
+func Load(path string) ([]Query, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read query library: %w", err)
+	}
+	var f file
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse query library: %w", err)
+	}
+	Sort(f.Queries)
+	return f.Queries, nil
+}
+
+// LoadDefault reads the library from DefaultPath.
+func LoadDefault() ([]Query, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return Load(path)
+}
+
+// Save writes queries to the library file at path atomically, creating any
+// necessary parent directories.
+func Save(path string, queries []Query) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create config dir: %w", err)
+	}
+	data, err := yaml.Marshal(file{Queries: queries})
+	if err != nil {
+		return fmt.Errorf("marshal query library: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".queries-*.yaml.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("chmod temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("close temp file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("rename temp file: %w", err)
+	}
+	return nil
+}
+
+// SaveDefault writes the personal queries in queries to DefaultPath.
+func SaveDefault(queries []Query) error {
+	path, err := DefaultPath()
+	if err != nil {
+		return err
+	}
+	var personal []Query
+	for _, q := range queries {
+		if !q.ReadOnly() {
+			personal = append(personal, q)
+		}
+	}
+	return Save(path, personal)
+}
+
+// Normalize trims the fields of q, cleans up its folder path and drops
+// empty or repeated tags.
+func Normalize(q Query) Query {
+	q.Name = strings.TrimSpace(q.Name)
+	q.Description = strings.TrimSpace(q.Description)
+	q.SQL = strings.TrimSpace(q.SQL)
+	q.Connection = strings.TrimSpace(q.Connection)
+
+	var parts []string
+	for _, p := range strings.Split(q.Folder, "/") {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	q.Folder = strings.Join(parts, "/")
+
+	var tags []string
+	seen := make(map[string]bool)
+	for _, t := range q.Tags {
+		t = strings.TrimSpace(t)
+		if t != "" && !seen[strings.ToLower(t)] {
+			seen[strings.ToLower(t)] = true
+			tags = append(tags, t)
+		}
+	}
+	q.Tags = tags
+	return q
+}
+
+// Validate checks that q can be saved.
+func Validate(q Query) error {
+	switch {
+	case q.Name == "":
+		return errors.New("name is required")
+	case strings.Contains(q.Name, "/"):
+		return errors.New("name cannot contain /; use the folder for that")
+	case q.SQL == "":
+		return errors.New("query is empty")
+	case q.Every < 0:
+		return errors.New("the interval cannot be negative")
+	case q.Every > 0 && q.Every < MinEvery:
+		return fmt.Errorf("scheduled queries run at most every %s", MinEvery)
+	case q.Every > 0 && q.Connection == "":
+		return errors.New("a scheduled query needs a connection to run on")
+	}
+	return nil
+}
+
+// Put returns queries with the personal query q added, replacing the
+// personal query at the same path, sorted by folder and name.
+func Put(queries []Query, q Query) []Query {
+	out := make([]Query, 0, len(queries)+1)
+	for _, existing := range queries {
+		if existing.ReadOnly() || existing.Path() != q.Path() {
+			out = append(out, existing)
+		}
+	}
+	out = append(out, q)
+	Sort(out)
+	return out
+}
+
+// Remove returns queries without the personal query at path.
+func Remove(queries []Query, path string) []Query {
+	out := make([]Query, 0, len(queries))
+	for _, q := range queries {
+		if q.ReadOnly() || q.Path() != path {
+			out = append(out, q)
+		}
+	}
+	return out
+}
+
+// Sort orders queries by source, then folder, then name. Personal queries
+// come before shared ones, and queries outside any folder come first.
+func Sort(queries []Query) {
+	sort.SliceStable(queries, func(i, j int) bool {
+		a, b := queries[i], queries[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Folder != b.Folder {
+			return a.Folder < b.Folder
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+}
+
+// ParseTags splits a comma- or space-separated list of tags.
+func ParseTags(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+}
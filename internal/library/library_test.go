@@ -0,0 +1,116 @@
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoad_Missing(t *testing.T) {
+	queries, err := Load(filepath.Join(t.TempDir(), "queries.yaml"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(queries) != 0 {
+		t.Fatalf("got %d queries, want 0", len(queries))
+	}
+}
+
+func TestSaveLoad_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "queries.yaml")
+	in := []Query{
+		{Name: "top customers", Folder: "reports", Description: "by revenue", Tags: []string{"sales"}, SQL: "SELECT *\nFROM customers"},
+		{Name: "active users", SQL: "SELECT 1"},
+	}
+	if err := Save(path, in); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("file mode = %o, want 600", perm)
+	}
+
+	out, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	// Queries outside a folder sort first.
+	want := []Query{in[1], in[0]}
+	if !reflect.DeepEqual(out, want) {
+		t.Fatalf("Load = %+v, want %+v", out, want)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queries.yaml")
+	if err := os.WriteFile(path, []byte("queries: [oops"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatal("expected an error for invalid YAML")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	q := Normalize(Query{
+		Name:   "  daily ",
+		Folder: "/reports// monthly /",
+		Tags:   []string{"a", " ", "B", "b", "a"},
+		SQL:    "\n SELECT 1 \n",
+	})
+	want := Query{Name: "daily", Folder: "reports/monthly", Tags: []string{"a", "B"}, SQL: "SELECT 1"}
+	if !reflect.DeepEqual(q, want) {
+		t.Fatalf("Normalize = %+v, want %+v", q, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		q       Query
+		wantErr bool
+	}{
+		{Query{Name: "a", SQL: "SELECT 1"}, false},
+		{Query{SQL: "SELECT 1"}, true},
+		{Query{Name: "a/b", SQL: "SELECT 1"}, true},
+		{Query{Name: "a"}, true},
+		{Query{Name: "a", SQL: "SELECT 1", Every: 5 * time.Minute, Connection: "prod"}, false},
+		{Query{Name: "a", SQL: "SELECT 1", Every: 5 * time.Minute}, true},
+		{Query{Name: "a", SQL: "SELECT 1", Every: time.Second, Connection: "prod"}, true},
+	}
+	for _, tt := range tests {
+		if err := Validate(tt.q); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%+v) = %v, wantErr %v", tt.q, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPutRemove(t *testing.T) {
+	var queries []Query
+	queries = Put(queries, Query{Name: "b", Folder: "x", SQL: "1"})
+	queries = Put(queries, Query{Name: "a", Folder: "x", SQL: "2"})
+	queries = Put(queries, Query{Name: "b", Folder: "x", SQL: "3"})
+	if len(queries) != 2 {
+		t.Fatalf("got %d queries, want 2", len(queries))
+	}
+	if queries[0].Name != "a" || queries[1].SQL != "3" {
+		t.Fatalf("Put = %+v", queries)
+	}
+
+	queries = Remove(queries, "x/a")
+	if len(queries) != 1 || queries[0].Name != "b" {
+		t.Fatalf("Remove = %+v", queries)
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	got := ParseTags("sales, weekly  ops,")
+	want := []string{"sales", "weekly", "ops"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseTags = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,76 @@
+package library
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+// LoadShared reads the queries of a shared library, marking each with the
+// library's name as its Source. A directory is read as every .yaml or .yml
+// file in it, in name order.
+func LoadShared(lib config.SharedLibrary) ([]Query, error) {
+	path := config.ExpandHome(lib.Path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("shared library %s: %w", lib.Name, err)
+	}
+	files := []string{path}
+	if info.IsDir() {
+		files = nil
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("shared library %s: %w", lib.Name, err)
+		}
+		for _, e := range entries {
+			ext := strings.ToLower(filepath.Ext(e.Name()))
+			if !e.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				files = append(files, filepath.Join(path, e.Name()))
+			}
+		}
+	}
+
+	var queries []Query
+	for _, f := range files {
+		qs, err := Load(f)
+		if err != nil {
+			return nil, fmt.Errorf("shared library %s: %w", lib.Name, err)
+		}
+		for _, q := range qs {
+			q = Normalize(q)
+			q.Source = lib.Name
+			queries = append(queries, q)
+		}
+	}
+	Sort(queries)
+	return queries, nil
+}
+
+// Sync brings the shared libraries kept in git up to date with a
+// fast-forward pull. Libraries on a plain directory need no syncing.
+func Sync(ctx context.Context, shared []config.SharedLibrary) error {
+	for _, lib := range shared {
+		if !lib.Git {
+			continue
+		}
+		dir := config.ExpandHome(lib.Path)
+		if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+			dir = filepath.Dir(dir)
+		}
+		cmd := exec.CommandContext(ctx, "git", "-C", dir, "pull", "--ff-only", "--quiet")
+		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			msg := strings.TrimSpace(string(out))
+			if msg == "" {
+				msg = err.Error()
+			}
+			return fmt.Errorf("sync %s: %s", lib.Name, msg)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package library
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+func writeLibrary(t *testing.T, path string, queries ...Query) {
+	t.Helper()
+	if err := Save(path, queries); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadShared_Directory(t *testing.T) {
+	dir := t.TempDir()
+	writeLibrary(t, filepath.Join(dir, "b.yaml"), Query{Name: "revenue", Folder: " reports/ ", SQL: "SELECT 2"})
+	writeLibrary(t, filepath.Join(dir, "a.yml"), Query{Name: "users", SQL: "SELECT 1"})
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a library"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	queries, err := LoadShared(config.SharedLibrary{Name: "team", Path: dir})
+	if err != nil {
+		t.Fatalf("LoadShared: %v", err)
+	}
+	var paths []string
+	for _, q := range queries {
+		if q.Source != "team" || !q.ReadOnly() {
+			t.Errorf("%s: Source = %q, want team", q.Name, q.Source)
+		}
+		paths = append(paths, q.Path())
+	}
+	if got := strings.Join(paths, ","); got != "users,reports/revenue" {
+		t.Errorf("paths = %s", got)
+	}
+}
+
+func TestLoadShared_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "team.yaml")
+	writeLibrary(t, path, Query{Name: "users", SQL: "SELECT 1"})
+	queries, err := LoadShared(config.SharedLibrary{Name: "team", Path: path})
+	if err != nil {
+		t.Fatalf("LoadShared: %v", err)
+	}
+	if len(queries) != 1 || queries[0].Source != "team" {
+		t.Fatalf("got %+v", queries)
+	}
+
+	if _, err := LoadShared(config.SharedLibrary{Name: "gone", Path: filepath.Join(t.TempDir(), "x")}); err == nil {
+		t.Error("expected an error for a missing shared library")
+	}
+}
+
+func TestPutRemove_KeepShared(t *testing.T) {
+	shared := Query{Name: "users", SQL: "SELECT 1", Source: "team"}
+	queries := Put([]Query{shared}, Query{Name: "users", SQL: "SELECT 2"})
+	if len(queries) != 2 {
+		t.Fatalf("Put replaced the shared query: %+v", queries)
+	}
+	queries = Remove(queries, "users")
+	if len(queries) != 1 || queries[0].Source != "team" {
+		t.Fatalf("Remove = %+v, want only the shared query", queries)
+	}
+}
+
+func TestSaveDefault_SkipsShared(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmp, ".config"))
+	if err := SaveDefault([]Query{
+		{Name: "mine", SQL: "SELECT 1"},
+		{Name: "theirs", SQL: "SELECT 2", Source: "team"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	queries, err := LoadDefault()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 1 || queries[0].Name != "mine" {
+		t.Fatalf("saved %+v, want only the personal query", queries)
+	}
+}
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+func TestSync_Git(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", "/dev/null")
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	root := t.TempDir()
+	upstream := filepath.Join(root, "upstream")
+	clone := filepath.Join(root, "clone")
+
+	git(t, root, "init", "--quiet", upstream)
+	writeLibrary(t, filepath.Join(upstream, "queries.yaml"), Query{Name: "users", SQL: "SELECT 1"})
+	git(t, upstream, "add", ".")
+	git(t, upstream, "commit", "--quiet", "-m", "first")
+	git(t, root, "clone", "--quiet", upstream, clone)
+
+	writeLibrary(t, filepath.Join(upstream, "queries.yaml"),
+		Query{Name: "users", SQL: "SELECT 1"}, Query{Name: "orders", SQL: "SELECT 2"})
+	git(t, upstream, "commit", "--quiet", "-am", "second")
+
+	lib := config.SharedLibrary{Name: "team", Path: filepath.Join(clone, "queries.yaml"), Git: true}
+	if err := Sync(context.Background(), []config.SharedLibrary{lib}); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	queries, err := LoadShared(lib)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 2 {
+		t.Fatalf("got %d queries after sync, want 2", len(queries))
+	}
+
+	bad := config.SharedLibrary{Name: "plain", Path: root, Git: true}
+	if err := Sync(context.Background(), []config.SharedLibrary{bad}); err == nil || !strings.Contains(err.Error(), "sync plain") {
+		t.Errorf("Sync outside a repository = %v, want an error", err)
+	}
+}
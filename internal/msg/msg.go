@@ -4,7 +4,9 @@ import (
 	"time"
 
 	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
 	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/tunnel"
 )
 
 // Pane focus targets.
@@ -69,6 +71,17 @@ type ConnectMsg struct {
 	Conn    adapter.Connection
 	Adapter string
 	DSN     string
+	Tunnel  *tunnel.Tunnel // SSH tunnel the connection runs through, or nil
+
+	// Name is the saved connection connected to, Env its environment tag,
+	// e.g. "prod", and EnvColor the tag's color; all are empty for ad hoc
+	// connections.
+	Name     string
+	Env      string
+	EnvColor string
+
+	// Defaults are the saved connection's execution defaults, or nil.
+	Defaults *config.ExecDefaults
 }
 
 // ConnectErrMsg is sent when a connection attempt fails.
@@ -76,6 +89,13 @@ type ConnectErrMsg struct {
 	Err error
 }
 
+// TunnelClosedMsg is sent when the SSH tunnel of the current connection
+// exits on its own.
+type TunnelClosedMsg struct {
+	Err     error
+	ConnGen uint64
+}
+
 // DisconnectMsg is sent when the connection is closed.
 type DisconnectMsg struct{}
 
@@ -84,6 +104,31 @@ type SchemaLoadedMsg struct {
 	Databases []schema.Database
 	ConnGen   uint64
 	Warnings  []string
+	Lazy      bool // only table names were loaded; tables load on expand
+
+	// Hash is a digest of Databases when the schema cache is on, equal for
+	// equal schemas.
+	Hash string
+	// Cached is when Databases were saved to the schema cache, shown until
+	// the schema is loaded again; zero for a schema just loaded.
+	Cached time.Time
+}
+
+// LoadTableMsg asks for the columns, indexes and foreign keys of a table
+// whose schema was loaded lazily.
+type LoadTableMsg struct {
+	Database string
+	Schema   string
+	Table    string
+}
+
+// TableLoadedMsg answers a LoadTableMsg.
+type TableLoadedMsg struct {
+	Database string
+	Schema   string
+	Table    schema.Table
+	Err      error
+	ConnGen  uint64
 }
 
 // SchemaErrMsg is sent when schema loading fails.
@@ -94,8 +139,20 @@ type SchemaErrMsg struct {
 
 // ExecuteQueryMsg requests query execution.
 type ExecuteQueryMsg struct {
-	Query string
-	TabID int
+	Query     string
+	TabID     int
+	NoLimit   bool // run as written, without the automatic LIMIT
+	NoTimeout bool // run without the query timeout
+	Lint      bool // warn about risky patterns first (queries typed in the editor)
+
+	// Args, when set, are the values of the query's bind parameters, in
+	// the order adapter.ParseParams names them; the query runs as a
+	// prepared statement.
+	Args []any
+
+	// Confirmed skips asking for a large DROP's name to be typed; the
+	// user already typed it.
+	Confirmed bool
 }
 
 // QueryStartedMsg is sent when a query begins executing.
@@ -103,6 +160,7 @@ type QueryStartedMsg struct {
 	TabID   int
 	RunID   uint64
 	ConnGen uint64
+	Timeout time.Duration // 0 if the query may run as long as it takes
 }
 
 // QueryResultMsg is sent when query execution completes.
@@ -130,9 +188,20 @@ type QueryStreamingMsg struct {
 	ConnGen  uint64
 }
 
+// TotalRowsMsg reports the row count of a streaming query, computed in the
+// background with SELECT COUNT(*).
+type TotalRowsMsg struct {
+	Count   int64
+	Err     error
+	TabID   int
+	RunID   uint64
+	ConnGen uint64
+}
+
 // NewTabMsg requests creating a new query tab.
 type NewTabMsg struct {
 	Query string
+	Run   bool // execute Query in the new tab right away
 }
 
 // CloseTabMsg requests closing a tab.
@@ -152,6 +221,23 @@ type StatusMsg struct {
 	Duration time.Duration
 }
 
+// ToastLevel is the severity of a toast notification.
+type ToastLevel int
+
+const (
+	ToastInfo ToastLevel = iota
+	ToastSuccess
+	ToastWarning
+	ToastError
+)
+
+// ToastMsg shows a notification in the top-right corner that dismisses
+// itself, for events worth noticing even when the status bar has moved on.
+type ToastMsg struct {
+	Text  string
+	Level ToastLevel
+}
+
 // ToggleKeyModeMsg switches between vim and standard keybindings.
 type ToggleKeyModeMsg struct{}
 
@@ -182,3 +268,137 @@ type RefreshSchemaMsg struct{}
 
 // OpenHistoryMsg opens the query history panel.
 type OpenHistoryMsg struct{}
+
+// LoadTableStatsMsg requests row counts and sizes for the sidebar tables.
+type LoadTableStatsMsg struct{}
+
+// TableStatsMsg carries the row counts and sizes of the loaded tables.
+type TableStatsMsg struct {
+	Stats    map[schema.TableRef]schema.TableStats
+	ConnGen  uint64
+	Warnings []string
+}
+
+// RefreshMatViewMsg requests refreshing a materialized view.
+type RefreshMatViewMsg struct {
+	Database string
+	Schema   string
+	View     string
+}
+
+// FavoritesChangedMsg is sent when a table is starred or unstarred in the
+// sidebar. Tables lists the favorites as "schema.table", in display order.
+type FavoritesChangedMsg struct {
+	Tables []string
+}
+
+// TableAction is an operation on a table picked from the sidebar menu.
+type TableAction int
+
+const (
+	TableCount TableAction = iota
+	TableTruncate
+	TableDrop
+)
+
+// TableActionMsg requests a table operation that needs the connection.
+type TableActionMsg struct {
+	Action   TableAction
+	Database string
+	Schema   string
+	Table    string
+	Kind     string // object type for DROP: "TABLE", "VIEW" or "MATERIALIZED VIEW"
+}
+
+// ShowDDLMsg requests the CREATE statement of a table or view.
+type ShowDDLMsg struct {
+	Database string
+	Schema   string
+	Table    string
+}
+
+// DependenciesMsg requests what depends on a table or view and what it
+// depends on.
+type DependenciesMsg struct {
+	Database string
+	Schema   string
+	Table    string
+}
+
+// TransferTableMsg opens the wizard that copies the rows of a table or view
+// to a table on another connection.
+type TransferTableMsg struct {
+	Database string
+	Schema   string
+	Table    string
+}
+
+// InsertRowMsg opens the form that inserts a row into a table.
+type InsertRowMsg struct {
+	Database string
+	Schema   string
+	Table    string
+}
+
+// GenerateDataMsg opens the test data generator for a table.
+type GenerateDataMsg struct {
+	Database string
+	Schema   string
+	Table    string
+}
+
+// BulkUpdateMsg opens the wizard that builds, previews and runs an UPDATE
+// of the rows of a table.
+type BulkUpdateMsg struct {
+	Database string
+	Schema   string
+	Table    string
+}
+
+// CompareDataMsg opens the comparison of the rows of a table with those of
+// a table on the connection open or a saved one.
+type CompareDataMsg struct {
+	Database string
+	Schema   string
+	Table    string
+}
+
+// DumpTableMsg opens the dialog that writes the CREATE TABLE and rows of a
+// table to a SQL file.
+type DumpTableMsg struct {
+	Database string
+	Schema   string
+	Table    string
+}
+
+// PortTableMsg requests the CREATE TABLE of a table written for another
+// dialect, to copy its structure to a database of that kind.
+type PortTableMsg struct {
+	Database string
+	Schema   string
+	Table    string
+	Dialect  string // adapter name of the target database
+}
+
+// ERDiagramMsg requests the ER diagram of a schema, on the neighbours of
+// Table when it is set. Empty names mean the first database or schema.
+type ERDiagramMsg struct {
+	Database string
+	Schema   string
+	Table    string
+}
+
+// ProfileTableMsg requests the data profile of a table: for each column,
+// its NULLs, distinct values, range, mean length and most frequent values.
+type ProfileTableMsg struct {
+	Database string
+	Schema   string
+	Table    string
+}
+
+// MaintenanceMsg requests the maintenance panel of a database, Schema
+// naming it on the connection ("main", or an attached database).
+type MaintenanceMsg struct {
+	Database string
+	Schema   string
+}
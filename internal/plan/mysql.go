@@ -0,0 +1,77 @@
+package plan
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// mysqlEstimate and mysqlActual are the figures after a node of
+	// EXPLAIN ANALYZE's tree, e.g. "(cost=1.25 rows=10) (actual
+	// time=0.0379..0.0458 rows=10 loops=1)".
+	mysqlEstimate = regexp.MustCompile(`\s*\(cost=[\d.e+-]+ rows=([\d.e+-]+)\)`)
+	mysqlActual   = regexp.MustCompile(`\s*\(actual time=[\d.e+-]+\.\.([\d.e+-]+) rows=([\d.e+-]+) loops=(\d+)\)`)
+	mysqlNever    = regexp.MustCompile(`\s*\(never executed\)`)
+)
+
+// parseMySQL reads the tree MySQL's EXPLAIN ANALYZE prints, one node per
+// "-> " line, indented four spaces a level. Times are per loop.
+func parseMySQL(text string) (*Plan, error) {
+	type open struct {
+		node  *Node
+		depth int
+	}
+	var root *Node
+	var stack []open
+	var last *Node
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if !strings.HasPrefix(trimmed, "-> ") {
+			if last != nil && strings.TrimSpace(line) != "" {
+				last.Detail = append(last.Detail, strings.TrimSpace(line))
+			}
+			continue
+		}
+		depth := len(line) - len(trimmed)
+		n := mysqlNode(strings.TrimPrefix(trimmed, "-> "))
+		for len(stack) > 0 && stack[len(stack)-1].depth >= depth {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			if root != nil {
+				return nil, fmt.Errorf("read plan: more than one root")
+			}
+			root = n
+		} else {
+			parent := stack[len(stack)-1].node
+			parent.Nodes = append(parent.Nodes, n)
+		}
+		stack = append(stack, open{n, depth})
+		last = n
+	}
+	if root == nil {
+		return nil, fmt.Errorf("read plan: no nodes")
+	}
+	return &Plan{Root: root, Execution: root.Time}, nil
+}
+
+// mysqlNode reads one node line, without its "-> ".
+func mysqlNode(s string) *Node {
+	n := &Node{}
+	if m := mysqlActual.FindStringSubmatchIndex(s); m != nil {
+		last, _ := strconv.ParseFloat(s[m[2]:m[3]], 64)
+		n.Rows, _ = strconv.ParseFloat(s[m[4]:m[5]], 64)
+		n.Loops, _ = strconv.ParseFloat(s[m[6]:m[7]], 64)
+		n.Time = last * n.Loops
+		s = s[:m[0]] + s[m[1]:]
+	}
+	s = mysqlNever.ReplaceAllString(s, "")
+	if m := mysqlEstimate.FindStringSubmatchIndex(s); m != nil {
+		n.EstRows, _ = strconv.ParseFloat(s[m[2]:m[3]], 64)
+		s = s[:m[0]] + s[m[1]:]
+	}
+	n.Op = strings.TrimSpace(s)
+	return n
+}
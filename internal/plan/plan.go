@@ -0,0 +1,228 @@
+// Package plan runs queries under EXPLAIN ANALYZE and reads the executed
+// plan back as a tree, so that the slow steps of a query can be found.
+package plan
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sadopc/gotermsql/internal/adapter"
+)
+
+// ErrUnsupported is returned for databases without EXPLAIN ANALYZE output
+// this package can read.
+var ErrUnsupported = errors.New("EXPLAIN ANALYZE is not supported")
+
+// Misestimate is how many times off a row estimate must be to be flagged.
+const Misestimate = 10
+
+// Share of the execution time, spent in a node itself, from which it is
+// shown as warm or hot.
+const (
+	warmShare = 0.10
+	hotShare  = 0.30
+)
+
+// Plan is an executed query plan.
+type Plan struct {
+	Root      *Node
+	Planning  float64 // ms; 0 if not reported
+	Execution float64 // ms; the root's time if not reported
+}
+
+// Node is one step of a plan. Row counts and times are per loop, as the
+// databases report them, except Time.
+type Node struct {
+	Op      string   // e.g. "Seq Scan on orders"
+	Detail  []string // conditions and the like, e.g. "Filter: (id > 10)"
+	EstRows float64
+	Rows    float64 // actual rows, averaged over loops
+	Loops   float64 // 0 if never executed
+	Time    float64 // ms spent in all loops, children included
+	Buffers Buffers
+	Nodes   []*Node
+}
+
+// Buffers are the PostgreSQL buffer and I/O statistics of a node,
+// children included.
+type Buffers struct {
+	SharedHit, SharedRead, SharedDirtied, SharedWritten int64
+	TempRead, TempWritten                               int64
+	IORead, IOWrite                                     float64 // ms
+}
+
+// Self returns the time spent in n itself, without its children.
+func (n *Node) Self() float64 {
+	self := n.Time
+	for _, c := range n.Nodes {
+		self -= c.Time
+	}
+	return max(self, 0)
+}
+
+// Off returns how many times the row estimate is off, and whether the
+// actual rows are more than estimated. It is 1 for a node never executed.
+func (n *Node) Off() (factor float64, under bool) {
+	if n.Loops == 0 {
+		return 1, false
+	}
+	est, rows := max(n.EstRows, 1), max(n.Rows, 1)
+	if rows > est {
+		return rows / est, true
+	}
+	return est / rows, false
+}
+
+// Query returns the statement that runs query under EXPLAIN ANALYZE on
+// the dialect. It executes the query, writes included.
+func Query(dialect, query string) (string, error) {
+	q := adapter.TrimStatement(query)
+	switch dialect {
+	case "postgres":
+		return "EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) " + q, nil
+	case "mysql":
+		return "EXPLAIN ANALYZE " + q, nil
+	}
+	return "", fmt.Errorf("%w on %s", ErrUnsupported, dialect)
+}
+
+// Parse reads the result of the statement Query returned.
+func Parse(dialect string, res *adapter.QueryResult) (*Plan, error) {
+	if res == nil || len(res.Rows) == 0 || len(res.Rows[0]) == 0 {
+		return nil, errors.New("explain returned no plan")
+	}
+	var out strings.Builder
+	for _, row := range res.Rows {
+		out.WriteString(row[0])
+		out.WriteByte('\n')
+	}
+	switch dialect {
+	case "postgres":
+		return parsePostgres(out.String())
+	case "mysql":
+		return parseMySQL(out.String())
+	}
+	return nil, fmt.Errorf("%w on %s", ErrUnsupported, dialect)
+}
+
+// Heat is how much of the execution time a line's node took.
+type Heat int
+
+const (
+	HeatNone Heat = iota // not a node, e.g. a condition
+	HeatCold
+	HeatWarm
+	HeatHot
+)
+
+// Line is a line of the rendered plan.
+type Line struct {
+	Text string
+	Heat Heat
+	Off  bool // the node's row estimate is off by Misestimate or more
+}
+
+// Lines renders the plan as a tree, one line per node followed by its
+// details, under a header with the totals.
+func (p *Plan) Lines() []Line {
+	header := fmt.Sprintf("Execution %s", ms(p.Execution))
+	if p.Planning > 0 {
+		header += ", planning " + ms(p.Planning)
+	}
+	lines := []Line{
+		{Text: header},
+		{Text: fmt.Sprintf("Share is of the execution time, in the node itself; ⚠ marks row estimates off %d× or more.", Misestimate)},
+		{},
+		{Text: fmt.Sprintf("%6s %10s  %s", "share", "self", "node")},
+	}
+	total := p.Execution
+	if total <= 0 && p.Root != nil {
+		total = p.Root.Time
+	}
+	var walk func(n *Node, depth int)
+	walk = func(n *Node, depth int) {
+		indent := strings.Repeat("  ", depth)
+		if depth > 0 {
+			indent += "→ "
+		}
+		share := 0.0
+		if total > 0 {
+			share = n.Self() / total
+		}
+		heat := HeatCold
+		switch {
+		case share >= hotShare:
+			heat = HeatHot
+		case share >= warmShare:
+			heat = HeatWarm
+		}
+		factor, under := n.Off()
+		lines = append(lines, Line{
+			Text: fmt.Sprintf("%5.1f%% %10s  %s%s  %s", share*100, ms(n.Self()), indent, n.Op, n.stats(factor, under)),
+			Heat: heat,
+			Off:  factor >= Misestimate,
+		})
+		pad := strings.Repeat(" ", 19) + strings.Repeat("  ", depth) + "    "
+		for _, d := range n.Detail {
+			lines = append(lines, Line{Text: pad + d})
+		}
+		for _, c := range n.Nodes {
+			walk(c, depth+1)
+		}
+	}
+	if p.Root != nil {
+		walk(p.Root, 0)
+	}
+	return lines
+}
+
+// stats describes the rows, loops and buffers of n.
+func (n *Node) stats(factor float64, under bool) string {
+	if n.Loops == 0 {
+		return "never executed"
+	}
+	s := fmt.Sprintf("rows %s est %s", count(n.Rows), count(n.EstRows))
+	if factor >= Misestimate {
+		dir := "over"
+		if under {
+			dir = "under"
+		}
+		s += fmt.Sprintf(" ⚠ %s× %s", count(factor), dir)
+	}
+	if n.Loops > 1 {
+		s += " loops " + count(n.Loops)
+	}
+	b := n.Buffers
+	for _, f := range []struct {
+		name string
+		n    int64
+	}{
+		{"hit", b.SharedHit}, {"read", b.SharedRead}, {"dirtied", b.SharedDirtied},
+		{"written", b.SharedWritten}, {"temp read", b.TempRead}, {"temp written", b.TempWritten},
+	} {
+		if f.n > 0 {
+			s += fmt.Sprintf("  %s %d", f.name, f.n)
+		}
+	}
+	if io := b.IORead + b.IOWrite; io > 0 {
+		s += "  I/O " + ms(io)
+	}
+	return s
+}
+
+// ms formats a time in milliseconds.
+func ms(t float64) string {
+	if t >= 100 {
+		return fmt.Sprintf("%.0f ms", t)
+	}
+	return fmt.Sprintf("%.2f ms", t)
+}
+
+// count formats a row count, which may be a fractional average.
+func count(n float64) string {
+	if n >= 100 || n == float64(int64(n)) {
+		return fmt.Sprintf("%.0f", n)
+	}
+	return fmt.Sprintf("%.1f", n)
+}
@@ -0,0 +1,156 @@
+package plan
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sadopc/gotermsql/internal/adapter"
+)
+
+const pgJSON = `[
+  {
+    "Plan": {
+      "Node Type": "Hash Join", "Join Type": "Inner",
+      "Plan Rows": 50, "Actual Rows": 2000, "Actual Loops": 1, "Actual Total Time": 10.0,
+      "Shared Hit Blocks": 12, "Shared Read Blocks": 30, "I/O Read Time": 1.5,
+      "Hash Cond": "(o.user_id = u.id)",
+      "Plans": [
+        {
+          "Node Type": "Seq Scan", "Relation Name": "orders", "Alias": "o",
+          "Plan Rows": 2000, "Actual Rows": 2000, "Actual Loops": 1, "Actual Total Time": 6.0,
+          "Filter": "(total > 10)", "Rows Removed by Filter": 100
+        },
+        {
+          "Node Type": "Hash",
+          "Plan Rows": 10, "Actual Rows": 10, "Actual Loops": 1, "Actual Total Time": 0.5,
+          "Plans": [
+            {
+              "Node Type": "Index Scan", "Relation Name": "users", "Alias": "u", "Index Name": "users_pkey",
+              "Plan Rows": 10, "Actual Rows": 10, "Actual Loops": 1, "Actual Total Time": 0.4
+            }
+          ]
+        }
+      ]
+    },
+    "Planning Time": 0.25,
+    "Execution Time": 10.5
+  }
+]`
+
+const mysqlTree = `-> Nested loop inner join  (cost=4.5 rows=10) (actual time=0.1..8 rows=10 loops=1)
+    -> Table scan on u  (cost=1.25 rows=10) (actual time=0.05..0.5 rows=10 loops=1)
+    -> Filter: (o.user_id = u.id)  (cost=0.3 rows=1) (actual time=0.2..0.7 rows=1 loops=10)
+        -> Index lookup on o using user_id (user_id=u.id)  (cost=0.3 rows=1) (actual time=0.1..0.6 rows=1 loops=10)
+    -> Covering index scan on x  (cost=1 rows=5) (never executed)
+`
+
+func result(text string) *adapter.QueryResult {
+	res := &adapter.QueryResult{Columns: []adapter.ColumnMeta{{Name: "QUERY PLAN"}}}
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		res.Rows = append(res.Rows, []string{line})
+	}
+	return res
+}
+
+func TestParsePostgres(t *testing.T) {
+	p, err := Parse("postgres", result(pgJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Execution != 10.5 || p.Planning != 0.25 {
+		t.Errorf("times = %v, %v; want 10.5, 0.25", p.Execution, p.Planning)
+	}
+	root := p.Root
+	if root.Op != "Hash Join" || len(root.Nodes) != 2 {
+		t.Fatalf("root = %q with %d children", root.Op, len(root.Nodes))
+	}
+	if got := root.Nodes[0].Op; got != "Seq Scan on orders o" {
+		t.Errorf("scan op = %q", got)
+	}
+	if got := root.Nodes[1].Nodes[0].Op; got != "Index Scan using users_pkey on users u" {
+		t.Errorf("index scan op = %q", got)
+	}
+	if got := root.Self(); got != 3.5 {
+		t.Errorf("root self = %v, want 3.5", got)
+	}
+	if factor, under := root.Off(); factor != 40 || !under {
+		t.Errorf("root off = %v, %v; want 40, under", factor, under)
+	}
+	if b := root.Buffers; b.SharedHit != 12 || b.SharedRead != 30 || b.IORead != 1.5 {
+		t.Errorf("root buffers = %+v", b)
+	}
+	want := []string{"Filter: (total > 10)", "Rows Removed by Filter: 100"}
+	if got := root.Nodes[0].Detail; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("scan detail = %q, want %q", got, want)
+	}
+}
+
+func TestParseMySQL(t *testing.T) {
+	p, err := Parse("mysql", result(mysqlTree))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := p.Root
+	if root.Op != "Nested loop inner join" || len(root.Nodes) != 3 {
+		t.Fatalf("root = %q with %d children", root.Op, len(root.Nodes))
+	}
+	if p.Execution != 8 {
+		t.Errorf("execution = %v, want 8", p.Execution)
+	}
+	filter := root.Nodes[1]
+	if filter.Time != 7 || filter.Loops != 10 || len(filter.Nodes) != 1 {
+		t.Errorf("filter time = %v, loops = %v, children = %d", filter.Time, filter.Loops, len(filter.Nodes))
+	}
+	if got := filter.Nodes[0].Op; got != "Index lookup on o using user_id (user_id=u.id)" {
+		t.Errorf("lookup op = %q", got)
+	}
+	never := root.Nodes[2]
+	if never.Loops != 0 || never.EstRows != 5 {
+		t.Errorf("never executed node = %+v", never)
+	}
+}
+
+func TestLines(t *testing.T) {
+	p, err := Parse("postgres", result(pgJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nodes []Line
+	for _, l := range p.Lines() {
+		if l.Heat != HeatNone {
+			nodes = append(nodes, l)
+		}
+	}
+	if len(nodes) != 4 {
+		t.Fatalf("got %d node lines, want 4", len(nodes))
+	}
+	// The scan takes 6 of 10.5 ms, the join 3.5; the hash almost nothing.
+	want := []Heat{HeatHot, HeatHot, HeatCold, HeatCold}
+	for i, l := range nodes {
+		if l.Heat != want[i] {
+			t.Errorf("line %q heat = %v, want %v", l.Text, l.Heat, want[i])
+		}
+	}
+	if !nodes[0].Off || nodes[1].Off {
+		t.Error("only the join's estimate should be flagged")
+	}
+	for _, s := range []string{"⚠ 40× under", "hit 12  read 30", "I/O 1.50 ms"} {
+		if !strings.Contains(nodes[0].Text, s) {
+			t.Errorf("join line %q lacks %q", nodes[0].Text, s)
+		}
+	}
+	if !strings.Contains(nodes[3].Text, "    → Index Scan") {
+		t.Errorf("index scan line %q is not indented as a grandchild", nodes[3].Text)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	got, err := Query("postgres", "SELECT 1;")
+	if err != nil || got != "EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) SELECT 1" {
+		t.Errorf("Query(postgres) = %q, %v", got, err)
+	}
+	if _, err := Query("sqlite", "SELECT 1"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Query(sqlite) error = %v, want ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,150 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// pgPlan is a node of EXPLAIN (FORMAT JSON) output.
+type pgPlan struct {
+	NodeType    string  `json:"Node Type"`
+	Strategy    string  `json:"Strategy"`
+	JoinType    string  `json:"Join Type"`
+	Subplan     string  `json:"Subplan Name"`
+	Relation    string  `json:"Relation Name"`
+	Alias       string  `json:"Alias"`
+	Index       string  `json:"Index Name"`
+	CTE         string  `json:"CTE Name"`
+	Function    string  `json:"Function Name"`
+	PlanRows    float64 `json:"Plan Rows"`
+	ActualRows  float64 `json:"Actual Rows"`
+	ActualLoops float64 `json:"Actual Loops"`
+	ActualTime  float64 `json:"Actual Total Time"` // per loop
+
+	SharedHit     int64   `json:"Shared Hit Blocks"`
+	SharedRead    int64   `json:"Shared Read Blocks"`
+	SharedDirtied int64   `json:"Shared Dirtied Blocks"`
+	SharedWritten int64   `json:"Shared Written Blocks"`
+	TempRead      int64   `json:"Temp Read Blocks"`
+	TempWritten   int64   `json:"Temp Written Blocks"`
+	IORead        float64 `json:"I/O Read Time"`
+	IOWrite       float64 `json:"I/O Write Time"`
+	SharedIORead  float64 `json:"Shared I/O Read Time"` // PostgreSQL 17 and later
+	SharedIOWrite float64 `json:"Shared I/O Write Time"`
+
+	IndexCond    string   `json:"Index Cond"`
+	RecheckCond  string   `json:"Recheck Cond"`
+	HashCond     string   `json:"Hash Cond"`
+	MergeCond    string   `json:"Merge Cond"`
+	JoinFilter   string   `json:"Join Filter"`
+	Filter       string   `json:"Filter"`
+	RemovedByFil float64  `json:"Rows Removed by Filter"`
+	SortKey      []string `json:"Sort Key"`
+	SortMethod   string   `json:"Sort Method"`
+	SortSpace    string   `json:"Sort Space Type"`
+	SortUsed     int64    `json:"Sort Space Used"` // kB
+	GroupKey     []string `json:"Group Key"`
+
+	Plans []pgPlan `json:"Plans"`
+}
+
+// parsePostgres reads EXPLAIN (ANALYZE, BUFFERS, FORMAT JSON) output.
+func parsePostgres(text string) (*Plan, error) {
+	var out []struct {
+		Plan      pgPlan  `json:"Plan"`
+		Planning  float64 `json:"Planning Time"`
+		Execution float64 `json:"Execution Time"`
+	}
+	if err := json.Unmarshal([]byte(text), &out); err != nil {
+		return nil, fmt.Errorf("read plan: %w", err)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("read plan: empty")
+	}
+	p := &Plan{
+		Root:      out[0].Plan.node(),
+		Planning:  out[0].Planning,
+		Execution: out[0].Execution,
+	}
+	if p.Execution <= 0 {
+		p.Execution = p.Root.Time
+	}
+	return p, nil
+}
+
+func (pp pgPlan) node() *Node {
+	n := &Node{
+		Op:      pp.op(),
+		EstRows: pp.PlanRows,
+		Rows:    pp.ActualRows,
+		Loops:   pp.ActualLoops,
+		Time:    pp.ActualTime * pp.ActualLoops,
+		Buffers: Buffers{
+			SharedHit:     pp.SharedHit,
+			SharedRead:    pp.SharedRead,
+			SharedDirtied: pp.SharedDirtied,
+			SharedWritten: pp.SharedWritten,
+			TempRead:      pp.TempRead,
+			TempWritten:   pp.TempWritten,
+			IORead:        pp.IORead + pp.SharedIORead,
+			IOWrite:       pp.IOWrite + pp.SharedIOWrite,
+		},
+	}
+	for _, d := range []struct{ label, value string }{
+		{"Index Cond", pp.IndexCond}, {"Recheck Cond", pp.RecheckCond},
+		{"Hash Cond", pp.HashCond}, {"Merge Cond", pp.MergeCond},
+		{"Join Filter", pp.JoinFilter}, {"Filter", pp.Filter},
+		{"Sort Key", strings.Join(pp.SortKey, ", ")}, {"Group Key", strings.Join(pp.GroupKey, ", ")},
+	} {
+		if d.value != "" {
+			n.Detail = append(n.Detail, d.label+": "+d.value)
+		}
+	}
+	if pp.RemovedByFil > 0 {
+		n.Detail = append(n.Detail, fmt.Sprintf("Rows Removed by Filter: %s", count(pp.RemovedByFil)))
+	}
+	if pp.SortMethod != "" {
+		n.Detail = append(n.Detail, fmt.Sprintf("Sort Method: %s  %s: %dkB", pp.SortMethod, pp.SortSpace, pp.SortUsed))
+	}
+	for _, c := range pp.Plans {
+		n.Nodes = append(n.Nodes, c.node())
+	}
+	return n
+}
+
+// aggregates names Aggregate nodes by strategy.
+var aggregates = map[string]string{
+	"Hashed": "HashAggregate",
+	"Sorted": "GroupAggregate",
+	"Mixed":  "MixedAggregate",
+}
+
+// op names the node the way EXPLAIN's text format does.
+func (pp pgPlan) op() string {
+	op := pp.NodeType
+	switch {
+	case pp.NodeType == "Aggregate" && aggregates[pp.Strategy] != "":
+		op = aggregates[pp.Strategy]
+	case pp.JoinType != "" && pp.JoinType != "Inner":
+		op = strings.TrimSuffix(op, " Join") + " " + pp.JoinType + " Join"
+	}
+	if pp.Index != "" {
+		op += " using " + pp.Index
+	}
+	switch {
+	case pp.Relation != "":
+		op += " on " + pp.Relation
+	case pp.CTE != "":
+		op += " on " + pp.CTE
+	case pp.Function != "":
+		op += " on " + pp.Function
+	}
+	if pp.Alias != "" && pp.Alias != pp.Relation && pp.Alias != pp.CTE && pp.Alias != pp.Function {
+		op += " " + pp.Alias
+	}
+	if pp.Subplan != "" {
+		op = pp.Subplan + ": " + op
+	}
+	return op
+}
@@ -0,0 +1,387 @@
+package schema
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Change is how an object differs between two schemas.
+type Change int
+
+const (
+	Added   Change = iota // only in the new schema
+	Dropped               // only in the old schema
+	Altered               // in both, defined differently
+)
+
+// String returns "added", "dropped" or "altered".
+func (c Change) String() string {
+	switch c {
+	case Added:
+		return "added"
+	case Dropped:
+		return "dropped"
+	}
+	return "altered"
+}
+
+// Kinds of objects an ObjectDiff is about, in the order Compare lists
+// them.
+const (
+	KindSchema   = "schema"
+	KindTable    = "table"
+	KindView     = "view"
+	KindSequence = "sequence"
+	KindRoutine  = "routine"
+	KindTrigger  = "trigger"
+)
+
+var kindOrder = []string{KindSchema, KindTable, KindView, KindSequence, KindRoutine, KindTrigger}
+
+// ObjectDiff is an object that differs between two schemas. The field of
+// its kind holds the object as it is on the side it exists on, the new
+// side when it was altered.
+type ObjectDiff struct {
+	Kind   string
+	Schema string // the schema's name on the old side, when it has one
+	Name   string
+	Change Change
+
+	Table   *Table
+	Changes *TableChanges // what changed in an altered table
+	View    *View
+	Routine *Routine
+	Trigger *Trigger
+}
+
+// Label names the object as "schema.name", with a routine's arguments
+// and a trigger's table.
+func (d ObjectDiff) Label() string {
+	name := d.Name
+	switch {
+	case d.Routine != nil:
+		name += "(" + d.Routine.Args + ")"
+	case d.Trigger != nil:
+		name = d.Trigger.Table + "." + name
+	}
+	if d.Kind == KindSchema || d.Schema == "" {
+		return name
+	}
+	return d.Schema + "." + name
+}
+
+// Details describes what changed in an altered object, one line each.
+func (d ObjectDiff) Details() []string {
+	if d.Change != Altered {
+		return nil
+	}
+	if d.Changes != nil {
+		return d.Changes.Details()
+	}
+	return []string{"definition changed"}
+}
+
+// ColumnChange is a column defined differently on each side.
+type ColumnChange struct {
+	Old, New Column
+}
+
+// TableChanges is how a table was altered. An index or foreign key
+// defined differently is both dropped and added.
+type TableChanges struct {
+	AddedColumns   []Column
+	DroppedColumns []Column
+	AlteredColumns []ColumnChange
+	AddedIndexes   []Index
+	DroppedIndexes []Index
+	AddedFKs       []ForeignKey
+	DroppedFKs     []ForeignKey
+	// PrimaryKey is the new primary key when its columns changed.
+	PrimaryKey []string
+	PKChanged  bool
+}
+
+// Details describes the changes, one line each.
+func (c TableChanges) Details() []string {
+	var lines []string
+	for _, col := range c.AddedColumns {
+		lines = append(lines, "column "+col.Name+" added ("+col.Type+")")
+	}
+	for _, col := range c.DroppedColumns {
+		lines = append(lines, "column "+col.Name+" dropped")
+	}
+	for _, ch := range c.AlteredColumns {
+		var what []string
+		if !strings.EqualFold(ch.Old.Type, ch.New.Type) {
+			what = append(what, "type "+ch.Old.Type+" → "+ch.New.Type)
+		}
+		if ch.Old.Nullable != ch.New.Nullable {
+			if ch.New.Nullable {
+				what = append(what, "nullable")
+			} else {
+				what = append(what, "not null")
+			}
+		}
+		if ch.Old.Default != ch.New.Default {
+			what = append(what, "default "+cmp.Or(ch.Old.Default, "none")+" → "+cmp.Or(ch.New.Default, "none"))
+		}
+		lines = append(lines, "column "+ch.New.Name+": "+strings.Join(what, ", "))
+	}
+	if c.PKChanged {
+		lines = append(lines, "primary key ("+strings.Join(c.PrimaryKey, ", ")+")")
+	}
+	for _, idx := range c.DroppedIndexes {
+		lines = append(lines, "index "+idx.Name+" dropped")
+	}
+	for _, idx := range c.AddedIndexes {
+		lines = append(lines, "index "+idx.Name+" added ("+strings.Join(idx.Columns, ", ")+")")
+	}
+	for _, fk := range c.DroppedFKs {
+		lines = append(lines, "foreign key "+fkLabel(fk)+" dropped")
+	}
+	for _, fk := range c.AddedFKs {
+		lines = append(lines, "foreign key "+fkLabel(fk)+" added")
+	}
+	return lines
+}
+
+// fkLabel renders a foreign key as "(cols) → table(cols)".
+func fkLabel(fk ForeignKey) string {
+	return "(" + strings.Join(fk.Columns, ", ") + ") → " + fk.RefTable + "(" + strings.Join(fk.RefColumns, ", ") + ")"
+}
+
+// PrimaryKey returns the names of the primary key columns of t, in column
+// order.
+func (t Table) PrimaryKey() []string {
+	var pk []string
+	for _, c := range t.Columns {
+		if c.IsPK {
+			pk = append(pk, c.Name)
+		}
+	}
+	return pk
+}
+
+// Compare lists the objects that differ between old and cur, the schemas
+// of two databases, so that a migration can turn old into cur. Schemas are
+// matched by name, except that a single schema on each side is compared
+// with the other whatever their names ("main" against "public"). Foreign
+// keys are matched by their columns and target rather than their names,
+// which some databases make up.
+func Compare(old, cur []Database) []ObjectDiff {
+	before, after := schemasByName(old), schemasByName(cur)
+	if len(before) == 1 && len(after) == 1 {
+		for name := range before {
+			for _, s := range after {
+				after = map[string]Schema{name: s}
+			}
+		}
+	}
+
+	var diffs []ObjectDiff
+	for name, s := range before {
+		if n, ok := after[name]; ok {
+			diffs = append(diffs, compareSchemas(name, s, n)...)
+			continue
+		}
+		diffs = append(diffs, ObjectDiff{Kind: KindSchema, Schema: name, Name: name, Change: Dropped})
+		diffs = append(diffs, compareSchemas(name, s, Schema{})...)
+	}
+	for name, s := range after {
+		if _, ok := before[name]; !ok {
+			diffs = append(diffs, ObjectDiff{Kind: KindSchema, Schema: name, Name: name, Change: Added})
+			diffs = append(diffs, compareSchemas(name, Schema{}, s)...)
+		}
+	}
+	slices.SortStableFunc(diffs, func(a, b ObjectDiff) int {
+		return cmp.Or(
+			strings.Compare(a.Schema, b.Schema),
+			cmp.Compare(slices.Index(kindOrder, a.Kind), slices.Index(kindOrder, b.Kind)),
+			strings.Compare(a.Label(), b.Label()),
+		)
+	})
+	return diffs
+}
+
+// schemasByName maps the name of each schema in dbs to it. Databases
+// listed by name only have no schemas and are left out.
+func schemasByName(dbs []Database) map[string]Schema {
+	schemas := make(map[string]Schema)
+	for _, db := range dbs {
+		for _, s := range db.Schemas {
+			if _, ok := schemas[s.Name]; !ok {
+				schemas[s.Name] = s
+			}
+		}
+	}
+	return schemas
+}
+
+// compareSchemas lists the objects that differ between two versions of
+// schema name.
+func compareSchemas(name string, old, cur Schema) []ObjectDiff {
+	var diffs []ObjectDiff
+	add := func(d ObjectDiff) {
+		d.Schema = name
+		diffs = append(diffs, d)
+	}
+
+	oldTables := byName(old.Tables, func(t Table) string { return t.Name })
+	for i, t := range cur.Tables {
+		prev, ok := oldTables[t.Name]
+		if !ok {
+			add(ObjectDiff{Kind: KindTable, Name: t.Name, Change: Added, Table: &cur.Tables[i]})
+		} else if ch := compareTables(prev, t); ch != nil {
+			add(ObjectDiff{Kind: KindTable, Name: t.Name, Change: Altered, Table: &cur.Tables[i], Changes: ch})
+		}
+	}
+	curTables := byName(cur.Tables, func(t Table) string { return t.Name })
+	for i, t := range old.Tables {
+		if _, ok := curTables[t.Name]; !ok {
+			add(ObjectDiff{Kind: KindTable, Name: t.Name, Change: Dropped, Table: &old.Tables[i]})
+		}
+	}
+
+	oldViews := byName(old.Views, func(v View) string { return v.Name })
+	for i, v := range cur.Views {
+		prev, ok := oldViews[v.Name]
+		switch {
+		case !ok:
+			add(ObjectDiff{Kind: KindView, Name: v.Name, Change: Added, View: &cur.Views[i]})
+		case fmt.Sprint(prev) != fmt.Sprint(v):
+			add(ObjectDiff{Kind: KindView, Name: v.Name, Change: Altered, View: &cur.Views[i]})
+		}
+	}
+	curViews := byName(cur.Views, func(v View) string { return v.Name })
+	for i, v := range old.Views {
+		if _, ok := curViews[v.Name]; !ok {
+			add(ObjectDiff{Kind: KindView, Name: v.Name, Change: Dropped, View: &old.Views[i]})
+		}
+	}
+
+	oldSeqs := byName(old.Sequences, func(q Sequence) string { return q.Name })
+	curSeqs := byName(cur.Sequences, func(q Sequence) string { return q.Name })
+	for _, q := range cur.Sequences {
+		if _, ok := oldSeqs[q.Name]; !ok {
+			add(ObjectDiff{Kind: KindSequence, Name: q.Name, Change: Added})
+		}
+	}
+	for _, q := range old.Sequences {
+		if _, ok := curSeqs[q.Name]; !ok {
+			add(ObjectDiff{Kind: KindSequence, Name: q.Name, Change: Dropped})
+		}
+	}
+
+	// Overloads share a name; each signature is an object.
+	signature := func(r Routine) string { return r.Name + "(" + r.Args + ")" }
+	oldRoutines, curRoutines := byName(old.Routines, signature), byName(cur.Routines, signature)
+	for i, r := range cur.Routines {
+		prev, ok := oldRoutines[signature(r)]
+		switch {
+		case !ok:
+			add(ObjectDiff{Kind: KindRoutine, Name: r.Name, Change: Added, Routine: &cur.Routines[i]})
+		case prev != r:
+			add(ObjectDiff{Kind: KindRoutine, Name: r.Name, Change: Altered, Routine: &cur.Routines[i]})
+		}
+	}
+	for i, r := range old.Routines {
+		if _, ok := curRoutines[signature(r)]; !ok {
+			add(ObjectDiff{Kind: KindRoutine, Name: r.Name, Change: Dropped, Routine: &old.Routines[i]})
+		}
+	}
+
+	trigger := func(t Trigger) string { return t.Table + "." + t.Name }
+	oldTriggers, curTriggers := byName(old.Triggers, trigger), byName(cur.Triggers, trigger)
+	for i, t := range cur.Triggers {
+		prev, ok := oldTriggers[trigger(t)]
+		switch {
+		case !ok:
+			add(ObjectDiff{Kind: KindTrigger, Name: t.Name, Change: Added, Trigger: &cur.Triggers[i]})
+		case prev != t:
+			add(ObjectDiff{Kind: KindTrigger, Name: t.Name, Change: Altered, Trigger: &cur.Triggers[i]})
+		}
+	}
+	for i, t := range old.Triggers {
+		if _, ok := curTriggers[trigger(t)]; !ok {
+			add(ObjectDiff{Kind: KindTrigger, Name: t.Name, Change: Dropped, Trigger: &old.Triggers[i]})
+		}
+	}
+	return diffs
+}
+
+// compareTables returns how t changed from prev, or nil when it did not.
+// A table whose columns were not loaded (a lazily loaded schema) is taken
+// as unchanged.
+func compareTables(prev, t Table) *TableChanges {
+	if len(prev.Columns) == 0 || len(t.Columns) == 0 {
+		return nil
+	}
+	var ch TableChanges
+
+	oldCols := byName(prev.Columns, func(c Column) string { return c.Name })
+	curCols := byName(t.Columns, func(c Column) string { return c.Name })
+	for _, c := range t.Columns {
+		o, ok := oldCols[c.Name]
+		switch {
+		case !ok:
+			ch.AddedColumns = append(ch.AddedColumns, c)
+		case !strings.EqualFold(o.Type, c.Type) || o.Nullable != c.Nullable || o.Default != c.Default:
+			ch.AlteredColumns = append(ch.AlteredColumns, ColumnChange{Old: o, New: c})
+		}
+	}
+	for _, c := range prev.Columns {
+		if _, ok := curCols[c.Name]; !ok {
+			ch.DroppedColumns = append(ch.DroppedColumns, c)
+		}
+	}
+	if pk := t.PrimaryKey(); !slices.Equal(prev.PrimaryKey(), pk) {
+		ch.PrimaryKey, ch.PKChanged = pk, true
+	}
+
+	index := func(i Index) string { return i.Name }
+	oldIdx, curIdx := byName(prev.Indexes, index), byName(t.Indexes, index)
+	for _, i := range t.Indexes {
+		o, ok := oldIdx[i.Name]
+		if ok && o.Unique == i.Unique && slices.Equal(o.Columns, i.Columns) {
+			continue
+		}
+		if ok {
+			ch.DroppedIndexes = append(ch.DroppedIndexes, o)
+		}
+		ch.AddedIndexes = append(ch.AddedIndexes, i)
+	}
+	for _, i := range prev.Indexes {
+		if _, ok := curIdx[i.Name]; !ok {
+			ch.DroppedIndexes = append(ch.DroppedIndexes, i)
+		}
+	}
+
+	oldFKs, curFKs := byName(prev.FKs, fkLabel), byName(t.FKs, fkLabel)
+	for _, fk := range t.FKs {
+		if _, ok := oldFKs[fkLabel(fk)]; !ok {
+			ch.AddedFKs = append(ch.AddedFKs, fk)
+		}
+	}
+	for _, fk := range prev.FKs {
+		if _, ok := curFKs[fkLabel(fk)]; !ok {
+			ch.DroppedFKs = append(ch.DroppedFKs, fk)
+		}
+	}
+
+	if len(ch.AddedColumns)+len(ch.DroppedColumns)+len(ch.AlteredColumns)+
+		len(ch.AddedIndexes)+len(ch.DroppedIndexes)+len(ch.AddedFKs)+len(ch.DroppedFKs) == 0 && !ch.PKChanged {
+		return nil
+	}
+	return &ch
+}
+
+// byName maps the key of each item to it.
+func byName[T any](items []T, key func(T) string) map[string]T {
+	m := make(map[string]T, len(items))
+	for _, item := range items {
+		m[key(item)] = item
+	}
+	return m
+}
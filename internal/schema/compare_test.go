@@ -0,0 +1,92 @@
+package schema
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	old := []Database{{Name: "dev.db", Schemas: []Schema{{
+		Name: "main",
+		Tables: []Table{
+			{
+				Name: "users",
+				Columns: []Column{
+					{Name: "id", Type: "integer", IsPK: true},
+					{Name: "name", Type: "text", Nullable: true},
+					{Name: "legacy", Type: "text", Nullable: true},
+				},
+				FKs: []ForeignKey{{Name: "fk_users_0", Columns: []string{"team_id"}, RefTable: "teams", RefColumns: []string{"id"}}},
+			},
+			{Name: "tmp", Columns: []Column{{Name: "x", Type: "text"}}},
+		},
+		Triggers: []Trigger{{Name: "touch", Table: "users", Timing: "AFTER", Event: "UPDATE"}},
+	}}}}
+	cur := []Database{{Name: "app", Schemas: []Schema{{
+		Name: "public",
+		Tables: []Table{
+			{
+				Name: "users",
+				Columns: []Column{
+					{Name: "id", Type: "INTEGER", IsPK: true},
+					{Name: "name", Type: "varchar(80)"},
+					{Name: "email", Type: "text", Nullable: true},
+				},
+				Indexes: []Index{{Name: "users_email", Columns: []string{"email"}, Unique: true}},
+				// The same key under another made-up name.
+				FKs: []ForeignKey{{Name: "fk_users_1", Columns: []string{"team_id"}, RefTable: "teams", RefColumns: []string{"id"}}},
+			},
+			{Name: "orders", Columns: []Column{{Name: "id", Type: "integer"}}},
+		},
+		Triggers: []Trigger{{Name: "touch", Table: "users", Timing: "BEFORE", Event: "UPDATE"}},
+	}}}}
+
+	var got []string
+	for _, d := range Compare(old, cur) {
+		got = append(got, d.Change.String()+" "+d.Kind+" "+d.Label())
+	}
+	want := []string{
+		"added table main.orders",
+		"dropped table main.tmp",
+		"altered table main.users",
+		"altered trigger main.users.touch",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("Compare = %q, want %q", got, want)
+	}
+
+	users := Compare(old, cur)[2]
+	details := users.Details()
+	wantDetails := []string{
+		"column email added (text)",
+		"column legacy dropped",
+		"column name: type text → varchar(80), not null",
+		"index users_email added (email)",
+	}
+	if !slices.Equal(details, wantDetails) {
+		t.Errorf("Details = %q, want %q", details, wantDetails)
+	}
+
+	if d := Compare(cur, cur); len(d) != 0 {
+		t.Errorf("a schema differs from itself: %v", d)
+	}
+}
+
+func TestCompare_Schemas(t *testing.T) {
+	old := []Database{{Name: "app", Schemas: []Schema{{Name: "public"}, {Name: "audit", Tables: []Table{{Name: "log"}}}}}}
+	cur := []Database{{Name: "app", Schemas: []Schema{{Name: "public"}, {Name: "billing", Sequences: []Sequence{{Name: "invoice_no"}}}}}}
+
+	var got []string
+	for _, d := range Compare(old, cur) {
+		got = append(got, d.Change.String()+" "+d.Kind+" "+d.Label())
+	}
+	want := []string{
+		"dropped schema audit",
+		"dropped table audit.log",
+		"added schema billing",
+		"added sequence billing.invoice_no",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Compare = %q, want %q", got, want)
+	}
+}
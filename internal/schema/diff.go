@@ -0,0 +1,82 @@
+package schema
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Changes lists the objects added, dropped and altered between two loads
+// of a schema, each as "schema.name" (or "database.schema.name" when
+// there are several databases).
+type Changes struct {
+	Added   []string
+	Dropped []string
+	Altered []string
+}
+
+// Empty reports whether nothing changed.
+func (c Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Dropped) == 0 && len(c.Altered) == 0
+}
+
+// Diff compares two loads of a schema. Tables, views, routines, sequences
+// and triggers are compared by name and definition; a table whose columns
+// were not loaded (a lazily loaded schema) by name alone.
+func Diff(old, cur []Database) Changes {
+	before, after := objects(old), objects(cur)
+	var c Changes
+	for name, def := range after {
+		prev, ok := before[name]
+		switch {
+		case !ok:
+			c.Added = append(c.Added, name)
+		case def != "" && prev != "" && def != prev:
+			c.Altered = append(c.Altered, name)
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			c.Dropped = append(c.Dropped, name)
+		}
+	}
+	slices.Sort(c.Added)
+	slices.Sort(c.Dropped)
+	slices.Sort(c.Altered)
+	return c
+}
+
+// objects maps the qualified name of every object in dbs to a description
+// of its definition, "" when it is not known.
+func objects(dbs []Database) map[string]string {
+	objs := make(map[string]string)
+	for _, db := range dbs {
+		for _, s := range db.Schemas {
+			prefix := s.Name + "."
+			if len(dbs) > 1 {
+				prefix = db.Name + "." + prefix
+			}
+			for _, t := range s.Tables {
+				def := ""
+				if len(t.Columns) > 0 {
+					def = fmt.Sprint(t.Columns, t.Indexes, t.FKs)
+				}
+				objs[prefix+t.Name] = def
+			}
+			for _, v := range s.Views {
+				objs[prefix+v.Name] = fmt.Sprint(v.Columns, v.Definition, v.Materialized)
+			}
+			for _, r := range s.Routines {
+				// Overloads share a name; each signature is an object.
+				objs[prefix+r.Name+"("+r.Args+")"] = r.Returns + " " + r.Kind
+			}
+			for _, q := range s.Sequences {
+				objs[prefix+q.Name] = "sequence"
+			}
+			for _, tr := range s.Triggers {
+				objs[prefix+tr.Table+"."+tr.Name] = strings.Join([]string{tr.Timing, tr.Event}, " ")
+			}
+		}
+	}
+	return objs
+}
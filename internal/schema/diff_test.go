@@ -0,0 +1,43 @@
+package schema
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	old := []Database{{Name: "app", Schemas: []Schema{{
+		Name: "public",
+		Tables: []Table{
+			{Name: "users", Columns: []Column{{Name: "id", Type: "integer"}}},
+			{Name: "orders", Columns: []Column{{Name: "id", Type: "integer"}}},
+			{Name: "tmp", Columns: []Column{{Name: "x", Type: "text"}}},
+			{Name: "big"}, // lazily loaded: names only
+		},
+		Routines: []Routine{{Name: "f", Args: "integer", Returns: "integer"}},
+	}}}}
+	cur := []Database{{Name: "app", Schemas: []Schema{{
+		Name: "public",
+		Tables: []Table{
+			{Name: "users", Columns: []Column{{Name: "id", Type: "integer"}}},
+			{Name: "orders", Columns: []Column{{Name: "id", Type: "bigint"}}},
+			{Name: "big", Columns: []Column{{Name: "id", Type: "integer"}}},
+			{Name: "payments"},
+		},
+		Routines: []Routine{{Name: "f", Args: "integer", Returns: "integer"}, {Name: "f", Args: "text", Returns: "integer"}},
+	}}}}
+
+	c := Diff(old, cur)
+	if want := []string{"public.f(text)", "public.payments"}; !slices.Equal(c.Added, want) {
+		t.Errorf("Added = %v, want %v", c.Added, want)
+	}
+	if want := []string{"public.tmp"}; !slices.Equal(c.Dropped, want) {
+		t.Errorf("Dropped = %v, want %v", c.Dropped, want)
+	}
+	if want := []string{"public.orders"}; !slices.Equal(c.Altered, want) {
+		t.Errorf("Altered = %v, want %v", c.Altered, want)
+	}
+	if !Diff(cur, cur).Empty() {
+		t.Error("a schema differs from itself")
+	}
+}
@@ -8,9 +8,12 @@ type Database struct {
 
 // Schema represents a database schema (e.g., "public" in PostgreSQL).
 type Schema struct {
-	Name   string
-	Tables []Table
-	Views  []View
+	Name      string
+	Tables    []Table
+	Views     []View
+	Routines  []Routine
+	Sequences []Sequence
+	Triggers  []Trigger
 }
 
 // Table represents a database table.
@@ -45,9 +48,66 @@ type ForeignKey struct {
 	RefColumns []string
 }
 
+// TableRef identifies a table across databases and schemas.
+type TableRef struct {
+	Database string
+	Schema   string
+	Table    string
+}
+
+// TableStats holds the approximate size of a table. Either field is -1 when
+// the database does not report it.
+type TableStats struct {
+	Rows  int64
+	Bytes int64
+}
+
 // View represents a database view.
 type View struct {
-	Name       string
-	Columns    []Column
-	Definition string
+	Name         string
+	Columns      []Column
+	Definition   string
+	Materialized bool // stores its rows and must be refreshed
+}
+
+// Routine represents a stored function or procedure.
+type Routine struct {
+	Name    string
+	Kind    string // "function" or "procedure"
+	Args    string // argument list as the database reports it, e.g. "a integer, b text"
+	Returns string // result type; empty for procedures
+}
+
+// Signature renders the routine as "name(args) → returns".
+func (r Routine) Signature() string {
+	s := r.Name + "(" + r.Args + ")"
+	if r.Returns != "" {
+		s += " → " + r.Returns
+	}
+	return s
+}
+
+// Sequence represents a sequence generator.
+type Sequence struct {
+	Name string
+}
+
+// Trigger represents a table trigger.
+type Trigger struct {
+	Name   string
+	Table  string
+	Timing string // BEFORE, AFTER or INSTEAD OF
+	Event  string // INSERT, UPDATE, DELETE, or several joined with " OR "
+}
+
+// Signature renders the trigger as "name: TIMING EVENT ON table".
+func (t Trigger) Signature() string {
+	s := t.Name + ":"
+	if t.Timing != "" {
+		s += " " + t.Timing
+	}
+	if t.Event != "" {
+		s += " " + t.Event
+	}
+	return s + " ON " + t.Table
 }
@@ -0,0 +1,126 @@
+// Package schemacache keeps the last schema introspected for each
+// connection on disk, so a reconnect can show it at once while the schema
+// is loaded again.
+package schemacache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+// Entry is the schema of one connection as last loaded.
+type Entry struct {
+	Key       string            `json:"key"`
+	Saved     time.Time         `json:"saved"`
+	Hash      string            `json:"hash"` // Hash of Databases
+	Lazy      bool              `json:"lazy"` // only table names were loaded
+	Databases []schema.Database `json:"databases"`
+}
+
+// Cache is a directory of entries, one file per connection, that expire
+// ttl after they were saved.
+type Cache struct {
+	dir string
+	ttl time.Duration
+}
+
+// New returns the cache kept in dir.
+func New(dir string, ttl time.Duration) *Cache {
+	return &Cache{dir: dir, ttl: ttl}
+}
+
+// DefaultDir returns ConfigDir()/schema-cache.
+func DefaultDir() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "schema-cache"), nil
+}
+
+// Hash returns a digest of databases, equal for equal schemas.
+func Hash(databases []schema.Database) string {
+	data, err := json.Marshal(databases)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// path returns the file the entry of key is kept in. Keys are DSNs, so
+// the name is a digest of the key rather than the key itself.
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:12])+".json")
+}
+
+// Load returns the entry of key, or nil when there is none or it has
+// expired; an expired entry is removed.
+func (c *Cache) Load(key string) (*Entry, error) {
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read schema cache: %w", err)
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		os.Remove(path) // unreadable: start over
+		return nil, fmt.Errorf("parse schema cache: %w", err)
+	}
+	if e.Key != key {
+		return nil, nil
+	}
+	if c.ttl > 0 && time.Since(e.Saved) > c.ttl {
+		os.Remove(path)
+		return nil, nil
+	}
+	return &e, nil
+}
+
+// Save writes e atomically, stamped with the current time. An empty Hash
+// is filled in.
+func (c *Cache) Save(e Entry) error {
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return fmt.Errorf("create schema cache dir: %w", err)
+	}
+	e.Saved = time.Now()
+	if e.Hash == "" {
+		e.Hash = Hash(e.Databases)
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal schema cache: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(c.dir, ".schema-*.json.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("close temp file: %w", err)
+	}
+	if err := os.Rename(tmpPath, c.path(e.Key)); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("rename temp file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package schemacache
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+func TestCache(t *testing.T) {
+	c := New(t.TempDir(), time.Hour)
+	dbs := []schema.Database{{Name: "app", Schemas: []schema.Schema{{Name: "public", Tables: []schema.Table{
+		{Name: "orders", Columns: []schema.Column{{Name: "id", Type: "integer", IsPK: true}}},
+	}}}}}
+
+	if e, err := c.Load("postgres://u:***@db/app"); err != nil || e != nil {
+		t.Fatalf("Load of a missing entry = %v, %v", e, err)
+	}
+	if err := c.Save(Entry{Key: "postgres://u:***@db/app", Lazy: true, Databases: dbs}); err != nil {
+		t.Fatal(err)
+	}
+	e, err := c.Load("postgres://u:***@db/app")
+	if err != nil || e == nil {
+		t.Fatalf("Load = %v, %v", e, err)
+	}
+	if !e.Lazy || e.Hash != Hash(dbs) || e.Databases[0].Schemas[0].Tables[0].Columns[0].Name != "id" {
+		t.Errorf("Load = %#v", e)
+	}
+	if e, _ := c.Load("postgres://u:***@db/other"); e != nil {
+		t.Error("another connection got the entry")
+	}
+
+	dbs[0].Schemas[0].Tables[0].Name = "order"
+	if Hash(dbs) == e.Hash {
+		t.Error("a renamed table kept the hash")
+	}
+
+	// An expired entry is removed.
+	expired := New(c.dir, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	if e, err := expired.Load("postgres://u:***@db/app"); err != nil || e != nil {
+		t.Errorf("Load of an expired entry = %v, %v", e, err)
+	}
+	if _, err := os.Stat(c.path("postgres://u:***@db/app")); !os.IsNotExist(err) {
+		t.Error("the expired entry was kept")
+	}
+}
+
+func TestSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	if snaps, err := Snapshots(dir + "/missing"); err != nil || snaps != nil {
+		t.Fatalf("Snapshots of a missing dir = %v, %v", snaps, err)
+	}
+	dbs := []schema.Database{{Name: "app", Schemas: []schema.Schema{{Name: "public"}}}}
+	if err := SaveSnapshot(dir, Snapshot{Name: "prod/before", Adapter: "postgres", Databases: dbs}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveSnapshot(dir, Snapshot{Name: "prod/after", Adapter: "postgres", Databases: dbs}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveSnapshot(dir, Snapshot{Name: " "}); err == nil {
+		t.Error("a snapshot without a name was saved")
+	}
+
+	snaps, err := Snapshots(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 2 || snaps[0].Name != "prod/after" || snaps[1].Name != "prod/before" {
+		t.Fatalf("Snapshots = %v, want the two, newest first", snaps)
+	}
+	if snaps[0].Adapter != "postgres" || snaps[0].Databases[0].Schemas[0].Name != "public" {
+		t.Errorf("snapshot read back as %#v", snaps[0])
+	}
+}
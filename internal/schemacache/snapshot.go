@@ -0,0 +1,94 @@
+package schemacache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+// Snapshot is a schema saved under a name, to compare a connection with
+// later. Unlike cache entries, snapshots never expire.
+type Snapshot struct {
+	Name      string            `json:"name"`
+	Adapter   string            `json:"adapter"` // dialect of the database it was taken from
+	Saved     time.Time         `json:"saved"`
+	Databases []schema.Database `json:"databases"`
+}
+
+// SnapshotDir returns ConfigDir()/schema-snapshots.
+func SnapshotDir() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "schema-snapshots"), nil
+}
+
+// snapshotFile returns the file name a snapshot called name is kept in:
+// the name with path separators and other awkward characters replaced.
+func snapshotFile(name string) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, name)
+	return strings.TrimLeft(safe, ".") + ".json"
+}
+
+// SaveSnapshot writes s into dir, stamped with the current time, replacing
+// a snapshot of the same name.
+func SaveSnapshot(dir string, s Snapshot) error {
+	if strings.TrimSpace(s.Name) == "" {
+		return fmt.Errorf("snapshot name is empty")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create snapshot dir: %w", err)
+	}
+	s.Saved = time.Now()
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("marshal snapshot: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, snapshotFile(s.Name)), data, 0o600); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	return nil
+}
+
+// Snapshots reads the snapshots in dir, newest first. A missing dir has
+// none; unreadable files are skipped.
+func Snapshots(dir string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read snapshot dir: %w", err)
+	}
+	var snaps []Snapshot
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		var s Snapshot
+		if json.Unmarshal(data, &s) != nil || s.Name == "" {
+			continue
+		}
+		snaps = append(snaps, s)
+	}
+	slices.SortFunc(snaps, func(a, b Snapshot) int { return b.Saved.Compare(a.Saved) })
+	return snaps, nil
+}
@@ -0,0 +1,130 @@
+// Package session saves the open tabs when gotermsql quits so they can be
+// restored on the next launch.
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sadopc/gotermsql/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Tab is an editor tab: its query and cursor position.
+type Tab struct {
+	Query  string `yaml:"query"`
+	Line   int    `yaml:"line"`   // zero-based cursor line
+	Column int    `yaml:"column"` // zero-based cursor column, in characters
+}
+
+// Session is the state of the tabs when gotermsql last quit.
+type Session struct {
+	Tabs       []Tab  `yaml:"tabs"`
+	Active     int    `yaml:"active"`               // index into Tabs
+	Connection string `yaml:"connection,omitempty"` // saved connection that was open
+}
+
+// Empty reports whether s has no text worth restoring.
+func (s *Session) Empty() bool {
+	if s == nil {
+		return true
+	}
+	for _, t := range s.Tabs {
+		if strings.TrimSpace(t.Query) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// DefaultPath returns ConfigDir()/session.yaml.
+func DefaultPath() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "session.yaml"), nil
+}
+
+// Load reads the session file at path. A missing file is a nil session.
+func Load(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read session: %w", err)
+	}
+	var s Session
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parse session: %w", err)
+	}
+	if s.Active < 0 || s.Active >= len(s.Tabs) {
+		s.Active = 0
+	}
+	return &s, nil
+}
+
+// LoadDefault reads the session from DefaultPath.
+func LoadDefault() (*Session, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return Load(path)
+}
+
+// Save writes s to path atomically. An empty session removes the file
+// instead, so there is nothing to offer next time.
+func Save(path string, s *Session) error {
+	if s.Empty() {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove session: %w", err)
+		}
+		return nil
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create config dir: %w", err)
+	}
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("marshal session: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".session-*.yaml.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("chmod temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("close temp file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("rename temp file: %w", err)
+	}
+	return nil
+}
+
+// SaveDefault writes s to DefaultPath.
+func SaveDefault(s *Session) error {
+	path, err := DefaultPath()
+	if err != nil {
+		return err
+	}
+	return Save(path, s)
+}
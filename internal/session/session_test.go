@@ -0,0 +1,80 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSaveLoad_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "session.yaml")
+	in := &Session{
+		Tabs: []Tab{
+			{Query: "SELECT 1"},
+			{Query: "SELECT *\nFROM users\nWHERE id = 1", Line: 2, Column: 6},
+		},
+		Active:     1,
+		Connection: "local-pg",
+	}
+	if err := Save(path, in); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("file mode = %o, want 600", perm)
+	}
+
+	out, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Fatalf("Load = %+v, want %+v", out, in)
+	}
+}
+
+func TestSave_EmptyRemoves(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.yaml")
+	if err := Save(path, &Session{Tabs: []Tab{{Query: "SELECT 1"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Save(path, &Session{Tabs: []Tab{{Query: "  \n"}}}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected an empty session to remove the file, stat err = %v", err)
+	}
+	s, err := Load(path)
+	if err != nil || s != nil {
+		t.Fatalf("Load of a missing file = %+v, %v; want nil, nil", s, err)
+	}
+	// Removing a file that is already gone is fine.
+	if err := Save(path, nil); err != nil {
+		t.Fatalf("Save(nil): %v", err)
+	}
+}
+
+func TestLoad_ClampsActive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.yaml")
+	if err := os.WriteFile(path, []byte("tabs:\n  - query: SELECT 1\nactive: 5\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Active != 0 {
+		t.Errorf("Active = %d, want 0", s.Active)
+	}
+
+	if err := os.WriteFile(path, []byte("tabs: [oops"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected an error for invalid YAML")
+	}
+}
@@ -0,0 +1,394 @@
+// Package telemetry exports a span for every query run to an
+// OpenTelemetry collector over OTLP/HTTP, so the latency seen in gotermsql
+// can be lined up with the database's own traces. Spans are batched and
+// sent in the background; an unreachable collector never holds up a query.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	batchSize     = 64              // spans sent in one request
+	flushInterval = 2 * time.Second // how often a partial batch is sent
+	queueSize     = 1024            // spans waiting to be sent before new ones are dropped
+	exportTimeout = 10 * time.Second
+	closeTimeout  = 5 * time.Second // how long Close waits for queued spans
+)
+
+// Options configures a Tracer.
+type Options struct {
+	// Endpoint is the collector's base URL, e.g. http://localhost:4318.
+	// Spans are posted to /v1/traces under it unless it already has a
+	// path.
+	Endpoint       string
+	Headers        map[string]string // sent with every export, e.g. for auth
+	ServiceName    string            // "gotermsql" if empty
+	ServiceVersion string
+
+	// Propagate appends the span's traceparent to each query as a
+	// sqlcommenter comment, so the database can join the trace.
+	Propagate bool
+
+	// Redact masks the secrets in a query, read the way the database
+	// system (the adapter name) reads it, before its text goes into a
+	// span. Nil exports the text as run.
+	Redact func(system, query string) string
+}
+
+// Tracer records query spans and exports them. A nil *Tracer records
+// nothing, so callers need not check whether tracing is on.
+type Tracer struct {
+	url       string
+	headers   map[string]string
+	resource  []attribute
+	version   string
+	propagate bool
+	redact    func(system, query string) string
+	client    *http.Client
+
+	spans chan span
+	done  chan struct{}
+	once  sync.Once
+}
+
+// New starts a Tracer exporting to opts.Endpoint.
+func New(opts Options) (*Tracer, error) {
+	endpoint, err := tracesURL(opts.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("telemetry: %w", err)
+	}
+	name := opts.ServiceName
+	if name == "" {
+		name = "gotermsql"
+	}
+	resource := []attribute{stringAttr("service.name", name)}
+	if opts.ServiceVersion != "" {
+		resource = append(resource, stringAttr("service.version", opts.ServiceVersion))
+	}
+	t := &Tracer{
+		url:       endpoint,
+		headers:   opts.Headers,
+		resource:  resource,
+		version:   opts.ServiceVersion,
+		propagate: opts.Propagate,
+		redact:    opts.Redact,
+		client:    &http.Client{Timeout: exportTimeout},
+		spans:     make(chan span, queueSize),
+		done:      make(chan struct{}),
+	}
+	go t.run()
+	return t, nil
+}
+
+// tracesURL returns the URL spans are posted to for endpoint.
+func tracesURL(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("endpoint %q is not an http(s) URL", endpoint)
+	}
+	if strings.Trim(u.Path, "/") == "" {
+		u.Path = "/v1/traces"
+	}
+	return u.String(), nil
+}
+
+// Span is a query being traced. A nil *Span ignores End.
+type Span struct {
+	tracer *Tracer
+	span   span
+}
+
+// Start begins a span for query, run on the database system (the adapter
+// name) and database. It returns the query to send, which carries the
+// span's traceparent when propagation is on; the span keeps its text
+// redacted.
+func (t *Tracer) Start(query, system, database string) (*Span, string) {
+	if t == nil {
+		return nil, query
+	}
+	text := query
+	if t.redact != nil {
+		text = t.redact(system, query)
+	}
+	s := &Span{tracer: t, span: span{
+		traceID: newID(16),
+		spanID:  newID(8),
+		name:    operation(query),
+		start:   time.Now(),
+		attrs: []attribute{
+			stringAttr("db.system.name", dbSystem(system)),
+			stringAttr("db.query.text", text),
+		},
+	}}
+	if database != "" {
+		s.span.attrs = append(s.span.attrs, stringAttr("db.namespace", database))
+	}
+	if s.span.name != "" {
+		s.span.attrs = append(s.span.attrs, stringAttr("db.operation.name", s.span.name))
+	} else {
+		s.span.name = "query"
+	}
+	if t.propagate {
+		query = withTraceparent(query, s.span.traceID, s.span.spanID)
+	}
+	return s, query
+}
+
+// End finishes the span and queues it for export. rows is the number of
+// rows returned or affected, or negative if it is not known yet (a
+// streamed result); err is the query's error, if it failed.
+func (s *Span) End(rows int64, err error) {
+	if s == nil {
+		return
+	}
+	s.span.end = time.Now()
+	if rows >= 0 {
+		s.span.attrs = append(s.span.attrs, intAttr("db.response.returned_rows", rows))
+	}
+	if err != nil {
+		s.span.err = err.Error()
+		s.span.attrs = append(s.span.attrs, stringAttr("error.type", fmt.Sprintf("%T", err)))
+	}
+	select {
+	case s.tracer.spans <- s.span:
+	default:
+	}
+}
+
+// Close sends the spans still queued, waiting a few seconds at most.
+func (t *Tracer) Close() {
+	if t == nil {
+		return
+	}
+	t.once.Do(func() { close(t.spans) })
+	select {
+	case <-t.done:
+	case <-time.After(closeTimeout):
+	}
+}
+
+func (t *Tracer) run() {
+	defer close(t.done)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	var batch []span
+	for {
+		select {
+		case s, ok := <-t.spans:
+			if !ok {
+				t.export(batch)
+				return
+			}
+			batch = append(batch, s)
+			if len(batch) >= batchSize {
+				t.export(batch)
+				batch = nil
+			}
+		case <-ticker.C:
+			t.export(batch)
+			batch = nil
+		}
+	}
+}
+
+// export posts batch to the collector. Failures are dropped: there is
+// nowhere useful to report them from the background.
+func (t *Tracer) export(batch []span) {
+	if len(batch) == 0 {
+		return
+	}
+	body, err := json.Marshal(t.request(batch))
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+// span is a finished (or finishing) query span.
+type span struct {
+	traceID, spanID string
+	name            string
+	start, end      time.Time
+	attrs           []attribute
+	err             string
+}
+
+// The OTLP/JSON encoding of an ExportTraceServiceRequest, limited to what
+// query spans use. 64-bit integers are strings, as in the protobuf JSON
+// mapping.
+type (
+	exportRequest struct {
+		ResourceSpans []resourceSpans `json:"resourceSpans"`
+	}
+	resourceSpans struct {
+		Resource   resource     `json:"resource"`
+		ScopeSpans []scopeSpans `json:"scopeSpans"`
+	}
+	resource struct {
+		Attributes []attribute `json:"attributes"`
+	}
+	scopeSpans struct {
+		Scope scope      `json:"scope"`
+		Spans []jsonSpan `json:"spans"`
+	}
+	scope struct {
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+	}
+	jsonSpan struct {
+		TraceID           string      `json:"traceId"`
+		SpanID            string      `json:"spanId"`
+		Name              string      `json:"name"`
+		Kind              int         `json:"kind"`
+		StartTimeUnixNano string      `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string      `json:"endTimeUnixNano"`
+		Attributes        []attribute `json:"attributes"`
+		Status            *status     `json:"status,omitempty"`
+	}
+	status struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+	attribute struct {
+		Key   string `json:"key"`
+		Value value  `json:"value"`
+	}
+	value struct {
+		StringValue *string `json:"stringValue,omitempty"`
+		IntValue    *string `json:"intValue,omitempty"`
+	}
+)
+
+const (
+	spanKindClient    = 3
+	statusCodeError   = 2
+	instrumentationID = "github.com/sadopc/gotermsql"
+)
+
+func stringAttr(key, v string) attribute {
+	return attribute{Key: key, Value: value{StringValue: &v}}
+}
+
+func intAttr(key string, v int64) attribute {
+	s := strconv.FormatInt(v, 10)
+	return attribute{Key: key, Value: value{IntValue: &s}}
+}
+
+func (t *Tracer) request(batch []span) exportRequest {
+	spans := make([]jsonSpan, len(batch))
+	for i, s := range batch {
+		spans[i] = jsonSpan{
+			TraceID:           s.traceID,
+			SpanID:            s.spanID,
+			Name:              s.name,
+			Kind:              spanKindClient,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:        s.attrs,
+		}
+		if s.err != "" {
+			spans[i].Status = &status{Code: statusCodeError, Message: s.err}
+		}
+	}
+	return exportRequest{ResourceSpans: []resourceSpans{{
+		Resource: resource{Attributes: t.resource},
+		ScopeSpans: []scopeSpans{{
+			Scope: scope{Name: instrumentationID, Version: t.version},
+			Spans: spans,
+		}},
+	}}}
+}
+
+// newID returns n random bytes in hex, as trace and span IDs are written.
+func newID(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// operation returns the first keyword of query, upper-cased, skipping
+// leading comments.
+func operation(query string) string {
+	q := strings.TrimSpace(query)
+	for {
+		switch {
+		case strings.HasPrefix(q, "--"):
+			i := strings.IndexByte(q, '\n')
+			if i < 0 {
+				return ""
+			}
+			q = strings.TrimSpace(q[i+1:])
+		case strings.HasPrefix(q, "/*"):
+			i := strings.Index(q, "*/")
+			if i < 0 {
+				return ""
+			}
+			q = strings.TrimSpace(q[i+2:])
+		default:
+			end := strings.IndexFunc(q, func(r rune) bool {
+				return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+			})
+			if end < 0 {
+				end = len(q)
+			}
+			return strings.ToUpper(q[:end])
+		}
+	}
+}
+
+// dbSystem maps an adapter name to the OpenTelemetry db.system.name value.
+func dbSystem(adapter string) string {
+	if adapter == "postgres" {
+		return "postgresql"
+	}
+	return adapter
+}
+
+// withTraceparent appends a sqlcommenter comment carrying the W3C
+// traceparent to query, before any trailing semicolon.
+func withTraceparent(query, traceID, spanID string) string {
+	trimmed := strings.TrimRight(query, " \t\r\n")
+	semi := strings.HasSuffix(trimmed, ";")
+	trimmed = strings.TrimRight(strings.TrimSuffix(trimmed, ";"), " \t\r\n")
+	comment := fmt.Sprintf("/*traceparent='00-%s-%s-01'*/", traceID, spanID)
+	// A trailing line comment would swallow the comment.
+	sep := " "
+	if i := strings.LastIndex(trimmed, "\n"); strings.Contains(trimmed[i+1:], "--") {
+		sep = "\n"
+	}
+	if semi {
+		return trimmed + sep + comment + ";"
+	}
+	return trimmed + sep + comment
+}
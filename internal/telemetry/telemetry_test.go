@@ -0,0 +1,154 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestTracer_Exports(t *testing.T) {
+	got := make(chan exportRequest, 1)
+	var header string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			t.Errorf("posted to %s", r.URL.Path)
+		}
+		header = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		var req exportRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("bad body: %v", err)
+		}
+		got <- req
+	}))
+	defer srv.Close()
+
+	tr, err := New(Options{Endpoint: srv.URL, Headers: map[string]string{"Authorization": "Bearer x"}, ServiceVersion: "1.2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, q := tr.Start("select * from users", "postgres", "shop")
+	if q != "select * from users" {
+		t.Errorf("query changed to %q without propagation", q)
+	}
+	s.End(3, nil)
+	s, _ = tr.Start("DELETE FROM nope", "sqlite", "")
+	s.End(-1, errors.New("no such table: nope"))
+	tr.Close()
+
+	req := <-got
+	if header != "Bearer x" {
+		t.Errorf("Authorization = %q", header)
+	}
+	rs := req.ResourceSpans[0]
+	if v := rs.Resource.Attributes[0]; v.Key != "service.name" || *v.Value.StringValue != "gotermsql" {
+		t.Errorf("resource = %+v", rs.Resource.Attributes)
+	}
+	spans := rs.ScopeSpans[0].Spans
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	ok := spans[0]
+	if ok.Name != "SELECT" || ok.Kind != spanKindClient || ok.Status != nil || len(ok.TraceID) != 32 || len(ok.SpanID) != 16 {
+		t.Errorf("span = %+v", ok)
+	}
+	attrs := map[string]string{}
+	for _, a := range ok.Attributes {
+		if a.Value.StringValue != nil {
+			attrs[a.Key] = *a.Value.StringValue
+		} else {
+			attrs[a.Key] = *a.Value.IntValue
+		}
+	}
+	if attrs["db.system.name"] != "postgresql" || attrs["db.namespace"] != "shop" ||
+		attrs["db.query.text"] != "select * from users" || attrs["db.response.returned_rows"] != "3" {
+		t.Errorf("attributes = %v", attrs)
+	}
+	failed := spans[1]
+	if failed.Status == nil || failed.Status.Code != statusCodeError || failed.Status.Message != "no such table: nope" {
+		t.Errorf("failed span status = %+v", failed.Status)
+	}
+	for _, a := range failed.Attributes {
+		if a.Key == "db.response.returned_rows" || a.Key == "db.namespace" {
+			t.Errorf("failed span has %s", a.Key)
+		}
+	}
+}
+
+func TestTracer_Nil(t *testing.T) {
+	var tr *Tracer
+	s, q := tr.Start("SELECT 1", "sqlite", "main")
+	if s != nil || q != "SELECT 1" {
+		t.Errorf("nil tracer: %v, %q", s, q)
+	}
+	s.End(1, nil)
+	tr.Close()
+}
+
+func TestTracer_Propagate(t *testing.T) {
+	tr, err := New(Options{Endpoint: "http://127.0.0.1:1", Propagate: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tr.Close()
+	s, q := tr.Start("SELECT 1;\n", "mysql", "")
+	want := "SELECT 1 /*traceparent='00-" + s.span.traceID + "-" + s.span.spanID + "-01'*/;"
+	if q != want {
+		t.Errorf("query = %q, want %q", q, want)
+	}
+}
+
+func TestTracer_Redact(t *testing.T) {
+	tr, err := New(Options{Endpoint: "http://127.0.0.1:1", Redact: func(system, q string) string {
+		return strings.Replace(q, "'hunter2'", "'***'", 1) + " -- " + system
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tr.Close()
+	s, q := tr.Start("UPDATE users SET password = 'hunter2'", "postgres", "")
+	if q != "UPDATE users SET password = 'hunter2'" {
+		t.Errorf("query sent = %q, want it as written", q)
+	}
+	for _, a := range s.span.attrs {
+		if a.Key == "db.query.text" && *a.Value.StringValue != "UPDATE users SET password = '***' -- postgres" {
+			t.Errorf("db.query.text = %q, want it redacted", *a.Value.StringValue)
+		}
+	}
+}
+
+func TestWithTraceparent_LineComment(t *testing.T) {
+	got := withTraceparent("SELECT 1 -- one", "t", "s")
+	if !strings.HasSuffix(got, "\n/*traceparent='00-t-s-01'*/") {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestOperation(t *testing.T) {
+	tests := map[string]string{
+		"select 1":                     "SELECT",
+		"  -- note\n/* x */ WITH a AS": "WITH",
+		"INSERT(":                      "INSERT",
+		"-- only a comment":            "",
+	}
+	for in, want := range tests {
+		if got := operation(in); got != want {
+			t.Errorf("operation(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNew_BadEndpoint(t *testing.T) {
+	for _, e := range []string{"", "localhost:4318", "ftp://x"} {
+		if _, err := New(Options{Endpoint: e}); err == nil {
+			t.Errorf("New(%q): expected an error", e)
+		}
+	}
+	if u, _ := tracesURL("https://otel.example.com/custom/path"); u != "https://otel.example.com/custom/path" {
+		t.Errorf("explicit path rewritten to %q", u)
+	}
+}
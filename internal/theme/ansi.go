@@ -0,0 +1,143 @@
+package theme
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// ParseProfile reads the colors setting of the config: "truecolor", "256",
+// "16" or "none" force that many colors, and "" or "auto" detect them from
+// the terminal (COLORTERM, then TERM's terminfo name). An unknown setting
+// is reported along with the detected profile.
+func ParseProfile(s string) (termenv.Profile, error) {
+	switch strings.ToLower(s) {
+	case "", "auto":
+		return lipgloss.ColorProfile(), nil
+	case "truecolor", "24bit":
+		return termenv.TrueColor, nil
+	case "256":
+		return termenv.ANSI256, nil
+	case "16", "ansi":
+		return termenv.ANSI, nil
+	case "none":
+		return termenv.Ascii, nil
+	}
+	return lipgloss.ColorProfile(), fmt.Errorf("unknown colors %q (auto, truecolor, 256, 16 or none)", s)
+}
+
+// ForProfile returns th as it should be drawn with the color profile p.
+// With true color or 256 colors that is th, since lipgloss finds close
+// colors among 256. With 16 it is a copy whose colors are each replaced by
+// the ANSI color of the same hue: taking the nearest of 16 turns most dark
+// backgrounds and muted text into the same black or gray, and the
+// terminal's own palette is made to be readable.
+func ForProfile(th *Theme, p termenv.Profile) *Theme {
+	if p != termenv.ANSI {
+		return th
+	}
+	ansi := *th
+	fields := reflect.ValueOf(&ansi).Elem()
+	for i := range fields.NumField() {
+		f := fields.Field(i)
+		if st, ok := f.Interface().(lipgloss.Style); ok {
+			f.Set(reflect.ValueOf(ansiStyle(st)))
+		}
+	}
+	return &ansi
+}
+
+// ansiStyle replaces the colors of st by ANSI colors.
+func ansiStyle(st lipgloss.Style) lipgloss.Style {
+	if c, ok := ansiColor(st.GetForeground(), false); ok {
+		st = st.Foreground(c)
+	}
+	if c, ok := ansiColor(st.GetBackground(), true); ok {
+		st = st.Background(c)
+	}
+	top, ok1 := ansiColor(st.GetBorderTopForeground(), false)
+	right, ok2 := ansiColor(st.GetBorderRightForeground(), false)
+	bottom, ok3 := ansiColor(st.GetBorderBottomForeground(), false)
+	left, ok4 := ansiColor(st.GetBorderLeftForeground(), false)
+	if ok1 || ok2 || ok3 || ok4 {
+		st = st.BorderForeground(top, right, bottom, left)
+	}
+	return st
+}
+
+// ansiColor returns the ANSI color for a hex color, as a foreground or a
+// background, reporting false for colors that are not hex.
+func ansiColor(c lipgloss.TerminalColor, background bool) (lipgloss.TerminalColor, bool) {
+	hex, ok := c.(lipgloss.Color)
+	if !ok || !strings.HasPrefix(string(hex), "#") {
+		return c, false
+	}
+	rgb, err := strconv.ParseUint(string(hex)[1:], 16, 32)
+	if err != nil || len(hex) != 7 {
+		return c, false
+	}
+	r := float64(rgb>>16&0xFF) / 255
+	g := float64(rgb>>8&0xFF) / 255
+	b := float64(rgb&0xFF) / 255
+	hi, lo := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	light := (hi + lo) / 2
+	var sat float64
+	if hi != lo {
+		sat = (hi - lo) / (1 - math.Abs(2*light-1))
+	}
+
+	// Grays go to black, bright black, white or bright white.
+	if sat < 0.25 || light < 0.12 || light > 0.92 {
+		steps := []float64{0.3, 0.6, 0.85}
+		if !background {
+			// Text is rarely meant to be black on a dark theme.
+			steps = []float64{0.15, 0.6, 0.85}
+		}
+		switch {
+		case light < steps[0]:
+			return lipgloss.Color("0"), true
+		case light < steps[1]:
+			return lipgloss.Color("8"), true
+		case light < steps[2]:
+			return lipgloss.Color("7"), true
+		}
+		return lipgloss.Color("15"), true
+	}
+
+	var hue float64
+	switch hi {
+	case r:
+		hue = math.Mod((g-b)/(hi-lo), 6)
+	case g:
+		hue = (b-r)/(hi-lo) + 2
+	default:
+		hue = (r-g)/(hi-lo) + 4
+	}
+	hue = math.Mod(hue*60+360, 360)
+	var n int
+	switch {
+	case hue < 15 || hue >= 345:
+		n = 1 // red
+	case hue < 70:
+		n = 3 // yellow
+	case hue < 165:
+		n = 2 // green
+	case hue < 200:
+		n = 6 // cyan
+	case hue < 260:
+		n = 4 // blue
+	default:
+		n = 5 // magenta
+	}
+	// Light text takes the bright variant; backgrounds stay dark unless
+	// they are light themselves.
+	if !background && light > 0.6 || background && light > 0.7 {
+		n += 8
+	}
+	return lipgloss.Color(strconv.Itoa(n)), true
+}
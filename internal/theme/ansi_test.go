@@ -0,0 +1,87 @@
+package theme
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestAnsiColor(t *testing.T) {
+	tests := []struct {
+		hex        string
+		background bool
+		want       string
+	}{
+		{"#1E1E1E", true, "0"},
+		{"#252526", true, "0"},
+		{"#264F78", true, "4"},
+		{"#FFFFFF", true, "15"},
+		{"#808080", false, "8"},
+		{"#D4D4D4", false, "7"},
+		{"#1E1E1E", false, "0"},
+		{"#F44747", false, "9"},
+		{"#6A9955", false, "2"},
+		{"#DCDCAA", false, "11"},
+		{"#C586C0", false, "13"},
+		{"#4EC9B0", false, "6"},
+	}
+	for _, tt := range tests {
+		got, ok := ansiColor(lipgloss.Color(tt.hex), tt.background)
+		if !ok || got != lipgloss.Color(tt.want) {
+			t.Errorf("ansiColor(%s, background %v) = %v, want %s", tt.hex, tt.background, got, tt.want)
+		}
+	}
+	if _, ok := ansiColor(lipgloss.Color("12"), false); ok {
+		t.Error("an ANSI color should be left alone")
+	}
+	if _, ok := ansiColor(lipgloss.NoColor{}, false); ok {
+		t.Error("no color should be left alone")
+	}
+}
+
+func TestForProfile(t *testing.T) {
+	d := Default()
+	for _, p := range []termenv.Profile{termenv.TrueColor, termenv.ANSI256, termenv.Ascii} {
+		if ForProfile(d, p) != d {
+			t.Errorf("profile %v should keep the theme", p)
+		}
+	}
+
+	ansi := ForProfile(d, termenv.ANSI)
+	if ansi.Name != d.Name {
+		t.Errorf("Name = %q, want %q", ansi.Name, d.Name)
+	}
+	if got := ansi.SidebarSelected.GetBackground(); got != lipgloss.Color("4") {
+		t.Errorf("SidebarSelected background = %v, want 4", got)
+	}
+	if got := ansi.FocusedBorder.GetBorderTopForeground(); got == d.FocusedBorder.GetBorderTopForeground() {
+		t.Errorf("FocusedBorder border color was kept: %v", got)
+	}
+	if !ansi.SQLKeyword.GetBold() {
+		t.Error("attributes other than colors should be kept")
+	}
+	if d.SidebarSelected.GetBackground() != lipgloss.Color("#264F78") {
+		t.Error("ForProfile changed the theme it was given")
+	}
+}
+
+func TestParseProfile(t *testing.T) {
+	tests := map[string]termenv.Profile{
+		"truecolor": termenv.TrueColor,
+		"256":       termenv.ANSI256,
+		"16":        termenv.ANSI,
+		"none":      termenv.Ascii,
+	}
+	for s, want := range tests {
+		if got, err := ParseProfile(s); err != nil || got != want {
+			t.Errorf("ParseProfile(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	if _, err := ParseProfile("auto"); err != nil {
+		t.Errorf("ParseProfile(auto): %v", err)
+	}
+	if _, err := ParseProfile("lots"); err == nil {
+		t.Error("ParseProfile(lots) should fail")
+	}
+}
@@ -0,0 +1,214 @@
+package theme
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadDir registers the themes defined by the .yaml and .yml files in
+// dir, each named after its file unless it sets a name. A missing dir is
+// not an error; files that cannot be read are skipped and reported together.
+func LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("themes: %w", err)
+	}
+	var errs []error
+	for _, e := range entries {
+		switch filepath.Ext(e.Name()) {
+		case ".yaml", ".yml":
+		default:
+			continue
+		}
+		if e.IsDir() {
+			continue
+		}
+		th, err := loadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		Themes[th.Name] = th
+	}
+	return errors.Join(errs...)
+}
+
+// loadFile reads a theme file. It holds an optional name, the theme to
+// start from as base ("default" if not set), and styles, which sets
+// attributes of the base theme's styles by their field names in snake case:
+//
+//	base: default
+//	styles:
+//	  sql_keyword: {fg: "#FF79C6", bold: true}
+//	  focused_border: {border: rounded, border_fg: "#BD93F9"}
+func loadFile(path string) (*Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	th, err := buildTheme(name, doc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return th, nil
+}
+
+// buildTheme makes the theme a file describes.
+func buildTheme(name string, doc map[string]any) (*Theme, error) {
+	base := "default"
+	var styles map[string]any
+	for _, k := range sortedKeys(doc) {
+		var ok bool
+		switch v := doc[k]; k {
+		case "name":
+			name, ok = v.(string)
+		case "base":
+			base, ok = v.(string)
+		case "styles":
+			styles, ok = v.(map[string]any)
+		default:
+			return nil, fmt.Errorf("unknown key %q", k)
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s: unexpected value %v", k, doc[k])
+		}
+	}
+	from, ok := Themes[base]
+	if !ok {
+		return nil, fmt.Errorf("base: no theme named %q", base)
+	}
+	th := *from
+	th.Name = name
+
+	fields := reflect.ValueOf(&th).Elem()
+	for _, k := range sortedKeys(styles) {
+		f := styleField(fields, k)
+		if !f.IsValid() {
+			return nil, fmt.Errorf("styles: unknown style %q", k)
+		}
+		attrs, ok := styles[k].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("styles.%s: unexpected value %v", k, styles[k])
+		}
+		st, err := applyStyle(f.Interface().(lipgloss.Style), attrs)
+		if err != nil {
+			return nil, fmt.Errorf("styles.%s: %w", k, err)
+		}
+		f.Set(reflect.ValueOf(st))
+	}
+	return &th, nil
+}
+
+// styleField returns the style field of a Theme named, in any case and
+// with or without underscores, by name; sql_keyword is SQLKeyword.
+func styleField(th reflect.Value, name string) reflect.Value {
+	want := strings.ToLower(strings.ReplaceAll(name, "_", ""))
+	for i := range th.NumField() {
+		f := th.Type().Field(i)
+		if f.Type == reflect.TypeFor[lipgloss.Style]() && strings.ToLower(f.Name) == want {
+			return th.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
+
+// borders are the border names a theme file can use.
+var borders = map[string]lipgloss.Border{
+	"normal":  lipgloss.NormalBorder(),
+	"rounded": lipgloss.RoundedBorder(),
+	"thick":   lipgloss.ThickBorder(),
+	"double":  lipgloss.DoubleBorder(),
+	"block":   lipgloss.BlockBorder(),
+	"hidden":  lipgloss.HiddenBorder(),
+	"none":    {},
+}
+
+// applyStyle sets the attributes a theme file gives a style.
+func applyStyle(st lipgloss.Style, attrs map[string]any) (lipgloss.Style, error) {
+	for _, k := range sortedKeys(attrs) {
+		v := attrs[k]
+		str, isStr := v.(string)
+		b, isBool := v.(bool)
+		n, isInt := v.(int)
+		if i64, ok := v.(int64); ok {
+			n, isInt = int(i64), true
+		}
+		var ok bool
+		switch k {
+		case "fg":
+			st, ok = st.Foreground(lipgloss.Color(str)), isStr
+		case "bg":
+			st, ok = st.Background(lipgloss.Color(str)), isStr
+		case "bold":
+			st, ok = st.Bold(b), isBool
+		case "italic":
+			st, ok = st.Italic(b), isBool
+		case "underline":
+			st, ok = st.Underline(b), isBool
+		case "faint":
+			st, ok = st.Faint(b), isBool
+		case "reverse":
+			st, ok = st.Reverse(b), isBool
+		case "border":
+			var border lipgloss.Border
+			if border, ok = borders[str]; !ok {
+				return st, fmt.Errorf("border: unknown border %q", str)
+			}
+			st = st.BorderStyle(border)
+		case "border_fg":
+			st, ok = st.BorderForeground(lipgloss.Color(str)), isStr
+		case "border_bg":
+			st, ok = st.BorderBackground(lipgloss.Color(str)), isStr
+		case "border_top":
+			st, ok = st.BorderTop(b), isBool
+		case "border_right":
+			st, ok = st.BorderRight(b), isBool
+		case "border_bottom":
+			st, ok = st.BorderBottom(b), isBool
+		case "border_left":
+			st, ok = st.BorderLeft(b), isBool
+		case "padding_top":
+			st, ok = st.PaddingTop(n), isInt
+		case "padding_right":
+			st, ok = st.PaddingRight(n), isInt
+		case "padding_bottom":
+			st, ok = st.PaddingBottom(n), isInt
+		case "padding_left":
+			st, ok = st.PaddingLeft(n), isInt
+		default:
+			return st, fmt.Errorf("unknown attribute %q", k)
+		}
+		if !ok {
+			return st, fmt.Errorf("%s: unexpected value %v", k, v)
+		}
+	}
+	return st, nil
+}
+
+// sortedKeys returns the keys of m in order, so errors are reported the
+// same way each time.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,108 @@
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func writeTheme(t *testing.T, dir, name, text string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	writeTheme(t, dir, "ocean.yaml", `
+base: light
+styles:
+  sql_keyword: {fg: "#FF79C6", bold: true}
+  FocusedBorder: {border: double, border_fg: "#BD93F9"}
+`)
+	writeTheme(t, dir, "forest.yml", `
+# Greens everywhere
+name: deep-forest
+styles:
+  sql_keyword:
+    fg: "#A6E22E" # keywords
+    italic: true
+  tab_active: {bg: "#1B2B1B", padding_left: 2, border_bottom: false}
+`)
+	writeTheme(t, dir, "dusk.toml", "[styles.sql_keyword]\nfg = \"#fff\"\n")
+	writeTheme(t, dir, "notes.txt", "not a theme")
+	defer func() {
+		delete(Themes, "ocean")
+		delete(Themes, "deep-forest")
+	}()
+
+	if err := LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+
+	ocean := Themes["ocean"]
+	if ocean == nil || ocean.Name != "ocean" {
+		t.Fatalf("ocean.yaml not registered as ocean: %+v", ocean)
+	}
+	if got := ocean.SQLKeyword.GetForeground(); got != lipgloss.Color("#FF79C6") || !ocean.SQLKeyword.GetBold() {
+		t.Errorf("ocean SQLKeyword = %v, bold %v", got, ocean.SQLKeyword.GetBold())
+	}
+	if ocean.FocusedBorder.GetBorderStyle() != lipgloss.DoubleBorder() {
+		t.Error("ocean FocusedBorder should have a double border")
+	}
+	// Styles the file leaves alone come from its base.
+	if ocean.SQLString.GetForeground() != Themes["light"].SQLString.GetForeground() {
+		t.Error("ocean SQLString should be light's")
+	}
+
+	forest := Themes["deep-forest"]
+	if forest == nil {
+		t.Fatal("forest.yml not registered under its name")
+	}
+	if got := forest.SQLKeyword.GetForeground(); got != lipgloss.Color("#A6E22E") || !forest.SQLKeyword.GetItalic() {
+		t.Errorf("forest SQLKeyword = %v, italic %v", got, forest.SQLKeyword.GetItalic())
+	}
+	if forest.TabActive.GetPaddingLeft() != 2 || forest.TabActive.GetBackground() != lipgloss.Color("#1B2B1B") {
+		t.Error("forest TabActive should take the inline map")
+	}
+	if Themes["dusk"] != nil {
+		t.Error("only YAML files are themes")
+	}
+	if Default().SQLKeyword.GetForeground() == forest.SQLKeyword.GetForeground() {
+		t.Error("loading a theme changed its base")
+	}
+}
+
+func TestLoadDir_Errors(t *testing.T) {
+	if err := LoadDir(filepath.Join(t.TempDir(), "missing")); err != nil {
+		t.Errorf("a missing directory: %v", err)
+	}
+
+	tests := []struct {
+		file, text, want string
+	}{
+		{"a.yaml", "styles:\n  sidebar_tittle: {fg: red}\n", `unknown style "sidebar_tittle"`},
+		{"b.yaml", "styles:\n  sql_keyword: {colour: red}\n", `unknown attribute "colour"`},
+		{"c.yaml", "styles:\n  sql_keyword: {bold: yes please}\n", "bold: unexpected value"},
+		{"d.yaml", "base: neon\n", `no theme named "neon"`},
+		{"e.yml", "styles:\n  sql_keyword: {fg: [1, 2]}\n", "fg: unexpected value"},
+		{"g.yaml", "styles:\n  focused_border: {border: wavy}\n", `unknown border "wavy"`},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		writeTheme(t, dir, tt.file, tt.text)
+		err := LoadDir(dir)
+		if err == nil || !strings.Contains(err.Error(), tt.want) || !strings.Contains(err.Error(), tt.file) {
+			t.Errorf("%s: LoadDir() = %v, want an error naming the file with %q", tt.file, err, tt.want)
+		}
+		name := strings.TrimSuffix(tt.file, filepath.Ext(tt.file))
+		if _, ok := Themes[name]; ok {
+			delete(Themes, name)
+			t.Errorf("%s: a theme that failed to load was registered", tt.file)
+		}
+	}
+}
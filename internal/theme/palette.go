@@ -0,0 +1,276 @@
+package theme
+
+import "github.com/charmbracelet/lipgloss"
+
+// palette holds the colors of a theme built by newPaletteTheme, by the
+// role they play.
+type palette struct {
+	Bg         string // editor and app background
+	BgAlt      string // headers, inactive tabs, popups
+	BgRow      string // every other results row
+	BgBar      string // tab bar
+	Selection  string // selected rows and items, unfocused borders
+	Fg         string // text
+	OnColor    string // text on the accent colors
+	Muted      string // comments, types, NULL, secondary text
+	LineNumber string
+
+	Accent string // keywords, focused borders, dialogs
+	Red    string
+	Orange string
+	Yellow string
+	Green  string
+	Cyan   string
+	Purple string
+}
+
+// newPaletteTheme builds a theme laid out like monokai from the colors of
+// p.
+func newPaletteTheme(name string, p palette) *Theme {
+	return &Theme{
+		Name: name,
+
+		// App-level
+		AppBackground: lipgloss.NewStyle().
+			Background(lipgloss.Color(p.Bg)),
+
+		// Sidebar
+		SidebarBorder: lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color(p.Selection)),
+		SidebarTitle: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(p.Accent)).
+			PaddingLeft(1),
+		SidebarDatabase: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(p.Yellow)),
+		SidebarSchema: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Cyan)),
+		SidebarTable: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Green)),
+		SidebarView: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Purple)),
+		SidebarMatView: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Yellow)).
+			Italic(true),
+		SidebarColumn: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Fg)),
+		SidebarColumnType: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Muted)).
+			Italic(true),
+		SidebarSelected: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(p.Fg)).
+			Background(lipgloss.Color(p.Selection)),
+		SidebarMatch: lipgloss.NewStyle().
+			Bold(true).
+			Underline(true).
+			Foreground(lipgloss.Color(p.Orange)),
+
+		// Editor
+		EditorBorder: lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color(p.Selection)),
+		EditorLineNumber: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.LineNumber)),
+		EditorCursor: lipgloss.NewStyle().
+			Background(lipgloss.Color(p.Fg)),
+
+		// SQL Syntax highlighting
+		SQLKeyword: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(p.Accent)),
+		SQLString: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Yellow)),
+		SQLNumber: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Purple)),
+		SQLComment: lipgloss.NewStyle().
+			Italic(true).
+			Foreground(lipgloss.Color(p.Muted)),
+		SQLOperator: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Accent)),
+		SQLFunction: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Green)),
+		SQLType: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Cyan)).
+			Italic(true),
+		SQLIdentifier: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Fg)),
+
+		// Results table
+		ResultsBorder: lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color(p.Selection)),
+		ResultsHeader: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(p.Green)).
+			Background(lipgloss.Color(p.BgAlt)).
+			Padding(0, 1),
+		ResultsCell: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Fg)).
+			Padding(0, 1),
+		ResultsCellAlt: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Fg)).
+			Background(lipgloss.Color(p.BgRow)).
+			Padding(0, 1),
+		ResultsSelectedRow: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Fg)).
+			Background(lipgloss.Color(p.Selection)).
+			Padding(0, 1),
+		ResultsNull: lipgloss.NewStyle().
+			Italic(true).
+			Foreground(lipgloss.Color(p.Muted)),
+
+		// Tab bar
+		TabActive: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(p.Fg)).
+			Background(lipgloss.Color(p.Bg)).
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderBottom(false).
+			BorderForeground(lipgloss.Color(p.Accent)).
+			PaddingLeft(1).
+			PaddingRight(1),
+		TabInactive: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Muted)).
+			Background(lipgloss.Color(p.BgAlt)).
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderBottom(true).
+			BorderForeground(lipgloss.Color(p.Selection)).
+			PaddingLeft(1).
+			PaddingRight(1),
+		TabBar: lipgloss.NewStyle().
+			Background(lipgloss.Color(p.BgBar)),
+
+		// Status bar
+		StatusBar: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Fg)).
+			Background(lipgloss.Color(p.Selection)),
+		StatusBarKey: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(p.OnColor)).
+			Background(lipgloss.Color(p.Accent)).
+			PaddingLeft(1).
+			PaddingRight(1),
+		StatusBarValue: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Fg)).
+			Background(lipgloss.Color(p.BgAlt)).
+			PaddingLeft(1).
+			PaddingRight(1),
+		StatusBarError: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(p.OnColor)).
+			Background(lipgloss.Color(p.Red)),
+		StatusBarSuccess: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(p.OnColor)).
+			Background(lipgloss.Color(p.Green)),
+
+		// Autocomplete
+		AutocompleteItem: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Fg)).
+			Background(lipgloss.Color(p.BgAlt)).
+			PaddingLeft(1).
+			PaddingRight(1),
+		AutocompleteSelected: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Fg)).
+			Background(lipgloss.Color(p.Selection)).
+			PaddingLeft(1).
+			PaddingRight(1),
+		AutocompleteBorder: lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color(p.Accent)),
+
+		// Dialog/Modal
+		DialogBorder: lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color(p.Accent)).
+			Padding(1, 2),
+		DialogTitle: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(p.Accent)),
+		DialogButton: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Fg)).
+			Background(lipgloss.Color(p.Selection)).
+			PaddingLeft(2).
+			PaddingRight(2),
+		DialogButtonActive: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(p.OnColor)).
+			Background(lipgloss.Color(p.Green)).
+			PaddingLeft(2).
+			PaddingRight(2),
+
+		// General
+		FocusedBorder: lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color(p.Accent)),
+		UnfocusedBorder: lipgloss.NewStyle().
+			BorderStyle(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color(p.Selection)),
+		ErrorText: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color(p.Red)),
+		SuccessText: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Green)),
+		WarningText: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Yellow)),
+		MutedText: lipgloss.NewStyle().
+			Foreground(lipgloss.Color(p.Muted)),
+	}
+}
+
+// dracula is the Dracula palette (draculatheme.com).
+var dracula = palette{
+	Bg: "#282A36", BgAlt: "#343746", BgRow: "#2D2F3B", BgBar: "#21222C",
+	Selection: "#44475A", Fg: "#F8F8F2", OnColor: "#282A36",
+	Muted: "#6272A4", LineNumber: "#6272A4",
+	Accent: "#FF79C6", Red: "#FF5555", Orange: "#FFB86C", Yellow: "#F1FA8C",
+	Green: "#50FA7B", Cyan: "#8BE9FD", Purple: "#BD93F9",
+}
+
+// solarizedDark and solarizedLight are Solarized (ethanschoonover.com/solarized).
+var (
+	solarizedDark = palette{
+		Bg: "#002B36", BgAlt: "#073642", BgRow: "#01313D", BgBar: "#00212B",
+		Selection: "#094959", Fg: "#93A1A1", OnColor: "#FDF6E3",
+		Muted: "#657B83", LineNumber: "#586E75",
+		Accent: "#268BD2", Red: "#DC322F", Orange: "#CB4B16", Yellow: "#B58900",
+		Green: "#859900", Cyan: "#2AA198", Purple: "#6C71C4",
+	}
+	solarizedLight = palette{
+		Bg: "#FDF6E3", BgAlt: "#EEE8D5", BgRow: "#F6F0DD", BgBar: "#E4DDC8",
+		Selection: "#D9D2BD", Fg: "#586E75", OnColor: "#FDF6E3",
+		Muted: "#93A1A1", LineNumber: "#93A1A1",
+		Accent: "#268BD2", Red: "#DC322F", Orange: "#CB4B16", Yellow: "#B58900",
+		Green: "#859900", Cyan: "#2AA198", Purple: "#6C71C4",
+	}
+)
+
+// gruvboxDark and gruvboxLight are Gruvbox (github.com/morhetz/gruvbox).
+var (
+	gruvboxDark = palette{
+		Bg: "#282828", BgAlt: "#3C3836", BgRow: "#32302F", BgBar: "#1D2021",
+		Selection: "#504945", Fg: "#EBDBB2", OnColor: "#282828",
+		Muted: "#928374", LineNumber: "#7C6F64",
+		Accent: "#FB4934", Red: "#FB4934", Orange: "#FE8019", Yellow: "#FABD2F",
+		Green: "#B8BB26", Cyan: "#8EC07C", Purple: "#D3869B",
+	}
+	gruvboxLight = palette{
+		Bg: "#FBF1C7", BgAlt: "#EBDBB2", BgRow: "#F2E5BC", BgBar: "#D5C4A1",
+		Selection: "#D5C4A1", Fg: "#3C3836", OnColor: "#FBF1C7",
+		Muted: "#928374", LineNumber: "#A89984",
+		Accent: "#9D0006", Red: "#9D0006", Orange: "#AF3A03", Yellow: "#B57614",
+		Green: "#79740E", Cyan: "#427B58", Purple: "#8F3F71",
+	}
+)
+
+// nord is the Nord palette (nordtheme.com).
+var nord = palette{
+	Bg: "#2E3440", BgAlt: "#3B4252", BgRow: "#333946", BgBar: "#242933",
+	Selection: "#434C5E", Fg: "#D8DEE9", OnColor: "#2E3440",
+	Muted: "#616E88", LineNumber: "#4C566A",
+	Accent: "#81A1C1", Red: "#BF616A", Orange: "#D08770", Yellow: "#EBCB8B",
+	Green: "#A3BE8C", Cyan: "#88C0D0", Purple: "#B48EAD",
+}
@@ -3,7 +3,11 @@
 // Theme struct so that the entire look-and-feel can be swapped at runtime.
 package theme
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
 
 // Theme holds lipgloss.Style values for every UI element in the application.
 type Theme struct {
@@ -19,9 +23,11 @@ type Theme struct {
 	SidebarSchema     lipgloss.Style
 	SidebarTable      lipgloss.Style
 	SidebarView       lipgloss.Style
+	SidebarMatView    lipgloss.Style // materialized views
 	SidebarColumn     lipgloss.Style
 	SidebarColumnType lipgloss.Style
 	SidebarSelected   lipgloss.Style
+	SidebarMatch      lipgloss.Style // characters matched by the sidebar search
 
 	// Editor
 	EditorBorder     lipgloss.Style
@@ -108,6 +114,9 @@ func newDefaultTheme() *Theme {
 			Foreground(lipgloss.Color("#4EC9B0")),
 		SidebarView: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#C586C0")),
+		SidebarMatView: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#D7BA7D")).
+			Italic(true),
 		SidebarColumn: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#D4D4D4")),
 		SidebarColumnType: lipgloss.NewStyle().
@@ -117,6 +126,10 @@ func newDefaultTheme() *Theme {
 			Bold(true).
 			Foreground(lipgloss.Color("#FFFFFF")).
 			Background(lipgloss.Color("#264F78")),
+		SidebarMatch: lipgloss.NewStyle().
+			Bold(true).
+			Underline(true).
+			Foreground(lipgloss.Color("#CE9178")),
 
 		// Editor
 		EditorBorder: lipgloss.NewStyle().
@@ -296,6 +309,9 @@ func newLightTheme() *Theme {
 			Foreground(lipgloss.Color("#267F99")),
 		SidebarView: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#AF00DB")),
+		SidebarMatView: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#795E26")).
+			Italic(true),
 		SidebarColumn: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#1E1E1E")),
 		SidebarColumnType: lipgloss.NewStyle().
@@ -305,6 +321,10 @@ func newLightTheme() *Theme {
 			Bold(true).
 			Foreground(lipgloss.Color("#FFFFFF")).
 			Background(lipgloss.Color("#0060C0")),
+		SidebarMatch: lipgloss.NewStyle().
+			Bold(true).
+			Underline(true).
+			Foreground(lipgloss.Color("#A31515")),
 
 		// Editor
 		EditorBorder: lipgloss.NewStyle().
@@ -484,6 +504,9 @@ func newMonokaiTheme() *Theme {
 			Foreground(lipgloss.Color("#A6E22E")),
 		SidebarView: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#AE81FF")),
+		SidebarMatView: lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#E6DB74")).
+			Italic(true),
 		SidebarColumn: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#F8F8F2")),
 		SidebarColumnType: lipgloss.NewStyle().
@@ -493,6 +516,10 @@ func newMonokaiTheme() *Theme {
 			Bold(true).
 			Foreground(lipgloss.Color("#F8F8F2")).
 			Background(lipgloss.Color("#49483E")),
+		SidebarMatch: lipgloss.NewStyle().
+			Bold(true).
+			Underline(true).
+			Foreground(lipgloss.Color("#FD971F")),
 
 		// Editor
 		EditorBorder: lipgloss.NewStyle().
@@ -656,6 +683,13 @@ var Themes = map[string]*Theme{
 	"default": newDefaultTheme(),
 	"light":   newLightTheme(),
 	"monokai": newMonokaiTheme(),
+
+	"dracula":         newPaletteTheme("dracula", dracula),
+	"solarized-dark":  newPaletteTheme("solarized-dark", solarizedDark),
+	"solarized-light": newPaletteTheme("solarized-light", solarizedLight),
+	"gruvbox-dark":    newPaletteTheme("gruvbox-dark", gruvboxDark),
+	"gruvbox-light":   newPaletteTheme("gruvbox-light", gruvboxLight),
+	"nord":            newPaletteTheme("nord", nord),
 }
 
 // Current is the currently active theme. It is initialized to Default.
@@ -674,3 +708,39 @@ func Get(name string) *Theme {
 	}
 	return Default()
 }
+
+// envColors are the colors of well-known environment tags.
+var envColors = map[string]string{
+	"prod":        "#F44747",
+	"production":  "#F44747",
+	"live":        "#F44747",
+	"staging":     "#CCA700",
+	"stage":       "#CCA700",
+	"uat":         "#CCA700",
+	"qa":          "#C586C0",
+	"test":        "#C586C0",
+	"dev":         "#6A9955",
+	"development": "#6A9955",
+	"local":       "#6A9955",
+}
+
+// EnvColor returns the color of a connection's environment tag, or "" when
+// the tag is empty or unknown. override, when set, wins.
+func EnvColor(env, override string) string {
+	if override != "" {
+		return override
+	}
+	return envColors[strings.ToLower(strings.TrimSpace(env))]
+}
+
+// EnvBadge returns the style environment tags are drawn in: dark text on
+// color, or on gray for tags without a color.
+func EnvBadge(color string) lipgloss.Style {
+	if color == "" {
+		color = "#808080"
+	}
+	return lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("#000000")).
+		Background(lipgloss.Color(color))
+}
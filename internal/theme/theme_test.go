@@ -5,7 +5,7 @@ import (
 )
 
 func TestThemes_AllRegistered(t *testing.T) {
-	expected := []string{"default", "light", "monokai"}
+	expected := []string{"default", "light", "monokai", "dracula", "solarized-dark", "solarized-light", "gruvbox-dark", "gruvbox-light", "nord"}
 	for _, name := range expected {
 		if _, ok := Themes[name]; !ok {
 			t.Errorf("expected theme %q to be registered", name)
@@ -0,0 +1,224 @@
+// Package tunnel forwards a local port to a database through SSH. It runs
+// the system ssh client rather than an SSH library, so keys, agents,
+// known_hosts and ~/.ssh/config work exactly as they do in a shell.
+package tunnel
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+// sshCommand is the ssh client to run; swapped out in tests.
+var sshCommand = "ssh"
+
+// Tunnel is a running SSH port forward from 127.0.0.1:LocalPort to the
+// database.
+type Tunnel struct {
+	LocalPort int
+
+	target string
+	cmd    *exec.Cmd
+	stderr *syncBuffer
+	done   chan struct{}
+	err    error // why ssh exited; valid once done is closed
+}
+
+// Args returns the ssh arguments forwarding localPort to remoteHost:
+// remotePort through the SSH server in cfg. BatchMode keeps ssh from
+// prompting on the terminal the TUI owns: passwords and unknown host keys
+// fail instead, so use a key or an agent. A host, user or jump host
+// starting with "-" is refused, as ssh would read it as an option.
+func Args(cfg config.SSHTunnel, localPort int, remoteHost string, remotePort int) ([]string, error) {
+	for _, v := range []struct{ name, value string }{
+		{"host", cfg.Host}, {"user", cfg.User}, {"jump host", cfg.JumpHost}, {"database host", remoteHost},
+	} {
+		if strings.HasPrefix(v.value, "-") {
+			return nil, fmt.Errorf("ssh tunnel: the %s %q cannot start with \"-\"", v.name, v.value)
+		}
+	}
+	if strings.Contains(remoteHost, ":") && !strings.HasPrefix(remoteHost, "[") {
+		remoteHost = "[" + remoteHost + "]" // an IPv6 address
+	}
+	args := []string{
+		"-N",
+		"-o", "BatchMode=yes",
+		"-o", "ExitOnForwardFailure=yes",
+		"-o", "ServerAliveInterval=30",
+		"-L", fmt.Sprintf("127.0.0.1:%d:%s:%d", localPort, remoteHost, remotePort),
+	}
+	if cfg.Port > 0 {
+		args = append(args, "-p", strconv.Itoa(cfg.Port))
+	}
+	if cfg.IdentityFile != "" {
+		args = append(args, "-i", cfg.IdentityFile)
+	}
+	if cfg.JumpHost != "" {
+		args = append(args, "-J", cfg.JumpHost)
+	}
+	return append(args, "--", cfg.Target()), nil
+}
+
+// Open starts ssh and waits until the forwarded port accepts connections,
+// ssh exits, or ctx is done.
+func Open(ctx context.Context, cfg config.SSHTunnel, remoteHost string, remotePort int) (*Tunnel, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("ssh tunnel: no SSH host")
+	}
+	port, err := freePort()
+	if err != nil {
+		return nil, fmt.Errorf("ssh tunnel: %w", err)
+	}
+	args, err := Args(cfg, port, remoteHost, remotePort)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Tunnel{
+		LocalPort: port,
+		target:    cfg.Target(),
+		stderr:    &syncBuffer{},
+		done:      make(chan struct{}),
+	}
+	// Not CommandContext: the tunnel outlives the connect timeout.
+	t.cmd = exec.Command(sshCommand, args...)
+	t.cmd.Stderr = t.stderr
+	if err := t.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("ssh tunnel: %w", err)
+	}
+	go func() {
+		t.err = t.cmd.Wait()
+		close(t.done)
+	}()
+
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+	tick := time.NewTicker(100 * time.Millisecond)
+	defer tick.Stop()
+	for {
+		if c, err := net.DialTimeout("tcp", addr, time.Second); err == nil {
+			c.Close()
+			return t, nil
+		}
+		select {
+		case <-t.done:
+			return nil, t.Err()
+		case <-ctx.Done():
+			t.Close()
+			return nil, fmt.Errorf("ssh tunnel to %s: %w", t.target, ctx.Err())
+		case <-tick.C:
+		}
+	}
+}
+
+// Done is closed when ssh exits.
+func (t *Tunnel) Done() <-chan struct{} { return t.done }
+
+// Err returns why the tunnel closed, including what ssh printed, or nil
+// while it is up.
+func (t *Tunnel) Err() error {
+	select {
+	case <-t.done:
+	default:
+		return nil
+	}
+	msg := strings.TrimSpace(t.stderr.String())
+	switch {
+	case msg != "":
+		return fmt.Errorf("ssh tunnel to %s: %s", t.target, msg)
+	case t.err != nil:
+		return fmt.Errorf("ssh tunnel to %s: %w", t.target, t.err)
+	}
+	return fmt.Errorf("ssh tunnel to %s closed", t.target)
+}
+
+// Close stops ssh and waits for it to exit.
+func (t *Tunnel) Close() error {
+	select {
+	case <-t.done:
+		return nil
+	default:
+	}
+	_ = t.cmd.Process.Kill()
+	<-t.done
+	return nil
+}
+
+// String describes the tunnel for the status bar, e.g. "ssh deploy@bastion".
+func (t *Tunnel) String() string {
+	return "ssh " + t.target
+}
+
+// ForConnection returns the DSN to connect to sc with. When sc has SSH
+// settings it first opens a tunnel and points the DSN at it; the caller
+// closes the tunnel with the connection.
+func ForConnection(ctx context.Context, sc config.SavedConnection) (string, *Tunnel, error) {
+	if sc.SSH == nil {
+		return sc.BuildDSN(), nil, nil
+	}
+	if sc.DSN != "" {
+		return "", nil, errors.New("ssh tunnel: use the host and port fields instead of a DSN")
+	}
+	port := sc.Port
+	switch strings.ToLower(sc.Adapter) {
+	case "postgres":
+		if port == 0 {
+			port = 5432
+		}
+	case "mysql":
+		if port == 0 {
+			port = 3306
+		}
+	default:
+		return "", nil, fmt.Errorf("ssh tunnel: not supported for %s", sc.Adapter)
+	}
+	host := sc.Host
+	if host == "" {
+		host = "localhost" // as seen from the SSH server
+	}
+
+	t, err := Open(ctx, *sc.SSH, host, port)
+	if err != nil {
+		return "", nil, err
+	}
+	local := sc
+	local.Host = "127.0.0.1"
+	local.Port = t.LocalPort
+	return local.BuildDSN(), t, nil
+}
+
+// freePort returns a local TCP port nothing is listening on.
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// syncBuffer is a bytes.Buffer safe to read while ssh writes to it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
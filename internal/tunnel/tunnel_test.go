@@ -0,0 +1,95 @@
+package tunnel
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sadopc/gotermsql/internal/config"
+)
+
+func TestArgs(t *testing.T) {
+	cfg := config.SSHTunnel{Host: "db-host", Port: 2222, User: "deploy", IdentityFile: "~/.ssh/id_ed25519", JumpHost: "ops@bastion"}
+	got, err := Args(cfg, 40001, "10.0.0.5", 5432)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"-N",
+		"-o", "BatchMode=yes",
+		"-o", "ExitOnForwardFailure=yes",
+		"-o", "ServerAliveInterval=30",
+		"-L", "127.0.0.1:40001:10.0.0.5:5432",
+		"-p", "2222",
+		"-i", "~/.ssh/id_ed25519",
+		"-J", "ops@bastion",
+		"--",
+		"deploy@db-host",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Args() = %v\nwant %v", got, want)
+	}
+
+	if got, err := Args(config.SSHTunnel{Host: "h"}, 1, "localhost", 3306); err != nil || got[len(got)-1] != "h" || got[len(got)-2] != "--" || len(got) != 11 {
+		t.Errorf("minimal Args() = %v, %v", got, err)
+	}
+}
+
+func TestArgs_IPv6(t *testing.T) {
+	for _, host := range []string{"fd00::5", "[fd00::5]"} {
+		got, err := Args(config.SSHTunnel{Host: "h"}, 40001, host, 5432)
+		if err != nil || got[8] != "127.0.0.1:40001:[fd00::5]:5432" {
+			t.Errorf("Args(%q) -L = %v, %v", host, got, err)
+		}
+	}
+}
+
+func TestArgs_RefusesOptions(t *testing.T) {
+	for _, cfg := range []config.SSHTunnel{
+		{Host: "-oProxyCommand=touch /tmp/x"},
+		{Host: "h", User: "-oProxyCommand=x"},
+		{Host: "h", JumpHost: "-oProxyCommand=x"},
+	} {
+		if got, err := Args(cfg, 1, "localhost", 5432); err == nil {
+			t.Errorf("Args(%+v) = %v, want an error", cfg, got)
+		}
+	}
+	if _, err := Args(config.SSHTunnel{Host: "h"}, 1, "-x", 5432); err == nil {
+		t.Error("a database host starting with - should be refused")
+	}
+}
+
+func TestForConnection(t *testing.T) {
+	ctx := context.Background()
+
+	plain := config.SavedConnection{Adapter: "postgres", Host: "db", Database: "app"}
+	dsn, tun, err := ForConnection(ctx, plain)
+	if err != nil || tun != nil || dsn != "postgres://db/app" {
+		t.Errorf("without SSH: %q, %v, %v", dsn, tun, err)
+	}
+
+	ssh := &config.SSHTunnel{Host: "bastion"}
+	for _, sc := range []config.SavedConnection{
+		{Adapter: "postgres", DSN: "postgres://db/app", SSH: ssh},
+		{Adapter: "sqlite", File: "app.db", SSH: ssh},
+	} {
+		if _, _, err := ForConnection(ctx, sc); err == nil {
+			t.Errorf("%s with DSN %q: expected an error", sc.Adapter, sc.DSN)
+		}
+	}
+}
+
+func TestOpen_SSHFails(t *testing.T) {
+	orig := sshCommand
+	defer func() { sshCommand = orig }()
+	sshCommand = "false"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := Open(ctx, config.SSHTunnel{Host: "bastion", User: "deploy"}, "localhost", 5432)
+	if err == nil || !strings.Contains(err.Error(), "deploy@bastion") {
+		t.Errorf("Open() error = %v, want one naming the SSH server", err)
+	}
+}
@@ -0,0 +1,323 @@
+// Package activity is the session manager opened with Alt+A: the sessions
+// on the server with what they run, their state, how long they have been in
+// it and what they wait on, sortable, with keys to cancel the query of the
+// selected one or end it.
+package activity
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/theme"
+	"github.com/sadopc/gotermsql/internal/ui/format"
+)
+
+// RefreshMsg asks the app to list the sessions again.
+type RefreshMsg struct{}
+
+// CancelMsg asks the app to cancel the query session ID is running.
+type CancelMsg struct{ ID int64 }
+
+// TerminateMsg asks the app to end session ID.
+type TerminateMsg struct{ ID int64 }
+
+// TickMsg lists the sessions again while auto-refresh is on.
+type TickMsg struct{ gen int }
+
+// refreshInterval is how often auto-refresh lists the sessions.
+const refreshInterval = 2 * time.Second
+
+// Sort orders.
+const (
+	sortDuration = iota
+	sortID
+	sortUser
+	sortState
+	sortCount
+)
+
+var sortNames = [sortCount]string{"time", "id", "user", "state"}
+
+// Model is the session manager modal.
+type Model struct {
+	backends []adapter.Backend
+	sortBy   int
+	cursor   int
+	offset   int
+	visible  bool
+	loading  bool
+	width    int
+	height   int
+
+	terminating int64 // the session asked to confirm ending, or 0
+
+	auto    bool // list the sessions every refreshInterval
+	tickGen int  // drops the ticks of an earlier Show or toggle
+
+	message string
+	notice  bool // message is news rather than a problem
+}
+
+// New creates a hidden session manager, with auto-refresh on.
+func New() Model {
+	return Model{auto: true}
+}
+
+// Show opens the session manager, waiting for the sessions to be listed,
+// and returns the first auto-refresh tick.
+func (m *Model) Show() tea.Cmd {
+	m.visible = true
+	m.loading = true
+	m.terminating = 0
+	m.message = ""
+	return m.tick()
+}
+
+// AutoRefresh returns whether the sessions are listed again every few
+// seconds.
+func (m Model) AutoRefresh() bool { return m.auto }
+
+// tick schedules the next auto-refresh, if it is on.
+func (m *Model) tick() tea.Cmd {
+	m.tickGen++
+	if !m.auto {
+		return nil
+	}
+	gen := m.tickGen
+	return tea.Tick(refreshInterval, func(time.Time) tea.Msg { return TickMsg{gen: gen} })
+}
+
+// Hide closes the session manager.
+func (m *Model) Hide() {
+	m.visible = false
+}
+
+// Visible returns whether the session manager is shown.
+func (m Model) Visible() bool { return m.visible }
+
+// SetSize sets the available space.
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// SetMessage shows text in place of the key help until the next key press,
+// as an error unless notice is set.
+func (m *Model) SetMessage(text string, notice bool) {
+	m.message = text
+	m.notice = notice
+	m.loading = false
+}
+
+// SetBackends lists backends, keeping the selected session selected when
+// it is still there.
+func (m *Model) SetBackends(backends []adapter.Backend) {
+	var selected int64
+	if b, ok := m.selected(); ok {
+		selected = b.ID
+	}
+	m.backends = backends
+	m.loading = false
+	m.sort()
+	m.cursor = max(slices.IndexFunc(m.backends, func(b adapter.Backend) bool { return b.ID == selected }), 0)
+	m.clamp()
+}
+
+// Backends returns the sessions listed, in the order shown.
+func (m Model) Backends() []adapter.Backend { return m.backends }
+
+func (m Model) selected() (adapter.Backend, bool) {
+	if m.cursor >= len(m.backends) {
+		return adapter.Backend{}, false
+	}
+	return m.backends[m.cursor], true
+}
+
+// sort orders the sessions by the sort column, the longest running first
+// and then by id.
+func (m *Model) sort() {
+	slices.SortStableFunc(m.backends, func(a, b adapter.Backend) int {
+		var c int
+		switch m.sortBy {
+		case sortDuration:
+			c = cmp.Compare(b.Duration, a.Duration)
+		case sortUser:
+			c = strings.Compare(a.User, b.User)
+		case sortState:
+			c = strings.Compare(a.State, b.State)
+		}
+		return cmp.Or(c, cmp.Compare(a.ID, b.ID))
+	})
+}
+
+// Update handles key presses: r refreshes, a turns auto-refresh on or off,
+// s changes the sort order, c cancels the selected session's query and x
+// ends the session once confirmed. It also handles TickMsg.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if t, ok := msg.(TickMsg); ok {
+		if !m.visible || !m.auto || t.gen != m.tickGen {
+			return m, nil
+		}
+		if m.loading {
+			return m, m.tick() // the last listing is not back yet
+		}
+		m.loading = true
+		return m, tea.Batch(func() tea.Msg { return RefreshMsg{} }, m.tick())
+	}
+	key, ok := msg.(tea.KeyMsg)
+	if !m.visible || !ok {
+		return m, nil
+	}
+	m.message = ""
+	if m.terminating != 0 {
+		id := m.terminating
+		m.terminating = 0
+		if key.String() == "y" {
+			return m, func() tea.Msg { return TerminateMsg{ID: id} }
+		}
+		return m, nil
+	}
+	switch key.String() {
+	case "esc", "q":
+		m.visible = false
+	case "up", "k":
+		m.cursor--
+	case "down", "j":
+		m.cursor++
+	case "pgup":
+		m.cursor -= m.listRows()
+	case "pgdown":
+		m.cursor += m.listRows()
+	case "home", "g":
+		m.cursor = 0
+	case "end", "G":
+		m.cursor = len(m.backends) - 1
+	case "s":
+		var selected int64
+		if b, ok := m.selected(); ok {
+			selected = b.ID
+		}
+		m.sortBy = (m.sortBy + 1) % sortCount
+		m.sort()
+		m.cursor = max(slices.IndexFunc(m.backends, func(b adapter.Backend) bool { return b.ID == selected }), 0)
+	case "r", "ctrl+r":
+		m.loading = true
+		return m, func() tea.Msg { return RefreshMsg{} }
+	case "a":
+		m.auto = !m.auto
+		return m, m.tick()
+	case "c":
+		if b, ok := m.selected(); ok {
+			return m, func() tea.Msg { return CancelMsg{ID: b.ID} }
+		}
+	case "x":
+		if b, ok := m.selected(); ok {
+			m.terminating = b.ID
+		}
+	}
+	m.clamp()
+	return m, nil
+}
+
+func (m *Model) clamp() {
+	m.cursor = max(min(m.cursor, len(m.backends)-1), 0)
+	rows := m.listRows()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+}
+
+// listRows returns how many sessions fit in the modal.
+func (m Model) listRows() int {
+	// Title, header, the selected query, help, the blank lines between
+	// them and the border.
+	return max(m.height-11, 3)
+}
+
+// View renders the session manager.
+func (m Model) View() string {
+	if !m.visible {
+		return ""
+	}
+	th := theme.Current
+	w := 140
+	if m.width > 0 && w > m.width-4 {
+		w = m.width - 4
+	}
+	textW := w - 6
+
+	title := fmt.Sprintf("  Sessions (%d)  ", len(m.backends))
+	lines := []string{th.DialogTitle.Render(title), ""}
+	header := row("ID", "USER", "DATABASE", "STATE", "TIME", "WAIT", "QUERY")
+	lines = append(lines, th.MutedText.Render("  "+runewidth.Truncate(header, textW, "…")))
+
+	rows := m.listRows()
+	end := min(m.offset+rows, len(m.backends))
+	for i := m.offset; i < end; i++ {
+		b := m.backends[i]
+		line := row(strconv.FormatInt(b.ID, 10), b.User, b.Database, b.State, format.Duration(b.Duration), b.Wait, format.OneLine(b.Query))
+		line = runewidth.FillRight(runewidth.Truncate(line, textW, "…"), textW)
+		if i == m.cursor {
+			lines = append(lines, "  "+th.SidebarSelected.Render(line))
+		} else {
+			lines = append(lines, "  "+line)
+		}
+	}
+	switch {
+	case m.loading && len(m.backends) == 0:
+		lines = append(lines, th.MutedText.Render("  Loading..."))
+		rows--
+	case len(m.backends) == 0:
+		lines = append(lines, th.MutedText.Render("  No other sessions"))
+		rows--
+	}
+	for i := end - m.offset; i < rows; i++ {
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, "")
+	query := ""
+	if b, ok := m.selected(); ok {
+		query = format.OneLine(b.Query)
+	}
+	lines = append(lines, "  "+runewidth.Truncate(query, textW, "…"), "")
+
+	switch {
+	case m.terminating != 0:
+		lines = append(lines, th.ErrorText.Render(fmt.Sprintf("  End session %d? y to confirm, any other key to keep it", m.terminating)))
+	case m.message != "" && m.notice:
+		lines = append(lines, th.SuccessText.Render("  "+m.message))
+	case m.message != "":
+		lines = append(lines, th.ErrorText.Render("  "+runewidth.Truncate(m.message, textW, "…")))
+	default:
+		auto := "off"
+		if m.auto {
+			auto = "on"
+		}
+		help := fmt.Sprintf("  c:cancel query  x:end session  r:refresh  a:auto (%s)  s:sort (%s)  esc:close", auto, sortNames[m.sortBy])
+		if m.loading {
+			help += "  refreshing..."
+		}
+		lines = append(lines, th.MutedText.Render(help))
+	}
+	return th.DialogBorder.Width(w).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// row lays out the columns of a session.
+func row(id, user, db, state, duration, wait, query string) string {
+	col := func(s string, w int) string {
+		return runewidth.FillRight(runewidth.Truncate(s, w, "…"), w) + " "
+	}
+	return col(id, 8) + col(user, 12) + col(db, 12) + col(state, 20) + col(duration, 8) + col(wait, 20) + query
+}
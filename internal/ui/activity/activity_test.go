@@ -0,0 +1,119 @@
+package activity
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+)
+
+func key(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func ids(m Model) []int64 {
+	var ids []int64
+	for _, b := range m.Backends() {
+		ids = append(ids, b.ID)
+	}
+	return ids
+}
+
+func TestSessions(t *testing.T) {
+	m := New()
+	m.SetSize(160, 30)
+	m.Show()
+	m.SetBackends([]adapter.Backend{
+		{ID: 10, User: "bob", State: "idle", Duration: time.Minute, Query: "SELECT 1"},
+		{ID: 20, User: "alice", State: "active", Duration: 2 * time.Hour, Wait: "Lock: relation", Query: "UPDATE orders\n   SET total = 0"},
+		{ID: 30, User: "carol", State: "active", Duration: 5 * time.Second},
+	})
+	if got := ids(m); got[0] != 20 || got[1] != 10 || got[2] != 30 {
+		t.Fatalf("order = %v, want the longest running first", got)
+	}
+	view := m.View()
+	for _, want := range []string{"Sessions (3)", "alice", "2h00m", "Lock: relation", "UPDATE orders SET total = 0"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+
+	// Sorting by id keeps the selected session selected.
+	m, _ = m.Update(key("j"))
+	m, _ = m.Update(key("s"))
+	if got := ids(m); got[0] != 10 || got[1] != 20 {
+		t.Fatalf("order = %v, want by id", got)
+	}
+	if b, _ := m.selected(); b.ID != 10 {
+		t.Errorf("selected %d, want 10", b.ID)
+	}
+
+	m, cmd := m.Update(key("c"))
+	if msg := cmd(); msg != (CancelMsg{ID: 10}) {
+		t.Errorf("c sent %#v, want a cancel", msg)
+	}
+
+	// Ending a session asks first.
+	m, cmd = m.Update(key("x"))
+	if cmd != nil || !strings.Contains(m.View(), "End session 10?") {
+		t.Fatal("x should ask to confirm")
+	}
+	m, cmd = m.Update(key("n"))
+	if cmd != nil {
+		t.Error("declining should keep the session")
+	}
+	m, _ = m.Update(key("x"))
+	m, cmd = m.Update(key("y"))
+	if msg := cmd(); msg != (TerminateMsg{ID: 10}) {
+		t.Errorf("y sent %#v, want an end", msg)
+	}
+
+	// A refresh keeps the selection on the same session.
+	m.SetBackends([]adapter.Backend{{ID: 5}, {ID: 10}})
+	if b, _ := m.selected(); b.ID != 10 {
+		t.Errorf("selected %d after refresh, want 10", b.ID)
+	}
+}
+
+func TestAutoRefresh(t *testing.T) {
+	m := New()
+	if m.Show() == nil {
+		t.Fatal("Show should start auto-refresh")
+	}
+	tick := TickMsg{gen: m.tickGen}
+
+	// A tick while the last listing is out only waits for the next one.
+	m, cmd := m.Update(tick)
+	if cmd == nil {
+		t.Fatal("the tick should schedule the next")
+	}
+	m.SetBackends(nil)
+	tick = TickMsg{gen: m.tickGen}
+	m, cmd = m.Update(tick)
+	if cmd == nil || !m.loading {
+		t.Fatal("a tick should list the sessions again")
+	}
+
+	// Turning it off stops the ticks already scheduled.
+	m.SetBackends(nil)
+	m, cmd = m.Update(key("a"))
+	if cmd != nil || m.AutoRefresh() || !strings.Contains(m.View(), "a:auto (off)") {
+		t.Fatal("a should turn auto-refresh off")
+	}
+	if _, cmd = m.Update(TickMsg{gen: m.tickGen}); cmd != nil {
+		t.Error("a tick with auto-refresh off should do nothing")
+	}
+	m, cmd = m.Update(key("a"))
+	if cmd == nil || !m.AutoRefresh() {
+		t.Fatal("a should turn auto-refresh back on")
+	}
+	if _, cmd = m.Update(tick); cmd != nil {
+		t.Error("a tick from before the toggle should be dropped")
+	}
+	m.Hide()
+	if _, cmd = m.Update(TickMsg{gen: m.tickGen}); cmd != nil {
+		t.Error("a tick once hidden should do nothing")
+	}
+}
@@ -0,0 +1,518 @@
+// Package bulkupdate is the wizard that builds an UPDATE of many rows of a
+// table, opened from the sidebar menu: type the values to set, build the
+// WHERE clause a condition at a time, check the rows it matches, then run
+// it in a transaction and commit or roll it back.
+package bulkupdate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+// Operators are the comparisons a condition can make. IS NULL and IS NOT
+// NULL take no value; IN takes values separated by commas.
+var Operators = []string{"=", "<>", "<", "<=", ">", ">=", "LIKE", "IS NULL", "IS NOT NULL", "IN"}
+
+// Assignment is a column set, to Value or adapter.NullValue for NULL.
+type Assignment struct {
+	Column schema.Column
+	Value  string
+}
+
+// Condition compares Column with Value by Operator.
+type Condition struct {
+	Column   schema.Column
+	Operator string
+	Value    string
+}
+
+// PreviewMsg asks the app for the UPDATE setting Set on the rows matching
+// every condition of Where, the rows it matches and some of them.
+type PreviewMsg struct {
+	Set   []Assignment
+	Where []Condition
+}
+
+// RunMsg asks the app to run the UPDATE previewed.
+type RunMsg struct{}
+
+// EndMsg asks the app to commit the transaction the UPDATE ran in, or to
+// roll it back.
+type EndMsg struct {
+	Commit bool
+}
+
+// step is a page of the wizard.
+type step int
+
+const (
+	stepSet     step = iota // values to set
+	stepWhere               // conditions
+	stepPreview             // statement, count and rows matched
+	stepDone                // ran or failed
+)
+
+// Condition parts focused in turn with tab.
+const (
+	partColumn = iota
+	partOperator
+	partValue
+)
+
+// condition is a condition being edited.
+type condition struct {
+	column   int
+	operator int
+	input    textinput.Model
+}
+
+// Model is the bulk UPDATE wizard modal.
+type Model struct {
+	table   string
+	columns []schema.Column
+	step    step
+	visible bool
+	width   int
+	height  int
+
+	// The values to set.
+	inputs []textinput.Model
+	nulls  []bool
+	focus  int
+	offset int
+
+	// The conditions, and the one and part of it focused.
+	conds []condition
+	cond  int
+	part  int
+
+	// The preview.
+	statement string
+	count     int64
+	header    []string
+	rows      [][]string
+	loading   bool
+	running   bool
+
+	message string
+	failed  bool
+	inTx    bool // the UPDATE ran in a transaction still open
+}
+
+// New creates a hidden wizard.
+func New() Model {
+	return Model{}
+}
+
+// Show opens the wizard on table, whose columns are columns.
+func (m *Model) Show(table string, columns []schema.Column) {
+	width := 0
+	for _, c := range columns {
+		width = max(width, runewidth.StringWidth(c.Name))
+	}
+	inputs := make([]textinput.Model, len(columns))
+	for i, c := range columns {
+		in := textinput.New()
+		in.Prompt = runewidth.FillRight(c.Name, width) + "  "
+		in.Placeholder = "unchanged"
+		in.Width = 30
+		inputs[i] = in
+	}
+	*m = Model{table: table, columns: columns, inputs: inputs, nulls: make([]bool, len(columns)),
+		visible: true, width: m.width, height: m.height}
+	if len(inputs) > 0 {
+		m.inputs[0].Focus()
+	}
+}
+
+// Hide closes the wizard.
+func (m *Model) Hide() {
+	m.visible = false
+}
+
+// Visible returns whether the wizard is shown.
+func (m Model) Visible() bool { return m.visible }
+
+// Table returns the table updated.
+func (m Model) Table() string { return m.table }
+
+// Running returns whether the UPDATE is running.
+func (m Model) Running() bool { return m.running }
+
+// SetSize sets the available space.
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.ensureVisible()
+}
+
+// SetPreview shows the UPDATE, how many rows it matches and the first of
+// them.
+func (m *Model) SetPreview(statement string, count int64, header []string, rows [][]string) {
+	if m.step != stepPreview {
+		return
+	}
+	m.statement, m.count, m.header, m.rows = statement, count, header, rows
+	m.loading = false
+	m.message, m.failed = "", false
+}
+
+// SetError shows why the preview or the UPDATE failed.
+func (m *Model) SetError(text string) {
+	m.loading, m.running = false, false
+	m.message, m.failed = text, true
+}
+
+// Done shows the UPDATE ran, in a transaction still open when inTx is set.
+func (m *Model) Done(text string, inTx bool) {
+	m.step = stepDone
+	m.running = false
+	m.message, m.failed = text, false
+	m.inTx = inTx
+}
+
+// Update handles key presses, page by page.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !m.visible || !ok || m.running {
+		return m, nil
+	}
+	switch m.step {
+	case stepSet:
+		return m.updateSet(key)
+	case stepWhere:
+		return m.updateWhere(key)
+	case stepPreview:
+		switch key.String() {
+		case "esc":
+			m.step = stepWhere
+			m.message, m.failed = "", false
+			return m, m.focusCondition()
+		case "enter":
+			if m.loading || m.statement == "" {
+				return m, nil
+			}
+			m.running = true
+			m.message, m.failed = "Updating...", false
+			return m, func() tea.Msg { return RunMsg{} }
+		}
+		return m, nil
+	}
+
+	switch key.String() {
+	case "c":
+		if m.inTx {
+			m.visible = false
+			return m, func() tea.Msg { return EndMsg{Commit: true} }
+		}
+	case "r":
+		if m.inTx {
+			m.visible = false
+			return m, func() tea.Msg { return EndMsg{Commit: false} }
+		}
+	case "esc", "q", "enter":
+		m.visible = false
+	}
+	return m, nil
+}
+
+// updateSet handles a key press on the values to set: tab and the arrows
+// move, ctrl+n sets NULL, enter goes on to the conditions.
+func (m Model) updateSet(key tea.KeyMsg) (Model, tea.Cmd) {
+	if len(m.inputs) == 0 {
+		if key.String() == "esc" {
+			m.visible = false
+		}
+		return m, nil
+	}
+	switch key.String() {
+	case "esc":
+		m.visible = false
+		return m, nil
+	case "tab", "down":
+		return m, m.moveFocus(1)
+	case "shift+tab", "up":
+		return m, m.moveFocus(-1)
+	case "ctrl+n":
+		m.nulls[m.focus] = !m.nulls[m.focus]
+		m.inputs[m.focus].SetValue("")
+		return m, nil
+	case "enter":
+		if len(m.assignments()) == 0 {
+			m.message, m.failed = "Type a value for a column to set", true
+			return m, nil
+		}
+		m.inputs[m.focus].Blur()
+		m.step = stepWhere
+		m.message, m.failed = "", false
+		return m, m.focusCondition()
+	}
+	m.nulls[m.focus] = false
+	var cmd tea.Cmd
+	m.inputs[m.focus], cmd = m.inputs[m.focus].Update(key)
+	return m, cmd
+}
+
+// updateWhere handles a key press on the conditions: ctrl+a adds one,
+// ctrl+d deletes one, up and down move between them, tab between their
+// column, operator and value, left and right change the column or the
+// operator, and enter previews the UPDATE.
+func (m Model) updateWhere(key tea.KeyMsg) (Model, tea.Cmd) {
+	switch key.String() {
+	case "esc":
+		m.blurCondition()
+		m.step = stepSet
+		m.message, m.failed = "", false
+		return m, m.inputs[m.focus].Focus()
+	case "ctrl+a":
+		m.blurCondition()
+		in := textinput.New()
+		in.Prompt = ""
+		in.Width = 30
+		m.conds = append(m.conds, condition{input: in})
+		m.cond, m.part = len(m.conds)-1, partColumn
+		return m, m.focusCondition()
+	case "ctrl+d":
+		if len(m.conds) > 0 {
+			m.conds = append(m.conds[:m.cond], m.conds[m.cond+1:]...)
+			m.cond = max(min(m.cond, len(m.conds)-1), 0)
+		}
+		return m, m.focusCondition()
+	case "enter":
+		where, err := m.conditions()
+		if err != "" {
+			m.message, m.failed = err, true
+			return m, nil
+		}
+		m.blurCondition()
+		m.step = stepPreview
+		m.loading = true
+		m.statement, m.header, m.rows = "", nil, nil
+		m.message, m.failed = "", false
+		preview := PreviewMsg{Set: m.assignments(), Where: where}
+		return m, func() tea.Msg { return preview }
+	}
+	if len(m.conds) == 0 {
+		return m, nil
+	}
+	c := &m.conds[m.cond]
+	switch key.String() {
+	case "up":
+		m.blurCondition()
+		m.cond = max(m.cond-1, 0)
+		return m, m.focusCondition()
+	case "down":
+		m.blurCondition()
+		m.cond = min(m.cond+1, len(m.conds)-1)
+		return m, m.focusCondition()
+	case "tab":
+		m.blurCondition()
+		m.part = (m.part + 1) % 3
+		return m, m.focusCondition()
+	case "shift+tab":
+		m.blurCondition()
+		m.part = (m.part + 2) % 3
+		return m, m.focusCondition()
+	case "left", "right":
+		delta := 1
+		if key.String() == "left" {
+			delta = -1
+		}
+		switch m.part {
+		case partColumn:
+			c.column = (c.column + delta + len(m.columns)) % len(m.columns)
+			return m, nil
+		case partOperator:
+			c.operator = (c.operator + delta + len(Operators)) % len(Operators)
+			return m, nil
+		}
+	}
+	if m.part != partValue {
+		return m, nil
+	}
+	var cmd tea.Cmd
+	c.input, cmd = c.input.Update(key)
+	return m, cmd
+}
+
+// assignments returns the columns given a value to set.
+func (m Model) assignments() []Assignment {
+	var set []Assignment
+	for i, in := range m.inputs {
+		switch {
+		case m.nulls[i]:
+			set = append(set, Assignment{Column: m.columns[i], Value: adapter.NullValue})
+		case in.Value() != "":
+			set = append(set, Assignment{Column: m.columns[i], Value: in.Value()})
+		}
+	}
+	return set
+}
+
+// conditions returns the conditions built, or what one lacks.
+func (m Model) conditions() ([]Condition, string) {
+	var where []Condition
+	for _, c := range m.conds {
+		op := Operators[c.operator]
+		value := c.input.Value()
+		if value == "" && op != "IS NULL" && op != "IS NOT NULL" {
+			return nil, "Type a value to compare " + m.columns[c.column].Name + " with"
+		}
+		where = append(where, Condition{Column: m.columns[c.column], Operator: op, Value: value})
+	}
+	return where, ""
+}
+
+// focusCondition focuses the value of the condition selected, when that
+// is the part selected.
+func (m *Model) focusCondition() tea.Cmd {
+	if len(m.conds) == 0 || m.part != partValue {
+		return nil
+	}
+	return m.conds[m.cond].input.Focus()
+}
+
+func (m *Model) blurCondition() {
+	if len(m.conds) > 0 {
+		m.conds[m.cond].input.Blur()
+	}
+}
+
+// moveFocus moves the focus by delta fields, wrapping around.
+func (m *Model) moveFocus(delta int) tea.Cmd {
+	m.inputs[m.focus].Blur()
+	m.focus = (m.focus + delta + len(m.inputs)) % len(m.inputs)
+	m.ensureVisible()
+	return m.inputs[m.focus].Focus()
+}
+
+// listRows returns how many lines of a page fit in the wizard.
+func (m Model) listRows() int {
+	// Title, subtitle, message, help, the blank lines and the border.
+	return max(m.height-10, 3)
+}
+
+func (m *Model) ensureVisible() {
+	rows := m.listRows()
+	if m.focus < m.offset {
+		m.offset = m.focus
+	}
+	if m.focus >= m.offset+rows {
+		m.offset = m.focus - rows + 1
+	}
+}
+
+// View renders the wizard.
+func (m Model) View() string {
+	if !m.visible {
+		return ""
+	}
+	th := theme.Current
+	w := 110
+	if m.width > 0 && w > m.width-4 {
+		w = m.width - 4
+	}
+	textW := w - 6
+
+	lines := []string{th.DialogTitle.Render("  Bulk Update: " + m.table + "  ")}
+	var help string
+	switch m.step {
+	case stepSet:
+		lines = append(lines, th.MutedText.Render("  1/3  Values to set (empty fields are left unchanged)"), "")
+		end := min(m.offset+m.listRows(), len(m.inputs))
+		for i := m.offset; i < end; i++ {
+			field := m.inputs[i].View()
+			if m.nulls[i] {
+				field = m.inputs[i].Prompt + th.MutedText.Render("NULL")
+			}
+			lines = append(lines, "  "+field)
+		}
+		help = "tab:next  ctrl+n:NULL  enter:conditions  esc:close"
+
+	case stepWhere:
+		lines = append(lines, th.MutedText.Render("  2/3  Rows to update, matching every condition"), "")
+		if len(m.conds) == 0 {
+			lines = append(lines, th.WarningText.Render("  No conditions: every row is updated"))
+		}
+		for i, c := range m.conds {
+			parts := []string{m.columns[c.column].Name, Operators[c.operator], c.input.View()}
+			if op := Operators[c.operator]; op == "IS NULL" || op == "IS NOT NULL" {
+				parts[2] = ""
+			}
+			for p := range parts[:2] {
+				if i == m.cond && p == m.part {
+					parts[p] = th.SidebarSelected.Render("‹" + parts[p] + "›")
+				} else {
+					parts[p] = " " + parts[p] + " "
+				}
+			}
+			prefix := "  WHERE "
+			if i > 0 {
+				prefix = "    AND "
+			}
+			lines = append(lines, prefix+strings.Join(parts, " "))
+		}
+		help = "ctrl+a:add  ctrl+d:delete  tab:part  ←/→:change  enter:preview  esc:back"
+
+	case stepPreview:
+		lines = append(lines, th.MutedText.Render("  3/3  Preview"), "")
+		if m.loading {
+			lines = append(lines, th.MutedText.Render("  Counting the rows matched..."))
+		} else if m.statement != "" {
+			for _, l := range strings.Split(m.statement, "\n") {
+				lines = append(lines, "  "+runewidth.Truncate(l, textW, "…"))
+			}
+			lines = append(lines, "", fmt.Sprintf("  %d rows match", m.count))
+			lines = append(lines, m.previewLines(textW)...)
+		}
+		help = "enter:update in a transaction  esc:back"
+
+	case stepDone:
+		lines = append(lines, "")
+		help = "esc:close"
+		if m.inTx {
+			help = "c:commit  r:roll back  esc:keep the transaction open (F6/F7)"
+		}
+	}
+
+	style := th.MutedText
+	if m.failed {
+		style = th.ErrorText
+	}
+	lines = append(lines, "")
+	if m.message != "" {
+		lines = append(lines, style.Render("  "+runewidth.Truncate(m.message, textW, "…")))
+	}
+	lines = append(lines, th.MutedText.Render("  "+help))
+	return th.DialogBorder.Width(w).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// previewLines renders the first rows matched as a table, as many as fit.
+func (m Model) previewLines(textW int) []string {
+	if len(m.header) == 0 {
+		return nil
+	}
+	th := theme.Current
+	cell := max(textW/len(m.header), 8)
+	row := func(values []string) string {
+		var sb strings.Builder
+		for _, v := range values {
+			sb.WriteString(runewidth.FillRight(runewidth.Truncate(v, cell-2, "…"), cell))
+		}
+		return "  " + runewidth.Truncate(sb.String(), textW, "…")
+	}
+	lines := []string{"", th.MutedText.Render(row(m.header))}
+	rows := m.rows[:min(len(m.rows), max(m.listRows()-len(strings.Split(m.statement, "\n"))-4, 1))]
+	for _, r := range rows {
+		lines = append(lines, row(r))
+	}
+	return lines
+}
@@ -0,0 +1,105 @@
+package bulkupdate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+func typeKeys(m Model, s string) Model {
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)})
+	return m
+}
+
+func TestWizard(t *testing.T) {
+	cols := []schema.Column{
+		{Name: "id", Type: "INTEGER"},
+		{Name: "status", Type: "TEXT"},
+		{Name: "note", Type: "TEXT", Nullable: true},
+	}
+	m := New()
+	m.SetSize(130, 40)
+	m.Show("orders", cols)
+	if view := m.View(); !strings.Contains(view, "Bulk Update: orders") || !strings.Contains(view, "unchanged") {
+		t.Fatalf("view lacks the title or the columns:\n%s", view)
+	}
+
+	if m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter}); !strings.Contains(m.View(), "Type a value") {
+		t.Fatalf("enter with nothing to set should be refused:\n%s", m.View())
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = typeKeys(m, "shipped")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if view := m.View(); !strings.Contains(view, "every row is updated") {
+		t.Errorf("no conditions should warn every row is updated:\n%s", view)
+	}
+
+	// id > 5: ctrl+a adds a condition on the first column, right moves
+	// the operator from = to <> to <, then to > two more.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlA})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	for range 4 {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter}); !strings.Contains(m.View(), "Type a value to compare id") {
+		t.Fatalf("a condition with no value should be refused:\n%s", m.View())
+	}
+	m = typeKeys(m, "5")
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("enter should ask for the preview")
+	}
+	want := PreviewMsg{
+		Set:   []Assignment{{Column: cols[1], Value: "shipped"}, {Column: cols[2], Value: adapter.NullValue}},
+		Where: []Condition{{Column: cols[0], Operator: ">", Value: "5"}},
+	}
+	if got := cmd(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("preview = %+v, want %+v", got, want)
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+		t.Error("enter should wait for the preview")
+	}
+
+	m.SetPreview("UPDATE orders SET ...", 2, []string{"id", "status"}, [][]string{{"6", "new"}, {"7", "paid"}})
+	if view := m.View(); !strings.Contains(view, "2 rows") || !strings.Contains(view, "paid") {
+		t.Errorf("view lacks the preview:\n%s", view)
+	}
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || cmd() != (RunMsg{}) || !m.Running() {
+		t.Fatal("enter on the preview should run the UPDATE")
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc}); cmd != nil {
+		t.Error("keys should be ignored while the UPDATE runs")
+	}
+
+	m.Done("2 rows updated", true)
+	if view := m.View(); !strings.Contains(view, "2 rows updated") {
+		t.Errorf("view lacks the result:\n%s", view)
+	}
+	done := m
+	if m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")}); cmd == nil || cmd() != (EndMsg{Commit: false}) || m.Visible() {
+		t.Error("r should roll the UPDATE back and close")
+	}
+	if m, cmd = done.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")}); cmd == nil || cmd() != (EndMsg{Commit: true}) || m.Visible() {
+		t.Error("c should commit the UPDATE and close")
+	}
+}
+
+func TestWizard_NoTransaction(t *testing.T) {
+	m := New()
+	m.Show("orders", []schema.Column{{Name: "id", Type: "INTEGER"}})
+	m.Done("3 rows updated", false)
+	if m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")}); cmd != nil || !m.Visible() {
+		t.Error("r should do nothing with no transaction open")
+	}
+	if m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc}); m.Visible() {
+		t.Error("esc should close the wizard")
+	}
+}
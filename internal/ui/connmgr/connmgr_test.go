@@ -2,11 +2,18 @@ package connmgr
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+	_ "github.com/sadopc/gotermsql/internal/adapter/postgres"
 	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/importer"
+	appmsg "github.com/sadopc/gotermsql/internal/msg"
 	"github.com/sadopc/gotermsql/internal/theme"
+	"github.com/zalando/go-keyring"
 )
 
 func init() {
@@ -562,3 +569,291 @@ func TestLoadIntoForm_ZeroPort(t *testing.T) {
 		t.Fatalf("expected empty port for port=0, got %q", m.inputs[fieldPort].Value())
 	}
 }
+
+func TestSSHFields_RoundTrip(t *testing.T) {
+	m := New(nil)
+	conn := config.SavedConnection{
+		Name:    "prod",
+		Adapter: "postgres",
+		Host:    "10.0.0.5",
+		SSH: &config.SSHTunnel{
+			Host:         "bastion.example.com",
+			Port:         2222,
+			User:         "deploy",
+			IdentityFile: "~/.ssh/id_ed25519",
+			JumpHost:     "ops@gateway",
+		},
+	}
+
+	m.loadIntoForm(conn)
+	got := m.formToConnection()
+	if got.SSH == nil || *got.SSH != *conn.SSH {
+		t.Fatalf("SSH = %+v, want %+v", got.SSH, conn.SSH)
+	}
+
+	// Loading a connection without SSH clears the fields, and an empty SSH
+	// host means no tunnel.
+	m.loadIntoForm(config.SavedConnection{Name: "local", Adapter: "postgres"})
+	if v := m.inputs[fieldSSHUser].Value(); v != "" {
+		t.Errorf("SSH user = %q, want it cleared", v)
+	}
+	if got := m.formToConnection(); got.SSH != nil {
+		t.Errorf("SSH = %+v, want nil without an SSH host", got.SSH)
+	}
+}
+
+func TestUpdateList_Enter_PassesSavedConnection(t *testing.T) {
+	conns := []config.SavedConnection{
+		{Name: "prod", Adapter: "postgres", Host: "db", SSH: &config.SSHTunnel{Host: "bastion"}},
+	}
+	m := New(conns)
+	m.Show()
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	msg := cmd().(ConnectRequestMsg)
+	if msg.Saved == nil || msg.Saved.SSH == nil || msg.Saved.SSH.Host != "bastion" {
+		t.Errorf("Saved = %+v, want the picked connection with its SSH settings", msg.Saved)
+	}
+}
+
+func TestFormConnection_KeepsSecretID(t *testing.T) {
+	conns := []config.SavedConnection{
+		{Name: "prod", Adapter: "postgres", Host: "db", User: "app", SecretID: "abc123"},
+	}
+	m := New(conns)
+	m.editing = 0
+	m.loadIntoForm(conns[0])
+	if p := m.inputs[fieldPassword].Placeholder; !strings.Contains(p, "keychain") {
+		t.Errorf("password placeholder = %q, want it to mention the keychain", p)
+	}
+
+	got := m.formConnection()
+	if got.SecretID != "abc123" || got.Password != "" {
+		t.Errorf("got SecretID %q, Password %q; want the stored secret kept", got.SecretID, got.Password)
+	}
+
+	// A new connection never picks up another connection's secret.
+	m.editing = -1
+	if got := m.formConnection(); got.SecretID != "" {
+		t.Errorf("new connection SecretID = %q, want empty", got.SecretID)
+	}
+}
+
+func TestUpdateList_Enter_ExpandsEnv(t *testing.T) {
+	t.Setenv("GOTERMSQL_TEST_PASSWORD", "s3cret")
+	conns := []config.SavedConnection{
+		{Name: "shared", Adapter: "postgres", Host: "db", User: "app", Password: "${GOTERMSQL_TEST_PASSWORD}", Database: "app"},
+	}
+	m := New(conns)
+	m.Show()
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	msg, ok := cmd().(ConnectRequestMsg)
+	if !ok {
+		t.Fatal("expected ConnectRequestMsg")
+	}
+	if !strings.Contains(msg.DSN, "s3cret") {
+		t.Errorf("DSN = %q, want the password expanded", msg.DSN)
+	}
+	if m.connections[0].Password != "${GOTERMSQL_TEST_PASSWORD}" {
+		t.Error("the saved connection was modified")
+	}
+
+	conns[0].Password = "${GOTERMSQL_TEST_UNSET}"
+	m = New(conns)
+	m.Show()
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if _, ok := cmd().(appmsg.ConnectErrMsg); !ok {
+		t.Error("expected ConnectErrMsg for an unset variable")
+	}
+}
+
+func TestGroups(t *testing.T) {
+	conns := []config.SavedConnection{
+		{Name: "acme-prod", Adapter: "postgres", Host: "db1", Group: "acme", Env: "prod"},
+		{Name: "scratch", Adapter: "sqlite", File: "/tmp/x.db"},
+		{Name: "acme-staging", Adapter: "postgres", Host: "db2", Group: "acme", Env: "staging"},
+		{Name: "home", Adapter: "postgres", Host: "db3", Group: "personal"},
+	}
+	m := New(conns)
+	m.Show()
+
+	// Ungrouped first, then each group under a header.
+	var got []string
+	for _, r := range m.rows() {
+		switch {
+		case r.header:
+			got = append(got, "["+r.group+"]")
+		case r.conn >= 0:
+			got = append(got, conns[r.conn].Name)
+		default:
+			got = append(got, "+")
+		}
+	}
+	want := []string{"scratch", "[acme]", "acme-prod", "acme-staging", "[personal]", "home", "+"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("rows = %v, want %v", got, want)
+	}
+	if view := m.View(); !strings.Contains(view, "acme (2)") || !strings.Contains(view, "PROD") {
+		t.Errorf("view should show the group and environment:\n%s", view)
+	}
+
+	// Enter on a header folds the group.
+	m.cursor = 1
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if n := len(m.rows()); n != 5 {
+		t.Errorf("rows after folding = %d, want 5", n)
+	}
+
+	// Connecting picks the connection under the cursor, not by position in
+	// the saved list.
+	m.cursor = 3 // home
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	msg := cmd().(ConnectRequestMsg)
+	if msg.Saved.Name != "home" {
+		t.Errorf("connected to %q, want home", msg.Saved.Name)
+	}
+}
+
+func TestImportWizard(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".pgpass")
+	data := "db1:5432:app:alice:pw1\ndb2:5432:app:bob:pw2\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	old := detectSources
+	detectSources = func() []importer.Source { return []importer.Source{{Kind: importer.KindPgpass, Path: path}} }
+	defer func() { detectSources = old }()
+
+	m := New([]config.SavedConnection{{Name: "bob@db2/app", Adapter: "postgres"}})
+	m.Show()
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	if m.state != StateImport || !strings.Contains(m.View(), "pgpass") {
+		t.Fatalf("import screen should list the detected file:\n%s", m.View())
+	}
+
+	// Reading the file ticks only the connection not saved yet.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if len(m.imp.found) != 2 || !m.imp.picked[0] || m.imp.picked[1] {
+		t.Fatalf("found %+v, picked %v; want alice ticked and bob left out", m.imp.found, m.imp.picked)
+	}
+	if !strings.Contains(m.View(), "already saved") {
+		t.Errorf("view should mark bob as already saved:\n%s", m.View())
+	}
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	msg, ok := cmd().(ConnectionsUpdatedMsg)
+	if !ok {
+		t.Fatal("expected ConnectionsUpdatedMsg")
+	}
+	if len(msg.Connections) != 2 || msg.Connections[1].Name != "alice@db1/app" || msg.Connections[1].Password != "pw1" {
+		t.Errorf("connections = %+v", msg.Connections)
+	}
+	if m.state != StateList || !strings.Contains(m.View(), "Imported 1 connections") {
+		t.Errorf("should be back on the list with a notice:\n%s", m.View())
+	}
+}
+
+func TestDuplicateConnection(t *testing.T) {
+	keyring.MockInit()
+	if err := keyring.Set("gotermsql", "abc123", "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+	conns := []config.SavedConnection{
+		{Name: "prod", Adapter: "postgres", Host: "db", User: "app", SecretID: "abc123", Color: "#FF00FF"},
+		{Name: "prod (copy)", Adapter: "postgres", Host: "db"},
+	}
+	m := New(conns)
+	m.Show()
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	if cmd == nil {
+		t.Fatal("c should duplicate the selected connection")
+	}
+	m, _ = m.Update(cmd())
+	if m.state != StateForm || m.editing != -1 {
+		t.Fatalf("state = %v, editing = %d; want the form for a new connection", m.state, m.editing)
+	}
+	if got := m.inputs[fieldName].Value(); got != "prod (copy 2)" {
+		t.Errorf("name = %q, want prod (copy 2)", got)
+	}
+
+	// The copy has the password itself, not the original's secret.
+	got := m.formConnection()
+	if got.Password != "s3cret" || got.SecretID != "" || got.Color != "#FF00FF" {
+		t.Errorf("copy = %+v; want the password, no secret ID and the color", got)
+	}
+}
+
+func TestRawDSNMode(t *testing.T) {
+	m := New(nil)
+	m.Show()
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	m.inputs[fieldName].SetValue("pg")
+	m.inputs[fieldAdapter].SetValue("postgres")
+	m.inputs[fieldHost].SetValue("db")
+	m.inputs[fieldUser].SetValue("app")
+	m.inputs[fieldPassword].SetValue("s3cret")
+	m.inputs[fieldDatabase].SetValue("shop")
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if !m.raw || m.formFocus != fieldDSN {
+		t.Fatal("ctrl+r should switch to the raw DSN with the DSN focused")
+	}
+	// The password stays out of the DSN.
+	if got := m.inputs[fieldDSN].Value(); got != "postgres://app@db/shop" {
+		t.Errorf("DSN = %q, want one built from the fields", got)
+	}
+	if v := m.View(); strings.Contains(v, "Host:") || !strings.Contains(v, "DSN:") {
+		t.Errorf("raw mode should show the DSN but not the host:\n%s", v)
+	}
+
+	m.inputs[fieldDSN].SetValue("postgres://app@db/shop?sslmode=require")
+	got := m.formConnection()
+	if got.Host != "" || got.DSN != "postgres://app@db/shop?sslmode=require" {
+		t.Errorf("raw connection = %+v, want only the DSN", got)
+	}
+	if dsn := got.BuildDSN(); dsn != "postgres://app:s3cret@db/shop?sslmode=require" {
+		t.Errorf("BuildDSN() = %q, want the password added", dsn)
+	}
+
+	// Switching back to unchanged fields drops the DSN again.
+	m.inputs[fieldDSN].SetValue("postgres://app@db/shop")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if m.raw || m.inputs[fieldDSN].Value() != "" {
+		t.Errorf("raw = %v, DSN = %q; want the fields back", m.raw, m.inputs[fieldDSN].Value())
+	}
+
+	// Editing a connection that has a DSN opens in raw mode.
+	m.loadIntoForm(config.SavedConnection{Name: "x", Adapter: "postgres", DSN: "postgres://db/x"})
+	if !m.raw {
+		t.Error("a connection with a DSN should open in raw mode")
+	}
+}
+
+func TestRawDSNMode_Validation(t *testing.T) {
+	m := New(nil)
+	m.Show()
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	m.inputs[fieldName].SetValue("pg")
+	m.inputs[fieldAdapter].SetValue("postgres")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+
+	m.inputs[fieldDSN].SetValue("postgres://app@db:notaport/shop")
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if cmd != nil || m.state != StateForm || !m.isError {
+		t.Fatalf("an invalid DSN should not be saved (state %v, message %q)", m.state, m.message)
+	}
+
+	m.inputs[fieldDSN].SetValue("")
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if cmd != nil || !m.isError {
+		t.Error("raw mode should require a DSN")
+	}
+
+	m.inputs[fieldDSN].SetValue("postgres://app@db:5432/shop")
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if cmd == nil || m.state != StateList {
+		t.Errorf("a valid DSN should be saved (message %q)", m.message)
+	}
+}
@@ -0,0 +1,134 @@
+package connmgr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/keychain"
+)
+
+// formFields returns the form fields shown, in order.
+func (m Model) formFields() []int {
+	if m.raw {
+		return rawFields
+	}
+	fields := make([]int, fieldCount)
+	for i := range fields {
+		fields[i] = i
+	}
+	return fields
+}
+
+// moveFocus moves the form focus by delta fields, wrapping around.
+func (m *Model) moveFocus(delta int) tea.Cmd {
+	fields := m.formFields()
+	pos := 0
+	for i, f := range fields {
+		if f == m.formFocus {
+			pos = i
+		}
+	}
+	pos = (pos + delta + len(fields)) % len(fields)
+	m.inputs[m.formFocus].Blur()
+	m.formFocus = fields[pos]
+	m.inputs[m.formFocus].Focus()
+	return textinput.Blink
+}
+
+// toggleRaw switches between the structured fields and editing the raw
+// DSN. A DSN is started from the structured fields, leaving the password
+// out; switching back drops it again if it was not changed.
+func (m *Model) toggleRaw() tea.Cmd {
+	structured := *m
+	structured.raw = false
+	built := structured.formToConnection()
+	built.DSN = ""
+	built.Password = ""
+	fromFields := ""
+	if built.Adapter != "" {
+		fromFields = built.BuildDSN()
+	}
+
+	dsn := m.inputs[fieldDSN].Value()
+	if m.raw {
+		if dsn == fromFields {
+			m.inputs[fieldDSN].SetValue("")
+		}
+	} else if dsn == "" {
+		m.inputs[fieldDSN].SetValue(fromFields)
+		m.inputs[fieldDSN].CursorEnd()
+	}
+	m.raw = !m.raw
+	m.message = ""
+
+	m.inputs[m.formFocus].Blur()
+	if m.raw {
+		m.formFocus = fieldDSN
+	}
+	m.inputs[m.formFocus].Focus()
+	return textinput.Blink
+}
+
+// validate checks the connection in the form before it is saved or tested.
+func (m Model) validate(conn config.SavedConnection) error {
+	if m.raw && strings.TrimSpace(conn.DSN) == "" {
+		return errors.New("enter a DSN, or press ctrl+r for the fields")
+	}
+	return validateDSN(conn)
+}
+
+// validateDSN checks the DSN of conn, if it has one, with the adapter's
+// DSNValidator. DSNs holding ${NAME} references are checked at connect
+// time instead.
+func validateDSN(conn config.SavedConnection) error {
+	if conn.DSN == "" || config.HasEnvRef(conn.DSN) {
+		return nil
+	}
+	a, ok := adapter.Registry[conn.Adapter]
+	if !ok {
+		return fmt.Errorf("unknown adapter: %q", conn.Adapter)
+	}
+	if conn.SSH != nil {
+		return errors.New("SSH tunnels need the host and port fields, not a DSN")
+	}
+	if v, ok := a.(adapter.DSNValidator); ok {
+		return v.ValidateDSN(conn.DSN)
+	}
+	return nil
+}
+
+// cloneMsg carries a copy of a saved connection to open in the form.
+type cloneMsg struct {
+	conn config.SavedConnection
+	err  error // reading the password from the keychain failed
+}
+
+// cloneConnection returns a command that copies sc under a new name. The
+// copy is saved with its own keychain entry, so its password is read from
+// the keychain here rather than sharing the original's secret.
+func (m Model) cloneConnection(sc config.SavedConnection) tea.Cmd {
+	sc.Name = m.copyName(sc.Name)
+	return func() tea.Msg {
+		err := keychain.Resolve(&sc)
+		sc.SecretID = ""
+		return cloneMsg{conn: sc, err: err}
+	}
+}
+
+// copyName returns "name (copy)", numbered if that is taken.
+func (m Model) copyName(name string) string {
+	taken := make(map[string]bool, len(m.connections))
+	for _, c := range m.connections {
+		taken[c.Name] = true
+	}
+	candidate := name + " (copy)"
+	for n := 2; taken[candidate]; n++ {
+		candidate = fmt.Sprintf("%s (copy %d)", name, n)
+	}
+	return candidate
+}
@@ -0,0 +1,105 @@
+package connmgr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+// listRow is a line of the connection list: a group header, a saved
+// connection, or the "New Connection" entry at the end.
+type listRow struct {
+	group  string // header: the group; connection: its group
+	conn   int    // index into m.connections, or -1
+	header bool
+}
+
+// rows lists the connection list lines. Connections without a group come
+// first, then each group in the order it first appears, under a header.
+// The connections of a collapsed group are hidden.
+func (m Model) rows() []listRow {
+	var rows []listRow
+	var groups []string
+	members := make(map[string][]int)
+	for i, c := range m.connections {
+		if c.Group == "" {
+			rows = append(rows, listRow{conn: i})
+			continue
+		}
+		if _, ok := members[c.Group]; !ok {
+			groups = append(groups, c.Group)
+		}
+		members[c.Group] = append(members[c.Group], i)
+	}
+	for _, g := range groups {
+		rows = append(rows, listRow{group: g, conn: -1, header: true})
+		if m.collapsed[g] {
+			continue
+		}
+		for _, i := range members[g] {
+			rows = append(rows, listRow{group: g, conn: i})
+		}
+	}
+	return append(rows, listRow{conn: -1})
+}
+
+// selected returns the row under the cursor.
+func (m Model) selected() listRow {
+	rows := m.rows()
+	if m.cursor < len(rows) {
+		return rows[m.cursor]
+	}
+	return rows[len(rows)-1]
+}
+
+// toggleGroup folds or unfolds a group.
+func (m *Model) toggleGroup(group string) {
+	if m.collapsed == nil {
+		m.collapsed = make(map[string]bool)
+	}
+	m.collapsed[group] = !m.collapsed[group]
+}
+
+// renderRow renders a line of the connection list.
+func (m Model) renderRow(r listRow, selected bool, th *theme.Theme) string {
+	var line string
+	switch {
+	case r.header:
+		arrow := "▾"
+		if m.collapsed[r.group] {
+			arrow = "▸"
+		}
+		n := 0
+		for _, c := range m.connections {
+			if c.Group == r.group {
+				n++
+			}
+		}
+		line = fmt.Sprintf("  %s %s (%d)", arrow, r.group, n)
+	case r.conn >= 0:
+		conn := m.connections[r.conn]
+		indent := "  "
+		if r.group != "" {
+			indent = "    "
+		}
+		line = fmt.Sprintf("%s%s  (%s)", indent, conn.Name, conn.DisplayString())
+		if conn.Env != "" && !selected {
+			tag := theme.EnvBadge(theme.EnvColor(conn.Env, conn.Color)).Render(" " + strings.ToUpper(conn.Env) + " ")
+			return "  " + line + " " + tag
+		}
+		if conn.Env != "" {
+			line += "  [" + strings.ToUpper(conn.Env) + "]"
+		}
+	default:
+		line = "  + New Connection"
+	}
+
+	switch {
+	case selected:
+		return th.SidebarSelected.Render(line)
+	case r.header:
+		return "  " + th.SidebarSchema.Render(line)
+	}
+	return "  " + line
+}
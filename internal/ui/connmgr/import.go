@@ -0,0 +1,225 @@
+package connmgr
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/importer"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+// detectSources is swapped out in tests.
+var detectSources = importer.Detect
+
+// importWizard is the state of the import screen, opened with i. It first
+// lists the files found in their default locations plus a path to type,
+// then the connections in the picked file, to tick and import.
+type importWizard struct {
+	sources []importer.Source
+	path    textinput.Model // row len(sources)
+	from    string          // file the found connections came from
+
+	found  []config.SavedConnection
+	picked []bool
+	exists []bool // a saved connection already has the name
+
+	cursor int
+	err    string
+}
+
+// openImport switches to the import screen.
+func (m *Model) openImport() tea.Cmd {
+	path := textinput.New()
+	path.Prompt = "Other file: "
+	path.Placeholder = "~/.pgpass, my.cnf, data-sources.json, dataSources.xml"
+	path.Width = 40
+	m.imp = importWizard{sources: detectSources(), path: path}
+	m.state = StateImport
+	return m.imp.focusPath()
+}
+
+// focusPath focuses the path input when the cursor is on it.
+func (w *importWizard) focusPath() tea.Cmd {
+	if w.found == nil && w.cursor == len(w.sources) {
+		return w.path.Focus()
+	}
+	w.path.Blur()
+	return nil
+}
+
+func (m Model) updateImport(msg tea.Msg) (Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	w := &m.imp
+	if w.found != nil {
+		return m.updateImportPick(key)
+	}
+
+	switch key.String() {
+	case "esc":
+		m.state = StateList
+		return m, nil
+	case "up", "shift+tab":
+		if w.cursor > 0 {
+			w.cursor--
+		}
+		return m, w.focusPath()
+	case "down", "tab":
+		if w.cursor < len(w.sources) {
+			w.cursor++
+		}
+		return m, w.focusPath()
+	case "enter":
+		w.err = ""
+		var src importer.Source
+		if w.cursor < len(w.sources) {
+			src = w.sources[w.cursor]
+		} else {
+			src.Path = config.ExpandHome(strings.TrimSpace(w.path.Value()))
+			if src.Path == "" {
+				return m, nil
+			}
+			kind, err := importer.KindOf(src.Path)
+			if err != nil {
+				w.err = err.Error()
+				return m, nil
+			}
+			src.Kind = kind
+		}
+		m.loadImport(src)
+		return m, w.focusPath()
+	}
+
+	if w.cursor == len(w.sources) {
+		var cmd tea.Cmd
+		w.path, cmd = w.path.Update(key)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// loadImport reads the connections in src and ticks those whose names are
+// not saved yet.
+func (m *Model) loadImport(src importer.Source) {
+	w := &m.imp
+	conns, err := importer.ReadFile(src.Kind, src.Path)
+	switch {
+	case err != nil:
+		w.err = err.Error()
+		return
+	case len(conns) == 0:
+		w.err = "No connections found in " + src.Path
+		return
+	}
+	saved := make(map[string]bool)
+	for _, c := range m.connections {
+		saved[c.Name] = true
+	}
+	w.from = src.Path
+	w.found = conns
+	w.picked = make([]bool, len(conns))
+	w.exists = make([]bool, len(conns))
+	for i, c := range conns {
+		w.exists[i] = saved[c.Name]
+		w.picked[i] = !w.exists[i]
+	}
+	w.cursor = 0
+}
+
+// updateImportPick handles keys while ticking the connections to import.
+func (m Model) updateImportPick(key tea.KeyMsg) (Model, tea.Cmd) {
+	w := &m.imp
+	switch key.String() {
+	case "esc":
+		w.found = nil
+		w.cursor = 0
+		return m, w.focusPath()
+	case "up", "k":
+		if w.cursor > 0 {
+			w.cursor--
+		}
+	case "down", "j":
+		if w.cursor < len(w.found)-1 {
+			w.cursor++
+		}
+	case " ":
+		w.picked[w.cursor] = !w.picked[w.cursor]
+	case "a":
+		all := true
+		for _, p := range w.picked {
+			all = all && p
+		}
+		for i := range w.picked {
+			w.picked[i] = !all
+		}
+	case "enter":
+		n := 0
+		for i, c := range w.found {
+			if w.picked[i] {
+				m.connections = append(m.connections, c)
+				n++
+			}
+		}
+		m.state = StateList
+		if n == 0 {
+			return m, nil
+		}
+		m.notice = fmt.Sprintf("Imported %d connections from %s", n, filepath.Base(w.from))
+		conns := make([]config.SavedConnection, len(m.connections))
+		copy(conns, m.connections)
+		return m, func() tea.Msg { return ConnectionsUpdatedMsg{Connections: conns} }
+	}
+	return m, nil
+}
+
+func (m Model) viewImport(th *theme.Theme) string {
+	w := m.imp
+	var lines []string
+	if w.found == nil {
+		lines = append(lines, th.DialogTitle.Render("  Import Connections  "), "")
+		if len(w.sources) == 0 {
+			lines = append(lines, th.MutedText.Render("  No .pgpass, .my.cnf or DBeaver connections found"))
+		}
+		for i, src := range w.sources {
+			line := runewidth.Truncate(fmt.Sprintf("  %-9s %s", src.Kind, src.Path), m.dialogWidth()-6, "…")
+			if i == w.cursor {
+				lines = append(lines, th.SidebarSelected.Render(line))
+			} else {
+				lines = append(lines, "  "+line)
+			}
+		}
+		lines = append(lines, "", "  "+w.path.View())
+		if w.err != "" {
+			lines = append(lines, "", th.ErrorText.Render("  "+w.err))
+		}
+		lines = append(lines, "", th.MutedText.Render("  enter:read  up/down:move  esc:back"))
+	} else {
+		lines = append(lines, th.DialogTitle.Render("  Import from "+filepath.Base(w.from)+"  "), "")
+		for i, c := range w.found {
+			box := "[ ]"
+			if w.picked[i] {
+				box = "[x]"
+			}
+			line := fmt.Sprintf("  %s %s  (%s)", box, c.Name, c.DisplayString())
+			if w.exists[i] {
+				line = fmt.Sprintf("  %s %s  (already saved)", box, c.Name)
+			}
+			line = runewidth.Truncate(line, m.dialogWidth()-6, "…")
+			if i == w.cursor {
+				lines = append(lines, th.SidebarSelected.Render(line))
+			} else {
+				lines = append(lines, "  "+line)
+			}
+		}
+		lines = append(lines, "", th.MutedText.Render("  space:tick  a:all  enter:import  esc:back"))
+	}
+	return th.DialogBorder.Width(m.dialogWidth()).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
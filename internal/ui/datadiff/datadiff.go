@@ -0,0 +1,409 @@
+// Package datadiff is the comparison of the rows of two tables, opened
+// from the sidebar menu: pick the connection of the other table (the one
+// open or a saved one), name it, then list the rows added, deleted and
+// changed by primary key, with the DML that turns the first table into
+// the second.
+package datadiff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	appmsg "github.com/sadopc/gotermsql/internal/msg"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+// writeClipboard is swapped out in tests.
+var writeClipboard = clipboard.WriteAll
+
+// Target is a connection the other table can be on.
+type Target struct {
+	Current bool   // the connection open
+	Name    string // the saved connection
+	Adapter string
+}
+
+// Label names the target in the list.
+func (t Target) Label() string {
+	if t.Current {
+		return "Current connection"
+	}
+	return t.Name
+}
+
+// StartMsg asks the app to compare the rows of the table with those of
+// Table on Target.
+type StartMsg struct {
+	Target Target
+	Table  string
+}
+
+// CancelMsg asks the app to stop the comparison running.
+type CancelMsg struct{}
+
+// Kind is how a row differs.
+type Kind int
+
+const (
+	Added   Kind = iota // only the other table has the row
+	Deleted             // only the table compared has the row
+	Changed             // both have the row, with other values
+)
+
+// Row is a row that differs, by its key.
+type Row struct {
+	Kind   Kind
+	Key    string // the key columns and their values
+	Detail string // the values of the row, or those that changed
+}
+
+// Result is what a comparison found.
+type Result struct {
+	Added, Deleted, Changed, Same int64
+	Rows                          []Row  // the first of the rows that differ
+	Script                        string // the DML turning the table into the other
+}
+
+// step is a page of the comparison.
+type step int
+
+const (
+	stepTarget  step = iota // pick the connection
+	stepTable               // name the table
+	stepRunning             // rows being compared
+	stepResult              // the rows that differ
+	stepFailed              // failed or stopped
+)
+
+// Model is the data comparison modal.
+type Model struct {
+	table    string
+	targets  []Target
+	target   Target
+	input    textinput.Model
+	step     step
+	cursor   int
+	offset   int
+	progress string
+	result   Result
+	other    string // the other table, as typed
+	message  string
+	visible  bool
+	width    int
+	height   int
+}
+
+// New creates a hidden comparison.
+func New() Model {
+	ti := textinput.New()
+	ti.Prompt = "  Table: "
+	ti.Width = 50
+	return Model{input: ti}
+}
+
+// Show opens the comparison of the rows of table with a table on one of
+// targets.
+func (m *Model) Show(table string, targets []Target) {
+	input := m.input
+	input.SetValue(table)
+	input.Blur()
+	*m = Model{table: table, targets: targets, input: input, visible: true, width: m.width, height: m.height}
+}
+
+// Hide closes the comparison.
+func (m *Model) Hide() {
+	m.visible = false
+	m.input.Blur()
+}
+
+// Visible returns whether the comparison is shown.
+func (m Model) Visible() bool { return m.visible }
+
+// Running returns whether rows are being compared.
+func (m Model) Running() bool { return m.step == stepRunning }
+
+// SetSize sets the available space.
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.clamp()
+}
+
+// SetProgress shows how far the comparison has got.
+func (m *Model) SetProgress(text string) {
+	if m.step == stepRunning {
+		m.progress = text
+	}
+}
+
+// SetResult shows the rows that differ.
+func (m *Model) SetResult(r Result) {
+	m.step = stepResult
+	m.result = r
+	m.cursor, m.offset = 0, 0
+}
+
+// Fail shows why the comparison stopped.
+func (m *Model) Fail(text string) {
+	m.step = stepFailed
+	m.message = text
+}
+
+// Update handles key presses, page by page: pick a connection, name the
+// table and start, then browse the rows that differ, where e opens the
+// DML in a new tab and y copies it.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if !m.visible {
+		return m, nil
+	}
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		if m.step == stepTable {
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	switch m.step {
+	case stepTarget:
+		switch key.String() {
+		case "esc", "q":
+			m.Hide()
+		case "up", "k":
+			m.cursor--
+		case "down", "j":
+			m.cursor++
+		case "enter":
+			if m.cursor < len(m.targets) {
+				m.target = m.targets[m.cursor]
+				m.step = stepTable
+				m.input.Focus()
+				m.input.CursorEnd()
+			}
+		}
+		m.clamp()
+		return m, nil
+
+	case stepTable:
+		switch key.String() {
+		case "esc":
+			m.step = stepTarget
+			m.input.Blur()
+			return m, nil
+		case "enter":
+			table := strings.TrimSpace(m.input.Value())
+			if table == "" {
+				return m, nil
+			}
+			m.step = stepRunning
+			m.progress = ""
+			m.other = table
+			m.input.Blur()
+			start := StartMsg{Target: m.target, Table: table}
+			return m, func() tea.Msg { return start }
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(key)
+		return m, cmd
+
+	case stepRunning:
+		if key.String() == "esc" {
+			m.progress = "Stopping..."
+			return m, func() tea.Msg { return CancelMsg{} }
+		}
+		return m, nil
+
+	case stepFailed:
+		switch key.String() {
+		case "esc", "q", "enter":
+			m.Hide()
+		}
+		return m, nil
+	}
+
+	switch key.String() {
+	case "esc", "q":
+		m.Hide()
+	case "up", "k":
+		m.cursor--
+	case "down", "j":
+		m.cursor++
+	case "pgup":
+		m.cursor -= m.listRows()
+	case "pgdown":
+		m.cursor += m.listRows()
+	case "home", "g":
+		m.cursor = 0
+	case "end", "G":
+		m.cursor = len(m.result.Rows) - 1
+	case "e":
+		if m.result.Script == "" {
+			return m, nil
+		}
+		m.Hide()
+		script := m.result.Script
+		return m, func() tea.Msg { return appmsg.NewTabMsg{Query: script} }
+	case "y":
+		if m.result.Script == "" {
+			return m, nil
+		}
+		script := m.result.Script
+		return m, func() tea.Msg {
+			if err := writeClipboard(script); err != nil {
+				return appmsg.StatusMsg{Text: "Copy failed: " + err.Error(), IsError: true}
+			}
+			return appmsg.StatusMsg{Text: "Copied the DML"}
+		}
+	}
+	m.clamp()
+	return m, nil
+}
+
+// count returns how many rows the list has.
+func (m Model) count() int {
+	if m.step == stepResult {
+		return len(m.result.Rows)
+	}
+	return len(m.targets)
+}
+
+func (m *Model) clamp() {
+	m.cursor = max(min(m.cursor, m.count()-1), 0)
+	rows := m.listRows()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+}
+
+// listRows returns how many rows of the list fit in the modal.
+func (m Model) listRows() int {
+	// Title, subtitle, counts, a note, help, the blank lines between them
+	// and the border.
+	return max(m.height-11, 3)
+}
+
+// View renders the comparison.
+func (m Model) View() string {
+	if !m.visible {
+		return ""
+	}
+	th := theme.Current
+	w := 100
+	if m.width > 0 && w > m.width-4 {
+		w = m.width - 4
+	}
+	textW := w - 6
+
+	lines := []string{th.DialogTitle.Render("  Compare Data: " + m.table + "  ")}
+	var help string
+	switch m.step {
+	case stepTarget:
+		lines = append(lines, th.MutedText.Render("  Compare the rows with a table on:"), "")
+		end := min(m.offset+m.listRows(), len(m.targets))
+		for i := m.offset; i < end; i++ {
+			t := m.targets[i]
+			line := runewidth.Truncate(runewidth.FillRight(runewidth.Truncate(t.Label(), 40, "…"), 42)+t.Adapter, textW, "…")
+			if i == m.cursor {
+				lines = append(lines, "  "+th.SidebarSelected.Render(runewidth.FillRight(line, textW)))
+			} else {
+				lines = append(lines, "  "+line)
+			}
+		}
+		help = "enter:pick  esc:close"
+
+	case stepTable:
+		lines = append(lines,
+			th.MutedText.Render("  On "+m.targetLabel()+", matched by the primary key of "+m.table+":"),
+			"",
+			m.input.View(),
+		)
+		help = "enter:compare  esc:back"
+
+	case stepRunning:
+		progress := m.progress
+		if progress == "" {
+			progress = "Starting..."
+		}
+		lines = append(lines, th.MutedText.Render("  "+m.sides(textW)), "", "  "+progress)
+		help = "esc:stop"
+
+	case stepFailed:
+		lines = append(lines, th.MutedText.Render("  "+m.sides(textW)), "",
+			th.ErrorText.Render("  "+runewidth.Truncate(m.message, textW, "…")))
+		help = "esc:close"
+
+	case stepResult:
+		lines = append(lines, m.resultLines(textW)...)
+		help = "esc:close"
+		if m.result.Script != "" {
+			help = "e:open DML in a tab  y:copy DML  esc:close"
+		}
+	}
+	lines = append(lines, "", th.MutedText.Render("  "+help))
+	return th.DialogBorder.Width(w).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// targetLabel names the connection picked, with its adapter.
+func (m Model) targetLabel() string {
+	return m.target.Label() + " (" + m.target.Adapter + ")"
+}
+
+// sides names the tables compared.
+func (m Model) sides(textW int) string {
+	return runewidth.Truncate(m.table+" → "+m.other+" on "+m.targetLabel(), textW, "…")
+}
+
+// resultLines renders the counts and the rows that differ.
+func (m Model) resultLines(textW int) []string {
+	th := theme.Current
+	r := m.result
+	lines := []string{
+		th.MutedText.Render("  " + m.sides(textW)), "",
+		"  " + th.SuccessText.Render(fmt.Sprintf("+ %d added", r.Added)) + "   " +
+			th.ErrorText.Render(fmt.Sprintf("- %d deleted", r.Deleted)) + "   " +
+			th.WarningText.Render(fmt.Sprintf("~ %d changed", r.Changed)) + "   " +
+			th.MutedText.Render(fmt.Sprintf("%d the same", r.Same)),
+		"",
+	}
+
+	rows := m.listRows()
+	end := min(m.offset+rows, len(r.Rows))
+	for i := m.offset; i < end; i++ {
+		row := r.Rows[i]
+		mark, style := "~", th.WarningText
+		switch row.Kind {
+		case Added:
+			mark, style = "+", th.SuccessText
+		case Deleted:
+			mark, style = "-", th.ErrorText
+		}
+		line := mark + " " + runewidth.FillRight(runewidth.Truncate(row.Key, 24, "…"), 25) + row.Detail
+		line = runewidth.Truncate(line, textW, "…")
+		if i == m.cursor {
+			lines = append(lines, "  "+th.SidebarSelected.Render(runewidth.FillRight(line, textW)))
+		} else {
+			lines = append(lines, "  "+style.Render(line))
+		}
+	}
+	if len(r.Rows) == 0 {
+		lines = append(lines, th.SuccessText.Render("  The tables hold the same rows"))
+		rows--
+	}
+	for i := end - m.offset; i < rows; i++ {
+		lines = append(lines, "")
+	}
+	if differ := r.Added + r.Deleted + r.Changed; int64(len(r.Rows)) < differ {
+		lines = append(lines, th.MutedText.Render(fmt.Sprintf("  Listing and writing DML for the first %d of the %d rows that differ", len(r.Rows), differ)))
+	}
+	return lines
+}
@@ -0,0 +1,86 @@
+package datadiff
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	appmsg "github.com/sadopc/gotermsql/internal/msg"
+)
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestCompare(t *testing.T) {
+	m := New()
+	m.SetSize(120, 30)
+	m.Show("orders", []Target{{Current: true, Adapter: "postgres"}, {Name: "staging", Adapter: "sqlite"}})
+	if view := m.View(); !strings.Contains(view, "Compare Data: orders") || !strings.Contains(view, "Current connection") || !strings.Contains(view, "staging") {
+		t.Fatalf("view lacks the targets:\n%s", view)
+	}
+
+	m, _ = m.Update(key("j"))
+	m, _ = m.Update(key("enter"))
+	if view := m.View(); !strings.Contains(view, "staging (sqlite)") || !strings.Contains(view, "orders") {
+		t.Fatalf("view lacks the table to compare with:\n%s", view)
+	}
+	m, _ = m.Update(key("_old"))
+	m, cmd := m.Update(key("enter"))
+	want := StartMsg{Target: Target{Name: "staging", Adapter: "sqlite"}, Table: "orders_old"}
+	if cmd == nil || cmd() != want || !m.Running() {
+		t.Fatalf("enter should compare with orders_old on staging")
+	}
+	m.SetProgress("1500 rows compared")
+	if !strings.Contains(m.View(), "1500 rows compared") {
+		t.Errorf("view lacks the progress:\n%s", m.View())
+	}
+	if _, cmd := m.Update(key("esc")); cmd == nil || cmd() != (CancelMsg{}) {
+		t.Error("esc while running should stop the comparison")
+	}
+
+	m.SetResult(Result{
+		Added: 1, Deleted: 1, Changed: 2, Same: 7,
+		Rows: []Row{
+			{Kind: Added, Key: "id=4", Detail: "status=new"},
+			{Kind: Deleted, Key: "id=5", Detail: "status=paid"},
+			{Kind: Changed, Key: "id=6", Detail: "status: new → paid"},
+		},
+		Script: "DELETE FROM orders WHERE id IN (5);\n",
+	})
+	view := m.View()
+	for _, want := range []string{"+ 1 added", "- 1 deleted", "~ 2 changed", "7 the same", "status: new → paid", "first 3 of the 4 rows"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+
+	var copied string
+	writeClipboard = func(s string) error { copied = s; return nil }
+	if _, cmd := m.Update(key("y")); cmd == nil || cmd() != (appmsg.StatusMsg{Text: "Copied the DML"}) || copied != "DELETE FROM orders WHERE id IN (5);\n" {
+		t.Errorf("y should copy the DML, copied %q", copied)
+	}
+	m, cmd = m.Update(key("e"))
+	if cmd == nil || cmd() != (appmsg.NewTabMsg{Query: "DELETE FROM orders WHERE id IN (5);\n"}) || m.Visible() {
+		t.Error("e should open the DML in a new tab and close")
+	}
+}
+
+func TestCompare_Same(t *testing.T) {
+	m := New()
+	m.SetSize(120, 30)
+	m.Show("orders", []Target{{Current: true, Adapter: "sqlite"}})
+	m.SetResult(Result{Same: 3})
+	if view := m.View(); !strings.Contains(view, "The tables hold the same rows") || strings.Contains(view, "e:open") {
+		t.Errorf("view should report no differences and offer no DML:\n%s", view)
+	}
+	if _, cmd := m.Update(key("e")); cmd != nil {
+		t.Error("e should do nothing with no DML")
+	}
+}
@@ -0,0 +1,175 @@
+// Package datagen is the dialog of the test data generator, opened from
+// the sidebar menu: ask how many rows to make up for a table, then follow
+// them being inserted.
+package datagen
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+// MaxRows caps the rows asked for at once.
+const MaxRows = 1_000_000
+
+// StartMsg asks the app to insert Rows rows of test data.
+type StartMsg struct {
+	Rows int
+}
+
+// CancelMsg asks the app to stop inserting.
+type CancelMsg struct{}
+
+// Model is the test data dialog.
+type Model struct {
+	table    string
+	input    textinput.Model
+	running  bool
+	done     bool
+	progress string
+	message  string
+	failed   bool
+	visible  bool
+	width    int
+}
+
+// New creates a hidden dialog.
+func New() Model {
+	ti := textinput.New()
+	ti.Prompt = "  Rows: "
+	ti.Width = 12
+	ti.CharLimit = 7
+	return Model{input: ti}
+}
+
+// Show opens the dialog for table.
+func (m *Model) Show(table string) {
+	input := m.input
+	input.SetValue("100")
+	input.CursorEnd()
+	input.Focus()
+	*m = Model{table: table, input: input, visible: true, width: m.width}
+}
+
+// Hide closes the dialog.
+func (m *Model) Hide() {
+	m.visible = false
+	m.input.Blur()
+}
+
+// Visible returns whether the dialog is shown.
+func (m Model) Visible() bool { return m.visible }
+
+// Running returns whether rows are being inserted.
+func (m Model) Running() bool { return m.running }
+
+// SetSize sets the available width.
+func (m *Model) SetSize(width, _ int) {
+	m.width = width
+}
+
+// SetProgress shows how many rows are in.
+func (m *Model) SetProgress(text string) {
+	if m.running {
+		m.progress = text
+	}
+}
+
+// Done shows how the insert ended.
+func (m *Model) Done(text string, failed bool) {
+	m.running = false
+	m.done = true
+	m.message = text
+	m.failed = failed
+}
+
+// Update handles key presses: enter starts, esc stops a run or closes.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !m.visible || !ok {
+		return m, nil
+	}
+	switch {
+	case m.running:
+		if key.String() == "esc" {
+			m.progress = "Stopping..."
+			return m, func() tea.Msg { return CancelMsg{} }
+		}
+		return m, nil
+	case m.done:
+		switch key.String() {
+		case "esc", "q", "enter":
+			m.Hide()
+		}
+		return m, nil
+	}
+
+	switch key.String() {
+	case "esc":
+		m.Hide()
+		return m, nil
+	case "enter":
+		n, err := strconv.Atoi(strings.TrimSpace(m.input.Value()))
+		if err != nil || n < 1 || n > MaxRows {
+			m.message, m.failed = "Type a number of rows from 1 to "+strconv.Itoa(MaxRows), true
+			return m, nil
+		}
+		m.running = true
+		m.progress, m.message, m.failed = "", "", false
+		m.input.Blur()
+		return m, func() tea.Msg { return StartMsg{Rows: n} }
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(key)
+	return m, cmd
+}
+
+// View renders the dialog.
+func (m Model) View() string {
+	if !m.visible {
+		return ""
+	}
+	th := theme.Current
+	w := 70
+	if m.width > 0 && w > m.width-4 {
+		w = m.width - 4
+	}
+	textW := w - 6
+
+	lines := []string{th.DialogTitle.Render("  Generate Test Data: " + m.table + "  "), ""}
+	var help string
+	switch {
+	case m.running:
+		progress := m.progress
+		if progress == "" {
+			progress = "Sampling the tables referenced..."
+		}
+		lines = append(lines, "  "+progress)
+		help = "esc:stop"
+	case m.done:
+		style := th.MutedText
+		if m.failed {
+			style = th.ErrorText
+		}
+		lines = append(lines, style.Render("  "+runewidth.Truncate(m.message, textW, "…")))
+		help = "esc:close"
+	default:
+		lines = append(lines,
+			th.MutedText.Render("  Made up from the names and types of the columns;"),
+			th.MutedText.Render("  foreign keys take values of the tables they reference."),
+			"",
+			m.input.View(),
+		)
+		if m.message != "" {
+			lines = append(lines, th.ErrorText.Render("  "+runewidth.Truncate(m.message, textW, "…")))
+		}
+		help = "enter:insert  esc:close"
+	}
+	lines = append(lines, "", th.MutedText.Render("  "+help))
+	return th.DialogBorder.Width(w).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,44 @@
+package datagen
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDialog(t *testing.T) {
+	m := New()
+	m.SetSize(120, 30)
+	m.Show("orders")
+	if view := m.View(); !strings.Contains(view, "Generate Test Data: orders") || !strings.Contains(view, "100") {
+		t.Errorf("view lacks the title or the default count:\n%s", view)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter}); m.Running() || !strings.Contains(m.View(), "from 1 to") {
+		t.Fatalf("100x should be refused:\n%s", m.View())
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("0")})
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || cmd() != (StartMsg{Rows: 1000}) || !m.Running() {
+		t.Fatal("enter should start inserting 1000 rows")
+	}
+
+	m.SetProgress("500 of 1000 rows inserted (50%)")
+	if !strings.Contains(m.View(), "50%") {
+		t.Errorf("view lacks the progress:\n%s", m.View())
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc}); cmd == nil || cmd() != (CancelMsg{}) {
+		t.Error("esc while running should stop the run")
+	}
+
+	m.Done("1000 rows inserted into orders", false)
+	if m.Running() || !strings.Contains(m.View(), "1000 rows inserted") {
+		t.Errorf("view lacks how the run ended:\n%s", m.View())
+	}
+	if m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc}); m.Visible() {
+		t.Error("esc should close the finished dialog")
+	}
+}
@@ -0,0 +1,214 @@
+// Package dependencies is the dependency browser of a table or view,
+// opened from the sidebar menu: the views, foreign keys and triggers that
+// depend on it, which altering or dropping it breaks, and the objects it
+// depends on. Picking one selects it in the sidebar.
+package dependencies
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+// PickMsg is sent when a dependency is picked.
+type PickMsg struct {
+	Dependency adapter.Dependency
+}
+
+// Model is the dependency browser modal.
+type Model struct {
+	name    string
+	deps    adapter.Dependencies
+	cursor  int // over the dependents, then what name depends on
+	offset  int // first line of the list shown
+	visible bool
+	loading bool
+	message string
+	width   int
+	height  int
+}
+
+// New creates a hidden dependency browser.
+func New() Model {
+	return Model{}
+}
+
+// Show opens the browser for the table or view name, waiting for its
+// dependencies.
+func (m *Model) Show(name string) {
+	*m = Model{name: name, visible: true, loading: true, width: m.width, height: m.height}
+}
+
+// Hide closes the browser.
+func (m *Model) Hide() {
+	m.visible = false
+}
+
+// Visible returns whether the browser is shown.
+func (m Model) Visible() bool { return m.visible }
+
+// Name returns the table or view browsed.
+func (m Model) Name() string { return m.name }
+
+// Loading returns whether the dependencies are still being read.
+func (m Model) Loading() bool { return m.loading }
+
+// SetSize sets the available space.
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.clamp()
+}
+
+// SetDependencies shows the dependencies read.
+func (m *Model) SetDependencies(deps adapter.Dependencies) {
+	m.deps = deps
+	m.loading = false
+	m.cursor, m.offset = 0, 0
+	m.clamp()
+}
+
+// SetError shows why the dependencies could not be read.
+func (m *Model) SetError(text string) {
+	m.message = text
+	m.loading = false
+}
+
+// Update handles key presses: the arrow keys move, enter picks the object
+// selected and esc closes.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !m.visible || !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "esc", "q":
+		m.visible = false
+		return m, nil
+	case "enter":
+		d, ok := m.selected()
+		if !ok {
+			return m, nil
+		}
+		m.visible = false
+		return m, func() tea.Msg { return PickMsg{Dependency: d} }
+	case "up", "k":
+		m.cursor--
+	case "down", "j":
+		m.cursor++
+	case "pgup":
+		m.cursor -= m.listRows()
+	case "pgdown":
+		m.cursor += m.listRows()
+	case "home", "g":
+		m.cursor = 0
+	case "end", "G":
+		m.cursor = m.count() - 1
+	}
+	m.clamp()
+	return m, nil
+}
+
+// count returns how many dependencies there are, in both directions.
+func (m Model) count() int {
+	return len(m.deps.Dependents) + len(m.deps.DependsOn)
+}
+
+// selected returns the dependency under the cursor.
+func (m Model) selected() (adapter.Dependency, bool) {
+	if m.loading || m.cursor >= m.count() {
+		return adapter.Dependency{}, false
+	}
+	if m.cursor < len(m.deps.Dependents) {
+		return m.deps.Dependents[m.cursor], true
+	}
+	return m.deps.DependsOn[m.cursor-len(m.deps.Dependents)], true
+}
+
+// line returns the line of the list the cursor is on: the dependents
+// follow their title, and the other list its title and a blank line.
+func (m Model) line() int {
+	if m.cursor < len(m.deps.Dependents) {
+		return m.cursor + 1
+	}
+	return m.cursor - len(m.deps.Dependents) + max(len(m.deps.Dependents), 1) + 3
+}
+
+func (m *Model) clamp() {
+	m.cursor = max(min(m.cursor, m.count()-1), 0)
+	rows := m.listRows()
+	line := m.line()
+	if m.cursor == 0 {
+		line = 0 // keep the first title in view
+	}
+	if line < m.offset {
+		m.offset = line
+	}
+	if line >= m.offset+rows {
+		m.offset = line - rows + 1
+	}
+}
+
+// listRows returns how many lines of the list fit in the modal.
+func (m Model) listRows() int {
+	// Title, help, the blank lines between them and the border.
+	return max(m.height-8, 3)
+}
+
+// View renders the browser.
+func (m Model) View() string {
+	if !m.visible {
+		return ""
+	}
+	th := theme.Current
+	w := 100
+	if m.width > 0 && w > m.width-4 {
+		w = m.width - 4
+	}
+	textW := w - 6
+
+	lines := []string{th.DialogTitle.Render("  Dependencies: " + m.name + "  "), ""}
+	switch {
+	case m.loading:
+		lines = append(lines, th.MutedText.Render("  Reading the catalog..."))
+	case m.message != "":
+		lines = append(lines, th.ErrorText.Render("  "+runewidth.Truncate(m.message, textW, "…")))
+	default:
+		list := m.listLines(textW)
+		end := min(m.offset+m.listRows(), len(list))
+		lines = append(lines, list[min(m.offset, end):end]...)
+	}
+	lines = append(lines, "", th.MutedText.Render(fmt.Sprintf("  %d depend on it, it depends on %d  enter:show in sidebar  esc:close",
+		len(m.deps.Dependents), len(m.deps.DependsOn))))
+	return th.DialogBorder.Width(w).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// listLines renders both lists under their titles.
+func (m Model) listLines(textW int) []string {
+	th := theme.Current
+	lines := []string{th.MutedText.Render("  Depends on " + m.name + " (broken by altering or dropping it)")}
+	section := func(deps []adapter.Dependency, first int) {
+		if len(deps) == 0 {
+			lines = append(lines, th.MutedText.Render("    nothing"))
+			return
+		}
+		for i, d := range deps {
+			line := fmt.Sprintf("%-18s", d.Kind) +
+				runewidth.FillRight(runewidth.Truncate(d.Schema+"."+d.Name, 36, "…"), 37)
+			line = runewidth.Truncate(line+d.Detail, textW-2, "…")
+			if first+i == m.cursor {
+				lines = append(lines, "    "+th.SidebarSelected.Render(runewidth.FillRight(line, textW-2)))
+			} else {
+				lines = append(lines, "    "+line)
+			}
+		}
+	}
+	section(m.deps.Dependents, 0)
+	lines = append(lines, "", th.MutedText.Render("  "+m.name+" depends on"))
+	section(m.deps.DependsOn, len(m.deps.Dependents))
+	return lines
+}
@@ -0,0 +1,76 @@
+package dependencies
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+)
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestBrowser(t *testing.T) {
+	m := New()
+	m.SetSize(120, 30)
+	m.Show("customers")
+	if view := m.View(); !strings.Contains(view, "Reading the catalog") {
+		t.Errorf("view lacks the loading line:\n%s", view)
+	}
+	if _, cmd := m.Update(key("enter")); cmd != nil {
+		t.Error("enter while loading should pick nothing")
+	}
+
+	orders := adapter.Dependency{Kind: adapter.DependencyTable, Schema: "main", Name: "orders", Detail: "FOREIGN KEY (customer_id) REFERENCES customers(id)"}
+	audit := adapter.Dependency{Kind: adapter.DependencyTable, Schema: "main", Name: "audit", Detail: "used by trigger customers_audit"}
+	m.SetDependencies(adapter.Dependencies{
+		Dependents: []adapter.Dependency{
+			{Kind: adapter.DependencyView, Schema: "main", Name: "big_customers"},
+			orders,
+		},
+		DependsOn: []adapter.Dependency{audit},
+	})
+	view := m.View()
+	for _, want := range []string{"Depends on customers", "main.big_customers", "FOREIGN KEY (customer_id)", "customers depends on", "main.audit", "2 depend on it, it depends on 1"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+
+	m, _ = m.Update(key("j"))
+	if _, cmd := m.Update(key("enter")); cmd == nil || cmd() != (PickMsg{Dependency: orders}) {
+		t.Error("enter should pick orders")
+	}
+	m, _ = m.Update(key("G"))
+	m, cmd := m.Update(key("enter"))
+	if cmd == nil || cmd() != (PickMsg{Dependency: audit}) {
+		t.Error("enter on the last line should pick audit")
+	}
+	if m.Visible() {
+		t.Error("picking should close the browser")
+	}
+}
+
+func TestBrowser_Empty(t *testing.T) {
+	m := New()
+	m.SetSize(120, 30)
+	m.Show("lonely")
+	m.SetDependencies(adapter.Dependencies{})
+	if view := m.View(); strings.Count(view, "nothing") != 2 {
+		t.Errorf("both lists should say nothing:\n%s", view)
+	}
+	if _, cmd := m.Update(key("enter")); cmd != nil {
+		t.Error("enter with no dependencies should pick nothing")
+	}
+	if m, _ = m.Update(key("esc")); m.Visible() {
+		t.Error("esc should close the browser")
+	}
+}
@@ -3,6 +3,7 @@ package dialog
 import (
 	"strings"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/sadopc/gotermsql/internal/theme"
@@ -12,6 +13,9 @@ import (
 type Button struct {
 	Label  string
 	Action func() tea.Msg
+	// Guarded buttons only fire once the text set with RequireText has
+	// been typed, for destructive actions such as DROP.
+	Guarded bool
 }
 
 // Model is a reusable modal dialog component.
@@ -24,6 +28,11 @@ type Model struct {
 	width     int
 	maxWidth  int
 	maxHeight int
+
+	// Typed confirmation (RequireText)
+	require  string
+	input    textinput.Model
+	mismatch bool
 }
 
 // New creates a new dialog.
@@ -36,6 +45,17 @@ func New(title, body string, buttons ...Button) Model {
 	}
 }
 
+// RequireText adds a text field the user must fill with text before a
+// Guarded button fires.
+func (m *Model) RequireText(text string) {
+	m.require = text
+	m.input = textinput.New()
+	m.input.Prompt = "> "
+	m.input.Placeholder = text
+	m.input.CharLimit = 200
+	m.input.Focus()
+}
+
 // Init returns no initial command.
 func (m Model) Init() tea.Cmd {
 	return nil
@@ -50,21 +70,44 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
-		case "left", "shift+tab":
+		case "shift+tab":
 			if m.active > 0 {
 				m.active--
 			}
-		case "right", "tab":
+		case "tab":
 			if m.active < len(m.buttons)-1 {
 				m.active++
 			}
+		case "left", "right":
+			if m.require != "" {
+				// Arrows move the cursor in the confirmation field.
+				var cmd tea.Cmd
+				m.input, cmd = m.input.Update(msg)
+				return m, cmd
+			}
+			if msg.String() == "left" && m.active > 0 {
+				m.active--
+			} else if msg.String() == "right" && m.active < len(m.buttons)-1 {
+				m.active++
+			}
 		case "enter":
 			if m.active < len(m.buttons) && m.buttons[m.active].Action != nil {
+				if m.buttons[m.active].Guarded && m.input.Value() != m.require {
+					m.mismatch = true
+					return m, nil
+				}
 				m.visible = false
 				return m, m.buttons[m.active].Action
 			}
 		case "esc":
 			m.visible = false
+		default:
+			if m.require != "" {
+				var cmd tea.Cmd
+				m.input, cmd = m.input.Update(msg)
+				m.mismatch = false
+				return m, cmd
+			}
 		}
 	}
 
@@ -102,13 +145,16 @@ func (m Model) View() string {
 	buttonRow = lipgloss.NewStyle().Width(m.maxWidth - 4).Align(lipgloss.Center).Render(buttonRow)
 
 	// Compose
-	content := lipgloss.JoinVertical(lipgloss.Left,
-		title,
-		"",
-		body,
-		"",
-		buttonRow,
-	)
+	parts := []string{title, "", body, ""}
+	if m.require != "" {
+		hint := th.MutedText.Render("Type " + m.require + " to confirm")
+		if m.mismatch {
+			hint = th.ErrorText.Render("Type " + m.require + " exactly to confirm")
+		}
+		parts = append(parts, hint, m.input.View(), "")
+	}
+	parts = append(parts, buttonRow)
+	content := lipgloss.JoinVertical(lipgloss.Left, parts...)
 
 	return th.DialogBorder.Render(content)
 }
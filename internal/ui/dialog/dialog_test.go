@@ -1,6 +1,7 @@
 package dialog
 
 import (
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -308,3 +309,33 @@ func TestInit(t *testing.T) {
 		t.Fatal("expected nil cmd from Init")
 	}
 }
+
+func TestRequireText(t *testing.T) {
+	fired := false
+	m := New("Drop", "DROP TABLE users",
+		Button{Label: "Drop", Guarded: true, Action: func() tea.Msg { fired = true; return nil }},
+		Button{Label: "Cancel", Action: func() tea.Msg { return nil }},
+	)
+	m.RequireText("users")
+	m.Show()
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || !m.Visible() {
+		t.Fatal("enter without the confirmation text should do nothing")
+	}
+	if !strings.Contains(m.View(), "exactly") {
+		t.Error("view should point out the missing confirmation")
+	}
+
+	for _, r := range "users" {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || m.Visible() {
+		t.Fatal("enter with the confirmation text should fire the button")
+	}
+	cmd()
+	if !fired {
+		t.Error("guarded action did not run")
+	}
+}
@@ -0,0 +1,217 @@
+// Package dump is the dialog that dumps a table to a SQL file, opened from
+// the sidebar menu: name the file, pick INSERT statements or PostgreSQL's
+// COPY format for the rows, then follow them being written.
+package dump
+
+import (
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+// StartMsg asks the app to dump the table to Path, its rows as COPY data
+// when Copy is set, else as INSERT statements.
+type StartMsg struct {
+	Path string
+	Copy bool
+}
+
+// CancelMsg asks the app to stop the dump running.
+type CancelMsg struct{}
+
+// Model is the dump dialog.
+type Model struct {
+	table    string
+	canCopy  bool
+	copy     bool
+	input    textinput.Model
+	replace  string // the existing file asked to confirm overwriting, or ""
+	running  bool
+	done     bool
+	progress string
+	message  string
+	failed   bool
+	visible  bool
+	width    int
+}
+
+// New creates a hidden dialog.
+func New() Model {
+	ti := textinput.New()
+	ti.Prompt = "  File: "
+	ti.Width = 60
+	return Model{input: ti}
+}
+
+// Show opens the dialog for table, offering the COPY format when canCopy
+// is set.
+func (m *Model) Show(table string, canCopy bool) {
+	input := m.input
+	input.SetValue(table + ".sql")
+	input.CursorEnd()
+	input.Focus()
+	*m = Model{table: table, canCopy: canCopy, input: input, visible: true, width: m.width}
+}
+
+// Hide closes the dialog.
+func (m *Model) Hide() {
+	m.visible = false
+	m.input.Blur()
+}
+
+// Visible returns whether the dialog is shown.
+func (m Model) Visible() bool { return m.visible }
+
+// Running returns whether the dump is being written.
+func (m Model) Running() bool { return m.running }
+
+// SetSize sets the available width.
+func (m *Model) SetSize(width, _ int) {
+	m.width = width
+}
+
+// SetProgress shows how many rows are written.
+func (m *Model) SetProgress(text string) {
+	if m.running {
+		m.progress = text
+	}
+}
+
+// Done shows how the dump ended.
+func (m *Model) Done(text string, failed bool) {
+	m.running = false
+	m.done = true
+	m.message = text
+	m.failed = failed
+}
+
+// Update handles key presses: tab switches the format, enter starts, once
+// confirmed when the file exists, esc stops a dump or closes.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !m.visible || !ok {
+		return m, nil
+	}
+	if m.replace != "" {
+		path := m.replace
+		m.replace = ""
+		if key.String() == "y" {
+			return m.start(path)
+		}
+		m.input.Focus()
+		return m, nil
+	}
+	switch {
+	case m.running:
+		if key.String() == "esc" {
+			m.progress = "Stopping..."
+			return m, func() tea.Msg { return CancelMsg{} }
+		}
+		return m, nil
+	case m.done:
+		switch key.String() {
+		case "esc", "q", "enter":
+			m.Hide()
+		}
+		return m, nil
+	}
+
+	switch key.String() {
+	case "esc":
+		m.Hide()
+		return m, nil
+	case "tab":
+		m.copy = m.canCopy && !m.copy
+		return m, nil
+	case "enter":
+		path := strings.TrimSpace(m.input.Value())
+		if path == "" {
+			m.message, m.failed = "Type the file to write", true
+			return m, nil
+		}
+		if _, err := os.Stat(config.ExpandHome(path)); err == nil {
+			m.replace = path
+			m.message, m.failed = "", false
+			m.input.Blur()
+			return m, nil
+		}
+		return m.start(path)
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(key)
+	return m, cmd
+}
+
+// start asks the app to dump the table to path.
+func (m Model) start(path string) (Model, tea.Cmd) {
+	m.running = true
+	m.progress, m.message, m.failed = "", "", false
+	m.input.Blur()
+	start := StartMsg{Path: path, Copy: m.copy}
+	return m, func() tea.Msg { return start }
+}
+
+// View renders the dialog.
+func (m Model) View() string {
+	if !m.visible {
+		return ""
+	}
+	th := theme.Current
+	w := 80
+	if m.width > 0 && w > m.width-4 {
+		w = m.width - 4
+	}
+	textW := w - 6
+
+	lines := []string{th.DialogTitle.Render("  Dump Table: " + m.table + "  "), ""}
+	var help string
+	switch {
+	case m.running:
+		progress := m.progress
+		if progress == "" {
+			progress = "Writing the CREATE TABLE..."
+		}
+		lines = append(lines, "  "+progress)
+		help = "esc:stop"
+	case m.done:
+		style := th.MutedText
+		if m.failed {
+			style = th.ErrorText
+		}
+		lines = append(lines, style.Render("  "+runewidth.Truncate(m.message, textW, "…")))
+		help = "esc:close"
+	default:
+		insertMark, copyMark := "(•)", "( )"
+		if m.copy {
+			insertMark, copyMark = copyMark, insertMark
+		}
+		lines = append(lines,
+			th.MutedText.Render("  The CREATE TABLE, then the rows; ~ is the home directory."),
+			"",
+			m.input.View(),
+			"",
+			"  "+insertMark+" INSERT statements, for any SQL client",
+		)
+		if m.canCopy {
+			lines = append(lines, "  "+copyMark+" COPY data, for psql")
+		}
+		if m.replace != "" {
+			lines = append(lines, th.ErrorText.Render("  "+runewidth.Truncate("The file exists. y to overwrite it, any other key to keep it", textW, "…")))
+		}
+		if m.message != "" {
+			lines = append(lines, th.ErrorText.Render("  "+runewidth.Truncate(m.message, textW, "…")))
+		}
+		help = "enter:dump  esc:close"
+		if m.canCopy {
+			help = "enter:dump  tab:format  esc:close"
+		}
+	}
+	lines = append(lines, "", th.MutedText.Render("  "+help))
+	return th.DialogBorder.Width(w).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
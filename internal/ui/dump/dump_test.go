@@ -0,0 +1,100 @@
+package dump
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case "backspace":
+		return tea.KeyMsg{Type: tea.KeyBackspace}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestDump(t *testing.T) {
+	m := New()
+	m.SetSize(100, 30)
+	m.Show("orders", true)
+	if view := m.View(); !strings.Contains(view, "Dump Table: orders") || !strings.Contains(view, "orders.sql") || !strings.Contains(view, "(•) INSERT") {
+		t.Fatalf("view lacks the file and format:\n%s", view)
+	}
+
+	m, _ = m.Update(key("tab"))
+	if view := m.View(); !strings.Contains(view, "(•) COPY") {
+		t.Errorf("tab should pick the COPY format:\n%s", view)
+	}
+	for range len(".sql") {
+		m, _ = m.Update(key("backspace"))
+	}
+	m, _ = m.Update(key(".copy"))
+	m, cmd := m.Update(key("enter"))
+	if cmd == nil || cmd() != (StartMsg{Path: "orders.copy", Copy: true}) || !m.Running() {
+		t.Fatal("enter should dump orders to orders.copy in the COPY format")
+	}
+	m.SetProgress("1500 rows written")
+	if !strings.Contains(m.View(), "1500 rows written") {
+		t.Errorf("view lacks the progress:\n%s", m.View())
+	}
+	if _, cmd := m.Update(key("esc")); cmd == nil || cmd() != (CancelMsg{}) {
+		t.Error("esc while running should stop the dump")
+	}
+
+	m.Done("1500 rows of orders written to orders.copy (48K)", false)
+	if view := m.View(); !strings.Contains(view, "orders.copy (48K)") {
+		t.Errorf("view lacks the outcome:\n%s", view)
+	}
+	m, _ = m.Update(key("esc"))
+	if m.Visible() {
+		t.Error("esc should close once done")
+	}
+}
+
+func TestDump_NoCopy(t *testing.T) {
+	m := New()
+	m.SetSize(100, 30)
+	m.Show("orders", false)
+	m, _ = m.Update(key("tab"))
+	if view := m.View(); strings.Contains(view, "COPY") {
+		t.Errorf("COPY should not be offered:\n%s", view)
+	}
+	_, cmd := m.Update(key("enter"))
+	if cmd == nil || cmd() != (StartMsg{Path: "orders.sql"}) {
+		t.Error("enter should dump orders as INSERT statements")
+	}
+}
+
+func TestDump_ConfirmOverwrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.sql")
+	if err := os.WriteFile(path, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := New()
+	m.SetSize(100, 30)
+	m.Show("orders", false)
+	m.input.SetValue(path)
+	m, cmd := m.Update(key("enter"))
+	if cmd != nil || m.Running() || !strings.Contains(m.View(), "file exists. y to overwrite") {
+		t.Fatalf("enter on an existing file should ask first:\n%s", m.View())
+	}
+	m, cmd = m.Update(key("n"))
+	if cmd != nil || m.Running() || strings.Contains(m.View(), "file exists") {
+		t.Fatal("any other key should keep the file")
+	}
+	m, _ = m.Update(key("enter"))
+	_, cmd = m.Update(key("y"))
+	if cmd == nil || cmd() != (StartMsg{Path: path}) {
+		t.Error("y should dump over the file")
+	}
+}
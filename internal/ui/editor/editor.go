@@ -2,11 +2,15 @@ package editor
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	appmsg "github.com/sadopc/gotermsql/internal/msg"
 	"github.com/sadopc/gotermsql/internal/theme"
 )
 
@@ -18,6 +22,9 @@ import (
 // TODO: Full inline syntax highlighting while editing requires textarea v2 or
 // a custom widget. For now, highlighted rendering is only shown in the
 // blurred/read-only view.
+//
+// With vim emulation on (SetVim), keys outside insert mode are vim commands
+// rather than text.
 type Model struct {
 	textarea    textarea.Model
 	highlighter *Highlighter
@@ -26,6 +33,9 @@ type Model struct {
 	focused     bool
 	modified    bool // track if content changed since last save/execute
 	id          int  // tab identifier
+	vim         *vim // vim emulation, nil when off
+	nowrap      bool // long lines are cut rather than wrapped (:set nowrap)
+	top, left   int  // first line and column shown when drawn by renderLines
 }
 
 // New creates a new editor instance. The id parameter is used to associate
@@ -64,6 +74,24 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	if !m.focused {
 		return m, nil
 	}
+	if k, ok := msg.(tea.KeyMsg); ok && m.vim != nil {
+		return m.repeatable(k)
+	}
+	return m.update(msg)
+}
+
+// update is Update without vim's macros and repeats.
+func (m Model) update(msg tea.Msg) (Model, tea.Cmd) {
+	if k, ok := msg.(tea.KeyMsg); ok && m.vim != nil && (m.vim.mode != appmsg.VimInsert || k.String() == "esc") {
+		var cmd tea.Cmd
+		if m.vim.exOn {
+			m, cmd = m.exKey(k)
+		} else {
+			m, cmd = m.vimKey(k)
+		}
+		m.follow()
+		return m, cmd
+	}
 
 	prevValue := m.textarea.Value()
 	var cmd tea.Cmd
@@ -73,6 +101,7 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	if m.textarea.Value() != prevValue {
 		m.modified = true
 	}
+	m.follow()
 
 	return m, cmd
 }
@@ -101,17 +130,41 @@ func (m Model) View() string {
 		innerH = 1
 	}
 
+	// The vim command line takes the last row.
+	ex := m.focused && m.vim != nil && m.vim.exOn
+	textH := innerH
+	if ex {
+		textH = max(innerH-1, 1)
+	}
+
 	var content string
-	if m.focused {
+	switch {
+	case m.focused && (m.nowrap || m.VimMode() == appmsg.VimVisual):
+		// The textarea can neither show a selection nor leave long lines
+		// unwrapped.
+		content = m.renderLines(th, innerW, textH)
+	case m.focused:
 		// Editing mode: let the textarea handle everything.
 		m.textarea.SetWidth(innerW)
-		m.textarea.SetHeight(innerH)
+		m.textarea.SetHeight(textH)
 		content = m.textarea.View()
-	} else {
+	default:
 		// Read-only mode: render syntax-highlighted content with line
 		// numbers.
 		content = m.renderHighlighted(th, innerW, innerH)
 	}
+	if ex {
+		line := ":" + string(m.vim.ex)
+		if over := runewidth.StringWidth(line) - (innerW - 1); over > 0 {
+			line = runewidth.TruncateLeft(line, over, "")
+		}
+		line += th.EditorCursor.Render(" ")
+		if innerH == 1 {
+			content = line
+		} else {
+			content += "\n" + line
+		}
+	}
 
 	return border.
 		Width(innerW).
@@ -167,6 +220,47 @@ func (m *Model) SetValue(s string) {
 	m.textarea.SetValue(s)
 }
 
+// Cursor returns the zero-based line and column, in characters, of the
+// cursor.
+func (m Model) Cursor() (line, col int) {
+	li := m.textarea.LineInfo()
+	return m.textarea.Line(), li.StartColumn + li.ColumnOffset
+}
+
+// CursorOffset returns the byte offset of the cursor in Value.
+func (m Model) CursorOffset() int {
+	line, col := m.Cursor()
+	lines := strings.Split(m.Value(), "\n")
+	offset := 0
+	for i := 0; i < line && i < len(lines); i++ {
+		offset += len(lines[i]) + 1
+	}
+	if line < len(lines) {
+		runes := []rune(lines[line])
+		offset += len(string(runes[:min(col, len(runes))]))
+	}
+	return offset
+}
+
+// SetCursor moves the cursor to the zero-based line and column, clamped to
+// the content.
+func (m *Model) SetCursor(line, col int) {
+	// The textarea can only move a (wrapped) row at a time.
+	line = max(0, min(line, m.textarea.LineCount()-1))
+	for m.textarea.Line() != line {
+		beforeLine, beforeCol := m.Cursor()
+		if m.textarea.Line() > line {
+			m.textarea.CursorUp()
+		} else {
+			m.textarea.CursorDown()
+		}
+		if l, c := m.Cursor(); l == beforeLine && c == beforeCol {
+			break
+		}
+	}
+	m.textarea.SetCursor(col)
+}
+
 // SetSize updates the editor dimensions. The values should include space for
 // the border.
 func (m *Model) SetSize(w, h int) {
@@ -238,6 +332,43 @@ func (m *Model) InsertText(text string) {
 	m.modified = true
 }
 
+// InsertAtCursor inserts text at the cursor, as if it were typed. With vim
+// on, u takes it back as one change.
+func (m *Model) InsertAtCursor(text string) {
+	if m.vim != nil {
+		line, col := m.Cursor()
+		b := newBuffer(m.textarea.Value(), line, col)
+		m.vim.record(snapshot{string(b.text), b.cur})
+	}
+	m.textarea.InsertString(text)
+	m.modified = true
+}
+
+// NameAtCursor returns the SQL name the cursor is on or just after, with
+// any qualifiers and quotes, such as public."Users".
+func (m Model) NameAtCursor() string {
+	line, col := m.Cursor()
+	b := newBuffer(m.textarea.Value(), line, col)
+	isName := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_$.\"`", r)
+	}
+	i := b.cur
+	if !isName(b.at(i)) {
+		i--
+	}
+	if !isName(b.at(i)) {
+		return ""
+	}
+	from, to := i, i
+	for from > 0 && isName(b.at(from-1)) {
+		from--
+	}
+	for to < len(b.text) && isName(b.at(to)) {
+		to++
+	}
+	return string(b.text[from:to])
+}
+
 // ReplaceWord replaces the last replaceLen characters with the given text.
 // Used by autocomplete to replace the typed prefix with the full completion.
 func (m *Model) ReplaceWord(text string, replaceLen int) {
@@ -249,3 +380,173 @@ func (m *Model) ReplaceWord(text string, replaceLen int) {
 	m.textarea.SetValue(current[:len(current)-replaceLen] + text)
 	m.modified = true
 }
+
+// SetVim turns vim emulation on or off. It starts in normal mode.
+func (m *Model) SetVim(on bool) {
+	switch {
+	case !on:
+		m.vim = nil
+	case m.vim == nil:
+		m.vim = newVim()
+	}
+}
+
+// VimRecording returns the register a vim macro is being recorded into,
+// or 0.
+func (m Model) VimRecording() rune {
+	if m.vim == nil {
+		return 0
+	}
+	return m.vim.recording
+}
+
+// VimPending returns the keys of a vim command still being typed, such as
+// d in dw.
+func (m Model) VimPending() []string {
+	if m.vim == nil || m.vim.exOn {
+		return nil
+	}
+	return append([]string(nil), m.vim.pending...)
+}
+
+// VimIdle reports whether vim is in normal mode waiting for a command,
+// with none half typed and the command line closed.
+func (m Model) VimIdle() bool {
+	return m.vim != nil && m.vim.idle()
+}
+
+// VimMode returns the vim mode the editor is in. With vim off it is insert
+// mode, as keys always edit.
+func (m Model) VimMode() appmsg.VimState {
+	if m.vim == nil {
+		return appmsg.VimInsert
+	}
+	return m.vim.mode
+}
+
+// vimKey runs a key through vim, outside insert mode or to leave it, and
+// applies what it did to the textarea.
+func (m Model) vimKey(k tea.KeyMsg) (Model, tea.Cmd) {
+	value := m.textarea.Value()
+	line, col := m.Cursor()
+	b := newBuffer(value, line, col)
+	keys := []string{k.String()}
+	if k.Type == tea.KeyRunes && !k.Alt {
+		// Pasted text is read a key at a time.
+		keys = keys[:0]
+		for _, r := range k.Runes {
+			keys = append(keys, string(r))
+		}
+	}
+	for _, key := range keys {
+		m.vim.key(&b, key)
+	}
+
+	if text := string(b.text); text != value {
+		m.textarea.SetValue(text)
+		m.modified = true
+	}
+	m.SetCursor(b.pos(b.cur))
+	// Let the textarea scroll to the cursor.
+	m.textarea, _ = m.textarea.Update(nil)
+	return m, nil
+}
+
+// scroll returns the first line and column renderLines shows, given the
+// lines that fit and the width they have: as close as it can to where the
+// view was while keeping the cursor in it.
+func (m Model) scroll(height, width int) (top, left int) {
+	line, col := m.Cursor()
+	top = max(min(m.top, line), line-height+1)
+	runes := []rune(m.textarea.Value())
+	b := buffer{text: runes}
+	start := b.lineDown(0, line)
+	x := runewidth.StringWidth(string(runes[start:min(start+col, len(runes))]))
+	left = max(min(m.left, x), x-width+1)
+	return max(top, 0), max(left, 0)
+}
+
+// follow keeps the cursor in the view renderLines draws.
+func (m *Model) follow() {
+	height, width := m.linesSize(max(m.width-2, 1), max(m.height-2, 1))
+	if m.vim != nil && m.vim.exOn {
+		height = max(height-1, 1)
+	}
+	m.top, m.left = m.scroll(height, width)
+}
+
+// linesSize returns the lines and text columns renderLines has in an
+// area, the line numbers taking the rest.
+func (m Model) linesSize(width, height int) (int, int) {
+	return height, max(width-m.gutterWidth()-1, 1)
+}
+
+func (m Model) gutterWidth() int {
+	return max(len(strconv.Itoa(m.textarea.LineCount())), 2)
+}
+
+// renderLines draws the content without wrapping, scrolled to keep the
+// cursor in view, with the cursor and any vim visual selection shown.
+func (m Model) renderLines(th *theme.Theme, width, height int) string {
+	line, col := m.Cursor()
+	b := newBuffer(m.textarea.Value(), line, col)
+	from, to := -1, -1
+	if m.VimMode() == appmsg.VimVisual {
+		from, to = m.vim.selection(&b)
+	}
+	_, textWidth := m.linesSize(width, height)
+	top, left := m.scroll(height, textWidth)
+	selected := lipgloss.NewStyle().Reverse(true)
+	styles := []func(string) string{
+		func(s string) string { return s },
+		func(s string) string { return selected.Render(s) },
+		func(s string) string { return th.EditorCursor.Render(s) },
+	}
+
+	var out []string
+	offset := 0
+	for i, l := range strings.Split(string(b.text), "\n") {
+		runes := []rune(l)
+		if i >= top && i < top+height {
+			var sb strings.Builder
+			sb.WriteString(th.EditorLineNumber.Render(fmt.Sprintf("%*d ", m.gutterWidth(), i+1)))
+			// Runs of runes styled alike are rendered together; the line
+			// break shows as a space when the cursor or selection is on
+			// it.
+			var run []rune
+			style, x := 0, 0
+			flush := func() {
+				sb.WriteString(styles[style](string(run)))
+				run = run[:0]
+			}
+			for j, r := range append(runes, ' ') {
+				s := 0
+				switch at := offset + j; {
+				case at == b.cur:
+					s = 2
+				case at >= from && at < to:
+					s = 1
+				}
+				if j == len(runes) && s == 0 {
+					break
+				}
+				w := runewidth.RuneWidth(r)
+				if x += w; x <= left {
+					continue
+				}
+				if x-left > textWidth {
+					break
+				}
+				if s != style {
+					flush()
+					style = s
+				}
+				run = append(run, r)
+			}
+			flush()
+			out = append(out, sb.String())
+		}
+		offset += len(runes) + 1
+	}
+	return strings.Join(out, "\n")
+}
@@ -348,3 +348,23 @@ func TestInsertText_SetsModified(t *testing.T) {
 		t.Error("InsertText should set Modified() = true")
 	}
 }
+
+func TestCursor_SetCursor(t *testing.T) {
+	m := New(0)
+	m.SetSize(20, 10) // narrow enough for the first line to wrap
+	m.SetValue("SELECT id, name, email, created_at\nFROM users\nWHERE id = 1")
+	if line, col := m.Cursor(); line != 2 || col != 12 {
+		t.Fatalf("after SetValue, Cursor() = %d,%d; want 2,12", line, col)
+	}
+
+	m.SetCursor(0, 25)
+	if line, col := m.Cursor(); line != 0 || col != 25 {
+		t.Errorf("Cursor() = %d,%d; want 0,25", line, col)
+	}
+
+	m.SetValue("SELECT 1\nFROM t")
+	m.SetCursor(1, 99)
+	if line, col := m.Cursor(); line != 1 || col != 6 {
+		t.Errorf("Cursor() = %d,%d; want the column clamped to 1,6", line, col)
+	}
+}
@@ -0,0 +1,304 @@
+package editor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	appmsg "github.com/sadopc/gotermsql/internal/msg"
+)
+
+// ExCommandMsg asks the app to run a vim ex command that reaches past the
+// editor: writing or reading a file, closing the tab, or running the query.
+type ExCommandMsg struct {
+	TabID int
+	Name  string // "w", "e", "q", "wq", "run" or "source"
+	Arg   string // the file name, if one was given
+	Force bool   // the command ended in !, as in :q!
+}
+
+// exNames maps the ex commands the app runs, and their long forms, to the
+// names ExCommandMsg uses.
+var exNames = map[string]string{
+	"w": "w", "write": "w", "e": "e", "edit": "e", "q": "q", "quit": "q",
+	"wq": "wq", "x": "wq", "xit": "wq", "run": "run",
+	"so": "source", "source": "source",
+}
+
+// exKey handles a key typed on the : command line.
+func (m Model) exKey(k tea.KeyMsg) (Model, tea.Cmd) {
+	v := m.vim
+	switch k.Type {
+	case tea.KeyEsc:
+		v.exOn = false
+	case tea.KeyEnter:
+		v.exOn = false
+		return m.runEx(string(v.ex))
+	case tea.KeyBackspace:
+		if len(v.ex) == 0 {
+			v.exOn = false
+		} else {
+			v.ex = v.ex[:len(v.ex)-1]
+		}
+	case tea.KeySpace:
+		v.ex = append(v.ex, ' ')
+	case tea.KeyRunes:
+		v.ex = append(v.ex, k.Runes...)
+	}
+	return m, nil
+}
+
+// runEx runs an ex command line: [range]name[!] [arg]. The editor runs
+// :s, :set and :N (go to line N) itself and hands the rest to the app.
+func (m Model) runEx(line string) (Model, tea.Cmd) {
+	line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), ":"))
+	if line == "" {
+		return m, nil
+	}
+	row, col := m.Cursor()
+	b := newBuffer(m.textarea.Value(), row, col)
+	from, to, rest, err := m.vim.exRange(&b, line)
+	if err != nil {
+		return m, exError(err.Error())
+	}
+
+	name := rest
+	for i, r := range rest {
+		if r < 'a' || r > 'z' {
+			name = rest[:i]
+			break
+		}
+	}
+	rest = rest[len(name):]
+	force := strings.HasPrefix(rest, "!")
+	arg := strings.TrimSpace(strings.TrimPrefix(rest, "!"))
+
+	switch {
+	case name == "" && rest == "":
+		// A range alone goes to its last line.
+		b.cur = b.firstNonBlank(b.lineDown(0, to))
+	case name == "s" || name == "substitute":
+		n, lines, err := substitute(&b, from, to, rest)
+		if err != nil {
+			return m, exError(err.Error())
+		}
+		m.vim.record(snapshot{m.textarea.Value(), b.cur})
+		m.textarea.SetValue(string(b.text))
+		m.modified = true
+		b.cur = b.firstNonBlank(b.lineDown(0, lines[len(lines)-1]))
+		m.SetCursor(b.pos(b.cur))
+		if n > 1 {
+			return m, exStatus(fmt.Sprintf("%d substitutions on %d lines", n, len(lines)))
+		}
+		return m, nil
+	case name == "set" || name == "se":
+		return m, m.set(arg)
+	case exNames[name] != "":
+		id := m.id
+		msg := ExCommandMsg{TabID: id, Name: exNames[name], Arg: arg, Force: force}
+		return m, func() tea.Msg { return msg }
+	default:
+		return m, exError("Not an editor command: " + line)
+	}
+	m.SetCursor(b.pos(b.cur))
+	return m, nil
+}
+
+// set changes an option: wrap, nowrap or wrap! (invwrap).
+func (m *Model) set(arg string) tea.Cmd {
+	switch arg {
+	case "wrap":
+		m.nowrap = false
+	case "nowrap":
+		m.nowrap = true
+	case "wrap!", "invwrap":
+		m.nowrap = !m.nowrap
+	case "":
+		return exStatus("wrap is the only option")
+	default:
+		return exError("Unknown option: " + arg)
+	}
+	return nil
+}
+
+// exRange reads the range at the start of line, returning its first and
+// last lines (the cursor's line if there is none) and the rest of line. A
+// range is %, or one or two addresses separated by a comma: a line number,
+// . for the cursor's line, $ for the last one, or '< and '> for the lines
+// of the last visual selection.
+func (v *vim) exRange(b *buffer, line string) (from, to int, rest string, err error) {
+	cur, _ := b.pos(b.cur)
+	last, _ := b.pos(len(b.text))
+	if strings.HasPrefix(line, "%") {
+		return 0, last, line[1:], nil
+	}
+	address := func() (int, bool, error) {
+		switch {
+		case strings.HasPrefix(line, "."):
+			line = line[1:]
+			return cur, true, nil
+		case strings.HasPrefix(line, "$"):
+			line = line[1:]
+			return last, true, nil
+		case strings.HasPrefix(line, "'<"), strings.HasPrefix(line, "'>"):
+			if !v.marked {
+				return 0, false, fmt.Errorf("Mark not set")
+			}
+			mark := v.marks[0]
+			if line[1] == '>' {
+				mark = v.marks[1]
+			}
+			line = line[2:]
+			return mark, true, nil
+		}
+		i := 0
+		for i < len(line) && line[i] >= '0' && line[i] <= '9' {
+			i++
+		}
+		if i == 0 {
+			return cur, false, nil
+		}
+		n, _ := strconv.Atoi(line[:i])
+		line = line[i:]
+		return max(0, min(n-1, last)), true, nil
+	}
+
+	from, ok, err := address()
+	if err != nil || !ok {
+		return cur, cur, line, err
+	}
+	to = from
+	if strings.HasPrefix(line, ",") {
+		line = line[1:]
+		if to, _, err = address(); err != nil {
+			return 0, 0, "", err
+		}
+	}
+	if from > to {
+		from, to = to, from
+	}
+	return from, to, line, nil
+}
+
+// substitute runs /pattern/replacement/flags on lines from to to of b,
+// returning how many replacements it made and on which lines. The pattern
+// is a Go regular expression; in the replacement & is the match and \1 to
+// \9 its groups. With the g flag every match on a line is replaced, not
+// just the first; with i case is ignored.
+func substitute(b *buffer, from, to int, arg string) (int, []int, error) {
+	if arg == "" {
+		return 0, nil, fmt.Errorf("No pattern")
+	}
+	parts := splitUnescaped(arg[1:], rune(arg[0]))
+	for len(parts) < 3 {
+		parts = append(parts, "")
+	}
+	pattern, replacement, flags := parts[0], exReplacement(parts[1]), parts[2]
+	if strings.Contains(flags, "i") {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return 0, nil, fmt.Errorf("Invalid pattern: %v", err)
+	}
+
+	lines := strings.Split(string(b.text), "\n")
+	n := 0
+	var changed []int
+	for i := from; i <= to && i < len(lines); i++ {
+		l := lines[i]
+		matches := re.FindAllStringSubmatchIndex(l, -1)
+		if !strings.Contains(flags, "g") && len(matches) > 1 {
+			matches = matches[:1]
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		var sb strings.Builder
+		end := 0
+		for _, loc := range matches {
+			sb.WriteString(l[end:loc[0]])
+			sb.Write(re.ExpandString(nil, replacement, l, loc))
+			end = loc[1]
+		}
+		sb.WriteString(l[end:])
+		lines[i] = sb.String()
+		n += len(matches)
+		changed = append(changed, i)
+	}
+	if n == 0 {
+		return 0, nil, fmt.Errorf("Pattern not found: %s", parts[0])
+	}
+	b.text = []rune(strings.Join(lines, "\n"))
+	return n, changed, nil
+}
+
+// splitUnescaped splits s at each delim that is not escaped with \; an
+// escaped delim loses its \.
+func splitUnescaped(s string, delim rune) []string {
+	var parts []string
+	var sb strings.Builder
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			if r != delim {
+				sb.WriteRune('\\')
+			}
+			sb.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == delim:
+			parts = append(parts, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	if escaped {
+		sb.WriteRune('\\')
+	}
+	return append(parts, sb.String())
+}
+
+// exReplacement turns a vim replacement (& for the match, \1 for a group,
+// \& for a literal &) into a regexp.Expand template.
+func exReplacement(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '$':
+			sb.WriteString("$$")
+		case c == '&':
+			sb.WriteString("${0}")
+		case c == '\\' && i+1 < len(s):
+			i++
+			switch d := s[i]; {
+			case d >= '0' && d <= '9':
+				sb.WriteString("${" + string(d) + "}")
+			case d == 'n' || d == 'r':
+				sb.WriteByte('\n')
+			case d == 't':
+				sb.WriteByte('\t')
+			case d == '$':
+				sb.WriteString("$$")
+			default:
+				sb.WriteByte(d)
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+func exStatus(text string) tea.Cmd {
+	return func() tea.Msg { return appmsg.StatusMsg{Text: text} }
+}
+
+func exError(text string) tea.Cmd {
+	return func() tea.Msg { return appmsg.StatusMsg{Text: text, IsError: true} }
+}
@@ -0,0 +1,556 @@
+package editor
+
+import (
+	"strings"
+	"unicode"
+)
+
+// buffer is the editor's content as vim works on it: runes, with the
+// cursor as an offset into them. Lines end at '\n'.
+type buffer struct {
+	text []rune
+	cur  int
+}
+
+// newBuffer returns a buffer holding text with the cursor at line, col.
+func newBuffer(text string, line, col int) buffer {
+	b := buffer{text: []rune(text)}
+	i := 0
+	for ; line > 0 && b.lineEnd(i) < len(b.text); line-- {
+		i = b.lineEnd(i) + 1
+	}
+	b.cur = min(i+col, b.lineEnd(i))
+	return b
+}
+
+// pos returns the zero-based line and column of offset i.
+func (b *buffer) pos(i int) (line, col int) {
+	start := b.lineStart(i)
+	for _, r := range b.text[:start] {
+		if r == '\n' {
+			line++
+		}
+	}
+	return line, i - start
+}
+
+// at returns the rune at i, or a line break outside the text.
+func (b *buffer) at(i int) rune {
+	if i < 0 || i >= len(b.text) {
+		return '\n'
+	}
+	return b.text[i]
+}
+
+func (b *buffer) lineStart(i int) int {
+	i = min(i, len(b.text))
+	for i > 0 && b.text[i-1] != '\n' {
+		i--
+	}
+	return i
+}
+
+// lineEnd returns the offset of the line break ending i's line, or the
+// length of the text on the last line.
+func (b *buffer) lineEnd(i int) int {
+	for i < len(b.text) && b.text[i] != '\n' {
+		i++
+	}
+	return i
+}
+
+func (b *buffer) firstNonBlank(i int) int {
+	i = b.lineStart(i)
+	for i < len(b.text) && (b.text[i] == ' ' || b.text[i] == '\t') {
+		i++
+	}
+	return i
+}
+
+// indent returns the white space i's line starts with.
+func (b *buffer) indent(i int) []rune {
+	start := b.lineStart(i)
+	return append([]rune(nil), b.text[start:b.firstNonBlank(start)]...)
+}
+
+// lineDown returns the start of the line n lines below i's, or above it if
+// n is negative, stopping at the first and last lines.
+func (b *buffer) lineDown(i, n int) int {
+	i = b.lineStart(i)
+	for ; n > 0 && b.lineEnd(i) < len(b.text); n-- {
+		i = b.lineEnd(i) + 1
+	}
+	for ; n < 0 && i > 0; n++ {
+		i = b.lineStart(i - 1)
+	}
+	return i
+}
+
+// empty reports whether i's line has nothing on it.
+func (b *buffer) empty(i int) bool {
+	return b.lineStart(i) == b.lineEnd(i)
+}
+
+// clamp keeps the cursor on a character, as it is outside insert mode:
+// not past the last one of its line unless the line is empty.
+func (b *buffer) clamp() {
+	b.cur = max(0, min(b.cur, len(b.text)))
+	if b.cur == b.lineEnd(b.cur) && b.cur > b.lineStart(b.cur) {
+		b.cur--
+	}
+}
+
+func (b *buffer) insert(at int, text []rune) {
+	b.text = append(b.text[:at], append(text, b.text[at:]...)...)
+}
+
+func (b *buffer) delete(from, to int) {
+	b.text = append(b.text[:from], b.text[to:]...)
+}
+
+func (b *buffer) mapCase(from, to int, f func(rune) rune) {
+	for i := from; i < to; i++ {
+		b.text[i] = f(b.text[i])
+	}
+}
+
+// join joins n lines starting with i's, putting a space between them in
+// place of the next line's indentation, the way J does.
+func (b *buffer) join(i, n int) {
+	for ; n > 1; n-- {
+		end := b.lineEnd(i)
+		if end == len(b.text) {
+			return
+		}
+		next := b.firstNonBlank(end + 1)
+		sep := []rune{' '}
+		if end == b.lineStart(end) || isBlank(b.at(end-1)) || next == b.lineEnd(next) || b.text[next] == ')' {
+			sep = nil
+		}
+		b.delete(end, next)
+		b.insert(end, sep)
+		b.cur = end
+	}
+}
+
+func isBlank(r rune) bool {
+	return r == ' ' || r == '\t'
+}
+
+// class sorts runes the way vim's word motions do: 0 for white space, 1
+// for word characters and 2 for other punctuation. For WORDs, which are
+// separated by white space only, every other rune is 1.
+func class(r rune, big bool) int {
+	switch {
+	case unicode.IsSpace(r):
+		return 0
+	case big || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+		return 1
+	}
+	return 2
+}
+
+// nextWord returns the start of the word after i. An empty line counts
+// as a word.
+func (b *buffer) nextWord(i int, big bool) int {
+	n := len(b.text)
+	if i >= n {
+		return n
+	}
+	if c := class(b.text[i], big); c != 0 {
+		for i < n && class(b.text[i], big) == c {
+			i++
+		}
+	}
+	for i < n && class(b.text[i], big) == 0 {
+		if b.text[i] == '\n' && i+1 < n && b.text[i+1] == '\n' {
+			return i + 1
+		}
+		i++
+	}
+	return i
+}
+
+// prevWord returns the start of the word before i.
+func (b *buffer) prevWord(i int, big bool) int {
+	i--
+	for i > 0 && class(b.text[i], big) == 0 {
+		if b.text[i] == '\n' && b.text[i-1] == '\n' {
+			return i
+		}
+		i--
+	}
+	if i <= 0 {
+		return 0
+	}
+	c := class(b.text[i], big)
+	for i > 0 && class(b.text[i-1], big) == c {
+		i--
+	}
+	return i
+}
+
+// wordEnd returns the end of the word after i.
+func (b *buffer) wordEnd(i int, big bool) int {
+	n := len(b.text)
+	i++
+	for i < n && class(b.text[i], big) == 0 {
+		i++
+	}
+	if i >= n {
+		return max(n-1, 0)
+	}
+	c := class(b.text[i], big)
+	for i+1 < n && class(b.text[i+1], big) == c {
+		i++
+	}
+	return i
+}
+
+// find returns where f, F, t or T with ch moves from i, searching the
+// line n times. Repeating t or T with ; or , looks past the character
+// next to i, which it would otherwise stop at again.
+func (b *buffer) find(i int, key string, ch rune, n int, again bool) (int, bool) {
+	start, end := b.lineStart(i), b.lineEnd(i)
+	forward := key == "f" || key == "t"
+	step := 1
+	if !forward {
+		step = -1
+	}
+	j := i
+	if again && (key == "t" || key == "T") {
+		j += step
+	}
+	for ; n > 0; n-- {
+		for {
+			j += step
+			if j < start || j >= end {
+				return i, false
+			}
+			if b.text[j] == ch {
+				break
+			}
+		}
+	}
+	switch key {
+	case "t":
+		j--
+	case "T":
+		j++
+	}
+	return j, true
+}
+
+// brackets are the pairs % and the bracket text objects match.
+const brackets = "()[]{}"
+
+// match returns the bracket matching the first one at or after i on its
+// line.
+func (b *buffer) match(i int) (int, bool) {
+	end := b.lineEnd(i)
+	for i < end && !strings.ContainsRune(brackets, b.text[i]) {
+		i++
+	}
+	if i == end {
+		return 0, false
+	}
+	k := strings.IndexRune(brackets, b.text[i])
+	other := rune(brackets[k^1])
+	step := 1
+	if k%2 == 1 {
+		step = -1
+	}
+	depth := 0
+	for j := i; j >= 0 && j < len(b.text); j += step {
+		switch b.text[j] {
+		case b.text[i]:
+			depth++
+		case other:
+			if depth--; depth == 0 {
+				return j, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// paragraph returns where } moves from i n times, the next empty line
+// after some text, or where { does if backward.
+func (b *buffer) paragraph(i, n int, backward bool) int {
+	line := b.lineStart(i)
+	for ; n > 0; n-- {
+		if backward {
+			for line > 0 && b.empty(line) {
+				line = b.lineStart(line - 1)
+			}
+			for line > 0 {
+				if line = b.lineStart(line - 1); b.empty(line) {
+					break
+				}
+			}
+			continue
+		}
+		for b.lineEnd(line) < len(b.text) && b.empty(line) {
+			line = b.lineEnd(line) + 1
+		}
+		for {
+			if b.lineEnd(line) == len(b.text) {
+				return len(b.text)
+			}
+			if line = b.lineEnd(line) + 1; b.empty(line) {
+				break
+			}
+		}
+	}
+	return line
+}
+
+// motionKind is how an operator takes the text a motion moves over.
+type motionKind int
+
+const (
+	exclusive motionKind = iota // up to the target
+	inclusive                   // up to and including the target
+	linewise                    // the whole lines from the cursor to the target
+)
+
+// motion returns where c's motion moves the cursor, and false if it
+// cannot move, such as f to a character the line does not have.
+func (v *vim) motion(b *buffer, c command) (int, motionKind, bool) {
+	n := c.n()
+	to := b.cur
+	switch c.key {
+	case "h", "left", "backspace":
+		return max(b.lineStart(to), to-n), exclusive, true
+	case "l", "right", " ":
+		return min(b.lineEnd(to), to+n), exclusive, true
+	case "j", "down", "k", "up":
+		if c.key == "k" || c.key == "up" {
+			n = -n
+		}
+		line := b.lineDown(to, n)
+		if line == b.lineStart(to) {
+			return to, linewise, false
+		}
+		if v.want < 0 {
+			return b.lineEnd(line), linewise, true
+		}
+		return min(line+v.want, b.lineEnd(line)), linewise, true
+	case "w", "W":
+		for ; n > 0; n-- {
+			to = b.nextWord(to, c.key == "W")
+		}
+		return to, exclusive, true
+	case "b", "B":
+		for ; n > 0; n-- {
+			to = b.prevWord(to, c.key == "B")
+		}
+		return to, exclusive, true
+	case "e", "E":
+		for ; n > 0; n-- {
+			to = b.wordEnd(to, c.key == "E")
+		}
+		return to, inclusive, true
+	case "0", "home":
+		return b.lineStart(to), exclusive, true
+	case "^":
+		return b.firstNonBlank(to), exclusive, true
+	case "$", "end":
+		return b.lineEnd(b.lineDown(to, n-1)), exclusive, true
+	case "gg", "G":
+		line := 0
+		if c.key == "G" {
+			line = len(b.text)
+		}
+		if c.count > 0 {
+			line = b.lineDown(0, c.count-1)
+		}
+		return b.firstNonBlank(line), linewise, true
+	case "f", "F", "t", "T":
+		v.lastFind = c
+		to, ok := b.find(to, c.key, c.char, n, false)
+		return to, findKind(c.key), ok
+	case ";", ",":
+		key := v.lastFind.key
+		if key == "" {
+			return to, exclusive, false
+		}
+		if c.key == "," {
+			key = map[string]string{"f": "F", "F": "f", "t": "T", "T": "t"}[key]
+		}
+		to, ok := b.find(to, key, v.lastFind.char, n, true)
+		return to, findKind(key), ok
+	case "%":
+		to, ok := b.match(to)
+		return to, inclusive, ok
+	case "{", "}":
+		return b.paragraph(to, n, c.key == "{"), exclusive, true
+	}
+	return to, exclusive, false
+}
+
+func findKind(key string) motionKind {
+	if key == "f" || key == "t" {
+		return inclusive
+	}
+	return exclusive
+}
+
+// textObject returns the text key (such as iw, a" or i() selects around
+// the cursor: from up to to, or the lines they are on if linewise.
+func (v *vim) textObject(b *buffer, key string) (from, to int, linewise, ok bool) {
+	inner := key[0] == 'i'
+	switch obj := key[1:]; obj {
+	case "w", "W":
+		from, to = b.word(b.cur, inner, obj == "W")
+		return from, to, false, true
+	case `"`, "'", "`":
+		from, to, ok = b.quoted(b.cur, rune(obj[0]), inner)
+		return from, to, false, ok
+	case "(", ")", "b":
+		from, to, ok = b.bracketed(b.cur, '(', inner)
+	case "[", "]":
+		from, to, ok = b.bracketed(b.cur, '[', inner)
+	case "{", "}", "B":
+		from, to, ok = b.bracketed(b.cur, '{', inner)
+	case "p":
+		from, to = b.paragraphObject(b.cur, inner)
+		return from, to, true, true
+	}
+	return from, to, false, ok
+}
+
+// word returns the word, or run of white space, at i. Around it (aw) the
+// white space after the word is taken too, or else that before it.
+func (b *buffer) word(i int, inner, big bool) (int, int) {
+	start, end := b.lineStart(i), b.lineEnd(i)
+	if i >= end {
+		return i, i
+	}
+	c := class(b.text[i], big)
+	from, to := i, i+1
+	for from > start && class(b.text[from-1], big) == c {
+		from--
+	}
+	for to < end && class(b.text[to], big) == c {
+		to++
+	}
+	if inner {
+		return from, to
+	}
+	if c == 0 {
+		// On white space, aw is the white space and the word after it.
+		if to < end {
+			c = class(b.text[to], big)
+			for to < end && class(b.text[to], big) == c {
+				to++
+			}
+		}
+		return from, to
+	}
+	after := to
+	for after < end && isBlank(b.text[after]) {
+		after++
+	}
+	if after > to {
+		return from, after
+	}
+	for from > start && isBlank(b.text[from-1]) {
+		from--
+	}
+	return from, to
+}
+
+// quoted returns the string quoted with q around i on its line, or the
+// first one after i. A doubled quote inside is an escaped one, as in SQL.
+// Around it (a") the white space after the string is taken too, or else
+// that before it.
+func (b *buffer) quoted(i int, q rune, inner bool) (int, int, bool) {
+	start, end := b.lineStart(i), b.lineEnd(i)
+	var quotes []int
+	in := false
+	for j := start; j < end; j++ {
+		if b.text[j] != q {
+			continue
+		}
+		if in && j+1 < end && b.text[j+1] == q {
+			j++
+			continue
+		}
+		quotes = append(quotes, j)
+		in = !in
+	}
+	for k := 0; k+1 < len(quotes); k += 2 {
+		open, close := quotes[k], quotes[k+1]
+		if i > close {
+			continue
+		}
+		if inner {
+			return open + 1, close, true
+		}
+		from, to := open, close+1
+		for to < end && isBlank(b.text[to]) {
+			to++
+		}
+		if to == close+1 {
+			for from > start && isBlank(b.text[from-1]) {
+				from--
+			}
+		}
+		return from, to, true
+	}
+	return 0, 0, false
+}
+
+// bracketed returns what is between the innermost open bracket around i
+// and its match, or including them if not inner.
+func (b *buffer) bracketed(i int, open rune, inner bool) (int, int, bool) {
+	close := rune(brackets[strings.IndexRune(brackets, open)+1])
+	start := -1
+	for j, depth := min(i, len(b.text)-1), 0; j >= 0 && start < 0; j-- {
+		switch b.text[j] {
+		case close:
+			if j != i {
+				depth++
+			}
+		case open:
+			if depth == 0 {
+				start = j
+			}
+			depth--
+		}
+	}
+	if start < 0 {
+		return 0, 0, false
+	}
+	end, ok := b.match(start)
+	if !ok {
+		return 0, 0, false
+	}
+	if inner {
+		return start + 1, end, true
+	}
+	return start, end + 1, true
+}
+
+// paragraphObject returns the lines of the paragraph, or run of empty
+// lines, at i. Around it (ap) the empty lines after it are taken too.
+func (b *buffer) paragraphObject(i int, inner bool) (int, int) {
+	line := b.lineStart(i)
+	empty := b.empty(line)
+	from := line
+	for from > 0 && b.empty(from-1) == empty {
+		from = b.lineStart(from - 1)
+	}
+	to := line
+	for b.lineEnd(to) < len(b.text) && b.empty(b.lineEnd(to)+1) == empty {
+		to = b.lineEnd(to) + 1
+	}
+	if !inner && b.lineEnd(to) < len(b.text) {
+		to = b.lineEnd(to) + 1
+		for b.lineEnd(to) < len(b.text) && b.empty(b.lineEnd(to)+1) == !empty {
+			to = b.lineEnd(to) + 1
+		}
+	}
+	return from, b.lineEnd(to)
+}
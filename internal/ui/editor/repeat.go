@@ -0,0 +1,144 @@
+package editor
+
+import (
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	appmsg "github.com/sadopc/gotermsql/internal/msg"
+)
+
+// maxReplayDepth stops a macro that runs itself from running forever.
+const maxReplayDepth = 100
+
+// keyCodes is where keys with no character, such as the arrows, start in
+// the private use area when a macro is kept as register text.
+const keyCodes = 0xE000
+
+// repeatable handles a key with vim on. Around handling it, it adds the
+// key to the macro being recorded and to the change being typed, which
+// becomes the change . repeats once it is done if it edited anything.
+// Then it runs the keys . or @ asked for.
+func (m Model) repeatable(k tea.KeyMsg) (Model, tea.Cmd) {
+	v := m.vim
+	recording := v.recording
+	tracking := !v.dotting
+	if tracking && v.idle() {
+		v.change, v.before, v.edited = nil, m.textarea.Value(), false
+	}
+	if tracking {
+		v.change = append(v.change, k)
+	}
+
+	m, cmd := m.update(k)
+	cmds := []tea.Cmd{cmd}
+
+	if recording != 0 && v.recording == recording && v.depth == 0 {
+		v.macro = append(v.macro, k)
+	}
+	if tracking && v.idle() {
+		if v.edited && !v.dotting {
+			v.dot = v.change
+		}
+		v.change = nil
+	}
+
+	if keys := v.replay; len(keys) > 0 {
+		v.replay = nil
+		if v.depth < maxReplayDepth {
+			v.depth++
+			for _, rk := range keys {
+				m, cmd = m.repeatable(rk)
+				cmds = append(cmds, cmd)
+			}
+			v.depth--
+		}
+		v.dotting = !tracking
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// idle reports whether vim is waiting for a new command in normal mode.
+func (v *vim) idle() bool {
+	return v.mode == appmsg.VimNormal && len(v.pending) == 0 && !v.exOn
+}
+
+// startRecording starts recording keys into reg; q stops it. An
+// upper-case register appends to the macro in its lower-case one.
+func (v *vim) startRecording(reg rune) {
+	if !macroRegister(reg) {
+		return
+	}
+	v.recording, v.macro = reg, nil
+}
+
+// stopRecording keeps the keys recorded as the text of their register.
+func (v *vim) stopRecording() {
+	reg, text := v.recording, keyText(v.macro)
+	if unicode.IsUpper(reg) {
+		reg = unicode.ToLower(reg)
+		text = v.registers[reg].text + text
+	}
+	v.registers[reg] = register{text: text}
+	v.recording, v.macro = 0, nil
+}
+
+// runMacro asks for the keys in reg to run n times; @@ runs the register
+// run last.
+func (v *vim) runMacro(reg rune, n int) {
+	if reg == '@' {
+		reg = v.lastMacro
+	}
+	r, ok := v.load(reg)
+	if !ok {
+		return
+	}
+	v.lastMacro = reg
+	keys := textKeys(r.text)
+	for ; n > 0; n-- {
+		v.replay = append(v.replay, keys...)
+	}
+}
+
+// macroRegister reports whether a macro can be recorded into reg.
+func macroRegister(reg rune) bool {
+	return reg >= 'a' && reg <= 'z' || reg >= 'A' && reg <= 'Z' || reg >= '0' && reg <= '9' || reg == '"'
+}
+
+// keyText writes keys as register text, the way vim keeps a macro: a
+// character for each key, with control characters for esc, enter, tab,
+// backspace and the ctrl keys.
+func keyText(keys []tea.KeyMsg) string {
+	var sb strings.Builder
+	for _, k := range keys {
+		switch {
+		case k.Type == tea.KeyRunes:
+			sb.WriteString(string(k.Runes))
+		case k.Type == tea.KeySpace:
+			sb.WriteRune(' ')
+		case k.Type >= 0:
+			sb.WriteRune(rune(k.Type))
+		default:
+			sb.WriteRune(keyCodes - rune(k.Type))
+		}
+	}
+	return sb.String()
+}
+
+// textKeys reads register text as the keys of a macro.
+func textKeys(text string) []tea.KeyMsg {
+	var keys []tea.KeyMsg
+	for _, r := range text {
+		switch {
+		case r == ' ':
+			keys = append(keys, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+		case r < ' ' || r == 0x7f:
+			keys = append(keys, tea.KeyMsg{Type: tea.KeyType(r)})
+		case r > keyCodes && r < keyCodes+0x100:
+			keys = append(keys, tea.KeyMsg{Type: tea.KeyType(keyCodes - r)})
+		default:
+			keys = append(keys, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+	return keys
+}
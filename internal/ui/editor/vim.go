@@ -0,0 +1,665 @@
+package editor
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	appmsg "github.com/sadopc/gotermsql/internal/msg"
+)
+
+// The system clipboard behind the + and * registers; swapped out in tests.
+var (
+	writeClipboard = clipboard.WriteAll
+	readClipboard  = clipboard.ReadAll
+)
+
+// undoLevels is how many changes u can take back.
+const undoLevels = 100
+
+// vim emulates vim's modal editing. In insert mode keys go to the textarea
+// as usual; in normal and visual mode they are read as vim commands and
+// applied to a buffer holding the editor's content.
+type vim struct {
+	mode       appmsg.VimState
+	lineVisual bool     // visual mode was entered with V
+	anchor     int      // the end of the visual selection the cursor is not at
+	pending    []string // keys of a command still being typed
+	want       int      // column j and k move to; -1 for the end of the line
+	lastFind   command  // the last f, F, t or T, for ; and ,
+	registers  map[rune]register
+	undo, redo []snapshot
+	ex         []rune // the : command line being typed
+	exOn       bool   // the : command line is open
+	marks      [2]int // first and last lines of the last visual selection, for '< and '>
+	marked     bool   // marks is set
+
+	recording rune         // the register a macro is being recorded into, or 0
+	macro     []tea.KeyMsg // the keys recorded so far
+	lastMacro rune         // the register @ last ran, for @@
+	edited    bool         // a command changed the content or started an insert
+	change    []tea.KeyMsg // the keys of the command being typed
+	before    string       // the content before it
+	dot       []tea.KeyMsg // the keys of the last change, for .
+	replay    []tea.KeyMsg // keys . or @ asked to run
+	dotting   bool         // . is running
+	depth     int          // how deeply replays are nested
+}
+
+// register is text yanked or deleted into a register.
+type register struct {
+	text     string
+	linewise bool // whole lines, ending with a line break
+}
+
+// snapshot is the content and cursor before a change, for undo and redo.
+type snapshot struct {
+	text string
+	cur  int
+}
+
+func newVim() *vim {
+	return &vim{registers: map[rune]register{}}
+}
+
+// command is a parsed normal or visual mode command, such as "a2dw.
+type command struct {
+	reg   rune   // register given with "x, or 0
+	count int    // 0 if no count was typed
+	op    string // d, c or y when an operator applies to the motion
+	key   string // the motion, text object or action
+	char  rune   // the character f, F, t, T and r take
+}
+
+// n returns the count, 1 if none was typed.
+func (c command) n() int {
+	if c.count == 0 {
+		return 1
+	}
+	return c.count
+}
+
+var motions = map[string]bool{
+	"h": true, "j": true, "k": true, "l": true, "left": true, "down": true,
+	"up": true, "right": true, "backspace": true, " ": true, "w": true,
+	"W": true, "b": true, "B": true, "e": true, "E": true, "0": true,
+	"^": true, "$": true, "home": true, "end": true, "gg": true, "G": true,
+	"f": true, "F": true, "t": true, "T": true, ";": true, ",": true,
+	"%": true, "{": true, "}": true,
+}
+
+// actions are the normal mode commands that are neither motions nor
+// operators.
+var actions = map[string]bool{
+	"x": true, "X": true, "D": true, "C": true, "s": true, "S": true,
+	"Y": true, "p": true, "P": true, "J": true, "r": true, "~": true,
+	"i": true, "a": true, "I": true, "A": true, "o": true, "O": true,
+	"v": true, "V": true, "u": true, "ctrl+r": true, "esc": true, ":": true,
+	"q": true, "@": true, ".": true,
+}
+
+// visualActions are the commands that act on a visual selection.
+var visualActions = map[string]bool{
+	"esc": true, "v": true, "V": true, "o": true, "d": true, "x": true,
+	"X": true, "D": true, "y": true, "Y": true, "c": true, "s": true,
+	"C": true, "S": true, "R": true, "~": true, "u": true, "U": true,
+	"J": true, "p": true, "P": true, "r": true, ":": true,
+}
+
+// textObjects are what can follow i or a in a text object.
+var textObjects = map[string]bool{
+	"w": true, "W": true, `"`: true, "'": true, "`": true, "(": true,
+	")": true, "b": true, "[": true, "]": true, "{": true, "}": true,
+	"B": true, "p": true,
+}
+
+// parse reads keys as a command. done is false while more keys are needed;
+// a done command with no key means keys cannot be a command.
+func parse(keys []string, visual bool) (c command, done bool) {
+	i := 0
+	next := func() (string, bool) {
+		if i == len(keys) {
+			return "", false
+		}
+		i++
+		return keys[i-1], true
+	}
+	char := func(k string) (rune, bool) {
+		r := []rune(k)
+		return r[0], len(r) == 1
+	}
+	count := func() (int, string, bool) {
+		n := 0
+		for {
+			k, ok := next()
+			if !ok {
+				return 0, "", false
+			}
+			if len(k) != 1 || k[0] < '0' || k[0] > '9' || (k == "0" && n == 0) {
+				return n, k, true
+			}
+			n = n*10 + int(k[0]-'0')
+		}
+	}
+
+	if len(keys) > 0 && keys[0] == `"` {
+		i++
+		k, ok := next()
+		if !ok {
+			return c, false
+		}
+		if c.reg, ok = char(k); !ok {
+			return command{}, true
+		}
+	}
+	n, k, ok := count()
+	if !ok {
+		return c, false
+	}
+	c.count = n
+	if !visual && (k == "d" || k == "c" || k == "y") {
+		c.op = k
+		if n, k, ok = count(); !ok {
+			return c, false
+		}
+		if n > 0 {
+			c.count = c.n() * n
+		}
+		if k == c.op {
+			c.key = k + k
+			return c, true
+		}
+	}
+
+	switch {
+	case (c.op != "" || visual) && (k == "i" || k == "a"):
+		obj, ok := next()
+		if !ok {
+			return c, false
+		}
+		if !textObjects[obj] {
+			return command{}, true
+		}
+		c.key = k + obj
+		return c, true
+	case k == "g":
+		k2, ok := next()
+		if !ok {
+			return c, false
+		}
+		k += k2
+	case k == "f" || k == "F" || k == "t" || k == "T" || k == "r",
+		!visual && (k == "q" || k == "@"):
+		ch, ok := next()
+		if !ok {
+			return c, false
+		}
+		if c.char, ok = char(ch); !ok {
+			return command{}, true
+		}
+	}
+	switch {
+	case motions[k],
+		c.op == "" && !visual && actions[k],
+		c.op == "" && visual && visualActions[k]:
+		c.key = k
+		return c, true
+	}
+	return command{}, true
+}
+
+// isTextObject reports whether key is a text object such as iw or a(.
+func isTextObject(key string) bool {
+	return len(key) >= 2 && (key[0] == 'i' || key[0] == 'a') && textObjects[key[1:]]
+}
+
+// key handles a key pressed in normal or visual mode, or in insert mode,
+// where only esc is vim's.
+func (v *vim) key(b *buffer, k string) {
+	if v.mode == appmsg.VimInsert {
+		if k == "esc" {
+			v.mode = appmsg.VimNormal
+			if b.cur > b.lineStart(b.cur) {
+				b.cur--
+			}
+			// An insert that typed nothing changed nothing.
+			if n := len(v.undo); n > 0 && v.undo[n-1].text == string(b.text) {
+				v.undo = v.undo[:n-1]
+			}
+			v.want = b.cur - b.lineStart(b.cur)
+		}
+		return
+	}
+
+	if k == "q" && v.recording != 0 && len(v.pending) == 0 {
+		v.stopRecording()
+		return
+	}
+	v.pending = append(v.pending, k)
+	c, done := parse(v.pending, v.mode == appmsg.VimVisual)
+	if !done {
+		return
+	}
+	v.pending = nil
+	if c.key == "" {
+		return
+	}
+
+	before := snapshot{string(b.text), b.cur}
+	undoing := v.mode == appmsg.VimNormal && (c.key == "u" || c.key == "ctrl+r")
+	v.run(b, c)
+	if !undoing && (v.mode == appmsg.VimInsert || string(b.text) != before.text) {
+		v.record(before)
+		v.edited = true
+	}
+	if v.mode != appmsg.VimInsert {
+		b.clamp()
+	}
+	switch c.key {
+	case "j", "k", "up", "down":
+	case "$", "end":
+		v.want = -1
+	default:
+		v.want = b.cur - b.lineStart(b.cur)
+	}
+}
+
+// record keeps before, the content ahead of a change, for undo.
+func (v *vim) record(before snapshot) {
+	v.undo = append(v.undo, before)
+	if len(v.undo) > undoLevels {
+		v.undo = v.undo[1:]
+	}
+	v.redo = nil
+}
+
+func (v *vim) run(b *buffer, c command) {
+	switch {
+	case v.mode == appmsg.VimVisual:
+		v.visual(b, c)
+	case c.op != "":
+		if from, to, linewise, ok := v.span(b, c); ok {
+			v.operate(b, c.op, from, to, linewise, c.reg)
+		}
+	case motions[c.key]:
+		if to, _, ok := v.motion(b, c); ok {
+			b.cur = to
+		}
+	default:
+		v.action(b, c)
+	}
+}
+
+// span returns the text an operator applies to: from up to to, or the
+// whole lines from and to are on if lines is true.
+func (v *vim) span(b *buffer, c command) (from, to int, lines, ok bool) {
+	switch {
+	case c.key == c.op+c.op:
+		return b.cur, b.lineDown(b.cur, c.n()-1), true, true
+	case isTextObject(c.key):
+		return v.textObject(b, c.key)
+	}
+
+	target, kind, ok := v.motion(b, c)
+	if !ok {
+		return 0, 0, false, false
+	}
+	if c.op == "c" && (c.key == "w" || c.key == "W") && !isBlank(b.at(b.cur)) {
+		// cw changes to the end of the word, like ce, counting the word
+		// the cursor is on even at its last character.
+		big := c.key == "W"
+		target = b.cur
+		for n := c.n(); n > 0; n-- {
+			if n == c.n() && class(b.at(target+1), big) != class(b.at(target), big) {
+				continue
+			}
+			target = b.wordEnd(target, big)
+		}
+		kind = inclusive
+	}
+
+	from, to = b.cur, target
+	if from > to {
+		from, to = to, from
+	}
+	switch kind {
+	case linewise:
+		return from, to, true, true
+	case inclusive:
+		return from, min(to+1, len(b.text)), false, true
+	}
+	// An exclusive motion that ends at the start of a later line stops at
+	// the end of the line before, so dw on a line's last word keeps the
+	// line break.
+	if target > b.cur && b.lineStart(target) > b.cur && target <= b.firstNonBlank(target) {
+		to = max(b.lineStart(target)-1, from)
+	}
+	return from, to, false, true
+}
+
+// operate applies op to the text from from up to to, or to the whole lines
+// they are on if linewise, keeping it in reg.
+func (v *vim) operate(b *buffer, op string, from, to int, linewise bool, reg rune) {
+	if from > to {
+		from, to = to, from
+	}
+	if linewise {
+		from, to = b.lineStart(from), b.lineEnd(to)
+		v.store(reg, string(b.text[from:to])+"\n", true, op == "y")
+		switch op {
+		case "y":
+			if b.lineStart(b.cur) != from {
+				b.cur = b.firstNonBlank(from)
+			}
+		case "d":
+			// Take a line break along: the one after, or before the last line.
+			if to < len(b.text) {
+				to++
+			} else if from > 0 {
+				from--
+			}
+			b.delete(from, to)
+			b.cur = b.firstNonBlank(min(from, len(b.text)))
+		case "c":
+			b.delete(from, to)
+			b.cur = from
+			v.mode = appmsg.VimInsert
+		}
+		return
+	}
+
+	v.store(reg, string(b.text[from:to]), false, op == "y")
+	switch op {
+	case "y":
+		b.cur = from
+	case "d":
+		b.delete(from, to)
+		b.cur = from
+	case "c":
+		b.delete(from, to)
+		b.cur = from
+		v.mode = appmsg.VimInsert
+	}
+}
+
+// store keeps text in reg and the unnamed register. With no register
+// named, yanks also go to "0, deletes of lines shift through "1 to "9 and
+// smaller deletes go to "-. An upper-case register appends; "_ keeps
+// nothing; "+ and "* are the system clipboard.
+func (v *vim) store(reg rune, text string, linewise, yank bool) {
+	r := register{text, linewise}
+	switch {
+	case reg == '_':
+		return
+	case reg >= 'A' && reg <= 'Z':
+		old := v.registers[unicode.ToLower(reg)]
+		if old.text != "" && old.linewise != linewise && !strings.HasSuffix(old.text, "\n") {
+			old.text += "\n"
+		}
+		r = register{old.text + text, old.linewise || linewise}
+		if r.linewise && !strings.HasSuffix(r.text, "\n") {
+			r.text += "\n"
+		}
+		v.registers[unicode.ToLower(reg)] = r
+	case reg == '+' || reg == '*':
+		_ = writeClipboard(text)
+	case reg != 0 && reg != '"':
+		v.registers[reg] = r
+	case yank:
+		v.registers['0'] = r
+	case linewise || strings.Contains(text, "\n"):
+		for i := '9'; i > '1'; i-- {
+			v.registers[i] = v.registers[i-1]
+		}
+		v.registers['1'] = r
+	default:
+		v.registers['-'] = r
+	}
+	v.registers['"'] = r
+}
+
+// load returns what reg holds, the unnamed register if reg is 0.
+func (v *vim) load(reg rune) (register, bool) {
+	switch reg {
+	case 0:
+		reg = '"'
+	case '+', '*':
+		text, err := readClipboard()
+		if err != nil || text == "" {
+			return register{}, false
+		}
+		return register{text, strings.HasSuffix(text, "\n")}, true
+	}
+	r, ok := v.registers[unicode.ToLower(reg)]
+	return r, ok && r.text != ""
+}
+
+// put pastes c's register count times after the cursor, or before it.
+// Lines go below or above the cursor's line.
+func (v *vim) put(b *buffer, c command, before bool) {
+	r, ok := v.load(c.reg)
+	if !ok {
+		return
+	}
+	text := []rune(strings.Repeat(r.text, c.n()))
+	if !r.linewise {
+		at := b.cur
+		if !before && at < b.lineEnd(at) {
+			at++
+		}
+		b.insert(at, text)
+		b.cur = at + len(text) - 1
+		return
+	}
+	at := b.lineStart(b.cur)
+	start := at
+	if !before {
+		at = b.lineEnd(b.cur)
+		start = at + 1
+		if at == len(b.text) {
+			// Below the last line the break goes before the new lines.
+			text = append([]rune{'\n'}, text[:len(text)-1]...)
+		} else {
+			at++
+		}
+	}
+	b.insert(at, text)
+	b.cur = b.firstNonBlank(start)
+}
+
+func (v *vim) action(b *buffer, c command) {
+	n := c.n()
+	switch c.key {
+	case "x":
+		v.operate(b, "d", b.cur, min(b.cur+n, b.lineEnd(b.cur)), false, c.reg)
+	case "X":
+		v.operate(b, "d", max(b.lineStart(b.cur), b.cur-n), b.cur, false, c.reg)
+	case "D", "C":
+		end := b.lineEnd(b.lineDown(b.cur, n-1))
+		v.operate(b, strings.ToLower(c.key), b.cur, end, false, c.reg)
+	case "s":
+		v.operate(b, "c", b.cur, min(b.cur+n, b.lineEnd(b.cur)), false, c.reg)
+	case "S":
+		v.operate(b, "c", b.cur, b.lineDown(b.cur, n-1), true, c.reg)
+	case "Y":
+		v.operate(b, "y", b.cur, b.lineDown(b.cur, n-1), true, c.reg)
+	case "p", "P":
+		v.put(b, c, c.key == "P")
+	case "J":
+		b.join(b.cur, max(n, 2))
+	case "r":
+		if end := b.cur + n; end <= b.lineEnd(b.cur) {
+			for i := b.cur; i < end; i++ {
+				b.text[i] = c.char
+			}
+			b.cur = end - 1
+		}
+	case "~":
+		end := min(b.cur+n, b.lineEnd(b.cur))
+		b.mapCase(b.cur, end, toggleCase)
+		b.cur = end
+	case "i":
+		v.mode = appmsg.VimInsert
+	case "a":
+		if b.cur < b.lineEnd(b.cur) {
+			b.cur++
+		}
+		v.mode = appmsg.VimInsert
+	case "I":
+		b.cur = b.firstNonBlank(b.cur)
+		v.mode = appmsg.VimInsert
+	case "A":
+		b.cur = b.lineEnd(b.cur)
+		v.mode = appmsg.VimInsert
+	case "o":
+		// New lines keep the indentation of the cursor's line.
+		indent := b.indent(b.cur)
+		at := b.lineEnd(b.cur)
+		b.insert(at, append([]rune{'\n'}, indent...))
+		b.cur = at + 1 + len(indent)
+		v.mode = appmsg.VimInsert
+	case "O":
+		indent := b.indent(b.cur)
+		at := b.lineStart(b.cur)
+		b.insert(at, append(indent, '\n'))
+		b.cur = at + len(indent)
+		v.mode = appmsg.VimInsert
+	case ":":
+		v.exOn, v.ex = true, nil
+	case "v", "V":
+		v.mode = appmsg.VimVisual
+		v.lineVisual = c.key == "V"
+		v.anchor = b.cur
+	case "u":
+		v.restore(b, &v.undo, &v.redo, n)
+	case "ctrl+r":
+		v.restore(b, &v.redo, &v.undo, n)
+	case "q":
+		v.startRecording(c.char)
+	case "@":
+		v.runMacro(c.char, n)
+	case ".":
+		for ; n > 0; n-- {
+			v.replay = append(v.replay, v.dot...)
+		}
+		v.dotting = len(v.replay) > 0
+	}
+}
+
+// restore takes n snapshots off from, keeping the current content on to.
+func (v *vim) restore(b *buffer, from, to *[]snapshot, n int) {
+	for ; n > 0 && len(*from) > 0; n-- {
+		s := (*from)[len(*from)-1]
+		*from = (*from)[:len(*from)-1]
+		*to = append(*to, snapshot{string(b.text), b.cur})
+		b.text, b.cur = []rune(s.text), s.cur
+	}
+}
+
+// selection returns the visual selection, from up to to, with whole lines
+// taking their line break.
+func (v *vim) selection(b *buffer) (from, to int) {
+	from, to = min(v.anchor, b.cur), max(v.anchor, b.cur)
+	if v.lineVisual {
+		return b.lineStart(from), min(b.lineEnd(to)+1, len(b.text))
+	}
+	return from, min(to+1, len(b.text))
+}
+
+// visual runs c in visual mode: motions and text objects move the
+// selection, everything else acts on it and returns to normal mode.
+func (v *vim) visual(b *buffer, c command) {
+	switch {
+	case isTextObject(c.key):
+		if from, to, linewise, ok := v.textObject(b, c.key); ok && to > from {
+			v.anchor, b.cur = from, max(from, to-1)
+			v.lineVisual = v.lineVisual || linewise
+		}
+		return
+	case motions[c.key]:
+		if to, _, ok := v.motion(b, c); ok {
+			b.cur = to
+		}
+		return
+	case c.key == "o":
+		v.anchor, b.cur = b.cur, v.anchor
+		return
+	case (c.key == "v" || c.key == "V") && (c.key == "V") != v.lineVisual:
+		v.lineVisual = c.key == "V"
+		return
+	}
+
+	from, to := min(v.anchor, b.cur), max(v.anchor, b.cur)
+	linewise := v.lineVisual
+	if !linewise {
+		to = min(to+1, len(b.text))
+	}
+	v.mode = appmsg.VimNormal
+	first, _ := b.pos(from)
+	last, _ := b.pos(max(v.anchor, b.cur))
+	v.marks, v.marked = [2]int{first, last}, true
+	switch c.key {
+	case ":":
+		// The command applies to the selected lines.
+		v.exOn, v.ex = true, []rune("'<,'>")
+	case "d", "x":
+		v.operate(b, "d", from, to, linewise, c.reg)
+	case "X", "D":
+		v.operate(b, "d", from, to, true, c.reg)
+	case "y":
+		v.operate(b, "y", from, to, linewise, c.reg)
+	case "Y":
+		v.operate(b, "y", from, to, true, c.reg)
+	case "c", "s":
+		v.operate(b, "c", from, to, linewise, c.reg)
+	case "C", "S", "R":
+		v.operate(b, "c", from, to, true, c.reg)
+	case "~", "u", "U":
+		f := map[string]func(rune) rune{"~": toggleCase, "u": unicode.ToLower, "U": unicode.ToUpper}[c.key]
+		if linewise {
+			from, to = b.lineStart(from), b.lineEnd(to)
+		}
+		b.mapCase(from, to, f)
+		b.cur = from
+	case "J":
+		lines := strings.Count(string(b.text[from:to]), "\n") + 1
+		b.join(from, max(lines, 2))
+	case "r":
+		if linewise {
+			from, to = b.lineStart(from), b.lineEnd(to)
+		}
+		for i := from; i < to; i++ {
+			if b.text[i] != '\n' {
+				b.text[i] = c.char
+			}
+		}
+		b.cur = from
+	case "p", "P":
+		// The selection is replaced by the register, and then held in
+		// the unnamed register itself.
+		r, ok := v.load(c.reg)
+		if !ok {
+			break
+		}
+		text := r.text
+		if linewise {
+			from, to = b.lineStart(from), b.lineEnd(to)
+			text = strings.TrimSuffix(text, "\n")
+		} else if r.linewise {
+			text = "\n" + text
+		}
+		old := string(b.text[from:to])
+		b.delete(from, to)
+		b.insert(from, []rune(text))
+		b.cur = from
+		v.store(0, old, linewise, false)
+	}
+}
+
+func toggleCase(r rune) rune {
+	if unicode.IsUpper(r) {
+		return unicode.ToLower(r)
+	}
+	return unicode.ToUpper(r)
+}
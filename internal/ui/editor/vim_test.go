@@ -0,0 +1,249 @@
+package editor
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	appmsg "github.com/sadopc/gotermsql/internal/msg"
+)
+
+// vimEditor returns a focused editor in vim normal mode holding text, with
+// the cursor at the start.
+func vimEditor(text string) Model {
+	m := New(0)
+	m.SetSize(80, 20)
+	m.Focus()
+	m.SetVim(true)
+	m.SetValue(text)
+	m.SetCursor(0, 0)
+	return m
+}
+
+// press types keys, one rune each, with <esc>, <c-r> and <cr> for those
+// keys.
+func press(m Model, keys string) Model {
+	m, _ = pressKeys(m, keys, false)
+	return m
+}
+
+// pressMsgs is press for keys that are all vim commands, returning the
+// messages of the commands they gave. (In insert mode the textarea gives
+// commands that wait for the cursor to blink.)
+func pressMsgs(m Model, keys string) (Model, []tea.Msg) {
+	return pressKeys(m, keys, true)
+}
+
+func pressKeys(m Model, keys string, run bool) (Model, []tea.Msg) {
+	var msgs []tea.Msg
+	for keys != "" {
+		var k tea.KeyMsg
+		switch {
+		case strings.HasPrefix(keys, "<esc>"):
+			k, keys = tea.KeyMsg{Type: tea.KeyEsc}, keys[len("<esc>"):]
+		case strings.HasPrefix(keys, "<c-r>"):
+			k, keys = tea.KeyMsg{Type: tea.KeyCtrlR}, keys[len("<c-r>"):]
+		case strings.HasPrefix(keys, "<cr>"):
+			k, keys = tea.KeyMsg{Type: tea.KeyEnter}, keys[len("<cr>"):]
+		default:
+			r := []rune(keys)[0]
+			k, keys = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}, keys[len(string(r)):]
+			if r == ' ' {
+				k.Type = tea.KeySpace
+			}
+		}
+		var cmd tea.Cmd
+		if m, cmd = m.Update(k); cmd != nil && run {
+			msgs = append(msgs, cmd())
+		}
+	}
+	return m, msgs
+}
+
+func TestVim_Commands(t *testing.T) {
+	tests := []struct {
+		text, keys, want string
+		line, col        int
+	}{
+		// Motions with operators.
+		{"SELECT a, b FROM t", "dw", "a, b FROM t", 0, 0},
+		{"SELECT a, b FROM t", "2dw", ", b FROM t", 0, 0},
+		{"SELECT a, b FROM t", "de", " a, b FROM t", 0, 0},
+		{"SELECT a, b FROM t", "wd$", "SELECT ", 0, 6},
+		{"SELECT a, b FROM t", "fFD", "SELECT a, b ", 0, 11},
+		{"SELECT a, b FROM t", "t,x", "SELECT , b FROM t", 0, 7},
+		{"SELECT a, b, c", "f,;x", "SELECT a, b c", 0, 11},
+		{"SELECT a, b FROM t", "$bd0", "FROM t", 0, 0},
+		{"SELECT a, b FROM t", "3x", "ECT a, b FROM t", 0, 0},
+		{"SELECT a, b FROM t", "cwselect<esc>", "select a, b FROM t", 0, 5},
+		{"SELECT a, b FROM t", "wwwcwc<esc>", "SELECT a, c FROM t", 0, 10},
+		{"count((a), b)", "f(d%", "count", 0, 4},
+		{"count((a), b)", "$%x", "count(a), b)", 0, 5},
+		{"foo\n  bar", "dw", "\n  bar", 0, 0},
+
+		// Text objects.
+		{"WHERE name = 'it''s ok'", "fodi'", "WHERE name = ''", 0, 14},
+		{"WHERE name = 'x' AND", "fxda'", "WHERE name = AND", 0, 13},
+		{"count(a, (b))", "fbci(x<esc>", "count(a, (x))", 0, 10},
+		{"count(a, (b))", "fadi(", "count()", 0, 6},
+		{"SELECT abc FROM t", "fbdiw", "SELECT  FROM t", 0, 7},
+		{"SELECT abc FROM t", "fbdaw", "SELECT FROM t", 0, 7},
+		{"a\nb\n\nc", "dap", "c", 0, 0},
+
+		// Lines.
+		{"a\nb\nc", "dd", "b\nc", 0, 0},
+		{"a\nb\nc", "Gdd", "a\nb", 1, 0},
+		{"a\nb\nc", "2ggdj", "a", 0, 0},
+		{"a\nb", "yyp", "a\na\nb", 1, 0},
+		{"a\nb", "yyjp", "a\nb\na", 2, 0},
+		{"a\nb", "jyykP", "b\na\nb", 0, 0},
+		{"a\n  b", "cchi<esc>", "hi\n  b", 0, 1},
+		{"SELECT *\n  FROM t", "J", "SELECT * FROM t", 0, 8},
+		{"  a", "ob<esc>", "  a\n  b", 1, 2},
+		{"a", "Ob<esc>", "b\na", 0, 0},
+
+		// Registers.
+		{"abc def", "yiwP", "abcabc def", 0, 2},
+		{"abc def", `"ayiww"byiw"ap`, "abc dabcef", 0, 7},
+		{"abc def", `"ayiww"Ayiw0"aP`, "abcdefabc def", 0, 5},
+		{"abc def", `"_dwP`, "def", 0, 0},
+		{"a\nb", "dd\"1P", "a\nb", 0, 0},
+
+		// Undo and redo.
+		{"a\nb", "ddu", "a\nb", 0, 0},
+		{"a\nb", "ddu<c-r>", "b", 0, 0},
+		{"abc", "ixy<esc>u", "abc", 0, 0},
+		{"abc", "i<esc>xu", "abc", 0, 0},
+
+		// Visual mode.
+		{"abc def", "veyP", "abcabc def", 0, 2},
+		{"abc def", "wvex", "abc ", 0, 3},
+		{"abc def", "wvbd", "ef", 0, 0},
+		{"a\nb\nc", "jVd", "a\nc", 1, 0},
+		{"abc def", "wviwU", "abc DEF", 0, 4},
+		{"a\nb", "yyjVp", "a\na", 1, 0},
+
+		// Other changes.
+		{"abc", "rX", "Xbc", 0, 0},
+		{"abc", "2~", "ABc", 0, 2},
+		{"abc", "A!<esc>", "abc!", 0, 3},
+		{"abc\nd\nxyz", "$jj", "abc\nd\nxyz", 2, 2},
+
+		// Macros and repeat.
+		{"a\nb\nc", "qaA;<esc>jq2@a", "a;\nb;\nc;", 2, 1},
+		{"a\nb", "qqI-- <esc>jq@q", "-- a\n-- b", 1, 2},
+		{"abcd", "qaxq@a@@", "d", 0, 0},
+		{"abc", `qaxq"ap`, "bxc", 0, 1},
+		{"a b c d", "dw.", "c d", 0, 0},
+		{"a b c d", "dw2.", "d", 0, 0},
+		{"abc def", "ciwx<esc>w.", "x x", 0, 2},
+		{"abc", "x.u", "bc", 0, 0},
+		{"a\nb\nc", "A!<esc>jj.k.", "a!\nb!\nc!", 1, 1},
+	}
+	for _, tt := range tests {
+		m := press(vimEditor(tt.text), tt.keys)
+		if got := m.Value(); got != tt.want {
+			t.Errorf("%q on %q: Value() = %q, want %q", tt.keys, tt.text, got, tt.want)
+		}
+		if line, col := m.Cursor(); line != tt.line || col != tt.col {
+			t.Errorf("%q on %q: cursor = %d,%d, want %d,%d", tt.keys, tt.text, line, col, tt.line, tt.col)
+		}
+	}
+}
+
+func TestVim_Modes(t *testing.T) {
+	m := vimEditor("SELECT 1")
+	if m.VimMode() != appmsg.VimNormal {
+		t.Fatalf("VimMode() = %v, want NORMAL", m.VimMode())
+	}
+	m = press(m, "x")
+	if !m.Modified() {
+		t.Error("a change in normal mode should mark the editor modified")
+	}
+	m = press(m, "v")
+	if m.VimMode() != appmsg.VimVisual {
+		t.Errorf("after v, VimMode() = %v, want VISUAL", m.VimMode())
+	}
+	if !strings.Contains(m.View(), "ELECT 1") {
+		t.Error("the visual view should show the content")
+	}
+	m = press(m, "<esc>a")
+	if m.VimMode() != appmsg.VimInsert {
+		t.Errorf("after a, VimMode() = %v, want INSERT", m.VimMode())
+	}
+	m = press(m, "<esc>")
+	if m.VimMode() != appmsg.VimNormal {
+		t.Errorf("after esc, VimMode() = %v, want NORMAL", m.VimMode())
+	}
+
+	m = press(m, "qa")
+	if m.VimRecording() != 'a' {
+		t.Errorf("after qa, VimRecording() = %q, want 'a'", m.VimRecording())
+	}
+	m = press(m, "q")
+	if m.VimRecording() != 0 {
+		t.Errorf("after q, VimRecording() = %q, want none", m.VimRecording())
+	}
+
+	// With vim off, keys edit as usual.
+	m.SetVim(false)
+	m = press(m, "x")
+	if got := m.Value(); got != "xELECT 1" {
+		t.Errorf("vim off: Value() = %q, want %q", got, "xELECT 1")
+	}
+}
+
+func TestVim_Ex(t *testing.T) {
+	tests := []struct {
+		text, keys, want string
+		line             int
+	}{
+		{"a a\na a", ":%s/a/b/g<cr>", "b b\nb b", 1},
+		{"a a\na a", ":s/a/b/<cr>", "b a\na a", 0},
+		{"a a\na a", ":s/A/b/gi<cr>", "b b\na a", 0},
+		{"x1\nx2\nx3", "jVj:s/x(\\d)/&-\\1/<cr>", "x1\nx2-2\nx3-3", 2},
+		{"a/b", `:s/\//+/<cr>`, "a+b", 0},
+		{"a\nb\nc", ":3<cr>", "a\nb\nc", 2},
+		{"a\nb\nc", ":2,3s/^/-- /<cr>", "a\n-- b\n-- c", 2},
+		{"a a", ":%s/a/b/g<cr>u", "a a", 0},
+		{"a", ":s/a/b<esc>", "a", 0},
+	}
+	for _, tt := range tests {
+		m := press(vimEditor(tt.text), tt.keys)
+		if got := m.Value(); got != tt.want {
+			t.Errorf("%q on %q: Value() = %q, want %q", tt.keys, tt.text, got, tt.want)
+		}
+		if line, _ := m.Cursor(); line != tt.line {
+			t.Errorf("%q on %q: cursor line = %d, want %d", tt.keys, tt.text, line, tt.line)
+		}
+	}
+}
+
+func TestVim_ExMessages(t *testing.T) {
+	_, msgs := pressMsgs(vimEditor("SELECT 1"), ":w ~/q.sql<cr>")
+	if len(msgs) != 1 || msgs[0] != (ExCommandMsg{Name: "w", Arg: "~/q.sql"}) {
+		t.Errorf(":w sent %#v", msgs)
+	}
+	_, msgs = pressMsgs(vimEditor("SELECT 1"), ":q!<cr>")
+	if len(msgs) != 1 || msgs[0] != (ExCommandMsg{Name: "q", Force: true}) {
+		t.Errorf(":q! sent %#v", msgs)
+	}
+	_, msgs = pressMsgs(vimEditor("SELECT 1"), ":s/x/y/<cr>")
+	if len(msgs) != 1 || !msgs[0].(appmsg.StatusMsg).IsError {
+		t.Errorf("a failed :s sent %#v, want an error", msgs)
+	}
+	_, msgs = pressMsgs(vimEditor("SELECT 1"), ":frobnicate<cr>")
+	if len(msgs) != 1 || msgs[0].(appmsg.StatusMsg).Text != "Not an editor command: frobnicate" {
+		t.Errorf("an unknown command sent %#v", msgs)
+	}
+
+	m := press(vimEditor("SELECT 1"), ":set nowra")
+	if !strings.Contains(m.View(), ":set nowra") {
+		t.Error("the command line should show while typing")
+	}
+	m = press(m, "p<cr>")
+	m.SetValue(strings.Repeat("x", 200))
+	if lines := strings.Count(m.View(), "\n"); lines != 19 {
+		t.Errorf("with nowrap the view has %d lines, want 19", lines)
+	}
+}
@@ -0,0 +1,154 @@
+package erdiagram
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// The ends of a line through a cell of the canvas.
+const (
+	up = 1 << iota
+	down
+	left
+	right
+)
+
+// lineRunes draws the lines through a cell by their ends.
+var lineRunes = map[uint8]rune{
+	left: '─', right: '─', left | right: '─',
+	up: '│', down: '│', up | down: '│',
+	down | right: '┌', down | left: '┐', up | right: '└', up | left: '┘',
+	up | down | right: '├', up | down | left: '┤',
+	left | right | down: '┬', left | right | up: '┴',
+	up | down | left | right: '┼',
+}
+
+// wide marks the cell covered by the right half of a wide rune.
+const wide = -1
+
+// canvas is a grid of cells the diagram is drawn on. A cell holds a rune,
+// or the ends of the lines through it.
+type canvas struct {
+	width, height int
+	runes         [][]rune
+	lines         [][]uint8
+}
+
+func newCanvas(width, height int) *canvas {
+	c := &canvas{width: width, height: height}
+	c.runes = make([][]rune, height)
+	c.lines = make([][]uint8, height)
+	for y := range height {
+		c.runes[y] = make([]rune, width)
+		c.lines[y] = make([]uint8, width)
+	}
+	return c
+}
+
+func (c *canvas) inside(x, y int) bool {
+	return x >= 0 && y >= 0 && x < c.width && y < c.height
+}
+
+// set puts r in a cell, over any line through it.
+func (c *canvas) set(x, y int, r rune) {
+	if c.inside(x, y) {
+		c.runes[y][x] = r
+	}
+}
+
+// join adds line ends to a cell.
+func (c *canvas) join(x, y int, ends uint8) {
+	if c.inside(x, y) {
+		c.lines[y][x] |= ends
+	}
+}
+
+// hline draws a line along row y between columns x1 and x2.
+func (c *canvas) hline(y, x1, x2 int) {
+	x1, x2 = min(x1, x2), max(x1, x2)
+	if x1 == x2 {
+		c.join(x1, y, left|right)
+		return
+	}
+	for x := x1; x <= x2; x++ {
+		if x > x1 {
+			c.join(x, y, left)
+		}
+		if x < x2 {
+			c.join(x, y, right)
+		}
+	}
+}
+
+// vline draws a line along column x between rows y1 and y2.
+func (c *canvas) vline(x, y1, y2 int) {
+	y1, y2 = min(y1, y2), max(y1, y2)
+	for y := y1; y <= y2; y++ {
+		if y > y1 {
+			c.join(x, y, up)
+		}
+		if y < y2 {
+			c.join(x, y, down)
+		}
+	}
+}
+
+// text writes s from column x of row y.
+func (c *canvas) text(x, y int, s string) {
+	for _, r := range s {
+		c.set(x, y, r)
+		if runewidth.RuneWidth(r) == 2 {
+			x++
+			c.set(x, y, wide)
+		}
+		x++
+	}
+}
+
+// box draws the box of node n, with a double border when selected.
+func (c *canvas) box(n node, selected bool) {
+	h, v, tl, tr, bl, br, ml, mr := "─", "│", "┌", "┐", "└", "┘", "├", "┤"
+	if selected {
+		h, v, tl, tr, bl, br, ml, mr = "═", "║", "╔", "╗", "╚", "╝", "╟", "╢"
+	}
+	inner := n.w - 2
+	c.text(n.x, n.y, tl+strings.Repeat(h, inner)+tr)
+	y := n.y + 1
+	for i, line := range n.lines {
+		if i == 1 {
+			c.text(n.x, y, ml+strings.Repeat("─", inner)+mr)
+			y++
+		}
+		c.text(n.x, y, v+" "+runewidth.FillRight(line, inner-2)+" "+v)
+		y++
+	}
+	c.text(n.x, y, bl+strings.Repeat(h, inner)+br)
+}
+
+// line returns the cells of row y from column x, width cells wide.
+func (c *canvas) line(y, x, width int) string {
+	if y < 0 || y >= c.height {
+		return ""
+	}
+	var b strings.Builder
+	for i := x; i < min(x+width, c.width); i++ {
+		switch r := c.runes[y][i]; {
+		case r == wide:
+			if i == x {
+				b.WriteByte(' ') // the left half is cut off
+			}
+		case r != 0:
+			if runewidth.RuneWidth(r) == 2 && i == x+width-1 {
+				b.WriteByte(' ') // the right half would be cut off
+				continue
+			}
+			b.WriteRune(r)
+		case c.lines[y][i] != 0:
+			b.WriteRune(lineRunes[c.lines[y][i]])
+		default:
+			b.WriteByte(' ')
+		}
+	}
+	return b.String()
+}
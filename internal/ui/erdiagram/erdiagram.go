@@ -0,0 +1,291 @@
+// Package erdiagram is the entity-relationship diagram opened with Alt+E or
+// from the sidebar menu: the tables of a schema as boxes, each to the right
+// of the tables it references, with a line for each foreign key. The arrow
+// keys move from box to box, and enter narrows the diagram to the tables
+// around the one selected.
+package erdiagram
+
+import (
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+// Model is the ER diagram modal.
+type Model struct {
+	title   string // the schema shown
+	tables  []schema.Table
+	focus   string // the table whose neighbours are shown, or "" for all
+	full    bool   // every column rather than the keys only
+	layout  *layout
+	cursor  int // the node selected
+	scrollX int
+	scrollY int
+	visible bool
+	width   int
+	height  int
+}
+
+// New creates a hidden ER diagram.
+func New() Model {
+	return Model{}
+}
+
+// Show opens the diagram of tables, the schema called title, on the
+// neighbours of table focus, or on every table when focus is "".
+func (m *Model) Show(title string, tables []schema.Table, focus string) {
+	*m = Model{title: title, tables: tables, focus: focus, visible: true, width: m.width, height: m.height}
+	m.relayout(focus)
+}
+
+// Hide closes the diagram.
+func (m *Model) Hide() {
+	m.visible = false
+}
+
+// Visible returns whether the diagram is shown.
+func (m Model) Visible() bool { return m.visible }
+
+// Focus returns the table whose neighbours are shown, or "" when every
+// table is.
+func (m Model) Focus() string { return m.focus }
+
+// Selected returns the table selected, or "".
+func (m Model) Selected() string {
+	if m.layout == nil || m.cursor >= len(m.layout.nodes) {
+		return ""
+	}
+	return m.layout.nodes[m.cursor].table
+}
+
+// SetSize sets the available space.
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.follow()
+}
+
+// neighbours returns the tables shown around focus: itself, the tables it
+// references and the tables that reference it.
+func (m Model) neighbours(focus string) []schema.Table {
+	var refs []string
+	for _, t := range m.tables {
+		if t.Name == focus {
+			for _, fk := range t.FKs {
+				refs = append(refs, fk.RefTable)
+			}
+		}
+	}
+	var shown []schema.Table
+	for _, t := range m.tables {
+		if t.Name == focus || slices.Contains(refs, t.Name) ||
+			slices.ContainsFunc(t.FKs, func(fk schema.ForeignKey) bool { return fk.RefTable == focus }) {
+			shown = append(shown, t)
+		}
+	}
+	return shown
+}
+
+// relayout lays the diagram out again and selects table, if shown.
+func (m *Model) relayout(table string) {
+	tables := m.tables
+	if m.focus != "" {
+		tables = m.neighbours(m.focus)
+	}
+	m.layout = newLayout(tables, m.full)
+	m.cursor = m.layout.tableNode(table)
+	if m.cursor < 0 {
+		m.cursor = m.first()
+	}
+	m.follow()
+}
+
+// first returns the first box of the first layer.
+func (m Model) first() int {
+	for _, layer := range m.layout.layers {
+		for _, n := range layer {
+			if m.layout.nodes[n].table != "" {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+// Update handles key presses: the arrow keys (or hjkl) move from box to
+// box, enter shows the neighbours of the table selected, a every table,
+// c toggles between the key columns and all of them, and esc closes.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !m.visible || !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "esc", "q":
+		m.visible = false
+	case "up", "k":
+		m.moveVertical(-1)
+	case "down", "j":
+		m.moveVertical(1)
+	case "left", "h":
+		m.moveHorizontal(-1)
+	case "right", "l":
+		m.moveHorizontal(1)
+	case "enter":
+		if table := m.Selected(); table != "" {
+			m.focus = table
+			m.relayout(table)
+		}
+	case "a":
+		if m.focus != "" {
+			m.focus = ""
+			m.relayout(m.Selected())
+		}
+	case "c":
+		m.full = !m.full
+		m.relayout(m.Selected())
+	}
+	return m, nil
+}
+
+// moveVertical selects the box above (-1) or below (1) in the same layer.
+func (m *Model) moveVertical(step int) {
+	if len(m.layout.nodes) == 0 {
+		return
+	}
+	layer := m.layout.layers[m.layout.nodes[m.cursor].layer]
+	for i := slices.Index(layer, m.cursor) + step; i >= 0 && i < len(layer); i += step {
+		if m.layout.nodes[layer[i]].table != "" {
+			m.cursor = layer[i]
+			break
+		}
+	}
+	m.follow()
+}
+
+// moveHorizontal selects the box nearest the selected one in the next
+// layer with boxes to the left (-1) or right (1).
+func (m *Model) moveHorizontal(step int) {
+	if len(m.layout.nodes) == 0 {
+		return
+	}
+	cur := m.layout.nodes[m.cursor]
+	middle := cur.y + cur.h/2
+	for l := cur.layer + step; l >= 0 && l < len(m.layout.layers); l += step {
+		best, bestDist := -1, 0
+		for _, n := range m.layout.layers[l] {
+			nd := m.layout.nodes[n]
+			if nd.table == "" {
+				continue
+			}
+			if dist := abs(nd.y + nd.h/2 - middle); best < 0 || dist < bestDist {
+				best, bestDist = n, dist
+			}
+		}
+		if best >= 0 {
+			m.cursor = best
+			break
+		}
+	}
+	m.follow()
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// viewSize returns the size of the part of the diagram shown.
+func (m Model) viewSize() (int, int) {
+	// The border, the padding and the indent; the title, the summary and
+	// the help, with blank lines around the diagram, and a line to spare.
+	return max(m.width-10, 10), max(m.height-10, 3)
+}
+
+// follow scrolls the diagram so the selected box is shown, as much of it
+// as fits.
+func (m *Model) follow() {
+	if m.layout == nil || m.cursor >= len(m.layout.nodes) {
+		return
+	}
+	w, h := m.viewSize()
+	n := m.layout.nodes[m.cursor]
+	if n.x+n.w > m.scrollX+w {
+		m.scrollX = n.x + n.w - w
+	}
+	if n.y+n.h > m.scrollY+h {
+		m.scrollY = n.y + n.h - h
+	}
+	m.scrollX = max(min(m.scrollX, n.x), 0)
+	m.scrollY = max(min(m.scrollY, n.y), 0)
+}
+
+// summary lists the tables the selected one references and those that
+// reference it.
+func (m Model) summary() string {
+	table := m.Selected()
+	if table == "" {
+		return ""
+	}
+	var refs, refBy []string
+	for _, t := range m.tables {
+		for _, fk := range t.FKs {
+			if t.Name == table && !slices.Contains(refs, fk.RefTable) {
+				refs = append(refs, fk.RefTable)
+			}
+			if fk.RefTable == table && !slices.Contains(refBy, t.Name) {
+				refBy = append(refBy, t.Name)
+			}
+		}
+	}
+	s := table
+	if len(refs) > 0 {
+		s += "  → " + strings.Join(refs, ", ")
+	}
+	if len(refBy) > 0 {
+		s += "  ← " + strings.Join(refBy, ", ")
+	}
+	return s
+}
+
+// View renders the diagram.
+func (m Model) View() string {
+	if !m.visible {
+		return ""
+	}
+	th := theme.Current
+	w, h := m.viewSize()
+	title := "  ER Diagram: " + m.title + "  "
+	if m.focus != "" {
+		title = "  ER Diagram: " + m.focus + " and its neighbours  "
+	}
+	lines := []string{th.DialogTitle.Render(title), th.MutedText.Render("  " + runewidth.Truncate(m.summary(), w, "…")), ""}
+
+	switch {
+	case len(m.layout.nodes) == 0:
+		lines = append(lines, th.MutedText.Render("  No tables in "+m.title))
+		for range h - 1 {
+			lines = append(lines, "")
+		}
+	default:
+		c := m.layout.draw(m.cursor)
+		for y := m.scrollY; y < m.scrollY+h; y++ {
+			lines = append(lines, "  "+c.line(y, m.scrollX, w))
+		}
+	}
+
+	lines = append(lines, "")
+	help := "  arrows:move  enter:neighbours  a:all tables  c:all columns  esc:close"
+	if m.full {
+		help = "  arrows:move  enter:neighbours  a:all tables  c:key columns  esc:close"
+	}
+	lines = append(lines, th.MutedText.Render(help))
+	return th.DialogBorder.Width(w + 6).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
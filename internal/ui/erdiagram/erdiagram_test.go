@@ -0,0 +1,119 @@
+package erdiagram
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func shopTables() []schema.Table {
+	id := schema.Column{Name: "id", Type: "integer", IsPK: true}
+	fk := func(col, table string) schema.ForeignKey {
+		return schema.ForeignKey{Columns: []string{col}, RefTable: table, RefColumns: []string{"id"}}
+	}
+	return []schema.Table{
+		{Name: "users", Columns: []schema.Column{id, {Name: "manager_id"}}, FKs: []schema.ForeignKey{fk("manager_id", "users")}},
+		{Name: "products", Columns: []schema.Column{id, {Name: "price"}}},
+		{Name: "orders", Columns: []schema.Column{id, {Name: "user_id"}}, FKs: []schema.ForeignKey{fk("user_id", "users")}},
+		{Name: "order_items", Columns: []schema.Column{{Name: "order_id", IsPK: true}, {Name: "product_id"}}, FKs: []schema.ForeignKey{
+			fk("order_id", "orders"), fk("product_id", "products"),
+		}},
+		{Name: "a", Columns: []schema.Column{{Name: "b_id"}}, FKs: []schema.ForeignKey{fk("b_id", "b")}},
+		{Name: "b", Columns: []schema.Column{{Name: "a_id"}}, FKs: []schema.ForeignKey{fk("a_id", "a")}},
+	}
+}
+
+func TestLayout(t *testing.T) {
+	l := newLayout(shopTables(), false)
+	layers := map[string]int{"users": 0, "products": 0, "orders": 1, "order_items": 2, "a": 1, "b": 0}
+	for table, want := range layers {
+		if got := l.nodes[l.tableNode(table)].layer; got != want {
+			t.Errorf("%s is in layer %d, want %d", table, got, want)
+		}
+	}
+	// products → order_items skips a layer, through a pass-through node.
+	if passes := len(l.nodes) - len(shopTables()); passes != 1 {
+		t.Errorf("%d pass-through nodes, want 1", passes)
+	}
+
+	users := l.nodes[l.tableNode("users")]
+	if want := []string{"users", "# id", "→ manager_id (↺)"}; strings.Join(users.lines, "|") != strings.Join(want, "|") {
+		t.Errorf("users box = %q, want %q", users.lines, want)
+	}
+	if b := l.nodes[l.tableNode("b")]; b.lines[1] != "→ a_id (→ a)" {
+		t.Errorf("the foreign key closing the cycle should be noted, got %q", b.lines)
+	}
+	if full := newLayout(shopTables(), true); len(full.nodes[full.tableNode("products")].lines) != 3 {
+		t.Error("every column should be listed when full")
+	}
+}
+
+func TestNavigate(t *testing.T) {
+	m := New()
+	m.SetSize(120, 40)
+	m.Show("public", shopTables(), "")
+	if got := m.Selected(); got != "b" {
+		t.Fatalf("selected %q, want the first box of the first layer", got)
+	}
+
+	view := m.View()
+	for _, want := range []string{"ER Diagram: public", "╔═══", "║ b ", "◀─", "│ # id "} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+
+	m, _ = m.Update(key("j"))
+	m, _ = m.Update(key("j"))
+	if got := m.Selected(); got != "users" {
+		t.Fatalf("j j selected %q, want users", got)
+	}
+	m, _ = m.Update(key("l"))
+	if got := m.Selected(); got != "orders" {
+		t.Fatalf("l selected %q, want orders", got)
+	}
+
+	m, _ = m.Update(key("enter"))
+	if m.Focus() != "orders" || len(m.layout.nodes) != 3 {
+		t.Errorf("enter should show orders with users and order_items, got %d nodes", len(m.layout.nodes))
+	}
+	if view := m.View(); !strings.Contains(view, "orders and its neighbours") || !strings.Contains(view, "orders  → users  ← order_items") {
+		t.Errorf("view lacks the neighbourhood:\n%s", view)
+	}
+	m, _ = m.Update(key("a"))
+	if m.Focus() != "" || m.Selected() != "orders" {
+		t.Error("a should show every table, keeping the selection")
+	}
+	m, _ = m.Update(key("esc"))
+	if m.Visible() {
+		t.Error("esc should close the diagram")
+	}
+}
+
+func TestCanvas(t *testing.T) {
+	c := newCanvas(5, 3)
+	c.hline(1, 0, 4)
+	c.vline(2, 0, 2)
+	c.text(0, 0, "表")
+	want := []string{"表│  ", "──┼──", "  │  "}
+	for y, w := range want {
+		if got := c.line(y, 0, 5); got != w {
+			t.Errorf("line %d = %q, want %q", y, got, w)
+		}
+	}
+	if got := c.line(0, 1, 3); got != " │ " {
+		t.Errorf("a wide rune cut in half = %q", got)
+	}
+}
@@ -0,0 +1,355 @@
+package erdiagram
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+// maxBoxText is the widest text of a box, in cells.
+const maxBoxText = 30
+
+// sweeps is how many times the layers are reordered to untangle the lines.
+const sweeps = 4
+
+// node is a box of the diagram, or a point a foreign key passes through on
+// its way across a layer of boxes.
+type node struct {
+	table string   // "" for a pass-through point
+	lines []string // the box's title, then its column lines
+	rows  map[string]int
+	layer int
+	pos   float64 // position in its layer while ordering
+	x, y  int
+	w, h  int
+}
+
+// row returns the canvas line column is on in the box, or its title line
+// when the column is not shown.
+func (n *node) row(column string) int {
+	if n.table == "" {
+		return n.y
+	}
+	if i, ok := n.rows[column]; ok {
+		return n.y + 3 + i
+	}
+	return n.y + 1
+}
+
+// segment is the line between a node of one layer and a node of the
+// next, left being on the side of the table referenced.
+type segment struct {
+	left, right             int // nodes
+	leftColumn, rightColumn string
+	arrow                   bool // left is the table referenced
+}
+
+// layout is a diagram laid out on a canvas.
+type layout struct {
+	nodes    []node
+	segments []segment
+	layers   [][]int // nodes by layer, top to bottom
+	width    int
+	height   int
+}
+
+// tableNode returns the index of the node of table, or -1.
+func (l *layout) tableNode(table string) int {
+	return slices.IndexFunc(l.nodes, func(n node) bool { return n.table != "" && n.table == table })
+}
+
+// fkEdge is a foreign key between two tables of a diagram.
+type fkEdge struct {
+	child, parent int // nodes
+	fk            schema.ForeignKey
+}
+
+// newLayout lays out tables: each table to the right of the tables it
+// references, with a line for each foreign key. Foreign keys that close a
+// cycle, and those to the table itself or to a table not shown, are not
+// drawn but noted in their box. full lists every column rather than the keys only.
+func newLayout(tables []schema.Table, full bool) *layout {
+	l := &layout{}
+	index := make(map[string]int, len(tables))
+	for i, t := range tables {
+		index[t.Name] = i
+	}
+
+	// The foreign keys that close a cycle are found depth first, in name
+	// order, so the same schema is always drawn the same way.
+	var edges []fkEdge
+	undrawn := make(map[int][]schema.ForeignKey)
+	state := make([]int, len(tables)) // 0 unseen, 1 on the path, 2 done
+	var visit func(i int)
+	visit = func(i int) {
+		state[i] = 1
+		for _, fk := range tables[i].FKs {
+			p, ok := index[fk.RefTable]
+			switch {
+			case len(fk.Columns) == 0:
+				continue
+			case !ok || p == i || state[p] == 1:
+				undrawn[i] = append(undrawn[i], fk)
+				continue
+			case state[p] == 0:
+				visit(p)
+			}
+			edges = append(edges, fkEdge{child: i, parent: p, fk: fk})
+		}
+		state[i] = 2
+	}
+	byName := make([]int, len(tables))
+	for i := range byName {
+		byName[i] = i
+	}
+	slices.SortFunc(byName, func(a, b int) int { return strings.Compare(tables[a].Name, tables[b].Name) })
+	for _, i := range byName {
+		if state[i] == 0 {
+			visit(i)
+		}
+	}
+
+	for i, t := range tables {
+		l.nodes = append(l.nodes, tableBox(t, undrawn[i], full))
+	}
+	for i, n := range byName {
+		l.nodes[n].pos = float64(i)
+	}
+	// Each table goes one layer right of the furthest table it references.
+	for changed := true; changed; {
+		changed = false
+		for _, e := range edges {
+			if l.nodes[e.child].layer <= l.nodes[e.parent].layer {
+				l.nodes[e.child].layer = l.nodes[e.parent].layer + 1
+				changed = true
+			}
+		}
+	}
+
+	for _, e := range edges {
+		l.addEdge(e)
+	}
+	l.order()
+	l.place()
+	return l
+}
+
+// tableBox returns the box of a table: its name, and its key columns
+// (every column when full) marked # for the primary key and → for a
+// foreign key. Foreign keys not drawn name their table.
+func tableBox(t schema.Table, undrawn []schema.ForeignKey, full bool) node {
+	n := node{table: t.Name, lines: []string{t.Name}, rows: make(map[string]int)}
+	fkCols := make(map[string]bool)
+	for _, fk := range t.FKs {
+		for _, c := range fk.Columns {
+			fkCols[c] = true
+		}
+	}
+	notes := make(map[string]string)
+	for _, fk := range undrawn {
+		note := " (→ " + fk.RefTable + ")"
+		if fk.RefTable == t.Name {
+			note = " (↺)"
+		}
+		notes[fk.Columns[0]] += note
+	}
+	for _, c := range t.Columns {
+		if !full && !c.IsPK && !fkCols[c.Name] {
+			continue
+		}
+		mark := "  "
+		switch {
+		case c.IsPK && fkCols[c.Name]:
+			mark = "#→"
+		case c.IsPK:
+			mark = "# "
+		case fkCols[c.Name]:
+			mark = "→ "
+		}
+		text := mark + c.Name
+		if full {
+			text += " " + c.Type
+		}
+		n.rows[c.Name] = len(n.lines) - 1
+		n.lines = append(n.lines, text+notes[c.Name])
+	}
+	for i, line := range n.lines {
+		n.lines[i] = runewidth.Truncate(line, maxBoxText, "…")
+		n.w = max(n.w, runewidth.StringWidth(n.lines[i]))
+	}
+	n.w += 4
+	n.h = 3
+	if len(n.lines) > 1 {
+		n.h = len(n.lines) + 3
+	}
+	return n
+}
+
+// addEdge adds the segments of a foreign key, through a pass-through node
+// in each layer between the two tables.
+func (l *layout) addEdge(e fkEdge) {
+	childLayer, parentLayer := l.nodes[e.child].layer, l.nodes[e.parent].layer
+	refColumn := ""
+	if len(e.fk.RefColumns) > 0 {
+		refColumn = e.fk.RefColumns[0]
+	}
+	left, leftColumn := e.parent, refColumn
+	for layer := parentLayer + 1; layer < childLayer; layer++ {
+		l.nodes = append(l.nodes, node{layer: layer, pos: l.nodes[e.child].pos, h: 1})
+		pass := len(l.nodes) - 1
+		l.segments = append(l.segments, segment{left: left, right: pass, leftColumn: leftColumn, arrow: left == e.parent})
+		left, leftColumn = pass, ""
+	}
+	l.segments = append(l.segments, segment{
+		left: left, right: e.child, leftColumn: leftColumn, rightColumn: e.fk.Columns[0], arrow: left == e.parent,
+	})
+}
+
+// order groups the nodes into layers and orders each layer by the mean
+// position of the nodes it is linked to, sweeping back and forth, so
+// fewer lines cross.
+func (l *layout) order() {
+	depth := 0
+	for _, n := range l.nodes {
+		depth = max(depth, n.layer+1)
+	}
+	l.layers = make([][]int, depth)
+	for i, n := range l.nodes {
+		l.layers[n.layer] = append(l.layers[n.layer], i)
+	}
+	sortLayer := func(layer []int) {
+		slices.SortStableFunc(layer, func(a, b int) int { return cmp.Compare(l.nodes[a].pos, l.nodes[b].pos) })
+		for i, n := range layer {
+			l.nodes[n].pos = float64(i)
+		}
+	}
+	for _, layer := range l.layers {
+		sortLayer(layer)
+	}
+
+	// mean returns the mean position of the nodes linked to n on one side.
+	mean := func(n int, fromLeft bool) (float64, bool) {
+		sum, count := 0.0, 0
+		for _, s := range l.segments {
+			switch {
+			case fromLeft && s.right == n:
+				sum += l.nodes[s.left].pos
+			case !fromLeft && s.left == n:
+				sum += l.nodes[s.right].pos
+			default:
+				continue
+			}
+			count++
+		}
+		if count == 0 {
+			return 0, false
+		}
+		return sum / float64(count), true
+	}
+	reorder := func(layer []int, fromLeft bool) {
+		for _, n := range layer {
+			if m, ok := mean(n, fromLeft); ok {
+				l.nodes[n].pos = m
+			}
+		}
+		sortLayer(layer)
+	}
+	for range sweeps {
+		for i := 1; i < len(l.layers); i++ {
+			reorder(l.layers[i], true)
+		}
+		for i := len(l.layers) - 2; i >= 0; i-- {
+			reorder(l.layers[i], false)
+		}
+	}
+}
+
+// place gives the nodes their place on the canvas: the layers side by
+// side, each a stack of boxes, with room between them for a vertical
+// track per line that changes rows.
+func (l *layout) place() {
+	widths := make([]int, len(l.layers))
+	for i, layer := range l.layers {
+		y := 0
+		for _, n := range layer {
+			widths[i] = max(widths[i], l.nodes[n].w)
+		}
+		for _, n := range layer {
+			nd := &l.nodes[n]
+			nd.y = y
+			if nd.table == "" {
+				nd.w = widths[i]
+			}
+			y += nd.h + 1
+		}
+		l.height = max(l.height, y-1)
+	}
+	x := 0
+	for i, layer := range l.layers {
+		for _, n := range layer {
+			l.nodes[n].x = x
+		}
+		x += widths[i]
+		if i < len(l.layers)-1 {
+			x += 4 + l.tracks(i)
+		}
+	}
+	l.width = x
+}
+
+// tracks returns how many segments leaving layer change rows, each taking
+// a vertical track of the gap after the layer.
+func (l *layout) tracks(layer int) int {
+	count := 0
+	for _, s := range l.segments {
+		if l.nodes[s.left].layer == layer && l.nodes[s.left].row(s.leftColumn) != l.nodes[s.right].row(s.rightColumn) {
+			count++
+		}
+	}
+	return count
+}
+
+// draw renders the layout with box selected drawn with a double border.
+func (l *layout) draw(selected int) *canvas {
+	c := newCanvas(l.width, l.height)
+	track := make(map[int]int) // next free track by layer
+	for _, s := range l.segments {
+		left, right := &l.nodes[s.left], &l.nodes[s.right]
+		from, to := left.row(s.leftColumn), right.row(s.rightColumn)
+		start, end := left.x+left.w, right.x-1
+		if left.table == "" {
+			start = left.x
+		}
+		if from == to {
+			c.hline(from, start, end)
+		} else {
+			gap := l.layerEnd(left.layer)
+			x := gap + 2 + track[left.layer]
+			track[left.layer]++
+			c.hline(from, start, x)
+			c.vline(x, from, to)
+			c.hline(to, x, end)
+		}
+		if s.arrow {
+			c.set(left.x+left.w, from, '◀')
+		}
+	}
+	for i, n := range l.nodes {
+		if n.table != "" {
+			c.box(n, i == selected)
+		}
+	}
+	return c
+}
+
+// layerEnd returns the first column after the boxes of layer.
+func (l *layout) layerEnd(layer int) int {
+	end := 0
+	for _, n := range l.layers[layer] {
+		end = max(end, l.nodes[n].x+l.nodes[n].w)
+	}
+	return end
+}
@@ -0,0 +1,82 @@
+// Package format renders values for the screen the same way across the UI:
+// text on one line, durations, sizes and counts.
+package format
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// OneLine puts s on a single line. A run of whitespace holding a newline or
+// a tab becomes one space, dropped at either end, so an indented query
+// reads as one line; the other spaces, those of a value, are kept.
+func OneLine(s string) string {
+	if !strings.ContainsAny(s, "\r\n\t") {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		j := i
+		for j < len(s) && unicode.IsSpace(rune(s[j])) {
+			j++
+		}
+		if j == i {
+			sb.WriteByte(s[i])
+			i++
+			continue
+		}
+		switch run := s[i:j]; {
+		case !strings.ContainsAny(run, "\r\n\t"):
+			sb.WriteString(run)
+		case i > 0 && j < len(s):
+			sb.WriteByte(' ')
+		}
+		i = j
+	}
+	return sb.String()
+}
+
+// Duration renders how long something took or has run: 420µs, 850ms,
+// 1.2s, 3m05s, 2h10m.
+func Duration(d time.Duration) string {
+	switch {
+	case d < time.Millisecond:
+		return fmt.Sprintf("%dµs", d.Microseconds())
+	case d < time.Second:
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	case d < time.Minute:
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// Size abbreviates a size in binary units: 512B, 1.5K, 48K, 5G.
+func Size(n int64) string {
+	return abbreviate(n, 1024, []string{"B", "K", "M", "G", "T"})
+}
+
+// Count abbreviates a count of rows: 950, 1.2k, 56k, 3.4M.
+func Count(n int64) string {
+	return abbreviate(n, 1000, []string{"", "k", "M", "B", "T"})
+}
+
+// abbreviate renders n in the largest unit below it, with one decimal under
+// ten units unless it is zero.
+func abbreviate(n, base int64, units []string) string {
+	v, i := float64(n), 0
+	for v >= float64(base) && i < len(units)-1 {
+		v /= float64(base)
+		i++
+	}
+	switch {
+	case i == 0:
+		return fmt.Sprintf("%d%s", n, units[0])
+	case v < 10:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", v), ".0") + units[i]
+	}
+	return fmt.Sprintf("%.0f%s", v, units[i])
+}
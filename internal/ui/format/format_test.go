@@ -0,0 +1,58 @@
+package format
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOneLine(t *testing.T) {
+	for s, want := range map[string]string{
+		"SELECT 1":                 "SELECT 1",
+		"a  b":                     "a  b",
+		"SELECT *\n    FROM t\r\n": "SELECT * FROM t",
+		"\n\tSELECT 1":             "SELECT 1",
+		"one\ttwo  three\nfour":    "one two  three four",
+		"  padded  ":               "  padded  ",
+	} {
+		if got := OneLine(s); got != want {
+			t.Errorf("OneLine(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestDuration(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		420 * time.Microsecond:        "420µs",
+		850 * time.Millisecond:        "850ms",
+		1500 * time.Millisecond:       "1.5s",
+		12 * time.Second:              "12.0s",
+		3*time.Minute + 5*time.Second: "3m05s",
+		2*time.Hour + 10*time.Minute:  "2h10m",
+	} {
+		if got := Duration(d); got != want {
+			t.Errorf("Duration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestSizeAndCount(t *testing.T) {
+	tests := []struct {
+		got, want string
+	}{
+		{Size(512), "512B"},
+		{Size(1536), "1.5K"},
+		{Size(49_152), "48K"},
+		{Size(1 << 20), "1M"},
+		{Size(1_610_612_736), "1.5G"},
+		{Size(5 << 30), "5G"},
+		{Count(950), "950"},
+		{Count(1234), "1.2k"},
+		{Count(56_000), "56k"},
+		{Count(3_400_000), "3.4M"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("got %q, want %q", tt.got, tt.want)
+		}
+	}
+}
@@ -8,8 +8,10 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 	"github.com/sadopc/gotermsql/internal/history"
 	"github.com/sadopc/gotermsql/internal/theme"
+	"github.com/sadopc/gotermsql/internal/ui/format"
 )
 
 // SelectQueryMsg is sent when the user picks a history entry.
@@ -17,6 +19,13 @@ type SelectQueryMsg struct {
 	Query string
 }
 
+// RunQueryMsg is sent to run a history entry again, in the current tab or
+// a new one.
+type RunQueryMsg struct {
+	Query  string
+	NewTab bool
+}
+
 // Model is the history browser modal.
 type Model struct {
 	hist    *history.History
@@ -27,12 +36,23 @@ type Model struct {
 	width   int
 	height  int
 	search  textinput.Model
+	terms   []string // search terms to highlight
+	scope   *history.Scope
+	all     bool // show every connection's queries, not just scope's
+
+	clearing bool   // asking to confirm clearing the scope's history
+	message  string // result of the last clear
+
+	// Statistics view, toggled with ctrl+s.
+	stats    bool
+	summary  *history.Summary
+	statsErr error
 }
 
 // New creates a new history browser.
 func New(hist *history.History) Model {
 	ti := textinput.New()
-	ti.Placeholder = "Search queries..."
+	ti.Placeholder = "Search queries... (error:true db:NAME adapter:NAME)"
 	ti.Prompt = "  > "
 	ti.Width = 50
 	return Model{
@@ -41,9 +61,19 @@ func New(hist *history.History) Model {
 	}
 }
 
+// SetScope sets the current connection's adapter and database, whose
+// queries the browser shows unless switched to all of them. nil shows all.
+func (m *Model) SetScope(scope *history.Scope) {
+	m.scope = scope
+}
+
 // Show makes the history browser visible and loads entries.
 func (m *Model) Show() {
 	m.visible = true
+	m.all = false
+	m.stats = false
+	m.clearing = false
+	m.message = ""
 	m.cursor = 0
 	m.offset = 0
 	m.search.SetValue("")
@@ -74,6 +104,17 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.clearing {
+			m.clearing = false
+			if msg.String() == "y" {
+				m.clearScope()
+			}
+			return m, nil
+		}
+		m.message = ""
+		if m.stats {
+			return m.updateStats(msg), nil
+		}
 		switch msg.String() {
 		case "esc", "ctrl+h":
 			m.visible = false
@@ -110,6 +151,31 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			}
 			m.ensureVisible()
 			return m, nil
+		case "ctrl+s":
+			m.stats = true
+			m.loadStats()
+			return m, nil
+		case "ctrl+d":
+			if m.hist != nil && m.scope != nil {
+				m.clearing = true
+			}
+			return m, nil
+		case "tab":
+			if m.scope != nil {
+				m.all = !m.all
+				m.cursor = 0
+				m.offset = 0
+				m.loadEntries()
+			}
+			return m, nil
+		case "ctrl+r", "ctrl+t":
+			if m.cursor < len(m.entries) {
+				run := RunQueryMsg{Query: m.entries[m.cursor].Query, NewTab: msg.String() == "ctrl+t"}
+				m.visible = false
+				m.search.Blur()
+				return m, func() tea.Msg { return run }
+			}
+			return m, nil
 		case "enter":
 			if m.cursor < len(m.entries) {
 				query := m.entries[m.cursor].Query
@@ -140,16 +206,42 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	return m, cmd
 }
 
+// updateStats handles a key in the statistics view.
+func (m Model) updateStats(msg tea.KeyMsg) Model {
+	switch msg.String() {
+	case "esc", "ctrl+s":
+		m.stats = false
+	case "ctrl+h":
+		m.Hide()
+	case "tab":
+		if m.scope != nil {
+			m.all = !m.all
+			m.cursor = 0
+			m.offset = 0
+			m.loadEntries()
+			m.loadStats()
+		}
+	}
+	return m
+}
+
 // View renders the history browser.
 func (m Model) View() string {
 	if !m.visible {
 		return ""
 	}
 
+	if m.stats {
+		return m.viewStats()
+	}
+
 	th := theme.Current
 	w := m.dialogWidth()
 
 	title := th.DialogTitle.Render("  Query History  ")
+	if label := m.scopeLabel(); label != "" {
+		title += th.MutedText.Render("  " + label)
+	}
 	searchView := "  " + m.search.View()
 
 	visible := m.visibleCount()
@@ -163,20 +255,39 @@ func (m Model) View() string {
 		e := m.entries[i]
 		line := m.formatEntry(e, w-6)
 		if i == m.cursor {
-			lines = append(lines, th.SidebarSelected.Render(line))
+			lines = append(lines, highlight(line, m.terms, th.SidebarSelected, th.SidebarMatch))
 		} else if e.IsError {
-			lines = append(lines, th.ErrorText.Render("  "+line))
+			lines = append(lines, "  "+highlight(line, m.terms, th.ErrorText, th.SidebarMatch))
 		} else {
-			lines = append(lines, "  "+line)
+			lines = append(lines, "  "+highlight(line, m.terms, lipgloss.NewStyle(), th.SidebarMatch))
 		}
 	}
 
 	if len(m.entries) == 0 {
-		lines = append(lines, th.MutedText.Render("  No history entries"))
+		msg := "  No history entries"
+		if m.scoped() {
+			msg += " for this database (tab shows all connections)"
+		}
+		lines = append(lines, th.MutedText.Render(msg))
 	}
 
 	countText := fmt.Sprintf("  %d entries", len(m.entries))
-	help := th.MutedText.Render("  enter:select  esc:close  up/down:navigate")
+	helpText := "  enter:edit  ctrl+r:run  ctrl+t:new tab  ctrl+s:stats  esc:close"
+	if m.scope != nil {
+		if m.all {
+			helpText += "  tab:this database"
+		} else {
+			helpText += "  tab:all connections"
+		}
+		helpText += "  ctrl+d:clear"
+	}
+	help := th.MutedText.Render(helpText)
+	switch {
+	case m.clearing:
+		help = th.ErrorText.Render(fmt.Sprintf("  Delete all history for %s:%s? (y/n)", m.scope.Adapter, m.scope.Database))
+	case m.message != "":
+		help = th.MutedText.Render("  " + m.message)
+	}
 
 	content := lipgloss.JoinVertical(lipgloss.Left,
 		title,
@@ -184,6 +295,7 @@ func (m Model) View() string {
 		"",
 		strings.Join(lines, "\n"),
 		"",
+		m.details(w-4),
 		th.MutedText.Render(countText),
 		help,
 	)
@@ -201,9 +313,9 @@ func (m Model) dialogWidth() int {
 
 // visibleCount returns how many entries fit in the visible area.
 func (m Model) visibleCount() int {
-	// Title + search + blank + blank + count + help = 6 lines of chrome
-	// Plus 2 for border
-	avail := m.height - 8
+	// Title + search + blank + blank + details + count + help = 7 lines
+	// of chrome. Plus 2 for border
+	avail := m.height - 9
 	if avail < 3 {
 		avail = 3
 	}
@@ -226,22 +338,116 @@ func (m *Model) loadEntries() {
 		return
 	}
 
-	var err error
-	searchText := m.search.Value()
-	if searchText != "" {
-		m.entries, err = m.hist.Search("%"+searchText+"%", 200)
-	} else {
-		m.entries, err = m.hist.Recent(200)
+	f := history.ParseFilter(strings.TrimSpace(m.search.Value()))
+	m.terms = f.Terms
+	if f.Database != "" {
+		m.terms = append(m.terms, f.Database)
 	}
+	// A db: or adapter: filter typed in the search replaces the scope.
+	if m.scoped() && f.Database == "" && f.Adapter == "" {
+		f.Scope = m.scope
+	}
+	var err error
+	m.entries, err = m.hist.Find(f, 200)
 	if err != nil {
 		m.entries = nil
 	}
 }
 
+// clearScope deletes the history of the current connection's database.
+func (m *Model) clearScope() {
+	n, err := m.hist.ClearScope(*m.scope)
+	if err != nil {
+		m.message = "Failed to clear history: " + err.Error()
+		return
+	}
+	m.message = fmt.Sprintf("Deleted %d entries", n)
+	m.cursor = 0
+	m.offset = 0
+	m.loadEntries()
+}
+
+// scoped reports whether only the current connection's queries are shown.
+func (m Model) scoped() bool {
+	return m.scope != nil && !m.all
+}
+
+// scopeLabel describes which queries are shown.
+func (m Model) scopeLabel() string {
+	switch {
+	case m.scope == nil:
+		return ""
+	case m.all:
+		return "all connections"
+	case m.scope.Database == "":
+		return m.scope.Adapter
+	}
+	return m.scope.Adapter + ":" + m.scope.Database
+}
+
+// highlight renders line in base with the case-insensitive occurrences of
+// terms in match.
+func highlight(line string, terms []string, base, match lipgloss.Style) string {
+	runes := []rune(line)
+	lower := []rune(strings.ToLower(line))
+	if len(lower) != len(runes) {
+		// Lowercasing changed the length; positions would not line up.
+		return base.Render(line)
+	}
+	hit := make([]bool, len(runes))
+	for _, term := range terms {
+		t := []rune(strings.ToLower(term))
+		if len(t) == 0 {
+			continue
+		}
+		for i := 0; i+len(t) <= len(lower); i++ {
+			if string(lower[i:i+len(t)]) == string(t) {
+				for j := i; j < i+len(t); j++ {
+					hit[j] = true
+				}
+			}
+		}
+	}
+	match = match.Inherit(base)
+
+	var sb strings.Builder
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i == len(runes) || hit[i] != hit[start] {
+			style := base
+			if hit[start] {
+				style = match
+			}
+			sb.WriteString(style.Render(string(runes[start:i])))
+			start = i
+		}
+	}
+	return sb.String()
+}
+
 func (m Model) formatEntry(e history.HistoryEntry, maxWidth int) string {
-	// First line of query, truncated
+	// Metadata
+	var meta []string
+	switch {
+	case e.Adapter != "" && e.DatabaseName != "":
+		meta = append(meta, e.Adapter+":"+e.DatabaseName)
+	case e.Adapter != "":
+		meta = append(meta, e.Adapter)
+	}
+	if e.DurationMS > 0 {
+		meta = append(meta, format.Duration(time.Duration(e.DurationMS)*time.Millisecond))
+	}
+	if e.IsError {
+		meta = append(meta, "error")
+	} else {
+		meta = append(meta, formatRows(e.RowCount))
+	}
+	meta = append(meta, RelativeTime(e.ExecutedAt))
+	metaText := strings.Join(meta, " | ")
+
+	// First line of query, truncated to leave room for the metadata
 	query := firstLine(e.Query)
-	queryMax := maxWidth - 30 // leave room for metadata
+	queryMax := maxWidth - runewidth.StringWidth(metaText) - 2
 	if queryMax < 10 {
 		queryMax = 10
 	}
@@ -249,17 +455,34 @@ func (m Model) formatEntry(e history.HistoryEntry, maxWidth int) string {
 		query = query[:queryMax-3] + "..."
 	}
 
-	// Metadata
-	var meta []string
-	if e.Adapter != "" {
-		meta = append(meta, e.Adapter)
+	return fmt.Sprintf("%-*s  %s", queryMax, query, metaText)
+}
+
+// details describes the selected entry in one line: the error message of a
+// failed query, or how long it took and what it returned.
+func (m Model) details(maxWidth int) string {
+	th := theme.Current
+	if m.cursor >= len(m.entries) {
+		return ""
 	}
-	if e.DurationMS > 0 {
-		meta = append(meta, formatDuration(e.DurationMS))
+	e := m.entries[m.cursor]
+	when := e.ExecutedAt.Local().Format("2006-01-02 15:04:05")
+	if e.IsError {
+		text := "failed"
+		if e.ErrorMessage != "" {
+			text = strings.Join(strings.Fields(e.ErrorMessage), " ")
+		}
+		return th.ErrorText.Render(runewidth.Truncate("  ✗ "+when+"  "+text, maxWidth, "…"))
 	}
-	meta = append(meta, RelativeTime(e.ExecutedAt))
+	text := fmt.Sprintf("  ✓ %s  %s in %s", when, formatRows(e.RowCount), format.Duration(time.Duration(e.DurationMS)*time.Millisecond))
+	return th.MutedText.Render(runewidth.Truncate(text, maxWidth, "…"))
+}
 
-	return fmt.Sprintf("%-*s  %s", queryMax, query, strings.Join(meta, " | "))
+func formatRows(n int64) string {
+	if n == 1 {
+		return "1 row"
+	}
+	return fmt.Sprintf("%d rows", n)
 }
 
 func firstLine(s string) string {
@@ -270,13 +493,6 @@ func firstLine(s string) string {
 	return s
 }
 
-func formatDuration(ms int64) string {
-	if ms < 1000 {
-		return fmt.Sprintf("%dms", ms)
-	}
-	return fmt.Sprintf("%.1fs", float64(ms)/1000)
-}
-
 // RelativeTime formats a timestamp as a human-readable relative time.
 func RelativeTime(t time.Time) string {
 	d := time.Since(t)
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/sadopc/gotermsql/internal/history"
 )
 
@@ -53,6 +54,46 @@ func TestSelectQueryMsg(t *testing.T) {
 	}
 }
 
+func TestRunQueryMsg(t *testing.T) {
+	for _, tt := range []struct {
+		key    tea.KeyType
+		newTab bool
+	}{{tea.KeyCtrlR, false}, {tea.KeyCtrlT, true}} {
+		m := New(nil)
+		m.visible = true
+		m.entries = []histEntry{{Query: "SELECT 1"}}
+
+		m, cmd := m.Update(tea.KeyMsg{Type: tt.key})
+		if m.Visible() {
+			t.Error("expected hidden after running")
+		}
+		run, ok := cmd().(RunQueryMsg)
+		if !ok || run.Query != "SELECT 1" || run.NewTab != tt.newTab {
+			t.Errorf("%v: got %#v", tt.key, cmd())
+		}
+	}
+}
+
+func TestDetails(t *testing.T) {
+	m := New(nil)
+	m.SetSize(120, 30)
+	m.visible = true
+	m.entries = []histEntry{
+		{Query: "SELECT 1", Adapter: "postgres", DurationMS: 1500, RowCount: 42, ExecutedAt: time.Now()},
+		{Query: "SELECT x", Adapter: "postgres", IsError: true, ErrorMessage: "column \"x\"\ndoes not exist", ExecutedAt: time.Now()},
+	}
+	view := m.View()
+	for _, want := range []string{"42 rows in 1.5s", "1.5s | 42 rows"} {
+		if !contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+	m.cursor = 1
+	if view := m.View(); !contains(view, `column "x" does not exist`) {
+		t.Errorf("view missing the error message:\n%s", view)
+	}
+}
+
 func TestRelativeTime(t *testing.T) {
 	tests := []struct {
 		offset time.Duration
@@ -120,6 +161,145 @@ func TestEscHides(t *testing.T) {
 	}
 }
 
+func TestSearchFilters(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	h, err := history.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	for _, e := range []histEntry{
+		{Query: "SELECT * FROM orders", Adapter: "postgres", DatabaseName: "shop", ExecutedAt: time.Now()},
+		{Query: "SELECT * FROM order_items", Adapter: "postgres", DatabaseName: "shop", IsError: true, ExecutedAt: time.Now()},
+		{Query: "SELECT 1", Adapter: "sqlite", DatabaseName: "orders.db", ExecutedAt: time.Now()},
+	} {
+		if err := h.Add(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := New(h)
+	m.Show()
+	for _, r := range "order error:true" {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if len(m.entries) != 1 || m.entries[0].Query != "SELECT * FROM order_items" {
+		t.Fatalf("entries = %+v, want the failed order_items query", m.entries)
+	}
+	if len(m.terms) != 1 || m.terms[0] != "order" {
+		t.Errorf("terms = %q, want [order]", m.terms)
+	}
+}
+
+func TestScope(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	h, err := history.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	for _, e := range []histEntry{
+		{Query: "SELECT * FROM orders", Adapter: "postgres", DatabaseName: "shop", ExecutedAt: time.Now()},
+		{Query: "SELECT 1", Adapter: "sqlite", DatabaseName: "scratch.db", ExecutedAt: time.Now()},
+	} {
+		if err := h.Add(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := New(h)
+	m.SetSize(100, 30)
+	m.SetScope(&history.Scope{Adapter: "postgres", Database: "shop"})
+	m.Show()
+	if len(m.entries) != 1 || m.entries[0].Query != "SELECT * FROM orders" {
+		t.Fatalf("entries = %+v, want only the shop query", m.entries)
+	}
+	if !contains(m.View(), "postgres:shop") {
+		t.Error("expected the title to name the scope")
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if len(m.entries) != 2 || !contains(m.View(), "all connections") {
+		t.Fatalf("entries after tab = %+v, want both", m.entries)
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if len(m.entries) != 1 {
+		t.Fatalf("entries after second tab = %+v, want the scoped one", m.entries)
+	}
+
+	// A typed db: filter searches beyond the scope.
+	for _, r := range "db:scratch" {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if len(m.entries) != 1 || m.entries[0].Query != "SELECT 1" {
+		t.Fatalf("entries with db: filter = %+v", m.entries)
+	}
+
+	// Reopening goes back to the current database.
+	m.Hide()
+	m.all = true
+	m.Show()
+	if m.all || len(m.entries) != 1 {
+		t.Errorf("expected Show to reset to the scope, all = %v", m.all)
+	}
+}
+
+func TestClearScope(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	h, err := history.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	for _, e := range []histEntry{
+		{Query: "SELECT 1", Adapter: "postgres", DatabaseName: "shop", ExecutedAt: time.Now()},
+		{Query: "SELECT 2", Adapter: "sqlite", DatabaseName: "scratch.db", ExecutedAt: time.Now()},
+	} {
+		if err := h.Add(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := New(h)
+	m.SetSize(100, 30)
+	m.SetScope(&history.Scope{Adapter: "sqlite", Database: "scratch.db"})
+	m.Show()
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	if !contains(m.View(), "Delete all history for sqlite:scratch.db?") {
+		t.Fatal("expected a confirmation")
+	}
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if len(m.entries) != 1 {
+		t.Fatal("expected n to keep the history")
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if len(m.entries) != 0 || !contains(m.View(), "Deleted 1 entries") {
+		t.Fatalf("entries after clearing = %+v", m.entries)
+	}
+	if all, _ := h.Recent(10); len(all) != 1 || all[0].Adapter != "postgres" {
+		t.Errorf("other connections' history = %+v, want kept", all)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	match := lipgloss.NewStyle().Transform(func(s string) string { return "[" + s + "]" })
+	got := highlight("SELECT * FROM Users u JOIN users_old", []string{"users"}, lipgloss.NewStyle(), match)
+	if got != "SELECT * FROM [Users] u JOIN [users]_old" {
+		t.Errorf("highlight() = %q", got)
+	}
+	if got := highlight("SELECT 1", nil, lipgloss.NewStyle(), match); got != "SELECT 1" {
+		t.Errorf("highlight() without terms = %q", got)
+	}
+}
+
 // histEntry is a shorthand alias used only in tests to reduce verbosity.
 type histEntry = history.HistoryEntry
 
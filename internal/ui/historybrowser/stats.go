@@ -0,0 +1,218 @@
+package historybrowser
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/history"
+	"github.com/sadopc/gotermsql/internal/theme"
+	"github.com/sadopc/gotermsql/internal/ui/format"
+)
+
+// statsDays is how many days the query volume chart covers.
+const statsDays = 30
+
+// chartRows is the height of the query volume chart.
+const chartRows = 4
+
+// blocks are the partial bar characters, from empty to full.
+var blocks = []rune(" ▁▂▃▄▅▆▇█")
+
+// loadStats summarizes the history shown: the scope's, or all of it.
+func (m *Model) loadStats() {
+	m.summary, m.statsErr = nil, nil
+	if m.hist == nil {
+		return
+	}
+	var scope *history.Scope
+	if m.scoped() {
+		scope = m.scope
+	}
+	m.summary, m.statsErr = m.hist.Summarize(scope, statsDays, time.Now())
+}
+
+// statsCount returns how many entries each list in the statistics view
+// shows, to fit the height.
+func (m Model) statsCount() int {
+	// Title, totals, four headers, the chart and its axis, help, the blank
+	// lines between them and the border.
+	n := (m.height - 16 - chartRows) / 3
+	return max(1, min(n, 5))
+}
+
+// viewStats renders the statistics view.
+func (m Model) viewStats() string {
+	th := theme.Current
+	w := m.dialogWidth()
+	iw := w - 4
+
+	title := th.DialogTitle.Render("  Query Statistics  ")
+	if label := m.scopeLabel(); label != "" {
+		title += th.MutedText.Render("  " + label)
+	}
+	helpText := "  ctrl+s:list  esc:back"
+	if m.scope != nil {
+		if m.all {
+			helpText = "  tab:this database" + helpText
+		} else {
+			helpText = "  tab:all connections" + helpText
+		}
+	}
+	help := th.MutedText.Render(helpText)
+
+	var body []string
+	switch s := m.summary; {
+	case m.statsErr != nil:
+		body = append(body, th.ErrorText.Render("  Failed to load statistics: "+m.statsErr.Error()))
+	case s == nil || s.Total == 0:
+		body = append(body, th.MutedText.Render("  No history entries"))
+	default:
+		body = m.statsBody(s, iw)
+	}
+
+	parts := append([]string{title, ""}, body...)
+	parts = append(parts, "", help)
+	return th.DialogBorder.Width(w).Render(lipgloss.JoinVertical(lipgloss.Left, parts...))
+}
+
+// statsBody renders the sections of a summary.
+func (m Model) statsBody(s *history.Summary, width int) []string {
+	th := theme.Current
+	n := m.statsCount()
+	header := func(text string) string { return th.SidebarTitle.Render("  " + text) }
+	var lines []string
+
+	totals := fmt.Sprintf("  %d queries, %d failed (%s)", s.Total, s.Errors, percent(s.Errors, s.Total))
+	lines = append(lines, totals, "")
+
+	lines = append(lines, header("Most frequent"))
+	for _, q := range s.Frequent[:min(n, len(s.Frequent))] {
+		count := fmt.Sprintf("%5d×", q.Count)
+		mean := "avg " + format.Duration(time.Duration(q.MeanMS)*time.Millisecond)
+		lines = append(lines, "  "+row(count, firstLine(q.Query), mean, width-2))
+	}
+
+	lines = append(lines, header("Slowest"))
+	if len(s.Slowest) == 0 {
+		lines = append(lines, th.MutedText.Render("    no successful queries"))
+	}
+	for _, e := range s.Slowest[:min(n, len(s.Slowest))] {
+		took := fmt.Sprintf("%6s", format.Duration(time.Duration(e.DurationMS)*time.Millisecond))
+		lines = append(lines, "  "+row(took, firstLine(e.Query), RelativeTime(e.ExecutedAt), width-2))
+	}
+
+	lines = append(lines, header("Error rate by connection"))
+	conns := s.Connections[:min(n, len(s.Connections))]
+	nameW := 0
+	for _, c := range conns {
+		nameW = max(nameW, runewidth.StringWidth(connLabel(c)))
+	}
+	nameW = min(nameW, width/3)
+	for _, c := range conns {
+		name := runewidth.FillRight(runewidth.Truncate(connLabel(c), nameW, "…"), nameW)
+		tail := fmt.Sprintf(" %6s  %d of %d", percent(c.Errors, c.Queries), c.Errors, c.Queries)
+		barW := max(5, width-4-nameW-1-len(tail))
+		style := lipgloss.NewStyle()
+		if c.Errors > 0 {
+			style = th.ErrorText
+		}
+		lines = append(lines, "    "+name+" "+style.Render(bar(c.ErrorRate(), barW))+tail)
+	}
+
+	total, peak := 0, 0
+	for _, d := range s.Daily {
+		total += d.Count
+		peak = max(peak, d.Count)
+	}
+	lines = append(lines, header(fmt.Sprintf("Queries per day, last %d days (%d, busiest day %d)", len(s.Daily), total, peak)))
+	for _, r := range chart(s.Daily, chartRows, width-4) {
+		lines = append(lines, "    "+th.SQLKeyword.Render(r))
+	}
+	if len(s.Daily) > 0 {
+		from := s.Daily[0].Day.Format("Jan 2")
+		chartW := len(s.Daily) * columnWidth(len(s.Daily), width-4)
+		gap := max(1, chartW-runewidth.StringWidth(from)-len("today"))
+		lines = append(lines, th.MutedText.Render("    "+from+strings.Repeat(" ", gap)+"today"))
+	}
+	return lines
+}
+
+// row lays out a left column, a text truncated to fit and a right column
+// in width cells.
+func row(left, text, right string, width int) string {
+	textW := width - runewidth.StringWidth(left) - runewidth.StringWidth(right) - 4
+	if textW < 5 {
+		textW = 5
+	}
+	text = runewidth.FillRight(runewidth.Truncate(text, textW, "…"), textW)
+	return left + "  " + text + "  " + right
+}
+
+// connLabel names a connection as adapter:database.
+func connLabel(c history.ConnectionStats) string {
+	if c.Database == "" {
+		return c.Adapter
+	}
+	return c.Adapter + ":" + c.Database
+}
+
+// percent formats part of whole as a percentage.
+func percent(part, whole int) string {
+	if whole == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(part)/float64(whole))
+}
+
+// bar draws frac of width cells filled.
+func bar(frac float64, width int) string {
+	filled := int(frac*float64(width) + 0.5)
+	if frac > 0 && filled == 0 {
+		filled = 1 // any error shows
+	}
+	filled = min(filled, width)
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+// columnWidth returns the cells per day of a chart of days days in width.
+func columnWidth(days, width int) int {
+	if days == 0 {
+		return 1
+	}
+	return max(1, min(2, width/days))
+}
+
+// chart draws the daily counts as a bar chart rows lines tall, scaled to
+// the busiest day, at most width cells wide.
+func chart(days []history.DayCount, rows, width int) []string {
+	if len(days) == 0 {
+		return nil
+	}
+	colW := columnWidth(len(days), width)
+	if len(days)*colW > width {
+		days = days[len(days)-width/colW:] // keep the latest days
+	}
+	peak := 0
+	for _, d := range days {
+		peak = max(peak, d.Count)
+	}
+	levels := len(blocks) - 1
+	out := make([]string, rows)
+	for r := range rows {
+		var sb strings.Builder
+		floor := (rows - 1 - r) * levels // eighths below this line
+		for _, d := range days {
+			height := 0
+			if peak > 0 {
+				height = (d.Count*rows*levels + peak - 1) / peak // round up so any query shows
+			}
+			fill := max(0, min(levels, height-floor))
+			sb.WriteString(strings.Repeat(string(blocks[fill]), colW))
+		}
+		out[r] = sb.String()
+	}
+	return out
+}
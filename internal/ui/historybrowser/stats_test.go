@@ -0,0 +1,90 @@
+package historybrowser
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/history"
+)
+
+func TestStatsView(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	h, err := history.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	now := time.Now()
+	for _, e := range []histEntry{
+		{Query: "SELECT * FROM orders", Adapter: "postgres", DatabaseName: "shop", DurationMS: 40, ExecutedAt: now.Add(-48 * time.Hour)},
+		{Query: "SELECT * FROM orders", Adapter: "postgres", DatabaseName: "shop", DurationMS: 60, ExecutedAt: now},
+		{Query: "SELECT pg_sleep(3)", Adapter: "postgres", DatabaseName: "shop", DurationMS: 3000, ExecutedAt: now},
+		{Query: "SELECT nope", Adapter: "postgres", DatabaseName: "shop", IsError: true, ExecutedAt: now},
+		{Query: "SELECT 1", Adapter: "sqlite", DatabaseName: "scratch.db", ExecutedAt: now},
+	} {
+		if err := h.Add(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := New(h)
+	m.SetSize(100, 40)
+	m.SetScope(&history.Scope{Adapter: "postgres", Database: "shop"})
+	m.Show()
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if !m.stats {
+		t.Fatal("expected ctrl+s to show statistics")
+	}
+	view := m.View()
+	for _, want := range []string{
+		"Query Statistics", "postgres:shop",
+		"4 queries, 1 failed (25.0%)",
+		"2×  SELECT * FROM orders", "avg 50ms",
+		"3.0s  SELECT pg_sleep(3)",
+		"Error rate by connection", "25.0%  1 of 4",
+		"Queries per day, last 30 days (4, busiest day 3)", "today",
+	} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q", want)
+		}
+	}
+	if strings.Contains(view, "scratch.db") {
+		t.Error("expected only the scoped connection")
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if view := m.View(); !strings.Contains(view, "5 queries") || !strings.Contains(view, "sqlite:scratch.db") {
+		t.Errorf("expected every connection after tab:\n%s", view)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.stats || !m.Visible() {
+		t.Fatal("expected esc to go back to the list")
+	}
+}
+
+func TestChart(t *testing.T) {
+	days := []history.DayCount{{Count: 0}, {Count: 1}, {Count: 8}}
+	got := chart(days, 2, 10)
+	want := []string{"    ██", "  ▂▂██"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("chart = %q, want %q", got, want)
+	}
+	// Too narrow for every day: the latest are kept.
+	if got := chart(days, 1, 2); len([]rune(got[0])) != 2 || got[0] != "▁█" {
+		t.Errorf("narrow chart = %q", got)
+	}
+}
+
+func TestBar(t *testing.T) {
+	if got := bar(0.5, 4); got != "██░░" {
+		t.Errorf("bar(0.5) = %q", got)
+	}
+	if got := bar(0.01, 4); got != "█░░░" {
+		t.Errorf("bar(0.01) = %q, want a small rate to show", got)
+	}
+}
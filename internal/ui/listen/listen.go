@@ -0,0 +1,263 @@
+// Package listen is the LISTEN/NOTIFY panel opened with Alt+N: the channels
+// listened on, the notifications received on them as they arrive, and a
+// form to send one. Listening goes on while the panel is closed, so the
+// notifications are there when it is opened again.
+package listen
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+// ListenMsg asks the app to LISTEN on Channel, or to UNLISTEN from it.
+type ListenMsg struct {
+	Channel  string
+	Unlisten bool
+}
+
+// NotifyMsg asks the app to send Payload to Channel.
+type NotifyMsg struct {
+	Channel string
+	Payload string
+}
+
+// maxReceived is how many notifications are kept; older ones are dropped.
+const maxReceived = 1000
+
+// Form fields.
+const (
+	fieldListen = iota
+	fieldChannel
+	fieldPayload
+	fieldCount
+)
+
+// Model is the LISTEN/NOTIFY panel.
+type Model struct {
+	channels []string // listened on, sorted
+	received []adapter.Notification
+	scroll   int // notifications scrolled back from the newest
+	inputs   [fieldCount]textinput.Model
+	focus    int
+	visible  bool
+	width    int
+	height   int
+
+	message string
+	notice  bool // message is news rather than a problem
+}
+
+// New creates a hidden panel.
+func New() Model {
+	var m Model
+	labels := [fieldCount]string{"Listen:  ", "Notify:  ", "Payload: "}
+	placeholders := [fieldCount]string{"channel to listen on, or one listened on to stop", "channel", "text sent with it"}
+	for i := range m.inputs {
+		in := textinput.New()
+		in.Prompt = labels[i]
+		in.Placeholder = placeholders[i]
+		in.Width = 50
+		m.inputs[i] = in
+	}
+	return m
+}
+
+// Show opens the panel.
+func (m *Model) Show() {
+	m.visible = true
+	m.message = ""
+	m.focusField(m.focus)
+}
+
+// Hide closes the panel.
+func (m *Model) Hide() {
+	m.visible = false
+	for i := range m.inputs {
+		m.inputs[i].Blur()
+	}
+}
+
+// Visible returns whether the panel is shown.
+func (m Model) Visible() bool { return m.visible }
+
+// SetSize sets the available space.
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// SetMessage shows text in place of the key help until the next key press,
+// as an error unless notice is set.
+func (m *Model) SetMessage(text string, notice bool) {
+	m.message = text
+	m.notice = notice
+}
+
+// SetListening records that channel is listened on, or no longer is.
+func (m *Model) SetListening(channel string, on bool) {
+	i, found := slices.BinarySearch(m.channels, channel)
+	switch {
+	case on && !found:
+		m.channels = slices.Insert(m.channels, i, channel)
+	case !on && found:
+		m.channels = slices.Delete(m.channels, i, i+1)
+	}
+}
+
+// Channels returns the channels listened on.
+func (m Model) Channels() []string { return m.channels }
+
+// StopListening forgets the channels, once the listening session is gone.
+// The notifications received are kept.
+func (m *Model) StopListening() {
+	m.channels = nil
+}
+
+// Receive adds a notification to the list. When scrolled back, the view
+// stays on the notifications shown.
+func (m *Model) Receive(n adapter.Notification) {
+	m.received = append(m.received, n)
+	if len(m.received) > maxReceived {
+		m.received = slices.Delete(m.received, 0, len(m.received)-maxReceived)
+	}
+	if m.scroll > 0 {
+		m.scroll = min(m.scroll+1, len(m.received)-1)
+	}
+}
+
+// Received returns the notifications received, oldest first.
+func (m Model) Received() []adapter.Notification { return m.received }
+
+// Update handles panel key presses: tab moves between the fields, enter
+// listens or sends, ctrl+x clears the notifications and esc closes.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !m.visible || !ok {
+		return m, nil
+	}
+	m.message = ""
+	switch key.String() {
+	case "esc":
+		m.Hide()
+		return m, nil
+	case "tab", "down":
+		return m, m.focusField((m.focus + 1) % fieldCount)
+	case "shift+tab", "up":
+		return m, m.focusField((m.focus + fieldCount - 1) % fieldCount)
+	case "pgup":
+		m.scroll = min(m.scroll+m.logRows(), max(len(m.received)-m.logRows(), 0))
+		return m, nil
+	case "pgdown":
+		m.scroll = max(m.scroll-m.logRows(), 0)
+		return m, nil
+	case "ctrl+x":
+		m.received = nil
+		m.scroll = 0
+		return m, nil
+	case "enter":
+		return m, m.submit()
+	}
+	var cmd tea.Cmd
+	m.inputs[m.focus], cmd = m.inputs[m.focus].Update(key)
+	return m, cmd
+}
+
+// submit listens on the channel typed, or stops listening on it, or sends
+// the notification typed.
+func (m *Model) submit() tea.Cmd {
+	if m.focus == fieldListen {
+		channel := strings.TrimSpace(m.inputs[fieldListen].Value())
+		if channel == "" {
+			return nil
+		}
+		m.inputs[fieldListen].SetValue("")
+		_, unlisten := slices.BinarySearch(m.channels, channel)
+		return func() tea.Msg { return ListenMsg{Channel: channel, Unlisten: unlisten} }
+	}
+	channel := strings.TrimSpace(m.inputs[fieldChannel].Value())
+	if channel == "" {
+		m.SetMessage("Type the channel to notify", false)
+		return m.focusField(fieldChannel)
+	}
+	payload := m.inputs[fieldPayload].Value()
+	m.inputs[fieldPayload].SetValue("")
+	return func() tea.Msg { return NotifyMsg{Channel: channel, Payload: payload} }
+}
+
+func (m *Model) focusField(i int) tea.Cmd {
+	m.inputs[m.focus].Blur()
+	m.focus = i
+	return m.inputs[i].Focus()
+}
+
+// logRows returns how many notifications fit in the panel.
+func (m Model) logRows() int {
+	// Title, channels, the form, the position and help lines, the blank
+	// lines between them and the border.
+	return max(m.height-14, 3)
+}
+
+// View renders the panel.
+func (m Model) View() string {
+	if !m.visible {
+		return ""
+	}
+	th := theme.Current
+	w := 100
+	if m.width > 0 && w > m.width-4 {
+		w = m.width - 4
+	}
+	textW := w - 6
+
+	listening := "  Not listening"
+	if len(m.channels) > 0 {
+		listening = "  Listening on " + strings.Join(m.channels, ", ")
+	}
+	lines := []string{
+		th.DialogTitle.Render("  LISTEN/NOTIFY  "),
+		th.MutedText.Render(runewidth.Truncate(listening, textW, "…")),
+		"",
+	}
+
+	rows := m.logRows()
+	end := len(m.received) - m.scroll
+	start := max(end-rows, 0)
+	if len(m.received) == 0 {
+		lines = append(lines, th.MutedText.Render("  No notifications yet"))
+		rows--
+	}
+	for _, n := range m.received[start:end] {
+		line := fmt.Sprintf("%s  %s  %s", n.At.Format("15:04:05.000"), n.Channel, strings.ReplaceAll(n.Payload, "\n", "⏎"))
+		lines = append(lines, "  "+runewidth.Truncate(line, textW, "…"))
+	}
+	for i := end - start; i < rows; i++ {
+		lines = append(lines, "")
+	}
+	position := fmt.Sprintf("  %d received", len(m.received))
+	if m.scroll > 0 {
+		position = fmt.Sprintf("  %d-%d of %d received", start+1, end, len(m.received))
+	}
+	lines = append(lines, th.MutedText.Render(position), "")
+
+	for _, in := range m.inputs {
+		lines = append(lines, "  "+in.View())
+	}
+	lines = append(lines, "")
+	switch {
+	case m.message != "" && m.notice:
+		lines = append(lines, th.SuccessText.Render("  "+m.message))
+	case m.message != "":
+		lines = append(lines, th.ErrorText.Render("  "+m.message))
+	default:
+		lines = append(lines, th.MutedText.Render("  enter:listen/send  tab:next field  pgup/pgdn:scroll  ctrl+x:clear  esc:close"))
+	}
+	return th.DialogBorder.Width(w).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
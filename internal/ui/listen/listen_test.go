@@ -0,0 +1,93 @@
+package listen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+)
+
+func typeText(m Model, text string) Model {
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)})
+	return m
+}
+
+func TestPanel(t *testing.T) {
+	m := New()
+	m.SetSize(120, 40)
+	m.Show()
+
+	m = typeText(m, "orders")
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if msg := cmd(); msg != (ListenMsg{Channel: "orders"}) {
+		t.Fatalf("enter sent %#v, want LISTEN on orders", msg)
+	}
+	m.SetListening("orders", true)
+	m.SetListening("jobs", true)
+	if want := []string{"jobs", "orders"}; !reflect.DeepEqual(m.Channels(), want) {
+		t.Errorf("channels = %q, want %q", m.Channels(), want)
+	}
+
+	// A channel listened on already is stopped.
+	m = typeText(m, "orders")
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if msg := cmd(); msg != (ListenMsg{Channel: "orders", Unlisten: true}) {
+		t.Fatalf("enter sent %#v, want UNLISTEN from orders", msg)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = typeText(m, `{"id": 1}`)
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || m.message == "" {
+		t.Fatal("sending without a channel should ask for one")
+	}
+	m = typeText(m, "jobs")
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if msg := cmd(); msg != (NotifyMsg{Channel: "jobs", Payload: `{"id": 1}`}) {
+		t.Fatalf("enter sent %#v, want a notification", msg)
+	}
+
+	at := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	m.Receive(adapter.Notification{Channel: "jobs", Payload: "done", At: at})
+	view := m.View()
+	for _, want := range []string{"LISTEN/NOTIFY", "Listening on jobs, orders", "15:04:05.000  jobs  done", "1 received"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.Visible() {
+		t.Error("esc should close the panel")
+	}
+}
+
+func TestReceive(t *testing.T) {
+	m := New()
+	m.SetSize(120, 20)
+	m.Show()
+	for i := range maxReceived + 5 {
+		m.Receive(adapter.Notification{Channel: "c", Payload: fmt.Sprint(i)})
+	}
+	if got := m.Received(); len(got) != maxReceived || got[0].Payload != "5" {
+		t.Fatalf("kept %d from %q, want the newest %d", len(got), got[0].Payload, maxReceived)
+	}
+
+	// Scrolled back, new notifications do not move the view.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyPgUp})
+	before := m.View()
+	m.Receive(adapter.Notification{Channel: "c", Payload: "new"})
+	if strings.Contains(m.View(), "new") || m.scroll == 0 {
+		t.Errorf("the view followed the new notification:\n%s\nwas:\n%s", m.View(), before)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlX})
+	if len(m.Received()) != 0 {
+		t.Error("ctrl+x should clear the notifications")
+	}
+}
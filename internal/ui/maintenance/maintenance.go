@@ -0,0 +1,276 @@
+// Package maintenance is the maintenance panel of a SQLite database, opened
+// from the sidebar menu: its storage settings, an integrity check, and
+// VACUUM, ANALYZE and REINDEX on one key each, with the time they have
+// been running.
+package maintenance
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/theme"
+	"github.com/sadopc/gotermsql/internal/ui/format"
+)
+
+// IntegrityCheck is the command RunMsg sends to check the database.
+const IntegrityCheck = "integrity_check"
+
+// RunMsg asks the app to run Command (IntegrityCheck or one of the
+// adapter.Maintain commands) on the database shown.
+type RunMsg struct {
+	Schema  string
+	Command string
+}
+
+// CancelMsg asks the app to stop the command running, as the panel was
+// closed.
+type CancelMsg struct{}
+
+// TickMsg redraws the time the command has been running.
+type TickMsg struct{ gen int }
+
+// tickInterval is how often the running time is redrawn.
+const tickInterval = time.Second
+
+// maxProblems is how many integrity problems are listed.
+const maxProblems = 8
+
+// Model is the maintenance modal.
+type Model struct {
+	database string
+	schema   string
+	settings []adapter.Setting
+	visible  bool
+	loading  bool
+	width    int
+	height   int
+
+	running string // the command running, or ""
+	started time.Time
+	tickGen int
+
+	checked  bool     // an integrity check has finished
+	problems []string // what it found
+
+	message string
+	notice  bool // message is news rather than a problem
+}
+
+// New creates a hidden maintenance panel.
+func New() Model {
+	return Model{}
+}
+
+// Show opens the panel for database, whose schema is schemaName, waiting
+// for its settings.
+func (m *Model) Show(database, schemaName string) {
+	*m = Model{
+		database: database,
+		schema:   schemaName,
+		visible:  true,
+		loading:  true,
+		width:    m.width,
+		height:   m.height,
+		tickGen:  m.tickGen,
+	}
+}
+
+// Hide closes the panel.
+func (m *Model) Hide() {
+	m.visible = false
+}
+
+// Visible returns whether the panel is shown.
+func (m Model) Visible() bool { return m.visible }
+
+// Schema returns the schema name of the database shown.
+func (m Model) Schema() string { return m.schema }
+
+// Running returns the command running, or "".
+func (m Model) Running() string { return m.running }
+
+// SetSize sets the available space.
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// SetSettings shows the storage settings of the database.
+func (m *Model) SetSettings(settings []adapter.Setting) {
+	m.settings = settings
+	m.loading = false
+}
+
+// SetMessage shows text in place of the key help until the next key press,
+// as an error unless notice is set.
+func (m *Model) SetMessage(text string, notice bool) {
+	m.message = text
+	m.notice = notice
+	m.loading = false
+}
+
+// Start shows command as running and returns the tick that redraws its
+// running time.
+func (m *Model) Start(command string) tea.Cmd {
+	m.running = command
+	m.started = time.Now()
+	m.message = ""
+	m.tickGen++
+	return m.tick()
+}
+
+// Done ends the command running, reporting how long it took, or failure
+// when it is not empty.
+func (m *Model) Done(failure string) {
+	command, took := m.running, time.Since(m.started)
+	m.running = ""
+	if failure != "" {
+		m.SetMessage(command+" failed: "+failure, false)
+		return
+	}
+	m.SetMessage(fmt.Sprintf("%s finished in %s", command, took.Round(time.Millisecond)), true)
+}
+
+// SetProblems shows the result of an integrity check.
+func (m *Model) SetProblems(problems []string) {
+	m.checked = true
+	m.problems = problems
+}
+
+func (m Model) tick() tea.Cmd {
+	gen := m.tickGen
+	return tea.Tick(tickInterval, func(time.Time) tea.Msg { return TickMsg{gen: gen} })
+}
+
+// Update handles key presses: i checks the database's integrity, v, a and
+// r run VACUUM, ANALYZE and REINDEX, and esc closes the panel, stopping
+// the command running. It also handles TickMsg.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if t, ok := msg.(TickMsg); ok {
+		if !m.visible || m.running == "" || t.gen != m.tickGen {
+			return m, nil
+		}
+		return m, m.tick()
+	}
+	key, ok := msg.(tea.KeyMsg)
+	if !m.visible || !ok {
+		return m, nil
+	}
+	m.message = ""
+	if key.String() == "esc" || key.String() == "q" {
+		m.visible = false
+		if m.running != "" {
+			return m, func() tea.Msg { return CancelMsg{} }
+		}
+		return m, nil
+	}
+	if m.running != "" {
+		return m, nil // one at a time
+	}
+	var command string
+	switch key.String() {
+	case "i":
+		command = IntegrityCheck
+	case "v":
+		command = adapter.MaintainVacuum
+	case "a":
+		command = adapter.MaintainAnalyze
+	case "r":
+		command = adapter.MaintainReindex
+	default:
+		return m, nil
+	}
+	run := RunMsg{Schema: m.schema, Command: command}
+	return m, func() tea.Msg { return run }
+}
+
+// View renders the panel.
+func (m Model) View() string {
+	if !m.visible {
+		return ""
+	}
+	th := theme.Current
+	w := 64
+	if m.width > 0 && w > m.width-4 {
+		w = m.width - 4
+	}
+	textW := w - 6
+
+	lines := []string{th.DialogTitle.Render("  Maintenance: " + m.database + "  "), ""}
+	if m.loading {
+		lines = append(lines, th.MutedText.Render("  Loading..."))
+	}
+	for _, s := range m.settings {
+		lines = append(lines, "  "+th.MutedText.Render(runewidth.FillRight(s.Name, 16))+s.Value)
+	}
+	if size := m.size(); size != "" {
+		lines = append(lines, "  "+th.MutedText.Render(runewidth.FillRight("size", 16))+size)
+	}
+
+	if m.checked {
+		lines = append(lines, "")
+		switch {
+		case len(m.problems) == 0:
+			lines = append(lines, th.SuccessText.Render("  Integrity check: ok"))
+		default:
+			lines = append(lines, th.ErrorText.Render(fmt.Sprintf("  Integrity check: %d problem(s)", len(m.problems))))
+			for i, p := range m.problems {
+				if i == maxProblems {
+					lines = append(lines, th.MutedText.Render(fmt.Sprintf("  … %d more", len(m.problems)-maxProblems)))
+					break
+				}
+				lines = append(lines, "  "+runewidth.Truncate(p, textW, "…"))
+			}
+		}
+	}
+
+	lines = append(lines, "")
+	switch {
+	case m.running != "":
+		elapsed := time.Since(m.started).Truncate(time.Second)
+		lines = append(lines, th.MutedText.Render(fmt.Sprintf("  Running %s... %s  (esc stops it)", m.running, elapsed)))
+	case m.message != "" && m.notice:
+		lines = append(lines, th.SuccessText.Render("  "+runewidth.Truncate(m.message, textW, "…")))
+	case m.message != "":
+		lines = append(lines, th.ErrorText.Render("  "+runewidth.Truncate(m.message, textW, "…")))
+	default:
+		lines = append(lines, th.MutedText.Render("  i:integrity check  v:vacuum  a:analyze  r:reindex  esc:close"))
+	}
+	return th.DialogBorder.Width(w).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// size describes the size of the database file from its page count and
+// size, with the part held by free pages that VACUUM would give back.
+func (m Model) size() string {
+	var pages, pageSize, free int64
+	var ok int
+	for _, s := range m.settings {
+		n, err := strconv.ParseInt(s.Value, 10, 64)
+		if err != nil {
+			continue
+		}
+		switch s.Name {
+		case "page_count":
+			pages = n
+			ok++
+		case "page_size":
+			pageSize = n
+			ok++
+		case "freelist_count":
+			free = n
+		}
+	}
+	if ok < 2 {
+		return ""
+	}
+	text := format.Size(pages * pageSize)
+	if free > 0 {
+		text += fmt.Sprintf(" (%s free)", format.Size(free*pageSize))
+	}
+	return text
+}
@@ -0,0 +1,68 @@
+package maintenance
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+)
+
+func key(s string) tea.KeyMsg {
+	if s == "esc" {
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestPanel(t *testing.T) {
+	m := New()
+	m.SetSize(100, 30)
+	m.Show("archive", "old")
+	m.SetSettings([]adapter.Setting{
+		{Name: "journal_mode", Value: "wal"},
+		{Name: "page_size", Value: "4096"},
+		{Name: "page_count", Value: "512"},
+		{Name: "freelist_count", Value: "128"},
+	})
+	view := m.View()
+	for _, want := range []string{"Maintenance: archive", "journal_mode", "wal", "2M (512K free)", "v:vacuum"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+
+	_, cmd := m.Update(key("v"))
+	if got, ok := cmd().(RunMsg); !ok || got != (RunMsg{Schema: "old", Command: adapter.MaintainVacuum}) {
+		t.Fatalf("v sent %#v", got)
+	}
+
+	m.Start(adapter.MaintainVacuum)
+	if !strings.Contains(m.View(), "Running VACUUM") {
+		t.Errorf("view lacks the progress:\n%s", m.View())
+	}
+	if _, cmd := m.Update(key("a")); cmd != nil {
+		t.Error("a second command started while one runs")
+	}
+	m.Done("")
+	if !strings.Contains(m.View(), "VACUUM finished in") {
+		t.Errorf("view lacks the result:\n%s", m.View())
+	}
+
+	m.Start(IntegrityCheck)
+	m.Done("")
+	m.SetProblems([]string{"row 3 missing from index t_name"})
+	if view := m.View(); !strings.Contains(view, "1 problem(s)") || !strings.Contains(view, "row 3 missing") {
+		t.Errorf("view lacks the problems:\n%s", view)
+	}
+
+	// Closing the panel stops the command running.
+	m.Start(adapter.MaintainReindex)
+	m, cmd = m.Update(key("esc"))
+	if m.Visible() || cmd == nil {
+		t.Fatal("esc should close the panel")
+	}
+	if _, ok := cmd().(CancelMsg); !ok {
+		t.Error("esc should cancel the command running")
+	}
+}
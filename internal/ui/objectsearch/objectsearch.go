@@ -0,0 +1,201 @@
+// Package objectsearch is the search across the names of every object of
+// the schema, columns included, opened with Alt+O: type a name to
+// list the tables, views, columns, routines, sequences and triggers
+// matching it, from the completion engine's index, and pick one to select
+// it in the sidebar.
+package objectsearch
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/completion"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+// PickMsg is sent when an object is picked.
+type PickMsg struct {
+	Object completion.Object
+}
+
+// Model is the object search modal.
+type Model struct {
+	engine  *completion.Engine
+	found   []completion.Object
+	cursor  int
+	offset  int
+	input   textinput.Model
+	visible bool
+	width   int
+	height  int
+}
+
+// New creates a hidden object search over the index of engine.
+func New(engine *completion.Engine) Model {
+	ti := textinput.New()
+	ti.Prompt = "  > "
+	ti.Placeholder = "table, column, view or routine name"
+	ti.Width = 50
+	return Model{engine: engine, input: ti}
+}
+
+// SetEngine replaces the completion engine searched, as on a new
+// connection.
+func (m *Model) SetEngine(engine *completion.Engine) {
+	m.engine = engine
+}
+
+// Show opens the search, keeping the text typed last so the search can be
+// picked up again.
+func (m *Model) Show() {
+	m.visible = true
+	m.input.Focus()
+	m.input.CursorEnd()
+	m.search()
+}
+
+// Hide closes the search.
+func (m *Model) Hide() {
+	m.visible = false
+	m.input.Blur()
+}
+
+// Visible returns whether the search is shown.
+func (m Model) Visible() bool { return m.visible }
+
+// Found returns the objects matching the text typed.
+func (m Model) Found() []completion.Object { return m.found }
+
+// SetSize sets the available space.
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// Update handles key presses: typing searches, the arrow keys move, enter
+// picks the object selected and esc closes.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if !m.visible {
+		return m, nil
+	}
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+
+	switch key.String() {
+	case "esc", "alt+o":
+		m.Hide()
+		return m, nil
+	case "up", "ctrl+k", "ctrl+p":
+		m.cursor = max(m.cursor-1, 0)
+		m.ensureVisible()
+		return m, nil
+	case "down", "ctrl+j", "ctrl+n":
+		m.cursor = max(min(m.cursor+1, len(m.found)-1), 0)
+		m.ensureVisible()
+		return m, nil
+	case "pgup":
+		m.cursor = max(m.cursor-m.visibleCount(), 0)
+		m.ensureVisible()
+		return m, nil
+	case "pgdown":
+		m.cursor = max(min(m.cursor+m.visibleCount(), len(m.found)-1), 0)
+		m.ensureVisible()
+		return m, nil
+	case "enter":
+		if m.cursor >= len(m.found) {
+			return m, nil
+		}
+		picked := m.found[m.cursor]
+		m.Hide()
+		return m, func() tea.Msg { return PickMsg{Object: picked} }
+	}
+
+	prev := m.input.Value()
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(key)
+	if m.input.Value() != prev {
+		m.search()
+	}
+	return m, cmd
+}
+
+// search lists the objects matching the text typed.
+func (m *Model) search() {
+	m.cursor, m.offset = 0, 0
+	m.found = nil
+	if m.engine != nil {
+		m.found = m.engine.Search(m.input.Value())
+	}
+}
+
+// View renders the search.
+func (m Model) View() string {
+	if !m.visible {
+		return ""
+	}
+	th := theme.Current
+	w := 100
+	if m.width > 0 && w > m.width-4 {
+		w = m.width - 4
+	}
+	textW := w - 6
+
+	var lines []string
+	end := min(m.offset+m.visibleCount(), len(m.found))
+	for i := m.offset; i < end; i++ {
+		o := m.found[i]
+		place := o.Schema
+		if o.Kind == completion.KindColumn {
+			place += "." + o.Table
+		}
+		line := fmt.Sprintf("%-10s", o.Kind) +
+			runewidth.FillRight(runewidth.Truncate(o.Name, 32, "…"), 33) +
+			runewidth.FillRight(runewidth.Truncate(place, 32, "…"), 33)
+		line = runewidth.Truncate(line+o.Detail, textW, "…")
+		if i == m.cursor {
+			lines = append(lines, "  "+th.SidebarSelected.Render(runewidth.FillRight(line, textW)))
+		} else {
+			lines = append(lines, "  "+line)
+		}
+	}
+	if len(m.found) == 0 {
+		text := "  Type a name to search the schema"
+		if m.input.Value() != "" {
+			text = "  No matching objects"
+		}
+		lines = append(lines, th.MutedText.Render(text))
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		th.DialogTitle.Render("  Find Object  "),
+		m.input.View(),
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, lines...),
+		"",
+		th.MutedText.Render(fmt.Sprintf("  %d found  enter:show in sidebar  esc:close", len(m.found))),
+	)
+	return th.DialogBorder.Width(w).Render(content)
+}
+
+// visibleCount returns how many objects fit in the list.
+func (m Model) visibleCount() int {
+	// Title, input, blank, blank, footer and the border take 7 lines.
+	return max(3, m.height-7)
+}
+
+func (m *Model) ensureVisible() {
+	visible := m.visibleCount()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+visible {
+		m.offset = m.cursor - visible + 1
+	}
+}
@@ -0,0 +1,62 @@
+package objectsearch
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/completion"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+func typeText(m Model, s string) Model {
+	for _, r := range s {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return m
+}
+
+func TestSearch(t *testing.T) {
+	e := completion.NewEngine("postgres")
+	e.UpdateSchema([]schema.Database{{Name: "shop", Schemas: []schema.Schema{{Name: "public", Tables: []schema.Table{
+		{Name: "orders", Columns: []schema.Column{{Name: "customer_uuid", Type: "uuid"}}},
+		{Name: "invoices", Columns: []schema.Column{{Name: "customer_uuid", Type: "uuid"}}},
+		{Name: "products", Columns: []schema.Column{{Name: "sku", Type: "text"}}},
+	}}}}})
+
+	m := New(e)
+	m.SetSize(120, 30)
+	m.Show()
+	if view := m.View(); !strings.Contains(view, "Type a name") {
+		t.Errorf("view lacks the hint:\n%s", view)
+	}
+
+	m = typeText(m, "customer_uuid")
+	if got := len(m.Found()); got != 2 {
+		t.Fatalf("found %d objects, want the column in orders and invoices", got)
+	}
+	view := m.View()
+	for _, want := range []string{"Find Object", "public.orders", "public.invoices", "uuid", "2 found"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.Visible() || cmd == nil {
+		t.Fatal("enter should pick the object and close the search")
+	}
+	if pick, ok := cmd().(PickMsg); !ok || pick.Object != m.Found()[1] {
+		t.Errorf("enter sent %#v, want the second column", pick)
+	}
+
+	m.Show()
+	if len(m.Found()) != 2 {
+		t.Error("opening the search again should keep the last search")
+	}
+	m = typeText(m, "zzz")
+	if view := m.View(); !strings.Contains(view, "No matching objects") {
+		t.Errorf("view lacks the empty result:\n%s", view)
+	}
+}
@@ -0,0 +1,139 @@
+// Package params is the prompt for the bind parameters of a query, shown
+// before it runs as a prepared statement. The values typed for a name are
+// offered again the next time a query uses it.
+package params
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+// Model is the parameter prompt modal.
+type Model struct {
+	names   []string
+	inputs  []textinput.Model
+	focus   int
+	visible bool
+	width   int
+
+	// submit makes the message sent with the values typed, in the order
+	// of names.
+	submit func(values []string) tea.Msg
+	// last holds the values typed before, by parameter name.
+	last map[string]string
+}
+
+// New creates a hidden prompt.
+func New() Model {
+	return Model{last: make(map[string]string)}
+}
+
+// Show opens the prompt for the parameters names. Enter sends the message
+// submit makes of the values; Esc cancels.
+func (m *Model) Show(names []string, submit func(values []string) tea.Msg) {
+	width := 0
+	for _, n := range names {
+		width = max(width, runewidth.StringWidth(label(n)))
+	}
+	m.names = names
+	m.inputs = make([]textinput.Model, len(names))
+	for i, n := range names {
+		in := textinput.New()
+		in.Prompt = label(n) + strings.Repeat(" ", width-runewidth.StringWidth(label(n))) + "  "
+		in.Placeholder = "NULL"
+		in.Width = 40
+		in.SetValue(m.last[n])
+		m.inputs[i] = in
+	}
+	m.focus = 0
+	m.inputs[0].Focus()
+	m.submit = submit
+	m.visible = true
+}
+
+// label is how the parameter name is shown: :name for a named one, and
+// $1 or ?1 for a positional one.
+func label(name string) string {
+	if strings.HasPrefix(name, "$") || strings.HasPrefix(name, "?") {
+		return name
+	}
+	return ":" + name
+}
+
+// Hide closes the prompt.
+func (m *Model) Hide() {
+	m.visible = false
+}
+
+// Visible returns whether the prompt is shown.
+func (m Model) Visible() bool { return m.visible }
+
+// SetSize sets the available width.
+func (m *Model) SetSize(width, _ int) {
+	m.width = width
+}
+
+// Update handles key presses: tab and the arrows move between fields,
+// enter runs the query and esc cancels it.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !m.visible || !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "esc":
+		m.visible = false
+		return m, nil
+	case "tab", "down":
+		return m, m.moveFocus(1)
+	case "shift+tab", "up":
+		return m, m.moveFocus(-1)
+	case "enter":
+		values := make([]string, len(m.inputs))
+		for i, in := range m.inputs {
+			values[i] = in.Value()
+			m.last[m.names[i]] = values[i]
+		}
+		m.visible = false
+		submit := m.submit
+		return m, func() tea.Msg { return submit(values) }
+	}
+	var cmd tea.Cmd
+	m.inputs[m.focus], cmd = m.inputs[m.focus].Update(key)
+	return m, cmd
+}
+
+// moveFocus moves the focus by delta fields, wrapping around.
+func (m *Model) moveFocus(delta int) tea.Cmd {
+	m.inputs[m.focus].Blur()
+	m.focus = (m.focus + delta + len(m.inputs)) % len(m.inputs)
+	return m.inputs[m.focus].Focus()
+}
+
+// View renders the prompt.
+func (m Model) View() string {
+	if !m.visible {
+		return ""
+	}
+	th := theme.Current
+	w := 70
+	if m.width > 0 && w > m.width-4 {
+		w = m.width - 4
+	}
+
+	lines := []string{th.DialogTitle.Render("  Query Parameters  "), ""}
+	for _, in := range m.inputs {
+		lines = append(lines, "  "+in.View())
+	}
+	lines = append(lines,
+		"",
+		th.MutedText.Render(runewidth.Truncate("  42, 1.5, true and NULL are typed; quote '007' to send text", w-4, "…")),
+		th.MutedText.Render("  tab:next field  enter:run  esc:cancel"),
+	)
+	return th.DialogBorder.Width(w).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,55 @@
+package params
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func typeText(m Model, text string) Model {
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)})
+	return m
+}
+
+func TestPrompt(t *testing.T) {
+	var got []string
+	submit := func(values []string) tea.Msg { got = values; return nil }
+
+	m := New()
+	m.SetSize(100, 30)
+	m.Show([]string{"id", "$2", "?3"}, submit)
+	view := m.View()
+	for _, want := range []string{":id", "$2", "?3", "Query Parameters"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+
+	m = typeText(m, "42")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m = typeText(m, "'x'")
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.Visible() || cmd == nil {
+		t.Fatal("enter should close the prompt and run")
+	}
+	cmd()
+	if want := []string{"42", "", "'x'"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("values = %q, want %q", got, want)
+	}
+
+	// The values are offered again for the same names; esc cancels.
+	m.Show([]string{"id", "other"}, submit)
+	if v := m.inputs[0].Value(); v != "42" {
+		t.Errorf("id = %q, want the last value", v)
+	}
+	if v := m.inputs[1].Value(); v != "" {
+		t.Errorf("other = %q, want empty", v)
+	}
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.Visible() || cmd != nil {
+		t.Error("esc should close the prompt without running")
+	}
+}
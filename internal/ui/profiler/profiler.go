@@ -0,0 +1,230 @@
+// Package profiler is the data profile of a table, opened from the sidebar
+// menu: for each column of a sample of its rows, the share of NULLs, the
+// distinct values, the least and greatest value and the mean length, and
+// the most frequent values of the column selected.
+package profiler
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/theme"
+	"github.com/sadopc/gotermsql/internal/ui/format"
+)
+
+// CancelMsg asks the app to stop profiling, as the panel was closed.
+type CancelMsg struct{}
+
+// topRows is how many lines are kept for the top values of the column
+// selected.
+const topRows = 5
+
+// Model is the profile modal.
+type Model struct {
+	table   string
+	sample  int // the most rows profiled
+	profile adapter.TableProfile
+	cursor  int
+	offset  int
+	visible bool
+	loading bool
+	message string
+	width   int
+	height  int
+}
+
+// New creates a hidden profile.
+func New() Model {
+	return Model{}
+}
+
+// Show opens the panel for table, waiting for the profile of up to sample
+// of its rows.
+func (m *Model) Show(table string, sample int) {
+	*m = Model{table: table, sample: sample, visible: true, loading: true, width: m.width, height: m.height}
+}
+
+// Hide closes the panel.
+func (m *Model) Hide() {
+	m.visible = false
+}
+
+// Visible returns whether the panel is shown.
+func (m Model) Visible() bool { return m.visible }
+
+// Table returns the table profiled.
+func (m Model) Table() string { return m.table }
+
+// Loading returns whether the profile is still being computed.
+func (m Model) Loading() bool { return m.loading }
+
+// SetSize sets the available space.
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.clamp()
+}
+
+// SetProfile shows the profile computed.
+func (m *Model) SetProfile(p adapter.TableProfile) {
+	m.profile = p
+	m.loading = false
+	m.cursor, m.offset = 0, 0
+}
+
+// SetError shows why the profile could not be computed.
+func (m *Model) SetError(text string) {
+	m.message = text
+	m.loading = false
+}
+
+// Update handles key presses: the arrow keys select a column, whose most
+// frequent values are listed below, and esc closes the panel, stopping the
+// profiling.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !m.visible || !ok {
+		return m, nil
+	}
+	switch key.String() {
+	case "esc", "q":
+		m.visible = false
+		if m.loading {
+			m.loading = false
+			return m, func() tea.Msg { return CancelMsg{} }
+		}
+	case "up", "k":
+		m.cursor--
+	case "down", "j":
+		m.cursor++
+	case "pgup":
+		m.cursor -= m.listRows()
+	case "pgdown":
+		m.cursor += m.listRows()
+	case "home", "g":
+		m.cursor = 0
+	case "end", "G":
+		m.cursor = len(m.profile.Columns) - 1
+	}
+	m.clamp()
+	return m, nil
+}
+
+func (m *Model) clamp() {
+	m.cursor = max(min(m.cursor, len(m.profile.Columns)-1), 0)
+	rows := m.listRows()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+}
+
+// listRows returns how many columns fit in the modal.
+func (m Model) listRows() int {
+	// Title, summary, header, the top values and their title, help, the
+	// blank lines between them and the border.
+	return max(m.height-topRows-13, 3)
+}
+
+// View renders the panel.
+func (m Model) View() string {
+	if !m.visible {
+		return ""
+	}
+	th := theme.Current
+	w := 120
+	if m.width > 0 && w > m.width-4 {
+		w = m.width - 4
+	}
+	textW := w - 6
+
+	lines := []string{th.DialogTitle.Render("  Profile: " + m.table + "  "), ""}
+	switch {
+	case m.loading:
+		lines = append(lines, th.MutedText.Render(fmt.Sprintf("  Profiling up to %d rows... (esc stops)", m.sample)))
+	case m.message != "":
+		lines = append(lines, th.ErrorText.Render("  "+runewidth.Truncate(m.message, textW, "…")))
+	default:
+		lines = append(lines, m.profileLines(textW)...)
+	}
+	lines = append(lines, "", th.MutedText.Render("  arrows:select column  esc:close"))
+	return th.DialogBorder.Width(w).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// profileLines renders a line per column and the top values of the one
+// selected.
+func (m Model) profileLines(textW int) []string {
+	th := theme.Current
+	p := m.profile
+	summary := fmt.Sprintf("%d rows profiled", p.Rows)
+	if p.Rows >= int64(m.sample) {
+		summary = fmt.Sprintf("The first %d rows profiled", p.Rows)
+	}
+	lines := []string{th.MutedText.Render("  " + summary), ""}
+
+	// The minimum and maximum share what the other columns leave.
+	valueW := max((textW-20-14-7-9-7-5)/2-1, 8)
+	row := func(name, typ, nulls, distinct, lo, hi, length string) string {
+		line := runewidth.FillRight(runewidth.Truncate(name, 19, "…"), 20) +
+			runewidth.FillRight(runewidth.Truncate(typ, 13, "…"), 14) +
+			fmt.Sprintf("%6s %8s  ", nulls, distinct) +
+			runewidth.FillRight(runewidth.Truncate(lo, valueW, "…"), valueW+1) +
+			runewidth.FillRight(runewidth.Truncate(hi, valueW, "…"), valueW+1) +
+			fmt.Sprintf("%7s", length)
+		return runewidth.Truncate(line, textW, "…")
+	}
+	lines = append(lines, th.MutedText.Render("  "+row("column", "type", "null", "distinct", "min", "max", "avg len")))
+
+	rows := m.listRows()
+	end := min(m.offset+rows, len(p.Columns))
+	for i := m.offset; i < end; i++ {
+		c := p.Columns[i]
+		length := ""
+		if c.AvgLength >= 0 {
+			length = fmt.Sprintf("%.1f", c.AvgLength)
+		}
+		line := row(c.Name, c.Type, percent(c.Nulls, p.Rows), fmt.Sprint(c.Distinct), format.OneLine(c.Min), format.OneLine(c.Max), length)
+		if i == m.cursor {
+			lines = append(lines, "  "+th.SidebarSelected.Render(runewidth.FillRight(line, textW)))
+		} else {
+			lines = append(lines, "  "+line)
+		}
+	}
+	for i := end - m.offset; i < rows; i++ {
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, "")
+	if m.cursor >= len(p.Columns) {
+		return append(lines, th.MutedText.Render("  No columns"))
+	}
+	c := p.Columns[m.cursor]
+	lines = append(lines, th.MutedText.Render("  Most frequent values of "+c.Name))
+	for i := range topRows {
+		if i >= len(c.Top) {
+			lines = append(lines, "")
+			continue
+		}
+		v := c.Top[i]
+		value := format.OneLine(v.Value)
+		if adapter.IsNull(v.Value) {
+			value = "NULL"
+		}
+		count := fmt.Sprintf("%8d  %6s", v.Count, percent(v.Count, p.Rows))
+		lines = append(lines, "    "+runewidth.FillRight(runewidth.Truncate(value, textW-20, "…"), textW-18)+count)
+	}
+	return lines
+}
+
+// percent renders n as a share of total.
+func percent(n, total int64) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(n)/float64(total))
+}
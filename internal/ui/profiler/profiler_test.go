@@ -0,0 +1,56 @@
+package profiler
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+)
+
+func key(s string) tea.KeyMsg {
+	if s == "esc" {
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestPanel(t *testing.T) {
+	m := New()
+	m.SetSize(140, 40)
+	m.Show("people", 100)
+	if view := m.View(); !strings.Contains(view, "Profiling up to 100 rows") {
+		t.Errorf("view lacks the loading line:\n%s", view)
+	}
+	m.SetProfile(adapter.TableProfile{Rows: 4, Columns: []adapter.ColumnProfile{
+		{Name: "id", Type: "INTEGER", Distinct: 4, Min: "1", Max: "4", AvgLength: -1},
+		{Name: "city", Type: "TEXT", Nulls: 1, Distinct: 2, Min: "Oslo", Max: "Rome", AvgLength: 4, Top: []adapter.ValueCount{
+			{Value: "Oslo", Count: 2}, {Value: adapter.NullValue, Count: 1}, {Value: "Rome", Count: 1},
+		}},
+	}})
+
+	view := m.View()
+	for _, want := range []string{"Profile: people", "4 rows profiled", "avg len", "Most frequent values of id"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+	m, _ = m.Update(key("j"))
+	view = m.View()
+	for _, want := range []string{"25.0%", "4.0", "Most frequent values of city", "NULL", "50.0%"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+
+	m, cmd := m.Update(key("esc"))
+	if m.Visible() || cmd != nil {
+		t.Error("esc should close a finished profile without cancelling")
+	}
+	m.Show("people", 100)
+	if _, cmd := m.Update(key("esc")); cmd == nil {
+		t.Error("esc while profiling should stop it")
+	} else if _, ok := cmd().(CancelMsg); !ok {
+		t.Error("esc while profiling should send CancelMsg")
+	}
+}
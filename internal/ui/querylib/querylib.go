@@ -0,0 +1,584 @@
+// Package querylib is the saved query library overlay opened with Ctrl+L: the
+// library's queries grouped by folder, filtered as you type, with a form to
+// save the editor's query under a name, folder, description and tags. Queries
+// from shared team libraries are listed after the personal ones, read-only.
+// A query can be scheduled to run every so often against a connection.
+package querylib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/library"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+// InsertMsg is sent when a query is picked to insert into the editor.
+type InsertMsg struct {
+	SQL string
+}
+
+// LibraryUpdatedMsg is sent when a query was saved or deleted. The app
+// writes Queries to the library file.
+type LibraryUpdatedMsg struct {
+	Queries []library.Query
+	Notice  string
+}
+
+// SyncMsg asks the app to pull the shared libraries kept in git and reload
+// the library.
+type SyncMsg struct{}
+
+// Form fields.
+const (
+	fieldName = iota
+	fieldFolder
+	fieldDescription
+	fieldTags
+	fieldEvery
+	fieldConnection
+	fieldCount
+)
+
+// previewLines is how many lines of the selected query are shown.
+const previewLines = 4
+
+// Model is the query library modal.
+type Model struct {
+	queries []library.Query
+	items   []int // indexes into queries matching the filter
+	cursor  int
+	offset  int
+	filter  textinput.Model
+	visible bool
+	width   int
+	height  int
+
+	editorSQL string // the editor's query when the library was opened
+	deleting  bool   // asking to confirm deleting the selected query
+	message   string
+	notice    bool // message is news rather than a problem
+
+	// Form state. editing is the path of the query being edited, or ""
+	// when saving a new one.
+	form      bool
+	editing   string
+	formSQL   string
+	inputs    [fieldCount]textinput.Model
+	formFocus int
+	formErr   string
+}
+
+// New creates a hidden query library.
+func New() Model {
+	ti := textinput.New()
+	ti.Prompt = "  > "
+	ti.Placeholder = "name, folder, description or #tag"
+	ti.Width = 50
+
+	m := Model{filter: ti}
+	labels := [fieldCount]string{"Name:        ", "Folder:      ", "Description: ", "Tags:        ", "Run every:   ", "Connection:  "}
+	placeholders := [fieldCount]string{"top customers", "reports/monthly", "", "comma or space separated", "e.g. 15m; empty = only when run", "saved connection to run on"}
+	for i := range m.inputs {
+		in := textinput.New()
+		in.Prompt = labels[i]
+		in.Placeholder = placeholders[i]
+		in.Width = 40
+		m.inputs[i] = in
+	}
+	return m
+}
+
+// Show opens the library on queries. editorSQL is the query in the editor,
+// which Ctrl+S saves to the library.
+func (m *Model) Show(queries []library.Query, editorSQL string) {
+	m.queries = queries
+	m.editorSQL = editorSQL
+	m.visible = true
+	m.form = false
+	m.deleting = false
+	m.message = ""
+	m.filter.SetValue("")
+	m.filter.Focus()
+	m.applyFilter()
+}
+
+// SetQueries replaces the listed queries, keeping the filter, after the
+// library was reloaded.
+func (m *Model) SetQueries(queries []library.Query) {
+	m.queries = queries
+	m.applyFilter()
+}
+
+// SetMessage shows text in place of the key help until the next key press,
+// as an error unless notice is set.
+func (m *Model) SetMessage(text string, notice bool) {
+	m.message = text
+	m.notice = notice
+}
+
+// Hide closes the library.
+func (m *Model) Hide() {
+	m.visible = false
+	m.filter.Blur()
+	for i := range m.inputs {
+		m.inputs[i].Blur()
+	}
+}
+
+// Visible returns whether the library is shown.
+func (m Model) Visible() bool { return m.visible }
+
+// SetSize sets the available space.
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// Update handles library key presses.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if !m.visible {
+		return m, nil
+	}
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		var cmd tea.Cmd
+		if m.form {
+			m.inputs[m.formFocus], cmd = m.inputs[m.formFocus].Update(msg)
+		} else {
+			m.filter, cmd = m.filter.Update(msg)
+		}
+		return m, cmd
+	}
+	if m.form {
+		return m.updateForm(key)
+	}
+	if m.deleting {
+		return m.updateDelete(key)
+	}
+
+	m.message = ""
+	switch key.String() {
+	case "esc", "ctrl+l":
+		m.Hide()
+		return m, nil
+	case "up", "ctrl+k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		m.ensureVisible()
+		return m, nil
+	case "down", "ctrl+n", "ctrl+j":
+		if m.cursor < len(m.items)-1 {
+			m.cursor++
+		}
+		m.ensureVisible()
+		return m, nil
+	case "enter":
+		q, ok := m.selected()
+		if !ok {
+			return m, nil
+		}
+		m.Hide()
+		return m, func() tea.Msg { return InsertMsg{SQL: q.SQL} }
+	case "ctrl+s":
+		if strings.TrimSpace(m.editorSQL) == "" {
+			m.SetMessage("The editor is empty; write a query to save first", false)
+			return m, nil
+		}
+		return m, m.openForm(library.Query{SQL: m.editorSQL}, "")
+	case "ctrl+e":
+		q, ok := m.selected()
+		switch {
+		case !ok:
+			return m, nil
+		case q.ReadOnly():
+			// Shared queries are edited as a personal copy.
+			q.Source = ""
+			return m, m.openForm(q, "")
+		}
+		return m, m.openForm(q, q.Path())
+	case "ctrl+d":
+		if q, ok := m.selected(); ok && q.ReadOnly() {
+			m.SetMessage(fmt.Sprintf("%s is shared from %s and read-only", q.Path(), q.Source), false)
+		} else if ok {
+			m.deleting = true
+		}
+		return m, nil
+	case "ctrl+r":
+		m.SetMessage("Syncing shared libraries…", true)
+		return m, func() tea.Msg { return SyncMsg{} }
+	}
+
+	prev := m.filter.Value()
+	var cmd tea.Cmd
+	m.filter, cmd = m.filter.Update(key)
+	if m.filter.Value() != prev {
+		m.applyFilter()
+	}
+	return m, cmd
+}
+
+// updateDelete handles the answer to the delete confirmation.
+func (m Model) updateDelete(key tea.KeyMsg) (Model, tea.Cmd) {
+	m.deleting = false
+	if key.String() != "y" {
+		return m, nil
+	}
+	q, _ := m.selected()
+	m.queries = library.Remove(m.queries, q.Path())
+	m.applyFilter()
+	return m, m.updated("Deleted " + q.Path())
+}
+
+// openForm shows the form filled in from q. editing is the path q is saved
+// under, or "" for a new query.
+func (m *Model) openForm(q library.Query, editing string) tea.Cmd {
+	m.form = true
+	m.editing = editing
+	m.formSQL = q.SQL
+	m.formErr = ""
+	m.inputs[fieldName].SetValue(q.Name)
+	m.inputs[fieldFolder].SetValue(q.Folder)
+	m.inputs[fieldDescription].SetValue(q.Description)
+	m.inputs[fieldTags].SetValue(strings.Join(q.Tags, ", "))
+	m.inputs[fieldEvery].SetValue("")
+	if q.Every > 0 {
+		m.inputs[fieldEvery].SetValue(formatEvery(q.Every))
+	}
+	m.inputs[fieldConnection].SetValue(q.Connection)
+	m.filter.Blur()
+	m.formFocus = fieldName
+	for i := range m.inputs {
+		m.inputs[i].Blur()
+		m.inputs[i].CursorEnd()
+	}
+	return m.inputs[m.formFocus].Focus()
+}
+
+// closeForm goes back to the list.
+func (m *Model) closeForm() tea.Cmd {
+	m.form = false
+	m.inputs[m.formFocus].Blur()
+	return m.filter.Focus()
+}
+
+func (m Model) updateForm(key tea.KeyMsg) (Model, tea.Cmd) {
+	switch key.String() {
+	case "esc":
+		return m, m.closeForm()
+	case "tab", "down":
+		return m, m.moveFocus(1)
+	case "shift+tab", "up":
+		return m, m.moveFocus(-1)
+	case "enter", "ctrl+s":
+		return m.saveForm()
+	}
+	var cmd tea.Cmd
+	m.inputs[m.formFocus], cmd = m.inputs[m.formFocus].Update(key)
+	return m, cmd
+}
+
+// moveFocus moves the form focus by delta fields, wrapping around.
+func (m *Model) moveFocus(delta int) tea.Cmd {
+	m.inputs[m.formFocus].Blur()
+	m.formFocus = (m.formFocus + delta + fieldCount) % fieldCount
+	return m.inputs[m.formFocus].Focus()
+}
+
+// saveForm validates the form and saves the query, replacing the one being
+// edited.
+func (m Model) saveForm() (Model, tea.Cmd) {
+	every, err := parseEvery(m.inputs[fieldEvery].Value())
+	if err != nil {
+		m.formErr = err.Error()
+		return m, nil
+	}
+	q := library.Normalize(library.Query{
+		Name:        m.inputs[fieldName].Value(),
+		Folder:      m.inputs[fieldFolder].Value(),
+		Description: m.inputs[fieldDescription].Value(),
+		Tags:        library.ParseTags(m.inputs[fieldTags].Value()),
+		SQL:         m.formSQL,
+		Every:       every,
+		Connection:  m.inputs[fieldConnection].Value(),
+	})
+	if err := library.Validate(q); err != nil {
+		m.formErr = err.Error()
+		return m, nil
+	}
+	if q.Path() != m.editing {
+		for _, existing := range m.queries {
+			if !existing.ReadOnly() && existing.Path() == q.Path() {
+				m.formErr = fmt.Sprintf("%q already exists", q.Path())
+				return m, nil
+			}
+		}
+	}
+
+	queries := m.queries
+	if m.editing != "" {
+		queries = library.Remove(queries, m.editing)
+	}
+	m.queries = library.Put(queries, q)
+	cmd := m.closeForm()
+	m.applyFilter()
+	m.selectPath(q.Path())
+	return m, tea.Batch(cmd, m.updated("Saved "+q.Path()))
+}
+
+// parseEvery reads the interval of the form: a duration such as "1h30m",
+// or a number of minutes. Empty is no schedule.
+func parseEvery(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return time.Duration(n) * time.Minute, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("run every: %q is not a duration such as 15m", s)
+	}
+	return d, nil
+}
+
+// formatEvery writes an interval the way parseEvery reads it, without
+// the zero units time.Duration prints.
+func formatEvery(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// updated returns a command reporting the changed library.
+func (m Model) updated(notice string) tea.Cmd {
+	queries := make([]library.Query, len(m.queries))
+	copy(queries, m.queries)
+	return func() tea.Msg { return LibraryUpdatedMsg{Queries: queries, Notice: notice} }
+}
+
+// selected returns the query under the cursor.
+func (m Model) selected() (library.Query, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.items) {
+		return library.Query{}, false
+	}
+	return m.queries[m.items[m.cursor]], true
+}
+
+// selectPath moves the cursor to the personal query at path, if it is
+// listed.
+func (m *Model) selectPath(path string) {
+	for pos, i := range m.items {
+		if q := m.queries[i]; !q.ReadOnly() && q.Path() == path {
+			m.cursor = pos
+			m.ensureVisible()
+			return
+		}
+	}
+}
+
+// applyFilter lists the queries matching every typed word, keeping their
+// folder order. A word starting with # matches a tag exactly; any other
+// word matches part of the name, folder, description or a tag.
+func (m *Model) applyFilter() {
+	m.cursor = 0
+	m.offset = 0
+	m.items = m.items[:0]
+	words := strings.Fields(strings.ToLower(m.filter.Value()))
+	for i, q := range m.queries {
+		if matches(q, words) {
+			m.items = append(m.items, i)
+		}
+	}
+}
+
+func matches(q library.Query, words []string) bool {
+	text := strings.ToLower(strings.Join([]string{q.Name, q.Folder, q.Description, strings.Join(q.Tags, " "), q.Source}, " "))
+	for _, w := range words {
+		if tag, ok := strings.CutPrefix(w, "#"); ok && tag != "" {
+			found := false
+			for _, t := range q.Tags {
+				found = found || strings.EqualFold(t, tag)
+			}
+			if !found {
+				return false
+			}
+			continue
+		}
+		if !strings.Contains(text, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// View renders the library.
+func (m Model) View() string {
+	if !m.visible {
+		return ""
+	}
+	if m.form {
+		return m.viewForm()
+	}
+	th := theme.Current
+	w := m.dialogWidth()
+
+	var lines []string
+	group := ""
+	end := min(m.offset+m.visibleCount(), len(m.items))
+	for pos := m.offset; pos < end; pos++ {
+		q := m.queries[m.items[pos]]
+		// Repeat the folder header at the top of a scrolled list.
+		header := groupHeader(q)
+		if header != "" && (header != group || pos == m.offset) {
+			lines = append(lines, th.SidebarSchema.Render("  ▸ "+header))
+		}
+		group = header
+
+		indent := "  "
+		if header != "" {
+			indent = "    "
+		}
+		line := indent + q.Name
+		if q.Description != "" {
+			line += " — " + q.Description
+		}
+		for _, t := range q.Tags {
+			line += " #" + t
+		}
+		mark := ""
+		if q.Scheduled() {
+			mark = fmt.Sprintf(" every %s on %s", formatEvery(q.Every), q.Connection)
+		}
+		if q.ReadOnly() {
+			mark += " read-only"
+		}
+		line = runewidth.Truncate(line, w-6-len(mark), "…")
+		if pos == m.cursor {
+			lines = append(lines, th.SidebarSelected.Render("  "+line)+th.MutedText.Render(mark))
+		} else {
+			lines = append(lines, "  "+line+th.MutedText.Render(mark))
+		}
+	}
+	if len(m.items) == 0 {
+		msg := "  No matching queries"
+		if len(m.queries) == 0 {
+			msg = "  No saved queries (Ctrl+S saves the editor's query)"
+		}
+		lines = append(lines, th.MutedText.Render(msg))
+	}
+
+	var preview []string
+	if q, ok := m.selected(); ok {
+		for i, l := range strings.Split(q.SQL, "\n") {
+			if i == previewLines {
+				preview = append(preview, th.MutedText.Render("  …"))
+				break
+			}
+			preview = append(preview, th.MutedText.Render(runewidth.Truncate("  │ "+l, w-4, "…")))
+		}
+	}
+
+	count := th.MutedText.Render(fmt.Sprintf("  %d queries", len(m.items)))
+	footer := th.MutedText.Render("  enter:insert  ctrl+s:save editor  ctrl+e:edit  ctrl+d:delete  ctrl+r:sync  esc:close")
+	switch {
+	case m.deleting:
+		q, _ := m.selected()
+		footer = th.ErrorText.Render(fmt.Sprintf("  Delete %s? (y/n)", q.Path()))
+	case m.message != "" && m.notice:
+		footer = th.MutedText.Render("  " + m.message)
+	case m.message != "":
+		footer = th.ErrorText.Render("  " + m.message)
+	}
+
+	parts := []string{
+		th.DialogTitle.Render("  Query Library  "),
+		m.filter.View(),
+		"",
+		strings.Join(lines, "\n"),
+		"",
+	}
+	if len(preview) > 0 {
+		parts = append(parts, strings.Join(preview, "\n"), "")
+	}
+	parts = append(parts, count, footer)
+	return th.DialogBorder.Width(w).Render(lipgloss.JoinVertical(lipgloss.Left, parts...))
+}
+
+// groupHeader returns the header a query is listed under: its folder,
+// preceded by the shared library it came from.
+func groupHeader(q library.Query) string {
+	switch {
+	case q.Source == "":
+		return q.Folder
+	case q.Folder == "":
+		return "[" + q.Source + "]"
+	}
+	return "[" + q.Source + "] " + q.Folder
+}
+
+func (m Model) viewForm() string {
+	th := theme.Current
+	w := m.dialogWidth()
+	title := "  Save Query  "
+	if m.editing != "" {
+		title = "  Edit Query  "
+	}
+
+	lines := []string{th.DialogTitle.Render(title), ""}
+	for i := range m.inputs {
+		lines = append(lines, "  "+m.inputs[i].View())
+	}
+	lines = append(lines, "")
+	for i, l := range strings.Split(m.formSQL, "\n") {
+		if i == previewLines {
+			lines = append(lines, th.MutedText.Render("  …"))
+			break
+		}
+		lines = append(lines, th.MutedText.Render(runewidth.Truncate("  │ "+l, w-4, "…")))
+	}
+	if m.formErr != "" {
+		lines = append(lines, "", th.ErrorText.Render("  "+m.formErr))
+	}
+	lines = append(lines, "", th.MutedText.Render("  tab:next field  enter:save  esc:back"))
+	return th.DialogBorder.Width(w).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+func (m Model) dialogWidth() int {
+	w := 80
+	if m.width > 0 && w > m.width-4 {
+		w = m.width - 4
+	}
+	return w
+}
+
+// visibleCount returns how many queries fit in the list. Each may need a
+// folder header line above it.
+func (m Model) visibleCount() int {
+	// Title, filter, blanks, preview, count, help and the border take 14
+	// lines.
+	return max(3, (m.height-14)/2)
+}
+
+func (m *Model) ensureVisible() {
+	visible := m.visibleCount()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+visible {
+		m.offset = m.cursor - visible + 1
+	}
+}
@@ -0,0 +1,303 @@
+package querylib
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/library"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+func init() {
+	theme.Current = theme.Default()
+}
+
+func sample() []library.Query {
+	return []library.Query{
+		{Name: "active users", SQL: "SELECT * FROM users WHERE active"},
+		{Name: "churn", Folder: "reports", Description: "monthly churn", Tags: []string{"sales"}, SQL: "SELECT 1"},
+		{Name: "revenue", Folder: "reports", Tags: []string{"finance"}, SQL: "SELECT 2"},
+	}
+}
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case "ctrl+e":
+		return tea.KeyMsg{Type: tea.KeyCtrlE}
+	case "ctrl+d":
+		return tea.KeyMsg{Type: tea.KeyCtrlD}
+	case "ctrl+s":
+		return tea.KeyMsg{Type: tea.KeyCtrlS}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func names(m Model) []string {
+	var out []string
+	for _, i := range m.items {
+		out = append(out, m.queries[i].Name)
+	}
+	return out
+}
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   string
+	}{
+		{"", "active users,churn,revenue"},
+		{"report", "churn,revenue"},
+		{"MONTHLY", "churn"},
+		{"#finance", "revenue"},
+		{"#fin", ""},
+		{"reports sales", "churn"},
+	}
+	for _, tt := range tests {
+		m := New()
+		m.Show(sample(), "")
+		m, _ = m.Update(key(tt.filter))
+		if got := strings.Join(names(m), ","); got != tt.want {
+			t.Errorf("filter %q = %q, want %q", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestEnterInserts(t *testing.T) {
+	m := New()
+	m.Show(sample(), "")
+	m, _ = m.Update(key("revenue"))
+	m, cmd := m.Update(key("enter"))
+	if m.Visible() {
+		t.Error("expected the library to close")
+	}
+	if msg, ok := cmd().(InsertMsg); !ok || msg.SQL != "SELECT 2" {
+		t.Fatalf("got %#v, want InsertMsg for SELECT 2", cmd())
+	}
+}
+
+func TestSaveEditorQuery(t *testing.T) {
+	m := New()
+	m.Show(sample(), "")
+	m, _ = m.Update(key("ctrl+s"))
+	if m.form || m.message == "" {
+		t.Fatal("expected an empty editor not to open the form")
+	}
+
+	m.Show(sample(), "SELECT 3")
+	m, _ = m.Update(key("ctrl+s"))
+	if !m.form {
+		t.Fatal("expected the form to open")
+	}
+
+	// A name already used in the folder is refused.
+	m, _ = m.Update(key("churn"))
+	m, _ = m.Update(key("tab"))
+	m, _ = m.Update(key("reports"))
+	m, cmd := m.Update(key("enter"))
+	if cmd != nil || !strings.Contains(m.formErr, "already exists") {
+		t.Fatalf("formErr = %q, want a duplicate error", m.formErr)
+	}
+
+	m, _ = m.Update(key("tab"))
+	m, _ = m.Update(key("weekly churn"))
+	m, _ = m.Update(key("tab"))
+	m, _ = m.Update(key("sales, ops"))
+	m.inputs[fieldName].SetValue("weekly")
+	m, cmd = m.Update(key("enter"))
+	if m.form {
+		t.Fatalf("expected the form to close, formErr = %q", m.formErr)
+	}
+	var updated LibraryUpdatedMsg
+	for _, msg := range cmd().(tea.BatchMsg) {
+		if msg == nil {
+			continue
+		}
+		if u, ok := msg().(LibraryUpdatedMsg); ok {
+			updated = u
+		}
+	}
+	if len(updated.Queries) != 4 {
+		t.Fatalf("got %d queries, want 4", len(updated.Queries))
+	}
+	q, _ := m.selected()
+	want := library.Query{Name: "weekly", Folder: "reports", Description: "weekly churn", Tags: []string{"sales", "ops"}, SQL: "SELECT 3"}
+	if q.Path() != want.Path() || q.Description != want.Description || strings.Join(q.Tags, ",") != "sales,ops" || q.SQL != want.SQL {
+		t.Errorf("selected %+v, want %+v", q, want)
+	}
+}
+
+func TestEditRenames(t *testing.T) {
+	m := New()
+	m.Show(sample(), "")
+	m, _ = m.Update(key("churn"))
+	m, _ = m.Update(key("ctrl+e"))
+	if !m.form || m.editing != "reports/churn" {
+		t.Fatalf("form = %v, editing = %q", m.form, m.editing)
+	}
+	m.inputs[fieldName].SetValue("churn rate")
+	m, cmd := m.Update(key("enter"))
+	if cmd == nil {
+		t.Fatalf("expected a save, formErr = %q", m.formErr)
+	}
+	var paths []string
+	for _, q := range m.queries {
+		paths = append(paths, q.Path())
+	}
+	if got := strings.Join(paths, ","); got != "active users,reports/churn rate,reports/revenue" {
+		t.Errorf("paths = %s", got)
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	m := New()
+	m.SetSize(120, 40)
+	m.Show(sample(), "")
+	m, _ = m.Update(key("churn"))
+	m, _ = m.Update(key("ctrl+e"))
+
+	m.inputs[fieldEvery].SetValue("15")
+	m, _ = m.Update(key("enter"))
+	if !m.form || !strings.Contains(m.formErr, "connection") {
+		t.Fatalf("formErr = %q, want a missing connection error", m.formErr)
+	}
+	m.inputs[fieldEvery].SetValue("soon")
+	m.inputs[fieldConnection].SetValue("prod")
+	m, _ = m.Update(key("enter"))
+	if !strings.Contains(m.formErr, "not a duration") {
+		t.Fatalf("formErr = %q, want a bad interval error", m.formErr)
+	}
+	m.inputs[fieldEvery].SetValue("1h")
+	m, _ = m.Update(key("enter"))
+	if m.form {
+		t.Fatalf("expected the form to close, formErr = %q", m.formErr)
+	}
+	q, _ := m.selected()
+	if q.Every != time.Hour || q.Connection != "prod" {
+		t.Errorf("saved every %v on %q, want 1h on prod", q.Every, q.Connection)
+	}
+	if !strings.Contains(m.View(), "every 1h on prod") {
+		t.Error("expected the list to show the schedule")
+	}
+
+	m, _ = m.Update(key("ctrl+e"))
+	if got := m.inputs[fieldEvery].Value(); got != "1h" {
+		t.Errorf("form shows every %q, want 1h", got)
+	}
+}
+
+func TestDeleteConfirms(t *testing.T) {
+	m := New()
+	m.Show(sample(), "")
+	m, _ = m.Update(key("ctrl+d"))
+	if !m.deleting || !strings.Contains(m.View(), "Delete active users?") {
+		t.Fatal("expected a delete confirmation")
+	}
+	m, cmd := m.Update(key("n"))
+	if cmd != nil || len(m.queries) != 3 {
+		t.Fatal("expected n to keep the query")
+	}
+
+	m, _ = m.Update(key("ctrl+d"))
+	m, cmd = m.Update(key("y"))
+	if cmd == nil {
+		t.Fatal("expected y to delete")
+	}
+	if msg := cmd().(LibraryUpdatedMsg); len(msg.Queries) != 2 || msg.Notice != "Deleted active users" {
+		t.Errorf("got %+v", msg)
+	}
+}
+
+func TestViewGroupsByFolder(t *testing.T) {
+	m := New()
+	m.SetSize(100, 40)
+	m.Show(sample(), "")
+	view := m.View()
+	if strings.Count(view, "▸ reports") != 1 {
+		t.Errorf("expected one reports folder header:\n%s", view)
+	}
+	for _, want := range []string{"churn — monthly churn #sales", "│ SELECT * FROM users WHERE active"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+}
+
+func withShared() []library.Query {
+	queries := sample()
+	queries = append(queries, library.Query{Name: "orders", Folder: "ops", SQL: "SELECT 3", Source: "team"})
+	library.Sort(queries)
+	return queries
+}
+
+func TestSharedReadOnly(t *testing.T) {
+	m := New()
+	m.SetSize(100, 40)
+	m.Show(withShared(), "")
+	m.filter.SetValue("orders")
+	m.applyFilter()
+
+	view := m.View()
+	for _, want := range []string{"▸ [team] ops", "orders", "read-only"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q:\n%s", want, view)
+		}
+	}
+
+	m, _ = m.Update(key("ctrl+d"))
+	if m.deleting || !strings.Contains(m.View(), "ops/orders is shared from team and read-only") {
+		t.Fatal("expected deleting a shared query to be refused")
+	}
+
+	// Editing a shared query saves a personal copy.
+	m, _ = m.Update(key("ctrl+e"))
+	if !m.form || m.editing != "" {
+		t.Fatalf("form = %v, editing = %q; want a new query form", m.form, m.editing)
+	}
+	m, cmd := m.Update(key("enter"))
+	if cmd == nil {
+		t.Fatal("expected saving the copy to update the library")
+	}
+	var updated LibraryUpdatedMsg
+	for _, msg := range cmd().(tea.BatchMsg) {
+		if msg == nil {
+			continue
+		}
+		if u, ok := msg().(LibraryUpdatedMsg); ok {
+			updated = u
+		}
+	}
+	var personal, shared int
+	for _, q := range updated.Queries {
+		if q.Path() == "ops/orders" {
+			if q.ReadOnly() {
+				shared++
+			} else {
+				personal++
+			}
+		}
+	}
+	if personal != 1 || shared != 1 {
+		t.Errorf("got %d personal and %d shared ops/orders, want 1 of each", personal, shared)
+	}
+}
+
+func TestSyncKey(t *testing.T) {
+	m := New()
+	m.Show(sample(), "")
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if cmd == nil {
+		t.Fatal("expected ctrl+r to sync")
+	}
+	if _, ok := cmd().(SyncMsg); !ok {
+		t.Fatal("expected a SyncMsg")
+	}
+	if !strings.Contains(m.View(), "Syncing shared libraries") {
+		t.Error("expected a syncing notice")
+	}
+}
@@ -0,0 +1,94 @@
+package results
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/ui/format"
+)
+
+// maxStatValueWidth caps min/max values of text columns in the footer.
+const maxStatValueWidth = 20
+
+// colStats summarises one column over the displayed rows.
+type colStats struct {
+	count   int     // non-NULL values
+	nulls   int     // NULL values
+	numeric bool    // every non-NULL value parses as a number
+	sum     float64 // sum of values (numeric columns only)
+	min     string
+	max     string
+}
+
+// columnStats computes count/sum/min/max of column col over rows. Min and
+// max use the same ordering as the column sort.
+func columnStats(rows [][]string, col int) colStats {
+	s := colStats{numeric: true}
+	for _, row := range rows {
+		v := cellAt(row, col)
+		if adapter.IsNull(v) {
+			s.nulls++
+			continue
+		}
+		if s.count == 0 || compareCells(v, s.min) < 0 {
+			s.min = v
+		}
+		if s.count == 0 || compareCells(v, s.max) > 0 {
+			s.max = v
+		}
+		s.count++
+		if !s.numeric {
+			continue
+		}
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			s.sum += f
+		} else {
+			s.numeric = false
+		}
+	}
+	if s.count == 0 {
+		s.numeric = false
+	}
+	return s
+}
+
+// String formats the statistics for the footer, e.g.
+// "count 12 (3 null) | sum 340 | avg 28.33 | min 1 | max 90".
+func (s colStats) String() string {
+	count := fmt.Sprintf("count %d", s.count)
+	if s.nulls > 0 {
+		count += fmt.Sprintf(" (%d null)", s.nulls)
+	}
+	parts := []string{count}
+	if s.count == 0 {
+		return count
+	}
+	if s.numeric {
+		parts = append(parts,
+			"sum "+formatStat(s.sum),
+			"avg "+formatStat(s.sum/float64(s.count)))
+	}
+	parts = append(parts,
+		"min "+runewidth.Truncate(format.OneLine(s.min), maxStatValueWidth, "…"),
+		"max "+runewidth.Truncate(format.OneLine(s.max), maxStatValueWidth, "…"))
+	return strings.Join(parts, " | ")
+}
+
+// formatStat formats a computed number with at most four decimals.
+func formatStat(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1e4)/1e4, 'f', -1, 64)
+}
+
+// aggregateNote returns the footer text for the selected column's
+// aggregates, or "" when they are off.
+func (m Model) aggregateNote() string {
+	src := m.srcCol(m.colCursor)
+	if !m.aggregate || src < 0 {
+		return ""
+	}
+	return "Σ " + m.columns[src].Name + ": " + columnStats(m.rows, src).String()
+}
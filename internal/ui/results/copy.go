@@ -0,0 +1,147 @@
+package results
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	appmsg "github.com/sadopc/gotermsql/internal/msg"
+)
+
+// writeClipboard is swapped out in tests.
+var writeClipboard = clipboard.WriteAll
+
+// copyMenuHint is shown in the footer while the copy sub-menu is open.
+const copyMenuHint = "copy: [c]ell  row as [t]sv / [v]csv / [j]son  c[o]lumn  esc cancel"
+
+// updateCopyMenu handles the key pressed after "y". Any key closes the menu;
+// unrecognised keys simply cancel it.
+func (m Model) updateCopyMenu(msg tea.KeyMsg) (Model, tea.Cmd) {
+	m.copyMenu = false
+
+	row := m.currentRow()
+	var (
+		text, what string
+		err        error
+	)
+	switch key := msg.String(); key {
+	case "c":
+		text, what = m.copyCell()
+	case "o":
+		text, what = m.copyColumn()
+	case "t", "v", "j":
+		if row == nil {
+			break
+		}
+		switch key {
+		case "t":
+			text, what = strings.Join(plainRow(row), "\t"), "row as TSV"
+		case "v":
+			text, err = rowCSV(row)
+			what = "row as CSV"
+		case "j":
+			text, err = rowJSON(m.columns, row)
+			what = "row as JSON"
+		}
+	default:
+		return m, nil
+	}
+	if err != nil {
+		return m, statusCmd("Copy failed: "+err.Error(), true)
+	}
+	if what == "" {
+		return m, statusCmd("Nothing to copy", true)
+	}
+	return m, copyToClipboard(text, what)
+}
+
+// currentRow returns the row under the cursor, or nil if there is none.
+func (m Model) currentRow() []string {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.rows) {
+		return nil
+	}
+	return m.rows[cursor]
+}
+
+func (m Model) copyCell() (string, string) {
+	row := m.currentRow()
+	src := m.srcCol(m.colCursor)
+	if row == nil || src < 0 {
+		return "", ""
+	}
+	return plainCell(cellAt(row, src)), "cell " + m.columns[src].Name
+}
+
+// copyColumn returns the selected column's values for the visible rows,
+// one per line.
+func (m Model) copyColumn() (string, string) {
+	src := m.srcCol(m.colCursor)
+	if len(m.rows) == 0 || src < 0 {
+		return "", ""
+	}
+	vals := make([]string, len(m.rows))
+	for i, row := range m.rows {
+		vals[i] = plainCell(cellAt(row, src))
+	}
+	return strings.Join(vals, "\n"), fmt.Sprintf("column %s (%d values)", m.columns[src].Name, len(vals))
+}
+
+// rowCSV encodes a single row as a CSV record without a trailing newline.
+func rowCSV(row []string) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(plainRow(row)); err != nil {
+		return "", err
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(buf.String(), "\r\n"), nil
+}
+
+// rowJSON encodes a row as a JSON object, keeping keys in column order.
+// SQL NULL is encoded as null.
+func rowJSON(columns []adapter.ColumnMeta, row []string) (string, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, c := range columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(c.Name)
+		if err != nil {
+			return "", err
+		}
+		v, err := json.Marshal(jsonCell(cellAt(row, i)))
+		if err != nil {
+			return "", err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.String(), nil
+}
+
+// copyToClipboard returns a command that writes text to the system
+// clipboard and reports the outcome in the status bar.
+func copyToClipboard(text, what string) tea.Cmd {
+	return func() tea.Msg {
+		if err := writeClipboard(text); err != nil {
+			return appmsg.StatusMsg{Text: "Copy failed: " + err.Error(), IsError: true}
+		}
+		return appmsg.StatusMsg{Text: "Copied " + what}
+	}
+}
+
+func statusCmd(text string, isErr bool) tea.Cmd {
+	return func() tea.Msg { return appmsg.StatusMsg{Text: text, IsError: isErr} }
+}
@@ -0,0 +1,96 @@
+package results
+
+import (
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+)
+
+// EditCellMsg asks the app to write a new value for one cell back to the
+// database. The app decides whether the result is editable (single table,
+// primary key present) and confirms the generated UPDATE before running it.
+type EditCellMsg struct {
+	TabID   int
+	Row     []string // the edited row as currently displayed
+	Column  int      // source column index
+	Value   string
+	SetNull bool // write NULL instead of Value
+}
+
+// Editing reports whether the cell editor currently has keyboard focus.
+func (m Model) Editing() bool {
+	return m.editing
+}
+
+// startEdit opens the cell editor on the selected cell.
+func (m *Model) startEdit() tea.Cmd {
+	row := m.currentRow()
+	src := m.srcCol(m.colCursor)
+	if row == nil || src < 0 {
+		return nil
+	}
+	if m.ViewingHistory() {
+		return statusCmd("Earlier results are read-only; re-run the query to edit", true)
+	}
+	m.editing = true
+	m.editBox.Prompt = m.columns[src].Name + ": "
+	m.editBox.SetValue(plainCell(cellAt(row, src)))
+	m.editBox.CursorEnd()
+	return m.editBox.Focus()
+}
+
+// updateEdit handles key presses while the cell editor is focused. Enter
+// submits the value, Ctrl+N submits NULL, and Esc cancels.
+func (m Model) updateEdit(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.stopEdit()
+		return m, nil
+	case "enter", "ctrl+n":
+		edit := EditCellMsg{
+			TabID:   m.tabID,
+			Row:     m.currentRow(),
+			Column:  m.srcCol(m.colCursor),
+			Value:   m.editBox.Value(),
+			SetNull: msg.String() == "ctrl+n",
+		}
+		m.stopEdit()
+		if edit.Row == nil || edit.Column < 0 {
+			return m, nil
+		}
+		return m, func() tea.Msg { return edit }
+	}
+
+	var cmd tea.Cmd
+	m.editBox, cmd = m.editBox.Update(msg)
+	return m, cmd
+}
+
+func (m *Model) stopEdit() {
+	m.editing = false
+	m.editBox.Blur()
+	m.editBox.SetValue("")
+}
+
+// ApplyEdit stores a value the database accepted for an edited cell and
+// refreshes the view so sorting and filtering see the new value.
+func (m *Model) ApplyEdit(e EditCellMsg) {
+	if e.Column < 0 || e.Column >= len(e.Row) {
+		return
+	}
+	// Rows share their backing arrays between allRows and the derived
+	// view, so updating the row in place updates both.
+	if e.SetNull {
+		e.Row[e.Column] = adapter.NullValue
+	} else {
+		e.Row[e.Column] = e.Value
+	}
+	m.applyView()
+}
+
+// newEditInput builds the text input used for in-place cell editing.
+func newEditInput() textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = "new value (enter to save, ctrl+n for NULL)"
+	return ti
+}
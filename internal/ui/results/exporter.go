@@ -10,7 +10,8 @@ import (
 	"github.com/sadopc/gotermsql/internal/adapter"
 )
 
-// ExportCSV writes the given columns and rows to a CSV file at path.
+// ExportCSV writes the given columns and rows to a CSV file at path. SQL
+// NULL is written as an empty field.
 func ExportCSV(path string, columns []adapter.ColumnMeta, rows [][]string) error {
 	f, err := os.Create(path)
 	if err != nil {
@@ -31,7 +32,7 @@ func ExportCSV(path string, columns []adapter.ColumnMeta, rows [][]string) error
 
 	// Write data rows.
 	for _, row := range rows {
-		if err := w.Write(row); err != nil {
+		if err := w.Write(plainRow(row)); err != nil {
 			return err
 		}
 	}
@@ -41,7 +42,8 @@ func ExportCSV(path string, columns []adapter.ColumnMeta, rows [][]string) error
 }
 
 // ExportJSON writes the given columns and rows as a JSON array of objects
-// to a file at path. Each object maps column names to string values.
+// to a file at path. Each object maps column names to string values, with
+// SQL NULL as null.
 func ExportJSON(path string, columns []adapter.ColumnMeta, rows [][]string) error {
 	f, err := os.Create(path)
 	if err != nil {
@@ -59,12 +61,12 @@ func ExportJSON(path string, columns []adapter.ColumnMeta, rows [][]string) erro
 
 	// Build the full array so the output is a proper JSON array.
 	// For in-memory exports the data fits in memory by definition.
-	objects := make([]map[string]string, 0, len(rows))
+	objects := make([]map[string]any, 0, len(rows))
 	for _, row := range rows {
-		obj := make(map[string]string, len(colNames))
+		obj := make(map[string]any, len(colNames))
 		for j, name := range colNames {
 			if j < len(row) {
-				obj[name] = row[j]
+				obj[name] = jsonCell(row[j])
 			} else {
 				obj[name] = ""
 			}
@@ -115,7 +117,7 @@ func ExportCSVFromIterator(ctx context.Context, path string, iter adapter.RowIte
 		}
 
 		for _, row := range rows {
-			if writeErr := w.Write(row); writeErr != nil {
+			if writeErr := w.Write(plainRow(row)); writeErr != nil {
 				w.Flush()
 				return count, writeErr
 			}
@@ -176,10 +178,10 @@ func ExportJSONFromIterator(ctx context.Context, path string, iter adapter.RowIt
 		}
 
 		for _, row := range rows {
-			obj := make(map[string]string, len(colNames))
+			obj := make(map[string]any, len(colNames))
 			for j, name := range colNames {
 				if j < len(row) {
-					obj[name] = row[j]
+					obj[name] = jsonCell(row[j])
 				} else {
 					obj[name] = ""
 				}
@@ -282,6 +282,43 @@ func TestExportJSON_NullValues(t *testing.T) {
 	}
 }
 
+func TestExport_SQLNull(t *testing.T) {
+	dir := t.TempDir()
+	cols := columns("id", "bio")
+	rows := [][]string{{"1", adapter.NullValue}, {"2", ""}}
+
+	jsonPath := filepath.Join(dir, "nulls.json")
+	if err := ExportJSON(jsonPath, cols, rows); err != nil {
+		t.Fatalf("ExportJSON failed: %v", err)
+	}
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+	var objects []map[string]any
+	if err := json.Unmarshal(data, &objects); err != nil {
+		t.Fatalf("parse JSON: %v", err)
+	}
+	if objects[0]["bio"] != nil {
+		t.Errorf("NULL bio = %#v, want null", objects[0]["bio"])
+	}
+	if objects[1]["bio"] != "" {
+		t.Errorf("empty bio = %#v, want \"\"", objects[1]["bio"])
+	}
+
+	csvPath := filepath.Join(dir, "nulls.csv")
+	if err := ExportCSV(csvPath, cols, rows); err != nil {
+		t.Fatalf("ExportCSV failed: %v", err)
+	}
+	data, err = os.ReadFile(csvPath)
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+	if got, want := string(data), "id,bio\n1,\n2,\n"; got != want {
+		t.Errorf("CSV = %q, want %q", got, want)
+	}
+}
+
 func TestExportJSON_RowShorterThanColumns(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "short_row.json")
@@ -0,0 +1,157 @@
+package results
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+)
+
+// rowFilter matches rows against a user-supplied filter expression.
+//
+// The expression is a case-insensitive substring by default. Wrapping it in
+// slashes (/^a.*z$/) makes it a regular expression, and prefixing it with a
+// column name and a colon (email:@example.com) restricts matching to that
+// column.
+type rowFilter struct {
+	col  int // column to match (-1 = any column)
+	text string
+	re   *regexp.Regexp
+}
+
+// parseFilter compiles a filter expression for the given column names. An
+// empty expression returns a nil filter.
+func parseFilter(expr string, columns []string) (*rowFilter, error) {
+	if expr == "" {
+		return nil, nil
+	}
+
+	f := &rowFilter{col: -1}
+	term := expr
+	if i := strings.Index(expr, ":"); i > 0 {
+		name := expr[:i]
+		for j, c := range columns {
+			if strings.EqualFold(c, name) {
+				f.col = j
+				term = expr[i+1:]
+				break
+			}
+		}
+	}
+
+	if len(term) >= 2 && strings.HasPrefix(term, "/") && strings.HasSuffix(term, "/") {
+		re, err := regexp.Compile("(?i)" + term[1:len(term)-1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex: %w", err)
+		}
+		f.re = re
+		return f, nil
+	}
+	f.text = strings.ToLower(term)
+	return f, nil
+}
+
+// match reports whether row satisfies the filter.
+func (f *rowFilter) match(row []string) bool {
+	if f.col >= 0 {
+		return f.matchCell(cellAt(row, f.col))
+	}
+	for _, cell := range row {
+		if f.matchCell(cell) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchCell matches one cell. SQL NULL matches as the text NULL, whatever
+// marker the grid draws for it.
+func (f *rowFilter) matchCell(cell string) bool {
+	if adapter.IsNull(cell) {
+		cell = "NULL"
+	}
+	if f.re != nil {
+		return f.re.MatchString(cell)
+	}
+	return strings.Contains(strings.ToLower(cell), f.text)
+}
+
+// filterIndices returns the indices of rows that satisfy f. A nil filter
+// matches every row.
+func filterIndices(rows [][]string, f *rowFilter) []int {
+	out := make([]int, 0, len(rows))
+	for i, row := range rows {
+		if f == nil || f.match(row) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// Filtering reports whether the filter input currently has keyboard focus.
+// While it does, the parent should route all text keys to the results pane.
+func (m Model) Filtering() bool {
+	return m.filtering
+}
+
+// Filter returns the active filter expression.
+func (m Model) Filter() string {
+	return m.filter
+}
+
+// startFilter focuses the filter input, pre-filled with the active filter.
+func (m *Model) startFilter() tea.Cmd {
+	m.filtering = true
+	m.filterBox.SetValue(m.filter)
+	m.filterBox.CursorEnd()
+	return m.filterBox.Focus()
+}
+
+// clearFilter removes the active filter and closes the filter input.
+func (m *Model) clearFilter() {
+	m.filtering = false
+	m.filterBox.Blur()
+	m.filterBox.SetValue("")
+	m.setFilter("")
+}
+
+// setFilter applies a new filter expression and recomputes the view. An
+// invalid expression is reported in the footer and leaves all rows visible.
+func (m *Model) setFilter(expr string) {
+	if expr == m.filter {
+		return
+	}
+	m.filter = expr
+	m.table.SetCursor(0)
+	m.viewTop = 0
+	m.applyView()
+}
+
+// updateFilter handles key presses while the filter input is focused.
+func (m Model) updateFilter(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.clearFilter()
+		return m, nil
+	case "enter":
+		m.filtering = false
+		m.filterBox.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.filterBox, cmd = m.filterBox.Update(msg)
+	m.setFilter(m.filterBox.Value())
+	return m, cmd
+}
+
+// newFilterInput builds the text input used for the "/" filter prompt.
+func newFilterInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.Placeholder = "filter rows (col:text, /regex/)"
+	return ti
+}
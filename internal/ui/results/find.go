@@ -0,0 +1,113 @@
+package results
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// FindMsg asks the app to search a streaming result on the server, since
+// the client-side filter only sees the buffered rows.
+type FindMsg struct {
+	TabID  int
+	Column string
+	Text   string
+	After  int64 // search rows after this 1-based row number
+}
+
+// FoundRowMsg answers a FindMsg with the 1-based row number of the first
+// match, or 0 when nothing matches.
+type FoundRowMsg struct {
+	TabID int
+	Text  string
+	Row   int64
+	Err   error
+}
+
+// findCommand runs a "find TEXT" entered at the ":" prompt. It reports
+// whether text was such a command.
+func (m *Model) findCommand(text string) (tea.Cmd, bool) {
+	rest, ok := strings.CutPrefix(text, "find ")
+	if !ok {
+		return nil, false
+	}
+	rest = strings.TrimSpace(rest)
+	if rest == "" {
+		return nil, true
+	}
+	m.findText = rest
+	return m.find(0), true
+}
+
+// findNext repeats the last search, starting after the selected row.
+func (m *Model) findNext() tea.Cmd {
+	if m.findText == "" {
+		return nil
+	}
+	return m.find(m.cursorRowNumber())
+}
+
+// find searches the selected column for the last search text, after the
+// 1-based row number after. Complete results are searched in memory;
+// streaming results are searched by the server through FindMsg.
+func (m *Model) find(after int64) tea.Cmd {
+	src := m.srcCol(m.colCursor)
+	if src < 0 {
+		return nil
+	}
+	if m.iterator != nil {
+		msg := FindMsg{TabID: m.tabID, Column: m.columns[src].Name, Text: m.findText, After: after}
+		return func() tea.Msg { return msg }
+	}
+
+	needle := strings.ToLower(m.findText)
+	start := 0
+	if after > 0 {
+		start = m.table.Cursor() + 1
+	}
+	for i := start; i < len(m.rows); i++ {
+		if strings.Contains(strings.ToLower(m.cellText(cellAt(m.rows[i], src))), needle) {
+			m.table.SetCursor(i)
+			m.updateViewTop()
+			return nil
+		}
+	}
+	return m.noMatch()
+}
+
+// cursorRowNumber returns the 1-based absolute row number of the selected
+// row, or 0 when no row is selected.
+func (m Model) cursorRowNumber() int64 {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.rows) {
+		return 0
+	}
+	src := cursor
+	if cursor < len(m.rowIndex) {
+		src = m.rowIndex[cursor]
+	}
+	return int64(m.offset + src + 1)
+}
+
+// applyFound jumps to the row a server-side search found.
+func (m *Model) applyFound(msg FoundRowMsg) tea.Cmd {
+	switch {
+	case msg.Err != nil:
+		return statusCmd("Search failed: "+msg.Err.Error(), true)
+	case msg.Row < 1:
+		return m.noMatch()
+	}
+	if cmd := m.goToRow(msg.Row); cmd != nil {
+		return cmd
+	}
+	return statusCmd(fmt.Sprintf("Match at row %d", msg.Row), false)
+}
+
+func (m Model) noMatch() tea.Cmd {
+	col := ""
+	if src := m.srcCol(m.colCursor); src >= 0 {
+		col = m.columns[src].Name
+	}
+	return statusCmd(fmt.Sprintf("No more matches for %q in %s", m.findText, col), true)
+}
@@ -0,0 +1,142 @@
+package results
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/ui/format"
+)
+
+// colKind classifies a column for alignment and truncation.
+type colKind int
+
+const (
+	kindText   colKind = iota
+	kindNumber         // right-aligned
+	kindTime           // left-aligned, never truncated from the middle
+	kindID             // long values are truncated in the middle
+	kindBinary         // raw bytes, shown as a hex preview
+)
+
+// numericTypes and timeTypes are database type names (lower case, without
+// length or precision) across the supported adapters.
+var (
+	numericTypes = map[string]bool{
+		"int": true, "integer": true, "int2": true, "int4": true, "int8": true,
+		"smallint": true, "mediumint": true, "bigint": true, "tinyint": true,
+		"hugeint": true, "utinyint": true, "usmallint": true, "uinteger": true,
+		"ubigint": true, "serial": true, "bigserial": true, "smallserial": true,
+		"decimal": true, "numeric": true, "real": true, "double": true,
+		"double precision": true, "float": true, "float4": true, "float8": true,
+		"money": true, "oid": true, "year": true,
+	}
+	timeTypes = map[string]bool{
+		"date": true, "time": true, "timetz": true, "timestamp": true,
+		"timestamptz": true, "datetime": true, "timestamp with time zone": true,
+		"timestamp without time zone": true, "time with time zone": true,
+		"time without time zone": true, "timestamp_s": true, "timestamp_ms": true,
+		"timestamp_ns": true,
+	}
+)
+
+// baseType normalises a database type name: lower case, without a length
+// or precision suffix and without MySQL's UNSIGNED marker.
+func baseType(t string) string {
+	t = strings.ToLower(strings.TrimSpace(t))
+	if i := strings.IndexByte(t, '('); i >= 0 {
+		t = strings.TrimSpace(t[:i])
+	}
+	t = strings.TrimPrefix(t, "unsigned ")
+	return strings.TrimSuffix(t, " unsigned")
+}
+
+// columnKind classifies column col from its declared type. Columns without
+// a type (e.g. SQLite expressions) count as numeric when every non-NULL
+// value in sample parses as a number.
+func columnKind(c adapter.ColumnMeta, sample [][]string, col int) colKind {
+	t := baseType(c.Type)
+	switch {
+	case numericTypes[t]:
+		return kindNumber
+	case timeTypes[t]:
+		return kindTime
+	case t == "uuid" || t == "uniqueidentifier":
+		return kindID
+	case binaryTypes[t]:
+		return kindBinary
+	case t == "" && len(sample) > 0 && columnStats(sample, col).numeric:
+		return kindNumber
+	}
+	name := strings.ToLower(c.Name)
+	if name == "id" || strings.HasSuffix(name, "_id") || strings.HasSuffix(name, "uuid") || strings.HasSuffix(name, "guid") {
+		return kindID
+	}
+	return kindText
+}
+
+// kindOf returns the kind of source column src.
+func (m Model) kindOf(src int) colKind {
+	if src >= 0 && src < len(m.kinds) {
+		return m.kinds[src]
+	}
+	return kindText
+}
+
+// displayCell returns the single-line text shown for a cell of source
+// column src. Binary values become a hex preview so raw bytes never reach
+// the terminal.
+func (m Model) displayCell(value string, src int) string {
+	if !adapter.IsNull(value) && (m.kindOf(src) == kindBinary || isBinary(value)) {
+		return hexPreview(value)
+	}
+	return format.OneLine(m.cellText(value))
+}
+
+// formatCell renders a cell value of source column src at exactly width
+// display cells, aligned and truncated according to the column kind.
+func (m Model) formatCell(value string, src, width int) string {
+	text := m.displayCell(value, src)
+	kind := m.kindOf(src)
+	if kind == kindID && !adapter.IsNull(value) {
+		return padRight(truncateMiddle(text, width), width)
+	}
+	text = runewidth.Truncate(text, width, "…")
+	if kind == kindNumber {
+		return padLeft(text, width)
+	}
+	return padRight(text, width)
+}
+
+// truncateMiddle shortens s to width display cells by replacing its middle
+// with "…", keeping both ends of long identifiers readable.
+func truncateMiddle(s string, width int) string {
+	if runewidth.StringWidth(s) <= width {
+		return s
+	}
+	if width < 3 {
+		return runewidth.Truncate(s, width, "")
+	}
+	tailW := (width - 1) / 2
+	headW := width - 1 - tailW
+	head := runewidth.Truncate(s, headW, "")
+	runes := []rune(s)
+	tail := ""
+	for i := len(runes) - 1; i >= 0; i-- {
+		next := string(runes[i]) + tail
+		if runewidth.StringWidth(next) > tailW {
+			break
+		}
+		tail = next
+	}
+	return head + "…" + tail
+}
+
+// padLeft pads s with spaces on the left so its display width equals w.
+func padLeft(s string, w int) string {
+	sw := runewidth.StringWidth(s)
+	if sw >= w {
+		return s
+	}
+	return strings.Repeat(" ", w-sw) + s
+}
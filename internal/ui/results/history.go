@@ -0,0 +1,149 @@
+package results
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/ui/format"
+)
+
+// defaultHistorySize is the number of earlier results kept per tab.
+const defaultHistorySize = 10
+
+// snapshot is an earlier result of the tab, kept so it can be shown again
+// without re-running its query.
+type snapshot struct {
+	query   string
+	result  *adapter.QueryResult
+	ranAt   time.Time
+	partial bool // streamed result: only the buffered rows were kept
+}
+
+// SetHistorySize sets how many earlier results the tab keeps. Zero turns
+// result history off.
+func (m *Model) SetHistorySize(n int) {
+	if n < 0 {
+		n = 0
+	}
+	m.histMax = n
+	m.trimHistory()
+}
+
+// SetQuery records the query that produced the current result, so the
+// result history can show it. Call it after SetResults or SetIterator.
+func (m *Model) SetQuery(query string) {
+	m.query = query
+}
+
+// ViewingHistory reports whether an earlier result is shown instead of the
+// latest one. Earlier results are read-only.
+func (m Model) ViewingHistory() bool {
+	return m.histPos < len(m.hist)
+}
+
+// remember saves the result on screen before a new one replaces it. A past
+// result on screen is already in the history.
+func (m *Model) remember() {
+	if !m.ViewingHistory() {
+		if s, ok := m.capture(); ok {
+			m.hist = append(m.hist, s)
+		}
+	}
+	m.trimHistory()
+	m.histPos = len(m.hist)
+	m.partial = false
+	m.autoLimit = 0
+	m.ranAt = time.Now()
+}
+
+// capture freezes the current result. A streaming result keeps only its
+// buffered rows.
+func (m *Model) capture() (snapshot, bool) {
+	if m.histMax == 0 || m.err != nil || (len(m.columns) == 0 && m.message == "") {
+		return snapshot{}, false
+	}
+	res := &adapter.QueryResult{
+		Columns:  m.columns,
+		Rows:     m.allRows,
+		RowCount: m.totalRows,
+		Duration: m.queryTime,
+		IsSelect: len(m.columns) > 0,
+		Message:  m.message,
+	}
+	s := snapshot{query: m.query, result: res, ranAt: m.ranAt, partial: m.partial}
+	if m.iterator != nil {
+		res.RowCount = int64(len(m.allRows))
+		s.partial = true
+	}
+	return s, true
+}
+
+// trimHistory drops the oldest results beyond the history size.
+func (m *Model) trimHistory() {
+	excess := len(m.hist) - m.histMax
+	if excess <= 0 {
+		return
+	}
+	m.hist = m.hist[excess:]
+	m.histPos -= excess
+	if m.histPos < 0 {
+		m.histPos = 0
+	}
+}
+
+// showOlder steps back to the previous result. Leaving the latest result
+// freezes it into the history, closing its iterator.
+func (m *Model) showOlder() tea.Cmd {
+	if m.histMax == 0 {
+		return statusCmd("Result history is off (results.result_history)", true)
+	}
+	if m.histPos == 0 || len(m.hist) == 0 {
+		return statusCmd("No earlier results in this tab", true)
+	}
+	if !m.ViewingHistory() {
+		// The history may briefly hold one extra entry; remember trims it.
+		if s, ok := m.capture(); ok {
+			m.hist = append(m.hist, s)
+			m.histPos = len(m.hist) - 1
+		}
+	}
+	m.histPos--
+	m.show(m.hist[m.histPos])
+	return nil
+}
+
+// showNewer steps forward to the next result.
+func (m *Model) showNewer() tea.Cmd {
+	if m.histPos >= len(m.hist)-1 {
+		return statusCmd("Already at the latest result", true)
+	}
+	m.histPos++
+	m.show(m.hist[m.histPos])
+	return nil
+}
+
+// show loads a snapshot without adding to the history.
+func (m *Model) show(s snapshot) {
+	m.load(s.result)
+	m.query = s.query
+	m.ranAt = s.ranAt
+	m.partial = s.partial
+}
+
+// historyNote returns the footer note for a past result, or "".
+func (m Model) historyNote() string {
+	if !m.ViewingHistory() {
+		return ""
+	}
+	note := fmt.Sprintf("result %d of %d, %s", m.histPos+1, len(m.hist), m.ranAt.Format("15:04:05"))
+	if m.partial {
+		note += ", buffered rows only"
+	}
+	if m.query != "" {
+		note += ": " + runewidth.Truncate(format.OneLine(m.query), 40, "…")
+	}
+	return note
+}
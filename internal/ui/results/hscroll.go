@@ -0,0 +1,134 @@
+package results
+
+import "fmt"
+
+// minPartialWidth is the narrowest a trailing, partially visible column is
+// drawn. Narrower leftovers are left blank rather than showing "…".
+const minPartialWidth = 3
+
+// renderCol is a column as drawn in the current horizontal viewport.
+type renderCol struct {
+	idx   int // display index into m.tableCols
+	width int // content width, excluding padding (may be less than natural)
+}
+
+// pinnedCount returns how many leading display columns are pinned. Pinned
+// columns are always drawn, regardless of the horizontal scroll offset. With
+// the sticky-first option the first column counts as pinned.
+func (m Model) pinnedCount() int {
+	n := 0
+	for _, src := range m.display {
+		if m.layout.position(src) < m.layout.pinned {
+			n++
+		}
+	}
+	if n == 0 && m.opts.StickyFirst && len(m.display) > 0 {
+		n = 1
+	}
+	return n
+}
+
+// renderedColumns returns the columns that fit in the content width: all
+// pinned columns, then unpinned columns starting at colOffset. The last one
+// may be truncated to fill the remaining space.
+func (m Model) renderedColumns() []renderCol {
+	avail := m.contentWidth() - m.gutterWidth()
+	pinned := m.pinnedCount()
+	var out []renderCol
+	used := 0
+	add := func(i int) bool {
+		w := m.tableCols[i].Width
+		if used+w+2 > avail {
+			if rest := avail - used - 2; rest >= minPartialWidth {
+				out = append(out, renderCol{idx: i, width: rest})
+			}
+			return false
+		}
+		out = append(out, renderCol{idx: i, width: w})
+		used += w + 2 // +2 for Padding(0,1)
+		return true
+	}
+	for i := 0; i < pinned && i < len(m.tableCols); i++ {
+		if !add(i) {
+			return out
+		}
+	}
+	start := m.colOffset
+	if start < pinned {
+		start = pinned
+	}
+	for i := start; i < len(m.tableCols); i++ {
+		if !add(i) {
+			break
+		}
+	}
+	return out
+}
+
+// ensureColVisible scrolls horizontally so the selected column is drawn at
+// its full width (or as wide as the pane allows).
+func (m *Model) ensureColVisible() {
+	pinned := m.pinnedCount()
+	if m.colOffset < pinned {
+		m.colOffset = pinned
+	}
+	if m.colOffset >= len(m.tableCols) {
+		m.colOffset = len(m.tableCols) - 1
+	}
+	if m.colOffset < 0 {
+		m.colOffset = 0
+	}
+	if m.colCursor < pinned {
+		return
+	}
+	if m.colCursor < m.colOffset {
+		m.colOffset = m.colCursor
+		return
+	}
+	for m.colOffset < m.colCursor && !m.fullyVisible(m.colCursor) {
+		m.colOffset++
+	}
+}
+
+// fullyVisible reports whether display column i is drawn untruncated.
+func (m Model) fullyVisible(i int) bool {
+	for _, rc := range m.renderedColumns() {
+		if rc.idx == i {
+			return rc.width == m.tableCols[i].Width
+		}
+	}
+	return false
+}
+
+// scrollIndicator describes the horizontal viewport for the footer, e.g.
+// "◀ cols 4-7 of 12 ▶". It returns "" when every column fits.
+func (m Model) scrollIndicator() string {
+	cols := m.renderedColumns()
+	if len(cols) == 0 {
+		return ""
+	}
+	pinned := m.pinnedCount()
+	last := cols[len(cols)-1]
+	moreLeft := m.colOffset > pinned
+	moreRight := last.idx < len(m.tableCols)-1 || last.width < m.tableCols[last.idx].Width
+	if !moreLeft && !moreRight {
+		return ""
+	}
+
+	// Count from the first scrolled column; pinned ones are always shown.
+	first := cols[0].idx
+	for _, rc := range cols {
+		if rc.idx >= pinned {
+			first = rc.idx
+			break
+		}
+	}
+	s := fmt.Sprintf("cols %d-%d of %d", first+1, last.idx+1, len(m.tableCols))
+	if moreLeft {
+		s = "◀ " + s
+	}
+	if moreRight {
+		s += " ▶"
+	}
+	return s
+}
@@ -0,0 +1,197 @@
+package results
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+// hexPreviewBytes is the number of bytes shown in the grid for binary cells.
+const hexPreviewBytes = 16
+
+// binaryTypes are database type names (see baseType) holding raw bytes.
+var binaryTypes = map[string]bool{
+	"bytea": true, "blob": true, "tinyblob": true, "mediumblob": true,
+	"longblob": true, "binary": true, "varbinary": true, "image": true,
+}
+
+// isBinary reports whether v cannot be printed as text: it is not valid
+// UTF-8 or contains control characters other than tabs and newlines.
+func isBinary(v string) bool {
+	if !utf8.ValidString(v) {
+		return true
+	}
+	for _, r := range v {
+		if unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r' {
+			return true
+		}
+	}
+	return false
+}
+
+// hexPreview renders the first bytes of a binary value as "0x…" so raw bytes
+// never reach the terminal.
+func hexPreview(v string) string {
+	if len(v) <= hexPreviewBytes {
+		return "0x" + hex.EncodeToString([]byte(v))
+	}
+	return "0x" + hex.EncodeToString([]byte(v[:hexPreviewBytes])) + "…"
+}
+
+// toggleInspector opens or closes the cell inspector, which shows the whole
+// value of the selected cell: a hex+ASCII dump for binary values, wrapped
+// text otherwise.
+func (m *Model) toggleInspector() {
+	if m.inspect {
+		m.inspect = false
+		return
+	}
+	if m.currentRow() == nil || m.srcCol(m.colCursor) < 0 {
+		return
+	}
+	m.inspect = true
+	m.inspTop = 0
+}
+
+// updateInspector scrolls the cell inspector. Esc, enter or i close it;
+// every other key is swallowed so the grid does not move underneath.
+func (m Model) updateInspector(msg tea.KeyMsg) (Model, tea.Cmd) {
+	visH := m.visibleDataHeight()
+	last := len(m.inspectLines(m.contentWidth()-2)) - visH
+	switch msg.String() {
+	case "esc", "enter", "i":
+		m.inspect = false
+	case "up", "k":
+		m.inspTop--
+	case "down", "j":
+		m.inspTop++
+	case "pgup", "b":
+		m.inspTop -= visH
+	case "pgdown", "f", " ":
+		m.inspTop += visH
+	case "home", "g":
+		m.inspTop = 0
+	case "end", "G":
+		m.inspTop = last
+	}
+	if m.inspTop > last {
+		m.inspTop = last
+	}
+	if m.inspTop < 0 {
+		m.inspTop = 0
+	}
+	return m, nil
+}
+
+// inspectedCell returns the selected cell and its source column.
+func (m Model) inspectedCell() (string, int, bool) {
+	row := m.currentRow()
+	src := m.srcCol(m.colCursor)
+	if row == nil || src < 0 {
+		return "", -1, false
+	}
+	return cellAt(row, src), src, true
+}
+
+// inspectLines returns the inspector body for the selected cell, one entry
+// per line, each at most width cells wide.
+func (m Model) inspectLines(width int) []string {
+	value, src, ok := m.inspectedCell()
+	if !ok {
+		return nil
+	}
+	if adapter.IsNull(value) {
+		return []string{m.cellText(value)}
+	}
+	if m.kindOf(src) == kindBinary || isBinary(value) {
+		dump := strings.TrimSuffix(hex.Dump([]byte(value)), "\n")
+		if dump == "" {
+			return []string{"(empty)"}
+		}
+		return strings.Split(dump, "\n")
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(value, "\r\n", "\n"), "\n") {
+		lines = append(lines, wrapLine(strings.ReplaceAll(line, "\t", "    "), width)...)
+	}
+	return lines
+}
+
+// wrapLine breaks s into pieces of at most width display cells.
+func wrapLine(s string, width int) []string {
+	if width < 1 {
+		width = 1
+	}
+	var lines []string
+	var cur strings.Builder
+	curW := 0
+	for _, r := range s {
+		w := runewidth.RuneWidth(r)
+		if curW+w > width && curW > 0 {
+			lines = append(lines, cur.String())
+			cur.Reset()
+			curW = 0
+		}
+		cur.WriteRune(r)
+		curW += w
+	}
+	return append(lines, cur.String())
+}
+
+// renderInspector renders the cell inspector in place of the grid.
+func (m Model) renderInspector(th *theme.Theme) string {
+	contentW := m.contentWidth()
+	visH := m.visibleDataHeight()
+
+	value, src, _ := m.inspectedCell()
+	title := ""
+	if src >= 0 {
+		c := m.columns[src]
+		title = c.Name
+		if c.Type != "" {
+			title += " (" + strings.ToLower(c.Type) + ")"
+		}
+		if !adapter.IsNull(value) {
+			title += fmt.Sprintf(" · %d bytes", len(value))
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(th.ResultsHeader.Render(padRight(runewidth.Truncate(title, contentW-2, "…"), contentW-2)))
+	sb.WriteByte('\n')
+	sb.WriteString(strings.Repeat("─", contentW))
+	sb.WriteByte('\n')
+
+	lines := m.inspectLines(contentW - 2)
+	for i := 0; i < visH; i++ {
+		line := ""
+		if idx := m.inspTop + i; idx < len(lines) {
+			line = runewidth.Truncate(lines[idx], contentW-2, "…")
+		}
+		sb.WriteString(th.ResultsCell.Render(padRight(line, contentW-2)))
+		if i < visH-1 {
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
+
+// inspectNote returns the footer note shown while the inspector is open.
+func (m Model) inspectNote() string {
+	n := len(m.inspectLines(m.contentWidth() - 2))
+	if n <= m.visibleDataHeight() {
+		return "inspect"
+	}
+	end := m.inspTop + m.visibleDataHeight()
+	if end > n {
+		end = n
+	}
+	return fmt.Sprintf("inspect lines %d-%d of %d", m.inspTop+1, end, n)
+}
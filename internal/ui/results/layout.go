@@ -0,0 +1,222 @@
+package results
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/sadopc/gotermsql/internal/adapter"
+)
+
+// colLayout describes how result columns are arranged on screen. Indices
+// refer to positions in the result's column list (source indices).
+type colLayout struct {
+	order  []int        // every source column, in display order
+	hidden map[int]bool // source columns that are not rendered
+	pinned int          // leading entries of order that are pinned
+}
+
+// defaultLayout shows all n columns in source order.
+func defaultLayout(n int) colLayout {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	return colLayout{order: order, hidden: map[int]bool{}}
+}
+
+// clone returns a deep copy so stored layouts are not mutated in place.
+func (l colLayout) clone() colLayout {
+	c := colLayout{
+		order:  append([]int(nil), l.order...),
+		hidden: make(map[int]bool, len(l.hidden)),
+		pinned: l.pinned,
+	}
+	for k, v := range l.hidden {
+		c.hidden[k] = v
+	}
+	return c
+}
+
+// visible returns the source indices of rendered columns in display order.
+func (l colLayout) visible() []int {
+	out := make([]int, 0, len(l.order))
+	for _, c := range l.order {
+		if !l.hidden[c] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// position returns the index of source column src within order.
+func (l colLayout) position(src int) int {
+	for i, c := range l.order {
+		if c == src {
+			return i
+		}
+	}
+	return -1
+}
+
+// layoutMap holds saved column layouts keyed by layoutKey.
+type layoutMap map[string]colLayout
+
+// layoutKey identifies a result shape so a tab can restore the layout when
+// the same query (or one with identical columns) is re-run.
+func layoutKey(cols []adapter.ColumnMeta) string {
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.Name
+	}
+	return strings.Join(names, "\x00")
+}
+
+// resetLayout restores the saved layout for the current columns, or the
+// default layout if none has been saved in this tab.
+func (m *Model) resetLayout() {
+	if saved, ok := m.layouts[layoutKey(m.columns)]; ok && len(saved.order) == len(m.columns) {
+		m.layout = saved.clone()
+	} else {
+		m.layout = defaultLayout(len(m.columns))
+	}
+	m.display = m.layout.visible()
+}
+
+// saveLayout records the current layout for this result shape and refreshes
+// the rendered columns.
+func (m *Model) saveLayout() {
+	if m.layouts == nil {
+		m.layouts = layoutMap{}
+	}
+	m.layouts[layoutKey(m.columns)] = m.layout.clone()
+	m.display = m.layout.visible()
+	if m.colCursor >= len(m.display) {
+		m.colCursor = len(m.display) - 1
+	}
+	if m.colCursor < 0 {
+		m.colCursor = 0
+	}
+	m.sizeColumns()
+}
+
+// srcCol maps a display column index to its source column index, or -1.
+func (m Model) srcCol(display int) int {
+	if display < 0 || display >= len(m.display) {
+		return -1
+	}
+	return m.display[display]
+}
+
+// hideColumn hides the selected column. The last visible column cannot be
+// hidden.
+func (m *Model) hideColumn() {
+	src := m.srcCol(m.colCursor)
+	if src < 0 || len(m.display) <= 1 {
+		return
+	}
+	m.layout.hidden[src] = true
+	m.saveLayout()
+}
+
+// showAllColumns unhides every hidden column.
+func (m *Model) showAllColumns() {
+	if len(m.layout.hidden) == 0 {
+		return
+	}
+	m.layout.hidden = map[int]bool{}
+	m.saveLayout()
+}
+
+// togglePin pins the selected column to the left edge, or unpins it.
+// Pinned columns keep their relative order at the start of the grid.
+func (m *Model) togglePin() {
+	src := m.srcCol(m.colCursor)
+	if src < 0 {
+		return
+	}
+	pos := m.layout.position(src)
+	order := m.layout.order
+	if pos < m.layout.pinned {
+		// Unpin: move to the end of the pinned block, then shrink it.
+		copy(order[pos:], order[pos+1:m.layout.pinned])
+		order[m.layout.pinned-1] = src
+		m.layout.pinned--
+	} else {
+		// Pin: move to the end of the pinned block, then grow it.
+		copy(order[m.layout.pinned+1:pos+1], order[m.layout.pinned:pos])
+		order[m.layout.pinned] = src
+		m.layout.pinned++
+	}
+	m.saveLayout()
+	m.colCursor = m.displayIndex(src)
+	m.ensureColVisible()
+}
+
+// moveColumn shifts the selected column one visible position left (dir < 0)
+// or right (dir > 0). Columns do not cross the pinned/unpinned boundary.
+func (m *Model) moveColumn(dir int) {
+	src := m.srcCol(m.colCursor)
+	other := m.srcCol(m.colCursor + dir)
+	if src < 0 || other < 0 {
+		return
+	}
+	a, b := m.layout.position(src), m.layout.position(other)
+	if (a < m.layout.pinned) != (b < m.layout.pinned) {
+		return
+	}
+	m.layout.order[a], m.layout.order[b] = other, src
+	m.saveLayout()
+	m.colCursor = m.displayIndex(src)
+	m.ensureColVisible()
+}
+
+// displayIndex returns the display position of source column src, or 0.
+func (m Model) displayIndex(src int) int {
+	for i, c := range m.display {
+		if c == src {
+			return i
+		}
+	}
+	return 0
+}
+
+// sizeColumns recomputes column widths for the visible columns and hands
+// them to the table widget.
+func (m *Model) sizeColumns() {
+	cols := make([]adapter.ColumnMeta, len(m.display))
+	for i, src := range m.display {
+		cols[i] = m.columns[src]
+	}
+
+	// Project a sample of rows onto the visible columns for width estimation.
+	sample := m.rows
+	if len(sample) > 100 {
+		sample = sample[:100]
+	}
+	m.kinds = make([]colKind, len(m.columns))
+	for i, c := range m.columns {
+		m.kinds[i] = columnKind(c, sample, i)
+	}
+	projected := make([][]string, len(sample))
+	for i, row := range sample {
+		p := make([]string, len(m.display))
+		for j, src := range m.display {
+			p[j] = m.displayCell(cellAt(row, src), src)
+		}
+		projected[i] = p
+	}
+
+	m.tableCols = autoSizeColumns(cols, projected, m.contentWidth())
+	for i, src := range m.display {
+		if src == m.barCol {
+			// Room for the inline bar and the space before it.
+			m.tableCols[i].Width += barWidth + 1
+		}
+	}
+
+	// The table widget only tracks the cursor; rendering is custom. It needs
+	// one column per cell in a row, so give it zero-width placeholders for
+	// every source column rather than the visible layout.
+	m.table.SetColumns(make([]table.Column, len(m.columns)))
+	m.ensureColVisible()
+}
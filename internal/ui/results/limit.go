@@ -0,0 +1,43 @@
+package results
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// RemoveLimitMsg asks the app to re-run the tab's query without the LIMIT
+// it added automatically.
+type RemoveLimitMsg struct {
+	TabID int
+}
+
+// SetAutoLimit records that the query of the current result was cut to n
+// rows by an automatic LIMIT, or 0 if it was not. Call it after
+// SetResults or SetIterator, which clear it.
+func (m *Model) SetAutoLimit(n int) {
+	m.autoLimit = n
+}
+
+// AutoLimit returns the automatic LIMIT of the current result, or 0.
+func (m Model) AutoLimit() int {
+	return m.autoLimit
+}
+
+// removeLimit asks for the current result without its automatic LIMIT.
+func (m Model) removeLimit() tea.Cmd {
+	if m.autoLimit == 0 || m.ViewingHistory() {
+		return nil
+	}
+	msg := RemoveLimitMsg{TabID: m.tabID}
+	return func() tea.Msg { return msg }
+}
+
+// limitNote returns the footer note for an automatically limited result,
+// or "".
+func (m Model) limitNote() string {
+	if m.autoLimit == 0 || m.ViewingHistory() {
+		return ""
+	}
+	return fmt.Sprintf("auto-limited to %d — press L to remove", m.autoLimit)
+}
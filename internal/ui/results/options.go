@@ -0,0 +1,106 @@
+package results
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sadopc/gotermsql/internal/adapter"
+)
+
+// DefaultNullText is the marker drawn for SQL NULL when none is configured.
+const DefaultNullText = "NULL"
+
+// Options holds display settings that come from the user's configuration.
+type Options struct {
+	RowNumbers  bool   // show a gutter with absolute row numbers
+	StickyFirst bool   // keep the first column visible while scrolling horizontally
+	NullText    string // marker drawn for SQL NULL cells; "" means DefaultNullText
+}
+
+// SetOptions applies display settings. Keys can still toggle them per tab.
+func (m *Model) SetOptions(o Options) {
+	m.opts = o
+	m.ensureColVisible()
+}
+
+// Options returns the current display settings.
+func (m Model) Options() Options {
+	return m.opts
+}
+
+// rowNumber returns the 1-based absolute row number of displayed row i,
+// accounting for rows trimmed from the front of a streaming buffer.
+func (m Model) rowNumber(i int) int {
+	src := i
+	if i < len(m.rowIndex) {
+		src = m.rowIndex[i]
+	}
+	return m.offset + src + 1
+}
+
+// gutterWidth returns the width of the row-number gutter including its
+// padding, or 0 when row numbers are off.
+func (m Model) gutterWidth() int {
+	if !m.opts.RowNumbers || m.vertical {
+		return 0
+	}
+	digits := len(strconv.Itoa(m.offset + len(m.allRows)))
+	if digits < 3 {
+		digits = 3
+	}
+	return digits + 2 // +2 for Padding(0,1)
+}
+
+// renderGutter renders one gutter cell: the row number, or text (for the
+// header), right-aligned.
+func (m Model) renderGutter(style lipgloss.Style, text string) string {
+	w := m.gutterWidth() - 2
+	if pad := w - len(text); pad > 0 {
+		text = strings.Repeat(" ", pad) + text
+	}
+	return style.Render(text)
+}
+
+// nullText returns the marker drawn for SQL NULL cells.
+func (m Model) nullText() string {
+	if m.opts.NullText == "" {
+		return DefaultNullText
+	}
+	return m.opts.NullText
+}
+
+// cellText returns a cell value as drawn in the grid: the NULL marker for
+// SQL NULL, the value itself otherwise.
+func (m Model) cellText(v string) string {
+	if adapter.IsNull(v) {
+		return m.nullText()
+	}
+	return v
+}
+
+// plainCell returns a cell value for the clipboard and file exports. SQL
+// NULL becomes an empty string, matching the usual CSV convention.
+func plainCell(v string) string {
+	if adapter.IsNull(v) {
+		return ""
+	}
+	return v
+}
+
+// jsonCell returns a cell value for JSON output, with SQL NULL as null.
+func jsonCell(v string) any {
+	if adapter.IsNull(v) {
+		return nil
+	}
+	return v
+}
+
+// plainRow applies plainCell to every value of row.
+func plainRow(row []string) []string {
+	out := make([]string, len(row))
+	for i, v := range row {
+		out[i] = plainCell(v)
+	}
+	return out
+}
@@ -0,0 +1,54 @@
+package results
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// SetPaging sets the streaming page size and the cap on buffered rows.
+// Values <= 0 keep the defaults. The buffer always holds at least one page.
+func (m *Model) SetPaging(pageSize, maxRows int) {
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if maxRows <= 0 {
+		maxRows = maxBufferedRows
+	}
+	if maxRows < pageSize {
+		maxRows = pageSize
+	}
+	m.pageSize = pageSize
+	m.maxRows = maxRows
+}
+
+// PageSize returns the number of rows to fetch per page when streaming.
+func (m Model) PageSize() int {
+	return m.pageSize
+}
+
+// MaxBufferedRows returns the cap on rows kept in memory while streaming.
+func (m Model) MaxBufferedRows() int {
+	return m.maxRows
+}
+
+// pagingCommand applies a "page N" or "buffer N" entered at the ":" prompt
+// as a per-tab override. It reports whether text was such a command.
+func (m *Model) pagingCommand(text string) (tea.Cmd, bool) {
+	fields := strings.Fields(text)
+	if len(fields) != 2 || (fields[0] != "page" && fields[0] != "buffer") {
+		return nil, false
+	}
+	n, err := strconv.Atoi(fields[1])
+	if err != nil || n < 1 {
+		return statusCmd(fmt.Sprintf("Invalid %s size %q", fields[0], fields[1]), true), true
+	}
+	if fields[0] == "page" {
+		m.SetPaging(n, m.maxRows)
+		return statusCmd(fmt.Sprintf("Page size %d (applies from the next run)", m.pageSize), false), true
+	}
+	m.SetPaging(m.pageSize, n)
+	return statusCmd(fmt.Sprintf("Buffering up to %d rows", m.maxRows), false), true
+}
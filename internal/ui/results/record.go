@@ -0,0 +1,136 @@
+package results
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+// maxFieldNameWidth caps the field-name column in the record view.
+const maxFieldNameWidth = 30
+
+// toggleRecordView switches between the grid and the vertical record view,
+// which shows the selected row as one "field | value" line per column.
+func (m *Model) toggleRecordView() {
+	if len(m.columns) == 0 {
+		return
+	}
+	m.vertical = !m.vertical
+	m.fieldTop = 0
+	m.updateRecordTop()
+}
+
+// updateRecord handles navigation keys in the record view. Up/down move
+// between fields; [ and ] move to the previous or next record. It reports
+// whether the key was consumed.
+func (m Model) updateRecord(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
+	switch msg.String() {
+	case "up", "k":
+		if m.colCursor > 0 {
+			m.colCursor--
+		}
+	case "down", "j":
+		if m.colCursor < len(m.display)-1 {
+			m.colCursor++
+		}
+	case "home", "g":
+		m.colCursor = 0
+	case "end", "G":
+		m.colCursor = len(m.display) - 1
+	case "[":
+		m.table.MoveUp(1)
+		m.updateViewTop()
+		if m.iterator != nil && m.offset > 0 && m.table.Cursor() == 0 {
+			m.loading = true
+			return m, fetchPrevPage(m.iterator, m.tabID), true
+		}
+	case "]":
+		if m.iterator != nil && m.table.Cursor() >= len(m.rows)-1 {
+			m.loading = true
+			return m, fetchNextPage(m.iterator, m.tabID), true
+		}
+		m.table.MoveDown(1)
+		m.updateViewTop()
+	default:
+		return m, nil, false
+	}
+	m.updateRecordTop()
+	return m, nil, true
+}
+
+// updateRecordTop scrolls the record view so the selected field is visible.
+func (m *Model) updateRecordTop() {
+	visH := m.visibleDataHeight()
+	if m.colCursor < m.fieldTop {
+		m.fieldTop = m.colCursor
+	}
+	if m.colCursor >= m.fieldTop+visH {
+		m.fieldTop = m.colCursor - visH + 1
+	}
+	if m.fieldTop < 0 {
+		m.fieldTop = 0
+	}
+}
+
+// renderRecord renders the selected row as a two-column field/value list.
+func (m Model) renderRecord(th *theme.Theme) string {
+	contentW := m.contentWidth()
+	visH := m.visibleDataHeight()
+
+	nameW := len("Field")
+	for _, src := range m.display {
+		if w := runewidth.StringWidth(m.columns[src].Name); w > nameW {
+			nameW = w
+		}
+	}
+	if nameW > maxFieldNameWidth {
+		nameW = maxFieldNameWidth
+	}
+	// Each cell carries Padding(0, 1), and a "│" separates the two cells.
+	valueW := contentW - nameW - 5
+	if valueW < 4 {
+		valueW = 4
+	}
+
+	var sb strings.Builder
+	sb.WriteString(th.ResultsHeader.Render(padRight("Field", nameW)))
+	sb.WriteString(th.ResultsHeader.Padding(0).Render("│"))
+	sb.WriteString(th.ResultsHeader.Render(padRight("Value", valueW)))
+	sb.WriteByte('\n')
+	sb.WriteString(strings.Repeat("─", contentW))
+	sb.WriteByte('\n')
+
+	row := m.currentRow()
+	for i := 0; i < visH; i++ {
+		idx := m.fieldTop + i
+		if idx >= len(m.display) || row == nil {
+			sb.WriteString(strings.Repeat(" ", contentW))
+		} else {
+			cellStyle := th.ResultsCell
+			switch {
+			case m.focused && idx == m.colCursor:
+				cellStyle = th.ResultsSelectedRow
+			case idx%2 == 1:
+				cellStyle = th.ResultsCellAlt
+			}
+			src := m.display[idx]
+			name := runewidth.Truncate(m.columns[src].Name, nameW, "…")
+			value := cellAt(row, src)
+			val := runewidth.Truncate(m.displayCell(value, src), valueW, "…")
+			valStyle := cellStyle
+			if adapter.IsNull(value) {
+				valStyle = nullStyle(th, cellStyle, m.focused && idx == m.colCursor)
+			}
+			sb.WriteString(cellStyle.Render(padRight(name, nameW)))
+			sb.WriteString(cellStyle.Padding(0).Render("│"))
+			sb.WriteString(valStyle.Render(padRight(val, valueW)))
+		}
+		if i < visH-1 {
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
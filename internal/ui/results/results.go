@@ -6,30 +6,41 @@ package results
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mattn/go-runewidth"
 	"github.com/sadopc/gotermsql/internal/adapter"
 	appmsg "github.com/sadopc/gotermsql/internal/msg"
 	"github.com/sadopc/gotermsql/internal/theme"
+	"github.com/sadopc/gotermsql/internal/ui/format"
 )
 
 // FetchedPageMsg carries rows fetched asynchronously from an iterator.
 type FetchedPageMsg struct {
 	Rows    [][]string
 	Forward bool // true = FetchNext, false = FetchPrev
+	Seek    bool // page starts at Offset after a "go to row" jump
+	Offset  int
 	Err     error
 	TabID   int
 }
 
-// maxBufferedRows is the maximum number of rows kept in memory for streamed
-// results. When this limit is exceeded, the oldest rows are trimmed.
+// defaultPageSize is the number of rows fetched per page when streaming.
+const defaultPageSize = 1000
+
+// maxBufferedRows is the default maximum number of rows kept in memory for
+// streamed results. When the limit is exceeded, the oldest rows are trimmed.
 const maxBufferedRows = 5000
 
+// scrollLines is how many rows a turn of the mouse wheel scrolls.
+const scrollLines = 3
+
 // Model is the results table component. It wraps bubbles/table with support
 // for streaming large result sets via adapter.RowIterator.
 type Model struct {
@@ -38,17 +49,54 @@ type Model struct {
 	tableCols []table.Column      // computed column definitions for rendering
 	rows      [][]string          // current page of rows in memory
 	allRows   [][]string          // all loaded rows (for non-streaming results)
+	rowIndex  []int               // index into allRows of each displayed row
 	totalRows int64               // total row count (-1 if unknown)
 	offset    int                 // current scroll offset in the full dataset
 	viewTop   int                 // first visible row index for custom rendering
 	pageSize  int                 // rows per page
+	maxRows   int                 // buffer cap for streamed results
 	iterator  adapter.RowIterator // for streaming results
+	colCursor int                 // selected column (display index)
+	colOffset int                 // first unpinned display column drawn
+	display   []int               // source column index for each display column
+	kinds     []colKind           // alignment kind of each source column
+	layout    colLayout           // column order, hidden and pinned columns
+	layouts   layoutMap           // saved layouts by column set
+	sortCol   int                 // column rows are sorted by (-1 = unsorted)
+	sortDesc  bool                // sort descending
+	filter    string              // active row filter expression
+	filterErr error               // parse error for filter, if any
+	filtering bool                // filter input has focus
+	filterBox textinput.Model     // filter prompt
+	copyMenu  bool                // copy sub-menu open (after "y")
+	editing   bool                // cell editor has focus
+	editBox   textinput.Model     // cell editor
+	seeking   bool                // "go to row" prompt has focus
+	seekBox   textinput.Model     // "go to row" prompt
+	findText  string              // last ":find" search text
+	query     string              // query that produced the result shown
+	ranAt     time.Time           // when the result shown was produced
+	partial   bool                // past streamed result: buffered rows only
+	hist      []snapshot          // earlier results, oldest first
+	histPos   int                 // index of the result shown; len(hist) = latest
+	histMax   int                 // number of earlier results kept
+	autoLimit int                 // LIMIT added to the query automatically (0 = none)
+	opts      Options             // display settings
+	vertical  bool                // show selected row vertically
+	fieldTop  int                 // first visible field in record view
+	inspect   bool                // cell inspector open
+	inspTop   int                 // first visible line in the inspector
+	aggregate bool                // show aggregates of the selected column
+	barCol    int                 // column drawn with inline bars (-1 = none)
+	barMax    float64             // value that fills a whole bar
 	tabID     int
 	width     int
 	height    int
 	focused   bool
 	loading   bool
-	message   string // status message ("INSERT 0 1", etc.)
+	deadline  time.Time // when the running query times out; zero if never
+	progress  string    // how far the running query has got, if known
+	message   string    // status message ("INSERT 0 1", etc.)
 	queryTime time.Duration
 	err       error
 }
@@ -72,9 +120,16 @@ func New(tabID int) Model {
 
 	return Model{
 		table:     t,
+		filterBox: newFilterInput(),
+		editBox:   newEditInput(),
+		seekBox:   newSeekInput(),
 		tabID:     tabID,
-		pageSize:  1000,
+		pageSize:  defaultPageSize,
+		maxRows:   maxBufferedRows,
 		totalRows: -1,
+		sortCol:   -1,
+		barCol:    -1,
+		histMax:   defaultHistorySize,
 	}
 }
 
@@ -91,7 +146,103 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			return m, nil
 		}
 
+		if m.filtering {
+			return m.updateFilter(msg)
+		}
+		if m.editing {
+			return m.updateEdit(msg)
+		}
+		if m.seeking {
+			return m.updateSeek(msg)
+		}
+		if m.copyMenu {
+			return m.updateCopyMenu(msg)
+		}
+		if m.inspect {
+			return m.updateInspector(msg)
+		}
+		if m.vertical {
+			if rm, cmd, ok := m.updateRecord(msg); ok {
+				return rm, cmd
+			}
+		}
+
 		switch msg.String() {
+		case "/":
+			return m, m.startFilter()
+		case ":":
+			return m, m.startSeek()
+		case "esc":
+			if m.filter != "" {
+				m.clearFilter()
+			}
+			return m, nil
+		case "left", "h":
+			if m.colCursor > 0 {
+				m.colCursor--
+			}
+			m.ensureColVisible()
+			return m, nil
+		case "right", "l":
+			if m.colCursor < len(m.display)-1 {
+				m.colCursor++
+			}
+			m.ensureColVisible()
+			return m, nil
+		case "s":
+			return m, m.toggleSort(m.srcCol(m.colCursor))
+		case "H":
+			m.hideColumn()
+			return m, nil
+		case "U":
+			m.showAllColumns()
+			return m, nil
+		case "P":
+			m.togglePin()
+			return m, nil
+		case "<":
+			m.moveColumn(-1)
+			return m, nil
+		case ">":
+			m.moveColumn(1)
+			return m, nil
+		case "x":
+			m.toggleRecordView()
+			return m, nil
+		case "i":
+			m.toggleInspector()
+			return m, nil
+		case "n":
+			return m, m.findNext()
+		case "{":
+			return m, m.showOlder()
+		case "}":
+			return m, m.showNewer()
+		case "e":
+			return m, m.startEdit()
+		case "a":
+			m.aggregate = !m.aggregate
+			return m, nil
+		case "L":
+			return m, m.removeLimit()
+		case "B":
+			if msg := m.toggleBars(); msg != "" {
+				return m, statusCmd(msg, true)
+			}
+			return m, nil
+		case "#":
+			m.opts.RowNumbers = !m.opts.RowNumbers
+			m.ensureColVisible()
+			return m, nil
+		case "F":
+			m.opts.StickyFirst = !m.opts.StickyFirst
+			m.ensureColVisible()
+			return m, nil
+		case "y":
+			if len(m.columns) > 0 {
+				m.copyMenu = true
+			}
+			return m, nil
 		case "pgdown":
 			// If we have an iterator and are near the end of loaded rows,
 			// fetch the next page.
@@ -115,31 +266,80 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		m.updateViewTop()
 		return m, cmd
 
+	case tea.MouseMsg:
+		// Coordinates are relative to the component: row 0 is the top
+		// border, row 1 the header.
+		if m.vertical || msg.Action != tea.MouseActionPress {
+			return m, nil
+		}
+		switch msg.Button {
+		case tea.MouseButtonWheelUp:
+			m.table.MoveUp(scrollLines)
+			m.updateViewTop()
+			return m, nil
+		case tea.MouseButtonWheelDown:
+			// Past the loaded rows, fetch the next page like pgdown.
+			if m.iterator != nil && !m.loading && m.table.Cursor() >= len(m.rows)-1 {
+				m.loading = true
+				return m, fetchNextPage(m.iterator, m.tabID)
+			}
+			m.table.MoveDown(scrollLines)
+			m.updateViewTop()
+			return m, nil
+		}
+		if msg.Button == tea.MouseButtonLeft && msg.Y == 1 {
+			if col := m.columnAtX(msg.X - 1); col >= 0 {
+				m.colCursor = col
+				return m, m.toggleSort(m.srcCol(col))
+			}
+		}
+		return m, nil
+
 	case appmsg.QueryResultMsg:
 		m.SetResults(msg.Result)
 		return m, nil
 
+	case FoundRowMsg:
+		if msg.TabID != m.tabID {
+			return m, nil
+		}
+		return m, m.applyFound(msg)
+
 	case FetchedPageMsg:
 		if msg.TabID != m.tabID {
 			return m, nil
 		}
+		if m.iterator == nil {
+			// The result was replaced, e.g. by an earlier one from history.
+			m.loading = false
+			return m, nil
+		}
 		m.loading = false
 		if msg.Err != nil {
+			if msg.Seek && adapter.SentinelEOF(msg.Err) {
+				return m, statusCmd(fmt.Sprintf("Row %d is past the end of the result", msg.Offset+1), true)
+			}
 			if !adapter.SentinelEOF(msg.Err) {
 				m.err = msg.Err
 			}
 			return m, nil
 		}
-		if msg.Forward {
+		if msg.Seek {
+			m.applySeek(msg)
+		} else if msg.Forward {
+			first := len(m.allRows) == 0
 			m.allRows = append(m.allRows, msg.Rows...)
 			// Trim oldest rows if exceeding buffer limit
-			if len(m.allRows) > maxBufferedRows {
-				excess := len(m.allRows) - maxBufferedRows
+			if len(m.allRows) > m.maxRows {
+				excess := len(m.allRows) - m.maxRows
 				m.allRows = m.allRows[excess:]
 				m.offset += excess
 			}
-			m.rows = m.allRows
-			m.rebuildTableRows()
+			m.applyView()
+			if first {
+				// Column widths and kinds were set from the headers alone.
+				m.sizeColumns()
+			}
 		} else {
 			m.allRows = append(msg.Rows, m.allRows...)
 			m.offset -= len(msg.Rows)
@@ -147,11 +347,10 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 				m.offset = 0
 			}
 			// Trim newest rows if exceeding buffer limit
-			if len(m.allRows) > maxBufferedRows {
-				m.allRows = m.allRows[:maxBufferedRows]
+			if len(m.allRows) > m.maxRows {
+				m.allRows = m.allRows[:m.maxRows]
 			}
-			m.rows = m.allRows
-			m.rebuildTableRows()
+			m.applyView()
 		}
 		return m, nil
 	}
@@ -181,8 +380,16 @@ func (m Model) View() string {
 	}
 
 	// Loading state.
-	if m.loading && len(m.rows) == 0 {
-		msg := th.MutedText.Render("  Executing query...")
+	if m.loading && len(m.allRows) == 0 {
+		text := "  Executing query..."
+		if !m.deadline.IsZero() {
+			left := max(time.Until(m.deadline).Truncate(time.Second), 0)
+			text += fmt.Sprintf(" (times out in %s)", left)
+		}
+		if m.progress != "" {
+			text += " " + m.progress
+		}
+		msg := th.MutedText.Render(text)
 		return m.wrapBorder(msg, contentHeight)
 	}
 
@@ -204,8 +411,17 @@ func (m Model) View() string {
 		return m.wrapBorder(placeholder, contentHeight)
 	}
 
-	// Render table with custom zebra striping.
-	tableView := m.renderTable()
+	// Render table with custom zebra striping, or the selected row
+	// vertically in record view.
+	var tableView string
+	switch {
+	case m.inspect:
+		tableView = m.renderInspector(th)
+	case m.vertical:
+		tableView = m.renderRecord(th)
+	default:
+		tableView = m.renderTable()
+	}
 
 	// Build footer.
 	footer := m.buildFooter()
@@ -214,8 +430,15 @@ func (m Model) View() string {
 	return m.wrapBorder(content, 0)
 }
 
-// SetResults loads a complete QueryResult into the table.
+// SetResults loads a complete QueryResult into the table. The result it
+// replaces goes into the tab's result history.
 func (m *Model) SetResults(result *adapter.QueryResult) {
+	m.remember()
+	m.load(result)
+}
+
+// load shows a complete QueryResult.
+func (m *Model) load(result *adapter.QueryResult) {
 	m.err = nil
 	m.loading = false
 	if m.iterator != nil {
@@ -224,12 +447,23 @@ func (m *Model) SetResults(result *adapter.QueryResult) {
 	}
 	m.offset = 0
 	m.queryTime = result.Duration
+	m.colCursor = 0
+	m.colOffset = 0
+	m.fieldTop = 0
+	m.sortCol = -1
+	m.sortDesc = false
+	m.barCol = -1
+	m.inspect = false
+	m.resetFilter()
+	m.stopEdit()
 
 	if !result.IsSelect {
 		// Non-SELECT statement: show message only.
 		m.message = result.Message
 		m.columns = nil
+		m.display = nil
 		m.rows = nil
+		m.rowIndex = nil
 		m.allRows = nil
 		m.totalRows = result.RowCount
 		m.table.SetRows(nil)
@@ -241,6 +475,7 @@ func (m *Model) SetResults(result *adapter.QueryResult) {
 	m.columns = result.Columns
 	m.allRows = result.Rows
 	m.rows = result.Rows
+	m.rowIndex = nil
 	m.totalRows = result.RowCount
 	m.viewTop = 0
 	if m.totalRows < 0 {
@@ -252,6 +487,7 @@ func (m *Model) SetResults(result *adapter.QueryResult) {
 
 // SetIterator configures the model for streaming mode with the given iterator.
 func (m *Model) SetIterator(iter adapter.RowIterator) {
+	m.remember()
 	if m.iterator != nil {
 		m.iterator.Close()
 	}
@@ -260,15 +496,35 @@ func (m *Model) SetIterator(iter adapter.RowIterator) {
 	m.totalRows = iter.TotalRows()
 	m.offset = 0
 	m.viewTop = 0
+	m.colCursor = 0
+	m.colOffset = 0
+	m.fieldTop = 0
+	m.sortCol = -1
+	m.sortDesc = false
+	m.barCol = -1
+	m.inspect = false
+	m.resetFilter()
+	m.stopEdit()
 	m.err = nil
 	m.message = ""
 	m.allRows = nil
 	m.rows = nil
+	m.rowIndex = nil
 
 	// Build column headers immediately so the table structure is visible.
-	m.tableCols = autoSizeColumns(m.columns, nil, m.contentWidth())
-	m.table.SetColumns(m.tableCols)
+	// Clear the old rows first: the widget requires rows to fit its columns.
 	m.table.SetRows(nil)
+	m.resetLayout()
+	m.sizeColumns()
+}
+
+// SetTotalRows records the total row count of a streaming result once it is
+// known, e.g. from a background COUNT(*).
+func (m *Model) SetTotalRows(n int64) {
+	if m.ViewingHistory() {
+		return
+	}
+	m.totalRows = n
 }
 
 // SetSize updates the component dimensions and recalculates table layout.
@@ -291,11 +547,12 @@ func (m *Model) SetSize(w, h int) {
 
 	m.table.SetWidth(innerW)
 	m.table.SetHeight(innerH)
+	m.filterBox.Width = innerW / 2
+	m.editBox.Width = innerW - 4
 
 	// Recalculate column widths if we have data.
 	if len(m.columns) > 0 {
-		m.tableCols = autoSizeColumns(m.columns, m.rows, m.contentWidth())
-		m.table.SetColumns(m.tableCols)
+		m.sizeColumns()
 	}
 }
 
@@ -304,9 +561,22 @@ func (m *Model) SetLoading(loading bool) {
 	m.loading = loading
 	if loading {
 		m.err = nil
+		m.progress = ""
 	}
 }
 
+// SetProgress sets how far the running query has got, shown while it
+// runs.
+func (m *Model) SetProgress(text string) {
+	m.progress = text
+}
+
+// SetDeadline sets when the running query times out, shown while it runs.
+// The zero time means it never does.
+func (m *Model) SetDeadline(t time.Time) {
+	m.deadline = t
+}
+
 // SetError sets the error state.
 func (m *Model) SetError(err error) {
 	m.err = err
@@ -340,6 +610,13 @@ func (m Model) Focused() bool {
 	return m.focused
 }
 
+// InputFocused reports whether one of the pane's text prompts (filter, cell
+// editor, go to row) has keyboard focus. While it does, the parent should
+// route all text keys to the results pane.
+func (m Model) InputFocused() bool {
+	return m.filtering || m.editing || m.seeking
+}
+
 // SelectedRow returns the data for the currently selected row, or nil if
 // no row is selected.
 func (m Model) SelectedRow() []string {
@@ -385,11 +662,50 @@ func (m *Model) CloseIterator() {
 
 // rebuildTable recalculates columns and repopulates the table widget.
 func (m *Model) rebuildTable() {
-	m.tableCols = autoSizeColumns(m.columns, m.rows, m.contentWidth())
-	m.table.SetColumns(m.tableCols)
+	// Clear the old rows first: the widget requires rows to fit its columns.
+	m.table.SetRows(nil)
+	m.resetLayout()
+	m.sizeColumns()
 	m.rebuildTableRows()
 }
 
+// applyView derives the displayed rows from allRows by applying the active
+// filter and sort order, then repopulates the table widget.
+func (m *Model) applyView() {
+	names := make([]string, len(m.columns))
+	for i, c := range m.columns {
+		names[i] = c.Name
+	}
+	f, err := parseFilter(m.filter, names)
+	m.filterErr = err
+
+	// Work on indices so allRows keeps the source order and each displayed
+	// row remembers its position for the row-number gutter.
+	idx := filterIndices(m.allRows, f)
+	if m.sortCol >= 0 && m.sortCol < len(m.columns) {
+		sortIndices(idx, m.allRows, m.sortCol, m.sortDesc)
+	}
+	m.rows = make([][]string, len(idx))
+	for i, j := range idx {
+		m.rows[i] = m.allRows[j]
+	}
+	m.rowIndex = idx
+	if m.barCol >= 0 {
+		m.barMax = barScale(m.rows, m.barCol)
+	}
+	m.rebuildTableRows()
+	m.updateViewTop()
+}
+
+// resetFilter drops the active filter without recomputing the view.
+func (m *Model) resetFilter() {
+	m.filter = ""
+	m.filterErr = nil
+	m.filtering = false
+	m.filterBox.Blur()
+	m.filterBox.SetValue("")
+}
+
 // rebuildTableRows converts [][]string rows into table.Row and sets them.
 func (m *Model) rebuildTableRows() {
 	tableRows := make([]table.Row, len(m.rows))
@@ -397,6 +713,10 @@ func (m *Model) rebuildTableRows() {
 		tableRows[i] = table.Row(row)
 	}
 	m.table.SetRows(tableRows)
+	// SetRows leaves the cursor at -1 after the table was empty.
+	if m.table.Cursor() < 0 && len(tableRows) > 0 {
+		m.table.SetCursor(0)
+	}
 }
 
 // contentWidth returns the usable width inside the border.
@@ -477,12 +797,33 @@ func (m Model) renderTable() string {
 func (m Model) renderHeader(th *theme.Theme, totalWidth int) string {
 	var sb strings.Builder
 	used := 0
-	for _, col := range m.tableCols {
-		cellWidth := col.Width + 2 // +2 for Padding(0,1)
-		text := runewidth.Truncate(col.Title, col.Width, "…")
-		text = padRight(text, col.Width)
-		rendered := th.ResultsHeader.Render(text)
-		sb.WriteString(rendered)
+	if g := m.gutterWidth(); g > 0 {
+		sb.WriteString(m.renderGutter(th.ResultsHeader, "#"))
+		used += g
+	}
+	for _, rc := range m.renderedColumns() {
+		i, width := rc.idx, rc.width
+		cellWidth := width + 2 // +2 for Padding(0,1)
+		title := m.tableCols[i].Title
+		if m.display[i] == m.sortCol {
+			// Keep the sort indicator visible even when the title is truncated.
+			indicator := " ▲"
+			if m.sortDesc {
+				indicator = " ▼"
+			}
+			title = runewidth.Truncate(title, width-runewidth.StringWidth(indicator), "…") + indicator
+		}
+		text := runewidth.Truncate(title, width, "…")
+		if m.kindOf(m.display[i]) == kindNumber {
+			text = padLeft(text, width)
+		} else {
+			text = padRight(text, width)
+		}
+		style := th.ResultsHeader
+		if m.focused && i == m.colCursor {
+			style = style.Underline(true)
+		}
+		sb.WriteString(style.Render(text))
 		used += cellWidth
 	}
 	// Pad remainder so the header background fills the full width.
@@ -507,15 +848,30 @@ func (m Model) renderDataRow(th *theme.Theme, rowIdx int, selected bool, totalWi
 	row := m.rows[rowIdx]
 	var sb strings.Builder
 	used := 0
-	for j, col := range m.tableCols {
-		cellWidth := col.Width + 2 // +2 for Padding(0,1)
-		var val string
-		if j < len(row) {
-			val = row[j]
-		}
-		text := runewidth.Truncate(val, col.Width, "…")
-		text = padRight(text, col.Width)
-		rendered := cellStyle.Render(text)
+	if g := m.gutterWidth(); g > 0 {
+		gutterStyle := cellStyle
+		if !selected {
+			gutterStyle = gutterStyle.Foreground(th.MutedText.GetForeground())
+		}
+		sb.WriteString(m.renderGutter(gutterStyle, strconv.Itoa(m.rowNumber(rowIdx))))
+		used += g
+	}
+	for _, rc := range m.renderedColumns() {
+		cellWidth := rc.width + 2 // +2 for Padding(0,1)
+		src := m.display[rc.idx]
+		value := cellAt(row, src)
+		var text string
+		if src == m.barCol {
+			text = runewidth.Truncate(m.barCell(value, src, m.tableCols[rc.idx].Width), rc.width, "…")
+			text = padRight(text, rc.width)
+		} else {
+			text = m.formatCell(value, src, rc.width)
+		}
+		style := cellStyle
+		if adapter.IsNull(value) {
+			style = nullStyle(th, cellStyle, selected)
+		}
+		rendered := style.Render(text)
 		sb.WriteString(rendered)
 		used += cellWidth
 	}
@@ -526,6 +882,32 @@ func (m Model) renderDataRow(th *theme.Theme, rowIdx int, selected bool, totalWi
 	return sb.String()
 }
 
+// nullStyle returns base restyled for a NULL marker: italic, and muted
+// unless the row is selected, where the selection colors win.
+func nullStyle(th *theme.Theme, base lipgloss.Style, selected bool) lipgloss.Style {
+	style := base.Italic(th.ResultsNull.GetItalic())
+	if !selected {
+		style = style.Foreground(th.ResultsNull.GetForeground())
+	}
+	return style
+}
+
+// columnAtX returns the index of the column rendered at horizontal offset x
+// within the table content area, or -1 if x is past the last column.
+func (m Model) columnAtX(x int) int {
+	pos := m.gutterWidth()
+	if x < pos {
+		return -1
+	}
+	for _, rc := range m.renderedColumns() {
+		pos += rc.width + 2 // +2 for Padding(0,1)
+		if x < pos {
+			return rc.idx
+		}
+	}
+	return -1
+}
+
 // padRight pads s with spaces on the right so its display width equals w.
 func padRight(s string, w int) string {
 	sw := runewidth.StringWidth(s)
@@ -542,15 +924,44 @@ func (m Model) buildFooter() string {
 
 	// Row count.
 	switch {
+	case m.filter != "" && m.filterErr == nil:
+		parts = append(parts, fmt.Sprintf("%d of %d rows", len(m.rows), len(m.allRows)))
+	case m.totalRows >= 0 && m.iterator != nil:
+		parts = append(parts, fmt.Sprintf("%d of %d rows", m.offset+len(m.allRows), m.totalRows))
 	case m.totalRows >= 0:
 		parts = append(parts, fmt.Sprintf("%d rows", m.totalRows))
 	case len(m.allRows) > 0:
 		parts = append(parts, fmt.Sprintf("%d rows loaded", len(m.allRows)))
 	}
 
+	// Hidden column count.
+	if n := len(m.columns) - len(m.display); n > 0 && len(m.display) > 0 {
+		parts = append(parts, fmt.Sprintf("%d hidden", n))
+	}
+
+	// Position within the result set in record view.
+	if m.vertical && len(m.rows) > 0 {
+		parts = append(parts, fmt.Sprintf("record %d of %d", m.table.Cursor()+1, len(m.rows)))
+	}
+
+	if m.inspect {
+		parts = append(parts, m.inspectNote())
+	}
+
+	if note := m.historyNote(); note != "" {
+		parts = append(parts, note)
+	}
+
+	// Horizontal scroll position.
+	if !m.vertical && !m.inspect {
+		if ind := m.scrollIndicator(); ind != "" {
+			parts = append(parts, ind)
+		}
+	}
+
 	// Query duration.
 	if m.queryTime > 0 {
-		parts = append(parts, fmt.Sprintf("%s", formatDuration(m.queryTime)))
+		parts = append(parts, fmt.Sprintf("%s", format.Duration(m.queryTime)))
 	}
 
 	// Loading indicator.
@@ -558,12 +969,46 @@ func (m Model) buildFooter() string {
 		parts = append(parts, "loading...")
 	}
 
+	if m.editing {
+		return " " + m.editBox.View()
+	}
+	if m.seeking {
+		return " " + m.seekBox.View()
+	}
+
+	if m.filtering {
+		// The filter prompt replaces the left side of the footer.
+		return " " + m.filterBox.View() + th.MutedText.Render("  "+strings.Join(parts, " | "))
+	}
+
+	if m.copyMenu {
+		return th.WarningText.Render("  " + copyMenuHint)
+	}
+
+	var filterNote string
+	switch {
+	case m.filterErr != nil:
+		filterNote = th.ErrorText.Render("  " + m.filterErr.Error())
+	case m.filter != "":
+		filterNote = th.MutedText.Render("  filter: " + m.filter)
+	}
+
+	if note := m.limitNote(); note != "" {
+		filterNote = th.WarningText.Render("  "+note) + filterNote
+	}
+	if agg := m.aggregateNote(); agg != "" {
+		filterNote = th.SuccessText.Render("  "+agg) + filterNote
+	}
+	if spark := m.sparkNote(); spark != "" && !m.vertical {
+		filterNote = th.SuccessText.Render("  "+spark) + filterNote
+	}
+
 	if len(parts) == 0 {
-		return ""
+		return filterNote
 	}
 
 	footer := "  " + strings.Join(parts, " | ")
-	return th.MutedText.Render(footer)
+	return th.MutedText.Render(footer) + filterNote
 }
 
 // wrapBorder renders the content inside a themed border frame.
@@ -607,20 +1052,6 @@ func (m *Model) applyStyles() {
 	m.table.SetStyles(s)
 }
 
-// formatDuration produces a human-readable duration string.
-func formatDuration(d time.Duration) string {
-	switch {
-	case d < time.Millisecond:
-		return fmt.Sprintf("%d us", d.Microseconds())
-	case d < time.Second:
-		return fmt.Sprintf("%d ms", d.Milliseconds())
-	case d < time.Minute:
-		return fmt.Sprintf("%.2f s", d.Seconds())
-	default:
-		return fmt.Sprintf("%.1f min", d.Minutes())
-	}
-}
-
 // SetQueryDuration sets the query execution time for the footer display.
 func (m *Model) SetQueryDuration(d time.Duration) {
 	m.queryTime = d
@@ -660,8 +1091,7 @@ func fetchPrevPage(iter adapter.RowIterator, tabID int) tea.Cmd {
 // ---------------------------------------------------------------------------
 
 // autoSizeColumns calculates column widths based on header names and data
-// content, distributing available space proportionally and capping individual
-// columns at maxWidth.
+// content, capping individual columns at 50 characters and at maxWidth.
 func autoSizeColumns(cols []adapter.ColumnMeta, rows [][]string, maxWidth int) []table.Column {
 	if len(cols) == 0 {
 		return nil
@@ -700,28 +1130,13 @@ func autoSizeColumns(cols []adapter.ColumnMeta, rows [][]string, maxWidth int) [
 		}
 	}
 
-	// Calculate total desired width. The bubbles/table component adds no
-	// separator between columns; spacing comes from the Cell style's
-	// Padding(0, 1) which adds 2 characters per column (1 left + 1 right).
-	paddingWidth := numCols * 2
-	totalDesired := paddingWidth
-	for _, w := range widths {
-		totalDesired += w
-	}
-
-	// If the total exceeds the available width, scale columns down
-	// proportionally.
-	available := maxWidth - paddingWidth
-	if available < numCols {
-		available = numCols
-	}
-
-	if totalDesired > maxWidth {
-		totalColWidth := totalDesired - paddingWidth
+	// Columns keep their natural width; when the total exceeds the pane the
+	// grid scrolls horizontally (see renderedColumns). A single column is
+	// still capped so it fits the pane, leaving room for its Padding(0, 1).
+	if maxWidth-2 >= 4 {
 		for i := range widths {
-			widths[i] = (widths[i] * available) / totalColWidth
-			if widths[i] < 2 {
-				widths[i] = 2
+			if widths[i] > maxWidth-2 {
+				widths[i] = maxWidth - 2
 			}
 		}
 	}
@@ -0,0 +1,1186 @@
+package results
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+func init() {
+	theme.Current = theme.Default()
+}
+
+func keyMsg(key string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+// stubIter is a minimal RowIterator used to put the model in streaming mode.
+type stubIter struct {
+	cols   []adapter.ColumnMeta
+	closed bool
+}
+
+func (it *stubIter) FetchNext(context.Context) ([][]string, error) { return nil, io.EOF }
+func (it *stubIter) FetchPrev(context.Context) ([][]string, error) { return nil, io.EOF }
+func (it *stubIter) Columns() []adapter.ColumnMeta                 { return it.cols }
+func (it *stubIter) TotalRows() int64                              { return -1 }
+func (it *stubIter) Close() error {
+	it.closed = true
+	return nil
+}
+
+// loaded returns a focused, sized model holding the given rows.
+func loaded(cols []adapter.ColumnMeta, rows [][]string) Model {
+	m := New(0)
+	m.SetSize(80, 20)
+	m.Focus()
+	m.SetResults(&adapter.QueryResult{
+		Columns:  cols,
+		Rows:     rows,
+		RowCount: int64(len(rows)),
+		IsSelect: true,
+	})
+	return m
+}
+
+func firstColumn(rows [][]string) []string {
+	out := make([]string, len(rows))
+	for i, r := range rows {
+		out[i] = r[0]
+	}
+	return out
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// --- Sorting ---
+
+func TestCompareCells(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2", "10", -1},
+		{"10", "2", 1},
+		{"1.5", "1.50", 0},
+		{"apple", "banana", -1},
+		{adapter.NullValue, "1", 1},
+		{"1", adapter.NullValue, -1},
+		{adapter.NullValue, adapter.NullValue, 0},
+		{"10", "abc", -1},
+	}
+	for _, tt := range tests {
+		if got := compareCells(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareCells(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSort_CyclesAscDescOff(t *testing.T) {
+	rows := [][]string{{"3"}, {"10"}, {adapter.NullValue}, {"1"}}
+	m := loaded(columns("n"), rows)
+
+	m, _ = m.Update(keyMsg("s"))
+	if got, want := firstColumn(m.rows), []string{"1", "3", "10", adapter.NullValue}; !equalStrings(got, want) {
+		t.Fatalf("asc = %v, want %v", got, want)
+	}
+
+	m, _ = m.Update(keyMsg("s"))
+	if got, want := firstColumn(m.rows), []string{"10", "3", "1", adapter.NullValue}; !equalStrings(got, want) {
+		t.Fatalf("desc = %v, want %v", got, want)
+	}
+
+	m, _ = m.Update(keyMsg("s"))
+	if m.sortCol != -1 {
+		t.Fatalf("sortCol = %d, want -1 after third press", m.sortCol)
+	}
+	if got, want := firstColumn(m.rows), []string{"3", "10", adapter.NullValue, "1"}; !equalStrings(got, want) {
+		t.Fatalf("unsorted = %v, want original order %v", got, want)
+	}
+}
+
+func TestSortIndices_NullsLast(t *testing.T) {
+	rows := [][]string{{adapter.NullValue}, {"2"}, {adapter.NullValue}, {"10"}, {"a"}}
+	for _, desc := range []bool{false, true} {
+		idx := []int{0, 1, 2, 3, 4}
+		sortIndices(idx, rows, 0, desc)
+		want := []int{1, 3, 4, 0, 2}
+		if desc {
+			want = []int{4, 3, 1, 0, 2}
+		}
+		for i := range want {
+			if idx[i] != want[i] {
+				t.Errorf("desc=%v: order = %v, want %v", desc, idx, want)
+				break
+			}
+		}
+	}
+}
+
+func TestSort_SelectedColumn(t *testing.T) {
+	rows := [][]string{{"1", "b"}, {"2", "a"}}
+	m := loaded(columns("id", "name"), rows)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if m.colCursor != 1 {
+		t.Fatalf("colCursor = %d, want 1", m.colCursor)
+	}
+	m, _ = m.Update(keyMsg("s"))
+	if m.sortCol != 1 {
+		t.Fatalf("sortCol = %d, want 1", m.sortCol)
+	}
+	if got := m.rows[0][1]; got != "a" {
+		t.Errorf("first row name = %q, want %q", got, "a")
+	}
+	// Source order is preserved for export.
+	if got := m.Rows()[0][1]; got != "b" {
+		t.Errorf("Rows()[0] name = %q, want source order %q", got, "b")
+	}
+}
+
+func TestSort_HeaderClick(t *testing.T) {
+	m := loaded(columns("id", "name"), [][]string{{"2", "x"}, {"1", "y"}})
+
+	m, _ = m.Update(tea.MouseMsg{X: 2, Y: 1, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+	if m.sortCol != 0 {
+		t.Fatalf("sortCol = %d, want 0 after header click", m.sortCol)
+	}
+	if m.rows[0][0] != "1" {
+		t.Errorf("first row id = %q, want 1", m.rows[0][0])
+	}
+}
+
+func TestMouseWheel(t *testing.T) {
+	var rows [][]string
+	for i := range 10 {
+		rows = append(rows, []string{fmt.Sprint(i)})
+	}
+	m := loaded(columns("id"), rows)
+	wheel := func(b tea.MouseButton) tea.Cmd {
+		var cmd tea.Cmd
+		m, cmd = m.Update(tea.MouseMsg{Action: tea.MouseActionPress, Button: b})
+		return cmd
+	}
+
+	wheel(tea.MouseButtonWheelDown)
+	wheel(tea.MouseButtonWheelDown)
+	if got := m.table.Cursor(); got != 6 {
+		t.Errorf("after two turns down: cursor = %d, want 6", got)
+	}
+	wheel(tea.MouseButtonWheelUp)
+	if got := m.table.Cursor(); got != 3 {
+		t.Errorf("after a turn up: cursor = %d, want 3", got)
+	}
+
+	// At the last loaded row of a streamed result, the wheel fetches more.
+	m.SetIterator(&stubIter{cols: columns("id")})
+	m, _ = m.Update(FetchedPageMsg{Rows: [][]string{{"1"}}, Forward: true})
+	if cmd := wheel(tea.MouseButtonWheelDown); cmd == nil || !m.loading {
+		t.Error("wheel down at the end of a page should fetch the next one")
+	}
+}
+
+func TestSort_StreamingOffersServerSort(t *testing.T) {
+	m := New(7)
+	m.SetSize(80, 20)
+	m.Focus()
+	m.SetIterator(&stubIter{cols: columns("id")})
+	m, _ = m.Update(FetchedPageMsg{Rows: [][]string{{"2"}, {"1"}}, Forward: true, TabID: 7})
+
+	_, cmd := m.Update(keyMsg("s"))
+	if cmd == nil {
+		t.Fatal("expected a command offering a server-side sort")
+	}
+	msg, ok := cmd().(SortQueryMsg)
+	if !ok {
+		t.Fatalf("cmd() returned %T, want SortQueryMsg", cmd())
+	}
+	if msg.TabID != 7 || msg.Column != "id" || msg.Desc {
+		t.Errorf("SortQueryMsg = %+v, want {TabID:7 Column:id Desc:false}", msg)
+	}
+}
+
+// --- Filtering ---
+
+func TestParseFilter(t *testing.T) {
+	cols := []string{"id", "email"}
+	row := []string{"42", "Bob@Example.com"}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"example", true},
+		{"EXAMPLE", true},
+		{"nomatch", false},
+		{"email:42", false},
+		{"ID:42", true},
+		{"/^bob@/", true},
+		{"id:/^4\\d$/", true},
+		{"id:/^5/", false},
+		{"unknown:42", false}, // not a column, so matched literally
+	}
+	for _, tt := range tests {
+		f, err := parseFilter(tt.expr, cols)
+		if err != nil {
+			t.Fatalf("parseFilter(%q): %v", tt.expr, err)
+		}
+		if got := f.match(row); got != tt.want {
+			t.Errorf("parseFilter(%q).match = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+
+	if f, _ := parseFilter("", cols); f != nil {
+		t.Error("empty expression should yield a nil filter")
+	}
+	if _, err := parseFilter("/[/", cols); err == nil {
+		t.Error("expected error for invalid regex")
+	}
+}
+
+func typeText(m Model, s string) Model {
+	for _, r := range s {
+		m, _ = m.Update(keyMsg(string(r)))
+	}
+	return m
+}
+
+func TestFilter_TypeAndClear(t *testing.T) {
+	rows := [][]string{{"1", "alice"}, {"2", "bob"}, {"3", "alicia"}}
+	m := loaded(columns("id", "name"), rows)
+
+	m, _ = m.Update(keyMsg("/"))
+	if !m.Filtering() {
+		t.Fatal("expected filter input to be focused after /")
+	}
+	m = typeText(m, "ali")
+	if len(m.rows) != 2 {
+		t.Fatalf("filtered rows = %d, want 2", len(m.rows))
+	}
+	if !strings.Contains(m.buildFooter(), "2 of 3 rows") {
+		t.Errorf("footer = %q, want it to contain %q", m.buildFooter(), "2 of 3 rows")
+	}
+
+	// Enter keeps the filter but returns keys to the table.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.Filtering() || m.Filter() != "ali" {
+		t.Fatalf("after enter: filtering=%v filter=%q", m.Filtering(), m.Filter())
+	}
+
+	// Esc clears it.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.Filter() != "" || len(m.rows) != 3 {
+		t.Fatalf("after esc: filter=%q rows=%d", m.Filter(), len(m.rows))
+	}
+}
+
+func TestFilter_CombinesWithSort(t *testing.T) {
+	rows := [][]string{{"3", "x"}, {"1", "y"}, {"2", "x"}}
+	m := loaded(columns("id", "tag"), rows)
+
+	m, _ = m.Update(keyMsg("s"))
+	m, _ = m.Update(keyMsg("/"))
+	m = typeText(m, "tag:x")
+
+	if got, want := firstColumn(m.rows), []string{"2", "3"}; !equalStrings(got, want) {
+		t.Fatalf("rows = %v, want %v", got, want)
+	}
+	if got := firstColumn(m.Rows()); !equalStrings(got, []string{"3", "1", "2"}) {
+		t.Errorf("Rows() = %v, want source order", got)
+	}
+}
+
+func TestFilter_InvalidRegexShowsAllRows(t *testing.T) {
+	m := loaded(columns("id"), [][]string{{"1"}, {"2"}})
+	m, _ = m.Update(keyMsg("/"))
+	m = typeText(m, "/[/")
+
+	if len(m.rows) != 2 {
+		t.Errorf("rows = %d, want all rows on invalid regex", len(m.rows))
+	}
+	if m.filterErr == nil {
+		t.Error("expected filterErr to be set")
+	}
+}
+
+// --- Copy ---
+
+func TestCopyMenu(t *testing.T) {
+	var copied string
+	orig := writeClipboard
+	writeClipboard = func(s string) error {
+		copied = s
+		return nil
+	}
+	defer func() { writeClipboard = orig }()
+
+	rows := [][]string{{"1", "a,b"}, {"2", `say "hi"`}}
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"c", "1"},
+		{"t", "1\ta,b"},
+		{"v", `1,"a,b"`},
+		{"j", `{"id":"1","name":"a,b"}`},
+		{"o", "1\n2"},
+	}
+	for _, tt := range tests {
+		m := loaded(columns("id", "name"), rows)
+		m, _ = m.Update(keyMsg("y"))
+		if !strings.Contains(m.buildFooter(), "copy:") {
+			t.Fatalf("footer = %q, want copy menu hint", m.buildFooter())
+		}
+		m, cmd := m.Update(keyMsg(tt.key))
+		if cmd == nil {
+			t.Fatalf("key %q: expected copy command", tt.key)
+		}
+		cmd()
+		if copied != tt.want {
+			t.Errorf("key %q copied %q, want %q", tt.key, copied, tt.want)
+		}
+		if m.copyMenu {
+			t.Errorf("key %q: copy menu should close", tt.key)
+		}
+	}
+}
+
+func TestCopyMenu_UnknownKeyCancels(t *testing.T) {
+	m := loaded(columns("id"), [][]string{{"1"}})
+	m, _ = m.Update(keyMsg("y"))
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd != nil || m.copyMenu {
+		t.Errorf("esc should cancel the copy menu without a command")
+	}
+}
+
+// --- Record view ---
+
+func TestRecordView(t *testing.T) {
+	rows := [][]string{{"1", "alice", "a@x"}, {"2", "bob", "b@x"}}
+	m := loaded(columns("id", "name", "email"), rows)
+
+	m, _ = m.Update(keyMsg("x"))
+	if !m.vertical {
+		t.Fatal("expected record view after x")
+	}
+	view := m.View()
+	for _, want := range []string{"Field", "Value", "email", "a@x", "record 1 of 2"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("record view missing %q", want)
+		}
+	}
+
+	// Down moves between fields, ] to the next record.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if m.colCursor != 1 {
+		t.Errorf("colCursor = %d, want 1", m.colCursor)
+	}
+	m, _ = m.Update(keyMsg("]"))
+	if m.table.Cursor() != 1 {
+		t.Errorf("cursor = %d, want 1 after ]", m.table.Cursor())
+	}
+	if !strings.Contains(m.View(), "b@x") {
+		t.Error("expected second record to be shown")
+	}
+
+	m, _ = m.Update(keyMsg("x"))
+	if m.vertical {
+		t.Error("expected grid view after second x")
+	}
+}
+
+// --- Column layout ---
+
+func headerTitles(m Model) []string {
+	out := make([]string, len(m.tableCols))
+	for i, c := range m.tableCols {
+		out[i] = c.Title
+	}
+	return out
+}
+
+func TestLayout_HideAndShow(t *testing.T) {
+	m := loaded(columns("a", "b", "c"), [][]string{{"1", "2", "3"}})
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	m, _ = m.Update(keyMsg("H"))
+	if got := headerTitles(m); !equalStrings(got, []string{"a", "c"}) {
+		t.Fatalf("headers = %v, want [a c]", got)
+	}
+	if !strings.Contains(m.buildFooter(), "1 hidden") {
+		t.Errorf("footer = %q, want hidden count", m.buildFooter())
+	}
+
+	// The cursor now sits on "c"; copying the cell uses the source column.
+	if text, _ := m.copyCell(); text != "3" {
+		t.Errorf("copyCell = %q, want 3", text)
+	}
+
+	m, _ = m.Update(keyMsg("U"))
+	if got := headerTitles(m); !equalStrings(got, []string{"a", "b", "c"}) {
+		t.Fatalf("headers = %v, want all columns", got)
+	}
+}
+
+func TestLayout_CannotHideLastColumn(t *testing.T) {
+	m := loaded(columns("a"), [][]string{{"1"}})
+	m, _ = m.Update(keyMsg("H"))
+	if len(m.display) != 1 {
+		t.Fatalf("display = %v, want the only column kept", m.display)
+	}
+}
+
+func TestLayout_PinAndMove(t *testing.T) {
+	m := loaded(columns("a", "b", "c", "d"), [][]string{{"1", "2", "3", "4"}})
+
+	// Pin "c": it moves to the front.
+	m.colCursor = 2
+	m, _ = m.Update(keyMsg("P"))
+	if got := headerTitles(m); !equalStrings(got, []string{"c", "a", "b", "d"}) {
+		t.Fatalf("after pin = %v", got)
+	}
+	if m.colCursor != 0 {
+		t.Errorf("colCursor = %d, want 0 (follows the pinned column)", m.colCursor)
+	}
+
+	// A pinned column cannot be moved past the pin boundary.
+	m, _ = m.Update(keyMsg(">"))
+	if got := headerTitles(m); !equalStrings(got, []string{"c", "a", "b", "d"}) {
+		t.Fatalf("moved across pin boundary: %v", got)
+	}
+
+	// Move "b" right within the unpinned block.
+	m.colCursor = 2
+	m, _ = m.Update(keyMsg(">"))
+	if got := headerTitles(m); !equalStrings(got, []string{"c", "a", "d", "b"}) {
+		t.Fatalf("after move = %v", got)
+	}
+
+	// Unpin "c": it returns to the unpinned block.
+	m.colCursor = 0
+	m, _ = m.Update(keyMsg("P"))
+	if got := headerTitles(m); !equalStrings(got, []string{"c", "a", "d", "b"}) || m.layout.pinned != 0 {
+		t.Fatalf("after unpin = %v pinned=%d", got, m.layout.pinned)
+	}
+}
+
+func TestLayout_RestoredForSameColumns(t *testing.T) {
+	cols := columns("id", "name")
+	m := loaded(cols, [][]string{{"1", "x"}})
+	m, _ = m.Update(keyMsg("H"))
+
+	// Re-running the same query keeps the layout.
+	m.SetResults(&adapter.QueryResult{Columns: cols, Rows: [][]string{{"2", "y"}}, IsSelect: true})
+	if got := headerTitles(m); !equalStrings(got, []string{"name"}) {
+		t.Fatalf("headers = %v, want saved layout [name]", got)
+	}
+
+	// A different result shape starts from the default layout.
+	m.SetResults(&adapter.QueryResult{Columns: columns("other"), Rows: [][]string{{"z"}}, IsSelect: true})
+	if got := headerTitles(m); !equalStrings(got, []string{"other"}) {
+		t.Fatalf("headers = %v, want [other]", got)
+	}
+}
+
+// --- Horizontal scrolling ---
+
+func wideResult(n int) Model {
+	names := make([]string, n)
+	row := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("column_%02d", i)
+		row[i] = strings.Repeat("v", 15)
+	}
+	return loaded(columns(names...), [][]string{row})
+}
+
+func TestHScroll_KeepsNaturalWidths(t *testing.T) {
+	m := wideResult(10)
+	for _, c := range m.tableCols {
+		if c.Width != 15 {
+			t.Fatalf("column width = %d, want natural width 15", c.Width)
+		}
+	}
+	ind := m.scrollIndicator()
+	if !strings.HasSuffix(ind, "▶") || strings.HasPrefix(ind, "◀") {
+		t.Errorf("indicator = %q, want only a right arrow at the start", ind)
+	}
+}
+
+func TestHScroll_FollowsCursor(t *testing.T) {
+	m := wideResult(10)
+	for i := 0; i < 9; i++ {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	}
+	if m.colOffset == 0 {
+		t.Fatal("expected the grid to scroll right")
+	}
+	if !m.fullyVisible(9) {
+		t.Error("last column should be fully visible")
+	}
+	if ind := m.scrollIndicator(); !strings.HasPrefix(ind, "◀") || strings.HasSuffix(ind, "▶") {
+		t.Errorf("indicator = %q, want only a left arrow at the end", ind)
+	}
+	if !strings.Contains(m.View(), "column_09") {
+		t.Error("view should show the last column header")
+	}
+
+	for i := 0; i < 9; i++ {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	}
+	if m.colOffset != 0 {
+		t.Errorf("colOffset = %d, want 0 after scrolling back", m.colOffset)
+	}
+}
+
+func TestHScroll_PinnedColumnStaysVisible(t *testing.T) {
+	m := wideResult(10)
+	m.colCursor = 5
+	m, _ = m.Update(keyMsg("P"))
+	for i := 0; i < 9; i++ {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	}
+	cols := m.renderedColumns()
+	if cols[0].idx != 0 || m.tableCols[0].Title != "column_05" {
+		t.Errorf("first rendered column = %q, want pinned column_05", m.tableCols[cols[0].idx].Title)
+	}
+}
+
+// --- Cell editing ---
+
+func TestEdit_SubmitAndApply(t *testing.T) {
+	m := loaded(columns("id", "name"), [][]string{{"1", "alice"}})
+	m.colCursor = 1
+
+	m, _ = m.Update(keyMsg("e"))
+	if !m.Editing() {
+		t.Fatal("expected cell editor after e")
+	}
+	if m.editBox.Value() != "alice" {
+		t.Errorf("editor value = %q, want current cell", m.editBox.Value())
+	}
+	m.editBox.SetValue("bob")
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.Editing() || cmd == nil {
+		t.Fatal("enter should close the editor and emit EditCellMsg")
+	}
+	edit, ok := cmd().(EditCellMsg)
+	if !ok || edit.Column != 1 || edit.Value != "bob" || edit.SetNull {
+		t.Fatalf("msg = %+v", edit)
+	}
+
+	// The grid only changes once the app applies the accepted edit.
+	if m.rows[0][1] != "alice" {
+		t.Fatalf("cell changed before ApplyEdit: %q", m.rows[0][1])
+	}
+	m.ApplyEdit(edit)
+	if m.rows[0][1] != "bob" || m.Rows()[0][1] != "bob" {
+		t.Errorf("cell = %q, want bob", m.rows[0][1])
+	}
+}
+
+func TestEdit_NullAndCancel(t *testing.T) {
+	m := loaded(columns("id"), [][]string{{"1"}})
+
+	m, _ = m.Update(keyMsg("e"))
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	if edit := cmd().(EditCellMsg); !edit.SetNull {
+		t.Error("ctrl+n should submit NULL")
+	}
+
+	m, _ = m.Update(keyMsg("e"))
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.Editing() || cmd != nil {
+		t.Error("esc should cancel without a command")
+	}
+}
+
+// --- Row numbers and sticky first column ---
+
+func TestRowNumbers_FollowSourcePosition(t *testing.T) {
+	m := loaded(columns("n"), [][]string{{"b"}, {"c"}, {"a"}})
+	m, _ = m.Update(keyMsg("#"))
+	if m.gutterWidth() == 0 {
+		t.Fatal("expected a gutter after #")
+	}
+
+	// Sorted ascending, "a" is displayed first but is source row 3.
+	m, _ = m.Update(keyMsg("s"))
+	if got := m.rowNumber(0); got != 3 {
+		t.Errorf("rowNumber(0) = %d, want 3", got)
+	}
+
+	// Streaming offsets shift numbers to absolute positions.
+	m.offset = 1000
+	if got := m.rowNumber(0); got != 1003 {
+		t.Errorf("rowNumber(0) = %d, want 1003 with offset", got)
+	}
+	if !strings.Contains(m.View(), "1003") {
+		t.Error("view should show the absolute row number")
+	}
+}
+
+func TestRowNumbers_ClickAccountsForGutter(t *testing.T) {
+	m := loaded(columns("id", "name"), [][]string{{"1", "x"}})
+	m.SetOptions(Options{RowNumbers: true})
+	if got := m.columnAtX(1); got != -1 {
+		t.Errorf("columnAtX in gutter = %d, want -1", got)
+	}
+	if got := m.columnAtX(m.gutterWidth()); got != 0 {
+		t.Errorf("columnAtX after gutter = %d, want 0", got)
+	}
+}
+
+func TestStickyFirstColumn(t *testing.T) {
+	m := wideResult(10)
+	m.SetOptions(Options{StickyFirst: true})
+	for i := 0; i < 9; i++ {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	}
+	cols := m.renderedColumns()
+	if cols[0].idx != 0 {
+		t.Errorf("first rendered column = %d, want 0 (sticky)", cols[0].idx)
+	}
+	if cols[len(cols)-1].idx != 9 {
+		t.Errorf("last rendered column = %d, want 9", cols[len(cols)-1].idx)
+	}
+}
+
+// --- NULL rendering ---
+
+func TestNullMarker(t *testing.T) {
+	m := loaded(columns("id", "bio"), [][]string{{"1", adapter.NullValue}, {"2", ""}})
+	if !strings.Contains(m.View(), "NULL") {
+		t.Error("NULL cell should render the default marker")
+	}
+
+	m.SetOptions(Options{NullText: "∅"})
+	view := m.View()
+	if !strings.Contains(view, "∅") || strings.Contains(view, "NULL") {
+		t.Error("NULL cell should render the configured marker")
+	}
+
+	// The clipboard gets an empty value for NULL, JSON gets null.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if text, _ := m.copyCell(); text != "" {
+		t.Errorf("copyCell = %q, want empty", text)
+	}
+	if got, _ := rowJSON(m.columns, m.currentRow()); got != `{"id":"1","bio":null}` {
+		t.Errorf("rowJSON = %s", got)
+	}
+
+	// Filtering for "null" finds the NULL row but not the empty string.
+	m.setFilter("bio:null")
+	if len(m.rows) != 1 || m.rows[0][0] != "1" {
+		t.Errorf("filter bio:null matched %v", m.rows)
+	}
+}
+
+// --- Column aggregates ---
+
+func TestColumnStats(t *testing.T) {
+	rows := [][]string{{"3"}, {"10"}, {adapter.NullValue}, {"1.5"}}
+	s := columnStats(rows, 0)
+	if s.count != 3 || s.nulls != 1 || !s.numeric || s.sum != 14.5 || s.min != "1.5" || s.max != "10" {
+		t.Fatalf("columnStats = %+v", s)
+	}
+	if got, want := s.String(), "count 3 (1 null) | sum 14.5 | avg 4.8333 | min 1.5 | max 10"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	text := columnStats([][]string{{"pear"}, {"apple"}, {"7"}}, 0)
+	if got, want := text.String(), "count 3 | min 7 | max pear"; got != want {
+		t.Errorf("text String() = %q, want %q", got, want)
+	}
+
+	if got := columnStats(nil, 0).String(); got != "count 0" {
+		t.Errorf("empty String() = %q", got)
+	}
+}
+
+func TestAggregateFooter_FollowsColumnAndFilter(t *testing.T) {
+	m := loaded(columns("name", "qty"), [][]string{{"a", "2"}, {"b", "5"}, {"c", "8"}})
+	if m.aggregateNote() != "" {
+		t.Fatal("aggregates should be off by default")
+	}
+	m, _ = m.Update(keyMsg("a"))
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if got := m.aggregateNote(); !strings.Contains(got, "qty") || !strings.Contains(got, "sum 15") {
+		t.Errorf("aggregateNote = %q", got)
+	}
+	m.setFilter("/^[ab]$/")
+	if got := m.aggregateNote(); !strings.Contains(got, "sum 7") {
+		t.Errorf("filtered aggregateNote = %q, want sum 7", got)
+	}
+	if !strings.Contains(m.View(), "Σ qty") {
+		t.Error("footer should show the aggregates")
+	}
+}
+
+// --- Inline bars and sparkline ---
+
+func TestBar(t *testing.T) {
+	tests := []struct {
+		v, max float64
+		want   string
+	}{
+		{10, 10, "████"},
+		{5, 10, "██  "},
+		{-5, 10, "██  "},
+		{1, 8, "▌   "},
+		{0, 10, "    "},
+		{3, 0, "    "},
+	}
+	for _, tt := range tests {
+		if got := bar(tt.v, tt.max, 4); got != tt.want {
+			t.Errorf("bar(%v, %v) = %q, want %q", tt.v, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	rows := [][]string{{"1"}, {"5"}, {adapter.NullValue}, {"3"}, {"8"}}
+	if got, want := sparkline(rows, 0, 10), "▁▅▃█"; got != want {
+		t.Errorf("sparkline = %q, want %q", got, want)
+	}
+	// More values than points are averaged into buckets.
+	if got := sparkline(rows, 0, 2); len([]rune(got)) != 2 {
+		t.Errorf("sparkline width = %d, want 2", len([]rune(got)))
+	}
+	if got := sparkline([][]string{{"x"}}, 0, 10); got != "" {
+		t.Errorf("sparkline of text = %q, want empty", got)
+	}
+}
+
+func TestToggleBars(t *testing.T) {
+	m := loaded(columns("name", "qty"), [][]string{{"a", "2"}, {"b", "8"}})
+	width := m.tableCols[1].Width
+
+	// Text columns cannot be charted.
+	if _, cmd := m.Update(keyMsg("B")); cmd == nil {
+		t.Fatal("B on a text column should report an error")
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	m, _ = m.Update(keyMsg("B"))
+	if m.barCol != 1 || m.barMax != 8 {
+		t.Fatalf("barCol/barMax = %d/%v, want 1/8", m.barCol, m.barMax)
+	}
+	if m.tableCols[1].Width != width+barWidth+1 {
+		t.Errorf("width = %d, want %d", m.tableCols[1].Width, width+barWidth+1)
+	}
+	if view := m.View(); !strings.Contains(view, "██████████") || !strings.Contains(view, "qty ▁█") {
+		t.Error("view should show a full bar for the max and a footer sparkline")
+	}
+
+	m, _ = m.Update(keyMsg("B"))
+	if m.barCol != -1 || m.tableCols[1].Width != width {
+		t.Error("second B should turn bars off")
+	}
+}
+
+// --- Streaming total count ---
+
+func TestFooter_StreamingTotal(t *testing.T) {
+	m := New(0)
+	m.SetSize(80, 20)
+	m.SetIterator(&stubIter{cols: columns("id")})
+	m, _ = m.Update(FetchedPageMsg{Rows: [][]string{{"1"}, {"2"}}, Forward: true})
+	if got := m.buildFooter(); !strings.Contains(got, "2 rows loaded") {
+		t.Errorf("footer = %q, want loaded count", got)
+	}
+	m.SetTotalRows(1500)
+	if got := m.buildFooter(); !strings.Contains(got, "2 of 1500 rows") {
+		t.Errorf("footer = %q, want \"2 of 1500 rows\"", got)
+	}
+}
+
+// --- Go to row ---
+
+// seekIter is a streaming iterator over n numbered rows that supports Seek.
+type seekIter struct {
+	stubIter
+	n, pos, page int
+}
+
+func (it *seekIter) Seek(_ context.Context, offset int64) error {
+	it.pos = int(offset)
+	return nil
+}
+
+func (it *seekIter) FetchNext(context.Context) ([][]string, error) {
+	if it.pos >= it.n {
+		return nil, io.EOF
+	}
+	var rows [][]string
+	for ; it.pos < it.n && len(rows) < it.page; it.pos++ {
+		rows = append(rows, []string{fmt.Sprint(it.pos + 1)})
+	}
+	return rows, nil
+}
+
+func TestGoToRow_InBuffer(t *testing.T) {
+	m := loaded(columns("n"), [][]string{{"a"}, {"b"}, {"c"}})
+	m, _ = m.Update(keyMsg(":"))
+	if !m.InputFocused() {
+		t.Fatal(": should open the go-to-row prompt")
+	}
+	m = typeText(m, "3")
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || m.table.Cursor() != 2 {
+		t.Errorf("cursor = %d, want 2", m.table.Cursor())
+	}
+}
+
+func TestGoToRow_SeeksStreamingIterator(t *testing.T) {
+	m := New(0)
+	m.SetSize(80, 20)
+	m.Focus()
+	iter := &seekIter{stubIter: stubIter{cols: columns("n")}, n: 10000, page: 100}
+	m.SetIterator(iter)
+	m, _ = m.Update(FetchFirstPage(iter, 0)())
+
+	m, _ = m.Update(keyMsg(":"))
+	m = typeText(m, "9500")
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected a seek command")
+	}
+	m, _ = m.Update(cmd())
+	if got := m.SelectedRow(); len(got) == 0 || got[0] != "9500" {
+		t.Errorf("selected row = %v, want 9500", got)
+	}
+	if got := m.rowNumber(0); got != 9500 {
+		t.Errorf("rowNumber(0) = %d, want 9500", got)
+	}
+
+	// Seeking past the end reports an error and keeps the buffer.
+	m, _ = m.Update(keyMsg(":"))
+	m = typeText(m, "20000")
+	m, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m, cmd = m.Update(cmd())
+	if cmd == nil || m.offset != 9499 {
+		t.Errorf("seek past end: offset = %d, want 9499 with an error status", m.offset)
+	}
+}
+
+// --- Paging settings ---
+
+func TestSetPaging(t *testing.T) {
+	m := New(0)
+	if m.PageSize() != defaultPageSize || m.MaxBufferedRows() != maxBufferedRows {
+		t.Fatalf("defaults = %d/%d", m.PageSize(), m.MaxBufferedRows())
+	}
+	m.SetPaging(500, 100)
+	if m.PageSize() != 500 || m.MaxBufferedRows() != 500 {
+		t.Errorf("buffer should hold at least one page, got %d/%d", m.PageSize(), m.MaxBufferedRows())
+	}
+	m.SetPaging(0, 0)
+	if m.PageSize() != defaultPageSize || m.MaxBufferedRows() != maxBufferedRows {
+		t.Errorf("zero values should restore defaults, got %d/%d", m.PageSize(), m.MaxBufferedRows())
+	}
+}
+
+func TestPaging_BufferCapTrimsOldestRows(t *testing.T) {
+	m := New(0)
+	m.SetSize(80, 20)
+	m.Focus()
+	iter := &seekIter{stubIter: stubIter{cols: columns("n")}, n: 1000, page: 100}
+	m.SetIterator(iter)
+	m.SetPaging(100, 0)
+
+	m, _ = m.Update(keyMsg(":"))
+	m = typeText(m, "buffer 250")
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || m.MaxBufferedRows() != 250 {
+		t.Fatalf("buffer override = %d, want 250", m.MaxBufferedRows())
+	}
+
+	for i := 0; i < 3; i++ {
+		m, _ = m.Update(fetchNextPage(iter, 0)())
+	}
+	if len(m.allRows) != 250 || m.offset != 50 {
+		t.Errorf("buffered %d rows at offset %d, want 250 at 50", len(m.allRows), m.offset)
+	}
+
+	m, _ = m.Update(keyMsg(":"))
+	m = typeText(m, "page 0")
+	if _, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd == nil {
+		t.Error("invalid page size should report an error")
+	}
+}
+
+// --- Type-aware formatting ---
+
+func TestColumnKind(t *testing.T) {
+	tests := []struct {
+		col    adapter.ColumnMeta
+		sample [][]string
+		want   colKind
+	}{
+		{adapter.ColumnMeta{Name: "total", Type: "NUMERIC(10,2)"}, nil, kindNumber},
+		{adapter.ColumnMeta{Name: "n", Type: "int unsigned"}, nil, kindNumber},
+		{adapter.ColumnMeta{Name: "created", Type: "timestamptz"}, nil, kindTime},
+		{adapter.ColumnMeta{Name: "key", Type: "uuid"}, nil, kindID},
+		{adapter.ColumnMeta{Name: "user_id", Type: "text"}, nil, kindID},
+		{adapter.ColumnMeta{Name: "count(*)"}, [][]string{{"3"}, {adapter.NullValue}}, kindNumber},
+		{adapter.ColumnMeta{Name: "label"}, [][]string{{"a"}}, kindText},
+	}
+	for _, tt := range tests {
+		if got := columnKind(tt.col, tt.sample, 0); got != tt.want {
+			t.Errorf("columnKind(%q %q) = %d, want %d", tt.col.Name, tt.col.Type, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateMiddle(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"0123456789abcdef", 9, "0123…cdef"},
+		{"0123456789abcdef", 8, "0123…def"},
+		{"abcdef", 2, "ab"},
+	}
+	for _, tt := range tests {
+		if got := truncateMiddle(tt.in, tt.width); got != tt.want {
+			t.Errorf("truncateMiddle(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestFormatCell(t *testing.T) {
+	m := New(0)
+	m.SetSize(80, 20)
+	m.SetResults(&adapter.QueryResult{
+		IsSelect: true,
+		Columns:  []adapter.ColumnMeta{{Name: "id", Type: "uuid"}, {Name: "amount", Type: "integer"}, {Name: "note", Type: "text"}},
+		Rows:     [][]string{{"123e4567-e89b-12d3-a456-426614174000", "42", "hi"}},
+	})
+
+	if got := m.formatCell("42", 1, 6); got != "    42" {
+		t.Errorf("number cell = %q, want right-aligned", got)
+	}
+	if got := m.formatCell("hi", 2, 4); got != "hi  " {
+		t.Errorf("text cell = %q, want left-aligned", got)
+	}
+	if got := m.formatCell("123e4567-e89b-12d3-a456-426614174000", 0, 9); got != "123e…4000" {
+		t.Errorf("id cell = %q, want middle truncation", got)
+	}
+	if got := m.formatCell(adapter.NullValue, 0, 6); got != "NULL  " {
+		t.Errorf("NULL id cell = %q, want plain NULL marker", got)
+	}
+}
+
+// --- Binary values ---
+
+func TestHexPreview(t *testing.T) {
+	if got := hexPreview("\x00\x01\xff"); got != "0x0001ff" {
+		t.Errorf("hexPreview = %q, want 0x0001ff", got)
+	}
+	long := strings.Repeat("\xab", hexPreviewBytes+1)
+	if got := hexPreview(long); got != "0x"+strings.Repeat("ab", hexPreviewBytes)+"…" {
+		t.Errorf("hexPreview(long) = %q, want truncated preview", got)
+	}
+	if !isBinary("PNG\x1a\n") || isBinary("line\nnext\ttab") {
+		t.Error("isBinary misclassified a value")
+	}
+}
+
+func TestInspector_HexDump(t *testing.T) {
+	m := New(0)
+	m.SetSize(100, 12)
+	m.Focus()
+	m.SetResults(&adapter.QueryResult{
+		IsSelect: true,
+		Columns:  []adapter.ColumnMeta{{Name: "data", Type: "BYTEA"}},
+		Rows:     [][]string{{strings.Repeat("gotermsql\x00", 20)}},
+	})
+
+	if got := m.formatCell(m.rows[0][0], 0, 40); !strings.HasPrefix(got, "0x676f7465726d73716c00") {
+		t.Errorf("grid cell = %q, want hex preview", got)
+	}
+
+	m, _ = m.Update(keyMsg("i"))
+	if !m.inspect {
+		t.Fatal("i should open the inspector")
+	}
+	lines := m.inspectLines(80)
+	if len(lines) != 13 || !strings.HasPrefix(lines[0], "00000000  67 6f 74 65") || !strings.HasSuffix(lines[0], "|gotermsql.goterm|") {
+		t.Errorf("hex dump = %q", lines)
+	}
+
+	m, _ = m.Update(keyMsg("G"))
+	if want := len(lines) - m.visibleDataHeight(); m.inspTop != want {
+		t.Errorf("inspTop after G = %d, want %d", m.inspTop, want)
+	}
+	if !strings.Contains(m.View(), "data (bytea) · 200 bytes") {
+		t.Error("inspector title missing from view")
+	}
+	m, _ = m.Update(keyMsg("esc"))
+	if m.inspect {
+		t.Error("esc should close the inspector")
+	}
+}
+
+// --- Find ---
+
+func TestFind_InMemory(t *testing.T) {
+	m := New(0)
+	m.SetSize(80, 20)
+	m.Focus()
+	m.SetResults(&adapter.QueryResult{
+		IsSelect: true,
+		Columns:  columns("name"),
+		Rows:     [][]string{{"alpha"}, {"Beta"}, {"gamma"}, {"beta two"}},
+	})
+
+	m, _ = m.Update(keyMsg(":"))
+	m = typeText(m, "find beta")
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := m.table.Cursor(); got != 1 {
+		t.Fatalf("cursor after find = %d, want 1", got)
+	}
+	m, _ = m.Update(keyMsg("n"))
+	if got := m.table.Cursor(); got != 3 {
+		t.Errorf("cursor after n = %d, want 3", got)
+	}
+	if _, cmd := m.Update(keyMsg("n")); cmd == nil {
+		t.Error("n past the last match should report no more matches")
+	}
+}
+
+func TestFind_StreamingAsksServer(t *testing.T) {
+	m := New(7)
+	m.SetSize(80, 20)
+	m.Focus()
+	iter := &seekIter{stubIter: stubIter{cols: columns("n")}, n: 1000, page: 100}
+	m.SetIterator(iter)
+	m, _ = m.Update(fetchNextPage(iter, 7)())
+
+	m, _ = m.Update(keyMsg(":"))
+	m = typeText(m, "find 42")
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("find on a streaming result should emit FindMsg")
+	}
+	want := FindMsg{TabID: 7, Column: "n", Text: "42"}
+	if got, ok := cmd().(FindMsg); !ok || got != want {
+		t.Fatalf("find emitted %#v, want %#v", cmd(), want)
+	}
+
+	m, cmd = m.Update(FoundRowMsg{TabID: 7, Text: "42", Row: 420})
+	if cmd == nil {
+		t.Fatal("a match outside the buffer should seek")
+	}
+	m, _ = m.Update(cmd())
+	if got := m.currentRow(); got == nil || got[0] != "420" {
+		t.Errorf("row after found = %v, want 420", got)
+	}
+	if got := m.cursorRowNumber(); got != 420 {
+		t.Errorf("cursorRowNumber = %d, want 420", got)
+	}
+}
+
+// --- Result history ---
+
+func TestResultHistory(t *testing.T) {
+	m := New(0)
+	m.SetSize(80, 20)
+	m.Focus()
+	run := func(query string, n int) {
+		rows := make([][]string, n)
+		for i := range rows {
+			rows[i] = []string{fmt.Sprint(i)}
+		}
+		m.SetResults(&adapter.QueryResult{IsSelect: true, Columns: columns("n"), Rows: rows, RowCount: int64(n)})
+		m.SetQuery(query)
+	}
+	run("SELECT 1 row", 1)
+	run("SELECT 2 rows", 2)
+	run("SELECT 3 rows", 3)
+
+	m, _ = m.Update(keyMsg("{"))
+	if !m.ViewingHistory() || len(m.allRows) != 2 || m.query != "SELECT 2 rows" {
+		t.Fatalf("after {: viewing=%v rows=%d query=%q", m.ViewingHistory(), len(m.allRows), m.query)
+	}
+	if got := m.buildFooter(); !strings.Contains(got, "result 2 of 3") {
+		t.Errorf("footer = %q, want history position", got)
+	}
+	if _, cmd := m.Update(keyMsg("e")); cmd == nil {
+		t.Error("editing a past result should be refused")
+	}
+
+	m, _ = m.Update(keyMsg("{"))
+	if _, cmd := m.Update(keyMsg("{")); cmd == nil || len(m.allRows) != 1 {
+		t.Errorf("at the oldest result: rows=%d", len(m.allRows))
+	}
+	m, _ = m.Update(keyMsg("}"))
+	m, _ = m.Update(keyMsg("}"))
+	if len(m.allRows) != 3 || m.query != "SELECT 3 rows" {
+		t.Errorf("after } }: rows=%d query=%q", len(m.allRows), m.query)
+	}
+
+	// A new result while an old one is shown goes after the latest.
+	run("SELECT 4 rows", 4)
+	if m.ViewingHistory() || len(m.hist) != 3 {
+		t.Errorf("history has %d entries, want 3", len(m.hist))
+	}
+}
+
+func TestResultHistory_SizeLimit(t *testing.T) {
+	m := New(0)
+	m.SetHistorySize(2)
+	for i := 1; i <= 5; i++ {
+		m.SetResults(&adapter.QueryResult{IsSelect: true, Columns: columns("n"), Rows: [][]string{{fmt.Sprint(i)}}})
+		m.SetQuery(fmt.Sprint("SELECT ", i))
+	}
+	if len(m.hist) != 2 || m.hist[0].query != "SELECT 3" {
+		t.Errorf("history = %d entries starting at %q, want 2 from SELECT 3", len(m.hist), m.hist[0].query)
+	}
+
+	m.SetHistorySize(0)
+	if len(m.hist) != 0 || m.showOlder() == nil {
+		t.Error("history size 0 should turn history off")
+	}
+}
+
+// --- Automatic LIMIT ---
+
+func TestAutoLimit_NoteAndRemove(t *testing.T) {
+	m := loaded(columns("id"), [][]string{{"1"}})
+	if _, cmd := m.Update(keyMsg("L")); cmd != nil {
+		t.Error("L without an automatic LIMIT should do nothing")
+	}
+	m.SetAutoLimit(1000)
+	if !strings.Contains(m.buildFooter(), "auto-limited to 1000 — press L to remove") {
+		t.Errorf("footer = %q, want the auto-limit note", m.buildFooter())
+	}
+	_, cmd := m.Update(keyMsg("L"))
+	if cmd == nil {
+		t.Fatal("L should ask for the query to be re-run")
+	}
+	if msg, ok := cmd().(RemoveLimitMsg); !ok || msg.TabID != 0 {
+		t.Errorf("got %#v, want RemoveLimitMsg for tab 0", cmd())
+	}
+}
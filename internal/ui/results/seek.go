@@ -0,0 +1,129 @@
+package results
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+)
+
+// Seeking reports whether the ":" prompt (go to row, paging overrides)
+// currently has keyboard focus.
+func (m Model) Seeking() bool {
+	return m.seeking
+}
+
+// startSeek opens the "go to row" prompt.
+func (m *Model) startSeek() tea.Cmd {
+	if len(m.columns) == 0 {
+		return nil
+	}
+	m.seeking = true
+	m.seekBox.SetValue("")
+	return m.seekBox.Focus()
+}
+
+func (m *Model) stopSeek() {
+	m.seeking = false
+	m.seekBox.Blur()
+	m.seekBox.SetValue("")
+}
+
+// updateSeek handles key presses while the ":" prompt is focused. A number
+// jumps to that row; "page N" and "buffer N" override paging for this tab;
+// "find TEXT" searches the selected column.
+func (m Model) updateSeek(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.stopSeek()
+		return m, nil
+	case "enter":
+		text := strings.TrimSpace(m.seekBox.Value())
+		m.stopSeek()
+		if cmd, ok := m.pagingCommand(text); ok {
+			return m, cmd
+		}
+		if cmd, ok := m.findCommand(text); ok {
+			return m, cmd
+		}
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil || n < 1 {
+			return m, statusCmd(fmt.Sprintf("Invalid row number %q", text), true)
+		}
+		return m, m.goToRow(n)
+	}
+
+	var cmd tea.Cmd
+	m.seekBox, cmd = m.seekBox.Update(msg)
+	return m, cmd
+}
+
+// goToRow selects the row with 1-based absolute number n. Rows in the
+// buffer are selected directly; otherwise a streaming iterator that
+// implements adapter.Seeker jumps there and loads a page starting at n.
+func (m *Model) goToRow(n int64) tea.Cmd {
+	src := n - 1 - int64(m.offset)
+	if src >= 0 && src < int64(len(m.allRows)) {
+		for i := range m.rows {
+			j := i
+			if i < len(m.rowIndex) {
+				j = m.rowIndex[i]
+			}
+			if int64(j) == src {
+				m.table.SetCursor(i)
+				m.updateViewTop()
+				return nil
+			}
+		}
+		return statusCmd(fmt.Sprintf("Row %d is hidden by the filter", n), true)
+	}
+
+	if m.iterator == nil {
+		return statusCmd(fmt.Sprintf("Row %d is past the end of the result", n), true)
+	}
+	seeker, ok := m.iterator.(adapter.Seeker)
+	if !ok {
+		return statusCmd("This result cannot jump to a row", true)
+	}
+	m.loading = true
+	return seekPage(m.iterator, seeker, n-1, m.tabID)
+}
+
+// seekPage returns a tea.Cmd that moves the iterator to offset and fetches
+// the page starting there.
+func seekPage(iter adapter.RowIterator, seeker adapter.Seeker, offset int64, tabID int) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		msg := FetchedPageMsg{Forward: true, Seek: true, Offset: int(offset), TabID: tabID}
+		if err := seeker.Seek(ctx, offset); err != nil {
+			msg.Err = err
+			return msg
+		}
+		msg.Rows, msg.Err = iter.FetchNext(ctx)
+		return msg
+	}
+}
+
+// applySeek replaces the buffer with a page loaded by seekPage.
+func (m *Model) applySeek(msg FetchedPageMsg) {
+	m.allRows = msg.Rows
+	m.offset = msg.Offset
+	m.table.SetCursor(0)
+	m.viewTop = 0
+	m.applyView()
+}
+
+// newSeekInput builds the text input used for the "go to row" prompt.
+func newSeekInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = ":"
+	ti.Placeholder = "row number, find TEXT, page N, or buffer N"
+	ti.CharLimit = 200
+	return ti
+}
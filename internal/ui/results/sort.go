@@ -0,0 +1,95 @@
+package results
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+)
+
+// SortQueryMsg asks the app to re-run a streaming query with an ORDER BY
+// clause. Client-side sorting of a streaming result only reorders the rows
+// that are currently buffered, so the app offers a server-side re-run.
+type SortQueryMsg struct {
+	TabID  int
+	Column string
+	Desc   bool
+}
+
+// toggleSort cycles the sort state of column col: ascending, descending,
+// then unsorted. Selecting a different column starts again at ascending.
+// For streaming results it returns a command offering a server-side sort.
+func (m *Model) toggleSort(col int) tea.Cmd {
+	if col < 0 || col >= len(m.columns) {
+		return nil
+	}
+	switch {
+	case m.sortCol != col:
+		m.sortCol = col
+		m.sortDesc = false
+	case !m.sortDesc:
+		m.sortDesc = true
+	default:
+		m.sortCol = -1
+		m.sortDesc = false
+	}
+	m.applyView()
+
+	if m.iterator == nil || m.sortCol < 0 {
+		return nil
+	}
+	msg := SortQueryMsg{TabID: m.tabID, Column: m.columns[col].Name, Desc: m.sortDesc}
+	return func() tea.Msg { return msg }
+}
+
+// sortIndices stably sorts idx, a list of indices into rows, by the value
+// in column col. NULLs come last in either direction.
+func sortIndices(idx []int, rows [][]string, col int, desc bool) {
+	sort.SliceStable(idx, func(i, j int) bool {
+		a, b := cellAt(rows[idx[i]], col), cellAt(rows[idx[j]], col)
+		if aNull, bNull := adapter.IsNull(a), adapter.IsNull(b); aNull || bNull {
+			return !aNull
+		}
+		if desc {
+			return compareCells(b, a) < 0
+		}
+		return compareCells(a, b) < 0
+	})
+}
+
+func cellAt(row []string, col int) string {
+	if col < len(row) {
+		return row[col]
+	}
+	return ""
+}
+
+// compareCells orders two cell values. NULLs sort after all other values;
+// values that both parse as numbers compare numerically, everything else
+// compares as text.
+func compareCells(a, b string) int {
+	aNull, bNull := adapter.IsNull(a), adapter.IsNull(b)
+	switch {
+	case aNull && bNull:
+		return 0
+	case aNull:
+		return 1
+	case bNull:
+		return -1
+	}
+
+	fa, errA := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	fb, errB := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	if errA == nil && errB == nil {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
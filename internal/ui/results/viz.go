@@ -0,0 +1,148 @@
+package results
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/sadopc/gotermsql/internal/adapter"
+)
+
+// barWidth is the width of the inline bar drawn next to numeric values.
+const barWidth = 10
+
+// sparkWidth is the maximum number of points in the footer sparkline.
+const sparkWidth = 30
+
+// barBlocks are the partial blocks used to draw bars in 1/8 cell steps.
+var barBlocks = []rune(" ▏▎▍▌▋▊▉█")
+
+// sparkBlocks are the sparkline levels, lowest first.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// toggleBars draws bars for the selected column, or turns them off. Only
+// columns whose loaded values are all numeric can be charted.
+func (m *Model) toggleBars() string {
+	src := m.srcCol(m.colCursor)
+	if src < 0 {
+		return ""
+	}
+	if m.barCol == src {
+		m.barCol = -1
+		m.sizeColumns()
+		return ""
+	}
+	if !columnStats(m.rows, src).numeric {
+		return m.columns[src].Name + " is not a numeric column"
+	}
+	m.barCol = src
+	m.barMax = barScale(m.rows, src)
+	m.sizeColumns()
+	return ""
+}
+
+// barScale returns the largest absolute value of column col, which maps to
+// a full bar.
+func barScale(rows [][]string, col int) float64 {
+	var max float64
+	for _, row := range rows {
+		if f, ok := numericCell(cellAt(row, col)); ok && math.Abs(f) > max {
+			max = math.Abs(f)
+		}
+	}
+	return max
+}
+
+// numericCell parses a cell as a number. NULL and text are not numbers.
+func numericCell(v string) (float64, bool) {
+	if adapter.IsNull(v) {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	return f, err == nil
+}
+
+// bar renders |v| as a horizontal bar of width cells, scaled so max fills
+// the whole width.
+func bar(v, max float64, width int) string {
+	if max <= 0 || width <= 0 {
+		return strings.Repeat(" ", width)
+	}
+	eighths := int(math.Round(math.Abs(v) / max * float64(width*8)))
+	full := eighths / 8
+	if full > width {
+		full = width
+	}
+	s := strings.Repeat(string(barBlocks[8]), full)
+	if full < width {
+		s += string(barBlocks[eighths%8])
+		s += strings.Repeat(" ", width-full-1)
+	}
+	return s
+}
+
+// barCell renders a value of the charted column: the value, then a bar,
+// within the column's natural width.
+func (m Model) barCell(value string, src, width int) string {
+	text := m.formatCell(value, src, width-barWidth-1)
+	b := strings.Repeat(" ", barWidth)
+	if f, ok := numericCell(value); ok {
+		b = bar(f, m.barMax, barWidth)
+	}
+	return text + " " + b
+}
+
+// sparkline summarises the values of column col in row order, averaging
+// neighbouring values when there are more rows than points.
+func sparkline(rows [][]string, col, width int) string {
+	var vals []float64
+	for _, row := range rows {
+		if f, ok := numericCell(cellAt(row, col)); ok {
+			vals = append(vals, f)
+		}
+	}
+	if len(vals) == 0 || width <= 0 {
+		return ""
+	}
+
+	n := len(vals)
+	if n > width {
+		n = width
+	}
+	points := make([]float64, n)
+	for i := range points {
+		lo, hi := i*len(vals)/n, (i+1)*len(vals)/n
+		var sum float64
+		for _, v := range vals[lo:hi] {
+			sum += v
+		}
+		points[i] = sum / float64(hi-lo)
+	}
+
+	min, max := points[0], points[0]
+	for _, p := range points {
+		min = math.Min(min, p)
+		max = math.Max(max, p)
+	}
+	var sb strings.Builder
+	for _, p := range points {
+		level := 0
+		if max > min {
+			level = int((p - min) / (max - min) * float64(len(sparkBlocks)-1))
+		}
+		sb.WriteRune(sparkBlocks[level])
+	}
+	return sb.String()
+}
+
+// sparkNote returns the footer sparkline for the charted column, or "".
+func (m Model) sparkNote() string {
+	if m.barCol < 0 || m.barCol >= len(m.columns) {
+		return ""
+	}
+	s := sparkline(m.rows, m.barCol, sparkWidth)
+	if s == "" {
+		return ""
+	}
+	return m.columns[m.barCol].Name + " " + s
+}
@@ -0,0 +1,374 @@
+// Package rowform is the form that inserts a row into a table, opened from
+// the sidebar menu: a field per column, showing its type, whether it takes
+// NULL, its default and the column its foreign key references, whose
+// values can be looked up and picked.
+package rowform
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+// Field is a column of the form, with the table and column its foreign
+// key references ("" for none).
+type Field struct {
+	Column    schema.Column
+	RefTable  string
+	RefColumn string
+}
+
+// SubmitMsg asks the app to insert the row: the columns given a value and
+// their values, adapter.NullValue for NULL. Columns left empty are left
+// out, for the database to fill in their default.
+type SubmitMsg struct {
+	Columns []schema.Column
+	Values  []string
+}
+
+// LookupMsg asks the app for the values Field may take: those of the
+// column its foreign key references.
+type LookupMsg struct {
+	Field  int
+	Table  string
+	Column string
+}
+
+// Option is a value a lookup offers, with the rest of its row as Label.
+type Option struct {
+	Value string
+	Label string
+}
+
+// Model is the insert form modal.
+type Model struct {
+	table   string
+	fields  []Field
+	inputs  []textinput.Model
+	nulls   []bool // fields set to NULL
+	focus   int
+	offset  int
+	message string
+	failed  bool
+	busy    bool // the INSERT is running
+	visible bool
+	width   int
+	height  int
+
+	// lookup is the field whose values are looked up, or -1.
+	lookup        int
+	options       []Option
+	filter        string
+	lookupCursor  int
+	lookupLoading bool
+}
+
+// New creates a hidden form.
+func New() Model {
+	return Model{lookup: -1}
+}
+
+// Show opens the form to insert a row into table.
+func (m *Model) Show(table string, fields []Field) {
+	width := 0
+	for _, f := range fields {
+		width = max(width, runewidth.StringWidth(f.Column.Name))
+	}
+	inputs := make([]textinput.Model, len(fields))
+	for i, f := range fields {
+		in := textinput.New()
+		in.Prompt = runewidth.FillRight(f.Column.Name, width) + "  "
+		in.Width = 30
+		switch {
+		case f.Column.Default != "":
+			in.Placeholder = "default"
+		case f.Column.Nullable:
+			in.Placeholder = "NULL"
+		}
+		inputs[i] = in
+	}
+	*m = Model{table: table, fields: fields, inputs: inputs, nulls: make([]bool, len(fields)),
+		lookup: -1, visible: true, width: m.width, height: m.height}
+	if len(inputs) > 0 {
+		m.inputs[0].Focus()
+	}
+}
+
+// Hide closes the form.
+func (m *Model) Hide() {
+	m.visible = false
+}
+
+// Visible returns whether the form is shown.
+func (m Model) Visible() bool { return m.visible }
+
+// Table returns the table the form inserts into.
+func (m Model) Table() string { return m.table }
+
+// SetSize sets the available space.
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.ensureVisible()
+}
+
+// SetError shows why the row was not inserted, leaving the values typed
+// to correct.
+func (m *Model) SetError(text string) {
+	m.message = text
+	m.failed = true
+	m.busy = false
+}
+
+// SetLookup shows the values field may take, when it is still looked up.
+func (m *Model) SetLookup(field int, options []Option) {
+	if field != m.lookup {
+		return
+	}
+	m.options = options
+	m.lookupLoading = false
+	m.lookupCursor = 0
+}
+
+// SetLookupError closes the lookup of field, showing why it failed.
+func (m *Model) SetLookupError(field int, text string) {
+	if field != m.lookup {
+		return
+	}
+	m.lookup = -1
+	m.lookupLoading = false
+	m.SetError(text)
+}
+
+// Update handles key presses: tab and the arrows move between fields,
+// ctrl+n sets a field to NULL, ctrl+f looks up the values of a foreign
+// key, enter inserts the row and esc closes.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !m.visible || !ok || m.busy || len(m.fields) == 0 && key.String() != "esc" {
+		return m, nil
+	}
+	if m.lookup >= 0 {
+		m.updateLookup(key)
+		return m, nil
+	}
+	switch key.String() {
+	case "esc":
+		m.visible = false
+		return m, nil
+	case "tab", "down":
+		return m, m.moveFocus(1)
+	case "shift+tab", "up":
+		return m, m.moveFocus(-1)
+	case "ctrl+n":
+		m.nulls[m.focus] = !m.nulls[m.focus]
+		m.inputs[m.focus].SetValue("")
+		return m, nil
+	case "ctrl+f":
+		f := m.fields[m.focus]
+		if f.RefTable == "" {
+			m.message, m.failed = f.Column.Name+" references no table", true
+			return m, nil
+		}
+		m.lookup, m.lookupLoading = m.focus, true
+		m.options, m.filter, m.lookupCursor = nil, "", 0
+		lookup := LookupMsg{Field: m.focus, Table: f.RefTable, Column: f.RefColumn}
+		return m, func() tea.Msg { return lookup }
+	case "enter":
+		return m, m.submit()
+	}
+	m.nulls[m.focus] = false
+	var cmd tea.Cmd
+	m.inputs[m.focus], cmd = m.inputs[m.focus].Update(key)
+	return m, cmd
+}
+
+// submit checks every column that needs a value has one and sends the
+// values typed.
+func (m *Model) submit() tea.Cmd {
+	var submit SubmitMsg
+	for i, f := range m.fields {
+		value := m.inputs[i].Value()
+		switch {
+		case m.nulls[i]:
+			value = adapter.NullValue
+		case value == "":
+			// A primary key without a default may be numbered by the
+			// database all the same (AUTO_INCREMENT, SQLite's rowid).
+			if !f.Column.Nullable && f.Column.Default == "" && !f.Column.IsPK {
+				m.message, m.failed = f.Column.Name+" needs a value", true
+				m.inputs[m.focus].Blur()
+				m.focus = i
+				m.ensureVisible()
+				return m.inputs[i].Focus()
+			}
+			continue
+		}
+		submit.Columns = append(submit.Columns, f.Column)
+		submit.Values = append(submit.Values, value)
+	}
+	m.busy = true
+	m.message, m.failed = "Inserting...", false
+	return func() tea.Msg { return submit }
+}
+
+// updateLookup handles a key press while the values of a foreign key are
+// listed: typing filters them, enter picks one and esc goes back.
+func (m *Model) updateLookup(key tea.KeyMsg) {
+	options := m.filtered()
+	switch key.String() {
+	case "esc":
+		m.lookup = -1
+	case "up":
+		m.lookupCursor = max(m.lookupCursor-1, 0)
+	case "down":
+		m.lookupCursor = max(min(m.lookupCursor+1, len(options)-1), 0)
+	case "enter":
+		if m.lookupCursor < len(options) {
+			m.inputs[m.lookup].SetValue(options[m.lookupCursor].Value)
+			m.nulls[m.lookup] = false
+			m.lookup = -1
+		}
+	case "backspace":
+		if r := []rune(m.filter); len(r) > 0 {
+			m.filter = string(r[:len(r)-1])
+			m.lookupCursor = 0
+		}
+	default:
+		if key.Type == tea.KeyRunes {
+			m.filter += string(key.Runes)
+			m.lookupCursor = 0
+		}
+	}
+}
+
+// filtered returns the options of the lookup whose value or label holds
+// the filter, ignoring case.
+func (m Model) filtered() []Option {
+	if m.filter == "" {
+		return m.options
+	}
+	filter := strings.ToLower(m.filter)
+	var options []Option
+	for _, o := range m.options {
+		if strings.Contains(strings.ToLower(o.Value+" "+o.Label), filter) {
+			options = append(options, o)
+		}
+	}
+	return options
+}
+
+// moveFocus moves the focus by delta fields, wrapping around.
+func (m *Model) moveFocus(delta int) tea.Cmd {
+	m.inputs[m.focus].Blur()
+	m.focus = (m.focus + delta + len(m.inputs)) % len(m.inputs)
+	m.ensureVisible()
+	return m.inputs[m.focus].Focus()
+}
+
+// listRows returns how many fields, or lookup values, fit in the form.
+func (m Model) listRows() int {
+	// Title, message, help, the blank lines between them and the border.
+	return max(m.height-9, 3)
+}
+
+func (m *Model) ensureVisible() {
+	rows := m.listRows()
+	if m.focus < m.offset {
+		m.offset = m.focus
+	}
+	if m.focus >= m.offset+rows {
+		m.offset = m.focus - rows + 1
+	}
+}
+
+// hint describes a column: its type, whether it takes NULL, its default
+// and the column its foreign key references.
+func hint(f Field) string {
+	parts := []string{f.Column.Type}
+	if f.Column.IsPK {
+		parts = append(parts, "primary key")
+	}
+	if !f.Column.Nullable {
+		parts = append(parts, "NOT NULL")
+	}
+	if f.Column.Default != "" {
+		parts = append(parts, "default "+f.Column.Default)
+	}
+	if f.RefTable != "" {
+		parts = append(parts, "→ "+f.RefTable+"."+f.RefColumn)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// View renders the form.
+func (m Model) View() string {
+	if !m.visible {
+		return ""
+	}
+	th := theme.Current
+	w := 100
+	if m.width > 0 && w > m.width-4 {
+		w = m.width - 4
+	}
+	textW := w - 6
+
+	lines := []string{th.DialogTitle.Render("  Insert Row: " + m.table + "  "), ""}
+	var help string
+	if m.lookup >= 0 {
+		f := m.fields[m.lookup]
+		lines = append(lines, th.MutedText.Render("  "+f.Column.Name+" → "+f.RefTable+"."+f.RefColumn+"   filter: "+m.filter))
+		options := m.filtered()
+		offset := max(m.lookupCursor-m.listRows()+2, 0)
+		end := min(offset+m.listRows()-1, len(options))
+		for i := offset; i < end; i++ {
+			line := runewidth.Truncate(runewidth.FillRight(runewidth.Truncate(options[i].Value, 24, "…"), 26)+options[i].Label, textW, "…")
+			if i == m.lookupCursor {
+				lines = append(lines, "  "+th.SidebarSelected.Render(runewidth.FillRight(line, textW)))
+			} else {
+				lines = append(lines, "  "+line)
+			}
+		}
+		switch {
+		case m.lookupLoading:
+			lines = append(lines, th.MutedText.Render("  Reading "+f.RefTable+"..."))
+		case len(options) == 0:
+			lines = append(lines, th.MutedText.Render("  No values"))
+		}
+		help = "type to filter  enter:pick  esc:back"
+	} else {
+		end := min(m.offset+m.listRows(), len(m.fields))
+		for i := m.offset; i < end; i++ {
+			field := m.inputs[i].View()
+			if m.nulls[i] {
+				field = m.inputs[i].Prompt + th.MutedText.Render(runewidth.FillRight("NULL", m.inputs[i].Width+1))
+			}
+			// An input pads itself differently with and without a
+			// placeholder, so the hints are aligned here.
+			used := runewidth.StringWidth(m.inputs[i].Prompt) + m.inputs[i].Width + 2
+			field += strings.Repeat(" ", max(used-lipgloss.Width(field), 0))
+			lines = append(lines, "  "+field+"  "+th.MutedText.Render(runewidth.Truncate(hint(m.fields[i]), max(textW-used-2, 0), "…")))
+		}
+		if len(m.fields) == 0 {
+			lines = append(lines, th.MutedText.Render("  No columns"))
+		}
+		help = "tab:next  ctrl+n:NULL  ctrl+f:look up  enter:insert  esc:close"
+	}
+
+	style := th.MutedText
+	if m.failed {
+		style = th.ErrorText
+	}
+	message := m.message
+	if message == "" {
+		message = "Empty fields are left out, for their default or NULL"
+	}
+	lines = append(lines, "", style.Render("  "+runewidth.Truncate(message, textW, "…")), th.MutedText.Render("  "+help))
+	return th.DialogBorder.Width(w).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,97 @@
+package rowform
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "ctrl+n":
+		return tea.KeyMsg{Type: tea.KeyCtrlN}
+	case "ctrl+f":
+		return tea.KeyMsg{Type: tea.KeyCtrlF}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+var orderFields = []Field{
+	{Column: schema.Column{Name: "id", Type: "integer", IsPK: true}},
+	{Column: schema.Column{Name: "customer_id", Type: "integer"}, RefTable: "customers", RefColumn: "id"},
+	{Column: schema.Column{Name: "note", Type: "text", Nullable: true}},
+	{Column: schema.Column{Name: "status", Type: "text", Default: "'new'"}},
+}
+
+func TestForm(t *testing.T) {
+	m := New()
+	m.SetSize(120, 30)
+	m.Show("orders", orderFields)
+	view := m.View()
+	for _, want := range []string{"Insert Row: orders", "integer · primary key · NOT NULL", "→ customers.id", "default 'new'"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+
+	// customer_id needs a value.
+	m, cmd := m.Update(key("enter"))
+	if _, ok := cmd().(SubmitMsg); ok || !strings.Contains(m.View(), "customer_id needs a value") {
+		t.Fatalf("enter should ask for customer_id:\n%s", m.View())
+	}
+
+	// Look it up and pick the second customer.
+	m, cmd = m.Update(key("ctrl+f"))
+	if cmd == nil || cmd() != (LookupMsg{Field: 1, Table: "customers", Column: "id"}) {
+		t.Fatal("ctrl+f should look up customers.id")
+	}
+	m.SetLookup(1, []Option{{Value: "1", Label: "ann"}, {Value: "2", Label: "bob"}})
+	m, _ = m.Update(key("bo"))
+	if view := m.View(); strings.Contains(view, "ann") || !strings.Contains(view, "bob") {
+		t.Errorf("filtering by bo should list bob only:\n%s", view)
+	}
+	m, _ = m.Update(key("enter"))
+
+	m, _ = m.Update(key("tab"))
+	m, _ = m.Update(key("ctrl+n"))
+	m, cmd = m.Update(key("enter"))
+	if cmd == nil {
+		t.Fatalf("enter should insert the row:\n%s", m.View())
+	}
+	got := cmd().(SubmitMsg)
+	if len(got.Columns) != 2 || got.Columns[0].Name != "customer_id" || got.Values[0] != "2" ||
+		got.Columns[1].Name != "note" || got.Values[1] != adapter.NullValue {
+		t.Errorf("submitted %+v, want customer_id 2 and note NULL", got)
+	}
+	if _, cmd := m.Update(key("esc")); cmd != nil {
+		t.Error("keys should wait while the row is inserted")
+	}
+
+	m.SetError("Insert failed: boom")
+	if !strings.Contains(m.View(), "Insert failed: boom") {
+		t.Errorf("view lacks the error:\n%s", m.View())
+	}
+	if m, _ = m.Update(key("esc")); m.Visible() {
+		t.Error("esc should close the form")
+	}
+}
+
+func TestForm_NoReference(t *testing.T) {
+	m := New()
+	m.Show("orders", orderFields)
+	m, cmd := m.Update(key("ctrl+f"))
+	if cmd != nil || !strings.Contains(m.View(), "id references no table") {
+		t.Errorf("ctrl+f on id should look nothing up:\n%s", m.View())
+	}
+}
@@ -0,0 +1,393 @@
+// Package schemadiff is the schema comparison opened with Alt+D: pick two
+// schemas (the connection open, a saved connection or a snapshot), list
+// the objects that differ between them, and open the migration that turns
+// the first into the second in a query tab.
+package schemadiff
+
+import (
+	"fmt"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	appmsg "github.com/sadopc/gotermsql/internal/msg"
+	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+// writeClipboard is swapped out in tests.
+var writeClipboard = clipboard.WriteAll
+
+// SourceKind is where the schema of a Source comes from.
+type SourceKind int
+
+const (
+	SourceCurrent  SourceKind = iota // the connection open
+	SourceSaved                      // a saved connection, connected to for the comparison
+	SourceSnapshot                   // a snapshot saved earlier
+)
+
+// Source is a schema that can be compared.
+type Source struct {
+	Kind   SourceKind
+	Name   string // the saved connection or snapshot
+	Detail string // its adapter, when the snapshot was taken
+}
+
+// Label names the source in the list.
+func (s Source) Label() string {
+	switch s.Kind {
+	case SourceCurrent:
+		return "Current connection"
+	case SourceSnapshot:
+		return "Snapshot " + s.Name
+	}
+	return s.Name
+}
+
+// CompareMsg asks the app to load the schemas of From and To and compare
+// them.
+type CompareMsg struct {
+	From, To Source
+}
+
+// SnapshotMsg asks the app to save the schema of the connection open as a
+// snapshot.
+type SnapshotMsg struct{}
+
+// CancelMsg asks the app to stop loading the schemas, as the comparison
+// was closed.
+type CancelMsg struct{}
+
+// maxDetails is how many lines of changes are shown for the selected
+// object.
+const maxDetails = 6
+
+// Model is the schema comparison modal.
+type Model struct {
+	sources []Source
+	from    *Source // picked first, or nil
+	cursor  int
+	offset  int
+	visible bool
+	width   int
+	height  int
+
+	loading bool
+	shown   bool // the comparison is shown rather than the sources
+
+	fromLabel, toLabel string
+	diffs              []schema.ObjectDiff
+	script             string
+
+	message string
+	notice  bool // message is news rather than a problem
+}
+
+// New creates a hidden schema comparison.
+func New() Model {
+	return Model{}
+}
+
+// Show opens the comparison on the list of sources.
+func (m *Model) Show(sources []Source) {
+	*m = Model{sources: sources, visible: true, width: m.width, height: m.height}
+}
+
+// SetSources replaces the list of sources, as after a snapshot is saved.
+func (m *Model) SetSources(sources []Source) {
+	m.sources = sources
+	m.clamp()
+}
+
+// Hide closes the comparison.
+func (m *Model) Hide() {
+	m.visible = false
+}
+
+// Visible returns whether the comparison is shown.
+func (m Model) Visible() bool { return m.visible }
+
+// Loading returns whether the schemas are being loaded.
+func (m Model) Loading() bool { return m.loading }
+
+// Diffs returns the objects that differ, in the order listed.
+func (m Model) Diffs() []schema.ObjectDiff { return m.diffs }
+
+// Script returns the migration of the comparison shown.
+func (m Model) Script() string { return m.script }
+
+// SetSize sets the available space.
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// SetMessage shows text in place of the key help until the next key press,
+// as an error unless notice is set.
+func (m *Model) SetMessage(text string, notice bool) {
+	m.message = text
+	m.notice = notice
+	m.loading = false
+}
+
+// SetDiff shows the objects that differ from the schema labelled from to
+// the one labelled to, and the migration between them.
+func (m *Model) SetDiff(from, to string, diffs []schema.ObjectDiff, script string) {
+	m.fromLabel, m.toLabel = from, to
+	m.diffs = diffs
+	m.script = script
+	m.loading = false
+	m.shown = true
+	m.cursor, m.offset = 0, 0
+}
+
+// Update handles key presses. Among the sources, enter picks the schema to
+// change and then the one to compare it with, s saves a snapshot of the
+// connection open, and esc steps back. Among the differences, e opens the
+// migration in a new tab, y copies it and b goes back to the sources.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !m.visible || !ok {
+		return m, nil
+	}
+	m.message = ""
+	switch key.String() {
+	case "esc", "q":
+		switch {
+		case m.loading:
+			m.loading = false
+			m.visible = false
+			return m, func() tea.Msg { return CancelMsg{} }
+		case !m.shown && m.from != nil:
+			m.from = nil
+		default:
+			m.visible = false
+		}
+		return m, nil
+	case "up", "k":
+		m.cursor--
+	case "down", "j":
+		m.cursor++
+	case "pgup":
+		m.cursor -= m.listRows()
+	case "pgdown":
+		m.cursor += m.listRows()
+	case "home", "g":
+		m.cursor = 0
+	case "end", "G":
+		m.cursor = m.count() - 1
+	}
+	if m.loading {
+		m.clamp()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	if m.shown {
+		cmd = m.diffKey(key.String())
+	} else {
+		cmd = m.sourceKey(key.String())
+	}
+	m.clamp()
+	return m, cmd
+}
+
+// sourceKey handles a key among the sources.
+func (m *Model) sourceKey(key string) tea.Cmd {
+	switch key {
+	case "enter":
+		if m.cursor >= len(m.sources) {
+			return nil
+		}
+		picked := m.sources[m.cursor]
+		if m.from == nil {
+			m.from = &picked
+			return nil
+		}
+		m.loading = true
+		compare := CompareMsg{From: *m.from, To: picked}
+		return func() tea.Msg { return compare }
+	case "s":
+		return func() tea.Msg { return SnapshotMsg{} }
+	}
+	return nil
+}
+
+// diffKey handles a key among the differences.
+func (m *Model) diffKey(key string) tea.Cmd {
+	switch key {
+	case "e":
+		if m.script == "" {
+			return nil
+		}
+		m.visible = false
+		script := m.script
+		return func() tea.Msg { return appmsg.NewTabMsg{Query: script} }
+	case "y":
+		if m.script == "" {
+			return nil
+		}
+		script := m.script
+		return func() tea.Msg {
+			if err := writeClipboard(script); err != nil {
+				return appmsg.StatusMsg{Text: "Copy failed: " + err.Error(), IsError: true}
+			}
+			return appmsg.StatusMsg{Text: "Copied the migration"}
+		}
+	case "b", "backspace":
+		m.shown = false
+		m.from = nil
+		m.cursor, m.offset = 0, 0
+	}
+	return nil
+}
+
+// count returns how many rows the list has.
+func (m Model) count() int {
+	if m.shown {
+		return len(m.diffs)
+	}
+	return len(m.sources)
+}
+
+func (m *Model) clamp() {
+	m.cursor = max(min(m.cursor, m.count()-1), 0)
+	rows := m.listRows()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+}
+
+// listRows returns how many rows of the list fit in the modal.
+func (m Model) listRows() int {
+	// Title, subtitle, the details, help, the blank lines between them
+	// and the border.
+	return max(m.height-maxDetails-10, 3)
+}
+
+// View renders the comparison.
+func (m Model) View() string {
+	if !m.visible {
+		return ""
+	}
+	th := theme.Current
+	w := 100
+	if m.width > 0 && w > m.width-4 {
+		w = m.width - 4
+	}
+	textW := w - 6
+
+	var lines []string
+	if m.shown {
+		lines = m.diffLines(textW)
+	} else {
+		lines = m.sourceLines(textW)
+	}
+
+	lines = append(lines, "")
+	switch {
+	case m.loading:
+		lines = append(lines, th.MutedText.Render("  Loading the schemas... (esc stops)"))
+	case m.message != "" && m.notice:
+		lines = append(lines, th.SuccessText.Render("  "+runewidth.Truncate(m.message, textW, "…")))
+	case m.message != "":
+		lines = append(lines, th.ErrorText.Render("  "+runewidth.Truncate(m.message, textW, "…")))
+	case m.shown:
+		lines = append(lines, th.MutedText.Render("  e:open migration in a tab  y:copy migration  b:back  esc:close"))
+	default:
+		lines = append(lines, th.MutedText.Render("  enter:pick  s:snapshot current schema  esc:back/close"))
+	}
+	return th.DialogBorder.Width(w).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// sourceLines renders the list of sources to pick from.
+func (m Model) sourceLines(textW int) []string {
+	th := theme.Current
+	prompt := "Pick the schema to change"
+	if m.from != nil {
+		prompt = "Change " + m.from.Label() + " into..."
+	}
+	lines := []string{th.DialogTitle.Render("  Compare Schemas  "), "", th.MutedText.Render("  " + prompt), ""}
+
+	rows := m.listRows() + maxDetails
+	end := min(m.offset+rows, len(m.sources))
+	for i := m.offset; i < end; i++ {
+		s := m.sources[i]
+		line := runewidth.FillRight(runewidth.Truncate(s.Label(), 40, "…"), 41) + th.MutedText.Render(s.Detail)
+		if m.from != nil && *m.from == s {
+			line = runewidth.FillRight(runewidth.Truncate(s.Label()+" (picked)", 40, "…"), 41) + s.Detail
+		}
+		line = runewidth.Truncate(line, textW, "…")
+		if i == m.cursor {
+			lines = append(lines, "  "+th.SidebarSelected.Render(runewidth.FillRight(line, textW)))
+		} else {
+			lines = append(lines, "  "+line)
+		}
+	}
+	if len(m.sources) == 0 {
+		lines = append(lines, th.MutedText.Render("  No connections or snapshots"))
+		rows--
+	}
+	for i := end - m.offset; i < rows; i++ {
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+// diffLines renders the objects that differ and the changes of the
+// selected one.
+func (m Model) diffLines(textW int) []string {
+	th := theme.Current
+	title := fmt.Sprintf("  Schema Differences (%d)  ", len(m.diffs))
+	lines := []string{
+		th.DialogTitle.Render(title), "",
+		th.MutedText.Render("  " + runewidth.Truncate(m.fromLabel+" → "+m.toLabel, textW, "…")), "",
+	}
+
+	rows := m.listRows()
+	end := min(m.offset+rows, len(m.diffs))
+	for i := m.offset; i < end; i++ {
+		d := m.diffs[i]
+		mark, style := "~", th.WarningText
+		switch d.Change {
+		case schema.Added:
+			mark, style = "+", th.SuccessText
+		case schema.Dropped:
+			mark, style = "-", th.ErrorText
+		}
+		line := runewidth.Truncate(fmt.Sprintf("%s %-9s%s", mark, d.Kind, d.Label()), textW, "…")
+		if i == m.cursor {
+			lines = append(lines, "  "+th.SidebarSelected.Render(runewidth.FillRight(line, textW)))
+		} else {
+			lines = append(lines, "  "+style.Render(line))
+		}
+	}
+	if len(m.diffs) == 0 {
+		lines = append(lines, th.SuccessText.Render("  The schemas are the same"))
+		rows--
+	}
+	for i := end - m.offset; i < rows; i++ {
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, "")
+	var details []string
+	if m.cursor < len(m.diffs) {
+		details = m.diffs[m.cursor].Details()
+	}
+	for i := range maxDetails {
+		switch {
+		case i == maxDetails-1 && len(details) > maxDetails:
+			lines = append(lines, th.MutedText.Render(fmt.Sprintf("    … %d more", len(details)-i)))
+		case i < len(details):
+			lines = append(lines, "    "+runewidth.Truncate(details[i], textW-2, "…"))
+		default:
+			lines = append(lines, "")
+		}
+	}
+	return lines
+}
@@ -0,0 +1,92 @@
+package schemadiff
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	appmsg "github.com/sadopc/gotermsql/internal/msg"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestPickSources(t *testing.T) {
+	m := New()
+	m.SetSize(120, 30)
+	m.Show([]Source{
+		{Kind: SourceCurrent, Detail: "postgres app"},
+		{Kind: SourceSaved, Name: "staging", Detail: "postgres"},
+		{Kind: SourceSnapshot, Name: "before", Detail: "postgres, saved 2h ago"},
+	})
+	if view := m.View(); !strings.Contains(view, "Pick the schema to change") || !strings.Contains(view, "Snapshot before") {
+		t.Errorf("view lacks the sources:\n%s", view)
+	}
+
+	m, _ = m.Update(key("j"))
+	m, _ = m.Update(key("enter"))
+	if view := m.View(); !strings.Contains(view, "Change staging into") {
+		t.Errorf("view does not show the first pick:\n%s", view)
+	}
+	// esc takes the first pick back.
+	m, _ = m.Update(key("esc"))
+	if !m.Visible() || strings.Contains(m.View(), "Change staging") {
+		t.Fatal("esc should take the first pick back, not close")
+	}
+
+	m, _ = m.Update(key("enter"))
+	m, _ = m.Update(key("G"))
+	m, cmd := m.Update(key("enter"))
+	got, ok := cmd().(CompareMsg)
+	if !ok || got.From.Name != "staging" || got.To.Name != "before" {
+		t.Fatalf("picking sent %#v, want staging compared with the snapshot", got)
+	}
+	if !m.Loading() {
+		t.Error("the comparison should be loading")
+	}
+	m, cmd = m.Update(key("esc"))
+	if _, ok := cmd().(CancelMsg); !ok || m.Visible() {
+		t.Error("esc while loading should close and cancel")
+	}
+}
+
+func TestShowDiff(t *testing.T) {
+	m := New()
+	m.SetSize(120, 30)
+	m.Show(nil)
+	diffs := []schema.ObjectDiff{
+		{Kind: schema.KindTable, Schema: "public", Name: "orders", Change: schema.Added},
+		{Kind: schema.KindTable, Schema: "public", Name: "users", Change: schema.Altered, Changes: &schema.TableChanges{
+			AddedColumns: []schema.Column{{Name: "email", Type: "text"}},
+		}},
+	}
+	m.SetDiff("prod", "staging", diffs, "ALTER TABLE users ADD COLUMN email text;\n")
+	m, _ = m.Update(key("j"))
+	view := m.View()
+	for _, want := range []string{"Schema Differences (2)", "prod → staging", "+ table    public.orders", "column email added (text)"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view lacks %q:\n%s", want, view)
+		}
+	}
+
+	var copied string
+	writeClipboard = func(s string) error { copied = s; return nil }
+	_, cmd := m.Update(key("y"))
+	cmd()
+	if copied != m.Script() {
+		t.Errorf("y copied %q", copied)
+	}
+
+	m, cmd = m.Update(key("e"))
+	if tab, ok := cmd().(appmsg.NewTabMsg); !ok || tab.Query != m.Script() || m.Visible() {
+		t.Errorf("e sent %#v, want the migration in a new tab", tab)
+	}
+}
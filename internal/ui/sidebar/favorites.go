@@ -0,0 +1,139 @@
+package sidebar
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	appmsg "github.com/sadopc/gotermsql/internal/msg"
+)
+
+// SetFavorites sets the starred tables of the connection, as "schema.table".
+// They are listed in a Favorites section at the top of the tree.
+func (m *Model) SetFavorites(tables []string) {
+	m.favorites = append([]string(nil), tables...)
+	m.rebuildFavorites()
+}
+
+// Favorites returns the starred tables, as "schema.table".
+func (m Model) Favorites() []string {
+	return append([]string(nil), m.favorites...)
+}
+
+// favoriteKey returns how a table is recorded in the favorites.
+func favoriteKey(node *TreeNode) string {
+	return node.Schema + "." + node.Table
+}
+
+// canFavorite reports whether nodes of kind k can be starred.
+func canFavorite(k NodeKind) bool {
+	return k == NodeTable || k == NodeView || k == NodeMatView
+}
+
+func (m Model) isFavorite(node *TreeNode) bool {
+	key := favoriteKey(node)
+	for _, f := range m.favorites {
+		if f == key {
+			return true
+		}
+	}
+	return false
+}
+
+// toggleFavorite stars or unstars the selected table or view.
+func (m *Model) toggleFavorite() tea.Cmd {
+	if m.cursor >= len(m.flat) || !canFavorite(m.flat[m.cursor].Kind) {
+		return nil
+	}
+	return m.toggleFavoriteFor(m.flat[m.cursor])
+}
+
+func (m *Model) toggleFavoriteFor(node *TreeNode) tea.Cmd {
+	key := favoriteKey(node)
+	status := "Added " + node.Table + " to favorites"
+	if m.isFavorite(node) {
+		status = "Removed " + node.Table + " from favorites"
+		kept := m.favorites[:0:0]
+		for _, f := range m.favorites {
+			if f != key {
+				kept = append(kept, f)
+			}
+		}
+		m.favorites = kept
+	} else {
+		m.favorites = append(m.favorites, key)
+	}
+	m.rebuildFavorites()
+
+	changed := appmsg.FavoritesChangedMsg{Tables: m.Favorites()}
+	return tea.Batch(
+		func() tea.Msg { return changed },
+		statusCmd(status, false),
+	)
+}
+
+// rebuildFavorites replaces the Favorites section with copies of the
+// starred tables found in the tree, keeping the selection and whether the
+// section was expanded. Favorites missing from the schema are skipped but
+// kept, so they come back when the table does.
+func (m *Model) rebuildFavorites() {
+	var selected *TreeNode
+	if m.cursor < len(m.flat) {
+		selected = m.flat[m.cursor]
+	}
+	expanded := true
+	if len(m.nodes) > 0 && m.nodes[0].Kind == NodeFavoriteGroup {
+		expanded = m.nodes[0].Expanded
+		m.nodes = m.nodes[1:]
+	}
+
+	found := make(map[string]*TreeNode)
+	for _, n := range m.nodes {
+		indexTables(n, found)
+	}
+	group := &TreeNode{Kind: NodeFavoriteGroup, Expanded: expanded}
+	for _, key := range m.favorites {
+		if n, ok := found[key]; ok {
+			fav := cloneAt(n, 1)
+			if n.Schema != "" && n.Schema != "main" && n.Schema != "public" {
+				fav.Label = key
+			}
+			group.Children = append(group.Children, fav)
+		}
+	}
+	if len(group.Children) > 0 {
+		group.Label = fmt.Sprintf("Favorites (%d)", len(group.Children))
+		m.nodes = append([]*TreeNode{group}, m.nodes...)
+	}
+
+	m.flatten()
+	for i, n := range m.flat {
+		if n == selected {
+			m.cursor = i
+			break
+		}
+	}
+	m.ensureVisible()
+}
+
+// indexTables records the table and view nodes below node by favorite key.
+func indexTables(node *TreeNode, found map[string]*TreeNode) {
+	if canFavorite(node.Kind) {
+		found[favoriteKey(node)] = node
+		return
+	}
+	for _, c := range node.Children {
+		indexTables(c, found)
+	}
+}
+
+// cloneAt copies node and its children, collapsed, moving it to depth.
+func cloneAt(node *TreeNode, depth int) *TreeNode {
+	c := *node
+	c.Depth = depth
+	c.Expanded = false
+	c.Children = nil
+	for _, child := range node.Children {
+		c.Children = append(c.Children, cloneAt(child, depth+1))
+	}
+	return &c
+}
@@ -0,0 +1,170 @@
+package sidebar
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+// fkTarget returns the table and column a column references, or "" when it
+// is not part of a foreign key. Referenced tables are assumed to live in
+// the same schema, as adapters report them without one.
+func fkTarget(t schema.Table, column string) (table, refColumn string) {
+	for _, fk := range t.FKs {
+		for i, c := range fk.Columns {
+			if c == column && i < len(fk.RefColumns) {
+				return fk.RefTable, fk.RefColumns[i]
+			}
+		}
+	}
+	return "", ""
+}
+
+// addReferencedBy adds a "Referenced by" group under each table node that
+// other tables of the schema point at with a foreign key.
+func addReferencedBy(tables []schema.Table, tableNodes map[string]*TreeNode) {
+	for _, t := range tables {
+		for _, fk := range t.FKs {
+			target, ok := tableNodes[fk.RefTable]
+			if !ok {
+				continue
+			}
+			var group *TreeNode
+			if n := len(target.Children); n > 0 && target.Children[n-1].Kind == NodeRefGroup {
+				group = target.Children[n-1]
+			} else {
+				group = &TreeNode{
+					Kind:     NodeRefGroup,
+					Database: target.Database,
+					Schema:   target.Schema,
+					Table:    target.Table,
+					Depth:    target.Depth + 1,
+				}
+				target.Children = append(target.Children, group)
+			}
+			group.Children = append(group.Children, &TreeNode{
+				Label:    t.Name + "." + strings.Join(fk.Columns, ", "),
+				Kind:     NodeRef,
+				Database: target.Database,
+				Schema:   target.Schema,
+				Table:    t.Name,
+				RefTable: t.Name,
+				Depth:    target.Depth + 2,
+			})
+			group.Label = fmt.Sprintf("Referenced by (%d)", len(group.Children))
+		}
+	}
+}
+
+// jumpToTable selects the table a foreign-key column or a "Referenced by"
+// entry leads to, expanding the tree down to it and clearing any search.
+func (m *Model) jumpToTable(node *TreeNode) tea.Cmd {
+	var target *TreeNode
+	for _, n := range m.nodes {
+		if n.Kind == NodeFavoriteGroup {
+			continue
+		}
+		if target = findTable(n, node.Database, node.Schema, node.RefTable); target != nil {
+			break
+		}
+	}
+	if target == nil {
+		return statusCmd("Table "+node.RefTable+" is not in the schema tree", true)
+	}
+	m.reveal(target)
+	return nil
+}
+
+// RevealTable selects the table named table in the given database and
+// schema, expanding the tree down to it and clearing any search. It
+// reports whether the table is in the tree.
+func (m *Model) RevealTable(database, schemaName, table string) bool {
+	for _, n := range m.nodes {
+		if n.Kind == NodeFavoriteGroup {
+			continue
+		}
+		if target := findTable(n, database, schemaName, table); target != nil {
+			m.reveal(target)
+			return true
+		}
+	}
+	return false
+}
+
+// RevealObject selects the node of an object named name in the given
+// database and schema: a column of table when table is set, else a table,
+// view, routine, sequence or trigger. It expands the tree down to it,
+// clearing any search, and reports whether the object is in the tree.
+func (m *Model) RevealObject(database, schemaName, table, name string) bool {
+	for _, n := range m.nodes {
+		if n.Kind == NodeFavoriteGroup {
+			continue
+		}
+		if target := findObject(n, database, schemaName, table, name); target != nil {
+			m.reveal(target)
+			return true
+		}
+	}
+	return false
+}
+
+// reveal selects target, expanding the tree down to it.
+func (m *Model) reveal(target *TreeNode) {
+	m.clearFilter()
+	for _, n := range m.nodes {
+		expandPath(n, target)
+	}
+	m.flatten()
+	for i, n := range m.flat {
+		if n == target {
+			m.cursor = i
+		}
+	}
+	m.ensureVisible()
+}
+
+// findTable returns the table node named table in the given database and
+// schema below node.
+func findTable(node *TreeNode, database, schemaName, table string) *TreeNode {
+	if node.Kind == NodeTable {
+		if node.Database == database && node.Schema == schemaName && node.Table == table {
+			return node
+		}
+		return nil
+	}
+	for _, c := range node.Children {
+		if t := findTable(c, database, schemaName, table); t != nil {
+			return t
+		}
+	}
+	return nil
+}
+
+// findObject returns the node of the object RevealObject looks for below
+// node. Routines and triggers are labelled with their signature.
+func findObject(node *TreeNode, database, schemaName, table, name string) *TreeNode {
+	if node.Database == database && node.Schema == schemaName {
+		switch node.Kind {
+		case NodeColumn:
+			if table != "" && node.Table == table && node.Column == name {
+				return node
+			}
+		case NodeTable, NodeView, NodeMatView:
+			if table == "" && node.Table == name {
+				return node
+			}
+		case NodeRoutine, NodeSequence, NodeTrigger:
+			if table == "" && (node.Label == name || strings.HasPrefix(node.Label, name+"(") || strings.HasPrefix(node.Label, name+":")) {
+				return node
+			}
+		}
+	}
+	for _, c := range node.Children {
+		if t := findObject(c, database, schemaName, table, name); t != nil {
+			return t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package sidebar
+
+import (
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	appmsg "github.com/sadopc/gotermsql/internal/msg"
+	"github.com/sadopc/gotermsql/internal/schema"
+)
+
+// newSpinner builds the spinner shown on tables whose columns are loading.
+func newSpinner() spinner.Model {
+	return spinner.New(spinner.WithSpinner(spinner.MiniDot))
+}
+
+// markPending flags every table below node as not loaded yet, for schemas
+// that were loaded lazily.
+func markPending(node *TreeNode) {
+	if node.Kind == NodeTable && len(node.Children) == 0 {
+		node.Pending = true
+		return
+	}
+	for _, c := range node.Children {
+		markPending(c)
+	}
+}
+
+// loadTable asks the app for the columns of a pending table, which is
+// expanded once they arrive. The spinner runs while any table is loading.
+func (m *Model) loadTable(node *TreeNode) tea.Cmd {
+	ref := schema.TableRef{Database: node.Database, Schema: node.Schema, Table: node.Table}
+	node.Loading = true
+	if m.loadingTables[ref] {
+		return nil
+	}
+	if m.loadingTables == nil {
+		m.loadingTables = make(map[schema.TableRef]bool)
+	}
+	m.loadingTables[ref] = true
+
+	msg := appmsg.LoadTableMsg{Database: node.Database, Schema: node.Schema, Table: node.Table}
+	cmds := []tea.Cmd{func() tea.Msg { return msg }}
+	if len(m.loadingTables) == 1 {
+		cmds = append(cmds, m.spinner.Tick)
+	}
+	return tea.Batch(cmds...)
+}
+
+// tableLoaded fills in the columns of every node of a table loaded on
+// demand, including its copy in Favorites. Nodes the user was waiting on
+// are expanded. After an error the table stays pending, so expanding it
+// again retries.
+func (m *Model) tableLoaded(msg appmsg.TableLoadedMsg) {
+	delete(m.loadingTables, schema.TableRef{Database: msg.Database, Schema: msg.Schema, Table: msg.Table.Name})
+	var visit func(nodes []*TreeNode)
+	visit = func(nodes []*TreeNode) {
+		for _, n := range nodes {
+			if n.Kind == NodeTable && n.Database == msg.Database && n.Schema == msg.Schema && n.Table == msg.Table.Name {
+				if msg.Err == nil {
+					n.Children = columnNodes(msg.Database, msg.Schema, msg.Table, n.Depth+1)
+					n.Pending = false
+					n.Expanded = n.Loading
+				}
+				n.Loading = false
+				continue
+			}
+			visit(n.Children)
+		}
+	}
+	visit(m.nodes)
+	m.flatten()
+}
+
+// updateSpinner advances the spinner while tables are loading and lets it
+// stop once none are.
+func (m *Model) updateSpinner(msg spinner.TickMsg) tea.Cmd {
+	if len(m.loadingTables) == 0 {
+		return nil
+	}
+	var cmd tea.Cmd
+	m.spinner, cmd = m.spinner.Update(msg)
+	return cmd
+}
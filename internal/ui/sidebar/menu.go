@@ -0,0 +1,409 @@
+package sidebar
+
+import (
+	"cmp"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/ddl"
+	appmsg "github.com/sadopc/gotermsql/internal/msg"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+// writeClipboard is swapped out in tests.
+var writeClipboard = clipboard.WriteAll
+
+// menuItem is an entry of the action menu opened with space or m.
+type menuItem struct {
+	key   string // shortcut while the menu is open
+	label string
+	run   func(m *Model, node *TreeNode) tea.Cmd
+}
+
+// openMenu opens the action menu for the selected node, if it has actions.
+func (m *Model) openMenu() {
+	if m.cursor >= len(m.flat) {
+		return
+	}
+	node := m.flat[m.cursor]
+	if items := m.menuItems(node); len(items) > 0 {
+		m.menu = items
+		m.menuPos = 0
+	}
+}
+
+// updateMenu handles key presses while the action menu is open.
+func (m Model) updateMenu(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", " ", "m":
+		m.menu = nil
+	case "up", "k":
+		if m.menuPos > 0 {
+			m.menuPos--
+		}
+	case "down", "j":
+		if m.menuPos < len(m.menu)-1 {
+			m.menuPos++
+		}
+	case "enter":
+		return m.runMenuItem(m.menuPos)
+	default:
+		for i, item := range m.menu {
+			if item.key == msg.String() {
+				return m.runMenuItem(i)
+			}
+		}
+	}
+	return m, nil
+}
+
+// runMenuItem closes the menu and runs entry i on the selected node.
+func (m Model) runMenuItem(i int) (Model, tea.Cmd) {
+	item := m.menu[i]
+	m.menu = nil
+	if m.cursor >= len(m.flat) {
+		return m, nil
+	}
+	return m, item.run(&m, m.flat[m.cursor])
+}
+
+// menuItems returns the actions offered for a node.
+func (m Model) menuItems(node *TreeNode) []menuItem {
+	copyName := menuItem{"y", "Copy qualified name", (*Model).copyName}
+	switch node.Kind {
+	case NodeTable, NodeView, NodeMatView:
+		items := []menuItem{
+			{"p", "Peek first 100 rows", (*Model).peekRows},
+			{"n", "Count rows", tableAction(appmsg.TableCount)},
+			{"o", "Profile data", (*Model).profileFor},
+			copyName,
+			{"s", "SELECT template", (*Model).selectTemplate},
+		}
+		if node.Kind == NodeTable {
+			items = append(items,
+				menuItem{"i", "INSERT template", (*Model).insertTemplate},
+				menuItem{"a", "Insert row…", (*Model).insertRowFor},
+				menuItem{"z", "Generate test data…", (*Model).generateDataFor},
+				menuItem{"v", "Bulk UPDATE…", (*Model).bulkUpdateFor},
+				menuItem{"l", "Compare data with…", (*Model).compareDataFor},
+				menuItem{"u", "UPDATE template", (*Model).updateTemplate},
+				menuItem{"e", "DELETE template", (*Model).deleteTemplate},
+			)
+		}
+		items = append(items,
+			menuItem{"d", "Show DDL", (*Model).showDDLFor},
+			menuItem{"b", "Dependencies", (*Model).dependenciesFor},
+			menuItem{"w", "Copy data to…", (*Model).transferFor},
+		)
+		if node.Kind == NodeTable {
+			items = append(items,
+				menuItem{"c", "CREATE TABLE for…", (*Model).portMenu},
+				menuItem{"g", "ER diagram", (*Model).erDiagramFor},
+				menuItem{"h", "Dump to SQL file…", (*Model).dumpFor},
+			)
+		}
+		favorite := menuItem{"f", "Add to favorites", (*Model).toggleFavoriteFor}
+		if m.isFavorite(node) {
+			favorite.label = "Remove from favorites"
+		}
+		items = append(items, favorite)
+		if node.Kind == NodeMatView {
+			items = append(items, menuItem{"r", "Refresh", (*Model).refreshFor})
+		}
+		if node.Kind == NodeTable {
+			items = append(items, menuItem{"t", "TRUNCATE…", tableAction(appmsg.TableTruncate)})
+		}
+		return append(items, menuItem{"x", "DROP…", tableAction(appmsg.TableDrop)})
+	case NodeDatabase, NodeSchema:
+		diagram := menuItem{"g", "ER diagram", (*Model).erDiagramFor}
+		if m.dialect == "sqlite" {
+			return []menuItem{copyName, diagram, {"v", "Maintenance…", (*Model).maintenanceFor}}
+		}
+		return []menuItem{copyName, diagram}
+	case NodeColumn, NodeSequence:
+		if node.RefTable != "" {
+			return []menuItem{{"j", "Jump to " + node.RefTable, (*Model).jumpToTable}, copyName}
+		}
+		return []menuItem{copyName}
+	case NodeRef:
+		return []menuItem{{"j", "Jump to " + node.RefTable, (*Model).jumpToTable}}
+	}
+	return nil
+}
+
+// peekRows opens the first 100 rows of a table or view in a new tab.
+func (m *Model) peekRows(node *TreeNode) tea.Cmd {
+	msg := appmsg.NewTabMsg{Query: "SELECT * FROM " + m.qualifiedName(node) + " LIMIT 100;", Run: true}
+	return func() tea.Msg { return msg }
+}
+
+// selectTemplate opens a SELECT listing every column in a new tab.
+func (m *Model) selectTemplate(node *TreeNode) tea.Cmd {
+	cols := m.columnNames(node)
+	list := " *"
+	if len(cols) > 0 {
+		list = "\n    " + strings.Join(cols, ",\n    ")
+	}
+	msg := appmsg.NewTabMsg{Query: "SELECT" + list + "\nFROM " + m.qualifiedName(node) + "\nWHERE \nLIMIT 100;"}
+	return func() tea.Msg { return msg }
+}
+
+// insertTemplate opens an INSERT for every column in a new tab, with each
+// value left as a comment naming its column and type.
+func (m *Model) insertTemplate(node *TreeNode) tea.Cmd {
+	var cols, values []string
+	for _, c := range node.Children {
+		if c.Kind != NodeColumn {
+			continue
+		}
+		cols = append(cols, m.quote(c.Column))
+		values = append(values, placeholder(c))
+	}
+	if len(cols) == 0 {
+		return statusCmd("No columns loaded for "+node.Table, true)
+	}
+	msg := appmsg.NewTabMsg{Query: "INSERT INTO " + m.qualifiedName(node) +
+		" (" + strings.Join(cols, ", ") + ")\nVALUES (" + strings.Join(values, ", ") + ");"}
+	return func() tea.Msg { return msg }
+}
+
+// updateTemplate opens an UPDATE setting every non-key column in a new
+// tab, with a WHERE on the primary key.
+func (m *Model) updateTemplate(node *TreeNode) tea.Cmd {
+	var set []string
+	for _, c := range node.Children {
+		if c.Kind == NodeColumn && !c.IsPK {
+			set = append(set, m.quote(c.Column)+" = "+placeholder(c))
+		}
+	}
+	if len(set) == 0 {
+		return statusCmd("No columns to update in "+node.Table, true)
+	}
+	msg := appmsg.NewTabMsg{Query: "UPDATE " + m.qualifiedName(node) +
+		"\nSET " + strings.Join(set, ",\n    ") + "\n" + m.keyWhere(node) + ";"}
+	return func() tea.Msg { return msg }
+}
+
+// deleteTemplate opens a DELETE with a WHERE on the primary key in a new tab.
+func (m *Model) deleteTemplate(node *TreeNode) tea.Cmd {
+	msg := appmsg.NewTabMsg{Query: "DELETE FROM " + m.qualifiedName(node) + "\n" + m.keyWhere(node) + ";"}
+	return func() tea.Msg { return msg }
+}
+
+// keyWhere returns a WHERE clause matching the primary key of a table node.
+// Without a known key the condition is left as a comment, so the statement
+// cannot run as is and touch every row.
+func (m *Model) keyWhere(node *TreeNode) string {
+	var conds []string
+	for _, c := range node.Children {
+		if c.Kind == NodeColumn && c.IsPK {
+			conds = append(conds, m.quote(c.Column)+" = "+placeholder(c))
+		}
+	}
+	if len(conds) == 0 {
+		return "WHERE /* condition: no primary key */"
+	}
+	return "WHERE " + strings.Join(conds, "\n  AND ")
+}
+
+// placeholder is the value left in a template for column node c.
+func placeholder(c *TreeNode) string {
+	return "/* " + strings.TrimSpace(c.Column+" "+c.ColType) + " */"
+}
+
+// copyName copies the quoted, qualified name of the node.
+func (m *Model) copyName(node *TreeNode) tea.Cmd {
+	var name string
+	switch node.Kind {
+	case NodeDatabase:
+		name = m.quote(node.Database)
+	case NodeSchema:
+		name = m.quote(node.Schema)
+	case NodeSequence:
+		name = m.quote(node.Label)
+		if node.Schema != "" && node.Schema != "main" {
+			name = m.quote(node.Schema) + "." + name
+		}
+	case NodeColumn:
+		name = m.qualifiedName(node) + "." + m.quote(node.Column)
+	default:
+		name = m.qualifiedName(node)
+	}
+	return func() tea.Msg {
+		if err := writeClipboard(name); err != nil {
+			return appmsg.StatusMsg{Text: "Copy failed: " + err.Error(), IsError: true}
+		}
+		return appmsg.StatusMsg{Text: "Copied " + name}
+	}
+}
+
+func (m *Model) showDDLFor(node *TreeNode) tea.Cmd {
+	msg := appmsg.ShowDDLMsg{Database: node.Database, Schema: node.Schema, Table: node.Table}
+	return func() tea.Msg { return msg }
+}
+
+// portMenu replaces the menu with the dialects a table's CREATE TABLE can
+// be written for.
+func (m *Model) portMenu(*TreeNode) tea.Cmd {
+	keys := map[string]string{"postgres": "p", "mysql": "y", "sqlite": "s", "duckdb": "k"}
+	m.menu = nil
+	for _, d := range ddl.Dialects {
+		dialect := d.Name
+		m.menu = append(m.menu, menuItem{keys[dialect], d.Title, func(m *Model, node *TreeNode) tea.Cmd {
+			msg := appmsg.PortTableMsg{Database: node.Database, Schema: node.Schema, Table: node.Table, Dialect: dialect}
+			return func() tea.Msg { return msg }
+		}})
+	}
+	m.menuPos = 0
+	return nil
+}
+
+// maintenanceFor opens the maintenance panel of the database of a node.
+// Each SQLite database has a single schema, named "main" or after the
+// alias it is attached as.
+func (m *Model) maintenanceFor(node *TreeNode) tea.Cmd {
+	schemaName := node.Schema
+	if schemaName == "" && len(node.Children) > 0 {
+		schemaName = node.Children[0].Schema
+	}
+	msg := appmsg.MaintenanceMsg{Database: node.Database, Schema: cmp.Or(schemaName, "main")}
+	return func() tea.Msg { return msg }
+}
+
+// erDiagramFor opens the ER diagram of the schema of a node, on the
+// neighbours of a table.
+func (m *Model) erDiagramFor(node *TreeNode) tea.Cmd {
+	msg := appmsg.ERDiagramMsg{Database: node.Database, Schema: node.Schema}
+	if node.Kind == NodeTable {
+		msg.Table = node.Table
+	}
+	return func() tea.Msg { return msg }
+}
+
+// profileFor opens the data profile of a table or view.
+func (m *Model) profileFor(node *TreeNode) tea.Cmd {
+	msg := appmsg.ProfileTableMsg{Database: node.Database, Schema: node.Schema, Table: node.Table}
+	return func() tea.Msg { return msg }
+}
+
+// dependenciesFor opens the dependency browser of a table or view.
+func (m *Model) dependenciesFor(node *TreeNode) tea.Cmd {
+	msg := appmsg.DependenciesMsg{Database: node.Database, Schema: node.Schema, Table: node.Table}
+	return func() tea.Msg { return msg }
+}
+
+// insertRowFor opens the form that inserts a row into a table.
+func (m *Model) insertRowFor(node *TreeNode) tea.Cmd {
+	msg := appmsg.InsertRowMsg{Database: node.Database, Schema: node.Schema, Table: node.Table}
+	return func() tea.Msg { return msg }
+}
+
+// generateDataFor opens the test data generator for a table.
+func (m *Model) generateDataFor(node *TreeNode) tea.Cmd {
+	msg := appmsg.GenerateDataMsg{Database: node.Database, Schema: node.Schema, Table: node.Table}
+	return func() tea.Msg { return msg }
+}
+
+// bulkUpdateFor opens the wizard that updates the rows of a table.
+func (m *Model) bulkUpdateFor(node *TreeNode) tea.Cmd {
+	msg := appmsg.BulkUpdateMsg{Database: node.Database, Schema: node.Schema, Table: node.Table}
+	return func() tea.Msg { return msg }
+}
+
+// compareDataFor opens the comparison of the rows of a table with another.
+func (m *Model) compareDataFor(node *TreeNode) tea.Cmd {
+	msg := appmsg.CompareDataMsg{Database: node.Database, Schema: node.Schema, Table: node.Table}
+	return func() tea.Msg { return msg }
+}
+
+// dumpFor opens the dialog that writes a table to a SQL file.
+func (m *Model) dumpFor(node *TreeNode) tea.Cmd {
+	msg := appmsg.DumpTableMsg{Database: node.Database, Schema: node.Schema, Table: node.Table}
+	return func() tea.Msg { return msg }
+}
+
+// transferFor opens the wizard that copies the rows of a table or view to
+// another connection.
+func (m *Model) transferFor(node *TreeNode) tea.Cmd {
+	msg := appmsg.TransferTableMsg{Database: node.Database, Schema: node.Schema, Table: node.Table}
+	return func() tea.Msg { return msg }
+}
+
+func (m *Model) refreshFor(node *TreeNode) tea.Cmd {
+	msg := appmsg.RefreshMatViewMsg{Database: node.Database, Schema: node.Schema, View: node.Table}
+	return func() tea.Msg { return msg }
+}
+
+// tableAction returns a menu action that hands a table operation to the app.
+func tableAction(action appmsg.TableAction) func(*Model, *TreeNode) tea.Cmd {
+	return func(m *Model, node *TreeNode) tea.Cmd {
+		kind := "TABLE"
+		switch node.Kind {
+		case NodeView:
+			kind = "VIEW"
+		case NodeMatView:
+			kind = "MATERIALIZED VIEW"
+		}
+		msg := appmsg.TableActionMsg{Action: action, Database: node.Database, Schema: node.Schema, Table: node.Table, Kind: kind}
+		return func() tea.Msg { return msg }
+	}
+}
+
+// columnNames returns the quoted names of a table's loaded columns.
+func (m *Model) columnNames(node *TreeNode) []string {
+	var cols []string
+	for _, c := range node.Children {
+		if c.Kind == NodeColumn {
+			cols = append(cols, m.quote(c.Column))
+		}
+	}
+	return cols
+}
+
+// renderMenu renders the action menu, width cells wide.
+func (m Model) renderMenu(width int, th *theme.Theme) []string {
+	lines := make([]string, 0, len(m.menu)+1)
+	lines = append(lines, th.MutedText.Render(padTo(" actions (esc closes)", width)))
+	for i, item := range m.menu {
+		line := padTo("  "+item.key+"  "+item.label, width)
+		if i == m.menuPos {
+			lines = append(lines, th.SidebarSelected.Render(line))
+		} else {
+			lines = append(lines, th.SidebarColumn.Render(line))
+		}
+	}
+	return lines
+}
+
+// placeMenu draws the menu lines over the tree lines just below the
+// selected node, or above it when there is no room below.
+func (m Model) placeMenu(lines, menu []string, height int) []string {
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	row := m.cursor - m.offset + 1
+	if row+len(menu) > height {
+		row = m.cursor - m.offset - len(menu)
+	}
+	if row < 0 {
+		row = 0
+	}
+	for i, line := range menu {
+		if row+i < len(lines) {
+			lines[row+i] = line
+		}
+	}
+	return lines
+}
+
+// padTo truncates or pads s to exactly width display cells.
+func padTo(s string, width int) string {
+	s = runewidth.Truncate(s, width, "…")
+	return s + strings.Repeat(" ", max(0, width-runewidth.StringWidth(s)))
+}
+
+func statusCmd(text string, isErr bool) tea.Cmd {
+	return func() tea.Msg { return appmsg.StatusMsg{Text: text, IsError: isErr} }
+}
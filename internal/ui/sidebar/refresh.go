@@ -0,0 +1,103 @@
+package sidebar
+
+import (
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// treeState is what survives the tree being rebuilt for a reloaded schema:
+// which nodes were expanded or collapsed, which tables of a lazily loaded
+// schema had their columns loaded, and the node selected. Nodes are known
+// by nodeKey.
+type treeState struct {
+	expanded map[string]bool
+	loaded   map[string]bool
+	selected string
+}
+
+// nodeKey identifies node across rebuilds: its kind and name under the key
+// of its parent, so the copy of a table in Favorites differs from the
+// table. Group labels carry counts, so groups go by kind alone.
+func nodeKey(parent string, node *TreeNode) string {
+	name := node.Label
+	switch node.Kind {
+	case NodeTableGroup, NodeViewGroup, NodeMatViewGroup, NodeRoutineGroup,
+		NodeSequenceGroup, NodeTriggerGroup, NodeFavoriteGroup, NodeRefGroup:
+		name = ""
+	case NodeTable, NodeView, NodeMatView:
+		name = node.Table
+	case NodeColumn:
+		name = node.Column
+	}
+	return parent + "/" + strconv.Itoa(int(node.Kind)) + ":" + name
+}
+
+// walkKeys calls fn on every node of the tree with its key.
+func walkKeys(nodes []*TreeNode, parent string, fn func(key string, node *TreeNode)) {
+	for _, n := range nodes {
+		key := nodeKey(parent, n)
+		fn(key, n)
+		walkKeys(n.Children, key, fn)
+	}
+}
+
+// saveState records the state of the current tree.
+func (m *Model) saveState() treeState {
+	st := treeState{expanded: make(map[string]bool), loaded: make(map[string]bool)}
+	var selected *TreeNode
+	if m.cursor < len(m.flat) {
+		selected = m.flat[m.cursor]
+	}
+	walkKeys(m.nodes, "", func(key string, n *TreeNode) {
+		if len(n.Children) > 0 || n.Pending {
+			st.expanded[key] = n.Expanded
+		}
+		if n.Kind == NodeTable && !n.Pending && len(n.Children) > 0 {
+			st.loaded[key] = true
+		}
+		if n == selected {
+			st.selected = key
+		}
+	})
+	return st
+}
+
+// restoreState applies st to a freshly built tree, before it is
+// flattened. Nodes new to the tree keep their default expansion. Tables
+// whose columns were loaded and shown are loaded again, since a lazily
+// loaded schema has only their names.
+func (m *Model) restoreState(st treeState) tea.Cmd {
+	var cmds []tea.Cmd
+	walkKeys(m.nodes, "", func(key string, n *TreeNode) {
+		expanded, ok := st.expanded[key]
+		if !ok {
+			return
+		}
+		if n.Pending {
+			if expanded && st.loaded[key] {
+				cmds = append(cmds, m.loadTable(n))
+			}
+			return
+		}
+		n.Expanded = expanded
+	})
+	return tea.Batch(cmds...)
+}
+
+// selectKey moves the cursor to the visible node with key, if there is one.
+func (m *Model) selectKey(key string) {
+	if key == "" {
+		return
+	}
+	index := make(map[*TreeNode]int, len(m.flat))
+	for i, n := range m.flat {
+		index[n] = i
+	}
+	walkKeys(m.nodes, "", func(k string, n *TreeNode) {
+		if i, ok := index[n]; ok && k == key {
+			m.cursor = i
+		}
+	})
+	m.ensureVisible()
+}
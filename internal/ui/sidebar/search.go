@@ -0,0 +1,250 @@
+package sidebar
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// InputFocused reports whether the search prompt or the action menu has
+// keyboard focus, in which case typed characters belong to it rather than
+// to global shortcuts.
+func (m Model) InputFocused() bool {
+	return m.searching || m.menu != nil
+}
+
+// startSearch opens the "/" prompt.
+func (m *Model) startSearch() tea.Cmd {
+	if len(m.nodes) == 0 {
+		return nil
+	}
+	m.searching = true
+	m.searchBox.SetValue(m.filter)
+	m.searchBox.CursorEnd()
+	return m.searchBox.Focus()
+}
+
+// updateSearch handles key presses while the search prompt is focused. The
+// tree is filtered as you type; enter keeps the filter and esc clears it.
+func (m Model) updateSearch(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.searching = false
+		m.searchBox.Blur()
+		m.clearFilter()
+		return m, nil
+	case "enter":
+		m.searching = false
+		m.searchBox.Blur()
+		if m.filter == "" {
+			m.clearFilter()
+		}
+		return m, nil
+	case "up", "down":
+		// Move between matches without leaving the prompt.
+		if msg.String() == "up" && m.cursor > 0 {
+			m.cursor--
+		} else if msg.String() == "down" && m.cursor < len(m.flat)-1 {
+			m.cursor++
+		}
+		m.ensureVisible()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.searchBox, cmd = m.searchBox.Update(msg)
+	if v := strings.TrimSpace(m.searchBox.Value()); v != m.filter {
+		m.setFilter(v)
+	}
+	return m, cmd
+}
+
+// setFilter filters the tree to nodes whose names fuzzy-match f, plus their
+// ancestors, and selects the first match.
+func (m *Model) setFilter(f string) {
+	m.filter = f
+	m.matches = nil
+	m.keep = nil
+	if f != "" {
+		m.matches = make(map[*TreeNode][]int)
+		m.keep = make(map[*TreeNode]bool)
+		for _, n := range m.nodes {
+			// Favorites are copies; search the tables where they live.
+			if n.Kind != NodeFavoriteGroup {
+				m.markMatches(n)
+			}
+		}
+	}
+	m.flatten()
+	m.cursor = 0
+	m.offset = 0
+	for i, n := range m.flat {
+		if _, ok := m.matches[n]; ok {
+			m.cursor = i
+			break
+		}
+	}
+	m.ensureVisible()
+}
+
+// clearFilter removes the search filter. The ancestors of the selected node
+// are expanded so the selection stays where it was.
+func (m *Model) clearFilter() {
+	if m.filter == "" {
+		return
+	}
+	var selected *TreeNode
+	if m.cursor < len(m.flat) {
+		selected = m.flat[m.cursor]
+	}
+	m.filter = ""
+	m.matches = nil
+	m.keep = nil
+	m.searchBox.SetValue("")
+	if selected != nil {
+		for _, n := range m.nodes {
+			expandPath(n, selected)
+		}
+	}
+	m.flatten()
+	for i, n := range m.flat {
+		if n == selected {
+			m.cursor = i
+		}
+	}
+	m.ensureVisible()
+}
+
+// expandPath expands every ancestor of target below node. It reports
+// whether target is node or one of its descendants.
+func expandPath(node, target *TreeNode) bool {
+	if node == target {
+		return true
+	}
+	for _, c := range node.Children {
+		if expandPath(c, target) {
+			node.Expanded = true
+			return true
+		}
+	}
+	return false
+}
+
+// markMatches records the nodes matching the filter and reports whether
+// node or any of its descendants matched.
+func (m *Model) markMatches(node *TreeNode) bool {
+	found := false
+	if searchable(node.Kind) {
+		if pos, ok := fuzzyMatch(m.filter, node.Label); ok {
+			m.matches[node] = pos
+			found = true
+		}
+	}
+	for _, c := range node.Children {
+		if m.markMatches(c) {
+			found = true
+		}
+	}
+	if found {
+		m.keep[node] = true
+	}
+	return found
+}
+
+// flattenFiltered lists node while a filter is active. Ancestors of matches
+// are shown expanded, with only their matching branches; a match's own
+// children follow its normal expanded state.
+func (m *Model) flattenFiltered(node *TreeNode) {
+	if !m.keep[node] {
+		return
+	}
+	m.flat = append(m.flat, node)
+	branches := false
+	for _, c := range node.Children {
+		if m.keep[c] {
+			branches = true
+			m.flattenFiltered(c)
+		}
+	}
+	if !branches && node.Expanded {
+		for _, c := range node.Children {
+			m.flattenNode(c)
+		}
+	}
+}
+
+// searchable reports whether nodes of kind k take part in the search.
+func searchable(k NodeKind) bool {
+	switch k {
+	case NodeTable, NodeView, NodeMatView, NodeColumn, NodeRoutine, NodeSequence, NodeTrigger:
+		return true
+	}
+	return false
+}
+
+// fuzzyMatch reports whether the runes of pattern appear in s in order,
+// ignoring case, and returns the rune positions in s they matched. Each
+// pattern rune is taken at its first occurrence after the previous one.
+func fuzzyMatch(pattern, s string) ([]int, bool) {
+	p := []rune(strings.ToLower(pattern))
+	if len(p) == 0 {
+		return nil, false
+	}
+	var pos []int
+	i := 0
+	for j, r := range []rune(s) {
+		if unicode.ToLower(r) == p[i] {
+			pos = append(pos, j)
+			i++
+			if i == len(p) {
+				return pos, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// highlight renders line with base, drawing the runes at positions pos
+// (offset by start) in the match style.
+func highlight(line string, start int, pos []int, base, match lipgloss.Style) string {
+	hit := make(map[int]bool, len(pos))
+	for _, p := range pos {
+		hit[start+p] = true
+	}
+	match = match.Inherit(base)
+
+	var sb, run strings.Builder
+	runHit := false
+	flush := func() {
+		if run.Len() == 0 {
+			return
+		}
+		if runHit {
+			sb.WriteString(match.Render(run.String()))
+		} else {
+			sb.WriteString(base.Render(run.String()))
+		}
+		run.Reset()
+	}
+	for i, r := range []rune(line) {
+		if hit[i] != runHit {
+			flush()
+			runHit = hit[i]
+		}
+		run.WriteRune(r)
+	}
+	flush()
+	return sb.String()
+}
+
+// newSearchInput builds the text input used for the sidebar search.
+func newSearchInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.Placeholder = "table or column"
+	ti.CharLimit = 100
+	return ti
+}
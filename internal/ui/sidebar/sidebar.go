@@ -5,8 +5,11 @@ import (
 	"os"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/sadopc/gotermsql/internal/adapter"
 	appmsg "github.com/sadopc/gotermsql/internal/msg"
 	"github.com/sadopc/gotermsql/internal/schema"
 	"github.com/sadopc/gotermsql/internal/theme"
@@ -27,6 +30,17 @@ const (
 	NodeViewGroup
 	NodeView
 	NodeColumn
+	NodeRoutineGroup
+	NodeRoutine
+	NodeSequenceGroup
+	NodeSequence
+	NodeTriggerGroup
+	NodeTrigger
+	NodeMatViewGroup
+	NodeMatView
+	NodeFavoriteGroup
+	NodeRefGroup
+	NodeRef
 )
 
 // TreeNode represents a node in the schema tree.
@@ -44,6 +58,13 @@ type TreeNode struct {
 	Column   string
 	ColType  string
 	IsPK     bool
+	RefTable string // table a foreign-key column or "Referenced by" entry leads to
+	RefCol   string
+
+	// Lazily loaded schemas: the table's columns are not loaded yet, and
+	// are being loaded.
+	Pending bool
+	Loading bool
 }
 
 // Model is the schema browser sidebar.
@@ -56,11 +77,34 @@ type Model struct {
 	height  int
 	focused bool
 	loading bool
+	dialect string // adapter name, for quoting identifiers
+
+	// Search ("/")
+	searching bool
+	searchBox textinput.Model
+	filter    string
+	matches   map[*TreeNode][]int // matching nodes and their matched runes
+	keep      map[*TreeNode]bool  // matching nodes and their ancestors
+
+	// Action menu (space / m)
+	menu    []menuItem
+	menuPos int
+
+	// Tables of a lazily loaded schema whose columns are being fetched
+	loadingTables map[schema.TableRef]bool
+	spinner       spinner.Model
+
+	// Starred tables ("f"), as "schema.table"
+	favorites []string
+
+	// Row counts and sizes ("c")
+	showStats bool
+	stats     map[schema.TableRef]schema.TableStats
 }
 
 // New creates a new sidebar.
 func New() Model {
-	return Model{}
+	return Model{searchBox: newSearchInput(), spinner: newSpinner()}
 }
 
 // Init returns no initial command.
@@ -72,15 +116,49 @@ func (m Model) Init() tea.Cmd {
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case appmsg.SchemaLoadedMsg:
+		// A reloaded schema keeps what was expanded and selected.
+		st := m.saveState()
 		m.nodes = buildTree(msg.Databases)
-		m.flatten()
 		m.loading = false
+		m.loadingTables = nil
+		if msg.Lazy {
+			for _, n := range m.nodes {
+				markPending(n)
+			}
+		}
+		m.rebuildFavorites()
+		cmd := m.restoreState(st)
+		// Re-apply an active search to the new tree.
+		m.setFilter(m.filter)
+		m.selectKey(st.selected)
+		return m, cmd
+
+	case appmsg.TableStatsMsg:
+		m.stats = msg.Stats
+
+	case appmsg.TableLoadedMsg:
+		m.tableLoaded(msg)
+
+	case spinner.TickMsg:
+		return m, m.updateSpinner(msg)
 
 	case tea.KeyMsg:
 		if !m.focused {
 			return m, nil
 		}
+		if m.menu != nil {
+			return m.updateMenu(msg)
+		}
+		if m.searching {
+			return m.updateSearch(msg)
+		}
 		switch msg.String() {
+		case " ", "m":
+			m.openMenu()
+		case "/":
+			return m, m.startSearch()
+		case "esc":
+			m.clearFilter()
 		case "up", "k":
 			if m.cursor > 0 {
 				m.cursor--
@@ -93,6 +171,14 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			}
 		case "enter", "right", "l":
 			return m, m.toggleOrSelect()
+		case "d":
+			return m, m.ShowDDL()
+		case "c":
+			return m, m.toggleStats()
+		case "f":
+			return m, m.toggleFavorite()
+		case "r":
+			return m, m.refreshMatView()
 		case "left", "h":
 			if m.cursor < len(m.flat) {
 				node := m.flat[m.cursor]
@@ -108,6 +194,9 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			m.cursor = len(m.flat) - 1
 			m.ensureVisible()
 		}
+
+	case tea.MouseMsg:
+		return m, m.handleMouse(msg)
 	}
 
 	return m, nil
@@ -146,17 +235,23 @@ func (m Model) View() string {
 		return m.borderStyle().Width(innerW).Height(innerH).Render(content)
 	}
 
-	if len(m.flat) == 0 {
+	if len(m.nodes) == 0 {
 		content := titleLine + "\n\n  No schema loaded.\n  Connect to a database."
 		return m.borderStyle().Width(innerW).Height(innerH).Render(content)
 	}
 
-	// Render visible nodes: innerH - 1 for the title line.
-	contentHeight := innerH - 1
-	if contentHeight < 1 {
-		contentHeight = 1
+	// The search prompt, when open or filtering, sits under the title.
+	if m.searching || m.filter != "" {
+		titleLine += "\n" + m.searchLine(innerW)
+	}
+
+	if len(m.flat) == 0 && m.filter != "" {
+		content := titleLine + "\n\n  No matches."
+		return m.borderStyle().Width(innerW).Height(innerH).Render(content)
 	}
 
+	contentHeight := m.contentHeight()
+
 	var lines []string
 	end := m.offset + contentHeight
 	if end > len(m.flat) {
@@ -168,6 +263,9 @@ func (m Model) View() string {
 		line := m.renderNode(node, i == m.cursor, th)
 		lines = append(lines, line)
 	}
+	if m.menu != nil {
+		lines = m.placeMenu(lines, m.renderMenu(m.width-4, th), contentHeight)
+	}
 
 	content := titleLine + "\n" + strings.Join(lines, "\n")
 	return m.borderStyle().Width(innerW).Height(innerH).Render(content)
@@ -193,6 +291,20 @@ func (m Model) renderNode(node *TreeNode, selected bool, th *theme.Theme) string
 			icon = "◇ "
 		case NodeColumn:
 			icon = "  "
+		case NodeRoutineGroup, NodeRoutine:
+			icon = "ƒ "
+		case NodeSequenceGroup, NodeSequence:
+			icon = "# "
+		case NodeTriggerGroup, NodeTrigger:
+			icon = "↯ "
+		case NodeMatViewGroup:
+			icon = "◎ "
+		case NodeMatView:
+			icon = "◈ "
+		case NodeFavoriteGroup:
+			icon = "* "
+		case NodeRefGroup, NodeRef:
+			icon = "← "
 		}
 	} else {
 		switch node.Kind {
@@ -210,12 +322,36 @@ func (m Model) renderNode(node *TreeNode, selected bool, th *theme.Theme) string
 			icon = "📄 "
 		case NodeColumn:
 			icon = "  "
+		case NodeRoutineGroup:
+			icon = "🔧 "
+		case NodeSequenceGroup:
+			icon = "🔢 "
+		case NodeTriggerGroup:
+			icon = "⚡ "
+		case NodeRoutine:
+			icon = "ƒ "
+		case NodeSequence:
+			icon = "# "
+		case NodeTrigger:
+			icon = "↯ "
+		case NodeMatViewGroup:
+			icon = "💾 "
+		case NodeMatView:
+			icon = "🧊 "
+		case NodeFavoriteGroup:
+			icon = "⭐ "
+		case NodeRefGroup:
+			icon = "🔗 "
+		case NodeRef:
+			icon = "← "
 		}
 	}
 
 	// Expand/collapse indicator for parent nodes
 	expandIcon := "  "
-	if len(node.Children) > 0 {
+	if node.Loading {
+		expandIcon = m.spinner.View() + " "
+	} else if len(node.Children) > 0 || node.Pending {
 		if node.Expanded {
 			expandIcon = "▼ "
 		} else {
@@ -227,11 +363,21 @@ func (m Model) renderNode(node *TreeNode, selected bool, th *theme.Theme) string
 	if node.Kind == NodeColumn && node.ColType != "" {
 		label = fmt.Sprintf("%s %s", node.Label, node.ColType)
 	}
+	if node.Kind == NodeColumn && node.RefTable != "" {
+		label += " → " + node.RefTable + "." + node.RefCol
+	}
 
-	line := indent + expandIcon + icon + label
+	prefix := indent + expandIcon + icon
+	line := prefix + label
 
-	// Truncate to width
+	// Truncate to width, leaving room for the row count and size
 	maxW := m.width - 4
+	stats := m.statsLabel(node)
+	if stats != "" && maxW-len(stats)-1 >= 8 {
+		maxW -= len(stats) + 1
+	} else {
+		stats = ""
+	}
 	if len(line) > maxW {
 		line = line[:maxW-1] + "…"
 	}
@@ -239,30 +385,52 @@ func (m Model) renderNode(node *TreeNode, selected bool, th *theme.Theme) string
 	for len(line) < maxW {
 		line += " "
 	}
+	if stats != "" {
+		line += " " + stats
+	}
 
+	style := nodeStyle(node, th)
 	if selected {
-		return th.SidebarSelected.Render(line)
+		style = th.SidebarSelected
+	}
+	if pos, ok := m.matches[node]; ok {
+		return highlight(line, len([]rune(prefix)), pos, style, th.SidebarMatch)
 	}
+	return style.Render(line)
+}
 
+// nodeStyle returns the style of an unselected node.
+func nodeStyle(node *TreeNode, th *theme.Theme) lipgloss.Style {
 	switch node.Kind {
 	case NodeDatabase:
-		return th.SidebarDatabase.Render(line)
+		return th.SidebarDatabase
 	case NodeSchema:
-		return th.SidebarSchema.Render(line)
+		return th.SidebarSchema
 	case NodeTable:
-		return th.SidebarTable.Render(line)
-	case NodeView:
-		return th.SidebarView.Render(line)
+		return th.SidebarTable
+	case NodeView, NodeRoutine, NodeSequence, NodeTrigger:
+		return th.SidebarView
+	case NodeMatView:
+		return th.SidebarMatView
 	case NodeColumn:
 		if node.IsPK {
-			return th.SidebarColumn.Bold(true).Render(line)
+			return th.SidebarColumn.Bold(true)
 		}
-		return th.SidebarColumn.Render(line)
+		return th.SidebarColumn
 	default:
-		return th.SidebarColumn.Render(line)
+		return th.SidebarColumn
 	}
 }
 
+// searchLine renders the search prompt, or the active filter when the
+// prompt is closed.
+func (m Model) searchLine(width int) string {
+	if m.searching {
+		return m.searchBox.View()
+	}
+	return theme.Current.MutedText.Width(width).Render("/" + m.filter + "  (esc to clear)")
+}
+
 func (m Model) borderStyle() lipgloss.Style {
 	th := theme.Current
 	if m.focused {
@@ -277,6 +445,10 @@ func (m *Model) toggleOrSelect() tea.Cmd {
 	}
 	node := m.flat[m.cursor]
 
+	if node.Pending {
+		return m.loadTable(node)
+	}
+
 	// Toggle expand/collapse for parent nodes
 	if len(node.Children) > 0 {
 		node.Expanded = !node.Expanded
@@ -284,13 +456,14 @@ func (m *Model) toggleOrSelect() tea.Cmd {
 		return nil
 	}
 
+	// Foreign keys lead to the other table
+	if node.RefTable != "" {
+		return m.jumpToTable(node)
+	}
+
 	// For table nodes, generate a SELECT query
 	if node.Kind == NodeTable {
-		tableName := quoteIdentifier(node.Table)
-		if node.Schema != "" && node.Schema != "main" {
-			tableName = quoteIdentifier(node.Schema) + "." + tableName
-		}
-		query := fmt.Sprintf("SELECT * FROM %s LIMIT 100;", tableName)
+		query := fmt.Sprintf("SELECT * FROM %s LIMIT 100;", m.qualifiedName(node))
 		return func() tea.Msg {
 			return appmsg.NewTabMsg{Query: query}
 		}
@@ -299,10 +472,50 @@ func (m *Model) toggleOrSelect() tea.Cmd {
 	return nil
 }
 
+// ShowDDL asks the app to show the CREATE statement of the selected table
+// or view.
+func (m *Model) ShowDDL() tea.Cmd {
+	if m.cursor >= len(m.flat) {
+		return nil
+	}
+	node := m.flat[m.cursor]
+	if node.Kind != NodeTable && node.Kind != NodeView && node.Kind != NodeMatView {
+		return nil
+	}
+	msg := appmsg.ShowDDLMsg{Database: node.Database, Schema: node.Schema, Table: node.Table}
+	return func() tea.Msg { return msg }
+}
+
+// ERDiagram asks the app for the ER diagram of the schema of the selected
+// node, on the neighbours of a selected table.
+func (m *Model) ERDiagram() tea.Cmd {
+	if m.cursor >= len(m.flat) {
+		return func() tea.Msg { return appmsg.ERDiagramMsg{} }
+	}
+	return m.erDiagramFor(m.flat[m.cursor])
+}
+
+// refreshMatView asks the app to refresh the selected materialized view.
+func (m *Model) refreshMatView() tea.Cmd {
+	if m.cursor >= len(m.flat) {
+		return nil
+	}
+	node := m.flat[m.cursor]
+	if node.Kind != NodeMatView {
+		return nil
+	}
+	msg := appmsg.RefreshMatViewMsg{Database: node.Database, Schema: node.Schema, View: node.Table}
+	return func() tea.Msg { return msg }
+}
+
 func (m *Model) flatten() {
 	m.flat = nil
 	for _, node := range m.nodes {
-		m.flattenNode(node)
+		if m.filter != "" {
+			m.flattenFiltered(node)
+		} else {
+			m.flattenNode(node)
+		}
 	}
 	if m.cursor >= len(m.flat) {
 		m.cursor = len(m.flat) - 1
@@ -321,11 +534,21 @@ func (m *Model) flattenNode(node *TreeNode) {
 	}
 }
 
-func (m *Model) ensureVisible() {
-	contentHeight := m.height - 3
-	if contentHeight < 1 {
-		contentHeight = 1
+// contentHeight returns the number of tree lines that fit: the height less
+// the border, the title, and the search line when shown.
+func (m Model) contentHeight() int {
+	h := m.height - 3
+	if m.searching || m.filter != "" {
+		h--
+	}
+	if h < 1 {
+		h = 1
 	}
+	return h
+}
+
+func (m *Model) ensureVisible() {
+	contentHeight := m.contentHeight()
 	if m.cursor < m.offset {
 		m.offset = m.cursor
 	}
@@ -334,6 +557,49 @@ func (m *Model) ensureVisible() {
 	}
 }
 
+// scrollLines is how many rows a turn of the mouse wheel scrolls.
+const scrollLines = 3
+
+// handleMouse scrolls the tree with the wheel, and selects the node
+// clicked, expanding or collapsing it. A click on the selected node opens
+// it like enter. Coordinates are relative to the sidebar: row 0 is the top
+// border, row 1 the title.
+func (m *Model) handleMouse(msg tea.MouseMsg) tea.Cmd {
+	if m.loading || m.menu != nil || msg.Action != tea.MouseActionPress || len(m.flat) == 0 {
+		return nil
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.scroll(-scrollLines)
+	case tea.MouseButtonWheelDown:
+		m.scroll(scrollLines)
+	case tea.MouseButtonLeft:
+		top := 2
+		if m.searching || m.filter != "" {
+			top++
+		}
+		row := msg.Y - top
+		i := m.offset + row
+		if row < 0 || row >= m.contentHeight() || i >= len(m.flat) {
+			return nil
+		}
+		again := i == m.cursor
+		m.cursor = i
+		if node := m.flat[i]; again || len(node.Children) > 0 || node.Pending {
+			return m.toggleOrSelect()
+		}
+	}
+	return nil
+}
+
+// scroll moves the view n rows down, or up if n is negative, keeping the
+// cursor inside it.
+func (m *Model) scroll(n int) {
+	h := m.contentHeight()
+	m.offset = max(min(m.offset+n, len(m.flat)-h), 0)
+	m.cursor = min(max(m.cursor, m.offset), m.offset+h-1, len(m.flat)-1)
+}
+
 // SetSize sets the sidebar dimensions.
 func (m *Model) SetSize(width, height int) {
 	m.width = width
@@ -349,13 +615,34 @@ func (m *Model) Blur() { m.focused = false }
 // Focused returns whether the sidebar is focused.
 func (m Model) Focused() bool { return m.focused }
 
+// Selected returns the node under the cursor, or nil.
+func (m Model) Selected() *TreeNode {
+	if m.cursor >= len(m.flat) {
+		return nil
+	}
+	return m.flat[m.cursor]
+}
+
 // SetLoading sets the loading state.
 func (m *Model) SetLoading(loading bool) { m.loading = loading }
 
-// quoteIdentifier wraps a SQL identifier in double-quotes (ANSI style),
-// escaping any embedded double-quotes by doubling them.
-func quoteIdentifier(s string) string {
-	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+// SetDialect sets the adapter name used to quote identifiers in generated
+// SQL. Until it is set, identifiers are quoted ANSI style.
+func (m *Model) SetDialect(dialect string) { m.dialect = dialect }
+
+// quote quotes a SQL identifier for the connection's dialect.
+func (m Model) quote(s string) string {
+	return adapter.QuoteIdentifier(m.dialect, s)
+}
+
+// qualifiedName returns the quoted, schema-qualified name of a table or
+// view node. SQLite's and DuckDB's default "main" schema is left out.
+func (m Model) qualifiedName(node *TreeNode) string {
+	name := m.quote(node.Table)
+	if node.Schema != "" && node.Schema != "main" {
+		name = m.quote(node.Schema) + "." + name
+	}
+	return name
 }
 
 func buildTree(databases []schema.Database) []*TreeNode {
@@ -389,6 +676,7 @@ func buildTree(databases []schema.Database) []*TreeNode {
 					Depth:    2,
 					Expanded: true,
 				}
+				tableNodes := make(map[string]*TreeNode, len(s.Tables))
 				for _, t := range s.Tables {
 					tableNode := &TreeNode{
 						Label:    t.Name,
@@ -398,47 +686,42 @@ func buildTree(databases []schema.Database) []*TreeNode {
 						Table:    t.Name,
 						Depth:    3,
 					}
-					for _, c := range t.Columns {
-						colNode := &TreeNode{
-							Label:    c.Name,
-							Kind:     NodeColumn,
-							Database: db.Name,
-							Schema:   s.Name,
-							Table:    t.Name,
-							Column:   c.Name,
-							ColType:  c.Type,
-							IsPK:     c.IsPK,
-							Depth:    4,
-						}
-						tableNode.Children = append(tableNode.Children, colNode)
-					}
+					tableNode.Children = columnNodes(db.Name, s.Name, t, 4)
 					tablesGroup.Children = append(tablesGroup.Children, tableNode)
+					tableNodes[t.Name] = tableNode
 				}
+				addReferencedBy(s.Tables, tableNodes)
 				schemaNode.Children = append(schemaNode.Children, tablesGroup)
 			}
 
-			// Views group
-			if len(s.Views) > 0 {
-				viewsGroup := &TreeNode{
-					Label:    fmt.Sprintf("Views (%d)", len(s.Views)),
-					Kind:     NodeViewGroup,
-					Database: db.Name,
-					Schema:   s.Name,
-					Depth:    2,
-				}
-				for _, v := range s.Views {
-					viewNode := &TreeNode{
-						Label:    v.Name,
-						Kind:     NodeView,
-						Database: db.Name,
-						Schema:   s.Name,
-						Table:    v.Name,
-						Depth:    3,
-					}
-					viewsGroup.Children = append(viewsGroup.Children, viewNode)
+			// Views and materialized views groups
+			var views, matViews []schema.View
+			for _, v := range s.Views {
+				if v.Materialized {
+					matViews = append(matViews, v)
+				} else {
+					views = append(views, v)
 				}
-				schemaNode.Children = append(schemaNode.Children, viewsGroup)
 			}
+			addViewGroup(schemaNode, "Views", NodeViewGroup, NodeView, views)
+			addViewGroup(schemaNode, "Materialized Views", NodeMatViewGroup, NodeMatView, matViews)
+
+			// Routines, sequences and triggers
+			var labels []string
+			for _, r := range s.Routines {
+				labels = append(labels, r.Signature())
+			}
+			addObjectGroup(schemaNode, "Routines", NodeRoutineGroup, NodeRoutine, labels)
+			labels = nil
+			for _, seq := range s.Sequences {
+				labels = append(labels, seq.Name)
+			}
+			addObjectGroup(schemaNode, "Sequences", NodeSequenceGroup, NodeSequence, labels)
+			labels = nil
+			for _, t := range s.Triggers {
+				labels = append(labels, t.Signature())
+			}
+			addObjectGroup(schemaNode, "Triggers", NodeTriggerGroup, NodeTrigger, labels)
 
 			dbNode.Children = append(dbNode.Children, schemaNode)
 		}
@@ -448,3 +731,76 @@ func buildTree(databases []schema.Database) []*TreeNode {
 
 	return nodes
 }
+
+// columnNodes returns the column nodes of a table at the given depth.
+func columnNodes(database, schemaName string, t schema.Table, depth int) []*TreeNode {
+	var nodes []*TreeNode
+	for _, c := range t.Columns {
+		refTable, refCol := fkTarget(t, c.Name)
+		nodes = append(nodes, &TreeNode{
+			Label:    c.Name,
+			Kind:     NodeColumn,
+			Database: database,
+			Schema:   schemaName,
+			Table:    t.Name,
+			Column:   c.Name,
+			ColType:  c.Type,
+			IsPK:     c.IsPK,
+			RefTable: refTable,
+			RefCol:   refCol,
+			Depth:    depth,
+		})
+	}
+	return nodes
+}
+
+// addViewGroup adds a collapsed group of view nodes under a schema node.
+// Empty groups are left out.
+func addViewGroup(schemaNode *TreeNode, name string, groupKind, kind NodeKind, views []schema.View) {
+	if len(views) == 0 {
+		return
+	}
+	group := &TreeNode{
+		Label:    fmt.Sprintf("%s (%d)", name, len(views)),
+		Kind:     groupKind,
+		Database: schemaNode.Database,
+		Schema:   schemaNode.Schema,
+		Depth:    2,
+	}
+	for _, v := range views {
+		group.Children = append(group.Children, &TreeNode{
+			Label:    v.Name,
+			Kind:     kind,
+			Database: schemaNode.Database,
+			Schema:   schemaNode.Schema,
+			Table:    v.Name,
+			Depth:    3,
+		})
+	}
+	schemaNode.Children = append(schemaNode.Children, group)
+}
+
+// addObjectGroup adds a collapsed group of leaf nodes, one per label, under a
+// schema node. Empty groups are left out.
+func addObjectGroup(schemaNode *TreeNode, name string, groupKind, kind NodeKind, labels []string) {
+	if len(labels) == 0 {
+		return
+	}
+	group := &TreeNode{
+		Label:    fmt.Sprintf("%s (%d)", name, len(labels)),
+		Kind:     groupKind,
+		Database: schemaNode.Database,
+		Schema:   schemaNode.Schema,
+		Depth:    2,
+	}
+	for _, label := range labels {
+		group.Children = append(group.Children, &TreeNode{
+			Label:    label,
+			Kind:     kind,
+			Database: schemaNode.Database,
+			Schema:   schemaNode.Schema,
+			Depth:    3,
+		})
+	}
+	schemaNode.Children = append(schemaNode.Children, group)
+}
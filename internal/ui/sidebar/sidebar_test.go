@@ -1,6 +1,8 @@
 package sidebar
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -359,6 +361,41 @@ func TestExpandCollapse(t *testing.T) {
 	}
 }
 
+func TestMouse(t *testing.T) {
+	m := New()
+	m.SetSize(40, 8)
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: multiDBSchema()})
+	click := func(y int) {
+		m, _ = m.Update(tea.MouseMsg{X: 5, Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+	}
+
+	// Row 2 is the first node, under the border and title.
+	click(3)
+	if m.cursor != 1 || !m.flat[1].Expanded {
+		t.Fatalf("a click on the second database: cursor = %d, expanded = %v", m.cursor, m.flat[1].Expanded)
+	}
+	click(3)
+	if m.flat[1].Expanded {
+		t.Error("a second click should collapse it")
+	}
+	click(2)
+	click(0)
+	if m.cursor != 0 {
+		t.Errorf("a click on the border moved the cursor to %d", m.cursor)
+	}
+
+	// The wheel scrolls the view, taking the cursor along.
+	n := len(m.flat)
+	m, _ = m.Update(tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelDown})
+	if m.offset != min(scrollLines, n-m.contentHeight()) || m.cursor != m.offset {
+		t.Errorf("after wheel down: offset = %d, cursor = %d", m.offset, m.cursor)
+	}
+	m, _ = m.Update(tea.MouseMsg{Action: tea.MouseActionPress, Button: tea.MouseButtonWheelUp})
+	if m.offset != 0 {
+		t.Errorf("after wheel up: offset = %d, want 0", m.offset)
+	}
+}
+
 func TestToggleOrSelect_Table(t *testing.T) {
 	m := New()
 	m.SetSize(40, 30)
@@ -623,3 +660,646 @@ func TestInit(t *testing.T) {
 		t.Fatal("expected nil cmd from Init")
 	}
 }
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       []int
+		ok         bool
+	}{
+		{"usr", "users", []int{0, 1, 3}, true},
+		{"OID", "order_id", []int{0, 6, 7}, true},
+		{"xyz", "users", nil, false},
+		{"", "users", nil, false},
+	}
+	for _, tt := range tests {
+		got, ok := fuzzyMatch(tt.pattern, tt.s)
+		if ok != tt.ok || fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, %v; want %v, %v", tt.pattern, tt.s, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSearch_FiltersAndExpands(t *testing.T) {
+	m := New()
+	m.SetSize(40, 30)
+	m.Focus()
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: singleDBSchema()})
+
+	m, _ = m.Update(keyMsg("/"))
+	if !m.InputFocused() {
+		t.Fatal("/ should open the search prompt")
+	}
+	for _, r := range "usrid" {
+		m, _ = m.Update(keyMsg(string(r)))
+	}
+
+	// Only orders.user_id matches: its ancestors are shown expanded even
+	// though the orders table is collapsed.
+	var labels []string
+	for _, n := range m.flat {
+		labels = append(labels, n.Label)
+	}
+	want := "[testdb public Tables (2) orders user_id]"
+	if fmt.Sprint(labels) != want {
+		t.Fatalf("filtered tree = %v, want %s", labels, want)
+	}
+	if m.flat[m.cursor].Label != "user_id" {
+		t.Errorf("cursor on %q, want the first match", m.flat[m.cursor].Label)
+	}
+	if !strings.Contains(m.View(), "/usrid") {
+		t.Error("view should show the search prompt")
+	}
+
+	m, _ = m.Update(specialKeyMsg(tea.KeyEnter))
+	if m.InputFocused() || m.filter != "usrid" {
+		t.Fatalf("enter should keep the filter: focused=%v filter=%q", m.InputFocused(), m.filter)
+	}
+
+	m, _ = m.Update(specialKeyMsg(tea.KeyEsc))
+	if m.filter != "" || m.flat[m.cursor].Label != "user_id" {
+		t.Errorf("esc should clear the filter and keep the selection, cursor on %q", m.flat[m.cursor].Label)
+	}
+}
+
+func TestSearch_NoMatches(t *testing.T) {
+	m := New()
+	m.SetSize(40, 30)
+	m.Focus()
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: singleDBSchema()})
+	m.setFilter("zzz")
+	if len(m.flat) != 0 || !strings.Contains(m.View(), "No matches") {
+		t.Errorf("flat = %d nodes, want a No matches view", len(m.flat))
+	}
+}
+
+func TestShowDDL(t *testing.T) {
+	m := New()
+	m.SetSize(40, 30)
+	m.Focus()
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: singleDBSchema()})
+	m.setFilter("orders")
+
+	_, cmd := m.Update(keyMsg("d"))
+	if cmd == nil {
+		t.Fatal("d on a table should return a command")
+	}
+	got, ok := cmd().(appmsg.ShowDDLMsg)
+	if !ok {
+		t.Fatalf("expected ShowDDLMsg, got %T", cmd())
+	}
+	want := appmsg.ShowDDLMsg{Database: "testdb", Schema: "public", Table: "orders"}
+	if got != want {
+		t.Errorf("ShowDDLMsg = %+v, want %+v", got, want)
+	}
+
+	m.cursor = 0 // the schema node
+	if _, cmd := m.Update(keyMsg("d")); cmd != nil {
+		t.Error("d on a schema node should do nothing")
+	}
+}
+
+func TestTableStats(t *testing.T) {
+	m := New()
+	m.SetSize(50, 30)
+	m.Focus()
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: singleDBSchema()})
+
+	m, cmd := m.Update(keyMsg("c"))
+	if cmd == nil {
+		t.Fatal("c should ask for table stats")
+	}
+	if _, ok := cmd().(appmsg.LoadTableStatsMsg); !ok {
+		t.Fatalf("expected LoadTableStatsMsg, got %T", cmd())
+	}
+
+	m, _ = m.Update(appmsg.TableStatsMsg{Stats: map[schema.TableRef]schema.TableStats{
+		{Database: "testdb", Schema: "public", Table: "orders"}: {Rows: 1_500_000, Bytes: 90 << 20},
+		{Database: "testdb", Schema: "public", Table: "users"}:  {Rows: 42, Bytes: -1},
+	}})
+	m.setFilter("rs") // expand down to both tables
+	view := m.View()
+	if !strings.Contains(view, "1.5M 90M") || !strings.Contains(view, " 42") {
+		t.Errorf("view should show row counts and sizes:\n%s", view)
+	}
+
+	m, cmd = m.Update(keyMsg("c"))
+	if cmd != nil || strings.Contains(m.View(), "1.5M") {
+		t.Error("c again should hide the stats without reloading")
+	}
+}
+
+func TestBuildTree_RoutinesSequencesTriggers(t *testing.T) {
+	dbs := []schema.Database{{
+		Name: "testdb",
+		Schemas: []schema.Schema{{
+			Name:      "public",
+			Routines:  []schema.Routine{{Name: "add", Kind: "function", Args: "a integer, b integer", Returns: "integer"}},
+			Sequences: []schema.Sequence{{Name: "users_id_seq"}},
+			Triggers:  []schema.Trigger{{Name: "touch", Table: "users", Timing: "BEFORE", Event: "UPDATE"}},
+		}},
+	}}
+	nodes := buildTree(dbs)
+	groups := nodes[0].Children[0].Children
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 groups, got %d", len(groups))
+	}
+	want := []struct {
+		group, child string
+		kind         NodeKind
+	}{
+		{"Routines (1)", "add(a integer, b integer) → integer", NodeRoutine},
+		{"Sequences (1)", "users_id_seq", NodeSequence},
+		{"Triggers (1)", "touch: BEFORE UPDATE ON users", NodeTrigger},
+	}
+	for i, w := range want {
+		g := groups[i]
+		if g.Label != w.group || g.Expanded {
+			t.Errorf("group %d = %q (expanded %v), want collapsed %q", i, g.Label, g.Expanded, w.group)
+		}
+		if len(g.Children) != 1 || g.Children[0].Label != w.child || g.Children[0].Kind != w.kind {
+			t.Errorf("group %q children = %+v", g.Label, g.Children)
+		}
+	}
+}
+
+func TestMaterializedViews(t *testing.T) {
+	dbs := []schema.Database{{
+		Name: "testdb",
+		Schemas: []schema.Schema{{
+			Name: "public",
+			Views: []schema.View{
+				{Name: "active_users"},
+				{Name: "daily_sales", Materialized: true},
+			},
+		}},
+	}}
+	m := New()
+	m.SetSize(40, 30)
+	m.Focus()
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: dbs})
+
+	groups := m.nodes[0].Children[0].Children
+	if len(groups) != 2 || groups[0].Label != "Views (1)" || groups[1].Label != "Materialized Views (1)" {
+		t.Fatalf("unexpected groups: %v", groups)
+	}
+	if groups[1].Children[0].Kind != NodeMatView {
+		t.Errorf("materialized view kind = %v, want NodeMatView", groups[1].Children[0].Kind)
+	}
+
+	m.setFilter("active")
+	if _, cmd := m.Update(keyMsg("r")); cmd != nil {
+		t.Error("r on a plain view should do nothing")
+	}
+	m.setFilter("daily")
+	_, cmd := m.Update(keyMsg("r"))
+	if cmd == nil {
+		t.Fatal("r on a materialized view should return a command")
+	}
+	want := appmsg.RefreshMatViewMsg{Database: "testdb", Schema: "public", View: "daily_sales"}
+	if got := cmd(); got != want {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestActionMenu(t *testing.T) {
+	m := New()
+	m.SetSize(40, 30)
+	m.Focus()
+	m.SetDialect("mysql")
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: singleDBSchema()})
+	m.setFilter("orders")
+	m.clearFilter()
+
+	m, _ = m.Update(keyMsg(" "))
+	if !m.InputFocused() || !strings.Contains(m.View(), "Peek first 100 rows") {
+		t.Fatal("space should open the action menu")
+	}
+	m, cmd := m.Update(keyMsg("s"))
+	if m.InputFocused() {
+		t.Error("running an action should close the menu")
+	}
+	got, ok := cmd().(appmsg.NewTabMsg)
+	want := "SELECT\n    `id`,\n    `user_id`,\n    `total`\nFROM `public`.`orders`\nWHERE \nLIMIT 100;"
+	if !ok || got.Query != want || got.Run {
+		t.Errorf("SELECT template = %#v, want query %q", got, want)
+	}
+
+	m, _ = m.Update(keyMsg("m"))
+	m, cmd = m.Update(keyMsg("p"))
+	if got, ok := cmd().(appmsg.NewTabMsg); !ok || !got.Run || got.Query != "SELECT * FROM `public`.`orders` LIMIT 100;" {
+		t.Errorf("peek = %#v", got)
+	}
+
+	m, _ = m.Update(keyMsg("m"))
+	_, cmd = m.Update(keyMsg("x"))
+	wantDrop := appmsg.TableActionMsg{Action: appmsg.TableDrop, Database: "testdb", Schema: "public", Table: "orders", Kind: "TABLE"}
+	if got := cmd(); got != wantDrop {
+		t.Errorf("drop = %#v, want %#v", got, wantDrop)
+	}
+}
+
+func TestActionMenu_CopyColumnName(t *testing.T) {
+	orig := writeClipboard
+	defer func() { writeClipboard = orig }()
+	var copied string
+	writeClipboard = func(s string) error { copied = s; return nil }
+
+	m := New()
+	m.SetSize(40, 30)
+	m.Focus()
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: singleDBSchema()})
+	m.setFilter("user_id")
+
+	m, _ = m.Update(keyMsg("m"))
+	m, _ = m.Update(keyMsg("n")) // not offered for columns
+	if !m.InputFocused() {
+		t.Fatal("an unknown key should keep the menu open")
+	}
+	_, cmd := m.Update(specialKeyMsg(tea.KeyEnter))
+	cmd()
+	if copied != `"public"."orders"."user_id"` {
+		t.Errorf("copied %q", copied)
+	}
+}
+
+func TestActionMenu_Maintenance(t *testing.T) {
+	m := New()
+	m.SetSize(40, 30)
+	m.Focus()
+	m.SetDialect("sqlite")
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: singleDBSchema()})
+
+	m, _ = m.Update(keyMsg("m"))
+	_, cmd := m.Update(keyMsg("v"))
+	want := appmsg.MaintenanceMsg{Database: "testdb", Schema: "public"}
+	if cmd == nil {
+		t.Fatal("v should open the maintenance panel of a SQLite database")
+	}
+	if got := cmd(); got != want {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
+
+func TestFavorites(t *testing.T) {
+	m := New()
+	m.SetSize(40, 30)
+	m.Focus()
+	m.SetFavorites([]string{"public.gone"}) // not in the schema: kept, not shown
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: singleDBSchema()})
+	if m.nodes[0].Kind == NodeFavoriteGroup {
+		t.Fatal("favorites missing from the schema should not be listed")
+	}
+
+	m.setFilter("orders")
+	m.clearFilter()
+	m, cmd := m.Update(keyMsg("f"))
+	if cmd == nil {
+		t.Fatal("f on a table should star it")
+	}
+	if got := m.Favorites(); len(got) != 2 || got[1] != "public.orders" {
+		t.Errorf("Favorites() = %v", got)
+	}
+	group := m.nodes[0]
+	if group.Kind != NodeFavoriteGroup || group.Label != "Favorites (1)" || len(group.Children) != 1 {
+		t.Fatalf("favorites group = %+v", group)
+	}
+	fav := group.Children[0]
+	if fav.Label != "orders" || fav.Depth != 1 || len(fav.Children) != 3 || fav.Children[0].Depth != 2 {
+		t.Errorf("favorite = %+v", fav)
+	}
+	if sel := m.flat[m.cursor]; sel.Table != "orders" || sel == fav {
+		t.Errorf("selection should stay on the table, got %+v", sel)
+	}
+
+	// Searching ignores the copies in Favorites.
+	m.setFilter("orders")
+	if _, ok := m.matches[fav]; ok || len(m.matches) != 1 {
+		t.Errorf("matches = %v", m.matches)
+	}
+	m.clearFilter()
+
+	m, _ = m.Update(keyMsg("f"))
+	if got := m.Favorites(); len(got) != 1 || got[0] != "public.gone" {
+		t.Errorf("Favorites() after unstar = %v", got)
+	}
+	if m.nodes[0].Kind == NodeFavoriteGroup {
+		t.Error("favorites group should go away with its last table")
+	}
+}
+
+func TestLazyTableLoad(t *testing.T) {
+	dbs := []schema.Database{{
+		Name:    "big",
+		Schemas: []schema.Schema{{Name: "public", Tables: []schema.Table{{Name: "users"}, {Name: "orders"}}}},
+	}}
+	m := New()
+	m.SetSize(40, 30)
+	m.Focus()
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: dbs, Lazy: true})
+	m.setFilter("users")
+	m.clearFilter()
+	users := m.flat[m.cursor]
+	if !users.Pending {
+		t.Fatal("tables of a lazy schema should be pending")
+	}
+
+	m, cmd := m.Update(specialKeyMsg(tea.KeyEnter))
+	if cmd == nil || !users.Loading {
+		t.Fatal("expanding a pending table should load it")
+	}
+	var load appmsg.LoadTableMsg
+	for _, c := range cmd().(tea.BatchMsg) {
+		if msg, ok := c().(appmsg.LoadTableMsg); ok {
+			load = msg
+		}
+	}
+	if load != (appmsg.LoadTableMsg{Database: "big", Schema: "public", Table: "users"}) {
+		t.Errorf("load = %#v", load)
+	}
+	if _, cmd := m.Update(specialKeyMsg(tea.KeyEnter)); cmd != nil {
+		t.Error("a table already loading should not be requested again")
+	}
+
+	m, _ = m.Update(appmsg.TableLoadedMsg{Database: "big", Schema: "public", Table: schema.Table{
+		Name:    "users",
+		Columns: []schema.Column{{Name: "id", Type: "integer", IsPK: true}, {Name: "name", Type: "text"}},
+	}})
+	if users.Pending || users.Loading || !users.Expanded || len(users.Children) != 2 || users.Children[0].Depth != 4 {
+		t.Errorf("loaded table = %+v", users)
+	}
+	if len(m.loadingTables) != 0 {
+		t.Errorf("loadingTables = %v, want none", m.loadingTables)
+	}
+}
+
+func TestReloadKeepsState(t *testing.T) {
+	m := New()
+	m.SetSize(40, 40)
+	m.Focus()
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: singleDBSchema()})
+	m.setFilter("orders")
+	m.clearFilter()
+	m.flat[m.cursor].Expanded = true
+	m.flatten()
+
+	dbs := singleDBSchema()
+	dbs[0].Schemas[0].Tables = append(dbs[0].Schemas[0].Tables, schema.Table{Name: "payments"})
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: dbs})
+	sel := m.flat[m.cursor]
+	if sel.Table != "orders" || !sel.Expanded {
+		t.Fatalf("selected %q (expanded %v), want orders still expanded", sel.Label, sel.Expanded)
+	}
+	if !strings.Contains(m.View(), "payments") {
+		t.Error("the added table is not shown")
+	}
+
+	// Tables of a lazy schema that were loaded and expanded load again.
+	lazy := []schema.Database{{Name: "big", Schemas: []schema.Schema{{Name: "public", Tables: []schema.Table{{Name: "users"}}}}}}
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: lazy, Lazy: true})
+	users := m.nodes[0].Children[0].Children[0].Children[0]
+	users.Loading = true
+	m, _ = m.Update(appmsg.TableLoadedMsg{Database: "big", Schema: "public", Table: schema.Table{
+		Name: "users", Columns: []schema.Column{{Name: "id", Type: "integer"}},
+	}})
+	m, cmd := m.Update(appmsg.SchemaLoadedMsg{Databases: lazy, Lazy: true})
+	if users = m.nodes[0].Children[0].Children[0].Children[0]; !users.Loading || cmd == nil {
+		t.Fatal("the expanded table was not loaded again")
+	}
+	var load appmsg.LoadTableMsg
+	for _, c := range cmd().(tea.BatchMsg) {
+		if msg, ok := c().(appmsg.LoadTableMsg); ok {
+			load = msg
+		}
+	}
+	if load.Table != "users" {
+		t.Errorf("load = %#v", load)
+	}
+}
+
+func TestForeignKeyNavigation(t *testing.T) {
+	dbs := singleDBSchema()
+	orders := &dbs[0].Schemas[0].Tables[1]
+	orders.FKs = []schema.ForeignKey{{Name: "orders_user_fk", Columns: []string{"user_id"}, RefTable: "users", RefColumns: []string{"id"}}}
+
+	m := New()
+	m.SetSize(60, 40)
+	m.Focus()
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: dbs})
+
+	users := m.nodes[0].Children[0].Children[0].Children[0]
+	refs := users.Children[len(users.Children)-1]
+	if refs.Kind != NodeRefGroup || refs.Label != "Referenced by (1)" || refs.Children[0].Label != "orders.user_id" {
+		t.Fatalf("users should list what references it, got %+v", refs)
+	}
+
+	// Enter on the foreign-key column jumps to the referenced table.
+	m.setFilter("user_id")
+	if !strings.Contains(m.View(), "→ users.id") {
+		t.Error("a foreign-key column should show the column it references")
+	}
+	m, _ = m.Update(specialKeyMsg(tea.KeyEnter))
+	if sel := m.flat[m.cursor]; sel != users || m.filter != "" {
+		t.Fatalf("enter on user_id selected %q (filter %q), want users", sel.Label, m.filter)
+	}
+
+	// And back through "Referenced by".
+	refs.Expanded = true
+	users.Expanded = true
+	m.flatten()
+	for i, n := range m.flat {
+		if n == refs.Children[0] {
+			m.cursor = i
+		}
+	}
+	m, _ = m.Update(specialKeyMsg(tea.KeyEnter))
+	if sel := m.flat[m.cursor]; sel.Kind != NodeTable || sel.Table != "orders" {
+		t.Errorf("enter on a reference selected %q, want orders", sel.Label)
+	}
+}
+
+func TestQueryTemplates(t *testing.T) {
+	m := New()
+	m.SetSize(40, 30)
+	m.Focus()
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: singleDBSchema()})
+	m.setFilter("users")
+	m.clearFilter()
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"i", "INSERT INTO \"public\".\"users\" (\"id\", \"name\", \"email\")\nVALUES (/* id integer */, /* name text */, /* email text */);"},
+		{"u", "UPDATE \"public\".\"users\"\nSET \"name\" = /* name text */,\n    \"email\" = /* email text */\nWHERE \"id\" = /* id integer */;"},
+		{"e", "DELETE FROM \"public\".\"users\"\nWHERE \"id\" = /* id integer */;"},
+	}
+	for _, tt := range tests {
+		m, _ = m.Update(keyMsg("m"))
+		var cmd tea.Cmd
+		m, cmd = m.Update(keyMsg(tt.key))
+		if cmd == nil {
+			t.Fatalf("%s: no command", tt.key)
+		}
+		if got, ok := cmd().(appmsg.NewTabMsg); !ok || got.Query != tt.want {
+			t.Errorf("%s: query = %q, want %q", tt.key, got.Query, tt.want)
+		}
+	}
+
+	// Without a primary key the WHERE is left for the user to write.
+	m.nodes[0].Children[0].Children[0].Children[0].Children[0].IsPK = false
+	m, _ = m.Update(keyMsg("m"))
+	_, cmd := m.Update(keyMsg("e"))
+	if got := cmd().(appmsg.NewTabMsg); got.Query != "DELETE FROM \"public\".\"users\"\nWHERE /* condition: no primary key */;" {
+		t.Errorf("DELETE without a key = %q", got.Query)
+	}
+}
+
+func TestActionMenu_CreateTableFor(t *testing.T) {
+	m := New()
+	m.SetSize(40, 30)
+	m.Focus()
+	m.SetDialect("mysql")
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: singleDBSchema()})
+	m.setFilter("orders")
+	m.clearFilter()
+
+	m, _ = m.Update(keyMsg("m"))
+	m, cmd := m.Update(keyMsg("c"))
+	if cmd != nil || !m.InputFocused() || !strings.Contains(m.View(), "DuckDB") {
+		t.Fatal("c should offer the dialects to write the table for")
+	}
+	m, cmd = m.Update(keyMsg("p"))
+	want := appmsg.PortTableMsg{Database: "testdb", Schema: "public", Table: "orders", Dialect: "postgres"}
+	if m.InputFocused() || cmd == nil || cmd() != want {
+		t.Errorf("p should ask for the table written for postgres, got %v", cmd)
+	}
+}
+
+func TestActionMenu_Profile(t *testing.T) {
+	m := New()
+	m.SetSize(40, 30)
+	m.Focus()
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: singleDBSchema()})
+	m.setFilter("orders")
+	m.clearFilter()
+
+	m, _ = m.Update(keyMsg("m"))
+	_, cmd := m.Update(keyMsg("o"))
+	want := appmsg.ProfileTableMsg{Database: "testdb", Schema: "public", Table: "orders"}
+	if cmd == nil || cmd() != want {
+		t.Errorf("o should ask for the profile of orders, got %v", cmd)
+	}
+}
+
+func TestActionMenu_Dependencies(t *testing.T) {
+	m := New()
+	m.SetSize(40, 30)
+	m.Focus()
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: singleDBSchema()})
+	m.setFilter("orders")
+	m.clearFilter()
+
+	m, _ = m.Update(keyMsg("m"))
+	_, cmd := m.Update(keyMsg("b"))
+	want := appmsg.DependenciesMsg{Database: "testdb", Schema: "public", Table: "orders"}
+	if cmd == nil || cmd() != want {
+		t.Errorf("b should ask for the dependencies of orders, got %v", cmd)
+	}
+}
+
+func TestActionMenu_Transfer(t *testing.T) {
+	m := New()
+	m.SetSize(40, 30)
+	m.Focus()
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: singleDBSchema()})
+	m.setFilter("orders")
+	m.clearFilter()
+
+	m, _ = m.Update(keyMsg("m"))
+	_, cmd := m.Update(keyMsg("w"))
+	want := appmsg.TransferTableMsg{Database: "testdb", Schema: "public", Table: "orders"}
+	if cmd == nil || cmd() != want {
+		t.Errorf("w should open the copy wizard for orders, got %v", cmd)
+	}
+}
+
+func TestActionMenu_InsertRow(t *testing.T) {
+	m := New()
+	m.SetSize(40, 30)
+	m.Focus()
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: singleDBSchema()})
+	m.setFilter("orders")
+	m.clearFilter()
+
+	m, _ = m.Update(keyMsg("m"))
+	_, cmd := m.Update(keyMsg("a"))
+	want := appmsg.InsertRowMsg{Database: "testdb", Schema: "public", Table: "orders"}
+	if cmd == nil || cmd() != want {
+		t.Errorf("a should open the insert form for orders, got %v", cmd)
+	}
+}
+
+func TestActionMenu_GenerateData(t *testing.T) {
+	m := New()
+	m.SetSize(40, 30)
+	m.Focus()
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: singleDBSchema()})
+	m.setFilter("orders")
+	m.clearFilter()
+
+	m, _ = m.Update(keyMsg("m"))
+	_, cmd := m.Update(keyMsg("z"))
+	want := appmsg.GenerateDataMsg{Database: "testdb", Schema: "public", Table: "orders"}
+	if cmd == nil || cmd() != want {
+		t.Errorf("z should open the test data generator for orders, got %v", cmd)
+	}
+}
+
+func TestActionMenu_BulkUpdate(t *testing.T) {
+	m := New()
+	m.SetSize(40, 30)
+	m.Focus()
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: singleDBSchema()})
+	m.setFilter("orders")
+	m.clearFilter()
+
+	m, _ = m.Update(keyMsg("m"))
+	_, cmd := m.Update(keyMsg("v"))
+	want := appmsg.BulkUpdateMsg{Database: "testdb", Schema: "public", Table: "orders"}
+	if cmd == nil || cmd() != want {
+		t.Errorf("v should open the bulk UPDATE wizard for orders, got %v", cmd)
+	}
+}
+
+func TestActionMenu_CompareData(t *testing.T) {
+	m := New()
+	m.SetSize(40, 30)
+	m.Focus()
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: singleDBSchema()})
+	m.setFilter("orders")
+	m.clearFilter()
+
+	m, _ = m.Update(keyMsg("m"))
+	_, cmd := m.Update(keyMsg("l"))
+	want := appmsg.CompareDataMsg{Database: "testdb", Schema: "public", Table: "orders"}
+	if cmd == nil || cmd() != want {
+		t.Errorf("l should open the data comparison for orders, got %v", cmd)
+	}
+}
+
+func TestActionMenu_Dump(t *testing.T) {
+	m := New()
+	m.SetSize(40, 30)
+	m.Focus()
+	m, _ = m.Update(appmsg.SchemaLoadedMsg{Databases: singleDBSchema()})
+	m.setFilter("orders")
+	m.clearFilter()
+
+	m, _ = m.Update(keyMsg("m"))
+	_, cmd := m.Update(keyMsg("h"))
+	want := appmsg.DumpTableMsg{Database: "testdb", Schema: "public", Table: "orders"}
+	if cmd == nil || cmd() != want {
+		t.Errorf("h should open the dump of orders, got %v", cmd)
+	}
+}
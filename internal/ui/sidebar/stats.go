@@ -0,0 +1,50 @@
+package sidebar
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	appmsg "github.com/sadopc/gotermsql/internal/msg"
+	"github.com/sadopc/gotermsql/internal/schema"
+	"github.com/sadopc/gotermsql/internal/ui/format"
+)
+
+// SetShowStats turns the row count and size column on or off without
+// loading anything.
+func (m *Model) SetShowStats(show bool) {
+	m.showStats = show
+}
+
+// ShowStats reports whether row counts and sizes are shown, so the app
+// knows to load them after the schema.
+func (m Model) ShowStats() bool {
+	return m.showStats
+}
+
+// toggleStats shows or hides row counts and sizes. Turning them on asks the
+// app to (re)load them, so toggling twice refreshes stale figures.
+func (m *Model) toggleStats() tea.Cmd {
+	m.showStats = !m.showStats
+	if !m.showStats || len(m.nodes) == 0 {
+		return nil
+	}
+	return func() tea.Msg { return appmsg.LoadTableStatsMsg{} }
+}
+
+// statsLabel returns the row count and size shown after a table, or "".
+func (m Model) statsLabel(node *TreeNode) string {
+	if !m.showStats || node.Kind != NodeTable {
+		return ""
+	}
+	st, ok := m.stats[schema.TableRef{Database: node.Database, Schema: node.Schema, Table: node.Table}]
+	if !ok {
+		return ""
+	}
+	switch {
+	case st.Rows >= 0 && st.Bytes >= 0:
+		return format.Count(st.Rows) + " " + format.Size(st.Bytes)
+	case st.Rows >= 0:
+		return format.Count(st.Rows)
+	case st.Bytes >= 0:
+		return format.Size(st.Bytes)
+	}
+	return ""
+}
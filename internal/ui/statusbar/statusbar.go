@@ -2,12 +2,16 @@ package statusbar
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/sadopc/gotermsql/internal/config"
 	appmsg "github.com/sadopc/gotermsql/internal/msg"
 	"github.com/sadopc/gotermsql/internal/theme"
+	"github.com/sadopc/gotermsql/internal/ui/format"
 )
 
 // ClearStatusMsg is sent after a timeout to revert the status bar to key hints.
@@ -15,6 +19,16 @@ type ClearStatusMsg struct {
 	Gen uint64
 }
 
+// ClockTickMsg redraws the clock segment at the start of every minute.
+type ClockTickMsg struct{}
+
+// tickClock waits for the next minute.
+func tickClock(now time.Time) tea.Cmd {
+	return tea.Tick(now.Truncate(time.Minute).Add(time.Minute).Sub(now), func(time.Time) tea.Msg {
+		return ClockTickMsg{}
+	})
+}
+
 // Model is the status bar component.
 type Model struct {
 	width        int
@@ -25,25 +39,53 @@ type Model struct {
 	rowCount     int64
 	keyMode      appmsg.KeyMode
 	vimState     appmsg.VimState
+	recording    rune // the register a vim macro is being recorded into, or 0
 	message      string
 	isError      bool
 	clearGen     uint64
 	cursorLine   int
 	cursorCol    int
 	connected    bool
+	tunnel       string // "ssh user@host" when connected through a tunnel
+	tunnelDown   bool
+	env          string // environment tag of the connection, e.g. "prod"
+	envColor     string
+	readOnly     bool // the connection's defaults make it read-only
+	safeMode     bool // only read-only statements are run
+
+	layout        config.StatusBarConfig
+	inTransaction bool
+	// lastTime and lastRows are the last query's, kept after the
+	// message segment's copies are cleared.
+	lastTime time.Duration
+	lastRows int64
+	now      func() time.Time
 }
 
 // New creates a new status bar.
 func New() Model {
 	return Model{
 		rowCount: -1,
+		lastRows: -1,
 		keyMode:  appmsg.KeyModeStandard,
+		layout:   config.DefaultStatusBar(),
+		now:      time.Now,
 	}
 }
 
-// Init returns no initial command.
+// Init starts the clock if the layout shows it.
 func (m Model) Init() tea.Cmd {
-	return nil
+	if !m.shows(config.SegmentClock) {
+		return nil
+	}
+	return tickClock(m.now())
+}
+
+// shows reports whether the layout has the segment.
+func (m Model) shows(segment string) bool {
+	return slices.Contains(m.layout.Left, segment) ||
+		slices.Contains(m.layout.Center, segment) ||
+		slices.Contains(m.layout.Right, segment)
 }
 
 // Update handles status bar messages.
@@ -64,17 +106,40 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		m.connected = true
 		m.message = ""
 		m.isError = false
+		m.tunnel = ""
+		m.tunnelDown = false
+		m.env = msg.Env
+		m.envColor = msg.EnvColor
+		m.readOnly = msg.Defaults != nil && msg.Defaults.ReadOnly
+		m.inTransaction = false
+		if msg.Tunnel != nil {
+			m.tunnel = msg.Tunnel.String()
+		}
+
+	case appmsg.TunnelClosedMsg:
+		m.tunnelDown = true
+		if msg.Err != nil {
+			m.message = msg.Err.Error()
+			m.isError = true
+		}
 
 	case appmsg.DisconnectMsg:
 		m.connected = false
 		m.adapterName = ""
 		m.databaseName = ""
 		m.dsn = ""
+		m.tunnel = ""
+		m.tunnelDown = false
+		m.env = ""
+		m.envColor = ""
+		m.readOnly = false
+		m.inTransaction = false
 
 	case appmsg.QueryResultMsg:
 		if msg.Result != nil {
 			m.queryTime = msg.Result.Duration
 			m.rowCount = msg.Result.RowCount
+			m.lastTime, m.lastRows = m.queryTime, m.rowCount
 			if msg.Result.Message != "" {
 				m.message = msg.Result.Message
 				m.isError = false
@@ -85,6 +150,7 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	case appmsg.QueryStreamingMsg:
 		m.queryTime = msg.Duration
 		m.rowCount = -1
+		m.lastTime, m.lastRows = m.queryTime, m.rowCount
 		m.message = "streaming"
 		m.isError = false
 		return m, clearAfter()
@@ -103,6 +169,7 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		m.isError = msg.IsError
 		if msg.Duration > 0 {
 			m.queryTime = msg.Duration
+			m.lastTime = msg.Duration
 		}
 		return m, clearAfter()
 
@@ -115,6 +182,11 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		m.message = ""
 		m.isError = false
 
+	case ClockTickMsg:
+		if m.shows(config.SegmentClock) {
+			return m, tickClock(m.now())
+		}
+
 	case appmsg.ToggleKeyModeMsg:
 		if m.keyMode == appmsg.KeyModeStandard {
 			m.keyMode = appmsg.KeyModeVim
@@ -133,35 +205,105 @@ func (m Model) View() string {
 	}
 
 	th := theme.Current
+	left := m.section(m.layout.Left)
+	center := m.section(m.layout.Center)
+	right := m.section(m.layout.Right)
 
-	// Left section: connection info
-	var left string
-	if m.connected {
-		connStr := fmt.Sprintf(" %s://%s ", m.adapterName, m.databaseName)
-		left = th.StatusBarKey.Render(connStr)
-	} else {
-		left = th.StatusBarKey.Render(" disconnected ")
+	// Calculate spacing
+	leftW := lipgloss.Width(left)
+	centerW := lipgloss.Width(center)
+	rightW := lipgloss.Width(right)
+	gap := m.width - leftW - centerW - rightW
+	if gap < 0 {
+		gap = 0
 	}
 
-	// Center section: query time + row count or message or key hints
-	var center string
-	if m.message != "" {
-		if m.isError {
-			center = th.StatusBarError.Render(" " + truncate(m.message, m.width/2) + " ")
+	leftGap := gap / 2
+	rightGap := gap - leftGap
+
+	bar := left +
+		th.StatusBar.Render(spaces(leftGap)) +
+		center +
+		th.StatusBar.Render(spaces(rightGap)) +
+		right
+
+	return th.StatusBar.Width(m.width).Render(bar)
+}
+
+// section renders segments side by side.
+func (m Model) section(segments []string) string {
+	var b strings.Builder
+	for _, seg := range segments {
+		b.WriteString(m.segment(seg))
+	}
+	return b.String()
+}
+
+// segment renders one segment of the layout; it is empty when there is
+// nothing to show.
+func (m Model) segment(name string) string {
+	th := theme.Current
+	switch name {
+	case config.SegmentConnection:
+		if !m.connected {
+			return th.StatusBarKey.Render(" disconnected ")
+		}
+		var s string
+		connStr := fmt.Sprintf(" %s://%s ", m.adapterName, m.databaseName)
+		if m.env != "" {
+			// The whole connection block takes the environment color.
+			s = theme.EnvBadge(m.envColor).Render(" " + strings.ToUpper(m.env) + connStr)
 		} else {
-			center = th.StatusBarSuccess.Render(" " + m.message + " ")
+			s = th.StatusBarKey.Render(connStr)
 		}
-	} else if m.queryTime > 0 {
-		timeStr := formatDuration(m.queryTime)
-		center = th.StatusBarValue.Render(fmt.Sprintf(" %s ", timeStr))
-		if m.rowCount >= 0 {
-			center += th.StatusBarValue.Render(fmt.Sprintf(" %s rows ", formatCount(m.rowCount)))
+		if m.readOnly {
+			s += th.StatusBarValue.Render(" read-only ")
+		}
+		switch {
+		case m.tunnelDown:
+			s += th.StatusBarError.Render(" " + m.tunnel + ": down ")
+		case m.tunnel != "":
+			s += th.StatusBarValue.Render(" via " + m.tunnel + " ")
+		}
+		return s
+
+	case config.SegmentDatabase:
+		if !m.connected {
+			return ""
+		}
+		return th.StatusBarValue.Render(" " + m.databaseName + " ")
+
+	case config.SegmentSafeMode:
+		if !m.safeMode {
+			return ""
+		}
+		return th.StatusBarSuccess.Render(" SAFE ")
+
+	case config.SegmentTransaction:
+		if !m.inTransaction {
+			return ""
+		}
+		return th.StatusBarError.Render(" TX ")
+
+	case config.SegmentMessage:
+		// Query time + row count or message or key hints
+		if m.message != "" {
+			if m.isError {
+				return th.StatusBarError.Render(" " + truncate(m.message, m.width/2) + " ")
+			}
+			return th.StatusBarSuccess.Render(" " + m.message + " ")
+		}
+		if m.queryTime > 0 {
+			s := th.StatusBarValue.Render(fmt.Sprintf(" %s ", format.Duration(m.queryTime)))
+			if m.rowCount >= 0 {
+				s += th.StatusBarValue.Render(fmt.Sprintf(" %s rows ", format.Count(m.rowCount)))
+			}
+			return s
 		}
-	} else {
 		// Show key hints when idle
 		hintKey := th.StatusBarValue
 		hintSep := th.StatusBar
-		center = hintKey.Render("F5") +
+		return hintKey.Render("F5") +
 			hintSep.Render(" Run ") +
 			hintKey.Render("Ctrl+Q") +
 			hintSep.Render(" Quit ") +
@@ -169,37 +311,39 @@ func (m Model) View() string {
 			hintSep.Render(" Switch pane ") +
 			hintKey.Render("F1") +
 			hintSep.Render(" Help ")
-	}
 
-	// Right section: key mode + cursor position
-	modeStr := fmt.Sprintf(" %s ", m.keyMode)
-	if m.keyMode == appmsg.KeyModeVim {
-		modeStr = fmt.Sprintf(" %s:%s ", m.keyMode, m.vimState)
-	}
-	right := th.StatusBarKey.Render(modeStr)
-	if m.cursorLine > 0 {
-		right += th.StatusBarValue.Render(fmt.Sprintf(" %d:%d ", m.cursorLine, m.cursorCol))
-	}
+	case config.SegmentDuration:
+		if m.lastTime <= 0 {
+			return ""
+		}
+		return th.StatusBarValue.Render(" " + format.Duration(m.lastTime) + " ")
 
-	// Calculate spacing
-	leftW := lipgloss.Width(left)
-	centerW := lipgloss.Width(center)
-	rightW := lipgloss.Width(right)
-	gap := m.width - leftW - centerW - rightW
-	if gap < 0 {
-		gap = 0
-	}
+	case config.SegmentRows:
+		if m.lastRows < 0 {
+			return ""
+		}
+		return th.StatusBarValue.Render(" " + format.Count(m.lastRows) + " rows ")
 
-	leftGap := gap / 2
-	rightGap := gap - leftGap
+	case config.SegmentClock:
+		return th.StatusBarValue.Render(" " + m.now().Format("15:04") + " ")
 
-	bar := left +
-		th.StatusBar.Render(spaces(leftGap)) +
-		center +
-		th.StatusBar.Render(spaces(rightGap)) +
-		right
+	case config.SegmentKeyMode:
+		modeStr := fmt.Sprintf(" %s ", m.keyMode)
+		if m.keyMode == appmsg.KeyModeVim {
+			modeStr = fmt.Sprintf(" %s:%s ", m.keyMode, m.vimState)
+			if m.recording != 0 {
+				modeStr += fmt.Sprintf("recording @%c ", m.recording)
+			}
+		}
+		return th.StatusBarKey.Render(modeStr)
 
-	return th.StatusBar.Width(m.width).Render(bar)
+	case config.SegmentCursor:
+		if m.cursorLine <= 0 {
+			return ""
+		}
+		return th.StatusBarValue.Render(fmt.Sprintf(" %d:%d ", m.cursorLine, m.cursorCol))
+	}
+	return ""
 }
 
 // SetSize sets the status bar width.
@@ -218,6 +362,28 @@ func (m *Model) SetVimState(state appmsg.VimState) {
 	m.vimState = state
 }
 
+// SetVimRecording shows the register a vim macro is being recorded into,
+// or hides the indicator if reg is 0.
+func (m *Model) SetVimRecording(reg rune) {
+	m.recording = reg
+}
+
+// SetLayout sets the segments shown, as the statusbar section of the
+// config lists them.
+func (m *Model) SetLayout(layout config.StatusBarConfig) {
+	m.layout = layout
+}
+
+// SetTransaction shows or hides the open transaction indicator.
+func (m *Model) SetTransaction(open bool) {
+	m.inTransaction = open
+}
+
+// SetSafeMode shows or hides the safe mode indicator.
+func (m *Model) SetSafeMode(on bool) {
+	m.safeMode = on
+}
+
 // KeyMode returns the current key mode.
 func (m Model) KeyMode() appmsg.KeyMode {
 	return m.keyMode
@@ -228,26 +394,6 @@ func (m *Model) SetKeyMode(mode appmsg.KeyMode) {
 	m.keyMode = mode
 }
 
-func formatDuration(d time.Duration) string {
-	if d < time.Millisecond {
-		return fmt.Sprintf("%dµs", d.Microseconds())
-	}
-	if d < time.Second {
-		return fmt.Sprintf("%dms", d.Milliseconds())
-	}
-	return fmt.Sprintf("%.1fs", d.Seconds())
-}
-
-func formatCount(n int64) string {
-	if n < 1000 {
-		return fmt.Sprintf("%d", n)
-	}
-	if n < 1_000_000 {
-		return fmt.Sprintf("%.1fk", float64(n)/1000)
-	}
-	return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
-}
-
 func truncate(s string, maxLen int) string {
 	if maxLen <= 3 {
 		return s
@@ -3,13 +3,16 @@ package statusbar
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/sadopc/gotermsql/internal/adapter"
+	"github.com/sadopc/gotermsql/internal/config"
 	appmsg "github.com/sadopc/gotermsql/internal/msg"
 	"github.com/sadopc/gotermsql/internal/schema"
 	"github.com/sadopc/gotermsql/internal/theme"
+	"github.com/sadopc/gotermsql/internal/tunnel"
 )
 
 func init() {
@@ -299,6 +302,20 @@ func TestSetVimState(t *testing.T) {
 	}
 }
 
+func TestSetVimRecording(t *testing.T) {
+	m := New()
+	m.SetSize(120)
+	m.SetKeyMode(appmsg.KeyModeVim)
+	m.SetVimRecording('q')
+	if !strings.Contains(m.View(), "recording @q") {
+		t.Fatalf("expected the recording indicator, got %q", m.View())
+	}
+	m.SetVimRecording(0)
+	if strings.Contains(m.View(), "recording") {
+		t.Fatalf("expected no recording indicator, got %q", m.View())
+	}
+}
+
 func TestView_ZeroWidth(t *testing.T) {
 	m := New()
 	view := m.View()
@@ -429,3 +446,98 @@ func TestUpdate_ClearStatusMsg_StaleIgnored(t *testing.T) {
 		t.Fatalf("fresh timer should clear message, got %q", m.message)
 	}
 }
+
+func TestView_TunnelStatus(t *testing.T) {
+	m := New()
+	m.SetSize(120)
+	conn := &mockConnection{dbName: "app", adapterName: "postgres"}
+	m, _ = m.Update(appmsg.ConnectMsg{Conn: conn, Adapter: "postgres", Tunnel: &tunnel.Tunnel{}})
+	if !strings.Contains(m.View(), "via ssh") {
+		t.Errorf("view should show the tunnel:\n%s", m.View())
+	}
+
+	m, _ = m.Update(appmsg.TunnelClosedMsg{Err: errors.New("ssh tunnel to bastion closed")})
+	view := m.View()
+	if !strings.Contains(view, ": down") {
+		t.Errorf("view should show the tunnel is down:\n%s", view)
+	}
+}
+
+func TestView_EnvTag(t *testing.T) {
+	m := New()
+	m.SetSize(120)
+	conn := &mockConnection{dbName: "app", adapterName: "postgres"}
+	m, _ = m.Update(appmsg.ConnectMsg{Conn: conn, Adapter: "postgres", Env: "prod", EnvColor: "#F44747"})
+	if !strings.Contains(m.View(), "PROD postgres://app") {
+		t.Errorf("view should show the environment:\n%s", m.View())
+	}
+
+	// An ad hoc connection clears it.
+	m, _ = m.Update(appmsg.ConnectMsg{Conn: conn, Adapter: "postgres"})
+	if strings.Contains(m.View(), "PROD") {
+		t.Errorf("view should not show an environment:\n%s", m.View())
+	}
+}
+
+func TestView_ReadOnly(t *testing.T) {
+	m := New()
+	m.SetSize(120)
+	conn := &mockConnection{dbName: "app", adapterName: "postgres"}
+	m, _ = m.Update(appmsg.ConnectMsg{Conn: conn, Adapter: "postgres", Defaults: &config.ExecDefaults{ReadOnly: true}})
+	if !strings.Contains(m.View(), "read-only") {
+		t.Errorf("view should show the connection is read-only:\n%s", m.View())
+	}
+	m, _ = m.Update(appmsg.ConnectMsg{Conn: conn, Adapter: "postgres"})
+	if strings.Contains(m.View(), "read-only") {
+		t.Errorf("view should not show read-only:\n%s", m.View())
+	}
+}
+
+func TestView_Layout(t *testing.T) {
+	m := New()
+	m.SetSize(120)
+	m.now = func() time.Time { return time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC) }
+	m.SetLayout(config.StatusBarConfig{
+		Left:  []string{config.SegmentDatabase, config.SegmentTransaction},
+		Right: []string{config.SegmentRows, config.SegmentDuration, config.SegmentClock},
+	})
+	if m.Init() == nil {
+		t.Error("Init should start the clock when the layout shows it")
+	}
+	conn := &mockConnection{dbName: "app", adapterName: "postgres"}
+	m, _ = m.Update(appmsg.ConnectMsg{Conn: conn, Adapter: "postgres"})
+	m.SetTransaction(true)
+	m, _ = m.Update(appmsg.QueryResultMsg{Result: &adapter.QueryResult{Duration: 12 * time.Millisecond, RowCount: 42}})
+	m, _ = m.Update(ClearStatusMsg{Gen: m.clearGen})
+
+	view := m.View()
+	order := []string{" app ", " TX ", " 42 rows ", " 12ms ", " 09:30 "}
+	last := -1
+	for _, s := range order {
+		i := strings.Index(view, s)
+		if i < 0 {
+			t.Fatalf("view should show %q:\n%s", s, view)
+		}
+		if i < last {
+			t.Errorf("%q should come after the segments before it:\n%s", s, view)
+		}
+		last = i
+	}
+	for _, s := range []string{"postgres://", "standard", "F5"} {
+		if strings.Contains(view, s) {
+			t.Errorf("view should not show %q, its segment is not in the layout:\n%s", s, view)
+		}
+	}
+
+	m.SetTransaction(false)
+	if strings.Contains(m.View(), " TX ") {
+		t.Error("TX should be hidden without an open transaction")
+	}
+	if _, cmd := m.Update(ClockTickMsg{}); cmd == nil {
+		t.Error("a clock tick should schedule the next one")
+	}
+	m.SetLayout(config.DefaultStatusBar())
+	if _, cmd := m.Update(ClockTickMsg{}); cmd != nil {
+		t.Error("the clock should stop when the layout hides it")
+	}
+}
@@ -0,0 +1,238 @@
+// Package switcher is the quick connection switcher opened with Ctrl+P: a
+// fuzzy-filtered list of the saved connections, most recently used first.
+package switcher
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/theme"
+	"github.com/sahilm/fuzzy"
+)
+
+// PickMsg is sent when a connection is picked.
+type PickMsg struct {
+	Conn config.SavedConnection
+}
+
+// Model is the switcher modal.
+type Model struct {
+	conns   []config.SavedConnection // recent first, then in saved order
+	recent  int                      // how many of conns are recent
+	current string                   // name of the active connection
+	items   []int                    // indexes into conns matching the filter
+	cursor  int
+	offset  int
+	input   textinput.Model
+	visible bool
+	width   int
+	height  int
+}
+
+// New creates a hidden switcher.
+func New() Model {
+	ti := textinput.New()
+	ti.Prompt = "  > "
+	ti.Placeholder = "connection name, group or env"
+	ti.Width = 50
+	return Model{input: ti}
+}
+
+// Show opens the switcher on the saved connections. recent names the most
+// recently used ones, newest first, and current the active one.
+func (m *Model) Show(conns []config.SavedConnection, recent []string, current string) {
+	byName := make(map[string]int, len(conns))
+	for i, c := range conns {
+		byName[c.Name] = i
+	}
+	used := make(map[int]bool)
+	m.conns = m.conns[:0]
+	for _, name := range recent {
+		if i, ok := byName[name]; ok && !used[i] {
+			used[i] = true
+			m.conns = append(m.conns, conns[i])
+		}
+	}
+	m.recent = len(m.conns)
+	for i, c := range conns {
+		if !used[i] {
+			m.conns = append(m.conns, c)
+		}
+	}
+
+	m.current = current
+	m.visible = true
+	m.input.SetValue("")
+	m.input.Focus()
+	m.filter()
+	// The active connection is usually first; start on the one to switch to.
+	if len(m.items) > 1 && m.conns[m.items[0]].Name == current {
+		m.cursor = 1
+	}
+}
+
+// Hide closes the switcher.
+func (m *Model) Hide() {
+	m.visible = false
+	m.input.Blur()
+}
+
+// Visible returns whether the switcher is shown.
+func (m Model) Visible() bool { return m.visible }
+
+// SetSize sets the available space.
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// Update handles switcher key presses.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if !m.visible {
+		return m, nil
+	}
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+
+	switch key.String() {
+	case "esc", "ctrl+p":
+		m.Hide()
+		return m, nil
+	case "up", "ctrl+k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		m.ensureVisible()
+		return m, nil
+	case "down", "ctrl+n", "ctrl+j":
+		if m.cursor < len(m.items)-1 {
+			m.cursor++
+		}
+		m.ensureVisible()
+		return m, nil
+	case "enter":
+		if m.cursor >= len(m.items) {
+			return m, nil
+		}
+		conn := m.conns[m.items[m.cursor]]
+		m.Hide()
+		return m, func() tea.Msg { return PickMsg{Conn: conn} }
+	}
+
+	prev := m.input.Value()
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(key)
+	if m.input.Value() != prev {
+		m.filter()
+	}
+	return m, cmd
+}
+
+// filter lists the connections matching the typed text, best match first,
+// or all of them when nothing is typed.
+func (m *Model) filter() {
+	m.cursor = 0
+	m.offset = 0
+	m.items = m.items[:0]
+	pattern := strings.TrimSpace(m.input.Value())
+	if pattern == "" {
+		for i := range m.conns {
+			m.items = append(m.items, i)
+		}
+		return
+	}
+	words := make([]string, len(m.conns))
+	for i, c := range m.conns {
+		words[i] = strings.TrimSpace(c.Name + " " + c.Group + " " + c.Env)
+	}
+	for _, match := range fuzzy.Find(pattern, words) {
+		m.items = append(m.items, match.Index)
+	}
+}
+
+// View renders the switcher.
+func (m Model) View() string {
+	if !m.visible {
+		return ""
+	}
+	th := theme.Current
+	w := m.dialogWidth()
+
+	var lines []string
+	end := min(m.offset+m.visibleCount(), len(m.items))
+	for pos := m.offset; pos < end; pos++ {
+		i := m.items[pos]
+		c := m.conns[i]
+		mark := "  "
+		if c.Name == m.current {
+			mark = "● "
+		}
+		line := mark + c.Name + "  " + c.DisplayString()
+		if c.Group != "" {
+			line += "  [" + c.Group + "]"
+		}
+		if i < m.recent {
+			line += "  recent"
+		}
+		line = runewidth.Truncate(line, w-14, "…")
+		tag := ""
+		if c.Env != "" {
+			tag = " " + theme.EnvBadge(theme.EnvColor(c.Env, c.Color)).Render(" "+strings.ToUpper(c.Env)+" ")
+		}
+		if pos == m.cursor {
+			lines = append(lines, th.SidebarSelected.Render("  "+line)+tag)
+		} else {
+			lines = append(lines, "  "+line+tag)
+		}
+	}
+	if len(m.items) == 0 {
+		msg := "  No matching connections"
+		if len(m.conns) == 0 {
+			msg = "  No saved connections (Ctrl+O to add one)"
+		}
+		lines = append(lines, th.MutedText.Render(msg))
+	}
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		th.DialogTitle.Render("  Switch Connection  "),
+		m.input.View(),
+		"",
+		strings.Join(lines, "\n"),
+		"",
+		th.MutedText.Render(fmt.Sprintf("  %d connections  enter:switch  esc:close", len(m.items))),
+	)
+	return th.DialogBorder.Width(w).Render(content)
+}
+
+func (m Model) dialogWidth() int {
+	w := 70
+	if m.width > 0 && w > m.width-4 {
+		w = m.width - 4
+	}
+	return w
+}
+
+// visibleCount returns how many connections fit in the list.
+func (m Model) visibleCount() int {
+	// Title, input, blank, blank, footer and the border take 7 lines.
+	return max(3, m.height-7)
+}
+
+func (m *Model) ensureVisible() {
+	visible := m.visibleCount()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+visible {
+		m.offset = m.cursor - visible + 1
+	}
+}
@@ -0,0 +1,78 @@
+package switcher
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sadopc/gotermsql/internal/config"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+func init() {
+	theme.Current = theme.Default()
+}
+
+var conns = []config.SavedConnection{
+	{Name: "local", Adapter: "sqlite", File: "/tmp/local.db"},
+	{Name: "acme-prod", Adapter: "postgres", Host: "db1", Group: "acme", Env: "prod"},
+	{Name: "acme-staging", Adapter: "postgres", Host: "db2", Group: "acme", Env: "staging"},
+	{Name: "home", Adapter: "mysql", Host: "nas"},
+}
+
+func names(m Model) []string {
+	var out []string
+	for _, i := range m.items {
+		out = append(out, m.conns[i].Name)
+	}
+	return out
+}
+
+func TestShow_RecentFirst(t *testing.T) {
+	m := New()
+	m.SetSize(100, 30)
+	m.Show(conns, []string{"acme-prod", "gone", "home"}, "acme-prod")
+
+	got := strings.Join(names(m), ",")
+	if want := "acme-prod,home,local,acme-staging"; got != want {
+		t.Errorf("order = %s, want %s", got, want)
+	}
+	// The active connection is first; the cursor starts on the next one.
+	if m.cursor != 1 {
+		t.Errorf("cursor = %d, want 1", m.cursor)
+	}
+	if view := m.View(); !strings.Contains(view, "● acme-prod") || !strings.Contains(view, "recent") {
+		t.Errorf("view should mark the active and recent connections:\n%s", view)
+	}
+}
+
+func TestFilterAndPick(t *testing.T) {
+	m := New()
+	m.SetSize(100, 30)
+	m.Show(conns, nil, "")
+
+	for _, r := range "stag" {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if got := names(m); len(got) != 1 || got[0] != "acme-staging" {
+		t.Fatalf("matches = %v, want [acme-staging]", got)
+	}
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.Visible() {
+		t.Error("switcher should close on enter")
+	}
+	pick, ok := cmd().(PickMsg)
+	if !ok || pick.Conn.Name != "acme-staging" {
+		t.Errorf("got %#v, want PickMsg for acme-staging", cmd())
+	}
+}
+
+func TestEsc(t *testing.T) {
+	m := New()
+	m.Show(conns, nil, "")
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.Visible() || cmd != nil {
+		t.Error("esc should close the switcher without a command")
+	}
+}
@@ -2,6 +2,7 @@ package tabs
 
 import (
 	"fmt"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -14,6 +15,7 @@ type Tab struct {
 	ID       int
 	Title    string
 	Modified bool
+	Done     bool // a query finished while the tab was not shown
 }
 
 // Model is the tab bar component.
@@ -22,6 +24,9 @@ type Model struct {
 	active int
 	nextID int
 	width  int
+
+	env      string // environment tag of the connection, e.g. "prod"
+	envColor string
 }
 
 // New creates a new tab bar with one default tab.
@@ -69,6 +74,12 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		idx := m.indexByID(msg.TabID)
 		if idx >= 0 {
 			m.active = idx
+			m.tabs[idx].Done = false
+		}
+
+	case tea.MouseMsg:
+		if msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft {
+			return m, m.click(msg.X)
 		}
 	}
 
@@ -80,19 +91,36 @@ func (m Model) View() string {
 	if m.width == 0 {
 		return ""
 	}
+	bar := lipgloss.JoinHorizontal(lipgloss.Bottom, m.parts()...)
+	return theme.Current.TabBar.Width(m.width).Render(bar)
+}
 
+// parts renders the pieces of the bar from left to right: the environment
+// badge if there is one, the tabs, then the new tab button.
+func (m Model) parts() []string {
 	th := theme.Current
 
 	var tabs []string
+	if m.env != "" {
+		// Tabs are bordered; pad the badge to their height.
+		badge := theme.EnvBadge(m.envColor).Padding(1, 1, 0, 1)
+		tabs = append(tabs, badge.Render(strings.ToUpper(m.env)))
+	}
 	for i, tab := range m.tabs {
 		title := tab.Title
 		if tab.Modified {
 			title += " *"
 		}
+		if tab.Done {
+			title += " ●"
+		}
 
 		var style lipgloss.Style
 		if i == m.active {
 			style = th.TabActive
+			if m.envColor != "" {
+				style = style.BorderForeground(lipgloss.Color(m.envColor))
+			}
 		} else {
 			style = th.TabInactive
 		}
@@ -101,9 +129,35 @@ func (m Model) View() string {
 
 	newTabBtn := th.TabInactive.Render(" + ")
 	tabs = append(tabs, newTabBtn)
+	return tabs
+}
 
-	bar := lipgloss.JoinHorizontal(lipgloss.Bottom, tabs...)
-	return th.TabBar.Width(m.width).Render(bar)
+// click switches to the tab at column x, or opens a new tab if x is on the
+// new tab button.
+func (m *Model) click(x int) tea.Cmd {
+	parts := m.parts()
+	first := 0
+	if m.env != "" {
+		first = 1
+	}
+	for i, part := range parts {
+		w := lipgloss.Width(part)
+		if x >= w {
+			x -= w
+			continue
+		}
+		switch idx := i - first; {
+		case idx < 0:
+			return nil
+		case idx == len(m.tabs):
+			return func() tea.Msg { return appmsg.NewTabMsg{} }
+		default:
+			m.active = idx
+			id := m.tabs[idx].ID
+			return func() tea.Msg { return appmsg.SwitchTabMsg{TabID: id} }
+		}
+	}
+	return nil
 }
 
 // SetSize sets the tab bar width.
@@ -111,6 +165,13 @@ func (m *Model) SetSize(width int) {
 	m.width = width
 }
 
+// SetEnv sets the environment tag of the connection and its color, shown
+// at the start of the bar. An empty env hides it.
+func (m *Model) SetEnv(env, color string) {
+	m.env = env
+	m.envColor = color
+}
+
 // ActiveTab returns the active tab.
 func (m Model) ActiveTab() Tab {
 	if m.active < len(m.tabs) {
@@ -132,6 +193,23 @@ func (m *Model) SetModified(tabID int, modified bool) {
 	}
 }
 
+// SetDone marks a tab as having a finished query not yet looked at. The
+// mark goes when the tab is switched to.
+func (m *Model) SetDone(tabID int, done bool) {
+	idx := m.indexByID(tabID)
+	if idx >= 0 {
+		m.tabs[idx].Done = done
+	}
+}
+
+// SetTitle renames a tab.
+func (m *Model) SetTitle(tabID int, title string) {
+	idx := m.indexByID(tabID)
+	if idx >= 0 {
+		m.tabs[idx].Title = title
+	}
+}
+
 // NextTab switches to the next tab.
 func (m *Model) NextTab() tea.Cmd {
 	if len(m.tabs) == 0 {
@@ -1,6 +1,7 @@
 package tabs
 
 import (
+	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -254,6 +255,24 @@ func TestSetModified(t *testing.T) {
 	m.SetModified(999, true)
 }
 
+func TestSetDone(t *testing.T) {
+	m := New()
+	m.SetSize(80)
+	m, _ = m.Update(appmsg.NewTabMsg{})
+
+	m.SetDone(0, true)
+	if !strings.Contains(m.View(), "Query 1 ●") {
+		t.Error("expected the done tab to show a badge")
+	}
+	m, _ = m.Update(appmsg.SwitchTabMsg{TabID: 0})
+	if m.Tabs()[0].Done || strings.Contains(m.View(), "●") {
+		t.Error("expected switching to the tab to clear its badge")
+	}
+
+	// SetDone on non-existent tab should not panic.
+	m.SetDone(999, true)
+}
+
 func TestActiveTab(t *testing.T) {
 	m := New()
 	m, _ = m.Update(appmsg.NewTabMsg{})
@@ -358,3 +377,48 @@ func TestUpdate_UnknownMsg(t *testing.T) {
 		t.Fatalf("expected 1 tab, got %d", m.Count())
 	}
 }
+
+func TestSetEnv(t *testing.T) {
+	m := New()
+	m.SetSize(80)
+	m.SetEnv("staging", "#CCA700")
+	if !strings.Contains(m.View(), "STAGING") {
+		t.Errorf("view should show the environment:\n%s", m.View())
+	}
+	m.SetEnv("", "")
+	if strings.Contains(m.View(), "STAGING") {
+		t.Errorf("view should not show an environment:\n%s", m.View())
+	}
+}
+
+func TestClick(t *testing.T) {
+	m := New()
+	m.SetSize(80)
+	m.SetEnv("prod", "#CC0000")
+	m, _ = m.Update(appmsg.NewTabMsg{})
+	click := func(x int) tea.Msg {
+		var cmd tea.Cmd
+		m, cmd = m.Update(tea.MouseMsg{X: x, Y: 1, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+		if cmd == nil {
+			return nil
+		}
+		return cmd()
+	}
+
+	// The badge is 6 columns wide, each tab 9 and the new tab button 5.
+	if msg := click(3); msg != nil {
+		t.Errorf("a click on the badge sent %#v", msg)
+	}
+	if msg := click(8); msg != (appmsg.SwitchTabMsg{TabID: 0}) || m.active != 0 {
+		t.Errorf("a click on the first tab sent %#v, active = %d", msg, m.active)
+	}
+	if msg := click(20); msg != (appmsg.SwitchTabMsg{TabID: 1}) || m.active != 1 {
+		t.Errorf("a click on the second tab sent %#v, active = %d", msg, m.active)
+	}
+	if msg := click(26); msg != (appmsg.NewTabMsg{}) {
+		t.Errorf("a click on + sent %#v, want a NewTabMsg", msg)
+	}
+	if msg := click(60); msg != nil {
+		t.Errorf("a click past the tabs sent %#v", msg)
+	}
+}
@@ -0,0 +1,144 @@
+package toast
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mattn/go-runewidth"
+	appmsg "github.com/sadopc/gotermsql/internal/msg"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+const (
+	maxToasts = 4  // older toasts are dropped when more arrive
+	maxWidth  = 48 // toast width, border included
+	top       = 1  // rows left above the stack, for the tab bar
+)
+
+// lifetime is how long a toast of each level stays up; errors stay longer
+// so they can be read.
+var lifetime = map[appmsg.ToastLevel]time.Duration{
+	appmsg.ToastInfo:    4 * time.Second,
+	appmsg.ToastSuccess: 4 * time.Second,
+	appmsg.ToastWarning: 6 * time.Second,
+	appmsg.ToastError:   8 * time.Second,
+}
+
+// DismissMsg removes a toast when its time is up.
+type DismissMsg struct {
+	ID uint64
+}
+
+type toast struct {
+	id    uint64
+	text  string
+	level appmsg.ToastLevel
+}
+
+// Model is a stack of notifications in the top-right corner, newest at
+// the bottom.
+type Model struct {
+	toasts []toast
+	nextID uint64
+	width  int
+}
+
+// New creates an empty stack.
+func New() Model {
+	return Model{}
+}
+
+// SetSize sets the width of the screen the stack is drawn over.
+func (m *Model) SetSize(width int) {
+	m.width = width
+}
+
+// Visible returns whether any toast is shown.
+func (m Model) Visible() bool { return len(m.toasts) > 0 }
+
+// Update adds toasts and dismisses them when their time is up.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case appmsg.ToastMsg:
+		m.nextID++
+		id := m.nextID
+		m.toasts = append(m.toasts, toast{id: id, text: msg.Text, level: msg.Level})
+		if len(m.toasts) > maxToasts {
+			m.toasts = m.toasts[len(m.toasts)-maxToasts:]
+		}
+		return m, tea.Tick(lifetime[msg.Level], func(time.Time) tea.Msg {
+			return DismissMsg{ID: id}
+		})
+
+	case DismissMsg:
+		for i, t := range m.toasts {
+			if t.id == msg.ID {
+				m.toasts = append(m.toasts[:i:i], m.toasts[i+1:]...)
+				break
+			}
+		}
+	}
+	return m, nil
+}
+
+// View renders the stack.
+func (m Model) View() string {
+	if len(m.toasts) == 0 {
+		return ""
+	}
+	width := min(maxWidth, m.width-2)
+	boxes := make([]string, 0, len(m.toasts))
+	for _, t := range m.toasts {
+		boxes = append(boxes, render(t, width))
+	}
+	return lipgloss.JoinVertical(lipgloss.Right, boxes...)
+}
+
+// render draws one toast in a box width columns wide, its border in the
+// color of its level.
+func render(t toast, width int) string {
+	th := theme.Current
+	var icon string
+	var style lipgloss.Style
+	switch t.level {
+	case appmsg.ToastSuccess:
+		icon, style = "✓", th.SuccessText
+	case appmsg.ToastWarning:
+		icon, style = "!", th.WarningText
+	case appmsg.ToastError:
+		icon, style = "✗", th.ErrorText
+	default:
+		icon, style = "i", th.MutedText
+	}
+	text := runewidth.Truncate(strings.ReplaceAll(t.text, "\n", " "), width-6, "…")
+	return lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(style.GetForeground()).
+		Padding(0, 1).
+		Render(style.Render(icon) + " " + text)
+}
+
+// Overlay draws the stack over the top-right corner of background.
+func (m Model) Overlay(background string) string {
+	stack := m.View()
+	if stack == "" {
+		return background
+	}
+	lines := strings.Split(background, "\n")
+	for i, line := range strings.Split(stack, "\n") {
+		y := top + i
+		if y >= len(lines) {
+			break
+		}
+		x := max(m.width-lipgloss.Width(line)-1, 0)
+		left := ansi.Truncate(lines[y], x, "")
+		if pad := x - lipgloss.Width(left); pad > 0 {
+			left += strings.Repeat(" ", pad)
+		}
+		lines[y] = left + line + ansi.TruncateLeft(lines[y], x+lipgloss.Width(line), "")
+	}
+	return strings.Join(lines, "\n")
+}
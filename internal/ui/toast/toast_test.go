@@ -0,0 +1,84 @@
+package toast
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	appmsg "github.com/sadopc/gotermsql/internal/msg"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+func init() {
+	theme.Current = theme.Default()
+}
+
+func TestUpdate_StackAndDismiss(t *testing.T) {
+	m := New()
+	m.SetSize(80)
+	if m.Visible() {
+		t.Fatal("a new stack should be empty")
+	}
+
+	m, cmd := m.Update(appmsg.ToastMsg{Text: "first", Level: appmsg.ToastSuccess})
+	if cmd == nil {
+		t.Fatal("a toast should schedule its dismissal")
+	}
+	for _, text := range []string{"second", "third", "fourth", "fifth"} {
+		m, _ = m.Update(appmsg.ToastMsg{Text: text})
+	}
+	if len(m.toasts) != maxToasts {
+		t.Fatalf("len(toasts) = %d, want %d", len(m.toasts), maxToasts)
+	}
+	view := m.View()
+	if strings.Contains(view, "first") || !strings.Contains(view, "fifth") {
+		t.Errorf("the oldest toast should be dropped first:\n%s", view)
+	}
+
+	second := m.toasts[0].id
+	m, _ = m.Update(DismissMsg{ID: second})
+	if strings.Contains(m.View(), "second") {
+		t.Errorf("a dismissed toast should be gone:\n%s", m.View())
+	}
+	m, _ = m.Update(DismissMsg{ID: second})
+	if len(m.toasts) != maxToasts-1 {
+		t.Errorf("dismissing twice should change nothing, len(toasts) = %d", len(m.toasts))
+	}
+}
+
+func TestOverlay(t *testing.T) {
+	m := New()
+	m.SetSize(60)
+	m, _ = m.Update(appmsg.ToastMsg{Text: "Export failed: disk full", Level: appmsg.ToastError})
+
+	bg := make([]string, 6)
+	for i := range bg {
+		bg[i] = strings.Repeat("x", 60)
+	}
+	lines := strings.Split(m.Overlay(strings.Join(bg, "\n")), "\n")
+	if len(lines) != len(bg) {
+		t.Fatalf("overlay has %d lines, want %d", len(lines), len(bg))
+	}
+	if lines[0] != bg[0] {
+		t.Errorf("the tab bar row should be left alone: %q", lines[0])
+	}
+	if !strings.Contains(lines[2], "Export failed: disk full") {
+		t.Errorf("the toast text should be on the second row of the stack:\n%s", strings.Join(lines, "\n"))
+	}
+	for i, line := range lines {
+		if w := lipgloss.Width(line); w != 60 {
+			t.Errorf("line %d is %d columns wide, want 60", i, w)
+		}
+	}
+	if !strings.HasPrefix(lines[2], "xxxx") || !strings.HasSuffix(lines[2], "x") {
+		t.Errorf("the toast should sit at the right with one column of background after it: %q", lines[2])
+	}
+	if lines[5] != bg[5] {
+		t.Errorf("rows below the stack should be left alone: %q", lines[5])
+	}
+
+	m.toasts = nil
+	if got := m.Overlay("bg"); got != "bg" {
+		t.Errorf("an empty stack should leave the background alone, got %q", got)
+	}
+}
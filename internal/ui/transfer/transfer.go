@@ -0,0 +1,266 @@
+// Package transfer is the wizard that copies the rows of a table to a
+// table on another connection, opened from the sidebar menu: pick the
+// saved connection to copy to, name the table there (created when it is
+// missing) and follow the rows copied.
+package transfer
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+// Target is a saved connection rows can be copied to.
+type Target struct {
+	Name    string
+	Adapter string
+}
+
+// StartMsg asks the app to copy the rows to Table on Target, deleting the
+// rows Table has first when Empty is set.
+type StartMsg struct {
+	Target Target
+	Table  string
+	Empty  bool
+}
+
+// CancelMsg asks the app to stop the copy running.
+type CancelMsg struct{}
+
+// step is a page of the wizard.
+type step int
+
+const (
+	stepTarget  step = iota // pick the connection
+	stepOptions             // name the table
+	stepRunning             // rows being copied
+	stepDone                // finished, failed or stopped
+)
+
+// Model is the copy wizard modal.
+type Model struct {
+	table    string
+	targets  []Target
+	cursor   int
+	offset   int
+	target   Target
+	input    textinput.Model
+	empty    bool
+	step     step
+	progress string
+	message  string
+	failed   bool
+	visible  bool
+	width    int
+	height   int
+}
+
+// New creates a hidden wizard.
+func New() Model {
+	ti := textinput.New()
+	ti.Prompt = "  Table: "
+	ti.Width = 50
+	return Model{input: ti}
+}
+
+// Show opens the wizard to copy the rows of table to one of targets.
+func (m *Model) Show(table string, targets []Target) {
+	input := m.input
+	input.SetValue(table)
+	input.Blur()
+	*m = Model{table: table, targets: targets, input: input, visible: true, width: m.width, height: m.height}
+}
+
+// Hide closes the wizard.
+func (m *Model) Hide() {
+	m.visible = false
+	m.input.Blur()
+}
+
+// Visible returns whether the wizard is shown.
+func (m Model) Visible() bool { return m.visible }
+
+// Running returns whether rows are being copied.
+func (m Model) Running() bool { return m.step == stepRunning }
+
+// SetSize sets the available space.
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.ensureVisible()
+}
+
+// SetProgress shows how far the copy has got.
+func (m *Model) SetProgress(text string) {
+	if m.step == stepRunning {
+		m.progress = text
+	}
+}
+
+// Done shows how the copy ended.
+func (m *Model) Done(text string, failed bool) {
+	m.step = stepDone
+	m.message = text
+	m.failed = failed
+}
+
+// Update handles key presses, page by page: pick a connection, name the
+// table and start, then follow the copy.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if !m.visible {
+		return m, nil
+	}
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		if m.step == stepOptions {
+			var cmd tea.Cmd
+			m.input, cmd = m.input.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	switch m.step {
+	case stepTarget:
+		switch key.String() {
+		case "esc", "q":
+			m.Hide()
+		case "up", "k":
+			m.cursor = max(m.cursor-1, 0)
+		case "down", "j":
+			m.cursor = max(min(m.cursor+1, len(m.targets)-1), 0)
+		case "enter":
+			if m.cursor < len(m.targets) {
+				m.target = m.targets[m.cursor]
+				m.step = stepOptions
+				m.input.Focus()
+				m.input.CursorEnd()
+			}
+		}
+		m.ensureVisible()
+		return m, nil
+
+	case stepOptions:
+		switch key.String() {
+		case "esc":
+			m.step = stepTarget
+			m.input.Blur()
+			return m, nil
+		case "tab":
+			m.empty = !m.empty
+			return m, nil
+		case "enter":
+			table := strings.TrimSpace(m.input.Value())
+			if table == "" {
+				return m, nil
+			}
+			m.step = stepRunning
+			m.progress = ""
+			m.input.Blur()
+			start := StartMsg{Target: m.target, Table: table, Empty: m.empty}
+			return m, func() tea.Msg { return start }
+		}
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(key)
+		return m, cmd
+
+	case stepRunning:
+		if key.String() == "esc" {
+			m.progress = "Stopping..."
+			return m, func() tea.Msg { return CancelMsg{} }
+		}
+		return m, nil
+	}
+
+	switch key.String() {
+	case "esc", "q", "enter":
+		m.Hide()
+	}
+	return m, nil
+}
+
+// visibleCount returns how many connections fit in the list.
+func (m Model) visibleCount() int {
+	// Title, subtitle, blank, blank, footer and the border take 7 lines.
+	return max(3, m.height-7)
+}
+
+func (m *Model) ensureVisible() {
+	visible := m.visibleCount()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+visible {
+		m.offset = m.cursor - visible + 1
+	}
+}
+
+// View renders the wizard.
+func (m Model) View() string {
+	if !m.visible {
+		return ""
+	}
+	th := theme.Current
+	w := 80
+	if m.width > 0 && w > m.width-4 {
+		w = m.width - 4
+	}
+	textW := w - 6
+
+	lines := []string{th.DialogTitle.Render("  Copy Data: " + m.table + "  ")}
+	var help string
+	switch m.step {
+	case stepTarget:
+		lines = append(lines, th.MutedText.Render("  Copy the rows to the saved connection:"), "")
+		end := min(m.offset+m.visibleCount(), len(m.targets))
+		for i := m.offset; i < end; i++ {
+			t := m.targets[i]
+			line := runewidth.Truncate(runewidth.FillRight(runewidth.Truncate(t.Name, 40, "…"), 42)+t.Adapter, textW, "…")
+			if i == m.cursor {
+				lines = append(lines, "  "+th.SidebarSelected.Render(runewidth.FillRight(line, textW)))
+			} else {
+				lines = append(lines, "  "+line)
+			}
+		}
+		if len(m.targets) == 0 {
+			lines = append(lines, th.MutedText.Render("  No saved connections"))
+		}
+		help = "enter:pick  esc:close"
+
+	case stepOptions:
+		check := "[ ]"
+		if m.empty {
+			check = "[x]"
+		}
+		lines = append(lines,
+			th.MutedText.Render("  To "+m.target.Name+" ("+m.target.Adapter+"), created there when missing:"),
+			"",
+			m.input.View(),
+			"  "+check+" Delete the rows it has first",
+		)
+		help = "enter:copy  tab:toggle delete  esc:back"
+
+	case stepRunning:
+		progress := m.progress
+		if progress == "" {
+			progress = "Starting..."
+		}
+		lines = append(lines, th.MutedText.Render("  To "+m.target.Name+" ("+m.target.Adapter+")"), "", "  "+progress)
+		help = "esc:stop"
+
+	case stepDone:
+		style := th.MutedText
+		if m.failed {
+			style = th.ErrorText
+		}
+		lines = append(lines, th.MutedText.Render("  To "+m.target.Name+" ("+m.target.Adapter+")"), "",
+			style.Render("  "+runewidth.Truncate(m.message, textW, "…")))
+		help = "esc:close"
+	}
+	lines = append(lines, "", th.MutedText.Render("  "+help))
+	return th.DialogBorder.Width(w).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
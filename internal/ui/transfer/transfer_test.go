@@ -0,0 +1,76 @@
+package transfer
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestWizard(t *testing.T) {
+	m := New()
+	m.SetSize(120, 30)
+	local := Target{Name: "local", Adapter: "sqlite"}
+	m.Show("users", []Target{{Name: "prod", Adapter: "postgres"}, local})
+	if view := m.View(); !strings.Contains(view, "Copy Data: users") || !strings.Contains(view, "postgres") {
+		t.Errorf("view lacks the title or the connections:\n%s", view)
+	}
+
+	m, _ = m.Update(key("down"))
+	m, _ = m.Update(key("enter"))
+	if view := m.View(); !strings.Contains(view, "To local (sqlite)") || !strings.Contains(view, "[ ] Delete") {
+		t.Errorf("view lacks the options:\n%s", view)
+	}
+	m, _ = m.Update(key("_2"))
+	m, _ = m.Update(key("tab"))
+	m, cmd := m.Update(key("enter"))
+	if cmd == nil || cmd() != (StartMsg{Target: local, Table: "users_2", Empty: true}) {
+		t.Fatal("enter should start the copy to users_2 on local, emptied first")
+	}
+	if !m.Running() {
+		t.Fatal("starting should show the progress")
+	}
+
+	m.SetProgress("10 rows copied")
+	if view := m.View(); !strings.Contains(view, "10 rows copied") {
+		t.Errorf("view lacks the progress:\n%s", view)
+	}
+	if m, cmd = m.Update(key("esc")); cmd == nil || cmd() != (CancelMsg{}) || !m.Visible() {
+		t.Fatal("esc while running should stop the copy and wait for it")
+	}
+
+	m.Done("Stopped after 10 rows", true)
+	if m.Running() || !strings.Contains(m.View(), "Stopped after 10 rows") {
+		t.Errorf("view lacks how the copy ended:\n%s", m.View())
+	}
+	if m, _ = m.Update(key("enter")); m.Visible() {
+		t.Error("enter should close the finished wizard")
+	}
+}
+
+func TestWizard_Back(t *testing.T) {
+	m := New()
+	m.Show("users", []Target{{Name: "local", Adapter: "sqlite"}})
+	m, _ = m.Update(key("enter"))
+	m, _ = m.Update(key("esc"))
+	if !m.Visible() || m.Running() {
+		t.Fatal("esc should go back to the connections")
+	}
+	if m, _ = m.Update(key("esc")); m.Visible() {
+		t.Error("esc on the connections should close the wizard")
+	}
+}
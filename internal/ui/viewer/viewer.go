@@ -0,0 +1,181 @@
+package viewer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	appmsg "github.com/sadopc/gotermsql/internal/msg"
+	"github.com/sadopc/gotermsql/internal/theme"
+)
+
+// writeClipboard is swapped out in tests.
+var writeClipboard = clipboard.WriteAll
+
+// Model is a read-only modal that shows a block of text, such as the DDL
+// of a table, with scrolling and copy support.
+type Model struct {
+	title   string
+	text    string
+	lines   []string
+	offset  int // first visible line
+	visible bool
+	width   int
+	height  int
+
+	// styles render the lines; lines past its end are plain.
+	styles []lipgloss.Style
+}
+
+// New creates a hidden viewer.
+func New() Model {
+	return Model{}
+}
+
+// Show opens the viewer on text.
+func (m *Model) Show(title, text string) {
+	m.title = title
+	m.text = text
+	m.lines = strings.Split(strings.ReplaceAll(text, "\t", "    "), "\n")
+	m.styles = nil
+	m.offset = 0
+	m.visible = true
+}
+
+// ShowStyled opens the viewer on text, rendering line i with styles[i].
+// Lines past the end of styles are left plain.
+func (m *Model) ShowStyled(title, text string, styles []lipgloss.Style) {
+	m.Show(title, text)
+	m.styles = styles
+}
+
+// Hide closes the viewer.
+func (m *Model) Hide() {
+	m.visible = false
+}
+
+// Visible returns whether the viewer is shown.
+func (m Model) Visible() bool { return m.visible }
+
+// Title returns the title of the text shown.
+func (m Model) Title() string { return m.title }
+
+// SetSize sets the available space.
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// Update handles viewer key presses. y copies the text, e opens it in a new
+// query tab, and esc or q closes the viewer.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !m.visible || !ok {
+		return m, nil
+	}
+
+	visible := m.visibleCount()
+	switch key.String() {
+	case "esc", "q":
+		m.visible = false
+	case "y":
+		text, title := m.text, m.title
+		return m, func() tea.Msg {
+			if err := writeClipboard(text); err != nil {
+				return appmsg.StatusMsg{Text: "Copy failed: " + err.Error(), IsError: true}
+			}
+			return appmsg.StatusMsg{Text: "Copied " + title}
+		}
+	case "e":
+		m.visible = false
+		text := m.text
+		return m, func() tea.Msg { return appmsg.NewTabMsg{Query: text} }
+	case "up", "k":
+		m.offset--
+	case "down", "j":
+		m.offset++
+	case "pgup", "b":
+		m.offset -= visible
+	case "pgdown", "f", " ":
+		m.offset += visible
+	case "home", "g":
+		m.offset = 0
+	case "end", "G":
+		m.offset = len(m.lines)
+	}
+	m.clampOffset()
+	return m, nil
+}
+
+// View renders the viewer.
+func (m Model) View() string {
+	if !m.visible {
+		return ""
+	}
+
+	th := theme.Current
+	w := m.dialogWidth()
+	textW := w - 6
+
+	visible := m.visibleCount()
+	end := m.offset + visible
+	if end > len(m.lines) {
+		end = len(m.lines)
+	}
+	var lines []string
+	for i, line := range m.lines[m.offset:end] {
+		line = runewidth.Truncate(line, textW, "…")
+		if i+m.offset < len(m.styles) {
+			line = m.styles[i+m.offset].Render(line)
+		}
+		lines = append(lines, "  "+line)
+	}
+
+	position := fmt.Sprintf("  %d lines", len(m.lines))
+	if len(m.lines) > visible {
+		position = fmt.Sprintf("  lines %d-%d of %d", m.offset+1, end, len(m.lines))
+	}
+	help := th.MutedText.Render("  y:copy  e:open in new tab  esc:close  up/down:scroll")
+
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		th.DialogTitle.Render("  "+m.title+"  "),
+		"",
+		strings.Join(lines, "\n"),
+		"",
+		th.MutedText.Render(position),
+		help,
+	)
+
+	return th.DialogBorder.Width(w).Render(content)
+}
+
+func (m Model) dialogWidth() int {
+	w := 100
+	if m.width > 0 && w > m.width-4 {
+		w = m.width - 4
+	}
+	return w
+}
+
+// visibleCount returns how many lines of text fit in the visible area.
+func (m Model) visibleCount() int {
+	// Title + blank + blank + position + help = 5 lines of chrome
+	// Plus 2 for border
+	avail := m.height - 7
+	if avail < 3 {
+		avail = 3
+	}
+	return avail
+}
+
+func (m *Model) clampOffset() {
+	if last := len(m.lines) - m.visibleCount(); m.offset > last {
+		m.offset = last
+	}
+	if m.offset < 0 {
+		m.offset = 0
+	}
+}
@@ -0,0 +1,88 @@
+package viewer
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	appmsg "github.com/sadopc/gotermsql/internal/msg"
+)
+
+func keyMsg(key string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+func longText(n int) string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = "line"
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestScroll(t *testing.T) {
+	m := New()
+	m.SetSize(80, 17) // 10 visible lines
+	m.Show("users", longText(25))
+
+	m, _ = m.Update(keyMsg("j"))
+	if m.offset != 1 {
+		t.Fatalf("offset after j = %d, want 1", m.offset)
+	}
+	m, _ = m.Update(keyMsg("G"))
+	if m.offset != 15 {
+		t.Fatalf("offset after G = %d, want 15", m.offset)
+	}
+	m, _ = m.Update(keyMsg("j"))
+	if m.offset != 15 {
+		t.Errorf("offset should stop at the last page, got %d", m.offset)
+	}
+	if !strings.Contains(m.View(), "lines 16-25 of 25") {
+		t.Error("view should show the visible line range")
+	}
+	m, _ = m.Update(keyMsg("g"))
+	if m.offset != 0 {
+		t.Errorf("offset after g = %d, want 0", m.offset)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.Visible() {
+		t.Error("esc should close the viewer")
+	}
+}
+
+func TestCopy(t *testing.T) {
+	orig := writeClipboard
+	defer func() { writeClipboard = orig }()
+	var copied string
+	writeClipboard = func(s string) error { copied = s; return nil }
+
+	m := New()
+	m.Show("users", "CREATE TABLE users (id int);")
+	m, cmd := m.Update(keyMsg("y"))
+	if cmd == nil {
+		t.Fatal("y should return a command")
+	}
+	if msg, ok := cmd().(appmsg.StatusMsg); !ok || msg.IsError {
+		t.Fatalf("expected a success StatusMsg, got %#v", msg)
+	}
+	if copied != "CREATE TABLE users (id int);" {
+		t.Errorf("copied %q", copied)
+	}
+	if !m.Visible() {
+		t.Error("copying should keep the viewer open")
+	}
+}
+
+func TestOpenInTab(t *testing.T) {
+	m := New()
+	m.Show("users", "CREATE TABLE users (id int);")
+	m, cmd := m.Update(keyMsg("e"))
+	if m.Visible() {
+		t.Error("e should close the viewer")
+	}
+	msg, ok := cmd().(appmsg.NewTabMsg)
+	if !ok || msg.Query != "CREATE TABLE users (id int);" {
+		t.Errorf("expected NewTabMsg with the text, got %#v", cmd())
+	}
+}